@@ -7,31 +7,72 @@ import (
 	"github.com/joho/godotenv"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"go.uber.org/fx"
 	"log"
 	"os"
 	"path/filepath"
+	"time"
 	"vivu/cmd/fx/account_fx"
+	"vivu/cmd/fx/account_merge_fx"
+	"vivu/cmd/fx/announcement_fx"
+	"vivu/cmd/fx/api_key_fx"
+	"vivu/cmd/fx/billing_fx"
+	"vivu/cmd/fx/booking_fx"
+	"vivu/cmd/fx/churn_fx"
+	"vivu/cmd/fx/collection_fx"
 	"vivu/cmd/fx/controllers_fx"
+	"vivu/cmd/fx/coupon_fx"
 	"vivu/cmd/fx/dashboard"
 	"vivu/cmd/fx/db_fx"
+	"vivu/cmd/fx/destination_rule_fx"
+	"vivu/cmd/fx/diagnostics_fx"
 	"vivu/cmd/fx/distance_matrix_fx"
+	"vivu/cmd/fx/embedding_fx"
+	"vivu/cmd/fx/event_bus_fx"
 	"vivu/cmd/fx/feedback_fx"
+	"vivu/cmd/fx/journey_backup_fx"
+	"vivu/cmd/fx/journey_budget_fx"
+	"vivu/cmd/fx/journey_comment_fx"
 	"vivu/cmd/fx/journey_fx"
+	"vivu/cmd/fx/journey_leg_fx"
+	"vivu/cmd/fx/journey_poll_fx"
+	"vivu/cmd/fx/legal_fx"
 	"vivu/cmd/fx/mail_fx"
 	"vivu/cmd/fx/memcache_fx"
+	"vivu/cmd/fx/notification_fx"
 	"vivu/cmd/fx/payment_service_fx"
+	"vivu/cmd/fx/personal_data_fx"
+	"vivu/cmd/fx/plan_fx"
+	"vivu/cmd/fx/plan_quota_fx"
+	"vivu/cmd/fx/planning_policy_fx"
 	"vivu/cmd/fx/poi_embedded_fx"
+	"vivu/cmd/fx/poi_export_fx"
+	"vivu/cmd/fx/poi_import_fx"
+	"vivu/cmd/fx/poi_province_check_fx"
+	"vivu/cmd/fx/poi_quality_fx"
+	"vivu/cmd/fx/poi_rating_fx"
 	"vivu/cmd/fx/pois_fx"
+	"vivu/cmd/fx/practical_info_fx"
 	"vivu/cmd/fx/prompt_fx"
 	"vivu/cmd/fx/province_fx"
+	"vivu/cmd/fx/province_guide_fx"
+	"vivu/cmd/fx/runtime_switch_fx"
+	"vivu/cmd/fx/secrets_fx"
 	"vivu/cmd/fx/tags_fx"
+	"vivu/cmd/fx/tracing_fx"
+	"vivu/cmd/fx/travel_document_fx"
+	"vivu/cmd/fx/travel_preset_fx"
+	"vivu/cmd/fx/trip_reminder_fx"
+	"vivu/cmd/fx/webhook_fx"
 	docs "vivu/docs"
 	"vivu/internal/api/controllers"
 	"vivu/internal/infra"
 	"vivu/internal/models/db_models"
+	"vivu/internal/services"
 
 	"vivu/pkg/middleware"
+	"vivu/pkg/utils"
 )
 
 func init() {
@@ -80,8 +121,19 @@ func loadDotEnv() error {
 // @in header
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token
+
+// @securityDefinitions.apikey PartnerAPIKey
+// @in header
+// @name X-API-Key
+// @description Partner API key issued by an admin, accepted on read-only POI and province routes and plan generation
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+
 	app := fx.New(
+		tracing_fx.Module,
 		fx.Invoke(infra.InitPostgresql),
 		db_fx.Module,
 		pois_fx.Module,
@@ -98,6 +150,42 @@ func main() {
 		payment_service_fx.Module,
 		dashboard.Module,
 		feedback_fx.Module,
+		diagnostics_fx.Module,
+		runtime_switch_fx.Module,
+		account_merge_fx.Module,
+		poi_rating_fx.Module,
+		destination_rule_fx.Module,
+		poi_import_fx.Module,
+		poi_export_fx.Module,
+		embedding_fx.Module,
+		practical_info_fx.Module,
+		trip_reminder_fx.Module,
+		plan_quota_fx.Module,
+		booking_fx.Module,
+		journey_poll_fx.Module,
+		journey_comment_fx.Module,
+		travel_document_fx.Module,
+		journey_budget_fx.Module,
+		travel_preset_fx.Module,
+		poi_quality_fx.Module,
+		api_key_fx.Module,
+		poi_province_check_fx.Module,
+		webhook_fx.Module,
+		notification_fx.Module,
+		event_bus_fx.Module,
+		plan_fx.Module,
+		billing_fx.Module,
+		churn_fx.Module,
+		announcement_fx.Module,
+		legal_fx.Module,
+		secrets_fx.Module,
+		coupon_fx.Module,
+		personal_data_fx.Module,
+		province_guide_fx.Module,
+		collection_fx.Module,
+		journey_leg_fx.Module,
+		journey_backup_fx.Module,
+		planning_policy_fx.Module,
 
 		fx.Invoke(StartServer),
 		fx.Provide(ProvideRouter),
@@ -105,10 +193,6 @@ func main() {
 		fx.Invoke(MigrateDB),
 	)
 
-	//errExcel := services.ExportPOIsToExcel(infra.GetPostgresql(), "exported_pois.xlsx")
-	//if errExcel != nil {
-	//	log.Println("Failed to export POIs to Excel:", errExcel)
-	//}
 	//services.NewOSClient()
 	app.Run()
 }
@@ -142,15 +226,58 @@ func ProvideRouter(
 	journeyController *controllers.JourneyController,
 	paymentController *controllers.PaymentController,
 	dashboardController *controllers.DashboardController,
-	feedbackController *controllers.FeedbackController) *gin.Engine {
+	feedbackController *controllers.FeedbackController,
+	diagnosticsController *controllers.DiagnosticsController,
+	switchController *controllers.RuntimeSwitchController,
+	accountMergeController *controllers.AccountMergeController,
+	poiRatingController *controllers.POIRatingController,
+	destinationRuleController *controllers.DestinationRuleController,
+	poiImportController *controllers.POIImportController,
+	poiExportController *controllers.POIExportController,
+	embeddingController *controllers.EmbeddingController,
+	practicalInfoController *controllers.PracticalInfoController,
+	tripReminderController *controllers.TripReminderController,
+	bookingController *controllers.BookingController,
+	pollController *controllers.JourneyPollController,
+	commentController *controllers.JourneyCommentController,
+	documentController *controllers.TravelDocumentController,
+	budgetController *controllers.JourneyBudgetController,
+	presetController *controllers.TravelPresetController,
+	qualityController *controllers.POIQualityController,
+	adminAccountController *controllers.AdminAccountController,
+	boundaryController *controllers.ProvinceBoundaryController,
+	apiKeyController *controllers.APIKeyController,
+	provinceFlagController *controllers.POIProvinceFlagController,
+	webhookController *controllers.WebhookController,
+	partnerUsageController *controllers.PartnerUsageController,
+	notificationController *controllers.NotificationController,
+	planController *controllers.PlanController,
+	billingController *controllers.BillingController,
+	mailOutboxController *controllers.MailOutboxController,
+	churnController *controllers.ChurnController,
+	announcementController *controllers.AnnouncementController,
+	legalController *controllers.LegalController,
+	secretController *controllers.SecretController,
+	couponController *controllers.CouponController,
+	personalDataController *controllers.PersonalDataController,
+	guideController *controllers.ProvinceGuideController,
+	collectionController *controllers.CollectionController,
+	backupController *controllers.JourneyBackupController,
+	policyController *controllers.PlanningPolicyController,
+	switches services.RuntimeSwitchServiceInterface) *gin.Engine {
 
 	r := gin.Default()
+	if err := r.SetTrustedProxies(middleware.TrustedProxies()); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
 	r.Use(middleware.CORSMiddleware())
+	r.Use(otelgin.Middleware(infra.TracingServiceName))
 	r.Use(middleware.TraceIDMiddleware())
+	r.Use(middleware.MaintenanceMiddleware(switches, services.SwitchMaintenance))
 
-	RegisterRoutes(r, poisController, tagsController, promptController, provinceController, accountController, journeyController, paymentController, dashboardController, feedbackController)
+	RegisterRoutes(r, poisController, tagsController, promptController, provinceController, accountController, journeyController, paymentController, dashboardController, feedbackController, diagnosticsController, switchController, accountMergeController, poiRatingController, destinationRuleController, poiImportController, poiExportController, embeddingController, practicalInfoController, tripReminderController, bookingController, pollController, commentController, documentController, budgetController, presetController, qualityController, adminAccountController, boundaryController, apiKeyController, provinceFlagController, webhookController, partnerUsageController, notificationController, planController, billingController, mailOutboxController, churnController, announcementController, legalController, secretController, couponController, personalDataController, guideController, collectionController, backupController, policyController, switches)
 
 	return r
 }
@@ -194,20 +321,23 @@ func SetupSwagger(router *gin.Engine) {
 	))
 }
 
+// MigrateDB applies pending migrations on startup. Schema changes go in /migrations, not here.
 func MigrateDB() {
-	db := infra.GetPostgresql()
-	infra.MigratePostgresql(db,
-		db_models.POIDetail{},
-		db_models.POI{},
-		db_models.Account{},
-		db_models.Journey{},
-		db_models.JourneyDay{},
-		db_models.JourneyActivity{},
-		db_models.Subscription{},
-		db_models.Transaction{},
-		db_models.Plan{},
-		db_models.Feedback{})
+	if err := infra.MigratePostgresql("up"); err != nil {
+		log.Printf("Error during migration: %v", err)
+		log.Fatal("Error during migration")
+	}
+}
 
+// runMigrate handles `vivu migrate up|down|status` without starting the server.
+func runMigrate(args []string) {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+	if err := infra.MigratePostgresql(command); err != nil {
+		log.Fatalf("migrate %s: %v", command, err)
+	}
 }
 
 func RegisterRoutes(r *gin.Engine,
@@ -219,39 +349,121 @@ func RegisterRoutes(r *gin.Engine,
 	journeyController *controllers.JourneyController,
 	paymentController *controllers.PaymentController,
 	dashboardController *controllers.DashboardController,
-	feedbackController *controllers.FeedbackController) {
+	feedbackController *controllers.FeedbackController,
+	diagnosticsController *controllers.DiagnosticsController,
+	switchController *controllers.RuntimeSwitchController,
+	accountMergeController *controllers.AccountMergeController,
+	poiRatingController *controllers.POIRatingController,
+	destinationRuleController *controllers.DestinationRuleController,
+	poiImportController *controllers.POIImportController,
+	poiExportController *controllers.POIExportController,
+	embeddingController *controllers.EmbeddingController,
+	practicalInfoController *controllers.PracticalInfoController,
+	tripReminderController *controllers.TripReminderController,
+	bookingController *controllers.BookingController,
+	pollController *controllers.JourneyPollController,
+	commentController *controllers.JourneyCommentController,
+	documentController *controllers.TravelDocumentController,
+	budgetController *controllers.JourneyBudgetController,
+	presetController *controllers.TravelPresetController,
+	qualityController *controllers.POIQualityController,
+	adminAccountController *controllers.AdminAccountController,
+	boundaryController *controllers.ProvinceBoundaryController,
+	apiKeyController *controllers.APIKeyController,
+	provinceFlagController *controllers.POIProvinceFlagController,
+	webhookController *controllers.WebhookController,
+	partnerUsageController *controllers.PartnerUsageController,
+	notificationController *controllers.NotificationController,
+	planController *controllers.PlanController,
+	billingController *controllers.BillingController,
+	mailOutboxController *controllers.MailOutboxController,
+	churnController *controllers.ChurnController,
+	announcementController *controllers.AnnouncementController,
+	legalController *controllers.LegalController,
+	secretController *controllers.SecretController,
+	couponController *controllers.CouponController,
+	personalDataController *controllers.PersonalDataController,
+	guideController *controllers.ProvinceGuideController,
+	collectionController *controllers.CollectionController,
+	backupController *controllers.JourneyBackupController,
+	policyController *controllers.PlanningPolicyController,
+	switches services.RuntimeSwitchServiceInterface) {
+
+	// Public, unauthenticated writes: velocity limit per IP + optional CAPTCHA
+	captcha := middleware.CaptchaMiddleware(utils.NewCaptchaVerifierFromEnv())
+	registerLimit := middleware.VelocityLimitMiddleware(middleware.NewIPRateLimiter(5, time.Hour))
+	forgotLimit := middleware.VelocityLimitMiddleware(middleware.NewIPRateLimiter(3, 15*time.Minute))
+	unlockLimit := middleware.VelocityLimitMiddleware(middleware.NewIPRateLimiter(10, 15*time.Minute))
+	feedbackLimit := middleware.VelocityLimitMiddleware(middleware.NewIPRateLimiter(5, 10*time.Minute))
 
 	accountGroup := r.Group("/accounts")
-	accountGroup.POST("/register", accountController.Register)
+	accountGroup.POST("/register", registerLimit, captcha, accountController.Register)
 	accountGroup.POST("/login", accountController.Login)
-	accountGroup.POST("/forgot-password", accountController.ForgotPassword)
+	accountGroup.POST("/2fa/challenge", accountController.TwoFactorChallenge)
+	accountGroup.POST("/unlock", unlockLimit, accountController.UnlockLogin)
+	accountGroup.POST("/2fa/setup", middleware.JWTAuthMiddleware(), accountController.SetupTwoFactor)
+	accountGroup.POST("/2fa/verify", middleware.JWTAuthMiddleware(), accountController.VerifyTwoFactor)
+	accountGroup.POST("/forgot-password", forgotLimit, captcha, accountController.ForgotPassword)
 	accountGroup.POST("/verify-otp", accountController.VerifyOtpToken)
 	accountGroup.POST("/reset-password", accountController.ResetPasswordWithOtp)
 	accountGroup.GET("/all", middleware.JWTAuthMiddleware(), accountController.GetAllAccounts)
 	accountGroup.GET("/profile", middleware.JWTAuthMiddleware(), accountController.GetProfileInfo)
-
+	accountGroup.GET("/me", middleware.JWTAuthMiddleware(), accountController.GetProfileInfo)
+	accountGroup.PUT("/me", middleware.JWTAuthMiddleware(), accountController.UpdateProfile)
+	accountGroup.GET("/me/data-export", middleware.JWTAuthMiddleware(), personalDataController.ExportMyData)
+	accountGroup.GET("/me/journeys/export", middleware.JWTAuthMiddleware(), middleware.KillSwitchMiddleware(switches, services.SwitchExports), backupController.ExportMyJourneys)
+	accountGroup.POST("/change-password", middleware.JWTAuthMiddleware(), accountController.ChangePassword)
+	accountGroup.PUT("/preferences/working-window", middleware.JWTAuthMiddleware(), accountController.UpdateWorkingWindow)
+	accountGroup.PUT("/preferences/companions", middleware.JWTAuthMiddleware(), accountController.UpdateCompanions)
+	accountGroup.PUT("/preferences/avoid", middleware.JWTAuthMiddleware(), accountController.UpdateAvoid)
+	accountGroup.PUT("/preferences/trip-emails", middleware.JWTAuthMiddleware(), accountController.UpdateTripEmails)
+	accountGroup.GET("/presets", middleware.JWTAuthMiddleware(), presetController.ListPresets)
+	accountGroup.POST("/presets", middleware.JWTAuthMiddleware(), presetController.CreatePreset)
+	accountGroup.PUT("/presets/:presetId", middleware.JWTAuthMiddleware(), presetController.UpdatePreset)
+	accountGroup.DELETE("/presets/:presetId", middleware.JWTAuthMiddleware(), presetController.DeletePreset)
+
+	// Partner apps may send an X-API-Key on the read routes (see /admin/api-keys)
+	poisRead := middleware.OptionalAPIKey(db_models.APIScopePOIsRead)
 	poisgroup := r.Group("/pois")
-	poisgroup.GET("/provinces/:provinceId", poisController.GetPoisByProvince)
-	poisgroup.GET("/pois-details/:id", poisController.GetPoiById)
+	poisgroup.GET("/provinces/:provinceId", poisRead, poisController.GetPoisByProvince)
+	poisgroup.GET("/pois-details/:id", poisRead, poisController.GetPoiById)
 	poisgroup.POST("/create-poi", poisController.CreatePoi)
 	poisgroup.DELETE("/delete-poi", poisController.DeletePoi)
 	poisgroup.PUT("/update-poi", poisController.UpdatePoi)
-	poisgroup.GET("/list-pois", poisController.ListPois)
-	poisgroup.GET("/search-poi-by-name-and-province", poisController.SearchPoiByNameAndProvince)
+	poisgroup.GET("/list-pois", poisRead, poisController.ListPois)
+	poisgroup.GET("/search-poi-by-name-and-province", poisRead, poisController.SearchPoiByNameAndProvince)
+	poisgroup.GET("/search", poisRead, poisController.SearchPOIs)
+	poisgroup.GET("/nearby", poisRead, poisController.FindNearbyPOIs)
+	poisgroup.GET("/:id/booking-links", poisRead, bookingController.ListBookingLinks)
+	poisgroup.POST("/import", middleware.JWTAuthMiddleware(), middleware.RoleMiddleware("admin"), poiImportController.ImportPOIs)
+	poisgroup.GET("/export", middleware.JWTAuthMiddleware(), middleware.RoleMiddleware("admin"), poiExportController.ExportPOIs)
+
+	collectionGroup := r.Group("/collections")
+	collectionGroup.GET("", poisRead, collectionController.ListCollections)
+	collectionGroup.GET("/:idOrSlug", poisRead, collectionController.GetCollection)
 
 	tagsGroup := r.Group("/tags")
 	tagsGroup.GET("/list-all", tagsController.ListAllTagsHandler)
-
-	promptGroup := r.Group("/prompt", middleware.JWTAuthMiddleware())
-	promptGroup.POST("/generate-plan", promptController.CreatePromptHandler)
-	promptGroup.POST("/quiz/start", promptController.StartQuizHandler)
-	promptGroup.POST("/quiz/answer", promptController.AnswerQuizHandler)
-	promptGroup.POST("/quiz/plan-only", promptController.PlanOnlyHandler)
-
-	provinceGroup := r.Group("/provinces", middleware.JWTAuthMiddleware())
-	provinceGroup.GET("/list-all", provinceController.GetAllProvinces)
-	provinceGroup.GET("/find-by-name/:province_name", provinceController.FindProvincesByName)
-	provinceGroup.POST("/create", provinceController.CreateProvinceHandler)
+	tagsGroup.POST("/bulk-assign", middleware.JWTAuthMiddleware(), middleware.RoleMiddleware("admin"), tagsController.BulkAssignTagHandler)
+
+	promptGroup := r.Group("/prompt")
+	planSwitch := middleware.KillSwitchMiddleware(switches, services.SwitchPlanGeneration)
+	promptGroup.POST("/generate-plan", middleware.APIKeyAuth(db_models.APIScopePlansGenerate), planSwitch, promptController.CreatePromptHandler)
+	promptGroup.POST("/quiz/start", middleware.JWTAuthMiddleware(), promptController.StartQuizHandler)
+	promptGroup.POST("/quiz/answer", middleware.JWTAuthMiddleware(), promptController.AnswerQuizHandler)
+	promptGroup.POST("/quiz/heartbeat", middleware.JWTAuthMiddleware(), promptController.QuizHeartbeatHandler)
+	promptGroup.POST("/quiz/plan-only", middleware.JWTAuthMiddleware(), planSwitch, promptController.PlanOnlyHandler)
+	promptGroup.GET("/explain/:planId/:activityId", middleware.JWTAuthMiddleware(), promptController.ExplainActivity)
+
+	provincesRead := middleware.APIKeyAuth(db_models.APIScopeProvincesRead)
+	provinceGroup := r.Group("/provinces")
+	provinceGroup.GET("/list-all", provincesRead, provinceController.GetAllProvinces)
+	provinceGroup.GET("/find-by-name/:province_name", provincesRead, provinceController.FindProvincesByName)
+	provinceGroup.POST("/create", middleware.JWTAuthMiddleware(), provinceController.CreateProvinceHandler)
+	provinceGroup.GET("/:provinceId/practical-info", provincesRead, practicalInfoController.GetInfo)
+	provinceGroup.GET("/:provinceId/guide", provincesRead, guideController.GetGuide)
+	provinceGroup.GET("/boundaries", provincesRead, boundaryController.ListBoundaries)
+	provinceGroup.GET("/:provinceId/boundary", provincesRead, boundaryController.GetBoundary)
 
 	journeyGroup := r.Group("/journeys", middleware.JWTAuthMiddleware())
 	journeyGroup.GET("/get-journey-by-userid", journeyController.GetJourneyByUserId)
@@ -260,19 +472,187 @@ func RegisterRoutes(r *gin.Engine,
 	journeyGroup.POST("/remove-poi-from-journey", journeyController.RemovePoiFromJourney)
 	journeyGroup.POST("/add-day-to-journey", journeyController.AddDayToJourney)
 	journeyGroup.POST("/update-journey-window", journeyController.UpdateJourneyWindow)
+	journeyGroup.GET("/:journeyId/export/pdf", middleware.KillSwitchMiddleware(switches, services.SwitchExports), journeyController.ExportJourneyPDF)
+	journeyGroup.GET("/:journeyId/print", journeyController.PrintJourney)
+	journeyGroup.POST("/:journeyId/share", journeyController.ShareJourney)
+	journeyGroup.DELETE("/:journeyId/share", journeyController.UnshareJourney)
+	journeyGroup.GET("/:journeyId/share-text", journeyController.GetShareText)
+	journeyGroup.POST("/:journeyId/optimize-day", journeyController.OptimizeDay)
+	journeyGroup.PUT("/:journeyId/accommodation", journeyController.SetDayAccommodation)
+	journeyGroup.POST("/:journeyId/rainy-swap", journeyController.SwapRainyDay)
+	journeyGroup.POST("/:journeyId/seed-day", collectionController.SeedDay)
+	journeyGroup.POST("/:journeyId/regenerate-day", planSwitch, promptController.RegenerateDayHandler)
+	journeyGroup.POST("/:journeyId/check-ins", journeyController.CheckInActivity)
+	journeyGroup.GET("/:journeyId/today", journeyController.GetToday)
+	journeyGroup.GET("/:journeyId/reminders", tripReminderController.GetReminders)
+	journeyGroup.PUT("/:journeyId/reminders", tripReminderController.SetReminders)
+	journeyGroup.POST("/:journeyId/activities/:activityId/book", bookingController.BookActivity)
+	journeyGroup.PUT("/:journeyId/activities/:activityId/booking", bookingController.UpdateBookingStatus)
+	journeyGroup.GET("/:journeyId/members", pollController.ListMembers)
+	journeyGroup.POST("/:journeyId/members", pollController.AddMember)
+	journeyGroup.DELETE("/:journeyId/members/:accountId", pollController.RemoveMember)
+	journeyGroup.GET("/:journeyId/polls", pollController.ListPolls)
+	journeyGroup.POST("/:journeyId/polls", pollController.CreatePoll)
+	journeyGroup.POST("/:journeyId/polls/:pollId/vote", pollController.Vote)
+	journeyGroup.GET("/:journeyId/comments", commentController.ListComments)
+	journeyGroup.POST("/:journeyId/comments", commentController.CreateComment)
+	journeyGroup.DELETE("/:journeyId/comments/:commentId", commentController.DeleteComment)
+	journeyGroup.GET("/:journeyId/documents", documentController.ListDocuments)
+	journeyGroup.POST("/:journeyId/documents", documentController.UploadDocument)
+	journeyGroup.GET("/:journeyId/documents/inbox", documentController.GetInbox)
+	journeyGroup.GET("/:journeyId/documents/:documentId/download", documentController.DownloadDocument)
+	journeyGroup.DELETE("/:journeyId/documents/:documentId", documentController.DeleteDocument)
+	journeyGroup.GET("/:journeyId/budget", budgetController.GetBudget)
+	journeyGroup.PUT("/:journeyId/budget", budgetController.SetBudget)
+	journeyGroup.DELETE("/:journeyId/budget", budgetController.DeleteBudget)
+	journeyGroup.GET("/:journeyId/expenses", budgetController.ListExpenses)
+	journeyGroup.POST("/:journeyId/expenses", budgetController.LogExpense)
+	journeyGroup.POST("/:journeyId/expenses/scan", budgetController.ScanReceipt)
+	journeyGroup.DELETE("/:journeyId/expenses/:expenseId", budgetController.DeleteExpense)
+	journeyGroup.POST("/import", middleware.KillSwitchMiddleware(switches, services.SwitchImports), journeyController.ImportJourney)
+
+	// Print view behind a journey's share link, no login
+	r.GET("/shared/journeys/:token/print", journeyController.PrintSharedJourney)
+
+	// Booking emails forwarded to a journey inbox, posted by the inbound mail provider
+	r.POST("/inbound/mail", documentController.ReceiveEmail)
 
 	paymentGroup := r.Group("/payments")
 	paymentGroup.POST("/create-checkout", middleware.JWTAuthMiddleware(), paymentController.CreateCheckoutRequest)
 	paymentGroup.POST("/webhook", paymentController.HandleWebhook)
+	paymentGroup.POST("/webhook/:provider", paymentController.HandleProviderWebhook)
 	paymentGroup.GET("/plans", paymentController.GetListOfAvailablePlans)
-	paymentGroup.GET("/transaction-history", middleware.JWTAuthMiddleware(), paymentController.GetAllTransactionHistory)
+	paymentGroup.GET("/transaction-history", middleware.JWTAuthMiddleware(), middleware.RoleMiddleware("admin"), paymentController.GetAllTransactionHistory)
+	paymentGroup.GET("/my-transactions", middleware.JWTAuthMiddleware(), billingController.ListTransactions)
+	paymentGroup.GET("/transactions/:id/receipt", middleware.JWTAuthMiddleware(), billingController.GetReceipt)
 	paymentGroup.GET("/subscription-details", middleware.JWTAuthMiddleware(), paymentController.GetSubscriptionDetails)
+	paymentGroup.POST("/pause-subscription", middleware.JWTAuthMiddleware(), paymentController.PauseSubscription)
+	paymentGroup.POST("/resume-subscription", middleware.JWTAuthMiddleware(), paymentController.ResumeSubscription)
+	paymentGroup.POST("/subscription/cancel", middleware.JWTAuthMiddleware(), paymentController.CancelSubscription)
+	paymentGroup.POST("/subscription/auto-renew", middleware.JWTAuthMiddleware(), paymentController.SetAutoRenew)
+	paymentGroup.POST("/change-plan", middleware.JWTAuthMiddleware(), paymentController.ChangePlan)
+	paymentGroup.GET("/coupons/:code", middleware.JWTAuthMiddleware(), couponController.ValidateCoupon)
+
+	// One group for the billing screen of the app
+	billingGroup := r.Group("/billing", middleware.JWTAuthMiddleware())
+	billingGroup.GET("", billingController.GetOverview)
+	billingGroup.GET("/payments", billingController.ListPayments)
+	billingGroup.GET("/invoices", billingController.ListInvoices)
+	billingGroup.GET("/invoices/:id", billingController.GetInvoice)
+	billingGroup.POST("/cancel", billingController.CancelSubscription)
+	billingGroup.PUT("/auto-renew", billingController.SetAutoRenew)
+	billingGroup.POST("/change-plan", billingController.ChangePlan)
+	billingGroup.POST("/pause", paymentController.PauseSubscription)
+	billingGroup.POST("/resume", paymentController.ResumeSubscription)
 
 	dashboardGroup := r.Group("/dashboard", middleware.JWTAuthMiddleware())
 	dashboardGroup.GET("/stats", dashboardController.GetDashboard)
+	dashboardGroup.GET("/cohorts", middleware.RoleMiddleware("admin"), dashboardController.GetCohorts)
+
+	partnerGroup := r.Group("/partner")
+	partnerGroup.GET("/usage", middleware.APIKeyAuth(""), partnerUsageController.GetUsage)
+	partnerGroup.GET("/planning-policy", middleware.APIKeyAuth(""), policyController.GetPolicy)
+	partnerGroup.PUT("/planning-policy", middleware.APIKeyAuth(db_models.APIScopePlanningPolicy), policyController.SetPolicy)
+	partnerGroup.DELETE("/planning-policy", middleware.APIKeyAuth(db_models.APIScopePlanningPolicy), policyController.DeletePolicy)
+
+	notificationGroup := r.Group("/notifications", middleware.JWTAuthMiddleware())
+	notificationGroup.GET("", notificationController.ListNotifications)
+	notificationGroup.POST("/:id/read", notificationController.MarkRead)
+
+	announcementGroup := r.Group("/announcements")
+	announcementGroup.GET("/active", middleware.OptionalJWTAuthMiddleware(), announcementController.GetActive)
+
+	legalGroup := r.Group("/legal")
+	legalGroup.GET("/documents", legalController.GetDocuments)
+	legalGroup.GET("/status", middleware.JWTAuthMiddleware(), legalController.GetStatus)
+	legalGroup.POST("/accept", middleware.JWTAuthMiddleware(), legalController.Accept)
+
+	webhookGroup := r.Group("/webhooks", middleware.JWTAuthMiddleware())
+	webhookGroup.GET("", webhookController.ListWebhooks)
+	webhookGroup.POST("", webhookController.CreateWebhook)
+	webhookGroup.PUT("/:id", webhookController.UpdateWebhook)
+	webhookGroup.DELETE("/:id", webhookController.DeleteWebhook)
+	webhookGroup.GET("/:id/deliveries", webhookController.ListDeliveries)
 
 	feedbackGroup := r.Group("/feedback")
-	feedbackGroup.POST("/add", feedbackController.AddFeedback)
+	feedbackGroup.POST("/add", feedbackLimit, captcha, feedbackController.AddFeedback)
 	feedbackGroup.GET("/list", feedbackController.ListFeedback)
 
+	adminGroup := r.Group("/admin", middleware.JWTAuthMiddleware(), middleware.RoleMiddleware("admin"))
+	adminGroup.GET("/diagnostics/slow-queries", diagnosticsController.ListSlowQueries)
+	adminGroup.DELETE("/diagnostics/slow-queries/:id", diagnosticsController.DeleteSlowQuery)
+	adminGroup.GET("/diagnostics/db-pool", diagnosticsController.GetPoolStats)
+	adminGroup.GET("/diagnostics/ai-providers", diagnosticsController.GetAIProviderStats)
+	adminGroup.GET("/switches", switchController.ListSwitches)
+	adminGroup.PUT("/switches/:key", switchController.SetSwitch)
+	adminGroup.GET("/accounts", adminAccountController.ListAccounts)
+	adminGroup.POST("/accounts/merge", accountMergeController.MergeAccounts)
+	adminGroup.POST("/accounts/:id/ban", adminAccountController.BanAccount)
+	adminGroup.POST("/accounts/:id/unban", adminAccountController.UnbanAccount)
+	adminGroup.POST("/accounts/:id/logout", adminAccountController.ForceLogout)
+	adminGroup.GET("/api-keys", apiKeyController.ListKeys)
+	adminGroup.POST("/api-keys", apiKeyController.IssueKey)
+	adminGroup.POST("/api-keys/:id/rotate", apiKeyController.RotateKey)
+	adminGroup.DELETE("/api-keys/:id", apiKeyController.RevokeKey)
+	adminGroup.GET("/webhooks/deliveries", webhookController.ListAllDeliveries)
+	adminGroup.GET("/mail-outbox", mailOutboxController.ListMessages)
+	adminGroup.POST("/mail-outbox/retry-dead", mailOutboxController.RetryDead)
+	adminGroup.POST("/mail-outbox/:id/retry", mailOutboxController.RetryMessage)
+	adminGroup.GET("/churn", churnController.GetOverview)
+	adminGroup.GET("/churn/accounts", churnController.ListAccounts)
+	adminGroup.POST("/churn/score", churnController.ScoreNow)
+	adminGroup.GET("/churn/campaigns", churnController.ListCampaigns)
+	adminGroup.PUT("/churn/campaigns/:segment", churnController.SetCampaign)
+	adminGroup.GET("/announcements", announcementController.ListAnnouncements)
+	adminGroup.POST("/announcements", announcementController.CreateAnnouncement)
+	adminGroup.GET("/announcements/:id", announcementController.GetAnnouncement)
+	adminGroup.PUT("/announcements/:id", announcementController.UpdateAnnouncement)
+	adminGroup.DELETE("/announcements/:id", announcementController.DeleteAnnouncement)
+	adminGroup.GET("/legal/documents", legalController.ListVersions)
+	adminGroup.POST("/legal/documents", legalController.Publish)
+	adminGroup.GET("/secrets", secretController.GetSecrets)
+	adminGroup.POST("/secrets/refresh", secretController.RefreshSecrets)
+	adminGroup.GET("/coupons", couponController.ListCoupons)
+	adminGroup.POST("/coupons", couponController.CreateCoupon)
+	adminGroup.POST("/payments/:transactionId/refund", paymentController.RefundTransaction)
+	adminGroup.GET("/privacy/inventory", personalDataController.GetInventory)
+	adminGroup.GET("/accounts/:id/data-export", personalDataController.ExportAccountData)
+	adminGroup.POST("/accounts/:id/erase", personalDataController.EraseAccount)
+	adminGroup.GET("/plans", planController.ListPlans)
+	adminGroup.POST("/plans", planController.CreatePlan)
+	adminGroup.PUT("/plans/:id", planController.UpdatePlan)
+	adminGroup.POST("/plans/:id/versions", planController.CreatePlanVersion)
+	adminGroup.DELETE("/plans/:id", planController.DeletePlan)
+	adminGroup.POST("/plans/:id/activate", planController.ActivatePlan)
+	adminGroup.POST("/plans/:id/deactivate", planController.DeactivatePlan)
+	adminGroup.GET("/plans/:id/audit", planController.ListPlanAudit)
+	adminGroup.GET("/pois/quality", qualityController.ListScores)
+	adminGroup.GET("/pois/quality/report", qualityController.GetReport)
+	adminGroup.GET("/pois/quality/export", qualityController.ExportNeedsAttention)
+	adminGroup.GET("/pois/province-flags", provinceFlagController.ListFlags)
+	adminGroup.POST("/pois/province-flags/scan", provinceFlagController.RunCheck)
+	adminGroup.POST("/pois/province-flags/:id/apply", provinceFlagController.ApplyFlag)
+	adminGroup.POST("/pois/province-flags/:id/dismiss", provinceFlagController.DismissFlag)
+	adminGroup.PUT("/pois/:id/external-refs", poiRatingController.SetExternalRef)
+	adminGroup.POST("/pois/:id/external-refs/refresh", poiRatingController.RefreshRatings)
+	adminGroup.PUT("/pois/:id/booking-links", bookingController.SetBookingLink)
+	adminGroup.DELETE("/pois/:id/booking-links/:partner", bookingController.DeleteBookingLink)
+	adminGroup.GET("/destination-rules", destinationRuleController.ListRules)
+	adminGroup.PUT("/destination-rules/:provinceId", destinationRuleController.SetRule)
+	adminGroup.DELETE("/destination-rules/:provinceId", destinationRuleController.DeleteRule)
+	adminGroup.PUT("/provinces/:provinceId/boundary", boundaryController.SetBoundary)
+	adminGroup.DELETE("/provinces/:provinceId/boundary", boundaryController.DeleteBoundary)
+	adminGroup.GET("/practical-info", practicalInfoController.ListInfo)
+	adminGroup.PUT("/practical-info/:provinceId", practicalInfoController.SetInfo)
+	adminGroup.DELETE("/practical-info/:provinceId", practicalInfoController.DeleteInfo)
+	adminGroup.GET("/province-guides", guideController.ListGuides)
+	adminGroup.PUT("/province-guides/:provinceId", guideController.SetGuide)
+	adminGroup.DELETE("/province-guides/:provinceId", guideController.DeleteGuide)
+	adminGroup.GET("/collections", collectionController.AdminListCollections)
+	adminGroup.POST("/collections", collectionController.CreateCollection)
+	adminGroup.GET("/collections/:id", collectionController.AdminGetCollection)
+	adminGroup.PUT("/collections/:id", collectionController.UpdateCollection)
+	adminGroup.DELETE("/collections/:id", collectionController.DeleteCollection)
+	adminGroup.POST("/embeddings/reindex", embeddingController.Reindex)
+
 }
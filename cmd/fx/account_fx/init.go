@@ -6,15 +6,27 @@ import (
 	"vivu/internal/repositories"
 	"vivu/internal/services"
 	mem "vivu/pkg/memcache"
+	"vivu/pkg/middleware"
 )
 
-var Module = fx.Provide(
-	provideAccountService, provideAccountRepo)
+var Module = fx.Options(
+	fx.Provide(provideAccountService, provideAccountRepo, provideAccountSessionGuard),
+	fx.Invoke(installAccountGuard),
+)
 
 func provideAccountRepo(db *gorm.DB) repositories.AccountRepository {
 	return repositories.NewAccountRepository(db)
 }
 
-func provideAccountService(accountRepo repositories.AccountRepository, mailService services.IMailService, memcache mem.ResetTokenStore) services.AccountServiceInterface {
-	return services.NewAccountService(accountRepo, mailService, memcache)
+func provideAccountSessionGuard(accountRepo repositories.AccountRepository) *services.AccountSessionGuard {
+	return services.NewAccountSessionGuard(accountRepo)
+}
+
+func provideAccountService(accountRepo repositories.AccountRepository, mailService services.IMailService, memcache mem.ResetTokenStore, attempts mem.LoginAttemptStore, sessions *services.AccountSessionGuard) services.AccountServiceInterface {
+	return services.NewAccountService(accountRepo, mailService, memcache, attempts, sessions)
+}
+
+// installAccountGuard makes JWTAuthMiddleware refuse tokens of banned or logged out accounts.
+func installAccountGuard(sessions *services.AccountSessionGuard) {
+	middleware.UseAccountGuard(sessions)
 }
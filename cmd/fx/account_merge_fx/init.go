@@ -0,0 +1,25 @@
+package account_merge_fx
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Provide(
+	provideAccountMergeRepo, provideAccountMergeService, provideAccountMergeController,
+)
+
+func provideAccountMergeRepo(db *gorm.DB) repositories.AccountMergeRepositoryInterface {
+	return repositories.NewAccountMergeRepository(db)
+}
+
+func provideAccountMergeService(accountRepo repositories.AccountRepository, mergeRepo repositories.AccountMergeRepositoryInterface) services.AccountMergeServiceInterface {
+	return services.NewAccountMergeService(accountRepo, mergeRepo)
+}
+
+func provideAccountMergeController(mergeService services.AccountMergeServiceInterface) *controllers.AccountMergeController {
+	return controllers.NewAccountMergeController(mergeService)
+}
@@ -0,0 +1,23 @@
+package announcement_fx
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Provide(provideAnnouncementRepo, provideAnnouncementService, provideAnnouncementController)
+
+func provideAnnouncementRepo(db *gorm.DB) repositories.AnnouncementRepositoryInterface {
+	return repositories.NewAnnouncementRepository(db)
+}
+
+func provideAnnouncementService(repo repositories.AnnouncementRepositoryInterface) services.AnnouncementServiceInterface {
+	return services.NewAnnouncementService(repo)
+}
+
+func provideAnnouncementController(announcementService services.AnnouncementServiceInterface) *controllers.AnnouncementController {
+	return controllers.NewAnnouncementController(announcementService)
+}
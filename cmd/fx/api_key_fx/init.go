@@ -0,0 +1,68 @@
+package api_key_fx
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+	"vivu/pkg/middleware"
+)
+
+var Module = fx.Options(
+	fx.Provide(provideAPIKeyRepo, provideAPIKeyService, provideAPIKeyController,
+		provideAPIKeyUsageRepo, provideAPIUsageService, providePartnerUsageController),
+	fx.Invoke(installAPIKeyVerifier, installAPIUsageMeter),
+)
+
+func provideAPIKeyRepo(db *gorm.DB) repositories.APIKeyRepositoryInterface {
+	return repositories.NewAPIKeyRepository(db)
+}
+
+func provideAPIKeyService(repo repositories.APIKeyRepositoryInterface, accountRepo repositories.AccountRepository) services.APIKeyServiceInterface {
+	return services.NewAPIKeyService(repo, accountRepo)
+}
+
+func provideAPIKeyUsageRepo(db *gorm.DB) repositories.APIKeyUsageRepositoryInterface {
+	return repositories.NewAPIKeyUsageRepository(db)
+}
+
+func provideAPIUsageService(repo repositories.APIKeyUsageRepositoryInterface, keyRepo repositories.APIKeyRepositoryInterface) services.APIUsageServiceInterface {
+	return services.NewAPIUsageService(repo, keyRepo)
+}
+
+func providePartnerUsageController(usageService services.APIUsageServiceInterface) *controllers.PartnerUsageController {
+	return controllers.NewPartnerUsageController(usageService)
+}
+
+func provideAPIKeyController(apiKeyService services.APIKeyServiceInterface) *controllers.APIKeyController {
+	return controllers.NewAPIKeyController(apiKeyService)
+}
+
+// installAPIKeyVerifier lets partner apps call the routes using middleware.APIKeyAuth.
+func installAPIKeyVerifier(apiKeyService services.APIKeyServiceInterface) {
+	middleware.UseAPIKeyVerifier(func(ctx context.Context, key, scope string) (middleware.APIKeyPrincipal, error) {
+		k, err := apiKeyService.Verify(ctx, key, scope)
+		if err != nil {
+			return middleware.APIKeyPrincipal{}, err
+		}
+		return middleware.APIKeyPrincipal{KeyID: k.ID.String(), AccountID: k.AccountID.String(), Role: k.Account.Role, DailyQuota: k.DailyQuota}, nil
+	})
+}
+
+// installAPIUsageMeter enforces the daily quotas of the keys and keeps their usage counts.
+func installAPIUsageMeter(lc fx.Lifecycle, usageService services.APIUsageServiceInterface) {
+	middleware.UseAPIKeyMeter(usageService)
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			usageService.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			usageService.Stop()
+			return nil
+		},
+	})
+}
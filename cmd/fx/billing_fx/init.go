@@ -0,0 +1,28 @@
+package billing_fx
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Provide(provideBillingRepo, provideBillingService, provideBillingController, provideReceiptMailer)
+
+func provideBillingRepo(db *gorm.DB) repositories.BillingRepositoryInterface {
+	return repositories.NewBillingRepository(db)
+}
+
+func provideBillingService(repo repositories.BillingRepositoryInterface, accountRepo repositories.AccountRepository, payments services.PaymentService) services.BillingServiceInterface {
+	return services.NewBillingService(repo, accountRepo, payments)
+}
+
+// provideReceiptMailer emails receipts of paid payments when RECEIPT_EMAILS is true.
+func provideReceiptMailer(repo repositories.BillingRepositoryInterface, accountRepo repositories.AccountRepository, mailService services.IMailService) services.ReceiptMailer {
+	return services.NewReceiptMailer(repo, accountRepo, mailService)
+}
+
+func provideBillingController(billingService services.BillingServiceInterface) *controllers.BillingController {
+	return controllers.NewBillingController(billingService)
+}
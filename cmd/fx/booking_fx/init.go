@@ -0,0 +1,25 @@
+package booking_fx
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Provide(
+	provideBookingRepo, provideBookingService, provideBookingController,
+)
+
+func provideBookingRepo(db *gorm.DB) repositories.BookingRepositoryInterface {
+	return repositories.NewBookingRepository(db)
+}
+
+func provideBookingService(repo repositories.BookingRepositoryInterface, poiRepo repositories.POIRepository, journeyRepo repositories.JourneyRepository) services.BookingServiceInterface {
+	return services.NewBookingService(repo, poiRepo, journeyRepo)
+}
+
+func provideBookingController(bookingService services.BookingServiceInterface) *controllers.BookingController {
+	return controllers.NewBookingController(bookingService)
+}
@@ -0,0 +1,41 @@
+package churn_fx
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Options(
+	fx.Provide(provideChurnRepo, provideChurnService, provideChurnController),
+	fx.Invoke(startChurnWorker),
+)
+
+func provideChurnRepo(db *gorm.DB) repositories.ChurnRepositoryInterface {
+	return repositories.NewChurnRepository(db)
+}
+
+func provideChurnService(repo repositories.ChurnRepositoryInterface, mail services.IMailService) services.ChurnServiceInterface {
+	return services.NewChurnService(repo, mail)
+}
+
+func provideChurnController(churnService services.ChurnServiceInterface) *controllers.ChurnController {
+	return controllers.NewChurnController(churnService)
+}
+
+func startChurnWorker(lc fx.Lifecycle, churnService services.ChurnServiceInterface) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			churnService.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			churnService.Stop()
+			return nil
+		},
+	})
+}
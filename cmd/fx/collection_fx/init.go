@@ -0,0 +1,32 @@
+package collection_fx
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Provide(
+	provideCollectionRepo, provideCollectionService, provideCollectionController,
+)
+
+func provideCollectionRepo(db *gorm.DB) repositories.CollectionRepositoryInterface {
+	return repositories.NewCollectionRepository(db)
+}
+
+func provideCollectionService(
+	repo repositories.CollectionRepositoryInterface,
+	poiRepo repositories.POIRepository,
+	journeyRepo repositories.JourneyRepository,
+	journeys services.JourneyServiceInterface,
+	matrix services.DistanceMatrixService,
+	events services.EventBus,
+) services.CollectionServiceInterface {
+	return services.NewCollectionService(repo, poiRepo, journeyRepo, journeys, matrix, events)
+}
+
+func provideCollectionController(collectionService services.CollectionServiceInterface) *controllers.CollectionController {
+	return controllers.NewCollectionController(collectionService)
+}
@@ -11,4 +11,5 @@ var Module = fx.Options(
 	fx.Provide(controllers.NewPromptController),
 	fx.Provide(controllers.NewProvincesController),
 	fx.Provide(controllers.NewAccountController),
+	fx.Provide(controllers.NewAdminAccountController),
 	fx.Provide(controllers.NewJourneyController))
@@ -0,0 +1,23 @@
+package coupon_fx
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Provide(provideCouponRepo, provideCouponService, provideCouponController)
+
+func provideCouponRepo(db *gorm.DB) repositories.CouponRepositoryInterface {
+	return repositories.NewCouponRepository(db)
+}
+
+func provideCouponService(repo repositories.CouponRepositoryInterface, planRepo repositories.IPlanRepository) services.CouponServiceInterface {
+	return services.NewCouponService(repo, planRepo)
+}
+
+func provideCouponController(couponService services.CouponServiceInterface) *controllers.CouponController {
+	return controllers.NewCouponController(couponService)
+}
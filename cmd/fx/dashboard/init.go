@@ -1,6 +1,9 @@
 package dashboard
 
 import (
+	"os"
+	"time"
+
 	"go.uber.org/fx"
 	"gorm.io/gorm"
 	"vivu/internal/api/controllers"
@@ -9,15 +12,24 @@ import (
 )
 
 var Module = fx.Provide(
-	provideDashboardRepo, provideDashboardService, provideDashboardController,
+	provideDashboardRepo, provideDashboardCache, provideDashboardService, provideDashboardController,
 )
 
 func provideDashboardRepo(db *gorm.DB) repositories.DashboardRepository {
 	return repositories.NewDashboardRepository(db)
 }
 
-func provideDashboardService(dashboardRepo repositories.DashboardRepository) services.DashboardService {
-	return services.NewDashboardService(dashboardRepo)
+// provideDashboardCache reads DASHBOARD_CACHE_TTL (default 1m, 0 turns caching off).
+func provideDashboardCache(dashboardRepo repositories.DashboardRepository) *services.DashboardCache {
+	ttl := time.Minute
+	if d, err := time.ParseDuration(os.Getenv("DASHBOARD_CACHE_TTL")); err == nil && d >= 0 {
+		ttl = d
+	}
+	return services.NewDashboardCache(services.NewDashboardService(dashboardRepo), ttl)
+}
+
+func provideDashboardService(cache *services.DashboardCache) services.DashboardService {
+	return cache
 }
 
 func provideDashboardController(dashboardService services.DashboardService) *controllers.DashboardController {
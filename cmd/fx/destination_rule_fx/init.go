@@ -0,0 +1,25 @@
+package destination_rule_fx
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Provide(
+	provideDestinationRuleRepo, provideDestinationRuleService, provideDestinationRuleController,
+)
+
+func provideDestinationRuleRepo(db *gorm.DB) repositories.DestinationRuleRepositoryInterface {
+	return repositories.NewDestinationRuleRepository(db)
+}
+
+func provideDestinationRuleService(repo repositories.DestinationRuleRepositoryInterface) services.DestinationRuleServiceInterface {
+	return services.NewDestinationRuleService(repo)
+}
+
+func provideDestinationRuleController(rulesService services.DestinationRuleServiceInterface) *controllers.DestinationRuleController {
+	return controllers.NewDestinationRuleController(rulesService)
+}
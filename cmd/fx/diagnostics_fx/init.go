@@ -0,0 +1,26 @@
+package diagnostics_fx
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+var Module = fx.Provide(
+	provideDiagnosticsRepo, provideDiagnosticsService, provideDiagnosticsController,
+)
+
+func provideDiagnosticsRepo(db *gorm.DB) repositories.DiagnosticsRepositoryInterface {
+	return repositories.NewDiagnosticsRepository(db)
+}
+
+func provideDiagnosticsService(diagnosticsRepo repositories.DiagnosticsRepositoryInterface, aiClient utils.EmbeddingClientInterface) services.DiagnosticsServiceInterface {
+	return services.NewDiagnosticsService(diagnosticsRepo, aiClient)
+}
+
+func provideDiagnosticsController(diagnosticsService services.DiagnosticsServiceInterface) *controllers.DiagnosticsController {
+	return controllers.NewDiagnosticsController(diagnosticsService)
+}
@@ -2,11 +2,73 @@ package distance_matrix_fx
 
 import (
 	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"log"
+	"os"
+	"strings"
+	"time"
+	"vivu/internal/repositories"
 	"vivu/internal/services"
+	"vivu/pkg/secrets"
 )
 
-var Module = fx.Provide(provideMatrixRepo)
+var Module = fx.Provide(provideMatrixCache, provideMatrixRepo, provideRouteOptimizer)
 
-func provideMatrixRepo() services.DistanceMatrixService {
-	return services.NewMapboxMatrixClient(services.NewInMemoryPairCache())
+// provideMatrixCache picks the pair cache from MATRIX_CACHE_BACKEND:
+// "postgres" (default) = memory L1 in front of poi_distance_cache, "memory" = memory only.
+func provideMatrixCache(db *gorm.DB) services.MatrixPairCache {
+	backend := strings.ToLower(os.Getenv("MATRIX_CACHE_BACKEND"))
+	switch backend {
+	case "memory":
+		return services.NewInMemoryPairCache()
+	case "", "postgres":
+		l2 := services.NewPostgresPairCache(repositories.NewDistanceCacheRepository(db))
+		return services.NewTieredPairCache(services.NewInMemoryPairCache(), l2, 24*time.Hour)
+	default:
+		log.Printf("Unknown MATRIX_CACHE_BACKEND %q, falling back to memory", backend)
+		return services.NewInMemoryPairCache()
+	}
+}
+
+// provideMatrixRepo picks the distance provider from MATRIX_PROVIDER:
+// "mapbox", "osrm" or "failover" (Mapbox first, OSRM on quota/5xx errors).
+// When unset it is inferred from which of MAPBOX_ACCESS_TOKEN / OSRM_BASE_URL are present.
+func provideMatrixRepo(cache services.MatrixPairCache, keys secrets.Getter) services.DistanceMatrixService {
+	provider := strings.ToLower(os.Getenv("MATRIX_PROVIDER"))
+	if provider == "" {
+		hasMapbox := keys.Get("MAPBOX_ACCESS_TOKEN") != ""
+		hasOSRM := os.Getenv("OSRM_BASE_URL") != ""
+		switch {
+		case hasMapbox && hasOSRM:
+			provider = "failover"
+		case hasOSRM:
+			provider = "osrm"
+		default:
+			provider = "mapbox"
+		}
+	}
+
+	switch provider {
+	case "osrm":
+		return services.NewOSRMMatrixClient(cache)
+	case "failover":
+		cooldown := 5 * time.Minute
+		if d, err := time.ParseDuration(os.Getenv("MATRIX_FAILOVER_COOLDOWN")); err == nil && d > 0 {
+			cooldown = d
+		}
+		return services.NewFailoverMatrixService(
+			services.NewMapboxMatrixClient(cache, keys),
+			services.NewOSRMMatrixClient(cache),
+			cooldown,
+		)
+	case "mapbox":
+		return services.NewMapboxMatrixClient(cache, keys)
+	default:
+		log.Printf("Unknown MATRIX_PROVIDER %q, using mapbox", provider)
+		return services.NewMapboxMatrixClient(cache, keys)
+	}
+}
+
+func provideRouteOptimizer(matrix services.DistanceMatrixService) *services.RouteOptimizer {
+	return services.NewRouteOptimizer(matrix)
 }
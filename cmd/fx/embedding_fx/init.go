@@ -0,0 +1,42 @@
+package embedding_fx
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+var Module = fx.Options(
+	fx.Provide(provideEmbeddingOutboxRepo, provideEmbeddingIndexService, provideEmbeddingController),
+	fx.Invoke(startEmbeddingWorker),
+)
+
+func provideEmbeddingOutboxRepo(db *gorm.DB) repositories.EmbeddingOutboxRepositoryInterface {
+	return repositories.NewEmbeddingOutboxRepository(db)
+}
+
+func provideEmbeddingIndexService(repo repositories.EmbeddingOutboxRepositoryInterface, embedder utils.EmbeddingClientInterface) services.EmbeddingIndexServiceInterface {
+	return services.NewEmbeddingIndexService(repo, embedder)
+}
+
+func provideEmbeddingController(indexService services.EmbeddingIndexServiceInterface) *controllers.EmbeddingController {
+	return controllers.NewEmbeddingController(indexService)
+}
+
+func startEmbeddingWorker(lc fx.Lifecycle, indexService services.EmbeddingIndexServiceInterface) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			indexService.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			indexService.Stop()
+			return nil
+		},
+	})
+}
@@ -0,0 +1,14 @@
+package event_bus_fx
+
+import (
+	"go.uber.org/fx"
+	"vivu/internal/services"
+)
+
+var Module = fx.Provide(provideEventBus)
+
+// provideEventBus hands the publishers every listener: webhooks, in-app notifications and the
+// dashboard cache, which is busted by payments.
+func provideEventBus(webhooks services.WebhookServiceInterface, notifications services.NotificationServiceInterface, dashboard *services.DashboardCache) services.EventBus {
+	return services.NewEventBus(webhooks, notifications, dashboard)
+}
@@ -0,0 +1,46 @@
+package journey_backup_fx
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Options(
+	fx.Provide(provideJourneyBackupRepo, provideJourneyBackupService, provideJourneyBackupController),
+	fx.Invoke(startJourneyBackupWorker),
+)
+
+func provideJourneyBackupRepo(db *gorm.DB) repositories.JourneyBackupRepositoryInterface {
+	return repositories.NewJourneyBackupRepository(db)
+}
+
+func provideJourneyBackupService(
+	repo repositories.JourneyBackupRepositoryInterface,
+	accountRepo repositories.AccountRepository,
+	storage services.FileStorage,
+	mail services.IMailService,
+) services.JourneyBackupServiceInterface {
+	return services.NewJourneyBackupService(repo, accountRepo, storage, mail)
+}
+
+func provideJourneyBackupController(backupService services.JourneyBackupServiceInterface) *controllers.JourneyBackupController {
+	return controllers.NewJourneyBackupController(backupService)
+}
+
+func startJourneyBackupWorker(lc fx.Lifecycle, backupService services.JourneyBackupServiceInterface) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			backupService.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			backupService.Stop()
+			return nil
+		},
+	})
+}
@@ -0,0 +1,46 @@
+package journey_budget_fx
+
+import (
+	"log"
+	"os"
+
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+	"vivu/pkg/secrets"
+	"vivu/pkg/utils"
+)
+
+var Module = fx.Provide(
+	provideJourneyBudgetRepo, provideReceiptScanner, provideJourneyBudgetService, provideJourneyBudgetController,
+)
+
+func provideJourneyBudgetRepo(db *gorm.DB) repositories.JourneyBudgetRepositoryInterface {
+	return repositories.NewJourneyBudgetRepository(db)
+}
+
+// provideReceiptScanner uses GEMINI_API_KEY and GEMINI_VISION_MODEL (default gemini-2.5-flash);
+// without a key receipt scanning is off and the rest of the budget feature works as usual.
+func provideReceiptScanner(keys secrets.Getter) utils.ReceiptScanner {
+	if keys.Get("GEMINI_API_KEY") == "" {
+		log.Printf("GEMINI_API_KEY is not set, receipt scanning is disabled")
+		return nil
+	}
+	apiKey := func() string { return keys.Get("GEMINI_API_KEY") }
+	scanner, err := utils.NewGeminiReceiptScanner(apiKey, os.Getenv("GEMINI_VISION_MODEL"))
+	if err != nil {
+		log.Printf("receipt scanning is disabled: %v", err)
+		return nil
+	}
+	return scanner
+}
+
+func provideJourneyBudgetService(repo repositories.JourneyBudgetRepositoryInterface, pollRepo repositories.JourneyPollRepositoryInterface, mail services.IMailService, scanner utils.ReceiptScanner) services.JourneyBudgetServiceInterface {
+	return services.NewJourneyBudgetService(repo, pollRepo, mail, scanner)
+}
+
+func provideJourneyBudgetController(budgetService services.JourneyBudgetServiceInterface) *controllers.JourneyBudgetController {
+	return controllers.NewJourneyBudgetController(budgetService)
+}
@@ -0,0 +1,25 @@
+package journey_comment_fx
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Provide(
+	provideJourneyCommentRepo, provideJourneyCommentService, provideJourneyCommentController,
+)
+
+func provideJourneyCommentRepo(db *gorm.DB) repositories.JourneyCommentRepositoryInterface {
+	return repositories.NewJourneyCommentRepository(db)
+}
+
+func provideJourneyCommentService(repo repositories.JourneyCommentRepositoryInterface, pollRepo repositories.JourneyPollRepositoryInterface, mail services.IMailService) services.JourneyCommentServiceInterface {
+	return services.NewJourneyCommentService(repo, pollRepo, mail)
+}
+
+func provideJourneyCommentController(commentService services.JourneyCommentServiceInterface) *controllers.JourneyCommentController {
+	return controllers.NewJourneyCommentController(commentService)
+}
@@ -5,15 +5,30 @@ import (
 	"gorm.io/gorm"
 	"vivu/internal/repositories"
 	"vivu/internal/services"
+	"vivu/pkg/secrets"
 )
 
-var Module = fx.Provide(provideJourneyRepo, provideJourneyService)
+var Module = fx.Provide(provideJourneyRepo, provideJourneyService, provideJourneyExportService, provideJourneyImportService)
 
 func provideJourneyRepo(db *gorm.DB) repositories.JourneyRepository {
 	return repositories.NewJourneyRepository(db)
 }
 
-func provideJourneyService(journeyRepo repositories.JourneyRepository) services.JourneyServiceInterface {
+func provideJourneyService(
+	journeyRepo repositories.JourneyRepository,
+	poiRepo repositories.POIRepository,
+	optimizer *services.RouteOptimizer,
+	matrix services.DistanceMatrixService,
+	rulesService services.DestinationRuleServiceInterface,
+) services.JourneyServiceInterface {
 
-	return services.NewJourneyService(journeyRepo)
+	return services.NewJourneyService(journeyRepo, poiRepo, optimizer, matrix, rulesService)
+}
+
+func provideJourneyExportService(journeyRepo repositories.JourneyRepository, accountRepo repositories.AccountRepository, keys secrets.Getter) services.JourneyExportServiceInterface {
+	return services.NewJourneyExportService(journeyRepo, accountRepo, keys)
+}
+
+func provideJourneyImportService(journeyRepo repositories.JourneyRepository, poiRepo repositories.POIRepository) services.JourneyImportServiceInterface {
+	return services.NewJourneyImportService(journeyRepo, poiRepo)
 }
@@ -0,0 +1,36 @@
+package journey_leg_fx
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Options(
+	fx.Provide(provideJourneyLegRepo, provideJourneyLegService),
+	fx.Invoke(startJourneyLegWorker),
+)
+
+func provideJourneyLegRepo(db *gorm.DB) repositories.JourneyLegRepositoryInterface {
+	return repositories.NewJourneyLegRepository(db)
+}
+
+func provideJourneyLegService(repo repositories.JourneyLegRepositoryInterface, matrix services.DistanceMatrixService) services.JourneyLegServiceInterface {
+	return services.NewJourneyLegService(repo, matrix)
+}
+
+func startJourneyLegWorker(lc fx.Lifecycle, legService services.JourneyLegServiceInterface) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			legService.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			legService.Stop()
+			return nil
+		},
+	})
+}
@@ -0,0 +1,41 @@
+package journey_poll_fx
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Options(
+	fx.Provide(provideJourneyPollRepo, provideJourneyPollService, provideJourneyPollController),
+	fx.Invoke(startPollCloser),
+)
+
+func provideJourneyPollRepo(db *gorm.DB) repositories.JourneyPollRepositoryInterface {
+	return repositories.NewJourneyPollRepository(db)
+}
+
+func provideJourneyPollService(repo repositories.JourneyPollRepositoryInterface, accountRepo repositories.AccountRepository, poiRepo repositories.POIRepository, mail services.IMailService) services.JourneyPollServiceInterface {
+	return services.NewJourneyPollService(repo, accountRepo, poiRepo, mail)
+}
+
+func provideJourneyPollController(pollService services.JourneyPollServiceInterface) *controllers.JourneyPollController {
+	return controllers.NewJourneyPollController(pollService)
+}
+
+func startPollCloser(lc fx.Lifecycle, pollService services.JourneyPollServiceInterface) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			pollService.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			pollService.Stop()
+			return nil
+		},
+	})
+}
@@ -0,0 +1,32 @@
+package legal_fx
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+	"vivu/pkg/middleware"
+)
+
+var Module = fx.Options(
+	fx.Provide(provideLegalRepo, provideLegalService, provideLegalController),
+	fx.Invoke(installLegalGate),
+)
+
+func provideLegalRepo(db *gorm.DB) repositories.LegalRepositoryInterface {
+	return repositories.NewLegalRepository(db)
+}
+
+func provideLegalService(repo repositories.LegalRepositoryInterface) services.LegalServiceInterface {
+	return services.NewLegalService(repo)
+}
+
+func provideLegalController(legalService services.LegalServiceInterface) *controllers.LegalController {
+	return controllers.NewLegalController(legalService)
+}
+
+// installLegalGate makes JWTAuthMiddleware answer 451 until the current documents are accepted.
+func installLegalGate(legalService services.LegalServiceInterface) {
+	middleware.UseLegalGate(legalService)
+}
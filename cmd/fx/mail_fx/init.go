@@ -1,15 +1,52 @@
 package mail_fx
 
 import (
+	"context"
 	"go.uber.org/fx"
+	"gorm.io/gorm"
 	"log"
 	"os"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
 	"vivu/internal/services"
 )
 
-var Module = fx.Provide(provideMailService)
+var Module = fx.Options(
+	fx.Provide(provideMailOutboxRepo, provideMailOutboxService, provideMailService, provideMailOutboxController),
+	fx.Invoke(startMailOutboxWorker),
+)
+
+func provideMailOutboxRepo(db *gorm.DB) repositories.MailOutboxRepositoryInterface {
+	return repositories.NewMailOutboxRepository(db)
+}
+
+// provideMailService hands the rest of the app the outbox, so every email is queued and retried.
+func provideMailService(outbox services.MailOutboxServiceInterface) services.IMailService {
+	return outbox
+}
+
+func provideMailOutboxService(repo repositories.MailOutboxRepositoryInterface) services.MailOutboxServiceInterface {
+	return services.NewMailOutboxService(repo, newSMTPMailService())
+}
+
+func provideMailOutboxController(outbox services.MailOutboxServiceInterface) *controllers.MailOutboxController {
+	return controllers.NewMailOutboxController(outbox)
+}
+
+func startMailOutboxWorker(lc fx.Lifecycle, outbox services.MailOutboxServiceInterface) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			outbox.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			outbox.Stop()
+			return nil
+		},
+	})
+}
 
-func provideMailService() services.IMailService {
+func newSMTPMailService() services.IMailService {
 
 	cfg := services.SMTPConfig{
 		Host:       "smtp.gmail.com",
@@ -5,8 +5,16 @@ import (
 	mem "vivu/pkg/memcache"
 )
 
-var Module = fx.Provide(provideMemcacheClient)
+var Module = fx.Provide(provideMemcacheClient, provideLoginAttempts, provideQuizSessions)
 
 func provideMemcacheClient() mem.ResetTokenStore {
 	return mem.NewResetTokens()
 }
+
+func provideLoginAttempts() mem.LoginAttemptStore {
+	return mem.NewLoginAttempts()
+}
+
+func provideQuizSessions() mem.QuizSessionStore {
+	return mem.NewQuizSessions()
+}
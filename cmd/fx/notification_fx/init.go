@@ -0,0 +1,23 @@
+package notification_fx
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Provide(provideNotificationRepo, provideNotificationService, provideNotificationController)
+
+func provideNotificationRepo(db *gorm.DB) repositories.NotificationRepositoryInterface {
+	return repositories.NewNotificationRepository(db)
+}
+
+func provideNotificationService(repo repositories.NotificationRepositoryInterface) services.NotificationServiceInterface {
+	return services.NewNotificationService(repo)
+}
+
+func provideNotificationController(notificationService services.NotificationServiceInterface) *controllers.NotificationController {
+	return controllers.NewNotificationController(notificationService)
+}
@@ -1,29 +1,64 @@
 package payment_service_fx
 
 import (
+	"context"
+
 	"go.uber.org/fx"
 	"gorm.io/gorm"
 	"log"
 	"os"
 	"vivu/internal/api/controllers"
 	"vivu/internal/services"
+	"vivu/pkg/secrets"
 )
 
 var payOsCgf = services.PayOSConfig{
-	ClientID:     os.Getenv("PAYOS_CLIENT_ID"),
-	ApiKey:       os.Getenv("PAYOS_API_KEY"),
-	ChecksumKey:  os.Getenv("PAYOS_CHECKSUM_KEY"),
-	ProviderName: "payos",
-	CancelURL:    "http://localhost:3000/payment/cancel",
-	ReturnURL:    "vivuapp://payment/success?orderId=123",
+	ClientID:    os.Getenv("PAYOS_CLIENT_ID"),
+	ApiKey:      os.Getenv("PAYOS_API_KEY"),
+	ChecksumKey: os.Getenv("PAYOS_CHECKSUM_KEY"),
+	CancelURL:   "http://localhost:3000/payment/cancel",
+	ReturnURL:   "vivuapp://payment/success?orderId=123",
+}
+
+// Stripe only redirects to web pages; the success page hands over to the app
+var stripeCfg = services.StripeConfig{
+	SuccessURL: envOr("STRIPE_SUCCESS_URL", "http://localhost:3000/payment/success"),
+	CancelURL:  envOr("STRIPE_CANCEL_URL", "http://localhost:3000/payment/cancel"),
 }
 
-var Module = fx.Provide(
-	providePaymentService, provicePaymentController,
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+var Module = fx.Options(
+	fx.Provide(providePaymentService, provicePaymentController),
+	fx.Invoke(startExpiryWorker),
 )
 
-func providePaymentService(db *gorm.DB) services.PaymentService {
-	instance, err := services.NewPaymentService(db, payOsCgf)
+// providePaymentProviders sets up every provider with credentials: payOS from PAYOS_*, Stripe
+// from STRIPE_SECRET_KEY and STRIPE_WEBHOOK_SECRET.
+func providePaymentProviders(keys secrets.Getter) []services.PaymentProvider {
+	var providers []services.PaymentProvider
+	if p, err := services.NewPayOSProvider(payOsCgf); err == nil {
+		providers = append(providers, p)
+	} else {
+		log.Printf("payOS disabled: %v", err)
+	}
+	if p, err := services.NewStripeProvider(stripeCfg, keys); err == nil {
+		providers = append(providers, p)
+	} else {
+		log.Printf("Stripe disabled: %v", err)
+	}
+	return providers
+}
+
+// providePaymentService takes checkouts through PAYMENT_PROVIDER (payos or stripe, default payos)
+// unless the checkout asks for another provider or the plan's currency needs one.
+func providePaymentService(db *gorm.DB, keys secrets.Getter, events services.EventBus, mailService services.IMailService, receipts services.ReceiptMailer) services.PaymentService {
+	instance, err := services.NewPaymentService(db, providePaymentProviders(keys), envOr("PAYMENT_PROVIDER", services.ProviderPayOS), events, mailService, receipts)
 	if err != nil {
 		log.Printf("Error initializing PaymentService: %v", err)
 	}
@@ -34,3 +69,21 @@ func providePaymentService(db *gorm.DB) services.PaymentService {
 func provicePaymentController(paymentService services.PaymentService) *controllers.PaymentController {
 	return controllers.NewPaymentController(paymentService)
 }
+
+// startExpiryWorker publishes subscription.expiring ahead of the end of subscriptions and ends
+// canceled ones when their period is over.
+func startExpiryWorker(lc fx.Lifecycle, paymentService services.PaymentService) {
+	if paymentService == nil { // no payment provider is configured
+		return
+	}
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			paymentService.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			paymentService.Stop()
+			return nil
+		},
+	})
+}
@@ -0,0 +1,29 @@
+package personal_data_fx
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Provide(providePersonalDataRepo, providePersonalDataService, providePersonalDataController)
+
+func providePersonalDataRepo(db *gorm.DB) repositories.PersonalDataRepositoryInterface {
+	return repositories.NewPersonalDataRepository(db)
+}
+
+func providePersonalDataService(
+	accountRepo repositories.AccountRepository,
+	repo repositories.PersonalDataRepositoryInterface,
+	storage services.FileStorage,
+	prompts services.PromptServiceInterface,
+	sessions *services.AccountSessionGuard,
+) services.PersonalDataServiceInterface {
+	return services.NewPersonalDataService(accountRepo, repo, storage, prompts, sessions)
+}
+
+func providePersonalDataController(personalDataService services.PersonalDataServiceInterface) *controllers.PersonalDataController {
+	return controllers.NewPersonalDataController(personalDataService)
+}
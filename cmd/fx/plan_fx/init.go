@@ -0,0 +1,23 @@
+package plan_fx
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Provide(providePlanRepo, providePlanService, providePlanController)
+
+func providePlanRepo(db *gorm.DB) repositories.IPlanRepository {
+	return repositories.NewPlanRepository(db)
+}
+
+func providePlanService(repo repositories.IPlanRepository) services.PlanServiceInterface {
+	return services.NewPlanService(repo)
+}
+
+func providePlanController(planService services.PlanServiceInterface) *controllers.PlanController {
+	return controllers.NewPlanController(planService)
+}
@@ -0,0 +1,20 @@
+package plan_quota_fx
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Provide(
+	providePlanUsageRepo, providePlanQuotaService,
+)
+
+func providePlanUsageRepo(db *gorm.DB) repositories.PlanUsageRepositoryInterface {
+	return repositories.NewPlanUsageRepository(db)
+}
+
+func providePlanQuotaService(repo repositories.PlanUsageRepositoryInterface) services.PlanQuotaServiceInterface {
+	return services.NewPlanQuotaService(repo)
+}
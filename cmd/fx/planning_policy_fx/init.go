@@ -0,0 +1,25 @@
+package planning_policy_fx
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Provide(
+	providePlanningPolicyRepo, providePlanningPolicyService, providePlanningPolicyController,
+)
+
+func providePlanningPolicyRepo(db *gorm.DB) repositories.PlanningPolicyRepositoryInterface {
+	return repositories.NewPlanningPolicyRepository(db)
+}
+
+func providePlanningPolicyService(repo repositories.PlanningPolicyRepositoryInterface, poisRepo repositories.POIRepository) services.PlanningPolicyServiceInterface {
+	return services.NewPlanningPolicyService(repo, poisRepo)
+}
+
+func providePlanningPolicyController(policyService services.PlanningPolicyServiceInterface) *controllers.PlanningPolicyController {
+	return controllers.NewPlanningPolicyController(policyService)
+}
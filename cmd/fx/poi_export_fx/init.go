@@ -0,0 +1,25 @@
+package poi_export_fx
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Provide(
+	providePOIExportRepo, provideExportService, providePOIExportController,
+)
+
+func providePOIExportRepo(db *gorm.DB) repositories.POIExportRepositoryInterface {
+	return repositories.NewPOIExportRepository(db)
+}
+
+func provideExportService(lookups repositories.POIImportRepositoryInterface, repo repositories.POIExportRepositoryInterface) services.ExportServiceInterface {
+	return services.NewExportService(lookups, repo)
+}
+
+func providePOIExportController(exportService services.ExportServiceInterface) *controllers.POIExportController {
+	return controllers.NewPOIExportController(exportService)
+}
@@ -0,0 +1,25 @@
+package poi_import_fx
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Provide(
+	providePOIImportRepo, provideBulkImportService, providePOIImportController,
+)
+
+func providePOIImportRepo(db *gorm.DB) repositories.POIImportRepositoryInterface {
+	return repositories.NewPOIImportRepository(db)
+}
+
+func provideBulkImportService(repo repositories.POIImportRepositoryInterface, boundaries services.ProvinceBoundaryServiceInterface) services.BulkImportServiceInterface {
+	return services.NewBulkImportService(repo, boundaries)
+}
+
+func providePOIImportController(importService services.BulkImportServiceInterface) *controllers.POIImportController {
+	return controllers.NewPOIImportController(importService)
+}
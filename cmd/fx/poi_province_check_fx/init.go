@@ -0,0 +1,41 @@
+package poi_province_check_fx
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Options(
+	fx.Provide(providePOIProvinceFlagRepo, providePOIProvinceCheckService, providePOIProvinceFlagController),
+	fx.Invoke(startPOIProvinceCheckWorker),
+)
+
+func providePOIProvinceFlagRepo(db *gorm.DB) repositories.POIProvinceFlagRepositoryInterface {
+	return repositories.NewPOIProvinceFlagRepository(db)
+}
+
+func providePOIProvinceCheckService(repo repositories.POIProvinceFlagRepositoryInterface, boundaries services.ProvinceBoundaryServiceInterface) services.POIProvinceCheckServiceInterface {
+	return services.NewPOIProvinceCheckService(repo, boundaries)
+}
+
+func providePOIProvinceFlagController(checkService services.POIProvinceCheckServiceInterface) *controllers.POIProvinceFlagController {
+	return controllers.NewPOIProvinceFlagController(checkService)
+}
+
+func startPOIProvinceCheckWorker(lc fx.Lifecycle, checkService services.POIProvinceCheckServiceInterface) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			checkService.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			checkService.Stop()
+			return nil
+		},
+	})
+}
@@ -0,0 +1,25 @@
+package poi_quality_fx
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Provide(
+	providePOIQualityRepo, providePOIQualityService, providePOIQualityController,
+)
+
+func providePOIQualityRepo(db *gorm.DB) repositories.POIQualityRepositoryInterface {
+	return repositories.NewPOIQualityRepository(db)
+}
+
+func providePOIQualityService(repo repositories.POIQualityRepositoryInterface) services.POIQualityServiceInterface {
+	return services.NewPOIQualityService(repo)
+}
+
+func providePOIQualityController(qualityService services.POIQualityServiceInterface) *controllers.POIQualityController {
+	return controllers.NewPOIQualityController(qualityService)
+}
@@ -0,0 +1,41 @@
+package poi_rating_fx
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Options(
+	fx.Provide(provideExternalRefRepo, provideRatingEnrichmentService, providePOIRatingController),
+	fx.Invoke(startRatingJob),
+)
+
+func provideExternalRefRepo(db *gorm.DB) repositories.POIExternalRefRepositoryInterface {
+	return repositories.NewPOIExternalRefRepository(db)
+}
+
+func provideRatingEnrichmentService(refRepo repositories.POIExternalRefRepositoryInterface, poiRepo repositories.POIRepository) services.RatingEnrichmentServiceInterface {
+	return services.NewRatingEnrichmentService(refRepo, poiRepo, services.NewRatingProvidersFromEnv())
+}
+
+func providePOIRatingController(ratingService services.RatingEnrichmentServiceInterface) *controllers.POIRatingController {
+	return controllers.NewPOIRatingController(ratingService)
+}
+
+func startRatingJob(lc fx.Lifecycle, ratingService services.RatingEnrichmentServiceInterface) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			ratingService.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			ratingService.Stop()
+			return nil
+		},
+	})
+}
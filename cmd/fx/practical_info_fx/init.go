@@ -0,0 +1,25 @@
+package practical_info_fx
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Provide(
+	providePracticalInfoRepo, providePracticalInfoService, providePracticalInfoController,
+)
+
+func providePracticalInfoRepo(db *gorm.DB) repositories.PracticalInfoRepositoryInterface {
+	return repositories.NewPracticalInfoRepository(db)
+}
+
+func providePracticalInfoService(repo repositories.PracticalInfoRepositoryInterface) services.PracticalInfoServiceInterface {
+	return services.NewPracticalInfoService(repo)
+}
+
+func providePracticalInfoController(infoService services.PracticalInfoServiceInterface) *controllers.PracticalInfoController {
+	return controllers.NewPracticalInfoController(infoService)
+}
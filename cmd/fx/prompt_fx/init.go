@@ -2,46 +2,135 @@
 package prompt_fx
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 	"vivu/internal/repositories"
 	"vivu/internal/services"
+	mem "vivu/pkg/memcache"
+	"vivu/pkg/secrets"
 	"vivu/pkg/utils"
 
 	"go.uber.org/fx"
 )
 
-var Module = fx.Provide(
-	ProvideEmbeddingClient,
-	ProvidePromptService)
+var Module = fx.Options(
+	fx.Provide(
+		ProvideEmbeddingClient,
+		ProvidePlanWarmup,
+		ProvideAIClientResolver,
+		ProvidePromptService,
+		ProvidePlanExplainService),
+	fx.Invoke(StartPlanWarmup),
+)
 
 // EmbeddingConfig holds configuration for embedding clients
 type EmbeddingConfig struct {
 	Provider string
 	APIKey   string
-	Model    string
+	// KeyName is the secret APIKey was read from; Gemini reads it again on every call so a
+	// rotated key is used without a restart.
+	KeyName string
+	Model   string
+	// Self-hosted providers (ollama) only
+	BaseURL    string
+	EmbedModel string
 }
 
-// ProvideEmbeddingClient creates an embedding client based on environment variables
-func ProvideEmbeddingClient() (utils.EmbeddingClientInterface, error) {
-	config := getEmbeddingConfig()
+// ProvideEmbeddingClient creates an embedding client based on environment variables.
+// EMBEDDING_PROVIDER=router spreads generation over AI_PROVIDERS (see newAIRouter).
+func ProvideEmbeddingClient(keys secrets.Getter) (utils.EmbeddingClientInterface, error) {
+	provider := strings.ToLower(getEnvWithDefault("EMBEDDING_PROVIDER", "gemini")) // Default to free Gemini
+	if provider == "router" {
+		return newAIRouter(keys)
+	}
 
+	config := getEmbeddingConfig(provider, keys)
+	if config.APIKey == "" && needsAPIKey(provider) {
+		log.Fatalf("an API key is required when using the %s provider", provider)
+	}
+	if provider == "anthropic" {
+		log.Printf("anthropic has no embeddings: semantic search will fail, use EMBEDDING_PROVIDER=router with AI_EMBEDDING_PROVIDER set to another provider")
+	}
 	log.Printf("Initializing %s embedding client with model: %s", config.Provider, config.Model)
+	return newAIClient(config, keys)
+}
 
-	switch strings.ToLower(config.Provider) {
+func newAIClient(config EmbeddingConfig, keys secrets.Getter) (utils.EmbeddingClientInterface, error) {
+	switch config.Provider {
 	case "openai":
 		return utils.NewOpenAIEmbeddingClient(config.APIKey, config.Model), nil
 	case "gemini":
-		client, err := utils.NewGeminiEmbeddingClient(config.APIKey, config.Model)
+		apiKey := func() string { return keys.Get(config.KeyName) }
+		client, err := utils.NewGeminiEmbeddingClient(apiKey, config.Model)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create Gemini client: %w", err)
 		}
 		return client, nil
+	case "anthropic":
+		return utils.NewAnthropicClient(config.APIKey, config.Model), nil
+	case "ollama":
+		return utils.NewOllamaClient(config.BaseURL, config.Model, config.EmbedModel), nil
+	case "mock":
+		return utils.NewMockAIClient(), nil
 	default:
-		return nil, fmt.Errorf("unsupported embedding provider: %s. Use 'openai' or 'gemini'", config.Provider)
+		return nil, fmt.Errorf("unsupported embedding provider: %s. Use 'openai', 'gemini', 'anthropic', 'ollama', 'mock' or 'router'", config.Provider)
+	}
+}
+
+// newAIRouter reads AI_PROVIDERS (ordered, default "gemini,openai"), AI_EMBEDDING_PROVIDER
+// (default the first provider with embeddings; must match the model the stored vectors came from),
+// AI_CALL_TIMEOUT (60s), AI_CIRCUIT_THRESHOLD (3) and AI_CIRCUIT_COOLDOWN (2m).
+func newAIRouter(keys secrets.Getter) (utils.EmbeddingClientInterface, error) {
+	var providers []utils.AIProvider
+	for _, name := range strings.Split(getEnvWithDefault("AI_PROVIDERS", "gemini,openai"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		config := getEmbeddingConfig(name, keys)
+		if config.APIKey == "" && needsAPIKey(name) {
+			return nil, fmt.Errorf("ai router: no API key for provider %s", name)
+		}
+		client, err := newAIClient(config, keys)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, utils.AIProvider{Name: name, Client: client})
+	}
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("ai router: AI_PROVIDERS is empty")
+	}
+
+	defaultEmbedding := providers[0].Name
+	for _, p := range providers {
+		if p.Name != "anthropic" { // no embeddings endpoint
+			defaultEmbedding = p.Name
+			break
+		}
+	}
+	embedding := strings.ToLower(getEnvWithDefault("AI_EMBEDDING_PROVIDER", defaultEmbedding))
+	cfg := utils.AIRouterConfig{}
+	if d, err := time.ParseDuration(os.Getenv("AI_CALL_TIMEOUT")); err == nil {
+		cfg.CallTimeout = d
+	}
+	if n, err := strconv.Atoi(os.Getenv("AI_CIRCUIT_THRESHOLD")); err == nil {
+		cfg.FailureThreshold = n
+	}
+	if d, err := time.ParseDuration(os.Getenv("AI_CIRCUIT_COOLDOWN")); err == nil {
+		cfg.Cooldown = d
+	}
+
+	names := make([]string, len(providers))
+	for i, p := range providers {
+		names[i] = p.Name
 	}
+	log.Printf("Initializing AI router: generation %s, embeddings %s", strings.Join(names, " -> "), embedding)
+	return utils.NewAIRouter(providers, embedding, cfg)
 }
 
 // ProvidePromptService creates the prompt service with all dependencies
@@ -54,6 +143,15 @@ func ProvidePromptService(
 	matrixService services.DistanceMatrixService,
 	journeyRepo repositories.JourneyRepository,
 	accountService services.AccountServiceInterface,
+	rulesService services.DestinationRuleServiceInterface,
+	practicalInfo services.PracticalInfoServiceInterface,
+	planQuota services.PlanQuotaServiceInterface,
+	presets services.TravelPresetServiceInterface,
+	events services.EventBus,
+	warmup *services.PlanWarmup,
+	aiProfiles services.AIClientResolverInterface,
+	policies services.PlanningPolicyServiceInterface,
+	quizSessions mem.QuizSessionStore,
 ) services.PromptServiceInterface {
 	return services.NewPromptService(
 		poisService,
@@ -64,37 +162,122 @@ func ProvidePromptService(
 		matrixService,
 		journeyRepo,
 		accountService,
+		rulesService,
+		practicalInfo,
+		planQuota,
+		presets,
+		events,
+		warmup,
+		aiProfiles,
+		policies,
+		quizSessions,
 	)
 }
 
-// getEmbeddingConfig reads configuration from environment variables
-func getEmbeddingConfig() EmbeddingConfig {
-	provider := getEnvWithDefault("EMBEDDING_PROVIDER", "gemini") // Default to free Gemini
+// ProvideAIClientResolver reads AI_PROFILES, the clients plans can switch their subscribers to
+// with the ai_profile entitlement, as name=provider[:model] separated by "," (e.g.
+// "beta=anthropic:claude-opus-4-1,fast=gemini:gemini-2.5-flash-lite"). The model defaults to
+// the provider's one. Accounts without a profile keep the default client.
+func ProvideAIClientResolver(
+	aiService utils.EmbeddingClientInterface,
+	keys secrets.Getter,
+	billingRepo repositories.BillingRepositoryInterface,
+	switches services.RuntimeSwitchServiceInterface,
+) (services.AIClientResolverInterface, error) {
+	profiles := make(services.AIProfiles)
+	for _, entry := range strings.Split(os.Getenv("AI_PROFILES"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, spec, ok := strings.Cut(entry, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !ok || name == "" {
+			return nil, fmt.Errorf("ai profiles: %q is not name=provider[:model]", entry)
+		}
+		provider, model, _ := strings.Cut(strings.TrimSpace(spec), ":")
+		provider = strings.ToLower(provider)
 
-	var apiKey, model string
+		config := getEmbeddingConfig(provider, keys)
+		if model != "" {
+			config.Model = model
+		}
+		if config.APIKey == "" && needsAPIKey(provider) {
+			return nil, fmt.Errorf("ai profiles: no API key for provider %s of profile %s", provider, name)
+		}
+		client, err := newAIClient(config, keys)
+		if err != nil {
+			return nil, fmt.Errorf("ai profiles: profile %s: %w", name, err)
+		}
+		profiles[name] = client
+		log.Printf("AI profile %s: %s with model %s", name, provider, config.Model)
+	}
+	return services.NewAIClientResolver(aiService, profiles, billingRepo, switches), nil
+}
+
+// ProvidePlanWarmup caches the retrieval step of plan generation; see services.NewPlanWarmup
+// for its settings.
+func ProvidePlanWarmup(dashboardRepo repositories.DashboardRepository, matrixService services.DistanceMatrixService) *services.PlanWarmup {
+	return services.NewPlanWarmup(dashboardRepo, matrixService)
+}
 
-	switch strings.ToLower(provider) {
+// StartPlanWarmup runs the warm-up once the prompt service bound its retrieval to it.
+func StartPlanWarmup(lc fx.Lifecycle, warmup *services.PlanWarmup, _ services.PromptServiceInterface) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			warmup.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			warmup.Stop()
+			return nil
+		},
+	})
+}
+
+func ProvidePlanExplainService(pollRepo repositories.JourneyPollRepositoryInterface, poisRepo repositories.POIRepository) services.PlanExplainServiceInterface {
+	return services.NewPlanExplainService(pollRepo, poisRepo)
+}
+
+// getEmbeddingConfig reads the key of one provider from the secrets and its model from
+// environment variables
+func getEmbeddingConfig(provider string, keys secrets.Getter) EmbeddingConfig {
+	var keyName, model, baseURL, embedModel string
+
+	switch provider {
 	case "openai":
-		apiKey = os.Getenv("OPENAI_API_KEY")
+		keyName = "OPENAI_API_KEY"
 		model = getEnvWithDefault("OPENAI_MODEL", "text-embedding-3-small")
-		if apiKey == "" {
-			log.Fatal("OPENAI_API_KEY is required when using OpenAI provider")
-		}
 	case "gemini":
-		apiKey = os.Getenv("GEMINI_API_KEY")
+		keyName = "GEMINI_API_KEY"
 		model = getEnvWithDefault("GEMINI_MODEL", "gemini-2.5-flash-lite")
-		if apiKey == "" {
-			log.Fatal("GEMINI_API_KEY is required when using Gemini provider")
-		}
+	case "anthropic":
+		keyName = "ANTHROPIC_API_KEY"
+		model = getEnvWithDefault("ANTHROPIC_MODEL", "claude-sonnet-4-5")
+	case "ollama":
+		baseURL = getEnvWithDefault("OLLAMA_BASE_URL", "http://localhost:11434")
+		model = getEnvWithDefault("OLLAMA_MODEL", "llama3.1")
+		embedModel = getEnvWithDefault("OLLAMA_EMBED_MODEL", "nomic-embed-text")
 	}
 
+	var apiKey string
+	if keyName != "" {
+		apiKey = keys.Get(keyName)
+	}
 	return EmbeddingConfig{
-		Provider: provider,
-		APIKey:   apiKey,
-		Model:    model,
+		Provider:   provider,
+		APIKey:     apiKey,
+		KeyName:    keyName,
+		Model:      model,
+		BaseURL:    baseURL,
+		EmbedModel: embedModel,
 	}
 }
 
+func needsAPIKey(provider string) bool {
+	return provider == "openai" || provider == "gemini" || provider == "anthropic"
+}
+
 // getEnvWithDefault returns environment variable or default value
 func getEnvWithDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -3,12 +3,13 @@ package province_fx
 import (
 	"go.uber.org/fx"
 	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
 	"vivu/internal/repositories"
 	"vivu/internal/services"
 )
 
 var Module = fx.Provide(
-	NewProvinceService, NewProvinceRepo)
+	NewProvinceService, NewProvinceRepo, NewProvinceBoundaryRepo, NewProvinceBoundaryService, NewProvinceBoundaryController)
 
 func NewProvinceService(repo repositories.ProvinceRepository) services.ProvinceServiceInterface {
 	return services.NewProvinceService(repo)
@@ -17,3 +18,15 @@ func NewProvinceService(repo repositories.ProvinceRepository) services.ProvinceS
 func NewProvinceRepo(db *gorm.DB) repositories.ProvinceRepository {
 	return repositories.NewProvinceRepository(db)
 }
+
+func NewProvinceBoundaryRepo(db *gorm.DB) repositories.ProvinceBoundaryRepositoryInterface {
+	return repositories.NewProvinceBoundaryRepository(db)
+}
+
+func NewProvinceBoundaryService(repo repositories.ProvinceBoundaryRepositoryInterface) services.ProvinceBoundaryServiceInterface {
+	return services.NewProvinceBoundaryService(repo)
+}
+
+func NewProvinceBoundaryController(boundaryService services.ProvinceBoundaryServiceInterface) *controllers.ProvinceBoundaryController {
+	return controllers.NewProvinceBoundaryController(boundaryService)
+}
@@ -0,0 +1,25 @@
+package province_guide_fx
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Provide(
+	provideProvinceGuideRepo, provideProvinceGuideService, provideProvinceGuideController,
+)
+
+func provideProvinceGuideRepo(db *gorm.DB) repositories.ProvinceGuideRepositoryInterface {
+	return repositories.NewProvinceGuideRepository(db)
+}
+
+func provideProvinceGuideService(repo repositories.ProvinceGuideRepositoryInterface, poiRepo repositories.POIRepository) services.ProvinceGuideServiceInterface {
+	return services.NewProvinceGuideService(repo, poiRepo)
+}
+
+func provideProvinceGuideController(guideService services.ProvinceGuideServiceInterface) *controllers.ProvinceGuideController {
+	return controllers.NewProvinceGuideController(guideService)
+}
@@ -0,0 +1,25 @@
+package runtime_switch_fx
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Provide(
+	provideRuntimeSwitchRepo, provideRuntimeSwitchService, provideRuntimeSwitchController,
+)
+
+func provideRuntimeSwitchRepo(db *gorm.DB) repositories.RuntimeSwitchRepositoryInterface {
+	return repositories.NewRuntimeSwitchRepository(db)
+}
+
+func provideRuntimeSwitchService(repo repositories.RuntimeSwitchRepositoryInterface) services.RuntimeSwitchServiceInterface {
+	return services.NewRuntimeSwitchService(repo)
+}
+
+func provideRuntimeSwitchController(switchService services.RuntimeSwitchServiceInterface) *controllers.RuntimeSwitchController {
+	return controllers.NewRuntimeSwitchController(switchService)
+}
@@ -0,0 +1,67 @@
+package secrets_fx
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+	"vivu/pkg/secrets"
+)
+
+var Module = fx.Options(
+	fx.Provide(provideSecretUsageRepo, provideSecretStore, provideSecretGetter, provideSecretService, provideSecretController),
+	fx.Invoke(startSecretRefresh),
+)
+
+func provideSecretUsageRepo(db *gorm.DB) repositories.SecretUsageRepositoryInterface {
+	return repositories.NewSecretUsageRepository(db)
+}
+
+// provideSecretStore reads the secrets manager settings (see secrets.SourceFromEnv) and
+// SECRETS_REFRESH_INTERVAL, how often rotated keys are picked up and usage is saved
+// (default 5m). A misconfigured manager stops the app rather than run on stale env keys.
+func provideSecretStore(repo repositories.SecretUsageRepositoryInterface) *secrets.Store {
+	source, err := secrets.SourceFromEnv()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	interval := 5 * time.Minute
+	if d, err := time.ParseDuration(os.Getenv("SECRETS_REFRESH_INTERVAL")); err == nil && d > 0 {
+		interval = d
+	}
+	store := secrets.NewStore(source, repo, interval)
+	log.Printf("Secrets are read from %s", store.SourceName())
+	return store
+}
+
+func provideSecretGetter(store *secrets.Store) secrets.Getter {
+	return store
+}
+
+func provideSecretService(store *secrets.Store, repo repositories.SecretUsageRepositoryInterface) services.SecretServiceInterface {
+	return services.NewSecretService(store, repo)
+}
+
+func provideSecretController(secretService services.SecretServiceInterface) *controllers.SecretController {
+	return controllers.NewSecretController(secretService)
+}
+
+// startSecretRefresh reloads the secrets and saves their usage periodically.
+func startSecretRefresh(lc fx.Lifecycle, store *secrets.Store) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			store.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			store.Stop()
+			return nil
+		},
+	})
+}
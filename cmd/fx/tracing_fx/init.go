@@ -0,0 +1,20 @@
+package tracing_fx
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+	"vivu/internal/infra"
+)
+
+// Module sets up the tracer provider before anything else starts and flushes it on stop.
+var Module = fx.Invoke(registerTracing)
+
+func registerTracing(lc fx.Lifecycle) {
+	shutdown := infra.InitTracing()
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return shutdown(ctx)
+		},
+	})
+}
@@ -0,0 +1,26 @@
+package travel_document_fx
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Provide(
+	services.NewFileStorage,
+	provideTravelDocumentRepo, provideTravelDocumentService, provideTravelDocumentController,
+)
+
+func provideTravelDocumentRepo(db *gorm.DB) repositories.TravelDocumentRepositoryInterface {
+	return repositories.NewTravelDocumentRepository(db)
+}
+
+func provideTravelDocumentService(repo repositories.TravelDocumentRepositoryInterface, pollRepo repositories.JourneyPollRepositoryInterface, storage services.FileStorage) services.TravelDocumentServiceInterface {
+	return services.NewTravelDocumentService(repo, pollRepo, storage)
+}
+
+func provideTravelDocumentController(documentService services.TravelDocumentServiceInterface) *controllers.TravelDocumentController {
+	return controllers.NewTravelDocumentController(documentService)
+}
@@ -0,0 +1,25 @@
+package travel_preset_fx
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Provide(
+	provideTravelPresetRepo, provideTravelPresetService, provideTravelPresetController,
+)
+
+func provideTravelPresetRepo(db *gorm.DB) repositories.TravelPresetRepositoryInterface {
+	return repositories.NewTravelPresetRepository(db)
+}
+
+func provideTravelPresetService(repo repositories.TravelPresetRepositoryInterface) services.TravelPresetServiceInterface {
+	return services.NewTravelPresetService(repo)
+}
+
+func provideTravelPresetController(presetService services.TravelPresetServiceInterface) *controllers.TravelPresetController {
+	return controllers.NewTravelPresetController(presetService)
+}
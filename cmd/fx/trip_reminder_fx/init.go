@@ -0,0 +1,41 @@
+package trip_reminder_fx
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Options(
+	fx.Provide(provideTripReminderRepo, provideTripReminderService, provideTripReminderController),
+	fx.Invoke(startTripReminderWorker),
+)
+
+func provideTripReminderRepo(db *gorm.DB) repositories.TripReminderRepositoryInterface {
+	return repositories.NewTripReminderRepository(db)
+}
+
+func provideTripReminderService(repo repositories.TripReminderRepositoryInterface, mail services.IMailService) services.TripReminderServiceInterface {
+	return services.NewTripReminderService(repo, mail)
+}
+
+func provideTripReminderController(reminderService services.TripReminderServiceInterface) *controllers.TripReminderController {
+	return controllers.NewTripReminderController(reminderService)
+}
+
+func startTripReminderWorker(lc fx.Lifecycle, reminderService services.TripReminderServiceInterface) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			reminderService.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			reminderService.Stop()
+			return nil
+		},
+	})
+}
@@ -0,0 +1,41 @@
+package webhook_fx
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"vivu/internal/api/controllers"
+	"vivu/internal/repositories"
+	"vivu/internal/services"
+)
+
+var Module = fx.Options(
+	fx.Provide(provideWebhookRepo, provideWebhookService, provideWebhookController),
+	fx.Invoke(startWebhookWorker),
+)
+
+func provideWebhookRepo(db *gorm.DB) repositories.WebhookRepositoryInterface {
+	return repositories.NewWebhookRepository(db)
+}
+
+func provideWebhookService(repo repositories.WebhookRepositoryInterface) services.WebhookServiceInterface {
+	return services.NewWebhookService(repo)
+}
+
+func provideWebhookController(webhookService services.WebhookServiceInterface) *controllers.WebhookController {
+	return controllers.NewWebhookController(webhookService)
+}
+
+func startWebhookWorker(lc fx.Lifecycle, webhookService services.WebhookServiceInterface) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			webhookService.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			webhookService.Stop()
+			return nil
+		},
+	})
+}
@@ -0,0 +1,379 @@
+// cmd/loadtest/main.go
+//
+// Replays recorded quiz profiles against a running environment and reports
+// latency percentiles per step plus error-budget compliance.
+//
+//	go run ./cmd/loadtest -target https://staging.example.com -email qa@vivu.vn -password secret \
+//	    -profiles cmd/loadtest/profiles.example.json -rps 2 -duration 2m -provider mock
+//
+// Start the target with EMBEDDING_PROVIDER=mock (optionally MOCK_AI_LATENCY_MS) to measure
+// our own stack only, or with the real provider to include model latency and quotas.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+)
+
+// Answers are replayed in quiz order; keys must match the quiz question IDs.
+type recordedProfile struct {
+	Name    string            `json:"name"`
+	Answers map[string]string `json:"answers"`
+}
+
+type config struct {
+	target      string
+	token       string
+	email       string
+	password    string
+	profiles    string
+	rps         float64
+	duration    time.Duration
+	maxInFlight int
+	timeout     time.Duration
+	provider    string
+	errorBudget float64
+	p95Budget   time.Duration
+}
+
+type apiEnvelope struct {
+	Status  string          `json:"status"`
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+type stepResult struct {
+	step    string
+	latency time.Duration
+	err     error
+}
+
+type recorder struct {
+	mu        sync.Mutex
+	latencies map[string][]time.Duration
+	errors    map[string]map[string]int
+	scenarios int64
+	failed    int64
+	dropped   int64
+}
+
+func newRecorder() *recorder {
+	return &recorder{
+		latencies: make(map[string][]time.Duration),
+		errors:    make(map[string]map[string]int),
+	}
+}
+
+func (r *recorder) add(res stepResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latencies[res.step] = append(r.latencies[res.step], res.latency)
+	if res.err != nil {
+		if r.errors[res.step] == nil {
+			r.errors[res.step] = make(map[string]int)
+		}
+		r.errors[res.step][res.err.Error()]++
+	}
+}
+
+func main() {
+	cfg := parseFlags()
+
+	profiles, err := loadProfiles(cfg.profiles)
+	if err != nil {
+		log.Fatalf("load profiles: %v", err)
+	}
+
+	client := &http.Client{Timeout: cfg.timeout}
+
+	if cfg.token == "" {
+		if cfg.email == "" || cfg.password == "" {
+			log.Fatal("either -token (or LOADTEST_TOKEN) or -email/-password is required")
+		}
+		cfg.token, err = login(client, cfg)
+		if err != nil {
+			log.Fatalf("login: %v", err)
+		}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	ctx, cancelRun := context.WithTimeout(ctx, cfg.duration)
+	defer cancelRun()
+
+	log.Printf("load test: target=%s provider=%s rps=%.2f duration=%s profiles=%d",
+		cfg.target, cfg.provider, cfg.rps, cfg.duration, len(profiles))
+
+	rec := newRecorder()
+	sem := make(chan struct{}, cfg.maxInFlight)
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / cfg.rps))
+	defer ticker.Stop()
+
+	var wg sync.WaitGroup
+	started := time.Now()
+	i := 0
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-ticker.C:
+			select {
+			case sem <- struct{}{}:
+			default:
+				// Too many scenarios in flight: the target can't keep up with the requested rate.
+				atomic.AddInt64(&rec.dropped, 1)
+				continue
+			}
+			p := profiles[i%len(profiles)]
+			i++
+			wg.Add(1)
+			go func(p recordedProfile) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				runScenario(client, cfg, p, rec)
+			}(p)
+		}
+	}
+
+	wg.Wait()
+	ok := report(os.Stdout, cfg, rec, time.Since(started))
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+func parseFlags() config {
+	var cfg config
+	flag.StringVar(&cfg.target, "target", "http://localhost:8080", "base URL of the environment under test")
+	flag.StringVar(&cfg.token, "token", os.Getenv("LOADTEST_TOKEN"), "bearer token (defaults to LOADTEST_TOKEN)")
+	flag.StringVar(&cfg.email, "email", "", "account email used to obtain a token when -token is empty")
+	flag.StringVar(&cfg.password, "password", os.Getenv("LOADTEST_PASSWORD"), "account password (defaults to LOADTEST_PASSWORD)")
+	flag.StringVar(&cfg.profiles, "profiles", "cmd/loadtest/profiles.example.json", "JSON file with recorded quiz profiles")
+	flag.Float64Var(&cfg.rps, "rps", 1, "scenarios started per second")
+	flag.DurationVar(&cfg.duration, "duration", time.Minute, "how long to keep starting scenarios")
+	flag.IntVar(&cfg.maxInFlight, "max-in-flight", 50, "maximum concurrent scenarios")
+	flag.DurationVar(&cfg.timeout, "timeout", 90*time.Second, "per-request timeout")
+	flag.StringVar(&cfg.provider, "provider", "mock", "AI provider the target runs with (mock|real), used for labelling the report")
+	flag.Float64Var(&cfg.errorBudget, "error-budget", 0.01, "maximum allowed failed-scenario ratio")
+	flag.DurationVar(&cfg.p95Budget, "p95-budget", 20*time.Second, "maximum allowed p95 latency of plan generation")
+	flag.Parse()
+
+	cfg.target = strings.TrimRight(cfg.target, "/")
+	if cfg.rps <= 0 {
+		log.Fatal("-rps must be greater than 0")
+	}
+	if cfg.maxInFlight < 1 {
+		log.Fatal("-max-in-flight must be at least 1")
+	}
+	if cfg.provider != "mock" && cfg.provider != "real" {
+		log.Fatal("-provider must be 'mock' or 'real'")
+	}
+	return cfg
+}
+
+func loadProfiles(path string) ([]recordedProfile, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var profiles []recordedProfile
+	if err := json.Unmarshal(raw, &profiles); err != nil {
+		return nil, err
+	}
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("no profiles in %s", path)
+	}
+	return profiles, nil
+}
+
+func login(client *http.Client, cfg config) (string, error) {
+	var out response_models.AccountLoginResponse
+	_, err := call(client, cfg.target+"/accounts/login", "", request_models.LoginRequest{
+		Email:    cfg.email,
+		Password: cfg.password,
+	}, &out)
+	if err != nil {
+		return "", err
+	}
+	if out.Token == "" {
+		return "", fmt.Errorf("empty token")
+	}
+	return out.Token, nil
+}
+
+// runScenario walks one profile through quiz/start -> quiz/answer (per question) -> quiz/plan-only.
+func runScenario(client *http.Client, cfg config, p recordedProfile, rec *recorder) {
+	atomic.AddInt64(&rec.scenarios, 1)
+	fail := func() { atomic.AddInt64(&rec.failed, 1) }
+
+	var quiz response_models.QuizResponse
+	lat, err := call(client, cfg.target+"/prompt/quiz/start", cfg.token, request_models.QuizStartRequest{UserID: "loadtest"}, &quiz)
+	rec.add(stepResult{step: "quiz/start", latency: lat, err: err})
+	if err != nil {
+		fail()
+		return
+	}
+
+	for step := 0; !quiz.IsComplete; step++ {
+		if step > len(p.Answers)+1 || len(quiz.Questions) == 0 {
+			rec.add(stepResult{step: "quiz/answer", err: fmt.Errorf("quiz did not complete")})
+			fail()
+			return
+		}
+		answers := make(map[string]string, len(quiz.Questions))
+		for _, q := range quiz.Questions {
+			answers[q.ID] = p.Answers[q.ID]
+		}
+		sessionID := quiz.SessionID
+		lat, err = call(client, cfg.target+"/prompt/quiz/answer", cfg.token, request_models.QuizRequest{
+			SessionID: sessionID,
+			Answers:   answers,
+		}, &quiz)
+		rec.add(stepResult{step: "quiz/answer", latency: lat, err: err})
+		if err != nil {
+			fail()
+			return
+		}
+		if quiz.SessionID == "" {
+			quiz.SessionID = sessionID
+		}
+	}
+
+	var plan response_models.PlanOnly
+	lat, err = call(client, cfg.target+"/prompt/quiz/plan-only", cfg.token, request_models.PlanOnlyRequest{SessionID: quiz.SessionID}, &plan)
+	if err == nil && len(plan.Days) == 0 {
+		err = fmt.Errorf("plan has no days")
+	}
+	rec.add(stepResult{step: "quiz/plan-only", latency: lat, err: err})
+	if err != nil {
+		fail()
+	}
+}
+
+// call posts a JSON body and decodes the APIResponse envelope. The API answers most
+// failures with HTTP 200 and the real code in the body, so both are checked.
+func call(client *http.Client, url, token string, body any, out any) (time.Duration, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return time.Since(start), fmt.Errorf("transport error")
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	latency := time.Since(start)
+	if err != nil {
+		return latency, fmt.Errorf("read body")
+	}
+
+	var env apiEnvelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return latency, fmt.Errorf("http %d: non-JSON body", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK || env.Status != "success" {
+		return latency, fmt.Errorf("http %d / code %d: %s", resp.StatusCode, env.Code, env.Message)
+	}
+	if out != nil && len(env.Data) > 0 {
+		if err := json.Unmarshal(env.Data, out); err != nil {
+			return latency, fmt.Errorf("decode data")
+		}
+	}
+	return latency, nil
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
+
+// report prints the summary and returns false when an error budget is exceeded.
+func report(w io.Writer, cfg config, rec *recorder, elapsed time.Duration) bool {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	fmt.Fprintf(w, "\n=== vivu load test (%s provider) ===\n", cfg.provider)
+	fmt.Fprintf(w, "elapsed: %s  scenarios: %d  failed: %d  dropped: %d\n\n",
+		elapsed.Round(time.Millisecond), rec.scenarios, rec.failed, rec.dropped)
+
+	steps := []string{"quiz/start", "quiz/answer", "quiz/plan-only"}
+	fmt.Fprintf(w, "%-16s %8s %8s %10s %10s %10s %10s\n", "step", "count", "errors", "p50", "p90", "p95", "p99")
+	for _, step := range steps {
+		lats := append([]time.Duration(nil), rec.latencies[step]...)
+		sort.Slice(lats, func(i, j int) bool { return lats[i] < lats[j] })
+		errCount := 0
+		for _, n := range rec.errors[step] {
+			errCount += n
+		}
+		fmt.Fprintf(w, "%-16s %8d %8d %10s %10s %10s %10s\n", step, len(lats), errCount,
+			percentile(lats, 50).Round(time.Millisecond),
+			percentile(lats, 90).Round(time.Millisecond),
+			percentile(lats, 95).Round(time.Millisecond),
+			percentile(lats, 99).Round(time.Millisecond))
+	}
+
+	for _, step := range steps {
+		for msg, n := range rec.errors[step] {
+			fmt.Fprintf(w, "  %s: %dx %s\n", step, n, msg)
+		}
+	}
+
+	ok := true
+	errRatio := 0.0
+	if rec.scenarios > 0 {
+		errRatio = float64(rec.failed) / float64(rec.scenarios)
+	}
+	fmt.Fprintf(w, "\nerror budget: %.2f%% used of %.2f%%", errRatio*100, cfg.errorBudget*100)
+	if errRatio > cfg.errorBudget {
+		fmt.Fprint(w, "  EXCEEDED")
+		ok = false
+	}
+
+	planLats := append([]time.Duration(nil), rec.latencies["quiz/plan-only"]...)
+	sort.Slice(planLats, func(i, j int) bool { return planLats[i] < planLats[j] })
+	p95 := percentile(planLats, 95)
+	fmt.Fprintf(w, "\nplan p95: %s (budget %s)", p95.Round(time.Millisecond), cfg.p95Budget)
+	if p95 > cfg.p95Budget {
+		fmt.Fprint(w, "  EXCEEDED")
+		ok = false
+	}
+	fmt.Fprintln(w)
+
+	return ok
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"net/http"
@@ -27,6 +28,7 @@ func NewAccountController(accountService services.AccountServiceInterface) *Acco
 // @Accept json
 // @Produce json
 // @Param request body request_models.SignUpRequest true "Account registration payload"
+// @Param X-Captcha-Token header string false "Turnstile/reCAPTCHA token, required when CAPTCHA is enabled"
 // @Success 200 {object} utils.APIResponse
 // @Failure 400 {object} utils.APIResponse
 // @Router /accounts/register [post]
@@ -47,13 +49,14 @@ func (a *AccountController) Register(c *gin.Context) {
 
 // Login godoc
 // @Summary Login to an account
-// @Description Authenticate a user and return a token
+// @Description Authenticate a user and return a token. Accounts with 2FA get a challengeToken instead, to send with a code to /accounts/2fa/challenge. Repeated failures lock the email or the client IP with a growing backoff (429 with Retry-After); the account owner is emailed an unlock link
 // @Tags Accounts
 // @Accept json
 // @Produce json
 // @Param request body request_models.LoginRequest true "Login payload"
 // @Success 200 {object} utils.APIResponse
 // @Failure 400 {object} utils.APIResponse
+// @Failure 429 {object} utils.APIResponse
 // @Router /accounts/login [post]
 func (a *AccountController) Login(c *gin.Context) {
 	var req request_models.LoginRequest
@@ -62,6 +65,9 @@ func (a *AccountController) Login(c *gin.Context) {
 		return
 	}
 
+	// Socket address unless the request came through a TRUSTED_PROXIES load balancer, so a
+	// forged X-Forwarded-For cannot reset the per-IP lockout
+	req.ClientIP = c.ClientIP()
 	ctx := context.Background()
 
 	token, err := a.accountService.Login(req, ctx)
@@ -70,6 +76,13 @@ func (a *AccountController) Login(c *gin.Context) {
 		return
 	}
 
+	if token.TwoFactorRequired {
+		utils.RespondSuccess(c,
+			gin.H{"twoFactorRequired": true, "challengeToken": token.ChallengeToken},
+			"Two-factor code required")
+		return
+	}
+
 	utils.RespondSuccess(c,
 		gin.H{"token": token.Token, "isUserHavePremium": token.IsUserHavePremium},
 		"Login successful")
@@ -82,6 +95,7 @@ func (a *AccountController) Login(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param request body request_models.RequestForgotPassword true "Forgot password payload"
+// @Param X-Captcha-Token header string false "Turnstile/reCAPTCHA token, required when CAPTCHA is enabled"
 // @Success 200 {object} utils.APIResponse
 // @Router /accounts/forgot-password [post]
 func (a *AccountController) ForgotPassword(c *gin.Context) {
@@ -180,6 +194,7 @@ func (a *AccountController) GetAllAccounts(c *gin.Context) {
 // @Success 200 {object} utils.APIResponse
 // @Security BearerAuth
 // @Router /accounts/profile [get]
+// @Router /accounts/me [get]
 func (a *AccountController) GetProfileInfo(c *gin.Context) {
 
 	userid := c.GetString("user_id")
@@ -199,3 +214,301 @@ func (a *AccountController) GetProfileInfo(c *gin.Context) {
 
 	utils.RespondSuccess(c, profile, "Profile info fetched successfully")
 }
+
+// UpdateProfile godoc
+// @Summary Update the signed-in account's profile
+// @Description Display name, avatar URL, locale (vi or en) and preferred currency (ISO 4217). Every field is replaced; send an optional one empty to reset it to the app default
+// @Tags Accounts
+// @Accept json
+// @Produce json
+// @Param request body request_models.UpdateProfileRequest true "Profile"
+// @Success 200 {object} utils.APIResponse
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /accounts/me [put]
+func (a *AccountController) UpdateProfile(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		utils.RespondError(c, http.StatusUnauthorized, "user_id is required")
+		return
+	}
+
+	var req request_models.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "name must be 3-50 characters, avatar_url a URL, locale vi or en and preferred_currency an ISO 4217 code")
+		return
+	}
+
+	profile, err := a.accountService.UpdateProfile(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, profile, "Profile updated successfully")
+}
+
+// ChangePassword godoc
+// @Summary Change the password while signed in
+// @Description Requires the current password. Wrong ones count as failed logins and can lock the account (429 with Retry-After). The owner is emailed about the change
+// @Tags Accounts
+// @Accept json
+// @Produce json
+// @Param request body request_models.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} utils.APIResponse
+// @Failure 400 {object} utils.APIResponse
+// @Failure 401 {object} utils.APIResponse
+// @Failure 429 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /accounts/change-password [post]
+func (a *AccountController) ChangePassword(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		utils.RespondError(c, http.StatusUnauthorized, "user_id is required")
+		return
+	}
+
+	var req request_models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "old_password is required and new_password must be at least 6 characters")
+		return
+	}
+
+	if err := a.accountService.ChangePassword(c.Request.Context(), userID, req); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Password changed successfully")
+}
+
+// UpdateWorkingWindow godoc
+// @Summary Set the traveler's day window
+// @Description Earliest start and latest end (HH:MM) that generated plans and manual edits must respect. Send both empty to reset to 09:00-21:00.
+// @Tags Accounts
+// @Accept json
+// @Produce json
+// @Param request body request_models.UpdateWorkingWindowRequest true "Day window"
+// @Success 200 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /accounts/preferences/working-window [put]
+func (a *AccountController) UpdateWorkingWindow(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		utils.RespondError(c, http.StatusUnauthorized, "user_id is required")
+		return
+	}
+
+	var req request_models.UpdateWorkingWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	profile, err := a.accountService.UpdateWorkingWindow(c.Request.Context(), userID, req)
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidInput) {
+			utils.RespondError(c, http.StatusBadRequest, "day_start and day_end must be HH:MM on the same day, at least 2 hours apart")
+			return
+		}
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, profile, "Working window updated successfully")
+}
+
+// UpdateCompanions godoc
+// @Summary Set the traveler's companions
+// @Description Partner, kids (with ages), parents... Plans generated from the quiz adapt pacing, places and tips to them. Send an empty list to clear.
+// @Tags Accounts
+// @Accept json
+// @Produce json
+// @Param request body request_models.UpdateCompanionsRequest true "Companions"
+// @Success 200 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /accounts/preferences/companions [put]
+func (a *AccountController) UpdateCompanions(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		utils.RespondError(c, http.StatusUnauthorized, "user_id is required")
+		return
+	}
+
+	var req request_models.UpdateCompanionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "At most 12 companions; relation must be partner, child, parent, friend or other and age 0-120")
+		return
+	}
+
+	profile, err := a.accountService.UpdateCompanions(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, profile, "Companions updated successfully")
+}
+
+// UpdateAvoid godoc
+// @Summary Set the places the traveler never wants in a plan
+// @Description Quiz options ("Museums", "Temples & pagodas", "Nightlife & bars"...) or free words ("karaoke"). Generated plans never include matching places. Send an empty list to clear.
+// @Tags Accounts
+// @Accept json
+// @Produce json
+// @Param request body request_models.UpdateAvoidRequest true "Places to avoid"
+// @Success 200 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /accounts/preferences/avoid [put]
+func (a *AccountController) UpdateAvoid(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		utils.RespondError(c, http.StatusUnauthorized, "user_id is required")
+		return
+	}
+
+	var req request_models.UpdateAvoidRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "At most 20 entries of up to 40 characters each")
+		return
+	}
+
+	profile, err := a.accountService.UpdateAvoid(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, profile, "Places to avoid updated successfully")
+}
+
+// UpdateTripEmails godoc
+// @Summary Turn the pre-trip emails on or off for the whole account
+// @Description Packing list, weather check, itinerary summary and day-of emails of every journey. Each journey can also be switched off on its own
+// @Tags Accounts
+// @Accept json
+// @Produce json
+// @Param request body request_models.UpdateTripEmailsRequest true "Enabled"
+// @Success 200 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /accounts/preferences/trip-emails [put]
+func (a *AccountController) UpdateTripEmails(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		utils.RespondError(c, http.StatusUnauthorized, "user_id is required")
+		return
+	}
+
+	var req request_models.UpdateTripEmailsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "enabled is required")
+		return
+	}
+
+	profile, err := a.accountService.UpdateTripEmails(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, profile, "Trip emails updated successfully")
+}
+
+// SetupTwoFactor godoc
+// @Summary Start two-factor authentication setup
+// @Description Create a TOTP secret and 10 single-use backup codes. Scan the provisioning URI with an authenticator app, then confirm with /accounts/2fa/verify; 2FA is not enforced before that. The secret and codes are only shown once
+// @Tags Accounts
+// @Produce json
+// @Success 200 {object} response_models.TwoFactorSetupResponse
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /accounts/2fa/setup [post]
+func (a *AccountController) SetupTwoFactor(c *gin.Context) {
+	setup, err := a.accountService.SetupTwoFactor(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, setup, "Scan the code with your authenticator app, then verify it")
+}
+
+// VerifyTwoFactor godoc
+// @Summary Turn on two-factor authentication
+// @Description Confirm the setup with a code from the authenticator app; later logins then require a code
+// @Tags Accounts
+// @Accept json
+// @Produce json
+// @Param request body request_models.TwoFactorVerifyRequest true "Code"
+// @Success 200 {object} utils.APIResponse
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /accounts/2fa/verify [post]
+func (a *AccountController) VerifyTwoFactor(c *gin.Context) {
+	var req request_models.TwoFactorVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "code is required")
+		return
+	}
+
+	if err := a.accountService.VerifyTwoFactor(c.Request.Context(), c.GetString("user_id"), req); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Two-factor authentication enabled")
+}
+
+// TwoFactorChallenge godoc
+// @Summary Complete a login with a two-factor code
+// @Description Exchange the challengeToken of /accounts/login (valid 5 minutes) and a code from the authenticator app, or a backup code, for an access token
+// @Tags Accounts
+// @Accept json
+// @Produce json
+// @Param request body request_models.TwoFactorChallengeRequest true "Challenge and code"
+// @Success 200 {object} utils.APIResponse
+// @Failure 400 {object} utils.APIResponse
+// @Router /accounts/2fa/challenge [post]
+func (a *AccountController) TwoFactorChallenge(c *gin.Context) {
+	var req request_models.TwoFactorChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "challenge_token and code are required")
+		return
+	}
+
+	token, err := a.accountService.CompleteTwoFactorLogin(c.Request.Context(), req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c,
+		gin.H{"token": token.Token, "isUserHavePremium": token.IsUserHavePremium},
+		"Login successful")
+}
+
+// UnlockLogin godoc
+// @Summary Unlock an account locked after failed logins
+// @Description Redeem the email and token of the unlock link sent when an account is locked. The link works once, for the current lockout
+// @Tags Accounts
+// @Accept json
+// @Produce json
+// @Param request body request_models.UnlockLoginRequest true "Unlock payload"
+// @Success 200 {object} utils.APIResponse
+// @Failure 400 {object} utils.APIResponse
+// @Failure 401 {object} utils.APIResponse
+// @Router /accounts/unlock [post]
+func (a *AccountController) UnlockLogin(c *gin.Context) {
+	var req request_models.UnlockLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "email and token are required")
+		return
+	}
+
+	if err := a.accountService.UnlockLogin(c.Request.Context(), req); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Account unlocked, you can log in again")
+}
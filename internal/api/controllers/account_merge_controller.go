@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type AccountMergeController struct {
+	mergeService services.AccountMergeServiceInterface
+}
+
+func NewAccountMergeController(mergeService services.AccountMergeServiceInterface) *AccountMergeController {
+	return &AccountMergeController{mergeService: mergeService}
+}
+
+// MergeAccounts godoc
+// @Summary Merge duplicate accounts
+// @Description Reassign journeys, check-ins, subscriptions, transactions and feedback from the source account to the target and remove the source. Use dry_run to preview (admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body request_models.MergeAccountsRequest true "Merge payload"
+// @Success 200 {object} response_models.AccountMergeReport
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/accounts/merge [post]
+func (a *AccountMergeController) MergeAccounts(c *gin.Context) {
+	var req request_models.MergeAccountsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "source_account_id and target_account_id must be valid UUIDs")
+		return
+	}
+
+	report, err := a.mergeService.MergeAccounts(c.Request.Context(), req, c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	msg := "Accounts merged successfully"
+	if report.DryRun {
+		msg = "Dry run completed, nothing was changed"
+	}
+	utils.RespondSuccess(c, report, msg)
+}
@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type AdminAccountController struct {
+	accountService services.AccountServiceInterface
+}
+
+func NewAdminAccountController(accountService services.AccountServiceInterface) *AdminAccountController {
+	return &AdminAccountController{accountService: accountService}
+}
+
+// ListAccounts godoc
+// @Summary List accounts
+// @Description Accounts newest first, filtered by role, status, subscription and a search on email or name (admin only)
+// @Tags Admin
+// @Produce json
+// @Param role query string false "user or admin"
+// @Param status query string false "active or banned"
+// @Param subscription query string false "active (with an active subscription) or none"
+// @Param q query string false "Part of the email or name"
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(20) minimum(1) maximum(100)
+// @Success 200 {object} response_models.AdminAccountPage
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/accounts [get]
+func (a *AdminAccountController) ListAccounts(c *gin.Context) {
+	var query request_models.AdminAccountQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+
+	page, err := a.accountService.ListAccounts(c.Request.Context(), query)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, page, "Accounts fetched successfully")
+}
+
+// BanAccount godoc
+// @Summary Ban an account
+// @Description Suspend an account: it cannot log in and its current tokens stop working. Admin accounts cannot be banned (admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param request body request_models.BanAccountRequest false "Reason"
+// @Success 200 {object} utils.APIResponse
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/accounts/{id}/ban [post]
+func (a *AdminAccountController) BanAccount(c *gin.Context) {
+	accountID := c.Param("id")
+	if _, err := uuid.Parse(accountID); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid account ID")
+		return
+	}
+
+	var req request_models.BanAccountRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "reason must be at most 500 characters")
+			return
+		}
+	}
+
+	if err := a.accountService.BanAccount(c.Request.Context(), c.GetString("user_id"), accountID, req); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Account banned")
+}
+
+// UnbanAccount godoc
+// @Summary Lift a ban
+// @Description The account can log in again; tokens issued before the ban stay invalid (admin only)
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/accounts/{id}/unban [post]
+func (a *AdminAccountController) UnbanAccount(c *gin.Context) {
+	accountID := c.Param("id")
+	if _, err := uuid.Parse(accountID); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid account ID")
+		return
+	}
+
+	if err := a.accountService.UnbanAccount(c.Request.Context(), accountID); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Account unbanned")
+}
+
+// ForceLogout godoc
+// @Summary Log an account out everywhere
+// @Description Every token issued to the account so far stops working; it has to log in again (admin only)
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/accounts/{id}/logout [post]
+func (a *AdminAccountController) ForceLogout(c *gin.Context) {
+	accountID := c.Param("id")
+	if _, err := uuid.Parse(accountID); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid account ID")
+		return
+	}
+
+	if err := a.accountService.ForceLogout(c.Request.Context(), accountID); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Account logged out everywhere")
+}
@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type AnnouncementController struct {
+	announcementService services.AnnouncementServiceInterface
+}
+
+func NewAnnouncementController(announcementService services.AnnouncementServiceInterface) *AnnouncementController {
+	return &AnnouncementController{announcementService: announcementService}
+}
+
+// GetActive godoc
+// @Summary Announcements to show now
+// @Description Banners running now for the caller: the token, when sent, targets by subscription tier and account locale. Stays reachable during maintenance
+// @Tags Announcements
+// @Produce json
+// @Param locale query string false "vi | en; defaults to the account's"
+// @Param app_version query string false "App version, e.g. 1.4.2; also read from X-App-Version"
+// @Success 200 {array} response_models.Announcement
+// @Failure 400 {object} utils.APIResponse
+// @Router /announcements/active [get]
+func (a *AnnouncementController) GetActive(c *gin.Context) {
+	var query request_models.ActiveAnnouncementsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+	if query.AppVersion == "" {
+		query.AppVersion = c.GetHeader("X-App-Version")
+	}
+
+	announcements, err := a.announcementService.Active(c.Request.Context(), c.GetString("user_id"), query.Locale, query.AppVersion)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, announcements, "Announcements fetched successfully")
+}
+
+// ListAnnouncements godoc
+// @Summary List announcements
+// @Description Every announcement, newest start first, with its targeting and status (admin only)
+// @Tags Admin
+// @Produce json
+// @Param page query int false "Page" default(1)
+// @Param pageSize query int false "Page size" default(20)
+// @Success 200 {object} response_models.AdminAnnouncementPage
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/announcements [get]
+func (a *AnnouncementController) ListAnnouncements(c *gin.Context) {
+	var query request_models.AnnouncementListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+
+	page, err := a.announcementService.List(c.Request.Context(), query)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, page, "Announcements fetched successfully")
+}
+
+// GetAnnouncement godoc
+// @Summary Get an announcement
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Announcement ID"
+// @Success 200 {object} response_models.AdminAnnouncement
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/announcements/{id} [get]
+func (a *AnnouncementController) GetAnnouncement(c *gin.Context) {
+	announcement, err := a.announcementService.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, announcement, "Announcement fetched successfully")
+}
+
+// CreateAnnouncement godoc
+// @Summary Create an announcement
+// @Description Empty tiers (guest, free, premium) and locales target everyone; app versions are inclusive bounds. Shown between starts_at and ends_at while active (admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body request_models.AnnouncementRequest true "Announcement"
+// @Success 200 {object} response_models.AdminAnnouncement
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/announcements [post]
+func (a *AnnouncementController) CreateAnnouncement(c *gin.Context) {
+	var req request_models.AnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	announcement, err := a.announcementService.Create(c.Request.Context(), req, c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, announcement, "Announcement created successfully")
+}
+
+// UpdateAnnouncement godoc
+// @Summary Replace an announcement
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Announcement ID"
+// @Param request body request_models.AnnouncementRequest true "Announcement"
+// @Success 200 {object} response_models.AdminAnnouncement
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/announcements/{id} [put]
+func (a *AnnouncementController) UpdateAnnouncement(c *gin.Context) {
+	var req request_models.AnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	announcement, err := a.announcementService.Update(c.Request.Context(), c.Param("id"), req, c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, announcement, "Announcement updated successfully")
+}
+
+// DeleteAnnouncement godoc
+// @Summary Delete an announcement
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Announcement ID"
+// @Success 200 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/announcements/{id} [delete]
+func (a *AnnouncementController) DeleteAnnouncement(c *gin.Context) {
+	if err := a.announcementService.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Announcement deleted successfully")
+}
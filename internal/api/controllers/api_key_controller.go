@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type APIKeyController struct {
+	apiKeyService services.APIKeyServiceInterface
+}
+
+func NewAPIKeyController(apiKeyService services.APIKeyServiceInterface) *APIKeyController {
+	return &APIKeyController{apiKeyService: apiKeyService}
+}
+
+// ListKeys godoc
+// @Summary List partner API keys
+// @Description API keys newest first, optionally of one account. Secrets are never listed (admin only)
+// @Tags Admin
+// @Produce json
+// @Param account_id query string false "Account ID"
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(20) minimum(1) maximum(100)
+// @Success 200 {object} response_models.APIKeyPage
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/api-keys [get]
+func (a *APIKeyController) ListKeys(c *gin.Context) {
+	var query request_models.APIKeyQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+
+	page, err := a.apiKeyService.ListKeys(c.Request.Context(), query)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, page, "API keys fetched successfully")
+}
+
+// IssueKey godoc
+// @Summary Issue a partner API key
+// @Description Create a key acting for an account, sent as X-API-Key. Scopes: pois:read, provinces:read, plans:generate, planning_policy:write. daily_quota caps the calls per day (Vietnam time), over it calls get 429; without it API_KEY_DAILY_QUOTA applies. The key is only shown in this response (admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body request_models.CreateAPIKeyRequest true "Key"
+// @Success 200 {object} response_models.IssuedAPIKey
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/api-keys [post]
+func (a *APIKeyController) IssueKey(c *gin.Context) {
+	var req request_models.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "account_id, name and at least one scope (pois:read, provinces:read, plans:generate, planning_policy:write) are required")
+		return
+	}
+
+	key, err := a.apiKeyService.IssueKey(c.Request.Context(), req, c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, key, "API key issued successfully")
+}
+
+// RotateKey godoc
+// @Summary Rotate a partner API key
+// @Description Replace the secret of a key, keeping its scopes; the old secret stops working at once. The new key is only shown in this response (admin only)
+// @Tags Admin
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} response_models.IssuedAPIKey
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/api-keys/{id}/rotate [post]
+func (a *APIKeyController) RotateKey(c *gin.Context) {
+	key, err := a.apiKeyService.RotateKey(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, key, "API key rotated successfully")
+}
+
+// RevokeKey godoc
+// @Summary Revoke a partner API key
+// @Description The key stops working; it stays listed as revoked (admin only)
+// @Tags Admin
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} utils.APIResponse
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/api-keys/{id} [delete]
+func (a *APIKeyController) RevokeKey(c *gin.Context) {
+	if err := a.apiKeyService.RevokeKey(c.Request.Context(), c.Param("id")); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "API key revoked successfully")
+}
@@ -0,0 +1,239 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type BillingController struct {
+	billingService services.BillingServiceInterface
+}
+
+func NewBillingController(billingService services.BillingServiceInterface) *BillingController {
+	return &BillingController{billingService: billingService}
+}
+
+// GetOverview godoc
+// @Summary Billing overview
+// @Description Everything the billing screen shows: the running subscription, the next renewal, the last payments, the plans to change to and which controls apply
+// @Tags Billing
+// @Produce json
+// @Success 200 {object} response_models.BillingOverview
+// @Failure 401 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /billing [get]
+func (b *BillingController) GetOverview(c *gin.Context) {
+	overview, err := b.billingService.GetOverview(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, overview, "Billing overview fetched successfully")
+}
+
+// ListPayments godoc
+// @Summary Payment history
+// @Description Payments of the current account, newest first, failed and pending ones included
+// @Tags Billing
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(20) minimum(1) maximum(100)
+// @Success 200 {object} response_models.BillingPaymentPage
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /billing/payments [get]
+func (b *BillingController) ListPayments(c *gin.Context) {
+	var query request_models.BillingPageQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+
+	page, err := b.billingService.ListPayments(c.Request.Context(), c.GetString("user_id"), query)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, page, "Payments fetched successfully")
+}
+
+// ListTransactions godoc
+// @Summary My transactions
+// @Description Payments of the current account, newest first, narrowed by status and by the date they were made (Vietnam time, both ends included)
+// @Tags Payments
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(20) minimum(1) maximum(100)
+// @Param status query []string false "pending, paid, failed or refunded; repeat for several" collectionFormat(multi)
+// @Param from query string false "First day, YYYY-MM-DD"
+// @Param to query string false "Last day, YYYY-MM-DD"
+// @Success 200 {object} response_models.BillingPaymentPage
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /payments/my-transactions [get]
+func (b *BillingController) ListTransactions(c *gin.Context) {
+	var query request_models.TransactionHistoryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid query parameters: status takes pending, paid, failed or refunded and dates are YYYY-MM-DD")
+		return
+	}
+
+	page, err := b.billingService.ListTransactions(c.Request.Context(), c.GetString("user_id"), query)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, page, "Transactions fetched successfully")
+}
+
+// ListInvoices godoc
+// @Summary Invoices
+// @Description One invoice per paid or refunded payment, newest first
+// @Tags Billing
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(20) minimum(1) maximum(100)
+// @Success 200 {object} response_models.InvoicePage
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /billing/invoices [get]
+func (b *BillingController) ListInvoices(c *gin.Context) {
+	var query request_models.BillingPageQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+
+	page, err := b.billingService.ListInvoices(c.Request.Context(), c.GetString("user_id"), query)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, page, "Invoices fetched successfully")
+}
+
+// GetInvoice godoc
+// @Summary Invoice of a payment
+// @Tags Billing
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Success 200 {object} response_models.Invoice
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /billing/invoices/{id} [get]
+func (b *BillingController) GetInvoice(c *gin.Context) {
+	invoice, err := b.billingService.GetInvoice(c.Request.Context(), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, invoice, "Invoice fetched successfully")
+}
+
+// GetReceipt godoc
+// @Summary Receipt of a payment as PDF
+// @Description Render the invoice of a paid payment (plan, amount, currency, VAT and the buyer) as a PDF file
+// @Tags Payments
+// @Produce application/pdf
+// @Param id path string true "Payment ID"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /payments/transactions/{id}/receipt [get]
+func (b *BillingController) GetReceipt(c *gin.Context) {
+	data, fileName, err := b.billingService.GetReceipt(c.Request.Context(), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Data(http.StatusOK, "application/pdf", data)
+}
+
+// CancelSubscription godoc
+// @Summary Cancel the subscription
+// @Description Turns renewal off; the plan keeps running until the end of the paid period
+// @Tags Billing
+// @Produce json
+// @Success 200 {object} response_models.BillingOverview
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /billing/cancel [post]
+func (b *BillingController) CancelSubscription(c *gin.Context) {
+	overview, err := b.billingService.SetAutoRenew(c.Request.Context(), c.GetString("user_id"), false)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, overview, "Subscription canceled; it stays active until the end of the period")
+}
+
+// SetAutoRenew godoc
+// @Summary Turn renewal on or off
+// @Tags Billing
+// @Accept json
+// @Produce json
+// @Param request body request_models.SetAutoRenewRequest true "Enabled"
+// @Success 200 {object} response_models.BillingOverview
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /billing/auto-renew [put]
+func (b *BillingController) SetAutoRenew(c *gin.Context) {
+	var req request_models.SetAutoRenewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "enabled is required")
+		return
+	}
+
+	overview, err := b.billingService.SetAutoRenew(c.Request.Context(), c.GetString("user_id"), *req.Enabled)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, overview, "Auto-renew updated successfully")
+}
+
+// ChangePlan godoc
+// @Summary Change plan
+// @Description Starts a checkout for another plan; with renewal on it takes over when the running subscription ends (see effective_at in the plan options)
+// @Tags Billing
+// @Accept json
+// @Produce json
+// @Param request body request_models.ChangePlanRequest true "Plan"
+// @Success 200 {object} response_models.CreateCheckoutResponse
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Failure 503 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /billing/change-plan [post]
+func (b *BillingController) ChangePlan(c *gin.Context) {
+	var req request_models.ChangePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "plan_code is required")
+		return
+	}
+
+	checkout, err := b.billingService.ChangePlan(c.Request.Context(), c.GetString("user_id"), req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, checkout, "Checkout created successfully")
+}
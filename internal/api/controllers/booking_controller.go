@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type BookingController struct {
+	bookingService services.BookingServiceInterface
+}
+
+func NewBookingController(bookingService services.BookingServiceInterface) *BookingController {
+	return &BookingController{bookingService: bookingService}
+}
+
+// SetBookingLink godoc
+// @Summary Set a partner booking link of a POI
+// @Description Create or replace the booking link of a bookable POI (tour, show...) for one partner. deep_link_params are added to the URL query and may use {date}, {time} and {ref} (admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "POI ID"
+// @Param request body request_models.SetBookingLinkRequest true "Booking link"
+// @Success 200 {object} response_models.BookingLink
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/pois/{id}/booking-links [put]
+func (b *BookingController) SetBookingLink(c *gin.Context) {
+	var req request_models.SetBookingLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "partner and a valid url are required")
+		return
+	}
+
+	link, err := b.bookingService.SetLink(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, link, "Booking link saved")
+}
+
+// DeleteBookingLink godoc
+// @Summary Remove a partner booking link of a POI
+// @Tags Admin
+// @Produce json
+// @Param id path string true "POI ID"
+// @Param partner path string true "Partner"
+// @Success 200 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/pois/{id}/booking-links/{partner} [delete]
+func (b *BookingController) DeleteBookingLink(c *gin.Context) {
+	if err := b.bookingService.DeleteLink(c.Request.Context(), c.Param("id"), c.Param("partner")); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Booking link removed")
+}
+
+// ListBookingLinks godoc
+// @Summary List the booking partners of a POI
+// @Description An empty list means the POI cannot be booked online
+// @Tags POIs
+// @Produce json
+// @Param id path string true "POI ID"
+// @Success 200 {array} response_models.BookingLink
+// @Failure 400 {object} utils.APIResponse
+// @Security PartnerAPIKey
+// @Router /pois/{id}/booking-links [get]
+func (b *BookingController) ListBookingLinks(c *gin.Context) {
+	links, err := b.bookingService.ListLinks(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, links, "Booking links retrieved")
+}
+
+// BookActivity godoc
+// @Summary Hand an activity off to a booking partner
+// @Description Record a "book" event with partner attribution and return the partner URL to open. The activity's booking status becomes pending unless it is already confirmed
+// @Tags Journey
+// @Accept json
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Param activityId path string true "Activity ID"
+// @Param request body request_models.BookActivityRequest false "Partner (optional)"
+// @Success 200 {object} response_models.BookingHandoff
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/activities/{activityId}/book [post]
+func (b *BookingController) BookActivity(c *gin.Context) {
+	var req request_models.BookActivityRequest
+	// The body is optional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "Invalid request format")
+			return
+		}
+	}
+
+	handoff, err := b.bookingService.BookActivity(c.Request.Context(), c.GetString("user_id"), c.Param("journeyId"), c.Param("activityId"), req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, handoff, "Booking hand-off created")
+}
+
+// UpdateBookingStatus godoc
+// @Summary Update the booking status of an activity
+// @Description Mark the reservation of an activity as pending, confirmed or cancelled, with the partner's reference
+// @Tags Journey
+// @Accept json
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Param activityId path string true "Activity ID"
+// @Param request body request_models.UpdateBookingStatusRequest true "Booking status"
+// @Success 200 {object} response_models.ActivityBooking
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/activities/{activityId}/booking [put]
+func (b *BookingController) UpdateBookingStatus(c *gin.Context) {
+	var req request_models.UpdateBookingStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "status must be pending, confirmed or cancelled")
+		return
+	}
+
+	booking, err := b.bookingService.UpdateBookingStatus(c.Request.Context(), c.GetString("user_id"), c.Param("journeyId"), c.Param("activityId"), req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, booking, "Booking status updated")
+}
@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type ChurnController struct {
+	churnService services.ChurnServiceInterface
+}
+
+func NewChurnController(churnService services.ChurnServiceInterface) *ChurnController {
+	return &ChurnController{churnService: churnService}
+}
+
+// GetOverview godoc
+// @Summary Churn-risk segments
+// @Description How many accounts are at low, medium and high risk after the last scoring run, and how many are in a win-back sequence (admin only)
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} response_models.ChurnOverview
+// @Failure 401 {object} utils.APIResponse
+// @Failure 403 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/churn [get]
+func (ch *ChurnController) GetOverview(c *gin.Context) {
+	overview, err := ch.churnService.Overview(c.Request.Context())
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, overview, "Churn segments fetched successfully")
+}
+
+// ListAccounts godoc
+// @Summary List scored accounts
+// @Description Accounts with their churn-risk score and reasons, riskiest first (admin only)
+// @Tags Admin
+// @Produce json
+// @Param segment query string false "low | medium | high"
+// @Param page query int false "Page" default(1)
+// @Param pageSize query int false "Page size" default(20)
+// @Success 200 {object} response_models.ChurnAccountPage
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/churn/accounts [get]
+func (ch *ChurnController) ListAccounts(c *gin.Context) {
+	var query request_models.ChurnAccountQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+
+	page, err := ch.churnService.ListAccounts(c.Request.Context(), query)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, page, "Accounts fetched successfully")
+}
+
+// ScoreNow godoc
+// @Summary Rescore every account now
+// @Description Runs the scheduled churn scoring right away and starts the win-back campaigns of the accounts that entered a segment (admin only)
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} response_models.ChurnRun
+// @Failure 401 {object} utils.APIResponse
+// @Failure 403 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/churn/score [post]
+func (ch *ChurnController) ScoreNow(c *gin.Context) {
+	run, err := ch.churnService.ScoreAll(c.Request.Context())
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, run, "Accounts scored successfully")
+}
+
+// ListCampaigns godoc
+// @Summary List win-back campaigns
+// @Description The email sequence of the medium and high segments (admin only)
+// @Tags Admin
+// @Produce json
+// @Success 200 {array} response_models.WinbackCampaign
+// @Failure 401 {object} utils.APIResponse
+// @Failure 403 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/churn/campaigns [get]
+func (ch *ChurnController) ListCampaigns(c *gin.Context) {
+	campaigns, err := ch.churnService.ListCampaigns(c.Request.Context())
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, campaigns, "Campaigns fetched successfully")
+}
+
+// SetCampaign godoc
+// @Summary Set the win-back campaign of a segment
+// @Description Replaces the email sequence of the medium or high segment. Each step waits delay_days after the previous one; "{name}" in the subject and body is replaced by the account's name. Emails go through the mail outbox (admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param segment path string true "medium | high"
+// @Param request body request_models.SetWinbackCampaignRequest true "Campaign"
+// @Success 200 {object} response_models.WinbackCampaign
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/churn/campaigns/{segment} [put]
+func (ch *ChurnController) SetCampaign(c *gin.Context) {
+	var req request_models.SetWinbackCampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	campaign, err := ch.churnService.SetCampaign(c.Request.Context(), c.Param("segment"), req, c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, campaign, "Campaign saved successfully")
+}
@@ -0,0 +1,207 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type CollectionController struct {
+	collectionService services.CollectionServiceInterface
+}
+
+func NewCollectionController(collectionService services.CollectionServiceInterface) *CollectionController {
+	return &CollectionController{collectionService: collectionService}
+}
+
+// ListCollections godoc
+// @Summary List curated collections
+// @Description Published collections of POIs ("Best cafés in Da Lat"), newest first, optionally of one province
+// @Tags Collections
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(20)
+// @Param province_id query string false "Province ID"
+// @Success 200 {object} response_models.CollectionPage
+// @Failure 400 {object} utils.APIResponse
+// @Security PartnerAPIKey
+// @Router /collections [get]
+func (cc *CollectionController) ListCollections(c *gin.Context) {
+	cc.listCollections(c, true)
+}
+
+// GetCollection godoc
+// @Summary Get a curated collection
+// @Description A published collection with its POIs in the curated order
+// @Tags Collections
+// @Produce json
+// @Param idOrSlug path string true "Collection ID or slug"
+// @Success 200 {object} response_models.CollectionDetail
+// @Failure 404 {object} utils.APIResponse
+// @Security PartnerAPIKey
+// @Router /collections/{idOrSlug} [get]
+func (cc *CollectionController) GetCollection(c *gin.Context) {
+	collection, err := cc.collectionService.GetCollection(c.Request.Context(), c.Param("idOrSlug"), true)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, collection, "Collection fetched successfully")
+}
+
+// AdminListCollections godoc
+// @Summary List all collections
+// @Description Collections including unpublished ones (admin only)
+// @Tags Admin
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(20)
+// @Param province_id query string false "Province ID"
+// @Success 200 {object} response_models.CollectionPage
+// @Security BearerAuth
+// @Router /admin/collections [get]
+func (cc *CollectionController) AdminListCollections(c *gin.Context) {
+	cc.listCollections(c, false)
+}
+
+func (cc *CollectionController) listCollections(c *gin.Context, publishedOnly bool) {
+	var query request_models.CollectionListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "page must be at least 1, pageSize 1-100 and province_id a UUID")
+		return
+	}
+
+	page, err := cc.collectionService.ListCollections(c.Request.Context(), query, publishedOnly)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, page, "Collections fetched successfully")
+}
+
+// AdminGetCollection godoc
+// @Summary Get a collection for editing
+// @Description A collection, published or not, with all its POIs (admin only)
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Collection ID or slug"
+// @Success 200 {object} response_models.CollectionDetail
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/collections/{id} [get]
+func (cc *CollectionController) AdminGetCollection(c *gin.Context) {
+	collection, err := cc.collectionService.GetCollection(c.Request.Context(), c.Param("id"), false)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, collection, "Collection fetched successfully")
+}
+
+// CreateCollection godoc
+// @Summary Create a collection
+// @Description POIs are listed in the order given and must not be drafts; the slug is made from the title when left out (admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body request_models.SaveCollectionRequest true "Collection"
+// @Success 200 {object} response_models.CollectionDetail
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/collections [post]
+func (cc *CollectionController) CreateCollection(c *gin.Context) {
+	cc.saveCollection(c, "")
+}
+
+// UpdateCollection godoc
+// @Summary Replace a collection
+// @Description Replaces the collection's fields and its POI list (admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Param request body request_models.SaveCollectionRequest true "Collection"
+// @Success 200 {object} response_models.CollectionDetail
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/collections/{id} [put]
+func (cc *CollectionController) UpdateCollection(c *gin.Context) {
+	cc.saveCollection(c, c.Param("id"))
+}
+
+func (cc *CollectionController) saveCollection(c *gin.Context, id string) {
+	var req request_models.SaveCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "title is required (at most 200 characters), slug takes at most 120, cover_image_url must be a URL and pois at most 50 entries with a poi_id, a note of at most 300 characters and duration_minutes 0-600")
+		return
+	}
+
+	collection, err := cc.collectionService.SaveCollection(c.Request.Context(), id, req, c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, collection, "Collection saved")
+}
+
+// DeleteCollection godoc
+// @Summary Delete a collection
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Collection ID"
+// @Success 200 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/collections/{id} [delete]
+func (cc *CollectionController) DeleteCollection(c *gin.Context) {
+	if err := cc.collectionService.DeleteCollection(c.Request.Context(), c.Param("id")); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Collection deleted")
+}
+
+// SeedDay godoc
+// @Summary Fill a journey day from a collection
+// @Description Plans the day with the places of a published collection in its order, timed around travel and opening hours. Places that do not fit are listed in skipped. A day that already has activities is only replaced when replace is true
+// @Tags Journey
+// @Accept json
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Param request body request_models.SeedDayFromCollectionRequest true "Day and collection"
+// @Success 200 {object} response_models.SeedDayResponse
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Failure 409 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/seed-day [post]
+func (cc *CollectionController) SeedDay(c *gin.Context) {
+	journeyId := c.Param("journeyId")
+	if _, err := uuid.Parse(journeyId); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid journey ID")
+		return
+	}
+
+	var req request_models.SeedDayFromCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "day_number and collection are required")
+		return
+	}
+
+	result, err := cc.collectionService.SeedJourneyDay(c.Request.Context(), journeyId, c.GetString("user_id"), req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, result, "Day planned from the collection")
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type CouponController struct {
+	couponService services.CouponServiceInterface
+}
+
+func NewCouponController(couponService services.CouponServiceInterface) *CouponController {
+	return &CouponController{couponService: couponService}
+}
+
+// ValidateCoupon godoc
+// @Summary Check a coupon code
+// @Description Tells whether the coupon can be used now; with plan_code, what the plan comes to with it
+// @Tags Payments
+// @Produce json
+// @Param code path string true "Coupon code"
+// @Param plan_code query string false "Plan code"
+// @Success 200 {object} response_models.Coupon
+// @Failure 404 {object} utils.APIResponse
+// @Failure 422 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /payments/coupons/{code} [get]
+func (cc *CouponController) ValidateCoupon(c *gin.Context) {
+	var q request_models.CouponQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+
+	coupon, err := cc.couponService.Validate(c.Request.Context(), c.Param("code"), q.PlanCode)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, coupon, "Coupon is valid")
+}
+
+// ListCoupons godoc
+// @Summary List coupons
+// @Description Every coupon with its redemptions, active ones first (admin only)
+// @Tags Admin
+// @Produce json
+// @Success 200 {array} response_models.AdminCoupon
+// @Security BearerAuth
+// @Router /admin/coupons [get]
+func (cc *CouponController) ListCoupons(c *gin.Context) {
+	coupons, err := cc.couponService.ListCoupons(c.Request.Context())
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, coupons, "Coupons retrieved successfully")
+}
+
+// CreateCoupon godoc
+// @Summary Create a coupon
+// @Description Either percent_off or amount_off_minor with its currency; plan_codes limits it to those plans (admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body request_models.CreateCouponRequest true "Coupon"
+// @Success 200 {object} response_models.AdminCoupon
+// @Failure 400 {object} utils.APIResponse
+// @Failure 409 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/coupons [post]
+func (cc *CouponController) CreateCoupon(c *gin.Context) {
+	var req request_models.CreateCouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	coupon, err := cc.couponService.CreateCoupon(c.Request.Context(), req, c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, coupon, "Coupon created successfully")
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 	"vivu/internal/models/response_models"
 	"vivu/internal/services"
@@ -29,8 +30,10 @@ func NewDashboardController(dashboardService services.DashboardService) *Dashboa
 // @Param start    query string false "RFC3339 start (e.g. 2025-10-01T00:00:00Z)"
 // @Param end      query string false "RFC3339 end   (e.g. 2025-10-19T23:59:59Z)"
 // @Param last_days query int   false "Relative lookback in days (mutually exclusive with start/end). Default 30"
+// @Param preset   query string false "Named range resolved in tz: mtd | qtd | ytd (mutually exclusive with start/end/last_days)"
+// @Param compare  query string false "Compare with previous_period (default) | previous_year | none and return deltas in percent"
 // @Param interval query string false "Bucket size: day | week | month (default: day)"
-// @Param tz       query string false "IANA timezone for bucketing (default: Asia/Ho_Chi_Minh)"
+// @Param tz       query string false "IANA timezone for bucketing and presets (default: Asia/Ho_Chi_Minh)"
 // @Param currency query string false "ISO 4217 currency code for labeling (default: VND)"
 // @Success 200 {object} utils.APIResponse
 // @Failure 400 {object} utils.APIResponse
@@ -47,6 +50,24 @@ func (p *DashboardController) GetDashboard(c *gin.Context) {
 		utils.RespondError(c, http.StatusBadRequest, "interval must be one of: day, week, month")
 		return
 	}
+	if _, err := time.LoadLocation(tz); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "tz must be an IANA timezone (e.g. Asia/Ho_Chi_Minh)")
+		return
+	}
+
+	preset := strings.ToLower(c.Query("preset"))
+	if preset != "" && !services.ValidDashboardPreset(preset) {
+		utils.RespondError(c, http.StatusBadRequest, "preset must be one of: mtd, qtd, ytd")
+		return
+	}
+	compare := strings.ToLower(c.DefaultQuery("compare", services.DashboardComparePreviousPeriod))
+	if !services.ValidDashboardCompare(compare) {
+		utils.RespondError(c, http.StatusBadRequest, "compare must be one of: previous_period, previous_year, none")
+		return
+	}
+	if compare == services.DashboardCompareNone {
+		compare = ""
+	}
 
 	var (
 		start, end time.Time
@@ -62,8 +83,15 @@ func (p *DashboardController) GetDashboard(c *gin.Context) {
 		utils.RespondError(c, http.StatusBadRequest, "provide either last_days or start/end (not both)")
 		return
 	}
+	if preset != "" && (lastDaysStr != "" || startStr != "" || endStr != "") {
+		utils.RespondError(c, http.StatusBadRequest, "provide either preset or last_days or start/end")
+		return
+	}
 
 	switch {
+	case preset != "":
+		// resolved by the service in tz
+
 	case lastDaysStr != "":
 		d, convErr := strconv.Atoi(lastDaysStr)
 		if convErr != nil || d <= 0 {
@@ -107,6 +135,8 @@ func (p *DashboardController) GetDashboard(c *gin.Context) {
 		End:      end,
 		Interval: interval,
 		Timezone: tz,
+		Preset:   preset,
+		Compare:  compare,
 	}
 
 	report, svcErr := p.dashboardService.BuildDashboard(c.Request.Context(), tr, currency)
@@ -118,6 +148,52 @@ func (p *DashboardController) GetDashboard(c *gin.Context) {
 	utils.RespondSuccess(c, report, "Dashboard data fetched successfully")
 }
 
+// GetCohorts godoc
+// @Summary Get cohort retention report
+// @Description Admin only. Group accounts by signup month and report, for each month since signup, the percentage still having a subscription (metric=subscription) or still creating journeys (metric=journeys)
+// @Tags Dashboard
+// @Accept json
+// @Produce json
+// @Param months query int    false "Signup months covered, ending with the current one (default 12, max 36)"
+// @Param metric query string false "What retains an account: subscription | journeys (default: subscription)"
+// @Param tz     query string false "IANA timezone the months are cut in (default: Asia/Ho_Chi_Minh)"
+// @Success 200 {object} utils.APIResponse
+// @Failure 400 {object} utils.APIResponse
+// @Failure 403 {object} utils.APIResponse
+// @Failure 500 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /dashboard/cohorts [get]
+func (p *DashboardController) GetCohorts(c *gin.Context) {
+	tz := c.DefaultQuery("tz", "Asia/Ho_Chi_Minh")
+	metric := strings.ToLower(c.DefaultQuery("metric", "subscription"))
+
+	if _, err := time.LoadLocation(tz); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "tz must be an IANA timezone (e.g. Asia/Ho_Chi_Minh)")
+		return
+	}
+	if !services.ValidCohortMetric(metric) {
+		utils.RespondError(c, http.StatusBadRequest, "metric must be one of: subscription, journeys")
+		return
+	}
+	months := services.DashboardCohortMonthsDefault
+	if s := c.Query("months"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 || n > services.DashboardCohortMonthsMax {
+			utils.RespondError(c, http.StatusBadRequest, "months must be an integer between 1 and 36")
+			return
+		}
+		months = n
+	}
+
+	report, err := p.dashboardService.BuildCohorts(c.Request.Context(), months, metric, tz)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, report, "Cohort report fetched successfully")
+}
+
 // ---- helpers ----
 
 func validInterval(s string) bool {
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type DestinationRuleController struct {
+	rulesService services.DestinationRuleServiceInterface
+}
+
+func NewDestinationRuleController(rulesService services.DestinationRuleServiceInterface) *DestinationRuleController {
+	return &DestinationRuleController{rulesService: rulesService}
+}
+
+// ListRules godoc
+// @Summary List destination rules
+// @Description Planning guardrails per province: max activities per day, restricted time windows and buffers between activities (admin only)
+// @Tags Admin
+// @Produce json
+// @Success 200 {array} response_models.DestinationRuleResponse
+// @Security BearerAuth
+// @Router /admin/destination-rules [get]
+func (d *DestinationRuleController) ListRules(c *gin.Context) {
+	rules, err := d.rulesService.ListRules(c.Request.Context())
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, rules, "Destination rules fetched successfully")
+}
+
+// SetRule godoc
+// @Summary Create or replace the rules of a province
+// @Description Forbidden windows use HH:MM-HH:MM in Vietnam time and may wrap midnight (e.g. 18:00-06:00). Zero means no limit (admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param provinceId path string true "Province ID"
+// @Param request body request_models.SetDestinationRuleRequest true "Rules"
+// @Success 200 {object} response_models.DestinationRuleResponse
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/destination-rules/{provinceId} [put]
+func (d *DestinationRuleController) SetRule(c *gin.Context) {
+	var req request_models.SetDestinationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "max_activities_per_day must be 0-12, buffer_minutes 0-240 and note at most 500 characters")
+		return
+	}
+
+	rule, err := d.rulesService.SetRule(c.Request.Context(), c.Param("provinceId"), req, c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, rule, "Destination rules saved")
+}
+
+// DeleteRule godoc
+// @Summary Remove the rules of a province
+// @Tags Admin
+// @Produce json
+// @Param provinceId path string true "Province ID"
+// @Success 200 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/destination-rules/{provinceId} [delete]
+func (d *DestinationRuleController) DeleteRule(c *gin.Context) {
+	if err := d.rulesService.DeleteRule(c.Request.Context(), c.Param("provinceId")); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Destination rules removed")
+}
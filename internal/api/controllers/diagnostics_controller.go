@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"strconv"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type DiagnosticsController struct {
+	diagnosticsService services.DiagnosticsServiceInterface
+}
+
+func NewDiagnosticsController(diagnosticsService services.DiagnosticsServiceInterface) *DiagnosticsController {
+	return &DiagnosticsController{diagnosticsService: diagnosticsService}
+}
+
+// ListSlowQueries godoc
+// @Summary List slow queries
+// @Description Slow queries captured by the GORM plugin with their EXPLAIN ANALYZE plans, slowest first (admin only)
+// @Tags Admin
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(20) minimum(1) maximum(100)
+// @Param minDurationMs query int false "Only queries at least this slow (ms)"
+// @Success 200 {object} response_models.SlowQueryPage
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/diagnostics/slow-queries [get]
+func (d *DiagnosticsController) ListSlowQueries(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid page number")
+		return
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid page size (must be 1-100)")
+		return
+	}
+
+	minDurationMs, err := strconv.ParseInt(c.DefaultQuery("minDurationMs", "0"), 10, 64)
+	if err != nil || minDurationMs < 0 {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid minDurationMs")
+		return
+	}
+
+	result, err := d.diagnosticsService.ListSlowQueries(c.Request.Context(), page, pageSize, minDurationMs)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, result, "Slow queries fetched successfully")
+}
+
+// DeleteSlowQuery godoc
+// @Summary Dismiss a slow query
+// @Description Remove a captured slow query once it has been fixed (admin only)
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Diagnostic ID"
+// @Success 200 {object} utils.APIResponse
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/diagnostics/slow-queries/{id} [delete]
+func (d *DiagnosticsController) DeleteSlowQuery(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := uuid.Parse(id); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	if err := d.diagnosticsService.DeleteSlowQuery(c.Request.Context(), id); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Slow query dismissed")
+}
+
+// GetPoolStats godoc
+// @Summary Database pool stats
+// @Description Current connection pool usage and saturation counters (admin only)
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} infra.PoolStats
+// @Security BearerAuth
+// @Router /admin/diagnostics/db-pool [get]
+func (d *DiagnosticsController) GetPoolStats(c *gin.Context) {
+	stats, err := d.diagnosticsService.GetPoolStats(c.Request.Context())
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, stats, "Pool stats fetched successfully")
+}
+
+// GetAIProviderStats godoc
+// @Summary AI provider stats
+// @Description Request, failure and failover counters and circuit state of each AI provider when EMBEDDING_PROVIDER=router; empty otherwise (admin only)
+// @Tags Admin
+// @Produce json
+// @Success 200 {array} utils.AIProviderStats
+// @Security BearerAuth
+// @Router /admin/diagnostics/ai-providers [get]
+func (d *DiagnosticsController) GetAIProviderStats(c *gin.Context) {
+	utils.RespondSuccess(c, d.diagnosticsService.GetAIProviderStats(c.Request.Context()), "AI provider stats fetched successfully")
+}
@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type EmbeddingController struct {
+	indexService services.EmbeddingIndexServiceInterface
+}
+
+func NewEmbeddingController(indexService services.EmbeddingIndexServiceInterface) *EmbeddingController {
+	return &EmbeddingController{indexService: indexService}
+}
+
+// Reindex godoc
+// @Summary Rebuild all POI embeddings
+// @Description Queue every POI for re-embedding; the background worker picks the queue up right away (admin only)
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/embeddings/reindex [post]
+func (e *EmbeddingController) Reindex(c *gin.Context) {
+	queued, err := e.indexService.Reindex(c.Request.Context())
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, gin.H{"queued": queued}, "Reindex queued")
+}
@@ -27,6 +27,7 @@ func NewFeedbackController(feedbackService services.FeedbackServiceInterface) *F
 // @Accept json
 // @Produce json
 // @Param request body request_models.AddFeedbackRequest true "Feedback payload"
+// @Param X-Captcha-Token header string false "Turnstile/reCAPTCHA token, required when CAPTCHA is enabled"
 // @Success 200 {object} utils.APIResponse
 // @Failure 400 {object} utils.APIResponse
 // @Router /feedback/add [post]
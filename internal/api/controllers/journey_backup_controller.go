@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type JourneyBackupController struct {
+	backupService services.JourneyBackupServiceInterface
+}
+
+func NewJourneyBackupController(backupService services.JourneyBackupServiceInterface) *JourneyBackupController {
+	return &JourneyBackupController{backupService: backupService}
+}
+
+// ExportMyJourneys godoc
+// @Summary Back up my journeys
+// @Description Every journey of the caller with its days, activities, check-ins, budget and expenses, as a zip of one JSON file per journey plus manifest.json. The zip is built in the background: while none is ready the call queues one (at most JOURNEY_BACKUP_DAILY_LIMIT a day) and answers the job, and the caller is emailed when it is ready. A ready zip is returned as the file until it expires; refresh=true queues a new one instead
+// @Tags Accounts
+// @Produce application/zip
+// @Produce json
+// @Param refresh query bool false "Queue a new backup even when one is ready"
+// @Success 200 {file} file
+// @Success 200 {object} response_models.JourneyBackupJob "No backup ready yet"
+// @Failure 401 {object} utils.APIResponse
+// @Failure 429 {object} utils.APIResponse "Daily backup limit reached"
+// @Security BearerAuth
+// @Router /accounts/me/journeys/export [get]
+func (j *JourneyBackupController) ExportMyJourneys(c *gin.Context) {
+	refresh := c.Query("refresh") == "true"
+	export, job, err := j.backupService.Request(c.Request.Context(), c.GetString("user_id"), refresh)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+	if export == nil {
+		utils.RespondSuccess(c, job, "Your backup is being prepared, we will email you when it is ready")
+		return
+	}
+
+	c.Header("Content-Type", export.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, export.FileName))
+	c.Status(http.StatusOK)
+	if err := export.Write(c.Writer); err != nil {
+		// Headers are gone already; a truncated file is all the client can get
+		log.Printf("[journey-backup] %s: %v", export.FileName, err)
+		c.Abort()
+	}
+}
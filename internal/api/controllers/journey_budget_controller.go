@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type JourneyBudgetController struct {
+	budgetService services.JourneyBudgetServiceInterface
+}
+
+func NewJourneyBudgetController(budgetService services.JourneyBudgetServiceInterface) *JourneyBudgetController {
+	return &JourneyBudgetController{budgetService: budgetService}
+}
+
+// GetBudget godoc
+// @Summary Get the budget of a journey
+// @Description Spend against the trip budget with the daily burn rate and the projected total. Data is null when no budget is set
+// @Tags Journey
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Success 200 {object} response_models.BudgetSummary
+// @Failure 403 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/budget [get]
+func (j *JourneyBudgetController) GetBudget(c *gin.Context) {
+	summary, err := j.budgetService.GetSummary(c.Request.Context(), c.Param("journeyId"), c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, summary, "Budget retrieved")
+}
+
+// SetBudget godoc
+// @Summary Set the budget of a journey
+// @Description Set the total trip budget in VND. Owner and editors only. Changing it re-arms the over-budget alert
+// @Tags Journey
+// @Accept json
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Param request body request_models.SetBudgetRequest true "Budget"
+// @Success 200 {object} response_models.BudgetSummary
+// @Failure 400 {object} utils.APIResponse
+// @Failure 403 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/budget [put]
+func (j *JourneyBudgetController) SetBudget(c *gin.Context) {
+	var req request_models.SetBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "amount must be a positive number of VND")
+		return
+	}
+
+	summary, err := j.budgetService.SetBudget(c.Request.Context(), c.Param("journeyId"), c.GetString("user_id"), req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, summary, "Budget set")
+}
+
+// DeleteBudget godoc
+// @Summary Remove the budget of a journey
+// @Description Owner and editors only. Logged expenses are kept
+// @Tags Journey
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Success 200 {object} utils.APIResponse
+// @Failure 403 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/budget [delete]
+func (j *JourneyBudgetController) DeleteBudget(c *gin.Context) {
+	if err := j.budgetService.RemoveBudget(c.Request.Context(), c.Param("journeyId"), c.GetString("user_id")); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Budget removed")
+}
+
+// ListExpenses godoc
+// @Summary List the expenses of a journey
+// @Description Expenses newest first. Owner and members only
+// @Tags Journey
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Success 200 {array} response_models.JourneyExpense
+// @Failure 403 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/expenses [get]
+func (j *JourneyBudgetController) ListExpenses(c *gin.Context) {
+	expenses, err := j.budgetService.ListExpenses(c.Request.Context(), c.Param("journeyId"), c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, expenses, "Expenses retrieved")
+}
+
+// LogExpense godoc
+// @Summary Log an expense on a journey
+// @Description Record an expense in VND, optionally on an activity. Participants are emailed once when the projected spend goes over the budget
+// @Tags Journey
+// @Accept json
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Param request body request_models.CreateExpenseRequest true "Expense"
+// @Success 200 {object} response_models.ExpenseLogged
+// @Failure 400 {object} utils.APIResponse
+// @Failure 403 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/expenses [post]
+func (j *JourneyBudgetController) LogExpense(c *gin.Context) {
+	var req request_models.CreateExpenseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "amount and category (food, transport, lodging, activities, shopping, other) are required")
+		return
+	}
+
+	logged, err := j.budgetService.LogExpense(c.Request.Context(), c.Param("journeyId"), c.GetString("user_id"), req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, logged, "Expense logged")
+}
+
+// DeleteExpense godoc
+// @Summary Delete a journey expense
+// @Description Who logged the expense or the journey owner can delete it
+// @Tags Journey
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Param expenseId path string true "Expense ID"
+// @Success 200 {object} utils.APIResponse
+// @Failure 403 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/expenses/{expenseId} [delete]
+func (j *JourneyBudgetController) DeleteExpense(c *gin.Context) {
+	if err := j.budgetService.DeleteExpense(c.Request.Context(), c.Param("journeyId"), c.GetString("user_id"), c.Param("expenseId")); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Expense deleted")
+}
+
+// ScanReceipt godoc
+// @Summary Read an expense off a receipt photo
+// @Description Send a receipt photo (JPEG, PNG, WebP or HEIC, max 8 MB) to get an expense prefilled with the amount, merchant and date. Nothing is saved and the photo is not kept: check the draft, then post it to /journeys/{journeyId}/expenses. Owner and editors only
+// @Tags Journey
+// @Accept multipart/form-data
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Param file formData file true "Receipt photo (max 8 MB)"
+// @Success 200 {object} response_models.ReceiptDraft
+// @Failure 400 {object} utils.APIResponse
+// @Failure 403 {object} utils.APIResponse
+// @Failure 503 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/expenses/scan [post]
+func (j *JourneyBudgetController) ScanReceipt(c *gin.Context) {
+	fh, err := c.FormFile("file")
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "file is required")
+		return
+	}
+	if fh.Size > services.MaxReceiptImageBytes {
+		utils.RespondError(c, http.StatusBadRequest, "file is too large (max 8 MB)")
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Cannot read file")
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, services.MaxReceiptImageBytes+1))
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Cannot read file")
+		return
+	}
+
+	draft, err := j.budgetService.ScanReceipt(c.Request.Context(), c.Param("journeyId"), c.GetString("user_id"), data, fh.Header.Get("Content-Type"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, draft, "Receipt read, please check the expense before saving it")
+}
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type JourneyCommentController struct {
+	commentService services.JourneyCommentServiceInterface
+}
+
+func NewJourneyCommentController(commentService services.JourneyCommentServiceInterface) *JourneyCommentController {
+	return &JourneyCommentController{commentService: commentService}
+}
+
+// ListComments godoc
+// @Summary List the comment threads of a journey
+// @Description Top-level comments oldest first, each with its replies. Owner and members only
+// @Tags Journey
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Param activity_id query string false "Only the comments on this activity"
+// @Success 200 {array} response_models.JourneyComment
+// @Failure 403 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/comments [get]
+func (j *JourneyCommentController) ListComments(c *gin.Context) {
+	comments, err := j.commentService.ListComments(c.Request.Context(), c.Param("journeyId"), c.GetString("user_id"), c.Query("activity_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, comments, "Comments retrieved")
+}
+
+// CreateComment godoc
+// @Summary Comment on a journey or an activity
+// @Description Post a comment, optionally on one activity or as a reply (parent_id). Participants mentioned as @name (their email name or display name without spaces) are notified by email
+// @Tags Journey
+// @Accept json
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Param request body request_models.CreateCommentRequest true "Comment"
+// @Success 200 {object} response_models.JourneyComment
+// @Failure 400 {object} utils.APIResponse
+// @Failure 403 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/comments [post]
+func (j *JourneyCommentController) CreateComment(c *gin.Context) {
+	var req request_models.CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "body is required (max 2000 characters)")
+		return
+	}
+
+	comment, err := j.commentService.CreateComment(c.Request.Context(), c.Param("journeyId"), c.GetString("user_id"), req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, comment, "Comment posted")
+}
+
+// DeleteComment godoc
+// @Summary Delete a journey comment
+// @Description The author or the journey owner can delete a comment; its replies stay visible
+// @Tags Journey
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Param commentId path string true "Comment ID"
+// @Success 200 {object} utils.APIResponse
+// @Failure 403 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/comments/{commentId} [delete]
+func (j *JourneyCommentController) DeleteComment(c *gin.Context) {
+	if err := j.commentService.DeleteComment(c.Request.Context(), c.Param("journeyId"), c.GetString("user_id"), c.Param("commentId")); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Comment deleted")
+}
@@ -1,8 +1,11 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
@@ -13,11 +16,25 @@ import (
 
 type JourneyController struct {
 	journeyService services.JourneyServiceInterface
+	exportService  services.JourneyExportServiceInterface
+	importService  services.JourneyImportServiceInterface
+	commentService services.JourneyCommentServiceInterface
+	budgetService  services.JourneyBudgetServiceInterface
 }
 
-func NewJourneyController(journeyService services.JourneyServiceInterface) *JourneyController {
+func NewJourneyController(
+	journeyService services.JourneyServiceInterface,
+	exportService services.JourneyExportServiceInterface,
+	importService services.JourneyImportServiceInterface,
+	commentService services.JourneyCommentServiceInterface,
+	budgetService services.JourneyBudgetServiceInterface,
+) *JourneyController {
 	return &JourneyController{
 		journeyService: journeyService,
+		exportService:  exportService,
+		importService:  importService,
+		commentService: commentService,
+		budgetService:  budgetService,
 	}
 }
 
@@ -67,6 +84,8 @@ func (j *JourneyController) GetJourneyByUserId(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param journeyId path string true "Journey ID"
+// @Param mode query string false "Travel mode for the return-to-accommodation legs (driving, walking, cycling)"
+// @Param include_comments query bool false "Add the comment threads (owner and members only)"
 // @Success 200 {object} response_models.JourneyDetailResponse
 // @Failure 400 {object} utils.APIResponse
 // @Failure 404 {object} utils.APIResponse
@@ -79,18 +98,33 @@ func (j *JourneyController) GetDetailsInfoOfJourneyById(c *gin.Context) {
 		return
 	}
 
-	journey, err := j.journeyService.GetDetailsInfoOfJourneyById(c.Request.Context(), journeyId)
+	mode := c.Query("mode")
+	if !services.IsValidTravelMode(mode) {
+		utils.RespondError(c, http.StatusBadRequest, "mode must be driving, walking or cycling")
+		return
+	}
+
+	journey, err := j.journeyService.GetDetailsInfoOfJourneyById(c.Request.Context(), journeyId, mode)
 	if err != nil {
 		utils.HandleServiceError(c, err)
 		return
 	}
 
+	if c.Query("include_comments") == "true" {
+		journey.Comments, err = j.commentService.ListComments(c.Request.Context(), journeyId, c.GetString("user_id"), "")
+		if err != nil {
+			utils.HandleServiceError(c, err)
+			return
+		}
+	}
+
 	utils.RespondSuccess(c, journey, "Journey details fetched successfully")
 }
 
 // AddPoiToJourney godoc
 // @Summary Add POI to journey
-// @Description Add a point of interest (POI) to a specific journey with optional start and end times
+// @Description Add a point of interest (POI) to a specific journey with optional start and end times.
+// @Description A time that does not fit the day is rejected with the nearest times that do in data.alternatives.
 // @Tags Journey
 // @Accept json
 // @Produce json
@@ -152,13 +186,15 @@ func (j *JourneyController) RemovePoiFromJourney(c *gin.Context) {
 
 // UpdateSelectedPoiInActivity godoc
 // @Summary Update selected POI in activity
-// @Description Update the selected POI in an activity with the given start and end times
+// @Description Update the selected POI in an activity with the given start and end times.
+// @Description A time that does not fit the day is rejected with the nearest times that do in data.alternatives.
 // @Tags Journey
 // @Accept json
 // @Produce json
 // @Param request body request_models.UpdatePoiInActivityRequest true "Activity ID, POI ID, Start Time, End Time"
 // @Success 200 {object} utils.APIResponse
 // @Failure 400 {object} utils.APIResponse
+// @Failure 422 {object} utils.APIResponse{data=response_models.ActivityTimeConflict}
 // @Failure 500 {object} utils.APIResponse
 // @Security BearerAuth
 // @Router /journeys/update-poi-in-activity [post]
@@ -258,3 +294,417 @@ func (j *JourneyController) UpdateJourneyWindow(c *gin.Context) {
 		"message":           "Journey days scaled to window",
 	}, "Journey window updated")
 }
+
+// ExportJourneyPDF godoc
+// @Summary Export journey as PDF
+// @Description Render the journey (days, activities, addresses, distances and map links) into a branded PDF file
+// @Tags Journey
+// @Produce application/pdf
+// @Param journeyId path string true "Journey ID"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/export/pdf [get]
+func (j *JourneyController) ExportJourneyPDF(c *gin.Context) {
+	journeyId := c.Param("journeyId")
+	if _, err := uuid.Parse(journeyId); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid journey ID")
+		return
+	}
+
+	data, fileName, err := j.exportService.ExportJourneyPDF(c.Request.Context(), journeyId, c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Data(http.StatusOK, "application/pdf", data)
+}
+
+// PrintJourney godoc
+// @Summary Print-friendly journey view
+// @Description Render the journey as a standalone HTML page for printing: one page per day, each with its map inlined. A lighter alternative to the PDF export
+// @Tags Journey
+// @Produce html
+// @Param journeyId path string true "Journey ID"
+// @Success 200 {string} string "HTML page"
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/print [get]
+func (j *JourneyController) PrintJourney(c *gin.Context) {
+	journeyId := c.Param("journeyId")
+	if _, err := uuid.Parse(journeyId); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid journey ID")
+		return
+	}
+
+	page, err := j.exportService.RenderJourneyHTML(c.Request.Context(), journeyId, c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+}
+
+// PrintSharedJourney godoc
+// @Summary Print-friendly view of a shared journey
+// @Description The print view of the journey a share link points to; no login needed
+// @Tags Journey
+// @Produce html
+// @Param token path string true "Share token"
+// @Success 200 {string} string "HTML page"
+// @Failure 404 {object} utils.APIResponse
+// @Router /shared/journeys/{token}/print [get]
+func (j *JourneyController) PrintSharedJourney(c *gin.Context) {
+	page, err := j.exportService.RenderSharedJourneyHTML(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	// The token is in the URL: keep it out of referrers and shared caches
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+}
+
+// ShareJourney godoc
+// @Summary Share a journey's print view
+// @Description Return the journey's share link, creating it on first call. Anyone with the link can open the print view without logging in (owner only)
+// @Tags Journey
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Success 200 {object} response_models.JourneyShareLink
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/share [post]
+func (j *JourneyController) ShareJourney(c *gin.Context) {
+	journeyId := c.Param("journeyId")
+	if _, err := uuid.Parse(journeyId); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid journey ID")
+		return
+	}
+
+	link, err := j.exportService.ShareJourney(c.Request.Context(), journeyId, c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, link, "Share link ready")
+}
+
+// GetShareText godoc
+// @Summary Journey as a message for chat apps
+// @Description Plain-text itinerary with emoji, one bullet per stop with its time and a map link, short enough for one Zalo or WhatsApp message (later days are cut and replaced by a link to the trip). In the user's account language unless lang is given. Owner, or anyone when the journey is shared.
+// @Tags Journey
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Param lang query string false "vi or en"
+// @Success 200 {object} response_models.JourneyShareText
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/share-text [get]
+func (j *JourneyController) GetShareText(c *gin.Context) {
+	journeyId := c.Param("journeyId")
+	if _, err := uuid.Parse(journeyId); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid journey ID")
+		return
+	}
+
+	text, err := j.exportService.ShareText(c.Request.Context(), journeyId, c.GetString("user_id"), c.Query("lang"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, text, "Share text ready")
+}
+
+// UnshareJourney godoc
+// @Summary Revoke a journey's share link
+// @Description The link stops working; sharing again creates a new one (owner only)
+// @Tags Journey
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Success 200 {object} utils.APIResponse
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/share [delete]
+func (j *JourneyController) UnshareJourney(c *gin.Context) {
+	journeyId := c.Param("journeyId")
+	if _, err := uuid.Parse(journeyId); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid journey ID")
+		return
+	}
+
+	if err := j.exportService.UnshareJourney(c.Request.Context(), journeyId, c.GetString("user_id")); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Share link revoked")
+}
+
+// OptimizeDay godoc
+// @Summary Optimize the order of a journey day
+// @Description Reorder the POI activities of one day by travel distance (nearest-neighbor + 2-opt) and persist the new times
+// @Tags Journey
+// @Accept json
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Param request body request_models.OptimizeDayRequest true "Day number to optimize"
+// @Success 200 {object} response_models.OptimizeDayResponse
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/optimize-day [post]
+func (j *JourneyController) OptimizeDay(c *gin.Context) {
+	journeyId := c.Param("journeyId")
+	if _, err := uuid.Parse(journeyId); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid journey ID")
+		return
+	}
+
+	var req request_models.OptimizeDayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "day_number is required")
+		return
+	}
+	if !services.IsValidTravelMode(req.Mode) {
+		utils.RespondError(c, http.StatusBadRequest, "mode must be driving, walking or cycling")
+		return
+	}
+
+	result, err := j.journeyService.OptimizeDay(c.Request.Context(), journeyId, c.GetString("user_id"), req.DayNumber, req.Mode)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, result, "Journey day optimized successfully")
+}
+
+// SetDayAccommodation godoc
+// @Summary Set the accommodation of a journey day
+// @Description Set where the traveller sleeps after a day (empty poi_id clears it). Days with an accommodation get a final leg back to it
+// @Tags Journey
+// @Accept json
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Param request body request_models.SetDayAccommodationRequest true "Day number and accommodation POI"
+// @Success 200 {object} response_models.JourneyDayResponse
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/accommodation [put]
+func (j *JourneyController) SetDayAccommodation(c *gin.Context) {
+	journeyId := c.Param("journeyId")
+	if _, err := uuid.Parse(journeyId); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid journey ID")
+		return
+	}
+
+	var req request_models.SetDayAccommodationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "day_number is required")
+		return
+	}
+
+	day, err := j.journeyService.SetDayAccommodation(c.Request.Context(), journeyId, c.GetString("user_id"), req.DayNumber, req.POIID)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, day, "Accommodation updated successfully")
+}
+
+// SwapRainyDay godoc
+// @Summary Swap a day to its rainy-day plan
+// @Description Replace the day's activities with its indoor alternative. The replaced activities become the new alternative, so calling it again swaps back
+// @Tags Journey
+// @Accept json
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Param request body request_models.RainySwapRequest true "Day number"
+// @Success 200 {object} response_models.JourneyDayResponse
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/rainy-swap [post]
+func (j *JourneyController) SwapRainyDay(c *gin.Context) {
+	journeyId := c.Param("journeyId")
+	if _, err := uuid.Parse(journeyId); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid journey ID")
+		return
+	}
+
+	var req request_models.RainySwapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "day_number is required")
+		return
+	}
+
+	day, err := j.journeyService.SwapRainyDay(c.Request.Context(), journeyId, c.GetString("user_id"), req.DayNumber)
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidInput) {
+			utils.RespondError(c, http.StatusBadRequest, "Day not found or it has no rainy-day plan")
+			return
+		}
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, day, "Rainy-day plan swapped successfully")
+}
+
+// CheckInActivity godoc
+// @Summary Check in to a planned activity
+// @Description Mark an activity of the journey as done. Check-ins open 2 hours before the activity and count as on time up to 30 minutes after its start. Returns the updated trip progress
+// @Tags Journey
+// @Accept json
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Param request body request_models.CheckInActivityRequest true "Activity check-in"
+// @Success 200 {object} response_models.JourneyCheckInResponse
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/check-ins [post]
+func (j *JourneyController) CheckInActivity(c *gin.Context) {
+	journeyId := c.Param("journeyId")
+	if _, err := uuid.Parse(journeyId); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid journey ID")
+		return
+	}
+
+	var req request_models.CheckInActivityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, err := j.journeyService.CheckInActivity(c.Request.Context(), journeyId, c.GetString("user_id"), req)
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidInput) {
+			utils.RespondError(c, http.StatusBadRequest, "Activity not found in this journey or not open for check-in yet")
+			return
+		}
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, result, "Checked in successfully")
+}
+
+// GetToday godoc
+// @Summary Get the day-of view of a journey
+// @Description Today's day of the trip with activity statuses, the next activity to check in, the trip progress and, when the trip has a budget, the spend against it
+// @Tags Journey
+// @Accept json
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Param mode query string false "Travel mode for the return-to-accommodation legs (driving, walking, cycling)"
+// @Success 200 {object} response_models.JourneyTodayResponse
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/today [get]
+func (j *JourneyController) GetToday(c *gin.Context) {
+	journeyId := c.Param("journeyId")
+	if _, err := uuid.Parse(journeyId); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid journey ID")
+		return
+	}
+
+	mode := c.Query("mode")
+	if !services.IsValidTravelMode(mode) {
+		utils.RespondError(c, http.StatusBadRequest, "mode must be driving, walking or cycling")
+		return
+	}
+
+	today, err := j.journeyService.GetToday(c.Request.Context(), journeyId, c.GetString("user_id"), mode)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+	// The budget is a bonus on the day-of view; a failure there must not hide today's plan
+	if budget, err := j.budgetService.GetSummary(c.Request.Context(), journeyId, c.GetString("user_id")); err == nil {
+		today.Budget = budget
+	}
+
+	utils.RespondSuccess(c, today, "Today's plan fetched successfully")
+}
+
+const maxImportFileBytes = 2 << 20
+
+// ImportJourney godoc
+// @Summary Import a journey
+// @Description Create a journey from a Google My Maps KML export or a CSV of places (name, address, lat, lng, date, day, start, end, notes). Places are matched to catalog POIs; unknown places with coordinates become draft POIs
+// @Tags Journey
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "KML or CSV file (max 2 MB)"
+// @Param title formData string false "Journey title (defaults to the map name)"
+// @Param start_date formData string false "First day, YYYY-MM-DD (defaults to the earliest date in the file, else tomorrow)"
+// @Param province_id formData string false "Province for draft POIs (inferred from matched POIs when empty)"
+// @Success 200 {object} response_models.JourneyImportResult
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/import [post]
+func (j *JourneyController) ImportJourney(c *gin.Context) {
+	var req request_models.ImportJourneyRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid form data")
+		return
+	}
+
+	fh, err := c.FormFile("file")
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "file is required")
+		return
+	}
+	if fh.Size > maxImportFileBytes {
+		utils.RespondError(c, http.StatusBadRequest, "file is too large (max 2 MB)")
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Cannot read file")
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxImportFileBytes))
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Cannot read file")
+		return
+	}
+
+	opts := services.JourneyImportOptions{Title: req.Title, ProvinceID: req.ProvinceID}
+	if req.ProvinceID != "" {
+		if _, err := uuid.Parse(req.ProvinceID); err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "Invalid province_id")
+			return
+		}
+	}
+	if req.StartDate != "" {
+		start, err := time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "start_date must be YYYY-MM-DD")
+			return
+		}
+		opts.StartDate = &start
+	}
+
+	result, err := j.importService.ImportJourney(c.Request.Context(), c.GetString("user_id"), fh.Filename, data, opts)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, result, "Journey imported successfully")
+}
@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type JourneyPollController struct {
+	pollService services.JourneyPollServiceInterface
+}
+
+func NewJourneyPollController(pollService services.JourneyPollServiceInterface) *JourneyPollController {
+	return &JourneyPollController{pollService: pollService}
+}
+
+// ListMembers godoc
+// @Summary List the members of a journey
+// @Description The owner first, then the invited accounts with their role (editor or viewer)
+// @Tags Journey
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Success 200 {array} response_models.JourneyMember
+// @Failure 403 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/members [get]
+func (p *JourneyPollController) ListMembers(c *gin.Context) {
+	members, err := p.pollService.ListMembers(c.Request.Context(), c.Param("journeyId"), c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, members, "Members retrieved")
+}
+
+// AddMember godoc
+// @Summary Invite an account to a journey
+// @Description Add a registered account as editor (can open polls and vote) or viewer (can vote), or change its role (owner only)
+// @Tags Journey
+// @Accept json
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Param request body request_models.AddJourneyMemberRequest true "Member"
+// @Success 200 {object} response_models.JourneyMember
+// @Failure 400 {object} utils.APIResponse
+// @Failure 403 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/members [post]
+func (p *JourneyPollController) AddMember(c *gin.Context) {
+	var req request_models.AddJourneyMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "a valid email and a role (editor or viewer) are required")
+		return
+	}
+
+	member, err := p.pollService.AddMember(c.Request.Context(), c.Param("journeyId"), c.GetString("user_id"), req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, member, "Member added")
+}
+
+// RemoveMember godoc
+// @Summary Remove a member from a journey
+// @Description The owner can remove anyone; members can remove themselves
+// @Tags Journey
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Param accountId path string true "Account ID"
+// @Success 200 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/members/{accountId} [delete]
+func (p *JourneyPollController) RemoveMember(c *gin.Context) {
+	if err := p.pollService.RemoveMember(c.Request.Context(), c.Param("journeyId"), c.GetString("user_id"), c.Param("accountId")); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Member removed")
+}
+
+// CreatePoll godoc
+// @Summary Open a poll for an activity slot
+// @Description Propose 2-3 POIs for an activity. Members vote until the deadline, then the option with the most votes replaces the activity's POI (ties go to the first option; without votes the plan is kept). Owner and editors only
+// @Tags Journey
+// @Accept json
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Param request body request_models.CreatePollRequest true "Poll"
+// @Success 200 {object} response_models.JourneyPoll
+// @Failure 400 {object} utils.APIResponse
+// @Failure 403 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/polls [post]
+func (p *JourneyPollController) CreatePoll(c *gin.Context) {
+	var req request_models.CreatePollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "activity_id, 2 to 3 poi_ids and a deadline are required")
+		return
+	}
+
+	poll, err := p.pollService.CreatePoll(c.Request.Context(), c.Param("journeyId"), c.GetString("user_id"), req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, poll, "Poll created")
+}
+
+// ListPolls godoc
+// @Summary List the polls of a journey
+// @Tags Journey
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Success 200 {array} response_models.JourneyPoll
+// @Failure 403 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/polls [get]
+func (p *JourneyPollController) ListPolls(c *gin.Context) {
+	polls, err := p.pollService.ListPolls(c.Request.Context(), c.Param("journeyId"), c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, polls, "Polls retrieved")
+}
+
+// Vote godoc
+// @Summary Vote in a journey poll
+// @Description Record the caller's choice; voting again changes it while the poll is open
+// @Tags Journey
+// @Accept json
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Param pollId path string true "Poll ID"
+// @Param request body request_models.VotePollRequest true "Vote"
+// @Success 200 {object} response_models.JourneyPoll
+// @Failure 400 {object} utils.APIResponse
+// @Failure 403 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/polls/{pollId}/vote [post]
+func (p *JourneyPollController) Vote(c *gin.Context) {
+	var req request_models.VotePollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "option_id is required")
+		return
+	}
+
+	poll, err := p.pollService.Vote(c.Request.Context(), c.Param("journeyId"), c.Param("pollId"), c.GetString("user_id"), req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, poll, "Vote recorded")
+}
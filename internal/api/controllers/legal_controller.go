@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type LegalController struct {
+	legalService services.LegalServiceInterface
+}
+
+func NewLegalController(legalService services.LegalServiceInterface) *LegalController {
+	return &LegalController{legalService: legalService}
+}
+
+// GetDocuments godoc
+// @Summary Current terms of service and privacy policy
+// @Tags Legal
+// @Produce json
+// @Param kind query string false "terms | privacy"
+// @Success 200 {array} response_models.LegalDocument
+// @Failure 400 {object} utils.APIResponse
+// @Router /legal/documents [get]
+func (l *LegalController) GetDocuments(c *gin.Context) {
+	var query request_models.LegalDocumentListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+
+	docs, err := l.legalService.Current(c.Request.Context(), query.Kind)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, docs, "Legal documents fetched successfully")
+}
+
+// GetStatus godoc
+// @Summary Which current legal documents I accepted
+// @Description While acceptance_required is true, other authenticated endpoints answer 451 with status legal_acceptance_required and the documents to accept
+// @Tags Legal
+// @Produce json
+// @Success 200 {object} response_models.LegalStatus
+// @Security BearerAuth
+// @Router /legal/status [get]
+func (l *LegalController) GetStatus(c *gin.Context) {
+	status, err := l.legalService.Status(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, status, "Legal status fetched successfully")
+}
+
+// Accept godoc
+// @Summary Accept the current legal documents
+// @Description Each entry names the version shown to the user; 409 when a newer one was published meanwhile
+// @Tags Legal
+// @Accept json
+// @Produce json
+// @Param request body request_models.AcceptLegalDocumentsRequest true "Documents"
+// @Success 200 {object} response_models.LegalStatus
+// @Failure 400 {object} utils.APIResponse
+// @Failure 409 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /legal/accept [post]
+func (l *LegalController) Accept(c *gin.Context) {
+	var req request_models.AcceptLegalDocumentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	status, err := l.legalService.Accept(c.Request.Context(), c.GetString("user_id"), req, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, status, "Legal documents accepted successfully")
+}
+
+// ListVersions godoc
+// @Summary List legal document versions
+// @Description Every published version, newest first; current marks the one accounts must accept (admin only)
+// @Tags Admin
+// @Produce json
+// @Param kind query string false "terms | privacy"
+// @Success 200 {array} response_models.AdminLegalDocument
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/legal/documents [get]
+func (l *LegalController) ListVersions(c *gin.Context) {
+	var query request_models.LegalDocumentListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+
+	docs, err := l.legalService.ListVersions(c.Request.Context(), query.Kind)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, docs, "Legal documents fetched successfully")
+}
+
+// Publish godoc
+// @Summary Publish a legal document version
+// @Description Accounts must accept a new version before using the API again, unless it is minor (admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body request_models.PublishLegalDocumentRequest true "Document"
+// @Success 200 {object} response_models.AdminLegalDocument
+// @Failure 400 {object} utils.APIResponse
+// @Failure 409 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/legal/documents [post]
+func (l *LegalController) Publish(c *gin.Context) {
+	var req request_models.PublishLegalDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	doc, err := l.legalService.Publish(c.Request.Context(), req, c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, doc, "Legal document published successfully")
+}
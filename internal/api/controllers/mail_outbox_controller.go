@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type MailOutboxController struct {
+	outbox services.MailOutboxServiceInterface
+}
+
+func NewMailOutboxController(outbox services.MailOutboxServiceInterface) *MailOutboxController {
+	return &MailOutboxController{outbox: outbox}
+}
+
+// ListMessages godoc
+// @Summary List the mail outbox
+// @Description Transactional emails, newest first: pending ones wait for their next attempt, dead ones ran out of attempts or expired (admin only)
+// @Tags Admin
+// @Produce json
+// @Param status query string false "pending | sent | dead"
+// @Param recipient query string false "Recipient email address"
+// @Param page query int false "Page" default(1)
+// @Param pageSize query int false "Page size" default(20)
+// @Success 200 {object} response_models.MailOutboxPage
+// @Failure 400 {object} utils.APIResponse
+// @Failure 401 {object} utils.APIResponse
+// @Failure 403 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/mail-outbox [get]
+func (m *MailOutboxController) ListMessages(c *gin.Context) {
+	var query request_models.MailOutboxQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+
+	page, err := m.outbox.ListMessages(c.Request.Context(), query)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, page, "Messages fetched successfully")
+}
+
+// RetryMessage godoc
+// @Summary Retry a dead message
+// @Description Puts the message back in the queue with a fresh set of attempts (admin only)
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Message ID"
+// @Success 200 {object} response_models.MailOutboxMessage
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/mail-outbox/{id}/retry [post]
+func (m *MailOutboxController) RetryMessage(c *gin.Context) {
+	msg, err := m.outbox.RetryMessage(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, msg, "Message queued again")
+}
+
+// RetryDead godoc
+// @Summary Retry every dead message
+// @Description Puts all dead messages back in the queue; expired ones are dead-lettered again without being sent (admin only)
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} response_models.MailOutboxRetried
+// @Failure 401 {object} utils.APIResponse
+// @Failure 403 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/mail-outbox/retry-dead [post]
+func (m *MailOutboxController) RetryDead(c *gin.Context) {
+	out, err := m.outbox.RetryDead(c.Request.Context())
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, out, "Dead messages queued again")
+}
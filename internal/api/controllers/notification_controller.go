@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type NotificationController struct {
+	notificationService services.NotificationServiceInterface
+}
+
+func NewNotificationController(notificationService services.NotificationServiceInterface) *NotificationController {
+	return &NotificationController{notificationService: notificationService}
+}
+
+// ListNotifications godoc
+// @Summary List notifications
+// @Description In-app notifications of the current account, newest first, with the unread count. Kinds: plan.generated, subscription.activated, subscription.expiring, payment.failed; data holds the event
+// @Tags Notifications
+// @Produce json
+// @Param unread query bool false "Only unread notifications"
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(20) minimum(1) maximum(100)
+// @Success 200 {object} response_models.NotificationPage
+// @Failure 400 {object} utils.APIResponse
+// @Failure 401 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /notifications [get]
+func (n *NotificationController) ListNotifications(c *gin.Context) {
+	var query request_models.NotificationQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+
+	page, err := n.notificationService.ListNotifications(c.Request.Context(), c.GetString("user_id"), query)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, page, "Notifications fetched successfully")
+}
+
+// MarkRead godoc
+// @Summary Mark a notification read
+// @Tags Notifications
+// @Produce json
+// @Param id path string true "Notification ID"
+// @Success 200 {object} utils.APIResponse
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /notifications/{id}/read [post]
+func (n *NotificationController) MarkRead(c *gin.Context) {
+	if err := n.notificationService.MarkRead(c.Request.Context(), c.GetString("user_id"), c.Param("id")); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Notification marked as read")
+}
@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type PartnerUsageController struct {
+	usageService services.APIUsageServiceInterface
+}
+
+func NewPartnerUsageController(usageService services.APIUsageServiceInterface) *PartnerUsageController {
+	return &PartnerUsageController{usageService: usageService}
+}
+
+// GetUsage godoc
+// @Summary API usage of the account's keys
+// @Description Daily requests, errors (4xx and 5xx answers), calls throttled by the quota and the error rate of every API key of the account, plus what is left of today's quota. Days are in Vietnam time, today included; today's count may lag a few seconds behind. Callable with the bearer token or any of the account's keys
+// @Tags Partner
+// @Produce json
+// @Param days query int false "Days to cover" default(30) minimum(1) maximum(90)
+// @Success 200 {object} response_models.PartnerUsage
+// @Failure 400 {object} utils.APIResponse
+// @Failure 401 {object} utils.APIResponse
+// @Security BearerAuth
+// @Security PartnerAPIKey
+// @Router /partner/usage [get]
+func (p *PartnerUsageController) GetUsage(c *gin.Context) {
+	var query request_models.PartnerUsageQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "days must be between 1 and 90")
+		return
+	}
+
+	usage, err := p.usageService.GetUsage(c.Request.Context(), c.GetString("user_id"), query)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, usage, "API usage fetched successfully")
+}
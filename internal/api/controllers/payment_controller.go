@@ -21,7 +21,7 @@ func NewPaymentController(paymentService services.PaymentService) *PaymentContro
 
 // CreateCheckoutRequest godoc
 // @Summary Create a checkout request for a subscription plan
-// @Description Create a checkout request for a subscription plan
+// @Description Create a checkout request for a subscription plan, less the coupon when coupon_code is set. A coupon that makes the plan free activates it at once and returns no payment URL. provider picks payOS (VND bank transfers) or Stripe (international cards); by default the configured provider takes the plan's currency if it can
 // @Tags Payments
 // @Accept json
 // @Produce json
@@ -46,7 +46,7 @@ func (p *PaymentController) CreateCheckoutRequest(c *gin.Context) {
 
 	userId, _ := uuid.Parse(userid)
 
-	checkoutURL, err := p.paymentService.CreateCheckoutForPlan(c.Request.Context(), userId, request.PlanCode)
+	checkoutURL, err := p.paymentService.CreateCheckoutForPlan(c.Request.Context(), userId, request.PlanCode, request.CouponCode, request.Provider)
 	if err != nil {
 		utils.HandleServiceError(c, err)
 		return
@@ -55,8 +55,14 @@ func (p *PaymentController) CreateCheckoutRequest(c *gin.Context) {
 	utils.RespondSuccess(c, gin.H{"checkout_url": checkoutURL}, "Checkout URL created successfully")
 }
 
+// HandleWebhook receives payOS webhooks on the original route.
 func (p *PaymentController) HandleWebhook(c *gin.Context) {
-	p.paymentService.HandleWebhook(c)
+	p.paymentService.HandleWebhook(c, services.ProviderPayOS)
+}
+
+// HandleProviderWebhook receives the webhooks of the provider named in the path.
+func (p *PaymentController) HandleProviderWebhook(c *gin.Context) {
+	p.paymentService.HandleWebhook(c, c.Param("provider"))
 }
 
 // GetListOfAvailablePlans godoc
@@ -107,7 +113,7 @@ func (p *PaymentController) GetSubscriptionDetails(c *gin.Context) {
 
 // GetAllTransactionHistory godoc
 // @Summary Get all transaction history
-// @Description Retrieve all transaction history
+// @Description Retrieve the transactions of every account (admin only); accounts see their own at /payments/my-transactions
 // @Tags Payments
 // @Accept json
 // @Produce json
@@ -124,3 +130,186 @@ func (p *PaymentController) GetAllTransactionHistory(c *gin.Context) {
 
 	utils.RespondSuccess(c, data, "Transaction history retrieved successfully")
 }
+
+// PauseSubscription godoc
+// @Summary Pause the current subscription
+// @Description Freezes the days left on the running subscription; the plan's features are off until it resumes. Once per billing period; a pause ends on its own after the maximum pause (30 days by default)
+// @Tags Payments
+// @Produce json
+// @Success 200 {object} response_models.SubscriptionStatusResponse
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Failure 409 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /payments/pause-subscription [post]
+func (p *PaymentController) PauseSubscription(c *gin.Context) {
+	userId, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "user_id is required")
+		return
+	}
+
+	subscription, err := p.paymentService.PauseSubscription(c.Request.Context(), userId)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, subscription, "Subscription paused successfully")
+}
+
+// ResumeSubscription godoc
+// @Summary Resume a paused subscription
+// @Description Runs the frozen days again from now and moves the end date accordingly
+// @Tags Payments
+// @Produce json
+// @Success 200 {object} response_models.SubscriptionStatusResponse
+// @Failure 400 {object} utils.APIResponse
+// @Failure 409 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /payments/resume-subscription [post]
+func (p *PaymentController) ResumeSubscription(c *gin.Context) {
+	userId, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "user_id is required")
+		return
+	}
+
+	subscription, err := p.paymentService.ResumeSubscription(c.Request.Context(), userId)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, subscription, "Subscription resumed successfully")
+}
+
+// CancelSubscription godoc
+// @Summary Cancel the current subscription
+// @Description Turns renewal off and emails a confirmation; the plan keeps its features until the end of the paid period
+// @Tags Payments
+// @Produce json
+// @Success 200 {object} response_models.SubscriptionStatusResponse
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /payments/subscription/cancel [post]
+func (p *PaymentController) CancelSubscription(c *gin.Context) {
+	userId, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "user_id is required")
+		return
+	}
+
+	subscription, err := p.paymentService.CancelSubscription(c.Request.Context(), userId)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, subscription, "Subscription canceled; it stays active until the end of the period")
+}
+
+// SetAutoRenew godoc
+// @Summary Turn renewal of the current subscription on or off
+// @Description Emails a confirmation when the setting changes
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param request body request_models.SetAutoRenewRequest true "Enabled"
+// @Success 200 {object} response_models.SubscriptionStatusResponse
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /payments/subscription/auto-renew [post]
+func (p *PaymentController) SetAutoRenew(c *gin.Context) {
+	userId, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "user_id is required")
+		return
+	}
+	var req request_models.SetAutoRenewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "enabled is required")
+		return
+	}
+
+	subscription, err := p.paymentService.SetAutoRenew(c.Request.Context(), userId, *req.Enabled)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, subscription, "Auto-renew updated successfully")
+}
+
+// ChangePlan godoc
+// @Summary Change the current subscription to another plan
+// @Description Credits the unused part of the current subscription against the new plan. When the credit covers it the change applies at once and any leftover becomes extra days; otherwise the response carries a checkout for the difference and the change applies once it is paid.
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param request body request_models.ChangePlanRequest true "Plan code"
+// @Success 200 {object} response_models.PlanChangeResponse
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /payments/change-plan [post]
+func (p *PaymentController) ChangePlan(c *gin.Context) {
+	userId, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "user_id is required")
+		return
+	}
+	var req request_models.ChangePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "plan_code is required")
+		return
+	}
+
+	change, err := p.paymentService.ChangePlan(c.Request.Context(), userId, req.PlanCode)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, change, "Plan change created successfully")
+}
+
+// RefundTransaction godoc
+// @Summary Refund a payment (admin)
+// @Description Refunds a paid transaction through its payment provider, whole unless amount_minor asks for less. A full refund ends the subscription it paid for now; a partial one takes the same share of its period off the end. The account is notified and emailed.
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Param transactionId path string true "Transaction ID"
+// @Param request body request_models.RefundTransactionRequest false "Amount and reason"
+// @Success 200 {object} response_models.RefundResponse
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Failure 409 {object} utils.APIResponse
+// @Failure 422 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/payments/{transactionId}/refund [post]
+func (p *PaymentController) RefundTransaction(c *gin.Context) {
+	transactionID, err := uuid.Parse(c.Param("transactionId"))
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid transaction ID")
+		return
+	}
+	var req request_models.RefundTransactionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "amount_minor must be positive and reason at most 500 characters")
+			return
+		}
+	}
+
+	refund, err := p.paymentService.RefundTransaction(c.Request.Context(), transactionID, req.AmountMinor, req.Reason, c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, refund, "Payment refunded successfully")
+}
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type PersonalDataController struct {
+	personalDataService services.PersonalDataServiceInterface
+}
+
+func NewPersonalDataController(personalDataService services.PersonalDataServiceInterface) *PersonalDataController {
+	return &PersonalDataController{personalDataService: personalDataService}
+}
+
+// ExportMyData godoc
+// @Summary Download my data
+// @Description Everything stored about the caller as one JSON file, table by table, secrets such as password and key hashes left out
+// @Tags Accounts
+// @Produce json
+// @Success 200 {file} file
+// @Failure 401 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /accounts/me/data-export [get]
+func (p *PersonalDataController) ExportMyData(c *gin.Context) {
+	userID := c.GetString("user_id")
+	p.export(c, userID, userID)
+}
+
+// ExportAccountData godoc
+// @Summary Export an account's data
+// @Description Everything stored about the account as one JSON file, for a data access request (admin only)
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/accounts/{id}/data-export [get]
+func (p *PersonalDataController) ExportAccountData(c *gin.Context) {
+	accountID := c.Param("id")
+	if _, err := uuid.Parse(accountID); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid account ID")
+		return
+	}
+	p.export(c, accountID, c.GetString("user_id"))
+}
+
+func (p *PersonalDataController) export(c *gin.Context, accountID, requestedBy string) {
+	export, err := p.personalDataService.Export(c.Request.Context(), accountID, requestedBy)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", export.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, export.FileName))
+	c.Status(http.StatusOK)
+	if err := export.Write(c.Writer); err != nil {
+		// Headers are gone already; a truncated file is all the client can get
+		log.Printf("[personal-data] %s: %v", export.FileName, err)
+		c.Abort()
+	}
+}
+
+// EraseAccount godoc
+// @Summary Erase an account's data
+// @Description Delete the account's journeys and everything in them, its messages, keys and usage; redact its comments on others' journeys and slow query captures of its prompts; anonymize the account and the billing records kept for accounting. Cannot be undone (admin only)
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {object} response_models.PersonalDataErasure
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/accounts/{id}/erase [post]
+func (p *PersonalDataController) EraseAccount(c *gin.Context) {
+	accountID := c.Param("id")
+	if _, err := uuid.Parse(accountID); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid account ID")
+		return
+	}
+
+	report, err := p.personalDataService.Erase(c.Request.Context(), accountID, c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, report, "Account data erased")
+}
+
+// GetInventory godoc
+// @Summary Personal data inventory
+// @Description Every table holding personal data, why it is kept and what erasing an account does to it; with account_id, how many of the account's rows each holds (admin only)
+// @Tags Admin
+// @Produce json
+// @Param account_id query string false "Account ID"
+// @Success 200 {object} response_models.PersonalDataInventory
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/privacy/inventory [get]
+func (p *PersonalDataController) GetInventory(c *gin.Context) {
+	inventory, err := p.personalDataService.Inventory(c.Request.Context(), c.Query("account_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, inventory, "Personal data inventory fetched successfully")
+}
@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type PlanController struct {
+	planService services.PlanServiceInterface
+}
+
+func NewPlanController(planService services.PlanServiceInterface) *PlanController {
+	return &PlanController{planService: planService}
+}
+
+// ListPlans godoc
+// @Summary List the plan catalog
+// @Description Every plan, retired and inactive ones included, with its entitlements and running subscriptions (admin only)
+// @Tags Admin
+// @Produce json
+// @Success 200 {array} response_models.AdminPlan
+// @Failure 401 {object} utils.APIResponse
+// @Failure 403 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/plans [get]
+func (p *PlanController) ListPlans(c *gin.Context) {
+	plans, err := p.planService.ListCatalog(c.Request.Context())
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, plans, "Plans fetched successfully")
+}
+
+// CreatePlan godoc
+// @Summary Create a plan
+// @Description Entitlements: unlimited_plans (bool), max_trip_days (int), ai_profile (string) (admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body request_models.CreatePlanRequest true "Plan"
+// @Success 200 {object} response_models.AdminPlan
+// @Failure 400 {object} utils.APIResponse
+// @Failure 409 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/plans [post]
+func (p *PlanController) CreatePlan(c *gin.Context) {
+	var req request_models.CreatePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	plan, err := p.planService.CreatePlan(c.Request.Context(), req, c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, plan, "Plan created successfully")
+}
+
+// UpdatePlan godoc
+// @Summary Update a plan
+// @Description Replaces the plan. Price, currency and period cannot change while subscriptions are running on it: create a new version instead (admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Plan ID"
+// @Param request body request_models.UpdatePlanRequest true "Plan"
+// @Success 200 {object} response_models.AdminPlan
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Failure 409 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/plans/{id} [put]
+func (p *PlanController) UpdatePlan(c *gin.Context) {
+	var req request_models.UpdatePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	plan, err := p.planService.UpdatePlan(c.Request.Context(), c.Param("id"), req, c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, plan, "Plan updated successfully")
+}
+
+// CreatePlanVersion godoc
+// @Summary Create a new version of a plan
+// @Description Copies the plan with the given changes and deactivates the old one; running subscriptions keep the price they paid (admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Plan ID"
+// @Param request body request_models.CreatePlanVersionRequest true "Changes"
+// @Success 200 {object} response_models.AdminPlan
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Failure 409 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/plans/{id}/versions [post]
+func (p *PlanController) CreatePlanVersion(c *gin.Context) {
+	var req request_models.CreatePlanVersionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	plan, err := p.planService.CreateVersion(c.Request.Context(), c.Param("id"), req, c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, plan, "Plan version created successfully")
+}
+
+// ActivatePlan godoc
+// @Summary Activate a plan
+// @Description Offers the plan to new checkouts again (admin only)
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Plan ID"
+// @Success 200 {object} response_models.AdminPlan
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/plans/{id}/activate [post]
+func (p *PlanController) ActivatePlan(c *gin.Context) {
+	plan, err := p.planService.SetActive(c.Request.Context(), c.Param("id"), true, c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, plan, "Plan activated successfully")
+}
+
+// DeactivatePlan godoc
+// @Summary Deactivate a plan
+// @Description Withdraws the plan from new checkouts; running subscriptions keep it until they end (admin only)
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Plan ID"
+// @Success 200 {object} response_models.AdminPlan
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/plans/{id}/deactivate [post]
+func (p *PlanController) DeactivatePlan(c *gin.Context) {
+	plan, err := p.planService.SetActive(c.Request.Context(), c.Param("id"), false, c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, plan, "Plan deactivated successfully")
+}
+
+// ListPlanAudit godoc
+// @Summary List the audit trail of a plan
+// @Description Every admin change to the plan, newest first, with the fields it changed (admin only)
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Plan ID"
+// @Success 200 {array} response_models.PlanAuditEntry
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/plans/{id}/audit [get]
+func (p *PlanController) ListPlanAudit(c *gin.Context) {
+	entries, err := p.planService.ListAudit(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, entries, "Plan audit fetched successfully")
+}
+
+// DeletePlan godoc
+// @Summary Delete a plan
+// @Description Only plans nobody subscribed to can be deleted; deactivate the others (admin only)
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Plan ID"
+// @Success 200 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Failure 409 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/plans/{id} [delete]
+func (p *PlanController) DeletePlan(c *gin.Context) {
+	if err := p.planService.DeletePlan(c.Request.Context(), c.Param("id"), c.GetString("user_id")); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Plan deleted successfully")
+}
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type PlanningPolicyController struct {
+	policyService services.PlanningPolicyServiceInterface
+}
+
+func NewPlanningPolicyController(policyService services.PlanningPolicyServiceInterface) *PlanningPolicyController {
+	return &PlanningPolicyController{policyService: policyService}
+}
+
+// GetPolicy godoc
+// @Summary Planning policy of the account
+// @Description What every plan generated for the account excludes, favours and always includes. Empty until a policy is set. Callable with the bearer token or any of the account's keys
+// @Tags Partner
+// @Produce json
+// @Success 200 {object} response_models.PlanningPolicyResponse
+// @Failure 401 {object} utils.APIResponse
+// @Security BearerAuth
+// @Security PartnerAPIKey
+// @Router /partner/planning-policy [get]
+func (p *PlanningPolicyController) GetPolicy(c *gin.Context) {
+	policy, err := p.policyService.GetPolicy(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, policy, "Planning policy fetched successfully")
+}
+
+// SetPolicy godoc
+// @Summary Replace the planning policy of the account
+// @Description exclude takes categories or words, matched like a traveler's avoid answers (e.g. "Nightlife & bars", "karaoke"); excluded places are left out of retrieval and removed from the plan. boost_poi_ids are favoured and require_poi_ids scheduled in every plan whose destination is in their province; a required POI the model leaves out is added to the lightest day. The traveler's own exclusions still win. Needs a key with planning_policy:write, or the bearer token
+// @Tags Partner
+// @Accept json
+// @Produce json
+// @Param request body request_models.SetPlanningPolicyRequest true "Policy"
+// @Success 200 {object} response_models.PlanningPolicyResponse
+// @Failure 400 {object} utils.APIResponse
+// @Failure 401 {object} utils.APIResponse
+// @Failure 403 {object} utils.APIResponse
+// @Security BearerAuth
+// @Security PartnerAPIKey
+// @Router /partner/planning-policy [put]
+func (p *PlanningPolicyController) SetPolicy(c *gin.Context) {
+	var req request_models.SetPlanningPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "exclude takes at most 50 entries of 100 characters, boost_poi_ids at most 50 and require_poi_ids at most 10 POI ids")
+		return
+	}
+
+	updatedBy := c.GetString("api_key_id")
+	if updatedBy == "" {
+		updatedBy = c.GetString("user_id")
+	}
+	policy, err := p.policyService.SetPolicy(c.Request.Context(), c.GetString("user_id"), req, updatedBy)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, policy, "Planning policy saved")
+}
+
+// DeletePolicy godoc
+// @Summary Remove the planning policy of the account
+// @Description Needs a key with planning_policy:write, or the bearer token
+// @Tags Partner
+// @Produce json
+// @Success 200 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Security PartnerAPIKey
+// @Router /partner/planning-policy [delete]
+func (p *PlanningPolicyController) DeletePolicy(c *gin.Context) {
+	if err := p.policyService.DeletePolicy(c.Request.Context(), c.GetString("user_id")); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Planning policy removed")
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type POIExportController struct {
+	exportService services.ExportServiceInterface
+}
+
+func NewPOIExportController(exportService services.ExportServiceInterface) *POIExportController {
+	return &POIExportController{exportService: exportService}
+}
+
+// ExportPOIs godoc
+// @Summary Export POIs
+// @Description Stream all POIs matching the filters as an XLSX, CSV or JSON file. The CSV/XLSX columns match the bulk import headers (admin only)
+// @Tags POIs
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce text/csv
+// @Produce json
+// @Param format query string false "xlsx (default), csv or json"
+// @Param province query string false "Province ID or name"
+// @Param category query string false "Category ID or name"
+// @Param updated_since query string false "RFC3339 time, YYYY-MM-DD (VN) or unix seconds"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /pois/export [get]
+func (p *POIExportController) ExportPOIs(c *gin.Context) {
+	var query request_models.POIExportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+
+	export, err := p.exportService.ExportPOIs(c.Request.Context(), query)
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidInput) {
+			utils.RespondError(c, http.StatusBadRequest, "format must be xlsx, csv or json; province and category must be an existing ID or name; updated_since must be a date or time")
+			return
+		}
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", export.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, export.FileName))
+	c.Status(http.StatusOK)
+	if err := export.Write(c.Writer); err != nil {
+		// Headers are gone already; a truncated file is all the client can get
+		log.Printf("[poi-export] %s: %v", export.FileName, err)
+		c.Abort()
+	}
+}
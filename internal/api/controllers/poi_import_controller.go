@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"strconv"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+const maxPOIImportFileBytes = 10 << 20
+
+type POIImportController struct {
+	importService services.BulkImportServiceInterface
+}
+
+func NewPOIImportController(importService services.BulkImportServiceInterface) *POIImportController {
+	return &POIImportController{importService: importService}
+}
+
+// ImportPOIs godoc
+// @Summary Bulk import POIs
+// @Description Import POIs from a CSV or XLSX file (columns: name, latitude, longitude, province, category, address, opening_hours, contact_info, description, images). Province and category accept a name or an ID; a blank province is taken from the stored province outline the coordinates fall in, and a given one must contain them when its outline is known. Valid rows are inserted in one transaction and queued for embedding; every rejected row is listed in the report (admin only)
+// @Tags POIs
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV or XLSX file (max 10 MB, 5000 rows)"
+// @Param dry_run query bool false "Validate only, insert nothing"
+// @Success 200 {object} response_models.POIImportReport
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /pois/import [post]
+func (p *POIImportController) ImportPOIs(c *gin.Context) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "dry_run must be true or false")
+		return
+	}
+
+	fh, err := c.FormFile("file")
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "file is required")
+		return
+	}
+	if fh.Size > maxPOIImportFileBytes {
+		utils.RespondError(c, http.StatusBadRequest, "file is too large (max 10 MB)")
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Cannot read file")
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxPOIImportFileBytes))
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Cannot read file")
+		return
+	}
+
+	report, err := p.importService.ImportPOIs(c.Request.Context(), fh.Filename, data, dryRun)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	msg := "POIs imported"
+	if dryRun {
+		msg = "Dry run completed, nothing was inserted"
+	}
+	utils.RespondSuccess(c, report, msg)
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type POIProvinceFlagController struct {
+	checkService services.POIProvinceCheckServiceInterface
+}
+
+func NewPOIProvinceFlagController(checkService services.POIProvinceCheckServiceInterface) *POIProvinceFlagController {
+	return &POIProvinceFlagController{checkService: checkService}
+}
+
+// ListFlags godoc
+// @Summary Province correction queue
+// @Description POIs whose coordinates do not match their province: in another province (wrong_province), only once latitude and longitude are swapped (swapped_coords), or outside every known outline (outside). Newest first (admin only)
+// @Tags Admin
+// @Produce json
+// @Param status query string false "open, applied, dismissed or cleared" default(open)
+// @Param kind query string false "wrong_province, swapped_coords or outside"
+// @Param province_id query string false "Province the POI is assigned to"
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(20) minimum(1) maximum(100)
+// @Success 200 {object} response_models.POIProvinceFlagPage
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/pois/province-flags [get]
+func (p *POIProvinceFlagController) ListFlags(c *gin.Context) {
+	var query request_models.POIProvinceFlagQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+
+	page, err := p.checkService.ListFlags(c.Request.Context(), query)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, page, "Province flags fetched successfully")
+}
+
+// RunCheck godoc
+// @Summary Run the province check now
+// @Description Check every POI against the province outlines without waiting for the scheduled run (admin only)
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} response_models.POIProvinceScan
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/pois/province-flags/scan [post]
+func (p *POIProvinceFlagController) RunCheck(c *gin.Context) {
+	scan, err := p.checkService.RunOnce(c.Request.Context())
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, scan, "Province check completed")
+}
+
+// ApplyFlag godoc
+// @Summary Apply a suggested correction
+// @Description Move the POI to the suggested province and/or swap its coordinates, provided it did not change since the check (admin only)
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Flag ID"
+// @Success 200 {object} utils.APIResponse
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/pois/province-flags/{id}/apply [post]
+func (p *POIProvinceFlagController) ApplyFlag(c *gin.Context) {
+	if err := p.checkService.ApplyFlag(c.Request.Context(), c.Param("id"), c.GetString("user_id")); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Correction applied successfully")
+}
+
+// DismissFlag godoc
+// @Summary Dismiss a flag
+// @Description The POI is right as it is; it is not flagged again until its coordinates or province change (admin only)
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Flag ID"
+// @Success 200 {object} utils.APIResponse
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/pois/province-flags/{id}/dismiss [post]
+func (p *POIProvinceFlagController) DismissFlag(c *gin.Context) {
+	if err := p.checkService.DismissFlag(c.Request.Context(), c.Param("id"), c.GetString("user_id")); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Flag dismissed successfully")
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type POIQualityController struct {
+	qualityService services.POIQualityServiceInterface
+}
+
+func NewPOIQualityController(qualityService services.POIQualityServiceInterface) *POIQualityController {
+	return &POIQualityController{qualityService: qualityService}
+}
+
+// ListScores godoc
+// @Summary List POI quality scores
+// @Description Completeness score (0-100) of each POI from its coordinates, opening hours, images, description length, embedding and reviews, lowest first, with what is missing (admin only)
+// @Tags Admin
+// @Produce json
+// @Param province_id query string false "Province ID"
+// @Param category_id query string false "Category ID"
+// @Param status query string false "POI status"
+// @Param min_score query int false "Minimum score"
+// @Param max_score query int false "Maximum score (inclusive)"
+// @Param missing query string false "coords, hours, images, description, embedding or reviews"
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(20) minimum(1) maximum(100)
+// @Success 200 {object} response_models.POIQualityPage
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/pois/quality [get]
+func (p *POIQualityController) ListScores(c *gin.Context) {
+	var query request_models.POIQualityQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+
+	page, err := p.qualityService.ListScores(c.Request.Context(), query)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, page, "POI quality scores fetched successfully")
+}
+
+// GetReport godoc
+// @Summary POI quality gap report
+// @Description How many POIs miss each signal, how many need attention and the average score per province, worst first. Takes the same filters as the score list (admin only)
+// @Tags Admin
+// @Produce json
+// @Param province_id query string false "Province ID"
+// @Param category_id query string false "Category ID"
+// @Param status query string false "POI status"
+// @Param missing query string false "coords, hours, images, description, embedding or reviews"
+// @Success 200 {object} response_models.POIQualityReport
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/pois/quality/report [get]
+func (p *POIQualityController) GetReport(c *gin.Context) {
+	var query request_models.POIQualityQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+
+	report, err := p.qualityService.GetReport(c.Request.Context(), query)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, report, "POI quality report fetched successfully")
+}
+
+// ExportNeedsAttention godoc
+// @Summary Export POIs needing attention
+// @Description Stream the POIs scoring below the attention threshold (POI_QUALITY_ATTENTION_BELOW, default 60), or up to max_score, with their gaps as XLSX or CSV (admin only)
+// @Tags Admin
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce text/csv
+// @Param format query string false "xlsx (default) or csv"
+// @Param province_id query string false "Province ID"
+// @Param category_id query string false "Category ID"
+// @Param status query string false "POI status"
+// @Param max_score query int false "Maximum score (inclusive)"
+// @Param missing query string false "coords, hours, images, description, embedding or reviews"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/pois/quality/export [get]
+func (p *POIQualityController) ExportNeedsAttention(c *gin.Context) {
+	var query request_models.POIQualityExportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+
+	export, err := p.qualityService.ExportNeedsAttention(c.Request.Context(), query)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", export.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, export.FileName))
+	c.Status(http.StatusOK)
+	if err := export.Write(c.Writer); err != nil {
+		// Headers are gone already; a truncated file is all the client can get
+		log.Printf("[poi-quality] %s: %v", export.FileName, err)
+		c.Abort()
+	}
+}
@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type POIRatingController struct {
+	ratingService services.RatingEnrichmentServiceInterface
+}
+
+func NewPOIRatingController(ratingService services.RatingEnrichmentServiceInterface) *POIRatingController {
+	return &POIRatingController{ratingService: ratingService}
+}
+
+// SetExternalRef godoc
+// @Summary Link a POI to a third-party listing
+// @Description Store the Google place ID or Tripadvisor location ID of a POI and fetch its rating right away (admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "POI ID"
+// @Param request body request_models.SetPOIExternalRefRequest true "External reference"
+// @Success 200 {object} response_models.ExternalRating
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/pois/{id}/external-refs [put]
+func (p *POIRatingController) SetExternalRef(c *gin.Context) {
+	var req request_models.SetPOIExternalRefRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "source must be google or tripadvisor and external_id is required")
+		return
+	}
+
+	rating, err := p.ratingService.SetExternalRef(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, rating, "External reference saved")
+}
+
+// RefreshRatings godoc
+// @Summary Refresh third-party ratings of a POI
+// @Description Fetch fresh rating snapshots for every external listing linked to the POI (admin only)
+// @Tags Admin
+// @Produce json
+// @Param id path string true "POI ID"
+// @Success 200 {array} response_models.ExternalRating
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/pois/{id}/external-refs/refresh [post]
+func (p *POIRatingController) RefreshRatings(c *gin.Context) {
+	ratings, err := p.ratingService.RefreshPOI(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, ratings, "Ratings refreshed")
+}
@@ -3,8 +3,10 @@ package controllers
 import (
 	"context"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"net/http"
 	"strconv"
+	"strings"
 	"vivu/internal/models/request_models"
 	"vivu/internal/services"
 	"vivu/pkg/utils"
@@ -27,6 +29,7 @@ func NewPOIsController(poiService services.POIServiceInterface) *POIsController
 // @Param id path string true "POI ID"
 // @Success 200 {object} response_models.POI
 // @Failure 404 {object} utils.APIResponse
+// @Security PartnerAPIKey
 // @Router /pois/pois-details/{id} [get]
 func (p *POIsController) GetPoiById(c *gin.Context) {
 	poiId := c.Param("id")
@@ -54,6 +57,7 @@ func (p *POIsController) GetPoiById(c *gin.Context) {
 // @Success 200 {array} response_models.POI
 // @Failure 400 {object} utils.APIResponse
 // @Failure 404 {object} utils.APIResponse
+// @Security PartnerAPIKey
 // @Router /pois/provinces/{provinceId} [get]
 func (p *POIsController) GetPoisByProvince(c *gin.Context) {
 	provinceId := c.Param("provinceId")
@@ -88,7 +92,7 @@ func (p *POIsController) GetPoisByProvince(c *gin.Context) {
 
 // CreatePoi godoc
 // @Summary Create a new POI
-// @Description Create a new Point of Interest (POI)
+// @Description Create a new Point of Interest (POI). Coordinates must lie in the supported country (POI_COUNTRY, Vietnam by default): out of range, 0,0, swapped or outside points are rejected
 // @Tags POIs
 // @Accept json
 // @Produce json
@@ -143,12 +147,13 @@ func (p *POIsController) DeletePoi(c *gin.Context) {
 
 // UpdatePoi godoc
 // @Summary Update a POI
-// @Description Update a Point of Interest (POI) by its ID
+// @Description Update a Point of Interest (POI) by its ID. Coordinates must lie in the supported country (POI_COUNTRY, Vietnam by default): out of range, 0,0, swapped or outside points are rejected
 // @Tags POIs
 // @Accept json
 // @Produce json
 // @Param request body request_models.UpdatePoiRequest true "POI update payload"
 // @Success 200 {object} utils.APIResponse
+// @Failure 400 {object} utils.APIResponse
 // @Security BearerAuth
 // @Router /pois/update-poi [put]
 func (p *POIsController) UpdatePoi(c *gin.Context) {
@@ -173,6 +178,7 @@ func (p *POIsController) UpdatePoi(c *gin.Context) {
 // @Param page query int false "Page number" default(1)
 // @Param pageSize query int false "Page size" default(5) minimum(1) maximum(100)
 // @Success 200 {array} response_models.POI
+// @Security PartnerAPIKey
 // @Router /pois/list-pois [get]
 func (p *POIsController) ListPois(c *gin.Context) {
 
@@ -209,6 +215,7 @@ func (p *POIsController) ListPois(c *gin.Context) {
 // @Param pageSize query int false "Page size" default(5) minimum(1) maximum(100)
 // @Success 200 {array} response_models.POI
 // @Failure 400 {object} utils.APIResponse
+// @Security PartnerAPIKey
 // @Router /pois/search-poi-by-name-and-province [get]
 func (p *POIsController) SearchPoiByNameAndProvince(c *gin.Context) {
 	name := c.Query("name")
@@ -237,3 +244,98 @@ func (p *POIsController) SearchPoiByNameAndProvince(c *gin.Context) {
 
 	utils.RespondSuccess(c, pois, "POIs fetched successfully")
 }
+
+// SearchPOIs godoc
+// @Summary Full-text POI search
+// @Description Ranked, accent-insensitive search over POI name, address and description ("ho xuan huong" finds "Hồ Xuân Hương"). Words match as prefixes
+// @Tags POIs
+// @Param q query string true "Search text (at least 2 characters)"
+// @Param provinceId query string false "Limit to a province"
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(10) minimum(1) maximum(100)
+// @Success 200 {object} response_models.POISearchPage
+// @Failure 400 {object} utils.APIResponse
+// @Security PartnerAPIKey
+// @Router /pois/search [get]
+func (p *POIsController) SearchPOIs(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if len([]rune(q)) < 2 {
+		utils.RespondError(c, http.StatusBadRequest, "q must be at least 2 characters")
+		return
+	}
+
+	provinceID := c.Query("provinceId")
+	if provinceID != "" {
+		if _, err := uuid.Parse(provinceID); err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "Invalid province ID")
+			return
+		}
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid page number")
+		return
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	if err != nil || pageSize < 1 || pageSize > 100 {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid page size (must be 1-100)")
+		return
+	}
+
+	result, err := p.poiService.SearchPOIs(c.Request.Context(), q, provinceID, page, pageSize)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, result, "POIs fetched successfully")
+}
+
+// FindNearbyPOIs godoc
+// @Summary POIs around a location
+// @Description Published POIs within the radius of a point, nearest first, with the straight-line distance in meters
+// @Tags POIs
+// @Param lat query number true "Latitude"
+// @Param lng query number true "Longitude"
+// @Param radius query int false "Radius in meters" default(2000) minimum(50) maximum(50000)
+// @Param category query string false "Category ID or name"
+// @Param limit query int false "Max results" default(20) minimum(1) maximum(100)
+// @Success 200 {array} response_models.POINearbyHit
+// @Failure 400 {object} utils.APIResponse
+// @Security PartnerAPIKey
+// @Router /pois/nearby [get]
+func (p *POIsController) FindNearbyPOIs(c *gin.Context) {
+	lat, err := strconv.ParseFloat(c.Query("lat"), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid lat (must be -90 to 90)")
+		return
+	}
+
+	lng, err := strconv.ParseFloat(c.Query("lng"), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid lng (must be -180 to 180)")
+		return
+	}
+
+	radius, err := strconv.ParseFloat(c.DefaultQuery("radius", "2000"), 64)
+	if err != nil || radius < 50 || radius > 50000 {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid radius (must be 50-50000 meters)")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid limit (must be 1-100)")
+		return
+	}
+
+	pois, err := p.poiService.FindNearbyPOIs(c.Request.Context(), lat, lng, radius, strings.TrimSpace(c.Query("category")), limit)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, pois, "POIs fetched successfully")
+}
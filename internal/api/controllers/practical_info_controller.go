@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type PracticalInfoController struct {
+	infoService services.PracticalInfoServiceInterface
+}
+
+func NewPracticalInfoController(infoService services.PracticalInfoServiceInterface) *PracticalInfoController {
+	return &PracticalInfoController{infoService: infoService}
+}
+
+// GetInfo godoc
+// @Summary Practical info of a province
+// @Description SIM/eSIM options, typical taxi costs, tipping norms and other tips curated by admins
+// @Tags Provinces
+// @Produce json
+// @Param provinceId path string true "Province ID"
+// @Success 200 {object} response_models.PracticalInfoResponse
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Security PartnerAPIKey
+// @Router /provinces/{provinceId}/practical-info [get]
+func (p *PracticalInfoController) GetInfo(c *gin.Context) {
+	info, err := p.infoService.GetInfo(c.Request.Context(), c.Param("provinceId"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, info, "Practical info fetched successfully")
+}
+
+// ListInfo godoc
+// @Summary List practical info
+// @Description Practical info block of every province that has one (admin only)
+// @Tags Admin
+// @Produce json
+// @Success 200 {array} response_models.PracticalInfoResponse
+// @Security BearerAuth
+// @Router /admin/practical-info [get]
+func (p *PracticalInfoController) ListInfo(c *gin.Context) {
+	infos, err := p.infoService.ListInfo(c.Request.Context())
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, infos, "Practical info fetched successfully")
+}
+
+// SetInfo godoc
+// @Summary Create or replace the practical info of a province
+// @Description The lines are added to the general tips of generated itineraries for that province (admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param provinceId path string true "Province ID"
+// @Param request body request_models.SetPracticalInfoRequest true "Practical info"
+// @Success 200 {object} response_models.PracticalInfoResponse
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/practical-info/{provinceId} [put]
+func (p *PracticalInfoController) SetInfo(c *gin.Context) {
+	var req request_models.SetPracticalInfoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "each list takes at most 10 lines of 300 characters and tipping at most 500 characters")
+		return
+	}
+
+	info, err := p.infoService.SetInfo(c.Request.Context(), c.Param("provinceId"), req, c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, info, "Practical info saved")
+}
+
+// DeleteInfo godoc
+// @Summary Remove the practical info of a province
+// @Tags Admin
+// @Produce json
+// @Param provinceId path string true "Province ID"
+// @Success 200 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/practical-info/{provinceId} [delete]
+func (p *PracticalInfoController) DeleteInfo(c *gin.Context) {
+	if err := p.infoService.DeleteInfo(c.Request.Context(), c.Param("provinceId")); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Practical info removed")
+}
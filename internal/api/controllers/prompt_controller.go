@@ -2,21 +2,26 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"net/http"
+	"path"
 	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
 	"vivu/internal/services"
 	"vivu/pkg/utils"
 )
 
 type PromptController struct {
-	promptService services.PromptServiceInterface
+	promptService  services.PromptServiceInterface
+	explainService services.PlanExplainServiceInterface
 }
 
-func NewPromptController(promptService services.PromptServiceInterface) *PromptController {
+func NewPromptController(promptService services.PromptServiceInterface, explainService services.PlanExplainServiceInterface) *PromptController {
 	return &PromptController{
-		promptService: promptService,
+		promptService:  promptService,
+		explainService: explainService,
 	}
 }
 
@@ -29,7 +34,7 @@ func (p *PromptController) CreatePromptHandler(c *gin.Context) {
 
 	ctx := context.Background()
 
-	createdPrompt, err := p.promptService.CreateNarrativeAIPlan(ctx, req.Prompt)
+	createdPrompt, err := p.promptService.CreateNarrativeAIPlan(ctx, req.Prompt, c.GetString("user_id"))
 	if err != nil {
 		utils.HandleServiceError(c, err)
 		return
@@ -40,11 +45,11 @@ func (p *PromptController) CreatePromptHandler(c *gin.Context) {
 
 // StartQuizHandler godoc
 // @Summary Start a travel quiz
-// @Description Start a quiz session for the user
+// @Description Start a quiz session for the user. With preset_id, one of the caller's travel presets fills the answers it covers (returned as prefilled) and their questions are skipped
 // @Tags Prompt
 // @Accept json
 // @Produce json
-// @Param request body request_models.QuizStartRequest true "User ID for quiz session"
+// @Param request body request_models.QuizStartRequest true "User ID for quiz session, optional preset"
 // @Success 200 {object} response_models.QuizResponse
 // @Failure 400 {object} utils.APIResponse
 // @Security BearerAuth
@@ -55,23 +60,30 @@ func (p *PromptController) StartQuizHandler(c *gin.Context) {
 		utils.RespondError(c, http.StatusBadRequest, "user_id is required")
 		return
 	}
-	resp, err := p.promptService.StartTravelQuiz(c.Request.Context(), req.UserID)
+	if req.PresetID != "" && req.UserID != c.GetString("user_id") {
+		utils.RespondError(c, http.StatusForbidden, "Presets can only be used in your own quiz")
+		return
+	}
+	resp, err := p.promptService.StartTravelQuiz(c.Request.Context(), req.UserID, req.PresetID)
 	if err != nil {
 		utils.HandleServiceError(c, err)
 		return
 	}
+	resp.NextEndpoint = quizEndpoint(c, resp.NextStep)
 	utils.RespondSuccess(c, resp, "Quiz started")
 }
 
 // AnswerQuizHandler godoc
 // @Summary Submit quiz answers
-// @Description Process answers for a quiz session
+// @Description Process answers for a quiz session. Every answer keeps the session alive for another QUIZ_SESSION_TTL; once it expired the answer is refused with 410 and data.restart_endpoint
 // @Tags Prompt
 // @Accept json
 // @Produce json
 // @Param request body request_models.QuizRequest true "Quiz answers and session ID"
 // @Success 200 {object} response_models.QuizResponse
 // @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse "No quiz session of the caller with this ID"
+// @Failure 410 {object} utils.APIResponse "Session expired; data is a response_models.QuizExpired"
 // @Security BearerAuth
 // @Router /prompt/quiz/answer [post]
 func (p *PromptController) AnswerQuizHandler(c *gin.Context) {
@@ -80,14 +92,72 @@ func (p *PromptController) AnswerQuizHandler(c *gin.Context) {
 		utils.RespondError(c, http.StatusBadRequest, "session_id is required")
 		return
 	}
-	resp, err := p.promptService.ProcessQuizAnswer(c.Request.Context(), req)
+	resp, err := p.promptService.ProcessQuizAnswer(c.Request.Context(), c.GetString("user_id"), req)
 	if err != nil {
-		utils.HandleServiceError(c, err)
+		handleQuizError(c, err)
 		return
 	}
+	resp.NextEndpoint = quizEndpoint(c, resp.NextStep)
 	utils.RespondSuccess(c, resp, "Answer accepted")
 }
 
+// QuizHeartbeatHandler godoc
+// @Summary Keep a quiz session alive
+// @Description Extends the caller's quiz session while the traveller is still busy with a question. Send it on activity, not on a timer: an abandoned quiz should expire. Answers the current step and the new expires_at / warn_at
+// @Tags Prompt
+// @Accept json
+// @Produce json
+// @Param request body request_models.QuizHeartbeatRequest true "Session ID"
+// @Success 200 {object} response_models.QuizResponse
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Failure 410 {object} utils.APIResponse "Session expired; data is a response_models.QuizExpired"
+// @Security BearerAuth
+// @Router /prompt/quiz/heartbeat [post]
+func (p *PromptController) QuizHeartbeatHandler(c *gin.Context) {
+	var req request_models.QuizHeartbeatRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.SessionID == "" {
+		utils.RespondError(c, http.StatusBadRequest, "session_id is required")
+		return
+	}
+	resp, err := p.promptService.KeepQuizAlive(c.Request.Context(), req.SessionID, c.GetString("user_id"))
+	if err != nil {
+		handleQuizError(c, err)
+		return
+	}
+	resp.NextEndpoint = quizEndpoint(c, resp.NextStep)
+	utils.RespondSuccess(c, resp, "Quiz session kept alive")
+}
+
+// quizStepRoutes are the routes of the quiz steps within the quiz group.
+var quizStepRoutes = map[string]string{
+	response_models.QuizStepStart:  "start",
+	response_models.QuizStepAnswer: "answer",
+	response_models.QuizStepPlan:   "plan-only",
+}
+
+// quizEndpoint resolves a quiz step to its route next to the one being served, so the
+// endpoints follow the router wherever the quiz group is mounted.
+func quizEndpoint(c *gin.Context, step string) string {
+	leaf, ok := quizStepRoutes[step]
+	if !ok || c.FullPath() == "" {
+		return ""
+	}
+	return path.Join(path.Dir(c.FullPath()), leaf)
+}
+
+// handleQuizError points the client of an expired quiz session to a new quiz.
+func handleQuizError(c *gin.Context, err error) {
+	var appErr *utils.AppError
+	if errors.As(err, &appErr) {
+		if data, ok := appErr.Data.(response_models.QuizExpired); ok {
+			data.RestartEndpoint = quizEndpoint(c, response_models.QuizStepStart)
+			err = appErr.WithData(data)
+		}
+	}
+	utils.HandleServiceError(c, err)
+}
+
 // PlanOnlyHandler godoc
 // @Summary Generate a travel plan without quiz
 // @Description Generate a travel plan based on session ID
@@ -97,6 +167,9 @@ func (p *PromptController) AnswerQuizHandler(c *gin.Context) {
 // @Param request body request_models.PlanOnlyRequest true "Session ID for plan generation"
 // @Success 200 {object} response_models.PlanOnly
 // @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse "No quiz session of the caller with this ID"
+// @Failure 410 {object} utils.APIResponse "Session expired; data is a response_models.QuizExpired"
+// @Failure 429 {object} utils.APIResponse "Free monthly plan quota used up; data is a utils.PlanQuota"
 // @Security BearerAuth
 // @Router /prompt/quiz/plan-only [post]
 func (p *PromptController) PlanOnlyHandler(c *gin.Context) {
@@ -105,6 +178,10 @@ func (p *PromptController) PlanOnlyHandler(c *gin.Context) {
 		utils.RespondError(c, http.StatusBadRequest, "session_id is required")
 		return
 	}
+	if !services.IsValidTravelMode(req.Mode) {
+		utils.RespondError(c, http.StatusBadRequest, "mode must be driving, walking or cycling")
+		return
+	}
 
 	userid := c.GetString("user_id")
 
@@ -119,11 +196,66 @@ func (p *PromptController) PlanOnlyHandler(c *gin.Context) {
 		return
 	}
 
-	plan, err := p.promptService.GeneratePlanAndSave(c.Request.Context(), req.SessionID, userUUID)
+	plan, err := p.promptService.GeneratePlanAndSave(c.Request.Context(), req.SessionID, userUUID, req.Mode)
 	if err != nil {
-		err = utils.ErrUserDoNotHavePremium
-		utils.HandleServiceError(c, err)
+		handleQuizError(c, err)
 		return
 	}
 	utils.RespondSuccess(c, plan, "Plan-only generated")
 }
+
+// RegenerateDayHandler godoc
+// @Summary Regenerate one day of a journey
+// @Description Replan a single day of a saved journey. POIs already used on the other days are never scheduled again; rule_adjustments lists what was replaced or removed
+// @Tags Prompt
+// @Accept json
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Param request body request_models.RegenerateDayRequest true "Day number"
+// @Success 200 {object} response_models.PlanOnly
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/regenerate-day [post]
+func (p *PromptController) RegenerateDayHandler(c *gin.Context) {
+	journeyId := c.Param("journeyId")
+	if _, err := uuid.Parse(journeyId); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid journey ID")
+		return
+	}
+
+	var req request_models.RegenerateDayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "day_number is required")
+		return
+	}
+
+	plan, err := p.promptService.RegenerateJourneyDay(c.Request.Context(), journeyId, c.GetString("user_id"), req.DayNumber)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, plan, "Day regenerated")
+}
+
+// ExplainActivity godoc
+// @Summary Explain why a plan picked a place
+// @Description Human-readable reasons an activity's POI was chosen (match with the request, interests, location, ratings), with the retrieval signals kept when the plan was generated. planId is the journey the plan was saved as; owner and members only
+// @Tags Prompt
+// @Produce json
+// @Param planId path string true "Plan (journey) ID"
+// @Param activityId path string true "Activity ID"
+// @Success 200 {object} response_models.ActivityExplanation
+// @Failure 400 {object} utils.APIResponse
+// @Failure 403 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /prompt/explain/{planId}/{activityId} [get]
+func (p *PromptController) ExplainActivity(c *gin.Context) {
+	explanation, err := p.explainService.ExplainActivity(c.Request.Context(), c.GetString("user_id"), c.Param("planId"), c.Param("activityId"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, explanation, "Explanation retrieved")
+}
@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type ProvinceBoundaryController struct {
+	boundaryService services.ProvinceBoundaryServiceInterface
+}
+
+func NewProvinceBoundaryController(boundaryService services.ProvinceBoundaryServiceInterface) *ProvinceBoundaryController {
+	return &ProvinceBoundaryController{boundaryService: boundaryService}
+}
+
+// ListBoundaries godoc
+// @Summary Province boundaries for the map
+// @Description GeoJSON FeatureCollection of every province with a known outline, simplified so a whole-country map stays light. Provinces without an outline are left out
+// @Tags Provinces
+// @Produce json
+// @Param tolerance query number false "Simplification in degrees, 0 for the full outline" default(0.005) minimum(0) maximum(0.5)
+// @Success 200 {object} response_models.ProvinceBoundaryCollection
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Security PartnerAPIKey
+// @Router /provinces/boundaries [get]
+func (p *ProvinceBoundaryController) ListBoundaries(c *gin.Context) {
+	tolerance, ok := boundaryTolerance(c)
+	if !ok {
+		return
+	}
+
+	boundaries, err := p.boundaryService.ListBoundaries(c.Request.Context(), tolerance)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, boundaries, "Province boundaries fetched successfully")
+}
+
+// GetBoundary godoc
+// @Summary Boundary of one province
+// @Description GeoJSON Feature with the province outline, simplified like the list
+// @Tags Provinces
+// @Produce json
+// @Param provinceId path string true "Province ID"
+// @Param tolerance query number false "Simplification in degrees, 0 for the full outline" default(0.005) minimum(0) maximum(0.5)
+// @Success 200 {object} response_models.ProvinceBoundaryFeature
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Security PartnerAPIKey
+// @Router /provinces/{provinceId}/boundary [get]
+func (p *ProvinceBoundaryController) GetBoundary(c *gin.Context) {
+	tolerance, ok := boundaryTolerance(c)
+	if !ok {
+		return
+	}
+
+	boundary, err := p.boundaryService.GetBoundary(c.Request.Context(), c.Param("provinceId"), tolerance)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, boundary, "Province boundary fetched successfully")
+}
+
+// SetBoundary godoc
+// @Summary Upload a province boundary
+// @Description Replace the outline of a province with a GeoJSON Polygon or MultiPolygon (a Feature or FeatureCollection of them is accepted too). Imported POIs are then placed and checked against it (admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param provinceId path string true "Province ID"
+// @Param request body request_models.SetProvinceBoundaryRequest true "Outline"
+// @Success 200 {object} response_models.ProvinceBoundaryFeature
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/provinces/{provinceId}/boundary [put]
+func (p *ProvinceBoundaryController) SetBoundary(c *gin.Context) {
+	var req request_models.SetProvinceBoundaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "geometry is required")
+		return
+	}
+
+	boundary, err := p.boundaryService.SetBoundary(c.Request.Context(), c.Param("provinceId"), req, c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, boundary, "Province boundary saved successfully")
+}
+
+// DeleteBoundary godoc
+// @Summary Remove a province boundary
+// @Description POIs of the province are no longer checked against an outline (admin only)
+// @Tags Admin
+// @Produce json
+// @Param provinceId path string true "Province ID"
+// @Success 200 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/provinces/{provinceId}/boundary [delete]
+func (p *ProvinceBoundaryController) DeleteBoundary(c *gin.Context) {
+	if err := p.boundaryService.DeleteBoundary(c.Request.Context(), c.Param("provinceId")); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Province boundary deleted successfully")
+}
+
+func boundaryTolerance(c *gin.Context) (float64, bool) {
+	raw := c.Query("tolerance")
+	if raw == "" {
+		return services.DefaultBoundaryTolerance, true
+	}
+	t, err := strconv.ParseFloat(raw, 64)
+	if err != nil || t < 0 || t > services.MaxBoundaryTolerance {
+		utils.RespondError(c, http.StatusBadRequest, "tolerance must be between 0 and 0.5 degrees")
+		return 0, false
+	}
+	return t, true
+}
@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type ProvinceGuideController struct {
+	guideService services.ProvinceGuideServiceInterface
+}
+
+func NewProvinceGuideController(guideService services.ProvinceGuideServiceInterface) *ProvinceGuideController {
+	return &ProvinceGuideController{guideService: guideService}
+}
+
+// GetGuide godoc
+// @Summary Destination guide of a province
+// @Description Editorial content for the destination landing screen: cover image, short intro, highlight POIs in the curated order and suggested trip lengths in days
+// @Tags Provinces
+// @Produce json
+// @Param provinceId path string true "Province ID"
+// @Success 200 {object} response_models.ProvinceGuideResponse
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Security PartnerAPIKey
+// @Router /provinces/{provinceId}/guide [get]
+func (p *ProvinceGuideController) GetGuide(c *gin.Context) {
+	guide, err := p.guideService.GetGuide(c.Request.Context(), c.Param("provinceId"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, guide, "Province guide fetched successfully")
+}
+
+// ListGuides godoc
+// @Summary List province guides
+// @Description Destination guide of every province that has one (admin only)
+// @Tags Admin
+// @Produce json
+// @Success 200 {array} response_models.ProvinceGuideResponse
+// @Security BearerAuth
+// @Router /admin/province-guides [get]
+func (p *ProvinceGuideController) ListGuides(c *gin.Context) {
+	guides, err := p.guideService.ListGuides(c.Request.Context())
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, guides, "Province guides fetched successfully")
+}
+
+// SetGuide godoc
+// @Summary Create or replace the guide of a province
+// @Description Highlights must be reviewed POIs of the province; trip lengths are days (admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param provinceId path string true "Province ID"
+// @Param request body request_models.SetProvinceGuideRequest true "Province guide"
+// @Success 200 {object} response_models.ProvinceGuideResponse
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/province-guides/{provinceId} [put]
+func (p *ProvinceGuideController) SetGuide(c *gin.Context) {
+	var req request_models.SetProvinceGuideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "cover_image_url must be a URL, intro takes at most 800 characters, highlight_poi_ids at most 12 POI IDs and trip_days at most 5 lengths of 1-30 days")
+		return
+	}
+
+	guide, err := p.guideService.SetGuide(c.Request.Context(), c.Param("provinceId"), req, c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, guide, "Province guide saved")
+}
+
+// DeleteGuide godoc
+// @Summary Remove the guide of a province
+// @Tags Admin
+// @Produce json
+// @Param provinceId path string true "Province ID"
+// @Success 200 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/province-guides/{provinceId} [delete]
+func (p *ProvinceGuideController) DeleteGuide(c *gin.Context) {
+	if err := p.guideService.DeleteGuide(c.Request.Context(), c.Param("provinceId")); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Province guide removed")
+}
@@ -29,6 +29,7 @@ func NewProvincesController(provinceService services.ProvinceServiceInterface) *
 // @Success 200 {object} response_models.ProvinceResponse
 // @Failure 400 {object} utils.APIResponse
 // @Security BearerAuth
+// @Security PartnerAPIKey
 // @Router /provinces/list-all [get]
 func (p *ProvincesController) GetAllProvinces(c *gin.Context) {
 
@@ -66,6 +67,7 @@ func (p *ProvincesController) GetAllProvinces(c *gin.Context) {
 // @Success 200 {object} response_models.ProvinceResponse
 // @Failure 400 {object} utils.APIResponse
 // @Security BearerAuth
+// @Security PartnerAPIKey
 // @Router /provinces/find-by-name/{province_name} [get]
 func (p *ProvincesController) FindProvincesByName(c *gin.Context) {
 	id := c.Param("province_name")
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type RuntimeSwitchController struct {
+	switchService services.RuntimeSwitchServiceInterface
+}
+
+func NewRuntimeSwitchController(switchService services.RuntimeSwitchServiceInterface) *RuntimeSwitchController {
+	return &RuntimeSwitchController{switchService: switchService}
+}
+
+// ListSwitches godoc
+// @Summary List runtime switches
+// @Description Maintenance mode and kill switches for expensive subsystems (admin only)
+// @Tags Admin
+// @Produce json
+// @Success 200 {array} response_models.RuntimeSwitchResponse
+// @Security BearerAuth
+// @Router /admin/switches [get]
+func (r *RuntimeSwitchController) ListSwitches(c *gin.Context) {
+	switches, err := r.switchService.ListSwitches(c.Request.Context())
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, switches, "Switches fetched successfully")
+}
+
+// SetSwitch godoc
+// @Summary Flip a runtime switch
+// @Description Engage or release a switch (maintenance_mode, disable_plan_generation, disable_imports, disable_exports) without redeploying (admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param key path string true "Switch key"
+// @Param request body request_models.SetRuntimeSwitchRequest true "Switch state"
+// @Success 200 {object} response_models.RuntimeSwitchResponse
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/switches/{key} [put]
+func (r *RuntimeSwitchController) SetSwitch(c *gin.Context) {
+	var req request_models.SetRuntimeSwitchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "enabled is required")
+		return
+	}
+
+	sw, err := r.switchService.SetSwitch(c.Request.Context(), c.Param("key"), *req.Enabled, req.Message, c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, sw, "Switch updated successfully")
+}
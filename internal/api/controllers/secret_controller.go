@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type SecretController struct {
+	secretService services.SecretServiceInterface
+}
+
+func NewSecretController(secretService services.SecretServiceInterface) *SecretController {
+	return &SecretController{secretService: secretService}
+}
+
+// GetSecrets godoc
+// @Summary API keys in use
+// @Description Every API key by fingerprint (never its value), the version each instance holds and when each version was last used, to check that nothing still runs on a rotated key before revoking it (admin only)
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} response_models.SecretReport
+// @Security BearerAuth
+// @Router /admin/secrets [get]
+func (s *SecretController) GetSecrets(c *gin.Context) {
+	report, err := s.secretService.Report(c.Request.Context())
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, report, "Secrets fetched successfully")
+}
+
+// RefreshSecrets godoc
+// @Summary Reload the secrets manager
+// @Description Picks up rotated keys on the answering instance now; the other instances do at their next refresh (SECRETS_REFRESH_INTERVAL) (admin only)
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} response_models.SecretReport
+// @Failure 500 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/secrets/refresh [post]
+func (s *SecretController) RefreshSecrets(c *gin.Context) {
+	report, err := s.secretService.Refresh(c.Request.Context())
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, report, "Secrets reloaded successfully")
+}
@@ -74,3 +74,30 @@ func (tc *TagController) CreateTagHandler(c *gin.Context) {
 	// Respond with success
 	utils.RespondSuccess(c, nil, "Tag created successfully")
 }
+
+// BulkAssignTagHandler godoc
+// @Summary Tag many POIs at once
+// @Description Assigns a tag to the listed POIs, or to every POI a filter (province, category, name) matches, in one transaction. At most 1000 POIs per call. Newly tagged POIs are queued for re-embedding; POIs that had the tag already are left as they are.
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body request_models.BulkAssignTagRequest true "Tag and POIs"
+// @Success 200 {object} response_models.BulkAssignTagResponse
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Router /tags/bulk-assign [post]
+func (tc *TagController) BulkAssignTagHandler(c *gin.Context) {
+	var req request_models.BulkAssignTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	resp, err := tc.tagService.BulkAssignTag(c.Request.Context(), req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+	utils.RespondSuccess(c, resp, "Tag assigned")
+}
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type TravelDocumentController struct {
+	documentService services.TravelDocumentServiceInterface
+}
+
+func NewTravelDocumentController(documentService services.TravelDocumentServiceInterface) *TravelDocumentController {
+	return &TravelDocumentController{documentService: documentService}
+}
+
+// UploadDocument godoc
+// @Summary Add a travel document to a journey
+// @Description Store a ticket, hotel confirmation or any file (max 10 MB) in the journey's vault, encrypted at rest. Owner and editors only
+// @Tags Journey
+// @Accept multipart/form-data
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Param file formData file true "Document (max 10 MB)"
+// @Success 200 {object} response_models.TravelDocument
+// @Failure 400 {object} utils.APIResponse
+// @Failure 403 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/documents [post]
+func (t *TravelDocumentController) UploadDocument(c *gin.Context) {
+	fh, err := c.FormFile("file")
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "file is required")
+		return
+	}
+	if fh.Size > services.MaxTravelDocumentBytes {
+		utils.RespondError(c, http.StatusBadRequest, "file is too large (max 10 MB)")
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Cannot read file")
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, services.MaxTravelDocumentBytes+1))
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Cannot read file")
+		return
+	}
+
+	doc, err := t.documentService.Upload(c.Request.Context(), c.Param("journeyId"), c.GetString("user_id"), fh.Filename, fh.Header.Get("Content-Type"), data)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, doc, "Document uploaded")
+}
+
+// ListDocuments godoc
+// @Summary List the travel documents of a journey
+// @Description Uploaded files and forwarded booking emails, newest first, with the booking details read from the emails
+// @Tags Journey
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Success 200 {array} response_models.TravelDocument
+// @Failure 403 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/documents [get]
+func (t *TravelDocumentController) ListDocuments(c *gin.Context) {
+	docs, err := t.documentService.List(c.Request.Context(), c.Param("journeyId"), c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, docs, "Documents retrieved")
+}
+
+// DownloadDocument godoc
+// @Summary Download a travel document
+// @Tags Journey
+// @Produce octet-stream
+// @Param journeyId path string true "Journey ID"
+// @Param documentId path string true "Document ID"
+// @Success 200 {file} file
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/documents/{documentId}/download [get]
+func (t *TravelDocumentController) DownloadDocument(c *gin.Context) {
+	doc, data, err := t.documentService.Download(c.Request.Context(), c.Param("journeyId"), c.GetString("user_id"), c.Param("documentId"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": doc.FileName}))
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, services.DocumentServeType(doc.ContentType), data)
+}
+
+// DeleteDocument godoc
+// @Summary Delete a travel document
+// @Description The uploader or the journey owner can delete a document
+// @Tags Journey
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Param documentId path string true "Document ID"
+// @Success 200 {object} utils.APIResponse
+// @Failure 403 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/documents/{documentId} [delete]
+func (t *TravelDocumentController) DeleteDocument(c *gin.Context) {
+	if err := t.documentService.Delete(c.Request.Context(), c.Param("journeyId"), c.GetString("user_id"), c.Param("documentId")); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Document deleted")
+}
+
+// GetInbox godoc
+// @Summary Get the booking email address of a journey
+// @Description Booking emails forwarded to this address by a participant are stored in the vault, with their attachments and the booking reference and dates found in them. Owner and editors only
+// @Tags Journey
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Success 200 {object} response_models.JourneyInbox
+// @Failure 403 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/documents/inbox [get]
+func (t *TravelDocumentController) GetInbox(c *gin.Context) {
+	inbox, err := t.documentService.Inbox(c.Request.Context(), c.Param("journeyId"), c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, inbox, "Inbox address retrieved")
+}
+
+// ReceiveEmail godoc
+// @Summary Inbound booking email webhook
+// @Description Called by the inbound mail provider with the raw message (message/rfc822). Authenticated with the X-Inbound-Secret header
+// @Tags Journey
+// @Accept plain
+// @Produce json
+// @Param X-Inbound-Secret header string true "Shared secret"
+// @Success 200 {object} response_models.InboundMailResult
+// @Failure 401 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Router /inbound/mail [post]
+func (t *TravelDocumentController) ReceiveEmail(c *gin.Context) {
+	raw, err := io.ReadAll(io.LimitReader(c.Request.Body, services.MaxInboundEmailBytes+1))
+	if err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Cannot read the email")
+		return
+	}
+	if len(raw) > services.MaxInboundEmailBytes {
+		utils.RespondError(c, http.StatusRequestEntityTooLarge, "email is too large (max 25 MB)")
+		return
+	}
+
+	result, err := t.documentService.ReceiveEmail(c.Request.Context(), c.GetHeader("X-Inbound-Secret"), raw)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, result, "Email filed")
+}
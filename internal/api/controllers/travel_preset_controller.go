@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type TravelPresetController struct {
+	presetService services.TravelPresetServiceInterface
+}
+
+func NewTravelPresetController(presetService services.TravelPresetServiceInterface) *TravelPresetController {
+	return &TravelPresetController{presetService: presetService}
+}
+
+// ListPresets godoc
+// @Summary List my travel style presets
+// @Description Saved presets by name, to pick from when starting the quiz
+// @Tags Accounts
+// @Produce json
+// @Success 200 {array} response_models.TravelPreset
+// @Security BearerAuth
+// @Router /accounts/presets [get]
+func (t *TravelPresetController) ListPresets(c *gin.Context) {
+	presets, err := t.presetService.ListPresets(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, presets, "Presets retrieved")
+}
+
+// CreatePreset godoc
+// @Summary Save a travel style preset
+// @Description Bundle a pace (relaxed, balanced, packed), a budget (a quiz budget option), interests and dietary needs under a name, up to 10 presets
+// @Tags Accounts
+// @Accept json
+// @Produce json
+// @Param request body request_models.TravelPresetRequest true "Preset"
+// @Success 200 {object} response_models.TravelPreset
+// @Failure 400 {object} utils.APIResponse
+// @Failure 409 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /accounts/presets [post]
+func (t *TravelPresetController) CreatePreset(c *gin.Context) {
+	var req request_models.TravelPresetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "name is required; pace must be relaxed, balanced or packed")
+		return
+	}
+
+	preset, err := t.presetService.CreatePreset(c.Request.Context(), c.GetString("user_id"), req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, preset, "Preset saved")
+}
+
+// UpdatePreset godoc
+// @Summary Replace a travel style preset
+// @Description Every field is replaced; send the full preset
+// @Tags Accounts
+// @Accept json
+// @Produce json
+// @Param presetId path string true "Preset ID"
+// @Param request body request_models.TravelPresetRequest true "Preset"
+// @Success 200 {object} response_models.TravelPreset
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Failure 409 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /accounts/presets/{presetId} [put]
+func (t *TravelPresetController) UpdatePreset(c *gin.Context) {
+	var req request_models.TravelPresetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "name is required; pace must be relaxed, balanced or packed")
+		return
+	}
+
+	preset, err := t.presetService.UpdatePreset(c.Request.Context(), c.GetString("user_id"), c.Param("presetId"), req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, preset, "Preset updated")
+}
+
+// DeletePreset godoc
+// @Summary Delete a travel style preset
+// @Tags Accounts
+// @Produce json
+// @Param presetId path string true "Preset ID"
+// @Success 200 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /accounts/presets/{presetId} [delete]
+func (t *TravelPresetController) DeletePreset(c *gin.Context) {
+	if err := t.presetService.DeletePreset(c.Request.Context(), c.GetString("user_id"), c.Param("presetId")); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Preset deleted")
+}
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type TripReminderController struct {
+	reminderService services.TripReminderServiceInterface
+}
+
+func NewTripReminderController(reminderService services.TripReminderServiceInterface) *TripReminderController {
+	return &TripReminderController{reminderService: reminderService}
+}
+
+// GetReminders godoc
+// @Summary Pre-trip reminders of a journey
+// @Description Packing list (T-14), weather check (T-3), itinerary summary (T-2 by default) and day-of reminder (T-1), computed from the journey start date. Nothing is sent while the account has trip emails off
+// @Tags Journey
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Success 200 {object} response_models.TripRemindersResponse
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/reminders [get]
+func (t *TripReminderController) GetReminders(c *gin.Context) {
+	out, err := t.reminderService.GetReminders(c.Request.Context(), c.Param("journeyId"), c.GetString("user_id"))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, out, "Reminders fetched successfully")
+}
+
+// SetReminders godoc
+// @Summary Turn the pre-trip reminders of a journey on or off
+// @Tags Journey
+// @Accept json
+// @Produce json
+// @Param journeyId path string true "Journey ID"
+// @Param request body request_models.SetTripRemindersRequest true "Enabled"
+// @Success 200 {object} response_models.TripRemindersResponse
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /journeys/{journeyId}/reminders [put]
+func (t *TripReminderController) SetReminders(c *gin.Context) {
+	var req request_models.SetTripRemindersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "enabled is required")
+		return
+	}
+
+	out, err := t.reminderService.SetReminders(c.Request.Context(), c.Param("journeyId"), c.GetString("user_id"), *req.Enabled)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, out, "Reminders updated successfully")
+}
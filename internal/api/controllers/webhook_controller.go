@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vivu/internal/models/request_models"
+	"vivu/internal/services"
+	"vivu/pkg/utils"
+)
+
+type WebhookController struct {
+	webhookService services.WebhookServiceInterface
+}
+
+func NewWebhookController(webhookService services.WebhookServiceInterface) *WebhookController {
+	return &WebhookController{webhookService: webhookService}
+}
+
+func isAdmin(c *gin.Context) bool {
+	return c.GetString("Role") == "admin"
+}
+
+// ListWebhooks godoc
+// @Summary List webhooks
+// @Description Webhooks of the current account; admins also see the ones receiving every account's events. Secrets are never listed
+// @Tags Webhooks
+// @Produce json
+// @Success 200 {array} response_models.WebhookEndpoint
+// @Failure 401 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /webhooks [get]
+func (w *WebhookController) ListWebhooks(c *gin.Context) {
+	endpoints, err := w.webhookService.ListEndpoints(c.Request.Context(), c.GetString("user_id"), isAdmin(c))
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, endpoints, "Webhooks fetched successfully")
+}
+
+// CreateWebhook godoc
+// @Summary Register a webhook
+// @Description Events are POSTed as JSON {id, type, created_at, account_id, data} with the headers X-Vivu-Event, X-Vivu-Delivery and X-Vivu-Signature: t=<unix>,v1=<hex HMAC-SHA256 of "<t>.<body>" keyed by the secret>. Failed deliveries are retried with backoff. Events: plan.generated, journey.updated, subscription.activated, subscription.expiring, payment.failed; none means all. The secret is only shown in this response
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param request body request_models.CreateWebhookRequest true "Webhook"
+// @Success 200 {object} response_models.CreatedWebhookEndpoint
+// @Failure 400 {object} utils.APIResponse
+// @Failure 401 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /webhooks [post]
+func (w *WebhookController) CreateWebhook(c *gin.Context) {
+	var req request_models.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "A valid url is required; events must be plan.generated, journey.updated, subscription.activated, subscription.expiring or payment.failed")
+		return
+	}
+
+	endpoint, err := w.webhookService.CreateEndpoint(c.Request.Context(), c.GetString("user_id"), isAdmin(c), req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, endpoint, "Webhook created successfully")
+}
+
+// UpdateWebhook godoc
+// @Summary Update a webhook
+// @Description Replace the URL, description, events and active flag of a webhook; the secret stays the same
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param request body request_models.UpdateWebhookRequest true "Webhook"
+// @Success 200 {object} response_models.WebhookEndpoint
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /webhooks/{id} [put]
+func (w *WebhookController) UpdateWebhook(c *gin.Context) {
+	var req request_models.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "A valid url is required; events must be plan.generated, journey.updated, subscription.activated, subscription.expiring or payment.failed")
+		return
+	}
+
+	endpoint, err := w.webhookService.UpdateEndpoint(c.Request.Context(), c.GetString("user_id"), isAdmin(c), c.Param("id"), req)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, endpoint, "Webhook updated successfully")
+}
+
+// DeleteWebhook godoc
+// @Summary Delete a webhook
+// @Description Deliveries still pending for the webhook end as failed
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} utils.APIResponse
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /webhooks/{id} [delete]
+func (w *WebhookController) DeleteWebhook(c *gin.Context) {
+	if err := w.webhookService.DeleteEndpoint(c.Request.Context(), c.GetString("user_id"), isAdmin(c), c.Param("id")); err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, nil, "Webhook deleted successfully")
+}
+
+// ListDeliveries godoc
+// @Summary List deliveries of a webhook
+// @Description Delivery log of a webhook, newest first, with attempts, the last status code or error and the payload
+// @Tags Webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param status query string false "pending, delivered or failed"
+// @Param event query string false "Event type"
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(20) minimum(1) maximum(100)
+// @Success 200 {object} response_models.WebhookDeliveryPage
+// @Failure 400 {object} utils.APIResponse
+// @Failure 404 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /webhooks/{id}/deliveries [get]
+func (w *WebhookController) ListDeliveries(c *gin.Context) {
+	w.listDeliveries(c, c.Param("id"))
+}
+
+// ListAllDeliveries godoc
+// @Summary List webhook deliveries
+// @Description Delivery log of every webhook, newest first (admin only)
+// @Tags Admin
+// @Produce json
+// @Param status query string false "pending, delivered or failed"
+// @Param event query string false "Event type"
+// @Param page query int false "Page number" default(1)
+// @Param pageSize query int false "Page size" default(20) minimum(1) maximum(100)
+// @Success 200 {object} response_models.WebhookDeliveryPage
+// @Failure 400 {object} utils.APIResponse
+// @Security BearerAuth
+// @Router /admin/webhooks/deliveries [get]
+func (w *WebhookController) ListAllDeliveries(c *gin.Context) {
+	w.listDeliveries(c, "")
+}
+
+func (w *WebhookController) listDeliveries(c *gin.Context, endpointID string) {
+	var query request_models.WebhookDeliveryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.RespondError(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+
+	page, err := w.webhookService.ListDeliveries(c.Request.Context(), c.GetString("user_id"), isAdmin(c), endpointID, query)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		return
+	}
+
+	utils.RespondSuccess(c, page, "Webhook deliveries fetched successfully")
+}
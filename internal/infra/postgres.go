@@ -1,40 +1,106 @@
 package infra
 
 import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/plugin/opentelemetry/tracing"
 	"log"
-	"os"
+	"vivu/migrations"
 )
 
 var pgSingleton *gorm.DB
 
 func InitPostgresql() *gorm.DB {
+	// Invoked by fx and by the db provider; only open one pool
+	if pgSingleton != nil {
+		return pgSingleton
+	}
+
+	cfg := LoadPostgresConfigFromEnv()
+
+	log.Printf("Connecting to PostgreSQL (max_open=%d max_idle=%d lifetime=%s idle_time=%s statement_timeout=%s)",
+		cfg.MaxOpenConns, cfg.MaxIdleConns, cfg.ConnMaxLifetime, cfg.ConnMaxIdleTime, cfg.StatementTimeout)
 
-	dsn := os.Getenv("POSTGRES_URL")
+	pgxCfg, err := pgx.ParseConfig(cfg.DSN)
+	if err != nil {
+		log.Printf("Error parsing POSTGRES_URL: %v", err)
+		log.Fatal("Error connecting to database")
+	}
+	if cfg.StatementTimeout > 0 {
+		// Sent as a startup parameter, so every pooled session gets it
+		pgxCfg.RuntimeParams["statement_timeout"] = fmt.Sprintf("%d", cfg.StatementTimeout.Milliseconds())
+	}
 
-	log.Printf("Connecting to PostgreSQL database with DSN: %s", dsn)
+	sqlDB := stdlib.OpenDB(*pgxCfg)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 
-	connectionPool, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	connectionPool, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
 
 	if err != nil {
 		log.Printf("Error connecting to database: %v", err)
 		log.Fatal("Error connecting to database")
 	}
+
+	startPoolMonitor(sqlDB, cfg)
+
+	if err := connectionPool.Use(NewSlowQueryPluginFromEnv()); err != nil {
+		log.Printf("Error registering slow query plugin: %v", err)
+	}
+	// Statements are recorded without bound values; pool stats already come from the pool monitor
+	if err := connectionPool.Use(tracing.NewPlugin(tracing.WithoutQueryVariables(), tracing.WithoutMetrics())); err != nil {
+		log.Printf("Error registering tracing plugin: %v", err)
+	}
+
 	pgSingleton = connectionPool
 	return connectionPool
 }
 
-func MigratePostgresql(db *gorm.DB, models ...interface{}) {
-	err := db.AutoMigrate(models...)
+// MigratePostgresql runs a goose command ("up", "down" or "status") against the versioned
+// migrations embedded in package migrations. It uses its own connection without
+// DB_STATEMENT_TIMEOUT, since index builds on big tables take longer than any request should.
+func MigratePostgresql(command string) error {
+	pgxCfg, err := pgx.ParseConfig(LoadPostgresConfigFromEnv().DSN)
 	if err != nil {
-		log.Printf("Error during migration: %v", err)
-		log.Fatal("Error during migration")
+		return err
 	}
-	log.Println("Database migration completed successfully")
+	pgxCfg.RuntimeParams["statement_timeout"] = "0"
+	sqlDB := stdlib.OpenDB(*pgxCfg)
+	defer sqlDB.Close()
+
+	goose.SetBaseFS(migrations.FS)
+	if err := goose.SetDialect("postgres"); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		err = goose.UpContext(ctx, sqlDB, migrations.Dir)
+	case "down":
+		err = goose.DownContext(ctx, sqlDB, migrations.Dir)
+	case "status":
+		err = goose.StatusContext(ctx, sqlDB, migrations.Dir)
+	default:
+		return fmt.Errorf("unknown migrate command %q (want up, down or status)", command)
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("Database migration %s completed successfully", command)
+	return nil
 }
 
 func ClosePostgresql(db *gorm.DB) {
+	stopPoolMonitor()
+
 	sqlDB, err := db.DB()
 	if err != nil {
 		log.Printf("Error getting database instance: %v", err)
@@ -0,0 +1,85 @@
+package infra
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// PostgresConfig holds connection and pool settings. Every field can be overridden by env:
+//
+//	POSTGRES_URL, DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS, DB_CONN_MAX_LIFETIME,
+//	DB_CONN_MAX_IDLE_TIME, DB_STATEMENT_TIMEOUT, DB_POOL_MONITOR_INTERVAL,
+//	DB_POOL_SATURATION_WARN (ratio 0..1)
+//
+// Durations use Go syntax ("30s", "5m").
+type PostgresConfig struct {
+	DSN              string
+	MaxOpenConns     int
+	MaxIdleConns     int
+	ConnMaxLifetime  time.Duration
+	ConnMaxIdleTime  time.Duration
+	StatementTimeout time.Duration // applied to every session; 0 disables
+
+	MonitorInterval time.Duration // 0 disables the pool monitor
+	SaturationWarn  float64       // warn when in-use / max-open reaches this ratio
+}
+
+func DefaultPostgresConfig() PostgresConfig {
+	return PostgresConfig{
+		MaxOpenConns:     25,
+		MaxIdleConns:     10,
+		ConnMaxLifetime:  30 * time.Minute,
+		ConnMaxIdleTime:  5 * time.Minute,
+		StatementTimeout: 30 * time.Second,
+		MonitorInterval:  30 * time.Second,
+		SaturationWarn:   0.8,
+	}
+}
+
+func LoadPostgresConfigFromEnv() PostgresConfig {
+	cfg := DefaultPostgresConfig()
+	cfg.DSN = os.Getenv("POSTGRES_URL")
+
+	cfg.MaxOpenConns = envInt("DB_MAX_OPEN_CONNS", cfg.MaxOpenConns)
+	cfg.MaxIdleConns = envInt("DB_MAX_IDLE_CONNS", cfg.MaxIdleConns)
+	cfg.ConnMaxLifetime = envDuration("DB_CONN_MAX_LIFETIME", cfg.ConnMaxLifetime)
+	cfg.ConnMaxIdleTime = envDuration("DB_CONN_MAX_IDLE_TIME", cfg.ConnMaxIdleTime)
+	cfg.StatementTimeout = envDuration("DB_STATEMENT_TIMEOUT", cfg.StatementTimeout)
+	cfg.MonitorInterval = envDuration("DB_POOL_MONITOR_INTERVAL", cfg.MonitorInterval)
+	if v, err := strconv.ParseFloat(os.Getenv("DB_POOL_SATURATION_WARN"), 64); err == nil && v > 0 && v <= 1 {
+		cfg.SaturationWarn = v
+	}
+
+	if cfg.MaxIdleConns > cfg.MaxOpenConns && cfg.MaxOpenConns > 0 {
+		cfg.MaxIdleConns = cfg.MaxOpenConns
+	}
+	return cfg
+}
+
+func envInt(key string, def int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil || v < 0 {
+		log.Printf("Invalid %s=%q, using default %d", key, raw, def)
+		return def
+	}
+	return v
+}
+
+func envDuration(key string, def time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+	v, err := time.ParseDuration(raw)
+	if err != nil || v < 0 {
+		log.Printf("Invalid %s=%q, using default %s", key, raw, def)
+		return def
+	}
+	return v
+}
@@ -0,0 +1,100 @@
+package infra
+
+import (
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+)
+
+// PoolStats is a snapshot of the connection pool, exposed on the admin diagnostics endpoint.
+type PoolStats struct {
+	MaxOpenConnections int     `json:"max_open_connections"`
+	OpenConnections    int     `json:"open_connections"`
+	InUse              int     `json:"in_use"`
+	Idle               int     `json:"idle"`
+	Saturation         float64 `json:"saturation"` // in_use / max_open
+	WaitCount          int64   `json:"wait_count"`
+	WaitDurationMs     int64   `json:"wait_duration_ms"`
+	MaxIdleClosed      int64   `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64   `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64   `json:"max_lifetime_closed"`
+	SaturatedSamples   int64   `json:"saturated_samples"` // monitor ticks at or above the warn ratio
+}
+
+type poolMonitor struct {
+	mu        sync.Mutex
+	saturated int64
+	stop      chan struct{}
+	once      sync.Once
+}
+
+var monitor = &poolMonitor{stop: make(chan struct{})}
+
+func toPoolStats(s sql.DBStats) PoolStats {
+	out := PoolStats{
+		MaxOpenConnections: s.MaxOpenConnections,
+		OpenConnections:    s.OpenConnections,
+		InUse:              s.InUse,
+		Idle:               s.Idle,
+		WaitCount:          s.WaitCount,
+		WaitDurationMs:     s.WaitDuration.Milliseconds(),
+		MaxIdleClosed:      s.MaxIdleClosed,
+		MaxIdleTimeClosed:  s.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  s.MaxLifetimeClosed,
+	}
+	if s.MaxOpenConnections > 0 {
+		out.Saturation = float64(s.InUse) / float64(s.MaxOpenConnections)
+	}
+	return out
+}
+
+// GetPoolStats returns the current pool snapshot.
+func GetPoolStats() (PoolStats, error) {
+	sqlDB, err := GetPostgresql().DB()
+	if err != nil {
+		return PoolStats{}, err
+	}
+	out := toPoolStats(sqlDB.Stats())
+	monitor.mu.Lock()
+	out.SaturatedSamples = monitor.saturated
+	monitor.mu.Unlock()
+	return out, nil
+}
+
+// startPoolMonitor samples the pool and logs when it saturates or callers start waiting.
+func startPoolMonitor(sqlDB *sql.DB, cfg PostgresConfig) {
+	if cfg.MonitorInterval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(cfg.MonitorInterval)
+		defer ticker.Stop()
+
+		var lastWait int64
+		for {
+			select {
+			case <-monitor.stop:
+				return
+			case <-ticker.C:
+				st := toPoolStats(sqlDB.Stats())
+				if st.Saturation >= cfg.SaturationWarn {
+					monitor.mu.Lock()
+					monitor.saturated++
+					monitor.mu.Unlock()
+					log.Printf("[db-pool] saturation %.0f%% (in_use=%d max_open=%d idle=%d)",
+						st.Saturation*100, st.InUse, st.MaxOpenConnections, st.Idle)
+				}
+				if st.WaitCount > lastWait {
+					log.Printf("[db-pool] %d new waits for a connection (total wait %dms)",
+						st.WaitCount-lastWait, st.WaitDurationMs)
+				}
+				lastWait = st.WaitCount
+			}
+		}
+	}()
+}
+
+func stopPoolMonitor() {
+	monitor.once.Do(func() { close(monitor.stop) })
+}
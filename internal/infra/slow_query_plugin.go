@@ -0,0 +1,287 @@
+package infra
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"vivu/internal/models/db_models"
+	"vivu/pkg/utils"
+)
+
+const slowQueryStartKey = "vivu:slow_query_start"
+
+// slowQueryBacklog bounds the slow runs waiting to be stored. A database slow enough to fill
+// it is not helped by more writes, so further runs are dropped until the worker catches up.
+const slowQueryBacklog = 256
+
+// skipDiagnosticsKey marks queries issued by the plugin itself so they are never measured.
+type skipDiagnosticsKey struct{}
+
+// SlowQueryPlugin logs statements slower than Threshold with their bound parameters and,
+// for the worst SELECTs, captures EXPLAIN ANALYZE into the query_diagnostics table.
+type SlowQueryPlugin struct {
+	Threshold       time.Duration
+	ExplainEnabled  bool
+	CaptureCooldown time.Duration // per fingerprint; avoids EXPLAIN storms on hot queries
+
+	mu          sync.Mutex
+	worst       map[string]time.Duration
+	lastCapture map[string]time.Time
+	db          *gorm.DB
+
+	records chan slowQueryRecord
+	dropped atomic.Int64
+}
+
+// slowQueryRecord is one slow run handed to the recording worker.
+type slowQueryRecord struct {
+	fp, table, sql, bound string
+	vars                  []interface{}
+	elapsed               time.Duration
+	rows                  int64
+	explain               bool
+	owner                 utils.DataOwner
+}
+
+// NewSlowQueryPluginFromEnv reads SLOW_QUERY_THRESHOLD_MS (default 200) and
+// SLOW_QUERY_EXPLAIN (default true).
+func NewSlowQueryPluginFromEnv() *SlowQueryPlugin {
+	threshold := 200 * time.Millisecond
+	if v, err := strconv.Atoi(os.Getenv("SLOW_QUERY_THRESHOLD_MS")); err == nil && v > 0 {
+		threshold = time.Duration(v) * time.Millisecond
+	}
+	explain := true
+	if v, err := strconv.ParseBool(os.Getenv("SLOW_QUERY_EXPLAIN")); err == nil {
+		explain = v
+	}
+	return &SlowQueryPlugin{
+		Threshold:       threshold,
+		ExplainEnabled:  explain,
+		CaptureCooldown: 10 * time.Minute,
+		worst:           make(map[string]time.Duration),
+		lastCapture:     make(map[string]time.Time),
+		records:         make(chan slowQueryRecord, slowQueryBacklog),
+	}
+}
+
+func (p *SlowQueryPlugin) Name() string {
+	return "vivu:slow_query"
+}
+
+func (p *SlowQueryPlugin) Initialize(db *gorm.DB) error {
+	p.db = db
+	go p.drain()
+	cb := db.Callback()
+
+	if err := cb.Query().Before("gorm:query").Register("vivu:slow_query_before_query", p.before); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("vivu:slow_query_after_query", p.after); err != nil {
+		return err
+	}
+	if err := cb.Create().Before("gorm:create").Register("vivu:slow_query_before_create", p.before); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("vivu:slow_query_after_create", p.after); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("vivu:slow_query_before_update", p.before); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("vivu:slow_query_after_update", p.after); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("vivu:slow_query_before_delete", p.before); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("vivu:slow_query_after_delete", p.after); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("vivu:slow_query_before_row", p.before); err != nil {
+		return err
+	}
+	if err := cb.Row().After("gorm:row").Register("vivu:slow_query_after_row", p.after); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("gorm:raw").Register("vivu:slow_query_before_raw", p.before); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("vivu:slow_query_after_raw", p.after)
+}
+
+func (p *SlowQueryPlugin) before(db *gorm.DB) {
+	db.InstanceSet(slowQueryStartKey, time.Now())
+}
+
+func (p *SlowQueryPlugin) after(db *gorm.DB) {
+	if db.Statement == nil || db.Statement.Context == nil {
+		return
+	}
+	if db.Statement.Context.Value(skipDiagnosticsKey{}) != nil {
+		return
+	}
+	v, ok := db.InstanceGet(slowQueryStartKey)
+	if !ok {
+		return
+	}
+	start, ok := v.(time.Time)
+	if !ok {
+		return
+	}
+
+	elapsed := time.Since(start)
+	if elapsed < p.Threshold {
+		return
+	}
+
+	sql := db.Statement.SQL.String()
+	if sql == "" {
+		return
+	}
+	vars := append([]interface{}(nil), db.Statement.Vars...)
+	bound := db.Dialector.Explain(sql, vars...)
+	owner, _ := utils.DataOwnerFrom(db.Statement.Context)
+
+	// Every slow run is counted; the cooldown only gates the log line and the EXPLAIN
+	fp := fingerprint(sql)
+	captured := p.shouldCapture(fp, elapsed)
+	if captured {
+		// Parameters of a query run for an account stay out of the log, which cannot be erased;
+		// the stored capture is tagged with the account instead
+		logged := bound
+		if owner.AccountID != "" {
+			logged = sql
+		}
+		log.Printf("[slow-query] %s (%d rows) table=%s: %s", elapsed.Round(time.Millisecond), db.RowsAffected, db.Statement.Table, logged)
+	}
+
+	// EXPLAIN ANALYZE executes the statement, so only reads are safe to replay
+	explain := captured && p.ExplainEnabled && isSelect(sql)
+
+	select {
+	case p.records <- slowQueryRecord{fp, db.Statement.Table, sql, bound, vars, elapsed, db.RowsAffected, explain, owner}:
+	default:
+		if n := p.dropped.Add(1); n%100 == 1 {
+			log.Printf("[slow-query] backlog full, %d runs dropped so far", n)
+		}
+	}
+}
+
+// drain stores the queued slow runs one at a time, so a burst costs one connection at most.
+func (p *SlowQueryPlugin) drain() {
+	for r := range p.records {
+		p.record(r)
+	}
+}
+
+// shouldCapture lets through a fingerprint's first slow run, any run slower than the worst
+// one already captured, or any run once the cooldown has elapsed.
+func (p *SlowQueryPlugin) shouldCapture(fp string, elapsed time.Duration) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	last, seen := p.lastCapture[fp]
+	if seen && elapsed <= p.worst[fp] && time.Since(last) < p.CaptureCooldown {
+		return false
+	}
+	p.lastCapture[fp] = time.Now()
+	if elapsed > p.worst[fp] {
+		p.worst[fp] = elapsed
+	}
+	return true
+}
+
+// record upserts the fingerprint's row. Runs that were not captured only bump the counters;
+// captured ones also keep the slowest statement and its plan.
+func (p *SlowQueryPlugin) record(r slowQueryRecord) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	ctx = context.WithValue(ctx, skipDiagnosticsKey{}, true)
+
+	session := p.db.Session(&gorm.Session{NewDB: true, Logger: logger.Default.LogMode(logger.Silent)}).WithContext(ctx)
+
+	explain := r.explain
+	var plan []string
+	if explain {
+		rs, err := session.Raw("EXPLAIN (ANALYZE, BUFFERS) "+r.sql, r.vars...).Rows()
+		if err != nil {
+			log.Printf("[slow-query] explain failed: %v", err)
+			explain = false
+		} else {
+			for rs.Next() {
+				var line string
+				if err := rs.Scan(&line); err == nil {
+					plan = append(plan, line)
+				}
+			}
+			_ = rs.Close()
+		}
+	}
+
+	now := time.Now().Unix()
+	diag := db_models.QueryDiagnostic{
+		Fingerprint:  r.fp,
+		Table:        r.table,
+		SQL:          r.sql,
+		BoundSQL:     r.bound,
+		DurationMs:   r.elapsed.Milliseconds(),
+		RowsAffected: r.rows,
+		Plan:         strings.Join(plan, "\n"),
+		Occurrences:  1,
+		LastSeenAt:   now,
+	}
+	if id, err := uuid.Parse(r.owner.AccountID); err == nil {
+		diag.OwnerAccountID = &id
+		diag.Purpose = r.owner.Purpose
+	}
+
+	updates := clause.Set{
+		{Column: clause.Column{Name: "occurrences"}, Value: gorm.Expr("query_diagnostics.occurrences + 1")},
+		{Column: clause.Column{Name: "last_seen_at"}, Value: now},
+		{Column: clause.Column{Name: "updated_at"}, Value: now},
+	}
+	if explain {
+		diag.LastCapturedAt = now
+		// Keep the slowest captured run per fingerprint
+		updates = append(updates,
+			clause.Assignment{Column: clause.Column{Name: "last_captured_at"}, Value: now},
+			clause.Assignment{Column: clause.Column{Name: "bound_sql"}, Value: gorm.Expr("CASE WHEN excluded.duration_ms > query_diagnostics.duration_ms THEN excluded.bound_sql ELSE query_diagnostics.bound_sql END")},
+			clause.Assignment{Column: clause.Column{Name: "plan"}, Value: gorm.Expr("CASE WHEN excluded.duration_ms > query_diagnostics.duration_ms THEN excluded.plan ELSE query_diagnostics.plan END")},
+			clause.Assignment{Column: clause.Column{Name: "rows_affected"}, Value: gorm.Expr("CASE WHEN excluded.duration_ms > query_diagnostics.duration_ms THEN excluded.rows_affected ELSE query_diagnostics.rows_affected END")},
+			// The owner goes with the bound SQL it typed
+			clause.Assignment{Column: clause.Column{Name: "owner_account_id"}, Value: gorm.Expr("CASE WHEN excluded.duration_ms > query_diagnostics.duration_ms THEN excluded.owner_account_id ELSE query_diagnostics.owner_account_id END")},
+			clause.Assignment{Column: clause.Column{Name: "purpose"}, Value: gorm.Expr("CASE WHEN excluded.duration_ms > query_diagnostics.duration_ms THEN excluded.purpose ELSE query_diagnostics.purpose END")},
+			clause.Assignment{Column: clause.Column{Name: "duration_ms"}, Value: gorm.Expr("GREATEST(excluded.duration_ms, query_diagnostics.duration_ms)")},
+		)
+	}
+
+	err := session.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "fingerprint"}},
+		DoUpdates: updates,
+	}).Create(&diag).Error
+	if err != nil {
+		log.Printf("[slow-query] store diagnostic failed: %v", err)
+	}
+}
+
+func isSelect(sql string) bool {
+	return strings.HasPrefix(strings.ToUpper(strings.TrimSpace(sql)), "SELECT")
+}
+
+func fingerprint(sql string) string {
+	h := fnv.New64a()
+	h.Write([]byte(strings.Join(strings.Fields(sql), " ")))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
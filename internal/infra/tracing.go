@@ -0,0 +1,54 @@
+package infra
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// TracingServiceName is reported as service.name unless OTEL_SERVICE_NAME overrides it.
+const TracingServiceName = "vivu-api"
+
+// InitTracing exports spans over OTLP/HTTP when OTEL_EXPORTER_OTLP_ENDPOINT (or
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT) is set; the exporter reads the other standard OTEL_*
+// variables (headers, sampler) itself. Without an endpoint spans are dropped, but W3C trace
+// context is still propagated. The returned function flushes pending spans.
+func InitTracing() func(context.Context) error {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		log.Println("Tracing disabled: OTEL_EXPORTER_OTLP_ENDPOINT is not set")
+		return func(context.Context) error { return nil }
+	}
+
+	exporter, err := otlptracehttp.New(context.Background())
+	if err != nil {
+		log.Printf("Error creating OTLP trace exporter, tracing disabled: %v", err)
+		return func(context.Context) error { return nil }
+	}
+
+	// Later detectors win, so OTEL_SERVICE_NAME / OTEL_RESOURCE_ATTRIBUTES override the default name
+	res, err := resource.New(context.Background(),
+		resource.WithAttributes(semconv.ServiceName(TracingServiceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		log.Printf("Error building tracing resource: %v", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	log.Println("Tracing enabled: exporting spans over OTLP")
+	return provider.Shutdown
+}
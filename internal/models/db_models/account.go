@@ -1,6 +1,14 @@
 package db_models
 
-import "gorm.io/datatypes"
+import (
+	"github.com/lib/pq"
+	"gorm.io/datatypes"
+)
+
+const (
+	AccountStatusActive = "active"
+	AccountStatusBanned = "banned"
+)
 
 type Account struct {
 	BaseModel
@@ -9,6 +17,37 @@ type Account struct {
 	PasswordHash string
 	Role         string `gorm:"default:'user'"`
 
+	// Banned accounts cannot log in and their tokens are refused. Tokens issued up to
+	// SessionsRevokedAt (unix seconds) are refused too; a ban or a forced logout sets it.
+	Status            string `gorm:"size:16;not null;default:'active'"` // active | banned
+	BannedAt          *int64
+	BanReason         string `gorm:"type:text"`
+	SessionsRevokedAt int64  `gorm:"not null;default:0"`
+
+	// Profile settings the traveler edits from /accounts/me; empty means the app default.
+	AvatarURL         string `gorm:"type:text"`
+	Locale            string `gorm:"size:5"` // vi | en
+	PreferredCurrency string `gorm:"size:3"` // ISO 4217, e.g. VND
+	// Optional, given at registration; accounts of minors never get adult-only places.
+	BirthYear *int
+
+	// Day window the traveler wants plans to respect ("HH:MM"); empty means the planner default.
+	DayStart string `gorm:"size:5"`
+	DayEnd   string `gorm:"size:5"`
+	// People the account usually travels with; the planner adapts pacing and POIs to them.
+	Companions []Companion `gorm:"type:jsonb;serializer:json"`
+	// Places the traveler never wants in a plan ("Museums", "karaoke"); see services.Exclusions.
+	Avoid pq.StringArray `gorm:"type:text[]"`
+	// Opt-out of every pre-trip email, whatever the reminder setting of each journey
+	TripEmailsOff bool `gorm:"not null;default:false"`
+
+	// Two-factor auth. The secret is sealed with utils.EncryptString and set at setup; 2FA is
+	// only enforced once a first code confirmed it. Backup codes are SHA-256 hashes, removed when used.
+	TOTPSecret      string         `gorm:"column:totp_secret;type:text"`
+	TOTPEnabled     bool           `gorm:"column:totp_enabled;not null;default:false"`
+	TOTPLastStep    int64          `gorm:"column:totp_last_step;not null;default:0"` // last accepted time step, against replays
+	TOTPBackupCodes pq.StringArray `gorm:"column:totp_backup_codes;type:text[]"`
+
 	// Store the entire subscription object as JSON in case of changes
 	SubscriptionSnapshot datatypes.JSON `gorm:"type:jsonb;default:'{}'"`
 
@@ -17,3 +56,12 @@ type Account struct {
 	Subs     []Subscription `gorm:"foreignKey:AccountID"`
 	Payments []Transaction  `gorm:"foreignKey:AccountID"`
 }
+
+// Companion is one person the traveler brings along. Age is optional; a child or parent
+// without one is planned for as a school-age child or a senior.
+type Companion struct {
+	Name     string `json:"name,omitempty"`
+	Relation string `json:"relation"` // partner | child | parent | friend | other
+	Age      *int   `json:"age,omitempty"`
+	Notes    string `json:"notes,omitempty"`
+}
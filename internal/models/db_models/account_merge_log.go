@@ -0,0 +1,18 @@
+package db_models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// AccountMergeLog is the audit trail of admin-initiated account merges (dry runs included).
+type AccountMergeLog struct {
+	BaseModel
+	SourceAccountID uuid.UUID `gorm:"type:uuid;index;not null"`
+	TargetAccountID uuid.UUID `gorm:"type:uuid;index;not null"`
+	PerformedBy     string    `gorm:"size:64"`
+	DryRun          bool      `gorm:"not null"`
+
+	// Snapshot of the report returned to the admin (counts moved, warnings)
+	Report datatypes.JSON `gorm:"type:jsonb;default:'{}'"`
+}
@@ -3,6 +3,7 @@ package db_models
 import (
 	"github.com/google/uuid"
 	"time"
+	resp "vivu/internal/models/response_models"
 )
 
 type JourneyDay struct {
@@ -10,9 +11,16 @@ type JourneyDay struct {
 	JourneyID uuid.UUID
 	Date      time.Time
 	DayNumber int
+	// Where the traveller sleeps that night; adds a final leg back to it
+	AccommodationPOIID *uuid.UUID `gorm:"type:uuid"`
+	// Indoor alternative for bad weather; swapped with Activities on request
+	RainyPlan []PlannedActivity `gorm:"type:jsonb;serializer:json"`
+	// Leg from the last stop back to the accommodation, kept fresh by the leg worker
+	ReturnLeg *resp.TravelLeg `gorm:"type:jsonb;serializer:json"`
 
-	Journey    Journey           `gorm:"foreignKey:JourneyID"`
-	Activities []JourneyActivity `gorm:"foreignKey:JourneyDayID"`
+	Journey       Journey           `gorm:"foreignKey:JourneyID"`
+	Activities    []JourneyActivity `gorm:"foreignKey:JourneyDayID"`
+	Accommodation *POI              `gorm:"foreignKey:AccommodationPOIID"`
 }
 
 type JourneyActivity struct {
@@ -23,7 +31,24 @@ type JourneyActivity struct {
 	ActivityType  string
 	SelectedPOIID uuid.UUID
 	Notes         string
+	// Reservation with a partner, see BookingStatus*
+	BookingStatus    string `gorm:"size:16"`
+	BookingPartner   string `gorm:"size:64"`
+	BookingReference string `gorm:"size:128"`
+	// Why the planner picked the POI; nil for activities added by hand
+	Selection *resp.SelectionSignals `gorm:"type:jsonb;serializer:json"`
+	// Leg from the previous stop of the day, kept fresh by the leg worker; nil until it ran
+	Leg *resp.TravelLeg `gorm:"type:jsonb;serializer:json"`
 
 	JourneyDay  JourneyDay `gorm:"foreignKey:JourneyDayID"`
 	SelectedPOI POI        `gorm:"foreignKey:SelectedPOIID"`
 }
+
+// PlannedActivity is an activity kept outside journey_activities (e.g. a day's rainy-day plan).
+// Times are VN wall clock "15:04" on the day's date.
+type PlannedActivity struct {
+	POIID     uuid.UUID `json:"poi_id"`
+	StartTime string    `json:"start_time"`
+	EndTime   string    `json:"end_time,omitempty"`
+	Notes     string    `json:"notes,omitempty"`
+}
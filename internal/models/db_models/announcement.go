@@ -0,0 +1,41 @@
+package db_models
+
+import "github.com/lib/pq"
+
+const (
+	AnnouncementInfo        = "info"
+	AnnouncementPromo       = "promo"
+	AnnouncementMaintenance = "maintenance"
+)
+
+// Audience tiers an announcement can target.
+const (
+	AudienceGuest   = "guest"   // not signed in
+	AudienceFree    = "free"    // signed in, no running subscription
+	AudiencePremium = "premium" // signed in with a running subscription
+)
+
+// Announcement is a banner shown in the apps between StartsAt and EndsAt while Active. Empty
+// Tiers and Locales target everyone; app versions are inclusive bounds, empty for none.
+type Announcement struct {
+	BaseModel
+	Kind        string `gorm:"size:16;not null"`
+	Title       string `gorm:"size:200;not null"`
+	Body        string `gorm:"type:text"`
+	CTAText     string `gorm:"column:cta_text;size:100"`
+	CTAURL      string `gorm:"column:cta_url;type:text"`
+	Dismissible bool   `gorm:"not null;default:true"`
+	Priority    int    `gorm:"not null;default:0"` // higher first
+
+	Tiers         pq.StringArray `gorm:"type:text[]"`
+	Locales       pq.StringArray `gorm:"type:text[]"`
+	MinAppVersion string         `gorm:"size:32"`
+	MaxAppVersion string         `gorm:"size:32"`
+
+	Active   bool  `gorm:"not null;default:false;index"`
+	StartsAt int64 `gorm:"not null;index"`
+	EndsAt   *int64
+
+	CreatedBy string `gorm:"size:64"`
+	UpdatedBy string `gorm:"size:64"`
+}
@@ -0,0 +1,33 @@
+package db_models
+
+import (
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Scopes an API key can be issued with.
+const (
+	APIScopePOIsRead      = "pois:read"
+	APIScopeProvincesRead = "provinces:read"
+	APIScopePlansGenerate = "plans:generate"
+	// Lets the key change the planning policy of its account
+	APIScopePlanningPolicy = "planning_policy:write"
+)
+
+// APIKey lets a partner app call selected endpoints as the account it belongs to. Only the
+// SHA-256 of the secret is stored; Prefix is the start of the key, kept to tell keys apart.
+type APIKey struct {
+	BaseModel
+	AccountID  uuid.UUID      `gorm:"type:uuid;not null;index"`
+	Name       string         `gorm:"size:100;not null"`
+	Prefix     string         `gorm:"size:16;not null"`
+	KeyHash    string         `gorm:"size:64;not null;uniqueIndex"`
+	Scopes     pq.StringArray `gorm:"type:text[];not null"`
+	ExpiresAt  *int64
+	RevokedAt  *int64
+	LastUsedAt *int64
+	DailyQuota int    `gorm:"not null;default:0"` // requests per VN day; 0: API_KEY_DAILY_QUOTA
+	CreatedBy  string `gorm:"size:64"`
+
+	Account Account `gorm:"foreignKey:AccountID"`
+}
@@ -0,0 +1,16 @@
+package db_models
+
+import "github.com/google/uuid"
+
+// APIKeyUsage counts the calls made with an API key on one day (VN time). Throttled calls were
+// refused for the daily quota and are not part of Requests.
+type APIKeyUsage struct {
+	BaseModel
+	APIKeyID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_api_key_usage_key_day"`
+	Day       string    `gorm:"size:10;not null;uniqueIndex:idx_api_key_usage_key_day"` // YYYY-MM-DD
+	Requests  int64     `gorm:"not null;default:0"`
+	Errors    int64     `gorm:"not null;default:0"` // answered with a 4xx or 5xx
+	Throttled int64     `gorm:"not null;default:0"`
+
+	APIKey APIKey `gorm:"foreignKey:APIKeyID"`
+}
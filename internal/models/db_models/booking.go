@@ -0,0 +1,31 @@
+package db_models
+
+import "github.com/google/uuid"
+
+// Booking status of a journey activity; empty until the traveller starts a booking.
+const (
+	BookingStatusPending   = "pending" // handed off to the partner, not confirmed yet
+	BookingStatusConfirmed = "confirmed"
+	BookingStatusCancelled = "cancelled"
+)
+
+// POIBookingLink is where a bookable POI (tour, show...) can be reserved with a partner.
+// DeepLinkParams are added to the URL query; values may use the {date}, {time} and {ref}
+// placeholders, filled from the activity and the booking event.
+type POIBookingLink struct {
+	BaseModel
+	POIID          uuid.UUID         `gorm:"type:uuid;not null;uniqueIndex:idx_poi_booking_partner"`
+	Partner        string            `gorm:"size:64;not null;uniqueIndex:idx_poi_booking_partner"`
+	URL            string            `gorm:"type:text;not null"`
+	DeepLinkParams map[string]string `gorm:"type:jsonb;serializer:json"`
+}
+
+// BookingEvent is one "book" hand-off to a partner, kept for attribution.
+type BookingEvent struct {
+	BaseModel
+	AccountID  uuid.UUID  `gorm:"type:uuid;not null;index"`
+	POIID      uuid.UUID  `gorm:"type:uuid;not null;index"`
+	ActivityID *uuid.UUID `gorm:"type:uuid;index"`
+	Partner    string     `gorm:"size:64;not null;index"`
+	URL        string     `gorm:"type:text"`
+}
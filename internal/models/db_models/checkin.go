@@ -7,10 +7,12 @@ type CheckIn struct {
 	AccountID uuid.UUID // Change from UserID
 	JourneyID uuid.UUID
 	POIID     uuid.UUID
-	Notes     string
-	Stars     int     // 1 to 5
-	Account   Account `gorm:"foreignKey:AccountID"`
-	Journey   Journey `gorm:"foreignKey:JourneyID"`
-	POI       POI     `gorm:"foreignKey:POIID"`
-	Photos    []Photo `gorm:"foreignKey:CheckInID"`
+	// Planned activity this check-in completes; nil for free check-ins
+	ActivityID *uuid.UUID `gorm:"type:uuid;index"`
+	Notes      string
+	Stars      int     // 1 to 5
+	Account    Account `gorm:"foreignKey:AccountID"`
+	Journey    Journey `gorm:"foreignKey:JourneyID"`
+	POI        POI     `gorm:"foreignKey:POIID"`
+	Photos     []Photo `gorm:"foreignKey:CheckInID"`
 }
@@ -0,0 +1,66 @@
+package db_models
+
+import (
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	ChurnRiskLow    = "low"
+	ChurnRiskMedium = "medium"
+	ChurnRiskHigh   = "high"
+)
+
+// Reasons an account is at risk, as stored in ChurnScore.Reasons.
+const (
+	ChurnReasonNoJourneys     = "no_journeys_60d"
+	ChurnReasonAutoRenewOff   = "auto_renew_off"
+	ChurnReasonUsageDeclining = "usage_declining"
+)
+
+// ChurnScore is the latest churn-risk score of an account, rewritten by every scoring run,
+// and where the account stands in its win-back sequence.
+type ChurnScore struct {
+	BaseModel
+	AccountID uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex"`
+	Score     int            `gorm:"not null"` // 0-100
+	Segment   string         `gorm:"size:16;not null;index"`
+	Reasons   pq.StringArray `gorm:"type:text[]"`
+	// Signals the score came from
+	LastJourneyAt  *int64
+	RecentJourneys int   `gorm:"not null;default:0"` // created in the last 30 days
+	PriorJourneys  int   `gorm:"not null;default:0"` // created 31 to 90 days ago
+	AutoRenewOff   bool  `gorm:"not null;default:false"`
+	ScoredAt       int64 `gorm:"not null"`
+
+	// Win-back sequence: started when the account entered a segment with an active campaign,
+	// WinbackStep is the next step to send and WinbackNextAt when; nil once the sequence ended.
+	// The sequence is forgotten when the account is back to low risk.
+	WinbackSegment   string `gorm:"size:16"`
+	WinbackStartedAt *int64
+	WinbackStep      int    `gorm:"not null;default:0"`
+	WinbackNextAt    *int64 `gorm:"index"`
+	WinbackLastAt    *int64
+
+	Account Account `gorm:"foreignKey:AccountID"`
+}
+
+// WinbackCampaign is the email sequence sent to the accounts of one risk segment. Each step
+// waits DelayDays after the previous one (after the account entered the segment for the first).
+type WinbackCampaign struct {
+	BaseModel
+	Segment   string        `gorm:"size:16;not null;uniqueIndex"` // medium | high
+	Active    bool          `gorm:"not null;default:false"`
+	Steps     []WinbackStep `gorm:"type:jsonb;serializer:json"`
+	UpdatedBy string        `gorm:"size:64"`
+}
+
+// WinbackStep is one email of a campaign; "{name}" in Subject and Body is replaced by the
+// account's name.
+type WinbackStep struct {
+	DelayDays int    `json:"delay_days"`
+	Subject   string `json:"subject"`
+	Body      string `json:"body"`
+	CTAText   string `json:"cta_text,omitempty"`
+	CTAURL    string `json:"cta_url,omitempty"`
+}
@@ -0,0 +1,32 @@
+package db_models
+
+import "github.com/google/uuid"
+
+// Collection is an editorial list of POIs ("Best cafés in Da Lat", "Hidden waterfalls").
+// Unpublished collections are only visible to admins.
+type Collection struct {
+	BaseModel
+	Slug          string     `gorm:"size:120;uniqueIndex;not null"`
+	Title         string     `gorm:"size:200;not null"`
+	Description   string     `gorm:"type:text"`
+	CoverImageURL string     `gorm:"type:text"`
+	ProvinceID    *uuid.UUID `gorm:"type:uuid;index"` // nil for collections across provinces
+	IsPublished   bool       `gorm:"not null;default:false;index"`
+	UpdatedBy     string     `gorm:"size:64"`
+
+	Province *Province        `gorm:"foreignKey:ProvinceID"`
+	Items    []CollectionItem `gorm:"foreignKey:CollectionID"`
+}
+
+// CollectionItem is one POI of a collection, shown in Position order.
+type CollectionItem struct {
+	BaseModel
+	CollectionID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_collection_items_collection_poi"`
+	POIID        uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_collection_items_collection_poi"`
+	Position     int       `gorm:"not null"`
+	Note         string    `gorm:"type:text"` // the curator's tip, e.g. "Order the egg coffee"
+	// How long a visit takes; 0 leaves it to the planner
+	DurationMinutes int `gorm:"not null;default:0"`
+
+	POI POI `gorm:"foreignKey:POIID"`
+}
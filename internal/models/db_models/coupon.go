@@ -0,0 +1,26 @@
+package db_models
+
+import "github.com/lib/pq"
+
+const (
+	CouponPercent = "percent"
+	CouponFixed   = "fixed"
+)
+
+// Coupon takes PercentOff percent or AmountOffMinor (in Currency) off the price of a plan at
+// checkout. Redemptions counts the payments that went through with it; MaxRedemptions and
+// ExpiresAt are unlimited when nil, and PlanCodes empty means every plan.
+type Coupon struct {
+	BaseModel
+	Code           string `gorm:"size:32;not null;uniqueIndex"` // upper case
+	Kind           string `gorm:"size:16;not null"`
+	PercentOff     int    `gorm:"not null;default:0"`
+	AmountOffMinor int64  `gorm:"not null;default:0"`
+	Currency       string `gorm:"size:3"`
+	MaxRedemptions *int
+	Redemptions    int `gorm:"not null;default:0"`
+	ExpiresAt      *int64
+	PlanCodes      pq.StringArray `gorm:"type:text[]"`
+	IsActive       bool           `gorm:"not null;default:true"`
+	CreatedBy      string         `gorm:"size:64"`
+}
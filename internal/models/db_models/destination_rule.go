@@ -0,0 +1,20 @@
+package db_models
+
+import (
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// DestinationRule holds planning guardrails for one province, e.g. ferry buffers for islands
+// or no driving on mountain passes after dark. Zero values mean "no rule".
+type DestinationRule struct {
+	BaseModel
+	ProvinceID          uuid.UUID      `gorm:"type:uuid;uniqueIndex;not null"`
+	MaxActivitiesPerDay int            `gorm:"not null;default:0"`
+	ForbiddenWindows    pq.StringArray `gorm:"type:text[]"`        // "HH:MM-HH:MM" in VN time, may wrap midnight
+	BufferMinutes       int            `gorm:"not null;default:0"` // minimum gap between two activities
+	Note                string         `gorm:"type:text"`          // free-text hint passed to the AI
+	UpdatedBy           string         `gorm:"size:64"`
+
+	Province Province `gorm:"foreignKey:ProvinceID"`
+}
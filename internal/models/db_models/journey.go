@@ -16,6 +16,12 @@ type Journey struct {
 	IsShared    bool
 	IsCompleted bool
 	Location    string
+	// Opt-out of the pre-trip reminder emails
+	RemindersOff bool `gorm:"not null;default:false"`
+	// Opens the print view without logging in; nil when no link was shared
+	ShareToken *string `gorm:"size:32;uniqueIndex"`
+	// Diversity score of the generated plan; nil for journeys built by hand or imported
+	Diversity *resp.PlanDiversity `gorm:"type:jsonb;serializer:json"`
 
 	Account  Account      `gorm:"foreignKey:AccountID"`
 	Days     []JourneyDay `gorm:"foreignKey:JourneyID"`
@@ -64,6 +70,7 @@ func BuildJourneyDetailResponse(j *Journey) *resp.JourneyDetailResponse {
 		IsShared:    j.IsShared,
 		IsCompleted: j.IsCompleted,
 		Location:    j.Location,
+		Diversity:   j.Diversity,
 	}
 
 	// Duration (inclusive days)
@@ -87,11 +94,23 @@ func BuildJourneyDetailResponse(j *Journey) *resp.JourneyDetailResponse {
 			Date:       formatTime(d.Date),
 			Activities: make([]resp.JourneyActivityDetail, 0, len(d.Activities)),
 		}
+		if d.Accommodation != nil && d.Accommodation.ID != uuid.Nil {
+			dayResp.Accommodation = &resp.POISummary{
+				ID:        d.Accommodation.ID,
+				Name:      d.Accommodation.Name,
+				Address:   d.Accommodation.Address,
+				Latitude:  d.Accommodation.Latitude,
+				Longitude: d.Accommodation.Longitude,
+				Status:    d.Accommodation.Status,
+			}
+		}
 
 		sort.Slice(d.Activities, func(i, j int) bool {
 			return d.Activities[i].Time.Before(d.Activities[j].Time)
 		})
 
+		// A stored leg only holds while the previous stop is still the one it was computed from
+		var prevStop uuid.UUID
 		for _, a := range d.Activities {
 			ad := resp.JourneyActivityDetail{
 				ID:           a.ID,
@@ -99,6 +118,10 @@ func BuildJourneyDetailResponse(j *Journey) *resp.JourneyDetailResponse {
 				EndTime:      formatTimeIfNotNil(a.EndTime),
 				ActivityType: a.ActivityType,
 				Notes:        a.Notes,
+
+				BookingStatus:    a.BookingStatus,
+				BookingPartner:   a.BookingPartner,
+				BookingReference: a.BookingReference,
 			}
 
 			if a.SelectedPOI.ID != uuid.Nil {
@@ -110,6 +133,12 @@ func BuildJourneyDetailResponse(j *Journey) *resp.JourneyDetailResponse {
 					Longitude: a.SelectedPOI.Longitude,
 					Status:    a.SelectedPOI.Status,
 				}
+				if a.Leg != nil && prevStop != uuid.Nil && a.Leg.FromPOIID == prevStop && a.Leg.ToPOIID == a.SelectedPOI.ID {
+					ad.Leg = a.Leg
+				}
+				if a.SelectedPOI.Latitude != 0 || a.SelectedPOI.Longitude != 0 {
+					prevStop = a.SelectedPOI.ID
+				}
 			}
 
 			dayResp.Activities = append(dayResp.Activities, ad)
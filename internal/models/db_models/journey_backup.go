@@ -0,0 +1,30 @@
+package db_models
+
+import "github.com/google/uuid"
+
+// Statuses of a journey backup job.
+const (
+	JourneyBackupPending = "pending" // queued or being built
+	JourneyBackupReady   = "ready"   // the zip is stored until ExpiresAt
+	JourneyBackupFailed  = "failed"  // gave up after the last attempt
+	JourneyBackupExpired = "expired" // the zip was deleted
+)
+
+// JourneyBackupJob queues a backup of every journey of an account. The worker writes the zip to
+// file storage and emails the owner; the file is served until ExpiresAt.
+type JourneyBackupJob struct {
+	BaseModel
+	AccountID uuid.UUID `gorm:"type:uuid;not null;index"`
+	Status    string    `gorm:"size:16;not null;default:'pending';index"`
+	Attempts  int       `gorm:"not null;default:0"`
+	LastError string    `gorm:"type:text"`
+	// Set while a worker builds the zip; a claim that outlives its worker expires and the job is retried
+	ClaimedUntil *int64
+
+	StorageKey  string `gorm:"size:255"`
+	FileName    string `gorm:"size:255"`
+	SizeBytes   int64
+	Journeys    int
+	CompletedAt *int64
+	ExpiresAt   *int64
+}
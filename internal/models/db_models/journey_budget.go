@@ -0,0 +1,26 @@
+package db_models
+
+import "github.com/google/uuid"
+
+// JourneyBudget is the spending envelope of a trip, in VND.
+type JourneyBudget struct {
+	BaseModel
+	JourneyID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex"`
+	Amount    int64     `gorm:"not null"`
+	// When the over-budget alert went out; cleared when the budget changes or the projection
+	// falls back under it, so the next overrun alerts again
+	AlertSentAt *int64
+}
+
+// JourneyExpense is one spending entry of a trip, in VND.
+type JourneyExpense struct {
+	BaseModel
+	JourneyID  uuid.UUID  `gorm:"type:uuid;not null;index"`
+	AccountID  uuid.UUID  `gorm:"type:uuid;not null"` // who logged it
+	ActivityID *uuid.UUID `gorm:"type:uuid"`
+	Amount     int64      `gorm:"not null"`
+	Category   string     `gorm:"size:32;not null"`
+	Merchant   string     `gorm:"size:128"`
+	Note       string     `gorm:"size:500"`
+	SpentAt    int64      `gorm:"not null;index"`
+}
@@ -0,0 +1,18 @@
+package db_models
+
+import "github.com/google/uuid"
+
+// JourneyComment is a message of a journey's participants, on the journey or on one activity.
+// Replies point at a top-level comment; deleted comments are soft-deleted so threads keep their shape.
+type JourneyComment struct {
+	BaseModel
+	JourneyID  uuid.UUID  `gorm:"type:uuid;not null;index"`
+	ActivityID *uuid.UUID `gorm:"type:uuid;index"`
+	ParentID   *uuid.UUID `gorm:"type:uuid;index"`
+	AccountID  uuid.UUID  `gorm:"type:uuid;not null"`
+	Body       string     `gorm:"type:text;not null"`
+	// Participants @mentioned in Body, notified when the comment is posted
+	Mentions []uuid.UUID `gorm:"type:jsonb;serializer:json"`
+
+	Account Account `gorm:"foreignKey:AccountID"`
+}
@@ -0,0 +1,59 @@
+package db_models
+
+import "github.com/google/uuid"
+
+// Collaboration roles of a journey. The owner is Journey.AccountID and has no member row.
+const (
+	JourneyRoleOwner  = "owner"
+	JourneyRoleEditor = "editor" // can open polls and vote
+	JourneyRoleViewer = "viewer" // can vote
+)
+
+// Poll status; a poll closes at its deadline and its winner replaces the activity's POI.
+const (
+	PollStatusOpen   = "open"
+	PollStatusClosed = "closed"
+)
+
+// JourneyMember is an account the owner invited to plan the journey together.
+type JourneyMember struct {
+	BaseModel
+	JourneyID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_journey_member"`
+	AccountID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_journey_member;index"`
+	Role      string    `gorm:"size:16;not null"`
+
+	Account Account `gorm:"foreignKey:AccountID"`
+}
+
+// JourneyPoll lets the members of a journey pick the POI of one activity slot.
+type JourneyPoll struct {
+	BaseModel
+	JourneyID      uuid.UUID  `gorm:"type:uuid;not null;index"`
+	ActivityID     uuid.UUID  `gorm:"type:uuid;not null;index"`
+	CreatedBy      uuid.UUID  `gorm:"type:uuid;not null"`
+	Question       string     `gorm:"size:255"`
+	Deadline       int64      `gorm:"not null;index"`
+	Status         string     `gorm:"size:16;not null;default:'open';index"`
+	WinnerOptionID *uuid.UUID `gorm:"type:uuid"`
+	ClosedAt       *int64
+
+	Options []JourneyPollOption `gorm:"foreignKey:PollID"`
+	Votes   []JourneyPollVote   `gorm:"foreignKey:PollID"`
+}
+
+type JourneyPollOption struct {
+	BaseModel
+	PollID   uuid.UUID `gorm:"type:uuid;not null;index"`
+	POIID    uuid.UUID `gorm:"type:uuid;not null"`
+	Position int       `gorm:"not null"` // ties go to the first option
+
+	POI POI `gorm:"foreignKey:POIID"`
+}
+
+// JourneyPollVote is the current choice of one member; voting again replaces it.
+type JourneyPollVote struct {
+	BaseModel
+	PollID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_poll_vote"`
+	AccountID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_poll_vote"`
+	OptionID  uuid.UUID `gorm:"type:uuid;not null"`
+}
@@ -0,0 +1,35 @@
+package db_models
+
+import "github.com/google/uuid"
+
+const (
+	LegalTerms   = "terms"
+	LegalPrivacy = "privacy"
+)
+
+// LegalDocument is one published version of the terms of service or the privacy policy.
+// Accounts must accept the latest version that is not Minor; a minor version (a typo fix)
+// does not ask accounts that accepted an earlier one again.
+type LegalDocument struct {
+	BaseModel
+	Kind        string `gorm:"size:16;not null;uniqueIndex:idx_legal_documents_kind_version"`
+	Version     string `gorm:"size:32;not null;uniqueIndex:idx_legal_documents_kind_version"`
+	Title       string `gorm:"size:200;not null"`
+	URL         string `gorm:"type:text"`
+	Body        string `gorm:"type:text"`
+	Minor       bool   `gorm:"not null;default:false"`
+	PublishedAt int64  `gorm:"not null"`
+	PublishedBy string `gorm:"size:64"`
+}
+
+// LegalAcceptance records that an account accepted a document version, and from where.
+type LegalAcceptance struct {
+	BaseModel
+	AccountID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_legal_acceptances_account_document"`
+	DocumentID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_legal_acceptances_account_document"`
+	Kind       string    `gorm:"size:16;not null"`
+	Version    string    `gorm:"size:32;not null"`
+	AcceptedAt int64     `gorm:"not null"`
+	IP         string    `gorm:"size:64"`
+	UserAgent  string    `gorm:"type:text"`
+}
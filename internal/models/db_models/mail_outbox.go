@@ -0,0 +1,43 @@
+package db_models
+
+const (
+	MailKindNotify    = "notify"
+	MailKindResetCode = "reset_code"
+	MailKindTemplate  = "template"
+)
+
+const (
+	MailOutboxPending = "pending"
+	MailOutboxSent    = "sent"
+	MailOutboxDead    = "dead" // out of attempts or expired; an admin can put it back in the queue
+)
+
+// MailOutboxMessage is one transactional email waiting to be sent, and its log once sent.
+// Code is the one-time code of a reset email, sealed with utils.EncryptString. Template
+// messages are rendered when sent, from Template, Locale and Params (the code kept in Code).
+type MailOutboxMessage struct {
+	BaseModel
+	Kind          string            `gorm:"size:16;not null"`
+	Recipient     string            `gorm:"size:320;not null;index"`
+	Subject       string            `gorm:"type:text;not null"`
+	Body          string            `gorm:"type:text"`
+	CTAText       string            `gorm:"column:cta_text;size:100"`
+	CTAURL        string            `gorm:"column:cta_url;type:text"`
+	Code          string            `gorm:"type:text"`
+	Template      string            `gorm:"size:64"`
+	Locale        string            `gorm:"size:5"`
+	Params        map[string]string `gorm:"type:jsonb;serializer:json"`
+	Status        string            `gorm:"size:16;not null;default:'pending';index"`
+	Attempts      int               `gorm:"not null;default:0"`
+	NextAttemptAt int64             `gorm:"not null;index"`
+	LastError     string            `gorm:"type:text"`
+	SentAt        *int64
+	// Not sent after this; a reset code is useless once the reset token expired
+	ExpiresAt *int64
+	// Set while a worker sends the message; a claim that outlives its worker expires and the message is retried
+	ClaimedUntil *int64
+	// A file sent with a notify message, e.g. a receipt PDF
+	AttachmentName string `gorm:"size:255"`
+	AttachmentType string `gorm:"size:100"`
+	Attachment     []byte `gorm:"type:bytea"`
+}
@@ -0,0 +1,20 @@
+package db_models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Notification is an in-app message to an account, created from an event on the bus. Kind is
+// the event type; Data holds the event, for the app to link to what it is about.
+type Notification struct {
+	BaseModel
+	AccountID uuid.UUID      `gorm:"type:uuid;not null;index"`
+	Kind      string         `gorm:"size:64;not null"`
+	Title     string         `gorm:"size:200;not null"`
+	Body      string         `gorm:"type:text"`
+	Data      datatypes.JSON `gorm:"type:jsonb"`
+	ReadAt    *int64
+
+	Account Account `gorm:"foreignKey:AccountID"`
+}
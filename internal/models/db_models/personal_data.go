@@ -0,0 +1,44 @@
+package db_models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Purposes personal data is kept for, tagged on every table that holds some
+// (see repositories.PersonalDataTables) and on diagnostics captured while serving an account.
+const (
+	PurposeAccount       = "account"       // identity, login and profile
+	PurposeTripPlanning  = "trip_planning" // journeys and everything planned in them
+	PurposeCollaboration = "collaboration" // shared journeys: members, comments, polls
+	PurposeAIPrompt      = "ai_prompt"     // text typed to the planner and its embeddings
+	PurposeBilling       = "billing"       // payments, kept for accounting
+	PurposeLegal         = "legal"         // evidence of accepted terms
+	PurposeSupport       = "support"       // feedback
+	PurposeMessaging     = "messaging"     // notifications, emails and webhooks sent
+	PurposeIntegrations  = "integrations"  // API keys and their usage
+	PurposeAnalytics     = "analytics"     // usage counters, churn scores, booking clicks
+	PurposeDiagnostics   = "diagnostics"   // slow query captures
+)
+
+// What erasing an account does with a table's rows.
+const (
+	ErasureDelete = "delete"
+	ErasureRedact = "redact" // the row stays, its personal fields are blanked
+	ErasureRetain = "retain" // kept under a legal obligation; the account itself is anonymized
+)
+
+const (
+	PersonalDataExport  = "export"
+	PersonalDataErasure = "erasure"
+)
+
+// PersonalDataRequest is the audit trail of data exports and erasures, kept after the account
+// is gone: Report holds the rows counted per table, no personal data.
+type PersonalDataRequest struct {
+	BaseModel
+	AccountID   uuid.UUID      `gorm:"type:uuid;not null;index"`
+	Kind        string         `gorm:"size:16;not null"`
+	RequestedBy string         `gorm:"size:64"`
+	Report      datatypes.JSON `gorm:"type:jsonb;default:'{}'"`
+}
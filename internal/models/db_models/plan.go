@@ -1,9 +1,17 @@
 package db_models
 
 import (
+	"github.com/google/uuid"
 	"gorm.io/datatypes"
 )
 
+// Entitlements a plan can grant, stored in Features as {"key": value}.
+const (
+	EntitlementUnlimitedPlans = "unlimited_plans" // bool: no monthly cap on generated plans
+	EntitlementMaxTripDays    = "max_trip_days"   // int: longest trip a plan is generated for
+	EntitlementAIProfile      = "ai_profile"      // string: AI profile plans are generated with (AI_PROFILES)
+)
+
 type Plan struct {
 	BaseModel
 	Code            string `gorm:"uniqueIndex"` // e.g., "basic", "pro_monthly", "pro_yearly"
@@ -17,4 +25,8 @@ type Plan struct {
 	IsActive        bool          `gorm:"default:true"`
 	// Optional: feature flags, limits, etc.
 	Features datatypes.JSON `gorm:"type:jsonb;default:'{}'"`
+
+	// A plan with subscribers keeps its price; a new version replaces it for new checkouts
+	Version        int32      `gorm:"not null;default:1"`
+	PreviousPlanID *uuid.UUID `gorm:"type:uuid"`
 }
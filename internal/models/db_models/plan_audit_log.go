@@ -0,0 +1,27 @@
+package db_models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// What an admin did to a plan.
+const (
+	PlanAuditCreated     = "created"
+	PlanAuditUpdated     = "updated"
+	PlanAuditVersioned   = "versioned" // a new version replaced the plan
+	PlanAuditActivated   = "activated"
+	PlanAuditDeactivated = "deactivated"
+	PlanAuditDeleted     = "deleted"
+)
+
+// PlanAuditLog is the audit trail of admin changes to the plan catalog.
+type PlanAuditLog struct {
+	BaseModel
+	PlanID      uuid.UUID `gorm:"type:uuid;index;not null"`
+	Action      string    `gorm:"size:32;not null"`
+	PerformedBy string    `gorm:"size:64"`
+
+	// The fields that changed, as {"field": {"from": old, "to": new}}
+	Changes datatypes.JSON `gorm:"type:jsonb;default:'{}'"`
+}
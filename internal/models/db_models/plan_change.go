@@ -0,0 +1,35 @@
+package db_models
+
+import "github.com/google/uuid"
+
+const (
+	PlanChangeUpgrade   = "upgrade"
+	PlanChangeDowngrade = "downgrade"
+
+	PlanChangePending = "pending"
+	PlanChangeApplied = "applied"
+	PlanChangeFailed  = "failed"
+)
+
+// SubscriptionPlanChange records a move of a running subscription to another plan. The unused
+// part of the old subscription is credited against the new plan: a positive ChargeMinor is paid
+// through a checkout before the change applies, and credit beyond the new price is added to the
+// new subscription as BonusSeconds.
+type SubscriptionPlanChange struct {
+	BaseModel
+	AccountID          uuid.UUID  `gorm:"type:uuid;not null;index"`
+	FromSubscriptionID uuid.UUID  `gorm:"type:uuid;not null"`
+	ToSubscriptionID   *uuid.UUID `gorm:"type:uuid"`
+	FromPlanID         uuid.UUID  `gorm:"type:uuid;not null"`
+	ToPlanID           uuid.UUID  `gorm:"type:uuid;not null"`
+	TransactionID      *uuid.UUID `gorm:"type:uuid"`
+
+	Direction        string `gorm:"size:16;not null;index"`
+	Status           string `gorm:"size:16;not null;index"`
+	Currency         string `gorm:"size:3;not null"`
+	RemainingSeconds int64  `gorm:"not null"` // left on the old subscription when quoted
+	CreditMinor      int64  `gorm:"not null"`
+	ChargeMinor      int64  `gorm:"not null"`
+	BonusSeconds     int64  `gorm:"not null;default:0"`
+	AppliedAt        *int64 `gorm:"index"`
+}
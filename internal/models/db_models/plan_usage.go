@@ -0,0 +1,13 @@
+package db_models
+
+import "github.com/google/uuid"
+
+// PlanUsage counts the AI plans an account generated in one calendar month (VN time).
+type PlanUsage struct {
+	BaseModel
+	AccountID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_plan_usage_account_month"`
+	Month     string    `gorm:"size:7;not null;uniqueIndex:idx_plan_usage_account_month"` // YYYY-MM
+	Count     int       `gorm:"not null;default:0"`
+
+	Account Account `gorm:"foreignKey:AccountID"`
+}
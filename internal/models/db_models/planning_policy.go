@@ -0,0 +1,19 @@
+package db_models
+
+import (
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// PlanningPolicy shapes every plan generated for a partner account (an agency or a white-label
+// app calling with its API keys): kinds of places never scheduled, partner POIs favoured and
+// POIs always included when the trip goes their way. Set through the partner API.
+type PlanningPolicy struct {
+	BaseModel
+	AccountID uuid.UUID `gorm:"type:uuid;uniqueIndex;not null"`
+	// Matched like the traveler's avoid answers: a category ("Nightlife & bars") or any word ("karaoke")
+	Exclude       pq.StringArray `gorm:"type:text[]"`
+	BoostPOIIDs   pq.StringArray `gorm:"column:boost_poi_ids;type:text[]"`
+	RequirePOIIDs pq.StringArray `gorm:"column:require_poi_ids;type:text[]"`
+	UpdatedBy     string         `gorm:"size:64"` // key or user that last changed it
+}
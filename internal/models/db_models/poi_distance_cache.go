@@ -0,0 +1,16 @@
+package db_models
+
+// PoiDistanceCache persists Mapbox matrix results per (mode, from, to) so restarts
+// don't re-pay for pairs we already know.
+type PoiDistanceCache struct {
+	Mode            string `gorm:"primaryKey;size:16"`
+	FromPoi         string `gorm:"primaryKey;size:64"`
+	ToPoi           string `gorm:"primaryKey;size:64"`
+	DistanceMeters  int    `gorm:"not null"`
+	DurationSeconds int    `gorm:"not null;default:0"`
+	ExpiresAt       int64  `gorm:"index;not null"` // unix seconds
+}
+
+func (PoiDistanceCache) TableName() string {
+	return "poi_distance_cache"
+}
@@ -14,5 +14,7 @@ type PoiEmbedding struct {
 	CategoryID  string          // stores the UUID of the category
 	Tags        pq.StringArray  `gorm:"type:text[]"`
 	Embedding   pgvector.Vector `gorm:"type:vector(1536)"`
-	CreatedAt   time.Time       `gorm:"autoCreateTime"`
+	// Version of the text builder the vector came from; older rows are re-embedded
+	ContentVersion int       `gorm:"not null;default:0"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
 }
@@ -0,0 +1,29 @@
+package db_models
+
+import "github.com/google/uuid"
+
+// Reasons a POI is queued for (re)embedding.
+const (
+	EmbeddingReasonImport  = "import"
+	EmbeddingReasonCreate  = "create"
+	EmbeddingReasonUpdate  = "update"
+	EmbeddingReasonDelete  = "delete"
+	EmbeddingReasonReindex = "reindex"
+)
+
+// POIEmbeddingOutbox queues POIs whose embedding must be (re)generated. Rows are written in the
+// same transaction as the POI change, so nothing is lost if the process dies before embedding.
+type POIEmbeddingOutbox struct {
+	BaseModel
+	POIID       uuid.UUID `gorm:"type:uuid;index;not null"`
+	Reason      string    `gorm:"size:32"`
+	ProcessedAt *int64    `gorm:"index"`
+	Attempts    int       `gorm:"not null;default:0"`
+	LastError   string    `gorm:"type:text"`
+	// Set while a worker embeds the row; a claim that outlives its worker expires and the row is retried
+	ClaimedUntil *int64
+}
+
+func (POIEmbeddingOutbox) TableName() string {
+	return "poi_embedding_outbox"
+}
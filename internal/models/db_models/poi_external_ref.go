@@ -0,0 +1,24 @@
+package db_models
+
+import "github.com/google/uuid"
+
+// Sources we pull third-party ratings from.
+const (
+	ExternalSourceGoogle      = "google"
+	ExternalSourceTripAdvisor = "tripadvisor"
+)
+
+// POIExternalRef links a POI to its listing on a third-party site and keeps the last rating
+// snapshot we fetched from it (refreshed by the enrichment job).
+type POIExternalRef struct {
+	BaseModel
+	POIID      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_poi_external_source"`
+	Source     string    `gorm:"size:32;not null;uniqueIndex:idx_poi_external_source"`
+	ExternalID string    `gorm:"size:255;not null"`
+	URL        string
+
+	Rating      *float64
+	ReviewCount *int
+	FetchedAt   *int64 `gorm:"index"` // unix seconds of the last successful refresh
+	LastError   string
+}
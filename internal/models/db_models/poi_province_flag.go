@@ -0,0 +1,42 @@
+package db_models
+
+import "github.com/google/uuid"
+
+// Why a POI was flagged by the province check.
+const (
+	POIProvinceFlagWrongProvince = "wrong_province" // the coordinates lie in another province
+	POIProvinceFlagSwapped       = "swapped_coords" // they do once latitude and longitude are swapped
+	POIProvinceFlagOutside       = "outside"        // they lie outside every known outline
+)
+
+const (
+	POIProvinceFlagOpen      = "open"
+	POIProvinceFlagApplied   = "applied"   // the suggested correction was written to the POI
+	POIProvinceFlagDismissed = "dismissed" // the POI is right; not raised again until it changes
+	POIProvinceFlagCleared   = "cleared"   // the POI was fixed by other means
+)
+
+// POIProvinceFlag is an entry of the admin correction queue: a POI whose coordinates do not
+// match its province. Latitude, Longitude and ProvinceID are the POI as it was checked, so a
+// correction is only applied to an unchanged POI.
+type POIProvinceFlag struct {
+	BaseModel
+	POIID      uuid.UUID `gorm:"type:uuid;uniqueIndex;not null"`
+	Kind       string    `gorm:"size:20;not null"`
+	Status     string    `gorm:"size:16;not null;default:'open';index"`
+	Latitude   float64   `gorm:"not null"`
+	Longitude  float64   `gorm:"not null"`
+	ProvinceID uuid.UUID `gorm:"type:uuid;not null;index"`
+
+	SuggestedProvinceID *uuid.UUID `gorm:"type:uuid"`
+	SuggestedLatitude   *float64
+	SuggestedLongitude  *float64
+
+	DetectedAt int64 `gorm:"not null"`
+	ResolvedAt *int64
+	ResolvedBy string `gorm:"size:64"`
+
+	POI               POI       `gorm:"foreignKey:POIID"`
+	Province          Province  `gorm:"foreignKey:ProvinceID"`
+	SuggestedProvince *Province `gorm:"foreignKey:SuggestedProvinceID"`
+}
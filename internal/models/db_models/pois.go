@@ -2,6 +2,9 @@ package db_models
 
 import "github.com/google/uuid"
 
+// POIStatusDraft marks POIs created from user imports; they stay out of search until reviewed.
+const POIStatusDraft = "draft"
+
 type POI struct {
 	BaseModel
 	Name         string
@@ -20,6 +23,7 @@ type POI struct {
 	Tags         []*Tag            `gorm:"many2many:poi_tags"`
 	Activities   []JourneyActivity `gorm:"foreignKey:SelectedPOIID"`
 	CheckIns     []CheckIn
+	ExternalRefs []POIExternalRef `gorm:"foreignKey:POIID"`
 }
 
 type POISearchDoc struct {
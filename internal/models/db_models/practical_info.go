@@ -0,0 +1,20 @@
+package db_models
+
+import (
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// PracticalInfo is the admin-maintained "good to know" block of a province: SIM/eSIM options,
+// typical taxi fares and tipping norms. It is shown as-is instead of tips invented by the AI.
+type PracticalInfo struct {
+	BaseModel
+	ProvinceID   uuid.UUID      `gorm:"type:uuid;uniqueIndex;not null"`
+	Connectivity pq.StringArray `gorm:"type:text[]"` // SIM/eSIM and Wi-Fi options
+	Transport    pq.StringArray `gorm:"type:text[]"` // typical taxi / ride-hailing costs
+	Tipping      string         `gorm:"type:text"`
+	Other        pq.StringArray `gorm:"type:text[]"`
+	UpdatedBy    string         `gorm:"size:64"`
+
+	Province Province `gorm:"foreignKey:ProvinceID"`
+}
@@ -0,0 +1,23 @@
+package db_models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// ProvinceBoundary is the outline of a province as a GeoJSON MultiPolygon ([lng, lat]
+// positions). The bounding box lets point lookups skip provinces cheaply.
+type ProvinceBoundary struct {
+	BaseModel
+	ProvinceID uuid.UUID      `gorm:"type:uuid;uniqueIndex;not null"`
+	Geometry   datatypes.JSON `gorm:"type:jsonb;not null"`
+	MinLat     float64        `gorm:"not null"`
+	MinLng     float64        `gorm:"not null"`
+	MaxLat     float64        `gorm:"not null"`
+	MaxLng     float64        `gorm:"not null"`
+	Points     int            `gorm:"not null;default:0"` // positions in Geometry
+	Source     string         `gorm:"size:255"`           // where the outline came from, e.g. "GADM 4.1"
+	UpdatedBy  string         `gorm:"size:64"`
+
+	Province Province `gorm:"foreignKey:ProvinceID"`
+}
@@ -0,0 +1,20 @@
+package db_models
+
+import (
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ProvinceGuide is the editorial content of a destination's landing screen: a cover image, a
+// short intro, hand-picked POIs in display order and the trip lengths that suit the place.
+type ProvinceGuide struct {
+	BaseModel
+	ProvinceID    uuid.UUID      `gorm:"type:uuid;uniqueIndex;not null"`
+	CoverImageURL string         `gorm:"type:text"`
+	Intro         string         `gorm:"type:text"`
+	HighlightPOIs pq.StringArray `gorm:"type:text[]"` // POI IDs
+	TripDays      pq.Int64Array  `gorm:"type:integer[]"`
+	UpdatedBy     string         `gorm:"size:64"`
+
+	Province Province `gorm:"foreignKey:ProvinceID"`
+}
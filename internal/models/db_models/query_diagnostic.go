@@ -0,0 +1,22 @@
+package db_models
+
+import "github.com/google/uuid"
+
+// QueryDiagnostic stores a slow query together with its EXPLAIN ANALYZE output.
+// One row per fingerprint; a slower run replaces the captured plan.
+type QueryDiagnostic struct {
+	BaseModel
+	Fingerprint    string `gorm:"size:32;uniqueIndex"` // fnv hash of the parameterized SQL
+	Table          string `gorm:"size:128;index"`
+	SQL            string `gorm:"type:text;not null"` // parameterized statement
+	BoundSQL       string `gorm:"type:text"`          // statement with bound parameters (slowest run)
+	DurationMs     int64  `gorm:"index"`              // slowest observed duration
+	RowsAffected   int64
+	Plan           string `gorm:"type:text"`
+	Occurrences    int64  `gorm:"not null;default:1"`
+	LastSeenAt     int64  `gorm:"index"`
+	LastCapturedAt int64
+	// Account whose request ran the slowest capture, and why; the bound SQL can hold what it typed
+	OwnerAccountID *uuid.UUID `gorm:"type:uuid;index"`
+	Purpose        string     `gorm:"size:32"`
+}
@@ -0,0 +1,11 @@
+package db_models
+
+// RuntimeSwitch is an operational toggle flipped by admins at runtime (no redeploy).
+// Enabled=true means the switch is engaged: maintenance is on, or the subsystem is turned off.
+type RuntimeSwitch struct {
+	BaseModel
+	Key       string `gorm:"size:64;uniqueIndex;not null"`
+	Enabled   bool   `gorm:"not null;default:false"`
+	Message   string `gorm:"type:text"` // shown to users while engaged
+	UpdatedBy string `gorm:"size:64"`
+}
@@ -0,0 +1,14 @@
+package db_models
+
+// SecretKeyUsage counts the reads of one version of an API key by one instance. Versions
+// are told apart by the fingerprint of their value, which is never stored.
+type SecretKeyUsage struct {
+	BaseModel
+	Name        string `gorm:"size:64;not null;uniqueIndex:idx_secret_key_usages_key"`
+	Fingerprint string `gorm:"size:16;not null;uniqueIndex:idx_secret_key_usages_key"`
+	Instance    string `gorm:"size:128;not null;uniqueIndex:idx_secret_key_usages_key"`
+	Source      string `gorm:"size:128;not null"` // env | vault:<path> | aws:<secret id>
+	Uses        int64  `gorm:"not null;default:0"`
+	FirstUsedAt int64  `gorm:"not null"`
+	LastUsedAt  int64  `gorm:"not null;index"`
+}
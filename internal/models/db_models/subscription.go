@@ -13,6 +13,9 @@ const (
 	SubStatusPastDue  SubscriptionStatus = "past_due"
 	SubStatusCanceled SubscriptionStatus = "canceled"
 	SubStatusExpired  SubscriptionStatus = "expired"
+	SubStatusPaused   SubscriptionStatus = "paused"
+	// SubStatusReplaced ends a subscription early because the account changed plans
+	SubStatusReplaced SubscriptionStatus = "replaced"
 )
 
 type BillingPeriod string
@@ -32,6 +35,20 @@ type Subscription struct {
 	EndsAt     int64              `gorm:"not null"`
 	CanceledAt *int64
 	AutoRenew  bool `gorm:"default:true"`
+	// ExpiryNoticeAt is when the "expiring soon" event went out; it goes out once per subscription
+	ExpiryNoticeAt *int64
+	// A pause freezes the PausedRemaining seconds left until EndsAt; resuming moves EndsAt to
+	// now + PausedRemaining. PausedAt stays set afterwards: one pause per period.
+	PausedAt        *int64
+	PausedRemaining int64 `gorm:"not null;default:0"`
+	ResumedAt       *int64
+
+	// Renewal of an auto-renewing subscription: RenewalAttempts counts the checkouts the renewal
+	// job opened, NextRenewalAt is when it acts next. PastDueAt is when a renewal first went
+	// unpaid; the account keeps the plan through the dunning grace period after the end.
+	RenewalAttempts int    `gorm:"not null;default:0"`
+	NextRenewalAt   *int64 `gorm:"index"`
+	PastDueAt       *int64
 
 	// Optional: couple to payment provider (keep if you bill through Stripe/PayPal)
 	Provider           string `gorm:"index"` // "stripe","paypal","local"
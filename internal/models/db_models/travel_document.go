@@ -0,0 +1,35 @@
+package db_models
+
+import (
+	"github.com/google/uuid"
+	resp "vivu/internal/models/response_models"
+)
+
+// How a travel document reached the vault.
+const (
+	TravelDocumentUpload          = "upload"
+	TravelDocumentEmail           = "email"            // a booking email forwarded to the journey inbox
+	TravelDocumentEmailAttachment = "email_attachment" // a file attached to such an email
+)
+
+// TravelDocument is a file of a journey (ticket, hotel confirmation...). The content lives in
+// the file storage, encrypted; only metadata is kept here.
+type TravelDocument struct {
+	BaseModel
+	JourneyID   uuid.UUID `gorm:"type:uuid;not null;index"`
+	AccountID   uuid.UUID `gorm:"type:uuid;not null"` // uploader, or sender of the email
+	Kind        string    `gorm:"size:24;not null"`
+	FileName    string    `gorm:"size:255;not null"`
+	ContentType string    `gorm:"size:128"`
+	Size        int64     `gorm:"not null"`
+	StorageKey  string    `gorm:"size:255;not null"`
+	// Details found in a forwarded booking email; nil for uploads
+	Parsed *resp.BookingEmailInfo `gorm:"type:jsonb;serializer:json"`
+}
+
+// JourneyInbox is the token of a journey's forwarding address (trip-<token>@<domain>).
+type JourneyInbox struct {
+	BaseModel
+	JourneyID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex"`
+	Token     string    `gorm:"size:32;not null;uniqueIndex"`
+}
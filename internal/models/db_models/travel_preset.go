@@ -0,0 +1,18 @@
+package db_models
+
+import (
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// TravelPreset is a named bundle of travel preferences ("family mode", "solo backpacking")
+// that fills the matching quiz answers and the plan profile.
+type TravelPreset struct {
+	BaseModel
+	AccountID uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_travel_preset_name"`
+	Name      string         `gorm:"size:60;not null;uniqueIndex:idx_travel_preset_name"`
+	Pace      string         `gorm:"size:16"` // relaxed, balanced or packed; empty leaves it to the plan
+	Budget    string         `gorm:"size:16"` // one of the quiz budget options
+	Interests pq.StringArray `gorm:"type:text[]"`
+	Dietary   pq.StringArray `gorm:"type:text[]"`
+}
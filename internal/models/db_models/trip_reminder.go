@@ -0,0 +1,29 @@
+package db_models
+
+import "github.com/google/uuid"
+
+// Pre-trip reminder kinds, sent the given number of days before the journey starts.
+const (
+	TripReminderPacking   = "packing"   // T-14: packing list
+	TripReminderWeather   = "weather"   // T-3: check the forecast, rainy-day plans
+	TripReminderItinerary = "itinerary" // T-2 (TRIP_ITINERARY_EMAIL_DAYS): day-by-day summary of the plan
+	TripReminderDayOf     = "day_of"    // T-1: tomorrow's first stop, day-of mode
+)
+
+// TripReminder is one scheduled pre-trip message of a journey. Rows are (re)computed from the
+// journey start date, so moving the trip reschedules them.
+type TripReminder struct {
+	BaseModel
+	JourneyID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_trip_reminder_kind"`
+	Kind      string    `gorm:"size:16;not null;uniqueIndex:idx_trip_reminder_kind"`
+	TripStart int64     `gorm:"not null"` // journey start the schedule was computed from
+	DueAt     int64     `gorm:"not null;index"`
+	SentAt    *int64
+	Skipped   bool   // superseded by a later reminder that became due at the same time
+	Attempts  int    `gorm:"not null;default:0"`
+	LastError string `gorm:"type:text"`
+	// Set while an instance sends the reminder; an expired claim is picked up again
+	ClaimedUntil *int64
+
+	Journey Journey `gorm:"foreignKey:JourneyID"`
+}
@@ -0,0 +1,47 @@
+package db_models
+
+import (
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/datatypes"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed" // gave up after the last retry
+)
+
+// WebhookEndpoint receives signed JSON events. Endpoints of an account get its own events;
+// an endpoint without an account (registered by an admin) gets the events of every account.
+// Secret is the HMAC key, sealed with utils.EncryptString.
+type WebhookEndpoint struct {
+	BaseModel
+	AccountID   *uuid.UUID     `gorm:"type:uuid;index"`
+	URL         string         `gorm:"type:text;not null"`
+	Description string         `gorm:"size:255"`
+	Events      pq.StringArray `gorm:"type:text[]"` // empty: every event
+	Secret      string         `gorm:"type:text;not null"`
+	Active      bool           `gorm:"not null;default:true"`
+	CreatedBy   string         `gorm:"size:64"`
+}
+
+// WebhookDelivery is one event on its way to one endpoint, and its log once sent. Payload is
+// the exact body posted, so a retry carries the same signature input.
+type WebhookDelivery struct {
+	BaseModel
+	EndpointID     uuid.UUID      `gorm:"type:uuid;not null;index"`
+	EventID        uuid.UUID      `gorm:"type:uuid;not null;index"`
+	EventType      string         `gorm:"size:64;not null"`
+	Payload        datatypes.JSON `gorm:"type:jsonb;not null"`
+	Status         string         `gorm:"size:16;not null;default:'pending';index"`
+	Attempts       int            `gorm:"not null;default:0"`
+	NextAttemptAt  int64          `gorm:"not null;index"`
+	LastStatusCode int            `gorm:"not null;default:0"` // HTTP status of the last attempt, 0 when none came back
+	LastError      string         `gorm:"type:text"`
+	DeliveredAt    *int64
+	// Set while a worker sends the row; a claim that outlives its worker expires and the row is retried
+	ClaimedUntil *int64
+
+	Endpoint WebhookEndpoint `gorm:"foreignKey:EndpointID"`
+}
@@ -0,0 +1,22 @@
+package db_models
+
+const (
+	WebhookEventReceived  = "received"
+	WebhookEventProcessed = "processed"
+	WebhookEventIgnored   = "ignored" // no transaction of ours
+	WebhookEventFailed    = "failed"  // will be retried by the provider
+)
+
+// WebhookEvent is a payment provider webhook we received, one row per provider event: a
+// redelivered event finds its row processed and is acknowledged without being applied again.
+type WebhookEvent struct {
+	BaseModel
+	Provider      string `gorm:"size:16;not null;uniqueIndex:idx_webhook_events_provider_event"`
+	EventID       string `gorm:"size:128;not null;uniqueIndex:idx_webhook_events_provider_event"`
+	ProviderTxnID string `gorm:"index"`
+	Outcome       string `gorm:"size:16"` // paid or failed, as the event reported it
+	Status        string `gorm:"size:16;not null;index"`
+	Attempts      int    `gorm:"not null;default:0"`
+	LastError     string
+	ProcessedAt   *int64
+}
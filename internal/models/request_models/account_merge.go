@@ -0,0 +1,7 @@
+package request_models
+
+type MergeAccountsRequest struct {
+	SourceAccountID string `json:"source_account_id" binding:"required,uuid"` // duplicate account, removed after merge
+	TargetAccountID string `json:"target_account_id" binding:"required,uuid"` // surviving account
+	DryRun          bool   `json:"dry_run"`
+}
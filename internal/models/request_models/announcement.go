@@ -0,0 +1,32 @@
+package request_models
+
+// AnnouncementRequest creates an announcement or replaces one. Times are RFC3339; starts_at
+// defaults to now and ends_at to never.
+type AnnouncementRequest struct {
+	Kind          string   `json:"kind" binding:"required,oneof=info promo maintenance"`
+	Title         string   `json:"title" binding:"required,max=200"`
+	Body          string   `json:"body" binding:"max=5000"`
+	CTAText       string   `json:"cta_text" binding:"max=100"`
+	CTAURL        string   `json:"cta_url" binding:"omitempty,url,max=500"`
+	Dismissible   bool     `json:"dismissible"`
+	Priority      int      `json:"priority" binding:"min=0,max=100"`
+	Tiers         []string `json:"tiers" binding:"max=3,dive,oneof=guest free premium"`
+	Locales       []string `json:"locales" binding:"max=2,dive,oneof=vi en"`
+	MinAppVersion string   `json:"min_app_version" binding:"max=32"`
+	MaxAppVersion string   `json:"max_app_version" binding:"max=32"`
+	Active        bool     `json:"active"`
+	StartsAt      string   `json:"starts_at,omitempty"`
+	EndsAt        string   `json:"ends_at,omitempty"`
+}
+
+type AnnouncementListQuery struct {
+	Page     int `form:"page,default=1" binding:"min=1"`
+	PageSize int `form:"pageSize,default=20" binding:"min=1,max=100"`
+}
+
+// ActiveAnnouncementsQuery describes the client; the app version also comes from the
+// X-App-Version header and the locale from the account.
+type ActiveAnnouncementsQuery struct {
+	Locale     string `form:"locale" binding:"omitempty,oneof=vi en"`
+	AppVersion string `form:"app_version" binding:"max=32"`
+}
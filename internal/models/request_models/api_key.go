@@ -0,0 +1,20 @@
+package request_models
+
+// CreateAPIKeyRequest issues a partner key for an account.
+type CreateAPIKeyRequest struct {
+	AccountID     string   `json:"account_id" binding:"required,uuid"`
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,dive,oneof=pois:read provinces:read plans:generate planning_policy:write"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=3650"` // 0: never expires
+	DailyQuota    int      `json:"daily_quota" binding:"omitempty,min=1"`              // requests per day; 0: the default quota
+}
+
+type APIKeyQuery struct {
+	AccountID string `form:"account_id" binding:"omitempty,uuid"`
+	Page      int    `form:"page,default=1" binding:"min=1"`
+	PageSize  int    `form:"pageSize,default=20" binding:"min=1,max=100"`
+}
+
+type PartnerUsageQuery struct {
+	Days int `form:"days,default=30" binding:"min=1,max=90"`
+}
@@ -0,0 +1,23 @@
+package request_models
+
+type BillingPageQuery struct {
+	Page     int `form:"page,default=1" binding:"min=1"`
+	PageSize int `form:"pageSize,default=20" binding:"min=1,max=100"`
+}
+
+// TransactionHistoryQuery narrows the account's payments: status repeats for several
+// (?status=paid&status=refunded); from and to are inclusive dates in Vietnam time.
+type TransactionHistoryQuery struct {
+	BillingPageQuery
+	Status []string `form:"status" binding:"max=4,dive,oneof=pending paid failed refunded"`
+	From   string   `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To     string   `form:"to" binding:"omitempty,datetime=2006-01-02"`
+}
+
+type SetAutoRenewRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+type ChangePlanRequest struct {
+	PlanCode string `json:"plan_code" binding:"required"`
+}
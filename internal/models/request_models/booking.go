@@ -0,0 +1,16 @@
+package request_models
+
+type SetBookingLinkRequest struct {
+	Partner        string            `json:"partner" binding:"required,max=64"`
+	URL            string            `json:"url" binding:"required,url"`
+	DeepLinkParams map[string]string `json:"deep_link_params,omitempty"`
+}
+
+type BookActivityRequest struct {
+	Partner string `json:"partner,omitempty"` // defaults to the POI's first partner
+}
+
+type UpdateBookingStatusRequest struct {
+	Status    string `json:"status" binding:"required,oneof=pending confirmed cancelled"`
+	Reference string `json:"reference,omitempty" binding:"max=128"`
+}
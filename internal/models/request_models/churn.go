@@ -0,0 +1,22 @@
+package request_models
+
+type ChurnAccountQuery struct {
+	Segment  string `form:"segment" binding:"omitempty,oneof=low medium high"`
+	Page     int    `form:"page,default=1" binding:"min=1"`
+	PageSize int    `form:"pageSize,default=20" binding:"min=1,max=100"`
+}
+
+// SetWinbackCampaignRequest replaces the sequence of a segment; an active campaign needs at
+// least one step.
+type SetWinbackCampaignRequest struct {
+	Active bool                 `json:"active"`
+	Steps  []WinbackStepRequest `json:"steps" binding:"max=5,dive"`
+}
+
+type WinbackStepRequest struct {
+	DelayDays int    `json:"delay_days" binding:"min=0,max=90"`
+	Subject   string `json:"subject" binding:"required,max=200"`
+	Body      string `json:"body" binding:"required,max=5000"`
+	CTAText   string `json:"cta_text" binding:"max=100"`
+	CTAURL    string `json:"cta_url" binding:"omitempty,url,max=500"`
+}
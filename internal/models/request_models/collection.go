@@ -0,0 +1,31 @@
+package request_models
+
+type CollectionListQuery struct {
+	Page       int    `form:"page,default=1" binding:"min=1"`
+	PageSize   int    `form:"pageSize,default=20" binding:"min=1,max=100"`
+	ProvinceID string `form:"province_id" binding:"omitempty,uuid"`
+}
+
+// SaveCollectionRequest creates or replaces a collection; POIs are shown in the order given.
+type SaveCollectionRequest struct {
+	Slug          string                 `json:"slug" binding:"omitempty,max=120"` // made from the title when empty
+	Title         string                 `json:"title" binding:"required,max=200"`
+	Description   string                 `json:"description" binding:"max=2000"`
+	CoverImageURL string                 `json:"cover_image_url" binding:"omitempty,url,max=1000"`
+	ProvinceID    string                 `json:"province_id" binding:"omitempty,uuid"`
+	IsPublished   bool                   `json:"is_published"`
+	POIs          []CollectionPOIRequest `json:"pois" binding:"max=50,dive"`
+}
+
+type CollectionPOIRequest struct {
+	POIID           string `json:"poi_id" binding:"required,uuid"`
+	Note            string `json:"note" binding:"max=300"`
+	DurationMinutes int    `json:"duration_minutes" binding:"min=0,max=600"` // 0 leaves it to the planner
+}
+
+type SeedDayFromCollectionRequest struct {
+	DayNumber  int    `json:"day_number" binding:"required,min=1"`
+	Collection string `json:"collection" binding:"required"` // ID or slug
+	// A day with activities is only overwritten when this is set
+	Replace bool `json:"replace"`
+}
@@ -0,0 +1,8 @@
+package request_models
+
+type SetDestinationRuleRequest struct {
+	MaxActivitiesPerDay int      `json:"max_activities_per_day" binding:"min=0,max=12"`
+	ForbiddenWindows    []string `json:"forbidden_windows"` // e.g. ["18:00-06:00"]
+	BufferMinutes       int      `json:"buffer_minutes" binding:"min=0,max=240"`
+	Note                string   `json:"note" binding:"max=500"`
+}
@@ -0,0 +1,14 @@
+package request_models
+
+type SetBudgetRequest struct {
+	Amount int64 `json:"amount" binding:"required,min=1"` // VND
+}
+
+type CreateExpenseRequest struct {
+	Amount     int64  `json:"amount" binding:"required,min=1"` // VND
+	Category   string `json:"category" binding:"required,oneof=food transport lodging activities shopping other"`
+	Merchant   string `json:"merchant,omitempty" binding:"max=128"`
+	Note       string `json:"note,omitempty" binding:"max=500"`
+	SpentAt    string `json:"spent_at,omitempty"` // RFC3339, defaults to now
+	ActivityID string `json:"activity_id,omitempty" binding:"omitempty,uuid"`
+}
@@ -0,0 +1,7 @@
+package request_models
+
+type CreateCommentRequest struct {
+	Body       string `json:"body" binding:"required,max=2000"`
+	ActivityID string `json:"activity_id,omitempty" binding:"omitempty,uuid"`
+	ParentID   string `json:"parent_id,omitempty" binding:"omitempty,uuid"` // reply to a comment
+}
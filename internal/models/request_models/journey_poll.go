@@ -0,0 +1,17 @@
+package request_models
+
+type AddJourneyMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+type CreatePollRequest struct {
+	ActivityID string   `json:"activity_id" binding:"required,uuid"`
+	POIIDs     []string `json:"poi_ids" binding:"required,min=2,max=3,dive,uuid"`
+	Question   string   `json:"question,omitempty" binding:"max=255"`
+	Deadline   string   `json:"deadline" binding:"required"` // RFC3339, before the activity starts
+}
+
+type VotePollRequest struct {
+	OptionID string `json:"option_id" binding:"required,uuid"`
+}
@@ -31,3 +31,38 @@ type UpdateJourneyWindowRequest struct {
 	Start string `json:"start" binding:"required"`
 	End   string `json:"end" binding:"required"`
 }
+
+type SetDayAccommodationRequest struct {
+	DayNumber int    `json:"day_number" binding:"required,min=1"`
+	POIID     string `json:"poi_id"` // empty clears the accommodation
+}
+
+type CheckInActivityRequest struct {
+	ActivityID string `json:"activity_id" binding:"required,uuid"`
+	Notes      string `json:"notes" binding:"max=500"`
+	Stars      int    `json:"stars" binding:"omitempty,min=1,max=5"`
+}
+
+type SetTripRemindersRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+type RainySwapRequest struct {
+	DayNumber int `json:"day_number" binding:"required,min=1"`
+}
+
+type RegenerateDayRequest struct {
+	DayNumber int `json:"day_number" binding:"required,min=1"`
+}
+
+type OptimizeDayRequest struct {
+	DayNumber int    `json:"day_number" binding:"required,min=1"`
+	Mode      string `json:"mode,omitempty"` // driving (default) | walking | cycling
+}
+
+// ImportJourneyRequest holds the form fields sent along with the import file.
+type ImportJourneyRequest struct {
+	Title      string `form:"title"`
+	StartDate  string `form:"start_date"`  // YYYY-MM-DD, overrides dates in the file
+	ProvinceID string `form:"province_id"` // province for POIs created from unknown places
+}
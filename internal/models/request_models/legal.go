@@ -0,0 +1,28 @@
+package request_models
+
+// PublishLegalDocumentRequest publishes a new version of the terms of service or the privacy
+// policy. A minor version (a typo fix) is shown to new accounts without asking accounts
+// that accepted an earlier version again.
+type PublishLegalDocumentRequest struct {
+	Kind    string `json:"kind" binding:"required,oneof=terms privacy"`
+	Version string `json:"version" binding:"required,max=32"`
+	Title   string `json:"title" binding:"required,max=200"`
+	URL     string `json:"url" binding:"omitempty,url,max=500"`
+	Body    string `json:"body" binding:"max=100000"`
+	Minor   bool   `json:"minor"`
+}
+
+// AcceptLegalDocumentsRequest accepts the current versions; each entry must name the version
+// the account was shown, so a document published meanwhile is not accepted unread.
+type AcceptLegalDocumentsRequest struct {
+	Documents []AcceptedLegalDocument `json:"documents" binding:"required,min=1,max=2,dive"`
+}
+
+type AcceptedLegalDocument struct {
+	Kind    string `json:"kind" binding:"required,oneof=terms privacy"`
+	Version string `json:"version" binding:"required,max=32"`
+}
+
+type LegalDocumentListQuery struct {
+	Kind string `form:"kind" binding:"omitempty,oneof=terms privacy"`
+}
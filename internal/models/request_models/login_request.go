@@ -3,12 +3,24 @@ package request_models
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
+
+	ClientIP string `json:"-"` // set by the controller, failed attempts are also counted per IP
+}
+
+// UnlockLoginRequest redeems the link emailed when an account is locked after failed logins.
+type UnlockLoginRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Token string `json:"token" binding:"required"`
 }
 
 type SignUpRequest struct {
 	DisplayName string `json:"display_name" binding:"required,min=3,max=50"`
 	Email       string `json:"email" binding:"required,email"`
 	Password    string `json:"password" binding:"required,min=6"`
+	// Language of the emails, vi or en; English when empty. Editable later from the profile.
+	Locale string `json:"locale" binding:"omitempty,oneof=vi en"`
+	// Optional; plans of accounts under 18 leave out bars and nightlife.
+	BirthYear *int `json:"birth_year" binding:"omitempty,min=1900"`
 }
 
 type ForgotPasswordRequest struct {
@@ -25,3 +37,69 @@ type RequestVerifyOtpToken struct {
 	Email string `json:"email" binding:"required,email"`
 	Token string `json:"token" binding:"required"`
 }
+
+// UpdateWorkingWindowRequest sets the traveler's day window; send both empty to go back to the default.
+type UpdateWorkingWindowRequest struct {
+	DayStart string `json:"day_start"`
+	DayEnd   string `json:"day_end"`
+}
+
+// UpdateCompanionsRequest replaces the saved companions; an empty list clears them.
+type UpdateCompanionsRequest struct {
+	Companions []CompanionInput `json:"companions" binding:"max=12,dive"`
+}
+
+// UpdateTripEmailsRequest turns the pre-trip emails of every journey of the account on or off.
+type UpdateTripEmailsRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// UpdateAvoidRequest replaces the places the traveler never wants in a plan; an empty list clears them.
+type UpdateAvoidRequest struct {
+	Avoid []string `json:"avoid" binding:"max=20,dive,required,max=40"`
+}
+
+// UpdateProfileRequest replaces the editable profile; empty optional fields reset to the app default.
+type UpdateProfileRequest struct {
+	Name              string `json:"name" binding:"required,min=3,max=50"`
+	AvatarURL         string `json:"avatar_url" binding:"omitempty,url,max=500"`
+	Locale            string `json:"locale" binding:"omitempty,oneof=vi en"`
+	PreferredCurrency string `json:"preferred_currency" binding:"omitempty,iso4217"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+type CompanionInput struct {
+	Name     string `json:"name" binding:"max=60"`
+	Relation string `json:"relation" binding:"required,oneof=partner child parent friend other"`
+	Age      *int   `json:"age" binding:"omitempty,min=0,max=120"`
+	Notes    string `json:"notes" binding:"max=200"`
+}
+
+type TwoFactorVerifyRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorChallengeRequest completes a login of an account with 2FA: the challenge token from
+// /accounts/login and a code from the authenticator app or a backup code.
+type TwoFactorChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// AdminAccountQuery filters the admin account list.
+type AdminAccountQuery struct {
+	Role         string `form:"role" binding:"omitempty,oneof=user admin"`
+	Status       string `form:"status" binding:"omitempty,oneof=active banned"`
+	Subscription string `form:"subscription" binding:"omitempty,oneof=active none"`
+	Search       string `form:"q" binding:"max=100"`
+	Page         int    `form:"page,default=1" binding:"min=1"`
+	PageSize     int    `form:"pageSize,default=20" binding:"min=1,max=100"`
+}
+
+type BanAccountRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
@@ -0,0 +1,8 @@
+package request_models
+
+type MailOutboxQuery struct {
+	Status    string `form:"status" binding:"omitempty,oneof=pending sent dead"`
+	Recipient string `form:"recipient" binding:"omitempty,max=320"`
+	Page      int    `form:"page,default=1" binding:"min=1"`
+	PageSize  int    `form:"pageSize,default=20" binding:"min=1,max=100"`
+}
@@ -0,0 +1,7 @@
+package request_models
+
+type NotificationQuery struct {
+	Unread   bool `form:"unread"` // only unread notifications
+	Page     int  `form:"page,default=1" binding:"min=1"`
+	PageSize int  `form:"pageSize,default=20" binding:"min=1,max=100"`
+}
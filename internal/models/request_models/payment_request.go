@@ -1,5 +1,29 @@
 package request_models
 
 type CreatePaymentRequest struct {
-	PlanCode string `json:"plan_code" binding:"required"`
+	PlanCode   string `json:"plan_code" binding:"required"`
+	CouponCode string `json:"coupon_code" binding:"omitempty,max=32"`
+	// payos or stripe; the default provider for the plan's currency when empty
+	Provider string `json:"provider" binding:"omitempty,oneof=payos stripe"`
+}
+
+// CreateCouponRequest takes either percent_off or amount_off_minor with its currency.
+type CreateCouponRequest struct {
+	Code           string   `json:"code" binding:"required,max=32"` // letters, digits, - and _
+	PercentOff     int      `json:"percent_off" binding:"omitempty,min=1,max=100"`
+	AmountOffMinor int64    `json:"amount_off_minor" binding:"omitempty,min=1"`
+	Currency       string   `json:"currency" binding:"omitempty,len=3"`
+	MaxRedemptions *int     `json:"max_redemptions" binding:"omitempty,min=1"`
+	ExpiresAt      string   `json:"expires_at,omitempty"` // RFC3339
+	PlanCodes      []string `json:"plan_codes"`           // empty: every plan
+}
+
+type CouponQuery struct {
+	PlanCode string `form:"plan_code"`
+}
+
+// RefundTransactionRequest refunds the whole payment unless amount_minor asks for less.
+type RefundTransactionRequest struct {
+	AmountMinor int64  `json:"amount_minor" binding:"omitempty,min=1"`
+	Reason      string `json:"reason" binding:"omitempty,max=500"`
 }
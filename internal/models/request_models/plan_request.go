@@ -0,0 +1,40 @@
+package request_models
+
+// Entitlements maps entitlement keys to values: unlimited_plans (bool), max_trip_days (int), ai_profile (string).
+
+type CreatePlanRequest struct {
+	Code            string         `json:"code" binding:"required,max=64"` // lowercase letters, digits and _
+	Name            string         `json:"name" binding:"required,max=100"`
+	Description     *string        `json:"description" binding:"omitempty,max=1000"`
+	BackgroundImage string         `json:"background_image" binding:"omitempty,url"`
+	Period          string         `json:"period" binding:"required,oneof=month year"`
+	PriceMinor      int64          `json:"price_minor" binding:"required,min=1"`
+	Currency        string         `json:"currency" binding:"required,len=3"`
+	TrialDays       int32          `json:"trial_days" binding:"min=0,max=365"`
+	IsActive        *bool          `json:"is_active"` // default true
+	Entitlements    map[string]any `json:"entitlements"`
+}
+
+// UpdatePlanRequest replaces the plan. Price, currency and period can only change while no
+// subscription is running on the plan.
+type UpdatePlanRequest struct {
+	Name            string         `json:"name" binding:"required,max=100"`
+	Description     *string        `json:"description" binding:"omitempty,max=1000"`
+	BackgroundImage string         `json:"background_image" binding:"omitempty,url"`
+	Period          string         `json:"period" binding:"required,oneof=month year"`
+	PriceMinor      int64          `json:"price_minor" binding:"required,min=1"`
+	Currency        string         `json:"currency" binding:"required,len=3"`
+	TrialDays       int32          `json:"trial_days" binding:"min=0,max=365"`
+	IsActive        bool           `json:"is_active"`
+	Entitlements    map[string]any `json:"entitlements"`
+}
+
+// CreatePlanVersionRequest copies a plan with the given changes; the copy replaces the plan
+// for new checkouts while running subscriptions keep the old one.
+type CreatePlanVersionRequest struct {
+	Code       string  `json:"code" binding:"omitempty,max=64"` // default: the code with _v<version>
+	PriceMinor *int64  `json:"price_minor" binding:"omitempty,min=1"`
+	Currency   *string `json:"currency" binding:"omitempty,len=3"`
+	Period     *string `json:"period" binding:"omitempty,oneof=month year"`
+	TrialDays  *int32  `json:"trial_days" binding:"omitempty,min=0,max=365"`
+}
@@ -0,0 +1,8 @@
+package request_models
+
+// SetPlanningPolicyRequest replaces the planning policy of the caller's account.
+type SetPlanningPolicyRequest struct {
+	Exclude       []string `json:"exclude" binding:"max=50,dive,max=100"`      // e.g. ["Nightlife & bars", "karaoke"]
+	BoostPOIIDs   []string `json:"boost_poi_ids" binding:"max=50,dive,uuid"`   // favoured when in the destination
+	RequirePOIIDs []string `json:"require_poi_ids" binding:"max=10,dive,uuid"` // always scheduled when in the destination
+}
@@ -0,0 +1,7 @@
+package request_models
+
+type SetPOIExternalRefRequest struct {
+	Source     string `json:"source" binding:"required,oneof=google tripadvisor"`
+	ExternalID string `json:"external_id" binding:"required"` // Google place ID or Tripadvisor location ID
+	URL        string `json:"url,omitempty"`
+}
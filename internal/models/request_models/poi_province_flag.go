@@ -0,0 +1,10 @@
+package request_models
+
+// POIProvinceFlagQuery filters the province correction queue.
+type POIProvinceFlagQuery struct {
+	Status     string `form:"status,default=open" binding:"omitempty,oneof=open applied dismissed cleared"`
+	Kind       string `form:"kind" binding:"omitempty,oneof=wrong_province swapped_coords outside"`
+	ProvinceID string `form:"province_id" binding:"omitempty,uuid"`
+	Page       int    `form:"page,default=1" binding:"min=1"`
+	PageSize   int    `form:"pageSize,default=20" binding:"min=1,max=100"`
+}
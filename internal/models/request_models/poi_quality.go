@@ -0,0 +1,21 @@
+package request_models
+
+// POIQualityQuery filters the POI quality report and its export.
+type POIQualityQuery struct {
+	ProvinceID string `form:"province_id" binding:"omitempty,uuid"`
+	CategoryID string `form:"category_id" binding:"omitempty,uuid"`
+	Status     string `form:"status"`
+	MinScore   *int   `form:"min_score" binding:"omitempty,min=0,max=100"`
+	MaxScore   *int   `form:"max_score" binding:"omitempty,min=0,max=100"`
+	// Only POIs with this gap: coords, hours, images, description, embedding or reviews
+	Missing  string `form:"missing" binding:"omitempty,oneof=coords hours images description embedding reviews"`
+	Page     int    `form:"page,default=1" binding:"min=1"`
+	PageSize int    `form:"pageSize,default=20" binding:"min=1,max=100"`
+}
+
+// POIQualityExportQuery exports the POIs needing attention; without max_score that is the ones
+// below the attention threshold.
+type POIQualityExportQuery struct {
+	POIQualityQuery
+	Format string `form:"format" binding:"omitempty,oneof=xlsx csv"`
+}
@@ -37,3 +37,11 @@ type UpdatePoiRequest struct {
 type DeletePoiRequest struct {
 	ID uuid.UUID `json:"id" binding:"required,uuid4"`
 }
+
+// POIExportQuery is the query string of GET /pois/export.
+type POIExportQuery struct {
+	Format       string `form:"format"`        // xlsx (default), csv or json
+	Province     string `form:"province"`      // province ID or name
+	Category     string `form:"category"`      // category ID or name
+	UpdatedSince string `form:"updated_since"` // RFC3339, YYYY-MM-DD (VN) or unix seconds
+}
@@ -0,0 +1,8 @@
+package request_models
+
+type SetPracticalInfoRequest struct {
+	Connectivity []string `json:"connectivity" binding:"max=10,dive,max=300"` // e.g. ["Viettel tourist SIM at the airport, ~200,000 VND for 30 days"]
+	Transport    []string `json:"transport" binding:"max=10,dive,max=300"`
+	Tipping      string   `json:"tipping" binding:"max=500"`
+	Other        []string `json:"other" binding:"max=10,dive,max=300"`
+}
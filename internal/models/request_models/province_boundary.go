@@ -0,0 +1,10 @@
+package request_models
+
+import "encoding/json"
+
+// SetProvinceBoundaryRequest uploads a province outline: a GeoJSON Polygon or MultiPolygon,
+// or a Feature / FeatureCollection of them, with [lng, lat] positions.
+type SetProvinceBoundaryRequest struct {
+	Geometry json.RawMessage `json:"geometry" binding:"required"`
+	Source   string          `json:"source" binding:"max=255"`
+}
@@ -0,0 +1,8 @@
+package request_models
+
+type SetProvinceGuideRequest struct {
+	CoverImageURL string   `json:"cover_image_url" binding:"omitempty,url,max=1000"`
+	Intro         string   `json:"intro" binding:"max=800"`
+	HighlightPOIs []string `json:"highlight_poi_ids" binding:"max=12,dive,uuid"` // in display order
+	TripDays      []int    `json:"trip_days" binding:"max=5,dive,min=1,max=30"`  // e.g. [2, 3, 5]
+}
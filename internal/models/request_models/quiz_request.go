@@ -19,9 +19,15 @@ type QuizQuestion struct {
 }
 
 type QuizStartRequest struct {
-	UserID string `json:"user_id"`
+	UserID   string `json:"user_id"`
+	PresetID string `json:"preset_id,omitempty"` // a travel preset of the user, fills the answers it covers
+}
+
+type QuizHeartbeatRequest struct {
+	SessionID string `json:"session_id"`
 }
 
 type PlanOnlyRequest struct {
 	SessionID string `json:"session_id"`
+	Mode      string `json:"mode,omitempty"` // driving (default) | walking | cycling
 }
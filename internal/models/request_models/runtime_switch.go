@@ -0,0 +1,6 @@
+package request_models
+
+type SetRuntimeSwitchRequest struct {
+	Enabled *bool  `json:"enabled" binding:"required"`
+	Message string `json:"message,omitempty"` // optional custom message shown while engaged
+}
@@ -10,3 +10,17 @@ type CreateTagRequest struct {
 	En   string `json:"en" binding:"required"`
 	Icon string `json:"icon" binding:"required"`
 }
+
+// BulkAssignTagRequest tags the listed POIs, or those matching Filter when PoiIDs is empty.
+type BulkAssignTagRequest struct {
+	TagID  string        `json:"tag_id" binding:"required,uuid"`
+	PoiIDs []string      `json:"poi_ids" binding:"max=1000,dive,uuid"`
+	Filter *POITagFilter `json:"filter"`
+}
+
+// POITagFilter picks POIs by province, category and name; at least one has to be set.
+type POITagFilter struct {
+	ProvinceID string `json:"province_id" binding:"omitempty,uuid"`
+	CategoryID string `json:"category_id" binding:"omitempty,uuid"`
+	Name       string `json:"name" binding:"omitempty,max=100"`
+}
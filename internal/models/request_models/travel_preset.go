@@ -0,0 +1,11 @@
+package request_models
+
+// TravelPresetRequest creates or replaces a travel style preset. Empty fields are left to the
+// quiz and the plan as usual.
+type TravelPresetRequest struct {
+	Name      string   `json:"name" binding:"required,max=60"`
+	Pace      string   `json:"pace,omitempty" binding:"omitempty,oneof=relaxed balanced packed"`
+	Budget    string   `json:"budget,omitempty"` // one of the quiz budget options, e.g. "$31-70"
+	Interests []string `json:"interests,omitempty" binding:"max=20,dive,required,max=40"`
+	Dietary   []string `json:"dietary,omitempty" binding:"max=10,dive,required,max=40"` // e.g. vegetarian, halal, no seafood
+}
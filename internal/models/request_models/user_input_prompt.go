@@ -19,6 +19,8 @@ type POISummary struct {
 	Name,
 	Category string
 	Description string
+	// Day of the geographic cluster the POI belongs to; 0 when not clustered
+	SuggestedDay int
 }
 
 type AddFeedbackRequest struct {
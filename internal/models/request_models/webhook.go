@@ -0,0 +1,22 @@
+package request_models
+
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required,url,max=2000"`
+	Description string   `json:"description" binding:"max=255"`
+	Events      []string `json:"events" binding:"omitempty,dive,oneof=plan.generated journey.updated subscription.activated subscription.expiring payment.failed"` // empty: every event
+	AllAccounts bool     `json:"all_accounts"`                                                                                                                     // admins only: receive the events of every account
+}
+
+type UpdateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required,url,max=2000"`
+	Description string   `json:"description" binding:"max=255"`
+	Events      []string `json:"events" binding:"omitempty,dive,oneof=plan.generated journey.updated subscription.activated subscription.expiring payment.failed"`
+	Active      bool     `json:"active"`
+}
+
+type WebhookDeliveryQuery struct {
+	Status   string `form:"status" binding:"omitempty,oneof=pending delivered failed"`
+	Event    string `form:"event" binding:"omitempty,oneof=plan.generated journey.updated subscription.activated subscription.expiring payment.failed"`
+	Page     int    `form:"page,default=1" binding:"min=1"`
+	PageSize int    `form:"pageSize,default=20" binding:"min=1,max=100"`
+}
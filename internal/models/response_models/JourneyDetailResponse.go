@@ -20,6 +20,44 @@ type JourneyDetailResponse struct {
 
 	// Plan details
 	Days []JourneyDayResponse `json:"days"`
+
+	// Check-in progress; nil when it could not be computed
+	Progress *JourneyProgress `json:"progress,omitempty"`
+	// Diversity of the generated plan, as scored when it was created
+	Diversity *PlanDiversity `json:"diversity,omitempty"`
+	// Comment threads, only with include_comments=true
+	Comments []JourneyComment `json:"comments,omitempty"`
+}
+
+// JourneyProgress is how well the traveler follows the plan, from check-ins and the clock.
+type JourneyProgress struct {
+	TotalActivities int `json:"total_activities"`
+	Completed       int `json:"completed"` // checked in
+	OnTime          int `json:"on_time"`   // checked in no later than 30 minutes after the start
+	Missed          int `json:"missed"`    // over without a check-in
+	Remaining       int `json:"remaining"`
+	Percent         int `json:"percent"`         // completed / total
+	OnTimePercent   int `json:"on_time_percent"` // on time / completed
+	CurrentStreak   int `json:"current_streak"`  // check-ins in a row, reset by a missed activity
+	BestStreak      int `json:"best_streak"`
+}
+
+// JourneyTodayResponse is the day-of view: today's day, the next stop and the progress so far.
+type JourneyTodayResponse struct {
+	JourneyID uuid.UUID              `json:"journey_id"`
+	Date      string                 `json:"date"` // today, YYYY-MM-DD (VN)
+	Day       *JourneyDayResponse    `json:"day,omitempty"`
+	Next      *JourneyActivityDetail `json:"next,omitempty"`
+	Progress  JourneyProgress        `json:"progress"`
+	// Budget envelope; nil when the trip has no budget
+	Budget *BudgetSummary `json:"budget,omitempty"`
+}
+
+type JourneyCheckInResponse struct {
+	CheckInID  uuid.UUID       `json:"check_in_id"`
+	ActivityID uuid.UUID       `json:"activity_id"`
+	OnTime     bool            `json:"on_time"`
+	Progress   JourneyProgress `json:"progress"`
 }
 
 // One day in the journey
@@ -28,6 +66,22 @@ type JourneyDayResponse struct {
 	DayNumber  int                     `json:"day_number"`
 	Date       string                  `json:"date"` // RFC3339 date
 	Activities []JourneyActivityDetail `json:"activities"`
+
+	Accommodation *POISummary `json:"accommodation,omitempty"`
+	ReturnLeg     *ReturnLeg  `json:"return_leg,omitempty"`
+	// Indoor alternative; POST /journeys/{id}/rainy-swap swaps it with Activities
+	RainyDay []JourneyActivityDetail `json:"rainy_day,omitempty"`
+}
+
+// ReturnLeg is the trip from the last stop of a day back to that night's accommodation.
+type ReturnLeg struct {
+	FromPOIID       uuid.UUID `json:"from_poi_id"`
+	DistanceMeters  int       `json:"distance_meters"`
+	DurationSeconds int       `json:"duration_seconds"`
+	DepartAt        string    `json:"depart_at,omitempty"` // RFC3339, end of the last activity
+	ArriveAt        string    `json:"arrive_at,omitempty"`
+	MapURL          string    `json:"map_url"`
+	Estimated       bool      `json:"estimated"` // straight-line estimate, routing was unavailable
 }
 
 // Activity inside a day
@@ -38,6 +92,26 @@ type JourneyActivityDetail struct {
 	ActivityType string      `json:"activity_type"`
 	Notes        string      `json:"notes,omitempty"`
 	SelectedPOI  *POISummary `json:"selected_poi,omitempty"`
+	Status       string      `json:"status,omitempty"` // done | missed | upcoming
+
+	BookingStatus    string `json:"booking_status,omitempty"` // pending | confirmed | cancelled
+	BookingPartner   string `json:"booking_partner,omitempty"`
+	BookingReference string `json:"booking_reference,omitempty"`
+
+	// Travel from the previous stop of the day; omitted until computed or once the day changed
+	Leg *TravelLeg `json:"leg,omitempty"`
+}
+
+// TravelLeg is a leg between two stops as stored by the background leg worker, so reading a
+// journey never waits on the routing provider.
+type TravelLeg struct {
+	FromPOIID       uuid.UUID `json:"from_poi_id"`
+	ToPOIID         uuid.UUID `json:"to_poi_id"`
+	Mode            string    `json:"mode"`
+	DistanceMeters  int       `json:"distance_meters"`
+	DurationSeconds int       `json:"duration_seconds"`
+	Estimated       bool      `json:"estimated"`   // straight-line estimate, routing was unavailable
+	ComputedAt      int64     `json:"computed_at"` // unix seconds
 }
 
 // Minimal POI info that's useful on UI
@@ -49,3 +123,30 @@ type POISummary struct {
 	Longitude float64   `json:"longitude,omitempty"`
 	Status    string    `json:"status,omitempty"`
 }
+
+// Result of reordering the activities of one day
+type OptimizeDayResponse struct {
+	JourneyID    uuid.UUID               `json:"journey_id"`
+	DayID        uuid.UUID               `json:"day_id"`
+	DayNumber    int                     `json:"day_number"`
+	Changed      bool                    `json:"changed"`
+	BeforeMeters int                     `json:"before_meters"`
+	AfterMeters  int                     `json:"after_meters"`
+	Activities   []JourneyActivityDetail `json:"activities"`
+}
+
+// JourneyShareLink opens the journey's print view without logging in, until it is revoked.
+type JourneyShareLink struct {
+	JourneyID uuid.UUID `json:"journey_id"`
+	Token     string    `json:"token"`
+	PrintPath string    `json:"print_path"` // relative to the API
+}
+
+// JourneyShareText is the itinerary as a message for chat apps (Zalo, WhatsApp).
+type JourneyShareText struct {
+	JourneyID uuid.UUID `json:"journey_id"`
+	Locale    string    `json:"locale"` // vi | en
+	Text      string    `json:"text"`
+	// Later days were left out to keep the message short; the text ends with a link to the trip
+	Truncated bool `json:"truncated"`
+}
@@ -0,0 +1,38 @@
+package response_models
+
+type AccountMergeCounts struct {
+	Journeys      int64 `json:"journeys"`
+	CheckIns      int64 `json:"check_ins"`
+	Subscriptions int64 `json:"subscriptions"`
+	Transactions  int64 `json:"transactions"`
+	Feedback      int64 `json:"feedback"`
+
+	JourneyMemberships int64 `json:"journey_memberships"`
+	Polls              int64 `json:"polls"`
+	PollVotes          int64 `json:"poll_votes"`
+	Comments           int64 `json:"comments"`
+	TravelDocuments    int64 `json:"travel_documents"`
+	PlanUsages         int64 `json:"plan_usages"`
+	Expenses           int64 `json:"expenses"`
+	TravelPresets      int64 `json:"travel_presets"`
+	BookingEvents      int64 `json:"booking_events"`
+	APIKeys            int64 `json:"api_keys"`
+	PlanningPolicies   int64 `json:"planning_policies"`
+	WebhookEndpoints   int64 `json:"webhook_endpoints"`
+	PlanChanges        int64 `json:"plan_changes"`
+	Notifications      int64 `json:"notifications"`
+	BackupJobs         int64 `json:"backup_jobs"`
+	ChurnScores        int64 `json:"churn_scores"`
+	LegalAcceptances   int64 `json:"legal_acceptances"`
+}
+
+type AccountMergeReport struct {
+	MergeID         string             `json:"merge_id"`
+	DryRun          bool               `json:"dry_run"`
+	SourceAccountID string             `json:"source_account_id"`
+	SourceEmail     string             `json:"source_email"`
+	TargetAccountID string             `json:"target_account_id"`
+	TargetEmail     string             `json:"target_email"`
+	Moved           AccountMergeCounts `json:"moved"` // on dry run: what would move
+	Warnings        []string           `json:"warnings,omitempty"`
+}
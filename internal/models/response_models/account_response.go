@@ -5,6 +5,17 @@ import "gorm.io/datatypes"
 type AccountLoginResponse struct {
 	Token             string `json:"token"`
 	IsUserHavePremium bool   `json:"is_user_have_premium"`
+	// Set instead of Token when the account has 2FA on; see /accounts/2fa/challenge
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+}
+
+// TwoFactorSetupResponse is shown once: the secret cannot be read back and backup codes are
+// stored hashed.
+type TwoFactorSetupResponse struct {
+	ProvisioningURI string   `json:"provisioning_uri"` // otpauth:// URI for the QR code
+	Secret          string   `json:"secret"`           // for manual entry
+	BackupCodes     []string `json:"backup_codes"`
 }
 
 type AccountResponse struct {
@@ -12,5 +23,43 @@ type AccountResponse struct {
 	Name                 string         `json:"name"`
 	Email                string         `json:"email"`
 	Role                 string         `json:"role"`
+	AvatarURL            string         `json:"avatar_url,omitempty"`
+	Locale               string         `json:"locale,omitempty"`
+	PreferredCurrency    string         `json:"preferred_currency,omitempty"`
+	BirthYear            *int           `json:"birth_year,omitempty"`
+	Minor                bool           `json:"minor"` // plans leave out adult-only places
 	SubscriptionSnapshot datatypes.JSON `json:"subscription_snapshot"`
+	DayStart             string         `json:"day_start,omitempty"`
+	DayEnd               string         `json:"day_end,omitempty"`
+	Companions           []Companion    `json:"companions"`
+	Avoid                []string       `json:"avoid"`
+	TripEmails           bool           `json:"trip_emails"`
+}
+
+type Companion struct {
+	Name     string `json:"name,omitempty"`
+	Relation string `json:"relation"`
+	Age      *int   `json:"age,omitempty"`
+	Notes    string `json:"notes,omitempty"`
+}
+
+// AdminAccountResponse is an account as listed to admins.
+type AdminAccountResponse struct {
+	ID                    string `json:"id"`
+	Name                  string `json:"name"`
+	Email                 string `json:"email"`
+	Role                  string `json:"role"`
+	Status                string `json:"status"`
+	BannedAt              string `json:"banned_at,omitempty"`
+	BanReason             string `json:"ban_reason,omitempty"`
+	HasActiveSubscription bool   `json:"has_active_subscription"`
+	TwoFactorEnabled      bool   `json:"two_factor_enabled"`
+	CreatedAt             string `json:"created_at"`
+}
+
+type AdminAccountPage struct {
+	Items    []AdminAccountResponse `json:"items"`
+	Total    int64                  `json:"total"`
+	Page     int                    `json:"page"`
+	PageSize int                    `json:"page_size"`
 }
@@ -0,0 +1,35 @@
+package response_models
+
+// Announcement is a banner as the apps show it.
+type Announcement struct {
+	ID          string `json:"id"`
+	Kind        string `json:"kind"` // info | promo | maintenance
+	Title       string `json:"title"`
+	Body        string `json:"body,omitempty"`
+	CTAText     string `json:"cta_text,omitempty"`
+	CTAURL      string `json:"cta_url,omitempty"`
+	Dismissible bool   `json:"dismissible"`
+	Priority    int    `json:"priority"`
+	StartsAt    string `json:"starts_at"`
+	EndsAt      string `json:"ends_at,omitempty"`
+}
+
+type AdminAnnouncement struct {
+	Announcement
+	Tiers         []string `json:"tiers"`
+	Locales       []string `json:"locales"`
+	MinAppVersion string   `json:"min_app_version,omitempty"`
+	MaxAppVersion string   `json:"max_app_version,omitempty"`
+	Active        bool     `json:"active"`
+	Status        string   `json:"status"` // draft | scheduled | live | ended
+	CreatedBy     string   `json:"created_by,omitempty"`
+	UpdatedBy     string   `json:"updated_by,omitempty"`
+	UpdatedAt     string   `json:"updated_at"`
+}
+
+type AdminAnnouncementPage struct {
+	Items    []AdminAnnouncement `json:"items"`
+	Total    int64               `json:"total"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
+}
@@ -0,0 +1,60 @@
+package response_models
+
+type APIKeyResponse struct {
+	ID           string   `json:"id"`
+	AccountID    string   `json:"account_id"`
+	AccountEmail string   `json:"account_email,omitempty"`
+	Name         string   `json:"name"`
+	Prefix       string   `json:"prefix"` // start of the key, to tell keys apart
+	Scopes       []string `json:"scopes"`
+	DailyQuota   int      `json:"daily_quota,omitempty"` // 0: the default quota
+	Status       string   `json:"status"`                // active | expired | revoked
+	ExpiresAt    string   `json:"expires_at,omitempty"`
+	RevokedAt    string   `json:"revoked_at,omitempty"`
+	LastUsedAt   string   `json:"last_used_at,omitempty"`
+	CreatedAt    string   `json:"created_at"`
+}
+
+// IssuedAPIKey carries the secret; it is only ever shown in this response.
+type IssuedAPIKey struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+type APIKeyPage struct {
+	Items    []APIKeyResponse `json:"items"`
+	Total    int64            `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+}
+
+// PartnerUsage covers the API keys of an account from From to To (VN days, inclusive).
+type PartnerUsage struct {
+	From string        `json:"from"`
+	To   string        `json:"to"`
+	Keys []APIKeyUsage `json:"keys"`
+}
+
+type APIKeyUsage struct {
+	KeyID          string           `json:"key_id"`
+	Name           string           `json:"name"`
+	Prefix         string           `json:"prefix"`
+	Status         string           `json:"status"`
+	DailyQuota     int              `json:"daily_quota"` // 0: unlimited
+	UsedToday      int64            `json:"used_today"`
+	RemainingToday *int64           `json:"remaining_today,omitempty"` // absent when unlimited
+	ResetsAt       string           `json:"resets_at"`
+	Requests       int64            `json:"requests"`
+	Errors         int64            `json:"errors"`
+	Throttled      int64            `json:"throttled"`  // refused for the quota, not in requests
+	ErrorRate      float64          `json:"error_rate"` // errors / requests
+	Days           []APIKeyUsageDay `json:"days"`
+}
+
+type APIKeyUsageDay struct {
+	Date      string  `json:"date"`
+	Requests  int64   `json:"requests"`
+	Errors    int64   `json:"errors"`
+	Throttled int64   `json:"throttled"`
+	ErrorRate float64 `json:"error_rate"`
+}
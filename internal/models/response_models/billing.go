@@ -0,0 +1,112 @@
+package response_models
+
+// BillingOverview is everything the billing screen shows at once.
+type BillingOverview struct {
+	Subscription    *BillingSubscription `json:"subscription"` // null without a running subscription
+	UpcomingRenewal *BillingRenewal      `json:"upcoming_renewal,omitempty"`
+	RecentPayments  []BillingPayment     `json:"recent_payments"`
+	PlanOptions     []BillingPlanOption  `json:"plan_options"`
+	Actions         BillingActions       `json:"actions"`
+}
+
+type BillingSubscription struct {
+	ID         string `json:"id"`
+	PlanCode   string `json:"plan_code"`
+	PlanName   string `json:"plan_name"`
+	Status     string `json:"status"` // active | trialing | past_due | paused
+	StartsAt   string `json:"starts_at"`
+	EndsAt     string `json:"ends_at,omitempty"` // unknown while paused
+	AutoRenew  bool   `json:"auto_renew"`
+	CanceledAt string `json:"canceled_at,omitempty"` // renewal turned off; the plan runs until ends_at
+	PausedAt   string `json:"paused_at,omitempty"`
+	// Days frozen by the pause
+	RemainingDays int `json:"remaining_days,omitempty"`
+}
+
+// BillingRenewal is the next period: already paid for when Prepaid, otherwise the payment due.
+type BillingRenewal struct {
+	Date        string `json:"date"`
+	PlanCode    string `json:"plan_code"`
+	PlanName    string `json:"plan_name"`
+	AmountMinor int64  `json:"amount_minor"`
+	Currency    string `json:"currency"`
+	Prepaid     bool   `json:"prepaid"`
+}
+
+type BillingPayment struct {
+	ID            string `json:"id"`
+	Status        string `json:"status"` // pending | paid | failed | refunded
+	AmountMinor   int64  `json:"amount_minor"`
+	Currency      string `json:"currency"`
+	PlanCode      string `json:"plan_code,omitempty"`
+	PlanName      string `json:"plan_name,omitempty"`
+	CreatedAt     string `json:"created_at"`
+	PaidAt        string `json:"paid_at,omitempty"`
+	InvoiceNumber string `json:"invoice_number,omitempty"` // paid and refunded payments
+}
+
+type BillingPaymentPage struct {
+	Items    []BillingPayment `json:"items"`
+	Total    int64            `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+}
+
+type Invoice struct {
+	Number        string        `json:"number"`
+	TransactionID string        `json:"transaction_id"`
+	Status        string        `json:"status"` // paid | refunded
+	IssuedAt      string        `json:"issued_at"`
+	RefundedAt    string        `json:"refunded_at,omitempty"`
+	Seller        InvoiceParty  `json:"seller"`
+	BilledTo      InvoiceParty  `json:"billed_to"`
+	Lines         []InvoiceLine `json:"lines"`
+	SubtotalMinor int64         `json:"subtotal_minor"` // total without the VAT it includes
+	VATRate       float64       `json:"vat_rate"`       // percent, 0 when no VAT is charged
+	VATMinor      int64         `json:"vat_minor"`
+	TotalMinor    int64         `json:"total_minor"`
+	Currency      string        `json:"currency"`
+	Provider      string        `json:"provider"`
+}
+
+type InvoiceParty struct {
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Address string `json:"address,omitempty"`
+	TaxCode string `json:"tax_code,omitempty"`
+}
+
+type InvoiceLine struct {
+	Description string `json:"description"`
+	PeriodStart string `json:"period_start,omitempty"`
+	PeriodEnd   string `json:"period_end,omitempty"`
+	AmountMinor int64  `json:"amount_minor"`
+}
+
+type InvoicePage struct {
+	Items    []Invoice `json:"items"`
+	Total    int64     `json:"total"`
+	Page     int       `json:"page"`
+	PageSize int       `json:"page_size"`
+}
+
+type BillingPlanOption struct {
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	Period     string `json:"period"`
+	PriceMinor int64  `json:"price_minor"`
+	Currency   string `json:"currency"`
+	// subscribe (no running plan) | current | upgrade | downgrade | switch (other currency),
+	// by price per month
+	Change string `json:"change"`
+	// When a plan bought now starts: at the end of the running subscription, or right away
+	EffectiveAt string `json:"effective_at"`
+}
+
+type BillingActions struct {
+	CanCancel          bool `json:"can_cancel"`
+	CanResumeAutoRenew bool `json:"can_resume_auto_renew"`
+	CanPause           bool `json:"can_pause"`
+	CanResume          bool `json:"can_resume"`
+	CanChangePlan      bool `json:"can_change_plan"`
+}
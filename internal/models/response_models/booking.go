@@ -0,0 +1,25 @@
+package response_models
+
+import "github.com/google/uuid"
+
+type BookingLink struct {
+	Partner        string            `json:"partner"`
+	URL            string            `json:"url"`
+	DeepLinkParams map[string]string `json:"deep_link_params,omitempty"`
+}
+
+// BookingHandoff is where the client sends the traveller to complete the booking.
+type BookingHandoff struct {
+	EventID    uuid.UUID `json:"event_id"`
+	ActivityID uuid.UUID `json:"activity_id"`
+	Partner    string    `json:"partner"`
+	URL        string    `json:"url"`
+	Status     string    `json:"booking_status"`
+}
+
+type ActivityBooking struct {
+	ActivityID uuid.UUID `json:"activity_id"`
+	Status     string    `json:"booking_status"`
+	Partner    string    `json:"booking_partner,omitempty"`
+	Reference  string    `json:"booking_reference,omitempty"`
+}
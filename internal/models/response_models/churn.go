@@ -0,0 +1,63 @@
+package response_models
+
+type ChurnSegment struct {
+	Segment   string `json:"segment"` // low | medium | high
+	Accounts  int64  `json:"accounts"`
+	InWinback int64  `json:"in_winback"` // with a win-back email still to come
+}
+
+type ChurnOverview struct {
+	Segments []ChurnSegment `json:"segments"`
+	ScoredAt string         `json:"scored_at,omitempty"`
+}
+
+type ChurnWinback struct {
+	Segment    string `json:"segment"`
+	Step       int    `json:"step"` // steps sent so far
+	StartedAt  string `json:"started_at"`
+	NextAt     string `json:"next_at,omitempty"`
+	LastSentAt string `json:"last_sent_at,omitempty"`
+}
+
+type ChurnAccount struct {
+	AccountID      string        `json:"account_id"`
+	Email          string        `json:"email"`
+	Name           string        `json:"name"`
+	Score          int           `json:"score"`
+	Segment        string        `json:"segment"`
+	Reasons        []string      `json:"reasons"` // no_journeys_60d | auto_renew_off | usage_declining
+	LastJourneyAt  string        `json:"last_journey_at,omitempty"`
+	RecentJourneys int           `json:"recent_journeys"`
+	PriorJourneys  int           `json:"prior_journeys"`
+	AutoRenewOff   bool          `json:"auto_renew_off"`
+	ScoredAt       string        `json:"scored_at"`
+	Winback        *ChurnWinback `json:"winback,omitempty"`
+}
+
+type ChurnAccountPage struct {
+	Items    []ChurnAccount `json:"items"`
+	Total    int64          `json:"total"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"page_size"`
+}
+
+type ChurnRun struct {
+	Scored          int   `json:"scored"`
+	WinbacksStarted int64 `json:"winbacks_started"`
+}
+
+type WinbackStep struct {
+	DelayDays int    `json:"delay_days"`
+	Subject   string `json:"subject"`
+	Body      string `json:"body"`
+	CTAText   string `json:"cta_text,omitempty"`
+	CTAURL    string `json:"cta_url,omitempty"`
+}
+
+type WinbackCampaign struct {
+	Segment   string        `json:"segment"`
+	Active    bool          `json:"active"`
+	Steps     []WinbackStep `json:"steps"`
+	UpdatedBy string        `json:"updated_by,omitempty"`
+	UpdatedAt string        `json:"updated_at,omitempty"`
+}
@@ -0,0 +1,39 @@
+package response_models
+
+type CollectionSummary struct {
+	ID            string `json:"id"`
+	Slug          string `json:"slug"`
+	Title         string `json:"title"`
+	Description   string `json:"description,omitempty"`
+	CoverImageURL string `json:"cover_image_url,omitempty"`
+	ProvinceID    string `json:"province_id,omitempty"`
+	ProvinceName  string `json:"province_name,omitempty"`
+	POICount      int    `json:"poi_count"`
+	IsPublished   bool   `json:"is_published"`
+	UpdatedAt     string `json:"updated_at,omitempty"`
+}
+
+type CollectionPage struct {
+	Items    []CollectionSummary `json:"items"`
+	Total    int64               `json:"total"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
+}
+
+type CollectionDetail struct {
+	CollectionSummary
+	POIs []CollectionPOI `json:"pois"`
+}
+
+type CollectionPOI struct {
+	POI
+	Note            string `json:"note,omitempty"`
+	DurationMinutes int    `json:"duration_minutes,omitempty"`
+}
+
+type SeedDayResponse struct {
+	Day   *JourneyDayResponse `json:"day"`
+	Added int                 `json:"added"`
+	// Stops of the collection left out, with why (closed, or no time left in the day)
+	Skipped []string `json:"skipped,omitempty"`
+}
@@ -0,0 +1,33 @@
+package response_models
+
+// Coupon is what a customer sees of a coupon; with a plan, the price it comes to.
+type Coupon struct {
+	Code           string   `json:"code"`
+	Kind           string   `json:"kind"` // "percent" | "fixed"
+	PercentOff     int      `json:"percent_off,omitempty"`
+	AmountOffMinor int64    `json:"amount_off_minor,omitempty"`
+	Currency       string   `json:"currency,omitempty"`
+	ExpiresAt      string   `json:"expires_at,omitempty"`
+	PlanCodes      []string `json:"plan_codes,omitempty"`
+
+	PlanCode      string `json:"plan_code,omitempty"`
+	PriceMinor    int64  `json:"price_minor,omitempty"`
+	DiscountMinor int64  `json:"discount_minor,omitempty"`
+	AmountMinor   *int64 `json:"amount_minor,omitempty"` // due at checkout
+}
+
+type AdminCoupon struct {
+	ID             string   `json:"id"`
+	Code           string   `json:"code"`
+	Kind           string   `json:"kind"`
+	PercentOff     int      `json:"percent_off"`
+	AmountOffMinor int64    `json:"amount_off_minor"`
+	Currency       string   `json:"currency,omitempty"`
+	MaxRedemptions *int     `json:"max_redemptions"`
+	Redemptions    int      `json:"redemptions"`
+	ExpiresAt      string   `json:"expires_at,omitempty"`
+	PlanCodes      []string `json:"plan_codes"`
+	IsActive       bool     `json:"is_active"`
+	CreatedBy      string   `json:"created_by,omitempty"`
+	CreatedAt      string   `json:"created_at"`
+}
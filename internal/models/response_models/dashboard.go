@@ -13,6 +13,10 @@ type TimeRange struct {
 	Interval string `json:"interval"`
 	// Optional: timezone used for bucketing (defaults to UTC if empty)
 	Timezone string `json:"timezone,omitempty"`
+	// "mtd" | "qtd" | "ytd": Start and End are resolved from it in Timezone
+	Preset string `json:"preset,omitempty"`
+	// "previous_period" | "previous_year": the range the report is compared with; empty for none
+	Compare string `json:"compare,omitempty"`
 }
 
 type KPIBlock struct {
@@ -24,6 +28,7 @@ type KPIBlock struct {
 	TrialingSubscriptions int64 `json:"trialing_subscriptions"`
 	CanceledSubscriptions int64 `json:"canceled_subscriptions"`
 	ExpiredSubscriptions  int64 `json:"expired_subscriptions"`
+	PausedSubscriptions   int64 `json:"paused_subscriptions"`
 
 	// Financial KPIs
 	MRRMinor  int64   `json:"mrr_minor"`  // monthly recurring revenue (minor units)
@@ -32,19 +37,68 @@ type KPIBlock struct {
 	ChurnPct  float64 `json:"churn_pct"`  // (canceled during period / subscribers at period start) * 100
 }
 
+// KPIDeltas holds the change of each KPI against the comparison range, in percent. A delta is
+// nil when the previous value is 0, and always for the subscription status counts, which keep
+// no history.
+type KPIDeltas struct {
+	TotalAccounts         *float64 `json:"total_accounts"`
+	NewAccounts           *float64 `json:"new_accounts"`
+	TotalJourneys         *float64 `json:"total_journeys"`
+	TotalActivities       *float64 `json:"total_activities"`
+	ActiveSubscriptions   *float64 `json:"active_subscriptions"`
+	TrialingSubscriptions *float64 `json:"trialing_subscriptions"`
+	CanceledSubscriptions *float64 `json:"canceled_subscriptions"`
+	ExpiredSubscriptions  *float64 `json:"expired_subscriptions"`
+	PausedSubscriptions   *float64 `json:"paused_subscriptions"`
+	MRRMinor              *float64 `json:"mrr_minor"`
+	ARRMinor              *float64 `json:"arr_minor"`
+	ARPUMinor             *float64 `json:"arpu_minor"`
+	ChurnPct              *float64 `json:"churn_pct"`
+}
+
 type SeriesPoint struct {
 	Bucket time.Time `json:"bucket"`
 	Value  int64     `json:"value"`
+	// Set when comparing: the value of the matching bucket of the comparison range
+	Previous *int64   `json:"previous,omitempty"`
+	DeltaPct *float64 `json:"delta_pct,omitempty"`
 }
 
 type RevenueSeries struct {
-	Currency   string        `json:"currency"`
-	Points     []SeriesPoint `json:"points"`
-	TotalMinor int64         `json:"total_minor"`
+	Currency           string        `json:"currency"`
+	Points             []SeriesPoint `json:"points"`
+	TotalMinor         int64         `json:"total_minor"`
+	PreviousTotalMinor *int64        `json:"previous_total_minor,omitempty"`
+	TotalDeltaPct      *float64      `json:"total_delta_pct,omitempty"`
 }
 
 type CountSeries struct {
-	Points []SeriesPoint `json:"points"`
+	Points        []SeriesPoint `json:"points"`
+	Total         int64         `json:"total"`
+	PreviousTotal *int64        `json:"previous_total,omitempty"`
+	TotalDeltaPct *float64      `json:"total_delta_pct,omitempty"`
+}
+
+// DashboardComparison is the comparison range of a report. Its KPIs are those of that range;
+// point-in-time ones (totals, active subscriptions, MRR) are taken at its end, and the status
+// counts that keep no history are 0.
+type DashboardComparison struct {
+	Range TimeRange `json:"range"`
+	// The comparison range in words, e.g. "previous 30 days" or "same period last year"
+	Label    string              `json:"label"`
+	KPIs     KPIBlock            `json:"kpis"`
+	Deltas   KPIDeltas           `json:"deltas"`
+	Headline []DashboardHeadline `json:"headline"`
+}
+
+// DashboardHeadline is one headline figure next to its value in the comparison range, ready
+// for "+12% vs previous 30 days".
+type DashboardHeadline struct {
+	Metric   string   `json:"metric"` // revenue_minor | new_accounts | new_subscriptions | churn_pct
+	Current  float64  `json:"current"`
+	Previous float64  `json:"previous"`
+	Change   float64  `json:"change"`    // current - previous; percentage points for churn_pct
+	DeltaPct *float64 `json:"delta_pct"` // nil when previous is 0
 }
 
 type PlanMixItem struct {
@@ -77,6 +131,24 @@ type RecentPayment struct {
 	AccountEmail  string     `json:"account_email"`
 }
 
+type PlanChangeFlow struct {
+	FromPlanCode  string `json:"from_plan_code"`
+	ToPlanCode    string `json:"to_plan_code"`
+	Direction     string `json:"direction"` // "upgrade" | "downgrade"
+	Count         int64  `json:"count"`
+	ChargedMinor  int64  `json:"charged_minor"`
+	CreditedMinor int64  `json:"credited_minor"`
+}
+
+// PlanChanges sums the prorated plan changes applied in the range.
+type PlanChanges struct {
+	Upgrades      int64            `json:"upgrades"`
+	Downgrades    int64            `json:"downgrades"`
+	ChargedMinor  int64            `json:"charged_minor"`
+	CreditedMinor int64            `json:"credited_minor"`
+	Flows         []PlanChangeFlow `json:"flows"`
+}
+
 type DashboardReport struct {
 	Range           TimeRange        `json:"range"`
 	KPIs            KPIBlock         `json:"kpis"`
@@ -86,4 +158,46 @@ type DashboardReport struct {
 	PlanMix         PlanMix          `json:"plan_mix"`
 	TopDestinations []TopDestination `json:"top_destinations"`
 	RecentPayments  []RecentPayment  `json:"recent_payments"`
+	PlanChanges     PlanChanges      `json:"plan_changes"`
+	// Against the previous period unless the request asked for compare=none
+	Comparison *DashboardComparison `json:"comparison,omitempty"`
+}
+
+// CohortReport is a retention matrix: a row per signup month, and in each row a column per
+// month since signup, up to the month in progress.
+type CohortReport struct {
+	// "subscription" | "journeys": what kept an account in its cohort for a month
+	Metric   string      `json:"metric"`
+	Timezone string      `json:"timezone"`
+	Start    time.Time   `json:"start"`
+	End      time.Time   `json:"end"`
+	Cohorts  []CohortRow `json:"cohorts"`
+}
+
+type CohortRow struct {
+	Cohort time.Time `json:"cohort"` // first day of the signup month
+	Size   int64     `json:"size"`
+	// Index i is the month i months after signup; the last one is the month in progress
+	Retained     []int64   `json:"retained"`
+	RetentionPct []float64 `json:"retention_pct"` // of Size, rounded to two decimals
+}
+
+type SlowQueryResponse struct {
+	ID           string `json:"id"`
+	Fingerprint  string `json:"fingerprint"`
+	Table        string `json:"table"`
+	SQL          string `json:"sql"`
+	BoundSQL     string `json:"bound_sql"`
+	DurationMs   int64  `json:"duration_ms"`
+	RowsAffected int64  `json:"rows_affected"`
+	Plan         string `json:"plan"`
+	Occurrences  int64  `json:"occurrences"`
+	LastSeenAt   string `json:"last_seen_at"`
+}
+
+type SlowQueryPage struct {
+	Items    []SlowQueryResponse `json:"items"`
+	Total    int64               `json:"total"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
 }
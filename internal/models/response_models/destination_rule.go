@@ -0,0 +1,12 @@
+package response_models
+
+type DestinationRuleResponse struct {
+	ProvinceID          string   `json:"province_id"`
+	ProvinceName        string   `json:"province_name,omitempty"`
+	MaxActivitiesPerDay int      `json:"max_activities_per_day"`
+	ForbiddenWindows    []string `json:"forbidden_windows"`
+	BufferMinutes       int      `json:"buffer_minutes"`
+	Note                string   `json:"note,omitempty"`
+	UpdatedBy           string   `json:"updated_by,omitempty"`
+	UpdatedAt           string   `json:"updated_at,omitempty"`
+}
@@ -0,0 +1,13 @@
+package response_models
+
+// JourneyBackupJob is the state of a backup of the caller's journeys. Times are RFC3339.
+type JourneyBackupJob struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"` // pending | ready | failed | expired
+	RequestedAt string `json:"requested_at"`
+	CompletedAt string `json:"completed_at,omitempty"`
+	// Until when GET /accounts/me/journeys/export returns the file
+	ExpiresAt string `json:"expires_at,omitempty"`
+	Journeys  int    `json:"journeys,omitempty"`
+	SizeBytes int64  `json:"size_bytes,omitempty"`
+}
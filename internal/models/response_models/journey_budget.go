@@ -0,0 +1,47 @@
+package response_models
+
+import "github.com/google/uuid"
+
+// BudgetSummary compares a trip's spending with its budget. Amounts are VND. The projection
+// assumes the remaining days cost what the elapsed ones did on average.
+type BudgetSummary struct {
+	Budget         int64 `json:"budget"`
+	Spent          int64 `json:"spent"`
+	Remaining      int64 `json:"remaining"` // negative once over budget
+	TotalDays      int   `json:"total_days"`
+	DaysElapsed    int   `json:"days_elapsed"` // today included
+	DaysRemaining  int   `json:"days_remaining"`
+	DailyBurn      int64 `json:"daily_burn"` // average spend per elapsed day
+	Projected      int64 `json:"projected"`  // spend by the end of the trip at that rate
+	SafeDailySpend int64 `json:"safe_daily_spend"`
+	OverBudget     bool  `json:"over_budget"`
+	ProjectedOver  bool  `json:"projected_over_budget"`
+}
+
+type JourneyExpense struct {
+	ID         uuid.UUID  `json:"id"`
+	Amount     int64      `json:"amount"`
+	Category   string     `json:"category"`
+	Merchant   string     `json:"merchant,omitempty"`
+	Note       string     `json:"note,omitempty"`
+	SpentAt    string     `json:"spent_at"`
+	ActivityID *uuid.UUID `json:"activity_id,omitempty"`
+	LoggedBy   uuid.UUID  `json:"logged_by"`
+}
+
+type ExpenseLogged struct {
+	Expense JourneyExpense `json:"expense"`
+	Budget  *BudgetSummary `json:"budget,omitempty"`
+}
+
+// ReceiptDraft is an expense prefilled from a receipt photo, for the user to check and send to
+// POST /journeys/{journeyId}/expenses. Fields that could not be read are left empty.
+type ReceiptDraft struct {
+	Amount   int64    `json:"amount,omitempty"` // VND; empty when the receipt is in another currency
+	Category string   `json:"category"`
+	Merchant string   `json:"merchant,omitempty"`
+	SpentAt  string   `json:"spent_at,omitempty"`
+	Currency string   `json:"currency,omitempty"`
+	Total    float64  `json:"total,omitempty"` // as printed, in Currency
+	Warnings []string `json:"warnings,omitempty"`
+}
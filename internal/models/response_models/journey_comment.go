@@ -0,0 +1,18 @@
+package response_models
+
+import "github.com/google/uuid"
+
+// JourneyComment is a top-level comment with its replies, oldest first. A deleted comment keeps
+// its place in the thread with an empty body.
+type JourneyComment struct {
+	ID         uuid.UUID        `json:"id"`
+	ActivityID *uuid.UUID       `json:"activity_id,omitempty"`
+	ParentID   *uuid.UUID       `json:"parent_id,omitempty"`
+	AccountID  uuid.UUID        `json:"account_id"`
+	AuthorName string           `json:"author_name,omitempty"`
+	Body       string           `json:"body"`
+	Mentions   []uuid.UUID      `json:"mentions,omitempty"`
+	Deleted    bool             `json:"deleted,omitempty"`
+	CreatedAt  string           `json:"created_at"`
+	Replies    []JourneyComment `json:"replies,omitempty"`
+}
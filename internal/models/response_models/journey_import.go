@@ -0,0 +1,27 @@
+package response_models
+
+type JourneyImportIssue struct {
+	Row    int    `json:"row"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+type JourneyImportMatch struct {
+	Row     int    `json:"row"`
+	Name    string `json:"name"`
+	PoiID   string `json:"poi_id"`
+	PoiName string `json:"poi_name"`
+	Day     int    `json:"day"`
+	Draft   bool   `json:"draft"` // true when the POI was created from the file
+}
+
+type JourneyImportResult struct {
+	JourneyID     string               `json:"journey_id"`
+	Title         string               `json:"title"`
+	Format        string               `json:"format"` // kml | csv
+	Days          int                  `json:"days"`
+	Matched       int                  `json:"matched"`
+	DraftsCreated int                  `json:"drafts_created"`
+	Places        []JourneyImportMatch `json:"places"`
+	Skipped       []JourneyImportIssue `json:"skipped,omitempty"`
+}
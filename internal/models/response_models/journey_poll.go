@@ -0,0 +1,28 @@
+package response_models
+
+import "github.com/google/uuid"
+
+type JourneyMember struct {
+	AccountID uuid.UUID `json:"account_id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name,omitempty"`
+	Role      string    `json:"role"`
+}
+
+type PollOption struct {
+	ID    uuid.UUID   `json:"id"`
+	POI   *POISummary `json:"poi"`
+	Votes int         `json:"votes"`
+}
+
+type JourneyPoll struct {
+	ID             uuid.UUID    `json:"id"`
+	ActivityID     uuid.UUID    `json:"activity_id"`
+	Question       string       `json:"question,omitempty"`
+	Deadline       string       `json:"deadline"`
+	Status         string       `json:"status"`
+	Options        []PollOption `json:"options"`
+	MyVote         *uuid.UUID   `json:"my_vote,omitempty"`
+	WinnerOptionID *uuid.UUID   `json:"winner_option_id,omitempty"`
+	ClosedAt       string       `json:"closed_at,omitempty"`
+}
@@ -7,3 +7,18 @@ type JourneyResponse struct {
 	EndDate   string `json:"end_date"`
 	Location  string `json:"location"`
 }
+
+// ActivityTimeSlot is a time the activity could move to instead of the one asked for.
+type ActivityTimeSlot struct {
+	StartTime    string `json:"start_time"` // RFC3339
+	EndTime      string `json:"end_time"`
+	ShiftMinutes int    `json:"shift_minutes"`   // from the requested start; negative is earlier
+	After        string `json:"after,omitempty"` // the activity it follows; empty when first of the day
+}
+
+// ActivityTimeConflict is sent with a rejected activity time: why it does not work and the
+// nearest times that do, best first.
+type ActivityTimeConflict struct {
+	Problems     []string           `json:"problems"`
+	Alternatives []ActivityTimeSlot `json:"alternatives"`
+}
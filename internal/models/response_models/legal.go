@@ -0,0 +1,31 @@
+package response_models
+
+type LegalDocument struct {
+	ID          string `json:"id"`
+	Kind        string `json:"kind"` // terms | privacy
+	Version     string `json:"version"`
+	Title       string `json:"title"`
+	URL         string `json:"url,omitempty"`
+	Body        string `json:"body,omitempty"`
+	Minor       bool   `json:"minor"`
+	PublishedAt string `json:"published_at"`
+}
+
+type AdminLegalDocument struct {
+	LegalDocument
+	Current     bool   `json:"current"` // the version accounts are asked to accept
+	PublishedBy string `json:"published_by,omitempty"`
+}
+
+// LegalDocumentStatus is where the account stands with one kind of document.
+type LegalDocumentStatus struct {
+	Current         LegalDocument `json:"current"`
+	Accepted        bool          `json:"accepted"`
+	AcceptedVersion string        `json:"accepted_version,omitempty"`
+	AcceptedAt      string        `json:"accepted_at,omitempty"`
+}
+
+type LegalStatus struct {
+	AcceptanceRequired bool                  `json:"acceptance_required"`
+	Documents          []LegalDocumentStatus `json:"documents"`
+}
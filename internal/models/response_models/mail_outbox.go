@@ -0,0 +1,29 @@
+package response_models
+
+type MailOutboxMessage struct {
+	ID            string `json:"id"`
+	Kind          string `json:"kind"` // notify | reset_code | template
+	Template      string `json:"template,omitempty"`
+	Locale        string `json:"locale,omitempty"`
+	Recipient     string `json:"recipient"`
+	Subject       string `json:"subject"`
+	Attachment    string `json:"attachment,omitempty"` // file name
+	Status        string `json:"status"`               // pending | sent | dead
+	Attempts      int    `json:"attempts"`
+	NextAttemptAt string `json:"next_attempt_at,omitempty"`
+	LastError     string `json:"last_error,omitempty"`
+	SentAt        string `json:"sent_at,omitempty"`
+	ExpiresAt     string `json:"expires_at,omitempty"`
+	CreatedAt     string `json:"created_at"`
+}
+
+type MailOutboxPage struct {
+	Items    []MailOutboxMessage `json:"items"`
+	Total    int64               `json:"total"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
+}
+
+type MailOutboxRetried struct {
+	Requeued int64 `json:"requeued"`
+}
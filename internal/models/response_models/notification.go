@@ -0,0 +1,22 @@
+package response_models
+
+import "encoding/json"
+
+type Notification struct {
+	ID        string          `json:"id"`
+	Kind      string          `json:"kind"` // the event, e.g. plan.generated
+	Title     string          `json:"title"`
+	Body      string          `json:"body"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	Read      bool            `json:"read"`
+	ReadAt    string          `json:"read_at,omitempty"`
+	CreatedAt string          `json:"created_at"`
+}
+
+type NotificationPage struct {
+	Items    []Notification `json:"items"`
+	Total    int64          `json:"total"`
+	Unread   int64          `json:"unread"` // unread notifications of the account, whatever the filter
+	Page     int            `json:"page"`
+	PageSize int            `json:"page_size"`
+}
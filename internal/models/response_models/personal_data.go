@@ -0,0 +1,22 @@
+package response_models
+
+// PersonalDataTable is one entry of the personal data inventory.
+type PersonalDataTable struct {
+	Table   string `json:"table"`
+	Purpose string `json:"purpose"`
+	Erasure string `json:"erasure"`        // delete | redact | retain
+	Rows    *int64 `json:"rows,omitempty"` // the account's rows, when one is asked for
+}
+
+type PersonalDataInventory struct {
+	AccountID string              `json:"account_id,omitempty"`
+	Tables    []PersonalDataTable `json:"tables"`
+}
+
+// PersonalDataErasure tells what erasing an account did, table by table.
+type PersonalDataErasure struct {
+	AccountID    string              `json:"account_id"`
+	Tables       []PersonalDataTable `json:"tables"`
+	FilesDeleted int                 `json:"files_deleted"`
+	QuizSessions int                 `json:"quiz_sessions"`
+}
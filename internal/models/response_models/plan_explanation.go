@@ -0,0 +1,27 @@
+package response_models
+
+// SelectionSignals are the retrieval signals a planned POI was picked with. They are stored on
+// the journey activity and turned into a rationale by the explain endpoint.
+type SelectionSignals struct {
+	Score         float64  `json:"score"`                    // fused retrieval score (reciprocal-rank fusion)
+	Rank          int      `json:"rank"`                     // position among the candidates, from 1
+	Candidates    int      `json:"candidates"`               // candidates the model chose from
+	Similarity    *float64 `json:"similarity,omitempty"`     // cosine similarity to the request, 0..1
+	VectorRank    *int     `json:"vector_rank,omitempty"`    // nil when not in the semantic ranking
+	KeywordRank   *int     `json:"keyword_rank,omitempty"`   // nil when not in the text ranking
+	LocationMatch bool     `json:"location_match,omitempty"` // found by the destination's place names
+	MatchedTags   []string `json:"matched_tags,omitempty"`   // POI tags among the traveler's interests
+	Rating        *float64 `json:"rating,omitempty"`         // best-reviewed third-party rating
+	ReviewCount   int      `json:"review_count,omitempty"`
+	RatingSource  string   `json:"rating_source,omitempty"`
+}
+
+// ActivityExplanation answers "why this place?" for one planned activity.
+type ActivityExplanation struct {
+	ActivityID string            `json:"activity_id"`
+	POIID      string            `json:"poi_id"`
+	POIName    string            `json:"poi_name"`
+	Summary    string            `json:"summary"`
+	Reasons    []string          `json:"reasons"`
+	Signals    *SelectionSignals `json:"signals,omitempty"` // nil for activities added by hand or before signals were kept
+}
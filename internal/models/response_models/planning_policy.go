@@ -0,0 +1,9 @@
+package response_models
+
+type PlanningPolicyResponse struct {
+	Exclude       []string `json:"exclude"`
+	BoostPOIIDs   []string `json:"boost_poi_ids"`
+	RequirePOIIDs []string `json:"require_poi_ids"`
+	UpdatedBy     string   `json:"updated_by,omitempty"`
+	UpdatedAt     int64    `json:"updated_at,omitempty"` // 0 until a policy is set
+}
@@ -0,0 +1,37 @@
+package response_models
+
+type POIProvinceFlag struct {
+	ID                  string   `json:"id"`
+	POIID               string   `json:"poi_id"`
+	POIName             string   `json:"poi_name"`
+	Kind                string   `json:"kind"`   // wrong_province | swapped_coords | outside
+	Status              string   `json:"status"` // open | applied | dismissed | cleared
+	Latitude            float64  `json:"latitude"`
+	Longitude           float64  `json:"longitude"`
+	ProvinceID          string   `json:"province_id"`
+	Province            string   `json:"province"`
+	SuggestedProvinceID string   `json:"suggested_province_id,omitempty"`
+	SuggestedProvince   string   `json:"suggested_province,omitempty"`
+	SuggestedLatitude   *float64 `json:"suggested_latitude,omitempty"`
+	SuggestedLongitude  *float64 `json:"suggested_longitude,omitempty"`
+	DetectedAt          string   `json:"detected_at"`
+	ResolvedAt          string   `json:"resolved_at,omitempty"`
+	ResolvedBy          string   `json:"resolved_by,omitempty"`
+}
+
+type POIProvinceFlagPage struct {
+	Items    []POIProvinceFlag `json:"items"`
+	Total    int64             `json:"total"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+}
+
+// POIProvinceScan sums up one run of the province check.
+type POIProvinceScan struct {
+	Checked       int   `json:"checked"`
+	Unknown       int   `json:"unknown"` // in a province without an outline and in no other one
+	WrongProvince int   `json:"wrong_province"`
+	Swapped       int   `json:"swapped_coords"`
+	Outside       int   `json:"outside"`
+	Cleared       int64 `json:"cleared"` // open flags closed because the POI was fixed
+}
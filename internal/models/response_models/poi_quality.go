@@ -0,0 +1,40 @@
+package response_models
+
+// POIQuality is the completeness score of a POI with what is missing from it.
+type POIQuality struct {
+	ID                string   `json:"id"`
+	Name              string   `json:"name"`
+	Status            string   `json:"status"`
+	Province          string   `json:"province"`
+	Category          string   `json:"category"`
+	Score             int      `json:"score"` // 0-100
+	Missing           []string `json:"missing"`
+	ImageCount        int      `json:"image_count"`
+	DescriptionLength int      `json:"description_length"`
+	UpdatedAt         string   `json:"updated_at,omitempty"`
+}
+
+type POIQualityPage struct {
+	Items    []POIQuality `json:"items"`
+	Total    int64        `json:"total"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"page_size"`
+}
+
+// POIQualityReport sums up the gaps of the catalog, worst provinces first.
+type POIQualityReport struct {
+	Total          int64                   `json:"total"`
+	AverageScore   float64                 `json:"average_score"`
+	AttentionBelow int                     `json:"attention_below"`
+	NeedsAttention int64                   `json:"needs_attention"`
+	Gaps           map[string]int64        `json:"gaps"` // POIs per gap
+	Provinces      []POIQualityProvinceGap `json:"provinces"`
+}
+
+type POIQualityProvinceGap struct {
+	ProvinceID     string  `json:"province_id"`
+	Province       string  `json:"province"`
+	Total          int64   `json:"total"`
+	AverageScore   float64 `json:"average_score"`
+	NeedsAttention int64   `json:"needs_attention"`
+}
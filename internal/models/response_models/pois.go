@@ -11,8 +11,11 @@ type POI struct {
 	Address      string      `json:"address"`
 	PoiDetails   *PoiDetails `json:"poi_details"`
 
-	DistanceToNextMeters *int   `json:"distance_to_next_meters,omitempty"`
-	NextLegMapURL        string `json:"next_leg_map_url,omitempty"`
+	ExternalRatings []ExternalRating `json:"external_ratings,omitempty"`
+
+	DistanceToNextMeters  *int   `json:"distance_to_next_meters,omitempty"`
+	DurationToNextSeconds *int   `json:"duration_to_next_seconds,omitempty"`
+	NextLegMapURL         string `json:"next_leg_map_url,omitempty"`
 }
 
 type PoiDetails struct {
@@ -20,3 +23,46 @@ type PoiDetails struct {
 	Description string   `json:"description"`
 	Image       []string `json:"images"`
 }
+
+type POISearchHit struct {
+	POI
+	Rank float64 `json:"rank"`
+}
+
+type POINearbyHit struct {
+	POI
+	DistanceMeters float64 `json:"distance_meters"`
+}
+
+type POISearchPage struct {
+	Items    []POISearchHit `json:"items"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"page_size"`
+	Total    int64          `json:"total"`
+}
+
+// ExternalRating is a third-party rating snapshot. Attribution must be shown next to it.
+type ExternalRating struct {
+	Source      string   `json:"source"` // google | tripadvisor
+	Attribution string   `json:"attribution"`
+	Rating      *float64 `json:"rating,omitempty"`
+	ReviewCount *int     `json:"review_count,omitempty"`
+	URL         string   `json:"url,omitempty"`
+	FetchedAt   string   `json:"fetched_at,omitempty"` // RFC3339
+}
+
+type POIImportRowError struct {
+	Row     int    `json:"row"` // spreadsheet row, header is row 1
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type POIImportReport struct {
+	DryRun     bool                `json:"dry_run"`
+	TotalRows  int                 `json:"total_rows"`
+	Valid      int                 `json:"valid"`
+	Inserted   int                 `json:"inserted"`
+	Failed     int                 `json:"failed"`
+	Errors     []POIImportRowError `json:"errors"`
+	CreatedIDs []string            `json:"created_ids,omitempty"`
+}
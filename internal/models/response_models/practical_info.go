@@ -0,0 +1,12 @@
+package response_models
+
+type PracticalInfoResponse struct {
+	ProvinceID   string   `json:"province_id"`
+	ProvinceName string   `json:"province_name,omitempty"`
+	Connectivity []string `json:"connectivity"`
+	Transport    []string `json:"transport"`
+	Tipping      string   `json:"tipping,omitempty"`
+	Other        []string `json:"other"`
+	UpdatedBy    string   `json:"updated_by,omitempty"`
+	UpdatedAt    string   `json:"updated_at,omitempty"`
+}
@@ -0,0 +1,24 @@
+package response_models
+
+import "encoding/json"
+
+// ProvinceBoundaryCollection is a GeoJSON FeatureCollection of province outlines.
+type ProvinceBoundaryCollection struct {
+	Type     string                    `json:"type"` // FeatureCollection
+	Features []ProvinceBoundaryFeature `json:"features"`
+}
+
+type ProvinceBoundaryFeature struct {
+	Type       string                     `json:"type"` // Feature
+	ID         string                     `json:"id"`
+	BBox       [4]float64                 `json:"bbox"` // min lng, min lat, max lng, max lat
+	Properties ProvinceBoundaryProperties `json:"properties"`
+	Geometry   json.RawMessage            `json:"geometry"`
+}
+
+type ProvinceBoundaryProperties struct {
+	ProvinceID string `json:"province_id"`
+	Name       string `json:"name"`
+	Points     int    `json:"points"` // positions after simplification
+	Source     string `json:"source,omitempty"`
+}
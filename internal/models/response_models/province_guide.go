@@ -0,0 +1,12 @@
+package response_models
+
+type ProvinceGuideResponse struct {
+	ProvinceID    string `json:"province_id"`
+	ProvinceName  string `json:"province_name,omitempty"`
+	CoverImageURL string `json:"cover_image_url,omitempty"`
+	Intro         string `json:"intro,omitempty"`
+	Highlights    []POI  `json:"highlights"`
+	TripDays      []int  `json:"trip_days"`
+	UpdatedBy     string `json:"updated_by,omitempty"`
+	UpdatedAt     string `json:"updated_at,omitempty"`
+}
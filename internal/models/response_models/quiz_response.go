@@ -5,13 +5,35 @@ import (
 	"vivu/internal/models/request_models"
 )
 
+// Quiz steps a QuizResponse sends the client to; the controller resolves them to NextEndpoint.
+const (
+	QuizStepStart  = "start"
+	QuizStepAnswer = "answer"
+	QuizStepPlan   = "plan"
+)
+
 type QuizResponse struct {
 	Questions    []request_models.QuizQuestion `json:"questions"`
 	CurrentStep  int                           `json:"current_step"`
 	TotalSteps   int                           `json:"total_steps"`
 	SessionID    string                        `json:"session_id"`
 	IsComplete   bool                          `json:"is_complete"`
+	NextStep     string                        `json:"next_step,omitempty"`
 	NextEndpoint string                        `json:"next_endpoint,omitempty"`
+	// Answers filled in by the travel preset the quiz was started with; their questions are skipped
+	Prefilled map[string]string `json:"prefilled,omitempty"`
+	// Unix seconds. The session expires at ExpiresAt unless answered or kept alive; from WarnAt
+	// on the client should tell the traveller
+	ExpiresAt int64 `json:"expires_at"`
+	WarnAt    int64 `json:"warn_at"`
+}
+
+// QuizExpired is the data of a quiz_session_expired error: the client starts a new quiz at
+// RestartEndpoint.
+type QuizExpired struct {
+	SessionID       string `json:"session_id"`
+	ExpiredAt       int64  `json:"expired_at"`
+	RestartEndpoint string `json:"restart_endpoint,omitempty"`
 }
 
 type QuizResultResponse struct {
@@ -45,27 +67,52 @@ type PlanOnly struct {
 	Duration       int            `json:"duration_days"`
 	Days           []PlanOnlyDay  `json:"days"`
 	CreatedAt      time.Time      `json:"created_at"`
+	TravelMode     string         `json:"travel_mode,omitempty"`
 	DistanceMatrix DistanceMatrix `json:"distance_matrix,omitempty"`
+	// Changes made to respect destination rules (moved or removed activities)
+	RuleAdjustments []string `json:"rule_adjustments,omitempty"`
+	// Practical hints for the traveler's companions (kids, seniors...)
+	Tips []string `json:"tips,omitempty"`
+	// How varied the plan is; a plan below the threshold was regenerated once with Hints
+	Diversity *PlanDiversity `json:"diversity,omitempty"`
+}
+
+// PlanDiversity scores a plan from 0 (monotonous) to 1 (varied).
+type PlanDiversity struct {
+	Score                float64  `json:"score"`
+	CategoryEntropy      float64  `json:"category_entropy"`
+	IndoorOutdoorBalance float64  `json:"indoor_outdoor_balance"`
+	FoodSpacing          float64  `json:"food_spacing"`
+	Hints                []string `json:"hints,omitempty"`
 }
 
 type PlanOnlyDay struct {
 	Day        int                `json:"day"`
 	Activities []PlanOnlyActivity `json:"activities"`
+	// Same time slots with outdoor stops swapped for indoor ones; empty when nothing needed swapping
+	RainyDay []PlanOnlyActivity `json:"rainy_day,omitempty"`
 }
 
 type PlanOnlyActivity struct {
 	StartTime string `json:"start_time"` // "09:00"
 	EndTime   string `json:"end_time"`   // "11:00"
 	MainPOIID string `json:"main_poi_id"`
+	// Planner hint shown with the activity (e.g. golden-hour timing); saved as the activity notes
+	Note string `json:"note,omitempty"`
 
 	MainPOI *POI `json:"main_poi,omitempty"`
 
-	DistanceToNextMeters *int   `json:"distance_to_next_meters,omitempty"`
-	NextLegMapURL        string `json:"next_leg_map_url,omitempty"`
+	DistanceToNextMeters  *int   `json:"distance_to_next_meters,omitempty"`
+	DurationToNextSeconds *int   `json:"duration_to_next_seconds,omitempty"`
+	NextLegMapURL         string `json:"next_leg_map_url,omitempty"`
+
+	// Saved with the activity for GET /prompt/explain; not part of the plan payload
+	Selection *SelectionSignals `json:"-"`
 }
 
 type MatrixEdge struct {
-	DistanceMeters int `json:"distance_meters"`
+	DistanceMeters  int `json:"distance_meters"`
+	DurationSeconds int `json:"duration_seconds"`
 }
 
 type DistanceMatrix map[string]map[string]MatrixEdge
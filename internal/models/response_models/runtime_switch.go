@@ -0,0 +1,9 @@
+package response_models
+
+type RuntimeSwitchResponse struct {
+	Key       string `json:"key"`
+	Enabled   bool   `json:"enabled"`
+	Message   string `json:"message"`
+	UpdatedBy string `json:"updated_by,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
@@ -0,0 +1,37 @@
+package response_models
+
+// SecretReport lists the API keys in use, identified by fingerprint: values are never shown.
+type SecretReport struct {
+	Instance string      `json:"instance"` // the instance that answered
+	Source   string      `json:"source"`   // env | vault:<path> | aws:<secret id>
+	Rotated  []string    `json:"rotated,omitempty"`
+	Keys     []SecretKey `json:"keys"`
+}
+
+type SecretKey struct {
+	Name string `json:"name"`
+	// The version the answering instance holds; empty when only other instances read it
+	Source      string `json:"source,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	LoadedAt    string `json:"loaded_at,omitempty"`
+	RotatedAt   string `json:"rotated_at,omitempty"`
+	// Every version read by any instance, most recently used first. An old version still used
+	// recently means some instance has not picked up the rotation yet.
+	Versions []SecretKeyVersion `json:"versions"`
+}
+
+type SecretKeyVersion struct {
+	Fingerprint string              `json:"fingerprint"`
+	Current     bool                `json:"current"`
+	Uses        int64               `json:"uses"`
+	FirstUsedAt string              `json:"first_used_at"`
+	LastUsedAt  string              `json:"last_used_at"`
+	Instances   []SecretKeyInstance `json:"instances"`
+}
+
+type SecretKeyInstance struct {
+	Instance   string `json:"instance"`
+	Source     string `json:"source"`
+	Uses       int64  `json:"uses"`
+	LastUsedAt string `json:"last_used_at"`
+}
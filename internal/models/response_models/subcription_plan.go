@@ -32,6 +32,30 @@ type SubscriptionStatusResponse struct {
 	StartsAt  int64     `json:"starts_at"`
 	EndsAt    int64     `json:"ends_at"`
 	AutoRenew bool      `json:"auto_renew"`
+	// Set when renewal was turned off: the plan ends at EndsAt
+	CanceledAt int64 `json:"canceled_at,omitempty"`
+	// Set while paused: the entitlement days left, and when the pause ends on its own
+	PausedAt      int64 `json:"paused_at,omitempty"`
+	RemainingDays int   `json:"remaining_days,omitempty"`
+	ResumesAt     int64 `json:"resumes_at,omitempty"`
+}
+
+// PlanChangeResponse is a prorated plan change: applied right away when the credit covers the
+// new plan, or pending until Checkout is paid.
+type PlanChangeResponse struct {
+	ChangeID     uuid.UUID `json:"change_id"`
+	Direction    string    `json:"direction"` // "upgrade" | "downgrade"
+	Status       string    `json:"status"`    // "pending" | "applied"
+	FromPlanCode string    `json:"from_plan_code"`
+	ToPlanCode   string    `json:"to_plan_code"`
+	Currency     string    `json:"currency"`
+	CreditMinor  int64     `json:"credit_minor"` // unused value of the current subscription
+	ChargeMinor  int64     `json:"charge_minor"`
+	// Days the credit left over after the new price adds to the new subscription
+	BonusDays int `json:"bonus_days,omitempty"`
+
+	Checkout     *CreateCheckoutResponse     `json:"checkout,omitempty"`
+	Subscription *SubscriptionStatusResponse `json:"subscription,omitempty"`
 }
 
 type TransactionResponse struct {
@@ -58,3 +82,42 @@ type FeedbackResponse struct {
 	CreatedAt int64     `json:"created_at"`
 	UpdatedAt int64     `json:"updated_at"`
 }
+
+// AdminPlan is a catalog entry as admins see it.
+type AdminPlan struct {
+	SubscriptionPlan
+	Version             int32          `json:"version"`
+	PreviousPlanID      string         `json:"previous_plan_id,omitempty"`
+	Entitlements        map[string]any `json:"entitlements"`
+	ActiveSubscriptions int64          `json:"active_subscriptions"`
+	CreatedAt           string         `json:"created_at"`
+	UpdatedAt           string         `json:"updated_at"`
+}
+
+// PlanAuditEntry is one admin change to a plan.
+type PlanAuditEntry struct {
+	ID          string         `json:"id"`
+	PlanID      string         `json:"plan_id"`
+	Action      string         `json:"action"`
+	PerformedBy string         `json:"performed_by"`
+	Changes     map[string]any `json:"changes"` // field -> {"from", "to"}
+	CreatedAt   string         `json:"created_at"`
+}
+
+type RefundResponse struct {
+	TransactionID    uuid.UUID `json:"transaction_id"`
+	AmountMinor      int64     `json:"amount_minor"` // refunded
+	Currency         string    `json:"currency"`
+	Provider         string    `json:"provider"`
+	ProviderRefundID string    `json:"provider_refund_id"`
+	ProviderStatus   string    `json:"provider_status"` // e.g. succeeded or pending
+	RefundedAt       int64     `json:"refunded_at"`
+	// The subscription the payment bought, after it was shortened or canceled; nil for none
+	Subscription *RefundedSubscription `json:"subscription,omitempty"`
+}
+
+type RefundedSubscription struct {
+	ID     uuid.UUID `json:"id"`
+	Status string    `json:"status"`
+	EndsAt string    `json:"ends_at"` // RFC3339
+}
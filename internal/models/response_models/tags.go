@@ -6,3 +6,11 @@ type TagResponse struct {
 	En   string `json:"en"`
 	Icon string `json:"icon"`
 }
+
+type BulkAssignTagResponse struct {
+	TagID         string   `json:"tag_id"`
+	Matched       int      `json:"matched"`        // POIs the request picked
+	Assigned      int      `json:"assigned"`       // newly tagged, queued for re-embedding
+	AlreadyTagged int      `json:"already_tagged"` // had the tag before
+	MissingPoiIDs []string `json:"missing_poi_ids,omitempty"`
+}
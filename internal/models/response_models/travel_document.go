@@ -0,0 +1,34 @@
+package response_models
+
+import "github.com/google/uuid"
+
+// BookingEmailInfo is what could be read from a booking email. Every field is best effort.
+type BookingEmailInfo struct {
+	Subject   string   `json:"subject,omitempty"`
+	Sender    string   `json:"sender,omitempty"`
+	Provider  string   `json:"provider,omitempty"` // sender domain, e.g. "vietnamairlines.com"
+	Reference string   `json:"reference,omitempty"`
+	Dates     []string `json:"dates,omitempty"` // YYYY-MM-DD, in order of appearance
+}
+
+type TravelDocument struct {
+	ID          uuid.UUID         `json:"id"`
+	Kind        string            `json:"kind"` // upload, email or email_attachment
+	FileName    string            `json:"file_name"`
+	ContentType string            `json:"content_type,omitempty"`
+	Size        int64             `json:"size"`
+	UploadedBy  uuid.UUID         `json:"uploaded_by"`
+	CreatedAt   string            `json:"created_at"`
+	Parsed      *BookingEmailInfo `json:"parsed,omitempty"`
+}
+
+// JourneyInbox is where booking emails can be forwarded to land in the journey's vault.
+type JourneyInbox struct {
+	JourneyID uuid.UUID `json:"journey_id"`
+	Address   string    `json:"address"`
+}
+
+type InboundMailResult struct {
+	JourneyID uuid.UUID `json:"journey_id"`
+	Documents int       `json:"documents"`
+}
@@ -0,0 +1,10 @@
+package response_models
+
+type TravelPreset struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Pace      string   `json:"pace,omitempty"`
+	Budget    string   `json:"budget,omitempty"`
+	Interests []string `json:"interests"`
+	Dietary   []string `json:"dietary"`
+}
@@ -0,0 +1,18 @@
+package response_models
+
+import "github.com/google/uuid"
+
+type TripRemindersResponse struct {
+	JourneyID uuid.UUID `json:"journey_id"`
+	Enabled   bool      `json:"enabled"`
+	// False when the owner turned off trip emails for the whole account; nothing is sent then
+	AccountEnabled bool               `json:"account_enabled"`
+	Reminders      []TripReminderItem `json:"reminders"`
+}
+
+type TripReminderItem struct {
+	Kind   string `json:"kind"` // packing | weather | itinerary | day_of
+	DueAt  string `json:"due_at"`
+	Status string `json:"status"` // scheduled | sent | skipped | failed
+	SentAt string `json:"sent_at,omitempty"`
+}
@@ -0,0 +1,42 @@
+package response_models
+
+import "encoding/json"
+
+type WebhookEndpoint struct {
+	ID          string   `json:"id"`
+	AccountID   string   `json:"account_id,omitempty"`
+	AllAccounts bool     `json:"all_accounts"`
+	URL         string   `json:"url"`
+	Description string   `json:"description,omitempty"`
+	Events      []string `json:"events"` // empty: every event
+	Active      bool     `json:"active"`
+	CreatedAt   string   `json:"created_at"`
+}
+
+// CreatedWebhookEndpoint carries the signing secret; it is only ever shown in this response.
+type CreatedWebhookEndpoint struct {
+	WebhookEndpoint
+	Secret string `json:"secret"`
+}
+
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	EndpointID     string          `json:"endpoint_id"`
+	EventID        string          `json:"event_id"`
+	Event          string          `json:"event"`
+	Status         string          `json:"status"` // pending | delivered | failed
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  string          `json:"next_attempt_at,omitempty"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	DeliveredAt    string          `json:"delivered_at,omitempty"`
+	CreatedAt      string          `json:"created_at"`
+	Payload        json.RawMessage `json:"payload"`
+}
+
+type WebhookDeliveryPage struct {
+	Items    []WebhookDelivery `json:"items"`
+	Total    int64             `json:"total"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+}
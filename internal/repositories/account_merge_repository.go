@@ -0,0 +1,198 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"vivu/internal/models/db_models"
+)
+
+// AccountOwnership counts the rows that belong to one account and move on merge.
+type AccountOwnership struct {
+	Journeys            int64
+	CheckIns            int64
+	Subscriptions       int64
+	ActiveSubscriptions int64
+	Transactions        int64
+	Feedback            int64
+	JourneyMemberships  int64
+	Polls               int64
+	PollVotes           int64
+	Comments            int64
+	TravelDocuments     int64
+	PlanUsages          int64
+	Expenses            int64
+	TravelPresets       int64
+	BookingEvents       int64
+	APIKeys             int64
+	PlanningPolicies    int64
+	WebhookEndpoints    int64
+	PlanChanges         int64
+	Notifications       int64
+	BackupJobs          int64
+	ChurnScores         int64
+	LegalAcceptances    int64
+}
+
+// ownedTables lists every account-owned table with its owner column, in the order they move.
+// Tables with a unique index involving the owner resolve conflicts with the target first
+// (see resolveMergeConflicts).
+var ownedTables = []struct {
+	model  any
+	column string
+	count  func(*AccountOwnership) *int64
+}{
+	{&db_models.Journey{}, "account_id", func(o *AccountOwnership) *int64 { return &o.Journeys }},
+	{&db_models.CheckIn{}, "account_id", func(o *AccountOwnership) *int64 { return &o.CheckIns }},
+	{&db_models.Subscription{}, "account_id", func(o *AccountOwnership) *int64 { return &o.Subscriptions }},
+	{&db_models.Transaction{}, "account_id", func(o *AccountOwnership) *int64 { return &o.Transactions }},
+	{&db_models.Feedback{}, "user_id", func(o *AccountOwnership) *int64 { return &o.Feedback }},
+	{&db_models.JourneyMember{}, "account_id", func(o *AccountOwnership) *int64 { return &o.JourneyMemberships }},
+	{&db_models.JourneyPoll{}, "created_by", func(o *AccountOwnership) *int64 { return &o.Polls }},
+	{&db_models.JourneyPollVote{}, "account_id", func(o *AccountOwnership) *int64 { return &o.PollVotes }},
+	{&db_models.JourneyComment{}, "account_id", func(o *AccountOwnership) *int64 { return &o.Comments }},
+	{&db_models.TravelDocument{}, "account_id", func(o *AccountOwnership) *int64 { return &o.TravelDocuments }},
+	{&db_models.PlanUsage{}, "account_id", func(o *AccountOwnership) *int64 { return &o.PlanUsages }},
+	{&db_models.JourneyExpense{}, "account_id", func(o *AccountOwnership) *int64 { return &o.Expenses }},
+	{&db_models.TravelPreset{}, "account_id", func(o *AccountOwnership) *int64 { return &o.TravelPresets }},
+	{&db_models.BookingEvent{}, "account_id", func(o *AccountOwnership) *int64 { return &o.BookingEvents }},
+	{&db_models.APIKey{}, "account_id", func(o *AccountOwnership) *int64 { return &o.APIKeys }},
+	{&db_models.PlanningPolicy{}, "account_id", func(o *AccountOwnership) *int64 { return &o.PlanningPolicies }},
+	{&db_models.WebhookEndpoint{}, "account_id", func(o *AccountOwnership) *int64 { return &o.WebhookEndpoints }},
+	{&db_models.SubscriptionPlanChange{}, "account_id", func(o *AccountOwnership) *int64 { return &o.PlanChanges }},
+	{&db_models.Notification{}, "account_id", func(o *AccountOwnership) *int64 { return &o.Notifications }},
+	{&db_models.JourneyBackupJob{}, "account_id", func(o *AccountOwnership) *int64 { return &o.BackupJobs }},
+	{&db_models.ChurnScore{}, "account_id", func(o *AccountOwnership) *int64 { return &o.ChurnScores }},
+	{&db_models.LegalAcceptance{}, "account_id", func(o *AccountOwnership) *int64 { return &o.LegalAcceptances }},
+}
+
+type AccountMergeRepositoryInterface interface {
+	CountOwned(ctx context.Context, accountID uuid.UUID) (AccountOwnership, error)
+	// MergeAccounts reassigns everything owned by source to target, soft-deletes source and
+	// writes the audit row, all in one transaction. It returns the number of rows moved.
+	MergeAccounts(ctx context.Context, sourceID, targetID uuid.UUID, audit *db_models.AccountMergeLog) (AccountOwnership, error)
+	InsertMergeLog(ctx context.Context, audit *db_models.AccountMergeLog) error
+}
+
+type AccountMergeRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountMergeRepository(db *gorm.DB) *AccountMergeRepository {
+	return &AccountMergeRepository{db: db}
+}
+
+func (r *AccountMergeRepository) CountOwned(ctx context.Context, accountID uuid.UUID) (AccountOwnership, error) {
+	var out AccountOwnership
+	db := r.db.WithContext(ctx)
+
+	for _, t := range ownedTables {
+		if err := db.Model(t.model).Where(t.column+" = ?", accountID).Count(t.count(&out)).Error; err != nil {
+			return out, err
+		}
+	}
+	if err := db.Model(&db_models.Subscription{}).
+		Where("account_id = ? AND status IN ?", accountID,
+			[]db_models.SubscriptionStatus{db_models.SubStatusActive, db_models.SubStatusTrialing}).
+		Count(&out.ActiveSubscriptions).Error; err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+func (r *AccountMergeRepository) MergeAccounts(ctx context.Context, sourceID, targetID uuid.UUID, audit *db_models.AccountMergeLog) (AccountOwnership, error) {
+	var moved AccountOwnership
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock both accounts so a concurrent merge/delete cannot interleave
+		var locked []db_models.Account
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", []uuid.UUID{sourceID, targetID}).
+			Find(&locked).Error; err != nil {
+			return err
+		}
+		if len(locked) != 2 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := resolveMergeConflicts(tx, sourceID, targetID); err != nil {
+			return err
+		}
+
+		for _, t := range ownedTables {
+			res := tx.Model(t.model).Where(t.column+" = ?", sourceID).Update(t.column, targetID)
+			if res.Error != nil {
+				return res.Error
+			}
+			*t.count(&moved) = res.RowsAffected
+		}
+
+		// The target now owns journeys it used to be a member of; owners have no member row
+		if err := tx.Exec(`DELETE FROM journey_members m USING journeys j
+			WHERE m.journey_id = j.id AND j.account_id = ? AND m.account_id = ?`, targetID, targetID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&db_models.Account{}, "id = ?", sourceID).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(audit).Error
+	})
+
+	return moved, err
+}
+
+// resolveMergeConflicts clears the source rows that would break a unique index once moved to the
+// target, keeping what they carried: the stronger journey role, the month's plan count and the
+// preset (renamed). A duplicate poll vote is dropped, the target's vote stands, and so is the
+// source's planning policy and churn score when the target has its own; the next scoring run
+// rescores the merged account. A document both accepted keeps the target's acceptance.
+func resolveMergeConflicts(tx *gorm.DB, sourceID, targetID uuid.UUID) error {
+	stmts := []string{
+		// Shared trips: both were members, keep the editor role if either had it
+		`UPDATE journey_members t SET role = s.role, updated_at = EXTRACT(EPOCH FROM now())::bigint
+			FROM journey_members s
+			WHERE s.journey_id = t.journey_id AND s.account_id = @source AND t.account_id = @target
+			AND s.role = 'editor' AND t.role <> 'editor'`,
+		`DELETE FROM journey_members s
+			WHERE s.account_id = @source AND (
+				EXISTS (SELECT 1 FROM journey_members t WHERE t.journey_id = s.journey_id AND t.account_id = @target)
+				OR EXISTS (SELECT 1 FROM journeys j WHERE j.id = s.journey_id AND j.account_id IN (@source, @target)))`,
+		`DELETE FROM journey_poll_votes s
+			WHERE s.account_id = @source
+			AND EXISTS (SELECT 1 FROM journey_poll_votes t WHERE t.poll_id = s.poll_id AND t.account_id = @target)`,
+		`UPDATE plan_usages t SET count = t.count + s.count, updated_at = EXTRACT(EPOCH FROM now())::bigint
+			FROM plan_usages s
+			WHERE s.month = t.month AND s.account_id = @source AND t.account_id = @target`,
+		`DELETE FROM plan_usages s
+			WHERE s.account_id = @source
+			AND EXISTS (SELECT 1 FROM plan_usages t WHERE t.month = s.month AND t.account_id = @target)`,
+		// Same preset name on both: the source's one gets a suffix from its id (fits the 60 chars)
+		`UPDATE travel_presets s SET name = left(s.name, 51) || ' ' || left(s.id::text, 8)
+			WHERE s.account_id = @source
+			AND EXISTS (SELECT 1 FROM travel_presets t WHERE t.name = s.name AND t.account_id = @target)`,
+		`DELETE FROM planning_policies s
+			WHERE s.account_id = @source
+			AND EXISTS (SELECT 1 FROM planning_policies t WHERE t.account_id = @target)`,
+		`DELETE FROM churn_scores s
+			WHERE s.account_id = @source
+			AND EXISTS (SELECT 1 FROM churn_scores t WHERE t.account_id = @target)`,
+		`DELETE FROM legal_acceptances s
+			WHERE s.account_id = @source
+			AND EXISTS (SELECT 1 FROM legal_acceptances t WHERE t.document_id = s.document_id AND t.account_id = @target)`,
+	}
+	args := map[string]any{"source": sourceID, "target": targetID}
+	for _, stmt := range stmts {
+		if err := tx.Exec(stmt, args).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *AccountMergeRepository) InsertMergeLog(ctx context.Context, audit *db_models.AccountMergeLog) error {
+	return r.db.WithContext(ctx).Create(audit).Error
+}
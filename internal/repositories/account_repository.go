@@ -3,7 +3,9 @@ package repositories
 import (
 	"context"
 	"errors"
+	"strings"
 
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 	"vivu/internal/models/db_models"
 )
@@ -17,8 +19,48 @@ type AccountRepository interface {
 	UpdatePasswordByEmail(ctx context.Context, email, newPasswordHash string) error
 	GetAllAccounts(ctx context.Context) ([]db_models.Account, error)
 	GetProfileInfo(ctx context.Context, accountId string) (*db_models.Account, error)
+	UpdateWorkingWindow(ctx context.Context, accountId, dayStart, dayEnd string) (bool, error)
+	UpdateCompanions(ctx context.Context, accountId string, companions []db_models.Companion) (bool, error)
+	UpdateAvoid(ctx context.Context, accountId string, avoid []string) (bool, error)
+	UpdateTripEmailsOff(ctx context.Context, accountId string, off bool) (bool, error)
+	UpdateProfile(ctx context.Context, accountId string, profile ProfileUpdate) (bool, error)
+	// UpdatePassword swaps the hash only while it still is currentHash; false when it changed meanwhile.
+	UpdatePassword(ctx context.Context, accountId, currentHash, newHash string) (bool, error)
+	// SaveTOTPSetup stores a pending 2FA secret and backup codes; 2FA stays off until EnableTOTP.
+	SaveTOTPSetup(ctx context.Context, accountId, sealedSecret string, backupCodeHashes []string) (bool, error)
+	EnableTOTP(ctx context.Context, accountId string, step int64) (bool, error)
+	// UseTOTPStep records an accepted code; false when a code of that step or a later one was used.
+	UseTOTPStep(ctx context.Context, accountId string, step int64) (bool, error)
+	// UseBackupCode removes a backup code; false when the account does not have it.
+	UseBackupCode(ctx context.Context, accountId, codeHash string) (bool, error)
+	// ListAccounts returns a page of accounts, newest first, with their active subscriptions loaded.
+	ListAccounts(ctx context.Context, filter AccountListFilter, page, pageSize int) ([]db_models.Account, int64, error)
+	// SetBanned bans (revoking every session issued until at) or unbans an account.
+	SetBanned(ctx context.Context, accountId string, banned bool, reason string, at int64) (bool, error)
+	RevokeSessions(ctx context.Context, accountId string, at int64) (bool, error)
+	// FindSessionState loads only the columns needed to accept a token: id, status and sessions_revoked_at.
+	FindSessionState(ctx context.Context, accountId string) (*db_models.Account, error)
 }
 
+// AccountListFilter narrows the admin account list; zero values mean "any".
+type AccountListFilter struct {
+	Role         string
+	Status       string
+	Subscription string // "active": with an active subscription, "none": without one
+	Search       string // part of the email or name
+}
+
+// ProfileUpdate holds the profile settings written by UpdateProfile; every field is replaced.
+type ProfileUpdate struct {
+	Name              string
+	AvatarURL         string
+	Locale            string
+	PreferredCurrency string
+}
+
+// likeEscaper keeps user input from acting as LIKE wildcards.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 type accountRepository struct {
 	db *gorm.DB
 }
@@ -58,6 +100,179 @@ func (a *accountRepository) UpdatePasswordByEmail(ctx context.Context, email, ne
 		Update("password_hash", newPasswordHash).Error
 }
 
+func (a *accountRepository) UpdateWorkingWindow(ctx context.Context, accountId, dayStart, dayEnd string) (bool, error) {
+	res := a.db.WithContext(ctx).
+		Model(&db_models.Account{}).
+		Where("id = ?", accountId).
+		Updates(map[string]any{"day_start": dayStart, "day_end": dayEnd})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (a *accountRepository) UpdateCompanions(ctx context.Context, accountId string, companions []db_models.Companion) (bool, error) {
+	// Struct update so the json serializer applies; Select writes the column even when empty
+	res := a.db.WithContext(ctx).
+		Model(&db_models.Account{}).
+		Where("id = ?", accountId).
+		Select("Companions").
+		Updates(&db_models.Account{Companions: companions})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (a *accountRepository) UpdateAvoid(ctx context.Context, accountId string, avoid []string) (bool, error) {
+	res := a.db.WithContext(ctx).
+		Model(&db_models.Account{}).
+		Where("id = ?", accountId).
+		Update("avoid", pq.StringArray(avoid))
+	return res.RowsAffected > 0, res.Error
+}
+
+func (a *accountRepository) UpdateTripEmailsOff(ctx context.Context, accountId string, off bool) (bool, error) {
+	res := a.db.WithContext(ctx).
+		Model(&db_models.Account{}).
+		Where("id = ?", accountId).
+		Update("trip_emails_off", off)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (a *accountRepository) UpdateProfile(ctx context.Context, accountId string, profile ProfileUpdate) (bool, error) {
+	res := a.db.WithContext(ctx).
+		Model(&db_models.Account{}).
+		Where("id = ?", accountId).
+		Updates(map[string]any{
+			"name":               profile.Name,
+			"avatar_url":         profile.AvatarURL,
+			"locale":             profile.Locale,
+			"preferred_currency": profile.PreferredCurrency,
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (a *accountRepository) UpdatePassword(ctx context.Context, accountId, currentHash, newHash string) (bool, error) {
+	res := a.db.WithContext(ctx).
+		Model(&db_models.Account{}).
+		Where("id = ? AND password_hash = ?", accountId, currentHash).
+		Update("password_hash", newHash)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (a *accountRepository) SaveTOTPSetup(ctx context.Context, accountId, sealedSecret string, backupCodeHashes []string) (bool, error) {
+	res := a.db.WithContext(ctx).
+		Model(&db_models.Account{}).
+		Where("id = ? AND totp_enabled = ?", accountId, false).
+		Updates(map[string]any{
+			"totp_secret":       sealedSecret,
+			"totp_last_step":    0,
+			"totp_backup_codes": pq.StringArray(backupCodeHashes),
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (a *accountRepository) EnableTOTP(ctx context.Context, accountId string, step int64) (bool, error) {
+	res := a.db.WithContext(ctx).
+		Model(&db_models.Account{}).
+		Where("id = ? AND totp_secret <> ''", accountId).
+		Updates(map[string]any{"totp_enabled": true, "totp_last_step": step})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (a *accountRepository) UseTOTPStep(ctx context.Context, accountId string, step int64) (bool, error) {
+	res := a.db.WithContext(ctx).
+		Model(&db_models.Account{}).
+		Where("id = ? AND totp_last_step < ?", accountId, step).
+		Update("totp_last_step", step)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (a *accountRepository) UseBackupCode(ctx context.Context, accountId, codeHash string) (bool, error) {
+	res := a.db.WithContext(ctx).
+		Model(&db_models.Account{}).
+		Where("id = ? AND ? = ANY(totp_backup_codes)", accountId, codeHash).
+		Update("totp_backup_codes", gorm.Expr("array_remove(totp_backup_codes, ?)", codeHash))
+	return res.RowsAffected > 0, res.Error
+}
+
+func (a *accountRepository) ListAccounts(ctx context.Context, filter AccountListFilter, page, pageSize int) ([]db_models.Account, int64, error) {
+	var (
+		accounts []db_models.Account
+		total    int64
+	)
+
+	q := a.db.WithContext(ctx).Model(&db_models.Account{})
+	if filter.Role != "" {
+		q = q.Where("role = ?", filter.Role)
+	}
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+	if filter.Search != "" {
+		like := "%" + likeEscaper.Replace(filter.Search) + "%"
+		q = q.Where("(email ILIKE ? OR name ILIKE ?)", like, like)
+	}
+	activeSub := "EXISTS (SELECT 1 FROM subscriptions s WHERE s.account_id = accounts.id AND s.status = ? AND s.deleted_at IS NULL)"
+	switch filter.Subscription {
+	case "active":
+		q = q.Where(activeSub, db_models.SubStatusActive)
+	case "none":
+		q = q.Where("NOT "+activeSub, db_models.SubStatusActive)
+	}
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := q.Preload("Subs", "status = ?", db_models.SubStatusActive).
+		Order("created_at DESC, id").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&accounts).Error
+	return accounts, total, err
+}
+
+func (a *accountRepository) SetBanned(ctx context.Context, accountId string, banned bool, reason string, at int64) (bool, error) {
+	updates := map[string]any{
+		"status":     db_models.AccountStatusActive,
+		"banned_at":  nil,
+		"ban_reason": "",
+	}
+	if banned {
+		updates = map[string]any{
+			"status":              db_models.AccountStatusBanned,
+			"banned_at":           at,
+			"ban_reason":          reason,
+			"sessions_revoked_at": at,
+		}
+	}
+	res := a.db.WithContext(ctx).
+		Model(&db_models.Account{}).
+		Where("id = ?", accountId).
+		Updates(updates)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (a *accountRepository) RevokeSessions(ctx context.Context, accountId string, at int64) (bool, error) {
+	res := a.db.WithContext(ctx).
+		Model(&db_models.Account{}).
+		Where("id = ?", accountId).
+		Update("sessions_revoked_at", at)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (a *accountRepository) FindSessionState(ctx context.Context, accountId string) (*db_models.Account, error) {
+	var account db_models.Account
+	err := a.db.WithContext(ctx).
+		Select("id", "status", "sessions_revoked_at").
+		First(&account, "id = ?", accountId).
+		Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &account, nil
+}
+
 func (a *accountRepository) UpdateAccount(account *db_models.Account, ctx context.Context) error {
 	return a.db.WithContext(ctx).Save(account).Error
 }
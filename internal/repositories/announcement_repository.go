@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"vivu/internal/models/db_models"
+)
+
+// AnnouncementAudience is what an account is targeted by.
+type AnnouncementAudience struct {
+	Locale     string `gorm:"column:locale"`
+	Subscribed bool   `gorm:"column:subscribed"`
+}
+
+type AnnouncementRepositoryInterface interface {
+	Create(ctx context.Context, a *db_models.Announcement) error
+	// Update rewrites every editable field.
+	Update(ctx context.Context, a *db_models.Announcement) (bool, error)
+	Delete(ctx context.Context, id string) (bool, error)
+	Find(ctx context.Context, id string) (*db_models.Announcement, error)
+	// List returns a page of announcements, newest start first.
+	List(ctx context.Context, page, pageSize int) ([]db_models.Announcement, int64, error)
+	// Live returns the active announcements running at now, highest priority first.
+	Live(ctx context.Context, now int64) ([]db_models.Announcement, error)
+	// Audience returns the locale of the account and whether a subscription runs at now; nil
+	// for an unknown account.
+	Audience(ctx context.Context, accountID uuid.UUID, now int64) (*AnnouncementAudience, error)
+}
+
+type AnnouncementRepository struct {
+	db *gorm.DB
+}
+
+func NewAnnouncementRepository(db *gorm.DB) *AnnouncementRepository {
+	return &AnnouncementRepository{db: db}
+}
+
+func (r *AnnouncementRepository) Create(ctx context.Context, a *db_models.Announcement) error {
+	return r.db.WithContext(ctx).Create(a).Error
+}
+
+func (r *AnnouncementRepository) Update(ctx context.Context, a *db_models.Announcement) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&db_models.Announcement{}).
+		Where("id = ?", a.ID).
+		Updates(map[string]any{
+			"kind":            a.Kind,
+			"title":           a.Title,
+			"body":            a.Body,
+			"cta_text":        a.CTAText,
+			"cta_url":         a.CTAURL,
+			"dismissible":     a.Dismissible,
+			"priority":        a.Priority,
+			"tiers":           a.Tiers,
+			"locales":         a.Locales,
+			"min_app_version": a.MinAppVersion,
+			"max_app_version": a.MaxAppVersion,
+			"active":          a.Active,
+			"starts_at":       a.StartsAt,
+			"ends_at":         a.EndsAt,
+			"updated_by":      a.UpdatedBy,
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *AnnouncementRepository) Delete(ctx context.Context, id string) (bool, error) {
+	res := r.db.WithContext(ctx).Where("id = ?", id).Delete(&db_models.Announcement{})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *AnnouncementRepository) Find(ctx context.Context, id string) (*db_models.Announcement, error) {
+	var a db_models.Announcement
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&a).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *AnnouncementRepository) List(ctx context.Context, page, pageSize int) ([]db_models.Announcement, int64, error) {
+	var (
+		rows  []db_models.Announcement
+		total int64
+	)
+	q := r.db.WithContext(ctx).Model(&db_models.Announcement{})
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := q.Order("starts_at DESC, id").Offset((page - 1) * pageSize).Limit(pageSize).Find(&rows).Error
+	return rows, total, err
+}
+
+func (r *AnnouncementRepository) Live(ctx context.Context, now int64) ([]db_models.Announcement, error) {
+	var out []db_models.Announcement
+	err := r.db.WithContext(ctx).
+		Where("active = TRUE AND starts_at <= ? AND (ends_at IS NULL OR ends_at > ?)", now, now).
+		Order("priority DESC, starts_at DESC").
+		Find(&out).Error
+	return out, err
+}
+
+func (r *AnnouncementRepository) Audience(ctx context.Context, accountID uuid.UUID, now int64) (*AnnouncementAudience, error) {
+	running := []db_models.SubscriptionStatus{db_models.SubStatusActive, db_models.SubStatusTrialing, db_models.SubStatusPastDue}
+	var out []AnnouncementAudience
+	err := r.db.WithContext(ctx).Raw(`
+SELECT a.locale,
+       EXISTS (
+           SELECT 1 FROM subscriptions s
+           WHERE s.account_id = a.id AND s.deleted_at IS NULL AND s.status IN ? AND s.starts_at <= ? AND s.ends_at > ?
+       ) AS subscribed
+FROM accounts a
+WHERE a.id = ? AND a.deleted_at IS NULL`, running, now, now, accountID).Scan(&out).Error
+	if err != nil || len(out) == 0 {
+		return nil, err
+	}
+	return &out[0], nil
+}
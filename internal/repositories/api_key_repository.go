@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"vivu/internal/models/db_models"
+)
+
+type APIKeyRepositoryInterface interface {
+	CreateKey(ctx context.Context, key *db_models.APIKey) error
+	// ListKeys returns a page of keys, newest first, with their account loaded; accountID "" lists all.
+	ListKeys(ctx context.Context, accountID string, page, pageSize int) ([]db_models.APIKey, int64, error)
+	// ListAccountKeys returns every key of an account, revoked ones included, newest first.
+	ListAccountKeys(ctx context.Context, accountID string) ([]db_models.APIKey, error)
+	FindByID(ctx context.Context, id string) (*db_models.APIKey, error)
+	// FindByHash loads a key with the id, role and status of its account.
+	FindByHash(ctx context.Context, keyHash string) (*db_models.APIKey, error)
+	// RotateKey replaces the secret of a key that is not revoked.
+	RotateKey(ctx context.Context, id, prefix, keyHash string) (bool, error)
+	RevokeKey(ctx context.Context, id string, at int64) (bool, error)
+	TouchLastUsed(ctx context.Context, id string, at int64) error
+}
+
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) CreateKey(ctx context.Context, key *db_models.APIKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+func (r *APIKeyRepository) ListKeys(ctx context.Context, accountID string, page, pageSize int) ([]db_models.APIKey, int64, error) {
+	var (
+		keys  []db_models.APIKey
+		total int64
+	)
+
+	q := r.db.WithContext(ctx).Model(&db_models.APIKey{})
+	if accountID != "" {
+		q = q.Where("account_id = ?", accountID)
+	}
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := q.
+		Preload("Account", func(db *gorm.DB) *gorm.DB { return db.Select("id", "email", "name") }).
+		Order("created_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&keys).Error
+	return keys, total, err
+}
+
+func (r *APIKeyRepository) ListAccountKeys(ctx context.Context, accountID string) ([]db_models.APIKey, error) {
+	var keys []db_models.APIKey
+	err := r.db.WithContext(ctx).
+		Where("account_id = ?", accountID).
+		Order("created_at DESC").
+		Find(&keys).Error
+	return keys, err
+}
+
+func (r *APIKeyRepository) FindByID(ctx context.Context, id string) (*db_models.APIKey, error) {
+	var key db_models.APIKey
+	err := r.db.WithContext(ctx).
+		Preload("Account", func(db *gorm.DB) *gorm.DB { return db.Select("id", "email", "name") }).
+		First(&key, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *APIKeyRepository) FindByHash(ctx context.Context, keyHash string) (*db_models.APIKey, error) {
+	var key db_models.APIKey
+	err := r.db.WithContext(ctx).
+		Preload("Account", func(db *gorm.DB) *gorm.DB { return db.Select("id", "role", "status") }).
+		First(&key, "key_hash = ?", keyHash).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *APIKeyRepository) RotateKey(ctx context.Context, id, prefix, keyHash string) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&db_models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]any{"prefix": prefix, "key_hash": keyHash, "last_used_at": nil})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *APIKeyRepository) RevokeKey(ctx context.Context, id string, at int64) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&db_models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id string, at int64) error {
+	return r.db.WithContext(ctx).Model(&db_models.APIKey{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", at).Error
+}
@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"vivu/internal/models/db_models"
+)
+
+type APIKeyUsageRepositoryInterface interface {
+	// AddUsage adds the counts of each row to the stored day of its key and fills the rows with
+	// the totals after the addition.
+	AddUsage(ctx context.Context, rows []db_models.APIKeyUsage) error
+	// DayUsage returns the stored counts of a key for day (YYYY-MM-DD), nil when there are none.
+	DayUsage(ctx context.Context, keyID uuid.UUID, day string) (*db_models.APIKeyUsage, error)
+	// ListUsage returns the stored days of the keys from from to to inclusive.
+	ListUsage(ctx context.Context, keyIDs []uuid.UUID, from, to string) ([]db_models.APIKeyUsage, error)
+}
+
+type APIKeyUsageRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyUsageRepository(db *gorm.DB) *APIKeyUsageRepository {
+	return &APIKeyUsageRepository{db: db}
+}
+
+func (r *APIKeyUsageRepository) AddUsage(ctx context.Context, rows []db_models.APIKeyUsage) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Omit("APIKey").
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "api_key_id"}, {Name: "day"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"requests":   gorm.Expr("api_key_usages.requests + EXCLUDED.requests"),
+				"errors":     gorm.Expr("api_key_usages.errors + EXCLUDED.errors"),
+				"throttled":  gorm.Expr("api_key_usages.throttled + EXCLUDED.throttled"),
+				"updated_at": gorm.Expr("EXCLUDED.updated_at"),
+			}),
+		}, clause.Returning{}).
+		Create(&rows).Error
+}
+
+func (r *APIKeyUsageRepository) DayUsage(ctx context.Context, keyID uuid.UUID, day string) (*db_models.APIKeyUsage, error) {
+	var usage db_models.APIKeyUsage
+	err := r.db.WithContext(ctx).
+		Where("api_key_id = ? AND day = ?", keyID, day).
+		First(&usage).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+func (r *APIKeyUsageRepository) ListUsage(ctx context.Context, keyIDs []uuid.UUID, from, to string) ([]db_models.APIKeyUsage, error) {
+	var rows []db_models.APIKeyUsage
+	if len(keyIDs) == 0 {
+		return rows, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("api_key_id IN ? AND day BETWEEN ? AND ?", keyIDs, from, to).
+		Order("day").
+		Find(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,195 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"vivu/internal/models/db_models"
+)
+
+// billingSubscriptionStatuses are the statuses a subscription shows on the billing screen with.
+var billingSubscriptionStatuses = []db_models.SubscriptionStatus{
+	db_models.SubStatusActive, db_models.SubStatusTrialing, db_models.SubStatusPastDue, db_models.SubStatusPaused,
+}
+
+// TransactionFilter narrows an account's payments; zero values mean "any". The range is on
+// created_at, From inclusive and To exclusive, in unix seconds.
+type TransactionFilter struct {
+	Statuses []db_models.TransactionStatus
+	From     int64
+	To       int64
+}
+
+type BillingRepositoryInterface interface {
+	// CurrentSubscription returns the subscription running at now (or paused), with its plan, or nil.
+	CurrentSubscription(ctx context.Context, accountID uuid.UUID, now int64) (*db_models.Subscription, error)
+	// QueuedSubscription returns the earliest subscription paid for that starts after now, with its plan, or nil.
+	QueuedSubscription(ctx context.Context, accountID uuid.UUID, now int64) (*db_models.Subscription, error)
+	// SetAutoRenew turns renewal of a subscription of the account on or off; canceledAt is
+	// written as given. False when the subscription is not the account's.
+	SetAutoRenew(ctx context.Context, accountID, subscriptionID uuid.UUID, autoRenew bool, canceledAt *int64) (bool, error)
+
+	// ListTransactions returns a page of the account's payments, newest first, narrowed to
+	// statuses when given, and the total count.
+	ListTransactions(ctx context.Context, accountID uuid.UUID, statuses []db_models.TransactionStatus, offset, limit int) ([]db_models.Transaction, int64, error)
+	// FilterTransactions returns a page of the account's payments matching filter, newest
+	// first, and the total count.
+	FilterTransactions(ctx context.Context, accountID uuid.UUID, filter TransactionFilter, offset, limit int) ([]db_models.Transaction, int64, error)
+	// FindTransaction returns a payment of the account, or nil.
+	FindTransaction(ctx context.Context, accountID uuid.UUID, transactionID string) (*db_models.Transaction, error)
+	// SubscriptionsByTransaction maps each transaction to the subscription it paid for.
+	SubscriptionsByTransaction(ctx context.Context, transactionIDs []uuid.UUID) (map[uuid.UUID]db_models.Subscription, error)
+
+	// PlansByID loads plans, retired and deleted ones included.
+	PlansByID(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]db_models.Plan, error)
+	ActivePlans(ctx context.Context) ([]db_models.Plan, error)
+}
+
+type BillingRepository struct {
+	db *gorm.DB
+}
+
+func NewBillingRepository(db *gorm.DB) *BillingRepository {
+	return &BillingRepository{db: db}
+}
+
+func (r *BillingRepository) CurrentSubscription(ctx context.Context, accountID uuid.UUID, now int64) (*db_models.Subscription, error) {
+	var sub db_models.Subscription
+	err := r.db.WithContext(ctx).
+		Preload("Plan", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where("account_id = ? AND status IN ?", accountID, billingSubscriptionStatuses).
+		Where("status = ? OR (starts_at <= ? AND ends_at > ?)", db_models.SubStatusPaused, now, now).
+		Order("ends_at DESC").
+		First(&sub).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (r *BillingRepository) QueuedSubscription(ctx context.Context, accountID uuid.UUID, now int64) (*db_models.Subscription, error) {
+	var sub db_models.Subscription
+	err := r.db.WithContext(ctx).
+		Preload("Plan", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where("account_id = ? AND status = ? AND starts_at > ?", accountID, db_models.SubStatusActive, now).
+		Order("starts_at ASC").
+		First(&sub).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (r *BillingRepository) SetAutoRenew(ctx context.Context, accountID, subscriptionID uuid.UUID, autoRenew bool, canceledAt *int64) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&db_models.Subscription{}).
+		Where("id = ? AND account_id = ?", subscriptionID, accountID).
+		Updates(map[string]any{"auto_renew": autoRenew, "canceled_at": canceledAt})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *BillingRepository) ListTransactions(ctx context.Context, accountID uuid.UUID, statuses []db_models.TransactionStatus, offset, limit int) ([]db_models.Transaction, int64, error) {
+	q := r.db.WithContext(ctx).Model(&db_models.Transaction{}).Where("account_id = ?", accountID)
+	if len(statuses) > 0 {
+		q = q.Where("status IN ?", statuses)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var out []db_models.Transaction
+	err := q.Order("created_at DESC").Offset(offset).Limit(limit).Find(&out).Error
+	return out, total, err
+}
+
+func (r *BillingRepository) FilterTransactions(ctx context.Context, accountID uuid.UUID, filter TransactionFilter, offset, limit int) ([]db_models.Transaction, int64, error) {
+	q := r.db.WithContext(ctx).Model(&db_models.Transaction{}).Where("account_id = ?", accountID)
+	if len(filter.Statuses) > 0 {
+		q = q.Where("status IN ?", filter.Statuses)
+	}
+	if filter.From > 0 {
+		q = q.Where("created_at >= ?", filter.From)
+	}
+	if filter.To > 0 {
+		q = q.Where("created_at < ?", filter.To)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var out []db_models.Transaction
+	err := q.Order("created_at DESC").Offset(offset).Limit(limit).Find(&out).Error
+	return out, total, err
+}
+
+func (r *BillingRepository) FindTransaction(ctx context.Context, accountID uuid.UUID, transactionID string) (*db_models.Transaction, error) {
+	var txn db_models.Transaction
+	err := r.db.WithContext(ctx).Where("id = ? AND account_id = ?", transactionID, accountID).First(&txn).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &txn, nil
+}
+
+func (r *BillingRepository) SubscriptionsByTransaction(ctx context.Context, transactionIDs []uuid.UUID) (map[uuid.UUID]db_models.Subscription, error) {
+	out := make(map[uuid.UUID]db_models.Subscription, len(transactionIDs))
+	if len(transactionIDs) == 0 {
+		return out, nil
+	}
+	ids := make([]string, 0, len(transactionIDs))
+	for _, id := range transactionIDs {
+		ids = append(ids, id.String())
+	}
+
+	// Every subscription records the payment that activated it; older payments have no subscription_id
+	var subs []db_models.Subscription
+	if err := r.db.WithContext(ctx).Unscoped().
+		Where("metadata->>'activated_by_txn' IN ?", ids).
+		Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	for _, s := range subs {
+		var meta struct {
+			ActivatedByTxn uuid.UUID `json:"activated_by_txn"`
+		}
+		if err := json.Unmarshal(s.Metadata, &meta); err == nil {
+			out[meta.ActivatedByTxn] = s
+		}
+	}
+	return out, nil
+}
+
+func (r *BillingRepository) PlansByID(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]db_models.Plan, error) {
+	out := make(map[uuid.UUID]db_models.Plan, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	var plans []db_models.Plan
+	if err := r.db.WithContext(ctx).Unscoped().Where("id IN ?", ids).Find(&plans).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range plans {
+		out[p.ID] = p
+	}
+	return out, nil
+}
+
+func (r *BillingRepository) ActivePlans(ctx context.Context) ([]db_models.Plan, error) {
+	var out []db_models.Plan
+	err := r.db.WithContext(ctx).Where("is_active = TRUE").Order("price_minor ASC").Find(&out).Error
+	return out, err
+}
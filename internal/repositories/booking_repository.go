@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"vivu/internal/models/db_models"
+)
+
+type BookingRepositoryInterface interface {
+	UpsertLink(ctx context.Context, link *db_models.POIBookingLink) error
+	ListLinksByPOI(ctx context.Context, poiID uuid.UUID) ([]db_models.POIBookingLink, error)
+	DeleteLink(ctx context.Context, poiID uuid.UUID, partner string) (bool, error)
+	CreateEvent(ctx context.Context, event *db_models.BookingEvent) error
+	// UpdateActivityBooking sets the booking fields of an activity; empty partner keeps the current one.
+	UpdateActivityBooking(ctx context.Context, activityID uuid.UUID, status, partner, reference string) error
+}
+
+type BookingRepository struct {
+	db *gorm.DB
+}
+
+func NewBookingRepository(db *gorm.DB) *BookingRepository {
+	return &BookingRepository{db: db}
+}
+
+func (r *BookingRepository) UpsertLink(ctx context.Context, link *db_models.POIBookingLink) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "poi_id"}, {Name: "partner"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"url":              link.URL,
+			"deep_link_params": gorm.Expr("excluded.deep_link_params"),
+			"updated_at":       time.Now().Unix(),
+			"deleted_at":       nil,
+		}),
+	}).Create(link).Error
+}
+
+func (r *BookingRepository) ListLinksByPOI(ctx context.Context, poiID uuid.UUID) ([]db_models.POIBookingLink, error) {
+	var links []db_models.POIBookingLink
+	err := r.db.WithContext(ctx).Where("poi_id = ?", poiID).Order("created_at ASC").Find(&links).Error
+	return links, err
+}
+
+func (r *BookingRepository) DeleteLink(ctx context.Context, poiID uuid.UUID, partner string) (bool, error) {
+	res := r.db.WithContext(ctx).Unscoped().
+		Where("poi_id = ? AND partner = ?", poiID, partner).
+		Delete(&db_models.POIBookingLink{})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *BookingRepository) CreateEvent(ctx context.Context, event *db_models.BookingEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+func (r *BookingRepository) UpdateActivityBooking(ctx context.Context, activityID uuid.UUID, status, partner, reference string) error {
+	updates := map[string]interface{}{
+		"booking_status":    status,
+		"booking_reference": reference,
+	}
+	if partner != "" {
+		updates["booking_partner"] = partner
+	}
+	res := r.db.WithContext(ctx).Model(&db_models.JourneyActivity{}).Where("id = ?", activityID).Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"vivu/internal/models/db_models"
+)
+
+// ChurnSignals is what a churn score is computed from, for one account.
+type ChurnSignals struct {
+	AccountID      uuid.UUID `gorm:"column:account_id"`
+	CreatedAt      int64     `gorm:"column:created_at"`
+	LastJourneyAt  *int64    `gorm:"column:last_journey_at"`
+	RecentJourneys int       `gorm:"column:recent_journeys"`
+	PriorJourneys  int       `gorm:"column:prior_journeys"`
+	AutoRenewOff   bool      `gorm:"column:auto_renew_off"`
+}
+
+type ChurnSegmentCount struct {
+	Segment   string `gorm:"column:segment"`
+	Accounts  int64  `gorm:"column:accounts"`
+	InWinback int64  `gorm:"column:in_winback"`
+	ScoredAt  int64  `gorm:"column:scored_at"`
+}
+
+type ChurnRepositoryInterface interface {
+	// Signals returns the signals of up to limit active user accounts with an ID after afterID,
+	// in ID order: journeys created since recentFrom and between priorFrom and recentFrom, and
+	// whether a subscription running at now will not renew.
+	Signals(ctx context.Context, afterID uuid.UUID, limit int, now, recentFrom, priorFrom int64) ([]ChurnSignals, error)
+	// SaveScores writes the scores, keeping the win-back progress of accounts already scored.
+	SaveScores(ctx context.Context, scores []db_models.ChurnScore) error
+	// DeleteStale removes the scores no run rewrote since before: deleted or banned accounts.
+	DeleteStale(ctx context.Context, before int64) error
+	// ResetWinback forgets the sequence of accounts back to low risk.
+	ResetWinback(ctx context.Context) error
+	// StartWinback starts the campaign of segment for its accounts without a sequence, the
+	// first step due at firstAt; returns how many started.
+	StartWinback(ctx context.Context, segment string, now, firstAt int64) (int64, error)
+	// ClaimWinbackDue returns scores whose next win-back step is due, with their account, and
+	// pushes that step back by lease so other workers leave them alone.
+	ClaimWinbackDue(ctx context.Context, now int64, limit int, lease time.Duration) ([]db_models.ChurnScore, error)
+	// AdvanceWinback records the step sent at sentAt; nextAt nil ends the sequence.
+	AdvanceWinback(ctx context.Context, id uuid.UUID, nextStep int, nextAt *int64, sentAt int64) error
+	// StopWinback ends a sequence without sending anything more.
+	StopWinback(ctx context.Context, id uuid.UUID) error
+
+	SegmentCounts(ctx context.Context) ([]ChurnSegmentCount, error)
+	// ListScores returns a page of scores, riskiest first, with their account.
+	ListScores(ctx context.Context, segment string, page, pageSize int) ([]db_models.ChurnScore, int64, error)
+
+	ListCampaigns(ctx context.Context) ([]db_models.WinbackCampaign, error)
+	UpsertCampaign(ctx context.Context, campaign *db_models.WinbackCampaign) error
+	FindCampaign(ctx context.Context, segment string) (*db_models.WinbackCampaign, error)
+}
+
+type ChurnRepository struct {
+	db *gorm.DB
+}
+
+func NewChurnRepository(db *gorm.DB) *ChurnRepository {
+	return &ChurnRepository{db: db}
+}
+
+func (r *ChurnRepository) Signals(ctx context.Context, afterID uuid.UUID, limit int, now, recentFrom, priorFrom int64) ([]ChurnSignals, error) {
+	running := []db_models.SubscriptionStatus{db_models.SubStatusActive, db_models.SubStatusTrialing, db_models.SubStatusPastDue}
+	var out []ChurnSignals
+	err := r.db.WithContext(ctx).Raw(`
+SELECT a.id AS account_id, a.created_at,
+       j.last_journey_at,
+       COALESCE(j.recent, 0) AS recent_journeys,
+       COALESCE(j.prior, 0) AS prior_journeys,
+       EXISTS (
+           SELECT 1 FROM subscriptions s
+           WHERE s.account_id = a.id AND s.deleted_at IS NULL AND s.status IN ?
+             AND s.starts_at <= ? AND s.ends_at > ? AND s.auto_renew = FALSE
+             -- a renewal already paid for keeps the account subscribed
+             AND NOT EXISTS (
+                 SELECT 1 FROM subscriptions q
+                 WHERE q.account_id = a.id AND q.deleted_at IS NULL AND q.status = ? AND q.starts_at >= s.ends_at
+             )
+       ) AS auto_renew_off
+FROM accounts a
+LEFT JOIN LATERAL (
+    SELECT MAX(created_at) AS last_journey_at,
+           COUNT(*) FILTER (WHERE created_at >= ?) AS recent,
+           COUNT(*) FILTER (WHERE created_at >= ? AND created_at < ?) AS prior
+    FROM journeys
+    WHERE account_id = a.id AND deleted_at IS NULL
+) j ON TRUE
+WHERE a.deleted_at IS NULL AND a.status = ? AND a.role = 'user' AND a.id > ?
+ORDER BY a.id
+LIMIT ?`,
+		running, now, now, db_models.SubStatusActive,
+		recentFrom, priorFrom, recentFrom,
+		db_models.AccountStatusActive, afterID, limit,
+	).Scan(&out).Error
+	return out, err
+}
+
+func (r *ChurnRepository) SaveScores(ctx context.Context, scores []db_models.ChurnScore) error {
+	if len(scores) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Omit("Account").Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "account_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"score", "segment", "reasons", "last_journey_at", "recent_journeys", "prior_journeys",
+			"auto_renew_off", "scored_at", "updated_at", "deleted_at",
+		}),
+	}).Create(&scores).Error
+}
+
+func (r *ChurnRepository) DeleteStale(ctx context.Context, before int64) error {
+	return r.db.WithContext(ctx).Unscoped().Where("scored_at < ?", before).Delete(&db_models.ChurnScore{}).Error
+}
+
+func (r *ChurnRepository) ResetWinback(ctx context.Context) error {
+	return r.db.WithContext(ctx).Model(&db_models.ChurnScore{}).
+		Where("segment = ? AND winback_started_at IS NOT NULL", db_models.ChurnRiskLow).
+		Updates(map[string]any{
+			"winback_segment":    "",
+			"winback_started_at": nil,
+			"winback_step":       0,
+			"winback_next_at":    nil,
+		}).Error
+}
+
+func (r *ChurnRepository) StartWinback(ctx context.Context, segment string, now, firstAt int64) (int64, error) {
+	res := r.db.WithContext(ctx).Model(&db_models.ChurnScore{}).
+		Where("segment = ? AND winback_started_at IS NULL", segment).
+		Updates(map[string]any{
+			"winback_segment":    segment,
+			"winback_started_at": now,
+			"winback_step":       0,
+			"winback_next_at":    firstAt,
+		})
+	return res.RowsAffected, res.Error
+}
+
+func (r *ChurnRepository) ClaimWinbackDue(ctx context.Context, now int64, limit int, lease time.Duration) ([]db_models.ChurnScore, error) {
+	var rows []db_models.ChurnScore
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Rows locked by a concurrent claim are skipped, not waited for
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("winback_next_at <= ?", now).
+			Order("winback_next_at").
+			Limit(limit).
+			Find(&rows).Error
+		if err != nil || len(rows) == 0 {
+			return err
+		}
+		ids := make([]uuid.UUID, len(rows))
+		for i := range rows {
+			ids[i] = rows[i].ID
+		}
+		return tx.Model(&db_models.ChurnScore{}).
+			Where("id IN ?", ids).
+			Update("winback_next_at", time.Unix(now, 0).Add(lease).Unix()).Error
+	})
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+
+	accountIDs := make([]uuid.UUID, len(rows))
+	for i := range rows {
+		accountIDs[i] = rows[i].AccountID
+	}
+	var accounts []db_models.Account
+	if err := r.db.WithContext(ctx).Unscoped().Where("id IN ?", accountIDs).Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]db_models.Account, len(accounts))
+	for _, a := range accounts {
+		byID[a.ID] = a
+	}
+	for i := range rows {
+		rows[i].Account = byID[rows[i].AccountID]
+	}
+	return rows, nil
+}
+
+func (r *ChurnRepository) AdvanceWinback(ctx context.Context, id uuid.UUID, nextStep int, nextAt *int64, sentAt int64) error {
+	return r.db.WithContext(ctx).Model(&db_models.ChurnScore{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"winback_step":    nextStep,
+			"winback_next_at": nextAt,
+			"winback_last_at": sentAt,
+		}).Error
+}
+
+func (r *ChurnRepository) StopWinback(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&db_models.ChurnScore{}).Where("id = ?", id).Update("winback_next_at", nil).Error
+}
+
+func (r *ChurnRepository) SegmentCounts(ctx context.Context) ([]ChurnSegmentCount, error) {
+	var out []ChurnSegmentCount
+	err := r.db.WithContext(ctx).Model(&db_models.ChurnScore{}).
+		Select("segment, COUNT(*) AS accounts, COUNT(*) FILTER (WHERE winback_next_at IS NOT NULL) AS in_winback, MAX(scored_at) AS scored_at").
+		Group("segment").
+		Scan(&out).Error
+	return out, err
+}
+
+func (r *ChurnRepository) ListScores(ctx context.Context, segment string, page, pageSize int) ([]db_models.ChurnScore, int64, error) {
+	var (
+		rows  []db_models.ChurnScore
+		total int64
+	)
+	q := r.db.WithContext(ctx).Model(&db_models.ChurnScore{})
+	if segment != "" {
+		q = q.Where("segment = ?", segment)
+	}
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := q.Preload("Account").
+		Order("score DESC, account_id").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&rows).Error
+	return rows, total, err
+}
+
+func (r *ChurnRepository) ListCampaigns(ctx context.Context) ([]db_models.WinbackCampaign, error) {
+	var out []db_models.WinbackCampaign
+	err := r.db.WithContext(ctx).Order("segment").Find(&out).Error
+	return out, err
+}
+
+func (r *ChurnRepository) UpsertCampaign(ctx context.Context, campaign *db_models.WinbackCampaign) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "segment"}},
+		DoUpdates: clause.AssignmentColumns([]string{"active", "steps", "updated_by", "updated_at"}),
+	}).Create(campaign).Error
+}
+
+func (r *ChurnRepository) FindCampaign(ctx context.Context, segment string) (*db_models.WinbackCampaign, error) {
+	var c db_models.WinbackCampaign
+	err := r.db.WithContext(ctx).Where("segment = ?", segment).First(&c).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"vivu/internal/models/db_models"
+)
+
+type CollectionRepositoryInterface interface {
+	// ListCollections returns a page of collections, newest first, and the total count. Unpublished
+	// ones are included only when published is false.
+	ListCollections(ctx context.Context, published bool, provinceID *uuid.UUID, offset, limit int) ([]db_models.Collection, int64, error)
+	// ItemCounts counts the live POIs of each collection.
+	ItemCounts(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]int, error)
+	// FindCollection looks a collection up by ID or slug, with its province and its items in
+	// order with their POIs; nil when there is none.
+	FindCollection(ctx context.Context, idOrSlug string) (*db_models.Collection, error)
+	// SaveCollection creates or updates the collection and replaces its items with the given ones.
+	SaveCollection(ctx context.Context, collection *db_models.Collection, items []db_models.CollectionItem) error
+	DeleteCollection(ctx context.Context, id uuid.UUID) (bool, error)
+}
+
+type CollectionRepository struct {
+	db *gorm.DB
+}
+
+func NewCollectionRepository(db *gorm.DB) *CollectionRepository {
+	return &CollectionRepository{db: db}
+}
+
+func (r *CollectionRepository) ListCollections(ctx context.Context, published bool, provinceID *uuid.UUID, offset, limit int) ([]db_models.Collection, int64, error) {
+	q := r.db.WithContext(ctx).Model(&db_models.Collection{})
+	if published {
+		q = q.Where("is_published = TRUE")
+	}
+	if provinceID != nil {
+		q = q.Where("province_id = ?", *provinceID)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var out []db_models.Collection
+	err := q.Preload("Province").Order("created_at DESC").Offset(offset).Limit(limit).Find(&out).Error
+	return out, total, err
+}
+
+func (r *CollectionRepository) ItemCounts(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]int, error) {
+	out := make(map[uuid.UUID]int, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	var rows []struct {
+		CollectionID uuid.UUID
+		N            int
+	}
+	err := r.db.WithContext(ctx).Model(&db_models.CollectionItem{}).
+		Select("collection_items.collection_id, COUNT(*) AS n").
+		Joins("JOIN pois p ON p.id = collection_items.poi_id AND p.deleted_at IS NULL").
+		Where("collection_items.collection_id IN ?", ids).
+		Group("collection_items.collection_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		out[row.CollectionID] = row.N
+	}
+	return out, nil
+}
+
+func (r *CollectionRepository) FindCollection(ctx context.Context, idOrSlug string) (*db_models.Collection, error) {
+	q := r.db.WithContext(ctx).
+		Preload("Province").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		Preload("Items.POI").
+		Preload("Items.POI.Details").
+		Preload("Items.POI.Category").
+		Preload("Items.POI.ExternalRefs")
+	if id, err := uuid.Parse(idOrSlug); err == nil {
+		q = q.Where("id = ?", id)
+	} else {
+		q = q.Where("slug = ?", idOrSlug)
+	}
+
+	var out db_models.Collection
+	err := q.First(&out).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *CollectionRepository) SaveCollection(ctx context.Context, collection *db_models.Collection, items []db_models.CollectionItem) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Items", "Province").Save(collection).Error; err != nil {
+			return err
+		}
+		// Items are rewritten in full: the order is the whole point of them
+		if err := tx.Unscoped().Where("collection_id = ?", collection.ID).Delete(&db_models.CollectionItem{}).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		for i := range items {
+			items[i].CollectionID = collection.ID
+		}
+		return tx.Omit("POI").Create(&items).Error
+	})
+}
+
+// DeleteCollection removes the collection for good, items included, so its slug can be used again.
+func (r *CollectionRepository) DeleteCollection(ctx context.Context, id uuid.UUID) (bool, error) {
+	res := r.db.WithContext(ctx).Unscoped().Where("id = ?", id).Delete(&db_models.Collection{})
+	return res.RowsAffected > 0, res.Error
+}
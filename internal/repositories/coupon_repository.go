@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"vivu/internal/models/db_models"
+)
+
+type CouponRepositoryInterface interface {
+	// FindByCode returns the coupon with the (upper case) code, active or not, or nil.
+	FindByCode(ctx context.Context, code string) (*db_models.Coupon, error)
+	List(ctx context.Context) ([]db_models.Coupon, error)
+	CodeExists(ctx context.Context, code string) (bool, error)
+	Create(ctx context.Context, coupon *db_models.Coupon) error
+}
+
+type CouponRepository struct {
+	db *gorm.DB
+}
+
+func NewCouponRepository(db *gorm.DB) *CouponRepository {
+	return &CouponRepository{db: db}
+}
+
+func (r *CouponRepository) FindByCode(ctx context.Context, code string) (*db_models.Coupon, error) {
+	var c db_models.Coupon
+	err := r.db.WithContext(ctx).Where("code = ?", code).First(&c).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *CouponRepository) List(ctx context.Context) ([]db_models.Coupon, error) {
+	var out []db_models.Coupon
+	err := r.db.WithContext(ctx).Order("is_active DESC, created_at DESC").Find(&out).Error
+	return out, err
+}
+
+func (r *CouponRepository) CodeExists(ctx context.Context, code string) (bool, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Unscoped().Model(&db_models.Coupon{}).Where("code = ?", code).Count(&n).Error
+	return n > 0, err
+}
+
+func (r *CouponRepository) Create(ctx context.Context, coupon *db_models.Coupon) error {
+	return r.db.WithContext(ctx).Create(coupon).Error
+}
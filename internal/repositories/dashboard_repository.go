@@ -19,6 +19,8 @@ type DashboardRepository interface {
 	CountSubscriptionsByStatus(ctx context.Context, status dbm.SubscriptionStatus) (int64, error)
 	CountCanceledInPeriod(ctx context.Context, start, end time.Time) (int64, error)
 	CountSubscribersAt(ctx context.Context, t time.Time) (int64, error)
+	// CountCreatedUntil counts the live rows of model created at or before t.
+	CountCreatedUntil(ctx context.Context, model any, t time.Time) (int64, error)
 
 	// Time series
 	RevenueSeries(ctx context.Context, start, end time.Time, interval, tz string) ([]BucketSum, error)
@@ -27,6 +29,9 @@ type DashboardRepository interface {
 
 	// MRR compute helpers
 	ActiveSubscriptionsWithPlan(ctx context.Context) ([]SubWithPlan, error)
+	// SubscriptionsWithPlanAt returns the subscriptions whose period covered t, whatever their
+	// status is now.
+	SubscriptionsWithPlanAt(ctx context.Context, t time.Time) ([]SubWithPlan, error)
 
 	// Plan mix (active subs)
 	PlanMix(ctx context.Context) ([]PlanMixRow, error)
@@ -36,8 +41,27 @@ type DashboardRepository interface {
 
 	// Recent payments
 	RecentPaidTransactions(ctx context.Context, limit int) ([]RecentPaymentRow, error)
+
+	// Plan changes applied in the period, per pair of plans
+	PlanChanges(ctx context.Context, start, end time.Time) ([]PlanChangeRow, error)
+
+	// Cohorts: accounts signed up in [start, end) per signup month in tz, and how many of them
+	// were retained in each month from their signup month to the month of end
+	SignupCohorts(ctx context.Context, start, end time.Time, tz string) ([]BucketSum, error)
+	CohortRetention(ctx context.Context, start, end time.Time, tz string, by CohortActivity) ([]CohortCellRow, error)
 }
 
+// CohortActivity is what keeps an account in its cohort for a month.
+type CohortActivity string
+
+const (
+	// CohortBySubscription retains the accounts with a subscription whose period overlapped the
+	// month, whatever its status is now
+	CohortBySubscription CohortActivity = "subscription"
+	// CohortByJourneys retains the accounts that created a journey in the month
+	CohortByJourneys CohortActivity = "journeys"
+)
+
 type dashboardRepository struct {
 	db *gorm.DB
 }
@@ -85,6 +109,21 @@ type RecentPaymentRow struct {
 	AccountEmail  string     `gorm:"column:email"`
 }
 
+type PlanChangeRow struct {
+	FromPlanCode  string `gorm:"column:from_plan_code"`
+	ToPlanCode    string `gorm:"column:to_plan_code"`
+	Direction     string `gorm:"column:direction"`
+	Count         int64  `gorm:"column:count"`
+	ChargedMinor  int64  `gorm:"column:charged_minor"`
+	CreditedMinor int64  `gorm:"column:credited_minor"`
+}
+
+type CohortCellRow struct {
+	Cohort   time.Time `gorm:"column:cohort"` // signup month, local to the tz of the query
+	Month    time.Time `gorm:"column:month"`
+	Retained int64     `gorm:"column:retained"`
+}
+
 // ---------- Helpers ----------
 func dateTrunc(interval, tz string, unixColumn string) string {
 	// unixColumn is a column holding UNIX seconds (e.g., paid_at, created_at)
@@ -153,6 +192,15 @@ func (r *dashboardRepository) CountSubscribersAt(ctx context.Context, t time.Tim
 	return n, err
 }
 
+func (r *dashboardRepository) CountCreatedUntil(ctx context.Context, model any, t time.Time) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).
+		Model(model).
+		Where("created_at <= ?", t.Unix()).
+		Count(&n).Error
+	return n, err
+}
+
 // ---------- Series ----------
 func (r *dashboardRepository) RevenueSeries(ctx context.Context, start, end time.Time, interval, tz string) ([]BucketSum, error) {
 	var rows []BucketSum
@@ -210,6 +258,17 @@ func (r *dashboardRepository) ActiveSubscriptionsWithPlan(ctx context.Context) (
 	return rows, err
 }
 
+func (r *dashboardRepository) SubscriptionsWithPlanAt(ctx context.Context, t time.Time) ([]SubWithPlan, error) {
+	var rows []SubWithPlan
+	err := r.db.WithContext(ctx).
+		Table("subscriptions s").
+		Select("s.id AS sub_id, s.plan_id, p.period, p.price_minor, s.status").
+		Joins("JOIN plans p ON p.id = s.plan_id").
+		Where("s.starts_at <= ? AND s.ends_at >= ?", t.Unix(), t.Unix()).
+		Find(&rows).Error
+	return rows, err
+}
+
 // ---------- Plan mix ----------
 func (r *dashboardRepository) PlanMix(ctx context.Context) ([]PlanMixRow, error) {
 	var rows []PlanMixRow
@@ -270,3 +329,88 @@ func (r *dashboardRepository) RecentPaidTransactions(ctx context.Context, limit
 		Find(&rows).Error
 	return rows, err
 }
+
+func (r *dashboardRepository) PlanChanges(ctx context.Context, start, end time.Time) ([]PlanChangeRow, error) {
+	var rows []PlanChangeRow
+	err := r.db.WithContext(ctx).
+		Table("subscription_plan_changes c").
+		Select(`
+			fp.code AS from_plan_code,
+			tp.code AS to_plan_code,
+			c.direction,
+			COUNT(*) AS count,
+			COALESCE(SUM(c.charge_minor), 0) AS charged_minor,
+			COALESCE(SUM(c.credit_minor), 0) AS credited_minor`).
+		Joins("JOIN plans fp ON fp.id = c.from_plan_id").
+		Joins("JOIN plans tp ON tp.id = c.to_plan_id").
+		Where("c.deleted_at IS NULL").
+		Where("c.status = ?", dbm.PlanChangeApplied).
+		Where("c.applied_at BETWEEN ? AND ?", start.Unix(), end.Unix()).
+		Group("fp.code, tp.code, c.direction").
+		Order("count DESC").
+		Find(&rows).Error
+	return rows, err
+}
+
+// ---------- Cohorts ----------
+// Deleted accounts are left out of the cohorts: merges and erasures delete accounts too, and
+// counting them would read as churn.
+func (r *dashboardRepository) SignupCohorts(ctx context.Context, start, end time.Time, tz string) ([]BucketSum, error) {
+	var rows []BucketSum
+	if tz == "" {
+		tz = "UTC"
+	}
+	err := r.db.WithContext(ctx).
+		Model(&dbm.Account{}).
+		Select(dateTrunc("month", tz, "created_at")+" AS bucket, COUNT(*) AS sum", "month", tz).
+		Where("created_at >= ? AND created_at < ?", start.Unix(), end.Unix()).
+		Group("bucket").
+		Order("bucket ASC").
+		Find(&rows).Error
+	return rows, err
+}
+
+func (r *dashboardRepository) CohortRetention(ctx context.Context, start, end time.Time, tz string, by CohortActivity) ([]CohortCellRow, error) {
+	if tz == "" {
+		tz = "UTC"
+	}
+	var retained string
+	switch by {
+	case CohortByJourneys:
+		retained = `SELECT 1 FROM journeys j
+           WHERE j.account_id = c.id AND j.deleted_at IS NULL
+             AND j.created_at >= p.starts_at AND j.created_at < p.ends_at`
+	default:
+		retained = `SELECT 1 FROM subscriptions s
+           WHERE s.account_id = c.id AND s.deleted_at IS NULL
+             AND s.starts_at < p.ends_at AND s.ends_at >= p.starts_at`
+	}
+
+	var rows []CohortCellRow
+	// The months are local to tz; starts_at and ends_at bound each of them in UNIX seconds
+	err := r.db.WithContext(ctx).Raw(`
+WITH cohort AS (
+    SELECT a.id, date_trunc('month', timezone(?, to_timestamp(a.created_at))) AS month
+    FROM accounts a
+    WHERE a.deleted_at IS NULL AND a.created_at >= ? AND a.created_at < ?
+), period AS (
+    SELECT m AS month,
+           extract(epoch FROM timezone(?, m))::bigint AS starts_at,
+           extract(epoch FROM timezone(?, m + interval '1 month'))::bigint AS ends_at
+    FROM generate_series(
+        date_trunc('month', timezone(?, to_timestamp(?))),
+        date_trunc('month', timezone(?, to_timestamp(?))),
+        interval '1 month') AS m
+)
+SELECT c.month AS cohort, p.month, COUNT(*) AS retained
+FROM cohort c
+JOIN period p ON p.month >= c.month
+WHERE EXISTS (`+retained+`)
+GROUP BY c.month, p.month
+ORDER BY c.month, p.month`,
+		tz, start.Unix(), end.Unix(),
+		tz, tz,
+		tz, start.Unix(), tz, end.Unix()).
+		Scan(&rows).Error
+	return rows, err
+}
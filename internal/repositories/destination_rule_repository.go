@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"vivu/internal/models/db_models"
+)
+
+type DestinationRuleRepositoryInterface interface {
+	ListRules(ctx context.Context) ([]db_models.DestinationRule, error)
+	FindByProvinceIDs(ctx context.Context, provinceIDs []string) ([]db_models.DestinationRule, error)
+	// FindByPOIIDs returns the rules of every province the given POIs belong to.
+	FindByPOIIDs(ctx context.Context, poiIDs []string) ([]db_models.DestinationRule, error)
+	UpsertRule(ctx context.Context, rule *db_models.DestinationRule) error
+	DeleteByProvinceID(ctx context.Context, provinceID string) (bool, error)
+}
+
+type DestinationRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewDestinationRuleRepository(db *gorm.DB) *DestinationRuleRepository {
+	return &DestinationRuleRepository{db: db}
+}
+
+func (r *DestinationRuleRepository) ListRules(ctx context.Context) ([]db_models.DestinationRule, error) {
+	var out []db_models.DestinationRule
+	err := r.db.WithContext(ctx).Preload("Province").Order("created_at ASC").Find(&out).Error
+	return out, err
+}
+
+func (r *DestinationRuleRepository) FindByProvinceIDs(ctx context.Context, provinceIDs []string) ([]db_models.DestinationRule, error) {
+	var out []db_models.DestinationRule
+	if len(provinceIDs) == 0 {
+		return out, nil
+	}
+	err := r.db.WithContext(ctx).Preload("Province").Where("province_id IN ?", provinceIDs).Find(&out).Error
+	return out, err
+}
+
+func (r *DestinationRuleRepository) FindByPOIIDs(ctx context.Context, poiIDs []string) ([]db_models.DestinationRule, error) {
+	var out []db_models.DestinationRule
+	if len(poiIDs) == 0 {
+		return out, nil
+	}
+	sub := r.db.Model(&db_models.POI{}).Select("province_id").Where("id IN ?", poiIDs)
+	err := r.db.WithContext(ctx).Preload("Province").Where("province_id IN (?)", sub).Find(&out).Error
+	return out, err
+}
+
+func (r *DestinationRuleRepository) UpsertRule(ctx context.Context, rule *db_models.DestinationRule) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "province_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"max_activities_per_day", "forbidden_windows", "buffer_minutes", "note", "updated_by", "updated_at", "deleted_at",
+		}),
+	}).Create(rule).Error
+}
+
+func (r *DestinationRuleRepository) DeleteByProvinceID(ctx context.Context, provinceID string) (bool, error) {
+	res := r.db.WithContext(ctx).Where("province_id = ?", provinceID).Delete(&db_models.DestinationRule{})
+	return res.RowsAffected > 0, res.Error
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"vivu/internal/models/db_models"
+)
+
+type DiagnosticsRepositoryInterface interface {
+	ListSlowQueries(ctx context.Context, page, pageSize int, minDurationMs int64) ([]db_models.QueryDiagnostic, int64, error)
+	DeleteSlowQuery(ctx context.Context, id string) error
+}
+
+type DiagnosticsRepository struct {
+	db *gorm.DB
+}
+
+func NewDiagnosticsRepository(db *gorm.DB) *DiagnosticsRepository {
+	return &DiagnosticsRepository{db: db}
+}
+
+func (r *DiagnosticsRepository) ListSlowQueries(ctx context.Context, page, pageSize int, minDurationMs int64) ([]db_models.QueryDiagnostic, int64, error) {
+	var (
+		rows  []db_models.QueryDiagnostic
+		total int64
+	)
+
+	q := r.db.WithContext(ctx).Model(&db_models.QueryDiagnostic{})
+	if minDurationMs > 0 {
+		q = q.Where("duration_ms >= ?", minDurationMs)
+	}
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := q.Order("duration_ms DESC").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&rows).Error
+	return rows, total, err
+}
+
+func (r *DiagnosticsRepository) DeleteSlowQuery(ctx context.Context, id string) error {
+	res := r.db.WithContext(ctx).Unscoped().Where("id = ?", id).Delete(&db_models.QueryDiagnostic{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"vivu/internal/models/db_models"
+)
+
+type DistanceCacheRepositoryInterface interface {
+	// FindEdges returns non-expired edges between any two of the given POIs for a mode.
+	FindEdges(ctx context.Context, mode string, poiIDs []string, now int64) ([]db_models.PoiDistanceCache, error)
+	UpsertEdges(ctx context.Context, rows []db_models.PoiDistanceCache) error
+	DeleteExpired(ctx context.Context, now int64) (int64, error)
+}
+
+type DistanceCacheRepository struct {
+	db *gorm.DB
+}
+
+func NewDistanceCacheRepository(db *gorm.DB) *DistanceCacheRepository {
+	return &DistanceCacheRepository{db: db}
+}
+
+func (r *DistanceCacheRepository) FindEdges(ctx context.Context, mode string, poiIDs []string, now int64) ([]db_models.PoiDistanceCache, error) {
+	var rows []db_models.PoiDistanceCache
+	if len(poiIDs) == 0 {
+		return rows, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("mode = ? AND from_poi IN ? AND to_poi IN ? AND expires_at > ?", mode, poiIDs, poiIDs, now).
+		Find(&rows).Error
+	return rows, err
+}
+
+func (r *DistanceCacheRepository) UpsertEdges(ctx context.Context, rows []db_models.PoiDistanceCache) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "mode"}, {Name: "from_poi"}, {Name: "to_poi"}},
+		DoUpdates: clause.AssignmentColumns([]string{"distance_meters", "duration_seconds", "expires_at"}),
+	}).CreateInBatches(rows, 500).Error
+}
+
+func (r *DistanceCacheRepository) DeleteExpired(ctx context.Context, now int64) (int64, error) {
+	res := r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&db_models.PoiDistanceCache{})
+	return res.RowsAffected, res.Error
+}
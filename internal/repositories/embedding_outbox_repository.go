@@ -0,0 +1,189 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"vivu/internal/models/db_models"
+)
+
+type EmbeddingOutboxRepositoryInterface interface {
+	// PendingBatch claims every pending outbox row of the oldest `limit` queued POIs for lease,
+	// skipping rows that already failed maxAttempts times and rows another worker holds.
+	PendingBatch(ctx context.Context, limit, maxAttempts int, lease time.Duration) ([]db_models.POIEmbeddingOutbox, error)
+	MarkProcessed(ctx context.Context, ids []uuid.UUID) error
+	MarkFailed(ctx context.Context, ids []uuid.UUID, reason string) error
+	// EnqueueAll queues every live POI not already waiting in the outbox and returns how many were queued.
+	EnqueueAll(ctx context.Context, reason string) (int64, error)
+	// EnqueueStale queues live POIs whose embedding is missing or older than contentVersion,
+	// skipping those already waiting in the outbox.
+	EnqueueStale(ctx context.Context, contentVersion int, reason string) (int64, error)
+
+	// LoadPOIs returns the live POIs among ids with what the embedding text needs.
+	LoadPOIs(ctx context.Context, ids []uuid.UUID) ([]db_models.POI, error)
+	// ReviewNotes returns the non-empty check-in notes of each POI.
+	ReviewNotes(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID][]string, error)
+	UpsertEmbeddings(ctx context.Context, rows []db_models.PoiEmbedding) error
+	DeleteEmbeddings(ctx context.Context, poiIDs []string) error
+}
+
+type EmbeddingOutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewEmbeddingOutboxRepository(db *gorm.DB) *EmbeddingOutboxRepository {
+	return &EmbeddingOutboxRepository{db: db}
+}
+
+// enqueueEmbedding queues a POI for re-embedding inside the caller's transaction.
+func enqueueEmbedding(tx *gorm.DB, poiID uuid.UUID, reason string) error {
+	return tx.Create(&db_models.POIEmbeddingOutbox{POIID: poiID, Reason: reason}).Error
+}
+
+func (r *EmbeddingOutboxRepository) PendingBatch(ctx context.Context, limit, maxAttempts int, lease time.Duration) ([]db_models.POIEmbeddingOutbox, error) {
+	var rows []db_models.POIEmbeddingOutbox
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().Unix()
+		claimable := func(db *gorm.DB) *gorm.DB {
+			return db.Where("processed_at IS NULL AND attempts < ?", maxAttempts).
+				Where("claimed_until IS NULL OR claimed_until < ?", now)
+		}
+		oldest := tx.Model(&db_models.POIEmbeddingOutbox{}).
+			Scopes(claimable).
+			Select("poi_id").
+			Group("poi_id").
+			Order("MIN(created_at)").
+			Limit(limit)
+
+		// Rows locked by a concurrent claim are skipped, not waited for
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Scopes(claimable).
+			Where("poi_id IN (?)", oldest).
+			Find(&rows).Error
+		if err != nil || len(rows) == 0 {
+			return err
+		}
+		ids := make([]uuid.UUID, len(rows))
+		for i := range rows {
+			ids[i] = rows[i].ID
+		}
+		return tx.Model(&db_models.POIEmbeddingOutbox{}).
+			Where("id IN ?", ids).
+			Update("claimed_until", time.Now().Add(lease).Unix()).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *EmbeddingOutboxRepository) MarkProcessed(ctx context.Context, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Model(&db_models.POIEmbeddingOutbox{}).
+		Where("id IN ?", ids).
+		Updates(map[string]any{"processed_at": time.Now().Unix(), "last_error": "", "claimed_until": nil}).Error
+}
+
+func (r *EmbeddingOutboxRepository) MarkFailed(ctx context.Context, ids []uuid.UUID, reason string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Model(&db_models.POIEmbeddingOutbox{}).
+		Where("id IN ?", ids).
+		Updates(map[string]any{"attempts": gorm.Expr("attempts + 1"), "last_error": reason, "claimed_until": nil}).Error
+}
+
+func (r *EmbeddingOutboxRepository) EnqueueAll(ctx context.Context, reason string) (int64, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Model(&db_models.POI{}).
+		Where("NOT EXISTS (SELECT 1 FROM poi_embedding_outbox o WHERE o.poi_id = pois.id AND o.processed_at IS NULL)").
+		Pluck("pois.id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	rows := make([]db_models.POIEmbeddingOutbox, 0, len(ids))
+	for _, id := range ids {
+		rows = append(rows, db_models.POIEmbeddingOutbox{POIID: id, Reason: reason})
+	}
+	if err := r.db.WithContext(ctx).CreateInBatches(rows, 500).Error; err != nil {
+		return 0, err
+	}
+	return int64(len(rows)), nil
+}
+
+func (r *EmbeddingOutboxRepository) EnqueueStale(ctx context.Context, contentVersion int, reason string) (int64, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Model(&db_models.POI{}).
+		Where("NOT EXISTS (SELECT 1 FROM poi_embeddings e WHERE e.poi_id = pois.id::text AND e.content_version >= ?)", contentVersion).
+		Where("NOT EXISTS (SELECT 1 FROM poi_embedding_outbox o WHERE o.poi_id = pois.id AND o.processed_at IS NULL)").
+		Pluck("pois.id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	rows := make([]db_models.POIEmbeddingOutbox, 0, len(ids))
+	for _, id := range ids {
+		rows = append(rows, db_models.POIEmbeddingOutbox{POIID: id, Reason: reason})
+	}
+	if err := r.db.WithContext(ctx).CreateInBatches(rows, 500).Error; err != nil {
+		return 0, err
+	}
+	return int64(len(rows)), nil
+}
+
+func (r *EmbeddingOutboxRepository) LoadPOIs(ctx context.Context, ids []uuid.UUID) ([]db_models.POI, error) {
+	var pois []db_models.POI
+	err := r.db.WithContext(ctx).
+		Preload("Province", func(db *gorm.DB) *gorm.DB { return db.Select("id", "name") }).
+		Preload("Category", func(db *gorm.DB) *gorm.DB { return db.Select("id", "name") }).
+		Preload("Tags").
+		Preload("ExternalRefs").
+		Where("id IN ?", ids).
+		Find(&pois).Error
+	return pois, err
+}
+
+func (r *EmbeddingOutboxRepository) ReviewNotes(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID][]string, error) {
+	var rows []struct {
+		POIID uuid.UUID
+		Notes string
+	}
+	err := r.db.WithContext(ctx).Model(&db_models.CheckIn{}).
+		Select("poi_id", "notes").
+		Where("poi_id IN ? AND notes <> ''", ids).
+		Order("created_at DESC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[uuid.UUID][]string)
+	for _, row := range rows {
+		out[row.POIID] = append(out[row.POIID], row.Notes)
+	}
+	return out, nil
+}
+
+func (r *EmbeddingOutboxRepository) UpsertEmbeddings(ctx context.Context, rows []db_models.PoiEmbedding) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "poi_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "description", "province_id", "category_id", "tags", "embedding", "content_version", "created_at"}),
+		}).
+		Create(&rows).Error
+}
+
+func (r *EmbeddingOutboxRepository) DeleteEmbeddings(ctx context.Context, poiIDs []string) error {
+	if len(poiIDs) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Where("poi_id IN ?", poiIDs).Delete(&db_models.PoiEmbedding{}).Error
+}
@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"vivu/internal/models/db_models"
+)
+
+type JourneyBackupRepositoryInterface interface {
+	CreateJob(ctx context.Context, job *db_models.JourneyBackupJob) error
+	// LatestJob returns the account's most recent backup job, or nil.
+	LatestJob(ctx context.Context, accountID uuid.UUID) (*db_models.JourneyBackupJob, error)
+	// RequestedSince returns when the account's jobs created from since on were requested, oldest first.
+	RequestedSince(ctx context.Context, accountID uuid.UUID, since int64) ([]int64, error)
+
+	// ClaimPending claims the oldest pending job for lease, skipping jobs another worker holds, or nil.
+	ClaimPending(ctx context.Context, lease time.Duration) (*db_models.JourneyBackupJob, error)
+	MarkReady(ctx context.Context, job *db_models.JourneyBackupJob) error
+	// MarkFailed records an attempt that failed. The job stays pending, and claimed so it is
+	// retried once the lease lapses, until it ran out of attempts.
+	MarkFailed(ctx context.Context, id uuid.UUID, reason string, maxAttempts int) error
+	// ExpiredReady returns the ready jobs whose file expired before now.
+	ExpiredReady(ctx context.Context, now int64) ([]db_models.JourneyBackupJob, error)
+	MarkExpired(ctx context.Context, id uuid.UUID) error
+
+	// AccountJourneyIDs lists the account's journeys, oldest first.
+	AccountJourneyIDs(ctx context.Context, accountID uuid.UUID) ([]uuid.UUID, error)
+	// LoadJourney returns a journey with its days, activities, check-ins and their photos, or nil.
+	LoadJourney(ctx context.Context, id uuid.UUID) (*db_models.Journey, error)
+	// JourneyMoney returns the budget of a journey (nil when none was set) and its expenses in
+	// the order they were spent.
+	JourneyMoney(ctx context.Context, journeyID uuid.UUID) (*int64, []db_models.JourneyExpense, error)
+}
+
+type JourneyBackupRepository struct {
+	db *gorm.DB
+}
+
+func NewJourneyBackupRepository(db *gorm.DB) *JourneyBackupRepository {
+	return &JourneyBackupRepository{db: db}
+}
+
+func (r *JourneyBackupRepository) CreateJob(ctx context.Context, job *db_models.JourneyBackupJob) error {
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+func (r *JourneyBackupRepository) LatestJob(ctx context.Context, accountID uuid.UUID) (*db_models.JourneyBackupJob, error) {
+	var job db_models.JourneyBackupJob
+	err := r.db.WithContext(ctx).
+		Where("account_id = ?", accountID).
+		Order("created_at DESC").
+		First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *JourneyBackupRepository) RequestedSince(ctx context.Context, accountID uuid.UUID, since int64) ([]int64, error) {
+	var at []int64
+	err := r.db.WithContext(ctx).Model(&db_models.JourneyBackupJob{}).
+		Where("account_id = ? AND created_at >= ?", accountID, since).
+		Order("created_at").
+		Pluck("created_at", &at).Error
+	return at, err
+}
+
+func (r *JourneyBackupRepository) ClaimPending(ctx context.Context, lease time.Duration) (*db_models.JourneyBackupJob, error) {
+	var job *db_models.JourneyBackupJob
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().Unix()
+		var rows []db_models.JourneyBackupJob
+		// Jobs locked by a concurrent claim are skipped, not waited for
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", db_models.JourneyBackupPending).
+			Where("claimed_until IS NULL OR claimed_until < ?", now).
+			Order("created_at").
+			Limit(1).
+			Find(&rows).Error
+		if err != nil || len(rows) == 0 {
+			return err
+		}
+		until := time.Now().Add(lease).Unix()
+		if err := tx.Model(&db_models.JourneyBackupJob{}).
+			Where("id = ?", rows[0].ID).
+			Update("claimed_until", until).Error; err != nil {
+			return err
+		}
+		rows[0].ClaimedUntil = &until
+		job = &rows[0]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (r *JourneyBackupRepository) MarkReady(ctx context.Context, job *db_models.JourneyBackupJob) error {
+	return r.db.WithContext(ctx).
+		Model(&db_models.JourneyBackupJob{}).
+		Where("id = ?", job.ID).
+		Updates(map[string]any{
+			"status":        db_models.JourneyBackupReady,
+			"storage_key":   job.StorageKey,
+			"file_name":     job.FileName,
+			"size_bytes":    job.SizeBytes,
+			"journeys":      job.Journeys,
+			"completed_at":  job.CompletedAt,
+			"expires_at":    job.ExpiresAt,
+			"last_error":    "",
+			"claimed_until": nil,
+		}).Error
+}
+
+func (r *JourneyBackupRepository) MarkFailed(ctx context.Context, id uuid.UUID, reason string, maxAttempts int) error {
+	return r.db.WithContext(ctx).
+		Model(&db_models.JourneyBackupJob{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"attempts":   gorm.Expr("attempts + 1"),
+			"last_error": reason,
+			"status":     gorm.Expr("CASE WHEN attempts + 1 >= ? THEN ? ELSE status END", maxAttempts, db_models.JourneyBackupFailed),
+		}).Error
+}
+
+func (r *JourneyBackupRepository) ExpiredReady(ctx context.Context, now int64) ([]db_models.JourneyBackupJob, error) {
+	var jobs []db_models.JourneyBackupJob
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND expires_at < ?", db_models.JourneyBackupReady, now).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+func (r *JourneyBackupRepository) MarkExpired(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&db_models.JourneyBackupJob{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"status": db_models.JourneyBackupExpired, "storage_key": ""}).Error
+}
+
+func (r *JourneyBackupRepository) AccountJourneyIDs(ctx context.Context, accountID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Model(&db_models.Journey{}).
+		Where("account_id = ?", accountID).
+		Order("start_date, created_at").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *JourneyBackupRepository) LoadJourney(ctx context.Context, id uuid.UUID) (*db_models.Journey, error) {
+	var j db_models.Journey
+	err := r.db.WithContext(ctx).
+		Preload("Days", func(db *gorm.DB) *gorm.DB { return db.Order("day_number") }).
+		Preload("Days.Activities").
+		Preload("Days.Activities.SelectedPOI", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Days.Accommodation", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("CheckIns", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		Preload("CheckIns.Photos").
+		Preload("CheckIns.POI", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where("id = ?", id).
+		First(&j).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+func (r *JourneyBackupRepository) JourneyMoney(ctx context.Context, journeyID uuid.UUID) (*int64, []db_models.JourneyExpense, error) {
+	var budgets []db_models.JourneyBudget
+	if err := r.db.WithContext(ctx).Where("journey_id = ?", journeyID).Limit(1).Find(&budgets).Error; err != nil {
+		return nil, nil, err
+	}
+	var expenses []db_models.JourneyExpense
+	if err := r.db.WithContext(ctx).Where("journey_id = ?", journeyID).Order("spent_at, created_at").Find(&expenses).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(budgets) == 0 {
+		return nil, expenses, nil
+	}
+	return &budgets[0].Amount, expenses, nil
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"vivu/internal/models/db_models"
+)
+
+type JourneyBudgetRepositoryInterface interface {
+	// FindBudget returns nil when the journey has no budget.
+	FindBudget(ctx context.Context, journeyID uuid.UUID) (*db_models.JourneyBudget, error)
+	// UpsertBudget sets the amount and re-arms the over-budget alert.
+	UpsertBudget(ctx context.Context, journeyID uuid.UUID, amount int64) error
+	DeleteBudget(ctx context.Context, journeyID uuid.UUID) error
+	// SetAlertSent marks (or clears, with nil) the over-budget alert. Marking only succeeds when
+	// the alert was not sent yet, so concurrent expenses alert once.
+	SetAlertSent(ctx context.Context, journeyID uuid.UUID, at *int64) (bool, error)
+
+	CreateExpense(ctx context.Context, expense *db_models.JourneyExpense) error
+	ListExpenses(ctx context.Context, journeyID uuid.UUID) ([]db_models.JourneyExpense, error)
+	FindExpense(ctx context.Context, journeyID, expenseID uuid.UUID) (*db_models.JourneyExpense, error)
+	DeleteExpense(ctx context.Context, expenseID uuid.UUID) error
+	TotalSpent(ctx context.Context, journeyID uuid.UUID) (int64, error)
+}
+
+type JourneyBudgetRepository struct {
+	db *gorm.DB
+}
+
+func NewJourneyBudgetRepository(db *gorm.DB) *JourneyBudgetRepository {
+	return &JourneyBudgetRepository{db: db}
+}
+
+func (r *JourneyBudgetRepository) FindBudget(ctx context.Context, journeyID uuid.UUID) (*db_models.JourneyBudget, error) {
+	var b db_models.JourneyBudget
+	err := r.db.WithContext(ctx).First(&b, "journey_id = ?", journeyID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (r *JourneyBudgetRepository) UpsertBudget(ctx context.Context, journeyID uuid.UUID, amount int64) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "journey_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"amount":        amount,
+			"alert_sent_at": nil,
+			"updated_at":    time.Now().Unix(),
+			"deleted_at":    nil,
+		}),
+	}).Create(&db_models.JourneyBudget{JourneyID: journeyID, Amount: amount}).Error
+}
+
+func (r *JourneyBudgetRepository) DeleteBudget(ctx context.Context, journeyID uuid.UUID) error {
+	return r.db.WithContext(ctx).Unscoped().Delete(&db_models.JourneyBudget{}, "journey_id = ?", journeyID).Error
+}
+
+func (r *JourneyBudgetRepository) SetAlertSent(ctx context.Context, journeyID uuid.UUID, at *int64) (bool, error) {
+	q := r.db.WithContext(ctx).Model(&db_models.JourneyBudget{}).Where("journey_id = ?", journeyID)
+	if at != nil {
+		q = q.Where("alert_sent_at IS NULL")
+	}
+	res := q.Update("alert_sent_at", at)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *JourneyBudgetRepository) CreateExpense(ctx context.Context, expense *db_models.JourneyExpense) error {
+	return r.db.WithContext(ctx).Create(expense).Error
+}
+
+func (r *JourneyBudgetRepository) ListExpenses(ctx context.Context, journeyID uuid.UUID) ([]db_models.JourneyExpense, error) {
+	var out []db_models.JourneyExpense
+	err := r.db.WithContext(ctx).Where("journey_id = ?", journeyID).Order("spent_at DESC").Find(&out).Error
+	return out, err
+}
+
+func (r *JourneyBudgetRepository) FindExpense(ctx context.Context, journeyID, expenseID uuid.UUID) (*db_models.JourneyExpense, error) {
+	var e db_models.JourneyExpense
+	err := r.db.WithContext(ctx).First(&e, "id = ? AND journey_id = ?", expenseID, journeyID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *JourneyBudgetRepository) DeleteExpense(ctx context.Context, expenseID uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&db_models.JourneyExpense{}, "id = ?", expenseID).Error
+}
+
+func (r *JourneyBudgetRepository) TotalSpent(ctx context.Context, journeyID uuid.UUID) (int64, error) {
+	var total int64
+	err := r.db.WithContext(ctx).Model(&db_models.JourneyExpense{}).
+		Where("journey_id = ?", journeyID).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&total).Error
+	return total, err
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"vivu/internal/models/db_models"
+)
+
+type JourneyCommentRepositoryInterface interface {
+	Create(ctx context.Context, comment *db_models.JourneyComment) error
+	// Find returns a live comment of the journey, or nil.
+	Find(ctx context.Context, journeyID, commentID uuid.UUID) (*db_models.JourneyComment, error)
+	// ListByJourney returns the comments of a journey oldest first, deleted ones included, with
+	// their authors. A non-nil activityID keeps only the comments on that activity.
+	ListByJourney(ctx context.Context, journeyID uuid.UUID, activityID *uuid.UUID) ([]db_models.JourneyComment, error)
+	Delete(ctx context.Context, commentID uuid.UUID) error
+}
+
+type JourneyCommentRepository struct {
+	db *gorm.DB
+}
+
+func NewJourneyCommentRepository(db *gorm.DB) *JourneyCommentRepository {
+	return &JourneyCommentRepository{db: db}
+}
+
+func (r *JourneyCommentRepository) Create(ctx context.Context, comment *db_models.JourneyComment) error {
+	return r.db.WithContext(ctx).Create(comment).Error
+}
+
+func (r *JourneyCommentRepository) Find(ctx context.Context, journeyID, commentID uuid.UUID) (*db_models.JourneyComment, error) {
+	var c db_models.JourneyComment
+	err := r.db.WithContext(ctx).First(&c, "id = ? AND journey_id = ?", commentID, journeyID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *JourneyCommentRepository) ListByJourney(ctx context.Context, journeyID uuid.UUID, activityID *uuid.UUID) ([]db_models.JourneyComment, error) {
+	q := r.db.WithContext(ctx).Unscoped().Preload("Account").Where("journey_id = ?", journeyID)
+	if activityID != nil {
+		q = q.Where("activity_id = ?", *activityID)
+	}
+	var comments []db_models.JourneyComment
+	err := q.Order("created_at ASC").Find(&comments).Error
+	return comments, err
+}
+
+func (r *JourneyCommentRepository) Delete(ctx context.Context, commentID uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&db_models.JourneyComment{}, "id = ?", commentID).Error
+}
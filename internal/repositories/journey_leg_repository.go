@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/response_models"
+)
+
+type JourneyLegRepositoryInterface interface {
+	// UpcomingJourneys pages the open journeys that have not ended before from and start before
+	// to, by id after the given one, with their activities, POIs and accommodations.
+	UpcomingJourneys(ctx context.Context, from, to int64, after uuid.UUID, limit int) ([]db_models.Journey, error)
+	SaveActivityLeg(ctx context.Context, activityID uuid.UUID, leg *response_models.TravelLeg) error
+	SaveReturnLeg(ctx context.Context, dayID uuid.UUID, leg *response_models.TravelLeg) error
+}
+
+type JourneyLegRepository struct {
+	db *gorm.DB
+}
+
+func NewJourneyLegRepository(db *gorm.DB) *JourneyLegRepository {
+	return &JourneyLegRepository{db: db}
+}
+
+func (r *JourneyLegRepository) UpcomingJourneys(ctx context.Context, from, to int64, after uuid.UUID, limit int) ([]db_models.Journey, error) {
+	var out []db_models.Journey
+	err := r.db.WithContext(ctx).
+		Where("is_completed = ? AND start_date < ? AND COALESCE(end_date, start_date) >= ?", false, to, from).
+		Where("id > ?", after).
+		Order("id").
+		Limit(limit).
+		Preload("Days").
+		Preload("Days.Activities").
+		Preload("Days.Activities.SelectedPOI").
+		Preload("Days.Accommodation").
+		Find(&out).Error
+	return out, err
+}
+
+// The legs are derived data: writing them must not bump updated_at of the activity or day.
+
+func (r *JourneyLegRepository) SaveActivityLeg(ctx context.Context, activityID uuid.UUID, leg *response_models.TravelLeg) error {
+	return r.db.WithContext(ctx).
+		Model(&db_models.JourneyActivity{BaseModel: db_models.BaseModel{ID: activityID}}).
+		Select("Leg").
+		UpdateColumns(db_models.JourneyActivity{Leg: leg}).Error
+}
+
+func (r *JourneyLegRepository) SaveReturnLeg(ctx context.Context, dayID uuid.UUID, leg *response_models.TravelLeg) error {
+	return r.db.WithContext(ctx).
+		Model(&db_models.JourneyDay{BaseModel: db_models.BaseModel{ID: dayID}}).
+		Select("ReturnLeg").
+		UpdateColumns(db_models.JourneyDay{ReturnLeg: leg}).Error
+}
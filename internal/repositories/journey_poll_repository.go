@@ -0,0 +1,184 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"vivu/internal/models/db_models"
+)
+
+type JourneyPollRepositoryInterface interface {
+	// FindJourney returns the journey with its account but without days, or nil when it does not exist.
+	FindJourney(ctx context.Context, journeyID string) (*db_models.Journey, error)
+	// FindActivity returns an activity of the journey, or nil when it is not part of it.
+	FindActivity(ctx context.Context, journeyID, activityID uuid.UUID) (*db_models.JourneyActivity, error)
+
+	ListMembers(ctx context.Context, journeyID uuid.UUID) ([]db_models.JourneyMember, error)
+	// UpsertMember adds an account to the journey or changes its role.
+	UpsertMember(ctx context.Context, member *db_models.JourneyMember) error
+	RemoveMember(ctx context.Context, journeyID, accountID uuid.UUID) (bool, error)
+
+	CreatePoll(ctx context.Context, poll *db_models.JourneyPoll) error
+	// ListPolls returns the polls of a journey, newest first, with options, their POIs and votes.
+	ListPolls(ctx context.Context, journeyID uuid.UUID) ([]db_models.JourneyPoll, error)
+	FindPoll(ctx context.Context, journeyID, pollID uuid.UUID) (*db_models.JourneyPoll, error)
+	UpsertVote(ctx context.Context, vote *db_models.JourneyPollVote) error
+	// DuePolls returns open polls whose deadline has passed, with options, their POIs and votes.
+	DuePolls(ctx context.Context, now int64, limit int) ([]db_models.JourneyPoll, error)
+	// ClosePoll closes an open poll and, with a winner, moves the activity to the winning POI.
+	// It reports false when another worker closed the poll first.
+	ClosePoll(ctx context.Context, poll *db_models.JourneyPoll, winner *db_models.JourneyPollOption) (bool, error)
+}
+
+type JourneyPollRepository struct {
+	db *gorm.DB
+}
+
+func NewJourneyPollRepository(db *gorm.DB) *JourneyPollRepository {
+	return &JourneyPollRepository{db: db}
+}
+
+func (r *JourneyPollRepository) FindJourney(ctx context.Context, journeyID string) (*db_models.Journey, error) {
+	var j db_models.Journey
+	err := r.db.WithContext(ctx).Preload("Account").First(&j, "id = ?", journeyID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+func (r *JourneyPollRepository) FindActivity(ctx context.Context, journeyID, activityID uuid.UUID) (*db_models.JourneyActivity, error) {
+	var a db_models.JourneyActivity
+	err := r.db.WithContext(ctx).
+		Joins("JOIN journey_days ON journey_days.id = journey_activities.journey_day_id AND journey_days.deleted_at IS NULL").
+		Where("journey_activities.id = ? AND journey_days.journey_id = ?", activityID, journeyID).
+		First(&a).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *JourneyPollRepository) ListMembers(ctx context.Context, journeyID uuid.UUID) ([]db_models.JourneyMember, error) {
+	var members []db_models.JourneyMember
+	err := r.db.WithContext(ctx).Preload("Account").
+		Where("journey_id = ?", journeyID).
+		Order("created_at ASC").
+		Find(&members).Error
+	return members, err
+}
+
+func (r *JourneyPollRepository) UpsertMember(ctx context.Context, member *db_models.JourneyMember) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "journey_id"}, {Name: "account_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"role":       member.Role,
+			"updated_at": time.Now().Unix(),
+			"deleted_at": nil,
+		}),
+	}).Create(member).Error
+}
+
+func (r *JourneyPollRepository) RemoveMember(ctx context.Context, journeyID, accountID uuid.UUID) (bool, error) {
+	res := r.db.WithContext(ctx).Unscoped().
+		Where("journey_id = ? AND account_id = ?", journeyID, accountID).
+		Delete(&db_models.JourneyMember{})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *JourneyPollRepository) CreatePoll(ctx context.Context, poll *db_models.JourneyPoll) error {
+	return r.db.WithContext(ctx).Create(poll).Error
+}
+
+func (r *JourneyPollRepository) withOptionsAndVotes(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Preload("Options", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		Preload("Options.POI").
+		Preload("Votes")
+}
+
+func (r *JourneyPollRepository) ListPolls(ctx context.Context, journeyID uuid.UUID) ([]db_models.JourneyPoll, error) {
+	var polls []db_models.JourneyPoll
+	err := r.withOptionsAndVotes(ctx).
+		Where("journey_id = ?", journeyID).
+		Order("created_at DESC").
+		Find(&polls).Error
+	return polls, err
+}
+
+func (r *JourneyPollRepository) FindPoll(ctx context.Context, journeyID, pollID uuid.UUID) (*db_models.JourneyPoll, error) {
+	var p db_models.JourneyPoll
+	err := r.withOptionsAndVotes(ctx).First(&p, "id = ? AND journey_id = ?", pollID, journeyID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *JourneyPollRepository) UpsertVote(ctx context.Context, vote *db_models.JourneyPollVote) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "poll_id"}, {Name: "account_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"option_id":  vote.OptionID,
+			"updated_at": time.Now().Unix(),
+		}),
+	}).Create(vote).Error
+}
+
+func (r *JourneyPollRepository) DuePolls(ctx context.Context, now int64, limit int) ([]db_models.JourneyPoll, error) {
+	var polls []db_models.JourneyPoll
+	err := r.withOptionsAndVotes(ctx).
+		Where("status = ? AND deadline <= ?", db_models.PollStatusOpen, now).
+		Order("deadline ASC").
+		Limit(limit).
+		Find(&polls).Error
+	return polls, err
+}
+
+func (r *JourneyPollRepository) ClosePoll(ctx context.Context, poll *db_models.JourneyPoll, winner *db_models.JourneyPollOption) (bool, error) {
+	closed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().Unix()
+		updates := map[string]interface{}{
+			"status":    db_models.PollStatusClosed,
+			"closed_at": now,
+		}
+		if winner != nil {
+			updates["winner_option_id"] = winner.ID
+		}
+		res := tx.Model(&db_models.JourneyPoll{}).
+			Where("id = ? AND status = ?", poll.ID, db_models.PollStatusOpen).
+			Updates(updates)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		closed = true
+		poll.Status = db_models.PollStatusClosed
+		poll.ClosedAt = &now
+		if winner == nil {
+			return nil
+		}
+		poll.WinnerOptionID = &winner.ID
+		// The activity may have been removed since; the poll still closes
+		return tx.Model(&db_models.JourneyActivity{}).
+			Where("id = ?", poll.ActivityID).
+			Update("selected_poi_id", winner.POIID).Error
+	})
+	return closed, err
+}
@@ -4,6 +4,7 @@ package repositories
 import (
 	"context"
 	"errors"
+	"sort"
 
 	"time"
 
@@ -35,6 +36,22 @@ type JourneyRepository interface {
 	UpdateJourneyWindow(
 		ctx context.Context, journeyId string, startUnix, endUnix int64,
 	) error
+	UpdateActivitySlots(ctx context.Context, dayId uuid.UUID, slots []ActivitySlot) error
+	// SetDayAccommodation sets (or clears, with nil) the accommodation of a day.
+	// It returns false when the POI does not exist.
+	SetDayAccommodation(ctx context.Context, dayId uuid.UUID, poiId *uuid.UUID) (bool, error)
+	// SwapRainyPlan exchanges a day's activities with its rainy-day plan; false when it has none.
+	SwapRainyPlan(ctx context.Context, dayId uuid.UUID) (bool, error)
+	// ReplaceDayPlan swaps a day's activities and rainy-day plan for a regenerated one.
+	ReplaceDayPlan(ctx context.Context, dayId uuid.UUID, plan *resp.PlanOnlyDay) error
+	ListCheckIns(ctx context.Context, journeyId uuid.UUID) ([]dbm.CheckIn, error)
+	CreateCheckIn(ctx context.Context, checkIn *dbm.CheckIn) error
+	// SetShareToken sets or, with nil, clears the journey's share link token.
+	SetShareToken(ctx context.Context, journeyId uuid.UUID, token *string) error
+	// FindJourneyIdByShareToken returns the journey the token was shared for, or uuid.Nil.
+	FindJourneyIdByShareToken(ctx context.Context, token string) (uuid.UUID, error)
+	// FindJourneyIdByActivityId returns the journey the activity belongs to, or uuid.Nil.
+	FindJourneyIdByActivityId(ctx context.Context, activityId uuid.UUID) (uuid.UUID, error)
 }
 
 func NewJourneyRepository(db *gorm.DB) JourneyRepository {
@@ -216,6 +233,10 @@ func (r *journeyRepository) GetDetailsOfJourneyById(ctx context.Context, journey
 		Preload("Days").
 		Preload("Days.Activities").
 		Preload("Days.Activities.SelectedPOI").
+		Preload("Days.Accommodation").
+		Preload("Account", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "day_start", "day_end")
+		}).
 		First(&journey).Error
 
 	if err != nil {
@@ -326,10 +347,13 @@ func (r *journeyRepository) ReplaceMaterializedPlan(
 				IsShared:    createIn.IsShared,
 				IsCompleted: createIn.IsCompleted,
 				Location:    plan.Destination,
+				Diversity:   plan.Diversity,
 			}
 			if err := tx.Create(&j).Error; err != nil {
 				return err
 			}
+		} else if err := tx.Model(&j).Select("Diversity").Updates(dbm.Journey{Diversity: plan.Diversity}).Error; err != nil {
+			return err
 		}
 
 		outID = j.ID
@@ -364,6 +388,11 @@ func (r *journeyRepository) ReplaceMaterializedPlan(
 				Date:      dayDate, // GORM should store with tz; if you store as timestamp w/o tz, keep consistency
 				DayNumber: d.Day,
 			}
+			for _, a := range d.RainyDay {
+				if poiID, err := uuid.Parse(a.MainPOIID); err == nil {
+					jd.RainyPlan = append(jd.RainyPlan, dbm.PlannedActivity{POIID: poiID, StartTime: a.StartTime, EndTime: a.EndTime, Notes: a.Note})
+				}
+			}
 			if err := tx.Create(&jd).Error; err != nil {
 				return err
 			}
@@ -379,35 +408,9 @@ func (r *journeyRepository) ReplaceMaterializedPlan(
 					continue
 				}
 
-				// VN-local base day
-				actStart := dayDate
-				if t, err := time.ParseInLocation("15:04", a.StartTime, vnLoc); err == nil {
-					actStart = time.Date(dayDate.Year(), dayDate.Month(), dayDate.Day(),
-						t.Hour(), t.Minute(), 0, 0, vnLoc)
-				}
-
-				// Parse end time if provided
-				var actEndPtr *time.Time
-				if a.EndTime != "" {
-					if et, err := time.ParseInLocation("15:04", a.EndTime, vnLoc); err == nil {
-						etFull := time.Date(dayDate.Year(), dayDate.Month(), dayDate.Day(),
-							et.Hour(), et.Minute(), 0, 0, vnLoc)
-						// ensure end >= start (adjust to next day if user meant crossing midnight)
-						if etFull.Before(actStart) {
-							etFull = etFull.Add(24 * time.Hour)
-						}
-						actEndPtr = &etFull
-					}
-				}
-
-				acts = append(acts, dbm.JourneyActivity{
-					JourneyDayID:  jd.ID,
-					Time:          actStart,  // start
-					EndTime:       actEndPtr, // end (nullable)
-					ActivityType:  "poi",
-					SelectedPOIID: poiID,
-					Notes:         "",
-				})
+				act := activityOnDay(jd.ID, dayDate, poiID, a.StartTime, a.EndTime, a.Note)
+				act.Selection = a.Selection
+				acts = append(acts, act)
 			}
 			if len(acts) > 0 {
 				if err := tx.Create(&acts).Error; err != nil {
@@ -543,6 +546,34 @@ func (r *journeyRepository) UpdateJourneyWindow(
 		}).Error
 }
 
+// ActivitySlot is the new time window of an activity after a reorder.
+type ActivitySlot struct {
+	ActivityID uuid.UUID
+	Time       time.Time
+	EndTime    *time.Time
+}
+
+// UpdateActivitySlots rewrites the times of activities of one day in a single transaction.
+func (r *journeyRepository) UpdateActivitySlots(ctx context.Context, dayId uuid.UUID, slots []ActivitySlot) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, s := range slots {
+			res := tx.Model(&dbm.JourneyActivity{}).
+				Where("id = ? AND journey_day_id = ?", s.ActivityID, dayId).
+				Updates(map[string]interface{}{
+					"time":     s.Time,
+					"end_time": s.EndTime,
+				})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return gorm.ErrRecordNotFound
+			}
+		}
+		return nil
+	})
+}
+
 type CreateJourneyInput struct {
 	AccountID   uuid.UUID
 	Title       string
@@ -551,3 +582,173 @@ type CreateJourneyInput struct {
 	IsShared    bool       // optional
 	IsCompleted bool       // optional
 }
+
+func (r *journeyRepository) SetDayAccommodation(ctx context.Context, dayId uuid.UUID, poiId *uuid.UUID) (bool, error) {
+	if poiId != nil {
+		var n int64
+		if err := r.db.WithContext(ctx).Model(&dbm.POI{}).Where("id = ?", *poiId).Count(&n).Error; err != nil {
+			return false, err
+		}
+		if n == 0 {
+			return false, nil
+		}
+	}
+
+	res := r.db.WithContext(ctx).
+		Model(&dbm.JourneyDay{}).
+		Where("id = ?", dayId).
+		Update("accommodation_poi_id", poiId)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return false, gorm.ErrRecordNotFound
+	}
+	return true, nil
+}
+
+// activityOnDay builds a POI activity from VN wall-clock "15:04" times on the given day.
+func activityOnDay(dayID uuid.UUID, dayDate time.Time, poiID uuid.UUID, start, end, notes string) dbm.JourneyActivity {
+	// VN-local base day
+	actStart := dayDate
+	if t, err := time.ParseInLocation("15:04", start, vnLoc); err == nil {
+		actStart = time.Date(dayDate.Year(), dayDate.Month(), dayDate.Day(),
+			t.Hour(), t.Minute(), 0, 0, vnLoc)
+	}
+
+	// Parse end time if provided
+	var actEndPtr *time.Time
+	if end != "" {
+		if et, err := time.ParseInLocation("15:04", end, vnLoc); err == nil {
+			etFull := time.Date(dayDate.Year(), dayDate.Month(), dayDate.Day(),
+				et.Hour(), et.Minute(), 0, 0, vnLoc)
+			// ensure end >= start (adjust to next day if user meant crossing midnight)
+			if etFull.Before(actStart) {
+				etFull = etFull.Add(24 * time.Hour)
+			}
+			actEndPtr = &etFull
+		}
+	}
+
+	return dbm.JourneyActivity{
+		JourneyDayID:  dayID,
+		Time:          actStart,  // start
+		EndTime:       actEndPtr, // end (nullable)
+		ActivityType:  "poi",
+		SelectedPOIID: poiID,
+		Notes:         notes,
+	}
+}
+
+func (r *journeyRepository) SwapRainyPlan(ctx context.Context, dayId uuid.UUID) (bool, error) {
+	swapped := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var day dbm.JourneyDay
+		if err := tx.Preload("Activities").First(&day, "id = ?", dayId).Error; err != nil {
+			return err
+		}
+		if len(day.RainyPlan) == 0 {
+			return nil
+		}
+
+		// The current activities become the alternative, so the swap can be undone
+		sort.Slice(day.Activities, func(i, j int) bool { return day.Activities[i].Time.Before(day.Activities[j].Time) })
+		previous := make([]dbm.PlannedActivity, 0, len(day.Activities))
+		for _, a := range day.Activities {
+			p := dbm.PlannedActivity{POIID: a.SelectedPOIID, StartTime: a.Time.In(vnLoc).Format("15:04"), Notes: a.Notes}
+			if a.EndTime != nil {
+				p.EndTime = a.EndTime.In(vnLoc).Format("15:04")
+			}
+			previous = append(previous, p)
+		}
+
+		dayDate := day.Date.In(vnLoc)
+		acts := make([]dbm.JourneyActivity, 0, len(day.RainyPlan))
+		for _, p := range day.RainyPlan {
+			acts = append(acts, activityOnDay(day.ID, dayDate, p.POIID, p.StartTime, p.EndTime, p.Notes))
+		}
+
+		if err := tx.Where("journey_day_id = ?", day.ID).Delete(&dbm.JourneyActivity{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&acts).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&day).Select("RainyPlan").Updates(dbm.JourneyDay{RainyPlan: previous}).Error; err != nil {
+			return err
+		}
+		swapped = true
+		return nil
+	})
+	return swapped, err
+}
+
+func (r *journeyRepository) ReplaceDayPlan(ctx context.Context, dayId uuid.UUID, plan *resp.PlanOnlyDay) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var day dbm.JourneyDay
+		if err := tx.First(&day, "id = ?", dayId).Error; err != nil {
+			return err
+		}
+
+		dayDate := day.Date.In(vnLoc)
+		acts := make([]dbm.JourneyActivity, 0, len(plan.Activities))
+		for _, a := range plan.Activities {
+			if poiID, err := uuid.Parse(a.MainPOIID); err == nil {
+				acts = append(acts, activityOnDay(day.ID, dayDate, poiID, a.StartTime, a.EndTime, a.Note))
+			}
+		}
+		rainy := make([]dbm.PlannedActivity, 0, len(plan.RainyDay))
+		for _, a := range plan.RainyDay {
+			if poiID, err := uuid.Parse(a.MainPOIID); err == nil {
+				rainy = append(rainy, dbm.PlannedActivity{POIID: poiID, StartTime: a.StartTime, EndTime: a.EndTime, Notes: a.Note})
+			}
+		}
+
+		if err := tx.Where("journey_day_id = ?", day.ID).Delete(&dbm.JourneyActivity{}).Error; err != nil {
+			return err
+		}
+		if len(acts) > 0 {
+			if err := tx.Create(&acts).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&day).Select("RainyPlan").Updates(dbm.JourneyDay{RainyPlan: rainy}).Error
+	})
+}
+
+func (r *journeyRepository) ListCheckIns(ctx context.Context, journeyId uuid.UUID) ([]dbm.CheckIn, error) {
+	var out []dbm.CheckIn
+	err := r.db.WithContext(ctx).Where("journey_id = ?", journeyId).Order("created_at ASC").Find(&out).Error
+	return out, err
+}
+
+func (r *journeyRepository) CreateCheckIn(ctx context.Context, checkIn *dbm.CheckIn) error {
+	return r.db.WithContext(ctx).Create(checkIn).Error
+}
+
+func (r *journeyRepository) SetShareToken(ctx context.Context, journeyId uuid.UUID, token *string) error {
+	return r.db.WithContext(ctx).Model(&dbm.Journey{}).Where("id = ?", journeyId).Update("share_token", token).Error
+}
+
+func (r *journeyRepository) FindJourneyIdByShareToken(ctx context.Context, token string) (uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Model(&dbm.Journey{}).Where("share_token = ?", token).Limit(1).Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return uuid.Nil, err
+	}
+	return ids[0], nil
+}
+
+func (r *journeyRepository) FindJourneyIdByActivityId(ctx context.Context, activityId uuid.UUID) (uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).
+		Table("journey_activities").
+		Joins("JOIN journey_days ON journey_days.id = journey_activities.journey_day_id").
+		Where("journey_activities.id = ? AND journey_activities.deleted_at IS NULL", activityId).
+		Limit(1).
+		Pluck("journey_days.journey_id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return uuid.Nil, err
+	}
+	return ids[0], nil
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"vivu/internal/models/db_models"
+)
+
+type LegalRepositoryInterface interface {
+	// ListDocuments returns every published version, oldest first.
+	ListDocuments(ctx context.Context) ([]db_models.LegalDocument, error)
+	CreateDocument(ctx context.Context, doc *db_models.LegalDocument) error
+	// AcceptedDocumentIDs returns the documents the account accepted.
+	AcceptedDocumentIDs(ctx context.Context, accountID uuid.UUID) ([]uuid.UUID, error)
+	// Acceptances returns the acceptances of the account, newest first.
+	Acceptances(ctx context.Context, accountID uuid.UUID) ([]db_models.LegalAcceptance, error)
+	// Accept records acceptances; ones already recorded are kept as they were.
+	Accept(ctx context.Context, acceptances []db_models.LegalAcceptance) error
+}
+
+type LegalRepository struct {
+	db *gorm.DB
+}
+
+func NewLegalRepository(db *gorm.DB) *LegalRepository {
+	return &LegalRepository{db: db}
+}
+
+func (r *LegalRepository) ListDocuments(ctx context.Context) ([]db_models.LegalDocument, error) {
+	var out []db_models.LegalDocument
+	err := r.db.WithContext(ctx).Order("published_at ASC, created_at ASC").Find(&out).Error
+	return out, err
+}
+
+func (r *LegalRepository) CreateDocument(ctx context.Context, doc *db_models.LegalDocument) error {
+	return r.db.WithContext(ctx).Create(doc).Error
+}
+
+func (r *LegalRepository) AcceptedDocumentIDs(ctx context.Context, accountID uuid.UUID) ([]uuid.UUID, error) {
+	var out []uuid.UUID
+	err := r.db.WithContext(ctx).Model(&db_models.LegalAcceptance{}).
+		Where("account_id = ?", accountID).
+		Pluck("document_id", &out).Error
+	return out, err
+}
+
+func (r *LegalRepository) Acceptances(ctx context.Context, accountID uuid.UUID) ([]db_models.LegalAcceptance, error) {
+	var out []db_models.LegalAcceptance
+	err := r.db.WithContext(ctx).Where("account_id = ?", accountID).Order("accepted_at DESC").Find(&out).Error
+	return out, err
+}
+
+func (r *LegalRepository) Accept(ctx context.Context, acceptances []db_models.LegalAcceptance) error {
+	if len(acceptances) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "account_id"}, {Name: "document_id"}},
+		DoNothing: true,
+	}).Create(&acceptances).Error
+}
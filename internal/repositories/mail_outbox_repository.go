@@ -0,0 +1,153 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"vivu/internal/models/db_models"
+)
+
+// MailOutboxFilter narrows the outbox listing; zero values mean "any".
+type MailOutboxFilter struct {
+	Status    string
+	Recipient string
+}
+
+type MailOutboxRepositoryInterface interface {
+	Enqueue(ctx context.Context, msg *db_models.MailOutboxMessage) error
+	// ClaimDue returns pending messages whose attempt is due and hides them from other workers
+	// for lease.
+	ClaimDue(ctx context.Context, now int64, limit int, lease time.Duration) ([]db_models.MailOutboxMessage, error)
+	MarkSent(ctx context.Context, id uuid.UUID, at int64) error
+	// MarkAttemptFailed records a failed attempt; nextAttemptAt nil dead-letters the message.
+	MarkAttemptFailed(ctx context.Context, id uuid.UUID, reason string, nextAttemptAt *int64) error
+	// List returns a page of the outbox, newest first.
+	List(ctx context.Context, filter MailOutboxFilter, page, pageSize int) ([]db_models.MailOutboxMessage, int64, error)
+	Find(ctx context.Context, id string) (*db_models.MailOutboxMessage, error)
+	// Requeue puts dead messages back in the queue with a fresh set of attempts, all of them
+	// when ids is empty, and returns how many moved.
+	Requeue(ctx context.Context, ids []uuid.UUID, now int64) (int64, error)
+}
+
+type MailOutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewMailOutboxRepository(db *gorm.DB) *MailOutboxRepository {
+	return &MailOutboxRepository{db: db}
+}
+
+func (r *MailOutboxRepository) Enqueue(ctx context.Context, msg *db_models.MailOutboxMessage) error {
+	return r.db.WithContext(ctx).Create(msg).Error
+}
+
+func (r *MailOutboxRepository) ClaimDue(ctx context.Context, now int64, limit int, lease time.Duration) ([]db_models.MailOutboxMessage, error) {
+	var rows []db_models.MailOutboxMessage
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Rows locked by a concurrent claim are skipped, not waited for
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", db_models.MailOutboxPending, now).
+			Where("claimed_until IS NULL OR claimed_until < ?", now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&rows).Error
+		if err != nil || len(rows) == 0 {
+			return err
+		}
+		ids := make([]uuid.UUID, len(rows))
+		for i := range rows {
+			ids[i] = rows[i].ID
+		}
+		return tx.Model(&db_models.MailOutboxMessage{}).
+			Where("id IN ?", ids).
+			Update("claimed_until", time.Unix(now, 0).Add(lease).Unix()).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *MailOutboxRepository) MarkSent(ctx context.Context, id uuid.UUID, at int64) error {
+	return r.db.WithContext(ctx).Model(&db_models.MailOutboxMessage{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":        db_models.MailOutboxSent,
+			"attempts":      gorm.Expr("attempts + 1"),
+			"last_error":    "",
+			"sent_at":       at,
+			"claimed_until": nil,
+			// the code has done its job; it should not sit in the log
+			"code": "",
+		}).Error
+}
+
+func (r *MailOutboxRepository) MarkAttemptFailed(ctx context.Context, id uuid.UUID, reason string, nextAttemptAt *int64) error {
+	updates := map[string]any{
+		"attempts":      gorm.Expr("attempts + 1"),
+		"last_error":    reason,
+		"claimed_until": nil,
+	}
+	if nextAttemptAt != nil {
+		updates["next_attempt_at"] = *nextAttemptAt
+	} else {
+		updates["status"] = db_models.MailOutboxDead
+	}
+	return r.db.WithContext(ctx).Model(&db_models.MailOutboxMessage{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (r *MailOutboxRepository) List(ctx context.Context, filter MailOutboxFilter, page, pageSize int) ([]db_models.MailOutboxMessage, int64, error) {
+	var (
+		rows  []db_models.MailOutboxMessage
+		total int64
+	)
+
+	q := r.db.WithContext(ctx).Model(&db_models.MailOutboxMessage{})
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+	if filter.Recipient != "" {
+		q = q.Where("LOWER(recipient) = LOWER(?)", filter.Recipient)
+	}
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Attachments are only needed to send the message
+	err := q.Omit("Attachment").
+		Order("created_at DESC, id").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&rows).Error
+	return rows, total, err
+}
+
+func (r *MailOutboxRepository) Find(ctx context.Context, id string) (*db_models.MailOutboxMessage, error) {
+	var msg db_models.MailOutboxMessage
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&msg).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+func (r *MailOutboxRepository) Requeue(ctx context.Context, ids []uuid.UUID, now int64) (int64, error) {
+	q := r.db.WithContext(ctx).Model(&db_models.MailOutboxMessage{}).Where("status = ?", db_models.MailOutboxDead)
+	if len(ids) > 0 {
+		q = q.Where("id IN ?", ids)
+	}
+	res := q.Updates(map[string]any{
+		"status":          db_models.MailOutboxPending,
+		"attempts":        0,
+		"next_attempt_at": now,
+		"claimed_until":   nil,
+	})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"vivu/internal/models/db_models"
+)
+
+type NotificationRepositoryInterface interface {
+	Create(ctx context.Context, n *db_models.Notification) error
+	// List returns a page of the account's notifications, newest first, and the total count.
+	List(ctx context.Context, accountID uuid.UUID, unreadOnly bool, page, pageSize int) ([]db_models.Notification, int64, error)
+	CountUnread(ctx context.Context, accountID uuid.UUID) (int64, error)
+	// MarkRead marks a notification of the account read; false when there is no such
+	// notification. Reading it again keeps the first read time.
+	MarkRead(ctx context.Context, id, accountID uuid.UUID, at int64) (bool, error)
+}
+
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func (r *NotificationRepository) Create(ctx context.Context, n *db_models.Notification) error {
+	return r.db.WithContext(ctx).Omit("Account").Create(n).Error
+}
+
+func (r *NotificationRepository) List(ctx context.Context, accountID uuid.UUID, unreadOnly bool, page, pageSize int) ([]db_models.Notification, int64, error) {
+	var (
+		rows  []db_models.Notification
+		total int64
+	)
+
+	q := r.db.WithContext(ctx).Model(&db_models.Notification{}).Where("account_id = ?", accountID)
+	if unreadOnly {
+		q = q.Where("read_at IS NULL")
+	}
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := q.Order("created_at DESC, id").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&rows).Error
+	return rows, total, err
+}
+
+func (r *NotificationRepository) CountUnread(ctx context.Context, accountID uuid.UUID) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Model(&db_models.Notification{}).
+		Where("account_id = ? AND read_at IS NULL", accountID).
+		Count(&n).Error
+	return n, err
+}
+
+func (r *NotificationRepository) MarkRead(ctx context.Context, id, accountID uuid.UUID, at int64) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&db_models.Notification{}).
+		Where("id = ? AND account_id = ?", id, accountID).
+		Update("read_at", gorm.Expr("COALESCE(read_at, ?)", at))
+	return res.RowsAffected > 0, res.Error
+}
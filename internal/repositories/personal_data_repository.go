@@ -0,0 +1,233 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"vivu/internal/models/db_models"
+)
+
+// PersonalDataTable tags a table holding personal data with its owner and purpose. Owner selects
+// the rows of one account, with @account standing for its ID; rows about the account's own
+// journeys belong to it even when a companion wrote them.
+type PersonalDataTable struct {
+	Table   string
+	Owner   string
+	Purpose string
+	Erasure string
+	// Redact lists the columns blanked by an erasure that redacts the rows
+	Redact map[string]any
+	// Omit lists the columns left out of an export: secrets and hashes, not data about the person
+	Omit []string
+}
+
+const (
+	ownJourneys = `journey_id IN (SELECT id FROM journeys WHERE account_id = @account)`
+	ownCheckIns = `check_in_id IN (SELECT id FROM check_ins WHERE account_id = @account OR journey_id IN (SELECT id FROM journeys WHERE account_id = @account))`
+	ownPolls    = `poll_id IN (SELECT p.id FROM journey_polls p JOIN journeys j ON j.id = p.journey_id WHERE j.account_id = @account)`
+)
+
+// PersonalDataTables is every table holding personal data, in the order an erasure goes through
+// them: rows before the rows they reference, the account itself last.
+var PersonalDataTables = []PersonalDataTable{
+	{Table: "photos", Owner: ownCheckIns, Purpose: db_models.PurposeTripPlanning, Erasure: db_models.ErasureDelete},
+	{Table: "check_ins", Owner: `account_id = @account OR ` + ownJourneys, Purpose: db_models.PurposeTripPlanning, Erasure: db_models.ErasureDelete},
+	{Table: "journey_poll_votes", Owner: `account_id = @account OR ` + ownPolls, Purpose: db_models.PurposeCollaboration, Erasure: db_models.ErasureDelete},
+	{Table: "journey_poll_options", Owner: ownPolls, Purpose: db_models.PurposeCollaboration, Erasure: db_models.ErasureDelete},
+	{Table: "journey_polls", Owner: ownJourneys, Purpose: db_models.PurposeCollaboration, Erasure: db_models.ErasureDelete},
+	// Comments on the journeys of others keep their place in the thread, without their text
+	{Table: "journey_comments", Owner: ownJourneys, Purpose: db_models.PurposeCollaboration, Erasure: db_models.ErasureDelete},
+	{Table: "journey_comments", Owner: `account_id = @account`, Purpose: db_models.PurposeCollaboration, Erasure: db_models.ErasureRedact,
+		Redact: map[string]any{"body": "", "mentions": nil}},
+	{Table: "travel_documents", Owner: `account_id = @account OR ` + ownJourneys, Purpose: db_models.PurposeTripPlanning, Erasure: db_models.ErasureDelete},
+	{Table: "journey_inboxes", Owner: ownJourneys, Purpose: db_models.PurposeTripPlanning, Erasure: db_models.ErasureDelete},
+	// Expenses logged on the journeys of others stay in their budget, without the note
+	{Table: "journey_expenses", Owner: ownJourneys, Purpose: db_models.PurposeTripPlanning, Erasure: db_models.ErasureDelete},
+	{Table: "journey_expenses", Owner: `account_id = @account`, Purpose: db_models.PurposeTripPlanning, Erasure: db_models.ErasureRedact,
+		Redact: map[string]any{"note": ""}},
+	{Table: "journey_budgets", Owner: ownJourneys, Purpose: db_models.PurposeTripPlanning, Erasure: db_models.ErasureDelete},
+	{Table: "trip_reminders", Owner: ownJourneys, Purpose: db_models.PurposeMessaging, Erasure: db_models.ErasureDelete},
+	{Table: "journey_members", Owner: `account_id = @account OR ` + ownJourneys, Purpose: db_models.PurposeCollaboration, Erasure: db_models.ErasureDelete},
+	{Table: "journey_activities", Owner: `journey_day_id IN (SELECT d.id FROM journey_days d JOIN journeys j ON j.id = d.journey_id WHERE j.account_id = @account)`, Purpose: db_models.PurposeTripPlanning, Erasure: db_models.ErasureDelete},
+	{Table: "journey_days", Owner: ownJourneys, Purpose: db_models.PurposeTripPlanning, Erasure: db_models.ErasureDelete},
+	{Table: "journey_backup_jobs", Owner: `account_id = @account`, Purpose: db_models.PurposeTripPlanning, Erasure: db_models.ErasureDelete},
+	{Table: "journeys", Owner: `account_id = @account`, Purpose: db_models.PurposeTripPlanning, Erasure: db_models.ErasureDelete},
+	{Table: "travel_presets", Owner: `account_id = @account`, Purpose: db_models.PurposeTripPlanning, Erasure: db_models.ErasureDelete},
+	// Slow query captures can hold the prompt typed and its embedding in the bound SQL and plan
+	{Table: "query_diagnostics", Owner: `owner_account_id = @account`, Purpose: db_models.PurposeDiagnostics, Erasure: db_models.ErasureRedact,
+		Redact: map[string]any{"bound_sql": "", "plan": "", "owner_account_id": nil, "purpose": ""}},
+	{Table: "feedbacks", Owner: `user_id = @account`, Purpose: db_models.PurposeSupport, Erasure: db_models.ErasureDelete},
+	{Table: "notifications", Owner: `account_id = @account`, Purpose: db_models.PurposeMessaging, Erasure: db_models.ErasureDelete},
+	{Table: "mail_outbox_messages", Owner: `recipient = (SELECT email FROM accounts WHERE id = @account)`, Purpose: db_models.PurposeMessaging, Erasure: db_models.ErasureDelete,
+		Omit: []string{"code"}},
+	{Table: "webhook_deliveries", Owner: `endpoint_id IN (SELECT id FROM webhook_endpoints WHERE account_id = @account)`, Purpose: db_models.PurposeMessaging, Erasure: db_models.ErasureDelete},
+	{Table: "webhook_endpoints", Owner: `account_id = @account`, Purpose: db_models.PurposeMessaging, Erasure: db_models.ErasureDelete,
+		Omit: []string{"secret"}},
+	{Table: "api_key_usages", Owner: `api_key_id IN (SELECT id FROM api_keys WHERE account_id = @account)`, Purpose: db_models.PurposeIntegrations, Erasure: db_models.ErasureDelete},
+	{Table: "planning_policies", Owner: `account_id = @account`, Purpose: db_models.PurposeIntegrations, Erasure: db_models.ErasureDelete},
+	{Table: "api_keys", Owner: `account_id = @account`, Purpose: db_models.PurposeIntegrations, Erasure: db_models.ErasureDelete,
+		Omit: []string{"key_hash"}},
+	{Table: "plan_usages", Owner: `account_id = @account`, Purpose: db_models.PurposeAnalytics, Erasure: db_models.ErasureDelete},
+	{Table: "churn_scores", Owner: `account_id = @account`, Purpose: db_models.PurposeAnalytics, Erasure: db_models.ErasureDelete},
+	{Table: "booking_events", Owner: `account_id = @account`, Purpose: db_models.PurposeAnalytics, Erasure: db_models.ErasureDelete},
+	{Table: "subscription_plan_changes", Owner: `account_id = @account`, Purpose: db_models.PurposeBilling, Erasure: db_models.ErasureRetain},
+	{Table: "transactions", Owner: `account_id = @account`, Purpose: db_models.PurposeBilling, Erasure: db_models.ErasureRetain},
+	{Table: "subscriptions", Owner: `account_id = @account`, Purpose: db_models.PurposeBilling, Erasure: db_models.ErasureRetain},
+	{Table: "legal_acceptances", Owner: `account_id = @account`, Purpose: db_models.PurposeLegal, Erasure: db_models.ErasureRetain},
+	{Table: "accounts", Owner: `id = @account`, Purpose: db_models.PurposeAccount, Erasure: db_models.ErasureRedact,
+		Redact: map[string]any{
+			"name":                  "",
+			"email":                 gorm.Expr("'erased-' || id::text || '@erased.invalid'"),
+			"password_hash":         "",
+			"avatar_url":            "",
+			"birth_year":            nil,
+			"day_start":             "",
+			"day_end":               "",
+			"companions":            nil,
+			"avoid":                 nil,
+			"ban_reason":            "",
+			"totp_secret":           "",
+			"totp_enabled":          false,
+			"totp_backup_codes":     nil,
+			"subscription_snapshot": "{}",
+		},
+		Omit: []string{"password_hash", "totp_secret", "totp_backup_codes", "totp_last_step"}},
+}
+
+// PersonalDataRows is the export of one table: the account's rows, column by column.
+type PersonalDataRows struct {
+	Table   string
+	Purpose string
+	Rows    []map[string]any
+}
+
+// PersonalDataReport sums row counts, in PersonalDataTables order, per table for the audit trail.
+func PersonalDataReport(counts []int64) datatypes.JSON {
+	report := make(map[string]int64, len(counts))
+	for i, n := range counts {
+		report[PersonalDataTables[i].Table] += n
+	}
+	b, _ := json.Marshal(report)
+	return b
+}
+
+type PersonalDataRepositoryInterface interface {
+	// CountOwned counts the account's rows per table, in PersonalDataTables order.
+	CountOwned(ctx context.Context, accountID uuid.UUID) ([]int64, error)
+	// ExportTable returns the account's rows of the table at index i of PersonalDataTables.
+	ExportTable(ctx context.Context, i int, accountID uuid.UUID) (*PersonalDataRows, error)
+	// StorageKeys lists the stored files of the account's travel documents and journey backups,
+	// read before erasing.
+	StorageKeys(ctx context.Context, accountID uuid.UUID) ([]string, error)
+	// Erase deletes or redacts every row of the account, anonymizes it and soft-deletes it, all
+	// in one transaction with its audit row. It returns the rows touched per table.
+	Erase(ctx context.Context, accountID uuid.UUID, requestedBy string) ([]int64, error)
+	InsertRequest(ctx context.Context, audit *db_models.PersonalDataRequest) error
+}
+
+type PersonalDataRepository struct {
+	db *gorm.DB
+}
+
+func NewPersonalDataRepository(db *gorm.DB) *PersonalDataRepository {
+	return &PersonalDataRepository{db: db}
+}
+
+func (r *PersonalDataRepository) owned(db *gorm.DB, t PersonalDataTable, accountID uuid.UUID) *gorm.DB {
+	// Soft-deleted rows are personal data all the same
+	return db.Table(t.Table).Where("("+t.Owner+")", map[string]any{"account": accountID})
+}
+
+func (r *PersonalDataRepository) CountOwned(ctx context.Context, accountID uuid.UUID) ([]int64, error) {
+	db := r.db.WithContext(ctx)
+	out := make([]int64, len(PersonalDataTables))
+	for i, t := range PersonalDataTables {
+		if err := r.owned(db, t, accountID).Count(&out[i]).Error; err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (r *PersonalDataRepository) ExportTable(ctx context.Context, i int, accountID uuid.UUID) (*PersonalDataRows, error) {
+	t := PersonalDataTables[i]
+	var rows []map[string]any
+	if err := r.owned(r.db.WithContext(ctx), t, accountID).Order("created_at").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		for _, col := range t.Omit {
+			delete(row, col)
+		}
+	}
+	return &PersonalDataRows{Table: t.Table, Purpose: t.Purpose, Rows: rows}, nil
+}
+
+func (r *PersonalDataRepository) StorageKeys(ctx context.Context, accountID uuid.UUID) ([]string, error) {
+	var keys, backups []string
+	err := r.db.WithContext(ctx).
+		Table("travel_documents").
+		Where("account_id = @account OR "+ownJourneys, map[string]any{"account": accountID}).
+		Where("storage_key <> ''").
+		Pluck("storage_key", &keys).Error
+	if err != nil {
+		return nil, err
+	}
+	err = r.db.WithContext(ctx).
+		Table("journey_backup_jobs").
+		Where("account_id = ? AND storage_key <> ''", accountID).
+		Pluck("storage_key", &backups).Error
+	return append(keys, backups...), err
+}
+
+func (r *PersonalDataRepository) Erase(ctx context.Context, accountID uuid.UUID, requestedBy string) ([]int64, error) {
+	touched := make([]int64, len(PersonalDataTables))
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the account so a concurrent merge or a login cannot interleave
+		var acc db_models.Account
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", accountID).First(&acc).Error; err != nil {
+			return err
+		}
+
+		for i, t := range PersonalDataTables {
+			var res *gorm.DB
+			switch t.Erasure {
+			case db_models.ErasureDelete:
+				res = r.owned(tx, t, accountID).Delete(map[string]any{})
+			case db_models.ErasureRedact:
+				res = r.owned(tx, t, accountID).Updates(t.Redact)
+			default:
+				continue
+			}
+			if res.Error != nil {
+				return res.Error
+			}
+			touched[i] = res.RowsAffected
+		}
+
+		// Tokens issued so far stop working; the anonymized account cannot be logged into again
+		now := time.Now()
+		if err := tx.Model(&db_models.Account{}).Where("id = ?", accountID).
+			Updates(map[string]any{"sessions_revoked_at": now.Unix(), "deleted_at": now}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&db_models.PersonalDataRequest{
+			AccountID:   accountID,
+			Kind:        db_models.PersonalDataErasure,
+			RequestedBy: requestedBy,
+			Report:      PersonalDataReport(touched),
+		}).Error
+	})
+
+	return touched, err
+}
+
+func (r *PersonalDataRepository) InsertRequest(ctx context.Context, audit *db_models.PersonalDataRequest) error {
+	return r.db.WithContext(ctx).Create(audit).Error
+}
@@ -3,13 +3,44 @@ package repositories
 import (
 	"context"
 	"errors"
+
 	"gorm.io/gorm"
 	"vivu/internal/models/db_models"
 )
 
+// activeSubscriptionStatuses are the statuses that still grant a plan, or will again once a
+// pause ends; a paused subscription runs whatever its EndsAt.
+var activeSubscriptionStatuses = []db_models.SubscriptionStatus{
+	db_models.SubStatusActive, db_models.SubStatusTrialing, db_models.SubStatusPastDue, db_models.SubStatusPaused,
+}
+
+// PlanCatalogRow is a plan with the number of subscriptions still running on it.
+type PlanCatalogRow struct {
+	db_models.Plan
+	ActiveSubscriptions int64
+}
+
 type IPlanRepository interface {
 	GetPlanInfoById(ctx context.Context, planID string) (*db_models.Plan, error)
 	GetAllPlans(ctx context.Context) ([]db_models.Plan, error)
+
+	// ListCatalog returns every plan, active ones first, with its running subscriptions.
+	ListCatalog(ctx context.Context, now int64) ([]PlanCatalogRow, error)
+	CodeExists(ctx context.Context, code string) (bool, error)
+	CreatePlan(ctx context.Context, plan *db_models.Plan) error
+	// UpdatePlan saves every editable field of the plan.
+	UpdatePlan(ctx context.Context, plan *db_models.Plan) (bool, error)
+	// CreateVersion stores next and retires previous in one transaction.
+	CreateVersion(ctx context.Context, previous, next *db_models.Plan) error
+	DeletePlan(ctx context.Context, planID string) (bool, error)
+	// CountActiveSubscriptions counts subscriptions on the plan that have not ended at now.
+	CountActiveSubscriptions(ctx context.Context, planID string, now int64) (int64, error)
+	// CountSubscriptions counts every subscription ever made on the plan.
+	CountSubscriptions(ctx context.Context, planID string) (int64, error)
+
+	InsertAudit(ctx context.Context, entry *db_models.PlanAuditLog) error
+	// ListAudit returns the audit trail of a plan, newest first.
+	ListAudit(ctx context.Context, planID string, limit int) ([]db_models.PlanAuditLog, error)
 }
 
 type PlanRepository struct {
@@ -46,3 +77,78 @@ func (p PlanRepository) GetAllPlans(ctx context.Context) ([]db_models.Plan, erro
 
 	return plans, nil
 }
+
+func (p PlanRepository) ListCatalog(ctx context.Context, now int64) ([]PlanCatalogRow, error) {
+	var rows []PlanCatalogRow
+	err := p.db.WithContext(ctx).
+		Model(&db_models.Plan{}).
+		Select(`plans.*, (SELECT count(*) FROM subscriptions s
+			WHERE s.plan_id = plans.id AND s.deleted_at IS NULL AND s.status IN ?
+				AND (s.ends_at > ? OR s.status = ?)) AS active_subscriptions`,
+			activeSubscriptionStatuses, now, db_models.SubStatusPaused).
+		Order("plans.is_active DESC, plans.code, plans.version DESC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (p PlanRepository) CodeExists(ctx context.Context, code string) (bool, error) {
+	var n int64
+	err := p.db.WithContext(ctx).Unscoped().Model(&db_models.Plan{}).Where("code = ?", code).Count(&n).Error
+	return n > 0, err
+}
+
+func (p PlanRepository) CreatePlan(ctx context.Context, plan *db_models.Plan) error {
+	return p.db.WithContext(ctx).Create(plan).Error
+}
+
+func (p PlanRepository) UpdatePlan(ctx context.Context, plan *db_models.Plan) (bool, error) {
+	res := p.db.WithContext(ctx).Model(plan).
+		Select("name", "description", "background_image", "period", "price_minor", "currency",
+			"trial_days", "is_active", "features", "updated_at").
+		Updates(plan)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (p PlanRepository) CreateVersion(ctx context.Context, previous, next *db_models.Plan) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		return tx.Model(previous).Update("is_active", false).Error
+	})
+}
+
+func (p PlanRepository) DeletePlan(ctx context.Context, planID string) (bool, error) {
+	res := p.db.WithContext(ctx).Where("id = ?", planID).Delete(&db_models.Plan{})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (p PlanRepository) CountActiveSubscriptions(ctx context.Context, planID string, now int64) (int64, error) {
+	var n int64
+	err := p.db.WithContext(ctx).Model(&db_models.Subscription{}).
+		Where("plan_id = ? AND status IN ? AND (ends_at > ? OR status = ?)", planID, activeSubscriptionStatuses, now, db_models.SubStatusPaused).
+		Count(&n).Error
+	return n, err
+}
+
+func (p PlanRepository) CountSubscriptions(ctx context.Context, planID string) (int64, error) {
+	var n int64
+	err := p.db.WithContext(ctx).Unscoped().Model(&db_models.Subscription{}).
+		Where("plan_id = ?", planID).
+		Count(&n).Error
+	return n, err
+}
+
+func (p PlanRepository) InsertAudit(ctx context.Context, entry *db_models.PlanAuditLog) error {
+	return p.db.WithContext(ctx).Create(entry).Error
+}
+
+func (p PlanRepository) ListAudit(ctx context.Context, planID string, limit int) ([]db_models.PlanAuditLog, error) {
+	var entries []db_models.PlanAuditLog
+	err := p.db.WithContext(ctx).
+		Where("plan_id = ?", planID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&entries).Error
+	return entries, err
+}
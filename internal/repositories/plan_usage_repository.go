@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"vivu/internal/models/db_models"
+)
+
+type PlanUsageRepositoryInterface interface {
+	// CountForMonth returns how many plans the account generated in month (YYYY-MM).
+	CountForMonth(ctx context.Context, accountID uuid.UUID, month string) (int, error)
+	// Reserve counts one plan against month unless the account already reached limit, in a
+	// single statement; false means the limit was reached.
+	Reserve(ctx context.Context, accountID uuid.UUID, month string, limit int) (bool, error)
+	// Release gives back one plan of month.
+	Release(ctx context.Context, accountID uuid.UUID, month string) error
+}
+
+type PlanUsageRepository struct {
+	db *gorm.DB
+}
+
+func NewPlanUsageRepository(db *gorm.DB) *PlanUsageRepository {
+	return &PlanUsageRepository{db: db}
+}
+
+func (r *PlanUsageRepository) CountForMonth(ctx context.Context, accountID uuid.UUID, month string) (int, error) {
+	var usage db_models.PlanUsage
+	err := r.db.WithContext(ctx).
+		Where("account_id = ? AND month = ?", accountID, month).
+		First(&usage).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return usage.Count, nil
+}
+
+func (r *PlanUsageRepository) Reserve(ctx context.Context, accountID uuid.UUID, month string, limit int) (bool, error) {
+	res := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "account_id"}, {Name: "month"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"count":      gorm.Expr("plan_usages.count + 1"),
+			"updated_at": gorm.Expr("EXCLUDED.updated_at"),
+		}),
+		// The row is locked by the upsert, so concurrent reservations queue up on it
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: "plan_usages.count < ?", Vars: []any{limit}},
+		}},
+	}).Create(&db_models.PlanUsage{AccountID: accountID, Month: month, Count: 1})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *PlanUsageRepository) Release(ctx context.Context, accountID uuid.UUID, month string) error {
+	return r.db.WithContext(ctx).
+		Model(&db_models.PlanUsage{}).
+		Where("account_id = ? AND month = ? AND count > 0", accountID, month).
+		Update("count", gorm.Expr("count - 1")).Error
+}
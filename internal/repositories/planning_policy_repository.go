@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"vivu/internal/models/db_models"
+)
+
+type PlanningPolicyRepositoryInterface interface {
+	// FindByAccountID returns the account's policy, or nil when it has none.
+	FindByAccountID(ctx context.Context, accountID uuid.UUID) (*db_models.PlanningPolicy, error)
+	UpsertPolicy(ctx context.Context, policy *db_models.PlanningPolicy) error
+	DeleteByAccountID(ctx context.Context, accountID uuid.UUID) (bool, error)
+}
+
+type PlanningPolicyRepository struct {
+	db *gorm.DB
+}
+
+func NewPlanningPolicyRepository(db *gorm.DB) *PlanningPolicyRepository {
+	return &PlanningPolicyRepository{db: db}
+}
+
+func (r *PlanningPolicyRepository) FindByAccountID(ctx context.Context, accountID uuid.UUID) (*db_models.PlanningPolicy, error) {
+	var policy db_models.PlanningPolicy
+	err := r.db.WithContext(ctx).Where("account_id = ?", accountID).First(&policy).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *PlanningPolicyRepository) UpsertPolicy(ctx context.Context, policy *db_models.PlanningPolicy) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "account_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"exclude", "boost_poi_ids", "require_poi_ids", "updated_by", "updated_at", "deleted_at",
+		}),
+	}).Create(policy).Error
+}
+
+func (r *PlanningPolicyRepository) DeleteByAccountID(ctx context.Context, accountID uuid.UUID) (bool, error) {
+	res := r.db.WithContext(ctx).Where("account_id = ?", accountID).Delete(&db_models.PlanningPolicy{})
+	return res.RowsAffected > 0, res.Error
+}
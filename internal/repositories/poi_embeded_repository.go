@@ -1,6 +1,11 @@
 package repositories
 
 import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/lib/pq"
 	"github.com/pgvector/pgvector-go"
 	"gorm.io/gorm"
 	"vivu/internal/models/db_models"
@@ -10,6 +15,26 @@ type IPoiEmbededRepository interface {
 	GetPoiEmbededByID(poiEmbededID int) (poiEmbeded db_models.PoiEmbedding, err error)
 	GetListOfPoiEmbededByVector(vector pgvector.Vector, filter interface{}) (poiEmbededs []db_models.PoiEmbedding, err error)
 	CreatePoiEmbeded(poiEmbeded db_models.PoiEmbedding) error
+	HybridSearchPOIs(ctx context.Context, vector *pgvector.Vector, queryText string, opts HybridSearchOptions) ([]PoiSearchHit, error)
+}
+
+// RRFK is the k constant of reciprocal-rank fusion: score = Σ 1/(k + rank).
+// 60 is the value from the original RRF paper and works well without tuning.
+const RRFK = 60
+
+type HybridSearchOptions struct {
+	Limit       int      // fused results to return (default 20)
+	Candidates  int      // candidates taken from each ranking before fusion (default 50)
+	ProvinceIDs []string // optional filter
+}
+
+// PoiSearchHit is one fused result. A nil rank means the POI did not appear in that ranking.
+type PoiSearchHit struct {
+	PoiID       string
+	Score       float64
+	VectorRank  *int
+	KeywordRank *int
+	Similarity  *float64 // cosine similarity to the query vector, with VectorRank
 }
 
 type PoiEmbededRepository struct {
@@ -41,6 +66,138 @@ func (p *PoiEmbededRepository) GetListOfPoiEmbededByVector(vector pgvector.Vecto
 	return results, nil
 }
 
+// HybridSearchPOIs ranks POIs by embedding similarity and by full-text match in a single query and
+// fuses both rankings with reciprocal-rank fusion. vector may be nil (e.g. the embedding call failed),
+// in which case only the keyword ranking contributes.
+func (p *PoiEmbededRepository) HybridSearchPOIs(ctx context.Context, vector *pgvector.Vector, queryText string, opts HybridSearchOptions) ([]PoiSearchHit, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = 20
+	}
+	if opts.Candidates <= 0 {
+		opts.Candidates = 50
+	}
+
+	tsQuery := BuildOrTsQuery(queryText)
+	if vector == nil && tsQuery == "" {
+		return nil, nil
+	}
+
+	vecCTE := `SELECT NULL::text AS poi_id, NULL::bigint AS rnk, NULL::float8 AS similarity WHERE false`
+	args := map[string]interface{}{
+		"tsq":       tsQuery,
+		"provinces": pq.StringArray(opts.ProvinceIDs),
+		"cand":      opts.Candidates,
+		"k":         RRFK,
+		"lim":       opts.Limit,
+	}
+	if vector != nil {
+		vecCTE = `
+            SELECT e.poi_id,
+                   ROW_NUMBER() OVER (ORDER BY e.embedding <=> CAST(@vec AS vector)) AS rnk,
+                   1 - (e.embedding <=> CAST(@vec AS vector)) AS similarity
+            FROM poi_embeddings e
+            JOIN pois p ON p.id::text = e.poi_id AND p.deleted_at IS NULL
+            WHERE (cardinality(CAST(@provinces AS text[])) = 0 OR p.province_id::text = ANY(CAST(@provinces AS text[])))
+            ORDER BY e.embedding <=> CAST(@vec AS vector)
+            LIMIT @cand`
+		args["vec"] = vector.String()
+	}
+
+	kwCTE := `SELECT NULL::text AS poi_id, NULL::bigint AS rnk WHERE false`
+	if tsQuery != "" {
+		// Name matches weigh more than category, which weigh more than description/address
+		kwCTE = `
+            SELECT p.id::text AS poi_id,
+                   ROW_NUMBER() OVER (ORDER BY ts_rank_cd(d.doc, q) DESC) AS rnk
+            FROM pois p
+            LEFT JOIN categories c ON c.id = p.category_id
+            CROSS JOIN to_tsquery('simple', @tsq) q
+            CROSS JOIN LATERAL (
+                SELECT setweight(to_tsvector('simple', coalesce(p.name, '')), 'A') ||
+                       setweight(to_tsvector('simple', coalesce(c.name, '')), 'B') ||
+                       setweight(to_tsvector('simple', coalesce(p.description, '') || ' ' || coalesce(p.address, '')), 'C') AS doc
+            ) d
+            WHERE p.deleted_at IS NULL
+              AND (cardinality(CAST(@provinces AS text[])) = 0 OR p.province_id::text = ANY(CAST(@provinces AS text[])))
+              AND d.doc @@ q
+            ORDER BY ts_rank_cd(d.doc, q) DESC
+            LIMIT @cand`
+	}
+
+	query := `
+        WITH vec AS (` + vecCTE + `
+        ),
+        kw AS (` + kwCTE + `
+        )
+        SELECT COALESCE(vec.poi_id, kw.poi_id) AS poi_id,
+               COALESCE(1.0 / (@k + vec.rnk), 0) + COALESCE(1.0 / (@k + kw.rnk), 0) AS score,
+               vec.rnk AS vector_rank,
+               kw.rnk AS keyword_rank,
+               vec.similarity AS similarity
+        FROM vec
+        FULL OUTER JOIN kw ON kw.poi_id = vec.poi_id
+        ORDER BY score DESC
+        LIMIT @lim
+    `
+
+	var hits []PoiSearchHit
+	err := p.db.WithContext(ctx).Raw(query, args).Scan(&hits).Error
+	if err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
+
+// BuildOrTsQuery turns free text into a to_tsquery('simple', ...) expression that matches any
+// of its words ("a | b | c"). Prompts are long sentences; AND-ing their words would match nothing.
+func BuildOrTsQuery(text string) string {
+	return strings.Join(tsQueryTerms(text), " | ")
+}
+
+// BuildPrefixTsQuery is for search boxes: every word must match, each as a prefix ("ho:* & xuan:*"),
+// so results show up while the user is still typing.
+func BuildPrefixTsQuery(text string) string {
+	terms := tsQueryTerms(text)
+	for i := range terms {
+		terms[i] += ":*"
+	}
+	return strings.Join(terms, " & ")
+}
+
+// tsQueryTerms splits text into lower-case words, dropping stopwords and duplicates. Everything
+// except letters and digits is removed, so the terms are always safe inside a tsquery.
+func tsQueryTerms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	seen := make(map[string]struct{}, len(words))
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		if len([]rune(w)) < 2 {
+			continue
+		}
+		if _, stop := tsStopwords[w]; stop {
+			continue
+		}
+		if _, dup := seen[w]; dup {
+			continue
+		}
+		seen[w] = struct{}{}
+		terms = append(terms, w)
+	}
+	return terms
+}
+
+// Words too common in travel prompts to say anything about a POI.
+var tsStopwords = map[string]struct{}{
+	"the": {}, "and": {}, "for": {}, "with": {}, "to": {}, "in": {}, "of": {}, "on": {}, "at": {},
+	"a": {}, "an": {}, "my": {}, "me": {}, "we": {}, "our": {}, "is": {}, "are": {}, "be": {},
+	"want": {}, "would": {}, "like": {}, "plan": {}, "trip": {}, "travel": {}, "day": {}, "days": {},
+	"some": {}, "go": {}, "visit": {}, "please": {}, "can": {}, "you": {}, "i": {},
+	"tôi": {}, "muốn": {}, "đi": {}, "và": {}, "có": {}, "ngày": {}, "cho": {}, "một": {}, "những": {}, "các": {},
+}
+
 func (p *PoiEmbededRepository) CreatePoiEmbeded(poiEmbeded db_models.PoiEmbedding) error {
 	return p.db.Create(&poiEmbeded).Error
 }
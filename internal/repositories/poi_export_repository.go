@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"vivu/internal/models/db_models"
+)
+
+// POIExportFilter narrows an export; zero values mean "any".
+type POIExportFilter struct {
+	ProvinceID   *uuid.UUID
+	CategoryID   *uuid.UUID
+	UpdatedSince int64 // unix seconds
+}
+
+type POIExportRepositoryInterface interface {
+	// StreamPOIs hands the matching POIs to fn in batches, ordered by id, with their
+	// province, category, details and tags loaded. An error from fn stops the scan.
+	StreamPOIs(ctx context.Context, filter POIExportFilter, batchSize int, fn func([]db_models.POI) error) error
+}
+
+type POIExportRepository struct {
+	db *gorm.DB
+}
+
+func NewPOIExportRepository(db *gorm.DB) *POIExportRepository {
+	return &POIExportRepository{db: db}
+}
+
+func (r *POIExportRepository) StreamPOIs(ctx context.Context, filter POIExportFilter, batchSize int, fn func([]db_models.POI) error) error {
+	q := r.db.WithContext(ctx).
+		Model(&db_models.POI{}).
+		Preload("Province", func(db *gorm.DB) *gorm.DB { return db.Select("id", "name") }).
+		Preload("Category", func(db *gorm.DB) *gorm.DB { return db.Select("id", "name") }).
+		Preload("Details").
+		Preload("Tags")
+	if filter.ProvinceID != nil {
+		q = q.Where("province_id = ?", *filter.ProvinceID)
+	}
+	if filter.CategoryID != nil {
+		q = q.Where("category_id = ?", *filter.CategoryID)
+	}
+	if filter.UpdatedSince > 0 {
+		q = q.Where("updated_at >= ?", filter.UpdatedSince)
+	}
+
+	var batch []db_models.POI
+	return q.FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	}).Error
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"vivu/internal/models/db_models"
+)
+
+type POIExternalRefRepositoryInterface interface {
+	UpsertRef(ctx context.Context, ref *db_models.POIExternalRef) error
+	ListRefsByPOI(ctx context.Context, poiID uuid.UUID) ([]db_models.POIExternalRef, error)
+	// ListStaleRefs returns refs never fetched or fetched before staleBefore, oldest first.
+	// Refs touched (attempted) after retryBefore are skipped so a failing listing is not retried every tick.
+	ListStaleRefs(ctx context.Context, staleBefore, retryBefore int64, limit int) ([]db_models.POIExternalRef, error)
+	SaveSnapshot(ctx context.Context, ref *db_models.POIExternalRef) error
+}
+
+type POIExternalRefRepository struct {
+	db *gorm.DB
+}
+
+func NewPOIExternalRefRepository(db *gorm.DB) *POIExternalRefRepository {
+	return &POIExternalRefRepository{db: db}
+}
+
+func (r *POIExternalRefRepository) UpsertRef(ctx context.Context, ref *db_models.POIExternalRef) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "poi_id"}, {Name: "source"}},
+		// A new external ID invalidates the previous snapshot
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"external_id":  ref.ExternalID,
+			"url":          ref.URL,
+			"rating":       nil,
+			"review_count": nil,
+			"fetched_at":   nil,
+			"last_error":   "",
+			"updated_at":   time.Now().Unix(),
+			"deleted_at":   nil,
+		}),
+	}).Create(ref).Error
+}
+
+func (r *POIExternalRefRepository) ListRefsByPOI(ctx context.Context, poiID uuid.UUID) ([]db_models.POIExternalRef, error) {
+	var refs []db_models.POIExternalRef
+	err := r.db.WithContext(ctx).Where("poi_id = ?", poiID).Order("source ASC").Find(&refs).Error
+	return refs, err
+}
+
+func (r *POIExternalRefRepository) ListStaleRefs(ctx context.Context, staleBefore, retryBefore int64, limit int) ([]db_models.POIExternalRef, error) {
+	var refs []db_models.POIExternalRef
+	err := r.db.WithContext(ctx).
+		Where("(fetched_at IS NULL OR fetched_at < ?) AND updated_at < ?", staleBefore, retryBefore).
+		Order("fetched_at ASC NULLS FIRST").
+		Limit(limit).
+		Find(&refs).Error
+	return refs, err
+}
+
+func (r *POIExternalRefRepository) SaveSnapshot(ctx context.Context, ref *db_models.POIExternalRef) error {
+	return r.db.WithContext(ctx).
+		Model(&db_models.POIExternalRef{}).
+		Where("id = ?", ref.ID).
+		Updates(map[string]interface{}{
+			"rating":       ref.Rating,
+			"review_count": ref.ReviewCount,
+			"url":          ref.URL,
+			"fetched_at":   ref.FetchedAt,
+			"last_error":   ref.LastError,
+		}).Error
+}
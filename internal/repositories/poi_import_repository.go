@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"vivu/internal/models/db_models"
+)
+
+type POIImportRepositoryInterface interface {
+	ListProvinces(ctx context.Context) ([]db_models.Province, error)
+	ListCategories(ctx context.Context) ([]db_models.Category, error)
+	// ExistingPOINames returns lower-cased "province_id|name" keys of the POIs already in the given provinces.
+	ExistingPOINames(ctx context.Context, provinceIDs []uuid.UUID) (map[string]struct{}, error)
+	// InsertPOIs creates the POIs in batches and queues their embeddings, all in one transaction.
+	InsertPOIs(ctx context.Context, pois []*db_models.POI, batchSize int) error
+}
+
+type POIImportRepository struct {
+	db *gorm.DB
+}
+
+func NewPOIImportRepository(db *gorm.DB) *POIImportRepository {
+	return &POIImportRepository{db: db}
+}
+
+func (r *POIImportRepository) ListProvinces(ctx context.Context) ([]db_models.Province, error) {
+	var out []db_models.Province
+	err := r.db.WithContext(ctx).Select("id", "name").Find(&out).Error
+	return out, err
+}
+
+func (r *POIImportRepository) ListCategories(ctx context.Context) ([]db_models.Category, error) {
+	var out []db_models.Category
+	err := r.db.WithContext(ctx).Select("id", "name").Find(&out).Error
+	return out, err
+}
+
+func (r *POIImportRepository) ExistingPOINames(ctx context.Context, provinceIDs []uuid.UUID) (map[string]struct{}, error) {
+	out := make(map[string]struct{})
+	if len(provinceIDs) == 0 {
+		return out, nil
+	}
+	var rows []struct {
+		ProvinceID uuid.UUID
+		Name       string
+	}
+	err := r.db.WithContext(ctx).
+		Model(&db_models.POI{}).
+		Select("province_id, name").
+		Where("province_id IN ?", provinceIDs).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		out[POIImportKey(row.ProvinceID, row.Name)] = struct{}{}
+	}
+	return out, nil
+}
+
+func (r *POIImportRepository) InsertPOIs(ctx context.Context, pois []*db_models.POI, batchSize int) error {
+	if len(pois) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(pois, batchSize).Error; err != nil {
+			return err
+		}
+
+		outbox := make([]db_models.POIEmbeddingOutbox, 0, len(pois))
+		for _, p := range pois {
+			outbox = append(outbox, db_models.POIEmbeddingOutbox{POIID: p.ID, Reason: db_models.EmbeddingReasonImport})
+		}
+		return tx.CreateInBatches(outbox, batchSize).Error
+	})
+}
+
+// POIImportKey is the duplicate-detection key of a POI: same name in the same province.
+func POIImportKey(provinceID uuid.UUID, name string) string {
+	return provinceID.String() + "|" + strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"vivu/internal/models/db_models"
+)
+
+// POILocation is what the province check needs of a POI.
+type POILocation struct {
+	ID         uuid.UUID
+	Latitude   float64
+	Longitude  float64
+	ProvinceID uuid.UUID
+}
+
+// POIProvinceFlagFilter narrows the correction queue; zero values mean "any".
+type POIProvinceFlagFilter struct {
+	Status     string
+	Kind       string
+	ProvinceID *uuid.UUID
+}
+
+type POIProvinceFlagRepositoryInterface interface {
+	// POILocationsAfter returns live POIs ordered by id, starting after afterID.
+	POILocationsAfter(ctx context.Context, afterID uuid.UUID, limit int) ([]POILocation, error)
+	// SaveFlags records mismatches. An existing flag is replaced while open; a dismissed or
+	// applied one only when the POI moved since, so a dismissal sticks to an unchanged POI.
+	SaveFlags(ctx context.Context, flags []db_models.POIProvinceFlag) error
+	// ClearFlags closes the open flags of POIs that now match their province.
+	ClearFlags(ctx context.Context, poiIDs []uuid.UUID, at int64) (int64, error)
+	// ListFlags returns a page of flags of live POIs, newest first, with the POI and provinces loaded.
+	ListFlags(ctx context.Context, filter POIProvinceFlagFilter, page, pageSize int) ([]db_models.POIProvinceFlag, int64, error)
+	FindFlag(ctx context.Context, id string) (*db_models.POIProvinceFlag, error)
+	// ApplyFlag writes the suggested correction to the POI, if it is still as it was checked,
+	// and marks the flag applied. found is false when the POI or the flag changed meanwhile.
+	ApplyFlag(ctx context.Context, flag *db_models.POIProvinceFlag, resolvedBy string, at int64) (bool, error)
+	// ResolveFlag moves an open flag to status.
+	ResolveFlag(ctx context.Context, id, status, resolvedBy string, at int64) (bool, error)
+}
+
+type POIProvinceFlagRepository struct {
+	db *gorm.DB
+}
+
+func NewPOIProvinceFlagRepository(db *gorm.DB) *POIProvinceFlagRepository {
+	return &POIProvinceFlagRepository{db: db}
+}
+
+func (r *POIProvinceFlagRepository) POILocationsAfter(ctx context.Context, afterID uuid.UUID, limit int) ([]POILocation, error) {
+	var out []POILocation
+	err := r.db.WithContext(ctx).Model(&db_models.POI{}).
+		Select("id", "latitude", "longitude", "province_id").
+		Where("id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Scan(&out).Error
+	return out, err
+}
+
+func (r *POIProvinceFlagRepository) SaveFlags(ctx context.Context, flags []db_models.POIProvinceFlag) error {
+	if len(flags) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "poi_id"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"kind":                  gorm.Expr("excluded.kind"),
+			"status":                db_models.POIProvinceFlagOpen,
+			"latitude":              gorm.Expr("excluded.latitude"),
+			"longitude":             gorm.Expr("excluded.longitude"),
+			"province_id":           gorm.Expr("excluded.province_id"),
+			"suggested_province_id": gorm.Expr("excluded.suggested_province_id"),
+			"suggested_latitude":    gorm.Expr("excluded.suggested_latitude"),
+			"suggested_longitude":   gorm.Expr("excluded.suggested_longitude"),
+			// an open flag keeps the time it was first raised
+			"detected_at": gorm.Expr("CASE WHEN poi_province_flags.status = ? THEN poi_province_flags.detected_at ELSE excluded.detected_at END", db_models.POIProvinceFlagOpen),
+			"resolved_at": nil,
+			"resolved_by": "",
+			"updated_at":  gorm.Expr("excluded.updated_at"),
+			"deleted_at":  nil,
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{gorm.Expr(
+			`poi_province_flags.status = ? OR poi_province_flags.deleted_at IS NOT NULL
+			OR (poi_province_flags.latitude, poi_province_flags.longitude, poi_province_flags.province_id)
+				IS DISTINCT FROM (excluded.latitude, excluded.longitude, excluded.province_id)`,
+			db_models.POIProvinceFlagOpen)}},
+	}).Create(&flags).Error
+}
+
+func (r *POIProvinceFlagRepository) ClearFlags(ctx context.Context, poiIDs []uuid.UUID, at int64) (int64, error) {
+	if len(poiIDs) == 0 {
+		return 0, nil
+	}
+	res := r.db.WithContext(ctx).Model(&db_models.POIProvinceFlag{}).
+		Where("poi_id IN ? AND status = ?", poiIDs, db_models.POIProvinceFlagOpen).
+		Updates(map[string]any{"status": db_models.POIProvinceFlagCleared, "resolved_at": at})
+	return res.RowsAffected, res.Error
+}
+
+func (r *POIProvinceFlagRepository) ListFlags(ctx context.Context, filter POIProvinceFlagFilter, page, pageSize int) ([]db_models.POIProvinceFlag, int64, error) {
+	var (
+		flags []db_models.POIProvinceFlag
+		total int64
+	)
+
+	q := r.db.WithContext(ctx).Model(&db_models.POIProvinceFlag{}).
+		Where("EXISTS (SELECT 1 FROM pois p WHERE p.id = poi_province_flags.poi_id AND p.deleted_at IS NULL)")
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+	if filter.Kind != "" {
+		q = q.Where("kind = ?", filter.Kind)
+	}
+	if filter.ProvinceID != nil {
+		q = q.Where("province_id = ?", *filter.ProvinceID)
+	}
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	provinceName := func(db *gorm.DB) *gorm.DB { return db.Select("id", "name") }
+	err := q.
+		Preload("POI", func(db *gorm.DB) *gorm.DB { return db.Select("id", "name") }).
+		Preload("Province", provinceName).
+		Preload("SuggestedProvince", provinceName).
+		Order("detected_at DESC, id").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&flags).Error
+	return flags, total, err
+}
+
+func (r *POIProvinceFlagRepository) FindFlag(ctx context.Context, id string) (*db_models.POIProvinceFlag, error) {
+	var flag db_models.POIProvinceFlag
+	err := r.db.WithContext(ctx).First(&flag, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &flag, nil
+}
+
+func (r *POIProvinceFlagRepository) ApplyFlag(ctx context.Context, flag *db_models.POIProvinceFlag, resolvedBy string, at int64) (bool, error) {
+	updates := map[string]any{}
+	if flag.SuggestedProvinceID != nil {
+		updates["province_id"] = *flag.SuggestedProvinceID
+	}
+	if flag.SuggestedLatitude != nil && flag.SuggestedLongitude != nil {
+		updates["latitude"] = *flag.SuggestedLatitude
+		updates["longitude"] = *flag.SuggestedLongitude
+	}
+
+	applied := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&db_models.POIProvinceFlag{}).
+			Where("id = ? AND status = ?", flag.ID, db_models.POIProvinceFlagOpen).
+			Updates(map[string]any{"status": db_models.POIProvinceFlagApplied, "resolved_at": at, "resolved_by": resolvedBy})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+
+		res = tx.Model(&db_models.POI{}).
+			Where("id = ? AND latitude = ? AND longitude = ? AND province_id = ?",
+				flag.POIID, flag.Latitude, flag.Longitude, flag.ProvinceID).
+			Updates(updates)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound // the POI moved since the check: leave the flag open
+		}
+		applied = true
+		return enqueueEmbedding(tx, flag.POIID, db_models.EmbeddingReasonUpdate)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return applied, err
+}
+
+func (r *POIProvinceFlagRepository) ResolveFlag(ctx context.Context, id, status, resolvedBy string, at int64) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&db_models.POIProvinceFlag{}).
+		Where("id = ? AND status = ?", id, db_models.POIProvinceFlagOpen).
+		Updates(map[string]any{"status": status, "resolved_at": at, "resolved_by": resolvedBy})
+	return res.RowsAffected > 0, res.Error
+}
@@ -0,0 +1,236 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Gaps a POI can have; each one costs it the matching share of the quality score.
+const (
+	POIGapCoords      = "coords"
+	POIGapHours       = "hours"
+	POIGapImages      = "images"
+	POIGapDescription = "description"
+	POIGapEmbedding   = "embedding"
+	POIGapReviews     = "reviews"
+)
+
+// POIQualityScoring weighs the signals of a POI into a 0-100 score. Images and description
+// earn their weight in proportion, up to their target.
+type POIQualityScoring struct {
+	Coords      int
+	Hours       int
+	Images      int
+	Description int
+	Embedding   int
+	Reviews     int
+
+	ImageTarget       int // images for the full weight
+	DescriptionTarget int // description characters for the full weight
+}
+
+// POIQualityFilter narrows the report; zero values mean "any".
+type POIQualityFilter struct {
+	ProvinceID *uuid.UUID
+	CategoryID *uuid.UUID
+	Status     string
+	MinScore   *int
+	MaxScore   *int   // inclusive
+	Gap        string // one of the POIGap constants
+}
+
+// POIQualityRow is one POI with the signals its score comes from.
+type POIQualityRow struct {
+	ID                uuid.UUID
+	Name              string
+	Status            string
+	ProvinceID        uuid.UUID
+	Province          string
+	Category          string
+	HasCoords         bool
+	HasHours          bool
+	ImageCount        int
+	DescriptionLength int
+	HasEmbedding      bool
+	HasReviews        bool
+	Score             int
+	UpdatedAt         int64
+}
+
+type POIQualitySummary struct {
+	Total          int64
+	AverageScore   float64
+	NeedsAttention int64 // below the attention threshold
+	MissingCoords  int64
+	MissingHours   int64
+	FewImages      int64
+	ShortDesc      int64
+	NoEmbedding    int64
+	NoReviews      int64
+}
+
+type POIQualityProvinceRow struct {
+	ProvinceID     uuid.UUID
+	Province       string
+	Total          int64
+	AverageScore   float64
+	NeedsAttention int64
+}
+
+type POIQualityRepositoryInterface interface {
+	// ListScores returns a page of matching POIs, lowest score first, and the total count.
+	ListScores(ctx context.Context, scoring POIQualityScoring, filter POIQualityFilter, offset, limit int) ([]POIQualityRow, int64, error)
+	Summarize(ctx context.Context, scoring POIQualityScoring, filter POIQualityFilter, attentionBelow int) (*POIQualitySummary, error)
+	// SummarizeByProvince is lowest average score first.
+	SummarizeByProvince(ctx context.Context, scoring POIQualityScoring, filter POIQualityFilter, attentionBelow int) ([]POIQualityProvinceRow, error)
+}
+
+type POIQualityRepository struct {
+	db *gorm.DB
+}
+
+func NewPOIQualityRepository(db *gorm.DB) *POIQualityRepository {
+	return &POIQualityRepository{db: db}
+}
+
+// poiSignalsSQL computes the signals of every live POI. Only the latest details row counts,
+// reviews are external refs with a fetched rating.
+const poiSignalsSQL = `
+SELECT p.id, p.name, COALESCE(p.status, '') AS status, p.province_id, p.category_id,
+	COALESCE(pr.name, '') AS province, COALESCE(c.name, '') AS category,
+	(p.latitude BETWEEN -90 AND 90 AND p.longitude BETWEEN -180 AND 180
+		AND NOT (p.latitude = 0 AND p.longitude = 0)) AS has_coords,
+	btrim(COALESCE(p.opening_hours, '')) <> '' AS has_hours,
+	COALESCE((SELECT cardinality(d.images) FROM poi_details d
+		WHERE d.poi_id = p.id AND d.deleted_at IS NULL
+		ORDER BY d.created_at DESC LIMIT 1), 0) AS image_count,
+	char_length(btrim(COALESCE(p.description, ''))) AS description_length,
+	EXISTS (SELECT 1 FROM poi_embeddings e WHERE e.poi_id = p.id::text) AS has_embedding,
+	EXISTS (SELECT 1 FROM poi_external_refs x
+		WHERE x.poi_id = p.id AND x.deleted_at IS NULL AND x.rating IS NOT NULL) AS has_reviews,
+	p.updated_at
+FROM pois p
+LEFT JOIN provinces pr ON pr.id = p.province_id
+LEFT JOIN categories c ON c.id = p.category_id
+WHERE p.deleted_at IS NULL`
+
+// scoredQuery wraps the signals in a CTE named "scored" with the score column and the filter
+// applied, and returns the SQL with its arguments.
+func scoredQuery(scoring POIQualityScoring, filter POIQualityFilter) (string, []any) {
+	var sb strings.Builder
+	args := make([]any, 0, 16)
+
+	sb.WriteString("WITH signals AS (")
+	sb.WriteString(poiSignalsSQL)
+	if filter.ProvinceID != nil {
+		sb.WriteString(" AND p.province_id = ?")
+		args = append(args, *filter.ProvinceID)
+	}
+	if filter.CategoryID != nil {
+		sb.WriteString(" AND p.category_id = ?")
+		args = append(args, *filter.CategoryID)
+	}
+	if filter.Status != "" {
+		sb.WriteString(" AND p.status = ?")
+		args = append(args, filter.Status)
+	}
+	sb.WriteString(`), scored AS (
+SELECT signals.*, ROUND(
+	? * has_coords::int + ? * has_hours::int
+	+ ? * LEAST(image_count, ?)::numeric / ? + ? * LEAST(description_length, ?)::numeric / ?
+	+ ? * has_embedding::int + ? * has_reviews::int)::int AS score
+FROM signals) `)
+	args = append(args,
+		scoring.Coords, scoring.Hours,
+		scoring.Images, scoring.ImageTarget, scoring.ImageTarget,
+		scoring.Description, scoring.DescriptionTarget, scoring.DescriptionTarget,
+		scoring.Embedding, scoring.Reviews)
+
+	var where []string
+	if filter.MinScore != nil {
+		where = append(where, "score >= ?")
+		args = append(args, *filter.MinScore)
+	}
+	if filter.MaxScore != nil {
+		where = append(where, "score <= ?")
+		args = append(args, *filter.MaxScore)
+	}
+	switch filter.Gap {
+	case POIGapCoords:
+		where = append(where, "NOT has_coords")
+	case POIGapHours:
+		where = append(where, "NOT has_hours")
+	case POIGapImages:
+		where = append(where, "image_count < ?")
+		args = append(args, scoring.ImageTarget)
+	case POIGapDescription:
+		where = append(where, "description_length < ?")
+		args = append(args, scoring.DescriptionTarget)
+	case POIGapEmbedding:
+		where = append(where, "NOT has_embedding")
+	case POIGapReviews:
+		where = append(where, "NOT has_reviews")
+	}
+	sb.WriteString("SELECT * FROM scored")
+	if len(where) > 0 {
+		sb.WriteString(" WHERE " + strings.Join(where, " AND "))
+	}
+	return sb.String(), args
+}
+
+func (r *POIQualityRepository) ListScores(ctx context.Context, scoring POIQualityScoring, filter POIQualityFilter, offset, limit int) ([]POIQualityRow, int64, error) {
+	query, args := scoredQuery(scoring, filter)
+
+	var total int64
+	if err := r.db.WithContext(ctx).Raw("SELECT count(*) FROM ("+query+") q", args...).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []POIQualityRow
+	err := r.db.WithContext(ctx).
+		Raw(query+" ORDER BY score, name, id LIMIT ? OFFSET ?", append(args, limit, offset)...).
+		Scan(&rows).Error
+	return rows, total, err
+}
+
+func (r *POIQualityRepository) Summarize(ctx context.Context, scoring POIQualityScoring, filter POIQualityFilter, attentionBelow int) (*POIQualitySummary, error) {
+	query, args := scoredQuery(scoring, filter)
+
+	var out POIQualitySummary
+	err := r.db.WithContext(ctx).Raw(`
+SELECT count(*) AS total,
+	COALESCE(avg(score), 0) AS average_score,
+	count(*) FILTER (WHERE score < ?) AS needs_attention,
+	count(*) FILTER (WHERE NOT has_coords) AS missing_coords,
+	count(*) FILTER (WHERE NOT has_hours) AS missing_hours,
+	count(*) FILTER (WHERE image_count < ?) AS few_images,
+	count(*) FILTER (WHERE description_length < ?) AS short_desc,
+	count(*) FILTER (WHERE NOT has_embedding) AS no_embedding,
+	count(*) FILTER (WHERE NOT has_reviews) AS no_reviews
+FROM (`+query+`) q`,
+		append([]any{attentionBelow, scoring.ImageTarget, scoring.DescriptionTarget}, args...)...).
+		Scan(&out).Error
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *POIQualityRepository) SummarizeByProvince(ctx context.Context, scoring POIQualityScoring, filter POIQualityFilter, attentionBelow int) ([]POIQualityProvinceRow, error) {
+	query, args := scoredQuery(scoring, filter)
+
+	var rows []POIQualityProvinceRow
+	err := r.db.WithContext(ctx).Raw(`
+SELECT province_id, province, count(*) AS total,
+	avg(score) AS average_score,
+	count(*) FILTER (WHERE score < ?) AS needs_attention
+FROM (`+query+`) q
+GROUP BY province_id, province
+ORDER BY average_score, province`,
+		append([]any{attentionBelow}, args...)...).
+		Scan(&rows).Error
+	return rows, err
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"math"
 	"strings"
 	"vivu/internal/models/db_models"
 )
@@ -25,6 +26,21 @@ type POIRepository interface {
 	FindPOIsByLocationNames(ctx context.Context, locations []string) ([]*db_models.POI, error)
 
 	SearchPoiByNameAndProvince(ctx context.Context, name string, provinceID string) ([]*db_models.POI, error)
+	FindPOIsNear(ctx context.Context, lat, lng, radiusMeters float64, limit int) ([]*db_models.POI, error)
+	FindNearbyPOIs(ctx context.Context, lat, lng, radiusMeters float64, category string, limit int) ([]POIDistance, error)
+	SearchPOIsFullText(ctx context.Context, query string, provinceID string, page, pageSize int) ([]POIRank, int64, error)
+}
+
+// POIRank is one full-text hit with its ts_rank_cd score; POI is preloaded like ListPoisByPoisId.
+type POIRank struct {
+	POI  *db_models.POI
+	Rank float64
+}
+
+// POIDistance is one nearby POI with its great-circle distance from the query point.
+type POIDistance struct {
+	POI            *db_models.POI
+	DistanceMeters float64
 }
 
 type poiRepository struct {
@@ -60,6 +76,7 @@ func (r *poiRepository) ListPoisByPoisId(ctx context.Context, ids []string) ([]*
 		Preload("Tags").
 		Preload("Category").
 		Preload("Province").
+		Preload("ExternalRefs").
 		Where("id in ?", ids).
 		Find(&pois).Error
 
@@ -164,12 +181,169 @@ func (r *poiRepository) FindPOIsByLocationNames(ctx context.Context, locations [
 	return pois, nil
 }
 
+// haversineSQL is the great-circle distance in meters from pois.latitude/longitude to a point.
+// Placeholders: lat, lat, lng.
+const haversineSQL = `6371000 * 2 * ASIN(SQRT(
+	POWER(SIN(RADIANS(pois.latitude - ?) / 2), 2) +
+	COS(RADIANS(?)) * COS(RADIANS(pois.latitude)) * POWER(SIN(RADIANS(pois.longitude - ?) / 2), 2)
+))`
+
+// withinRadius narrows q to POIs within radiusMeters of (lat, lng). A bounding box
+// (index friendly) cuts the rows before the haversine distance is computed.
+func withinRadius(q *gorm.DB, lat, lng, radiusMeters float64) *gorm.DB {
+	const metersPerDegree = 111320.0
+	dLat := radiusMeters / metersPerDegree
+	dLng := radiusMeters / (metersPerDegree * math.Max(math.Cos(lat*math.Pi/180), 0.01))
+
+	return q.
+		Where("pois.latitude BETWEEN ? AND ? AND pois.longitude BETWEEN ? AND ?", lat-dLat, lat+dLat, lng-dLng, lng+dLng).
+		Where(haversineSQL+" <= ?", lat, lat, lng, radiusMeters)
+}
+
+// FindPOIsNear returns POIs within radiusMeters of (lat, lng), nearest first.
+func (r *poiRepository) FindPOIsNear(ctx context.Context, lat, lng, radiusMeters float64, limit int) ([]*db_models.POI, error) {
+	var pois []*db_models.POI
+	err := withinRadius(r.db.WithContext(ctx), lat, lng, radiusMeters).
+		Preload("Category").
+		Select("pois.*, "+haversineSQL+" AS distance_m", lat, lat, lng).
+		Order("distance_m ASC").
+		Limit(limit).
+		Find(&pois).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find POIs near location: %w", err)
+	}
+	return pois, nil
+}
+
+// FindNearbyPOIs is FindPOIsNear for the public "around me" API: drafts are hidden, the
+// distance is kept, and category (a category ID or name) optionally filters the results.
+func (r *poiRepository) FindNearbyPOIs(ctx context.Context, lat, lng, radiusMeters float64, category string, limit int) ([]POIDistance, error) {
+	q := withinRadius(r.db.WithContext(ctx).Table("pois"), lat, lng, radiusMeters).
+		Where("pois.deleted_at IS NULL").
+		Where("COALESCE(pois.status, '') <> ?", db_models.POIStatusDraft)
+	if category != "" {
+		if _, err := uuid.Parse(category); err == nil {
+			q = q.Where("pois.category_id = ?", category)
+		} else {
+			q = q.Joins("JOIN categories ON categories.id = pois.category_id").
+				Where("LOWER(categories.name) = LOWER(?)", category)
+		}
+	}
+
+	var rows []struct {
+		ID        uuid.UUID
+		DistanceM float64
+	}
+	err := q.Select("pois.id, "+haversineSQL+" AS distance_m", lat, lat, lng).
+		Order("distance_m ASC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find nearby POIs: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID.String())
+	}
+	pois, err := r.ListPoisByPoisId(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*db_models.POI, len(pois))
+	for _, p := range pois {
+		byID[p.ID] = p
+	}
+
+	out := make([]POIDistance, 0, len(rows))
+	for _, row := range rows {
+		if p, ok := byID[row.ID]; ok {
+			out = append(out, POIDistance{POI: p, DistanceMeters: row.DistanceM})
+		}
+	}
+	return out, nil
+}
+
+// SearchPOIsFullText matches query against the generated, accent-insensitive search_vector column
+// (name > address > description) and returns one page ordered by rank, plus the total hit count.
+// Draft POIs are excluded.
+func (r *poiRepository) SearchPOIsFullText(ctx context.Context, query string, provinceID string, page, pageSize int) ([]POIRank, int64, error) {
+	tsq := BuildPrefixTsQuery(query)
+	if tsq == "" {
+		return nil, 0, nil
+	}
+
+	base := r.db.WithContext(ctx).
+		Table("pois").
+		Where("pois.deleted_at IS NULL").
+		Where("COALESCE(pois.status, '') <> ?", db_models.POIStatusDraft).
+		Where("pois.search_vector @@ to_tsquery('simple', f_unaccent(?))", tsq)
+	if provinceID != "" {
+		base = base.Where("pois.province_id = ?", provinceID)
+	}
+
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count POI search results: %w", err)
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	var ranked []struct {
+		ID   uuid.UUID
+		Rank float64
+	}
+	err := base.Session(&gorm.Session{}).
+		Select("pois.id, ts_rank_cd(pois.search_vector, to_tsquery('simple', f_unaccent(?))) AS rank", tsq).
+		Order("rank DESC, pois.name ASC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Scan(&ranked).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search POIs: %w", err)
+	}
+	if len(ranked) == 0 {
+		return nil, total, nil
+	}
+
+	ids := make([]string, 0, len(ranked))
+	for _, h := range ranked {
+		ids = append(ids, h.ID.String())
+	}
+	pois, err := r.ListPoisByPoisId(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[uuid.UUID]*db_models.POI, len(pois))
+	for _, p := range pois {
+		byID[p.ID] = p
+	}
+
+	out := make([]POIRank, 0, len(ranked))
+	for _, h := range ranked {
+		if p, ok := byID[h.ID]; ok {
+			out = append(out, POIRank{POI: p, Rank: h.Rank})
+		}
+	}
+	return out, total, nil
+}
+
 func NewPOIRepository(db *gorm.DB) POIRepository {
 	return &poiRepository{db: db}
 }
 
 func (r *poiRepository) CreatePoi(ctx context.Context, poi *db_models.POI) (uuid.UUID, error) {
-	if err := r.db.WithContext(ctx).Create(poi).Error; err != nil {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(poi).Error; err != nil {
+			return err
+		}
+		return enqueueEmbedding(tx, poi.ID, db_models.EmbeddingReasonCreate)
+	})
+	if err != nil {
 		return uuid.Nil, err
 	}
 	return poi.ID, nil
@@ -189,12 +363,18 @@ func (r *poiRepository) UpdatePoi(ctx context.Context, poi *db_models.POI) error
 			return gorm.ErrRecordNotFound
 		}
 
-		return nil
+		return enqueueEmbedding(tx, poi.ID, db_models.EmbeddingReasonUpdate)
 	})
 }
 
 func (r *poiRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	err := r.db.WithContext(ctx).Delete(&db_models.POI{}, "id = ?", id).Error
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&db_models.POI{}, "id = ?", id).Error; err != nil {
+			return err
+		}
+		// The worker drops the embedding so search stops returning the POI
+		return enqueueEmbedding(tx, id, db_models.EmbeddingReasonDelete)
+	})
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return err
 	}
@@ -213,6 +393,7 @@ func (r *poiRepository) GetByIDWithDetails(ctx context.Context, id string) (*db_
 		Preload("Tags").
 		Preload("Category").
 		Preload("Province").
+		Preload("ExternalRefs").
 		First(&poi, "id = ?", id).Error
 
 	if err != nil {
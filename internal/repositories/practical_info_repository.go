@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"vivu/internal/models/db_models"
+)
+
+type PracticalInfoRepositoryInterface interface {
+	ListInfo(ctx context.Context) ([]db_models.PracticalInfo, error)
+	FindByProvinceID(ctx context.Context, provinceID string) (*db_models.PracticalInfo, error)
+	// FindByPOIIDs returns the info of every province the given POIs belong to.
+	FindByPOIIDs(ctx context.Context, poiIDs []string) ([]db_models.PracticalInfo, error)
+	UpsertInfo(ctx context.Context, info *db_models.PracticalInfo) error
+	DeleteByProvinceID(ctx context.Context, provinceID string) (bool, error)
+}
+
+type PracticalInfoRepository struct {
+	db *gorm.DB
+}
+
+func NewPracticalInfoRepository(db *gorm.DB) *PracticalInfoRepository {
+	return &PracticalInfoRepository{db: db}
+}
+
+func (r *PracticalInfoRepository) ListInfo(ctx context.Context) ([]db_models.PracticalInfo, error) {
+	var out []db_models.PracticalInfo
+	err := r.db.WithContext(ctx).Preload("Province").Order("created_at ASC").Find(&out).Error
+	return out, err
+}
+
+func (r *PracticalInfoRepository) FindByProvinceID(ctx context.Context, provinceID string) (*db_models.PracticalInfo, error) {
+	var out db_models.PracticalInfo
+	err := r.db.WithContext(ctx).Preload("Province").Where("province_id = ?", provinceID).First(&out).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *PracticalInfoRepository) FindByPOIIDs(ctx context.Context, poiIDs []string) ([]db_models.PracticalInfo, error) {
+	var out []db_models.PracticalInfo
+	if len(poiIDs) == 0 {
+		return out, nil
+	}
+	sub := r.db.Model(&db_models.POI{}).Select("province_id").Where("id IN ?", poiIDs)
+	err := r.db.WithContext(ctx).Preload("Province").Where("province_id IN (?)", sub).Find(&out).Error
+	return out, err
+}
+
+func (r *PracticalInfoRepository) UpsertInfo(ctx context.Context, info *db_models.PracticalInfo) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "province_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"connectivity", "transport", "tipping", "other", "updated_by", "updated_at", "deleted_at",
+		}),
+	}).Create(info).Error
+}
+
+func (r *PracticalInfoRepository) DeleteByProvinceID(ctx context.Context, provinceID string) (bool, error) {
+	res := r.db.WithContext(ctx).Where("province_id = ?", provinceID).Delete(&db_models.PracticalInfo{})
+	return res.RowsAffected > 0, res.Error
+}
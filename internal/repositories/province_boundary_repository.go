@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"vivu/internal/models/db_models"
+)
+
+type ProvinceBoundaryRepositoryInterface interface {
+	// ListBoundaries returns every boundary with its province name loaded.
+	ListBoundaries(ctx context.Context) ([]db_models.ProvinceBoundary, error)
+	FindByProvinceID(ctx context.Context, provinceID string) (*db_models.ProvinceBoundary, error)
+	UpsertBoundary(ctx context.Context, boundary *db_models.ProvinceBoundary) error
+	DeleteByProvinceID(ctx context.Context, provinceID string) (bool, error)
+}
+
+type ProvinceBoundaryRepository struct {
+	db *gorm.DB
+}
+
+func NewProvinceBoundaryRepository(db *gorm.DB) *ProvinceBoundaryRepository {
+	return &ProvinceBoundaryRepository{db: db}
+}
+
+func (r *ProvinceBoundaryRepository) ListBoundaries(ctx context.Context) ([]db_models.ProvinceBoundary, error) {
+	var out []db_models.ProvinceBoundary
+	err := r.db.WithContext(ctx).
+		Preload("Province", func(db *gorm.DB) *gorm.DB { return db.Select("id", "name") }).
+		Order("province_id").
+		Find(&out).Error
+	return out, err
+}
+
+func (r *ProvinceBoundaryRepository) FindByProvinceID(ctx context.Context, provinceID string) (*db_models.ProvinceBoundary, error) {
+	var out db_models.ProvinceBoundary
+	err := r.db.WithContext(ctx).
+		Preload("Province", func(db *gorm.DB) *gorm.DB { return db.Select("id", "name") }).
+		First(&out, "province_id = ?", provinceID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *ProvinceBoundaryRepository) UpsertBoundary(ctx context.Context, boundary *db_models.ProvinceBoundary) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "province_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"geometry", "min_lat", "min_lng", "max_lat", "max_lng", "points", "source", "updated_by", "updated_at", "deleted_at",
+		}),
+	}).Create(boundary).Error
+}
+
+func (r *ProvinceBoundaryRepository) DeleteByProvinceID(ctx context.Context, provinceID string) (bool, error) {
+	res := r.db.WithContext(ctx).Where("province_id = ?", provinceID).Delete(&db_models.ProvinceBoundary{})
+	return res.RowsAffected > 0, res.Error
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"vivu/internal/models/db_models"
+)
+
+type ProvinceGuideRepositoryInterface interface {
+	ListGuides(ctx context.Context) ([]db_models.ProvinceGuide, error)
+	FindByProvinceID(ctx context.Context, provinceID string) (*db_models.ProvinceGuide, error)
+	UpsertGuide(ctx context.Context, guide *db_models.ProvinceGuide) error
+	DeleteByProvinceID(ctx context.Context, provinceID string) (bool, error)
+}
+
+type ProvinceGuideRepository struct {
+	db *gorm.DB
+}
+
+func NewProvinceGuideRepository(db *gorm.DB) *ProvinceGuideRepository {
+	return &ProvinceGuideRepository{db: db}
+}
+
+func (r *ProvinceGuideRepository) ListGuides(ctx context.Context) ([]db_models.ProvinceGuide, error) {
+	var out []db_models.ProvinceGuide
+	err := r.db.WithContext(ctx).Preload("Province").Order("created_at ASC").Find(&out).Error
+	return out, err
+}
+
+func (r *ProvinceGuideRepository) FindByProvinceID(ctx context.Context, provinceID string) (*db_models.ProvinceGuide, error) {
+	var out db_models.ProvinceGuide
+	err := r.db.WithContext(ctx).Preload("Province").Where("province_id = ?", provinceID).First(&out).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *ProvinceGuideRepository) UpsertGuide(ctx context.Context, guide *db_models.ProvinceGuide) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "province_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"cover_image_url", "intro", "highlight_pois", "trip_days", "updated_by", "updated_at", "deleted_at",
+		}),
+	}).Create(guide).Error
+}
+
+func (r *ProvinceGuideRepository) DeleteByProvinceID(ctx context.Context, provinceID string) (bool, error) {
+	res := r.db.WithContext(ctx).Where("province_id = ?", provinceID).Delete(&db_models.ProvinceGuide{})
+	return res.RowsAffected > 0, res.Error
+}
@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"vivu/internal/models/db_models"
+)
+
+type RuntimeSwitchRepositoryInterface interface {
+	ListSwitches(ctx context.Context) ([]db_models.RuntimeSwitch, error)
+	UpsertSwitch(ctx context.Context, sw *db_models.RuntimeSwitch) error
+}
+
+type RuntimeSwitchRepository struct {
+	db *gorm.DB
+}
+
+func NewRuntimeSwitchRepository(db *gorm.DB) *RuntimeSwitchRepository {
+	return &RuntimeSwitchRepository{db: db}
+}
+
+func (r *RuntimeSwitchRepository) ListSwitches(ctx context.Context) ([]db_models.RuntimeSwitch, error) {
+	var out []db_models.RuntimeSwitch
+	err := r.db.WithContext(ctx).Order("key ASC").Find(&out).Error
+	return out, err
+}
+
+func (r *RuntimeSwitchRepository) UpsertSwitch(ctx context.Context, sw *db_models.RuntimeSwitch) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "message", "updated_by", "updated_at"}),
+	}).Create(sw).Error
+}
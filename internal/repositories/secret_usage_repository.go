@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"vivu/internal/models/db_models"
+	"vivu/pkg/secrets"
+)
+
+type SecretUsageRepositoryInterface interface {
+	// RecordUsage adds the reads counted by an instance since its last flush.
+	RecordUsage(ctx context.Context, instance string, usage []secrets.KeyUsage) error
+	// List returns the usage of every key version on every instance, most recently used first.
+	List(ctx context.Context) ([]db_models.SecretKeyUsage, error)
+}
+
+type SecretUsageRepository struct {
+	db *gorm.DB
+}
+
+func NewSecretUsageRepository(db *gorm.DB) *SecretUsageRepository {
+	return &SecretUsageRepository{db: db}
+}
+
+func (r *SecretUsageRepository) RecordUsage(ctx context.Context, instance string, usage []secrets.KeyUsage) error {
+	if len(usage) == 0 {
+		return nil
+	}
+	rows := make([]db_models.SecretKeyUsage, 0, len(usage))
+	for _, u := range usage {
+		rows = append(rows, db_models.SecretKeyUsage{
+			Name:        u.Name,
+			Fingerprint: u.Fingerprint,
+			Instance:    instance,
+			Source:      u.Source,
+			Uses:        u.Uses,
+			FirstUsedAt: u.FirstUsedAt.Unix(),
+			LastUsedAt:  u.LastUsedAt.Unix(),
+		})
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "name"}, {Name: "fingerprint"}, {Name: "instance"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"uses":         gorm.Expr("secret_key_usages.uses + EXCLUDED.uses"),
+			"last_used_at": gorm.Expr("GREATEST(secret_key_usages.last_used_at, EXCLUDED.last_used_at)"),
+			"source":       gorm.Expr("EXCLUDED.source"),
+			"updated_at":   gorm.Expr("EXCLUDED.updated_at"),
+		}),
+	}).Create(&rows).Error
+}
+
+func (r *SecretUsageRepository) List(ctx context.Context) ([]db_models.SecretKeyUsage, error) {
+	var out []db_models.SecretKeyUsage
+	err := r.db.WithContext(ctx).Order("last_used_at DESC").Find(&out).Error
+	return out, err
+}
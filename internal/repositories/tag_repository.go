@@ -3,14 +3,30 @@ package repositories
 import (
 	"context"
 	"errors"
+
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"vivu/internal/models/db_models"
 )
 
+// POITagFilter picks the POIs a bulk tag assignment applies to; zero values mean "any".
+type POITagFilter struct {
+	ProvinceID *uuid.UUID
+	CategoryID *uuid.UUID
+	Name       string // case-insensitive substring
+}
+
 type TagRepositoryInterface interface {
 	CreateTag(tag db_models.Tag, ctx context.Context) error
 	GetTagByID(tagID string) (*db_models.Tag, error)
 	GetAllTags(page int, pageSize int, ctx context.Context) ([]db_models.Tag, error)
+
+	// FindPOIIDs returns the live POIs among ids, or those matching filter when ids is nil, at
+	// most limit of them.
+	FindPOIIDs(ctx context.Context, ids []uuid.UUID, filter POITagFilter, limit int) ([]uuid.UUID, error)
+	// AssignTag tags the POIs in one transaction and queues the newly tagged ones for
+	// re-embedding; it returns those, leaving out POIs that had the tag already.
+	AssignTag(ctx context.Context, tagID uuid.UUID, poiIDs []uuid.UUID) ([]uuid.UUID, error)
 }
 
 func NewTagRepository(db *gorm.DB) TagRepositoryInterface {
@@ -61,3 +77,49 @@ func (t *TagRepository) GetAllTags(page int, pageSize int, ctx context.Context)
 	}
 	return tags, nil
 }
+
+func (t *TagRepository) FindPOIIDs(ctx context.Context, ids []uuid.UUID, filter POITagFilter, limit int) ([]uuid.UUID, error) {
+	q := t.db.WithContext(ctx).Model(&db_models.POI{})
+	if ids != nil {
+		q = q.Where("id IN ?", ids)
+	}
+	if filter.ProvinceID != nil {
+		q = q.Where("province_id = ?", *filter.ProvinceID)
+	}
+	if filter.CategoryID != nil {
+		q = q.Where("category_id = ?", *filter.CategoryID)
+	}
+	if filter.Name != "" {
+		q = q.Where("name ILIKE ?", "%"+likeEscaper.Replace(filter.Name)+"%")
+	}
+
+	var out []uuid.UUID
+	if err := q.Order("id").Limit(limit).Pluck("id", &out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (t *TagRepository) AssignTag(ctx context.Context, tagID uuid.UUID, poiIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var tagged []uuid.UUID
+	err := t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Raw(`
+INSERT INTO poi_tags (tag_id, poi_id)
+SELECT ?, p.id FROM pois p WHERE p.id IN ? AND p.deleted_at IS NULL
+ON CONFLICT DO NOTHING
+RETURNING poi_id`, tagID, poiIDs).Scan(&tagged).Error; err != nil {
+			return err
+		}
+		// Tags are part of the embedding text
+		for _, id := range tagged {
+			if err := enqueueEmbedding(tx, id, db_models.EmbeddingReasonUpdate); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tagged, nil
+}
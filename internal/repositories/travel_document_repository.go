@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"vivu/internal/models/db_models"
+)
+
+type TravelDocumentRepositoryInterface interface {
+	Create(ctx context.Context, doc *db_models.TravelDocument) error
+	ListByJourney(ctx context.Context, journeyID uuid.UUID) ([]db_models.TravelDocument, error)
+	// Find returns a document of the journey, or nil.
+	Find(ctx context.Context, journeyID, documentID uuid.UUID) (*db_models.TravelDocument, error)
+	Delete(ctx context.Context, documentID uuid.UUID) error
+
+	// EnsureInbox returns the inbox of a journey, creating it with token when it has none.
+	EnsureInbox(ctx context.Context, journeyID uuid.UUID, token string) (*db_models.JourneyInbox, error)
+	// FindInboxByToken returns nil for an unknown token.
+	FindInboxByToken(ctx context.Context, token string) (*db_models.JourneyInbox, error)
+}
+
+type TravelDocumentRepository struct {
+	db *gorm.DB
+}
+
+func NewTravelDocumentRepository(db *gorm.DB) *TravelDocumentRepository {
+	return &TravelDocumentRepository{db: db}
+}
+
+func (r *TravelDocumentRepository) Create(ctx context.Context, doc *db_models.TravelDocument) error {
+	return r.db.WithContext(ctx).Create(doc).Error
+}
+
+func (r *TravelDocumentRepository) ListByJourney(ctx context.Context, journeyID uuid.UUID) ([]db_models.TravelDocument, error) {
+	var docs []db_models.TravelDocument
+	err := r.db.WithContext(ctx).Where("journey_id = ?", journeyID).Order("created_at DESC").Find(&docs).Error
+	return docs, err
+}
+
+func (r *TravelDocumentRepository) Find(ctx context.Context, journeyID, documentID uuid.UUID) (*db_models.TravelDocument, error) {
+	var d db_models.TravelDocument
+	err := r.db.WithContext(ctx).First(&d, "id = ? AND journey_id = ?", documentID, journeyID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *TravelDocumentRepository) Delete(ctx context.Context, documentID uuid.UUID) error {
+	return r.db.WithContext(ctx).Unscoped().Delete(&db_models.TravelDocument{}, "id = ?", documentID).Error
+}
+
+func (r *TravelDocumentRepository) EnsureInbox(ctx context.Context, journeyID uuid.UUID, token string) (*db_models.JourneyInbox, error) {
+	inbox := &db_models.JourneyInbox{JourneyID: journeyID, Token: token}
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "journey_id"}},
+		DoNothing: true,
+	}).Create(inbox).Error; err != nil {
+		return nil, err
+	}
+	var out db_models.JourneyInbox
+	err := r.db.WithContext(ctx).First(&out, "journey_id = ?", journeyID).Error
+	return &out, err
+}
+
+func (r *TravelDocumentRepository) FindInboxByToken(ctx context.Context, token string) (*db_models.JourneyInbox, error) {
+	var inbox db_models.JourneyInbox
+	err := r.db.WithContext(ctx).First(&inbox, "token = ?", token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &inbox, nil
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"vivu/internal/models/db_models"
+)
+
+type TravelPresetRepositoryInterface interface {
+	ListPresets(ctx context.Context, accountID uuid.UUID) ([]db_models.TravelPreset, error)
+	// FindPreset returns nil when the account has no such preset.
+	FindPreset(ctx context.Context, accountID, presetID uuid.UUID) (*db_models.TravelPreset, error)
+	// NameTaken reports whether another preset of the account has this name (case-insensitive).
+	NameTaken(ctx context.Context, accountID uuid.UUID, name string, except uuid.UUID) (bool, error)
+	CreatePreset(ctx context.Context, preset *db_models.TravelPreset) error
+	SavePreset(ctx context.Context, preset *db_models.TravelPreset) error
+	// DeletePreset removes the row for good so the name can be used again.
+	DeletePreset(ctx context.Context, accountID, presetID uuid.UUID) (bool, error)
+}
+
+type TravelPresetRepository struct {
+	db *gorm.DB
+}
+
+func NewTravelPresetRepository(db *gorm.DB) *TravelPresetRepository {
+	return &TravelPresetRepository{db: db}
+}
+
+func (r *TravelPresetRepository) ListPresets(ctx context.Context, accountID uuid.UUID) ([]db_models.TravelPreset, error) {
+	var presets []db_models.TravelPreset
+	err := r.db.WithContext(ctx).
+		Where("account_id = ?", accountID).
+		Order("name").
+		Find(&presets).Error
+	return presets, err
+}
+
+func (r *TravelPresetRepository) FindPreset(ctx context.Context, accountID, presetID uuid.UUID) (*db_models.TravelPreset, error) {
+	var preset db_models.TravelPreset
+	err := r.db.WithContext(ctx).First(&preset, "id = ? AND account_id = ?", presetID, accountID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &preset, nil
+}
+
+func (r *TravelPresetRepository) NameTaken(ctx context.Context, accountID uuid.UUID, name string, except uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&db_models.TravelPreset{}).
+		Where("account_id = ? AND lower(name) = lower(?) AND id <> ?", accountID, name, except).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *TravelPresetRepository) CreatePreset(ctx context.Context, preset *db_models.TravelPreset) error {
+	return r.db.WithContext(ctx).Create(preset).Error
+}
+
+func (r *TravelPresetRepository) SavePreset(ctx context.Context, preset *db_models.TravelPreset) error {
+	return r.db.WithContext(ctx).Save(preset).Error
+}
+
+func (r *TravelPresetRepository) DeletePreset(ctx context.Context, accountID, presetID uuid.UUID) (bool, error) {
+	res := r.db.WithContext(ctx).Unscoped().
+		Where("id = ? AND account_id = ?", presetID, accountID).
+		Delete(&db_models.TravelPreset{})
+	return res.RowsAffected > 0, res.Error
+}
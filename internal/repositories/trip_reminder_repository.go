@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"vivu/internal/models/db_models"
+)
+
+type TripReminderRepositoryInterface interface {
+	// FindJourney returns the journey with its account but without its days, or nil when it does not exist.
+	FindJourney(ctx context.Context, journeyID string) (*db_models.Journey, error)
+	// UpcomingJourneys lists open journeys with reminders on, of accounts that did not turn
+	// trip emails off, that start in [from, to).
+	UpcomingJourneys(ctx context.Context, from, to int64) ([]db_models.Journey, error)
+	// UpsertSchedule inserts missing reminders and resets the ones whose trip start moved.
+	UpsertSchedule(ctx context.Context, rows []db_models.TripReminder) error
+	ListForJourney(ctx context.Context, journeyID uuid.UUID) ([]db_models.TripReminder, error)
+	// ClaimDue claims unsent reminders due by now of trips that have not started yet for lease,
+	// so no other instance sends them, and returns them with the journey, its account and its activities.
+	ClaimDue(ctx context.Context, now int64, limit, maxAttempts int, lease time.Duration) ([]db_models.TripReminder, error)
+	MarkSent(ctx context.Context, ids []uuid.UUID, skipped bool) error
+	MarkFailed(ctx context.Context, id uuid.UUID, reason string) error
+	SetRemindersOff(ctx context.Context, journeyID uuid.UUID, off bool) error
+}
+
+type TripReminderRepository struct {
+	db *gorm.DB
+}
+
+func NewTripReminderRepository(db *gorm.DB) *TripReminderRepository {
+	return &TripReminderRepository{db: db}
+}
+
+func (r *TripReminderRepository) FindJourney(ctx context.Context, journeyID string) (*db_models.Journey, error) {
+	var j db_models.Journey
+	err := r.db.WithContext(ctx).Preload("Account").First(&j, "id = ?", journeyID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+func (r *TripReminderRepository) UpcomingJourneys(ctx context.Context, from, to int64) ([]db_models.Journey, error) {
+	var out []db_models.Journey
+	err := r.db.WithContext(ctx).
+		Select("journeys.id", "journeys.start_date").
+		Joins("JOIN accounts ON accounts.id = journeys.account_id AND accounts.trip_emails_off = ?", false).
+		Where("journeys.start_date >= ? AND journeys.start_date < ?", from, to).
+		Where("journeys.is_completed = ? AND journeys.reminders_off = ?", false, false).
+		Find(&out).Error
+	return out, err
+}
+
+func (r *TripReminderRepository) UpsertSchedule(ctx context.Context, rows []db_models.TripReminder) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "journey_id"}, {Name: "kind"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"trip_start": gorm.Expr("excluded.trip_start"),
+			"due_at":     gorm.Expr("excluded.due_at"),
+			"sent_at":    nil,
+			"skipped":    false,
+			"attempts":   0,
+			"last_error": "",
+			"updated_at": time.Now().Unix(),
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: "trip_reminders.trip_start <> excluded.trip_start"},
+		}},
+	}).Create(&rows).Error
+}
+
+func (r *TripReminderRepository) ListForJourney(ctx context.Context, journeyID uuid.UUID) ([]db_models.TripReminder, error) {
+	var out []db_models.TripReminder
+	err := r.db.WithContext(ctx).Where("journey_id = ?", journeyID).Order("due_at ASC").Find(&out).Error
+	return out, err
+}
+
+func (r *TripReminderRepository) ClaimDue(ctx context.Context, now int64, limit, maxAttempts int, lease time.Duration) ([]db_models.TripReminder, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Rows another instance is claiming right now are skipped, not waited for
+		err := tx.Model(&db_models.TripReminder{}).
+			Joins("JOIN journeys ON journeys.id = trip_reminders.journey_id AND journeys.deleted_at IS NULL").
+			Joins("JOIN accounts ON accounts.id = journeys.account_id AND accounts.trip_emails_off = ?", false).
+			Where("journeys.reminders_off = ? AND journeys.is_completed = ?", false, false).
+			Where("trip_reminders.sent_at IS NULL AND trip_reminders.attempts < ?", maxAttempts).
+			Where("trip_reminders.due_at <= ? AND trip_reminders.trip_start > ?", now, now).
+			Where("trip_reminders.claimed_until IS NULL OR trip_reminders.claimed_until < ?", now).
+			Order("trip_reminders.due_at ASC").
+			Limit(limit).
+			Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "trip_reminders"}, Options: "SKIP LOCKED"}).
+			Pluck("trip_reminders.id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		return tx.Model(&db_models.TripReminder{}).
+			Where("id IN ?", ids).
+			Update("claimed_until", time.Unix(now, 0).Add(lease).Unix()).Error
+	})
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	var out []db_models.TripReminder
+	err = r.db.WithContext(ctx).
+		Preload("Journey.Account").
+		Preload("Journey.Days.Activities.SelectedPOI").
+		Where("id IN ?", ids).
+		Order("due_at ASC").
+		Find(&out).Error
+	return out, err
+}
+
+func (r *TripReminderRepository) MarkSent(ctx context.Context, ids []uuid.UUID, skipped bool) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Model(&db_models.TripReminder{}).
+		Where("id IN ?", ids).
+		Updates(map[string]any{"sent_at": time.Now().Unix(), "skipped": skipped, "last_error": "", "claimed_until": nil}).Error
+}
+
+func (r *TripReminderRepository) MarkFailed(ctx context.Context, id uuid.UUID, reason string) error {
+	return r.db.WithContext(ctx).
+		Model(&db_models.TripReminder{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"attempts": gorm.Expr("attempts + 1"), "last_error": reason, "claimed_until": nil}).Error
+}
+
+func (r *TripReminderRepository) SetRemindersOff(ctx context.Context, journeyID uuid.UUID, off bool) error {
+	return r.db.WithContext(ctx).
+		Model(&db_models.Journey{}).
+		Where("id = ?", journeyID).
+		Update("reminders_off", off).Error
+}
@@ -0,0 +1,211 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"vivu/internal/models/db_models"
+)
+
+// WebhookDeliveryFilter narrows the delivery log; zero values mean "any".
+type WebhookDeliveryFilter struct {
+	EndpointID *uuid.UUID
+	Status     string
+	EventType  string
+}
+
+type WebhookRepositoryInterface interface {
+	CreateEndpoint(ctx context.Context, endpoint *db_models.WebhookEndpoint) error
+	// ListEndpoints returns the endpoints of an account, newest first, and with withGlobal
+	// also the ones receiving every account's events.
+	ListEndpoints(ctx context.Context, accountID uuid.UUID, withGlobal bool) ([]db_models.WebhookEndpoint, error)
+	FindEndpoint(ctx context.Context, id string) (*db_models.WebhookEndpoint, error)
+	// UpdateEndpoint writes url, description, events and active.
+	UpdateEndpoint(ctx context.Context, endpoint *db_models.WebhookEndpoint) (bool, error)
+	DeleteEndpoint(ctx context.Context, id string) (bool, error)
+	// MatchingEndpoints returns the active endpoints subscribed to an event of the account.
+	MatchingEndpoints(ctx context.Context, accountID uuid.UUID, eventType string) ([]db_models.WebhookEndpoint, error)
+
+	CreateDeliveries(ctx context.Context, deliveries []db_models.WebhookDelivery) error
+	// ClaimDue returns pending deliveries whose attempt is due, with their endpoint loaded, and
+	// hides them from other workers for lease.
+	ClaimDue(ctx context.Context, now int64, limit int, lease time.Duration) ([]db_models.WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, id uuid.UUID, statusCode int, at int64) error
+	// MarkAttemptFailed records a failed attempt; nextAttemptAt nil gives up on the delivery.
+	MarkAttemptFailed(ctx context.Context, id uuid.UUID, statusCode int, reason string, nextAttemptAt *int64) error
+	// ListDeliveries returns a page of the log, newest first.
+	ListDeliveries(ctx context.Context, filter WebhookDeliveryFilter, page, pageSize int) ([]db_models.WebhookDelivery, int64, error)
+}
+
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) CreateEndpoint(ctx context.Context, endpoint *db_models.WebhookEndpoint) error {
+	return r.db.WithContext(ctx).Create(endpoint).Error
+}
+
+func (r *WebhookRepository) ListEndpoints(ctx context.Context, accountID uuid.UUID, withGlobal bool) ([]db_models.WebhookEndpoint, error) {
+	var out []db_models.WebhookEndpoint
+	q := r.db.WithContext(ctx).Order("created_at DESC")
+	if withGlobal {
+		q = q.Where("account_id = ? OR account_id IS NULL", accountID)
+	} else {
+		q = q.Where("account_id = ?", accountID)
+	}
+	err := q.Find(&out).Error
+	return out, err
+}
+
+func (r *WebhookRepository) FindEndpoint(ctx context.Context, id string) (*db_models.WebhookEndpoint, error) {
+	var out db_models.WebhookEndpoint
+	err := r.db.WithContext(ctx).First(&out, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *WebhookRepository) UpdateEndpoint(ctx context.Context, endpoint *db_models.WebhookEndpoint) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&db_models.WebhookEndpoint{}).
+		Where("id = ?", endpoint.ID).
+		Updates(map[string]any{
+			"url":         endpoint.URL,
+			"description": endpoint.Description,
+			"events":      endpoint.Events,
+			"active":      endpoint.Active,
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *WebhookRepository) DeleteEndpoint(ctx context.Context, id string) (bool, error) {
+	res := r.db.WithContext(ctx).Where("id = ?", id).Delete(&db_models.WebhookEndpoint{})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *WebhookRepository) MatchingEndpoints(ctx context.Context, accountID uuid.UUID, eventType string) ([]db_models.WebhookEndpoint, error) {
+	var out []db_models.WebhookEndpoint
+	err := r.db.WithContext(ctx).
+		Where("active AND (account_id = ? OR account_id IS NULL)", accountID).
+		Where("(events IS NULL OR cardinality(events) = 0 OR ? = ANY(events))", eventType).
+		Find(&out).Error
+	return out, err
+}
+
+func (r *WebhookRepository) CreateDeliveries(ctx context.Context, deliveries []db_models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Omit("Endpoint").Create(&deliveries).Error
+}
+
+func (r *WebhookRepository) ClaimDue(ctx context.Context, now int64, limit int, lease time.Duration) ([]db_models.WebhookDelivery, error) {
+	var rows []db_models.WebhookDelivery
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Rows locked by a concurrent claim are skipped, not waited for
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", db_models.WebhookDeliveryPending, now).
+			Where("claimed_until IS NULL OR claimed_until < ?", now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&rows).Error
+		if err != nil || len(rows) == 0 {
+			return err
+		}
+		ids := make([]uuid.UUID, len(rows))
+		for i := range rows {
+			ids[i] = rows[i].ID
+		}
+		return tx.Model(&db_models.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("claimed_until", time.Unix(now, 0).Add(lease).Unix()).Error
+	})
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+
+	// Endpoints deleted since the event was published are loaded too, so their rows can be closed
+	endpointIDs := make([]uuid.UUID, 0, len(rows))
+	for i := range rows {
+		endpointIDs = append(endpointIDs, rows[i].EndpointID)
+	}
+	var endpoints []db_models.WebhookEndpoint
+	if err := r.db.WithContext(ctx).Unscoped().Where("id IN ?", endpointIDs).Find(&endpoints).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]db_models.WebhookEndpoint, len(endpoints))
+	for _, e := range endpoints {
+		byID[e.ID] = e
+	}
+	for i := range rows {
+		rows[i].Endpoint = byID[rows[i].EndpointID]
+	}
+	return rows, nil
+}
+
+func (r *WebhookRepository) MarkDelivered(ctx context.Context, id uuid.UUID, statusCode int, at int64) error {
+	return r.db.WithContext(ctx).Model(&db_models.WebhookDelivery{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":           db_models.WebhookDeliveryDelivered,
+			"attempts":         gorm.Expr("attempts + 1"),
+			"last_status_code": statusCode,
+			"last_error":       "",
+			"delivered_at":     at,
+			"claimed_until":    nil,
+		}).Error
+}
+
+func (r *WebhookRepository) MarkAttemptFailed(ctx context.Context, id uuid.UUID, statusCode int, reason string, nextAttemptAt *int64) error {
+	updates := map[string]any{
+		"attempts":         gorm.Expr("attempts + 1"),
+		"last_status_code": statusCode,
+		"last_error":       reason,
+		"claimed_until":    nil,
+	}
+	if nextAttemptAt != nil {
+		updates["next_attempt_at"] = *nextAttemptAt
+	} else {
+		updates["status"] = db_models.WebhookDeliveryFailed
+	}
+	return r.db.WithContext(ctx).Model(&db_models.WebhookDelivery{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, filter WebhookDeliveryFilter, page, pageSize int) ([]db_models.WebhookDelivery, int64, error) {
+	var (
+		rows  []db_models.WebhookDelivery
+		total int64
+	)
+
+	q := r.db.WithContext(ctx).Model(&db_models.WebhookDelivery{})
+	if filter.EndpointID != nil {
+		q = q.Where("endpoint_id = ?", *filter.EndpointID)
+	}
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+	if filter.EventType != "" {
+		q = q.Where("event_type = ?", filter.EventType)
+	}
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := q.
+		Order("created_at DESC, id").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&rows).Error
+	return rows, total, err
+}
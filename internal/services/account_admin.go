@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+func (a *AccountService) ListAccounts(ctx context.Context, query request_models.AdminAccountQuery) (*response_models.AdminAccountPage, error) {
+	accounts, total, err := a.accountRepo.ListAccounts(ctx, repositories.AccountListFilter{
+		Role:         query.Role,
+		Status:       query.Status,
+		Subscription: query.Subscription,
+		Search:       strings.TrimSpace(query.Search),
+	}, query.Page, query.PageSize)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+
+	out := &response_models.AdminAccountPage{
+		Items:    make([]response_models.AdminAccountResponse, 0, len(accounts)),
+		Total:    total,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	}
+	for _, account := range accounts {
+		item := response_models.AdminAccountResponse{
+			ID:                    account.ID.String(),
+			Name:                  account.Name,
+			Email:                 account.Email,
+			Role:                  account.Role,
+			Status:                account.Status,
+			BanReason:             account.BanReason,
+			HasActiveSubscription: len(account.Subs) > 0, // only active ones are loaded
+			TwoFactorEnabled:      account.TOTPEnabled,
+			CreatedAt:             utils.FormatRFC3339VN(utils.FromUnixSecondsVN(account.CreatedAt)),
+		}
+		if account.BannedAt != nil {
+			item.BannedAt = utils.FormatRFC3339VN(utils.FromUnixSecondsVN(*account.BannedAt))
+		}
+		out.Items = append(out.Items, item)
+	}
+	return out, nil
+}
+
+// BanAccount suspends an account and ends its sessions. Admins cannot be banned, so two of
+// them cannot lock each other out.
+func (a *AccountService) BanAccount(ctx context.Context, adminID, accountID string, request request_models.BanAccountRequest) error {
+	if accountID == adminID {
+		return utils.ErrInvalidInput.WithMessage("You cannot ban your own account")
+	}
+	account, err := a.accountRepo.FindById(ctx, accountID)
+	if err != nil {
+		return utils.ErrDatabaseError
+	}
+	if account == nil {
+		return utils.ErrAccountNotFound
+	}
+	if account.Role == "admin" {
+		return utils.ErrInvalidInput.WithMessage("Admin accounts cannot be banned")
+	}
+
+	if _, err := a.accountRepo.SetBanned(ctx, accountID, true, strings.TrimSpace(request.Reason), time.Now().Unix()); err != nil {
+		return utils.ErrDatabaseError
+	}
+	a.sessions.Forget(accountID)
+	return nil
+}
+
+// UnbanAccount lifts a ban; the account has to log in again.
+func (a *AccountService) UnbanAccount(ctx context.Context, accountID string) error {
+	found, err := a.accountRepo.SetBanned(ctx, accountID, false, "", 0)
+	if err != nil {
+		return utils.ErrDatabaseError
+	}
+	if !found {
+		return utils.ErrAccountNotFound
+	}
+	a.sessions.Forget(accountID)
+	return nil
+}
+
+func (a *AccountService) ForceLogout(ctx context.Context, accountID string) error {
+	found, err := a.accountRepo.RevokeSessions(ctx, accountID, time.Now().Unix())
+	if err != nil {
+		return utils.ErrDatabaseError
+	}
+	if !found {
+		return utils.ErrAccountNotFound
+	}
+	a.sessions.Forget(accountID)
+	return nil
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"vivu/internal/models/request_models"
+	mem "vivu/pkg/memcache"
+	"vivu/pkg/utils"
+)
+
+// loginThrottle counts failed logins per email and per client IP. Reaching the limit locks
+// the key for the base lockout, doubling with every consecutive lockout up to the maximum;
+// a successful login or the emailed unlock link clears the email's history.
+type loginThrottle struct {
+	store            mem.LoginAttemptStore
+	maxEmailFailures int           // LOGIN_MAX_FAILURES, default 5
+	maxIPFailures    int           // LOGIN_MAX_IP_FAILURES, default 20
+	lockBase         time.Duration // LOGIN_LOCKOUT_BASE, default 1m
+	lockMax          time.Duration // LOGIN_LOCKOUT_MAX, default 1h
+	memory           time.Duration // how long a quiet key keeps its history
+}
+
+func newLoginThrottle(store mem.LoginAttemptStore) *loginThrottle {
+	t := &loginThrottle{
+		store:            store,
+		maxEmailFailures: 5,
+		maxIPFailures:    20,
+		lockBase:         time.Minute,
+		lockMax:          time.Hour,
+		memory:           24 * time.Hour,
+	}
+	if n, err := strconv.Atoi(os.Getenv("LOGIN_MAX_FAILURES")); err == nil && n > 0 {
+		t.maxEmailFailures = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("LOGIN_MAX_IP_FAILURES")); err == nil && n > 0 {
+		t.maxIPFailures = n
+	}
+	if d, err := time.ParseDuration(os.Getenv("LOGIN_LOCKOUT_BASE")); err == nil && d > 0 {
+		t.lockBase = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("LOGIN_LOCKOUT_MAX")); err == nil && d >= t.lockBase {
+		t.lockMax = d
+	}
+	return t
+}
+
+func emailAttemptKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// check returns the lockout error while the email or the IP is locked.
+func (t *loginThrottle) check(email, ip string) error {
+	now := time.Now()
+	until := t.store.Get(emailAttemptKey(email)).LockedUntil
+	if ip != "" {
+		if ipUntil := t.store.Get("ip:" + ip).LockedUntil; ipUntil.After(until) {
+			until = ipUntil
+		}
+	}
+	if until.After(now) {
+		return utils.NewLoginLockedError(until)
+	}
+	return nil
+}
+
+// fail records a failed attempt. It returns the new lockout of the email, if this failure
+// caused one, with the token of its unlock link.
+func (t *loginThrottle) fail(email, ip string) (lockedUntil time.Time, unlockToken string) {
+	now := time.Now()
+	if ip != "" {
+		t.store.Update("ip:"+ip, t.memory, func(a *mem.LoginAttempts) { t.record(a, t.maxIPFailures, now) })
+	}
+
+	locked := false
+	state := t.store.Update(emailAttemptKey(email), t.memory, func(a *mem.LoginAttempts) {
+		if locked = t.record(a, t.maxEmailFailures, now); !locked {
+			return
+		}
+		token, err := utils.GenerateSecureToken(24)
+		if err != nil {
+			log.Printf("[login] unlock token: %v", err)
+			return
+		}
+		a.UnlockToken = token
+	})
+	if !locked {
+		return time.Time{}, ""
+	}
+	return state.LockedUntil, state.UnlockToken
+}
+
+// record counts a failure and reports whether it locked the key.
+func (t *loginThrottle) record(a *mem.LoginAttempts, limit int, now time.Time) bool {
+	if a.LockedUntil.After(now) {
+		return false
+	}
+	a.Failures++
+	if a.Failures < limit {
+		return false
+	}
+	lock := t.lockBase
+	for i := 0; i < a.Lockouts && lock < t.lockMax; i++ {
+		lock *= 2
+	}
+	if lock > t.lockMax {
+		lock = t.lockMax
+	}
+	a.Failures = 0
+	a.Lockouts++
+	a.LockedUntil = now.Add(lock)
+	return true
+}
+
+func (t *loginThrottle) succeed(email string) {
+	t.store.Delete(emailAttemptKey(email))
+}
+
+// unlock clears the email's lockout when token is its current unlock token.
+func (t *loginThrottle) unlock(email, token string) bool {
+	key := emailAttemptKey(email)
+	state := t.store.Get(key)
+	if state.UnlockToken == "" || subtle.ConstantTimeCompare([]byte(state.UnlockToken), []byte(token)) != 1 {
+		return false
+	}
+	t.store.Delete(key)
+	return true
+}
+
+// loginFailed records a failed login and, when it locks a known account, emails the owner an
+// unlock link. It returns the error to report for the attempt.
+func (a *AccountService) loginFailed(email, ip string, known bool, failure error) error {
+	until, token := a.throttle.fail(email, ip)
+	if until.IsZero() {
+		return failure
+	}
+	if known && token != "" {
+		link := fmt.Sprintf("%s/unlock?email=%s&token=%s", a.publicAppURL, url.QueryEscape(email), token)
+		body := fmt.Sprintf("We blocked sign-ins to your account after several failed attempts, until %s (Vietnam time). "+
+			"If this was you, use the link to unlock it now. If it was not, consider resetting your password.",
+			until.In(vnLoc).Format("15:04 02/01/2006"))
+		if err := a.mailService.SendMailToNotifyUser(email, "Your Vivu account was locked", body, "Unlock my account", link); err != nil {
+			log.Printf("Failed to send unlock email to %s: %v", email, err)
+		}
+	}
+	return utils.NewLoginLockedError(until)
+}
+
+func (a *AccountService) UnlockLogin(ctx context.Context, request request_models.UnlockLoginRequest) error {
+	if !a.throttle.unlock(request.Email, request.Token) {
+		return utils.ErrInvalidToken
+	}
+	return nil
+}
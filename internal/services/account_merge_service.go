@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+type AccountMergeServiceInterface interface {
+	MergeAccounts(ctx context.Context, req request_models.MergeAccountsRequest, performedBy string) (*response_models.AccountMergeReport, error)
+}
+
+type AccountMergeService struct {
+	accountRepo repositories.AccountRepository
+	mergeRepo   repositories.AccountMergeRepositoryInterface
+}
+
+func NewAccountMergeService(accountRepo repositories.AccountRepository, mergeRepo repositories.AccountMergeRepositoryInterface) AccountMergeServiceInterface {
+	return &AccountMergeService{accountRepo: accountRepo, mergeRepo: mergeRepo}
+}
+
+// MergeAccounts moves everything the source account owns (journeys, check-ins, billing,
+// feedback, trip memberships, polls and votes, comments, documents, expenses, presets, plan
+// usage and booking clicks) to the target and removes the source. With DryRun nothing is changed and the
+// report shows what would move. Both outcomes are written to account_merge_logs.
+func (s *AccountMergeService) MergeAccounts(ctx context.Context, req request_models.MergeAccountsRequest, performedBy string) (*response_models.AccountMergeReport, error) {
+	sourceID, err := uuid.Parse(req.SourceAccountID)
+	if err != nil {
+		return nil, utils.ErrInvalidInput
+	}
+	targetID, err := uuid.Parse(req.TargetAccountID)
+	if err != nil || sourceID == targetID {
+		return nil, utils.ErrInvalidInput
+	}
+
+	source, err := s.accountRepo.FindById(ctx, sourceID.String())
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	target, err := s.accountRepo.FindById(ctx, targetID.String())
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if source == nil || target == nil {
+		return nil, utils.ErrAccountNotFound
+	}
+
+	sourceOwned, err := s.mergeRepo.CountOwned(ctx, sourceID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	targetOwned, err := s.mergeRepo.CountOwned(ctx, targetID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+
+	report := &response_models.AccountMergeReport{
+		DryRun:          req.DryRun,
+		SourceAccountID: sourceID.String(),
+		SourceEmail:     source.Email,
+		TargetAccountID: targetID.String(),
+		TargetEmail:     target.Email,
+		Moved:           toMergeCounts(sourceOwned),
+		Warnings:        mergeWarnings(source, target, sourceOwned, targetOwned),
+	}
+
+	audit := &db_models.AccountMergeLog{
+		BaseModel:       db_models.BaseModel{ID: uuid.New()},
+		SourceAccountID: sourceID,
+		TargetAccountID: targetID,
+		PerformedBy:     performedBy,
+		DryRun:          req.DryRun,
+	}
+	report.MergeID = audit.ID.String()
+
+	if req.DryRun {
+		audit.Report, _ = json.Marshal(report)
+		if err := s.mergeRepo.InsertMergeLog(ctx, audit); err != nil {
+			log.Printf("[account-merge] failed to write dry-run audit %s: %v", audit.ID, err)
+		}
+		return report, nil
+	}
+
+	// The audit row is written inside the merge transaction, so it reflects the planned counts;
+	// the actual counts come back from the repository and replace them in the response.
+	audit.Report, _ = json.Marshal(report)
+	moved, err := s.mergeRepo.MergeAccounts(ctx, sourceID, targetID, audit)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrAccountNotFound
+		}
+		log.Printf("[account-merge] merge %s -> %s failed: %v", sourceID, targetID, err)
+		return nil, utils.ErrDatabaseError
+	}
+	report.Moved = toMergeCounts(moved)
+
+	log.Printf("[account-merge] %s merged %s (%s) into %s (%s): %+v",
+		performedBy, sourceID, source.Email, targetID, target.Email, report.Moved)
+
+	return report, nil
+}
+
+func toMergeCounts(o repositories.AccountOwnership) response_models.AccountMergeCounts {
+	return response_models.AccountMergeCounts{
+		Journeys:      o.Journeys,
+		CheckIns:      o.CheckIns,
+		Subscriptions: o.Subscriptions,
+		Transactions:  o.Transactions,
+		Feedback:      o.Feedback,
+
+		JourneyMemberships: o.JourneyMemberships,
+		Polls:              o.Polls,
+		PollVotes:          o.PollVotes,
+		Comments:           o.Comments,
+		TravelDocuments:    o.TravelDocuments,
+		PlanUsages:         o.PlanUsages,
+		Expenses:           o.Expenses,
+		TravelPresets:      o.TravelPresets,
+		BookingEvents:      o.BookingEvents,
+		APIKeys:            o.APIKeys,
+		PlanningPolicies:   o.PlanningPolicies,
+		WebhookEndpoints:   o.WebhookEndpoints,
+		PlanChanges:        o.PlanChanges,
+		Notifications:      o.Notifications,
+		BackupJobs:         o.BackupJobs,
+		ChurnScores:        o.ChurnScores,
+		LegalAcceptances:   o.LegalAcceptances,
+	}
+}
+
+// mergeWarnings flags things an admin should look at before (or after) confirming a merge.
+func mergeWarnings(source, target *db_models.Account, sourceOwned, targetOwned repositories.AccountOwnership) []string {
+	var out []string
+	if sourceOwned.ActiveSubscriptions > 0 && targetOwned.ActiveSubscriptions > 0 {
+		out = append(out, "both accounts have an active subscription; the target will hold two until one is canceled")
+	}
+	if source.Role != target.Role {
+		out = append(out, "accounts have different roles ("+source.Role+" vs "+target.Role+"); the target's role is kept")
+	}
+	if source.Role == "admin" {
+		out = append(out, "source account is an admin and will lose access after the merge")
+	}
+	return out
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+func (a *AccountService) UpdateProfile(ctx context.Context, accountID string, request request_models.UpdateProfileRequest) (response_models.AccountResponse, error) {
+	name := strings.TrimSpace(request.Name)
+	if len([]rune(name)) < 3 {
+		return response_models.AccountResponse{}, utils.ErrInvalidInput.WithMessage("name must be at least 3 characters")
+	}
+
+	found, err := a.accountRepo.UpdateProfile(ctx, accountID, repositories.ProfileUpdate{
+		Name:              name,
+		AvatarURL:         strings.TrimSpace(request.AvatarURL),
+		Locale:            request.Locale,
+		PreferredCurrency: strings.ToUpper(request.PreferredCurrency),
+	})
+	if err != nil {
+		return response_models.AccountResponse{}, utils.ErrDatabaseError
+	}
+	if !found {
+		return response_models.AccountResponse{}, utils.ErrAccountNotFound
+	}
+	return a.GetProfileInfo(ctx, accountID)
+}
+
+// ChangePassword replaces the password of a signed-in account. Wrong current passwords count
+// as failed logins of the account, so a stolen session cannot be used to guess it. Every
+// session, this one included, ends with the old password.
+func (a *AccountService) ChangePassword(ctx context.Context, accountID string, request request_models.ChangePasswordRequest) error {
+	account, err := a.accountRepo.FindById(ctx, accountID)
+	if err != nil {
+		return utils.ErrDatabaseError
+	}
+	if account == nil {
+		return utils.ErrAccountNotFound
+	}
+
+	if err := a.throttle.check(account.Email, ""); err != nil {
+		return err
+	}
+	if err := utils.ComparePasswords(account.PasswordHash, request.OldPassword); err != nil {
+		return a.loginFailed(account.Email, "", true, utils.ErrInvalidCredentials.WithMessage("Current password is incorrect"))
+	}
+	if request.NewPassword == request.OldPassword {
+		return utils.ErrInvalidInput.WithMessage("The new password must be different from the current one")
+	}
+
+	hashed, err := utils.HashPassword(request.NewPassword)
+	if err != nil {
+		return utils.ErrInternal.Wrap(err)
+	}
+	changed, err := a.accountRepo.UpdatePassword(ctx, accountID, account.PasswordHash, hashed)
+	if err != nil {
+		return utils.ErrDatabaseError
+	}
+	if !changed {
+		// Reset or changed by another request since we read it
+		return utils.ErrInvalidCredentials.WithMessage("Current password is incorrect")
+	}
+	a.throttle.succeed(account.Email)
+	a.endSessions(ctx, accountID)
+
+	err = a.mailService.SendMailToNotifyUser(account.Email, "Your Vivu password was changed",
+		"The password of your account was just changed. If this was not you, reset it now.",
+		"Reset my password", a.publicAppURL+"/forgot-password")
+	if err != nil {
+		log.Printf("Failed to send password change email to %s: %v", account.Email, err)
+	}
+	return nil
+}
+
+// endSessions logs the account out everywhere after its password changed. The password stands
+// if this fails; an admin can still force the logout.
+func (a *AccountService) endSessions(ctx context.Context, accountID string) {
+	if _, err := a.accountRepo.RevokeSessions(ctx, accountID, time.Now().Unix()); err != nil {
+		log.Printf("Failed to revoke sessions of %s after a password change: %v", accountID, err)
+	}
+	a.sessions.Forget(accountID)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
 	"time"
 	"vivu/internal/models/db_models"
 	"vivu/internal/models/request_models"
@@ -22,6 +24,21 @@ type AccountServiceInterface interface {
 	IsUserHaveSubscription(accountID string) (bool, error)
 	GetAllAccounts(ctx context.Context) ([]response_models.AccountResponse, error)
 	GetProfileInfo(ctx context.Context, accountID string) (response_models.AccountResponse, error)
+	UpdateWorkingWindow(ctx context.Context, accountID string, request request_models.UpdateWorkingWindowRequest) (response_models.AccountResponse, error)
+	UpdateCompanions(ctx context.Context, accountID string, request request_models.UpdateCompanionsRequest) (response_models.AccountResponse, error)
+	UpdateAvoid(ctx context.Context, accountID string, request request_models.UpdateAvoidRequest) (response_models.AccountResponse, error)
+	UpdateTripEmails(ctx context.Context, accountID string, request request_models.UpdateTripEmailsRequest) (response_models.AccountResponse, error)
+	UpdateProfile(ctx context.Context, accountID string, request request_models.UpdateProfileRequest) (response_models.AccountResponse, error)
+	ChangePassword(ctx context.Context, accountID string, request request_models.ChangePasswordRequest) error
+	SetupTwoFactor(ctx context.Context, accountID string) (*response_models.TwoFactorSetupResponse, error)
+	VerifyTwoFactor(ctx context.Context, accountID string, request request_models.TwoFactorVerifyRequest) error
+	CompleteTwoFactorLogin(ctx context.Context, request request_models.TwoFactorChallengeRequest) (response_models.AccountLoginResponse, error)
+	UnlockLogin(ctx context.Context, request request_models.UnlockLoginRequest) error
+	ListAccounts(ctx context.Context, query request_models.AdminAccountQuery) (*response_models.AdminAccountPage, error)
+	BanAccount(ctx context.Context, adminID, accountID string, request request_models.BanAccountRequest) error
+	UnbanAccount(ctx context.Context, accountID string) error
+	// ForceLogout revokes every token issued to the account so far.
+	ForceLogout(ctx context.Context, accountID string) error
 }
 
 type AccountService struct {
@@ -30,6 +47,8 @@ type AccountService struct {
 	resetStore   mem.ResetTokenStore // inject this
 	resetTTL     time.Duration       // e.g., 1 * time.Hour
 	publicAppURL string
+	throttle     *loginThrottle
+	sessions     *AccountSessionGuard
 }
 
 func (a *AccountService) GetProfileInfo(ctx context.Context, accountID string) (response_models.AccountResponse, error) {
@@ -47,10 +66,102 @@ func (a *AccountService) GetProfileInfo(ctx context.Context, accountID string) (
 		Name:                 account.Name,
 		Email:                account.Email,
 		Role:                 account.Role,
+		AvatarURL:            account.AvatarURL,
+		Locale:               account.Locale,
+		PreferredCurrency:    account.PreferredCurrency,
+		BirthYear:            account.BirthYear,
+		Minor:                isMinor(account.BirthYear, time.Now().In(vnLoc)),
 		SubscriptionSnapshot: account.SubscriptionSnapshot,
+		DayStart:             account.DayStart,
+		DayEnd:               account.DayEnd,
+		Companions:           toCompanionResponses(account.Companions),
+		Avoid:                append([]string{}, account.Avoid...),
+		TripEmails:           !account.TripEmailsOff,
 	}, nil
 }
 
+func (a *AccountService) UpdateWorkingWindow(ctx context.Context, accountID string, request request_models.UpdateWorkingWindowRequest) (response_models.AccountResponse, error) {
+	start, end := strings.TrimSpace(request.DayStart), strings.TrimSpace(request.DayEnd)
+	if start != "" || end != "" {
+		w, ok := NewWorkingWindow(start, end)
+		if !ok {
+			return response_models.AccountResponse{}, utils.ErrInvalidInput
+		}
+		start, end = w.StartClock(), w.EndClock()
+	}
+
+	found, err := a.accountRepo.UpdateWorkingWindow(ctx, accountID, start, end)
+	if err != nil {
+		return response_models.AccountResponse{}, utils.ErrDatabaseError
+	}
+	if !found {
+		return response_models.AccountResponse{}, utils.ErrAccountNotFound
+	}
+	return a.GetProfileInfo(ctx, accountID)
+}
+
+func (a *AccountService) UpdateCompanions(ctx context.Context, accountID string, request request_models.UpdateCompanionsRequest) (response_models.AccountResponse, error) {
+	companions := make([]db_models.Companion, 0, len(request.Companions))
+	for _, c := range request.Companions {
+		companions = append(companions, db_models.Companion{
+			Name:     strings.TrimSpace(c.Name),
+			Relation: c.Relation,
+			Age:      c.Age,
+			Notes:    strings.TrimSpace(c.Notes),
+		})
+	}
+
+	found, err := a.accountRepo.UpdateCompanions(ctx, accountID, companions)
+	if err != nil {
+		return response_models.AccountResponse{}, utils.ErrDatabaseError
+	}
+	if !found {
+		return response_models.AccountResponse{}, utils.ErrAccountNotFound
+	}
+	return a.GetProfileInfo(ctx, accountID)
+}
+
+func (a *AccountService) UpdateAvoid(ctx context.Context, accountID string, request request_models.UpdateAvoidRequest) (response_models.AccountResponse, error) {
+	avoid := make([]string, 0, len(request.Avoid))
+	seen := make(map[string]bool)
+	for _, entry := range request.Avoid {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || seen[strings.ToLower(entry)] {
+			continue
+		}
+		seen[strings.ToLower(entry)] = true
+		avoid = append(avoid, entry)
+	}
+
+	found, err := a.accountRepo.UpdateAvoid(ctx, accountID, avoid)
+	if err != nil {
+		return response_models.AccountResponse{}, utils.ErrDatabaseError
+	}
+	if !found {
+		return response_models.AccountResponse{}, utils.ErrAccountNotFound
+	}
+	return a.GetProfileInfo(ctx, accountID)
+}
+
+func (a *AccountService) UpdateTripEmails(ctx context.Context, accountID string, request request_models.UpdateTripEmailsRequest) (response_models.AccountResponse, error) {
+	found, err := a.accountRepo.UpdateTripEmailsOff(ctx, accountID, !*request.Enabled)
+	if err != nil {
+		return response_models.AccountResponse{}, utils.ErrDatabaseError
+	}
+	if !found {
+		return response_models.AccountResponse{}, utils.ErrAccountNotFound
+	}
+	return a.GetProfileInfo(ctx, accountID)
+}
+
+func toCompanionResponses(list []db_models.Companion) []response_models.Companion {
+	out := make([]response_models.Companion, 0, len(list))
+	for _, c := range list {
+		out = append(out, response_models.Companion{Name: c.Name, Relation: c.Relation, Age: c.Age, Notes: c.Notes})
+	}
+	return out
+}
+
 func (a *AccountService) GetAllAccounts(ctx context.Context) ([]response_models.AccountResponse, error) {
 	accounts, err := a.accountRepo.GetAllAccounts(ctx)
 	if err != nil {
@@ -83,9 +194,10 @@ func (a *AccountService) IsUserHaveSubscription(accountID string) (bool, error)
 
 	fmt.Printf("Account details: %+v\n", account)
 
-	// Check if the account has active subscriptions
+	// Check if the account has active subscriptions; a past-due one keeps the plan through
+	// the dunning grace period, until the renewal job expires it
 	for _, sub := range account.Subs {
-		if sub.Status == db_models.SubStatusActive {
+		if sub.Status == db_models.SubStatusActive || sub.Status == db_models.SubStatusPastDue {
 			return true, nil
 		}
 	}
@@ -106,18 +218,24 @@ func (a *AccountService) VerifyOtpToken(request request_models.RequestVerifyOtpT
 	return utils.ErrInvalidToken
 }
 
-func NewAccountService(accountRepo repositories.AccountRepository, mailService IMailService, resetStore mem.ResetTokenStore) AccountServiceInterface {
+func NewAccountService(accountRepo repositories.AccountRepository, mailService IMailService, resetStore mem.ResetTokenStore, attempts mem.LoginAttemptStore, sessions *AccountSessionGuard) AccountServiceInterface {
 	return &AccountService{
 		accountRepo:  accountRepo,
 		mailService:  mailService,
 		resetStore:   resetStore,
 		resetTTL:     time.Hour,
 		publicAppURL: "https://vivu.com",
+		throttle:     newLoginThrottle(attempts),
+		sessions:     sessions,
 	}
 }
 
 func (a *AccountService) Login(request request_models.LoginRequest, ctx context.Context) (response_models.AccountLoginResponse, error) {
 
+	if err := a.throttle.check(request.Email, request.ClientIP); err != nil {
+		return response_models.AccountLoginResponse{}, err
+	}
+
 	startTime := time.Now()
 
 	account, err := a.accountRepo.FindByEmail(ctx, request.Email)
@@ -128,14 +246,32 @@ func (a *AccountService) Login(request request_models.LoginRequest, ctx context.
 	log.Printf("Login process took %s", time.Since(startTime))
 
 	if account == nil {
-		return response_models.AccountLoginResponse{}, utils.ErrAccountNotFound
+		return response_models.AccountLoginResponse{}, a.loginFailed(request.Email, request.ClientIP, false, utils.ErrAccountNotFound)
 	}
 
 	err = utils.ComparePasswords(account.PasswordHash, request.Password)
 	if err != nil {
-		return response_models.AccountLoginResponse{}, utils.ErrInvalidCredentials
+		return response_models.AccountLoginResponse{}, a.loginFailed(account.Email, request.ClientIP, true, utils.ErrInvalidCredentials)
+	}
+	if account.Status == db_models.AccountStatusBanned {
+		return response_models.AccountLoginResponse{}, utils.ErrAccountBanned
+	}
+
+	// With 2FA the history is only cleared once the code is right too
+	if account.TOTPEnabled {
+		challenge, err := utils.CreateTwoFactorChallenge(account.ID)
+		if err != nil {
+			return response_models.AccountLoginResponse{}, utils.ErrInternal.Wrap(err)
+		}
+		return response_models.AccountLoginResponse{TwoFactorRequired: true, ChallengeToken: challenge}, nil
 	}
+	a.throttle.succeed(account.Email)
+
+	return a.loginResponse(account)
+}
 
+// loginResponse issues the access token of an authenticated account.
+func (a *AccountService) loginResponse(account *db_models.Account) (response_models.AccountLoginResponse, error) {
 	token, err := utils.CreateToken(account.ID, account.Role)
 	if err != nil {
 		return response_models.AccountLoginResponse{}, utils.ErrInvalidCredentials
@@ -155,6 +291,10 @@ func (a *AccountService) Login(request request_models.LoginRequest, ctx context.
 
 func (a *AccountService) CreateAccount(request request_models.SignUpRequest) error {
 
+	if utils.IsDisposableEmail(request.Email) {
+		return utils.ErrDisposableEmail
+	}
+
 	existingAccount, err := a.accountRepo.FindByEmail(context.Background(), request.Email)
 	if err != nil {
 		return utils.ErrDatabaseError
@@ -163,6 +303,10 @@ func (a *AccountService) CreateAccount(request request_models.SignUpRequest) err
 		return utils.ErrEmailAlreadyExists
 	}
 
+	if request.BirthYear != nil && *request.BirthYear > time.Now().In(vnLoc).Year() {
+		return utils.ErrInvalidInput.WithMessage("birth_year cannot be in the future")
+	}
+
 	hashedPassword, err := utils.HashPassword(request.Password)
 	if err != nil {
 		return utils.ErrDatabaseError
@@ -173,20 +317,22 @@ func (a *AccountService) CreateAccount(request request_models.SignUpRequest) err
 		Email:        request.Email,
 		PasswordHash: hashedPassword,
 		Role:         "user", // default role
+		Locale:       request.Locale,
+		BirthYear:    request.BirthYear,
 	}
 
 	if err := a.accountRepo.InsertTx(newAccount, context.Background()); err != nil {
 		return utils.ErrDatabaseError
 	}
 
-	go func() {
-		err := a.mailService.SendMailToNotifyUser(newAccount.Email, "Welcome to Vivu", "Your account is ready. Explore features and let us know if you need help!", "click here", "https://vivu.com/login")
-		if err != nil {
-			log.Printf("Failed to send welcome email to %s: %v", newAccount.Email, err)
-		} else {
-			log.Printf("Welcome email sent to %s", newAccount.Email)
-		}
-	}()
+	// Queued in the mail outbox, which retries it until it goes through
+	err = a.mailService.SendLocalized(newAccount.Email, newAccount.Locale, MailTemplateWelcome, map[string]string{
+		"name": newAccount.Name,
+		"url":  a.publicAppURL + "/login",
+	})
+	if err != nil {
+		log.Printf("Failed to send welcome email to %s: %v", newAccount.Email, err)
+	}
 
 	return nil
 }
@@ -210,18 +356,13 @@ func (a *AccountService) ForgotPassword(email string) error {
 	// 3) Cache the token (token -> accountID) with TTL
 	a.resetStore.Set(resetToken, account.Email, a.resetTTL)
 
-	go func() {
-
-		err := a.mailService.SendMailToResetPassword(
-			account.Email,
-			resetToken,
-		)
-		if err != nil {
-			log.Printf("Failed to send password reset email to %s: %v", account.Email, err)
-		}
-	}()
-
-	log.Printf("Password reset email sent to %s", account.Email)
+	err = a.mailService.SendLocalized(account.Email, account.Locale, MailTemplateResetPassword, map[string]string{
+		"code":            resetToken,
+		"expires_minutes": strconv.Itoa(int(a.resetTTL.Minutes())),
+	})
+	if err != nil {
+		log.Printf("Failed to send password reset email to %s: %v", account.Email, err)
+	}
 	return nil
 }
 
@@ -241,6 +382,12 @@ func (a *AccountService) VerifyAndConsumeResetToken(resetRequest request_models.
 	if err != nil {
 		return "", utils.ErrDatabaseError
 	}
+	// Whoever made the reset necessary may still hold a session (the token maps to the email)
+	if account, err := a.accountRepo.FindByEmail(context.Background(), accountID); err == nil && account != nil {
+		a.endSessions(context.Background(), account.ID.String())
+	} else {
+		log.Printf("Failed to end sessions of %s after a password reset: %v", accountID, err)
+	}
 
 	return accountID, nil
 }
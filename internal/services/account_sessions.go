@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"vivu/internal/models/db_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+// AccountSessionGuard decides whether the token of an account is still accepted: not when
+// the account is banned, gone, or its sessions were revoked after the token was issued.
+// Account states are cached briefly, so another instance sees a ban within the TTL; the
+// instance that bans or logs out an account forgets its cached state at once.
+type AccountSessionGuard struct {
+	repo repositories.AccountRepository
+	ttl  time.Duration
+
+	mu     sync.Mutex
+	states map[string]sessionState
+}
+
+type sessionState struct {
+	found     bool
+	banned    bool
+	revokedAt int64
+	expiresAt time.Time
+}
+
+func NewAccountSessionGuard(repo repositories.AccountRepository) *AccountSessionGuard {
+	return &AccountSessionGuard{
+		repo:   repo,
+		ttl:    30 * time.Second,
+		states: make(map[string]sessionState),
+	}
+}
+
+func (g *AccountSessionGuard) Check(ctx context.Context, userID string, issuedAt time.Time) error {
+	state, err := g.state(ctx, userID)
+	if err != nil {
+		return utils.ErrDatabaseError.Wrap(err)
+	}
+	switch {
+	case !state.found:
+		return utils.ErrInvalidToken
+	case state.banned:
+		return utils.ErrAccountBanned
+	// Both are whole seconds: a token from the second of the revocation is the login that
+	// followed it (e.g. right after a password reset), so only earlier ones are refused
+	case state.revokedAt > 0 && issuedAt.Unix() < state.revokedAt:
+		return utils.ErrSessionRevoked
+	}
+	return nil
+}
+
+// Forget drops the cached state of an account after it changed.
+func (g *AccountSessionGuard) Forget(userID string) {
+	g.mu.Lock()
+	delete(g.states, userID)
+	g.mu.Unlock()
+}
+
+func (g *AccountSessionGuard) state(ctx context.Context, userID string) (sessionState, error) {
+	now := time.Now()
+	g.mu.Lock()
+	state, ok := g.states[userID]
+	g.mu.Unlock()
+	if ok && now.Before(state.expiresAt) {
+		return state, nil
+	}
+
+	account, err := g.repo.FindSessionState(ctx, userID)
+	if err != nil {
+		return sessionState{}, err
+	}
+	state = sessionState{expiresAt: now.Add(g.ttl)}
+	if account != nil {
+		state.found = true
+		state.banned = account.Status == db_models.AccountStatusBanned
+		state.revokedAt = account.SessionsRevokedAt
+	}
+
+	g.mu.Lock()
+	if len(g.states) > 50000 { // a token flood should not grow the cache forever
+		g.states = make(map[string]sessionState)
+	}
+	g.states[userID] = state
+	g.mu.Unlock()
+	return state, nil
+}
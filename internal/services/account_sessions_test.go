@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"vivu/internal/models/db_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+// sessionStateRepo serves FindSessionState from a fixed account; the guard needs nothing else.
+type sessionStateRepo struct {
+	repositories.AccountRepository
+	account *db_models.Account
+}
+
+func (r sessionStateRepo) FindSessionState(ctx context.Context, accountId string) (*db_models.Account, error) {
+	return r.account, nil
+}
+
+func TestAccountSessionGuardCheck(t *testing.T) {
+	revokedAt := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		account  *db_models.Account
+		issuedAt time.Time
+		want     error
+	}{
+		{
+			name:     "never revoked",
+			account:  &db_models.Account{Status: db_models.AccountStatusActive},
+			issuedAt: revokedAt,
+		},
+		{
+			name:     "issued before the revocation",
+			account:  &db_models.Account{Status: db_models.AccountStatusActive, SessionsRevokedAt: revokedAt.Unix()},
+			issuedAt: revokedAt.Add(-time.Second),
+			want:     utils.ErrSessionRevoked,
+		},
+		{
+			name:     "login in the same second as the revocation",
+			account:  &db_models.Account{Status: db_models.AccountStatusActive, SessionsRevokedAt: revokedAt.Unix()},
+			issuedAt: revokedAt.Add(500 * time.Millisecond),
+		},
+		{
+			name:     "issued after the revocation",
+			account:  &db_models.Account{Status: db_models.AccountStatusActive, SessionsRevokedAt: revokedAt.Unix()},
+			issuedAt: revokedAt.Add(time.Minute),
+		},
+		{
+			name:     "banned",
+			account:  &db_models.Account{Status: db_models.AccountStatusBanned},
+			issuedAt: revokedAt,
+			want:     utils.ErrAccountBanned,
+		},
+		{
+			name:     "account gone",
+			issuedAt: revokedAt,
+			want:     utils.ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guard := NewAccountSessionGuard(sessionStateRepo{account: tt.account})
+			err := guard.Check(context.Background(), "account-1", tt.issuedAt)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Check() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/pkg/utils"
+)
+
+const (
+	totpIssuer      = "Vivu"
+	backupCodeCount = 10
+)
+
+var backupCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// SetupTwoFactor creates a new secret and backup codes. 2FA is enforced only after
+// VerifyTwoFactor; calling it again before that replaces the pending secret.
+func (a *AccountService) SetupTwoFactor(ctx context.Context, accountID string) (*response_models.TwoFactorSetupResponse, error) {
+	account, err := a.accountRepo.FindById(ctx, accountID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if account == nil {
+		return nil, utils.ErrAccountNotFound
+	}
+	if account.TOTPEnabled {
+		return nil, utils.ErrInvalidInput.WithMessage("Two-factor authentication is already enabled")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, utils.ErrInternal.Wrap(err)
+	}
+	sealed, err := utils.EncryptString(secret)
+	if errors.Is(err, utils.ErrEncryptionKeyMissing) {
+		return nil, utils.ErrStorageNotConfigured
+	}
+	if err != nil {
+		return nil, utils.ErrInternal.Wrap(err)
+	}
+
+	codes := make([]string, 0, backupCodeCount)
+	hashes := make([]string, 0, backupCodeCount)
+	for i := 0; i < backupCodeCount; i++ {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, utils.ErrInternal.Wrap(err)
+		}
+		code := strings.ToLower(backupCodeEncoding.EncodeToString(b))[:10]
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, hashBackupCode(code))
+	}
+
+	ok, err := a.accountRepo.SaveTOTPSetup(ctx, accountID, sealed, hashes)
+	if err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	if !ok {
+		return nil, utils.ErrInvalidInput.WithMessage("Two-factor authentication is already enabled")
+	}
+	return &response_models.TwoFactorSetupResponse{
+		ProvisioningURI: utils.TOTPProvisioningURI(totpIssuer, account.Email, secret),
+		Secret:          secret,
+		BackupCodes:     codes,
+	}, nil
+}
+
+// VerifyTwoFactor confirms the pending secret with a first code and turns 2FA on.
+func (a *AccountService) VerifyTwoFactor(ctx context.Context, accountID string, request request_models.TwoFactorVerifyRequest) error {
+	account, err := a.accountRepo.FindById(ctx, accountID)
+	if err != nil {
+		return utils.ErrDatabaseError
+	}
+	if account == nil {
+		return utils.ErrAccountNotFound
+	}
+	if account.TOTPEnabled {
+		return utils.ErrInvalidInput.WithMessage("Two-factor authentication is already enabled")
+	}
+	if account.TOTPSecret == "" {
+		return utils.ErrInvalidInput.WithMessage("Start the two-factor setup first")
+	}
+
+	step, ok, err := checkTOTP(account, request.Code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return utils.ErrInvalidCredentials.WithMessage("Invalid two-factor code")
+	}
+	if _, err := a.accountRepo.EnableTOTP(ctx, accountID, step); err != nil {
+		return utils.ErrDatabaseError.Wrap(err)
+	}
+	return nil
+}
+
+// CompleteTwoFactorLogin exchanges a login challenge and a TOTP or backup code for a token.
+func (a *AccountService) CompleteTwoFactorLogin(ctx context.Context, request request_models.TwoFactorChallengeRequest) (response_models.AccountLoginResponse, error) {
+	accountID, err := utils.ValidateTwoFactorChallenge(request.ChallengeToken)
+	if err != nil {
+		return response_models.AccountLoginResponse{}, utils.ErrInvalidToken
+	}
+	account, err := a.accountRepo.FindById(ctx, accountID)
+	if err != nil {
+		return response_models.AccountLoginResponse{}, utils.ErrDatabaseError
+	}
+	if account == nil || !account.TOTPEnabled {
+		return response_models.AccountLoginResponse{}, utils.ErrInvalidToken
+	}
+	if account.Status == db_models.AccountStatusBanned {
+		return response_models.AccountLoginResponse{}, utils.ErrAccountBanned
+	}
+
+	// Wrong codes count towards the same lockout as wrong passwords
+	if err := a.throttle.check(account.Email, ""); err != nil {
+		return response_models.AccountLoginResponse{}, err
+	}
+	if err := a.useSecondFactor(ctx, account, request.Code); err != nil {
+		if errors.Is(err, utils.ErrInvalidCredentials) {
+			return response_models.AccountLoginResponse{}, a.loginFailed(account.Email, "", true, err)
+		}
+		return response_models.AccountLoginResponse{}, err
+	}
+	a.throttle.succeed(account.Email)
+	return a.loginResponse(account)
+}
+
+// useSecondFactor accepts a TOTP code once, or consumes a backup code.
+func (a *AccountService) useSecondFactor(ctx context.Context, account *db_models.Account, code string) error {
+	code = strings.TrimSpace(code)
+	if len(code) == 6 {
+		step, ok, err := checkTOTP(account, code)
+		if err != nil {
+			return err
+		}
+		if ok {
+			used, err := a.accountRepo.UseTOTPStep(ctx, account.ID.String(), step)
+			if err != nil {
+				return utils.ErrDatabaseError.Wrap(err)
+			}
+			if used {
+				return nil
+			}
+		}
+		return utils.ErrInvalidCredentials.WithMessage("Invalid two-factor code")
+	}
+
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	used, err := a.accountRepo.UseBackupCode(ctx, account.ID.String(), hashBackupCode(normalized))
+	if err != nil {
+		return utils.ErrDatabaseError.Wrap(err)
+	}
+	if !used {
+		return utils.ErrInvalidCredentials.WithMessage("Invalid two-factor code")
+	}
+	return nil
+}
+
+func checkTOTP(account *db_models.Account, code string) (int64, bool, error) {
+	secret, err := utils.DecryptString(account.TOTPSecret)
+	if err != nil {
+		return 0, false, utils.ErrInternal.Wrap(err)
+	}
+	step, ok := utils.VerifyTOTP(secret, code, time.Now(), account.TOTPLastStep)
+	return step, ok, nil
+}
+
+func hashBackupCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"vivu/internal/models/response_models"
+	"vivu/pkg/utils"
+)
+
+// Alternative times are offered on this grid, in minutes.
+const alternativeSlotStep = 15
+
+// At most this many alternative times are offered for a rejected one.
+const maxAlternativeSlots = 3
+
+// openingHoursRange finds "08:00-17:00", "8h-17h30" or "8.30 – 22" in free-text opening hours.
+var openingHoursRange = regexp.MustCompile(`(\d{1,2})(?:[:hH.](\d{2}))?[hH]?\s*[-–—]\s*(\d{1,2})(?:[:hH.](\d{2}))?`)
+
+// openingWindows reads the time ranges out of a POI's opening hours. Nil means the place is
+// open around the clock or the text could not be read; either way it does not restrict times.
+// Weekdays are ignored: a place open "Mon-Fri 8:00-17:00, Sat 9:00-12:00" counts as open 8-17.
+func openingWindows(hours string) []timeWindow {
+	lower := strings.ToLower(hours)
+	if strings.Contains(lower, "24/7") || strings.Contains(lower, "24 hours") || strings.Contains(lower, "24 giờ") {
+		return nil
+	}
+	clock := func(h, m string) (int, bool) {
+		hh, _ := strconv.Atoi(h)
+		mm := 0
+		if m != "" {
+			mm, _ = strconv.Atoi(m)
+		}
+		if hh > 24 || mm > 59 || (hh == 24 && mm > 0) {
+			return 0, false
+		}
+		return hh*60 + mm, true
+	}
+	var out []timeWindow
+	for _, m := range openingHoursRange.FindAllStringSubmatch(hours, -1) {
+		from, ok1 := clock(m[1], m[2])
+		to, ok2 := clock(m[3], m[4])
+		if !ok1 || !ok2 || from == to || from == 24*60 {
+			continue
+		}
+		out = append(out, timeWindow{From: from, To: to})
+	}
+	return out
+}
+
+// openDuring reports whether [start, end) fits inside one of the windows.
+func openDuring(windows []timeWindow, start, end int) bool {
+	for _, w := range windows {
+		if w.From < w.To && start >= w.From && end <= w.To {
+			return true
+		}
+		// Open past midnight, e.g. a night market 17:00-02:00
+		if w.From > w.To && (start >= w.From || end <= w.To) {
+			return true
+		}
+	}
+	return false
+}
+
+// activityPlacement is what an activity added to, or moved within, a day has to fit around.
+type activityPlacement struct {
+	matrix     DistanceMatrixService
+	label      string
+	point      *MatrixPoint
+	hours      string       // as the POI lists them
+	open       []timeWindow // nil when they do not restrict the time
+	others     []guardSlot  // the rest of the day, by start time
+	hotel      *MatrixPoint
+	guardrails *PlanGuardrails
+	baseline   map[string]int // rule violations the day has without the activity
+	travel     map[[2]string]int
+}
+
+func newActivityPlacement(ctx context.Context, matrix DistanceMatrixService, label string, point *MatrixPoint, hours string,
+	others []guardSlot, hotel *MatrixPoint, guardrails *PlanGuardrails) *activityPlacement {
+	sort.Slice(others, func(a, b int) bool { return others[a].Start < others[b].Start })
+	p := &activityPlacement{
+		matrix:     matrix,
+		label:      label,
+		point:      point,
+		hours:      hours,
+		open:       openingWindows(hours),
+		others:     others,
+		hotel:      hotel,
+		guardrails: guardrails,
+		baseline:   map[string]int{},
+		travel:     map[[2]string]int{},
+	}
+	if guardrails != nil {
+		for _, v := range guardrails.CheckDay(p.withReturnSlot(ctx, append([]guardSlot{}, others...))) {
+			p.baseline[v]++
+		}
+	}
+	return p
+}
+
+// travelMinutes is the drive between two stops, 0 when either has no coordinates.
+func (p *activityPlacement) travelMinutes(ctx context.Context, from, to *MatrixPoint) int {
+	if from == nil || to == nil || from.ID == to.ID {
+		return 0
+	}
+	key := [2]string{from.ID, to.ID}
+	if m, ok := p.travel[key]; ok {
+		return m
+	}
+	leg, _ := legBetween(ctx, p.matrix, *from, *to, TravelModeDriving)
+	m := (leg.DurationSeconds + 59) / 60
+	p.travel[key] = m
+	return m
+}
+
+// withReturnSlot adds the drive from the last stop back to the accommodation as a travel slot.
+func (p *activityPlacement) withReturnSlot(ctx context.Context, slots []guardSlot) []guardSlot {
+	if p.hotel == nil || len(slots) == 0 {
+		return slots
+	}
+	last := slots[len(slots)-1]
+	if last.Point == nil || last.Point.ID == p.hotel.ID {
+		return slots
+	}
+	return append(slots, guardSlot{
+		Label:  "return to accommodation",
+		Start:  last.End,
+		End:    last.End + p.travelMinutes(ctx, last.Point, p.hotel),
+		Travel: true,
+	})
+}
+
+// problems lists why the activity cannot take [start, end); ruleBroken is true when one of them
+// is a destination rule or the traveler's day window.
+func (p *activityPlacement) problems(ctx context.Context, start, end int) (out []string, ruleBroken bool) {
+	if p.open != nil && !openDuring(p.open, start, end) {
+		out = append(out, fmt.Sprintf("%s is not open from %s to %s (opening hours: %s)", p.label, formatClock(start), formatClock(end), p.hours))
+	}
+
+	// Neighbours: the activity must not overlap them and there must be time to drive between
+	var prev, next *guardSlot
+	for i := range p.others {
+		o := &p.others[i]
+		if o.Start < end && start < o.End {
+			out = append(out, fmt.Sprintf("%s overlaps %s (%s-%s)", p.label, o.Label, formatClock(o.Start), formatClock(o.End)))
+			continue
+		}
+		if o.End <= start {
+			prev = o
+		} else if next == nil {
+			next = o
+		}
+	}
+	if prev != nil {
+		if m := p.travelMinutes(ctx, prev.Point, p.point); prev.End+m > start {
+			out = append(out, fmt.Sprintf("%s is %d minutes away from %s, which ends at %s", p.label, m, prev.Label, formatClock(prev.End)))
+		}
+	}
+	if next != nil {
+		if m := p.travelMinutes(ctx, p.point, next.Point); end+m > next.Start {
+			out = append(out, fmt.Sprintf("%s is %d minutes away from %s, which starts at %s", p.label, m, next.Label, formatClock(next.Start)))
+		}
+	}
+
+	if p.guardrails != nil {
+		slot := guardSlot{Label: p.label, Start: start, End: end, Point: p.point}
+		slots := append(append([]guardSlot{}, p.others...), slot)
+		sort.Slice(slots, func(a, b int) bool { return slots[a].Start < slots[b].Start })
+		seen := map[string]int{}
+		for _, v := range p.guardrails.CheckDay(p.withReturnSlot(ctx, slots)) {
+			if seen[v]++; seen[v] > p.baseline[v] {
+				out = append(out, v)
+				ruleBroken = true
+			}
+		}
+	}
+	return out, ruleBroken
+}
+
+// alternatives are the feasible times nearest the requested start, at most one per gap between
+// the day's activities so each is a real choice, best first. day is midnight of the day (VN).
+func (p *activityPlacement) alternatives(ctx context.Context, day time.Time, requested, duration int) []response_models.ActivityTimeSlot {
+	window := DefaultWorkingWindow
+	if p.guardrails != nil && p.guardrails.Window != nil {
+		window = *p.guardrails.Window
+	}
+	type candidate struct{ start, shift, gap int }
+	best := map[int]candidate{}
+	first := (window.Start + alternativeSlotStep - 1) / alternativeSlotStep * alternativeSlotStep
+	for s := first; s+duration <= window.End; s += alternativeSlotStep {
+		if s == requested {
+			continue
+		}
+		if issues, _ := p.problems(ctx, s, s+duration); len(issues) > 0 {
+			continue
+		}
+		gap := sort.Search(len(p.others), func(i int) bool { return p.others[i].Start >= s })
+		c := candidate{start: s, shift: s - requested, gap: gap}
+		if cur, ok := best[gap]; !ok || abs(c.shift) < abs(cur.shift) {
+			best[gap] = c
+		}
+	}
+
+	ranked := make([]candidate, 0, len(best))
+	for _, c := range best {
+		ranked = append(ranked, c)
+	}
+	sort.Slice(ranked, func(a, b int) bool {
+		if abs(ranked[a].shift) != abs(ranked[b].shift) {
+			return abs(ranked[a].shift) < abs(ranked[b].shift)
+		}
+		return ranked[a].start < ranked[b].start
+	})
+	if len(ranked) > maxAlternativeSlots {
+		ranked = ranked[:maxAlternativeSlots]
+	}
+
+	out := make([]response_models.ActivityTimeSlot, 0, len(ranked))
+	for _, c := range ranked {
+		slot := response_models.ActivityTimeSlot{
+			StartTime:    utils.FormatRFC3339VN(day.Add(time.Duration(c.start) * time.Minute)),
+			EndTime:      utils.FormatRFC3339VN(day.Add(time.Duration(c.start+duration) * time.Minute)),
+			ShiftMinutes: c.shift,
+		}
+		if c.gap > 0 {
+			slot.After = p.others[c.gap-1].Label
+		}
+		out = append(out, slot)
+	}
+	return out
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+// AIProfiles are the AI clients a plan can switch its subscribers to with the ai_profile
+// entitlement, by profile name.
+type AIProfiles map[string]utils.EmbeddingClientInterface
+
+type AIClientResolverInterface interface {
+	// Resolve returns the client the account's plans are generated with and the name of its
+	// profile, "" for the default client. It never fails: anything it cannot tell falls back to
+	// the default.
+	Resolve(ctx context.Context, accountID string) (utils.EmbeddingClientInterface, string)
+}
+
+// AIClientResolver picks the AI client of a request from the entitlements of the account's
+// subscription, so beta testers can be moved to another model without touching the others.
+// Engaging the disable_ai_profiles switch sends everyone back to the default client.
+type AIClientResolver struct {
+	fallback utils.EmbeddingClientInterface
+	profiles AIProfiles
+	billing  repositories.BillingRepositoryInterface
+	switches RuntimeSwitchServiceInterface
+}
+
+func NewAIClientResolver(fallback utils.EmbeddingClientInterface, profiles AIProfiles, billing repositories.BillingRepositoryInterface, switches RuntimeSwitchServiceInterface) AIClientResolverInterface {
+	return &AIClientResolver{fallback: fallback, profiles: profiles, billing: billing, switches: switches}
+}
+
+func (r *AIClientResolver) Resolve(ctx context.Context, accountID string) (utils.EmbeddingClientInterface, string) {
+	if len(r.profiles) == 0 {
+		return r.fallback, ""
+	}
+	if on, _ := r.switches.IsEngaged(SwitchAIProfiles); on {
+		return r.fallback, ""
+	}
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return r.fallback, ""
+	}
+
+	sub, err := r.billing.CurrentSubscription(ctx, id, time.Now().Unix())
+	if err != nil {
+		log.Printf("[ai-profile] subscription of %s: %v", accountID, err)
+		return r.fallback, ""
+	}
+	// A paused subscription grants nothing until it resumes
+	if sub == nil || sub.Status == db_models.SubStatusPaused {
+		return r.fallback, ""
+	}
+	var entitlements map[string]any
+	if err := json.Unmarshal(sub.Plan.Features, &entitlements); err != nil {
+		return r.fallback, ""
+	}
+	name, _ := entitlements[db_models.EntitlementAIProfile].(string)
+	if name == "" {
+		return r.fallback, ""
+	}
+	client, ok := r.profiles[name]
+	if !ok {
+		log.Printf("[ai-profile] plan %s grants unknown profile %q, using the default", sub.Plan.Code, name)
+		return r.fallback, ""
+	}
+	return client, name
+}
+
+// aiClientKey carries the AI client chosen for a call; see WithAIClient.
+type aiClientKey struct{}
+
+// WithAIClient makes plan generation under ctx use client instead of the default one.
+func WithAIClient(ctx context.Context, client utils.EmbeddingClientInterface) context.Context {
+	if client == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, aiClientKey{}, client)
+}
+
+func aiClientFrom(ctx context.Context) utils.EmbeddingClientInterface {
+	client, _ := ctx.Value(aiClientKey{}).(utils.EmbeddingClientInterface)
+	return client
+}
@@ -0,0 +1,358 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+// announcementsTTL bounds how late a change shows up on instances other than the one that made it.
+const announcementsTTL = 30 * time.Second
+
+type AnnouncementServiceInterface interface {
+	// Active returns the announcements running now for the client: userID is empty for guests,
+	// locale and appVersion may be empty when unknown.
+	Active(ctx context.Context, userID, locale, appVersion string) ([]response_models.Announcement, error)
+
+	List(ctx context.Context, query request_models.AnnouncementListQuery) (*response_models.AdminAnnouncementPage, error)
+	Get(ctx context.Context, id string) (*response_models.AdminAnnouncement, error)
+	Create(ctx context.Context, req request_models.AnnouncementRequest, adminID string) (*response_models.AdminAnnouncement, error)
+	Update(ctx context.Context, id string, req request_models.AnnouncementRequest, adminID string) (*response_models.AdminAnnouncement, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type AnnouncementService struct {
+	repo repositories.AnnouncementRepositoryInterface
+
+	mu       sync.Mutex
+	live     []db_models.Announcement
+	loadedAt time.Time
+}
+
+func NewAnnouncementService(repo repositories.AnnouncementRepositoryInterface) AnnouncementServiceInterface {
+	return &AnnouncementService{repo: repo}
+}
+
+func (s *AnnouncementService) liveAnnouncements(ctx context.Context) ([]db_models.Announcement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.live != nil && time.Since(s.loadedAt) < announcementsTTL {
+		return s.live, nil
+	}
+	live, err := s.repo.Live(ctx, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+	if live == nil {
+		live = []db_models.Announcement{}
+	}
+	s.live, s.loadedAt = live, time.Now()
+	return live, nil
+}
+
+func (s *AnnouncementService) forgetLive() {
+	s.mu.Lock()
+	s.live = nil
+	s.mu.Unlock()
+}
+
+func (s *AnnouncementService) Active(ctx context.Context, userID, locale, appVersion string) ([]response_models.Announcement, error) {
+	now := time.Now().Unix()
+	tier := db_models.AudienceGuest
+	if userID != "" {
+		accountID, err := uuid.Parse(userID)
+		if err != nil {
+			return nil, utils.ErrInvalidInput
+		}
+		audience, err := s.repo.Audience(ctx, accountID, now)
+		if err != nil {
+			return nil, utils.ErrDatabaseError
+		}
+		if audience != nil {
+			tier = db_models.AudienceFree
+			if audience.Subscribed {
+				tier = db_models.AudiencePremium
+			}
+			if locale == "" {
+				locale = audience.Locale
+			}
+		}
+	}
+	version, versionOK := parseAppVersion(appVersion)
+
+	live, err := s.liveAnnouncements(ctx)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	out := make([]response_models.Announcement, 0, len(live))
+	for i := range live {
+		a := &live[i]
+		// the list may be a few seconds old
+		if a.StartsAt > now || (a.EndsAt != nil && *a.EndsAt <= now) {
+			continue
+		}
+		if len(a.Tiers) > 0 && !slices.Contains(a.Tiers, tier) {
+			continue
+		}
+		if len(a.Locales) > 0 && !slices.Contains(a.Locales, locale) {
+			continue
+		}
+		if a.MinAppVersion != "" || a.MaxAppVersion != "" {
+			// a client that does not say its version only gets untargeted announcements
+			if !versionOK {
+				continue
+			}
+			if lo, ok := parseAppVersion(a.MinAppVersion); ok && compareAppVersions(version, lo) < 0 {
+				continue
+			}
+			if hi, ok := parseAppVersion(a.MaxAppVersion); ok && compareAppVersions(version, hi) > 0 {
+				continue
+			}
+		}
+		out = append(out, toAnnouncementResponse(a))
+	}
+	return out, nil
+}
+
+func (s *AnnouncementService) List(ctx context.Context, query request_models.AnnouncementListQuery) (*response_models.AdminAnnouncementPage, error) {
+	rows, total, err := s.repo.List(ctx, query.Page, query.PageSize)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	now := time.Now().Unix()
+	out := &response_models.AdminAnnouncementPage{
+		Items:    make([]response_models.AdminAnnouncement, 0, len(rows)),
+		Total:    total,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	}
+	for i := range rows {
+		out.Items = append(out.Items, toAdminAnnouncementResponse(&rows[i], now))
+	}
+	return out, nil
+}
+
+func (s *AnnouncementService) Get(ctx context.Context, id string) (*response_models.AdminAnnouncement, error) {
+	a, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	out := toAdminAnnouncementResponse(a, time.Now().Unix())
+	return &out, nil
+}
+
+func (s *AnnouncementService) Create(ctx context.Context, req request_models.AnnouncementRequest, adminID string) (*response_models.AdminAnnouncement, error) {
+	a := &db_models.Announcement{CreatedBy: adminID}
+	if err := applyAnnouncementRequest(a, req, adminID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, a); err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	s.forgetLive()
+	out := toAdminAnnouncementResponse(a, time.Now().Unix())
+	return &out, nil
+}
+
+func (s *AnnouncementService) Update(ctx context.Context, id string, req request_models.AnnouncementRequest, adminID string) (*response_models.AdminAnnouncement, error) {
+	a, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyAnnouncementRequest(a, req, adminID); err != nil {
+		return nil, err
+	}
+	found, err := s.repo.Update(ctx, a)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if !found {
+		return nil, utils.RecordNotFound.WithMessage("Announcement not found")
+	}
+	s.forgetLive()
+	return s.Get(ctx, id)
+}
+
+func (s *AnnouncementService) Delete(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return utils.ErrInvalidInput.WithMessage("Invalid announcement ID")
+	}
+	found, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return utils.ErrDatabaseError
+	}
+	if !found {
+		return utils.RecordNotFound.WithMessage("Announcement not found")
+	}
+	s.forgetLive()
+	return nil
+}
+
+func (s *AnnouncementService) find(ctx context.Context, id string) (*db_models.Announcement, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, utils.ErrInvalidInput.WithMessage("Invalid announcement ID")
+	}
+	a, err := s.repo.Find(ctx, id)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if a == nil {
+		return nil, utils.RecordNotFound.WithMessage("Announcement not found")
+	}
+	return a, nil
+}
+
+// applyAnnouncementRequest validates what binding cannot and copies the request over a.
+func applyAnnouncementRequest(a *db_models.Announcement, req request_models.AnnouncementRequest, adminID string) error {
+	startsAt := time.Now()
+	if req.StartsAt != "" {
+		t, err := time.Parse(time.RFC3339, req.StartsAt)
+		if err != nil {
+			return utils.ErrInvalidInput.WithMessage("starts_at must be an RFC3339 time")
+		}
+		startsAt = t
+	}
+	var endsAt *int64
+	if req.EndsAt != "" {
+		t, err := time.Parse(time.RFC3339, req.EndsAt)
+		if err != nil {
+			return utils.ErrInvalidInput.WithMessage("ends_at must be an RFC3339 time")
+		}
+		if !t.After(startsAt) {
+			return utils.ErrInvalidInput.WithMessage("ends_at must be after starts_at")
+		}
+		at := t.Unix()
+		endsAt = &at
+	}
+
+	minV, minOK := parseAppVersion(req.MinAppVersion)
+	maxV, maxOK := parseAppVersion(req.MaxAppVersion)
+	if (req.MinAppVersion != "" && !minOK) || (req.MaxAppVersion != "" && !maxOK) {
+		return utils.ErrInvalidInput.WithMessage("App versions look like 1.4 or 1.4.2")
+	}
+	if minOK && maxOK && compareAppVersions(minV, maxV) > 0 {
+		return utils.ErrInvalidInput.WithMessage("min_app_version must not be above max_app_version")
+	}
+
+	a.Kind = req.Kind
+	a.Title = strings.TrimSpace(req.Title)
+	a.Body = strings.TrimSpace(req.Body)
+	a.CTAText = strings.TrimSpace(req.CTAText)
+	a.CTAURL = strings.TrimSpace(req.CTAURL)
+	a.Dismissible = req.Dismissible
+	a.Priority = req.Priority
+	a.Tiers = pq.StringArray(uniqueStrings(req.Tiers))
+	a.Locales = pq.StringArray(uniqueStrings(req.Locales))
+	a.MinAppVersion = strings.TrimSpace(req.MinAppVersion)
+	a.MaxAppVersion = strings.TrimSpace(req.MaxAppVersion)
+	a.Active = req.Active
+	a.StartsAt = startsAt.Unix()
+	a.EndsAt = endsAt
+	a.UpdatedBy = adminID
+	return nil
+}
+
+// parseAppVersion reads "1", "1.4" or "1.4.2", with an optional "v" prefix and ignoring any
+// "-beta"/"+build" suffix.
+func parseAppVersion(v string) ([]int, bool) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	if v == "" {
+		return nil, false
+	}
+	parts := strings.Split(v, ".")
+	if len(parts) > 4 {
+		return nil, false
+	}
+	out := make([]int, 0, len(parts))
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		out = append(out, n)
+	}
+	return out, true
+}
+
+// compareAppVersions compares part by part, missing parts counting as 0 (1.4 == 1.4.0).
+func compareAppVersions(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func toAnnouncementResponse(a *db_models.Announcement) response_models.Announcement {
+	out := response_models.Announcement{
+		ID:          a.ID.String(),
+		Kind:        a.Kind,
+		Title:       a.Title,
+		Body:        a.Body,
+		CTAText:     a.CTAText,
+		CTAURL:      a.CTAURL,
+		Dismissible: a.Dismissible,
+		Priority:    a.Priority,
+		StartsAt:    utils.FormatRFC3339VN(utils.FromUnixSecondsVN(a.StartsAt)),
+	}
+	if a.EndsAt != nil {
+		out.EndsAt = utils.FormatRFC3339VN(utils.FromUnixSecondsVN(*a.EndsAt))
+	}
+	return out
+}
+
+func toAdminAnnouncementResponse(a *db_models.Announcement, now int64) response_models.AdminAnnouncement {
+	out := response_models.AdminAnnouncement{
+		Announcement:  toAnnouncementResponse(a),
+		Tiers:         []string(a.Tiers),
+		Locales:       []string(a.Locales),
+		MinAppVersion: a.MinAppVersion,
+		MaxAppVersion: a.MaxAppVersion,
+		Active:        a.Active,
+		CreatedBy:     a.CreatedBy,
+		UpdatedBy:     a.UpdatedBy,
+		UpdatedAt:     utils.FormatRFC3339VN(utils.FromUnixSecondsVN(a.UpdatedAt)),
+	}
+	if out.Tiers == nil {
+		out.Tiers = []string{}
+	}
+	if out.Locales == nil {
+		out.Locales = []string{}
+	}
+	switch {
+	case a.EndsAt != nil && *a.EndsAt <= now:
+		out.Status = "ended"
+	case !a.Active:
+		out.Status = "draft"
+	case a.StartsAt > now:
+		out.Status = "scheduled"
+	default:
+		out.Status = "live"
+	}
+	return out
+}
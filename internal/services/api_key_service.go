@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+const (
+	apiKeyPrefix    = "vivu_"
+	apiKeyShownLen  = 12 // characters kept as APIKey.Prefix
+	apiKeyCacheTTL  = 30 * time.Second
+	apiKeyTouchStep = int64(5 * 60) // last_used_at is written at most this often, in seconds
+)
+
+type APIKeyServiceInterface interface {
+	ListKeys(ctx context.Context, query request_models.APIKeyQuery) (*response_models.APIKeyPage, error)
+	// IssueKey creates a key for an account; the secret is only returned here.
+	IssueKey(ctx context.Context, req request_models.CreateAPIKeyRequest, issuedBy string) (*response_models.IssuedAPIKey, error)
+	// RotateKey replaces the secret of a key, keeping its scopes; the old secret stops working.
+	RotateKey(ctx context.Context, keyID string) (*response_models.IssuedAPIKey, error)
+	RevokeKey(ctx context.Context, keyID string) error
+
+	// Verify resolves a raw key that must hold scope; "" accepts any valid key. Keys are cached
+	// briefly, so another instance sees a revocation within the TTL.
+	Verify(ctx context.Context, rawKey, scope string) (*db_models.APIKey, error)
+}
+
+type APIKeyService struct {
+	repo        repositories.APIKeyRepositoryInterface
+	accountRepo repositories.AccountRepository
+
+	mu   sync.Mutex
+	keys map[string]cachedAPIKey // by key hash
+}
+
+type cachedAPIKey struct {
+	key       *db_models.APIKey // nil: unknown key
+	expiresAt time.Time
+}
+
+func NewAPIKeyService(repo repositories.APIKeyRepositoryInterface, accountRepo repositories.AccountRepository) APIKeyServiceInterface {
+	return &APIKeyService{repo: repo, accountRepo: accountRepo, keys: make(map[string]cachedAPIKey)}
+}
+
+func (s *APIKeyService) ListKeys(ctx context.Context, query request_models.APIKeyQuery) (*response_models.APIKeyPage, error) {
+	keys, total, err := s.repo.ListKeys(ctx, query.AccountID, query.Page, query.PageSize)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	out := &response_models.APIKeyPage{
+		Items:    make([]response_models.APIKeyResponse, 0, len(keys)),
+		Total:    total,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	}
+	for i := range keys {
+		out.Items = append(out.Items, toAPIKeyResponse(&keys[i]))
+	}
+	return out, nil
+}
+
+func (s *APIKeyService) IssueKey(ctx context.Context, req request_models.CreateAPIKeyRequest, issuedBy string) (*response_models.IssuedAPIKey, error) {
+	account, err := s.accountRepo.FindSessionState(ctx, req.AccountID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if account == nil {
+		return nil, utils.ErrAccountNotFound
+	}
+	if account.Status == db_models.AccountStatusBanned {
+		return nil, utils.ErrInvalidInput.WithMessage("Cannot issue a key for a banned account")
+	}
+
+	raw, err := newAPIKeySecret()
+	if err != nil {
+		return nil, utils.ErrInternal.Wrap(err)
+	}
+	scopes := slices.Clone(req.Scopes)
+	slices.Sort(scopes)
+	key := &db_models.APIKey{
+		AccountID:  account.ID,
+		Name:       strings.TrimSpace(req.Name),
+		Prefix:     raw[:apiKeyShownLen],
+		KeyHash:    hashAPIKey(raw),
+		Scopes:     pq.StringArray(slices.Compact(scopes)),
+		DailyQuota: req.DailyQuota,
+		CreatedBy:  issuedBy,
+	}
+	if req.ExpiresInDays > 0 {
+		at := time.Now().AddDate(0, 0, req.ExpiresInDays).Unix()
+		key.ExpiresAt = &at
+	}
+	if err := s.repo.CreateKey(ctx, key); err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+
+	return &response_models.IssuedAPIKey{APIKeyResponse: toAPIKeyResponse(key), Key: raw}, nil
+}
+
+func (s *APIKeyService) RotateKey(ctx context.Context, keyID string) (*response_models.IssuedAPIKey, error) {
+	if _, err := uuid.Parse(keyID); err != nil {
+		return nil, utils.ErrInvalidInput.WithMessage("Invalid API key ID")
+	}
+	raw, err := newAPIKeySecret()
+	if err != nil {
+		return nil, utils.ErrInternal.Wrap(err)
+	}
+	found, err := s.repo.RotateKey(ctx, keyID, raw[:apiKeyShownLen], hashAPIKey(raw))
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if !found {
+		return nil, utils.RecordNotFound.WithMessage("API key not found or revoked")
+	}
+	s.forget(keyID)
+
+	key, err := s.repo.FindByID(ctx, keyID)
+	if err != nil || key == nil {
+		return nil, utils.ErrDatabaseError
+	}
+	return &response_models.IssuedAPIKey{APIKeyResponse: toAPIKeyResponse(key), Key: raw}, nil
+}
+
+func (s *APIKeyService) RevokeKey(ctx context.Context, keyID string) error {
+	if _, err := uuid.Parse(keyID); err != nil {
+		return utils.ErrInvalidInput.WithMessage("Invalid API key ID")
+	}
+	found, err := s.repo.RevokeKey(ctx, keyID, time.Now().Unix())
+	if err != nil {
+		return utils.ErrDatabaseError
+	}
+	if !found {
+		return utils.RecordNotFound.WithMessage("API key not found or already revoked")
+	}
+	s.forget(keyID)
+	return nil
+}
+
+func (s *APIKeyService) Verify(ctx context.Context, rawKey, scope string) (*db_models.APIKey, error) {
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return nil, utils.ErrInvalidAPIKey
+	}
+	hash := hashAPIKey(rawKey)
+	now := time.Now()
+
+	s.mu.Lock()
+	cached, ok := s.keys[hash]
+	s.mu.Unlock()
+	if !ok || now.After(cached.expiresAt) {
+		key, err := s.repo.FindByHash(ctx, hash)
+		if err != nil {
+			return nil, utils.ErrDatabaseError.Wrap(err)
+		}
+		if key != nil && (key.LastUsedAt == nil || now.Unix()-*key.LastUsedAt >= apiKeyTouchStep) {
+			if err := s.repo.TouchLastUsed(ctx, key.ID.String(), now.Unix()); err == nil {
+				at := now.Unix()
+				key.LastUsedAt = &at
+			}
+		}
+		cached = cachedAPIKey{key: key, expiresAt: now.Add(apiKeyCacheTTL)}
+		s.mu.Lock()
+		if len(s.keys) > 10000 { // guessed keys should not grow the cache forever
+			s.keys = make(map[string]cachedAPIKey)
+		}
+		s.keys[hash] = cached
+		s.mu.Unlock()
+	}
+
+	key := cached.key
+	switch {
+	case key == nil, key.RevokedAt != nil, key.ExpiresAt != nil && *key.ExpiresAt <= now.Unix():
+		return nil, utils.ErrInvalidAPIKey
+	case key.Account.ID == uuid.Nil: // owner merged away or erased, the preload skips deleted rows
+		return nil, utils.ErrInvalidAPIKey
+	case key.Account.Status == db_models.AccountStatusBanned:
+		return nil, utils.ErrAccountBanned
+	case scope != "" && !slices.Contains(key.Scopes, scope):
+		return nil, utils.ErrAPIKeyScope
+	}
+	return key, nil
+}
+
+// forget drops the cached entries of a key after its secret changed or it was revoked.
+func (s *APIKeyService) forget(keyID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for hash, c := range s.keys {
+		if c.key != nil && c.key.ID.String() == keyID {
+			delete(s.keys, hash)
+		}
+	}
+}
+
+func newAPIKeySecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + hex.EncodeToString(b), nil
+}
+
+func hashAPIKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func toAPIKeyResponse(k *db_models.APIKey) response_models.APIKeyResponse {
+	out := response_models.APIKeyResponse{
+		ID:           k.ID.String(),
+		AccountID:    k.AccountID.String(),
+		AccountEmail: k.Account.Email,
+		Name:         k.Name,
+		Prefix:       k.Prefix,
+		Scopes:       []string(k.Scopes),
+		DailyQuota:   k.DailyQuota,
+		Status:       "active",
+		CreatedAt:    utils.FormatRFC3339VN(utils.FromUnixSecondsVN(k.CreatedAt)),
+	}
+	if k.ExpiresAt != nil {
+		out.ExpiresAt = utils.FormatRFC3339VN(utils.FromUnixSecondsVN(*k.ExpiresAt))
+		if *k.ExpiresAt <= time.Now().Unix() {
+			out.Status = "expired"
+		}
+	}
+	if k.RevokedAt != nil {
+		out.RevokedAt = utils.FormatRFC3339VN(utils.FromUnixSecondsVN(*k.RevokedAt))
+		out.Status = "revoked"
+	}
+	if k.LastUsedAt != nil {
+		out.LastUsedAt = utils.FormatRFC3339VN(utils.FromUnixSecondsVN(*k.LastUsedAt))
+	}
+	return out
+}
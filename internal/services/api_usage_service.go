@@ -0,0 +1,339 @@
+package services
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+const defaultAPIKeyDailyQuota = 10000
+
+type APIUsageServiceInterface interface {
+	// Allow counts one call of a key against its daily quota (quota 0: the default), or
+	// counts it as throttled once the quota is used up. Satisfies middleware.APIKeyMeter.
+	Allow(ctx context.Context, keyID string, quota int) (limit, remaining int, resetIn time.Duration, ok bool)
+	// Record counts an allowed call answered with a 4xx or 5xx as an error.
+	Record(keyID string, status int)
+
+	// GetUsage returns the daily counts of every key of the account over the last query.Days days.
+	GetUsage(ctx context.Context, accountID string, query request_models.PartnerUsageQuery) (*response_models.PartnerUsage, error)
+
+	// Flush writes the counts gathered since the last flush.
+	Flush(ctx context.Context) error
+	Start()
+	Stop()
+}
+
+// APIUsageService counts in memory and adds the counts to api_key_usages every interval.
+// The quota check reads the stored total of the day once and adds what this instance counted
+// since, so with several instances a key can overshoot by what the others counted since the
+// last flush.
+type APIUsageService struct {
+	repo     repositories.APIKeyUsageRepositoryInterface
+	keyRepo  repositories.APIKeyRepositoryInterface
+	quota    int
+	interval time.Duration
+
+	mu     sync.Mutex
+	counts map[apiUsageKey]*apiUsageCount
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+type apiUsageKey struct {
+	keyID uuid.UUID
+	day   string
+}
+
+type apiUsageCount struct {
+	loaded bool
+	stored int64 // requests of the day in the table, as of the last read or flush
+
+	// not flushed yet
+	requests, errors, throttled int64
+}
+
+// NewAPIUsageService reads API_KEY_DAILY_QUOTA (default 10000; 0 leaves keys without their own
+// quota unlimited) and API_USAGE_FLUSH_INTERVAL (default 30s).
+func NewAPIUsageService(repo repositories.APIKeyUsageRepositoryInterface, keyRepo repositories.APIKeyRepositoryInterface) APIUsageServiceInterface {
+	s := &APIUsageService{
+		repo:     repo,
+		keyRepo:  keyRepo,
+		quota:    defaultAPIKeyDailyQuota,
+		interval: 30 * time.Second,
+		counts:   make(map[apiUsageKey]*apiUsageCount),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if v, err := strconv.Atoi(os.Getenv("API_KEY_DAILY_QUOTA")); err == nil && v >= 0 {
+		s.quota = v
+	}
+	if d, err := time.ParseDuration(os.Getenv("API_USAGE_FLUSH_INTERVAL")); err == nil && d > 0 {
+		s.interval = d
+	}
+	return s
+}
+
+// usageDay returns the current VN day and how long until the next one starts.
+func usageDay() (string, time.Duration) {
+	now := time.Now().In(vnLoc)
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, vnLoc)
+	return start.Format("2006-01-02"), start.AddDate(0, 0, 1).Sub(now)
+}
+
+func (s *APIUsageService) limitFor(quota int) int {
+	if quota > 0 {
+		return quota
+	}
+	return s.quota
+}
+
+func (s *APIUsageService) Allow(ctx context.Context, keyID string, quota int) (int, int, time.Duration, bool) {
+	id, err := uuid.Parse(keyID)
+	if err != nil {
+		return 0, 0, 0, true
+	}
+	day, resetIn := usageDay()
+	key := apiUsageKey{keyID: id, day: day}
+	limit := s.limitFor(quota)
+
+	s.mu.Lock()
+	c := s.entry(key)
+	loaded := c.loaded
+	s.mu.Unlock()
+	if !loaded && limit > 0 {
+		// read outside the lock; a parallel first call may read it too
+		stored, err := s.repo.DayUsage(ctx, id, day)
+		if err != nil {
+			log.Printf("[api-usage] load usage of %s: %v", keyID, err)
+		}
+		s.mu.Lock()
+		if !c.loaded {
+			c.loaded = true
+			if stored != nil {
+				c.stored = stored.Requests
+			}
+		}
+		s.mu.Unlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if limit <= 0 {
+		c.requests++
+		return 0, 0, 0, true
+	}
+	used := c.stored + c.requests
+	if used >= int64(limit) {
+		c.throttled++
+		return limit, 0, resetIn, false
+	}
+	c.requests++
+	return limit, limit - int(used) - 1, resetIn, true
+}
+
+func (s *APIUsageService) Record(keyID string, status int) {
+	if status < http.StatusBadRequest {
+		return
+	}
+	id, err := uuid.Parse(keyID)
+	if err != nil {
+		return
+	}
+	day, _ := usageDay()
+
+	s.mu.Lock()
+	s.entry(apiUsageKey{keyID: id, day: day}).errors++
+	s.mu.Unlock()
+}
+
+// entry returns the count of key, creating it; s.mu must be held.
+func (s *APIUsageService) entry(key apiUsageKey) *apiUsageCount {
+	c, ok := s.counts[key]
+	if !ok {
+		c = &apiUsageCount{}
+		s.counts[key] = c
+	}
+	return c
+}
+
+func (s *APIUsageService) Flush(ctx context.Context) error {
+	today, _ := usageDay()
+
+	s.mu.Lock()
+	keys := make([]apiUsageKey, 0, len(s.counts))
+	rows := make([]db_models.APIKeyUsage, 0, len(s.counts))
+	for key, c := range s.counts {
+		if c.requests == 0 && c.errors == 0 && c.throttled == 0 {
+			if key.day != today {
+				delete(s.counts, key)
+			}
+			continue
+		}
+		keys = append(keys, key)
+		rows = append(rows, db_models.APIKeyUsage{
+			APIKeyID: key.keyID, Day: key.day,
+			Requests: c.requests, Errors: c.errors, Throttled: c.throttled,
+		})
+		c.requests, c.errors, c.throttled = 0, 0, 0
+	}
+	s.mu.Unlock()
+	if len(rows) == 0 {
+		return nil
+	}
+
+	err := s.repo.AddUsage(ctx, rows)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, key := range keys {
+		c := s.entry(key)
+		if err != nil {
+			// counted again on the next flush
+			c.requests += rows[i].Requests
+			c.errors += rows[i].Errors
+			c.throttled += rows[i].Throttled
+			continue
+		}
+		// the returned total includes what other instances flushed
+		c.loaded, c.stored = true, rows[i].Requests
+	}
+	return err
+}
+
+func (s *APIUsageService) GetUsage(ctx context.Context, accountID string, query request_models.PartnerUsageQuery) (*response_models.PartnerUsage, error) {
+	if _, err := uuid.Parse(accountID); err != nil {
+		return nil, utils.ErrInvalidInput
+	}
+	keys, err := s.keyRepo.ListAccountKeys(ctx, accountID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+
+	today, _ := usageDay()
+	end, _ := time.ParseInLocation("2006-01-02", today, vnLoc)
+	from := end.AddDate(0, 0, -(query.Days - 1)).Format("2006-01-02")
+	ids := make([]uuid.UUID, 0, len(keys))
+	for i := range keys {
+		ids = append(ids, keys[i].ID)
+	}
+	rows, err := s.repo.ListUsage(ctx, ids, from, today)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+
+	// stored counts plus what this instance has not flushed yet
+	counts := make(map[apiUsageKey]*response_models.APIKeyUsageDay, len(rows))
+	add := func(key apiUsageKey, requests, errors, throttled int64) {
+		d, ok := counts[key]
+		if !ok {
+			d = &response_models.APIKeyUsageDay{Date: key.day}
+			counts[key] = d
+		}
+		d.Requests += requests
+		d.Errors += errors
+		d.Throttled += throttled
+	}
+	for _, r := range rows {
+		add(apiUsageKey{keyID: r.APIKeyID, day: r.Day}, r.Requests, r.Errors, r.Throttled)
+	}
+	s.mu.Lock()
+	for key, c := range s.counts {
+		if key.day >= from {
+			add(key, c.requests, c.errors, c.throttled)
+		}
+	}
+	s.mu.Unlock()
+
+	out := &response_models.PartnerUsage{
+		From: from,
+		To:   today,
+		Keys: make([]response_models.APIKeyUsage, 0, len(keys)),
+	}
+	resetsAt := utils.FormatRFC3339VN(end.AddDate(0, 0, 1))
+	for i := range keys {
+		k := &keys[i]
+		usage := response_models.APIKeyUsage{
+			KeyID:      k.ID.String(),
+			Name:       k.Name,
+			Prefix:     k.Prefix,
+			Status:     toAPIKeyResponse(k).Status,
+			DailyQuota: s.limitFor(k.DailyQuota),
+			ResetsAt:   resetsAt,
+			Days:       make([]response_models.APIKeyUsageDay, 0, query.Days),
+		}
+		for day := end.AddDate(0, 0, -(query.Days - 1)); !day.After(end); day = day.AddDate(0, 0, 1) {
+			d := response_models.APIKeyUsageDay{Date: day.Format("2006-01-02")}
+			if c, ok := counts[apiUsageKey{keyID: k.ID, day: d.Date}]; ok {
+				d = *c
+			}
+			d.ErrorRate = usageErrorRate(d.Errors, d.Requests)
+			usage.Requests += d.Requests
+			usage.Errors += d.Errors
+			usage.Throttled += d.Throttled
+			usage.Days = append(usage.Days, d)
+		}
+		usage.UsedToday = usage.Days[len(usage.Days)-1].Requests
+		usage.ErrorRate = usageErrorRate(usage.Errors, usage.Requests)
+		if usage.DailyQuota > 0 {
+			remaining := max(int64(usage.DailyQuota)-usage.UsedToday, 0)
+			usage.RemainingToday = &remaining
+		}
+		out.Keys = append(out.Keys, usage)
+	}
+	return out, nil
+}
+
+func usageErrorRate(errors, requests int64) float64 {
+	if requests == 0 {
+		return 0
+	}
+	return math.Round(float64(errors)/float64(requests)*10000) / 10000
+}
+
+func (s *APIUsageService) Start() {
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				if err := s.Flush(ctx); err != nil {
+					log.Printf("[api-usage] flush failed: %v", err)
+				}
+				cancel()
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the worker and writes what is left.
+func (s *APIUsageService) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := s.Flush(ctx); err != nil {
+			log.Printf("[api-usage] final flush failed: %v", err)
+		}
+	})
+}
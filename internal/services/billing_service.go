@@ -0,0 +1,495 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+// billingRecentPayments is how many payments the overview shows.
+const billingRecentPayments = 5
+
+// invoicedStatuses are the payments that have an invoice.
+var invoicedStatuses = []db_models.TransactionStatus{db_models.TxnStatusPaid, db_models.TxnStatusRefunded}
+
+// BillingServiceInterface backs the billing screen of the app: one overview plus the controls
+// it links to. Pausing and resuming stay on PaymentService.
+type BillingServiceInterface interface {
+	GetOverview(ctx context.Context, accountID string) (*response_models.BillingOverview, error)
+	ListPayments(ctx context.Context, accountID string, query request_models.BillingPageQuery) (*response_models.BillingPaymentPage, error)
+	// ListTransactions is ListPayments narrowed by status and a date range.
+	ListTransactions(ctx context.Context, accountID string, query request_models.TransactionHistoryQuery) (*response_models.BillingPaymentPage, error)
+	ListInvoices(ctx context.Context, accountID string, query request_models.BillingPageQuery) (*response_models.InvoicePage, error)
+	GetInvoice(ctx context.Context, accountID, transactionID string) (*response_models.Invoice, error)
+	// GetReceipt renders the invoice of a paid payment as a PDF and returns it with its file name.
+	GetReceipt(ctx context.Context, accountID, transactionID string) ([]byte, string, error)
+	// SetAutoRenew turns renewal of the running subscription on or off. Turning it off cancels
+	// the subscription at the end of the period; it keeps running until then.
+	SetAutoRenew(ctx context.Context, accountID string, enabled bool) (*response_models.BillingOverview, error)
+	// ChangePlan starts a checkout for another plan. With renewal on, the new plan takes over
+	// when the running subscription ends.
+	ChangePlan(ctx context.Context, accountID string, req request_models.ChangePlanRequest) (*response_models.CreateCheckoutResponse, error)
+}
+
+type BillingService struct {
+	repo        repositories.BillingRepositoryInterface
+	accountRepo repositories.AccountRepository
+	payments    PaymentService // nil when payOS is not configured
+	invoice     InvoiceConfig
+}
+
+// NewBillingService reads the seller and VAT details of invoices from the environment, see
+// InvoiceConfigFromEnv.
+func NewBillingService(repo repositories.BillingRepositoryInterface, accountRepo repositories.AccountRepository, payments PaymentService) BillingServiceInterface {
+	return &BillingService{repo: repo, accountRepo: accountRepo, payments: payments, invoice: InvoiceConfigFromEnv()}
+}
+
+func (s *BillingService) GetOverview(ctx context.Context, accountID string) (*response_models.BillingOverview, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, utils.ErrUnauthenticated
+	}
+	now := time.Now().Unix()
+
+	current, err := s.repo.CurrentSubscription(ctx, id, now)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	queued, err := s.repo.QueuedSubscription(ctx, id, now)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	payments, _, err := s.repo.ListTransactions(ctx, id, nil, 0, billingRecentPayments)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	recent, err := s.toPayments(ctx, payments)
+	if err != nil {
+		return nil, err
+	}
+	plans, err := s.repo.ActivePlans(ctx)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+
+	out := &response_models.BillingOverview{
+		RecentPayments: recent,
+		PlanOptions:    billingPlanOptions(plans, current, queued, now),
+		Actions: response_models.BillingActions{
+			CanChangePlan: s.payments != nil && len(plans) > 0,
+		},
+	}
+	if current == nil {
+		return out, nil
+	}
+
+	out.Subscription = toBillingSubscription(current)
+	out.Actions.CanCancel = current.AutoRenew
+	out.Actions.CanResumeAutoRenew = !current.AutoRenew
+	out.Actions.CanPause = s.payments != nil && current.Status == db_models.SubStatusActive && current.PausedAt == nil && queued == nil
+	out.Actions.CanResume = s.payments != nil && current.Status == db_models.SubStatusPaused
+
+	switch {
+	case queued != nil:
+		out.UpcomingRenewal = &response_models.BillingRenewal{
+			Date:        formatBillingTime(queued.StartsAt),
+			PlanCode:    queued.Plan.Code,
+			PlanName:    queued.Plan.Name,
+			AmountMinor: queued.Plan.PriceMinor,
+			Currency:    queued.Plan.Currency,
+			Prepaid:     true,
+		}
+	case current.AutoRenew && current.Status != db_models.SubStatusPaused:
+		out.UpcomingRenewal = &response_models.BillingRenewal{
+			Date:        formatBillingTime(current.EndsAt),
+			PlanCode:    current.Plan.Code,
+			PlanName:    current.Plan.Name,
+			AmountMinor: current.Plan.PriceMinor,
+			Currency:    current.Plan.Currency,
+		}
+	}
+	return out, nil
+}
+
+func (s *BillingService) ListPayments(ctx context.Context, accountID string, query request_models.BillingPageQuery) (*response_models.BillingPaymentPage, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, utils.ErrUnauthenticated
+	}
+	rows, total, err := s.repo.ListTransactions(ctx, id, nil, (query.Page-1)*query.PageSize, query.PageSize)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	items, err := s.toPayments(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+	return &response_models.BillingPaymentPage{Items: items, Total: total, Page: query.Page, PageSize: query.PageSize}, nil
+}
+
+func (s *BillingService) ListTransactions(ctx context.Context, accountID string, query request_models.TransactionHistoryQuery) (*response_models.BillingPaymentPage, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, utils.ErrUnauthenticated
+	}
+
+	var filter repositories.TransactionFilter
+	for _, st := range query.Status {
+		filter.Statuses = append(filter.Statuses, db_models.TransactionStatus(st))
+	}
+	// Binding checked the format; a date is the whole day in Vietnam
+	if query.From != "" {
+		from, _ := time.ParseInLocation("2006-01-02", query.From, vnLoc)
+		filter.From = from.Unix()
+	}
+	if query.To != "" {
+		to, _ := time.ParseInLocation("2006-01-02", query.To, vnLoc)
+		filter.To = to.AddDate(0, 0, 1).Unix()
+	}
+	if filter.From > 0 && filter.To > 0 && filter.From >= filter.To {
+		return nil, utils.ErrInvalidInput.WithMessage("from must not be after to")
+	}
+
+	rows, total, err := s.repo.FilterTransactions(ctx, id, filter, (query.Page-1)*query.PageSize, query.PageSize)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	items, err := s.toPayments(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+	return &response_models.BillingPaymentPage{Items: items, Total: total, Page: query.Page, PageSize: query.PageSize}, nil
+}
+
+func (s *BillingService) ListInvoices(ctx context.Context, accountID string, query request_models.BillingPageQuery) (*response_models.InvoicePage, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, utils.ErrUnauthenticated
+	}
+	account, err := s.accountRepo.FindById(ctx, accountID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if account == nil {
+		return nil, utils.ErrAccountNotFound
+	}
+	rows, total, err := s.repo.ListTransactions(ctx, id, invoicedStatuses, (query.Page-1)*query.PageSize, query.PageSize)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	items, err := s.toInvoices(ctx, account, rows)
+	if err != nil {
+		return nil, err
+	}
+	return &response_models.InvoicePage{Items: items, Total: total, Page: query.Page, PageSize: query.PageSize}, nil
+}
+
+func (s *BillingService) GetInvoice(ctx context.Context, accountID, transactionID string) (*response_models.Invoice, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, utils.ErrUnauthenticated
+	}
+	if _, err := uuid.Parse(transactionID); err != nil {
+		return nil, utils.ErrInvalidInput.WithMessage("Invalid payment ID")
+	}
+	txn, err := s.repo.FindTransaction(ctx, id, transactionID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if txn == nil || (txn.Status != db_models.TxnStatusPaid && txn.Status != db_models.TxnStatusRefunded) {
+		return nil, utils.RecordNotFound.WithMessage("Invoice not found")
+	}
+	account, err := s.accountRepo.FindById(ctx, accountID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if account == nil {
+		return nil, utils.ErrAccountNotFound
+	}
+	invoices, err := s.toInvoices(ctx, account, []db_models.Transaction{*txn})
+	if err != nil {
+		return nil, err
+	}
+	return &invoices[0], nil
+}
+
+func (s *BillingService) GetReceipt(ctx context.Context, accountID, transactionID string) ([]byte, string, error) {
+	inv, err := s.GetInvoice(ctx, accountID, transactionID)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := renderReceiptPDF(inv)
+	if err != nil {
+		return nil, "", utils.ErrInternal.Wrap(err)
+	}
+	return data, receiptFileName(inv), nil
+}
+
+func (s *BillingService) SetAutoRenew(ctx context.Context, accountID string, enabled bool) (*response_models.BillingOverview, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, utils.ErrUnauthenticated
+	}
+	current, err := s.repo.CurrentSubscription(ctx, id, time.Now().Unix())
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if current == nil {
+		return nil, utils.ErrNoActiveSubscription
+	}
+
+	if current.AutoRenew != enabled {
+		var canceledAt *int64
+		if !enabled {
+			now := time.Now().Unix()
+			canceledAt = &now
+		}
+		found, err := s.repo.SetAutoRenew(ctx, id, current.ID, enabled, canceledAt)
+		if err != nil {
+			return nil, utils.ErrDatabaseError
+		}
+		if !found {
+			return nil, utils.ErrNoActiveSubscription
+		}
+	}
+	return s.GetOverview(ctx, accountID)
+}
+
+func (s *BillingService) ChangePlan(ctx context.Context, accountID string, req request_models.ChangePlanRequest) (*response_models.CreateCheckoutResponse, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, utils.ErrUnauthenticated
+	}
+	if s.payments == nil {
+		return nil, utils.ErrPaymentsUnavailable
+	}
+
+	plans, err := s.repo.ActivePlans(ctx)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	var target *db_models.Plan
+	for i := range plans {
+		if plans[i].Code == req.PlanCode {
+			target = &plans[i]
+		}
+	}
+	if target == nil {
+		return nil, utils.RecordNotFound.WithMessage("This plan is not available")
+	}
+	current, err := s.repo.CurrentSubscription(ctx, id, time.Now().Unix())
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if current != nil && current.PlanID == target.ID {
+		return nil, utils.ErrInvalidInput.WithMessage("You are already on this plan")
+	}
+
+	checkout, err := s.payments.CreateCheckoutForPlan(ctx, id, target.Code, "", "")
+	if err != nil {
+		return nil, utils.ErrThirdService.Wrap(err)
+	}
+	return checkout, nil
+}
+
+func (s *BillingService) toPayments(ctx context.Context, rows []db_models.Transaction) ([]response_models.BillingPayment, error) {
+	plans, err := s.repo.PlansByID(ctx, transactionPlanIDs(rows))
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	out := make([]response_models.BillingPayment, 0, len(rows))
+	for i := range rows {
+		t := &rows[i]
+		p := response_models.BillingPayment{
+			ID:          t.ID.String(),
+			Status:      string(t.Status),
+			AmountMinor: t.AmountMinor,
+			Currency:    t.Currency,
+			CreatedAt:   formatBillingTime(t.CreatedAt),
+		}
+		if plan, ok := plans[transactionPlanID(t)]; ok {
+			p.PlanCode, p.PlanName = plan.Code, plan.Name
+		}
+		if t.PaidAt != nil {
+			p.PaidAt = formatBillingTime(*t.PaidAt)
+		}
+		if t.Status == db_models.TxnStatusPaid || t.Status == db_models.TxnStatusRefunded {
+			p.InvoiceNumber = invoiceNumber(t)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+func (s *BillingService) toInvoices(ctx context.Context, account *db_models.Account, rows []db_models.Transaction) ([]response_models.Invoice, error) {
+	return buildInvoices(ctx, s.repo, s.invoice, account, rows)
+}
+
+// buildInvoices makes the invoices of paid or refunded payments of one account.
+func buildInvoices(ctx context.Context, repo repositories.BillingRepositoryInterface, cfg InvoiceConfig, account *db_models.Account, rows []db_models.Transaction) ([]response_models.Invoice, error) {
+	plans, err := repo.PlansByID(ctx, transactionPlanIDs(rows))
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, t := range rows {
+		ids = append(ids, t.ID)
+	}
+	subs, err := repo.SubscriptionsByTransaction(ctx, ids)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+
+	out := make([]response_models.Invoice, 0, len(rows))
+	for i := range rows {
+		t := &rows[i]
+		issued := t.CreatedAt
+		if t.PaidAt != nil {
+			issued = *t.PaidAt
+		}
+		line := response_models.InvoiceLine{Description: "Vivu subscription", AmountMinor: t.AmountMinor}
+		if plan, ok := plans[transactionPlanID(t)]; ok {
+			line.Description = fmt.Sprintf("%s (%s)", plan.Name, billingPeriodLabel(plan.Period))
+		}
+		if sub, ok := subs[t.ID]; ok {
+			line.PeriodStart = formatBillingTime(sub.StartsAt)
+			line.PeriodEnd = formatBillingTime(sub.EndsAt)
+		}
+
+		inv := response_models.Invoice{
+			Number:        invoiceNumber(t),
+			TransactionID: t.ID.String(),
+			Status:        string(t.Status),
+			IssuedAt:      formatBillingTime(issued),
+			Seller:        cfg.seller(),
+			BilledTo:      response_models.InvoiceParty{Name: account.Name, Email: account.Email},
+			Lines:         []response_models.InvoiceLine{line},
+			VATRate:       cfg.VATRate,
+			VATMinor:      cfg.vatIncluded(t.AmountMinor),
+			TotalMinor:    t.AmountMinor,
+			Currency:      t.Currency,
+			Provider:      t.Provider,
+		}
+		inv.SubtotalMinor = inv.TotalMinor - inv.VATMinor
+		if t.RefundedAt != nil {
+			inv.RefundedAt = formatBillingTime(*t.RefundedAt)
+		}
+		out = append(out, inv)
+	}
+	return out, nil
+}
+
+// transactionPlanID reads the plan a checkout was made for from the transaction metadata.
+func transactionPlanID(t *db_models.Transaction) uuid.UUID {
+	var meta struct {
+		PlanID uuid.UUID `json:"plan_id"`
+	}
+	_ = json.Unmarshal(t.Metadata, &meta)
+	return meta.PlanID
+}
+
+func transactionPlanIDs(rows []db_models.Transaction) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool)
+	out := make([]uuid.UUID, 0, len(rows))
+	for i := range rows {
+		if id := transactionPlanID(&rows[i]); id != uuid.Nil && !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// invoiceNumber is stable for a payment: month it was paid in and the start of its ID.
+func invoiceNumber(t *db_models.Transaction) string {
+	at := t.CreatedAt
+	if t.PaidAt != nil {
+		at = *t.PaidAt
+	}
+	return fmt.Sprintf("VIVU-%s-%s", time.Unix(at, 0).In(vnLoc).Format("200601"), strings.ToUpper(t.ID.String()[:8]))
+}
+
+func billingPeriodLabel(period db_models.BillingPeriod) string {
+	if period == db_models.PeriodYear {
+		return "1 year"
+	}
+	return "1 month"
+}
+
+// billingPlanOptions ranks the catalog against the running plan by price per month. A plan
+// bought while renewal is on starts when the paid time runs out.
+func billingPlanOptions(plans []db_models.Plan, current, queued *db_models.Subscription, now int64) []response_models.BillingPlanOption {
+	effective := now
+	if current != nil && current.AutoRenew && current.Status == db_models.SubStatusActive {
+		effective = current.EndsAt
+		if queued != nil && queued.EndsAt > effective {
+			effective = queued.EndsAt
+		}
+	}
+
+	out := make([]response_models.BillingPlanOption, 0, len(plans))
+	for _, p := range plans {
+		option := response_models.BillingPlanOption{
+			Code:        p.Code,
+			Name:        p.Name,
+			Period:      string(p.Period),
+			PriceMinor:  p.PriceMinor,
+			Currency:    p.Currency,
+			Change:      "upgrade",
+			EffectiveAt: formatBillingTime(effective),
+		}
+		switch {
+		case current == nil:
+			option.Change = "subscribe"
+		case p.ID == current.PlanID:
+			option.Change = "current"
+		case !strings.EqualFold(p.Currency, current.Plan.Currency):
+			option.Change = "switch"
+		case monthlyPrice(p) < monthlyPrice(current.Plan):
+			option.Change = "downgrade"
+		}
+		out = append(out, option)
+	}
+	return out
+}
+
+func monthlyPrice(p db_models.Plan) int64 {
+	if p.Period == db_models.PeriodYear {
+		return p.PriceMinor / 12
+	}
+	return p.PriceMinor
+}
+
+func toBillingSubscription(sub *db_models.Subscription) *response_models.BillingSubscription {
+	out := &response_models.BillingSubscription{
+		ID:        sub.ID.String(),
+		PlanCode:  sub.Plan.Code,
+		PlanName:  sub.Plan.Name,
+		Status:    string(sub.Status),
+		StartsAt:  formatBillingTime(sub.StartsAt),
+		EndsAt:    formatBillingTime(sub.EndsAt),
+		AutoRenew: sub.AutoRenew,
+	}
+	if sub.CanceledAt != nil {
+		out.CanceledAt = formatBillingTime(*sub.CanceledAt)
+	}
+	if sub.Status == db_models.SubStatusPaused && sub.PausedAt != nil {
+		out.PausedAt = formatBillingTime(*sub.PausedAt)
+		out.RemainingDays = int(sub.PausedRemaining / 86400)
+		out.EndsAt = "" // moves when the pause ends
+	}
+	return out
+}
+
+func formatBillingTime(sec int64) string {
+	return utils.FormatRFC3339VN(utils.FromUnixSecondsVN(sec))
+}
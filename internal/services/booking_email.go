@@ -0,0 +1,208 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"vivu/internal/models/response_models"
+)
+
+// inboundEmail is the part of a forwarded email the document vault cares about.
+type inboundEmail struct {
+	From        string
+	Recipients  []string
+	Subject     string
+	Text        string
+	Attachments []emailAttachment
+}
+
+type emailAttachment struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+var mimeWords = new(mime.WordDecoder)
+
+// parseInboundEmail reads a raw RFC 5322 message: recipients, subject, the text body (HTML
+// stripped when there is no plain part) and the attachments.
+func parseInboundEmail(raw []byte) (*inboundEmail, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	out := &inboundEmail{Subject: decodeMIMEHeader(msg.Header.Get("Subject"))}
+	if from, err := mail.ParseAddress(msg.Header.Get("From")); err == nil {
+		out.From = strings.ToLower(from.Address)
+	}
+	// Forwarding services keep the original envelope recipient in one of these
+	for _, h := range []string{"To", "Cc", "Delivered-To", "X-Original-To", "X-Forwarded-To"} {
+		if list, err := mail.ParseAddressList(msg.Header.Get(h)); err == nil {
+			for _, a := range list {
+				out.Recipients = append(out.Recipients, strings.ToLower(a.Address))
+			}
+		}
+	}
+
+	var plain, htmlText string
+	err = walkMIMEPart(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), "", msg.Body,
+		func(mediaType, fileName string, data []byte) {
+			switch {
+			case fileName != "":
+				out.Attachments = append(out.Attachments, emailAttachment{FileName: fileName, ContentType: mediaType, Data: data})
+			case mediaType == "text/plain" && plain == "":
+				plain = string(data)
+			case mediaType == "text/html" && htmlText == "":
+				htmlText = string(data)
+			}
+		})
+	if err != nil {
+		return nil, err
+	}
+	out.Text = plain
+	if out.Text == "" && htmlText != "" {
+		out.Text = stripHTML(htmlText)
+	}
+	return out, nil
+}
+
+// walkMIMEPart calls leaf for every non-multipart part, decoded; fileName is set for attachments.
+func walkMIMEPart(contentType, encoding, disposition string, body io.Reader, leaf func(mediaType, fileName string, data []byte)) error {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			// NextPart already undoes quoted-printable and drops the header
+			err = walkMIMEPart(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"),
+				part.Header.Get("Content-Disposition"), part, leaf)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	var r io.Reader = body
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		r = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		r = quotedprintable.NewReader(body)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("decode %s part: %w", mediaType, err)
+	}
+
+	fileName := params["name"]
+	if disp, dparams, err := mime.ParseMediaType(disposition); err == nil {
+		if dparams["filename"] != "" {
+			fileName = dparams["filename"]
+		} else if disp == "attachment" && fileName == "" {
+			fileName = "attachment"
+		}
+	}
+	leaf(mediaType, decodeMIMEHeader(fileName), data)
+	return nil
+}
+
+func decodeMIMEHeader(s string) string {
+	if d, err := mimeWords.DecodeHeader(s); err == nil {
+		return d
+	}
+	return s
+}
+
+var (
+	htmlTagPattern    = regexp.MustCompile(`(?s)<(script|style)[^>]*>.*?</(script|style)>|<[^>]+>`)
+	blankLinesPattern = regexp.MustCompile(`\n\s*\n+`)
+)
+
+func stripHTML(s string) string {
+	s = htmlTagPattern.ReplaceAllString(s, "\n")
+	return strings.TrimSpace(blankLinesPattern.ReplaceAllString(html.UnescapeString(s), "\n"))
+}
+
+var (
+	// "Booking reference: ABC123", "Mã đặt chỗ ABC123", "PNR: QWERTY"
+	bookingRefPattern = regexp.MustCompile(`(?i:booking|confirmation|reservation|reference|pnr|itinerary|mã đặt chỗ|mã xác nhận|số xác nhận|mã đặt phòng)[^\p{L}\p{N}]{0,20}(?:(?i:number|no\.?|code|id|#)[^\p{L}\p{N}]{0,5})?([A-Z0-9]{5,12})\b`)
+
+	isoDatePattern   = regexp.MustCompile(`\b(\d{4})-(\d{2})-(\d{2})\b`)
+	vnDatePattern    = regexp.MustCompile(`\b(\d{1,2})/(\d{1,2})/(\d{4})\b`) // dd/mm/yyyy
+	longDatePattern  = regexp.MustCompile(`\b(\d{1,2}) (Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec)[a-z]*,? (\d{4})\b`)
+	upperWordPattern = regexp.MustCompile(`^[A-Z]+$`)
+)
+
+// bookingEmailInfo reads the provider, booking reference and dates of a booking email.
+func bookingEmailInfo(e *inboundEmail) *response_models.BookingEmailInfo {
+	info := &response_models.BookingEmailInfo{Subject: e.Subject, Sender: e.From}
+	if _, domain, ok := strings.Cut(e.From, "@"); ok {
+		info.Provider = domain
+	}
+
+	text := e.Subject + "\n" + e.Text
+	for _, m := range bookingRefPattern.FindAllStringSubmatch(text, -1) {
+		ref := m[1]
+		// Shouted words ("CONFIRMED") are not references; letter-only PNRs are 6 long
+		if upperWordPattern.MatchString(ref) && len(ref) != 6 {
+			continue
+		}
+		info.Reference = ref
+		break
+	}
+	info.Dates = emailDates(text)
+	return info
+}
+
+func emailDates(text string) []string {
+	type found struct {
+		at   int
+		date string
+	}
+	var all []found
+	add := func(at int, layout, value string) {
+		if t, err := time.Parse(layout, value); err == nil {
+			all = append(all, found{at, t.Format("2006-01-02")})
+		}
+	}
+	for _, m := range isoDatePattern.FindAllStringIndex(text, -1) {
+		add(m[0], "2006-01-02", text[m[0]:m[1]])
+	}
+	for _, m := range vnDatePattern.FindAllStringSubmatchIndex(text, -1) {
+		add(m[0], "2/1/2006", fmt.Sprintf("%s/%s/%s", text[m[2]:m[3]], text[m[4]:m[5]], text[m[6]:m[7]]))
+	}
+	for _, m := range longDatePattern.FindAllStringSubmatchIndex(text, -1) {
+		add(m[0], "2 Jan 2006", fmt.Sprintf("%s %s %s", text[m[2]:m[3]], text[m[4]:m[5]], text[m[6]:m[7]]))
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].at < all[j].at })
+
+	seen := make(map[string]bool)
+	var out []string
+	for _, f := range all {
+		if !seen[f.date] {
+			seen[f.date] = true
+			out = append(out, f.date)
+		}
+	}
+	return out
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+// bookingRefParam carries the booking event ID when no deep-link parameter uses {ref}.
+const bookingRefParam = "vivu_ref"
+
+type BookingServiceInterface interface {
+	SetLink(ctx context.Context, poiID string, req request_models.SetBookingLinkRequest) (*response_models.BookingLink, error)
+	ListLinks(ctx context.Context, poiID string) ([]response_models.BookingLink, error)
+	DeleteLink(ctx context.Context, poiID, partner string) error
+
+	// BookActivity records a "book" event and returns the partner URL to open.
+	BookActivity(ctx context.Context, userID, journeyID, activityID string, req request_models.BookActivityRequest) (*response_models.BookingHandoff, error)
+	UpdateBookingStatus(ctx context.Context, userID, journeyID, activityID string, req request_models.UpdateBookingStatusRequest) (*response_models.ActivityBooking, error)
+}
+
+type BookingService struct {
+	repo        repositories.BookingRepositoryInterface
+	poiRepo     repositories.POIRepository
+	journeyRepo repositories.JourneyRepository
+}
+
+func NewBookingService(repo repositories.BookingRepositoryInterface, poiRepo repositories.POIRepository, journeyRepo repositories.JourneyRepository) BookingServiceInterface {
+	return &BookingService{repo: repo, poiRepo: poiRepo, journeyRepo: journeyRepo}
+}
+
+func (s *BookingService) SetLink(ctx context.Context, poiID string, req request_models.SetBookingLinkRequest) (*response_models.BookingLink, error) {
+	id, err := uuid.Parse(poiID)
+	if err != nil {
+		return nil, utils.ErrInvalidInput
+	}
+	poi, err := s.poiRepo.GetByIDWithDetails(ctx, poiID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	if poi == nil {
+		return nil, utils.ErrPOINotFound
+	}
+
+	link := &db_models.POIBookingLink{
+		POIID:          id,
+		Partner:        strings.ToLower(strings.TrimSpace(req.Partner)),
+		URL:            req.URL,
+		DeepLinkParams: req.DeepLinkParams,
+	}
+	if err := s.repo.UpsertLink(ctx, link); err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	out := toBookingLinkResponse(*link)
+	return &out, nil
+}
+
+func (s *BookingService) ListLinks(ctx context.Context, poiID string) ([]response_models.BookingLink, error) {
+	id, err := uuid.Parse(poiID)
+	if err != nil {
+		return nil, utils.ErrInvalidInput
+	}
+	links, err := s.repo.ListLinksByPOI(ctx, id)
+	if err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	out := make([]response_models.BookingLink, 0, len(links))
+	for _, l := range links {
+		out = append(out, toBookingLinkResponse(l))
+	}
+	return out, nil
+}
+
+func (s *BookingService) DeleteLink(ctx context.Context, poiID, partner string) error {
+	id, err := uuid.Parse(poiID)
+	if err != nil {
+		return utils.ErrInvalidInput
+	}
+	found, err := s.repo.DeleteLink(ctx, id, strings.ToLower(strings.TrimSpace(partner)))
+	if err != nil {
+		return utils.ErrDatabaseError.Wrap(err)
+	}
+	if !found {
+		return utils.RecordNotFound
+	}
+	return nil
+}
+
+func (s *BookingService) BookActivity(ctx context.Context, userID, journeyID, activityID string, req request_models.BookActivityRequest) (*response_models.BookingHandoff, error) {
+	journey, activity, err := s.ownedActivity(ctx, userID, journeyID, activityID)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := s.repo.ListLinksByPOI(ctx, activity.SelectedPOIID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	if len(links) == 0 {
+		return nil, utils.ErrInvalidInput.WithMessage("This place cannot be booked online")
+	}
+	link := &links[0]
+	if partner := strings.ToLower(strings.TrimSpace(req.Partner)); partner != "" {
+		link = nil
+		for i := range links {
+			if links[i].Partner == partner {
+				link = &links[i]
+				break
+			}
+		}
+		if link == nil {
+			return nil, utils.ErrInvalidInput.WithMessage(fmt.Sprintf("No booking partner %q for this place", req.Partner))
+		}
+	}
+
+	event := &db_models.BookingEvent{
+		BaseModel:  db_models.BaseModel{ID: uuid.New()},
+		AccountID:  journey.AccountID,
+		POIID:      activity.SelectedPOIID,
+		ActivityID: &activity.ID,
+		Partner:    link.Partner,
+	}
+	event.URL, err = bookingURL(link, activity, event.ID)
+	if err != nil {
+		return nil, utils.ErrInternal.Wrap(fmt.Errorf("booking link of %s/%s: %w", link.POIID, link.Partner, err))
+	}
+	if err := s.repo.CreateEvent(ctx, event); err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+
+	// A confirmed reservation stays confirmed when the traveller opens the partner page again
+	status := activity.BookingStatus
+	if status != db_models.BookingStatusConfirmed {
+		status = db_models.BookingStatusPending
+		if err := s.repo.UpdateActivityBooking(ctx, activity.ID, status, link.Partner, activity.BookingReference); err != nil {
+			return nil, utils.ErrDatabaseError.Wrap(err)
+		}
+	}
+
+	return &response_models.BookingHandoff{
+		EventID:    event.ID,
+		ActivityID: activity.ID,
+		Partner:    link.Partner,
+		URL:        event.URL,
+		Status:     status,
+	}, nil
+}
+
+func (s *BookingService) UpdateBookingStatus(ctx context.Context, userID, journeyID, activityID string, req request_models.UpdateBookingStatusRequest) (*response_models.ActivityBooking, error) {
+	_, activity, err := s.ownedActivity(ctx, userID, journeyID, activityID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateActivityBooking(ctx, activity.ID, req.Status, "", strings.TrimSpace(req.Reference)); err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	return &response_models.ActivityBooking{
+		ActivityID: activity.ID,
+		Status:     req.Status,
+		Partner:    activity.BookingPartner,
+		Reference:  strings.TrimSpace(req.Reference),
+	}, nil
+}
+
+// ownedActivity loads an activity of a journey owned by userID.
+func (s *BookingService) ownedActivity(ctx context.Context, userID, journeyID, activityID string) (*db_models.Journey, *db_models.JourneyActivity, error) {
+	actID, err := uuid.Parse(activityID)
+	if err != nil {
+		return nil, nil, utils.ErrInvalidInput.WithMessage("Invalid activity ID")
+	}
+	journey, err := s.journeyRepo.GetDetailsOfJourneyById(ctx, journeyID)
+	if err != nil {
+		return nil, nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	if journey == nil {
+		return nil, nil, utils.ErrJourneyNotFound
+	}
+	if journey.AccountID.String() != userID {
+		return nil, nil, utils.ErrUnauthorized
+	}
+	for di := range journey.Days {
+		for ai := range journey.Days[di].Activities {
+			if a := &journey.Days[di].Activities[ai]; a.ID == actID {
+				return journey, a, nil
+			}
+		}
+	}
+	return nil, nil, utils.ErrInvalidInput.WithMessage("Activity not found in this journey")
+}
+
+// bookingURL adds the link's deep-link parameters to its URL, filling {date}, {time} and {ref}.
+func bookingURL(link *db_models.POIBookingLink, activity *db_models.JourneyActivity, eventID uuid.UUID) (string, error) {
+	u, err := url.Parse(link.URL)
+	if err != nil {
+		return "", err
+	}
+	start := activity.Time.In(vnLoc)
+	fill := strings.NewReplacer(
+		"{date}", start.Format("2006-01-02"),
+		"{time}", start.Format("15:04"),
+		"{ref}", eventID.String(),
+	)
+
+	q := u.Query()
+	hasRef := false
+	for k, v := range link.DeepLinkParams {
+		if strings.Contains(v, "{ref}") {
+			hasRef = true
+		}
+		q.Set(k, fill.Replace(v))
+	}
+	if !hasRef {
+		q.Set(bookingRefParam, eventID.String())
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+func toBookingLinkResponse(l db_models.POIBookingLink) response_models.BookingLink {
+	return response_models.BookingLink{Partner: l.Partner, URL: l.URL, DeepLinkParams: l.DeepLinkParams}
+}
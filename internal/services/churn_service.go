@@ -0,0 +1,422 @@
+package services
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+const (
+	churnBatchSize    = 500
+	churnIdleAfter    = 60 * 24 * time.Hour // no journey created for this long is a risk
+	churnRecentWindow = 30 * 24 * time.Hour // usage is compared between this window...
+	churnPriorWindow  = 60 * 24 * time.Hour // ...and the one before it, per month
+	winbackBatchSize  = 100
+	winbackClaimLease = 10 * time.Minute
+
+	// Weights of the reasons in the 0-100 score, and where the segments start.
+	churnWeightNoJourneys = 40
+	churnWeightAutoRenew  = 30
+	churnWeightDeclining  = 30
+	churnMediumFrom       = 30
+	churnHighFrom         = 60
+)
+
+type ChurnServiceInterface interface {
+	// ScoreAll rescores every active account and starts the win-back campaign of the segment
+	// accounts entered.
+	ScoreAll(ctx context.Context) (*response_models.ChurnRun, error)
+	// SendDueWinbacks queues the win-back emails that are due and returns how many were queued.
+	SendDueWinbacks(ctx context.Context) (int, error)
+
+	Overview(ctx context.Context) (*response_models.ChurnOverview, error)
+	ListAccounts(ctx context.Context, query request_models.ChurnAccountQuery) (*response_models.ChurnAccountPage, error)
+	ListCampaigns(ctx context.Context) ([]response_models.WinbackCampaign, error)
+	SetCampaign(ctx context.Context, segment string, req request_models.SetWinbackCampaignRequest, updatedBy string) (*response_models.WinbackCampaign, error)
+
+	Start()
+	Stop()
+}
+
+type ChurnService struct {
+	repo          repositories.ChurnRepositoryInterface
+	mail          IMailService
+	interval      time.Duration
+	scoreInterval time.Duration
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// NewChurnService reads CHURN_SCORE_INTERVAL (default 24h) and WINBACK_SEND_INTERVAL
+// (default 15m), how often due win-back emails are looked for.
+func NewChurnService(repo repositories.ChurnRepositoryInterface, mail IMailService) ChurnServiceInterface {
+	s := &ChurnService{
+		repo:          repo,
+		mail:          mail,
+		interval:      15 * time.Minute,
+		scoreInterval: 24 * time.Hour,
+		stop:          make(chan struct{}),
+	}
+	if d, err := time.ParseDuration(os.Getenv("CHURN_SCORE_INTERVAL")); err == nil && d > 0 {
+		s.scoreInterval = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("WINBACK_SEND_INTERVAL")); err == nil && d > 0 {
+		s.interval = d
+	}
+	return s
+}
+
+// scoreChurn weighs the signals of an account. Accounts younger than the idle window are not
+// held to it.
+func scoreChurn(sig repositories.ChurnSignals, now time.Time) (int, []string) {
+	score, reasons := 0, []string{}
+	idleFrom := now.Add(-churnIdleAfter).Unix()
+	if sig.CreatedAt < idleFrom && (sig.LastJourneyAt == nil || *sig.LastJourneyAt < idleFrom) {
+		score += churnWeightNoJourneys
+		reasons = append(reasons, db_models.ChurnReasonNoJourneys)
+	}
+	if sig.AutoRenewOff {
+		score += churnWeightAutoRenew
+		reasons = append(reasons, db_models.ChurnReasonAutoRenewOff)
+	}
+	// Under half of the prior monthly average: the prior window is two months long
+	if sig.PriorJourneys > 0 && sig.RecentJourneys*4 < sig.PriorJourneys {
+		score += churnWeightDeclining
+		reasons = append(reasons, db_models.ChurnReasonUsageDeclining)
+	}
+	return score, reasons
+}
+
+func churnSegment(score int) string {
+	switch {
+	case score >= churnHighFrom:
+		return db_models.ChurnRiskHigh
+	case score >= churnMediumFrom:
+		return db_models.ChurnRiskMedium
+	default:
+		return db_models.ChurnRiskLow
+	}
+}
+
+func (s *ChurnService) ScoreAll(ctx context.Context) (*response_models.ChurnRun, error) {
+	now := time.Now()
+	runAt := now.Unix()
+	recentFrom := now.Add(-churnRecentWindow).Unix()
+	priorFrom := now.Add(-churnRecentWindow - churnPriorWindow).Unix()
+
+	out := &response_models.ChurnRun{}
+	after := uuid.Nil
+	for {
+		signals, err := s.repo.Signals(ctx, after, churnBatchSize, runAt, recentFrom, priorFrom)
+		if err != nil {
+			return nil, utils.ErrDatabaseError
+		}
+		if len(signals) == 0 {
+			break
+		}
+		scores := make([]db_models.ChurnScore, 0, len(signals))
+		for _, sig := range signals {
+			score, reasons := scoreChurn(sig, now)
+			scores = append(scores, db_models.ChurnScore{
+				AccountID:      sig.AccountID,
+				Score:          score,
+				Segment:        churnSegment(score),
+				Reasons:        pq.StringArray(reasons),
+				LastJourneyAt:  sig.LastJourneyAt,
+				RecentJourneys: sig.RecentJourneys,
+				PriorJourneys:  sig.PriorJourneys,
+				AutoRenewOff:   sig.AutoRenewOff,
+				ScoredAt:       runAt,
+			})
+		}
+		if err := s.repo.SaveScores(ctx, scores); err != nil {
+			return nil, utils.ErrDatabaseError
+		}
+		out.Scored += len(signals)
+		after = signals[len(signals)-1].AccountID
+		if len(signals) < churnBatchSize {
+			break
+		}
+	}
+
+	if err := s.repo.DeleteStale(ctx, runAt); err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if err := s.repo.ResetWinback(ctx); err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	campaigns, err := s.repo.ListCampaigns(ctx)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	for _, c := range campaigns {
+		if !c.Active || len(c.Steps) == 0 {
+			continue
+		}
+		firstAt := now.Add(time.Duration(c.Steps[0].DelayDays) * 24 * time.Hour).Unix()
+		n, err := s.repo.StartWinback(ctx, c.Segment, runAt, firstAt)
+		if err != nil {
+			return nil, utils.ErrDatabaseError
+		}
+		out.WinbacksStarted += n
+	}
+	return out, nil
+}
+
+func (s *ChurnService) SendDueWinbacks(ctx context.Context) (int, error) {
+	now := time.Now()
+	due, err := s.repo.ClaimWinbackDue(ctx, now.Unix(), winbackBatchSize, winbackClaimLease)
+	if err != nil || len(due) == 0 {
+		return 0, err
+	}
+	campaigns, err := s.repo.ListCampaigns(ctx)
+	if err != nil {
+		return 0, err
+	}
+	bySegment := make(map[string]db_models.WinbackCampaign, len(campaigns))
+	for _, c := range campaigns {
+		bySegment[c.Segment] = c
+	}
+
+	queued := 0
+	for i := range due {
+		row := &due[i]
+		c, ok := bySegment[row.WinbackSegment]
+		account := row.Account
+		// A campaign switched off or shortened, or an account that left, ends the sequence
+		if !ok || !c.Active || row.WinbackStep >= len(c.Steps) ||
+			account.ID == uuid.Nil || account.DeletedAt.Valid || account.Status != db_models.AccountStatusActive {
+			if err := s.repo.StopWinback(ctx, row.ID); err != nil {
+				log.Printf("[churn] stopping win-back of %s: %v", row.AccountID, err)
+			}
+			continue
+		}
+
+		step := c.Steps[row.WinbackStep]
+		subject := strings.ReplaceAll(step.Subject, "{name}", account.Name)
+		body := strings.ReplaceAll(step.Body, "{name}", account.Name)
+		if err := s.mail.SendMailToNotifyUser(account.Email, subject, body, step.CTAText, step.CTAURL); err != nil {
+			// the claim runs out and the step is tried again
+			log.Printf("[churn] win-back step %d to %s: %v", row.WinbackStep, account.Email, err)
+			continue
+		}
+
+		var next *int64
+		if row.WinbackStep+1 < len(c.Steps) {
+			at := now.Add(time.Duration(c.Steps[row.WinbackStep+1].DelayDays) * 24 * time.Hour).Unix()
+			next = &at
+		}
+		if err := s.repo.AdvanceWinback(ctx, row.ID, row.WinbackStep+1, next, now.Unix()); err != nil {
+			log.Printf("[churn] win-back step %d to %s sent but not recorded: %v", row.WinbackStep, account.Email, err)
+		}
+		queued++
+	}
+	return queued, nil
+}
+
+func (s *ChurnService) Overview(ctx context.Context) (*response_models.ChurnOverview, error) {
+	rows, err := s.repo.SegmentCounts(ctx)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	bySegment := make(map[string]repositories.ChurnSegmentCount, len(rows))
+	var scoredAt int64
+	for _, r := range rows {
+		bySegment[r.Segment] = r
+		if r.ScoredAt > scoredAt {
+			scoredAt = r.ScoredAt
+		}
+	}
+
+	out := &response_models.ChurnOverview{}
+	for _, seg := range []string{db_models.ChurnRiskHigh, db_models.ChurnRiskMedium, db_models.ChurnRiskLow} {
+		r := bySegment[seg]
+		out.Segments = append(out.Segments, response_models.ChurnSegment{Segment: seg, Accounts: r.Accounts, InWinback: r.InWinback})
+	}
+	if scoredAt > 0 {
+		out.ScoredAt = utils.FormatRFC3339VN(utils.FromUnixSecondsVN(scoredAt))
+	}
+	return out, nil
+}
+
+func (s *ChurnService) ListAccounts(ctx context.Context, query request_models.ChurnAccountQuery) (*response_models.ChurnAccountPage, error) {
+	rows, total, err := s.repo.ListScores(ctx, query.Segment, query.Page, query.PageSize)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	out := &response_models.ChurnAccountPage{
+		Items:    make([]response_models.ChurnAccount, 0, len(rows)),
+		Total:    total,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	}
+	for i := range rows {
+		out.Items = append(out.Items, toChurnAccountResponse(&rows[i]))
+	}
+	return out, nil
+}
+
+func (s *ChurnService) ListCampaigns(ctx context.Context) ([]response_models.WinbackCampaign, error) {
+	campaigns, err := s.repo.ListCampaigns(ctx)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	bySegment := make(map[string]*db_models.WinbackCampaign, len(campaigns))
+	for i := range campaigns {
+		bySegment[campaigns[i].Segment] = &campaigns[i]
+	}
+	// Segments without a campaign yet are listed as inactive
+	out := make([]response_models.WinbackCampaign, 0, 2)
+	for _, seg := range []string{db_models.ChurnRiskHigh, db_models.ChurnRiskMedium} {
+		c, ok := bySegment[seg]
+		if !ok {
+			c = &db_models.WinbackCampaign{Segment: seg}
+		}
+		out = append(out, toWinbackCampaignResponse(c))
+	}
+	return out, nil
+}
+
+func (s *ChurnService) SetCampaign(ctx context.Context, segment string, req request_models.SetWinbackCampaignRequest, updatedBy string) (*response_models.WinbackCampaign, error) {
+	if segment != db_models.ChurnRiskMedium && segment != db_models.ChurnRiskHigh {
+		return nil, utils.ErrInvalidInput.WithMessage("Win-back campaigns exist for the medium and high segments")
+	}
+	if req.Active && len(req.Steps) == 0 {
+		return nil, utils.ErrInvalidInput.WithMessage("An active campaign needs at least one step")
+	}
+
+	campaign := &db_models.WinbackCampaign{
+		Segment:   segment,
+		Active:    req.Active,
+		Steps:     make([]db_models.WinbackStep, 0, len(req.Steps)),
+		UpdatedBy: updatedBy,
+	}
+	for _, st := range req.Steps {
+		campaign.Steps = append(campaign.Steps, db_models.WinbackStep{
+			DelayDays: st.DelayDays,
+			Subject:   strings.TrimSpace(st.Subject),
+			Body:      strings.TrimSpace(st.Body),
+			CTAText:   strings.TrimSpace(st.CTAText),
+			CTAURL:    strings.TrimSpace(st.CTAURL),
+		})
+	}
+	if err := s.repo.UpsertCampaign(ctx, campaign); err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	saved, err := s.repo.FindCampaign(ctx, segment)
+	if err != nil || saved == nil {
+		return nil, utils.ErrDatabaseError
+	}
+	out := toWinbackCampaignResponse(saved)
+	return &out, nil
+}
+
+func (s *ChurnService) Start() {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		var scoredAt time.Time
+		for {
+			if time.Since(scoredAt) >= s.scoreInterval {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+				run, err := s.ScoreAll(ctx)
+				cancel()
+				if err != nil {
+					log.Printf("[churn] scoring failed: %v", err)
+				} else {
+					scoredAt = time.Now()
+					log.Printf("[churn] scored %d accounts, %d win-back sequences started", run.Scored, run.WinbacksStarted)
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			n, err := s.SendDueWinbacks(ctx)
+			cancel()
+			if err != nil {
+				log.Printf("[churn] win-back run failed: %v", err)
+			} else if n > 0 {
+				log.Printf("[churn] queued %d win-back emails", n)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (s *ChurnService) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+func toChurnAccountResponse(c *db_models.ChurnScore) response_models.ChurnAccount {
+	out := response_models.ChurnAccount{
+		AccountID:      c.AccountID.String(),
+		Email:          c.Account.Email,
+		Name:           c.Account.Name,
+		Score:          c.Score,
+		Segment:        c.Segment,
+		Reasons:        []string(c.Reasons),
+		RecentJourneys: c.RecentJourneys,
+		PriorJourneys:  c.PriorJourneys,
+		AutoRenewOff:   c.AutoRenewOff,
+		ScoredAt:       utils.FormatRFC3339VN(utils.FromUnixSecondsVN(c.ScoredAt)),
+	}
+	if out.Reasons == nil {
+		out.Reasons = []string{}
+	}
+	if c.LastJourneyAt != nil {
+		out.LastJourneyAt = utils.FormatRFC3339VN(utils.FromUnixSecondsVN(*c.LastJourneyAt))
+	}
+	if c.WinbackStartedAt != nil {
+		w := &response_models.ChurnWinback{
+			Segment:   c.WinbackSegment,
+			Step:      c.WinbackStep,
+			StartedAt: utils.FormatRFC3339VN(utils.FromUnixSecondsVN(*c.WinbackStartedAt)),
+		}
+		if c.WinbackNextAt != nil {
+			w.NextAt = utils.FormatRFC3339VN(utils.FromUnixSecondsVN(*c.WinbackNextAt))
+		}
+		if c.WinbackLastAt != nil {
+			w.LastSentAt = utils.FormatRFC3339VN(utils.FromUnixSecondsVN(*c.WinbackLastAt))
+		}
+		out.Winback = w
+	}
+	return out
+}
+
+func toWinbackCampaignResponse(c *db_models.WinbackCampaign) response_models.WinbackCampaign {
+	out := response_models.WinbackCampaign{
+		Segment:   c.Segment,
+		Active:    c.Active,
+		Steps:     make([]response_models.WinbackStep, 0, len(c.Steps)),
+		UpdatedBy: c.UpdatedBy,
+	}
+	for _, st := range c.Steps {
+		out.Steps = append(out.Steps, response_models.WinbackStep{
+			DelayDays: st.DelayDays,
+			Subject:   st.Subject,
+			Body:      st.Body,
+			CTAText:   st.CTAText,
+			CTAURL:    st.CTAURL,
+		})
+	}
+	if c.UpdatedAt > 0 {
+		out.UpdatedAt = utils.FormatRFC3339VN(utils.FromUnixSecondsVN(c.UpdatedAt))
+	}
+	return out
+}
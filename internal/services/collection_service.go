@@ -0,0 +1,402 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+// A stop of a collection without a duration of its own takes this long on a seeded day, in minutes.
+const collectionStopMinutes = 90
+
+// Seeded stops start on this grid, in minutes.
+const collectionSlotStep = 15
+
+var collectionSlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+type CollectionServiceInterface interface {
+	// ListCollections pages through the collections; public callers see only published ones.
+	ListCollections(ctx context.Context, query request_models.CollectionListQuery, publishedOnly bool) (*response_models.CollectionPage, error)
+	GetCollection(ctx context.Context, idOrSlug string, publishedOnly bool) (*response_models.CollectionDetail, error)
+	// SaveCollection creates a collection when id is empty and replaces the one with that ID otherwise.
+	SaveCollection(ctx context.Context, id string, req request_models.SaveCollectionRequest, updatedBy string) (*response_models.CollectionDetail, error)
+	DeleteCollection(ctx context.Context, id string) error
+	// SeedJourneyDay fills a day of the caller's journey with the stops of a published
+	// collection, in the collection's order, timed around travel and opening hours.
+	SeedJourneyDay(ctx context.Context, journeyId string, userId string, req request_models.SeedDayFromCollectionRequest) (*response_models.SeedDayResponse, error)
+}
+
+type CollectionService struct {
+	repo        repositories.CollectionRepositoryInterface
+	poiRepo     repositories.POIRepository
+	journeyRepo repositories.JourneyRepository
+	journeys    JourneyServiceInterface
+	matrix      DistanceMatrixService
+	events      EventBus
+}
+
+func NewCollectionService(
+	repo repositories.CollectionRepositoryInterface,
+	poiRepo repositories.POIRepository,
+	journeyRepo repositories.JourneyRepository,
+	journeys JourneyServiceInterface,
+	matrix DistanceMatrixService,
+	events EventBus,
+) CollectionServiceInterface {
+	return &CollectionService{repo: repo, poiRepo: poiRepo, journeyRepo: journeyRepo, journeys: journeys, matrix: matrix, events: events}
+}
+
+func (s *CollectionService) ListCollections(ctx context.Context, query request_models.CollectionListQuery, publishedOnly bool) (*response_models.CollectionPage, error) {
+	var provinceID *uuid.UUID
+	if query.ProvinceID != "" {
+		id, err := uuid.Parse(query.ProvinceID)
+		if err != nil {
+			return nil, utils.ErrInvalidInput
+		}
+		provinceID = &id
+	}
+
+	collections, total, err := s.repo.ListCollections(ctx, publishedOnly, provinceID, (query.Page-1)*query.PageSize, query.PageSize)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	ids := make([]uuid.UUID, 0, len(collections))
+	for _, c := range collections {
+		ids = append(ids, c.ID)
+	}
+	counts, err := s.repo.ItemCounts(ctx, ids)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+
+	out := &response_models.CollectionPage{
+		Items:    make([]response_models.CollectionSummary, 0, len(collections)),
+		Total:    total,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	}
+	for i := range collections {
+		out.Items = append(out.Items, toCollectionSummary(&collections[i], counts[collections[i].ID]))
+	}
+	return out, nil
+}
+
+func (s *CollectionService) GetCollection(ctx context.Context, idOrSlug string, publishedOnly bool) (*response_models.CollectionDetail, error) {
+	collection, err := s.repo.FindCollection(ctx, idOrSlug)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if collection == nil || (publishedOnly && !collection.IsPublished) {
+		return nil, utils.RecordNotFound
+	}
+	return toCollectionDetail(collection, publishedOnly), nil
+}
+
+func (s *CollectionService) SaveCollection(ctx context.Context, id string, req request_models.SaveCollectionRequest, updatedBy string) (*response_models.CollectionDetail, error) {
+	collection := &db_models.Collection{}
+	if id != "" {
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, utils.ErrInvalidInput
+		}
+		existing, err := s.repo.FindCollection(ctx, id)
+		if err != nil {
+			return nil, utils.ErrDatabaseError
+		}
+		if existing == nil {
+			return nil, utils.RecordNotFound
+		}
+		collection = existing
+	}
+
+	slug := strings.ToLower(strings.TrimSpace(req.Slug))
+	if slug == "" {
+		slug = collectionSlug(req.Title)
+	}
+	if !collectionSlugPattern.MatchString(slug) {
+		return nil, utils.ErrInvalidInput.WithMessage("The slug may only hold lowercase letters, digits and single dashes")
+	}
+	// A UUID-shaped slug would be looked up as an ID and never found
+	if _, err := uuid.Parse(slug); err == nil {
+		return nil, utils.ErrInvalidInput.WithMessage("The slug cannot look like an ID")
+	}
+	if slug != collection.Slug {
+		taken, err := s.repo.FindCollection(ctx, slug)
+		if err != nil {
+			return nil, utils.ErrDatabaseError
+		}
+		if taken != nil {
+			return nil, utils.ErrInvalidInput.WithMessage("Another collection already uses the slug " + slug)
+		}
+	}
+
+	var provinceID *uuid.UUID
+	if req.ProvinceID != "" {
+		pid, err := uuid.Parse(req.ProvinceID)
+		if err != nil {
+			return nil, utils.ErrInvalidInput
+		}
+		provinceID = &pid
+	}
+
+	// Each POI once, first position wins
+	var poiIDs []string
+	seen := map[string]bool{}
+	for _, p := range req.POIs {
+		if key := strings.ToLower(p.POIID); !seen[key] {
+			seen[key] = true
+			poiIDs = append(poiIDs, key)
+		}
+	}
+	pois := map[string]*db_models.POI{}
+	if len(poiIDs) > 0 {
+		found, err := s.poiRepo.ListPoisByPoisId(ctx, poiIDs)
+		if err != nil {
+			return nil, utils.ErrDatabaseError
+		}
+		for _, p := range found {
+			if p.Status != db_models.POIStatusDraft {
+				pois[p.ID.String()] = p
+			}
+		}
+	}
+	items := make([]db_models.CollectionItem, 0, len(poiIDs))
+	seen = map[string]bool{}
+	for _, p := range req.POIs {
+		key := strings.ToLower(p.POIID)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		poi, ok := pois[key]
+		if !ok {
+			return nil, utils.ErrInvalidInput.WithMessage("POI " + key + " does not exist or is still a draft")
+		}
+		if provinceID != nil && poi.ProvinceID != *provinceID {
+			return nil, utils.ErrInvalidInput.WithMessage("POI " + poi.Name + " is not in the collection's province")
+		}
+		items = append(items, db_models.CollectionItem{
+			POIID:           poi.ID,
+			Position:        len(items) + 1,
+			Note:            strings.TrimSpace(p.Note),
+			DurationMinutes: p.DurationMinutes,
+		})
+	}
+
+	collection.Slug = slug
+	collection.Title = strings.TrimSpace(req.Title)
+	collection.Description = strings.TrimSpace(req.Description)
+	collection.CoverImageURL = strings.TrimSpace(req.CoverImageURL)
+	collection.ProvinceID = provinceID
+	collection.Province = nil
+	collection.IsPublished = req.IsPublished
+	collection.UpdatedBy = updatedBy
+	if err := s.repo.SaveCollection(ctx, collection, items); err != nil {
+		// FK violation on an unknown province lands here too
+		log.Printf("[collection] saving %s: %v", slug, err)
+		return nil, utils.ErrDatabaseError
+	}
+	return s.GetCollection(ctx, collection.ID.String(), false)
+}
+
+func (s *CollectionService) DeleteCollection(ctx context.Context, id string) error {
+	cid, err := uuid.Parse(id)
+	if err != nil {
+		return utils.ErrInvalidInput
+	}
+	found, err := s.repo.DeleteCollection(ctx, cid)
+	if err != nil {
+		return utils.ErrDatabaseError
+	}
+	if !found {
+		return utils.RecordNotFound
+	}
+	return nil
+}
+
+func (s *CollectionService) SeedJourneyDay(ctx context.Context, journeyId string, userId string, req request_models.SeedDayFromCollectionRequest) (*response_models.SeedDayResponse, error) {
+	journey, err := s.journeyRepo.GetDetailsOfJourneyById(ctx, journeyId)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if journey == nil {
+		return nil, utils.ErrJourneyNotFound
+	}
+	if journey.AccountID.String() != userId {
+		return nil, utils.ErrUnauthorized
+	}
+
+	var day *db_models.JourneyDay
+	for i := range journey.Days {
+		if journey.Days[i].DayNumber == req.DayNumber {
+			day = &journey.Days[i]
+			break
+		}
+	}
+	if day == nil {
+		return nil, utils.ErrInvalidInput.WithMessage("Day not found in this journey")
+	}
+	if len(day.Activities) > 0 && !req.Replace {
+		return nil, utils.ErrJourneyDayNotEmpty
+	}
+
+	collection, err := s.repo.FindCollection(ctx, strings.TrimSpace(req.Collection))
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if collection == nil || !collection.IsPublished {
+		return nil, utils.RecordNotFound
+	}
+
+	window := DefaultWorkingWindow
+	if w, ok := NewWorkingWindow(journey.Account.DayStart, journey.Account.DayEnd); ok {
+		window = w
+	}
+	activities, skipped := s.scheduleCollection(ctx, collection.Items, window)
+	if len(activities) == 0 {
+		return nil, utils.ErrInvalidInput.WithMessage("None of the collection's places fit into this day")
+	}
+
+	if err := s.journeyRepo.ReplaceDayPlan(ctx, day.ID, &response_models.PlanOnlyDay{Day: day.DayNumber, Activities: activities}); err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	s.events.Publish(ctx, journey.AccountID, EventJourneyUpdated, JourneyUpdatedEvent{
+		JourneyID: journeyId,
+		Change:    "day_seeded",
+		Day:       day.DayNumber,
+	})
+
+	detail, err := s.journeys.GetDetailsInfoOfJourneyById(ctx, journeyId, TravelModeDriving)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	out := &response_models.SeedDayResponse{Added: len(activities), Skipped: skipped}
+	for i := range detail.Days {
+		if detail.Days[i].ID == day.ID {
+			out.Day = &detail.Days[i]
+			break
+		}
+	}
+	return out, nil
+}
+
+// scheduleCollection lays the stops out one after the other from the start of the window,
+// adding the drive from the previous stop. A stop closed at its turn waits for its place to
+// open when that still fits; stops that do not fit the day are skipped and reported.
+func (s *CollectionService) scheduleCollection(ctx context.Context, items []db_models.CollectionItem, window WorkingWindow) ([]response_models.PlanOnlyActivity, []string) {
+	var activities []response_models.PlanOnlyActivity
+	var skipped []string
+	var prev *MatrixPoint
+	clock := window.Start
+	for i := range items {
+		item := &items[i]
+		poi := &item.POI
+		// Deleted POIs are not preloaded
+		if poi.ID == uuid.Nil || poi.Status == db_models.POIStatusDraft {
+			continue
+		}
+
+		point := MatrixPoint{ID: poi.ID.String(), Lat: poi.Latitude, Lng: poi.Longitude}
+		start := clock
+		if prev != nil && hasCoords(prev.Lat, prev.Lng) && hasCoords(point.Lat, point.Lng) {
+			if leg, ok := legBetween(ctx, s.matrix, *prev, point, TravelModeDriving); ok {
+				start += (leg.DurationSeconds + 59) / 60
+			}
+		}
+		start = (start + collectionSlotStep - 1) / collectionSlotStep * collectionSlotStep
+
+		minutes := item.DurationMinutes
+		if minutes <= 0 {
+			minutes = collectionStopMinutes
+		}
+		if open := openingWindows(poi.OpeningHours); len(open) > 0 && !openDuring(open, start, start+minutes) {
+			moved := false
+			for _, w := range open {
+				if w.From > start && w.From < w.To && openDuring(open, w.From, w.From+minutes) && w.From+minutes <= window.End {
+					start, moved = w.From, true
+					break
+				}
+			}
+			if !moved {
+				skipped = append(skipped, fmt.Sprintf("%s: closed at %s", poi.Name, formatClock(start)))
+				continue
+			}
+		}
+		if start+minutes > window.End {
+			skipped = append(skipped, poi.Name+": no time left in the day")
+			continue
+		}
+
+		activities = append(activities, response_models.PlanOnlyActivity{
+			StartTime: formatClock(start),
+			EndTime:   formatClock(start + minutes),
+			MainPOIID: poi.ID.String(),
+			Note:      item.Note,
+		})
+		clock = start + minutes
+		prev = &point
+	}
+	return activities, skipped
+}
+
+// collectionSlug turns a title into a URL slug: "Best cafés in Đà Lạt" becomes
+// "best-cafes-in-da-lat".
+func collectionSlug(title string) string {
+	slug := strings.ReplaceAll(keywordText(title), " ", "-")
+	if len(slug) > 120 {
+		slug = strings.TrimRight(slug[:120], "-")
+	}
+	return slug
+}
+
+func toCollectionSummary(c *db_models.Collection, poiCount int) response_models.CollectionSummary {
+	out := response_models.CollectionSummary{
+		ID:            c.ID.String(),
+		Slug:          c.Slug,
+		Title:         c.Title,
+		Description:   c.Description,
+		CoverImageURL: c.CoverImageURL,
+		POICount:      poiCount,
+		IsPublished:   c.IsPublished,
+	}
+	if c.ProvinceID != nil {
+		out.ProvinceID = c.ProvinceID.String()
+	}
+	if c.Province != nil {
+		out.ProvinceName = c.Province.Name
+	}
+	if c.UpdatedAt > 0 {
+		out.UpdatedAt = time.Unix(c.UpdatedAt, 0).UTC().Format(time.RFC3339)
+	}
+	return out
+}
+
+// toCollectionDetail lists the items in order. Deleted POIs are dropped; drafts are shown to
+// admins only, since the public cannot open them.
+func toCollectionDetail(c *db_models.Collection, publishedOnly bool) *response_models.CollectionDetail {
+	pois := make([]response_models.CollectionPOI, 0, len(c.Items))
+	for i := range c.Items {
+		item := &c.Items[i]
+		if item.POI.ID == uuid.Nil || (publishedOnly && item.POI.Status == db_models.POIStatusDraft) {
+			continue
+		}
+		pois = append(pois, response_models.CollectionPOI{
+			POI:             toPOIResponse(&item.POI),
+			Note:            item.Note,
+			DurationMinutes: item.DurationMinutes,
+		})
+	}
+	return &response_models.CollectionDetail{
+		CollectionSummary: toCollectionSummary(c, len(pois)),
+		POIs:              pois,
+	}
+}
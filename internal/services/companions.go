@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+)
+
+// Quiz answers for the companions question; anything else also means "use my profile".
+const (
+	companionsFromProfile = "Bring my saved companions"
+	companionsNone        = "Just us this time"
+)
+
+// Whole words (diacritics folded) of places that do not suit the group.
+var (
+	adultOnlyKeywords = []string{
+		"bar", "pub", "nightclub", "club", "lounge", "casino", "beer", "bia", "rooftop bar", "quan nhau",
+	}
+	strenuousKeywords = []string{
+		"hiking", "trekking", "trek", "climbing", "leo nui", "canyoning", "zipline", "paragliding",
+		"du luon", "rappelling", "abseiling",
+	}
+)
+
+// minCompanionCandidates keeps the filter from starving the planner in small catalogs.
+const minCompanionCandidates = 8
+
+// CompanionNeeds summarises who comes along, in the terms the planner cares about.
+type CompanionNeeds struct {
+	Toddlers int // 0-3
+	Kids     int // 4-12, or a child without an age
+	Seniors  int // 65+, or a parent without an age
+	Others   int
+	people   []string // "child (age 2)", used in the model profile
+}
+
+func companionNeeds(list []response_models.Companion) CompanionNeeds {
+	var n CompanionNeeds
+	for _, c := range list {
+		switch {
+		case c.Age != nil && *c.Age <= 3:
+			n.Toddlers++
+		case c.Age != nil && *c.Age <= 12, c.Age == nil && c.Relation == "child":
+			n.Kids++
+		case c.Age != nil && *c.Age >= 65, c.Age == nil && c.Relation == "parent":
+			n.Seniors++
+		default:
+			n.Others++
+		}
+
+		who := c.Relation
+		if c.Age != nil {
+			who = fmt.Sprintf("%s (age %d)", who, *c.Age)
+		}
+		if c.Notes != "" {
+			who += ": " + c.Notes
+		}
+		n.people = append(n.people, who)
+	}
+	return n
+}
+
+func (n CompanionNeeds) empty() bool {
+	return len(n.people) == 0
+}
+
+// Profile describes the companions for the model profile.
+func (n CompanionNeeds) Profile() []string {
+	return append([]string{}, n.people...)
+}
+
+// WithCompanions returns a copy of g tightened for the group: fewer stops and longer breaks
+// with small children or seniors, and a nap window for toddlers. Works on nil.
+func (g *PlanGuardrails) WithCompanions(n CompanionNeeds) *PlanGuardrails {
+	if n.Toddlers+n.Kids+n.Seniors == 0 {
+		return g
+	}
+	out := &PlanGuardrails{}
+	if g != nil {
+		*out = *g
+		out.Forbidden = append([]timeWindow{}, g.Forbidden...)
+		out.Notes = append([]string{}, g.Notes...)
+	}
+	tighten := func(maxPerDay, buffer int) {
+		if out.MaxActivitiesPerDay == 0 || maxPerDay < out.MaxActivitiesPerDay {
+			out.MaxActivitiesPerDay = maxPerDay
+		}
+		if buffer > out.BufferMinutes {
+			out.BufferMinutes = buffer
+		}
+	}
+
+	if n.Toddlers > 0 {
+		tighten(3, 30)
+		nap := timeWindow{From: 12*60 + 30, To: 14*60 + 30}
+		out.Forbidden = append(out.Forbidden, nap)
+		out.Notes = append(out.Notes, fmt.Sprintf("Traveling with a toddler: keep %s free for a nap, prefer stroller-friendly places.", nap))
+	}
+	if n.Kids > 0 {
+		tighten(4, 20)
+		out.Notes = append(out.Notes, "Traveling with children: prefer interactive, family-friendly places and no bars or nightlife.")
+	}
+	if n.Seniors > 0 {
+		tighten(4, 30)
+		out.Notes = append(out.Notes, "Traveling with seniors: avoid strenuous hikes and long walks, keep the pace relaxed.")
+	}
+	return out
+}
+
+// filterForCompanions drops places that do not suit the group, unless too few would remain.
+func filterForCompanions(pois []*db_models.POI, n CompanionNeeds) []*db_models.POI {
+	noAdultOnly := n.Toddlers+n.Kids > 0
+	noStrenuous := n.Toddlers+n.Seniors > 0
+	if !noAdultOnly && !noStrenuous {
+		return pois
+	}
+	kept := make([]*db_models.POI, 0, len(pois))
+	for _, p := range pois {
+		text := poiKeywordText(p)
+		if noAdultOnly && hasKeyword(text, adultOnlyKeywords) {
+			continue
+		}
+		if noStrenuous && hasKeyword(text, strenuousKeywords) {
+			continue
+		}
+		kept = append(kept, p)
+	}
+	if len(kept) < minCompanionCandidates && len(kept) < len(pois) {
+		return pois
+	}
+	return kept
+}
+
+// Tips are practical hints for the group, shown with the plan.
+func (n CompanionNeeds) Tips() []string {
+	var out []string
+	if n.Toddlers > 0 {
+		out = append(out,
+			"Plan the midday block around nap time; most cafés and malls have quiet corners if you get stuck.",
+			"Bring a foldable stroller: sidewalks are often busy with motorbikes, so a baby carrier helps too.",
+			"Ask ride-hailing apps for a 7-seat car; child seats are rare, so consider bringing a travel booster.")
+	}
+	if n.Kids > 0 {
+		out = append(out,
+			"Many attractions have reduced tickets for children under 1.4 m; carry their passports or birth certificates.",
+			"Keep water and snacks at hand, and plan an indoor break during the hottest hours.")
+	}
+	if n.Seniors > 0 {
+		out = append(out,
+			"Book ground-floor or elevator rooms; many mini-hotels only have stairs.",
+			"Pagodas and viewpoints often involve steep steps: check access or plan a rest nearby.")
+	}
+	return out
+}
+
+// PromptLines is the companion section of free-text prompts.
+func (n CompanionNeeds) PromptLines() []string {
+	if n.empty() {
+		return nil
+	}
+	return []string{"Companions: " + strings.Join(n.people, "; ")}
+}
+
+// resolveCompanions reads the saved companions unless the quiz says to leave them home.
+func (p *PromptService) resolveCompanions(ctx context.Context, answers map[string]string, userId string) CompanionNeeds {
+	if strings.EqualFold(strings.TrimSpace(answers["companions"]), companionsNone) || userId == "" {
+		return CompanionNeeds{}
+	}
+	acc, err := p.accountSerivce.GetProfileInfo(ctx, userId)
+	if err != nil {
+		return CompanionNeeds{}
+	}
+	return companionNeeds(acc.Companions)
+}
+
+func companionsQuestion() request_models.QuizQuestion {
+	return request_models.QuizQuestion{
+		ID:       "companions",
+		Question: "Who's coming along? 👨‍👩‍👧 (set your companions in your profile)",
+		Type:     "single_choice",
+		Options:  []string{companionsFromProfile, companionsNone},
+		Required: false,
+		Category: "party",
+	}
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"vivu/pkg/utils"
+)
+
+// CoordinateBounds is the loose bounding box POI coordinates must fall in, offshore islands
+// included. Points outside it are almost always swapped lat/lng or a missing minus sign.
+type CoordinateBounds struct {
+	Country                        string // shown in errors, e.g. "Vietnam"
+	MinLat, MaxLat, MinLng, MaxLng float64
+}
+
+// countryBounds are the presets POI_COUNTRY picks from, by ISO 3166 code.
+var countryBounds = map[string]CoordinateBounds{
+	"VN": {Country: "Vietnam", MinLat: 8.0, MaxLat: 23.5, MinLng: 102.0, MaxLng: 117.5},
+	"LA": {Country: "Laos", MinLat: 13.9, MaxLat: 22.5, MinLng: 100.0, MaxLng: 107.7},
+	"KH": {Country: "Cambodia", MinLat: 9.9, MaxLat: 14.7, MinLng: 102.3, MaxLng: 107.7},
+	"TH": {Country: "Thailand", MinLat: 5.6, MaxLat: 20.5, MinLng: 97.3, MaxLng: 105.7},
+}
+
+// CoordinateBoundsFromEnv returns the preset of POI_COUNTRY (default VN), or the box in
+// POI_BOUNDS ("minLat,minLng,maxLat,maxLng") when set.
+func CoordinateBoundsFromEnv() CoordinateBounds {
+	bounds := countryBounds["VN"]
+	if code := strings.ToUpper(strings.TrimSpace(os.Getenv("POI_COUNTRY"))); code != "" {
+		if b, ok := countryBounds[code]; ok {
+			bounds = b
+		} else {
+			log.Printf("[coordinates] unknown POI_COUNTRY %q, using Vietnam", code)
+		}
+	}
+	if raw := os.Getenv("POI_BOUNDS"); raw != "" {
+		b, err := parseCoordinateBounds(raw)
+		if err != nil {
+			log.Printf("[coordinates] ignoring POI_BOUNDS: %v", err)
+		} else {
+			b.Country = bounds.Country
+			bounds = b
+		}
+	}
+	return bounds
+}
+
+func parseCoordinateBounds(raw string) (CoordinateBounds, error) {
+	parts := strings.Split(raw, ",")
+	if len(parts) != 4 {
+		return CoordinateBounds{}, fmt.Errorf("want minLat,minLng,maxLat,maxLng")
+	}
+	var v [4]float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return CoordinateBounds{}, err
+		}
+		v[i] = f
+	}
+	b := CoordinateBounds{MinLat: v[0], MinLng: v[1], MaxLat: v[2], MaxLng: v[3]}
+	if b.MinLat >= b.MaxLat || b.MinLng >= b.MaxLng || b.MinLat < -90 || b.MaxLat > 90 || b.MinLng < -180 || b.MaxLng > 180 {
+		return CoordinateBounds{}, fmt.Errorf("empty or out of range box")
+	}
+	return b, nil
+}
+
+func (b CoordinateBounds) contains(lat, lng float64) bool {
+	return lat >= b.MinLat && lat <= b.MaxLat && lng >= b.MinLng && lng <= b.MaxLng
+}
+
+// Validate rejects coordinates that cannot be a POI in the country: out of range, 0,0,
+// swapped, or outside the box.
+func (b CoordinateBounds) Validate(lat, lng float64) error {
+	switch {
+	case math.IsNaN(lat) || math.IsNaN(lng) || lat < -90 || lat > 90 || lng < -180 || lng > 180:
+		if b.contains(lng, lat) {
+			return utils.ErrCoordinatesSwapped
+		}
+		return utils.ErrCoordinatesInvalid
+	case lat == 0 && lng == 0:
+		return utils.ErrCoordinatesZero
+	case b.contains(lat, lng):
+		return nil
+	case b.contains(lng, lat):
+		return utils.ErrCoordinatesSwapped
+	}
+	return utils.ErrCoordinatesOutOfBounds.WithMessage("Coordinates are outside " + b.Country)
+}
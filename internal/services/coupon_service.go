@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+var couponCodePattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9_-]*$`)
+
+type CouponServiceInterface interface {
+	// Validate tells whether the coupon can be used now and, when planCode is given, what the
+	// plan costs with it.
+	Validate(ctx context.Context, code, planCode string) (*response_models.Coupon, error)
+
+	ListCoupons(ctx context.Context) ([]response_models.AdminCoupon, error)
+	CreateCoupon(ctx context.Context, req request_models.CreateCouponRequest, createdBy string) (*response_models.AdminCoupon, error)
+}
+
+type CouponService struct {
+	repo     repositories.CouponRepositoryInterface
+	planRepo repositories.IPlanRepository
+}
+
+func NewCouponService(repo repositories.CouponRepositoryInterface, planRepo repositories.IPlanRepository) CouponServiceInterface {
+	return &CouponService{repo: repo, planRepo: planRepo}
+}
+
+// normalizeCouponCode is how codes are stored and looked up: customers type them in any case.
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// couponUsable reports why the coupon cannot be redeemed at now, if it cannot.
+func couponUsable(c *db_models.Coupon, now int64) error {
+	switch {
+	case !c.IsActive:
+		return utils.ErrCouponInvalid.WithMessage("This coupon is no longer available")
+	case c.ExpiresAt != nil && *c.ExpiresAt <= now:
+		return utils.ErrCouponInvalid.WithMessage("This coupon has expired")
+	case c.MaxRedemptions != nil && c.Redemptions >= *c.MaxRedemptions:
+		return utils.ErrCouponInvalid.WithMessage("This coupon has been fully redeemed")
+	}
+	return nil
+}
+
+// couponDiscount is what the coupon takes off the plan's price, never more than the price.
+func couponDiscount(c *db_models.Coupon, plan *db_models.Plan) (int64, error) {
+	if len(c.PlanCodes) > 0 {
+		allowed := false
+		for _, code := range c.PlanCodes {
+			allowed = allowed || code == plan.Code
+		}
+		if !allowed {
+			return 0, utils.ErrCouponInvalid.WithMessage("This coupon does not apply to this plan")
+		}
+	}
+
+	var off int64
+	switch c.Kind {
+	case db_models.CouponPercent:
+		off = plan.PriceMinor * int64(c.PercentOff) / 100
+	case db_models.CouponFixed:
+		if !strings.EqualFold(c.Currency, plan.Currency) {
+			return 0, utils.ErrCouponInvalid.WithMessage("This coupon does not apply to plans in " + strings.ToUpper(plan.Currency))
+		}
+		off = c.AmountOffMinor
+	}
+	return min(off, plan.PriceMinor), nil
+}
+
+func (s *CouponService) Validate(ctx context.Context, code, planCode string) (*response_models.Coupon, error) {
+	coupon, err := s.repo.FindByCode(ctx, normalizeCouponCode(code))
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if coupon == nil {
+		return nil, utils.ErrCouponInvalid.WithMessage("This coupon does not exist")
+	}
+	if err := couponUsable(coupon, time.Now().Unix()); err != nil {
+		return nil, err
+	}
+
+	out := &response_models.Coupon{
+		Code:           coupon.Code,
+		Kind:           coupon.Kind,
+		PercentOff:     coupon.PercentOff,
+		AmountOffMinor: coupon.AmountOffMinor,
+		Currency:       coupon.Currency,
+		PlanCodes:      coupon.PlanCodes,
+	}
+	if coupon.ExpiresAt != nil {
+		out.ExpiresAt = utils.FormatRFC3339VN(utils.FromUnixSecondsVN(*coupon.ExpiresAt))
+	}
+	if planCode == "" {
+		return out, nil
+	}
+
+	plans, err := s.planRepo.GetAllPlans(ctx)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	for i := range plans {
+		if plans[i].Code != planCode || !plans[i].IsActive {
+			continue
+		}
+		off, err := couponDiscount(coupon, &plans[i])
+		if err != nil {
+			return nil, err
+		}
+		amount := plans[i].PriceMinor - off
+		out.PlanCode = planCode
+		out.PriceMinor = plans[i].PriceMinor
+		out.DiscountMinor = off
+		out.AmountMinor = &amount
+		return out, nil
+	}
+	return nil, utils.RecordNotFound.WithMessage("This plan is not available")
+}
+
+func (s *CouponService) ListCoupons(ctx context.Context) ([]response_models.AdminCoupon, error) {
+	rows, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	out := make([]response_models.AdminCoupon, 0, len(rows))
+	for i := range rows {
+		out = append(out, toAdminCoupon(&rows[i]))
+	}
+	return out, nil
+}
+
+func (s *CouponService) CreateCoupon(ctx context.Context, req request_models.CreateCouponRequest, createdBy string) (*response_models.AdminCoupon, error) {
+	code := normalizeCouponCode(req.Code)
+	if !couponCodePattern.MatchString(code) {
+		return nil, utils.ErrInvalidInput.WithMessage("Coupon codes use letters, digits, - and _")
+	}
+
+	coupon := &db_models.Coupon{
+		Code:           code,
+		MaxRedemptions: req.MaxRedemptions,
+		PlanCodes:      pq.StringArray(uniqueStrings(req.PlanCodes)),
+		IsActive:       true,
+		CreatedBy:      createdBy,
+	}
+	switch {
+	case req.PercentOff > 0 && req.AmountOffMinor > 0:
+		return nil, utils.ErrInvalidInput.WithMessage("Give either percent_off or amount_off_minor, not both")
+	case req.PercentOff > 0:
+		coupon.Kind = db_models.CouponPercent
+		coupon.PercentOff = req.PercentOff
+	case req.AmountOffMinor > 0:
+		if req.Currency == "" {
+			return nil, utils.ErrInvalidInput.WithMessage("A fixed amount coupon needs a currency")
+		}
+		coupon.Kind = db_models.CouponFixed
+		coupon.AmountOffMinor = req.AmountOffMinor
+		coupon.Currency = strings.ToUpper(req.Currency)
+	default:
+		return nil, utils.ErrInvalidInput.WithMessage("Give percent_off or amount_off_minor")
+	}
+	if req.ExpiresAt != "" {
+		t, err := time.Parse(time.RFC3339, req.ExpiresAt)
+		if err != nil {
+			return nil, utils.ErrInvalidInput.WithMessage("expires_at must be an RFC3339 time")
+		}
+		expiresAt := t.Unix()
+		coupon.ExpiresAt = &expiresAt
+	}
+
+	exists, err := s.repo.CodeExists(ctx, code)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if exists {
+		return nil, utils.ErrCouponCodeTaken
+	}
+	if err := s.repo.Create(ctx, coupon); err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	out := toAdminCoupon(coupon)
+	return &out, nil
+}
+
+func toAdminCoupon(c *db_models.Coupon) response_models.AdminCoupon {
+	out := response_models.AdminCoupon{
+		ID:             c.ID.String(),
+		Code:           c.Code,
+		Kind:           c.Kind,
+		PercentOff:     c.PercentOff,
+		AmountOffMinor: c.AmountOffMinor,
+		Currency:       c.Currency,
+		MaxRedemptions: c.MaxRedemptions,
+		Redemptions:    c.Redemptions,
+		PlanCodes:      c.PlanCodes,
+		IsActive:       c.IsActive,
+		CreatedBy:      c.CreatedBy,
+		CreatedAt:      utils.FormatRFC3339VN(utils.FromUnixSecondsVN(c.CreatedAt)),
+	}
+	if out.PlanCodes == nil {
+		out.PlanCodes = []string{}
+	}
+	if c.ExpiresAt != nil {
+		out.ExpiresAt = utils.FormatRFC3339VN(utils.FromUnixSecondsVN(*c.ExpiresAt))
+	}
+	return out
+}
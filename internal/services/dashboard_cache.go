@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	resp "vivu/internal/models/response_models"
+)
+
+// dashboardCacheMaxEntries bounds the reports kept; past it the cache starts over.
+const dashboardCacheMaxEntries = 256
+
+// DashboardCache keeps built dashboard reports in memory for a short TTL. It listens on the
+// event bus and drops every report when a payment or subscription changes, so the revenue
+// figures never lag behind a payment on this instance.
+type DashboardCache struct {
+	next DashboardService
+	ttl  time.Duration
+
+	mu         sync.Mutex
+	entries    map[string]dashboardCacheEntry
+	generation uint64 // bumped on every bust
+}
+
+type dashboardCacheEntry struct {
+	report    *resp.DashboardReport
+	expiresAt time.Time
+}
+
+// NewDashboardCache caches the reports of next for ttl; a ttl of 0 turns caching off.
+func NewDashboardCache(next DashboardService, ttl time.Duration) *DashboardCache {
+	return &DashboardCache{next: next, ttl: ttl, entries: make(map[string]dashboardCacheEntry)}
+}
+
+// dashboardCacheKey is the range as requested, before presets and open ends resolve to "now".
+// Relative ranges ("last 30 days") arrive already ending now, so their ends are rounded down to
+// the TTL: requests within one TTL share a report.
+func dashboardCacheKey(rng resp.TimeRange, currency string, ttl time.Duration) string {
+	return fmt.Sprintf("%d|%d|%s|%s|%s|%s|%s",
+		rng.Start.Truncate(ttl).Unix(), rng.End.Truncate(ttl).Unix(), rng.Interval, rng.Timezone, rng.Preset, rng.Compare, currency)
+}
+
+func (c *DashboardCache) BuildDashboard(ctx context.Context, rng resp.TimeRange, currency string) (*resp.DashboardReport, error) {
+	if c.ttl <= 0 {
+		return c.next.BuildDashboard(ctx, rng, currency)
+	}
+	key := dashboardCacheKey(rng, currency, c.ttl)
+
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok && time.Now().Before(e.expiresAt) {
+		c.mu.Unlock()
+		return e.report, nil
+	}
+	generation := c.generation
+	c.mu.Unlock()
+
+	report, err := c.next.BuildDashboard(ctx, rng, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// A bust while the report was built means it may already miss a payment
+	if c.generation != generation {
+		return report, nil
+	}
+	now := time.Now()
+	if len(c.entries) >= dashboardCacheMaxEntries {
+		for k, e := range c.entries {
+			if !now.Before(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= dashboardCacheMaxEntries {
+			c.entries = make(map[string]dashboardCacheEntry)
+		}
+	}
+	c.entries[key] = dashboardCacheEntry{report: report, expiresAt: now.Add(c.ttl)}
+	return report, nil
+}
+
+// BuildCohorts is not cached; the report is asked for rarely.
+func (c *DashboardCache) BuildCohorts(ctx context.Context, months int, metric, tz string) (*resp.CohortReport, error) {
+	return c.next.BuildCohorts(ctx, months, metric, tz)
+}
+
+// Invalidate drops every cached report.
+func (c *DashboardCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]dashboardCacheEntry)
+	c.generation++
+}
+
+// Publish busts the cache on the events that move revenue or subscription counts.
+func (c *DashboardCache) Publish(ctx context.Context, accountID uuid.UUID, eventType string, data any) {
+	switch eventType {
+	case EventSubscriptionActivated, EventSubscriptionPastDue, EventSubscriptionExpired, EventPaymentRefunded:
+		c.Invalidate()
+	}
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	resp "vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+)
+
+// Bounds of the signup months a cohort report covers.
+const (
+	DashboardCohortMonthsDefault = 12
+	DashboardCohortMonthsMax     = 36
+)
+
+func ValidCohortMetric(m string) bool {
+	switch repositories.CohortActivity(m) {
+	case repositories.CohortBySubscription, repositories.CohortByJourneys:
+		return true
+	}
+	return false
+}
+
+// cohortMonth numbers months so two of them can be subtracted.
+func cohortMonth(t time.Time) int {
+	return t.Year()*12 + int(t.Month()) - 1
+}
+
+func (s *dashboardService) BuildCohorts(ctx context.Context, months int, metric, tz string) (*resp.CohortReport, error) {
+	if months <= 0 {
+		months = DashboardCohortMonthsDefault
+	}
+	if months > DashboardCohortMonthsMax {
+		months = DashboardCohortMonthsMax
+	}
+	if metric == "" {
+		metric = string(repositories.CohortBySubscription)
+	}
+	loc := dashboardLocation(tz)
+	end := time.Now().In(loc)
+	start := time.Date(end.Year(), end.Month()-time.Month(months-1), 1, 0, 0, 0, 0, loc)
+
+	g, gctx := errgroup.WithContext(ctx)
+	var sizeRows []repositories.BucketSum
+	var cellRows []repositories.CohortCellRow
+	g.Go(func() (err error) { sizeRows, err = s.repo.SignupCohorts(gctx, start, end, tz); return })
+	g.Go(func() (err error) {
+		cellRows, err = s.repo.CohortRetention(gctx, start, end, tz, repositories.CohortActivity(metric))
+		return
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	// The buckets come back as local wall-clock times; only their year and month are read
+	first, last := cohortMonth(start), cohortMonth(end)
+	rows := make([]resp.CohortRow, months)
+	for i := range rows {
+		rows[i] = resp.CohortRow{
+			Cohort:       time.Date(start.Year(), start.Month()+time.Month(i), 1, 0, 0, 0, 0, loc),
+			Retained:     make([]int64, last-first-i+1),
+			RetentionPct: make([]float64, last-first-i+1),
+		}
+	}
+	for _, r := range sizeRows {
+		if i := cohortMonth(r.Bucket) - first; i >= 0 && i < months {
+			rows[i].Size = r.Sum
+		}
+	}
+	for _, r := range cellRows {
+		i, k := cohortMonth(r.Cohort)-first, cohortMonth(r.Month)-cohortMonth(r.Cohort)
+		if i < 0 || i >= months || k < 0 || k >= len(rows[i].Retained) {
+			continue
+		}
+		rows[i].Retained[k] = r.Retained
+	}
+	for i := range rows {
+		if rows[i].Size == 0 {
+			continue
+		}
+		for k, n := range rows[i].Retained {
+			rows[i].RetentionPct[k] = math.Round(float64(n)/float64(rows[i].Size)*10000) / 100
+		}
+	}
+
+	return &resp.CohortReport{
+		Metric:   metric,
+		Timezone: loc.String(),
+		Start:    start,
+		End:      end,
+		Cohorts:  rows,
+	}, nil
+}
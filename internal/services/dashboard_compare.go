@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	dbm "vivu/internal/models/db_models"
+	resp "vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+)
+
+// Named dashboard ranges, from the start of the month, quarter or year until now.
+const (
+	DashboardPresetMTD = "mtd"
+	DashboardPresetQTD = "qtd"
+	DashboardPresetYTD = "ytd"
+)
+
+// What a dashboard range can be compared with.
+const (
+	DashboardComparePreviousPeriod = "previous_period"
+	DashboardComparePreviousYear   = "previous_year"
+	DashboardCompareNone           = "none" // opts out of the default previous-period comparison
+)
+
+func ValidDashboardPreset(p string) bool {
+	return p == DashboardPresetMTD || p == DashboardPresetQTD || p == DashboardPresetYTD
+}
+
+func ValidDashboardCompare(c string) bool {
+	return c == DashboardComparePreviousPeriod || c == DashboardComparePreviousYear || c == DashboardCompareNone
+}
+
+// dashboardLocation is the timezone a range is read in; UTC when none or an unknown one is set.
+func dashboardLocation(tz string) *time.Location {
+	if tz == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// resolveDashboardPreset turns a preset into a range ending now. The month, quarter or year
+// starts at midnight in loc, so "this month" is the destination's month and not UTC's.
+func resolveDashboardPreset(preset string, loc *time.Location, now time.Time) (time.Time, time.Time) {
+	local := now.In(loc)
+	y, m, _ := local.Date()
+	var start time.Time
+	switch preset {
+	case DashboardPresetQTD:
+		start = time.Date(y, (m-1)/3*3+1, 1, 0, 0, 0, 0, loc)
+	case DashboardPresetYTD:
+		start = time.Date(y, time.January, 1, 0, 0, 0, 0, loc)
+	default:
+		start = time.Date(y, m, 1, 0, 0, 0, 0, loc)
+	}
+	return start.UTC(), now.UTC()
+}
+
+// rangeShift moves a time back to where it falls in the comparison range: by whole calendar
+// months, or by a fixed duration for a custom range.
+type rangeShift struct {
+	months int
+	by     time.Duration
+}
+
+func (s rangeShift) apply(t time.Time, loc *time.Location) time.Time {
+	if s.months == 0 {
+		return t.Add(-s.by)
+	}
+	return addMonthsClamped(t.In(loc), -s.months).UTC()
+}
+
+// addMonthsClamped adds months keeping the day of the month where the target month has it:
+// 31 March minus one month is 28 (or 29) February, not 3 March.
+func addMonthsClamped(t time.Time, months int) time.Time {
+	y, m, d := t.Date()
+	first := time.Date(y, m+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); d > last {
+		d = last
+	}
+	return first.AddDate(0, 0, d-1)
+}
+
+// comparisonRange is the range r is compared with. The previous period of a preset is the same
+// stretch of the previous month, quarter or year (1-16 October against 1-16 September); that
+// of a custom range is as long and ends just before it starts.
+func comparisonRange(r resp.TimeRange, loc *time.Location) (resp.TimeRange, rangeShift) {
+	var shift rangeShift
+	switch {
+	case r.Compare == DashboardComparePreviousYear:
+		shift.months = 12
+	case r.Preset == DashboardPresetMTD:
+		shift.months = 1
+	case r.Preset == DashboardPresetQTD:
+		shift.months = 3
+	case r.Preset == DashboardPresetYTD:
+		shift.months = 12
+	default:
+		shift.by = r.End.Sub(r.Start) + time.Second
+	}
+	prev := r
+	prev.Start = shift.apply(r.Start, loc)
+	prev.End = shift.apply(r.End, loc)
+	prev.Compare = ""
+	return prev, shift
+}
+
+// truncBucket truncates a bucket time the way date_trunc does; buckets hold local wall-clock
+// times in UTC.
+func truncBucket(t time.Time, interval string) time.Time {
+	y, m, d := t.Date()
+	switch interval {
+	case "month":
+		return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	case "week":
+		return time.Date(y, m, d-(int(t.Weekday())+6)%7, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// deltaPct is the change from prev to cur in percent, rounded to two decimals; nil when prev is 0.
+func deltaPct(cur, prev float64) *float64 {
+	if prev == 0 {
+		return nil
+	}
+	v := math.Round((cur-prev)/prev*10000) / 100
+	return &v
+}
+
+// compareSeries sets the previous value and the delta of every point from the rows of the
+// comparison range, and returns the previous total.
+func compareSeries(points []resp.SeriesPoint, prevRows []repositories.BucketSum, shift rangeShift, interval string) int64 {
+	prev := make(map[time.Time]int64, len(prevRows))
+	var total int64
+	for _, r := range prevRows {
+		prev[truncBucket(r.Bucket, interval)] += r.Sum
+		total += r.Sum
+	}
+	for i := range points {
+		v := prev[truncBucket(shift.apply(points[i].Bucket, time.UTC), interval)]
+		points[i].Previous = &v
+		points[i].DeltaPct = deltaPct(float64(points[i].Value), float64(v))
+	}
+	return total
+}
+
+// comparableKPIs computes the KPIs that can be had for any range: point-in-time ones at its
+// end, the others over it. Status counts are left out; only their current value is known.
+func (s *dashboardService) comparableKPIs(ctx context.Context, rng resp.TimeRange) (resp.KPIBlock, error) {
+	var k resp.KPIBlock
+	var err error
+	if k.TotalAccounts, err = s.repo.CountCreatedUntil(ctx, &dbm.Account{}, rng.End); err != nil {
+		return k, err
+	}
+	if k.NewAccounts, err = s.repo.CountNewAccounts(ctx, rng.Start, rng.End); err != nil {
+		return k, err
+	}
+	if k.TotalJourneys, err = s.repo.CountCreatedUntil(ctx, &dbm.Journey{}, rng.End); err != nil {
+		return k, err
+	}
+	if k.TotalActivities, err = s.repo.CountCreatedUntil(ctx, &dbm.JourneyActivity{}, rng.End); err != nil {
+		return k, err
+	}
+
+	subs, err := s.repo.SubscriptionsWithPlanAt(ctx, rng.End)
+	if err != nil {
+		return k, err
+	}
+	for _, sub := range subs {
+		k.MRRMinor += monthlyEquivalent(sub.PriceMinor, sub.Period)
+	}
+	k.ActiveSubscriptions = int64(len(subs))
+	k.ARRMinor = k.MRRMinor * 12
+	if k.ActiveSubscriptions > 0 {
+		k.ARPUMinor = float64(k.MRRMinor) / float64(k.ActiveSubscriptions)
+	}
+
+	k.ChurnPct, err = s.churnPct(ctx, rng)
+	return k, err
+}
+
+// compare fills the comparison block and the series deltas of a report.
+func (s *dashboardService) compare(ctx context.Context, rng resp.TimeRange, report *resp.DashboardReport) error {
+	prevRange, shift := comparisonRange(rng, dashboardLocation(rng.Timezone))
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(dashboardQueryConcurrency)
+	var cur, prev resp.KPIBlock
+	var revenueRows, newUsersRows, newSubsRows []repositories.BucketSum
+	g.Go(func() (err error) { cur, err = s.comparableKPIs(gctx, rng); return })
+	g.Go(func() (err error) { prev, err = s.comparableKPIs(gctx, prevRange); return })
+	g.Go(func() (err error) {
+		revenueRows, err = s.repo.RevenueSeries(gctx, prevRange.Start, prevRange.End, rng.Interval, rng.Timezone)
+		return
+	})
+	g.Go(func() (err error) {
+		newUsersRows, err = s.repo.NewUsersSeries(gctx, prevRange.Start, prevRange.End, rng.Interval, rng.Timezone)
+		return
+	})
+	g.Go(func() (err error) {
+		newSubsRows, err = s.repo.NewSubsSeries(gctx, prevRange.Start, prevRange.End, rng.Interval, rng.Timezone)
+		return
+	})
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	revenueTotal := compareSeries(report.Revenue.Points, revenueRows, shift, rng.Interval)
+	report.Revenue.PreviousTotalMinor = &revenueTotal
+	report.Revenue.TotalDeltaPct = deltaPct(float64(report.Revenue.TotalMinor), float64(revenueTotal))
+	for _, c := range []struct {
+		series *resp.CountSeries
+		rows   []repositories.BucketSum
+	}{{&report.NewUsers, newUsersRows}, {&report.NewSubs, newSubsRows}} {
+		total := compareSeries(c.series.Points, c.rows, shift, rng.Interval)
+		c.series.PreviousTotal = &total
+		c.series.TotalDeltaPct = deltaPct(float64(c.series.Total), float64(total))
+	}
+
+	report.Comparison = &resp.DashboardComparison{
+		Range: prevRange,
+		Label: comparisonLabel(rng),
+		KPIs:  prev,
+		Deltas: resp.KPIDeltas{
+			TotalAccounts:       deltaPct(float64(cur.TotalAccounts), float64(prev.TotalAccounts)),
+			NewAccounts:         deltaPct(float64(cur.NewAccounts), float64(prev.NewAccounts)),
+			TotalJourneys:       deltaPct(float64(cur.TotalJourneys), float64(prev.TotalJourneys)),
+			TotalActivities:     deltaPct(float64(cur.TotalActivities), float64(prev.TotalActivities)),
+			ActiveSubscriptions: deltaPct(float64(cur.ActiveSubscriptions), float64(prev.ActiveSubscriptions)),
+			MRRMinor:            deltaPct(float64(cur.MRRMinor), float64(prev.MRRMinor)),
+			ARRMinor:            deltaPct(float64(cur.ARRMinor), float64(prev.ARRMinor)),
+			ARPUMinor:           deltaPct(cur.ARPUMinor, prev.ARPUMinor),
+			ChurnPct:            deltaPct(cur.ChurnPct, prev.ChurnPct),
+		},
+		Headline: []resp.DashboardHeadline{
+			headline("revenue_minor", float64(report.Revenue.TotalMinor), float64(revenueTotal)),
+			headline("new_accounts", float64(cur.NewAccounts), float64(prev.NewAccounts)),
+			headline("new_subscriptions", float64(report.NewSubs.Total), float64(*report.NewSubs.PreviousTotal)),
+			headline("churn_pct", cur.ChurnPct, prev.ChurnPct),
+		},
+	}
+	return nil
+}
+
+func headline(metric string, cur, prev float64) resp.DashboardHeadline {
+	return resp.DashboardHeadline{
+		Metric:   metric,
+		Current:  cur,
+		Previous: prev,
+		Change:   math.Round((cur-prev)*100) / 100,
+		DeltaPct: deltaPct(cur, prev),
+	}
+}
+
+// comparisonLabel names the comparison range of r the way the admin UI shows it.
+func comparisonLabel(r resp.TimeRange) string {
+	switch {
+	case r.Compare == DashboardComparePreviousYear:
+		return "same period last year"
+	case r.Preset == DashboardPresetMTD:
+		return "previous month to date"
+	case r.Preset == DashboardPresetQTD:
+		return "previous quarter to date"
+	case r.Preset == DashboardPresetYTD:
+		return "previous year to date"
+	}
+	days := int(math.Round(r.End.Sub(r.Start).Hours() / 24))
+	switch {
+	case days < 1:
+		return "previous period"
+	case days == 1:
+		return "previous day"
+	default:
+		return fmt.Sprintf("previous %d days", days)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
 
 	dbm "vivu/internal/models/db_models"
 	resp "vivu/internal/models/response_models"
@@ -14,8 +15,15 @@ import (
 
 type DashboardService interface {
 	BuildDashboard(ctx context.Context, rng resp.TimeRange, currency string) (*resp.DashboardReport, error)
+	// BuildCohorts reports, for the accounts signed up in each of the last months months in tz,
+	// the share still retained by metric ("subscription" or "journeys") in every month since.
+	BuildCohorts(ctx context.Context, months int, metric, tz string) (*resp.CohortReport, error)
 }
 
+// dashboardQueryConcurrency caps the queries one dashboard runs at once, so a report does not
+// take over the connection pool.
+const dashboardQueryConcurrency = 4
+
 type dashboardService struct {
 	repo repositories.DashboardRepository
 }
@@ -30,6 +38,9 @@ func normalizeRange(r resp.TimeRange) resp.TimeRange {
 	if out.Interval == "" {
 		out.Interval = "day"
 	}
+	if out.Preset != "" {
+		out.Start, out.End = resolveDashboardPreset(out.Preset, dashboardLocation(out.Timezone), time.Now())
+	}
 	if out.End.IsZero() {
 		out.End = time.Now().UTC()
 	}
@@ -57,49 +68,61 @@ func monthlyEquivalent(priceMinor int64, period string) int64 {
 func (s *dashboardService) BuildDashboard(ctx context.Context, rng resp.TimeRange, currency string) (*resp.DashboardReport, error) {
 	rng = normalizeRange(rng)
 
+	// The queries are independent of each other; run a few at a time instead of one by one
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(dashboardQueryConcurrency)
+
 	// ---------- Core counts ----------
-	totalAccounts, err := s.repo.CountTotalAccounts(ctx)
-	if err != nil {
-		return nil, err
-	}
+	var totalAccounts, newAccounts, totalJourneys, totalActivities int64
+	g.Go(func() (err error) { totalAccounts, err = s.repo.CountTotalAccounts(gctx); return })
+	g.Go(func() (err error) { newAccounts, err = s.repo.CountNewAccounts(gctx, rng.Start, rng.End); return })
+	g.Go(func() (err error) { totalJourneys, err = s.repo.CountTotalJourneys(gctx); return })
+	g.Go(func() (err error) { totalActivities, err = s.repo.CountTotalActivities(gctx); return })
 
-	newAccounts, err := s.repo.CountNewAccounts(ctx, rng.Start, rng.End)
-	if err != nil {
-		return nil, err
+	var activeSubs, trialSubs, canceledSubs, expiredSubs, pausedSubs int64
+	for status, dst := range map[dbm.SubscriptionStatus]*int64{
+		dbm.SubStatusActive:   &activeSubs,
+		dbm.SubStatusTrialing: &trialSubs,
+		dbm.SubStatusCanceled: &canceledSubs,
+		dbm.SubStatusExpired:  &expiredSubs,
+		dbm.SubStatusPaused:   &pausedSubs,
+	} {
+		g.Go(func() (err error) { *dst, err = s.repo.CountSubscriptionsByStatus(gctx, status); return })
 	}
 
-	totalJourneys, err := s.repo.CountTotalJourneys(ctx)
-	if err != nil {
-		return nil, err
-	}
+	// ---------- Series, plans, destinations, payments ----------
+	var revenueRows, newUsersRows, newSubsRows []repositories.BucketSum
+	g.Go(func() (err error) {
+		revenueRows, err = s.repo.RevenueSeries(gctx, rng.Start, rng.End, rng.Interval, rng.Timezone)
+		return
+	})
+	g.Go(func() (err error) {
+		newUsersRows, err = s.repo.NewUsersSeries(gctx, rng.Start, rng.End, rng.Interval, rng.Timezone)
+		return
+	})
+	g.Go(func() (err error) {
+		newSubsRows, err = s.repo.NewSubsSeries(gctx, rng.Start, rng.End, rng.Interval, rng.Timezone)
+		return
+	})
 
-	totalActivities, err := s.repo.CountTotalActivities(ctx)
-	if err != nil {
-		return nil, err
-	}
+	var activeWithPlan []repositories.SubWithPlan
+	var churnPct float64
+	var planRows []repositories.PlanMixRow
+	var locRows []repositories.LocationRow
+	var payRows []repositories.RecentPaymentRow
+	var changeRows []repositories.PlanChangeRow
+	g.Go(func() (err error) { activeWithPlan, err = s.repo.ActiveSubscriptionsWithPlan(gctx); return })
+	g.Go(func() (err error) { churnPct, err = s.churnPct(gctx, rng); return })
+	g.Go(func() (err error) { planRows, err = s.repo.PlanMix(gctx); return })
+	g.Go(func() (err error) { locRows, err = s.repo.TopDestinations(gctx, rng.Start, rng.End, 10); return })
+	g.Go(func() (err error) { payRows, err = s.repo.RecentPaidTransactions(gctx, 10); return })
+	g.Go(func() (err error) { changeRows, err = s.repo.PlanChanges(gctx, rng.Start, rng.End); return })
 
-	activeSubs, err := s.repo.CountSubscriptionsByStatus(ctx, dbm.SubStatusActive)
-	if err != nil {
-		return nil, err
-	}
-	trialSubs, err := s.repo.CountSubscriptionsByStatus(ctx, dbm.SubStatusTrialing)
-	if err != nil {
-		return nil, err
-	}
-	canceledSubs, err := s.repo.CountSubscriptionsByStatus(ctx, dbm.SubStatusCanceled)
-	if err != nil {
-		return nil, err
-	}
-	expiredSubs, err := s.repo.CountSubscriptionsByStatus(ctx, dbm.SubStatusExpired)
-	if err != nil {
+	if err := g.Wait(); err != nil {
 		return nil, err
 	}
 
 	// ---------- Series ----------
-	revenueRows, err := s.repo.RevenueSeries(ctx, rng.Start, rng.End, rng.Interval, rng.Timezone)
-	if err != nil {
-		return nil, err
-	}
 	var revenuePoints []resp.SeriesPoint
 	var totalRevenue int64
 	for _, r := range revenueRows {
@@ -107,30 +130,21 @@ func (s *dashboardService) BuildDashboard(ctx context.Context, rng resp.TimeRang
 		totalRevenue += r.Sum
 	}
 
-	newUsersRows, err := s.repo.NewUsersSeries(ctx, rng.Start, rng.End, rng.Interval, rng.Timezone)
-	if err != nil {
-		return nil, err
-	}
 	var newUsersPoints []resp.SeriesPoint
+	var totalNewUsers int64
 	for _, r := range newUsersRows {
 		newUsersPoints = append(newUsersPoints, resp.SeriesPoint{Bucket: r.Bucket, Value: r.Sum})
+		totalNewUsers += r.Sum
 	}
 
-	newSubsRows, err := s.repo.NewSubsSeries(ctx, rng.Start, rng.End, rng.Interval, rng.Timezone)
-	if err != nil {
-		return nil, err
-	}
 	var newSubsPoints []resp.SeriesPoint
+	var totalNewSubs int64
 	for _, r := range newSubsRows {
 		newSubsPoints = append(newSubsPoints, resp.SeriesPoint{Bucket: r.Bucket, Value: r.Sum})
+		totalNewSubs += r.Sum
 	}
 
 	// ---------- Financials: MRR/ARR/ARPU ----------
-	activeWithPlan, err := s.repo.ActiveSubscriptionsWithPlan(ctx)
-	if err != nil {
-		return nil, err
-	}
-
 	var mrr int64
 	var activeCount int64
 	for _, srow := range activeWithPlan {
@@ -142,25 +156,7 @@ func (s *dashboardService) BuildDashboard(ctx context.Context, rng resp.TimeRang
 		arpu = float64(mrr) / float64(activeCount)
 	}
 
-	// ---------- Churn ----------
-	canceledInPeriod, err := s.repo.CountCanceledInPeriod(ctx, rng.Start, rng.End)
-	if err != nil {
-		return nil, err
-	}
-	subscribersAtStart, err := s.repo.CountSubscribersAt(ctx, rng.Start)
-	if err != nil {
-		return nil, err
-	}
-	var churnPct float64
-	if subscribersAtStart > 0 {
-		churnPct = (float64(canceledInPeriod) / float64(subscribersAtStart)) * 100.0
-	}
-
 	// ---------- Plan mix ----------
-	planRows, err := s.repo.PlanMix(ctx)
-	if err != nil {
-		return nil, err
-	}
 	var planMixItems []resp.PlanMixItem
 	var totalActive float64
 	for _, r := range planRows {
@@ -183,10 +179,6 @@ func (s *dashboardService) BuildDashboard(ctx context.Context, rng resp.TimeRang
 	}
 
 	// ---------- Top locations ----------
-	locRows, err := s.repo.TopDestinations(ctx, rng.Start, rng.End, 10)
-	if err != nil {
-		return nil, err
-	}
 	var topDestinations []resp.TopDestination
 	for _, r := range locRows {
 		topDestinations = append(topDestinations, resp.TopDestination{
@@ -196,10 +188,6 @@ func (s *dashboardService) BuildDashboard(ctx context.Context, rng resp.TimeRang
 	}
 
 	// ---------- Recent payments ----------
-	payRows, err := s.repo.RecentPaidTransactions(ctx, 10)
-	if err != nil {
-		return nil, err
-	}
 	var recent []resp.RecentPayment
 	for _, r := range payRows {
 		var id uuid.UUID
@@ -222,12 +210,34 @@ func (s *dashboardService) BuildDashboard(ctx context.Context, rng resp.TimeRang
 		})
 	}
 
+	// ---------- Plan changes ----------
+	planChanges := resp.PlanChanges{Flows: make([]resp.PlanChangeFlow, 0, len(changeRows))}
+	for _, r := range changeRows {
+		if r.Direction == dbm.PlanChangeUpgrade {
+			planChanges.Upgrades += r.Count
+		} else {
+			planChanges.Downgrades += r.Count
+		}
+		planChanges.ChargedMinor += r.ChargedMinor
+		planChanges.CreditedMinor += r.CreditedMinor
+		planChanges.Flows = append(planChanges.Flows, resp.PlanChangeFlow{
+			FromPlanCode:  r.FromPlanCode,
+			ToPlanCode:    r.ToPlanCode,
+			Direction:     r.Direction,
+			Count:         r.Count,
+			ChargedMinor:  r.ChargedMinor,
+			CreditedMinor: r.CreditedMinor,
+		})
+	}
+
 	report := &resp.DashboardReport{
 		Range: resp.TimeRange{
 			Start:    rng.Start,
 			End:      rng.End,
 			Interval: rng.Interval,
 			Timezone: rng.Timezone,
+			Preset:   rng.Preset,
+			Compare:  rng.Compare,
 		},
 		KPIs: resp.KPIBlock{
 			TotalAccounts:         totalAccounts,
@@ -238,6 +248,7 @@ func (s *dashboardService) BuildDashboard(ctx context.Context, rng resp.TimeRang
 			TrialingSubscriptions: trialSubs,
 			CanceledSubscriptions: canceledSubs,
 			ExpiredSubscriptions:  expiredSubs,
+			PausedSubscriptions:   pausedSubs,
 
 			MRRMinor:  mrr,
 			ARRMinor:  mrr * 12,
@@ -251,16 +262,41 @@ func (s *dashboardService) BuildDashboard(ctx context.Context, rng resp.TimeRang
 		},
 		NewUsers: resp.CountSeries{
 			Points: newUsersPoints,
+			Total:  totalNewUsers,
 		},
 		NewSubs: resp.CountSeries{
 			Points: newSubsPoints,
+			Total:  totalNewSubs,
 		},
 		PlanMix: resp.PlanMix{
 			Items: planMixItems,
 		},
 		TopDestinations: topDestinations,
 		RecentPayments:  recent,
+		PlanChanges:     planChanges,
+	}
+
+	if rng.Compare != "" {
+		if err := s.compare(ctx, rng, report); err != nil {
+			return nil, err
+		}
 	}
 
 	return report, nil
 }
+
+// churnPct is the share of the subscribers at the start of the range that canceled within it.
+func (s *dashboardService) churnPct(ctx context.Context, rng resp.TimeRange) (float64, error) {
+	canceledInPeriod, err := s.repo.CountCanceledInPeriod(ctx, rng.Start, rng.End)
+	if err != nil {
+		return 0, err
+	}
+	subscribersAtStart, err := s.repo.CountSubscribersAt(ctx, rng.Start)
+	if err != nil {
+		return 0, err
+	}
+	if subscribersAtStart == 0 {
+		return 0, nil
+	}
+	return (float64(canceledInPeriod) / float64(subscribersAtStart)) * 100.0, nil
+}
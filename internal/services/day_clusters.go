@@ -0,0 +1,109 @@
+package services
+
+import (
+	"math"
+
+	"vivu/internal/models/db_models"
+)
+
+const dayClusterIterations = 25
+
+// clusterPOIsByDay groups candidates into one geographic cluster per day (k-means over
+// coordinates) and numbers the clusters so consecutive days are neighbours, starting with the
+// cluster of the best-ranked POI. It returns POI ID -> suggested day; POIs without coordinates
+// get none, and nil means clustering does not apply (one day, or fewer located POIs than days).
+func clusterPOIsByDay(pois []*db_models.POI, days int) map[string]int {
+	var points []*db_models.POI
+	for _, p := range pois {
+		if p != nil && (p.Latitude != 0 || p.Longitude != 0) {
+			points = append(points, p)
+		}
+	}
+	if days < 2 || len(points) < days {
+		return nil
+	}
+
+	// Farthest-point seeding keeps the result deterministic for the same candidates
+	centroids := [][2]float64{{points[0].Latitude, points[0].Longitude}}
+	for len(centroids) < days {
+		far, farDist := 0, -1.0
+		for i, p := range points {
+			if _, d := nearestCentroid(p, centroids); d > farDist {
+				far, farDist = i, d
+			}
+		}
+		centroids = append(centroids, [2]float64{points[far].Latitude, points[far].Longitude})
+	}
+
+	assign := make([]int, len(points))
+	for iter := 0; iter < dayClusterIterations; iter++ {
+		changed := iter == 0
+		for i, p := range points {
+			if c, _ := nearestCentroid(p, centroids); c != assign[i] {
+				assign[i] = c
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+
+		sums := make([][3]float64, days)
+		for i, p := range points {
+			sums[assign[i]][0] += p.Latitude
+			sums[assign[i]][1] += p.Longitude
+			sums[assign[i]][2]++
+		}
+		for c := range centroids {
+			if sums[c][2] > 0 {
+				centroids[c] = [2]float64{sums[c][0] / sums[c][2], sums[c][1] / sums[c][2]}
+				continue
+			}
+			// An empty cluster takes the point worst served by its own centroid
+			worst, worstDist := 0, -1.0
+			for i, p := range points {
+				if d := haversineMeters(p.Latitude, p.Longitude, centroids[assign[i]][0], centroids[assign[i]][1]); d > worstDist {
+					worst, worstDist = i, d
+				}
+			}
+			centroids[c] = [2]float64{points[worst].Latitude, points[worst].Longitude}
+			assign[worst] = c
+		}
+	}
+
+	// Day 1 is the cluster of the top candidate, then always the nearest cluster not yet visited
+	dayOf := make([]int, days)
+	current := assign[0]
+	for d := 1; d <= days; d++ {
+		dayOf[current] = d
+		next, nextDist := -1, math.MaxFloat64
+		for c := range centroids {
+			if dayOf[c] != 0 {
+				continue
+			}
+			if dist := haversineMeters(centroids[current][0], centroids[current][1], centroids[c][0], centroids[c][1]); dist < nextDist {
+				next, nextDist = c, dist
+			}
+		}
+		if next < 0 {
+			break
+		}
+		current = next
+	}
+
+	out := make(map[string]int, len(points))
+	for i, p := range points {
+		out[p.ID.String()] = dayOf[assign[i]]
+	}
+	return out
+}
+
+func nearestCentroid(p *db_models.POI, centroids [][2]float64) (int, float64) {
+	best, bestDist := 0, math.MaxFloat64
+	for c, ctr := range centroids {
+		if d := haversineMeters(p.Latitude, p.Longitude, ctr[0], ctr[1]); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best, bestDist
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+// regenerateDayPoolSize caps the POIs loaded per province of the journey as candidates.
+const regenerateDayPoolSize = 100
+
+// RegenerateJourneyDay replans one day of a saved journey. POIs used on the other days are
+// left out of the candidates and listed to the model, and the answer is checked again, so the
+// whole journey keeps every POI at most once.
+func (p *PromptService) RegenerateJourneyDay(ctx context.Context, journeyId, userId string, dayNumber int) (*response_models.PlanOnly, error) {
+	ctx, span := utils.Tracer.Start(ctx, "PromptService.RegenerateJourneyDay")
+	defer span.End()
+
+	journey, err := p.journeyRepo.GetDetailsOfJourneyById(ctx, journeyId)
+	if err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	if journey == nil {
+		return nil, utils.ErrJourneyNotFound
+	}
+	if journey.AccountID.String() != userId {
+		return nil, utils.ErrUnauthorized
+	}
+	ctx = p.withAIProfile(ctx, userId)
+
+	var day *db_models.JourneyDay
+	for i := range journey.Days {
+		if journey.Days[i].DayNumber == dayNumber {
+			day = &journey.Days[i]
+			break
+		}
+	}
+	if day == nil {
+		return nil, utils.ErrInvalidInput.WithMessage("Day not found in this journey")
+	}
+
+	// POIs of the other days are off limits; the journey's provinces give the candidates
+	used := make(map[string]int)
+	byID := make(map[string]*db_models.POI)
+	provinceSet := make(map[uuid.UUID]struct{})
+	for di := range journey.Days {
+		d := &journey.Days[di]
+		for ai := range d.Activities {
+			poi := &d.Activities[ai].SelectedPOI
+			if poi.ID == uuid.Nil {
+				continue
+			}
+			if poi.ProvinceID != uuid.Nil {
+				provinceSet[poi.ProvinceID] = struct{}{}
+			}
+			if d.DayNumber != dayNumber {
+				used[poi.ID.String()] = d.DayNumber
+				byID[poi.ID.String()] = poi
+			}
+		}
+	}
+	if len(provinceSet) == 0 {
+		return nil, utils.ErrInvalidInput.WithMessage("The journey has no places to plan this day around")
+	}
+
+	var candidates []*db_models.POI
+	provinceIDs := make([]string, 0, len(provinceSet))
+	for id := range provinceSet {
+		provinceIDs = append(provinceIDs, id.String())
+		pois, err := p.poisRepo.ListPoisByProvinceId(ctx, id.String(), 1, regenerateDayPoolSize)
+		if err != nil {
+			return nil, utils.ErrDatabaseError.Wrap(err)
+		}
+		for i := range pois {
+			if _, taken := used[pois[i].ID.String()]; !taken {
+				candidates = append(candidates, &pois[i])
+			}
+		}
+	}
+
+	companions := p.resolveCompanions(ctx, nil, userId)
+	exclusions := p.resolveExclusions(ctx, nil, userId)
+	policy := p.planningPolicy(ctx, userId)
+	policyExclusions := policy.Exclusions()
+	candidates = policyExclusions.Filter(exclusions.Filter(filterForCompanions(candidates, companions)))
+	var pinned []*db_models.POI
+	for _, poi := range p.policyPOIs(ctx, policy, provinceIDs) {
+		if _, taken := used[poi.ID.String()]; !taken {
+			pinned = append(pinned, poi)
+		}
+	}
+	candidates = policy.Pin(candidates, policyExclusions.Filter(exclusions.Filter(filterForCompanions(pinned, companions))))
+	if len(candidates) == 0 {
+		return nil, utils.ErrPoorQualityInput.WithMessage("Every place nearby is already on another day of this journey")
+	}
+
+	list := make([]request_models.POISummary, 0, 20)
+	for _, poi := range candidates {
+		byID[poi.ID.String()] = poi
+		if len(list) < 20 {
+			list = append(list, request_models.POISummary{
+				ID: poi.ID.String(), Name: poi.Name, Category: p.categorizePOI(poi), Description: poi.Description,
+			})
+		}
+	}
+
+	guardrails := p.rulesService.GuardrailsForProvinces(ctx, provinceIDs)
+	window, explicitWindow := p.resolveWorkingWindow(ctx, nil, userId)
+	if explicitWindow {
+		guardrails = guardrails.WithWorkingWindow(window)
+	}
+	guardrails = guardrails.WithCompanions(companions)
+
+	usedIDs := make([]string, 0, len(used))
+	for id := range used {
+		usedIDs = append(usedIDs, id)
+	}
+	sort.Strings(usedIDs)
+
+	dayDate := day.Date.In(vnLoc).Format("2006-01-02")
+	payload := planModelProfile{
+		Destination:       journey.Location,
+		DurationDays:      1,
+		StartDate:         dayDate,
+		EndDate:           dayDate,
+		DestinationRules:  guardrails.PromptLines(),
+		DayStart:          window.StartClock(),
+		DayEnd:            window.EndClock(),
+		Companions:        companions.Profile(),
+		MustNotInclude:    append(exclusions.Labels(), policyExclusions.Labels()...),
+		AlreadyUsedPOIIDs: usedIDs,
+	}
+	payload.PreferredPOIIDs, payload.MustIncludePOIIDs = policy.PromptIDs(list)
+
+	plan, err := p.requestPlan(ctx, payload, list, 1)
+	if err != nil {
+		return nil, err
+	}
+	plan.Destination = journey.Location
+	plan.Duration = 1
+	plan.Days[0].Day = dayNumber
+	policyNotes := policy.EnforcePlan(&plan, list, window)
+
+	// Repeats of other days are replaced first; whatever is still unknown was invented by the model
+	notes := dedupePlan(&plan, candidates, byID, used)
+	regenerated := &plan.Days[0]
+	kept := regenerated.Activities[:0]
+	for _, a := range regenerated.Activities {
+		if _, known := byID[a.MainPOIID]; known {
+			kept = append(kept, a)
+		}
+	}
+	regenerated.Activities = kept
+	for ai := range regenerated.Activities {
+		resp := planPOIResponse(byID[regenerated.Activities[ai].MainPOIID])
+		regenerated.Activities[ai].MainPOI = &resp
+	}
+
+	plan.RuleAdjustments = append(notes, exclusions.EnforcePlan(&plan, byID)...)
+	plan.RuleAdjustments = append(plan.RuleAdjustments, policyExclusions.EnforcePlan(&plan, byID)...)
+	plan.RuleAdjustments = append(plan.RuleAdjustments, policyNotes...)
+	plan.RuleAdjustments = append(plan.RuleAdjustments, guardrails.EnforcePlan(&plan)...)
+	if len(regenerated.Activities) == 0 {
+		return nil, utils.ErrUnexpectedBehaviorOfAI.Wrap(fmt.Errorf("regenerated day %d has no usable activities", dayNumber))
+	}
+	plan.Tips = companions.Tips()
+	buildRainyDays(&plan, byID, candidates, planPOIResponse)
+
+	if err := p.journeyRepo.ReplaceDayPlan(ctx, day.ID, regenerated); err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	p.events.Publish(ctx, journey.AccountID, EventJourneyUpdated, JourneyUpdatedEvent{
+		JourneyID: journeyId,
+		Change:    "day_regenerated",
+		Day:       dayNumber,
+	})
+	plan.CreatedAt = time.Now()
+	return &plan, nil
+}
@@ -0,0 +1,377 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+type DestinationRuleServiceInterface interface {
+	ListRules(ctx context.Context) ([]response_models.DestinationRuleResponse, error)
+	SetRule(ctx context.Context, provinceID string, req request_models.SetDestinationRuleRequest, updatedBy string) (*response_models.DestinationRuleResponse, error)
+	DeleteRule(ctx context.Context, provinceID string) error
+
+	// GuardrailsForProvinces and GuardrailsForPOIs return nil when no rule applies.
+	GuardrailsForProvinces(ctx context.Context, provinceIDs []string) *PlanGuardrails
+	GuardrailsForPOIs(ctx context.Context, poiIDs []string) *PlanGuardrails
+}
+
+type DestinationRuleService struct {
+	repo repositories.DestinationRuleRepositoryInterface
+}
+
+func NewDestinationRuleService(repo repositories.DestinationRuleRepositoryInterface) DestinationRuleServiceInterface {
+	return &DestinationRuleService{repo: repo}
+}
+
+func (s *DestinationRuleService) ListRules(ctx context.Context) ([]response_models.DestinationRuleResponse, error) {
+	rules, err := s.repo.ListRules(ctx)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	out := make([]response_models.DestinationRuleResponse, 0, len(rules))
+	for _, r := range rules {
+		out = append(out, toDestinationRuleResponse(r))
+	}
+	return out, nil
+}
+
+func (s *DestinationRuleService) SetRule(ctx context.Context, provinceID string, req request_models.SetDestinationRuleRequest, updatedBy string) (*response_models.DestinationRuleResponse, error) {
+	id, err := uuid.Parse(provinceID)
+	if err != nil {
+		return nil, utils.ErrInvalidInput
+	}
+
+	windows := make([]string, 0, len(req.ForbiddenWindows))
+	for _, w := range req.ForbiddenWindows {
+		parsed, err := parseTimeWindow(w)
+		if err != nil {
+			return nil, utils.ErrInvalidInput
+		}
+		windows = append(windows, parsed.String())
+	}
+
+	rule := &db_models.DestinationRule{
+		ProvinceID:          id,
+		MaxActivitiesPerDay: req.MaxActivitiesPerDay,
+		ForbiddenWindows:    pq.StringArray(windows),
+		BufferMinutes:       req.BufferMinutes,
+		Note:                strings.TrimSpace(req.Note),
+		UpdatedBy:           updatedBy,
+	}
+	if err := s.repo.UpsertRule(ctx, rule); err != nil {
+		// FK violation on an unknown province lands here too
+		return nil, utils.ErrDatabaseError
+	}
+
+	out := toDestinationRuleResponse(*rule)
+	return &out, nil
+}
+
+func (s *DestinationRuleService) DeleteRule(ctx context.Context, provinceID string) error {
+	if _, err := uuid.Parse(provinceID); err != nil {
+		return utils.ErrInvalidInput
+	}
+	found, err := s.repo.DeleteByProvinceID(ctx, provinceID)
+	if err != nil {
+		return utils.ErrDatabaseError
+	}
+	if !found {
+		return utils.RecordNotFound
+	}
+	return nil
+}
+
+func (s *DestinationRuleService) GuardrailsForProvinces(ctx context.Context, provinceIDs []string) *PlanGuardrails {
+	rules, err := s.repo.FindByProvinceIDs(ctx, provinceIDs)
+	if err != nil {
+		// Rules are a safety net, not a reason to fail a plan
+		log.Printf("[rules] failed to load destination rules: %v", err)
+		return nil
+	}
+	return mergeDestinationRules(rules)
+}
+
+func (s *DestinationRuleService) GuardrailsForPOIs(ctx context.Context, poiIDs []string) *PlanGuardrails {
+	rules, err := s.repo.FindByPOIIDs(ctx, poiIDs)
+	if err != nil {
+		log.Printf("[rules] failed to load destination rules: %v", err)
+		return nil
+	}
+	return mergeDestinationRules(rules)
+}
+
+func toDestinationRuleResponse(r db_models.DestinationRule) response_models.DestinationRuleResponse {
+	out := response_models.DestinationRuleResponse{
+		ProvinceID:          r.ProvinceID.String(),
+		ProvinceName:        r.Province.Name,
+		MaxActivitiesPerDay: r.MaxActivitiesPerDay,
+		ForbiddenWindows:    append([]string{}, r.ForbiddenWindows...),
+		BufferMinutes:       r.BufferMinutes,
+		Note:                r.Note,
+		UpdatedBy:           r.UpdatedBy,
+	}
+	if r.UpdatedAt > 0 {
+		out.UpdatedAt = time.Unix(r.UpdatedAt, 0).UTC().Format(time.RFC3339)
+	}
+	return out
+}
+
+// ---------- Guardrails ----------
+
+// timeWindow is a [From, To) range in minutes after midnight (VN). From > To wraps midnight.
+type timeWindow struct {
+	From, To int
+}
+
+func parseTimeWindow(s string) (timeWindow, error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) != 2 {
+		return timeWindow{}, fmt.Errorf("window %q must look like HH:MM-HH:MM", s)
+	}
+	from, ok1 := clockMinutes(parts[0])
+	to, ok2 := clockMinutes(parts[1])
+	if !ok1 || !ok2 || from == to {
+		return timeWindow{}, fmt.Errorf("window %q must look like HH:MM-HH:MM", s)
+	}
+	return timeWindow{From: from, To: to}, nil
+}
+
+func clockMinutes(s string) (int, bool) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+func formatClock(m int) string {
+	return fmt.Sprintf("%02d:%02d", m/60, m%60)
+}
+
+func (w timeWindow) String() string {
+	return formatClock(w.From) + "-" + formatClock(w.To)
+}
+
+// overlaps reports whether [start, end) touches the window.
+func (w timeWindow) overlaps(start, end int) bool {
+	if w.From < w.To {
+		return start < w.To && w.From < end
+	}
+	return start < w.To || end > w.From
+}
+
+// PlanGuardrails is the strictest combination of the rules of every province a plan touches,
+// plus the traveler's own day window when they set one.
+type PlanGuardrails struct {
+	MaxActivitiesPerDay int
+	BufferMinutes       int
+	Forbidden           []timeWindow
+	Notes               []string
+	Window              *WorkingWindow
+}
+
+// WithWorkingWindow returns a copy of g that also keeps activities inside w. Works on nil.
+func (g *PlanGuardrails) WithWorkingWindow(w WorkingWindow) *PlanGuardrails {
+	out := &PlanGuardrails{}
+	if g != nil {
+		*out = *g
+	}
+	out.Window = &w
+	return out
+}
+
+// restricted is every range activities must stay out of.
+func (g *PlanGuardrails) restricted() []timeWindow {
+	if g.Window == nil {
+		return g.Forbidden
+	}
+	return append(append([]timeWindow{}, g.Forbidden...), g.Window.outside())
+}
+
+func mergeDestinationRules(rules []db_models.DestinationRule) *PlanGuardrails {
+	if len(rules) == 0 {
+		return nil
+	}
+	g := &PlanGuardrails{}
+	seen := map[timeWindow]bool{}
+	for _, r := range rules {
+		if r.MaxActivitiesPerDay > 0 && (g.MaxActivitiesPerDay == 0 || r.MaxActivitiesPerDay < g.MaxActivitiesPerDay) {
+			g.MaxActivitiesPerDay = r.MaxActivitiesPerDay
+		}
+		if r.BufferMinutes > g.BufferMinutes {
+			g.BufferMinutes = r.BufferMinutes
+		}
+		for _, raw := range r.ForbiddenWindows {
+			w, err := parseTimeWindow(raw)
+			if err != nil || seen[w] {
+				continue
+			}
+			seen[w] = true
+			g.Forbidden = append(g.Forbidden, w)
+		}
+		if r.Note != "" {
+			note := r.Note
+			if r.Province.Name != "" {
+				note = r.Province.Name + ": " + note
+			}
+			g.Notes = append(g.Notes, note)
+		}
+	}
+	return g
+}
+
+// PromptLines renders the guardrails as plain instructions for the model.
+func (g *PlanGuardrails) PromptLines() []string {
+	if g == nil {
+		return nil
+	}
+	var out []string
+	if g.Window != nil {
+		out = append(out, g.Window.promptLine())
+	}
+	if g.MaxActivitiesPerDay > 0 {
+		out = append(out, fmt.Sprintf("At most %d activities per day.", g.MaxActivitiesPerDay))
+	}
+	if g.BufferMinutes > 0 {
+		out = append(out, fmt.Sprintf("Leave at least %d minutes between the end of one activity and the start of the next.", g.BufferMinutes))
+	}
+	for _, w := range g.Forbidden {
+		out = append(out, fmt.Sprintf("No activity may overlap %s.", w))
+	}
+	return append(out, g.Notes...)
+}
+
+type guardSlot struct {
+	Label      string
+	Start, End int // minutes after midnight
+	// Travel slots (e.g. the drive back to the accommodation) only have to avoid restricted
+	// windows; they do not count as activities and need no buffer.
+	Travel bool
+	Point  *MatrixPoint
+}
+
+// CheckDay lists every rule the day breaks. Slots must be sorted by start time.
+func (g *PlanGuardrails) CheckDay(slots []guardSlot) []string {
+	if g == nil {
+		return nil
+	}
+	var out []string
+	activities := 0
+	prev := -1
+	for i, s := range slots {
+		for _, w := range g.Forbidden {
+			if w.overlaps(s.Start, s.End) {
+				out = append(out, fmt.Sprintf("%s overlaps the restricted window %s", s.Label, w))
+			}
+		}
+		if s.Travel {
+			continue
+		}
+		if g.Window != nil && !g.Window.contains(s.Start, s.End) {
+			out = append(out, fmt.Sprintf("%s is outside the day window %s", s.Label, g.Window))
+		}
+		activities++
+		if prev >= 0 && g.BufferMinutes > 0 && s.Start-slots[prev].End < g.BufferMinutes {
+			out = append(out, fmt.Sprintf("%s starts less than %d minutes after the previous activity", s.Label, g.BufferMinutes))
+		}
+		prev = i
+	}
+	if g.MaxActivitiesPerDay > 0 && activities > g.MaxActivitiesPerDay {
+		out = append(out, fmt.Sprintf("%d activities exceed the limit of %d per day", activities, g.MaxActivitiesPerDay))
+	}
+	return out
+}
+
+// EnforcePlan repairs a generated plan in place: activities are pushed later to honour the
+// buffer and restricted windows, and dropped when they no longer fit the day or the daily limit.
+// It returns a human-readable note for every change.
+func (g *PlanGuardrails) EnforcePlan(plan *response_models.PlanOnly) []string {
+	if g == nil || plan == nil {
+		return nil
+	}
+	var notes []string
+	for di := range plan.Days {
+		day := &plan.Days[di]
+
+		// Activities with unreadable times are left alone; the AI contract says HH:MM
+		type timed struct {
+			act        response_models.PlanOnlyActivity
+			start, end int
+		}
+		var items []timed
+		var untimed []response_models.PlanOnlyActivity
+		for _, a := range day.Activities {
+			s, ok1 := clockMinutes(a.StartTime)
+			e, ok2 := clockMinutes(a.EndTime)
+			if !ok1 || !ok2 || e <= s {
+				untimed = append(untimed, a)
+				continue
+			}
+			items = append(items, timed{act: a, start: s, end: e})
+		}
+		sort.SliceStable(items, func(i, j int) bool { return items[i].start < items[j].start })
+
+		kept := make([]response_models.PlanOnlyActivity, 0, len(day.Activities))
+		prevEnd := -1
+		for _, it := range items {
+			label := planActivityLabel(it.act)
+			if g.MaxActivitiesPerDay > 0 && len(kept) >= g.MaxActivitiesPerDay {
+				notes = append(notes, fmt.Sprintf("Day %d: removed %s (limit of %d activities per day)", day.Day, label, g.MaxActivitiesPerDay))
+				continue
+			}
+
+			dur := it.end - it.start
+			start := it.start
+			if prevEnd >= 0 && start < prevEnd+g.BufferMinutes {
+				start = prevEnd + g.BufferMinutes
+			}
+			// Push past restricted windows until the slot is clear; each window moves it at most once
+			restricted := g.restricted()
+			for moved, i := true, 0; moved && i <= len(restricted); i++ {
+				moved = false
+				for _, w := range restricted {
+					if w.overlaps(start, start+dur) {
+						next := w.To
+						if next <= start {
+							next = 24 * 60 // wrapped window runs to the end of the day
+						}
+						start, moved = next, true
+					}
+				}
+			}
+			if start+dur > 24*60 {
+				notes = append(notes, fmt.Sprintf("Day %d: removed %s (no time left within allowed hours)", day.Day, label))
+				continue
+			}
+
+			if start != it.start {
+				it.act.StartTime = formatClock(start)
+				it.act.EndTime = formatClock(start + dur)
+				notes = append(notes, fmt.Sprintf("Day %d: moved %s to %s", day.Day, label, it.act.StartTime))
+			}
+			kept = append(kept, it.act)
+			prevEnd = start + dur
+		}
+		day.Activities = append(kept, untimed...)
+	}
+	return notes
+}
+
+func planActivityLabel(a response_models.PlanOnlyActivity) string {
+	if a.MainPOI != nil && a.MainPOI.Name != "" {
+		return a.MainPOI.Name
+	}
+	return a.MainPOIID
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"vivu/internal/infra"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+type DiagnosticsServiceInterface interface {
+	ListSlowQueries(ctx context.Context, page, pageSize int, minDurationMs int64) (*response_models.SlowQueryPage, error)
+	DeleteSlowQuery(ctx context.Context, id string) error
+	GetPoolStats(ctx context.Context) (*infra.PoolStats, error)
+	// GetAIProviderStats is empty unless the AI client is the multi-provider router.
+	GetAIProviderStats(ctx context.Context) []utils.AIProviderStats
+}
+
+type DiagnosticsService struct {
+	diagnosticsRepo repositories.DiagnosticsRepositoryInterface
+	aiClient        utils.EmbeddingClientInterface
+}
+
+func NewDiagnosticsService(diagnosticsRepo repositories.DiagnosticsRepositoryInterface, aiClient utils.EmbeddingClientInterface) DiagnosticsServiceInterface {
+	return &DiagnosticsService{diagnosticsRepo: diagnosticsRepo, aiClient: aiClient}
+}
+
+func (s *DiagnosticsService) ListSlowQueries(ctx context.Context, page, pageSize int, minDurationMs int64) (*response_models.SlowQueryPage, error) {
+	rows, total, err := s.diagnosticsRepo.ListSlowQueries(ctx, page, pageSize, minDurationMs)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+
+	out := &response_models.SlowQueryPage{
+		Items:    make([]response_models.SlowQueryResponse, 0, len(rows)),
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}
+	for _, r := range rows {
+		out.Items = append(out.Items, response_models.SlowQueryResponse{
+			ID:           r.ID.String(),
+			Fingerprint:  r.Fingerprint,
+			Table:        r.Table,
+			SQL:          r.SQL,
+			BoundSQL:     r.BoundSQL,
+			DurationMs:   r.DurationMs,
+			RowsAffected: r.RowsAffected,
+			Plan:         r.Plan,
+			Occurrences:  r.Occurrences,
+			LastSeenAt:   utils.FormatRFC3339VN(utils.FromUnixSecondsVN(r.LastSeenAt)),
+		})
+	}
+	return out, nil
+}
+
+func (s *DiagnosticsService) DeleteSlowQuery(ctx context.Context, id string) error {
+	if err := s.diagnosticsRepo.DeleteSlowQuery(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.RecordNotFound
+		}
+		return utils.ErrDatabaseError
+	}
+	return nil
+}
+
+func (s *DiagnosticsService) GetPoolStats(ctx context.Context) (*infra.PoolStats, error) {
+	stats, err := infra.GetPoolStats()
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	return &stats, nil
+}
+
+func (s *DiagnosticsService) GetAIProviderStats(ctx context.Context) []utils.AIProviderStats {
+	if r, ok := s.aiClient.(utils.AIStatsReporter); ok {
+		return r.AIProviderStats()
+	}
+	return []utils.AIProviderStats{}
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+type EmbeddingIndexServiceInterface interface {
+	// Reindex queues every POI for a fresh embedding and wakes the worker; returns how many were queued.
+	Reindex(ctx context.Context) (int64, error)
+	// ProcessPending embeds one batch from the outbox and returns how many POIs it handled.
+	ProcessPending(ctx context.Context) (int, error)
+
+	Start()
+	Stop()
+}
+
+type EmbeddingIndexService struct {
+	repo     repositories.EmbeddingOutboxRepositoryInterface
+	embedder utils.EmbeddingClientInterface
+	content  POIEmbeddingContentBuilder
+
+	interval    time.Duration // idle wait between outbox polls
+	batchSize   int           // POIs per GetEmbeddings call
+	maxAttempts int           // rows failing this often are left for an admin to look at
+	claimLease  time.Duration // how long a claimed batch stays hidden from other workers
+
+	wake     chan struct{}
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// NewEmbeddingIndexService reads EMBEDDING_WORKER_INTERVAL (default 30s) and EMBEDDING_BATCH_SIZE (default 50).
+func NewEmbeddingIndexService(repo repositories.EmbeddingOutboxRepositoryInterface, embedder utils.EmbeddingClientInterface) EmbeddingIndexServiceInterface {
+	s := &EmbeddingIndexService{
+		repo:        repo,
+		embedder:    embedder,
+		content:     NewPOIEmbeddingContentBuilder(),
+		interval:    30 * time.Second,
+		batchSize:   50,
+		maxAttempts: 5,
+		claimLease:  10 * time.Minute,
+		wake:        make(chan struct{}, 1),
+		stop:        make(chan struct{}),
+	}
+	if d, err := time.ParseDuration(os.Getenv("EMBEDDING_WORKER_INTERVAL")); err == nil && d > 0 {
+		s.interval = d
+	}
+	if n, err := strconv.Atoi(os.Getenv("EMBEDDING_BATCH_SIZE")); err == nil && n > 0 {
+		s.batchSize = n
+	}
+	return s
+}
+
+func (s *EmbeddingIndexService) Reindex(ctx context.Context) (int64, error) {
+	n, err := s.repo.EnqueueAll(ctx, db_models.EmbeddingReasonReindex)
+	if err != nil {
+		log.Printf("[embeddings] reindex: %v", err)
+		return 0, utils.ErrDatabaseError
+	}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return n, nil
+}
+
+func (s *EmbeddingIndexService) ProcessPending(ctx context.Context) (int, error) {
+	rows, err := s.repo.PendingBatch(ctx, s.batchSize, s.maxAttempts, s.claimLease)
+	if err != nil || len(rows) == 0 {
+		return 0, err
+	}
+
+	// A POI edited several times since the last run has several rows; one embedding covers them all
+	rowIDs := make([]uuid.UUID, 0, len(rows))
+	seen := make(map[uuid.UUID]struct{}, len(rows))
+	var poiIDs []uuid.UUID
+	for _, r := range rows {
+		rowIDs = append(rowIDs, r.ID)
+		if _, ok := seen[r.POIID]; !ok {
+			seen[r.POIID] = struct{}{}
+			poiIDs = append(poiIDs, r.POIID)
+		}
+	}
+
+	fail := func(err error) (int, error) {
+		if markErr := s.repo.MarkFailed(ctx, rowIDs, err.Error()); markErr != nil {
+			log.Printf("[embeddings] failed to record error: %v", markErr)
+		}
+		return 0, err
+	}
+
+	pois, err := s.repo.LoadPOIs(ctx, poiIDs)
+	if err != nil {
+		return fail(err)
+	}
+
+	// POIs that are gone (deleted) lose their embedding
+	live := make(map[uuid.UUID]struct{}, len(pois))
+	for _, p := range pois {
+		live[p.ID] = struct{}{}
+	}
+	var gone []string
+	for _, id := range poiIDs {
+		if _, ok := live[id]; !ok {
+			gone = append(gone, id.String())
+		}
+	}
+	if err := s.repo.DeleteEmbeddings(ctx, gone); err != nil {
+		return fail(err)
+	}
+
+	if len(pois) > 0 {
+		notes, err := s.repo.ReviewNotes(ctx, poiIDs)
+		if err != nil {
+			return fail(err)
+		}
+		texts := make([]string, len(pois))
+		for i := range pois {
+			texts[i] = s.content.Build(&pois[i], reviewKeywords(notes[pois[i].ID], s.content.MaxReviewKeywords))
+		}
+		vectors, err := s.embedder.GetEmbeddings(ctx, texts)
+		if err != nil {
+			return fail(err)
+		}
+		if len(vectors) != len(pois) {
+			return fail(utils.ErrUnexpectedBehaviorOfAI)
+		}
+
+		out := make([]db_models.PoiEmbedding, len(pois))
+		for i := range pois {
+			out[i] = toPoiEmbedding(&pois[i])
+			out[i].Embedding = vectors[i]
+		}
+		if err := s.repo.UpsertEmbeddings(ctx, out); err != nil {
+			return fail(err)
+		}
+	}
+
+	if err := s.repo.MarkProcessed(ctx, rowIDs); err != nil {
+		return 0, err
+	}
+	return len(poiIDs), nil
+}
+
+// Start drains the outbox in the background until Stop is called. POIs embedded with an
+// older content version are queued first, so changing the text builder backfills itself.
+func (s *EmbeddingIndexService) Start() {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		if n, err := s.repo.EnqueueStale(ctx, poiEmbeddingContentVersion, db_models.EmbeddingReasonReindex); err != nil {
+			log.Printf("[embeddings] queue stale embeddings: %v", err)
+		} else if n > 0 {
+			log.Printf("[embeddings] queued %d POIs for content version %d", n, poiEmbeddingContentVersion)
+		}
+		cancel()
+
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+		for {
+			select {
+			case <-timer.C:
+			case <-s.wake:
+			case <-s.stop:
+				return
+			}
+
+			s.drain()
+			timer.Reset(s.interval)
+		}
+	}()
+}
+
+// drain processes batches until the outbox is empty, a batch fails or the service stops.
+func (s *EmbeddingIndexService) drain() {
+	total := 0
+	for {
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		n, err := s.ProcessPending(ctx)
+		cancel()
+		if err != nil {
+			log.Printf("[embeddings] batch failed: %v", err)
+			break
+		}
+		if n == 0 {
+			break
+		}
+		total += n
+	}
+	if total > 0 {
+		log.Printf("[embeddings] embedded %d POIs", total)
+	}
+}
+
+func (s *EmbeddingIndexService) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+func toPoiEmbedding(p *db_models.POI) db_models.PoiEmbedding {
+	out := db_models.PoiEmbedding{
+		PoiID:       p.ID.String(),
+		Name:        p.Name,
+		Description: p.Description,
+		ProvinceID:  p.ProvinceID.String(),
+		Tags:        poiTagNames(p),
+		CreatedAt:   time.Now(),
+
+		ContentVersion: poiEmbeddingContentVersion,
+	}
+	if p.CategoryID != nil {
+		out.CategoryID = p.CategoryID.String()
+	}
+	return out
+}
+
+func poiTagNames(p *db_models.POI) []string {
+	out := make([]string, 0, len(p.Tags))
+	for _, t := range p.Tags {
+		if t != nil && t.EnName != "" {
+			out = append(out, t.EnName)
+		}
+	}
+	return out
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Events published on the bus. Data is one of the event structs below, delivered to webhooks
+// as the JSON object under "data".
+const (
+	EventPlanGenerated         = "plan.generated"         // PlanGeneratedEvent
+	EventJourneyUpdated        = "journey.updated"        // JourneyUpdatedEvent
+	EventSubscriptionActivated = "subscription.activated" // SubscriptionEvent
+	EventSubscriptionExpiring  = "subscription.expiring"  // SubscriptionEvent
+	EventSubscriptionPastDue   = "subscription.past_due"  // SubscriptionEvent
+	EventSubscriptionExpired   = "subscription.expired"   // SubscriptionEvent
+	EventPaymentFailed         = "payment.failed"         // PaymentFailedEvent
+	EventPaymentRefunded       = "payment.refunded"       // PaymentRefundedEvent
+)
+
+// WebhookEvents lists every event an endpoint can subscribe to.
+var WebhookEvents = []string{
+	EventPlanGenerated, EventJourneyUpdated, EventSubscriptionActivated, EventSubscriptionExpiring, EventPaymentFailed,
+	EventPaymentRefunded, EventSubscriptionPastDue, EventSubscriptionExpired,
+}
+
+type PlanGeneratedEvent struct {
+	JourneyID    string `json:"journey_id"`
+	Destination  string `json:"destination"`
+	DurationDays int    `json:"duration_days"`
+}
+
+type JourneyUpdatedEvent struct {
+	JourneyID string `json:"journey_id"`
+	Change    string `json:"change"` // day_regenerated, day_seeded
+	Day       int    `json:"day,omitempty"`
+}
+
+type SubscriptionEvent struct {
+	SubscriptionID string `json:"subscription_id"`
+	PlanCode       string `json:"plan_code"`
+	StartsAt       string `json:"starts_at"`
+	EndsAt         string `json:"ends_at"`
+}
+
+type PaymentFailedEvent struct {
+	TransactionID string `json:"transaction_id"`
+	OrderCode     int64  `json:"order_code"`
+	AmountMinor   int64  `json:"amount_minor"`
+	Currency      string `json:"currency"`
+	Reason        string `json:"reason"`
+}
+
+type PaymentRefundedEvent struct {
+	TransactionID string `json:"transaction_id"`
+	AmountMinor   int64  `json:"amount_minor"` // refunded
+	Currency      string `json:"currency"`
+	Reason        string `json:"reason,omitempty"`
+	// Set when the payment bought a subscription: when it now ends, or that it was canceled
+	SubscriptionID     string `json:"subscription_id,omitempty"`
+	SubscriptionStatus string `json:"subscription_status,omitempty"`
+	SubscriptionEndsAt string `json:"subscription_ends_at,omitempty"`
+}
+
+// EventBus hands events of an account to whoever listens for them. Publish never fails the
+// caller: an event that cannot be queued is logged and dropped.
+type EventBus interface {
+	Publish(ctx context.Context, accountID uuid.UUID, eventType string, data any)
+}
+
+type eventBuses []EventBus
+
+// NewEventBus publishes every event to each listener in turn.
+func NewEventBus(listeners ...EventBus) EventBus {
+	return eventBuses(listeners)
+}
+
+func (b eventBuses) Publish(ctx context.Context, accountID uuid.UUID, eventType string, data any) {
+	for _, l := range b {
+		l.Publish(ctx, accountID, eventType, data)
+	}
+}
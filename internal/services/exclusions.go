@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+)
+
+// Quiz answer meaning "only what my profile says".
+const avoidNothing = "Nothing in particular"
+
+const (
+	avoidNightlife = "Nightlife & bars"
+	// adultAge is the age from which plans may include bars and nightlife.
+	adultAge = 18
+)
+
+// avoidCatalog maps the quiz options to the words (diacritics folded) that identify such places.
+// Anything else the traveler types is matched as a word of its own.
+var avoidCatalog = []struct {
+	Label    string
+	Keywords []string
+}{
+	{"Museums", []string{"museum", "bao tang", "gallery", "exhibition", "trien lam"}},
+	{"Temples & pagodas", []string{"temple", "pagoda", "chua", "den", "shrine", "mieu"}},
+	{"Churches", []string{"church", "cathedral", "nha tho"}},
+	{avoidNightlife, adultOnlyKeywords},
+	{"Hiking & strenuous activities", strenuousKeywords},
+	{"Shopping & markets", []string{"market", "cho", "mall", "shopping", "trung tam thuong mai", "night market"}},
+	{"Beaches", []string{"beach", "bai bien", "bien"}},
+	{"Theme parks & zoos", []string{"theme park", "amusement park", "water park", "zoo", "thao cam vien", "khu vui choi"}},
+}
+
+// Exclusions are the places a traveler never wants in a plan ("no museums"). Unlike the
+// companion filter they are hard: retrieval, the prompt and the final plan all apply them.
+type Exclusions struct {
+	labels   []string // as the traveler said them, for prompts and messages
+	keywords []string
+	reason   string // why EnforcePlan removed an activity; the traveler's own wish when empty
+}
+
+func avoidLabels() []string {
+	out := make([]string, 0, len(avoidCatalog)+1)
+	for _, c := range avoidCatalog {
+		out = append(out, c.Label)
+	}
+	return append(out, avoidNothing)
+}
+
+// parseExclusions turns quiz or profile entries ("Museums", "no karaoke") into exclusions.
+func parseExclusions(entries []string) Exclusions {
+	var e Exclusions
+	seen := make(map[string]bool)
+	for _, raw := range entries {
+		entry := strings.TrimSpace(raw)
+		if entry == "" || strings.EqualFold(entry, avoidNothing) {
+			continue
+		}
+		key := keywordText(entry)
+		key = strings.TrimPrefix(strings.TrimPrefix(key, "no "), "khong ")
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		forms := []string{key}
+		// "karaokes" still excludes "karaoke"
+		if singular := strings.TrimSuffix(key, "s"); singular != key && len(singular) > 3 {
+			forms = append(forms, singular)
+		}
+
+		matched := false
+		for _, c := range avoidCatalog {
+			if key == keywordText(c.Label) || hasKeyword(strings.Join(forms, " | "), c.Keywords) {
+				e.labels = append(e.labels, c.Label)
+				e.keywords = append(e.keywords, c.Keywords...)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			e.labels = append(e.labels, entry)
+			e.keywords = append(e.keywords, forms...)
+		}
+	}
+	return e
+}
+
+func (e Exclusions) empty() bool {
+	return len(e.keywords) == 0
+}
+
+// Labels lists what is excluded, as the traveler put it.
+func (e Exclusions) Labels() []string {
+	return append([]string{}, e.labels...)
+}
+
+func (e Exclusions) excludes(text string) bool {
+	return !e.empty() && hasKeyword(text, e.keywords)
+}
+
+// ExcludesPOI reports whether p falls under one of the exclusions (name, category or tags).
+func (e Exclusions) ExcludesPOI(p *db_models.POI) bool {
+	return p != nil && e.excludes(poiKeywordText(p))
+}
+
+// Filter drops excluded POIs from retrieval results.
+func (e Exclusions) Filter(pois []*db_models.POI) []*db_models.POI {
+	if e.empty() {
+		return pois
+	}
+	kept := make([]*db_models.POI, 0, len(pois))
+	for _, p := range pois {
+		if !e.ExcludesPOI(p) {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// PromptLines is the "must not include" block of free-text prompts.
+func (e Exclusions) PromptLines() []string {
+	if e.empty() {
+		return nil
+	}
+	return []string{
+		"Must NOT include (hard constraint, never schedule or suggest these): " + strings.Join(e.labels, ", "),
+	}
+}
+
+func (e Exclusions) removalReason() string {
+	if e.reason != "" {
+		return e.reason
+	}
+	return "you asked to avoid it"
+}
+
+// EnforcePlan removes activities on excluded POIs the model picked anyway, and reports what it removed. byID holds the plan's POIs.
+func (e Exclusions) EnforcePlan(plan *response_models.PlanOnly, byID map[string]*db_models.POI) []string {
+	if e.empty() || plan == nil {
+		return nil
+	}
+	var notes []string
+	for di := range plan.Days {
+		day := &plan.Days[di]
+		kept := day.Activities[:0]
+		for _, a := range day.Activities {
+			if e.ExcludesPOI(byID[a.MainPOIID]) {
+				notes = append(notes, fmt.Sprintf("Day %d: removed %s (%s)", day.Day, planActivityLabel(a), e.removalReason()))
+				continue
+			}
+			kept = append(kept, a)
+		}
+		day.Activities = kept
+	}
+	return notes
+}
+
+// EnforceItinerary does the same for narrative itineraries, which only carry POI names and categories.
+func (e Exclusions) EnforceItinerary(it *response_models.TravelItinerary) {
+	if e.empty() || it == nil {
+		return
+	}
+	for di := range it.Days {
+		day := &it.Days[di]
+		kept := day.Activities[:0]
+		for _, a := range day.Activities {
+			if e.excludes(keywordText(a.MainPOI.Name, a.MainPOI.Category)) {
+				continue
+			}
+			support := a.SupportPOIs[:0]
+			for _, s := range a.SupportPOIs {
+				if !e.excludes(keywordText(s.Name, s.Category)) {
+					support = append(support, s)
+				}
+			}
+			a.SupportPOIs = support
+			kept = append(kept, a)
+		}
+		day.Activities = kept
+	}
+}
+
+// resolveExclusions merges the quiz answer (comma-separated) with the account's saved dislikes,
+// and keeps bars and nightlife out of the plans of minors whatever they answered. The age gate
+// fails closed: when the profile cannot be loaded, nightlife is left out as for a minor.
+func (p *PromptService) resolveExclusions(ctx context.Context, answers map[string]string, userId string) Exclusions {
+	entries := strings.Split(answers["avoid"], ",")
+	if userId != "" {
+		if acc, err := p.accountSerivce.GetProfileInfo(ctx, userId); err != nil {
+			log.Printf("[plan] profile of %s unavailable, excluding nightlife: %v", userId, err)
+			entries = append(entries, avoidNightlife)
+		} else {
+			entries = append(entries, acc.Avoid...)
+			if acc.Minor {
+				entries = append(entries, avoidNightlife)
+			}
+		}
+	}
+	return parseExclusions(entries)
+}
+
+// isMinor reports whether an account born in birthYear may be under adultAge at now. With
+// only the year known, the year the account turns 18 still counts as under age. Accounts
+// that did not give a birth year are treated as adults.
+func isMinor(birthYear *int, now time.Time) bool {
+	return birthYear != nil && now.Year()-*birthYear <= adultAge
+}
+
+func avoidQuestion() request_models.QuizQuestion {
+	return request_models.QuizQuestion{
+		ID:          "avoid",
+		Question:    "Anything you'd rather skip? 🚫 (pick any, or type your own, comma-separated)",
+		Type:        "multiple_choice",
+		Options:     avoidLabels(),
+		Required:    false,
+		Category:    "activities",
+		Placeholder: "e.g. museums, karaoke",
+	}
+}
@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+const (
+	ExportFormatXLSX = "xlsx"
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+
+	poiExportBatchSize = 500
+)
+
+// Column order of CSV/XLSX exports. The names match the bulk importer's headers,
+// so an export can be edited and imported again.
+var poiExportColumns = []string{
+	"id", "name", "latitude", "longitude", "province", "category", "status", "address",
+	"opening_hours", "contact_info", "description", "images", "tags", "updated_at",
+}
+
+type ExportServiceInterface interface {
+	// ExportPOIs validates the query and returns the file to stream. Nothing is read
+	// from the database until Write is called.
+	ExportPOIs(ctx context.Context, query request_models.POIExportQuery) (*FileExport, error)
+}
+
+// FileExport is a file produced on the fly.
+type FileExport struct {
+	FileName    string
+	ContentType string
+	Write       func(w io.Writer) error
+}
+
+type ExportService struct {
+	lookups repositories.POIImportRepositoryInterface // province/category lookups are shared with the importer
+	repo    repositories.POIExportRepositoryInterface
+}
+
+func NewExportService(lookups repositories.POIImportRepositoryInterface, repo repositories.POIExportRepositoryInterface) ExportServiceInterface {
+	return &ExportService{lookups: lookups, repo: repo}
+}
+
+func (s *ExportService) ExportPOIs(ctx context.Context, query request_models.POIExportQuery) (*FileExport, error) {
+	format := strings.ToLower(strings.TrimSpace(query.Format))
+	if format == "" {
+		format = ExportFormatXLSX
+	}
+
+	var filter repositories.POIExportFilter
+	if query.Province != "" {
+		provinces, err := s.lookups.ListProvinces(ctx)
+		if err != nil {
+			return nil, utils.ErrDatabaseError
+		}
+		id, ok := matchExportLookup(query.Province, len(provinces), func(i int) (uuid.UUID, string) {
+			return provinces[i].ID, provinces[i].Name
+		})
+		if !ok {
+			return nil, utils.ErrInvalidInput
+		}
+		filter.ProvinceID = &id
+	}
+	if query.Category != "" {
+		categories, err := s.lookups.ListCategories(ctx)
+		if err != nil {
+			return nil, utils.ErrDatabaseError
+		}
+		id, ok := matchExportLookup(query.Category, len(categories), func(i int) (uuid.UUID, string) {
+			return categories[i].ID, categories[i].Name
+		})
+		if !ok {
+			return nil, utils.ErrInvalidInput
+		}
+		filter.CategoryID = &id
+	}
+	if query.UpdatedSince != "" {
+		since, ok := parseUpdatedSince(query.UpdatedSince)
+		if !ok {
+			return nil, utils.ErrInvalidInput
+		}
+		filter.UpdatedSince = since
+	}
+
+	name := "pois_" + time.Now().In(vnLoc).Format("20060102_1504") + "." + format
+	stream := func(fn func([]db_models.POI) error) error {
+		return s.repo.StreamPOIs(ctx, filter, poiExportBatchSize, fn)
+	}
+
+	switch format {
+	case ExportFormatXLSX:
+		return &FileExport{
+			FileName:    name,
+			ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+			Write:       func(w io.Writer) error { return writePOIsXLSX(w, stream) },
+		}, nil
+	case ExportFormatCSV:
+		return &FileExport{
+			FileName:    name,
+			ContentType: "text/csv; charset=utf-8",
+			Write:       func(w io.Writer) error { return writePOIsCSV(w, stream) },
+		}, nil
+	case ExportFormatJSON:
+		return &FileExport{
+			FileName:    name,
+			ContentType: "application/json",
+			Write:       func(w io.Writer) error { return writePOIsJSON(w, stream) },
+		}, nil
+	default:
+		return nil, utils.ErrInvalidInput
+	}
+}
+
+type poiStream func(fn func([]db_models.POI) error) error
+
+func writePOIsXLSX(w io.Writer, stream poiStream) error {
+	xw, err := utils.NewXLSXWriter(w, "POIs")
+	if err != nil {
+		return err
+	}
+	header := make([]any, len(poiExportColumns))
+	for i, c := range poiExportColumns {
+		header[i] = c
+	}
+	if err := xw.WriteRow(header...); err != nil {
+		return err
+	}
+	err = stream(func(batch []db_models.POI) error {
+		for i := range batch {
+			r := poiExportRecord(&batch[i])
+			if err := xw.WriteRow(r.ID, r.Name, r.Latitude, r.Longitude, r.Province, r.Category, r.Status, r.Address,
+				r.OpeningHours, r.ContactInfo, r.Description, strings.Join(r.Images, "|"), strings.Join(r.Tags, "|"), r.UpdatedAt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return xw.Close()
+}
+
+func writePOIsCSV(w io.Writer, stream poiStream) error {
+	if _, err := io.WriteString(w, "\xef\xbb\xbf"); err != nil { // BOM so Excel reads Vietnamese correctly
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(poiExportColumns); err != nil {
+		return err
+	}
+	err := stream(func(batch []db_models.POI) error {
+		for i := range batch {
+			r := poiExportRecord(&batch[i])
+			if err := cw.Write([]string{
+				r.ID, r.Name,
+				strconv.FormatFloat(r.Latitude, 'f', -1, 64), strconv.FormatFloat(r.Longitude, 'f', -1, 64),
+				r.Province, r.Category, r.Status, r.Address, r.OpeningHours, r.ContactInfo, r.Description,
+				strings.Join(r.Images, "|"), strings.Join(r.Tags, "|"), r.UpdatedAt,
+			}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writePOIsJSON(w io.Writer, stream poiStream) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	first := true
+	err := stream(func(batch []db_models.POI) error {
+		for i := range batch {
+			b, err := json.Marshal(poiExportRecord(&batch[i]))
+			if err != nil {
+				return err
+			}
+			if !first {
+				if _, err := io.WriteString(w, ","); err != nil {
+					return err
+				}
+			}
+			first = false
+			if _, err := w.Write(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "]")
+	return err
+}
+
+type poiExportRow struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Latitude     float64  `json:"latitude"`
+	Longitude    float64  `json:"longitude"`
+	Province     string   `json:"province"`
+	Category     string   `json:"category"`
+	Status       string   `json:"status"`
+	Address      string   `json:"address"`
+	OpeningHours string   `json:"opening_hours"`
+	ContactInfo  string   `json:"contact_info"`
+	Description  string   `json:"description"`
+	Images       []string `json:"images"`
+	Tags         []string `json:"tags"`
+	UpdatedAt    string   `json:"updated_at"`
+}
+
+func poiExportRecord(p *db_models.POI) poiExportRow {
+	row := poiExportRow{
+		ID:           p.ID.String(),
+		Name:         p.Name,
+		Latitude:     p.Latitude,
+		Longitude:    p.Longitude,
+		Province:     p.Province.Name,
+		Category:     p.Category.Name,
+		Status:       p.Status,
+		Address:      p.Address,
+		OpeningHours: p.OpeningHours,
+		ContactInfo:  p.ContactInfo,
+		Description:  p.Description,
+		Images:       append([]string{}, p.Details.Images...),
+		Tags:         make([]string, 0, len(p.Tags)),
+	}
+	for _, t := range p.Tags {
+		if t != nil {
+			row.Tags = append(row.Tags, t.EnName)
+		}
+	}
+	if p.UpdatedAt > 0 {
+		row.UpdatedAt = time.Unix(p.UpdatedAt, 0).In(vnLoc).Format(time.RFC3339)
+	}
+	return row
+}
+
+// matchExportLookup accepts an ID or a name (case and diacritics ignored).
+func matchExportLookup(value string, n int, at func(i int) (uuid.UUID, string)) (uuid.UUID, bool) {
+	value = strings.TrimSpace(value)
+	if id, err := uuid.Parse(value); err == nil {
+		for i := 0; i < n; i++ {
+			if got, _ := at(i); got == id {
+				return id, true
+			}
+		}
+		return uuid.Nil, false
+	}
+	key := lookupKey(value)
+	for i := 0; i < n; i++ {
+		if id, name := at(i); lookupKey(name) == key {
+			return id, true
+		}
+	}
+	return uuid.Nil, false
+}
+
+func parseUpdatedSince(s string) (int64, bool) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.Unix(), true
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, vnLoc); err == nil {
+		return t.Unix(), true
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil && n > 0 {
+		return n, true
+	}
+	return 0, false
+}
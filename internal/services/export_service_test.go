@@ -0,0 +1,340 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+var (
+	exportDaLat  = db_models.Province{BaseModel: db_models.BaseModel{ID: uuid.MustParse("11111111-1111-1111-1111-111111111111")}, Name: "Đà Lạt"}
+	exportHue    = db_models.Province{BaseModel: db_models.BaseModel{ID: uuid.MustParse("22222222-2222-2222-2222-222222222222")}, Name: "Huế"}
+	exportCafe   = db_models.Category{BaseModel: db_models.BaseModel{ID: uuid.MustParse("33333333-3333-3333-3333-333333333333")}, Name: "Cafe"}
+	exportMuseum = db_models.Category{BaseModel: db_models.BaseModel{ID: uuid.MustParse("44444444-4444-4444-4444-444444444444")}, Name: "Museum"}
+)
+
+// exportLookups serves the province and category lists; the exporter reads nothing else.
+type exportLookups struct {
+	repositories.POIImportRepositoryInterface
+}
+
+func (exportLookups) ListProvinces(ctx context.Context) ([]db_models.Province, error) {
+	return []db_models.Province{exportDaLat, exportHue}, nil
+}
+
+func (exportLookups) ListCategories(ctx context.Context) ([]db_models.Category, error) {
+	return []db_models.Category{exportCafe, exportMuseum}, nil
+}
+
+// exportRepo streams its POIs two at a time, so the writers see more than one batch.
+type exportRepo struct {
+	pois   []db_models.POI
+	filter repositories.POIExportFilter
+	calls  int
+}
+
+func (r *exportRepo) StreamPOIs(ctx context.Context, filter repositories.POIExportFilter, batchSize int, fn func([]db_models.POI) error) error {
+	r.calls++
+	r.filter = filter
+	for start := 0; start < len(r.pois); start += 2 {
+		end := min(start+2, len(r.pois))
+		if err := fn(r.pois[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func exportFixturePOIs() []db_models.POI {
+	updated := time.Date(2026, 3, 1, 8, 30, 0, 0, vnLoc).Unix()
+	return []db_models.POI{
+		{
+			BaseModel:    db_models.BaseModel{ID: uuid.MustParse("aaaaaaaa-0000-0000-0000-000000000001"), UpdatedAt: updated},
+			Name:         "Cà phê Tùng",
+			Latitude:     11.9416,
+			Longitude:    108.4383,
+			Province:     exportDaLat,
+			Category:     exportCafe,
+			Status:       "published",
+			Address:      "6 Khu Hòa Bình, Đà Lạt",
+			OpeningHours: "07:00-22:00",
+			ContactInfo:  "0263 3821 390",
+			Description:  `Old-school café, "since 1952", with jazz`,
+			Details:      db_models.POIDetail{Images: []string{"https://img/1.jpg", "https://img/2.jpg"}},
+			Tags:         []*db_models.Tag{{EnName: "coffee"}, nil, {EnName: "historic"}},
+		},
+		{
+			BaseModel: db_models.BaseModel{ID: uuid.MustParse("aaaaaaaa-0000-0000-0000-000000000002")},
+			Name:      "Bảo tàng <Cổ vật> & Cung đình",
+			Latitude:  16.4698,
+			Longitude: 107.5797,
+			Province:  exportHue,
+			Category:  exportMuseum,
+			Status:    "draft",
+			// No address, hours or contact: the empty cells in between must keep the columns aligned
+			Description: "Line one\nline two",
+			Tags:        []*db_models.Tag{{EnName: "culture"}},
+		},
+		{
+			BaseModel: db_models.BaseModel{ID: uuid.MustParse("aaaaaaaa-0000-0000-0000-000000000003"), UpdatedAt: updated},
+			Name:      "Hồ Xuân Hương",
+			Latitude:  11.9404,
+			Longitude: 108.4423,
+			Province:  exportDaLat,
+			Status:    "published",
+		},
+	}
+}
+
+// exportFixtureRows is what every format must read back as, in poiExportColumns order.
+var exportFixtureRows = [][]string{
+	{"aaaaaaaa-0000-0000-0000-000000000001", "Cà phê Tùng", "11.9416", "108.4383", "Đà Lạt", "Cafe", "published",
+		"6 Khu Hòa Bình, Đà Lạt", "07:00-22:00", "0263 3821 390", `Old-school café, "since 1952", with jazz`,
+		"https://img/1.jpg|https://img/2.jpg", "coffee|historic", "2026-03-01T08:30:00+07:00"},
+	{"aaaaaaaa-0000-0000-0000-000000000002", "Bảo tàng <Cổ vật> & Cung đình", "16.4698", "107.5797", "Huế", "Museum", "draft",
+		"", "", "", "Line one\nline two", "", "culture", ""},
+	{"aaaaaaaa-0000-0000-0000-000000000003", "Hồ Xuân Hương", "11.9404", "108.4423", "Đà Lạt", "", "published",
+		"", "", "", "", "", "", "2026-03-01T08:30:00+07:00"},
+}
+
+func TestExportPOIsRoundTrip(t *testing.T) {
+	tests := []struct {
+		format      string
+		contentType string
+		read        func(t *testing.T, data []byte) [][]string
+	}{
+		{ExportFormatCSV, "text/csv; charset=utf-8", readCSVExport},
+		{ExportFormatJSON, "application/json", readJSONExport},
+		{ExportFormatXLSX, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", readXLSXExport},
+		{"", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", readXLSXExport}, // xlsx by default
+	}
+
+	for _, tt := range tests {
+		t.Run("format="+tt.format, func(t *testing.T) {
+			repo := &exportRepo{pois: exportFixturePOIs()}
+			svc := NewExportService(exportLookups{}, repo)
+
+			file, err := svc.ExportPOIs(context.Background(), request_models.POIExportQuery{Format: tt.format})
+			if err != nil {
+				t.Fatalf("ExportPOIs() error = %v", err)
+			}
+			if repo.calls != 0 {
+				t.Fatalf("POIs were read before Write")
+			}
+			if file.ContentType != tt.contentType {
+				t.Errorf("ContentType = %q, want %q", file.ContentType, tt.contentType)
+			}
+			ext := tt.format
+			if ext == "" {
+				ext = ExportFormatXLSX
+			}
+			if !strings.HasPrefix(file.FileName, "pois_") || !strings.HasSuffix(file.FileName, "."+ext) {
+				t.Errorf("FileName = %q", file.FileName)
+			}
+
+			var buf bytes.Buffer
+			if err := file.Write(&buf); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if got := tt.read(t, buf.Bytes()); !reflect.DeepEqual(got, exportFixtureRows) {
+				t.Errorf("read back\n%q\nwant\n%q", got, exportFixtureRows)
+			}
+		})
+	}
+}
+
+func TestExportPOIsEmpty(t *testing.T) {
+	for _, format := range []string{ExportFormatCSV, ExportFormatJSON, ExportFormatXLSX} {
+		t.Run(format, func(t *testing.T) {
+			file, err := NewExportService(exportLookups{}, &exportRepo{}).
+				ExportPOIs(context.Background(), request_models.POIExportQuery{Format: format})
+			if err != nil {
+				t.Fatalf("ExportPOIs() error = %v", err)
+			}
+			var buf bytes.Buffer
+			if err := file.Write(&buf); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			var rows [][]string
+			switch format {
+			case ExportFormatCSV:
+				rows = readCSVExport(t, buf.Bytes())
+			case ExportFormatJSON:
+				rows = readJSONExport(t, buf.Bytes())
+			default:
+				rows = readXLSXExport(t, buf.Bytes())
+			}
+			if len(rows) != 0 {
+				t.Errorf("got %d rows, want none", len(rows))
+			}
+		})
+	}
+}
+
+func TestExportPOIsFilters(t *testing.T) {
+	vnDay := time.Date(2026, 1, 2, 0, 0, 0, 0, vnLoc).Unix()
+
+	tests := []struct {
+		name  string
+		query request_models.POIExportQuery
+		want  repositories.POIExportFilter
+	}{
+		{
+			name: "no filter",
+		},
+		{
+			name:  "province by name without diacritics",
+			query: request_models.POIExportQuery{Province: "  da   LAT "},
+			want:  repositories.POIExportFilter{ProvinceID: &exportDaLat.ID},
+		},
+		{
+			name:  "province by id",
+			query: request_models.POIExportQuery{Province: exportHue.ID.String()},
+			want:  repositories.POIExportFilter{ProvinceID: &exportHue.ID},
+		},
+		{
+			name:  "category by name",
+			query: request_models.POIExportQuery{Category: "museum"},
+			want:  repositories.POIExportFilter{CategoryID: &exportMuseum.ID},
+		},
+		{
+			name:  "updated since RFC3339",
+			query: request_models.POIExportQuery{UpdatedSince: "2026-01-02T03:04:05Z"},
+			want:  repositories.POIExportFilter{UpdatedSince: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC).Unix()},
+		},
+		{
+			name:  "updated since a VN date",
+			query: request_models.POIExportQuery{UpdatedSince: "2026-01-02"},
+			want:  repositories.POIExportFilter{UpdatedSince: vnDay},
+		},
+		{
+			name:  "updated since unix seconds",
+			query: request_models.POIExportQuery{UpdatedSince: " " + strconv.FormatInt(vnDay, 10) + " "},
+			want:  repositories.POIExportFilter{UpdatedSince: vnDay},
+		},
+		{
+			name: "everything",
+			query: request_models.POIExportQuery{
+				Format: "CSV", Province: "Huế", Category: exportCafe.ID.String(), UpdatedSince: "2026-01-02",
+			},
+			want: repositories.POIExportFilter{ProvinceID: &exportHue.ID, CategoryID: &exportCafe.ID, UpdatedSince: vnDay},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &exportRepo{}
+			file, err := NewExportService(exportLookups{}, repo).ExportPOIs(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("ExportPOIs() error = %v", err)
+			}
+			if err := file.Write(&bytes.Buffer{}); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if !reflect.DeepEqual(repo.filter, tt.want) {
+				t.Errorf("filter = %+v, want %+v", repo.filter, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportPOIsBadInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		query request_models.POIExportQuery
+	}{
+		{"unknown format", request_models.POIExportQuery{Format: "pdf"}},
+		{"unknown province", request_models.POIExportQuery{Province: "Atlantis"}},
+		{"unknown province id", request_models.POIExportQuery{Province: uuid.NewString()}},
+		{"category id of a province", request_models.POIExportQuery{Category: exportDaLat.ID.String()}},
+		{"unknown category", request_models.POIExportQuery{Category: "Spa"}},
+		{"updated since words", request_models.POIExportQuery{UpdatedSince: "yesterday"}},
+		{"updated since zero", request_models.POIExportQuery{UpdatedSince: "0"}},
+		{"updated since negative", request_models.POIExportQuery{UpdatedSince: "-5"}},
+		{"updated since bad date", request_models.POIExportQuery{UpdatedSince: "2026-13-40"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &exportRepo{}
+			_, err := NewExportService(exportLookups{}, repo).ExportPOIs(context.Background(), tt.query)
+			if !errors.Is(err, utils.ErrInvalidInput) {
+				t.Fatalf("ExportPOIs() error = %v, want %v", err, utils.ErrInvalidInput)
+			}
+			if repo.calls != 0 {
+				t.Errorf("POIs were read for an invalid query")
+			}
+		})
+	}
+}
+
+func readCSVExport(t *testing.T, data []byte) [][]string {
+	t.Helper()
+	data, ok := bytes.CutPrefix(data, []byte("\xef\xbb\xbf"))
+	if !ok {
+		t.Fatalf("CSV export has no BOM")
+	}
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV: %v", err)
+	}
+	return exportDataRows(t, records)
+}
+
+func readJSONExport(t *testing.T, data []byte) [][]string {
+	t.Helper()
+	var records []poiExportRow
+	if err := json.Unmarshal(data, &records); err != nil {
+		t.Fatalf("reading JSON: %v\n%s", err, data)
+	}
+	var rows [][]string
+	for _, r := range records {
+		rows = append(rows, []string{
+			r.ID, r.Name,
+			strconv.FormatFloat(r.Latitude, 'f', -1, 64), strconv.FormatFloat(r.Longitude, 'f', -1, 64),
+			r.Province, r.Category, r.Status, r.Address, r.OpeningHours, r.ContactInfo, r.Description,
+			strings.Join(r.Images, "|"), strings.Join(r.Tags, "|"), r.UpdatedAt,
+		})
+	}
+	return rows
+}
+
+func readXLSXExport(t *testing.T, data []byte) [][]string {
+	t.Helper()
+	records, err := utils.ReadXLSXRows(data)
+	if err != nil {
+		t.Fatalf("reading XLSX: %v", err)
+	}
+	// Empty cells are not written, so a row stops at its last value
+	for i := range records {
+		for len(records[i]) < len(poiExportColumns) {
+			records[i] = append(records[i], "")
+		}
+	}
+	return exportDataRows(t, records)
+}
+
+// exportDataRows checks the header row and returns the rows under it.
+func exportDataRows(t *testing.T, records [][]string) [][]string {
+	t.Helper()
+	if len(records) == 0 || !reflect.DeepEqual(records[0], poiExportColumns) {
+		t.Fatalf("header = %q, want %q", records, poiExportColumns)
+	}
+	if len(records) == 1 {
+		return nil
+	}
+	return records[1:]
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"vivu/pkg/utils"
+)
+
+// ErrStoredFileNotFound is returned by FileStorage.Get for an unknown key.
+var ErrStoredFileNotFound = errors.New("stored file not found")
+
+// FileStorage keeps binary objects under slash-separated keys.
+type FileStorage interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type localFileStorage struct {
+	root string
+}
+
+// NewFileStorage stores files under STORAGE_DIR (default ./data/storage), encrypted at rest with
+// DATA_ENCRYPTION_KEY.
+func NewFileStorage() FileStorage {
+	root := os.Getenv("STORAGE_DIR")
+	if root == "" {
+		root = filepath.Join("data", "storage")
+	}
+	return &encryptedFileStorage{inner: &localFileStorage{root: root}}
+}
+
+func (s *localFileStorage) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if clean == "." || filepath.IsAbs(clean) || strings.HasPrefix(clean, "..") {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return filepath.Join(s.root, clean), nil
+}
+
+func (s *localFileStorage) Put(ctx context.Context, key string, data []byte) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return err
+	}
+	// Write then rename so a crash never leaves half a file under the key
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p)
+}
+
+func (s *localFileStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrStoredFileNotFound
+	}
+	return data, err
+}
+
+func (s *localFileStorage) Delete(ctx context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// encryptedFileStorage seals objects before they reach the backend.
+type encryptedFileStorage struct {
+	inner FileStorage
+}
+
+func (s *encryptedFileStorage) Put(ctx context.Context, key string, data []byte) error {
+	sealed, err := utils.EncryptBytes(data)
+	if err != nil {
+		return err
+	}
+	return s.inner.Put(ctx, key, sealed)
+}
+
+func (s *encryptedFileStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	sealed, err := s.inner.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return utils.DecryptBytes(sealed)
+}
+
+func (s *encryptedFileStorage) Delete(ctx context.Context, key string) error {
+	return s.inner.Delete(ctx, key)
+}
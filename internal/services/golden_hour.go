@@ -0,0 +1,149 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/response_models"
+)
+
+// Words (diacritics folded) that mark a POI as worth visiting at sunrise or sunset,
+// matched against its name, category and tags.
+var goldenHourKeywords = []string{
+	"viewpoint", "view point", "lookout", "observation", "photography", "photo spot", "scenic",
+	"sunset", "sunrise", "ngam canh", "diem ngam", "dai quan sat", "hoang hon", "binh minh",
+}
+
+func isGoldenHourPOI(p *db_models.POI) bool {
+	fields := []string{p.Name, p.Category.Name}
+	for _, t := range p.Tags {
+		if t != nil {
+			fields = append(fields, t.EnName, t.ViName)
+		}
+	}
+	for _, f := range fields {
+		f = lookupKey(f)
+		for _, kw := range goldenHourKeywords {
+			if strings.Contains(f, kw) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sunTimes returns sunrise and sunset (VN time) for the calendar day of date at the given point,
+// using the standard sunrise equation (about a minute of error). ok is false during polar day/night.
+func sunTimes(date time.Time, lat, lng float64) (sunrise, sunset time.Time, ok bool) {
+	const rad = math.Pi / 180
+	d := date.In(vnLoc)
+	noon := time.Date(d.Year(), d.Month(), d.Day(), 12, 0, 0, 0, vnLoc)
+	jd := float64(noon.Unix())/86400 + 2440587.5
+
+	n := math.Round(jd - 2451545.0 + 0.0008)
+	jStar := n - lng/360
+	m := math.Mod(357.5291+0.98560028*jStar, 360)
+	c := 1.9148*math.Sin(m*rad) + 0.02*math.Sin(2*m*rad) + 0.0003*math.Sin(3*m*rad)
+	lambda := math.Mod(m+c+180+102.9372, 360)
+	transit := 2451545.0 + jStar + 0.0053*math.Sin(m*rad) - 0.0069*math.Sin(2*lambda*rad)
+
+	sinDecl := math.Sin(lambda*rad) * math.Sin(23.4397*rad)
+	cosDecl := math.Cos(math.Asin(sinDecl))
+	cosOmega := (math.Sin(-0.833*rad) - math.Sin(lat*rad)*sinDecl) / (math.Cos(lat*rad) * cosDecl)
+	if cosOmega < -1 || cosOmega > 1 {
+		return time.Time{}, time.Time{}, false
+	}
+	omega := math.Acos(cosOmega) / rad
+
+	toTime := func(j float64) time.Time {
+		return time.Unix(int64(math.Round((j-2440587.5)*86400)), 0).In(vnLoc)
+	}
+	return toTime(transit - omega/360), toTime(transit + omega/360), true
+}
+
+// placeGoldenHour moves photography/viewpoint activities to sunset (or sunrise when the evening is
+// taken) on their day, if that slot fits the day window and does not clash with other activities.
+// Every such activity gets a note with the time, moved or not. Returns one line per move.
+func placeGoldenHour(plan *response_models.PlanOnly, startDate time.Time, golden map[string]bool, window WorkingWindow) []string {
+	var moves []string
+	for di := range plan.Days {
+		day := &plan.Days[di]
+		date := startDate.AddDate(0, 0, day.Day-1)
+		moved := false
+
+		for ai := range day.Activities {
+			a := &day.Activities[ai]
+			if !golden[a.MainPOIID] || a.MainPOI == nil || !hasCoords(a.MainPOI.Latitude, a.MainPOI.Longitude) {
+				continue
+			}
+			rise, set, ok := sunTimes(date, a.MainPOI.Latitude, a.MainPOI.Longitude)
+			if !ok {
+				continue
+			}
+			start, okStart := clockMinutes(a.StartTime)
+			end, okEnd := clockMinutes(a.EndTime)
+			if !okStart || !okEnd || end <= start {
+				continue
+			}
+			dur := max(end-start, 60)
+
+			sunsetMin := set.Hour()*60 + set.Minute()
+			sunriseMin := rise.Hour()*60 + rise.Minute()
+			// Arrive before the light turns golden and stay until just after the sun is gone / up
+			candidates := []struct {
+				label      string
+				at         int
+				start, end int
+			}{
+				{"sunset", sunsetMin, sunsetMin + 15 - dur, sunsetMin + 15},
+				{"sunrise", sunriseMin, sunriseMin - 15, sunriseMin - 15 + dur},
+			}
+
+			a.Note = fmt.Sprintf("Golden hour: sunset at %s, sunrise at %s", formatClock(sunsetMin), formatClock(sunriseMin))
+			for _, cand := range candidates {
+				if cand.start < 0 || cand.end > 24*60 || !window.contains(cand.start, cand.end) {
+					continue
+				}
+				if start == cand.start && end == cand.end {
+					a.Note = fmt.Sprintf("Golden hour: timed for %s (%s)", cand.label, formatClock(cand.at))
+					break
+				}
+				if clashesWithDay(day.Activities, ai, cand.start, cand.end) {
+					continue
+				}
+				moves = append(moves, fmt.Sprintf("Day %d: moved %s to %s-%s for %s", day.Day, planActivityLabel(*a), formatClock(cand.start), formatClock(cand.end), cand.label))
+				a.StartTime, a.EndTime = formatClock(cand.start), formatClock(cand.end)
+				a.Note = fmt.Sprintf("Golden hour: timed for %s (%s)", cand.label, formatClock(cand.at))
+				moved = true
+				break
+			}
+		}
+
+		if moved {
+			sort.SliceStable(day.Activities, func(i, j int) bool {
+				si, _ := clockMinutes(day.Activities[i].StartTime)
+				sj, _ := clockMinutes(day.Activities[j].StartTime)
+				return si < sj
+			})
+		}
+	}
+	return moves
+}
+
+func clashesWithDay(acts []response_models.PlanOnlyActivity, skip, start, end int) bool {
+	for i, other := range acts {
+		if i == skip {
+			continue
+		}
+		otherStart, ok1 := clockMinutes(other.StartTime)
+		otherEnd, ok2 := clockMinutes(other.EndTime)
+		if ok1 && ok2 && otherStart < end && start < otherEnd {
+			return true
+		}
+	}
+	return false
+}
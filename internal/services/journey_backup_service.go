@@ -0,0 +1,442 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+// journeyBackupFormat is written into every file of a backup; bump it when the layout changes.
+const journeyBackupFormat = 1
+
+const (
+	journeyBackupWindow      = 24 * time.Hour // the daily limit counts requests in this window
+	journeyBackupClaimLease  = 10 * time.Minute
+	journeyBackupMaxAttempts = 3
+)
+
+type JourneyBackupServiceInterface interface {
+	// Request returns the account's backup: the file while a ready one has not expired,
+	// otherwise the job building it, queued when there is none. refresh queues a new backup
+	// even when one is ready. Queuing is limited to JOURNEY_BACKUP_DAILY_LIMIT a day.
+	Request(ctx context.Context, accountID string, refresh bool) (*FileExport, *response_models.JourneyBackupJob, error)
+
+	// RunOnce builds the queued backups, deletes the expired ones and returns how many were built.
+	RunOnce(ctx context.Context) (int, error)
+	Start()
+	Stop()
+}
+
+type JourneyBackupService struct {
+	repo        repositories.JourneyBackupRepositoryInterface
+	accountRepo repositories.AccountRepository
+	storage     FileStorage
+	mail        IMailService
+	appURL      string
+
+	interval   time.Duration
+	ttl        time.Duration
+	dailyLimit int
+
+	wake     chan struct{}
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// NewJourneyBackupService reads JOURNEY_BACKUP_INTERVAL (default 1m), how often the queue is
+// polled, JOURNEY_BACKUP_TTL (default 168h), how long a backup can be downloaded,
+// JOURNEY_BACKUP_DAILY_LIMIT (default 3) and APP_PUBLIC_URL for the link of the email.
+func NewJourneyBackupService(repo repositories.JourneyBackupRepositoryInterface, accountRepo repositories.AccountRepository, storage FileStorage, mail IMailService) JourneyBackupServiceInterface {
+	s := &JourneyBackupService{
+		repo:        repo,
+		accountRepo: accountRepo,
+		storage:     storage,
+		mail:        mail,
+		appURL:      "https://vivu.com",
+		interval:    time.Minute,
+		ttl:         7 * 24 * time.Hour,
+		dailyLimit:  3,
+		wake:        make(chan struct{}, 1),
+		stop:        make(chan struct{}),
+	}
+	if d, err := time.ParseDuration(os.Getenv("JOURNEY_BACKUP_INTERVAL")); err == nil && d > 0 {
+		s.interval = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("JOURNEY_BACKUP_TTL")); err == nil && d > 0 {
+		s.ttl = d
+	}
+	if n, err := strconv.Atoi(os.Getenv("JOURNEY_BACKUP_DAILY_LIMIT")); err == nil && n > 0 {
+		s.dailyLimit = n
+	}
+	if u := strings.TrimRight(os.Getenv("APP_PUBLIC_URL"), "/"); u != "" {
+		s.appURL = u
+	}
+	return s
+}
+
+func (s *JourneyBackupService) Request(ctx context.Context, accountID string, refresh bool) (*FileExport, *response_models.JourneyBackupJob, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, nil, utils.ErrInvalidInput.WithMessage("Invalid account ID")
+	}
+	latest, err := s.repo.LatestJob(ctx, id)
+	if err != nil {
+		return nil, nil, utils.ErrDatabaseError
+	}
+	now := time.Now()
+
+	if latest != nil && latest.Status == db_models.JourneyBackupPending {
+		return nil, toJourneyBackupJob(latest), nil
+	}
+	if latest != nil && latest.Status == db_models.JourneyBackupReady && !refresh &&
+		latest.ExpiresAt != nil && *latest.ExpiresAt > now.Unix() {
+		data, err := s.storage.Get(ctx, latest.StorageKey)
+		switch {
+		case err == nil:
+			return &FileExport{
+				FileName:    latest.FileName,
+				ContentType: "application/zip",
+				Write: func(w io.Writer) error {
+					_, err := w.Write(data)
+					return err
+				},
+			}, nil, nil
+		case errors.Is(err, ErrStoredFileNotFound):
+			// Lost with its storage; a new backup is queued below
+			_ = s.repo.MarkExpired(ctx, latest.ID)
+		default:
+			log.Printf("[journey-backup] read %s: %v", latest.StorageKey, err)
+			return nil, nil, utils.ErrStorageNotConfigured
+		}
+	}
+
+	requested, err := s.repo.RequestedSince(ctx, id, now.Add(-journeyBackupWindow).Unix())
+	if err != nil {
+		return nil, nil, utils.ErrDatabaseError
+	}
+	if len(requested) >= s.dailyLimit {
+		retry := time.Unix(requested[len(requested)-s.dailyLimit], 0).Add(journeyBackupWindow).Sub(now)
+		return nil, nil, utils.ErrJourneyBackupLimited.WithRetryAfter(retry)
+	}
+
+	job := &db_models.JourneyBackupJob{AccountID: id, Status: db_models.JourneyBackupPending}
+	if err := s.repo.CreateJob(ctx, job); err != nil {
+		return nil, nil, utils.ErrDatabaseError
+	}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return nil, toJourneyBackupJob(job), nil
+}
+
+func toJourneyBackupJob(job *db_models.JourneyBackupJob) *response_models.JourneyBackupJob {
+	out := &response_models.JourneyBackupJob{
+		ID:          job.ID.String(),
+		Status:      job.Status,
+		RequestedAt: utils.FormatRFC3339VN(time.Unix(job.CreatedAt, 0)),
+		Journeys:    job.Journeys,
+		SizeBytes:   job.SizeBytes,
+	}
+	if job.CompletedAt != nil {
+		out.CompletedAt = utils.FormatRFC3339VN(time.Unix(*job.CompletedAt, 0))
+	}
+	if job.ExpiresAt != nil {
+		out.ExpiresAt = utils.FormatRFC3339VN(time.Unix(*job.ExpiresAt, 0))
+	}
+	return out
+}
+
+func (s *JourneyBackupService) RunOnce(ctx context.Context) (int, error) {
+	s.deleteExpired(ctx)
+
+	built := 0
+	for {
+		if ctx.Err() != nil {
+			return built, ctx.Err()
+		}
+		job, err := s.repo.ClaimPending(ctx, journeyBackupClaimLease)
+		if err != nil {
+			return built, err
+		}
+		if job == nil {
+			return built, nil
+		}
+		if err := s.build(ctx, job); err != nil {
+			log.Printf("[journey-backup] job %s: %v", job.ID, err)
+			if markErr := s.repo.MarkFailed(ctx, job.ID, err.Error(), journeyBackupMaxAttempts); markErr != nil {
+				log.Printf("[journey-backup] failed to record error: %v", markErr)
+			}
+			continue
+		}
+		built++
+	}
+}
+
+// build writes the zip of the job, stores it and emails the owner. Failing to queue the email
+// does not fail the job: the file is there and the app shows it.
+func (s *JourneyBackupService) build(ctx context.Context, job *db_models.JourneyBackupJob) error {
+	account, err := s.accountRepo.FindById(ctx, job.AccountID.String())
+	if err != nil {
+		return err
+	}
+	if account == nil {
+		return fmt.Errorf("account %s not found", job.AccountID)
+	}
+
+	now := time.Now()
+	var buf bytes.Buffer
+	count, err := s.writeZip(ctx, &buf, job.AccountID, now)
+	if err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("backups/%s/%s.zip", job.AccountID, job.ID)
+	if err := s.storage.Put(ctx, key, buf.Bytes()); err != nil {
+		return err
+	}
+	completed, expires := now.Unix(), now.Add(s.ttl).Unix()
+	job.StorageKey = key
+	job.FileName = fmt.Sprintf("vivu_journeys_%s.zip", now.In(vnLoc).Format("20060102_1504"))
+	job.SizeBytes = int64(buf.Len())
+	job.Journeys = count
+	job.CompletedAt = &completed
+	job.ExpiresAt = &expires
+	if err := s.repo.MarkReady(ctx, job); err != nil {
+		_ = s.storage.Delete(ctx, key)
+		return err
+	}
+
+	if s.mail != nil && account.Email != "" {
+		err := s.mail.SendLocalized(account.Email, account.Locale, MailTemplateJourneyBackup, map[string]string{
+			"name":    account.Name,
+			"count":   strconv.Itoa(count),
+			"expires": time.Unix(expires, 0).In(vnLoc).Format("02/01/2006 15:04"),
+			"url":     s.appURL + "/account/backup",
+		})
+		if err != nil {
+			log.Printf("[journey-backup] email for job %s: %v", job.ID, err)
+		}
+	}
+	return nil
+}
+
+// journeyBackupManifest is manifest.json at the root of a backup.
+type journeyBackupManifest struct {
+	FormatVersion int                          `json:"format_version"`
+	AccountID     string                       `json:"account_id"`
+	ExportedAt    string                       `json:"exported_at"`
+	Journeys      []journeyBackupManifestEntry `json:"journeys"`
+}
+
+type journeyBackupManifestEntry struct {
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	File       string `json:"file"`
+	StartDate  string `json:"start_date,omitempty"`
+	Days       int    `json:"days"`
+	Activities int    `json:"activities"`
+	CheckIns   int    `json:"check_ins"`
+	Expenses   int    `json:"expenses"`
+}
+
+// journeyBackupFile is one journeys/<id>.json of a backup. Amounts are in VND.
+type journeyBackupFile struct {
+	FormatVersion int                                    `json:"format_version"`
+	Journey       *response_models.JourneyDetailResponse `json:"journey"`
+	CreatedAt     string                                 `json:"created_at"`
+	UpdatedAt     string                                 `json:"updated_at"`
+	CheckIns      []journeyBackupCheckIn                 `json:"check_ins"`
+	Budget        *int64                                 `json:"budget,omitempty"`
+	Expenses      []journeyBackupExpense                 `json:"expenses"`
+}
+
+type journeyBackupCheckIn struct {
+	ID         string   `json:"id"`
+	POIID      string   `json:"poi_id"`
+	POIName    string   `json:"poi_name,omitempty"`
+	ActivityID string   `json:"activity_id,omitempty"`
+	Notes      string   `json:"notes,omitempty"`
+	Stars      int      `json:"stars,omitempty"`
+	Photos     []string `json:"photos,omitempty"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+type journeyBackupExpense struct {
+	ID         string `json:"id"`
+	ActivityID string `json:"activity_id,omitempty"`
+	Amount     int64  `json:"amount"`
+	Category   string `json:"category"`
+	Merchant   string `json:"merchant,omitempty"`
+	Note       string `json:"note,omitempty"`
+	SpentAt    string `json:"spent_at"`
+	LoggedBy   string `json:"logged_by"`
+}
+
+// writeZip writes the backup a journey at a time, so only one journey is in memory besides
+// the zip itself, and returns how many journeys it holds.
+func (s *JourneyBackupService) writeZip(ctx context.Context, w io.Writer, accountID uuid.UUID, at time.Time) (int, error) {
+	ids, err := s.repo.AccountJourneyIDs(ctx, accountID)
+	if err != nil {
+		return 0, err
+	}
+
+	zw := zip.NewWriter(w)
+	manifest := journeyBackupManifest{
+		FormatVersion: journeyBackupFormat,
+		AccountID:     accountID.String(),
+		ExportedAt:    utils.FormatRFC3339VN(at),
+		Journeys:      make([]journeyBackupManifestEntry, 0, len(ids)),
+	}
+	for _, id := range ids {
+		j, err := s.repo.LoadJourney(ctx, id)
+		if err != nil {
+			return 0, err
+		}
+		if j == nil {
+			continue // deleted while the backup ran
+		}
+		budget, expenses, err := s.repo.JourneyMoney(ctx, id)
+		if err != nil {
+			return 0, err
+		}
+		file := journeyBackupContent(j, budget, expenses)
+		name := fmt.Sprintf("journeys/%s.json", id)
+		if err := writeZipJSON(zw, name, at, file); err != nil {
+			return 0, err
+		}
+		manifest.Journeys = append(manifest.Journeys, journeyBackupManifestEntry{
+			ID:         id.String(),
+			Title:      j.Title,
+			File:       name,
+			StartDate:  file.Journey.StartDate,
+			Days:       file.Journey.TotalDays,
+			Activities: file.Journey.TotalActivities,
+			CheckIns:   len(file.CheckIns),
+			Expenses:   len(file.Expenses),
+		})
+	}
+	if err := writeZipJSON(zw, "manifest.json", at, manifest); err != nil {
+		return 0, err
+	}
+	return len(manifest.Journeys), zw.Close()
+}
+
+func writeZipJSON(zw *zip.Writer, name string, at time.Time, v any) error {
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: at})
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func journeyBackupContent(j *db_models.Journey, budget *int64, expenses []db_models.JourneyExpense) journeyBackupFile {
+	out := journeyBackupFile{
+		FormatVersion: journeyBackupFormat,
+		Journey:       db_models.BuildJourneyDetailResponse(j),
+		CreatedAt:     utils.FormatRFC3339VN(time.Unix(j.CreatedAt, 0)),
+		UpdatedAt:     utils.FormatRFC3339VN(time.Unix(j.UpdatedAt, 0)),
+		CheckIns:      make([]journeyBackupCheckIn, 0, len(j.CheckIns)),
+		Budget:        budget,
+		Expenses:      make([]journeyBackupExpense, 0, len(expenses)),
+	}
+	for _, c := range j.CheckIns {
+		entry := journeyBackupCheckIn{
+			ID:        c.ID.String(),
+			POIID:     c.POIID.String(),
+			POIName:   c.POI.Name,
+			Notes:     c.Notes,
+			Stars:     c.Stars,
+			CreatedAt: utils.FormatRFC3339VN(time.Unix(c.CreatedAt, 0)),
+		}
+		if c.ActivityID != nil {
+			entry.ActivityID = c.ActivityID.String()
+		}
+		for _, p := range c.Photos {
+			entry.Photos = append(entry.Photos, p.URL)
+		}
+		out.CheckIns = append(out.CheckIns, entry)
+	}
+	for _, e := range expenses {
+		entry := journeyBackupExpense{
+			ID:       e.ID.String(),
+			Amount:   e.Amount,
+			Category: e.Category,
+			Merchant: e.Merchant,
+			Note:     e.Note,
+			SpentAt:  utils.FormatRFC3339VN(time.Unix(e.SpentAt, 0)),
+			LoggedBy: e.AccountID.String(),
+		}
+		if e.ActivityID != nil {
+			entry.ActivityID = e.ActivityID.String()
+		}
+		out.Expenses = append(out.Expenses, entry)
+	}
+	return out
+}
+
+// deleteExpired removes the files of expired backups; a failed delete is retried next run.
+func (s *JourneyBackupService) deleteExpired(ctx context.Context) {
+	jobs, err := s.repo.ExpiredReady(ctx, time.Now().Unix())
+	if err != nil {
+		log.Printf("[journey-backup] expired backups: %v", err)
+		return
+	}
+	for _, job := range jobs {
+		if err := s.storage.Delete(ctx, job.StorageKey); err != nil {
+			log.Printf("[journey-backup] delete %s: %v", job.StorageKey, err)
+			continue
+		}
+		if err := s.repo.MarkExpired(ctx, job.ID); err != nil {
+			log.Printf("[journey-backup] expire job %s: %v", job.ID, err)
+		}
+	}
+}
+
+// Start builds queued backups in the background until Stop is called; a new request wakes it.
+func (s *JourneyBackupService) Start() {
+	go func() {
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+		for {
+			select {
+			case <-timer.C:
+			case <-s.wake:
+			case <-s.stop:
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+			n, err := s.RunOnce(ctx)
+			cancel()
+			if err != nil {
+				log.Printf("[journey-backup] run failed: %v", err)
+			} else if n > 0 {
+				log.Printf("[journey-backup] built %d backups", n)
+			}
+			timer.Reset(s.interval)
+		}
+	}()
+}
+
+func (s *JourneyBackupService) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
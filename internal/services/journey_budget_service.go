@@ -0,0 +1,394 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+type JourneyBudgetServiceInterface interface {
+	// SetBudget sets the trip budget (owner and editors) and re-arms the over-budget alert.
+	SetBudget(ctx context.Context, journeyID, userID string, req request_models.SetBudgetRequest) (*response_models.BudgetSummary, error)
+	RemoveBudget(ctx context.Context, journeyID, userID string) error
+	// GetSummary returns nil without error when the trip has no budget.
+	GetSummary(ctx context.Context, journeyID, userID string) (*response_models.BudgetSummary, error)
+
+	// LogExpense records an expense and alerts the participants the first time the projected
+	// spend goes over the budget.
+	LogExpense(ctx context.Context, journeyID, userID string, req request_models.CreateExpenseRequest) (*response_models.ExpenseLogged, error)
+	ListExpenses(ctx context.Context, journeyID, userID string) ([]response_models.JourneyExpense, error)
+	DeleteExpense(ctx context.Context, journeyID, userID, expenseID string) error
+
+	// ScanReceipt reads a receipt photo into an expense draft; nothing is saved.
+	ScanReceipt(ctx context.Context, journeyID, userID string, image []byte, mimeType string) (*response_models.ReceiptDraft, error)
+}
+
+// MaxReceiptImageBytes caps receipt photos sent to ScanReceipt.
+const MaxReceiptImageBytes = 8 << 20
+
+var receiptImageTypes = map[string]bool{
+	"image/jpeg": true, "image/png": true, "image/webp": true, "image/heic": true, "image/heif": true,
+}
+
+type JourneyBudgetService struct {
+	repo     repositories.JourneyBudgetRepositoryInterface
+	pollRepo repositories.JourneyPollRepositoryInterface
+	mail     IMailService
+	scanner  utils.ReceiptScanner // nil when no vision model is configured
+	appURL   string
+}
+
+// NewJourneyBudgetService reads APP_PUBLIC_URL for the links in alert emails. scanner may be
+// nil, receipt scanning is then unavailable.
+func NewJourneyBudgetService(repo repositories.JourneyBudgetRepositoryInterface, pollRepo repositories.JourneyPollRepositoryInterface, mail IMailService, scanner utils.ReceiptScanner) JourneyBudgetServiceInterface {
+	s := &JourneyBudgetService{repo: repo, pollRepo: pollRepo, mail: mail, scanner: scanner, appURL: "https://vivu.com"}
+	if u := strings.TrimRight(os.Getenv("APP_PUBLIC_URL"), "/"); u != "" {
+		s.appURL = u
+	}
+	return s
+}
+
+func (s *JourneyBudgetService) SetBudget(ctx context.Context, journeyID, userID string, req request_models.SetBudgetRequest) (*response_models.BudgetSummary, error) {
+	journey, _, role, err := journeyAccess(ctx, s.pollRepo, journeyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role == db_models.JourneyRoleViewer {
+		return nil, utils.ErrUnauthorized.WithMessage("Viewers cannot change the budget")
+	}
+	if err := s.repo.UpsertBudget(ctx, journey.ID, req.Amount); err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	return s.summary(ctx, journey, time.Now())
+}
+
+func (s *JourneyBudgetService) RemoveBudget(ctx context.Context, journeyID, userID string) error {
+	journey, _, role, err := journeyAccess(ctx, s.pollRepo, journeyID, userID)
+	if err != nil {
+		return err
+	}
+	if role == db_models.JourneyRoleViewer {
+		return utils.ErrUnauthorized.WithMessage("Viewers cannot change the budget")
+	}
+	if err := s.repo.DeleteBudget(ctx, journey.ID); err != nil {
+		return utils.ErrDatabaseError.Wrap(err)
+	}
+	return nil
+}
+
+func (s *JourneyBudgetService) GetSummary(ctx context.Context, journeyID, userID string) (*response_models.BudgetSummary, error) {
+	journey, _, _, err := journeyAccess(ctx, s.pollRepo, journeyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.summary(ctx, journey, time.Now())
+}
+
+func (s *JourneyBudgetService) summary(ctx context.Context, journey *db_models.Journey, now time.Time) (*response_models.BudgetSummary, error) {
+	budget, err := s.repo.FindBudget(ctx, journey.ID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	if budget == nil {
+		return nil, nil
+	}
+	spent, err := s.repo.TotalSpent(ctx, journey.ID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	out := budgetSummary(budget.Amount, spent, journey, now)
+	return &out, nil
+}
+
+func (s *JourneyBudgetService) LogExpense(ctx context.Context, journeyID, userID string, req request_models.CreateExpenseRequest) (*response_models.ExpenseLogged, error) {
+	journey, members, role, err := journeyAccess(ctx, s.pollRepo, journeyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role == db_models.JourneyRoleViewer {
+		return nil, utils.ErrUnauthorized.WithMessage("Viewers cannot log expenses")
+	}
+
+	expense := &db_models.JourneyExpense{
+		JourneyID: journey.ID,
+		AccountID: uuid.MustParse(userID),
+		Amount:    req.Amount,
+		Category:  req.Category,
+		Merchant:  strings.TrimSpace(req.Merchant),
+		Note:      strings.TrimSpace(req.Note),
+		SpentAt:   time.Now().Unix(),
+	}
+	if req.SpentAt != "" {
+		at, err := time.Parse(time.RFC3339, req.SpentAt)
+		if err != nil {
+			return nil, utils.ErrInvalidInput.WithMessage("spent_at must be an RFC3339 time")
+		}
+		expense.SpentAt = at.Unix()
+	}
+	if req.ActivityID != "" {
+		activity, err := s.pollRepo.FindActivity(ctx, journey.ID, uuid.MustParse(req.ActivityID))
+		if err != nil {
+			return nil, utils.ErrDatabaseError.Wrap(err)
+		}
+		if activity == nil {
+			return nil, utils.ErrInvalidInput.WithMessage("Activity not found in this journey")
+		}
+		expense.ActivityID = &activity.ID
+	}
+	if err := s.repo.CreateExpense(ctx, expense); err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+
+	out := &response_models.ExpenseLogged{Expense: toJourneyExpenseResponse(*expense)}
+	out.Budget, err = s.summary(ctx, journey, time.Now())
+	if err != nil {
+		// The expense is saved; the summary can be fetched again
+		log.Printf("[budget] summary of journey %s: %v", journey.ID, err)
+		return out, nil
+	}
+	s.checkAlert(ctx, journey, members, out.Budget)
+	return out, nil
+}
+
+// checkAlert sends the over-budget alert once per overrun and re-arms it when the projection
+// is back under the budget.
+func (s *JourneyBudgetService) checkAlert(ctx context.Context, journey *db_models.Journey, members []db_models.JourneyMember, summary *response_models.BudgetSummary) {
+	if summary == nil {
+		return
+	}
+	if !summary.ProjectedOver {
+		if _, err := s.repo.SetAlertSent(ctx, journey.ID, nil); err != nil {
+			log.Printf("[budget] re-arm alert of journey %s: %v", journey.ID, err)
+		}
+		return
+	}
+	now := time.Now().Unix()
+	first, err := s.repo.SetAlertSent(ctx, journey.ID, &now)
+	if err != nil {
+		log.Printf("[budget] mark alert of journey %s: %v", journey.ID, err)
+		return
+	}
+	if !first || s.mail == nil {
+		return
+	}
+
+	subject := fmt.Sprintf("%s is heading over budget", journey.Title)
+	body := fmt.Sprintf("At %s a day, the trip would cost about %s against a budget of %s.",
+		formatVND(summary.DailyBurn), formatVND(summary.Projected), formatVND(summary.Budget))
+	if summary.DaysRemaining > 0 && summary.Remaining > 0 {
+		body += fmt.Sprintf(" To stay within it, keep to %s a day for the %d days left.", formatVND(summary.SafeDailySpend), summary.DaysRemaining)
+	}
+	link := fmt.Sprintf("%s/journeys/%s", s.appURL, journey.ID)
+	participants := journeyParticipants(journey, members)
+	go func() {
+		for _, a := range participants {
+			if a.Email == "" {
+				continue
+			}
+			if err := s.mail.SendMailToNotifyUser(a.Email, subject, body, "See the budget", link); err != nil {
+				log.Printf("[budget] alert %s about journey %s: %v", a.Email, journey.ID, err)
+			}
+		}
+	}()
+}
+
+func (s *JourneyBudgetService) ListExpenses(ctx context.Context, journeyID, userID string) ([]response_models.JourneyExpense, error) {
+	journey, _, _, err := journeyAccess(ctx, s.pollRepo, journeyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	expenses, err := s.repo.ListExpenses(ctx, journey.ID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	out := make([]response_models.JourneyExpense, 0, len(expenses))
+	for _, e := range expenses {
+		out = append(out, toJourneyExpenseResponse(e))
+	}
+	return out, nil
+}
+
+func (s *JourneyBudgetService) DeleteExpense(ctx context.Context, journeyID, userID, expenseID string) error {
+	journey, members, role, err := journeyAccess(ctx, s.pollRepo, journeyID, userID)
+	if err != nil {
+		return err
+	}
+	id, err := uuid.Parse(expenseID)
+	if err != nil {
+		return utils.ErrInvalidInput.WithMessage("Invalid expense ID")
+	}
+	expense, err := s.repo.FindExpense(ctx, journey.ID, id)
+	if err != nil {
+		return utils.ErrDatabaseError.Wrap(err)
+	}
+	if expense == nil {
+		return utils.RecordNotFound
+	}
+	if expense.AccountID.String() != userID && role != db_models.JourneyRoleOwner {
+		return utils.ErrUnauthorized.WithMessage("Only who logged the expense or the journey owner can delete it")
+	}
+	if err := s.repo.DeleteExpense(ctx, expense.ID); err != nil {
+		return utils.ErrDatabaseError.Wrap(err)
+	}
+	if summary, err := s.summary(ctx, journey, time.Now()); err == nil {
+		s.checkAlert(ctx, journey, members, summary)
+	}
+	return nil
+}
+
+func (s *JourneyBudgetService) ScanReceipt(ctx context.Context, journeyID, userID string, image []byte, mimeType string) (*response_models.ReceiptDraft, error) {
+	_, _, role, err := journeyAccess(ctx, s.pollRepo, journeyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role == db_models.JourneyRoleViewer {
+		return nil, utils.ErrUnauthorized.WithMessage("Viewers cannot log expenses")
+	}
+	if s.scanner == nil {
+		return nil, utils.ErrReceiptScanUnavailable
+	}
+	if len(image) == 0 || len(image) > MaxReceiptImageBytes {
+		return nil, utils.ErrInvalidInput.WithMessage("Receipt photo must be at most 8 MB")
+	}
+	// Trust the bytes over the client's header; HEIC is not sniffed, so it is taken as declared
+	if sniffed := http.DetectContentType(image); sniffed != "application/octet-stream" {
+		mimeType = sniffed
+	}
+	if !receiptImageTypes[mimeType] {
+		return nil, utils.ErrInvalidInput.WithMessage("Receipt must be a JPEG, PNG, WebP or HEIC photo")
+	}
+
+	scan, err := s.scanner.ScanReceipt(ctx, image, mimeType)
+	if err != nil {
+		return nil, utils.ErrUnexpectedBehaviorOfAI.Wrap(err)
+	}
+	return receiptDraft(scan, time.Now()), nil
+}
+
+// receiptDraft turns a scan into expense fields, leaving out what the expense cannot take as is.
+func receiptDraft(scan *utils.ReceiptScan, now time.Time) *response_models.ReceiptDraft {
+	draft := &response_models.ReceiptDraft{
+		Category: "other",
+		Merchant: scan.Merchant,
+		Currency: scan.Currency,
+		Total:    scan.Total,
+	}
+	if r := []rune(draft.Merchant); len(r) > 128 {
+		draft.Merchant = string(r[:128])
+	}
+	switch scan.Category {
+	case "food", "transport", "lodging", "activities", "shopping":
+		draft.Category = scan.Category
+	}
+
+	switch {
+	case scan.Total <= 0:
+		draft.Warnings = append(draft.Warnings, "The total could not be read")
+	case scan.Currency == "" || scan.Currency == "VND":
+		draft.Amount = int64(math.Round(scan.Total))
+		draft.Currency = "VND"
+	default:
+		draft.Warnings = append(draft.Warnings, fmt.Sprintf("The receipt is in %s, enter the amount in VND", scan.Currency))
+	}
+
+	if day, err := time.ParseInLocation("2006-01-02", scan.Date, vnLoc); err == nil {
+		// Receipts carry no time; noon keeps the day the same in every time zone nearby
+		at := day.Add(12 * time.Hour)
+		if at.After(now) || at.Before(now.AddDate(-1, 0, 0)) {
+			draft.Warnings = append(draft.Warnings, "The receipt date looks wrong, check it")
+		} else {
+			draft.SpentAt = at.Format(time.RFC3339)
+		}
+	} else {
+		draft.Warnings = append(draft.Warnings, "The date could not be read")
+	}
+	return draft
+}
+
+// budgetSummary projects the trip's spend from the average of the elapsed days. Before the
+// trip nothing has elapsed: what was spent (flights, deposits) is the projection.
+func budgetSummary(budget, spent int64, journey *db_models.Journey, now time.Time) response_models.BudgetSummary {
+	start := midnightIn(time.Unix(journey.StartDate, 0), vnLoc)
+	end := start
+	if journey.EndDate != nil && *journey.EndDate > journey.StartDate {
+		end = midnightIn(time.Unix(*journey.EndDate, 0), vnLoc)
+	}
+	totalDays := int(end.Sub(start).Hours()/24+0.5) + 1
+	today := midnightIn(now, vnLoc)
+
+	elapsed := 0
+	switch {
+	case today.Before(start):
+	case today.After(end):
+		elapsed = totalDays
+	default:
+		elapsed = int(today.Sub(start).Hours()/24+0.5) + 1
+	}
+
+	out := response_models.BudgetSummary{
+		Budget:        budget,
+		Spent:         spent,
+		Remaining:     budget - spent,
+		TotalDays:     totalDays,
+		DaysElapsed:   elapsed,
+		DaysRemaining: totalDays - elapsed,
+		Projected:     spent,
+	}
+	if elapsed > 0 {
+		out.DailyBurn = spent / int64(elapsed)
+		out.Projected = spent + out.DailyBurn*int64(out.DaysRemaining)
+	}
+	if out.DaysRemaining > 0 && out.Remaining > 0 {
+		out.SafeDailySpend = out.Remaining / int64(out.DaysRemaining)
+	}
+	out.OverBudget = spent > budget
+	out.ProjectedOver = out.Projected > budget
+	return out
+}
+
+func midnightIn(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// formatVND renders 1250000 as "1.250.000 ₫".
+func formatVND(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	digits := fmt.Sprint(amount)
+	var b strings.Builder
+	for i, r := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(r)
+	}
+	return sign + b.String() + " ₫"
+}
+
+func toJourneyExpenseResponse(e db_models.JourneyExpense) response_models.JourneyExpense {
+	return response_models.JourneyExpense{
+		ID:         e.ID,
+		Amount:     e.Amount,
+		Category:   e.Category,
+		Merchant:   e.Merchant,
+		Note:       e.Note,
+		SpentAt:    time.Unix(e.SpentAt, 0).UTC().Format(time.RFC3339),
+		ActivityID: e.ActivityID,
+		LoggedBy:   e.AccountID,
+	}
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+// mentionPattern matches "@handle"; a handle is a participant's email name (before the @) or
+// their display name without spaces.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([\p{L}\p{N}._+-]+)`)
+
+type JourneyCommentServiceInterface interface {
+	// ListComments returns the threads of a journey; a non-empty activityID keeps that activity's only.
+	ListComments(ctx context.Context, journeyID, userID, activityID string) ([]response_models.JourneyComment, error)
+	// CreateComment posts a comment or a reply and emails the participants it @mentions.
+	CreateComment(ctx context.Context, journeyID, userID string, req request_models.CreateCommentRequest) (*response_models.JourneyComment, error)
+	// DeleteComment soft-deletes a comment (its author or the journey owner).
+	DeleteComment(ctx context.Context, journeyID, userID, commentID string) error
+}
+
+type JourneyCommentService struct {
+	repo     repositories.JourneyCommentRepositoryInterface
+	pollRepo repositories.JourneyPollRepositoryInterface
+	mail     IMailService
+	appURL   string
+}
+
+// NewJourneyCommentService reads APP_PUBLIC_URL for the links in mention emails.
+func NewJourneyCommentService(repo repositories.JourneyCommentRepositoryInterface, pollRepo repositories.JourneyPollRepositoryInterface, mail IMailService) JourneyCommentServiceInterface {
+	s := &JourneyCommentService{repo: repo, pollRepo: pollRepo, mail: mail, appURL: "https://vivu.com"}
+	if u := strings.TrimRight(os.Getenv("APP_PUBLIC_URL"), "/"); u != "" {
+		s.appURL = u
+	}
+	return s
+}
+
+func (s *JourneyCommentService) ListComments(ctx context.Context, journeyID, userID, activityID string) ([]response_models.JourneyComment, error) {
+	journey, _, _, err := journeyAccess(ctx, s.pollRepo, journeyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	var actID *uuid.UUID
+	if activityID != "" {
+		id, err := uuid.Parse(activityID)
+		if err != nil {
+			return nil, utils.ErrInvalidInput.WithMessage("Invalid activity ID")
+		}
+		actID = &id
+	}
+	comments, err := s.repo.ListByJourney(ctx, journey.ID, actID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	return commentThreads(comments), nil
+}
+
+func (s *JourneyCommentService) CreateComment(ctx context.Context, journeyID, userID string, req request_models.CreateCommentRequest) (*response_models.JourneyComment, error) {
+	journey, members, _, err := journeyAccess(ctx, s.pollRepo, journeyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return nil, utils.ErrInvalidInput.WithMessage("body is required")
+	}
+
+	comment := &db_models.JourneyComment{
+		JourneyID: journey.ID,
+		AccountID: uuid.MustParse(userID),
+		Body:      body,
+	}
+	if req.ParentID != "" {
+		parent, err := s.repo.Find(ctx, journey.ID, uuid.MustParse(req.ParentID))
+		if err != nil {
+			return nil, utils.ErrDatabaseError.Wrap(err)
+		}
+		if parent == nil {
+			return nil, utils.ErrInvalidInput.WithMessage("parent comment not found")
+		}
+		// Threads are one level deep: a reply to a reply joins the same thread
+		comment.ParentID = &parent.ID
+		if parent.ParentID != nil {
+			comment.ParentID = parent.ParentID
+		}
+		comment.ActivityID = parent.ActivityID
+	} else if req.ActivityID != "" {
+		activity, err := s.pollRepo.FindActivity(ctx, journey.ID, uuid.MustParse(req.ActivityID))
+		if err != nil {
+			return nil, utils.ErrDatabaseError.Wrap(err)
+		}
+		if activity == nil {
+			return nil, utils.ErrInvalidInput.WithMessage("Activity not found in this journey")
+		}
+		comment.ActivityID = &activity.ID
+	}
+
+	participants := journeyParticipants(journey, members)
+	mentioned := mentionedParticipants(body, participants, comment.AccountID)
+	for _, a := range mentioned {
+		comment.Mentions = append(comment.Mentions, a.ID)
+	}
+
+	if err := s.repo.Create(ctx, comment); err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	for _, a := range participants {
+		if a.ID == comment.AccountID {
+			comment.Account = a
+		}
+	}
+
+	s.notifyMentions(journey, comment, mentioned)
+	out := toJourneyCommentResponse(*comment)
+	return &out, nil
+}
+
+func (s *JourneyCommentService) DeleteComment(ctx context.Context, journeyID, userID, commentID string) error {
+	journey, _, role, err := journeyAccess(ctx, s.pollRepo, journeyID, userID)
+	if err != nil {
+		return err
+	}
+	id, err := uuid.Parse(commentID)
+	if err != nil {
+		return utils.ErrInvalidInput.WithMessage("Invalid comment ID")
+	}
+	comment, err := s.repo.Find(ctx, journey.ID, id)
+	if err != nil {
+		return utils.ErrDatabaseError.Wrap(err)
+	}
+	if comment == nil {
+		return utils.RecordNotFound
+	}
+	if comment.AccountID.String() != userID && role != db_models.JourneyRoleOwner {
+		return utils.ErrUnauthorized.WithMessage("Only the author or the journey owner can delete a comment")
+	}
+	if err := s.repo.Delete(ctx, comment.ID); err != nil {
+		return utils.ErrDatabaseError.Wrap(err)
+	}
+	return nil
+}
+
+// journeyParticipants is the owner followed by the members of a journey.
+func journeyParticipants(journey *db_models.Journey, members []db_models.JourneyMember) []db_models.Account {
+	out := make([]db_models.Account, 0, len(members)+1)
+	owner := journey.Account
+	owner.ID = journey.AccountID
+	out = append(out, owner)
+	for _, m := range members {
+		a := m.Account
+		a.ID = m.AccountID
+		out = append(out, a)
+	}
+	return out
+}
+
+// mentionedParticipants resolves the @handles of body, without the author and without repeats.
+func mentionedParticipants(body string, participants []db_models.Account, authorID uuid.UUID) []db_models.Account {
+	handles := make(map[string]bool)
+	for _, m := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		handles[strings.ToLower(strings.TrimRight(m[1], "."))] = true
+	}
+	if len(handles) == 0 {
+		return nil
+	}
+	var out []db_models.Account
+	for _, a := range participants {
+		if a.ID == authorID {
+			continue
+		}
+		local, _, _ := strings.Cut(strings.ToLower(a.Email), "@")
+		name := strings.ToLower(strings.Join(strings.Fields(a.Name), ""))
+		if (local != "" && handles[local]) || (name != "" && handles[name]) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// notifyMentions emails the mentioned participants; delivery is best effort.
+func (s *JourneyCommentService) notifyMentions(journey *db_models.Journey, comment *db_models.JourneyComment, mentioned []db_models.Account) {
+	if s.mail == nil || len(mentioned) == 0 {
+		return
+	}
+	author := comment.Account.Name
+	if author == "" {
+		author = "A travel companion"
+	}
+	subject := fmt.Sprintf("%s mentioned you on %s", author, journey.Title)
+	link := fmt.Sprintf("%s/journeys/%s", s.appURL, journey.ID)
+	go func() {
+		for _, a := range mentioned {
+			if a.Email == "" {
+				continue
+			}
+			if err := s.mail.SendMailToNotifyUser(a.Email, subject, comment.Body, "Reply", link); err != nil {
+				log.Printf("[comments] notify %s about journey %s: %v", a.Email, journey.ID, err)
+			}
+		}
+	}()
+}
+
+// commentThreads nests replies under their top-level comment. Deleted replies are dropped;
+// a deleted comment stays, emptied, only while it has replies.
+func commentThreads(comments []db_models.JourneyComment) []response_models.JourneyComment {
+	replies := make(map[uuid.UUID][]response_models.JourneyComment)
+	for _, c := range comments {
+		if c.ParentID != nil && !c.DeletedAt.Valid {
+			replies[*c.ParentID] = append(replies[*c.ParentID], toJourneyCommentResponse(c))
+		}
+	}
+	out := make([]response_models.JourneyComment, 0)
+	for _, c := range comments {
+		if c.ParentID != nil {
+			continue
+		}
+		if c.DeletedAt.Valid && len(replies[c.ID]) == 0 {
+			continue
+		}
+		item := toJourneyCommentResponse(c)
+		item.Replies = replies[c.ID]
+		out = append(out, item)
+	}
+	return out
+}
+
+func toJourneyCommentResponse(c db_models.JourneyComment) response_models.JourneyComment {
+	out := response_models.JourneyComment{
+		ID:         c.ID,
+		ActivityID: c.ActivityID,
+		ParentID:   c.ParentID,
+		AccountID:  c.AccountID,
+		AuthorName: c.Account.Name,
+		Body:       c.Body,
+		Mentions:   c.Mentions,
+		CreatedAt:  time.Unix(c.CreatedAt, 0).UTC().Format(time.RFC3339),
+	}
+	if c.DeletedAt.Valid {
+		out.Body = ""
+		out.Mentions = nil
+		out.Deleted = true
+	}
+	return out
+}
@@ -0,0 +1,445 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-pdf/fpdf"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/secrets"
+	"vivu/pkg/utils"
+)
+
+// Number of days rendered between two context checks. Long trips are rendered
+// chunk by chunk so a cancelled request stops burning CPU early.
+const exportDaysPerChunk = 5
+
+type JourneyExportServiceInterface interface {
+	// ExportJourneyPDF renders the journey into a PDF and returns the file bytes and a suggested file name.
+	ExportJourneyPDF(ctx context.Context, journeyId string, userId string) ([]byte, string, error)
+	// RenderJourneyHTML renders the print view of a journey the user owns or that is shared.
+	RenderJourneyHTML(ctx context.Context, journeyId string, userId string) ([]byte, error)
+	// RenderSharedJourneyHTML renders the print view of the journey a share link points to.
+	RenderSharedJourneyHTML(ctx context.Context, token string) ([]byte, error)
+
+	// ShareJourney returns the journey's share link, creating it on first use.
+	ShareJourney(ctx context.Context, journeyId string, userId string) (*response_models.JourneyShareLink, error)
+	// UnshareJourney revokes the share link; a new one gets a new token.
+	UnshareJourney(ctx context.Context, journeyId string, userId string) error
+	// ShareText renders the itinerary as a short message for chat apps, in locale (vi or en)
+	// or, when empty, the language of the user's account.
+	ShareText(ctx context.Context, journeyId string, userId string, locale string) (*response_models.JourneyShareText, error)
+}
+
+type JourneyExportService struct {
+	journeyRepo repositories.JourneyRepository
+	accountRepo repositories.AccountRepository
+	theme       pdfTheme
+	keys        secrets.Getter // MAPBOX_ACCESS_TOKEN for the print view's maps
+	http        *http.Client
+	appURL      string
+}
+
+// NewJourneyExportService reads APP_PUBLIC_URL for the trip link of share texts.
+func NewJourneyExportService(journeyRepo repositories.JourneyRepository, accountRepo repositories.AccountRepository, keys secrets.Getter) JourneyExportServiceInterface {
+	s := &JourneyExportService{
+		journeyRepo: journeyRepo,
+		accountRepo: accountRepo,
+		theme:       defaultPdfTheme(),
+		keys:        keys,
+		http:        utils.NewTracedHTTPClient(10 * time.Second),
+		appURL:      "https://vivu.com",
+	}
+	if u := strings.TrimRight(os.Getenv("APP_PUBLIC_URL"), "/"); u != "" {
+		s.appURL = u
+	}
+	return s
+}
+
+// ---------- Templating layer ----------
+
+type rgb struct{ R, G, B int }
+
+// pdfTheme keeps every branding decision in one place so the renderer only deals with layout.
+type pdfTheme struct {
+	BrandName   string
+	Tagline     string
+	Primary     rgb
+	Accent      rgb
+	Muted       rgb
+	FontFamily  string
+	FontPath    string // optional UTF-8 TTF (needed for full Vietnamese glyphs)
+	FooterLabel string
+}
+
+func defaultPdfTheme() pdfTheme {
+	return pdfTheme{
+		BrandName:   "ViVu",
+		Tagline:     "Your journey, planned.",
+		Primary:     rgb{R: 14, G: 116, B: 144},
+		Accent:      rgb{R: 245, G: 158, B: 11},
+		Muted:       rgb{R: 107, G: 114, B: 128},
+		FontFamily:  "Helvetica",
+		FontPath:    os.Getenv("PDF_FONT_PATH"),
+		FooterLabel: "Generated by ViVu",
+	}
+}
+
+// exportActivityView is the flattened, print-ready version of an activity.
+type exportActivityView struct {
+	TimeRange      string
+	Title          string
+	ActivityType   string
+	Address        string
+	Notes          string
+	DistanceToNext string
+	NextLegMapURL  string
+}
+
+type exportDayView struct {
+	Heading    string
+	Activities []exportActivityView
+	// Final leg back to the accommodation, empty when none is set
+	ReturnLeg       string
+	ReturnLegMapURL string
+}
+
+type exportJourneyView struct {
+	Title      string
+	Location   string
+	DateRange  string
+	Summary    string
+	Days       []exportDayView
+	ExportedAt string
+}
+
+func buildExportJourneyView(j *response_models.JourneyDetailResponse) exportJourneyView {
+	view := exportJourneyView{
+		Title:      j.Title,
+		Location:   j.Location,
+		DateRange:  formatExportDateRange(j.StartDate, j.EndDate),
+		Summary:    fmt.Sprintf("%d days - %d activities", j.TotalDays, j.TotalActivities),
+		Days:       make([]exportDayView, 0, len(j.Days)),
+		ExportedAt: time.Now().In(vnLoc).Format("02/01/2006 15:04"),
+	}
+
+	for _, d := range j.Days {
+		day := exportDayView{
+			Heading:    fmt.Sprintf("Day %d", d.DayNumber),
+			Activities: make([]exportActivityView, 0, len(d.Activities)),
+		}
+		if t, err := time.Parse(time.RFC3339, d.Date); err == nil {
+			day.Heading = fmt.Sprintf("Day %d - %s", d.DayNumber, t.In(vnLoc).Format("Mon, 02/01/2006"))
+		}
+
+		for i, a := range d.Activities {
+			av := exportActivityView{
+				TimeRange:    formatExportTimeRange(a.Time, a.EndTime),
+				Title:        a.ActivityType,
+				ActivityType: a.ActivityType,
+				Notes:        a.Notes,
+			}
+			if a.SelectedPOI != nil {
+				av.Title = a.SelectedPOI.Name
+				av.Address = a.SelectedPOI.Address
+			}
+
+			// Leg to the next stop of the same day
+			if i+1 < len(d.Activities) && a.SelectedPOI != nil && d.Activities[i+1].SelectedPOI != nil {
+				from, to := a.SelectedPOI, d.Activities[i+1].SelectedPOI
+				if hasCoords(from.Latitude, from.Longitude) && hasCoords(to.Latitude, to.Longitude) {
+					meters := haversineMeters(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+					av.DistanceToNext = formatDistance(meters)
+					av.NextLegMapURL = BuildGoogleDirURL(from.Latitude, from.Longitude, to.Latitude, to.Longitude, TravelModeDriving)
+				}
+			}
+
+			day.Activities = append(day.Activities, av)
+		}
+
+		if hotel := d.Accommodation; hotel != nil && hasCoords(hotel.Latitude, hotel.Longitude) {
+			for i := len(d.Activities) - 1; i >= 0; i-- {
+				from := d.Activities[i].SelectedPOI
+				if from == nil || !hasCoords(from.Latitude, from.Longitude) {
+					continue
+				}
+				if from.ID != hotel.ID {
+					meters := haversineMeters(from.Latitude, from.Longitude, hotel.Latitude, hotel.Longitude)
+					day.ReturnLeg = fmt.Sprintf("Back to %s: %s", hotel.Name, formatDistance(meters))
+					day.ReturnLegMapURL = BuildGoogleDirURL(from.Latitude, from.Longitude, hotel.Latitude, hotel.Longitude, TravelModeDriving)
+				}
+				break
+			}
+		}
+
+		view.Days = append(view.Days, day)
+	}
+
+	return view
+}
+
+// ---------- Service ----------
+
+func (s *JourneyExportService) ExportJourneyPDF(ctx context.Context, journeyId string, userId string) ([]byte, string, error) {
+	journey, err := s.journeyRepo.GetDetailsOfJourneyById(ctx, journeyId)
+	if err != nil {
+		return nil, "", utils.ErrDatabaseError
+	}
+	if journey == nil {
+		return nil, "", utils.ErrJourneyNotFound
+	}
+	if userId != "" && journey.AccountID.String() != userId && !journey.IsShared {
+		return nil, "", utils.ErrUnauthorized
+	}
+
+	view := buildExportJourneyView(db_models.BuildJourneyDetailResponse(journey))
+
+	data, err := s.render(ctx, view)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, exportFileName(view.Title, journeyId), nil
+}
+
+func (s *JourneyExportService) render(ctx context.Context, view exportJourneyView) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 20, 15)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.SetTitle(view.Title, true)
+	pdf.SetAuthor(s.theme.BrandName, true)
+
+	family, tr := s.setupFont(pdf)
+	th := s.theme
+
+	pdf.SetHeaderFunc(func() {
+		pdf.SetFont(family, "B", 10)
+		pdf.SetTextColor(th.Primary.R, th.Primary.G, th.Primary.B)
+		pdf.CellFormat(0, 6, tr(th.BrandName), "", 0, "L", false, 0, "")
+		pdf.SetFont(family, "", 9)
+		pdf.SetTextColor(th.Muted.R, th.Muted.G, th.Muted.B)
+		pdf.CellFormat(0, 6, tr(view.Title), "", 1, "R", false, 0, "")
+		pdf.SetDrawColor(th.Primary.R, th.Primary.G, th.Primary.B)
+		pdf.Line(15, pdf.GetY()+1, 195, pdf.GetY()+1)
+		pdf.Ln(5)
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont(family, "I", 8)
+		pdf.SetTextColor(th.Muted.R, th.Muted.G, th.Muted.B)
+		pdf.CellFormat(0, 10, tr(fmt.Sprintf("%s - %s", th.FooterLabel, view.ExportedAt)), "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 10, fmt.Sprintf("Page %d/{nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+	pdf.AliasNbPages("{nb}")
+
+	s.renderCover(pdf, family, tr, view)
+
+	for start := 0; start < len(view.Days); start += exportDaysPerChunk {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		end := start + exportDaysPerChunk
+		if end > len(view.Days) {
+			end = len(view.Days)
+		}
+		for _, day := range view.Days[start:end] {
+			s.renderDay(pdf, family, tr, day)
+		}
+	}
+
+	if err := pdf.Error(); err != nil {
+		return nil, fmt.Errorf("render pdf: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("write pdf: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *JourneyExportService) setupFont(pdf *fpdf.Fpdf) (string, func(string) string) {
+	return setupPdfFont(pdf, s.theme)
+}
+
+// setupPdfFont registers the UTF-8 font when available; otherwise falls back to a core font
+// and folds Vietnamese diacritics so the text stays readable.
+func setupPdfFont(pdf *fpdf.Fpdf, theme pdfTheme) (string, func(string) string) {
+	if theme.FontPath != "" {
+		if _, err := os.Stat(theme.FontPath); err == nil {
+			pdf.AddUTF8Font("vivu", "", theme.FontPath)
+			pdf.AddUTF8Font("vivu", "B", theme.FontPath)
+			pdf.AddUTF8Font("vivu", "I", theme.FontPath)
+			return "vivu", func(s string) string { return s }
+		}
+	}
+	cp := pdf.UnicodeTranslatorFromDescriptor("")
+	return theme.FontFamily, func(s string) string { return cp(foldDiacritics(s)) }
+}
+
+func (s *JourneyExportService) renderCover(pdf *fpdf.Fpdf, family string, tr func(string) string, view exportJourneyView) {
+	th := s.theme
+	pdf.AddPage()
+
+	pdf.Ln(30)
+	pdf.SetFont(family, "B", 26)
+	pdf.SetTextColor(th.Primary.R, th.Primary.G, th.Primary.B)
+	pdf.MultiCell(0, 12, tr(view.Title), "", "C", false)
+
+	pdf.Ln(4)
+	pdf.SetFont(family, "", 14)
+	pdf.SetTextColor(0, 0, 0)
+	if view.Location != "" {
+		pdf.CellFormat(0, 8, tr(view.Location), "", 1, "C", false, 0, "")
+	}
+	if view.DateRange != "" {
+		pdf.CellFormat(0, 8, tr(view.DateRange), "", 1, "C", false, 0, "")
+	}
+
+	pdf.Ln(4)
+	pdf.SetFont(family, "I", 11)
+	pdf.SetTextColor(th.Accent.R, th.Accent.G, th.Accent.B)
+	pdf.CellFormat(0, 8, tr(view.Summary), "", 1, "C", false, 0, "")
+
+	pdf.Ln(20)
+	pdf.SetFont(family, "", 10)
+	pdf.SetTextColor(th.Muted.R, th.Muted.G, th.Muted.B)
+	pdf.CellFormat(0, 6, tr(th.Tagline), "", 1, "C", false, 0, "")
+}
+
+func (s *JourneyExportService) renderDay(pdf *fpdf.Fpdf, family string, tr func(string) string, day exportDayView) {
+	th := s.theme
+	pdf.AddPage()
+
+	pdf.SetFont(family, "B", 16)
+	pdf.SetFillColor(th.Primary.R, th.Primary.G, th.Primary.B)
+	pdf.SetTextColor(255, 255, 255)
+	pdf.CellFormat(0, 10, tr(" "+day.Heading), "", 1, "L", true, 0, "")
+	pdf.Ln(3)
+
+	if len(day.Activities) == 0 {
+		pdf.SetFont(family, "I", 11)
+		pdf.SetTextColor(th.Muted.R, th.Muted.G, th.Muted.B)
+		pdf.CellFormat(0, 8, tr("Free day - no activities planned."), "", 1, "L", false, 0, "")
+		return
+	}
+
+	for _, a := range day.Activities {
+		pdf.SetFont(family, "B", 11)
+		pdf.SetTextColor(th.Accent.R, th.Accent.G, th.Accent.B)
+		pdf.CellFormat(35, 7, tr(a.TimeRange), "", 0, "L", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+		pdf.MultiCell(0, 7, tr(a.Title), "", "L", false)
+
+		pdf.SetFont(family, "", 9)
+		pdf.SetTextColor(th.Muted.R, th.Muted.G, th.Muted.B)
+		if a.Address != "" {
+			pdf.SetX(50)
+			pdf.MultiCell(0, 5, tr(a.Address), "", "L", false)
+		}
+		if a.Notes != "" {
+			pdf.SetX(50)
+			pdf.MultiCell(0, 5, tr(a.Notes), "", "L", false)
+		}
+		if a.DistanceToNext != "" {
+			pdf.SetX(50)
+			pdf.SetTextColor(th.Primary.R, th.Primary.G, th.Primary.B)
+			pdf.WriteLinkString(5, tr(fmt.Sprintf("Next stop: %s (open map)", a.DistanceToNext)), a.NextLegMapURL)
+			pdf.Ln(5)
+		}
+		pdf.Ln(3)
+	}
+
+	if day.ReturnLeg != "" {
+		pdf.SetFont(family, "I", 10)
+		pdf.SetTextColor(th.Primary.R, th.Primary.G, th.Primary.B)
+		pdf.WriteLinkString(5, tr(day.ReturnLeg+" (open map)"), day.ReturnLegMapURL)
+		pdf.Ln(5)
+	}
+}
+
+// ---------- helpers ----------
+
+func formatExportDateRange(start, end string) string {
+	s, errS := time.Parse(time.RFC3339, start)
+	if errS != nil {
+		return ""
+	}
+	e, errE := time.Parse(time.RFC3339, end)
+	if errE != nil {
+		return s.In(vnLoc).Format("02/01/2006")
+	}
+	return fmt.Sprintf("%s - %s", s.In(vnLoc).Format("02/01/2006"), e.In(vnLoc).Format("02/01/2006"))
+}
+
+func formatExportTimeRange(start, end string) string {
+	s, err := time.Parse(time.RFC3339, start)
+	if err != nil {
+		return ""
+	}
+	out := s.In(vnLoc).Format("15:04")
+	if e, err := time.Parse(time.RFC3339, end); err == nil {
+		out += " - " + e.In(vnLoc).Format("15:04")
+	}
+	return out
+}
+
+func formatDistance(meters float64) string {
+	if meters < 1000 {
+		return fmt.Sprintf("%.0f m", meters)
+	}
+	return fmt.Sprintf("%.1f km", meters/1000)
+}
+
+func hasCoords(lat, lng float64) bool {
+	return lat != 0 || lng != 0
+}
+
+// haversineMeters returns the great-circle distance, good enough for a printed itinerary.
+func haversineMeters(lat1, lng1, lat2, lng2 float64) float64 {
+	const earthRadius = 6371000.0
+	toRad := func(d float64) float64 { return d * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return earthRadius * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+func foldDiacritics(s string) string {
+	s = strings.NewReplacer("đ", "d", "Đ", "D").Replace(s)
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	out, _, err := transform.String(t, s)
+	if err != nil {
+		return s
+	}
+	return out
+}
+
+func exportFileName(title, journeyId string) string {
+	slug := strings.ToLower(foldDiacritics(title))
+	slug = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '-'
+	}, slug)
+	slug = strings.Trim(slug, "-")
+	if slug == "" {
+		slug = "journey-" + journeyId
+	}
+	return slug + ".pdf"
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// importPlace is one row of an imported itinerary, whatever the source format.
+type importPlace struct {
+	Row       int // 1-based row (CSV) or placemark index (KML), for error reporting
+	Name      string
+	Address   string
+	Notes     string
+	Lat, Lng  *float64
+	Day       int        // 0 = not given
+	Date      *time.Time // VN midnight
+	StartTime string     // "15:04", empty = not given
+	EndTime   string
+}
+
+const (
+	importMaxPlaces   = 500
+	importPlacesByDay = 4 // used when the file says nothing about days
+)
+
+// ---------- KML (Google My Maps) ----------
+
+type kmlFile struct {
+	Document kmlContainer `xml:"Document"`
+}
+
+type kmlContainer struct {
+	Name       string         `xml:"name"`
+	Folders    []kmlContainer `xml:"Folder"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name        string `xml:"name"`
+	Description string `xml:"description"`
+	Address     string `xml:"address"`
+	Point       *struct {
+		Coordinates string `xml:"coordinates"`
+	} `xml:"Point"`
+}
+
+var dayInNameRe = regexp.MustCompile(`(?i)(?:day|ngày|ngay)\s*(\d{1,2})`)
+
+// parseKML reads a My Maps export. Every layer (Folder) becomes one day, using "Day N"/"Ngày N"
+// from the layer name when present; a single-layer map is split into days of importPlacesByDay.
+// Placemarks without a Point (drawn routes, areas) are skipped.
+func parseKML(data []byte) (title string, places []importPlace, err error) {
+	var doc kmlFile
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return "", nil, fmt.Errorf("invalid KML: %w", err)
+	}
+
+	type group struct {
+		name       string
+		placemarks []kmlPlacemark
+	}
+	var groups []group
+	var walk func(c kmlContainer)
+	walk = func(c kmlContainer) {
+		if len(c.Placemarks) > 0 {
+			groups = append(groups, group{name: c.Name, placemarks: c.Placemarks})
+		}
+		for _, f := range c.Folders {
+			walk(f)
+		}
+	}
+	walk(doc.Document)
+
+	row := 0
+	for gi, g := range groups {
+		day := 0
+		if len(groups) > 1 {
+			day = gi + 1
+			if m := dayInNameRe.FindStringSubmatch(g.name); m != nil {
+				if n, err := strconv.Atoi(m[1]); err == nil && n > 0 {
+					day = n
+				}
+			}
+		}
+		for _, pm := range g.placemarks {
+			row++
+			if pm.Point == nil {
+				continue
+			}
+			lat, lng, ok := parseKMLCoordinates(pm.Point.Coordinates)
+			if !ok {
+				continue
+			}
+			places = append(places, importPlace{
+				Row:     row,
+				Name:    strings.TrimSpace(pm.Name),
+				Address: strings.TrimSpace(pm.Address),
+				Notes:   stripTags(pm.Description),
+				Lat:     &lat,
+				Lng:     &lng,
+				Day:     day,
+			})
+		}
+	}
+
+	return strings.TrimSpace(doc.Document.Name), places, nil
+}
+
+// KML coordinates are "lng,lat[,alt]".
+func parseKMLCoordinates(s string) (lat, lng float64, ok bool) {
+	parts := strings.Split(strings.TrimSpace(s), ",")
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	lng, err1 := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	lat, err2 := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err1 != nil || err2 != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return 0, 0, false
+	}
+	return lat, lng, true
+}
+
+var htmlTagRe = regexp.MustCompile(`<[^>]*>`)
+
+// My Maps descriptions are HTML fragments.
+func stripTags(s string) string {
+	s = strings.ReplaceAll(s, "<br>", "\n")
+	return strings.TrimSpace(htmlTagRe.ReplaceAllString(s, ""))
+}
+
+// ---------- CSV ----------
+
+// Accepted header names (case-insensitive) for each column.
+var csvColumns = map[string][]string{
+	"name":    {"name", "place", "title", "tên", "ten", "địa điểm"},
+	"address": {"address", "địa chỉ", "dia chi"},
+	"lat":     {"lat", "latitude"},
+	"lng":     {"lng", "lon", "long", "longitude"},
+	"date":    {"date", "ngày", "ngay"},
+	"day":     {"day", "day_number"},
+	"start":   {"start", "start_time", "time", "giờ", "gio"},
+	"end":     {"end", "end_time"},
+	"notes":   {"notes", "note", "ghi chú", "ghi chu"},
+}
+
+var csvDateLayouts = []string{"2006-01-02", "02/01/2006", "2/1/2006", "02-01-2006"}
+var csvTimeLayouts = []string{"15:04", "15:04:05", "3:04 PM", "3:04PM", "3PM"}
+
+// parseCSV reads a planning spreadsheet export. Only "name" is required; dates are read
+// day-first (Vietnamese convention) unless they are ISO.
+func parseCSV(data []byte) ([]importPlace, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // Excel BOM
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	// Spreadsheets exported with ";" are common in vi-VN locales
+	if first, _, _ := bytes.Cut(data, []byte("\n")); bytes.Count(first, []byte(";")) > bytes.Count(first, []byte(",")) {
+		r.Comma = ';'
+	}
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+	col := make(map[string]int)
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(h))
+		for key, aliases := range csvColumns {
+			for _, a := range aliases {
+				if h == a {
+					if _, seen := col[key]; !seen {
+						col[key] = i
+					}
+				}
+			}
+		}
+	}
+	if _, ok := col["name"]; !ok {
+		return nil, fmt.Errorf("CSV must have a name column")
+	}
+
+	get := func(rec []string, key string) string {
+		if i, ok := col[key]; ok && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+
+	var places []importPlace
+	for row := 2; ; row++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("CSV row %d: %w", row, err)
+		}
+		name := get(rec, "name")
+		if name == "" {
+			continue
+		}
+
+		p := importPlace{
+			Row:     row,
+			Name:    name,
+			Address: get(rec, "address"),
+			Notes:   get(rec, "notes"),
+		}
+		if lat, err := strconv.ParseFloat(get(rec, "lat"), 64); err == nil {
+			if lng, err := strconv.ParseFloat(get(rec, "lng"), 64); err == nil && lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180 {
+				p.Lat, p.Lng = &lat, &lng
+			}
+		}
+		if d, err := strconv.Atoi(get(rec, "day")); err == nil && d > 0 {
+			p.Day = d
+		}
+		if v := get(rec, "date"); v != "" {
+			for _, layout := range csvDateLayouts {
+				if t, err := time.ParseInLocation(layout, v, vnLoc); err == nil {
+					p.Date = &t
+					break
+				}
+			}
+		}
+		p.StartTime = normalizeClock(get(rec, "start"))
+		p.EndTime = normalizeClock(get(rec, "end"))
+
+		places = append(places, p)
+	}
+	return places, nil
+}
+
+// normalizeClock returns "15:04" or "" when the value is not a recognisable time.
+func normalizeClock(v string) string {
+	v = strings.ToUpper(strings.TrimSpace(v))
+	if v == "" {
+		return ""
+	}
+	for _, layout := range csvTimeLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t.Format("15:04")
+		}
+	}
+	return ""
+}
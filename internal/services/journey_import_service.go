@@ -0,0 +1,367 @@
+package services
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+type JourneyImportServiceInterface interface {
+	ImportJourney(ctx context.Context, userId string, fileName string, data []byte, opts JourneyImportOptions) (*response_models.JourneyImportResult, error)
+}
+
+type JourneyImportOptions struct {
+	Title      string
+	StartDate  *time.Time // overrides dates from the file; defaults to tomorrow when neither is given
+	ProvinceID string     // province for draft POIs; inferred from matched POIs when empty
+}
+
+const (
+	importMatchRadiusMeters = 300 // search radius around imported coordinates
+	importSameSpotMeters    = 60  // closer than this is the same place whatever the name says
+	importNameSimilarityMin = 0.5
+)
+
+type JourneyImportService struct {
+	journeyRepo repositories.JourneyRepository
+	poiRepo     repositories.POIRepository
+}
+
+func NewJourneyImportService(journeyRepo repositories.JourneyRepository, poiRepo repositories.POIRepository) JourneyImportServiceInterface {
+	return &JourneyImportService{journeyRepo: journeyRepo, poiRepo: poiRepo}
+}
+
+// ImportJourney builds a new journey from a My Maps KML or a CSV of places. Places are matched
+// to catalog POIs by coordinates and name; unknown places that have coordinates become draft POIs.
+func (s *JourneyImportService) ImportJourney(ctx context.Context, userId string, fileName string, data []byte, opts JourneyImportOptions) (*response_models.JourneyImportResult, error) {
+	accountID, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, utils.ErrUnauthorized
+	}
+
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(fileName)), ".")
+	var (
+		places   []importPlace
+		docTitle string
+	)
+	switch format {
+	case "kml":
+		docTitle, places, err = parseKML(data)
+	case "csv":
+		places, err = parseCSV(data)
+	default:
+		return nil, utils.ErrImportFileInvalid
+	}
+	if err != nil {
+		log.Printf("[journey-import] %s: %v", fileName, err)
+		return nil, utils.ErrImportFileInvalid
+	}
+	if len(places) == 0 || len(places) > importMaxPlaces {
+		return nil, utils.ErrImportFileInvalid
+	}
+
+	result := &response_models.JourneyImportResult{Format: format}
+
+	// 1) Match against the catalog
+	matched := make([]*db_models.POI, len(places))
+	for i := range places {
+		poi, err := s.matchPlace(ctx, &places[i])
+		if err != nil {
+			return nil, utils.ErrDatabaseError
+		}
+		matched[i] = poi
+	}
+
+	// 2) Unknown places with coordinates become drafts in the trip's province
+	provinceID := opts.ProvinceID
+	if provinceID == "" {
+		var found []*db_models.POI
+		for _, p := range matched {
+			if p != nil {
+				found = append(found, p)
+			}
+		}
+		if dom := dominantProvinces(found); len(dom) > 0 {
+			provinceID = dom[0]
+		}
+	}
+	provinceUUID, _ := uuid.Parse(provinceID)
+
+	drafts := make([]bool, len(places))
+	for i, p := range places {
+		if matched[i] != nil {
+			continue
+		}
+		switch {
+		case p.Lat == nil || p.Lng == nil:
+			result.Skipped = append(result.Skipped, response_models.JourneyImportIssue{Row: p.Row, Name: p.Name, Reason: "no matching POI and no coordinates to create one"})
+			continue
+		case provinceUUID == uuid.Nil:
+			result.Skipped = append(result.Skipped, response_models.JourneyImportIssue{Row: p.Row, Name: p.Name, Reason: "no matching POI and the province is unknown; pass province_id"})
+			continue
+		case p.Name == "":
+			result.Skipped = append(result.Skipped, response_models.JourneyImportIssue{Row: p.Row, Reason: "place has no name"})
+			continue
+		}
+
+		draft := &db_models.POI{
+			Name:        p.Name,
+			Latitude:    *p.Lat,
+			Longitude:   *p.Lng,
+			ProvinceID:  provinceUUID,
+			Status:      db_models.POIStatusDraft,
+			Address:     p.Address,
+			Description: p.Notes,
+		}
+		if _, err := s.poiRepo.CreatePoi(ctx, draft); err != nil {
+			log.Printf("[journey-import] failed to create draft POI %q: %v", p.Name, err)
+			result.Skipped = append(result.Skipped, response_models.JourneyImportIssue{Row: p.Row, Name: p.Name, Reason: "could not create draft POI"})
+			continue
+		}
+		matched[i] = draft
+		drafts[i] = true
+		result.DraftsCreated++
+	}
+
+	// 3) Lay the places out on days and build the plan
+	start, dayOf := assignImportDays(places, opts.StartDate)
+	plan := &response_models.PlanOnly{Destination: docTitle}
+
+	byDay := make(map[int][]int)
+	for i := range places {
+		if matched[i] != nil {
+			byDay[dayOf[i]] = append(byDay[dayOf[i]], i)
+		}
+	}
+	if len(byDay) == 0 {
+		return nil, utils.ErrImportFileInvalid
+	}
+
+	days := make([]int, 0, len(byDay))
+	for d := range byDay {
+		days = append(days, d)
+	}
+	sort.Ints(days)
+	lastDay := days[len(days)-1]
+
+	for d := 1; d <= lastDay; d++ {
+		day := response_models.PlanOnlyDay{Day: d}
+		for _, slot := range scheduleImportDay(places, byDay[d]) {
+			i := slot.index
+			day.Activities = append(day.Activities, response_models.PlanOnlyActivity{
+				StartTime: slot.start,
+				EndTime:   slot.end,
+				MainPOIID: matched[i].ID.String(),
+			})
+			result.Places = append(result.Places, response_models.JourneyImportMatch{
+				Row:     places[i].Row,
+				Name:    places[i].Name,
+				PoiID:   matched[i].ID.String(),
+				PoiName: matched[i].Name,
+				Day:     d,
+				Draft:   drafts[i],
+			})
+			if !drafts[i] {
+				result.Matched++
+			}
+		}
+		plan.Days = append(plan.Days, day)
+	}
+	plan.Duration = len(plan.Days)
+
+	title := strings.TrimSpace(opts.Title)
+	if title == "" {
+		title = docTitle
+	}
+	if title == "" {
+		title = "Imported trip"
+	}
+	if plan.Destination == "" {
+		plan.Destination = title
+	}
+
+	journeyID, err := s.journeyRepo.ReplaceMaterializedPlan(ctx, nil, plan, &repositories.CreateJourneyInput{
+		AccountID: accountID,
+		Title:     title,
+		StartDate: start,
+	})
+	if err != nil {
+		log.Printf("[journey-import] failed to save journey: %v", err)
+		return nil, utils.ErrDatabaseError
+	}
+
+	result.JourneyID = journeyID.String()
+	result.Title = title
+	result.Days = len(plan.Days)
+	return result, nil
+}
+
+// matchPlace looks for the catalog POI an imported place refers to. With coordinates, the
+// nearby POI with the most similar name wins (or the nearest one if it is practically on top);
+// without coordinates only a good name match is accepted.
+func (s *JourneyImportService) matchPlace(ctx context.Context, p *importPlace) (*db_models.POI, error) {
+	if p.Lat != nil && p.Lng != nil {
+		nearby, err := s.poiRepo.FindPOIsNear(ctx, *p.Lat, *p.Lng, importMatchRadiusMeters, 10)
+		if err != nil {
+			return nil, err
+		}
+		var best *db_models.POI
+		bestScore := 0.0
+		for _, poi := range nearby {
+			if score := nameSimilarity(p.Name, poi.Name); score > bestScore {
+				best, bestScore = poi, score
+			}
+		}
+		if best != nil && bestScore >= importNameSimilarityMin {
+			return best, nil
+		}
+		// nearest first
+		if len(nearby) > 0 && haversineMeters(*p.Lat, *p.Lng, nearby[0].Latitude, nearby[0].Longitude) <= importSameSpotMeters {
+			return nearby[0], nil
+		}
+		return nil, nil
+	}
+
+	if p.Name == "" {
+		return nil, nil
+	}
+	candidates, err := s.poiRepo.SearchPOIsByName(ctx, p.Name)
+	if err != nil {
+		return nil, err
+	}
+	var best *db_models.POI
+	bestScore := 0.0
+	for _, poi := range candidates {
+		if poi.Status == db_models.POIStatusDraft {
+			continue
+		}
+		if score := nameSimilarity(p.Name, poi.Name); score > bestScore {
+			best, bestScore = poi, score
+		}
+	}
+	if best != nil && bestScore >= importNameSimilarityMin {
+		return best, nil
+	}
+	return nil, nil
+}
+
+// nameSimilarity is the Jaccard index of the accent-folded words of both names.
+func nameSimilarity(a, b string) float64 {
+	wa, wb := nameWords(a), nameWords(b)
+	if len(wa) == 0 || len(wb) == 0 {
+		return 0
+	}
+	inter := 0
+	for w := range wa {
+		if _, ok := wb[w]; ok {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(wa)+len(wb)-inter)
+}
+
+func nameWords(s string) map[string]struct{} {
+	out := make(map[string]struct{})
+	for _, w := range strings.Fields(strings.ToLower(foldDiacritics(s))) {
+		w = strings.Trim(w, ".,;:!?()[]\"'-")
+		if w != "" {
+			out[w] = struct{}{}
+		}
+	}
+	return out
+}
+
+// assignImportDays decides the start date and the day number of every place: explicit day
+// numbers win, then dates (relative to the earliest one), then blocks of importPlacesByDay.
+func assignImportDays(places []importPlace, startOverride *time.Time) (time.Time, []int) {
+	var earliest *time.Time
+	for _, p := range places {
+		if p.Date != nil && (earliest == nil || p.Date.Before(*earliest)) {
+			earliest = p.Date
+		}
+	}
+
+	start := time.Now().In(vnLoc).AddDate(0, 0, 1)
+	switch {
+	case startOverride != nil:
+		start = startOverride.In(vnLoc)
+	case earliest != nil:
+		start = *earliest
+	}
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, vnLoc)
+
+	dayOf := make([]int, len(places))
+	seq := 0
+	for i, p := range places {
+		switch {
+		case p.Day > 0:
+			dayOf[i] = p.Day
+		case p.Date != nil && earliest != nil:
+			dayOf[i] = int(p.Date.Sub(*earliest).Hours()/24+0.5) + 1
+		default:
+			dayOf[i] = seq/importPlacesByDay + 1
+			seq++
+		}
+	}
+	return start, dayOf
+}
+
+type importSlot struct {
+	index      int
+	start, end string
+}
+
+// scheduleImportDay keeps times from the file and fills the gaps with 90-minute visits
+// from 09:00, leaving 30 minutes to move between stops.
+func scheduleImportDay(places []importPlace, idx []int) []importSlot {
+	const (
+		visit = 90 * time.Minute
+		move  = 30 * time.Minute
+	)
+	base := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	cursor := base.Add(9 * time.Hour)
+
+	// Only reorder when every stop has a time; otherwise the file order is the plan
+	timed := true
+	for _, i := range idx {
+		if places[i].StartTime == "" {
+			timed = false
+			break
+		}
+	}
+	if timed {
+		sort.SliceStable(idx, func(a, b int) bool {
+			return places[idx[a]].StartTime < places[idx[b]].StartTime
+		})
+	}
+
+	out := make([]importSlot, 0, len(idx))
+	for _, i := range idx {
+		p := places[i]
+		start := cursor
+		if t, err := time.Parse("15:04", p.StartTime); err == nil {
+			start = base.Add(time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute)
+		}
+		end := start.Add(visit)
+		if t, err := time.Parse("15:04", p.EndTime); err == nil {
+			if e := base.Add(time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute); e.After(start) {
+				end = e
+			}
+		}
+		if end.Day() != base.Day() {
+			end = base.Add(23*time.Hour + 59*time.Minute)
+		}
+		out = append(out, importSlot{index: i, start: start.Format("15:04"), end: end.Format("15:04")})
+		cursor = end.Add(move)
+	}
+	return out
+}
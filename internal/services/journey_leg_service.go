@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+)
+
+// Legs are stored for the default mode; reads in another mode fall back to estimates.
+const journeyLegMode = TravelModeDriving
+
+type JourneyLegServiceInterface interface {
+	// RunOnce recomputes the stored legs of every journey that has not ended and starts within
+	// the horizon, and returns how many legs were stored.
+	RunOnce(ctx context.Context) (int, error)
+
+	Start()
+	Stop()
+}
+
+type JourneyLegService struct {
+	repo      repositories.JourneyLegRepositoryInterface
+	matrix    DistanceMatrixService
+	interval  time.Duration
+	horizon   time.Duration
+	batchSize int
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// NewJourneyLegService reads JOURNEY_LEG_INTERVAL (default 24h) and JOURNEY_LEG_HORIZON_DAYS
+// (default 60), how far ahead of their start journeys get their legs computed.
+func NewJourneyLegService(repo repositories.JourneyLegRepositoryInterface, matrix DistanceMatrixService) JourneyLegServiceInterface {
+	s := &JourneyLegService{
+		repo:      repo,
+		matrix:    matrix,
+		interval:  24 * time.Hour,
+		horizon:   60 * 24 * time.Hour,
+		batchSize: 50,
+		stop:      make(chan struct{}),
+	}
+	if d, err := time.ParseDuration(os.Getenv("JOURNEY_LEG_INTERVAL")); err == nil && d > 0 {
+		s.interval = d
+	}
+	if n, err := strconv.Atoi(os.Getenv("JOURNEY_LEG_HORIZON_DAYS")); err == nil && n > 0 {
+		s.horizon = time.Duration(n) * 24 * time.Hour
+	}
+	return s
+}
+
+func (s *JourneyLegService) RunOnce(ctx context.Context) (int, error) {
+	now := time.Now()
+	// End dates are midnight of the last day, so a trip ending today is still picked up
+	from := now.Add(-24 * time.Hour).Unix()
+	to := now.Add(s.horizon).Unix()
+
+	stored, skipped := 0, 0
+	after := uuid.Nil
+	for {
+		journeys, err := s.repo.UpcomingJourneys(ctx, from, to, after, s.batchSize)
+		if err != nil {
+			return stored, err
+		}
+		for i := range journeys {
+			after = journeys[i].ID
+			for d := range journeys[i].Days {
+				n, err := s.refreshDay(ctx, &journeys[i].Days[d], now)
+				stored += n
+				if errors.Is(err, errLegsUnavailable) {
+					skipped++
+					continue
+				}
+				if err != nil {
+					return stored, err
+				}
+			}
+		}
+		if len(journeys) < s.batchSize {
+			break
+		}
+	}
+	if skipped > 0 {
+		log.Printf("[journey-legs] routing unavailable for %d days, their previous legs were kept", skipped)
+	}
+	return stored, nil
+}
+
+// errLegsUnavailable means the routing provider failed for a whole day.
+var errLegsUnavailable = errors.New("routing unavailable")
+
+// refreshDay stores the leg into every routable stop of the day from the one before it, and the
+// leg back to the accommodation. When the provider is down the day keeps its previous legs
+// rather than having them replaced by estimates.
+func (s *JourneyLegService) refreshDay(ctx context.Context, day *db_models.JourneyDay, now time.Time) (int, error) {
+	stops := make([]*db_models.JourneyActivity, 0, len(day.Activities))
+	for i := range day.Activities {
+		a := &day.Activities[i]
+		if a.SelectedPOI.ID != uuid.Nil && hasCoords(a.SelectedPOI.Latitude, a.SelectedPOI.Longitude) {
+			stops = append(stops, a)
+		}
+	}
+	sort.SliceStable(stops, func(i, j int) bool { return stops[i].Time.Before(stops[j].Time) })
+
+	hotel := day.Accommodation
+	if hotel != nil && (hotel.ID == uuid.Nil || !hasCoords(hotel.Latitude, hotel.Longitude)) {
+		hotel = nil
+	}
+	if hotel != nil && (len(stops) == 0 || stops[len(stops)-1].SelectedPOIID == hotel.ID) {
+		hotel = nil
+	}
+
+	points := make([]MatrixPoint, 0, len(stops)+1)
+	seen := make(map[string]bool, len(stops)+1)
+	add := func(p *db_models.POI) MatrixPoint {
+		pt := legPoint(p)
+		if !seen[pt.ID] {
+			seen[pt.ID] = true
+			points = append(points, pt)
+		}
+		return pt
+	}
+	stopPoints := make([]MatrixPoint, len(stops))
+	for i, a := range stops {
+		stopPoints[i] = add(&a.SelectedPOI)
+	}
+	var hotelPoint MatrixPoint
+	if hotel != nil {
+		hotelPoint = add(hotel)
+	}
+	if len(points) < 2 {
+		return 0, nil
+	}
+
+	mat, err := s.matrix.ComputeDistances(ctx, points, journeyLegMode)
+	var partial *PartialMatrixError
+	if err != nil && !errors.As(err, &partial) {
+		log.Printf("[journey-legs] day %s: %v", day.ID, err)
+		return 0, errLegsUnavailable
+	}
+
+	leg := func(from, to MatrixPoint, fromID, toID uuid.UUID) *response_models.TravelLeg {
+		edge, ok := mat[from.ID][to.ID]
+		if !ok {
+			edge = estimateLeg(from, to, journeyLegMode)
+		}
+		return &response_models.TravelLeg{
+			FromPOIID:       fromID,
+			ToPOIID:         toID,
+			Mode:            journeyLegMode,
+			DistanceMeters:  edge.DistanceMeters,
+			DurationSeconds: edge.DurationSeconds,
+			Estimated:       !ok,
+			ComputedAt:      now.Unix(),
+		}
+	}
+
+	stored := 0
+	for k := 1; k < len(stops); k++ {
+		l := leg(stopPoints[k-1], stopPoints[k], stops[k-1].SelectedPOIID, stops[k].SelectedPOIID)
+		if err := s.repo.SaveActivityLeg(ctx, stops[k].ID, l); err != nil {
+			return stored, err
+		}
+		stored++
+	}
+	if hotel != nil {
+		last := stops[len(stops)-1]
+		l := leg(stopPoints[len(stops)-1], hotelPoint, last.SelectedPOIID, hotel.ID)
+		if err := s.repo.SaveReturnLeg(ctx, day.ID, l); err != nil {
+			return stored, err
+		}
+		stored++
+	}
+	return stored, nil
+}
+
+// legPoint keys a POI by its coordinates too: the pair cache is keyed by point ID, so a POI that
+// moved must not be answered with the distances of its old place.
+func legPoint(p *db_models.POI) MatrixPoint {
+	return MatrixPoint{
+		ID:  fmt.Sprintf("%s@%.5f,%.5f", p.ID, p.Latitude, p.Longitude),
+		Lat: p.Latitude,
+		Lng: p.Longitude,
+	}
+}
+
+// Start recomputes the legs in the background until Stop is called.
+func (s *JourneyLegService) Start() {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+			n, err := s.RunOnce(ctx)
+			cancel()
+			if err != nil {
+				log.Printf("[journey-legs] run failed after %d legs: %v", n, err)
+			} else {
+				log.Printf("[journey-legs] stored %d legs of upcoming journeys", n)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (s *JourneyLegService) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
@@ -0,0 +1,490 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+type JourneyPollServiceInterface interface {
+	ListMembers(ctx context.Context, journeyID, userID string) ([]response_models.JourneyMember, error)
+	// AddMember invites an existing account to the journey (owner only).
+	AddMember(ctx context.Context, journeyID, userID string, req request_models.AddJourneyMemberRequest) (*response_models.JourneyMember, error)
+	RemoveMember(ctx context.Context, journeyID, userID, accountID string) error
+
+	// CreatePoll proposes 2-3 POIs for an activity slot (owner and editors).
+	CreatePoll(ctx context.Context, journeyID, userID string, req request_models.CreatePollRequest) (*response_models.JourneyPoll, error)
+	ListPolls(ctx context.Context, journeyID, userID string) ([]response_models.JourneyPoll, error)
+	// Vote records or changes the caller's choice while the poll is open (any member).
+	Vote(ctx context.Context, journeyID, pollID, userID string, req request_models.VotePollRequest) (*response_models.JourneyPoll, error)
+	// RunOnce closes the polls past their deadline and applies the winners.
+	RunOnce(ctx context.Context) (int, error)
+
+	Start()
+	Stop()
+}
+
+type JourneyPollService struct {
+	repo        repositories.JourneyPollRepositoryInterface
+	accountRepo repositories.AccountRepository
+	poiRepo     repositories.POIRepository
+	mail        IMailService
+	appURL      string
+
+	interval  time.Duration
+	batchSize int
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// NewJourneyPollService reads POLL_CLOSE_INTERVAL (default 1m) and APP_PUBLIC_URL for links.
+func NewJourneyPollService(repo repositories.JourneyPollRepositoryInterface, accountRepo repositories.AccountRepository, poiRepo repositories.POIRepository, mail IMailService) JourneyPollServiceInterface {
+	s := &JourneyPollService{
+		repo:        repo,
+		accountRepo: accountRepo,
+		poiRepo:     poiRepo,
+		mail:        mail,
+		appURL:      "https://vivu.com",
+		interval:    time.Minute,
+		batchSize:   100,
+		stop:        make(chan struct{}),
+	}
+	if d, err := time.ParseDuration(os.Getenv("POLL_CLOSE_INTERVAL")); err == nil && d > 0 {
+		s.interval = d
+	}
+	if u := strings.TrimRight(os.Getenv("APP_PUBLIC_URL"), "/"); u != "" {
+		s.appURL = u
+	}
+	return s
+}
+
+// journeyAccess loads the journey, its members and the caller's role on it; accounts without a
+// role are refused.
+func journeyAccess(ctx context.Context, repo repositories.JourneyPollRepositoryInterface, journeyID, userID string) (*db_models.Journey, []db_models.JourneyMember, string, error) {
+	if _, err := uuid.Parse(journeyID); err != nil {
+		return nil, nil, "", utils.ErrInvalidInput
+	}
+	journey, err := repo.FindJourney(ctx, journeyID)
+	if err != nil {
+		return nil, nil, "", utils.ErrDatabaseError.Wrap(err)
+	}
+	if journey == nil {
+		return nil, nil, "", utils.ErrJourneyNotFound
+	}
+	members, err := repo.ListMembers(ctx, journey.ID)
+	if err != nil {
+		return nil, nil, "", utils.ErrDatabaseError.Wrap(err)
+	}
+	if journey.AccountID.String() == userID {
+		return journey, members, db_models.JourneyRoleOwner, nil
+	}
+	for _, m := range members {
+		if m.AccountID.String() == userID {
+			return journey, members, m.Role, nil
+		}
+	}
+	return nil, nil, "", utils.ErrUnauthorized
+}
+
+func (s *JourneyPollService) ListMembers(ctx context.Context, journeyID, userID string) ([]response_models.JourneyMember, error) {
+	journey, members, _, err := journeyAccess(ctx, s.repo, journeyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]response_models.JourneyMember, 0, len(members)+1)
+	out = append(out, response_models.JourneyMember{
+		AccountID: journey.AccountID,
+		Email:     journey.Account.Email,
+		Name:      journey.Account.Name,
+		Role:      db_models.JourneyRoleOwner,
+	})
+	for _, m := range members {
+		out = append(out, toJourneyMemberResponse(m))
+	}
+	return out, nil
+}
+
+func (s *JourneyPollService) AddMember(ctx context.Context, journeyID, userID string, req request_models.AddJourneyMemberRequest) (*response_models.JourneyMember, error) {
+	journey, _, role, err := journeyAccess(ctx, s.repo, journeyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role != db_models.JourneyRoleOwner {
+		return nil, utils.ErrUnauthorized.WithMessage("Only the journey owner can manage members")
+	}
+	account, err := s.accountRepo.FindByEmail(ctx, strings.ToLower(strings.TrimSpace(req.Email)))
+	if err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	if account == nil {
+		return nil, utils.ErrAccountNotFound
+	}
+	if account.ID == journey.AccountID {
+		return nil, utils.ErrInvalidInput.WithMessage("The owner is already part of the journey")
+	}
+
+	member := &db_models.JourneyMember{
+		JourneyID: journey.ID,
+		AccountID: account.ID,
+		Role:      req.Role,
+		Account:   *account,
+	}
+	if err := s.repo.UpsertMember(ctx, member); err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	out := toJourneyMemberResponse(*member)
+	return &out, nil
+}
+
+func (s *JourneyPollService) RemoveMember(ctx context.Context, journeyID, userID, accountID string) error {
+	journey, _, role, err := journeyAccess(ctx, s.repo, journeyID, userID)
+	if err != nil {
+		return err
+	}
+	target, err := uuid.Parse(accountID)
+	if err != nil {
+		return utils.ErrInvalidInput
+	}
+	// Members may leave on their own
+	if role != db_models.JourneyRoleOwner && target.String() != userID {
+		return utils.ErrUnauthorized.WithMessage("Only the journey owner can manage members")
+	}
+	found, err := s.repo.RemoveMember(ctx, journey.ID, target)
+	if err != nil {
+		return utils.ErrDatabaseError.Wrap(err)
+	}
+	if !found {
+		return utils.RecordNotFound
+	}
+	return nil
+}
+
+func (s *JourneyPollService) CreatePoll(ctx context.Context, journeyID, userID string, req request_models.CreatePollRequest) (*response_models.JourneyPoll, error) {
+	journey, members, role, err := journeyAccess(ctx, s.repo, journeyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role == db_models.JourneyRoleViewer {
+		return nil, utils.ErrUnauthorized.WithMessage("Viewers can vote but not open polls")
+	}
+
+	activity, err := s.repo.FindActivity(ctx, journey.ID, uuid.MustParse(req.ActivityID))
+	if err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	if activity == nil {
+		return nil, utils.ErrInvalidInput.WithMessage("Activity not found in this journey")
+	}
+
+	deadline, err := time.Parse(time.RFC3339, req.Deadline)
+	if err != nil {
+		return nil, utils.ErrInvalidInput.WithMessage("deadline must be an RFC3339 time")
+	}
+	if !deadline.After(time.Now()) {
+		return nil, utils.ErrInvalidInput.WithMessage("deadline must be in the future")
+	}
+	if deadline.After(activity.Time) {
+		return nil, utils.ErrInvalidInput.WithMessage("deadline must be before the activity starts")
+	}
+
+	seen := make(map[string]bool, len(req.POIIDs))
+	for _, id := range req.POIIDs {
+		if seen[id] {
+			return nil, utils.ErrInvalidInput.WithMessage("poll options must be different places")
+		}
+		seen[id] = true
+	}
+	pois, err := s.poiRepo.ListPoisByPoisId(ctx, req.POIIDs)
+	if err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	byID := make(map[string]*db_models.POI, len(pois))
+	for _, p := range pois {
+		byID[p.ID.String()] = p
+	}
+
+	poll := &db_models.JourneyPoll{
+		BaseModel:  db_models.BaseModel{ID: uuid.New()},
+		JourneyID:  journey.ID,
+		ActivityID: activity.ID,
+		CreatedBy:  uuid.MustParse(userID),
+		Question:   strings.TrimSpace(req.Question),
+		Deadline:   deadline.Unix(),
+		Status:     db_models.PollStatusOpen,
+	}
+	for i, id := range req.POIIDs {
+		poi, ok := byID[id]
+		if !ok {
+			return nil, utils.ErrPOINotFound.WithMessage(fmt.Sprintf("POI %s not found", id))
+		}
+		poll.Options = append(poll.Options, db_models.JourneyPollOption{
+			PollID:   poll.ID,
+			POIID:    poi.ID,
+			Position: i,
+		})
+	}
+	if err := s.repo.CreatePoll(ctx, poll); err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	for i := range poll.Options {
+		poll.Options[i].POI = *byID[poll.Options[i].POIID.String()]
+	}
+
+	s.notify(journey, members, userID, "New poll on your trip",
+		fmt.Sprintf("%s. Vote before %s: %s.", pollQuestion(poll), deadline.In(vnLoc).Format("15:04 02/01/2006"), strings.Join(pollOptionNames(poll), ", ")))
+
+	out := toJourneyPollResponse(*poll, userID)
+	return &out, nil
+}
+
+func (s *JourneyPollService) ListPolls(ctx context.Context, journeyID, userID string) ([]response_models.JourneyPoll, error) {
+	journey, _, _, err := journeyAccess(ctx, s.repo, journeyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	polls, err := s.repo.ListPolls(ctx, journey.ID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	out := make([]response_models.JourneyPoll, 0, len(polls))
+	for _, p := range polls {
+		out = append(out, toJourneyPollResponse(p, userID))
+	}
+	return out, nil
+}
+
+func (s *JourneyPollService) Vote(ctx context.Context, journeyID, pollID, userID string, req request_models.VotePollRequest) (*response_models.JourneyPoll, error) {
+	journey, _, _, err := journeyAccess(ctx, s.repo, journeyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	pid, err := uuid.Parse(pollID)
+	if err != nil {
+		return nil, utils.ErrInvalidInput.WithMessage("Invalid poll ID")
+	}
+	poll, err := s.repo.FindPoll(ctx, journey.ID, pid)
+	if err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	if poll == nil {
+		return nil, utils.RecordNotFound
+	}
+	if poll.Status != db_models.PollStatusOpen || poll.Deadline <= time.Now().Unix() {
+		return nil, utils.ErrInvalidInput.WithMessage("This poll is closed")
+	}
+	optionID := uuid.MustParse(req.OptionID)
+	valid := false
+	for _, o := range poll.Options {
+		if o.ID == optionID {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return nil, utils.ErrInvalidInput.WithMessage("option_id is not an option of this poll")
+	}
+
+	if err := s.repo.UpsertVote(ctx, &db_models.JourneyPollVote{
+		PollID:    poll.ID,
+		AccountID: uuid.MustParse(userID),
+		OptionID:  optionID,
+	}); err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+
+	poll, err = s.repo.FindPoll(ctx, journey.ID, pid)
+	if err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	if poll == nil {
+		return nil, utils.RecordNotFound
+	}
+	out := toJourneyPollResponse(*poll, userID)
+	return &out, nil
+}
+
+func (s *JourneyPollService) RunOnce(ctx context.Context) (int, error) {
+	due, err := s.repo.DuePolls(ctx, time.Now().Unix(), s.batchSize)
+	if err != nil {
+		return 0, err
+	}
+	closed := 0
+	for i := range due {
+		poll := &due[i]
+		winner := pollWinner(poll)
+		ok, err := s.repo.ClosePoll(ctx, poll, winner)
+		if err != nil {
+			return closed, err
+		}
+		if !ok {
+			continue
+		}
+		closed++
+
+		journey, err := s.repo.FindJourney(ctx, poll.JourneyID.String())
+		if err != nil || journey == nil {
+			continue
+		}
+		members, err := s.repo.ListMembers(ctx, journey.ID)
+		if err != nil {
+			log.Printf("[polls] members of journey %s: %v", journey.ID, err)
+			continue
+		}
+		body := fmt.Sprintf("%s: nobody voted, the plan stays as it was.", pollQuestion(poll))
+		if winner != nil {
+			body = fmt.Sprintf("%s: %s won with %d vote(s) and is now in the plan.", pollQuestion(poll), winner.POI.Name, pollTally(poll)[winner.ID])
+		}
+		s.notify(journey, members, "", "Poll closed on your trip", body)
+	}
+	return closed, nil
+}
+
+// pollWinner is the option with the most votes, the first listed one on a tie; nil without votes.
+func pollWinner(poll *db_models.JourneyPoll) *db_models.JourneyPollOption {
+	tally := pollTally(poll)
+	var winner *db_models.JourneyPollOption
+	for i := range poll.Options {
+		o := &poll.Options[i]
+		if tally[o.ID] == 0 {
+			continue
+		}
+		if winner == nil || tally[o.ID] > tally[winner.ID] {
+			winner = o
+		}
+	}
+	return winner
+}
+
+func pollTally(poll *db_models.JourneyPoll) map[uuid.UUID]int {
+	tally := make(map[uuid.UUID]int, len(poll.Options))
+	for _, v := range poll.Votes {
+		tally[v.OptionID]++
+	}
+	return tally
+}
+
+func pollQuestion(poll *db_models.JourneyPoll) string {
+	if poll.Question != "" {
+		return poll.Question
+	}
+	return "Where should we go"
+}
+
+func pollOptionNames(poll *db_models.JourneyPoll) []string {
+	names := make([]string, 0, len(poll.Options))
+	for _, o := range poll.Options {
+		names = append(names, o.POI.Name)
+	}
+	return names
+}
+
+// notify emails the owner and the members of a journey, except the account that caused it.
+// Delivery is best effort and does not hold up the request.
+func (s *JourneyPollService) notify(journey *db_models.Journey, members []db_models.JourneyMember, exceptUserID, subject, body string) {
+	if s.mail == nil {
+		return
+	}
+	var to []string
+	if journey.AccountID.String() != exceptUserID && journey.Account.Email != "" {
+		to = append(to, journey.Account.Email)
+	}
+	for _, m := range members {
+		if m.AccountID.String() != exceptUserID && m.Account.Email != "" {
+			to = append(to, m.Account.Email)
+		}
+	}
+	link := fmt.Sprintf("%s/journeys/%s", s.appURL, journey.ID)
+	go func() {
+		for _, addr := range to {
+			if err := s.mail.SendMailToNotifyUser(addr, subject, body, "Open the trip", link); err != nil {
+				log.Printf("[polls] notify %s about journey %s: %v", addr, journey.ID, err)
+			}
+		}
+	}()
+}
+
+// Start closes due polls in the background until Stop is called.
+func (s *JourneyPollService) Start() {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			n, err := s.RunOnce(ctx)
+			cancel()
+			if err != nil {
+				log.Printf("[polls] run failed: %v", err)
+			} else if n > 0 {
+				log.Printf("[polls] closed %d polls", n)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (s *JourneyPollService) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+func toJourneyMemberResponse(m db_models.JourneyMember) response_models.JourneyMember {
+	return response_models.JourneyMember{
+		AccountID: m.AccountID,
+		Email:     m.Account.Email,
+		Name:      m.Account.Name,
+		Role:      m.Role,
+	}
+}
+
+func toJourneyPollResponse(p db_models.JourneyPoll, userID string) response_models.JourneyPoll {
+	tally := pollTally(&p)
+	out := response_models.JourneyPoll{
+		ID:             p.ID,
+		ActivityID:     p.ActivityID,
+		Question:       p.Question,
+		Deadline:       time.Unix(p.Deadline, 0).UTC().Format(time.RFC3339),
+		Status:         p.Status,
+		Options:        make([]response_models.PollOption, 0, len(p.Options)),
+		WinnerOptionID: p.WinnerOptionID,
+	}
+	if p.ClosedAt != nil {
+		out.ClosedAt = time.Unix(*p.ClosedAt, 0).UTC().Format(time.RFC3339)
+	}
+	for _, o := range p.Options {
+		out.Options = append(out.Options, response_models.PollOption{
+			ID: o.ID,
+			POI: &response_models.POISummary{
+				ID:        o.POI.ID,
+				Name:      o.POI.Name,
+				Address:   o.POI.Address,
+				Latitude:  o.POI.Latitude,
+				Longitude: o.POI.Longitude,
+				Status:    o.POI.Status,
+			},
+			Votes: tally[o.ID],
+		})
+	}
+	for _, v := range p.Votes {
+		if v.AccountID.String() == userID {
+			id := v.OptionID
+			out.MyVote = &id
+		}
+	}
+	return out
+}
@@ -0,0 +1,281 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/response_models"
+	"vivu/pkg/utils"
+)
+
+// Mapbox Static Images draws at most this many pins per map; longer days show their first stops.
+const printMapMaxPins = 25
+
+// Static maps fetched at once while rendering a print view.
+const printMapConcurrency = 4
+
+// printDayView is a day of the print view: the PDF's day plus its map, inlined so the page
+// prints (and keeps printing once saved) without fetching anything.
+type printDayView struct {
+	exportDayView
+	MapImage template.URL
+}
+
+type printJourneyView struct {
+	exportJourneyView
+	Days []printDayView
+}
+
+// mapStop is a pin on a day's map.
+type mapStop struct{ Lat, Lng float64 }
+
+// staticMapURL is the Mapbox Static Images request for the stops, numbered in visiting order.
+func staticMapURL(stops []mapStop, token string) string {
+	if len(stops) > printMapMaxPins {
+		stops = stops[:printMapMaxPins]
+	}
+	pins := make([]string, 0, len(stops))
+	for i, s := range stops {
+		pins = append(pins, fmt.Sprintf("pin-s-%d+0e7490(%.5f,%.5f)", i+1, s.Lng, s.Lat))
+	}
+	return fmt.Sprintf("https://api.mapbox.com/styles/v1/mapbox/streets-v12/static/%s/auto/640x320@2x?padding=40&access_token=%s",
+		strings.Join(pins, ","), url.QueryEscape(token))
+}
+
+// dayStops lists the day's stops with coordinates in visiting order, the accommodation first.
+func dayStops(d *response_models.JourneyDayResponse) []mapStop {
+	var stops []mapStop
+	if h := d.Accommodation; h != nil && hasCoords(h.Latitude, h.Longitude) {
+		stops = append(stops, mapStop{Lat: h.Latitude, Lng: h.Longitude})
+	}
+	for _, a := range d.Activities {
+		if p := a.SelectedPOI; p != nil && hasCoords(p.Latitude, p.Longitude) {
+			stops = append(stops, mapStop{Lat: p.Latitude, Lng: p.Longitude})
+		}
+	}
+	return stops
+}
+
+// fetchStaticMap downloads a map and returns it as a data URL, or "" when it cannot be had:
+// a print view without maps beats no print view.
+func (s *JourneyExportService) fetchStaticMap(ctx context.Context, stops []mapStop) template.URL {
+	token := s.keys.Get("MAPBOX_ACCESS_TOKEN")
+	if token == "" || len(stops) == 0 {
+		return ""
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, staticMapURL(stops, token), nil)
+	if err != nil {
+		return ""
+	}
+	res, err := s.http.Do(req)
+	if err != nil {
+		log.Printf("[journey-print] static map: %v", err)
+		return ""
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		log.Printf("[journey-print] static map: status %d", res.StatusCode)
+		return ""
+	}
+	img, err := io.ReadAll(io.LimitReader(res.Body, 2<<20))
+	if err != nil {
+		return ""
+	}
+	contentType := res.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "image/png"
+	}
+	return template.URL("data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(img))
+}
+
+func (s *JourneyExportService) buildPrintView(ctx context.Context, journey *db_models.Journey) printJourneyView {
+	detail := db_models.BuildJourneyDetailResponse(journey)
+	base := buildExportJourneyView(detail)
+	view := printJourneyView{exportJourneyView: base, Days: make([]printDayView, len(base.Days))}
+
+	sem := make(chan struct{}, printMapConcurrency)
+	var wg sync.WaitGroup
+	for i := range base.Days {
+		view.Days[i].exportDayView = base.Days[i]
+		stops := dayStops(&detail.Days[i])
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			view.Days[i].MapImage = s.fetchStaticMap(ctx, stops)
+		}(i)
+	}
+	wg.Wait()
+	return view
+}
+
+func (s *JourneyExportService) renderHTML(ctx context.Context, journey *db_models.Journey) ([]byte, error) {
+	view := s.buildPrintView(ctx, journey)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := journeyPrintTemplate.Execute(&buf, map[string]any{"Journey": view, "Theme": s.theme}); err != nil {
+		return nil, fmt.Errorf("render print view: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *JourneyExportService) RenderJourneyHTML(ctx context.Context, journeyId string, userId string) ([]byte, error) {
+	journey, err := s.journeyRepo.GetDetailsOfJourneyById(ctx, journeyId)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if journey == nil {
+		return nil, utils.ErrJourneyNotFound
+	}
+	if journey.AccountID.String() != userId && !journey.IsShared {
+		return nil, utils.ErrUnauthorized
+	}
+	return s.renderHTML(ctx, journey)
+}
+
+func (s *JourneyExportService) RenderSharedJourneyHTML(ctx context.Context, token string) ([]byte, error) {
+	if _, err := hex.DecodeString(token); err != nil || len(token) != 32 {
+		return nil, utils.ErrJourneyNotFound
+	}
+	id, err := s.journeyRepo.FindJourneyIdByShareToken(ctx, token)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if id == uuid.Nil {
+		return nil, utils.ErrJourneyNotFound
+	}
+	journey, err := s.journeyRepo.GetDetailsOfJourneyById(ctx, id.String())
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if journey == nil {
+		return nil, utils.ErrJourneyNotFound
+	}
+	return s.renderHTML(ctx, journey)
+}
+
+// ownJourney loads the journey when userId owns it: only the owner hands out links.
+func (s *JourneyExportService) ownJourney(ctx context.Context, journeyId, userId string) (*db_models.Journey, error) {
+	journey, err := s.journeyRepo.GetDetailsOfJourneyById(ctx, journeyId)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if journey == nil {
+		return nil, utils.ErrJourneyNotFound
+	}
+	if journey.AccountID.String() != userId {
+		return nil, utils.ErrUnauthorized.WithMessage("Only the owner of the journey can share it")
+	}
+	return journey, nil
+}
+
+func (s *JourneyExportService) ShareJourney(ctx context.Context, journeyId string, userId string) (*response_models.JourneyShareLink, error) {
+	journey, err := s.ownJourney(ctx, journeyId, userId)
+	if err != nil {
+		return nil, err
+	}
+	if journey.ShareToken == nil {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return nil, utils.ErrInternal.Wrap(err)
+		}
+		token := hex.EncodeToString(b)
+		if err := s.journeyRepo.SetShareToken(ctx, journey.ID, &token); err != nil {
+			return nil, utils.ErrDatabaseError
+		}
+		journey.ShareToken = &token
+	}
+	return &response_models.JourneyShareLink{
+		JourneyID: journey.ID,
+		Token:     *journey.ShareToken,
+		PrintPath: "/shared/journeys/" + *journey.ShareToken + "/print",
+	}, nil
+}
+
+func (s *JourneyExportService) UnshareJourney(ctx context.Context, journeyId string, userId string) error {
+	journey, err := s.ownJourney(ctx, journeyId, userId)
+	if err != nil {
+		return err
+	}
+	if err := s.journeyRepo.SetShareToken(ctx, journey.ID, nil); err != nil {
+		return utils.ErrDatabaseError
+	}
+	return nil
+}
+
+// journeyPrintTemplate lays out one day per printed page; on screen it reads as a single column.
+var journeyPrintTemplate = template.Must(template.New("journey_print").Funcs(template.FuncMap{
+	"rgb": func(c rgb) template.CSS { return template.CSS(fmt.Sprintf("rgb(%d,%d,%d)", c.R, c.G, c.B)) },
+}).Parse(`<!DOCTYPE html>
+<html lang="vi">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Journey.Title}} - {{.Theme.BrandName}}</title>
+<style>
+  @page { size: A4; margin: 15mm; }
+  body { font-family: -apple-system, "Segoe UI", Roboto, Helvetica, Arial, sans-serif; color: #111; max-width: 180mm; margin: 0 auto; padding: 8mm 4mm; line-height: 1.4; }
+  header.cover { text-align: center; padding: 20mm 0 10mm; }
+  header.cover h1 { color: {{rgb .Theme.Primary}}; font-size: 28pt; margin: 0 0 4mm; }
+  header.cover .summary { color: {{rgb .Theme.Accent}}; font-style: italic; }
+  section.day { page-break-before: always; break-before: page; }
+  section.day h2 { color: {{rgb .Theme.Primary}}; border-bottom: 1px solid {{rgb .Theme.Primary}}; padding-bottom: 2mm; }
+  .map { width: 100%; border: 1px solid #ddd; margin: 2mm 0 4mm; }
+  ol.activities { padding-left: 0; list-style: none; }
+  ol.activities li { margin-bottom: 4mm; page-break-inside: avoid; break-inside: avoid; }
+  .time { font-weight: bold; color: {{rgb .Theme.Accent}}; }
+  .muted, .leg, footer { color: {{rgb .Theme.Muted}}; font-size: 9pt; }
+  a { color: inherit; }
+  footer { margin-top: 10mm; text-align: center; }
+  @media print { a { text-decoration: none; } .screen-only { display: none; } }
+</style>
+</head>
+<body>
+<header class="cover">
+  <h1>{{.Journey.Title}}</h1>
+  {{with .Journey.Location}}<div>{{.}}</div>{{end}}
+  {{with .Journey.DateRange}}<div>{{.}}</div>{{end}}
+  <p class="summary">{{.Journey.Summary}}</p>
+  <p class="muted">{{.Theme.Tagline}}</p>
+  <p class="screen-only"><button onclick="window.print()">Print</button></p>
+</header>
+{{range .Journey.Days}}
+<section class="day">
+  <h2>{{.Heading}}</h2>
+  {{with .MapImage}}<img class="map" src="{{.}}" alt="Map of the day">{{end}}
+  <ol class="activities">
+  {{range $i, $a := .Activities}}
+    <li>
+      <span class="time">{{$a.TimeRange}}</span> {{$a.Title}}
+      {{with $a.Address}}<div class="muted">{{.}}</div>{{end}}
+      {{with $a.Notes}}<div>{{.}}</div>{{end}}
+      {{if $a.DistanceToNext}}<div class="leg">Next stop: {{if $a.NextLegMapURL}}<a href="{{$a.NextLegMapURL}}">{{$a.DistanceToNext}}</a>{{else}}{{$a.DistanceToNext}}{{end}}</div>{{end}}
+    </li>
+  {{else}}
+    <li class="muted">No activities planned</li>
+  {{end}}
+  </ol>
+  {{if .ReturnLeg}}<div class="leg">{{if .ReturnLegMapURL}}<a href="{{.ReturnLegMapURL}}">{{.ReturnLeg}}</a>{{else}}{{.ReturnLeg}}{{end}}</div>{{end}}
+</section>
+{{end}}
+<footer>{{.Theme.FooterLabel}} - {{.Journey.ExportedAt}}</footer>
+</body>
+</html>
+`))
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"sort"
+	"time"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/response_models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ActivityStatusDone     = "done"
+	ActivityStatusMissed   = "missed"
+	ActivityStatusUpcoming = "upcoming"
+
+	// A check-in up to this long after the planned start still counts as on time
+	checkInGrace = 30 * time.Minute
+	// Check-ins open this long before the planned start
+	checkInLead = 2 * time.Hour
+	// Activities without an end time are over this long after they start
+	defaultActivityLength = time.Hour
+)
+
+type activityProgress struct {
+	Status string
+	OnTime bool
+}
+
+// computeJourneyProgress walks the activities in time order and matches them with the
+// journey's check-ins. Check-ins made before activity ids were recorded match by POI and day.
+func computeJourneyProgress(journey *db_models.Journey, checkIns []db_models.CheckIn, now time.Time) (map[uuid.UUID]activityProgress, response_models.JourneyProgress) {
+	var activities []db_models.JourneyActivity
+	for _, d := range journey.Days {
+		activities = append(activities, d.Activities...)
+	}
+	sort.SliceStable(activities, func(a, b int) bool {
+		return activities[a].Time.Before(activities[b].Time)
+	})
+
+	byActivity := make(map[uuid.UUID]db_models.CheckIn, len(checkIns))
+	var loose []db_models.CheckIn
+	for _, c := range checkIns {
+		if c.ActivityID != nil {
+			if _, seen := byActivity[*c.ActivityID]; !seen {
+				byActivity[*c.ActivityID] = c
+			}
+			continue
+		}
+		loose = append(loose, c)
+	}
+	for _, c := range loose {
+		day := time.Unix(c.CreatedAt, 0).In(vnLoc).Format("2006-01-02")
+		for _, a := range activities {
+			if _, taken := byActivity[a.ID]; taken {
+				continue
+			}
+			if a.SelectedPOIID == c.POIID && a.Time.In(vnLoc).Format("2006-01-02") == day {
+				byActivity[a.ID] = c
+				break
+			}
+		}
+	}
+
+	statuses := make(map[uuid.UUID]activityProgress, len(activities))
+	progress := response_models.JourneyProgress{TotalActivities: len(activities)}
+	for _, a := range activities {
+		if c, ok := byActivity[a.ID]; ok {
+			onTime := !time.Unix(c.CreatedAt, 0).After(a.Time.Add(checkInGrace))
+			statuses[a.ID] = activityProgress{Status: ActivityStatusDone, OnTime: onTime}
+			progress.Completed++
+			if onTime {
+				progress.OnTime++
+			}
+			progress.CurrentStreak++
+			if progress.CurrentStreak > progress.BestStreak {
+				progress.BestStreak = progress.CurrentStreak
+			}
+			continue
+		}
+
+		end := a.Time.Add(defaultActivityLength)
+		if a.EndTime != nil && a.EndTime.After(a.Time) {
+			end = *a.EndTime
+		}
+		if now.After(end) {
+			statuses[a.ID] = activityProgress{Status: ActivityStatusMissed}
+			progress.Missed++
+			progress.CurrentStreak = 0
+			continue
+		}
+		statuses[a.ID] = activityProgress{Status: ActivityStatusUpcoming}
+		progress.Remaining++
+	}
+
+	if progress.TotalActivities > 0 {
+		progress.Percent = progress.Completed * 100 / progress.TotalActivities
+	}
+	if progress.Completed > 0 {
+		progress.OnTimePercent = progress.OnTime * 100 / progress.Completed
+	}
+	return statuses, progress
+}
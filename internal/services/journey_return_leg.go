@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/response_models"
+)
+
+// Rough door-to-door speeds used when the routing provider has no answer for a leg.
+var fallbackSpeedKmh = map[string]float64{
+	TravelModeDriving: 30,
+	TravelModeCycling: 14,
+	TravelModeWalking: 4.5,
+}
+
+// roadDetourFactor turns a straight line into a believable road distance.
+const roadDetourFactor = 1.3
+
+// estimateLeg is the offline fallback: straight-line distance with a detour factor.
+func estimateLeg(from, to MatrixPoint, mode string) MatrixEdge {
+	meters := haversineMeters(from.Lat, from.Lng, to.Lat, to.Lng) * roadDetourFactor
+	speed := fallbackSpeedKmh[NormalizeTravelMode(mode)]
+	return MatrixEdge{
+		DistanceMeters:  int(meters),
+		DurationSeconds: int(meters / (speed * 1000 / 3600)),
+	}
+}
+
+// legBetween asks the matrix provider for one leg and falls back to an estimate.
+// The bool is true when the estimate was used.
+func legBetween(ctx context.Context, matrix DistanceMatrixService, from, to MatrixPoint, mode string) (MatrixEdge, bool) {
+	if matrix != nil {
+		mat, err := matrix.ComputeDistances(ctx, []MatrixPoint{from, to}, mode)
+		var partial *PartialMatrixError
+		if err != nil && !errors.As(err, &partial) {
+			log.Printf("[return-leg] matrix unavailable, estimating: %v", err)
+		}
+		if e, ok := mat[from.ID][to.ID]; ok {
+			return e, false
+		}
+	}
+	return estimateLeg(from, to, mode), true
+}
+
+// lastRoutableActivity is the last activity of the day (by start time) that has coordinates.
+func lastRoutableActivity(day *db_models.JourneyDay) *db_models.JourneyActivity {
+	var last *db_models.JourneyActivity
+	for i := range day.Activities {
+		a := &day.Activities[i]
+		if a.SelectedPOI.ID == uuid.Nil || !hasCoords(a.SelectedPOI.Latitude, a.SelectedPOI.Longitude) {
+			continue
+		}
+		if last == nil || a.Time.After(last.Time) {
+			last = a
+		}
+	}
+	return last
+}
+
+// returnLegFor is the end-of-day leg back to the accommodation, or nil when the day has no
+// accommodation, no routable stop, or already ends at the accommodation. It is read from the leg
+// the background worker stored; when that is missing or no longer matches the day, it is
+// estimated rather than routed, so reading a journey never waits on the provider.
+func returnLegFor(day *db_models.JourneyDay, mode string) *response_models.ReturnLeg {
+	hotel := day.Accommodation
+	if hotel == nil || hotel.ID == uuid.Nil || !hasCoords(hotel.Latitude, hotel.Longitude) {
+		return nil
+	}
+	last := lastRoutableActivity(day)
+	if last == nil || last.SelectedPOIID == hotel.ID {
+		return nil
+	}
+
+	from := MatrixPoint{ID: last.SelectedPOI.ID.String(), Lat: last.SelectedPOI.Latitude, Lng: last.SelectedPOI.Longitude}
+	to := MatrixPoint{ID: hotel.ID.String(), Lat: hotel.Latitude, Lng: hotel.Longitude}
+	mode = NormalizeTravelMode(mode)
+
+	var edge MatrixEdge
+	estimated := true
+	if stored := day.ReturnLeg; stored != nil && stored.Mode == mode && stored.FromPOIID == last.SelectedPOIID && stored.ToPOIID == hotel.ID {
+		edge = MatrixEdge{DistanceMeters: stored.DistanceMeters, DurationSeconds: stored.DurationSeconds}
+		estimated = stored.Estimated
+	} else {
+		edge = estimateLeg(from, to, mode)
+	}
+
+	depart := last.Time
+	if last.EndTime != nil {
+		depart = *last.EndTime
+	}
+	return &response_models.ReturnLeg{
+		FromPOIID:       last.SelectedPOI.ID,
+		DistanceMeters:  edge.DistanceMeters,
+		DurationSeconds: edge.DurationSeconds,
+		DepartAt:        depart.UTC().Format(time.RFC3339),
+		ArriveAt:        depart.Add(time.Duration(edge.DurationSeconds) * time.Second).UTC().Format(time.RFC3339),
+		MapURL:          BuildGoogleDirURL(from.Lat, from.Lng, to.Lat, to.Lng, mode),
+		Estimated:       estimated,
+	}
+}
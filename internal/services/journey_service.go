@@ -2,10 +2,11 @@ package services
 
 import (
 	"context"
-	"fmt"
 	"github.com/google/uuid"
+	"sort"
 	"time"
 	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
 	"vivu/internal/models/response_models"
 	"vivu/internal/repositories"
 	"vivu/pkg/utils"
@@ -13,7 +14,9 @@ import (
 
 type JourneyServiceInterface interface {
 	GetListOfJourneyByUserId(ctx context.Context, page int, pagesize int, userId string) ([]response_models.JourneyResponse, error)
-	GetDetailsInfoOfJourneyById(ctx context.Context, journeyId string) (*response_models.JourneyDetailResponse, error)
+	// GetDetailsInfoOfJourneyById includes the return-to-accommodation leg of each day for mode, as
+	// stored by the leg worker or estimated.
+	GetDetailsInfoOfJourneyById(ctx context.Context, journeyId string, mode string) (*response_models.JourneyDetailResponse, error)
 	AddPoiToJourneyWithGivenStartAndEndDate(ctx context.Context, journeyId string, poiId string, startDate time.Time, endDate time.Time) error
 	RemovePoiFromJourney(ctx context.Context, journeyId string, poiId string) error
 	AddDayToJourney(ctx context.Context, journeyId string) (uuid.UUID, error)
@@ -21,10 +24,22 @@ type JourneyServiceInterface interface {
 	UpdateJourneyWindow(
 		ctx context.Context, journeyId, startRFC3339, endRFC3339 string,
 	) (uuid.UUID, int, int, error)
+	OptimizeDay(ctx context.Context, journeyId string, userId string, dayNumber int, mode string) (*response_models.OptimizeDayResponse, error)
+	SetDayAccommodation(ctx context.Context, journeyId string, userId string, dayNumber int, poiId string) (*response_models.JourneyDayResponse, error)
+	// SwapRainyDay replaces a day's activities with its rainy-day plan; calling it again swaps back.
+	SwapRainyDay(ctx context.Context, journeyId string, userId string, dayNumber int) (*response_models.JourneyDayResponse, error)
+	// CheckInActivity marks a planned activity as done; checking in twice returns the first check-in.
+	CheckInActivity(ctx context.Context, journeyId string, userId string, req request_models.CheckInActivityRequest) (*response_models.JourneyCheckInResponse, error)
+	// GetToday returns the day-of view: today's day, the next activity to check in and the trip progress.
+	GetToday(ctx context.Context, journeyId string, userId string, mode string) (*response_models.JourneyTodayResponse, error)
 }
 
 type JourneyService struct {
-	journeyRepo repositories.JourneyRepository
+	journeyRepo  repositories.JourneyRepository
+	poiRepo      repositories.POIRepository
+	optimizer    *RouteOptimizer
+	matrix       DistanceMatrixService
+	rulesService DestinationRuleServiceInterface
 }
 
 func (j *JourneyService) UpdateSelectedPoiInActivity(ctx context.Context,
@@ -35,8 +50,19 @@ func (j *JourneyService) UpdateSelectedPoiInActivity(ctx context.Context,
 		return utils.ErrInvalidInput
 	}
 
+	journeyId, err := j.journeyRepo.FindJourneyIdByActivityId(ctx, activityId)
+	if err != nil {
+		return utils.ErrDatabaseError
+	}
+	if journeyId == uuid.Nil {
+		return utils.ErrInvalidInput.WithMessage("Activity not found")
+	}
+	if err := j.checkActivityTime(ctx, journeyId.String(), activityId, currentPoiId, startTimen, endTime); err != nil {
+		return err
+	}
+
 	// Call the repository method
-	err := j.journeyRepo.UpdateSelectedPoiInActivityWithGivenTime(ctx, activityId, currentPoiId, startTimen, endTime)
+	err = j.journeyRepo.UpdateSelectedPoiInActivityWithGivenTime(ctx, activityId, currentPoiId, startTimen, endTime)
 	if err != nil {
 		return utils.ErrDatabaseError
 	}
@@ -74,6 +100,10 @@ func (j *JourneyService) RemovePoiFromJourney(ctx context.Context, journeyId str
 
 func (j *JourneyService) AddPoiToJourneyWithGivenStartAndEndDate(ctx context.Context, journeyId string, poiId string, startDate time.Time, endDate time.Time) error {
 
+	if err := j.checkActivityTime(ctx, journeyId, uuid.Nil, poiId, startDate, endDate); err != nil {
+		return err
+	}
+
 	err := j.journeyRepo.AddPoiToJourneyWithStartEnd(ctx, journeyId, poiId, startDate, &endDate)
 	if err != nil {
 		return utils.ErrDatabaseError
@@ -82,9 +112,100 @@ func (j *JourneyService) AddPoiToJourneyWithGivenStartAndEndDate(ctx context.Con
 	return nil
 }
 
-func NewJourneyService(journeyRepo repositories.JourneyRepository) JourneyServiceInterface {
+// checkActivityTime rejects an activity time that breaks a destination rule or the traveler's
+// day window in a way the day did not already (older journeys may predate both), falls outside
+// the POI's opening hours, or leaves no time to drive from the previous stop or to the next one.
+// The rejection carries the nearest times that work. activityId is the activity being moved,
+// uuid.Nil for a new one.
+func (j *JourneyService) checkActivityTime(ctx context.Context, journeyId string, activityId uuid.UUID, poiId string, start, end time.Time) error {
+	journey, err := j.journeyRepo.GetDetailsOfJourneyById(ctx, journeyId)
+	if err != nil {
+		return utils.ErrDatabaseError
+	}
+	if journey == nil {
+		return utils.ErrJourneyNotFound
+	}
+
+	startVN, endVN := start.In(vnLoc), end.In(vnLoc)
+	var day *db_models.JourneyDay
+	for i := range journey.Days {
+		d := journey.Days[i].Date.In(vnLoc)
+		if d.Year() == startVN.Year() && d.YearDay() == startVN.YearDay() {
+			day = &journey.Days[i]
+			break
+		}
+	}
+	if day == nil {
+		return nil // no such day; the repository reports it
+	}
+
+	poiIDs := []string{poiId}
+	var others []guardSlot
+	for _, a := range day.Activities {
+		poiIDs = append(poiIDs, a.SelectedPOIID.String())
+		if a.ID == activityId || a.EndTime == nil {
+			continue
+		}
+		slot := activityGuardSlot(a.SelectedPOI.Name, a.Time.In(vnLoc), a.EndTime.In(vnLoc))
+		if hasCoords(a.SelectedPOI.Latitude, a.SelectedPOI.Longitude) {
+			slot.Point = &MatrixPoint{ID: a.SelectedPOI.ID.String(), Lat: a.SelectedPOI.Latitude, Lng: a.SelectedPOI.Longitude}
+		}
+		others = append(others, slot)
+	}
+
+	guardrails := j.rulesService.GuardrailsForPOIs(ctx, poiIDs)
+	if w, ok := NewWorkingWindow(journey.Account.DayStart, journey.Account.DayEnd); ok {
+		guardrails = guardrails.WithWorkingWindow(w)
+	}
+
+	label, hours := "the activity", ""
+	var point, hotel *MatrixPoint
+	if poi, err := j.poiRepo.GetByIDWithDetails(ctx, poiId); err == nil && poi != nil {
+		label, hours = poi.Name, poi.OpeningHours
+		if hasCoords(poi.Latitude, poi.Longitude) {
+			point = &MatrixPoint{ID: poi.ID.String(), Lat: poi.Latitude, Lng: poi.Longitude}
+		}
+	}
+	// The drive back to the accommodation must respect the restricted windows too
+	// (e.g. no mountain roads after dark)
+	if h := day.Accommodation; h != nil && hasCoords(h.Latitude, h.Longitude) {
+		hotel = &MatrixPoint{ID: h.ID.String(), Lat: h.Latitude, Lng: h.Longitude}
+	}
+
+	placement := newActivityPlacement(ctx, j.matrix, label, point, hours, others, hotel, guardrails)
+	slot := activityGuardSlot(label, startVN, endVN)
+	problems, ruleBroken := placement.problems(ctx, slot.Start, slot.End)
+	if len(problems) == 0 {
+		return nil
+	}
+
+	midnight := time.Date(startVN.Year(), startVN.Month(), startVN.Day(), 0, 0, 0, 0, vnLoc)
+	conflict := response_models.ActivityTimeConflict{
+		Problems:     problems,
+		Alternatives: placement.alternatives(ctx, midnight, slot.Start, slot.End-slot.Start),
+	}
+	if ruleBroken {
+		return utils.ErrPlanRuleViolation.WithData(conflict)
+	}
+	return utils.ErrActivityTimeConflict.WithData(conflict)
+}
+
+func activityGuardSlot(label string, start, end time.Time) guardSlot {
+	s := start.Hour()*60 + start.Minute()
+	e := end.Hour()*60 + end.Minute()
+	if e <= s {
+		e = 24 * 60 // runs past midnight
+	}
+	return guardSlot{Label: label, Start: s, End: e}
+}
+
+func NewJourneyService(journeyRepo repositories.JourneyRepository, poiRepo repositories.POIRepository, optimizer *RouteOptimizer, matrix DistanceMatrixService, rulesService DestinationRuleServiceInterface) JourneyServiceInterface {
 	return &JourneyService{
-		journeyRepo: journeyRepo,
+		journeyRepo:  journeyRepo,
+		poiRepo:      poiRepo,
+		optimizer:    optimizer,
+		matrix:       matrix,
+		rulesService: rulesService,
 	}
 }
 
@@ -114,7 +235,7 @@ func (j *JourneyService) GetListOfJourneyByUserId(
 	return out, nil
 }
 
-func (j *JourneyService) GetDetailsInfoOfJourneyById(ctx context.Context, journeyId string) (*response_models.JourneyDetailResponse, error) {
+func (j *JourneyService) GetDetailsInfoOfJourneyById(ctx context.Context, journeyId string, mode string) (*response_models.JourneyDetailResponse, error) {
 	journey, err := j.journeyRepo.GetDetailsOfJourneyById(ctx, journeyId)
 	if err != nil {
 		return nil, err
@@ -124,6 +245,9 @@ func (j *JourneyService) GetDetailsInfoOfJourneyById(ctx context.Context, journe
 	}
 
 	out := db_models.BuildJourneyDetailResponse(journey)
+	j.attachReturnLegs(journey, out, mode)
+	j.attachRainyDays(ctx, journey, out)
+	j.attachProgress(ctx, journey, out)
 
 	return out, nil
 }
@@ -142,16 +266,16 @@ func (j *JourneyService) UpdateJourneyWindow(
 
 	start, err := time.Parse(time.RFC3339, startRFC3339)
 	if err != nil {
-		return uuid.Nil, 0, 0, fmt.Errorf("invalid start: %w", err)
+		return uuid.Nil, 0, 0, utils.ErrInvalidInput.WithMessage("start must be an RFC3339 date-time").Wrap(err)
 	}
 	end, err := time.Parse(time.RFC3339, endRFC3339)
 	if err != nil {
-		return uuid.Nil, 0, 0, fmt.Errorf("invalid end: %w", err)
+		return uuid.Nil, 0, 0, utils.ErrInvalidInput.WithMessage("end must be an RFC3339 date-time").Wrap(err)
 	}
 	start = start.In(vnLoc)
 	end = end.In(vnLoc)
 	if end.Before(start) {
-		return uuid.Nil, 0, 0, fmt.Errorf("end must be after or equal to start")
+		return uuid.Nil, 0, 0, utils.ErrInvalidInput.WithMessage("end must be after or equal to start")
 	}
 
 	added, removed, err := j.journeyRepo.ScaleDaysForJourney(ctx, journeyId, start, end)
@@ -166,3 +290,447 @@ func (j *JourneyService) UpdateJourneyWindow(
 
 	return result.ID, added, removed, nil
 }
+
+// OptimizeDay reorders the POI stops of one day by travel distance. The day keeps its start
+// time; each stop then keeps its own duration and starts after the travel leg from the previous
+// one. Activities without a POI keep their place and times.
+func (j *JourneyService) OptimizeDay(ctx context.Context, journeyId string, userId string, dayNumber int, mode string) (*response_models.OptimizeDayResponse, error) {
+	journey, err := j.journeyRepo.GetDetailsOfJourneyById(ctx, journeyId)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if journey == nil {
+		return nil, utils.ErrJourneyNotFound
+	}
+	if journey.AccountID.String() != userId {
+		return nil, utils.ErrUnauthorized
+	}
+
+	var day *db_models.JourneyDay
+	for i := range journey.Days {
+		if journey.Days[i].DayNumber == dayNumber {
+			day = &journey.Days[i]
+			break
+		}
+	}
+	if day == nil {
+		return nil, utils.ErrInvalidInput
+	}
+
+	acts := append([]db_models.JourneyActivity(nil), day.Activities...)
+	sort.Slice(acts, func(a, b int) bool { return acts[a].Time.Before(acts[b].Time) })
+
+	// Positions (in time order) of the activities that can be routed
+	slotIdx := make([]int, 0, len(acts))
+	points := make([]MatrixPoint, 0, len(acts))
+	for i, a := range acts {
+		if a.SelectedPOI.ID == uuid.Nil || (a.SelectedPOI.Latitude == 0 && a.SelectedPOI.Longitude == 0) {
+			continue
+		}
+		slotIdx = append(slotIdx, i)
+		points = append(points, MatrixPoint{
+			ID:  a.SelectedPOI.ID.String(),
+			Lat: a.SelectedPOI.Latitude,
+			Lng: a.SelectedPOI.Longitude,
+		})
+	}
+
+	route, err := j.optimizer.OptimizeOpenPath(ctx, points, mode)
+	if err != nil {
+		return nil, utils.ErrThirdService
+	}
+
+	changed := false
+	for k, from := range route.Order {
+		if from != k {
+			changed = true
+		}
+	}
+
+	// Walk the day in its new order: position slotIdx[k] now holds acts[slotIdx[route.Order[k]]]
+	ordered := append([]db_models.JourneyActivity(nil), acts...)
+	for k, from := range route.Order {
+		ordered[slotIdx[k]] = acts[slotIdx[from]]
+	}
+	routable := make(map[int]MatrixPoint, len(slotIdx))
+	for k, i := range slotIdx {
+		routable[i] = points[route.Order[k]]
+	}
+
+	slots := make([]repositories.ActivitySlot, 0, len(slotIdx))
+	var cursor time.Time
+	var prev *MatrixPoint
+	if len(ordered) > 0 {
+		cursor = ordered[0].Time
+	}
+	for i, a := range ordered {
+		p, ok := routable[i]
+		if !ok {
+			if end := activityEnd(a); end.After(cursor) {
+				cursor = end
+			}
+			continue
+		}
+		start := cursor
+		if prev != nil {
+			leg, found := route.DistanceMatrix[prev.ID][p.ID]
+			if !found {
+				leg = estimateLeg(*prev, p, mode)
+			}
+			start = start.Add(time.Duration(leg.DurationSeconds) * time.Second)
+		}
+		slot := repositories.ActivitySlot{ActivityID: a.ID, Time: start}
+		if a.EndTime != nil {
+			end := start.Add(a.EndTime.Sub(a.Time))
+			slot.EndTime = &end
+		}
+		slots = append(slots, slot)
+		cursor = start
+		if slot.EndTime != nil {
+			cursor = *slot.EndTime
+		}
+		prev = &p
+	}
+
+	if changed {
+		if err := j.journeyRepo.UpdateActivitySlots(ctx, day.ID, slots); err != nil {
+			return nil, utils.ErrDatabaseError
+		}
+		journey, err = j.journeyRepo.GetDetailsOfJourneyById(ctx, journeyId)
+		if err != nil || journey == nil {
+			return nil, utils.ErrDatabaseError
+		}
+	}
+
+	out := &response_models.OptimizeDayResponse{
+		JourneyID:    journey.ID,
+		DayID:        day.ID,
+		DayNumber:    dayNumber,
+		Changed:      changed,
+		BeforeMeters: route.BeforeMeters,
+		AfterMeters:  route.AfterMeters,
+	}
+	for _, d := range db_models.BuildJourneyDetailResponse(journey).Days {
+		if d.ID == day.ID {
+			out.Activities = d.Activities
+		}
+	}
+	return out, nil
+}
+
+// activityEnd is when an activity finishes, its start when it has no end time.
+func activityEnd(a db_models.JourneyActivity) time.Time {
+	if a.EndTime != nil {
+		return *a.EndTime
+	}
+	return a.Time
+}
+
+// SetDayAccommodation sets where the traveller sleeps after the given day (empty poiId clears it)
+// and returns the day with its new return leg.
+func (j *JourneyService) SetDayAccommodation(ctx context.Context, journeyId string, userId string, dayNumber int, poiId string) (*response_models.JourneyDayResponse, error) {
+	var poiUUID *uuid.UUID
+	if poiId != "" {
+		id, err := uuid.Parse(poiId)
+		if err != nil {
+			return nil, utils.ErrInvalidInput
+		}
+		poiUUID = &id
+	}
+
+	journey, err := j.journeyRepo.GetDetailsOfJourneyById(ctx, journeyId)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if journey == nil {
+		return nil, utils.ErrJourneyNotFound
+	}
+	if journey.AccountID.String() != userId {
+		return nil, utils.ErrUnauthorized
+	}
+
+	var dayID uuid.UUID
+	for _, d := range journey.Days {
+		if d.DayNumber == dayNumber {
+			dayID = d.ID
+			break
+		}
+	}
+	if dayID == uuid.Nil {
+		return nil, utils.ErrInvalidInput
+	}
+
+	found, err := j.journeyRepo.SetDayAccommodation(ctx, dayID, poiUUID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if !found {
+		return nil, utils.ErrPOINotFound
+	}
+
+	detail, err := j.GetDetailsInfoOfJourneyById(ctx, journeyId, TravelModeDriving)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	for i := range detail.Days {
+		if detail.Days[i].ID == dayID {
+			return &detail.Days[i], nil
+		}
+	}
+	return nil, utils.ErrDatabaseError
+}
+
+// SwapRainyDay exchanges the day's activities with its rainy-day plan and returns the updated day.
+func (j *JourneyService) SwapRainyDay(ctx context.Context, journeyId string, userId string, dayNumber int) (*response_models.JourneyDayResponse, error) {
+	journey, err := j.journeyRepo.GetDetailsOfJourneyById(ctx, journeyId)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if journey == nil {
+		return nil, utils.ErrJourneyNotFound
+	}
+	if journey.AccountID.String() != userId {
+		return nil, utils.ErrUnauthorized
+	}
+
+	var dayID uuid.UUID
+	for _, d := range journey.Days {
+		if d.DayNumber == dayNumber {
+			dayID = d.ID
+			break
+		}
+	}
+	if dayID == uuid.Nil {
+		return nil, utils.ErrInvalidInput
+	}
+
+	swapped, err := j.journeyRepo.SwapRainyPlan(ctx, dayID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if !swapped {
+		return nil, utils.ErrInvalidInput
+	}
+
+	detail, err := j.GetDetailsInfoOfJourneyById(ctx, journeyId, TravelModeDriving)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	for i := range detail.Days {
+		if detail.Days[i].ID == dayID {
+			return &detail.Days[i], nil
+		}
+	}
+	return nil, utils.ErrDatabaseError
+}
+
+// attachRainyDays renders each day's stored rainy-day plan. POIs that no longer exist are skipped.
+func (j *JourneyService) attachRainyDays(ctx context.Context, journey *db_models.Journey, out *response_models.JourneyDetailResponse) {
+	var ids []string
+	for _, d := range journey.Days {
+		for _, p := range d.RainyPlan {
+			ids = append(ids, p.POIID.String())
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	pois, err := j.poiRepo.ListPoisByPoisId(ctx, ids)
+	if err != nil {
+		return
+	}
+	byID := make(map[uuid.UUID]*db_models.POI, len(pois))
+	for _, p := range pois {
+		byID[p.ID] = p
+	}
+
+	for _, d := range journey.Days {
+		if len(d.RainyPlan) == 0 {
+			continue
+		}
+		day := d.Date.In(vnLoc)
+		rainy := make([]response_models.JourneyActivityDetail, 0, len(d.RainyPlan))
+		for _, p := range d.RainyPlan {
+			poi := byID[p.POIID]
+			if poi == nil {
+				continue
+			}
+			ad := response_models.JourneyActivityDetail{
+				Time:         onDay(day, p.StartTime),
+				ActivityType: "poi",
+				Notes:        p.Notes,
+				SelectedPOI: &response_models.POISummary{
+					ID:        poi.ID,
+					Name:      poi.Name,
+					Address:   poi.Address,
+					Latitude:  poi.Latitude,
+					Longitude: poi.Longitude,
+					Status:    poi.Status,
+				},
+			}
+			if p.EndTime != "" {
+				ad.EndTime = onDay(day, p.EndTime)
+			}
+			rainy = append(rainy, ad)
+		}
+		for k := range out.Days {
+			if out.Days[k].ID == d.ID {
+				out.Days[k].RainyDay = rainy
+			}
+		}
+	}
+}
+
+// onDay formats a VN wall-clock "15:04" on the given day as RFC3339.
+func onDay(day time.Time, clock string) string {
+	t, err := time.ParseInLocation("15:04", clock, vnLoc)
+	if err != nil {
+		return day.Format(time.RFC3339)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, vnLoc).Format(time.RFC3339)
+}
+
+func (j *JourneyService) attachReturnLegs(journey *db_models.Journey, out *response_models.JourneyDetailResponse, mode string) {
+	for i := range journey.Days {
+		leg := returnLegFor(&journey.Days[i], mode)
+		if leg == nil {
+			continue
+		}
+		for k := range out.Days {
+			if out.Days[k].ID == journey.Days[i].ID {
+				out.Days[k].ReturnLeg = leg
+			}
+		}
+	}
+}
+
+// attachProgress fills the trip progress and each activity's status. Without check-ins it still
+// reports missed activities, so a failing query just leaves progress out.
+func (j *JourneyService) attachProgress(ctx context.Context, journey *db_models.Journey, out *response_models.JourneyDetailResponse) {
+	checkIns, err := j.journeyRepo.ListCheckIns(ctx, journey.ID)
+	if err != nil {
+		return
+	}
+	statuses, progress := computeJourneyProgress(journey, checkIns, time.Now())
+	for di := range out.Days {
+		for ai := range out.Days[di].Activities {
+			a := &out.Days[di].Activities[ai]
+			a.Status = statuses[a.ID].Status
+		}
+	}
+	out.Progress = &progress
+}
+
+func (j *JourneyService) CheckInActivity(ctx context.Context, journeyId string, userId string, req request_models.CheckInActivityRequest) (*response_models.JourneyCheckInResponse, error) {
+	activityID, err := uuid.Parse(req.ActivityID)
+	if err != nil {
+		return nil, utils.ErrInvalidInput
+	}
+	accountID, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, utils.ErrUnauthorized
+	}
+
+	journey, err := j.journeyRepo.GetDetailsOfJourneyById(ctx, journeyId)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if journey == nil {
+		return nil, utils.ErrJourneyNotFound
+	}
+	if journey.AccountID != accountID {
+		return nil, utils.ErrUnauthorized
+	}
+
+	var activity *db_models.JourneyActivity
+	for di := range journey.Days {
+		for ai := range journey.Days[di].Activities {
+			if journey.Days[di].Activities[ai].ID == activityID {
+				activity = &journey.Days[di].Activities[ai]
+			}
+		}
+	}
+	now := time.Now()
+	if activity == nil || now.Before(activity.Time.Add(-checkInLead)) {
+		return nil, utils.ErrInvalidInput
+	}
+
+	checkIns, err := j.journeyRepo.ListCheckIns(ctx, journey.ID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	var checkIn *db_models.CheckIn
+	for i := range checkIns {
+		if checkIns[i].ActivityID != nil && *checkIns[i].ActivityID == activityID {
+			checkIn = &checkIns[i]
+			break
+		}
+	}
+	if checkIn == nil {
+		checkIn = &db_models.CheckIn{
+			AccountID:  accountID,
+			JourneyID:  journey.ID,
+			POIID:      activity.SelectedPOIID,
+			ActivityID: &activityID,
+			Notes:      req.Notes,
+			Stars:      req.Stars,
+		}
+		if err := j.journeyRepo.CreateCheckIn(ctx, checkIn); err != nil {
+			return nil, utils.ErrDatabaseError
+		}
+		checkIns = append(checkIns, *checkIn)
+	}
+
+	statuses, progress := computeJourneyProgress(journey, checkIns, now)
+	return &response_models.JourneyCheckInResponse{
+		CheckInID:  checkIn.ID,
+		ActivityID: activityID,
+		OnTime:     statuses[activityID].OnTime,
+		Progress:   progress,
+	}, nil
+}
+
+func (j *JourneyService) GetToday(ctx context.Context, journeyId string, userId string, mode string) (*response_models.JourneyTodayResponse, error) {
+	journey, err := j.journeyRepo.GetDetailsOfJourneyById(ctx, journeyId)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if journey == nil {
+		return nil, utils.ErrJourneyNotFound
+	}
+	if journey.AccountID.String() != userId {
+		return nil, utils.ErrUnauthorized
+	}
+
+	detail, err := j.GetDetailsInfoOfJourneyById(ctx, journeyId, mode)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+
+	today := time.Now().In(vnLoc).Format("2006-01-02")
+	out := &response_models.JourneyTodayResponse{JourneyID: journey.ID, Date: today}
+	if detail.Progress != nil {
+		out.Progress = *detail.Progress
+	}
+
+	var todayID uuid.UUID
+	for _, d := range journey.Days {
+		if d.Date.In(vnLoc).Format("2006-01-02") == today {
+			todayID = d.ID
+			break
+		}
+	}
+	for di := range detail.Days {
+		day := &detail.Days[di]
+		if day.ID == todayID {
+			out.Day = day
+		}
+		// The next stop is the first open activity, today or on a later day of the trip
+		for ai := range day.Activities {
+			if out.Next == nil && day.Activities[ai].Status == ActivityStatusUpcoming {
+				out.Next = &day.Activities[ai]
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/response_models"
+	"vivu/pkg/utils"
+)
+
+// Share texts stay under this many characters so chat apps send them as one message and the
+// preview stays readable; later days are replaced by a link to the trip.
+const shareTextMaxChars = 3000
+
+// shareTextWords is the wording of a share text in one language.
+type shareTextWords struct {
+	Days      string // "%d days"
+	Day       string // "Day %d"
+	Weekdays  [7]string
+	FreeDay   string
+	Stay      string
+	More      string // "...and %d more days"
+	SeeAll    string
+	PlannedBy string
+}
+
+var shareTextLocales = map[string]shareTextWords{
+	"en": {
+		Days:      "%d days",
+		Day:       "Day %d",
+		Weekdays:  [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+		FreeDay:   "Free day",
+		Stay:      "Stay",
+		More:      "...and %d more day(s)",
+		SeeAll:    "Full plan",
+		PlannedBy: "Planned with Vivu",
+	},
+	"vi": {
+		Days:      "%d ngày",
+		Day:       "Ngày %d",
+		Weekdays:  viWeekdays,
+		FreeDay:   "Ngày tự do",
+		Stay:      "Nghỉ tại",
+		More:      "...và %d ngày nữa",
+		SeeAll:    "Xem cả lịch trình",
+		PlannedBy: "Lên kế hoạch cùng Vivu",
+	},
+}
+
+// shareMapURL is the shortest Google Maps link that opens a pin in the app or the browser.
+func shareMapURL(lat, lng float64) string {
+	return fmt.Sprintf("https://maps.google.com/?q=%.5f,%.5f", lat, lng)
+}
+
+// buildShareText renders the journey as a chat message: a header, then per day a line with the
+// date and a bullet with time, name and map link per stop. Whole days are dropped from the end
+// to stay within maxChars; truncated reports it.
+func buildShareText(j *db_models.Journey, locale, tripURL string, maxChars int) (text string, truncated bool) {
+	w, ok := shareTextLocales[locale]
+	if !ok {
+		w = shareTextLocales[defaultMailLocale]
+	}
+
+	days := append([]db_models.JourneyDay(nil), j.Days...)
+	sort.Slice(days, func(a, b int) bool { return days[a].DayNumber < days[b].DayNumber })
+
+	header := []string{"🗺️ " + j.Title}
+	if j.Location != "" && !strings.Contains(j.Title, j.Location) {
+		header = append(header, "📍 "+j.Location)
+	}
+	dates := fmt.Sprintf(w.Days, len(days))
+	if j.StartDate > 0 {
+		start := utils.FromUnixSecondsVN(j.StartDate)
+		span := start.Format("02/01/2006")
+		if j.EndDate != nil && *j.EndDate > j.StartDate {
+			span = start.Format("02/01") + " - " + utils.FromUnixSecondsVN(*j.EndDate).Format("02/01/2006")
+		}
+		dates = span + " · " + dates
+	}
+	header = append(header, "📅 "+dates)
+
+	footer := []string{"", "✨ " + w.PlannedBy}
+	if tripURL != "" {
+		footer = []string{"", "🔗 " + w.SeeAll + ": " + tripURL, "✨ " + w.PlannedBy}
+	}
+
+	blocks := make([]string, 0, len(days))
+	for _, d := range days {
+		blocks = append(blocks, shareTextDay(&d, w))
+	}
+
+	body := strings.Join(header, "\n")
+	budget := maxChars - utf8.RuneCountInString(body) - utf8.RuneCountInString(strings.Join(footer, "\n")) - 40
+	kept := 0
+	for _, b := range blocks {
+		n := utf8.RuneCountInString(b) + 2
+		// The first day goes in whatever its length: a message with no day says nothing
+		if kept > 0 && n > budget {
+			break
+		}
+		budget -= n
+		kept++
+	}
+	parts := append([]string{body}, blocks[:kept]...)
+	if kept < len(blocks) {
+		parts = append(parts, fmt.Sprintf(w.More, len(blocks)-kept))
+		truncated = true
+	}
+	return strings.Join(parts, "\n\n") + "\n" + strings.Join(footer, "\n"), truncated
+}
+
+func shareTextDay(d *db_models.JourneyDay, w shareTextWords) string {
+	date := d.Date.In(vnLoc)
+	lines := []string{fmt.Sprintf("🗓️ %s · %s %s", fmt.Sprintf(w.Day, d.DayNumber), w.Weekdays[date.Weekday()], date.Format("02/01"))}
+
+	activities := append([]db_models.JourneyActivity(nil), d.Activities...)
+	sort.Slice(activities, func(a, b int) bool { return activities[a].Time.Before(activities[b].Time) })
+	if len(activities) == 0 {
+		lines = append(lines, "🌴 "+w.FreeDay)
+	}
+	for _, a := range activities {
+		name := a.SelectedPOI.Name
+		if name == "" {
+			name = a.ActivityType
+		}
+		line := fmt.Sprintf("• %s %s", a.Time.In(vnLoc).Format("15:04"), name)
+		if p := a.SelectedPOI; hasCoords(p.Latitude, p.Longitude) {
+			line += "\n  " + shareMapURL(p.Latitude, p.Longitude)
+		}
+		lines = append(lines, line)
+	}
+	if h := d.Accommodation; h != nil && h.Name != "" {
+		line := "🏨 " + w.Stay + ": " + h.Name
+		if hasCoords(h.Latitude, h.Longitude) {
+			line += "\n  " + shareMapURL(h.Latitude, h.Longitude)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s *JourneyExportService) ShareText(ctx context.Context, journeyId string, userId string, locale string) (*response_models.JourneyShareText, error) {
+	journey, err := s.journeyRepo.GetDetailsOfJourneyById(ctx, journeyId)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if journey == nil {
+		return nil, utils.ErrJourneyNotFound
+	}
+	if journey.AccountID.String() != userId && !journey.IsShared {
+		return nil, utils.ErrUnauthorized
+	}
+
+	if _, ok := shareTextLocales[locale]; !ok {
+		locale = journey.Account.Locale
+		if journey.AccountID.String() != userId {
+			if account, err := s.accountRepo.FindById(ctx, userId); err == nil && account != nil {
+				locale = account.Locale
+			}
+		}
+		if _, ok := shareTextLocales[locale]; !ok {
+			locale = defaultMailLocale
+		}
+	}
+
+	text, truncated := buildShareText(journey, locale, fmt.Sprintf("%s/journeys/%s", s.appURL, journey.ID), shareTextMaxChars)
+	return &response_models.JourneyShareText{
+		JourneyID: journey.ID,
+		Locale:    locale,
+		Text:      text,
+		Truncated: truncated,
+	}, nil
+}
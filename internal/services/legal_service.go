@@ -0,0 +1,366 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+const (
+	// legalDocumentsTTL bounds how late a version published on another instance is asked for.
+	legalDocumentsTTL = time.Minute
+	// legalAcceptedTTL is how long an account found up to date is not looked up again.
+	legalAcceptedTTL = 5 * time.Minute
+)
+
+var legalKinds = []string{db_models.LegalTerms, db_models.LegalPrivacy}
+
+type LegalServiceInterface interface {
+	// Current returns the version of each document new readers get, narrowed to kind when given.
+	Current(ctx context.Context, kind string) ([]response_models.LegalDocument, error)
+	// Status tells which current documents the account accepted.
+	Status(ctx context.Context, userID string) (*response_models.LegalStatus, error)
+	Accept(ctx context.Context, userID string, req request_models.AcceptLegalDocumentsRequest, ip, userAgent string) (*response_models.LegalStatus, error)
+	// Pending returns utils.ErrLegalAcceptanceRequired, listing the documents, while the
+	// account has not accepted the versions it must.
+	Pending(ctx context.Context, userID string) error
+
+	ListVersions(ctx context.Context, kind string) ([]response_models.AdminLegalDocument, error)
+	Publish(ctx context.Context, req request_models.PublishLegalDocumentRequest, adminID string) (*response_models.AdminLegalDocument, error)
+}
+
+type LegalService struct {
+	repo repositories.LegalRepositoryInterface
+
+	mu        sync.Mutex
+	documents []db_models.LegalDocument
+	loadedAt  time.Time
+	// accepted maps account IDs found up to date to the required versions they were checked
+	// against and when.
+	accepted map[uuid.UUID]legalAcceptedEntry
+}
+
+type legalAcceptedEntry struct {
+	required string
+	at       time.Time
+}
+
+func NewLegalService(repo repositories.LegalRepositoryInterface) LegalServiceInterface {
+	return &LegalService{repo: repo, accepted: make(map[uuid.UUID]legalAcceptedEntry)}
+}
+
+// legalVersions is the published documents of one kind, oldest first.
+type legalVersions []db_models.LegalDocument
+
+// latest is the version shown to new readers.
+func (v legalVersions) latest() *db_models.LegalDocument {
+	if len(v) == 0 {
+		return nil
+	}
+	return &v[len(v)-1]
+}
+
+// required is the latest version that is not minor: accepting it or any later version
+// satisfies the document.
+func (v legalVersions) required() *db_models.LegalDocument {
+	for i := len(v) - 1; i >= 0; i-- {
+		if !v[i].Minor {
+			return &v[i]
+		}
+	}
+	// only minor versions so far: the first one still has to be accepted
+	return v.latest()
+}
+
+func (v legalVersions) satisfiedBy(accepted []uuid.UUID) (*db_models.LegalDocument, bool) {
+	req := v.required()
+	if req == nil {
+		return nil, true
+	}
+	var newest *db_models.LegalDocument
+	for i := range v {
+		if slices.Contains(accepted, v[i].ID) {
+			newest = &v[i]
+		}
+	}
+	if newest == nil {
+		return nil, false
+	}
+	return newest, newest.PublishedAt >= req.PublishedAt
+}
+
+func (s *LegalService) loadDocuments(ctx context.Context) (map[string]legalVersions, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.documents == nil || time.Since(s.loadedAt) >= legalDocumentsTTL {
+		docs, err := s.repo.ListDocuments(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if docs == nil {
+			docs = []db_models.LegalDocument{}
+		}
+		s.documents, s.loadedAt = docs, time.Now()
+	}
+	out := make(map[string]legalVersions, len(legalKinds))
+	for _, d := range s.documents {
+		out[d.Kind] = append(out[d.Kind], d)
+	}
+	return out, nil
+}
+
+func (s *LegalService) forgetDocuments() {
+	s.mu.Lock()
+	s.documents = nil
+	s.accepted = make(map[uuid.UUID]legalAcceptedEntry)
+	s.mu.Unlock()
+}
+
+// requiredSignature names the versions an account must have accepted, to tell whether a
+// cached answer is still about them.
+func requiredSignature(docs map[string]legalVersions) string {
+	parts := make([]string, 0, len(legalKinds))
+	for _, kind := range legalKinds {
+		if req := docs[kind].required(); req != nil {
+			parts = append(parts, req.ID.String())
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func (s *LegalService) Pending(ctx context.Context, userID string) error {
+	accountID, err := uuid.Parse(userID)
+	if err != nil {
+		return utils.ErrInvalidInput
+	}
+	docs, err := s.loadDocuments(ctx)
+	if err != nil {
+		return utils.ErrDatabaseError
+	}
+	signature := requiredSignature(docs)
+	if signature == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	entry, ok := s.accepted[accountID]
+	s.mu.Unlock()
+	if ok && entry.required == signature && time.Since(entry.at) < legalAcceptedTTL {
+		return nil
+	}
+
+	accepted, err := s.repo.AcceptedDocumentIDs(ctx, accountID)
+	if err != nil {
+		return utils.ErrDatabaseError
+	}
+	var pending []utils.PendingLegalDocument
+	for _, kind := range legalKinds {
+		if _, ok := docs[kind].satisfiedBy(accepted); ok {
+			continue
+		}
+		d := docs[kind].latest()
+		pending = append(pending, utils.PendingLegalDocument{
+			Kind:        d.Kind,
+			Version:     d.Version,
+			Title:       d.Title,
+			URL:         d.URL,
+			PublishedAt: utils.FormatRFC3339VN(utils.FromUnixSecondsVN(d.PublishedAt)),
+		})
+	}
+	if len(pending) > 0 {
+		return utils.NewLegalAcceptanceError(pending)
+	}
+
+	s.mu.Lock()
+	s.accepted[accountID] = legalAcceptedEntry{required: signature, at: time.Now()}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *LegalService) Current(ctx context.Context, kind string) ([]response_models.LegalDocument, error) {
+	docs, err := s.loadDocuments(ctx)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	out := make([]response_models.LegalDocument, 0, len(legalKinds))
+	for _, k := range legalKinds {
+		if kind != "" && k != kind {
+			continue
+		}
+		if d := docs[k].latest(); d != nil {
+			out = append(out, legalDocumentResponse(d))
+		}
+	}
+	return out, nil
+}
+
+func (s *LegalService) Status(ctx context.Context, userID string) (*response_models.LegalStatus, error) {
+	accountID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, utils.ErrInvalidInput
+	}
+	docs, err := s.loadDocuments(ctx)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	acceptances, err := s.repo.Acceptances(ctx, accountID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	accepted := make([]uuid.UUID, 0, len(acceptances))
+	acceptedAt := make(map[uuid.UUID]int64, len(acceptances))
+	for _, a := range acceptances {
+		accepted = append(accepted, a.DocumentID)
+		acceptedAt[a.DocumentID] = a.AcceptedAt
+	}
+
+	out := &response_models.LegalStatus{Documents: make([]response_models.LegalDocumentStatus, 0, len(legalKinds))}
+	for _, kind := range legalKinds {
+		latest := docs[kind].latest()
+		if latest == nil {
+			continue
+		}
+		st := response_models.LegalDocumentStatus{Current: legalDocumentResponse(latest)}
+		newest, ok := docs[kind].satisfiedBy(accepted)
+		st.Accepted = ok
+		if newest != nil {
+			st.AcceptedVersion = newest.Version
+			st.AcceptedAt = utils.FormatRFC3339VN(utils.FromUnixSecondsVN(acceptedAt[newest.ID]))
+		}
+		if !ok {
+			out.AcceptanceRequired = true
+		}
+		out.Documents = append(out.Documents, st)
+	}
+	return out, nil
+}
+
+func (s *LegalService) Accept(ctx context.Context, userID string, req request_models.AcceptLegalDocumentsRequest, ip, userAgent string) (*response_models.LegalStatus, error) {
+	accountID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, utils.ErrInvalidInput
+	}
+	docs, err := s.loadDocuments(ctx)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+
+	now := time.Now().Unix()
+	acceptances := make([]db_models.LegalAcceptance, 0, len(req.Documents))
+	for _, a := range req.Documents {
+		latest := docs[a.Kind].latest()
+		if latest == nil {
+			return nil, utils.RecordNotFound.WithMessage("No " + a.Kind + " document is published")
+		}
+		if latest.Version != strings.TrimSpace(a.Version) {
+			return nil, utils.ErrLegalVersionOutdated.WithData(legalDocumentResponse(latest))
+		}
+		acceptances = append(acceptances, db_models.LegalAcceptance{
+			AccountID:  accountID,
+			DocumentID: latest.ID,
+			Kind:       latest.Kind,
+			Version:    latest.Version,
+			AcceptedAt: now,
+			IP:         ip,
+			UserAgent:  userAgent,
+		})
+	}
+	if err := s.repo.Accept(ctx, acceptances); err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	return s.Status(ctx, userID)
+}
+
+func (s *LegalService) ListVersions(ctx context.Context, kind string) ([]response_models.AdminLegalDocument, error) {
+	docs, err := s.loadDocuments(ctx)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	out := []response_models.AdminLegalDocument{}
+	for _, k := range legalKinds {
+		if kind != "" && k != kind {
+			continue
+		}
+		versions := docs[k]
+		req := versions.required()
+		// newest first
+		for i := len(versions) - 1; i >= 0; i-- {
+			out = append(out, adminLegalDocumentResponse(&versions[i], req != nil && versions[i].ID == req.ID))
+		}
+	}
+	return out, nil
+}
+
+func (s *LegalService) Publish(ctx context.Context, req request_models.PublishLegalDocumentRequest, adminID string) (*response_models.AdminLegalDocument, error) {
+	version := strings.TrimSpace(req.Version)
+	if version == "" {
+		return nil, utils.ErrInvalidInput.WithMessage("version is required")
+	}
+	if strings.TrimSpace(req.URL) == "" && strings.TrimSpace(req.Body) == "" {
+		return nil, utils.ErrInvalidInput.WithMessage("Either url or body is required")
+	}
+	docs, err := s.loadDocuments(ctx)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	for _, d := range docs[req.Kind] {
+		if d.Version == version {
+			return nil, utils.ErrLegalVersionTaken
+		}
+	}
+
+	doc := &db_models.LegalDocument{
+		Kind:        req.Kind,
+		Version:     version,
+		Title:       strings.TrimSpace(req.Title),
+		URL:         strings.TrimSpace(req.URL),
+		Body:        req.Body,
+		Minor:       req.Minor,
+		PublishedAt: time.Now().Unix(),
+		PublishedBy: adminID,
+	}
+	if err := s.repo.CreateDocument(ctx, doc); err != nil {
+		// a concurrent publish of the same version lands here too
+		return nil, utils.ErrDatabaseError
+	}
+	s.forgetDocuments()
+
+	// a minor version keeps the previous required one; the first document is required either way
+	docs, err = s.loadDocuments(ctx)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	required := docs[doc.Kind].required()
+	out := adminLegalDocumentResponse(doc, required != nil && required.ID == doc.ID)
+	return &out, nil
+}
+
+func legalDocumentResponse(d *db_models.LegalDocument) response_models.LegalDocument {
+	return response_models.LegalDocument{
+		ID:          d.ID.String(),
+		Kind:        d.Kind,
+		Version:     d.Version,
+		Title:       d.Title,
+		URL:         d.URL,
+		Body:        d.Body,
+		Minor:       d.Minor,
+		PublishedAt: utils.FormatRFC3339VN(utils.FromUnixSecondsVN(d.PublishedAt)),
+	}
+}
+
+func adminLegalDocumentResponse(d *db_models.LegalDocument, current bool) response_models.AdminLegalDocument {
+	return response_models.AdminLegalDocument{
+		LegalDocument: legalDocumentResponse(d),
+		Current:       current,
+		PublishedBy:   d.PublishedBy,
+	}
+}
@@ -0,0 +1,368 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+const (
+	mailBatchSize    = 50
+	mailClaimLease   = 2 * time.Minute
+	mailFirstRetry   = 30 * time.Second // doubled after every failed attempt
+	mailMaxRetry     = time.Hour
+	mailResetCodeTTL = time.Hour // a one-time code is useless once its reset token expired
+)
+
+// MailOutboxServiceInterface is the mailer the rest of the app uses: sending a message queues
+// it, and a worker delivers the queue through SMTP, retrying failures until they run out of
+// attempts and are dead-lettered.
+type MailOutboxServiceInterface interface {
+	IMailService
+
+	// ListMessages returns a page of the outbox for admins.
+	ListMessages(ctx context.Context, query request_models.MailOutboxQuery) (*response_models.MailOutboxPage, error)
+	// RetryMessage puts a dead message back in the queue.
+	RetryMessage(ctx context.Context, id string) (*response_models.MailOutboxMessage, error)
+	// RetryDead puts every dead message back in the queue.
+	RetryDead(ctx context.Context) (*response_models.MailOutboxRetried, error)
+
+	// RunOnce sends the messages that are due and returns how many went out.
+	RunOnce(ctx context.Context) (int, error)
+	Start()
+	Stop()
+}
+
+type MailOutboxService struct {
+	repo        repositories.MailOutboxRepositoryInterface
+	smtp        IMailService
+	interval    time.Duration
+	maxAttempts int
+
+	kick     chan struct{}
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// NewMailOutboxService reads MAIL_OUTBOX_INTERVAL (default 30s) and MAIL_MAX_ATTEMPTS
+// (default 8).
+func NewMailOutboxService(repo repositories.MailOutboxRepositoryInterface, smtp IMailService) MailOutboxServiceInterface {
+	s := &MailOutboxService{
+		repo:        repo,
+		smtp:        smtp,
+		interval:    30 * time.Second,
+		maxAttempts: 8,
+		kick:        make(chan struct{}, 1),
+		stop:        make(chan struct{}),
+	}
+	if d, err := time.ParseDuration(os.Getenv("MAIL_OUTBOX_INTERVAL")); err == nil && d > 0 {
+		s.interval = d
+	}
+	if n, err := strconv.Atoi(os.Getenv("MAIL_MAX_ATTEMPTS")); err == nil && n > 0 {
+		s.maxAttempts = n
+	}
+	return s
+}
+
+func (s *MailOutboxService) SendMailToNotifyUser(to, subject, body, ctaText, ctaURL string) error {
+	return s.enqueue(&db_models.MailOutboxMessage{
+		Kind:      db_models.MailKindNotify,
+		Recipient: to,
+		Subject:   subject,
+		Body:      body,
+		CTAText:   ctaText,
+		CTAURL:    ctaURL,
+	}, func() error { return s.smtp.SendMailToNotifyUser(to, subject, body, ctaText, ctaURL) })
+}
+
+func (s *MailOutboxService) SendMailWithAttachment(to, subject, body, ctaText, ctaURL string, attachment MailAttachment) error {
+	return s.enqueue(&db_models.MailOutboxMessage{
+		Kind:           db_models.MailKindNotify,
+		Recipient:      to,
+		Subject:        subject,
+		Body:           body,
+		CTAText:        ctaText,
+		CTAURL:         ctaURL,
+		AttachmentName: attachment.FileName,
+		AttachmentType: attachment.ContentType,
+		Attachment:     attachment.Data,
+	}, func() error { return s.smtp.SendMailWithAttachment(to, subject, body, ctaText, ctaURL, attachment) })
+}
+
+func (s *MailOutboxService) SendMailToResetPassword(to, code string) error {
+	return s.SendLocalized(to, defaultMailLocale, MailTemplateResetPassword, map[string]string{"code": code})
+}
+
+func (s *MailOutboxService) SendLocalized(to, locale, name string, params map[string]string) error {
+	// Rendered now for the outbox listing, and again when sent
+	subject, _, _, used, err := renderMailTemplate(name, locale, params)
+	if err != nil {
+		return err
+	}
+	msg := &db_models.MailOutboxMessage{
+		Kind:      db_models.MailKindTemplate,
+		Recipient: to,
+		Subject:   subject,
+		Template:  name,
+		Locale:    used,
+		Params:    make(map[string]string, len(params)),
+	}
+	for k, v := range params {
+		msg.Params[k] = v
+	}
+	direct := func() error { return s.smtp.SendLocalized(to, locale, name, params) }
+
+	if code, ok := msg.Params["code"]; ok {
+		sealed, err := utils.EncryptString(code)
+		if err != nil {
+			// the code is not stored in clear; without a key it goes out once, unqueued
+			log.Printf("[mail] sending the code to %s without the outbox: %v", to, err)
+			return direct()
+		}
+		delete(msg.Params, "code")
+		msg.Code = sealed
+		expires := time.Now().Add(mailResetCodeTTL).Unix()
+		msg.ExpiresAt = &expires
+	}
+	return s.enqueue(msg, direct)
+}
+
+// enqueue stores the message for the worker; when the database refuses it the message is
+// sent right away instead, as it was before the outbox.
+func (s *MailOutboxService) enqueue(msg *db_models.MailOutboxMessage, direct func() error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	msg.Status = db_models.MailOutboxPending
+	msg.NextAttemptAt = time.Now().Unix()
+	if err := s.repo.Enqueue(ctx, msg); err != nil {
+		log.Printf("[mail] cannot queue %q to %s, sending now: %v", msg.Subject, msg.Recipient, err)
+		return direct()
+	}
+	s.wake()
+	return nil
+}
+
+func (s *MailOutboxService) ListMessages(ctx context.Context, query request_models.MailOutboxQuery) (*response_models.MailOutboxPage, error) {
+	filter := repositories.MailOutboxFilter{Status: query.Status, Recipient: strings.TrimSpace(query.Recipient)}
+	rows, total, err := s.repo.List(ctx, filter, query.Page, query.PageSize)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	out := &response_models.MailOutboxPage{
+		Items:    make([]response_models.MailOutboxMessage, 0, len(rows)),
+		Total:    total,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	}
+	for i := range rows {
+		out.Items = append(out.Items, toMailOutboxResponse(&rows[i]))
+	}
+	return out, nil
+}
+
+func (s *MailOutboxService) RetryMessage(ctx context.Context, id string) (*response_models.MailOutboxMessage, error) {
+	msgID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, utils.ErrInvalidInput.WithMessage("Invalid message ID")
+	}
+	msg, err := s.repo.Find(ctx, id)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if msg == nil {
+		return nil, utils.RecordNotFound.WithMessage("Message not found")
+	}
+	if msg.Status != db_models.MailOutboxDead {
+		return nil, utils.ErrInvalidInput.WithMessage("Only dead messages can be retried")
+	}
+	if msg.ExpiresAt != nil && *msg.ExpiresAt <= time.Now().Unix() {
+		return nil, utils.ErrInvalidInput.WithMessage("This message has expired; the user has to ask for a new one")
+	}
+
+	if _, err := s.repo.Requeue(ctx, []uuid.UUID{msgID}, time.Now().Unix()); err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	s.wake()
+
+	msg, err = s.repo.Find(ctx, id)
+	if err != nil || msg == nil {
+		return nil, utils.ErrDatabaseError
+	}
+	out := toMailOutboxResponse(msg)
+	return &out, nil
+}
+
+func (s *MailOutboxService) RetryDead(ctx context.Context) (*response_models.MailOutboxRetried, error) {
+	// expired messages go back too; the worker dead-letters them again without sending
+	n, err := s.repo.Requeue(ctx, nil, time.Now().Unix())
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if n > 0 {
+		s.wake()
+	}
+	return &response_models.MailOutboxRetried{Requeued: n}, nil
+}
+
+func (s *MailOutboxService) wake() {
+	select {
+	case s.kick <- struct{}{}:
+	default:
+	}
+}
+
+func (s *MailOutboxService) RunOnce(ctx context.Context) (int, error) {
+	due, err := s.repo.ClaimDue(ctx, time.Now().Unix(), mailBatchSize, mailClaimLease)
+	if err != nil {
+		return 0, err
+	}
+	// one SMTP conversation at a time keeps us under the provider's rate limits
+	sent := 0
+	for i := range due {
+		if s.deliver(ctx, &due[i]) {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// deliver makes one attempt and records its outcome.
+func (s *MailOutboxService) deliver(ctx context.Context, m *db_models.MailOutboxMessage) bool {
+	err := s.send(m)
+	if err == nil {
+		if err := s.repo.MarkSent(ctx, m.ID, time.Now().Unix()); err != nil {
+			log.Printf("[mail] message %s sent but not recorded: %v", m.ID, err)
+		}
+		return true
+	}
+
+	var next *int64
+	var permanent *mailPermanentError
+	if !errors.As(err, &permanent) && m.Attempts+1 < s.maxAttempts {
+		delay := mailFirstRetry << m.Attempts
+		if delay > mailMaxRetry {
+			delay = mailMaxRetry
+		}
+		at := time.Now().Add(delay).Unix()
+		next = &at
+	}
+	if next == nil {
+		log.Printf("[mail] giving up on message %s to %s: %v", m.ID, m.Recipient, err)
+	}
+	reason := err.Error()
+	if len(reason) > 500 {
+		reason = reason[:500]
+	}
+	if err := s.repo.MarkAttemptFailed(ctx, m.ID, reason, next); err != nil {
+		log.Printf("[mail] message %s failed and not recorded: %v", m.ID, err)
+	}
+	return false
+}
+
+// mailPermanentError dead-letters a message without retrying it.
+type mailPermanentError struct{ reason string }
+
+func (e *mailPermanentError) Error() string { return e.reason }
+
+func (s *MailOutboxService) send(m *db_models.MailOutboxMessage) error {
+	if m.ExpiresAt != nil && *m.ExpiresAt <= time.Now().Unix() {
+		return &mailPermanentError{"expired before it could be sent"}
+	}
+	switch m.Kind {
+	case db_models.MailKindNotify:
+		if m.AttachmentName != "" {
+			return s.smtp.SendMailWithAttachment(m.Recipient, m.Subject, m.Body, m.CTAText, m.CTAURL, MailAttachment{
+				FileName: m.AttachmentName, ContentType: m.AttachmentType, Data: m.Attachment,
+			})
+		}
+		return s.smtp.SendMailToNotifyUser(m.Recipient, m.Subject, m.Body, m.CTAText, m.CTAURL)
+	case db_models.MailKindResetCode:
+		code, err := utils.DecryptString(m.Code)
+		if err != nil {
+			return &mailPermanentError{"cannot open the reset code"}
+		}
+		return s.smtp.SendMailToResetPassword(m.Recipient, code)
+	case db_models.MailKindTemplate:
+		params := make(map[string]string, len(m.Params)+1)
+		for k, v := range m.Params {
+			params[k] = v
+		}
+		if m.Code != "" {
+			code, err := utils.DecryptString(m.Code)
+			if err != nil {
+				return &mailPermanentError{"cannot open the code"}
+			}
+			params["code"] = code
+		}
+		return s.smtp.SendLocalized(m.Recipient, m.Locale, m.Template, params)
+	default:
+		return &mailPermanentError{"unknown message kind " + m.Kind}
+	}
+}
+
+func (s *MailOutboxService) Start() {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			n, err := s.RunOnce(ctx)
+			cancel()
+			if err != nil {
+				log.Printf("[mail] outbox run failed: %v", err)
+			} else if n > 0 {
+				log.Printf("[mail] sent %d messages", n)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-s.kick:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (s *MailOutboxService) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+func toMailOutboxResponse(m *db_models.MailOutboxMessage) response_models.MailOutboxMessage {
+	out := response_models.MailOutboxMessage{
+		ID:         m.ID.String(),
+		Kind:       m.Kind,
+		Template:   m.Template,
+		Locale:     m.Locale,
+		Recipient:  m.Recipient,
+		Subject:    m.Subject,
+		Attachment: m.AttachmentName,
+		Status:     m.Status,
+		Attempts:   m.Attempts,
+		LastError:  m.LastError,
+		CreatedAt:  utils.FormatRFC3339VN(utils.FromUnixSecondsVN(m.CreatedAt)),
+	}
+	if m.Status == db_models.MailOutboxPending {
+		out.NextAttemptAt = utils.FormatRFC3339VN(utils.FromUnixSecondsVN(m.NextAttemptAt))
+	}
+	if m.SentAt != nil {
+		out.SentAt = utils.FormatRFC3339VN(utils.FromUnixSecondsVN(*m.SentAt))
+	}
+	if m.ExpiresAt != nil {
+		out.ExpiresAt = utils.FormatRFC3339VN(utils.FromUnixSecondsVN(*m.ExpiresAt))
+	}
+	return out
+}
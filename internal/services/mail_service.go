@@ -4,10 +4,12 @@ package services
 import (
 	"bytes"
 	"crypto/tls"
+	"encoding/base64"
 	"fmt"
 	"html/template"
 	"net"
 	"net/smtp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -18,6 +20,21 @@ type IMailService interface {
 	) error
 	// Pass the OTP code as the second arg (re-using the method name to avoid breaking callers).
 	SendMailToResetPassword(to, code string) error
+	// SendLocalized sends a registered template (see mail_templates.go) in locale, falling back
+	// to English. params fill the template; "code" shows a one-time code ("expires_minutes"
+	// says for how long) and "url" the button.
+	SendLocalized(to, locale, name string, params map[string]string) error
+	// SendMailWithAttachment is SendMailToNotifyUser with a file attached, e.g. a receipt.
+	SendMailWithAttachment(
+		to, subject, body, ctaText, ctaURL string, attachment MailAttachment,
+	) error
+}
+
+// MailAttachment is a file sent along with an email.
+type MailAttachment struct {
+	FileName    string
+	ContentType string
+	Data        []byte
 }
 
 // SMTPConfig holds your SMTP + branding config.
@@ -75,16 +92,44 @@ func (s *smtpMailService) SendMailToNotifyUser(
 	return s.send(to, subject, html, text)
 }
 
+func (s *smtpMailService) SendMailWithAttachment(
+	to, subject, body, ctaText, ctaURL string, attachment MailAttachment,
+) error {
+	html, text, err := s.renderEmail(EmailData{
+		Title:     subject,
+		Intro:     body,
+		ButtonURL: ctaURL,
+		ButtonTxt: ctaText,
+		AppName:   s.cfg.AppName,
+		Year:      time.Now().Year(),
+	})
+	if err != nil {
+		return err
+	}
+	return s.send(to, subject, html, text, attachment)
+}
+
 // Now sends an OTP instead of a link. Pass the OTP code as the second param.
 func (s *smtpMailService) SendMailToResetPassword(to, code string) error {
-	subject := "Your verification code"
-	intro := "Use the verification code below to reset your password. For your security, do not share this code with anyone."
+	return s.SendLocalized(to, defaultMailLocale, MailTemplateResetPassword, map[string]string{"code": code})
+}
 
+func (s *smtpMailService) SendLocalized(to, locale, name string, params map[string]string) error {
+	subject, intro, button, used, err := renderMailTemplate(name, locale, params)
+	if err != nil {
+		return err
+	}
 	expires := s.cfg.OTPExpiresMinutes
+	if n, err := strconv.Atoi(params["expires_minutes"]); err == nil {
+		expires = n
+	}
 	html, text, err := s.renderEmail(EmailData{
+		Lang:           used,
 		Title:          subject,
 		Intro:          intro,
-		Code:           code,
+		ButtonURL:      params["url"],
+		ButtonTxt:      button,
+		Code:           params["code"],
 		ExpiresMinutes: expires,
 		AppName:        s.cfg.AppName,
 		Year:           time.Now().Year(),
@@ -98,6 +143,8 @@ func (s *smtpMailService) SendMailToResetPassword(to, code string) error {
 // ------------------- Rendering -------------------
 
 type EmailData struct {
+	Lang           string // en when empty
+	L              mailLayout
 	Title          string
 	Intro          string
 	ButtonURL      string
@@ -110,7 +157,7 @@ type EmailData struct {
 
 // NOTE: If .Code is set, the OTP block is shown and the CTA button is suppressed.
 const baseHTMLTemplate = `<!doctype html>
-<html>
+<html lang="{{.Lang}}">
 <head>
   <meta charset="UTF-8">
   <meta name="viewport" content="width=device-width,initial-scale=1">
@@ -172,6 +219,7 @@ const baseHTMLTemplate = `<!doctype html>
       color: #cbd5e1;
       font-size: 16px;
     }
+    .intro { white-space: pre-line; }
     .btn-container { margin: 32px 0 24px; }
     .btn { 
       display: inline-block; 
@@ -291,28 +339,28 @@ const baseHTMLTemplate = `<!doctype html>
       </div>
       <div class="hero">
         <h1>{{.Title}}</h1>
-        <p>{{.Intro}}</p>
+        <p class="intro">{{.Intro}}</p>
 
         {{if .Code}}
           <div class="otp-wrap">
             <span class="otp-code">{{.Code}}</span>
             <div class="otp-meta">
-              {{if gt .ExpiresMinutes 0}}This code expires in {{.ExpiresMinutes}} minutes.{{else}}This code will expire soon.{{end}}
+              {{if gt .ExpiresMinutes 0}}{{printf .L.CodeExpiresIn .ExpiresMinutes}}{{else}}{{.L.CodeExpiresSoon}}{{end}}
             </div>
           </div>
-          <p class="muted">If you didn’t request this, you can ignore this email.</p>
+          <p class="muted">{{.L.NotRequested}}</p>
         {{else if .ButtonURL}}
           <div class="btn-container">
             <a class="btn" href="{{.ButtonURL}}">{{.ButtonTxt}}</a>
           </div>
           <div class="link-fallback">
-            <p class="muted">If the button doesn't work, copy and paste this link into your browser:</p>
+            <p class="muted">{{.L.LinkFallback}}</p>
             <a href="{{.ButtonURL}}" class="link-text">{{.ButtonURL}}</a>
           </div>
         {{end}}
       </div>
       <div class="footer">
-        © {{.Year}} {{.AppName}}. All rights reserved.
+        © {{.Year}} {{.AppName}}. {{.L.RightsReserved}}
       </div>
     </div>
   </div>
@@ -323,9 +371,9 @@ const plainTextTemplate = `{{.Title}}
 
 {{.Intro}}
 
-{{if .Code}}CODE: {{.Code}}
-{{if gt .ExpiresMinutes 0}}(Expires in {{.ExpiresMinutes}} minutes){{end}}
-{{else if .ButtonURL}}Open this link:
+{{if .Code}}{{.L.CodeLabel}}: {{.Code}}
+{{if gt .ExpiresMinutes 0}}({{printf .L.CodeExpiresIn .ExpiresMinutes}}){{end}}
+{{else if .ButtonURL}}{{.L.OpenLink}}
 {{.ButtonURL}}
 {{end}}
 
@@ -334,6 +382,8 @@ const plainTextTemplate = `{{.Title}}
 
 func (s *smtpMailService) renderEmail(data EmailData) (html string, text string, err error) {
 	var hb, tb bytes.Buffer
+	data.Lang = mailLocale(data.Lang)
+	data.L = mailLayouts[data.Lang]
 
 	// HTML
 	if err = s.notifyTplHTML.Execute(&hb, data); err != nil {
@@ -348,10 +398,11 @@ func (s *smtpMailService) renderEmail(data EmailData) (html string, text string,
 
 // ------------------- SMTP Send -------------------
 
-func (s *smtpMailService) send(to, subject, htmlBody, textBody string) error {
+func (s *smtpMailService) send(to, subject, htmlBody, textBody string, attachments ...MailAttachment) error {
 	fromHeader := s.formatFromHeader()
 	date := time.Now().Format(time.RFC1123Z)
 	boundary := fmt.Sprintf("mixed_%d", time.Now().UnixNano())
+	outer := fmt.Sprintf("outer_%d", time.Now().UnixNano())
 
 	var msg bytes.Buffer
 	write := func(format string, a ...any) { _, _ = msg.WriteString(fmt.Sprintf(format, a...)) }
@@ -362,6 +413,12 @@ func (s *smtpMailService) send(to, subject, htmlBody, textBody string) error {
 	write("Subject: %s\r\n", subject)
 	write("Date: %s\r\n", date)
 	write("MIME-Version: 1.0\r\n")
+	// With attachments the text and HTML bodies become the first part of a multipart/mixed
+	if len(attachments) > 0 {
+		write("Content-Type: multipart/mixed; boundary=%q\r\n", outer)
+		write("\r\n")
+		write("--%s\r\n", outer)
+	}
 	write("Content-Type: multipart/alternative; boundary=%q\r\n", boundary)
 	write("\r\n")
 
@@ -380,6 +437,22 @@ func (s *smtpMailService) send(to, subject, htmlBody, textBody string) error {
 	// End
 	write("--%s--\r\n", boundary)
 
+	for _, a := range attachments {
+		write("\r\n--%s\r\n", outer)
+		write("Content-Type: %s; name=%q\r\n", a.ContentType, a.FileName)
+		write("Content-Disposition: attachment; filename=%q\r\n", a.FileName)
+		write("Content-Transfer-Encoding: base64\r\n\r\n")
+		encoded := base64.StdEncoding.EncodeToString(a.Data)
+		for len(encoded) > 76 {
+			write("%s\r\n", encoded[:76])
+			encoded = encoded[76:]
+		}
+		write("%s\r\n", encoded)
+	}
+	if len(attachments) > 0 {
+		write("--%s--\r\n", outer)
+	}
+
 	addr := fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.Port)
 	auth := smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
 
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// Emails rendered from the template registry, in the recipient's locale.
+const (
+	MailTemplateWelcome       = "welcome"
+	MailTemplateResetPassword = "reset_password"
+	MailTemplateTripItinerary = "trip_itinerary"
+	MailTemplateJourneyBackup = "journey_backup"
+)
+
+// defaultMailLocale is used for unknown locales and for templates without a translation.
+const defaultMailLocale = "en"
+
+// mailTemplate is one email in one language. Subject, Intro and Button are text/templates over
+// the params of the send: "name", "url", "code", and whatever the email itself needs.
+type mailTemplate struct {
+	Subject string
+	Intro   string
+	Button  string
+}
+
+var mailTemplateSources = map[string]map[string]mailTemplate{
+	MailTemplateWelcome: {
+		"en": {
+			Subject: "Welcome to Vivu",
+			Intro:   "Hi {{.name}}, your account is ready. Explore features and let us know if you need help!",
+			Button:  "Start planning",
+		},
+		"vi": {
+			Subject: "Chào mừng bạn đến với Vivu",
+			Intro:   "Chào {{.name}}, tài khoản của bạn đã sẵn sàng. Hãy khám phá các tính năng và cho chúng tôi biết nếu bạn cần hỗ trợ!",
+			Button:  "Bắt đầu lên kế hoạch",
+		},
+	},
+	MailTemplateResetPassword: {
+		"en": {
+			Subject: "Your verification code",
+			Intro:   "Use the verification code below to reset your password. For your security, do not share this code with anyone.",
+		},
+		"vi": {
+			Subject: "Mã xác minh của bạn",
+			Intro:   "Dùng mã xác minh bên dưới để đặt lại mật khẩu. Để bảo mật, đừng chia sẻ mã này với bất kỳ ai.",
+		},
+	},
+	MailTemplateTripItinerary: {
+		"en": {
+			Subject: "Your itinerary for {{.where}}",
+			Intro:   "Your trip to {{.where}} starts on {{.start}}. Here is the plan:\n{{.plan}}",
+			Button:  "Open my trip",
+		},
+		"vi": {
+			Subject: "Lịch trình chuyến đi {{.where}}",
+			Intro:   "Chuyến đi {{.where}} của bạn bắt đầu ngày {{.start}}. Đây là lịch trình:\n{{.plan}}",
+			Button:  "Mở chuyến đi",
+		},
+	},
+	MailTemplateJourneyBackup: {
+		"en": {
+			Subject: "Your Vivu trips backup is ready",
+			Intro:   "Hi {{.name}}, the backup of your {{.count}} trips is ready. You can download it until {{.expires}}.",
+			Button:  "Download backup",
+		},
+		"vi": {
+			Subject: "Bản sao lưu chuyến đi Vivu của bạn đã sẵn sàng",
+			Intro:   "Chào {{.name}}, bản sao lưu {{.count}} chuyến đi của bạn đã sẵn sàng. Bạn có thể tải về đến {{.expires}}.",
+			Button:  "Tải bản sao lưu",
+		},
+	},
+}
+
+// mailLayout holds the wording of the shared layout around every email.
+type mailLayout struct {
+	CodeExpiresIn   string // formatted with the minutes
+	CodeExpiresSoon string
+	NotRequested    string
+	LinkFallback    string
+	OpenLink        string
+	CodeLabel       string
+	RightsReserved  string
+}
+
+var mailLayouts = map[string]mailLayout{
+	"en": {
+		CodeExpiresIn:   "This code expires in %d minutes.",
+		CodeExpiresSoon: "This code will expire soon.",
+		NotRequested:    "If you didn’t request this, you can ignore this email.",
+		LinkFallback:    "If the button doesn't work, copy and paste this link into your browser:",
+		OpenLink:        "Open this link:",
+		CodeLabel:       "CODE",
+		RightsReserved:  "All rights reserved.",
+	},
+	"vi": {
+		CodeExpiresIn:   "Mã này hết hạn sau %d phút.",
+		CodeExpiresSoon: "Mã này sẽ sớm hết hạn.",
+		NotRequested:    "Nếu bạn không yêu cầu, hãy bỏ qua email này.",
+		LinkFallback:    "Nếu nút không hoạt động, hãy sao chép và dán liên kết này vào trình duyệt:",
+		OpenLink:        "Mở liên kết:",
+		CodeLabel:       "MÃ",
+		RightsReserved:  "Bảo lưu mọi quyền.",
+	},
+}
+
+type compiledMailTemplate struct {
+	subject, intro, button *template.Template
+}
+
+var mailTemplates = compileMailTemplates()
+
+func compileMailTemplates() map[string]map[string]compiledMailTemplate {
+	out := make(map[string]map[string]compiledMailTemplate, len(mailTemplateSources))
+	for name, locales := range mailTemplateSources {
+		out[name] = make(map[string]compiledMailTemplate, len(locales))
+		for locale, src := range locales {
+			id := name + "." + locale
+			out[name][locale] = compiledMailTemplate{
+				subject: template.Must(template.New(id + ".subject").Option("missingkey=zero").Parse(src.Subject)),
+				intro:   template.Must(template.New(id + ".intro").Option("missingkey=zero").Parse(src.Intro)),
+				button:  template.Must(template.New(id + ".button").Option("missingkey=zero").Parse(src.Button)),
+			}
+		}
+	}
+	return out
+}
+
+// mailLocale returns locale when emails can be written in it, the default otherwise.
+func mailLocale(locale string) string {
+	if _, ok := mailLayouts[locale]; ok {
+		return locale
+	}
+	return defaultMailLocale
+}
+
+// renderMailTemplate renders template name in locale, or in the default locale when it has no
+// translation, and returns the locale it used.
+func renderMailTemplate(name, locale string, params map[string]string) (subject, intro, button, used string, err error) {
+	locales, ok := mailTemplates[name]
+	if !ok {
+		return "", "", "", "", fmt.Errorf("unknown mail template %q", name)
+	}
+	used = mailLocale(locale)
+	tpl, ok := locales[used]
+	if !ok {
+		used = defaultMailLocale
+		tpl = locales[used]
+	}
+
+	var b bytes.Buffer
+	render := func(t *template.Template) (string, error) {
+		b.Reset()
+		if err := t.Execute(&b, params); err != nil {
+			return "", err
+		}
+		return b.String(), nil
+	}
+	if subject, err = render(tpl.subject); err != nil {
+		return "", "", "", "", err
+	}
+	if intro, err = render(tpl.intro); err != nil {
+		return "", "", "", "", err
+	}
+	if button, err = render(tpl.button); err != nil {
+		return "", "", "", "", err
+	}
+	return subject, intro, button, used, nil
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// FailoverMatrixService asks the primary provider first and falls back to the secondary when
+// the primary is out of quota, erroring on the server side or unreachable. Both providers
+// should share a MatrixPairCache so the fallback only fetches pairs the primary did not return.
+type FailoverMatrixService struct {
+	primary   DistanceMatrixService
+	secondary DistanceMatrixService
+
+	// After a retryable failure the primary is skipped for cooldown (quota rarely comes back in seconds)
+	cooldown time.Duration
+
+	mu        sync.Mutex
+	skipUntil time.Time
+}
+
+func NewFailoverMatrixService(primary, secondary DistanceMatrixService, cooldown time.Duration) *FailoverMatrixService {
+	return &FailoverMatrixService{primary: primary, secondary: secondary, cooldown: cooldown}
+}
+
+func (f *FailoverMatrixService) ComputeDistances(ctx context.Context, points []MatrixPoint, mode string) (DistanceMatrix, error) {
+	if !f.primaryCoolingDown() {
+		mat, err := f.primary.ComputeDistances(ctx, points, mode)
+		// A cancelled caller is not the provider's fault
+		if err == nil || ctx.Err() != nil || !shouldFailover(err) {
+			return mat, err
+		}
+		log.Printf("[matrix] primary provider failed, using fallback for %ds: %v", int(f.cooldown.Seconds()), err)
+		f.startCooldown()
+	}
+
+	return f.secondary.ComputeDistances(ctx, points, mode)
+}
+
+func (f *FailoverMatrixService) primaryCoolingDown() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return time.Now().Before(f.skipUntil)
+}
+
+func (f *FailoverMatrixService) startCooldown() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.skipUntil = time.Now().Add(f.cooldown)
+}
+
+// shouldFailover is true for provider errors another provider could plausibly answer.
+// A PartialMatrixError unwraps to its first tile error, so partial quota failures fail over too.
+func shouldFailover(err error) bool {
+	var pe *MatrixProviderError
+	if errors.As(err, &pe) {
+		return pe.Retryable()
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"vivu/pkg/utils"
+)
+
+// -------------- OSRM table client (self-hosted fallback) ---------------
+
+// OSRM's default --max-table-size is 100 coordinates.
+const osrmMaxCoordinates = 100
+
+type OSRMMatrixClient struct {
+	HTTP       *http.Client
+	BaseURL    string // e.g. http://osrm:5000
+	Cache      MatrixPairCache
+	DefaultTTL time.Duration
+	Profile    string // default mode when none is given: "driving"
+
+	// OSRM profile names differ from ours and depend on how the instance was built.
+	Profiles map[string]string
+
+	MaxCoordinates int
+}
+
+// NewOSRMMatrixClient reads OSRM_BASE_URL (required) and OSRM_MAX_COORDINATES.
+// OSRM_PROFILE_DRIVING / _WALKING / _CYCLING override the profile names sent to the server.
+func NewOSRMMatrixClient(cache MatrixPairCache) *OSRMMatrixClient {
+	base := strings.TrimRight(os.Getenv("OSRM_BASE_URL"), "/")
+	if base == "" {
+		panic("OSRM_BASE_URL is empty")
+	}
+
+	maxCoords := osrmMaxCoordinates
+	if v, err := strconv.Atoi(os.Getenv("OSRM_MAX_COORDINATES")); err == nil && v > 1 {
+		maxCoords = v
+	}
+
+	profiles := map[string]string{
+		TravelModeDriving: "driving",
+		TravelModeWalking: "foot",
+		TravelModeCycling: "bike",
+	}
+	for mode := range profiles {
+		if v := os.Getenv("OSRM_PROFILE_" + strings.ToUpper(mode)); v != "" {
+			profiles[mode] = v
+		}
+	}
+
+	return &OSRMMatrixClient{
+		HTTP:       utils.NewTracedHTTPClient(15 * time.Second),
+		BaseURL:    base,
+		Cache:      cache,
+		DefaultTTL: 7 * 24 * time.Hour,
+		Profile:    TravelModeDriving,
+		Profiles:   profiles,
+
+		MaxCoordinates: maxCoords,
+	}
+}
+
+func (c *OSRMMatrixClient) ComputeDistances(ctx context.Context, points []MatrixPoint, mode string) (DistanceMatrix, error) {
+	if mode == "" {
+		mode = c.Profile
+	}
+	maxCoords := c.MaxCoordinates
+	if maxCoords <= 1 {
+		maxCoords = osrmMaxCoordinates
+	}
+	return computeTiledMatrix(ctx, c.Cache, c.DefaultTTL, maxCoords, points, NormalizeTravelMode(mode), c.fetchTile)
+}
+
+// fetchTile calls the OSRM table service for one tile.
+func (c *OSRMMatrixClient) fetchTile(ctx context.Context, mode string, points []MatrixPoint, t matrixTile) ([][]*MatrixEdge, error) {
+	coords, srcPos, dstPos := tileCoordinates(points, t)
+
+	profile := c.Profiles[mode]
+	if profile == "" {
+		profile = mode
+	}
+
+	q := url.Values{}
+	q.Set("annotations", "distance,duration")
+	q.Set("sources", strings.Join(srcPos, ";"))
+	q.Set("destinations", strings.Join(dstPos, ";"))
+	u := fmt.Sprintf("%s/table/v1/%s/%s?%s", c.BaseURL, profile, strings.Join(coords, ";"), q.Encode())
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", u, nil)
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, &MatrixProviderError{Provider: "osrm", Err: err}
+	}
+	defer resp.Body.Close()
+
+	var payload struct {
+		Code      string       `json:"code"`
+		Message   string       `json:"message"`
+		Distances [][]*float64 `json:"distances"`
+		Durations [][]*float64 `json:"durations"`
+	}
+	if resp.StatusCode/100 != 2 {
+		_ = json.NewDecoder(resp.Body).Decode(&payload)
+		return nil, &MatrixProviderError{Provider: "osrm", StatusCode: resp.StatusCode, Err: fmt.Errorf("bad status: %s %s", resp.Status, payload.Message)}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, &MatrixProviderError{Provider: "osrm", StatusCode: resp.StatusCode, Err: fmt.Errorf("decode: %w", err)}
+	}
+	if payload.Code != "Ok" {
+		return nil, &MatrixProviderError{Provider: "osrm", StatusCode: http.StatusUnprocessableEntity, Err: fmt.Errorf("error code: %s %s", payload.Code, payload.Message)}
+	}
+
+	return tableEdges(t, payload.Distances, payload.Durations), nil
+}
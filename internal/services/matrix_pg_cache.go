@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"vivu/internal/models/db_models"
+	"vivu/internal/repositories"
+)
+
+// --------- Postgres-backed cache (poi_distance_cache) ---------
+
+type postgresPairCache struct {
+	repo    repositories.DistanceCacheRepositoryInterface
+	writes  chan db_models.PoiDistanceCache
+	timeout time.Duration
+}
+
+// NewPostgresPairCache persists pairs in poi_distance_cache. Writes are buffered and flushed
+// in batches by a background worker so a 25-point matrix does not mean 600 round trips.
+func NewPostgresPairCache(repo repositories.DistanceCacheRepositoryInterface) MatrixPairCache {
+	c := &postgresPairCache{
+		repo:    repo,
+		writes:  make(chan db_models.PoiDistanceCache, 4096),
+		timeout: 3 * time.Second,
+	}
+	go c.flushLoop()
+	return c
+}
+
+func (c *postgresPairCache) Get(k pairKey) (MatrixEdge, bool) {
+	got := c.GetMany([]pairKey{k})
+	v, ok := got[k]
+	return v, ok
+}
+
+func (c *postgresPairCache) GetMany(keys []pairKey) map[pairKey]MatrixEdge {
+	out := make(map[pairKey]MatrixEdge, len(keys))
+	if len(keys) == 0 {
+		return out
+	}
+
+	// Group POI ids by mode, one query per mode
+	byMode := make(map[string]map[string]struct{})
+	for _, k := range keys {
+		if byMode[k.Mode] == nil {
+			byMode[k.Mode] = make(map[string]struct{})
+		}
+		byMode[k.Mode][k.A] = struct{}{}
+		byMode[k.Mode][k.B] = struct{}{}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	wanted := make(map[pairKey]struct{}, len(keys))
+	for _, k := range keys {
+		wanted[k] = struct{}{}
+	}
+
+	now := time.Now().Unix()
+	for mode, set := range byMode {
+		ids := make([]string, 0, len(set))
+		for id := range set {
+			ids = append(ids, id)
+		}
+		rows, err := c.repo.FindEdges(ctx, mode, ids, now)
+		if err != nil {
+			log.Printf("[matrix-cache] postgres read failed: %v", err)
+			continue
+		}
+		for _, r := range rows {
+			k := pairKey{Mode: r.Mode, A: r.FromPoi, B: r.ToPoi}
+			if _, ok := wanted[k]; ok {
+				out[k] = MatrixEdge{DistanceMeters: r.DistanceMeters, DurationSeconds: r.DurationSeconds}
+			}
+		}
+	}
+	return out
+}
+
+func (c *postgresPairCache) Set(k pairKey, v MatrixEdge, ttl time.Duration) {
+	row := db_models.PoiDistanceCache{
+		Mode:            k.Mode,
+		FromPoi:         k.A,
+		ToPoi:           k.B,
+		DistanceMeters:  v.DistanceMeters,
+		DurationSeconds: v.DurationSeconds,
+		ExpiresAt:       time.Now().Add(ttl).Unix(),
+	}
+	select {
+	case c.writes <- row:
+	default:
+		// Buffer full: losing a cache write only costs a future API call
+		log.Printf("[matrix-cache] write buffer full, dropping %s %s->%s", k.Mode, k.A, k.B)
+	}
+}
+
+func (c *postgresPairCache) flushLoop() {
+	const batchSize = 500
+	flushTicker := time.NewTicker(time.Second)
+	cleanupTicker := time.NewTicker(time.Hour)
+	defer flushTicker.Stop()
+	defer cleanupTicker.Stop()
+
+	batch := make([]db_models.PoiDistanceCache, 0, batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := c.repo.UpsertEdges(ctx, batch); err != nil {
+			log.Printf("[matrix-cache] postgres write failed (%d rows): %v", len(batch), err)
+		}
+		cancel()
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case row := <-c.writes:
+			batch = append(batch, row)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-flushTicker.C:
+			flush()
+		case <-cleanupTicker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			if n, err := c.repo.DeleteExpired(ctx, time.Now().Unix()); err != nil {
+				log.Printf("[matrix-cache] cleanup failed: %v", err)
+			} else if n > 0 {
+				log.Printf("[matrix-cache] removed %d expired pairs", n)
+			}
+			cancel()
+		}
+	}
+}
+
+// --------- L1 (memory) + L2 (postgres) ---------
+
+type tieredPairCache struct {
+	l1    MatrixPairCache
+	l2    MatrixPairCache
+	l1TTL time.Duration
+}
+
+// NewTieredPairCache serves from l1 first and back-fills it from l2 hits.
+func NewTieredPairCache(l1, l2 MatrixPairCache, l1TTL time.Duration) MatrixPairCache {
+	return &tieredPairCache{l1: l1, l2: l2, l1TTL: l1TTL}
+}
+
+func (c *tieredPairCache) Get(k pairKey) (MatrixEdge, bool) {
+	if v, ok := c.l1.Get(k); ok {
+		return v, true
+	}
+	if v, ok := c.l2.Get(k); ok {
+		c.l1.Set(k, v, c.l1TTL)
+		return v, true
+	}
+	return MatrixEdge{}, false
+}
+
+func (c *tieredPairCache) GetMany(keys []pairKey) map[pairKey]MatrixEdge {
+	out := c.l1.GetMany(keys)
+	if len(out) == len(keys) {
+		return out
+	}
+
+	misses := make([]pairKey, 0, len(keys)-len(out))
+	for _, k := range keys {
+		if _, ok := out[k]; !ok {
+			misses = append(misses, k)
+		}
+	}
+	for k, v := range c.l2.GetMany(misses) {
+		out[k] = v
+		c.l1.Set(k, v, c.l1TTL)
+	}
+	return out
+}
+
+func (c *tieredPairCache) Set(k pairKey, v MatrixEdge, ttl time.Duration) {
+	l1TTL := c.l1TTL
+	if ttl < l1TTL {
+		l1TTL = ttl
+	}
+	c.l1.Set(k, v, l1TTL)
+	c.l2.Set(k, v, ttl)
+}
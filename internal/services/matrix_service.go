@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
 	"sync"
 	"time"
+
+	"vivu/pkg/secrets"
+	"vivu/pkg/utils"
 )
 
 type MatrixPoint struct {
@@ -19,7 +21,36 @@ type MatrixPoint struct {
 }
 
 type MatrixEdge struct {
-	DistanceMeters int
+	DistanceMeters  int
+	DurationSeconds int
+}
+
+// Travel modes map 1:1 to Mapbox profiles
+const (
+	TravelModeDriving = "driving"
+	TravelModeWalking = "walking"
+	TravelModeCycling = "cycling"
+)
+
+// NormalizeTravelMode returns a supported mode, defaulting to driving.
+func NormalizeTravelMode(mode string) string {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case TravelModeWalking:
+		return TravelModeWalking
+	case TravelModeCycling:
+		return TravelModeCycling
+	default:
+		return TravelModeDriving
+	}
+}
+
+// IsValidTravelMode reports whether mode is empty (default) or one of the supported modes.
+func IsValidTravelMode(mode string) bool {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", TravelModeDriving, TravelModeWalking, TravelModeCycling:
+		return true
+	}
+	return false
 }
 
 type DistanceMatrix map[string]map[string]MatrixEdge
@@ -27,7 +58,7 @@ type DistanceMatrix map[string]map[string]MatrixEdge
 // --------- In-memory cache theo cặp (A,B) ---------
 
 type pairKey struct {
-	Mode string // "driving"
+	Mode string // "driving" | "walking" | "cycling"
 	A    string // ID POI ổn định
 	B    string
 }
@@ -39,6 +70,8 @@ type matrixPairCacheEntry struct {
 
 type MatrixPairCache interface {
 	Get(k pairKey) (MatrixEdge, bool)
+	// GetMany returns only the keys that were found
+	GetMany(keys []pairKey) map[pairKey]MatrixEdge
 	Set(k pairKey, v MatrixEdge, ttl time.Duration)
 }
 
@@ -61,65 +94,134 @@ func (c *inMemoryPairCache) Get(k pairKey) (MatrixEdge, bool) {
 	return it.Edge, true
 }
 
+func (c *inMemoryPairCache) GetMany(keys []pairKey) map[pairKey]MatrixEdge {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make(map[pairKey]MatrixEdge, len(keys))
+	now := time.Now()
+	for _, k := range keys {
+		if it, ok := c.store[k]; ok && now.Before(it.ExpiresAt) {
+			out[k] = it.Edge
+		}
+	}
+	return out
+}
+
 func (c *inMemoryPairCache) Set(k pairKey, v MatrixEdge, ttl time.Duration) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.store[k] = matrixPairCacheEntry{Edge: v, ExpiresAt: time.Now().Add(ttl)}
 }
 
-// -------------- Mapbox Matrix client (distance-only) ---------------
+// -------------- Mapbox Matrix client (distance + duration) ---------------
 
 type DistanceMatrixService interface {
-	ComputeDistances(ctx context.Context, points []MatrixPoint) (DistanceMatrix, error)
+	// ComputeDistances returns distances and durations between every pair of points for the
+	// given travel mode (empty means the client's default profile).
+	ComputeDistances(ctx context.Context, points []MatrixPoint, mode string) (DistanceMatrix, error)
 }
 
 type MapboxMatrixClient struct {
-	HTTP        *http.Client
-	AccessToken string
+	HTTP *http.Client
+	// AccessToken returns the current token; it is read on every request so a rotated token
+	// is used without a restart.
+	AccessToken func() string
 	Cache       MatrixPairCache
 	DefaultTTL  time.Duration // ví dụ 7 ngày
-	Profile     string        // "driving"
+	Profile     string        // default mode when none is given: "driving"
+
+	MaxCoordinates int // per request; 0 means the Mapbox limit (25)
 }
 
-func NewMapboxMatrixClient(cache MatrixPairCache) *MapboxMatrixClient {
-	token := os.Getenv("MAPBOX_ACCESS_TOKEN")
-	if token == "" {
+func NewMapboxMatrixClient(cache MatrixPairCache, keys secrets.Getter) *MapboxMatrixClient {
+	if keys.Get("MAPBOX_ACCESS_TOKEN") == "" {
 		panic("MAPBOX_ACCESS_TOKEN is empty")
 	}
 	return &MapboxMatrixClient{
-		HTTP:        &http.Client{Timeout: 15 * time.Second},
-		AccessToken: token,
+		HTTP:        utils.NewTracedHTTPClient(15 * time.Second),
+		AccessToken: func() string { return keys.Get("MAPBOX_ACCESS_TOKEN") },
 		Cache:       cache,
 		DefaultTTL:  7 * 24 * time.Hour,
-		Profile:     "driving",
+		Profile:     TravelModeDriving,
+
+		MaxCoordinates: mapboxMaxCoordinates,
 	}
 }
 
-func (c *MapboxMatrixClient) ComputeDistances(ctx context.Context, points []MatrixPoint) (DistanceMatrix, error) {
+// Mapbox accepts at most 25 coordinates per matrix request (sources ∪ destinations).
+const mapboxMaxCoordinates = 25
+
+// PartialMatrixError is returned together with a usable (but incomplete) matrix when
+// some tiles failed. Pairs from failed tiles are simply absent from the matrix.
+type PartialMatrixError struct {
+	FailedTiles int
+	TotalTiles  int
+	Err         error // first tile error
+}
+
+func (e *PartialMatrixError) Error() string {
+	return fmt.Sprintf("distance matrix incomplete: %d/%d tiles failed: %v", e.FailedTiles, e.TotalTiles, e.Err)
+}
+
+func (e *PartialMatrixError) Unwrap() error { return e.Err }
+
+type matrixTile struct {
+	src []int // indexes into points
+	dst []int
+}
+
+func (c *MapboxMatrixClient) ComputeDistances(ctx context.Context, points []MatrixPoint, mode string) (DistanceMatrix, error) {
+	if mode == "" {
+		mode = c.Profile
+	}
+	maxCoords := c.MaxCoordinates
+	if maxCoords <= 1 {
+		maxCoords = mapboxMaxCoordinates
+	}
+	return computeTiledMatrix(ctx, c.Cache, c.DefaultTTL, maxCoords, points, NormalizeTravelMode(mode), c.fetchTile)
+}
+
+// tileFetcher asks a provider for one tile. The result is indexed [src][dst]; nil means no route.
+type tileFetcher func(ctx context.Context, mode string, points []MatrixPoint, t matrixTile) ([][]*MatrixEdge, error)
+
+// computeTiledMatrix is the provider-independent part of a matrix call: serve what we can from
+// the cache, split the misses into tiles of at most maxCoords coordinates and fetch them concurrently.
+func computeTiledMatrix(ctx context.Context, cache MatrixPairCache, ttl time.Duration, maxCoords int, points []MatrixPoint, mode string, fetch tileFetcher) (DistanceMatrix, error) {
 	n := len(points)
 	if n == 0 {
 		return DistanceMatrix{}, nil
 	}
 
-	mode := c.Profile
 	mat := make(DistanceMatrix, n)
-	needCall := false
+	missing := make([][]bool, n)
 
 	for _, p := range points {
 		mat[p.ID] = make(map[string]MatrixEdge, n)
 	}
 
-	// 1) Thử lấy từ cache
+	// 1) Thử lấy từ cache (một lần cho tất cả các cặp)
+	keys := make([]pairKey, 0, n*(n-1))
 	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i != j {
+				keys = append(keys, pairKey{Mode: mode, A: points[i].ID, B: points[j].ID})
+			}
+		}
+	}
+	cached := cache.GetMany(keys)
+
+	needCall := false
+	for i := 0; i < n; i++ {
+		missing[i] = make([]bool, n)
 		for j := 0; j < n; j++ {
 			if i == j {
 				mat[points[i].ID][points[j].ID] = MatrixEdge{DistanceMeters: 0}
 				continue
 			}
-			k := pairKey{Mode: mode, A: points[i].ID, B: points[j].ID}
-			if v, ok := c.Cache.Get(k); ok {
+			if v, ok := cached[pairKey{Mode: mode, A: points[i].ID, B: points[j].ID}]; ok {
 				mat[points[i].ID][points[j].ID] = v
 			} else {
+				missing[i][j] = true
 				needCall = true
 			}
 		}
@@ -129,58 +231,211 @@ func (c *MapboxMatrixClient) ComputeDistances(ctx context.Context, points []Matr
 		return mat, nil
 	}
 
-	// 2) Gọi Mapbox Matrix cho toàn bộ tập điểm
-	coords := make([]string, 0, n)
-	for _, p := range points {
-		coords = append(coords, fmt.Sprintf("%f,%f", p.Lng, p.Lat))
+	// 2) Chia tập điểm thành các tile (sources x destinations) vừa giới hạn của provider,
+	//    chỉ gọi những tile còn thiếu trong cache
+	tiles := planMatrixTiles(n, maxCoords, missing)
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		failed   int
+		firstErr error
+		sem      = make(chan struct{}, 4)
+	)
+
+	for _, t := range tiles {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(t matrixTile) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			edges, err := fetch(ctx, mode, points, t)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			// 3) Ghi vào matrix + cache
+			for si, i := range t.src {
+				for di, j := range t.dst {
+					if i == j || edges[si][di] == nil {
+						continue
+					}
+					edge := *edges[si][di]
+					mat[points[i].ID][points[j].ID] = edge
+					cache.Set(pairKey{Mode: mode, A: points[i].ID, B: points[j].ID}, edge, ttl)
+				}
+			}
+		}(t)
+	}
+	wg.Wait()
+
+	if failed == len(tiles) {
+		return nil, firstErr
+	}
+	if failed > 0 {
+		return mat, &PartialMatrixError{FailedTiles: failed, TotalTiles: len(tiles), Err: firstErr}
+	}
+	return mat, nil
+}
+
+// planMatrixTiles splits n points into blocks of maxCoords/2 so that any (source block,
+// destination block) pair fits in one request, and keeps only tiles with cache misses.
+func planMatrixTiles(n, maxCoords int, missing [][]bool) []matrixTile {
+	block := maxCoords / 2
+	if n <= maxCoords {
+		block = n
+	}
+
+	var blocks [][]int
+	for start := 0; start < n; start += block {
+		end := start + block
+		if end > n {
+			end = n
+		}
+		idx := make([]int, 0, end-start)
+		for i := start; i < end; i++ {
+			idx = append(idx, i)
+		}
+		blocks = append(blocks, idx)
 	}
-	coordStr := strings.Join(coords, ";")
+
+	var tiles []matrixTile
+	for _, src := range blocks {
+		for _, dst := range blocks {
+			need := false
+			for _, i := range src {
+				for _, j := range dst {
+					if missing[i][j] {
+						need = true
+						break
+					}
+				}
+				if need {
+					break
+				}
+			}
+			if need {
+				tiles = append(tiles, matrixTile{src: src, dst: dst})
+			}
+		}
+	}
+	return tiles
+}
+
+// fetchTile calls Mapbox for one tile. The result is indexed [src][dst]; nil means no route.
+func (c *MapboxMatrixClient) fetchTile(ctx context.Context, mode string, points []MatrixPoint, t matrixTile) ([][]*MatrixEdge, error) {
+	coords, srcPos, dstPos := tileCoordinates(points, t)
 
 	u := url.URL{
 		Scheme: "https",
 		Host:   "api.mapbox.com",
-		Path:   fmt.Sprintf("/directions-matrix/v1/mapbox/%s/%s", mode, coordStr),
+		Path:   fmt.Sprintf("/directions-matrix/v1/mapbox/%s/%s", mode, strings.Join(coords, ";")),
 	}
 	q := url.Values{}
-	q.Set("annotations", "distance") // chỉ cần distance
-	q.Set("sources", "all")
-	q.Set("destinations", "all")
-	q.Set("access_token", c.AccessToken)
+	q.Set("annotations", "distance,duration")
+	q.Set("sources", strings.Join(srcPos, ";"))
+	q.Set("destinations", strings.Join(dstPos, ";"))
+	q.Set("access_token", c.AccessToken())
 	u.RawQuery = q.Encode()
 
 	req, _ := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	resp, err := c.HTTP.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("mapbox matrix http error: %w", err)
+		return nil, &MatrixProviderError{Provider: "mapbox", Err: err}
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode/100 != 2 {
-		return nil, fmt.Errorf("mapbox matrix bad status: %s", resp.Status)
+		return nil, &MatrixProviderError{Provider: "mapbox", StatusCode: resp.StatusCode, Err: fmt.Errorf("bad status: %s", resp.Status)}
 	}
 
 	var payload struct {
+		Code      string       `json:"code"`
 		Distances [][]*float64 `json:"distances"`
+		Durations [][]*float64 `json:"durations"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
-		return nil, fmt.Errorf("mapbox decode: %w", err)
+		return nil, &MatrixProviderError{Provider: "mapbox", StatusCode: resp.StatusCode, Err: fmt.Errorf("decode: %w", err)}
+	}
+	if payload.Code != "" && payload.Code != "Ok" {
+		return nil, &MatrixProviderError{Provider: "mapbox", StatusCode: http.StatusUnprocessableEntity, Err: fmt.Errorf("error code: %s", payload.Code)}
 	}
 
-	// 3) Ghi vào matrix + cache
-	for i := 0; i < n; i++ {
-		for j := 0; j < n; j++ {
-			if i == j {
-				mat[points[i].ID][points[j].ID] = MatrixEdge{DistanceMeters: 0}
+	return tableEdges(t, payload.Distances, payload.Durations), nil
+}
+
+// tileCoordinates lists the coordinates of a tile (sources ∪ destinations, deduplicated since
+// diagonal tiles share a block) and the positions of sources/destinations within that list.
+// Mapbox and OSRM both take "lng,lat;lng,lat" plus ";"-joined sources/destinations.
+func tileCoordinates(points []MatrixPoint, t matrixTile) (coords, srcPos, dstPos []string) {
+	coordIdx := make([]int, 0, len(t.src)+len(t.dst))
+	pos := make(map[int]int, len(t.src)+len(t.dst))
+	for _, i := range append(append([]int(nil), t.src...), t.dst...) {
+		if _, ok := pos[i]; ok {
+			continue
+		}
+		pos[i] = len(coordIdx)
+		coordIdx = append(coordIdx, i)
+	}
+
+	coords = make([]string, 0, len(coordIdx))
+	for _, i := range coordIdx {
+		coords = append(coords, fmt.Sprintf("%f,%f", points[i].Lng, points[i].Lat))
+	}
+	srcPos = make([]string, 0, len(t.src))
+	for _, i := range t.src {
+		srcPos = append(srcPos, fmt.Sprintf("%d", pos[i]))
+	}
+	dstPos = make([]string, 0, len(t.dst))
+	for _, j := range t.dst {
+		dstPos = append(dstPos, fmt.Sprintf("%d", pos[j]))
+	}
+	return coords, srcPos, dstPos
+}
+
+// tableEdges converts the distances/durations tables of a matrix response into edges.
+func tableEdges(t matrixTile, distances, durations [][]*float64) [][]*MatrixEdge {
+	out := make([][]*MatrixEdge, len(t.src))
+	for si := range t.src {
+		out[si] = make([]*MatrixEdge, len(t.dst))
+		for di := range t.dst {
+			if si >= len(distances) || di >= len(distances[si]) || distances[si][di] == nil {
 				continue
 			}
-			dM := 0
-			if payload.Distances != nil && i < len(payload.Distances) && j < len(payload.Distances[i]) && payload.Distances[i][j] != nil {
-				dM = int(*payload.Distances[i][j] + 0.5)
+			edge := MatrixEdge{DistanceMeters: int(*distances[si][di] + 0.5)}
+			if si < len(durations) && di < len(durations[si]) && durations[si][di] != nil {
+				edge.DurationSeconds = int(*durations[si][di] + 0.5)
 			}
-			edge := MatrixEdge{DistanceMeters: dM}
-			mat[points[i].ID][points[j].ID] = edge
-			c.Cache.Set(pairKey{Mode: mode, A: points[i].ID, B: points[j].ID}, edge, c.DefaultTTL)
+			out[si][di] = &edge
 		}
 	}
+	return out
+}
 
-	return mat, nil
+// MatrixProviderError describes a failed provider call. StatusCode is 0 for transport errors.
+type MatrixProviderError struct {
+	Provider   string
+	StatusCode int
+	Err        error
+}
+
+func (e *MatrixProviderError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("%s matrix: status %d: %v", e.Provider, e.StatusCode, e.Err)
+	}
+	return fmt.Sprintf("%s matrix: %v", e.Provider, e.Err)
+}
+
+func (e *MatrixProviderError) Unwrap() error { return e.Err }
+
+// Retryable reports whether another provider is worth trying: quota exhaustion (429),
+// server errors and transport failures. 4xx for bad input would fail everywhere.
+func (e *MatrixProviderError) Retryable() bool {
+	return e.StatusCode == 0 || e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
 }
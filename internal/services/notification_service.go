@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+type NotificationServiceInterface interface {
+	// EventBus turns the events an account should hear about into notifications.
+	EventBus
+
+	ListNotifications(ctx context.Context, userID string, query request_models.NotificationQuery) (*response_models.NotificationPage, error)
+	MarkRead(ctx context.Context, userID, notificationID string) error
+}
+
+type NotificationService struct {
+	repo repositories.NotificationRepositoryInterface
+}
+
+func NewNotificationService(repo repositories.NotificationRepositoryInterface) NotificationServiceInterface {
+	return &NotificationService{repo: repo}
+}
+
+// notificationText returns the title and body of the notification for an event; ok is false
+// for events that do not make one.
+func notificationText(eventType string, data any) (title, body string, ok bool) {
+	switch e := data.(type) {
+	case PlanGeneratedEvent:
+		return "Your plan is saved",
+			fmt.Sprintf("Your %d-day trip to %s is ready in your journeys.", e.DurationDays, e.Destination), true
+	case SubscriptionEvent:
+		ends := e.EndsAt
+		if t, err := time.Parse(time.RFC3339, e.EndsAt); err == nil {
+			ends = t.In(vnLoc).Format("02/01/2006")
+		}
+		switch eventType {
+		case EventSubscriptionActivated:
+			return "Payment received", fmt.Sprintf("Your %s subscription is active until %s.", e.PlanCode, ends), true
+		case EventSubscriptionExpiring:
+			return "Subscription expiring soon",
+				fmt.Sprintf("Your %s subscription ends on %s. Renew to keep your benefits.", e.PlanCode, ends), true
+		case EventSubscriptionPastDue:
+			return "Renewal payment due",
+				fmt.Sprintf("We could not renew your %s subscription. Pay the renewal we emailed you to keep your benefits.", e.PlanCode), true
+		case EventSubscriptionExpired:
+			return "Subscription ended",
+				fmt.Sprintf("Your %s subscription was not renewed and your account is back on the free plan.", e.PlanCode), true
+		}
+	case PaymentFailedEvent:
+		body := "We could not complete your payment. No money was taken for this order."
+		if e.Reason != "" {
+			body = fmt.Sprintf("We could not complete your payment (%s). No money was taken for this order.", e.Reason)
+		}
+		return "Payment failed", body, true
+	case PaymentRefundedEvent:
+		return "Payment refunded", refundNotice(e), true
+	}
+	return "", "", false
+}
+
+func (s *NotificationService) Publish(ctx context.Context, accountID uuid.UUID, eventType string, data any) {
+	title, body, ok := notificationText(eventType, data)
+	if !ok {
+		return
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("[notifications] dropping %s for %s: %v", eventType, accountID, err)
+		return
+	}
+	// the notification outlives the request that raised it
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	err = s.repo.Create(ctx, &db_models.Notification{
+		AccountID: accountID,
+		Kind:      eventType,
+		Title:     title,
+		Body:      body,
+		Data:      datatypes.JSON(raw),
+	})
+	if err != nil {
+		log.Printf("[notifications] dropping %s for %s: %v", eventType, accountID, err)
+	}
+}
+
+func (s *NotificationService) ListNotifications(ctx context.Context, userID string, query request_models.NotificationQuery) (*response_models.NotificationPage, error) {
+	accountID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, utils.ErrInvalidInput
+	}
+	rows, total, err := s.repo.List(ctx, accountID, query.Unread, query.Page, query.PageSize)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	unread := total
+	if !query.Unread {
+		if unread, err = s.repo.CountUnread(ctx, accountID); err != nil {
+			return nil, utils.ErrDatabaseError
+		}
+	}
+
+	out := &response_models.NotificationPage{
+		Items:    make([]response_models.Notification, 0, len(rows)),
+		Total:    total,
+		Unread:   unread,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	}
+	for i := range rows {
+		out.Items = append(out.Items, toNotificationResponse(&rows[i]))
+	}
+	return out, nil
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, userID, notificationID string) error {
+	accountID, err := uuid.Parse(userID)
+	if err != nil {
+		return utils.ErrInvalidInput
+	}
+	id, err := uuid.Parse(notificationID)
+	if err != nil {
+		return utils.ErrInvalidInput.WithMessage("Invalid notification ID")
+	}
+	found, err := s.repo.MarkRead(ctx, id, accountID, time.Now().Unix())
+	if err != nil {
+		return utils.ErrDatabaseError
+	}
+	if !found {
+		return utils.RecordNotFound.WithMessage("Notification not found")
+	}
+	return nil
+}
+
+func toNotificationResponse(n *db_models.Notification) response_models.Notification {
+	out := response_models.Notification{
+		ID:        n.ID.String(),
+		Kind:      n.Kind,
+		Title:     n.Title,
+		Body:      n.Body,
+		Data:      json.RawMessage(n.Data),
+		Read:      n.ReadAt != nil,
+		CreatedAt: utils.FormatRFC3339VN(utils.FromUnixSecondsVN(n.CreatedAt)),
+	}
+	if n.ReadAt != nil {
+		out.ReadAt = utils.FormatRFC3339VN(utils.FromUnixSecondsVN(*n.ReadAt))
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	dbm "vivu/internal/models/db_models"
+	"vivu/pkg/utils"
+)
+
+// Names the payment providers are configured, requested and stored (Transaction.Provider) under.
+const (
+	ProviderPayOS  = "payos"
+	ProviderStripe = "stripe"
+)
+
+// PaymentProvider is a payment gateway: it opens hosted checkouts, authenticates the webhooks
+// reporting their outcome and refunds what they collected.
+type PaymentProvider interface {
+	Name() string
+	// Supports tells whether the provider can charge in the currency (ISO 4217).
+	Supports(currency string) bool
+	CreateCheckout(ctx context.Context, req CheckoutRequest) (*CheckoutSession, error)
+	// VerifyWebhook authenticates a webhook and reads what it reports; an error means the
+	// request did not come from the provider.
+	VerifyWebhook(ctx context.Context, header http.Header, body []byte) (*PaymentWebhookEvent, error)
+	// Refund returns amountMinor of a paid transaction to the payer.
+	Refund(ctx context.Context, txn *dbm.Transaction, amountMinor int64, reason string) (*ProviderRefund, error)
+}
+
+// CheckoutRequest is one payment to collect through a hosted checkout.
+type CheckoutRequest struct {
+	TransactionID uuid.UUID // our transaction, echoed back by providers that support it
+	AmountMinor   int64
+	Currency      string
+	ItemName      string
+	Description   string
+}
+
+type CheckoutSession struct {
+	ProviderTxnID string // what the provider's webhooks identify the payment by
+	OrderCode     int64  // payOS order code; 0 for other providers
+	PaymentURL    string
+	Raw           any // the provider's response, kept on the transaction for traceability
+}
+
+// PaymentWebhookEvent is what a webhook reports about one payment.
+type PaymentWebhookEvent struct {
+	// Empty for events about no payment (payOS's URL confirmation, Stripe events we do not use):
+	// they are acknowledged and ignored
+	ProviderTxnID string
+	// EventID is the same on every delivery of one event, so redeliveries are applied once
+	EventID    string
+	Paid       bool
+	Failed     bool
+	Reason     string // why the payment failed
+	OrderCode  int64
+	PaymentRef string // provider reference refunds need (Stripe payment intent)
+}
+
+type ProviderRefund struct {
+	ProviderRefundID string
+	Status           string // as the provider reports it, e.g. succeeded or pending
+}
+
+// paymentProviders holds the configured providers and picks one for each checkout.
+type paymentProviders struct {
+	byName      map[string]PaymentProvider
+	order       []PaymentProvider
+	defaultName string
+}
+
+func newPaymentProviders(providers []PaymentProvider, defaultName string) *paymentProviders {
+	ps := &paymentProviders{byName: make(map[string]PaymentProvider, len(providers)), defaultName: defaultName}
+	for _, p := range providers {
+		ps.byName[p.Name()] = p
+		ps.order = append(ps.order, p)
+	}
+	if _, ok := ps.byName[defaultName]; !ok && len(ps.order) > 0 {
+		ps.defaultName = ps.order[0].Name()
+	}
+	return ps
+}
+
+func (ps *paymentProviders) get(name string) (PaymentProvider, bool) {
+	p, ok := ps.byName[name]
+	return p, ok
+}
+
+func (ps *paymentProviders) names() []string {
+	out := make([]string, 0, len(ps.order))
+	for _, p := range ps.order {
+		out = append(out, p.Name())
+	}
+	return out
+}
+
+// pick returns the requested provider, else the default when it takes the currency, else the
+// first configured one that does: international cards go to whichever gateway can take them.
+func (ps *paymentProviders) pick(requested, currency string) (PaymentProvider, error) {
+	if requested != "" {
+		p, ok := ps.byName[strings.ToLower(requested)]
+		if !ok {
+			return nil, utils.ErrInvalidInput.WithMessage("This payment provider is not available")
+		}
+		if !p.Supports(currency) {
+			return nil, utils.ErrInvalidInput.WithMessage("This payment provider does not take payments in " + strings.ToUpper(currency))
+		}
+		return p, nil
+	}
+	if p := ps.byName[ps.defaultName]; p != nil && p.Supports(currency) {
+		return p, nil
+	}
+	for _, p := range ps.order {
+		if p.Supports(currency) {
+			return p, nil
+		}
+	}
+	return nil, utils.ErrInvalidInput.WithMessage("No payment provider takes payments in " + strings.ToUpper(currency))
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/payOSHQ/payos-lib-golang"
+	dbm "vivu/internal/models/db_models"
+	"vivu/pkg/utils"
+)
+
+type PayOSConfig struct {
+	ClientID    string // e.g. P-xxxxx
+	ApiKey      string // public key if required by SDK
+	ChecksumKey string // secret used to sign webhooks (a.k.a. "client secret")
+	ReturnURL   string // e.g. https://yourapp.com/pay/return
+	CancelURL   string // e.g. https://yourapp.com/pay/cancel
+}
+
+// payOS's webhook URL confirmation sends this order code
+const payOSConfirmOrderCode = 123
+
+type payOSProvider struct {
+	cfg PayOSConfig
+}
+
+// NewPayOSProvider registers the credentials with the payOS SDK, which keeps them globally.
+func NewPayOSProvider(cfg PayOSConfig) (PaymentProvider, error) {
+	if cfg.ClientID == "" || cfg.ApiKey == "" || cfg.ChecksumKey == "" {
+		return nil, errors.New("missing payOS credentials")
+	}
+	if err := payos.Key(cfg.ClientID, cfg.ApiKey, cfg.ChecksumKey); err != nil {
+		return nil, fmt.Errorf("payos client init: %w", err)
+	}
+	return &payOSProvider{cfg: cfg}, nil
+}
+
+func (p *payOSProvider) Name() string { return ProviderPayOS }
+
+// Supports: payOS collects bank transfers in dong only.
+func (p *payOSProvider) Supports(currency string) bool { return strings.EqualFold(currency, "VND") }
+
+func (p *payOSProvider) CreateCheckout(ctx context.Context, req CheckoutRequest) (*CheckoutSession, error) {
+	// Generate a unique order code (payOS expects int64). Keep it within 13 digits.
+	// We combine unix seconds + short random to reduce collision probability.
+	orderCode := time.Now().Unix()%1_000_000_000 + int64(rand.Intn(9000)+1000)
+
+	body := payos.CheckoutRequestType{
+		OrderCode: orderCode,
+		Amount:    int(req.AmountMinor),
+		Items: []payos.Item{{
+			Name:     req.ItemName,
+			Price:    int(req.AmountMinor), // SDK Item.Price is int
+			Quantity: 1,
+		}},
+		Description: req.Description,
+		CancelUrl:   p.cfg.CancelURL,
+		ReturnUrl:   p.cfg.ReturnURL,
+	}
+	resp, err := payos.CreatePaymentLink(body)
+	if err != nil {
+		return nil, fmt.Errorf("payos create link: %w", err)
+	}
+	return &CheckoutSession{
+		ProviderTxnID: fmt.Sprintf("payos:%d", orderCode),
+		OrderCode:     orderCode,
+		PaymentURL:    resp.CheckoutUrl,
+		Raw:           resp,
+	}, nil
+}
+
+func (p *payOSProvider) VerifyWebhook(ctx context.Context, header http.Header, body []byte) (*PaymentWebhookEvent, error) {
+	var hook payos.WebhookType
+	if err := json.Unmarshal(body, &hook); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+	data, err := payos.VerifyPaymentWebhookData(hook)
+	if err != nil {
+		return nil, err
+	}
+	if data.OrderCode == payOSConfirmOrderCode {
+		return &PaymentWebhookEvent{}, nil
+	}
+
+	// payOS reports a failed payment with a code other than "00". Its webhooks carry no event
+	// ID; a redelivery repeats the order code and the code.
+	return &PaymentWebhookEvent{
+		ProviderTxnID: fmt.Sprintf("payos:%d", data.OrderCode),
+		EventID:       fmt.Sprintf("%d:%s", data.OrderCode, data.Code),
+		OrderCode:     data.OrderCode,
+		Paid:          data.Code == "00",
+		Failed:        data.Code != "00",
+		Reason:        data.Desc,
+		PaymentRef:    data.Reference,
+	}, nil
+}
+
+// Refund: payOS has no refund API; its transfers are returned by hand from the bank account.
+func (p *payOSProvider) Refund(ctx context.Context, txn *dbm.Transaction, amountMinor int64, reason string) (*ProviderRefund, error) {
+	return nil, utils.ErrRefundUnsupported
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	dbm "vivu/internal/models/db_models"
+	"vivu/pkg/secrets"
+	"vivu/pkg/utils"
+)
+
+const stripeAPIBase = "https://api.stripe.com/v1"
+
+// Webhooks signed longer ago than this are replays
+const stripeWebhookTolerance = 5 * time.Minute
+
+type StripeConfig struct {
+	SuccessURL string
+	CancelURL  string
+}
+
+type stripeProvider struct {
+	cfg  StripeConfig
+	keys secrets.Getter // STRIPE_SECRET_KEY and STRIPE_WEBHOOK_SECRET, read on every call for rotation
+	http *http.Client
+}
+
+func NewStripeProvider(cfg StripeConfig, keys secrets.Getter) (PaymentProvider, error) {
+	if keys.Get("STRIPE_SECRET_KEY") == "" || keys.Get("STRIPE_WEBHOOK_SECRET") == "" {
+		return nil, errors.New("missing Stripe credentials")
+	}
+	return &stripeProvider{cfg: cfg, keys: keys, http: utils.NewTracedHTTPClient(20 * time.Second)}, nil
+}
+
+func (s *stripeProvider) Name() string { return ProviderStripe }
+
+// Supports: Stripe charges cards in any currency; amounts are in the currency's minor unit,
+// as ours are (dong, cents).
+func (s *stripeProvider) Supports(currency string) bool { return len(currency) == 3 }
+
+// post calls the Stripe API with a form body and decodes the JSON answer into out.
+func (s *stripeProvider) post(ctx context.Context, path string, form url.Values, idempotencyKey string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, stripeAPIBase+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(s.keys.Get("STRIPE_SECRET_KEY"), "")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+	res, err := s.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if res.StatusCode >= 300 {
+		var e struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(body, &e)
+		return fmt.Errorf("stripe %s: status %d: %s", path, res.StatusCode, e.Error.Message)
+	}
+	return json.Unmarshal(body, out)
+}
+
+type stripeCheckoutSession struct {
+	ID            string            `json:"id"`
+	URL           string            `json:"url"`
+	PaymentStatus string            `json:"payment_status"` // paid | unpaid | no_payment_required
+	PaymentIntent string            `json:"payment_intent"`
+	Metadata      map[string]string `json:"metadata"`
+}
+
+func (s *stripeProvider) CreateCheckout(ctx context.Context, req CheckoutRequest) (*CheckoutSession, error) {
+	form := url.Values{}
+	form.Set("mode", "payment")
+	form.Set("success_url", s.cfg.SuccessURL)
+	form.Set("cancel_url", s.cfg.CancelURL)
+	form.Set("client_reference_id", req.TransactionID.String())
+	form.Set("metadata[transaction_id]", req.TransactionID.String())
+	form.Set("payment_intent_data[metadata][transaction_id]", req.TransactionID.String())
+	form.Set("line_items[0][quantity]", "1")
+	form.Set("line_items[0][price_data][currency]", strings.ToLower(req.Currency))
+	form.Set("line_items[0][price_data][unit_amount]", strconv.FormatInt(req.AmountMinor, 10))
+	form.Set("line_items[0][price_data][product_data][name]", req.ItemName)
+	if req.Description != "" {
+		form.Set("line_items[0][price_data][product_data][description]", req.Description)
+	}
+
+	var session stripeCheckoutSession
+	if err := s.post(ctx, "/checkout/sessions", form, "checkout:"+req.TransactionID.String(), &session); err != nil {
+		return nil, err
+	}
+	return &CheckoutSession{
+		ProviderTxnID: "stripe:" + session.ID,
+		PaymentURL:    session.URL,
+		Raw:           map[string]string{"id": session.ID, "url": session.URL},
+	}, nil
+}
+
+// verifySignature checks the Stripe-Signature header: t=<unix>,v1=<hex hmac of "t.body">,
+// where any v1 may match (several are sent while the secret rotates).
+func (s *stripeProvider) verifySignature(header string, body []byte, now time.Time) error {
+	var ts int64
+	var sigs []string
+	for _, part := range strings.Split(header, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "t":
+			ts, _ = strconv.ParseInt(v, 10, 64)
+		case "v1":
+			sigs = append(sigs, v)
+		}
+	}
+	if ts == 0 || len(sigs) == 0 {
+		return errors.New("malformed Stripe-Signature header")
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > stripeWebhookTolerance || age < -stripeWebhookTolerance {
+		return errors.New("webhook timestamp outside the tolerance")
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.keys.Get("STRIPE_WEBHOOK_SECRET")))
+	mac.Write([]byte(strconv.FormatInt(ts, 10) + "."))
+	mac.Write(body)
+	expected := mac.Sum(nil)
+	for _, sig := range sigs {
+		if got, err := hex.DecodeString(sig); err == nil && hmac.Equal(got, expected) {
+			return nil
+		}
+	}
+	return errors.New("webhook signature mismatch")
+}
+
+func (s *stripeProvider) VerifyWebhook(ctx context.Context, header http.Header, body []byte) (*PaymentWebhookEvent, error) {
+	if err := s.verifySignature(header.Get("Stripe-Signature"), body, time.Now()); err != nil {
+		return nil, err
+	}
+	var event struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Data struct {
+			Object stripeCheckoutSession `json:"object"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+
+	session := event.Data.Object
+	out := &PaymentWebhookEvent{ProviderTxnID: "stripe:" + session.ID, EventID: event.ID, PaymentRef: session.PaymentIntent}
+	switch event.Type {
+	case "checkout.session.completed":
+		// Delayed methods (bank debits) complete unpaid and report again when they settle
+		out.Paid = session.PaymentStatus == "paid"
+	case "checkout.session.async_payment_succeeded":
+		out.Paid = true
+	case "checkout.session.async_payment_failed":
+		out.Failed = true
+		out.Reason = "payment failed"
+	case "checkout.session.expired":
+		out.Failed = true
+		out.Reason = "checkout expired"
+	default:
+		return &PaymentWebhookEvent{}, nil
+	}
+	if !out.Paid && !out.Failed {
+		return &PaymentWebhookEvent{}, nil
+	}
+	return out, nil
+}
+
+func (s *stripeProvider) Refund(ctx context.Context, txn *dbm.Transaction, amountMinor int64, reason string) (*ProviderRefund, error) {
+	if txn.PaymentMethodRef == "" {
+		return nil, utils.ErrRefundUnsupported.WithMessage("This payment has no Stripe payment intent to refund")
+	}
+	form := url.Values{}
+	form.Set("payment_intent", txn.PaymentMethodRef)
+	form.Set("amount", strconv.FormatInt(amountMinor, 10))
+	form.Set("metadata[transaction_id]", txn.ID.String())
+	if reason != "" {
+		form.Set("metadata[reason]", reason)
+	}
+
+	var refund struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+	}
+	key := fmt.Sprintf("refund:%s:%d:%d", txn.ID, amountMinor, time.Now().Unix()/60)
+	if err := s.post(ctx, "/refunds", form, key, &refund); err != nil {
+		return nil, err
+	}
+	return &ProviderRefund{ProviderRefundID: refund.ID, Status: refund.Status}, nil
+}
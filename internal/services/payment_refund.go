@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	dbm "vivu/internal/models/db_models"
+	"vivu/internal/models/response_models"
+	"vivu/pkg/utils"
+)
+
+// RefundTransaction refunds a paid transaction through its provider, whole or amountMinor of
+// it, and takes back what it bought: a full refund cancels the subscription now, a partial one
+// takes the same share of its period off the end. The account gets a notification and an email.
+func (p *paymentService) RefundTransaction(ctx context.Context, transactionID uuid.UUID, amountMinor int64, reason, refundedBy string) (*response_models.RefundResponse, error) {
+	now := time.Now().Unix()
+
+	var txn dbm.Transaction
+	var sub *dbm.Subscription
+	var refund *ProviderRefund
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The lock is held across the provider call so two admins cannot refund twice
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", transactionID).First(&txn).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrTransactionNotFound
+		}
+		if err != nil {
+			return utils.ErrDatabaseError
+		}
+		if txn.Status != dbm.TxnStatusPaid {
+			return utils.ErrTransactionNotRefundable
+		}
+		if amountMinor == 0 {
+			amountMinor = txn.AmountMinor
+		}
+		if amountMinor > txn.AmountMinor {
+			return utils.ErrInvalidInput.WithMessage("The refund cannot be more than the payment")
+		}
+		provider, ok := p.providers.get(txn.Provider)
+		if !ok {
+			return utils.ErrRefundUnsupported
+		}
+
+		if txn.SubscriptionID != nil {
+			sub = &dbm.Subscription{}
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Preload("Plan", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+				Where("id = ?", *txn.SubscriptionID).First(sub).Error; err != nil {
+				return utils.ErrDatabaseError
+			}
+		}
+
+		refund, err = provider.Refund(ctx, &txn, amountMinor, reason)
+		if err != nil {
+			var appErr *utils.AppError
+			if errors.As(err, &appErr) {
+				return err
+			}
+			log.Printf("[payments] refund of %s through %s: %v", txn.ID, txn.Provider, err)
+			return utils.ErrPaymentsUnavailable.WithMessage("The payment provider did not take the refund; nothing was refunded").Wrap(err)
+		}
+
+		txn.Status = dbm.TxnStatusRefunded
+		txn.RefundedAt = &now
+		if err := tx.Model(&txn).Updates(map[string]any{
+			"status":      txn.Status,
+			"refunded_at": now,
+			"metadata": gorm.Expr("COALESCE(metadata, '{}'::jsonb) || ?::jsonb", string(jsonRaw(map[string]any{
+				"refund": map[string]any{
+					"amount_minor":       amountMinor,
+					"provider_refund_id": refund.ProviderRefundID,
+					"provider_status":    refund.Status,
+					"reason":             reason,
+					"refunded_by":        refundedBy,
+				},
+			}))),
+		}).Error; err != nil {
+			// The money went back already; the row has to be fixed by hand
+			log.Printf("[payments] refund %s of %s went through but was not recorded: %v", refund.ProviderRefundID, txn.ID, err)
+			return utils.ErrDatabaseError
+		}
+
+		if sub != nil {
+			takeBackSubscription(sub, amountMinor, txn.AmountMinor, now)
+			if err := tx.Model(sub).Updates(map[string]any{
+				"status":           sub.Status,
+				"ends_at":          sub.EndsAt,
+				"paused_remaining": sub.PausedRemaining,
+				"auto_renew":       sub.AutoRenew,
+				"canceled_at":      sub.CanceledAt,
+			}).Error; err != nil {
+				log.Printf("[payments] refund %s of %s went through but its subscription was not updated: %v", refund.ProviderRefundID, txn.ID, err)
+				return utils.ErrDatabaseError
+			}
+			return p.snapshotSubscription(tx, sub)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	event := PaymentRefundedEvent{
+		TransactionID: txn.ID.String(),
+		AmountMinor:   amountMinor,
+		Currency:      txn.Currency,
+		Reason:        reason,
+	}
+	out := &response_models.RefundResponse{
+		TransactionID:    txn.ID,
+		AmountMinor:      amountMinor,
+		Currency:         txn.Currency,
+		Provider:         txn.Provider,
+		ProviderRefundID: refund.ProviderRefundID,
+		ProviderStatus:   refund.Status,
+		RefundedAt:       now,
+	}
+	if sub != nil {
+		event.SubscriptionID = sub.ID.String()
+		event.SubscriptionStatus = string(sub.Status)
+		event.SubscriptionEndsAt = utils.FormatRFC3339VN(utils.FromUnixSecondsVN(sub.EndsAt))
+		out.Subscription = &response_models.RefundedSubscription{
+			ID:     sub.ID,
+			Status: string(sub.Status),
+			EndsAt: event.SubscriptionEndsAt,
+		}
+	}
+	p.events.Publish(ctx, txn.AccountID, EventPaymentRefunded, event)
+	p.sendRefundNotice(ctx, txn.AccountID, event)
+	return out, nil
+}
+
+// takeBackSubscription shortens sub by the refunded share of its period, never into the past
+// (or before it starts); what is left with no time is canceled. A paused subscription loses
+// the time from what it has left.
+func takeBackSubscription(sub *dbm.Subscription, refundedMinor, paidMinor, now int64) {
+	cut := sub.EndsAt - sub.StartsAt
+	if refundedMinor < paidMinor && paidMinor > 0 {
+		cut = cut * refundedMinor / paidMinor
+	}
+
+	if sub.Status == dbm.SubStatusPaused {
+		sub.PausedRemaining -= cut
+		if sub.PausedRemaining > 0 && refundedMinor < paidMinor {
+			return
+		}
+		sub.PausedRemaining = 0
+	} else {
+		floor := max(now, sub.StartsAt)
+		sub.EndsAt -= cut
+		if sub.EndsAt > floor && refundedMinor < paidMinor {
+			return
+		}
+		sub.EndsAt = floor
+	}
+	sub.Status = dbm.SubStatusCanceled
+	sub.AutoRenew = false
+	sub.CanceledAt = &now
+}
+
+// refundNotice is the sentence telling the account what was refunded and what became of its plan.
+func refundNotice(e PaymentRefundedEvent) string {
+	body := fmt.Sprintf("We refunded %s to your original payment method. It can take a few business days to show up.", formatMinor(e.AmountMinor, e.Currency))
+	switch {
+	case e.SubscriptionStatus == string(dbm.SubStatusCanceled):
+		body += " The subscription it paid for has ended."
+	case e.SubscriptionEndsAt != "":
+		ends := e.SubscriptionEndsAt
+		if t, err := time.Parse(time.RFC3339, ends); err == nil {
+			ends = t.In(vnLoc).Format("02/01/2006")
+		}
+		body += fmt.Sprintf(" Your subscription now ends on %s.", ends)
+	}
+	return body
+}
+
+// sendRefundNotice emails the account about the refund. The mail outbox retries it; failing to
+// queue it does not undo the refund.
+func (p *paymentService) sendRefundNotice(ctx context.Context, accountID uuid.UUID, e PaymentRefundedEvent) {
+	if p.mail == nil {
+		return
+	}
+	var account dbm.Account
+	if err := p.db.WithContext(ctx).Select("id", "email").First(&account, "id = ?", accountID).Error; err != nil {
+		log.Printf("[payments] account of refund %s: %v", e.TransactionID, err)
+		return
+	}
+	body := refundNotice(e)
+	if e.Reason != "" {
+		body += " Reason: " + e.Reason
+	}
+	if err := p.mail.SendMailToNotifyUser(account.Email, "Your Vivu payment was refunded", body, "See my billing", p.appURL+"/billing"); err != nil {
+		log.Printf("[payments] refund notice to %s: %v", account.Email, err)
+	}
+}
+
+// formatMinor renders an amount in the currency's minor unit: dong as "1.250.000 ₫", others
+// with two decimals.
+func formatMinor(amount int64, currency string) string {
+	if strings.EqualFold(currency, "VND") {
+		return formatVND(amount)
+	}
+	return fmt.Sprintf("%.2f %s", float64(amount)/100, strings.ToUpper(currency))
+}
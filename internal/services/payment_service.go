@@ -7,50 +7,94 @@ import (
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/payOSHQ/payos-lib-golang"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"io"
 	"log"
-	"math/rand"
 	"net/http"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	dbm "vivu/internal/models/db_models"
 	"vivu/internal/models/response_models"
+	"vivu/pkg/utils"
 )
 
-type PayOSConfig struct {
-	ClientID     string // e.g. P-xxxxx
-	ApiKey       string // public key if required by SDK
-	ChecksumKey  string // secret used to sign webhooks (a.k.a. "client secret")
-	BaseURL      string // optional: payOS API base if using sandbox
-	ReturnURL    string // e.g. https://yourapp.com/pay/return
-	CancelURL    string // e.g. https://yourapp.com/pay/cancel
-	AppBaseURL   string // for building deep links if needed
-	ProviderName string // "payos" (stored on Transaction.Provider)
-}
+// Plans a coupon made free are recorded as a zero-amount transaction of this provider
+const couponProvider = "coupon"
 
 type PaymentService interface {
-	CreateCheckoutForPlan(ctx context.Context, accountID uuid.UUID, planCode string) (*response_models.CreateCheckoutResponse, error)
-	HandleWebhook(c *gin.Context)
+	// CreateCheckoutForPlan opens a checkout for the plan, less the coupon when couponCode is
+	// set; a coupon that makes the plan free activates it without a checkout.
+	// provider picks the gateway; when empty the configured default takes the plan's currency
+	// if it can, else the first provider that can.
+	CreateCheckoutForPlan(ctx context.Context, accountID uuid.UUID, planCode, couponCode, provider string) (*response_models.CreateCheckoutResponse, error)
+	// HandleWebhook settles the payment a webhook of the named provider reports on.
+	HandleWebhook(c *gin.Context, provider string)
 	GetListOfPlans(ctx context.Context) ([]response_models.SubscriptionPlan, error)
 	GetStatusOfSubscription(ctx context.Context, accountID uuid.UUID) (*response_models.SubscriptionStatusResponse, error)
 	GetAllTransactions(ctx context.Context) ([]response_models.TransactionResponse, error)
+
+	// NotifyExpiringSubscriptions publishes subscription.expiring once for every active
+	// subscription ending within the notice window that no later subscription follows.
+	NotifyExpiringSubscriptions(ctx context.Context) (int, error)
+
+	// PauseSubscription freezes the days left on the running subscription, once per period;
+	// ResumeSubscription runs them again from now. Pauses end on their own after the maximum pause.
+	PauseSubscription(ctx context.Context, accountID uuid.UUID) (*response_models.SubscriptionStatusResponse, error)
+	ResumeSubscription(ctx context.Context, accountID uuid.UUID) (*response_models.SubscriptionStatusResponse, error)
+	ResumeOverduePauses(ctx context.Context) (int, error)
+
+	// CancelSubscription turns renewal off and emails a confirmation; the plan keeps running
+	// until the end of the paid period. SetAutoRenew turns it off or back on the same way.
+	CancelSubscription(ctx context.Context, accountID uuid.UUID) (*response_models.SubscriptionStatusResponse, error)
+	SetAutoRenew(ctx context.Context, accountID uuid.UUID, enabled bool) (*response_models.SubscriptionStatusResponse, error)
+	CloseCanceledSubscriptions(ctx context.Context) (int, error)
+
+	// RenewSubscriptions opens and emails renewal checkouts for auto-renewing subscriptions
+	// near their end, retries while they stay unpaid and downgrades them after the grace period.
+	RenewSubscriptions(ctx context.Context) (int, error)
+
+	// ChangePlan moves the running subscription to another plan now, crediting its unused
+	// part: the rest of the price goes through a checkout, leftover credit becomes extra days.
+	ChangePlan(ctx context.Context, accountID uuid.UUID, planCode string) (*response_models.PlanChangeResponse, error)
+
+	// RefundTransaction refunds a paid transaction, whole when amountMinor is 0, and cancels or
+	// shortens the subscription it bought.
+	RefundTransaction(ctx context.Context, transactionID uuid.UUID, amountMinor int64, reason, refundedBy string) (*response_models.RefundResponse, error)
+
+	Start()
+	Stop()
 }
 
 type paymentService struct {
-	db  *gorm.DB
-	cfg PayOSConfig
-	loc *time.Location
+	db        *gorm.DB
+	providers *paymentProviders
+	loc       *time.Location
+	events    EventBus
+	mail      IMailService
+	receipts  ReceiptMailer // nil sends no receipts
+	appURL    string
+
+	expiryNotice  time.Duration // how long before the end subscription.expiring goes out
+	checkInterval time.Duration
+	maxPause      time.Duration
+
+	renewalLead     time.Duration // how long before the end the first renewal checkout goes out
+	dunningGrace    time.Duration // how long after the end an unpaid subscription keeps the plan
+	renewalInterval time.Duration
+
+	stopOnce sync.Once
+	stop     chan struct{}
 }
 
 func (p *paymentService) GetAllTransactions(ctx context.Context) ([]response_models.TransactionResponse, error) {
 
 	var transactions []dbm.Transaction
 	if err := p.db.WithContext(ctx).
-		Where("provider = ?", p.cfg.ProviderName).
+		Where("provider IN ?", p.providers.names()).
 		Order("created_at DESC").
 		Find(&transactions).Error; err != nil {
 		return nil, err
@@ -82,7 +126,7 @@ func (p *paymentService) GetStatusOfSubscription(ctx context.Context, accountID
 	var sub dbm.Subscription
 	err := p.db.WithContext(ctx).
 		Where("account_id = ? AND status IN ?", accountID,
-			[]dbm.SubscriptionStatus{dbm.SubStatusActive, dbm.SubStatusTrialing, dbm.SubStatusPastDue}).
+			[]dbm.SubscriptionStatus{dbm.SubStatusActive, dbm.SubStatusTrialing, dbm.SubStatusPastDue, dbm.SubStatusPaused}).
 		Order("ends_at DESC").
 		First(&sub).Error
 	if err != nil {
@@ -104,6 +148,14 @@ func (p *paymentService) GetStatusOfSubscription(ctx context.Context, accountID
 		EndsAt:    sub.EndsAt,
 		AutoRenew: sub.AutoRenew,
 	}
+	if sub.CanceledAt != nil {
+		resp.CanceledAt = *sub.CanceledAt
+	}
+	if sub.Status == dbm.SubStatusPaused && sub.PausedAt != nil {
+		resp.PausedAt = *sub.PausedAt
+		resp.RemainingDays = int(sub.PausedRemaining / 86400)
+		resp.ResumesAt = *sub.PausedAt + int64(p.maxPause.Seconds())
+	}
 
 	return resp, nil
 }
@@ -138,7 +190,7 @@ func (p *paymentService) GetListOfPlans(ctx context.Context) ([]response_models.
 	return result, nil
 }
 
-func (p *paymentService) CreateCheckoutForPlan(ctx context.Context, accountID uuid.UUID, planCode string) (*response_models.CreateCheckoutResponse, error) {
+func (p *paymentService) CreateCheckoutForPlan(ctx context.Context, accountID uuid.UUID, planCode, couponCode, provider string) (*response_models.CreateCheckoutResponse, error) {
 	var plan dbm.Plan
 	if err := p.db.WithContext(ctx).
 		Where("code = ? AND is_active = TRUE", planCode).
@@ -154,90 +206,153 @@ func (p *paymentService) CreateCheckoutForPlan(ctx context.Context, accountID uu
 	if amount <= 0 {
 		return nil, fmt.Errorf("plan %s is not billable (amount=%d)", planCode, amount)
 	}
+	gateway, err := p.providers.pick(provider, plan.Currency)
+	if err != nil {
+		return nil, err
+	}
 
-	// Generate a unique order code (payOS expects int64). Keep it within 13 digits.
-	// We combine unix seconds + short random to reduce collision probability.
-	rand.Seed(time.Now().UnixNano())
-	orderCode := time.Now().Unix()%1_000_000_000 + int64(rand.Intn(9000)+1000)
+	var meta map[string]any
+	if couponCode != "" {
+		var coupon dbm.Coupon
+		err := p.db.WithContext(ctx).Where("code = ?", normalizeCouponCode(couponCode)).First(&coupon).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrCouponInvalid.WithMessage("This coupon does not exist")
+		}
+		if err != nil {
+			return nil, utils.ErrDatabaseError
+		}
+		if err := couponUsable(&coupon, time.Now().Unix()); err != nil {
+			return nil, err
+		}
+		off, err := couponDiscount(&coupon, &plan)
+		if err != nil {
+			return nil, err
+		}
+		amount -= off
+		meta = map[string]any{
+			"coupon_id":      coupon.ID,
+			"coupon_code":    coupon.Code,
+			"discount_minor": off,
+			"price_minor":    plan.PriceMinor,
+		}
+		if amount == 0 {
+			return p.activateFree(ctx, accountID, &plan, coupon.ID, meta)
+		}
+	}
+
+	return p.createPaymentLink(ctx, gateway, accountID, &plan, amount, fmt.Sprintf("Subscription %s", plan.Code), meta)
+}
 
-	// Create a pending Transaction first (idempotency control via unique ProviderTxnID or OrderCode mapping)
+// activateFree records a paid zero-amount transaction for a plan a coupon fully paid for and
+// activates the plan as its webhook would. Nothing is paid, so the redemption is claimed here
+// against the limit instead of being counted after the fact.
+func (p *paymentService) activateFree(ctx context.Context, accountID uuid.UUID, plan *dbm.Plan, couponID uuid.UUID, meta map[string]any) (*response_models.CreateCheckoutResponse, error) {
+	now := time.Now().Unix()
+	meta["plan_id"] = plan.ID
+	meta["plan_code"] = plan.Code
+	txn := dbm.Transaction{
+		AccountID:     accountID,
+		Currency:      strings.ToUpper(plan.Currency),
+		Status:        dbm.TxnStatusPaid,
+		Provider:      couponProvider,
+		ProviderTxnID: fmt.Sprintf("coupon:%s:%d", meta["coupon_code"], time.Now().UnixNano()),
+		PaidAt:        &now,
+		Metadata:      jsonRaw(meta),
+	}
+	var sub *dbm.Subscription
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Concurrent checkouts may all have seen the last free slot; only one gets it
+		res := tx.Model(&dbm.Coupon{}).
+			Where("id = ? AND (max_redemptions IS NULL OR redemptions < max_redemptions)", couponID).
+			Update("redemptions", gorm.Expr("redemptions + 1"))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return utils.ErrCouponInvalid.WithMessage("This coupon has been fully redeemed")
+		}
+		if err := tx.Create(&txn).Error; err != nil {
+			return err
+		}
+		var err error
+		sub, err = p.activateSubscription(tx, &txn)
+		return err
+	})
+	if err != nil {
+		var appErr *utils.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+
+	p.events.Publish(ctx, accountID, EventSubscriptionActivated, subscriptionEvent(sub))
+	return &response_models.CreateCheckoutResponse{ProviderName: couponProvider}, nil
+}
+
+// createPaymentLink records a pending transaction for amount and opens a checkout for it with the
+// provider. meta is stored on the transaction next to the plan, for activateSubscription to read back.
+func (p *paymentService) createPaymentLink(ctx context.Context, provider PaymentProvider, accountID uuid.UUID, plan *dbm.Plan, amount int64, description string, meta map[string]any) (*response_models.CreateCheckoutResponse, error) {
+	// Create a pending Transaction first; its provider reference is known once the checkout is open
 	txn := &dbm.Transaction{
+		BaseModel:        dbm.BaseModel{ID: uuid.New()},
 		AccountID:        accountID,
 		AmountMinor:      amount,
 		Currency:         strings.ToUpper(plan.Currency),
 		Status:           dbm.TxnStatusPending,
-		Provider:         p.cfg.ProviderName,
-		ProviderTxnID:    fmt.Sprintf("payos:%d", orderCode), // link local record <-> provider order
+		Provider:         provider.Name(),
 		PaymentMethodRef: "",
 	}
+	txn.ProviderTxnID = provider.Name() + ":pending:" + txn.ID.String()
 
 	if err := p.db.WithContext(ctx).Create(txn).Error; err != nil {
 		return nil, fmt.Errorf("create transaction: %w", err)
 	}
 
-	// Build payOS items
-	item := payos.Item{
-		Name:     fmt.Sprintf("%s (%s)", plan.Name, plan.Code),
-		Price:    int(amount), // SDK Item.Price is int
-		Quantity: 1,
-	}
-
-	// Create checkout request
-	body := payos.CheckoutRequestType{
-		OrderCode:   orderCode,
-		Amount:      int(amount),
-		Items:       []payos.Item{item},
-		Description: fmt.Sprintf("Subscription %s", plan.Code),
-		CancelUrl:   p.cfg.CancelURL,
-		ReturnUrl:   p.cfg.ReturnURL,
-		// Optional: BuyerInfo, ExpireAt, etc.
-	}
-
-	clientErr := payos.Key(p.cfg.ClientID, p.cfg.ApiKey, p.cfg.ChecksumKey)
-
-	if clientErr != nil {
-		return nil, fmt.Errorf("payos client init: %w", clientErr)
-	}
-
-	resp, err := payos.CreatePaymentLink(body)
+	session, err := provider.CreateCheckout(ctx, CheckoutRequest{
+		TransactionID: txn.ID,
+		AmountMinor:   amount,
+		Currency:      txn.Currency,
+		ItemName:      fmt.Sprintf("%s (%s)", plan.Name, plan.Code),
+		Description:   description,
+	})
 	if err != nil {
 		_ = p.db.WithContext(ctx).Model(txn).
 			Updates(map[string]interface{}{"status": dbm.TxnStatusFailed})
-		return nil, fmt.Errorf("payos create link: %w", err)
+		return nil, err
 	}
 
 	// Store provider payload snapshot for traceability
-	meta := map[string]any{
-		"payos_link": resp,
-		"plan_id":    plan.ID,
-		"plan_code":  plan.Code,
+	if meta == nil {
+		meta = map[string]any{}
 	}
+	meta[provider.Name()+"_link"] = session.Raw
+	meta["plan_id"] = plan.ID
+	meta["plan_code"] = plan.Code
 
-	if bytes, _ := json.Marshal(meta); bytes != nil {
-		_ = p.db.WithContext(ctx).Model(txn).Update("metadata", bytes).Error
+	// The link is usable anyway; a webhook that cannot find the transaction is logged
+	if err := p.db.WithContext(ctx).Model(txn).Updates(map[string]any{
+		"provider_txn_id": session.ProviderTxnID,
+		"metadata":        jsonRaw(meta),
+	}).Error; err != nil {
+		log.Printf("[payments] transaction %s: %v", txn.ID, err)
 	}
 
 	return &response_models.CreateCheckoutResponse{
-		OrderCode:    orderCode,
+		OrderCode:    session.OrderCode,
 		Amount:       amount,
-		PaymentURL:   resp.CheckoutUrl, // field name per SDK
-		ProviderName: p.cfg.ProviderName,
+		PaymentURL:   session.PaymentURL,
+		ProviderName: provider.Name(),
 	}, nil
 }
 
-func (p *paymentService) HandleWebhook(c *gin.Context) {
-
-	// 3) Parse minimal fields we need (adjust to actual payOS webhook schema)
-	if err := payos.Key(os.Getenv("PAYOS_CLIENT_ID"),
-		os.Getenv("PAYOS_API_KEY"),
-		os.Getenv("PAYOS_CHECKSUM_KEY")); err != nil {
-
-		log.Printf("key: %s", os.Getenv("PAYOS_CLIENT_ID"))
-		log.Printf("key: %s", os.Getenv("PAYOS_API_KEY"))
-		log.Printf("key: %s", os.Getenv("PAYOS_CHECKSUM_KEY"))
-
-		log.Panicf("Error setting payos key: %v", err)
-
+func (p *paymentService) HandleWebhook(c *gin.Context, providerName string) {
+	provider, ok := p.providers.get(providerName)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Unknown payment provider",
+		})
+		return
 	}
 
 	rawBody, err := io.ReadAll(c.Request.Body)
@@ -249,86 +364,258 @@ func (p *paymentService) HandleWebhook(c *gin.Context) {
 		return
 	}
 
-	var body payos.WebhookType
-	if err := json.Unmarshal(rawBody, &body); err != nil {
-		log.Printf("Error parsing webhook data: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid webhook payload",
-		})
-		return
-	}
-
-	data, payosErr := payos.VerifyPaymentWebhookData(body)
-
-	if payosErr != nil {
-		log.Printf("Error verifying webhook data: %v", payosErr)
+	event, err := provider.VerifyWebhook(c.Request.Context(), c.Request.Header, rawBody)
+	if err != nil {
+		log.Printf("[payments] webhook rejected (provider=%s, err=%v)", providerName, err)
 		c.JSON(http.StatusUnprocessableEntity, gin.H{
 			"error": "Failed to verify webhook data",
 		})
 		return
 	}
 
-	if data.OrderCode == 123 {
+	if event.ProviderTxnID == "" {
 		c.JSON(http.StatusOK, gin.H{
-			"message": "COnfirm webhook complete",
+			"message": "Webhook acknowledged",
 		})
 		return
 	}
+	if event.EventID == "" {
+		event.EventID = event.ProviderTxnID
+	}
 
-	orderCode := data.OrderCode
-	providerTxn := fmt.Sprintf("payos:%d", orderCode)
+	result, err := p.processWebhookEvent(c.Request.Context(), providerName, event)
+	if err != nil {
+		log.Printf("[payments] webhook failed (provider=%s, event=%s, txn=%s, err=%v)", providerName, event.EventID, event.ProviderTxnID, err)
+		p.recordWebhookFailure(c.Request.Context(), providerName, event, err)
+		// A 5xx makes the provider deliver the event again
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to process transaction",
+		})
+		return
+	}
 
-	// 4) Load the pending transaction
-	var txn dbm.Transaction
-	if err := p.db.
-		Where("provider_txn_id = ?", providerTxn).
-		First(&txn).Error; err != nil {
-		// If not found, ack 200 to avoid retries storm, but log for investigation.
-		log.Printf("webhook: transaction not found for order %d", orderCode)
+	switch result.status {
+	case webhookDuplicate:
+		log.Printf("[payments] webhook duplicate (provider=%s, event=%s, txn=%s, first_processed_at=%d)", providerName, event.EventID, event.ProviderTxnID, result.processedAt)
+	case dbm.WebhookEventIgnored:
+		// Acknowledged so the provider does not retry a payment we know nothing about
+		log.Printf("[payments] webhook ignored (provider=%s, event=%s, txn=%s, reason=%s)", providerName, event.EventID, event.ProviderTxnID, result.reason)
+	default:
+		log.Printf("[payments] webhook processed (provider=%s, event=%s, txn=%s, paid=%t, failed=%t)", providerName, event.EventID, event.ProviderTxnID, event.Paid, event.Failed)
+	}
 
-		return
+	// Published once the changes are committed, so a redelivery never repeats them
+	if result.failed != nil {
+		p.events.Publish(c.Request.Context(), result.failed.AccountID, EventPaymentFailed, PaymentFailedEvent{
+			TransactionID: result.failed.ID.String(),
+			OrderCode:     event.OrderCode,
+			AmountMinor:   result.failed.AmountMinor,
+			Currency:      result.failed.Currency,
+			Reason:        event.Reason,
+		})
+	}
+	if result.activated != nil {
+		p.events.Publish(c.Request.Context(), result.activated.AccountID, EventSubscriptionActivated, subscriptionEvent(result.activated))
+	}
+	if result.paid != nil && p.receipts != nil {
+		// The payment stands whether or not its receipt goes out; it can be downloaded later
+		if err := p.receipts.SendReceipt(c.Request.Context(), result.paid.AccountID, result.paid.ID.String()); err != nil {
+			log.Printf("[payments] receipt of %s: %v", result.paid.ID, err)
+		}
 	}
 
-	// Idempotency: update only if currently pending/failed
-	if txn.Status != dbm.TxnStatusPaid {
-		now := time.Now().Unix()
-		err = p.db.Transaction(func(tx *gorm.DB) error {
-			if err := tx.Model(&txn).Updates(map[string]interface{}{
-				"status":  dbm.TxnStatusPaid,
-				"paid_at": now,
-			}).Error; err != nil {
-				return err
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Webhook processed",
+		"status":  result.status,
+	})
+}
+
+// Reported for an event whose row was already processed; not a stored status
+const webhookDuplicate = "duplicate"
+
+type webhookResult struct {
+	status      string
+	reason      string // why it was ignored
+	processedAt int64  // when a duplicate was first processed
+	failed      *dbm.Transaction
+	paid        *dbm.Transaction
+	activated   *dbm.Subscription
+}
+
+// processWebhookEvent applies a webhook event once. The event row and the transaction are
+// locked and updated in one database transaction: a redelivery waits for the first delivery
+// and then finds the event processed; an error rolls everything back for the retry.
+func (p *paymentService) processWebhookEvent(ctx context.Context, providerName string, event *PaymentWebhookEvent) (*webhookResult, error) {
+	outcome := ""
+	switch {
+	case event.Paid:
+		outcome = string(dbm.TxnStatusPaid)
+	case event.Failed:
+		outcome = string(dbm.TxnStatusFailed)
+	}
+
+	result := &webhookResult{}
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&dbm.WebhookEvent{
+			Provider:      providerName,
+			EventID:       event.EventID,
+			ProviderTxnID: event.ProviderTxnID,
+			Outcome:       outcome,
+			Status:        dbm.WebhookEventReceived,
+		}).Error; err != nil {
+			return err
+		}
+		var row dbm.WebhookEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("provider = ? AND event_id = ?", providerName, event.EventID).
+			First(&row).Error; err != nil {
+			return err
+		}
+		if row.Status == dbm.WebhookEventProcessed || row.Status == dbm.WebhookEventIgnored {
+			result.status = webhookDuplicate
+			if row.ProcessedAt != nil {
+				result.processedAt = *row.ProcessedAt
 			}
-			// Activate/Create subscription
-			return p.activateSubscription(tx, &txn)
-		})
+			return nil
+		}
+
+		result.status = dbm.WebhookEventProcessed
+		if err := p.applyWebhookEvent(tx, providerName, event, result); err != nil {
+			return err
+		}
+		now := time.Now().Unix()
+		return tx.Model(&row).Updates(map[string]any{
+			"status":       result.status,
+			"attempts":     gorm.Expr("attempts + 1"),
+			"last_error":   result.reason,
+			"processed_at": now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// applyWebhookEvent settles the transaction the event reports on.
+func (p *paymentService) applyWebhookEvent(tx *gorm.DB, providerName string, event *PaymentWebhookEvent, result *webhookResult) error {
+	var txn dbm.Transaction
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("provider = ? AND provider_txn_id = ?", providerName, event.ProviderTxnID).
+		First(&txn).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		result.status, result.reason = dbm.WebhookEventIgnored, "transaction not found"
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case event.Failed:
+		if txn.Status != dbm.TxnStatusPending {
+			result.status, result.reason = dbm.WebhookEventIgnored, "transaction is "+string(txn.Status)
+			return nil
+		}
+		if err := tx.Model(&txn).Update("status", dbm.TxnStatusFailed).Error; err != nil {
+			return err
+		}
+		if err := p.failPlanChange(tx, &txn); err != nil {
+			return err
+		}
+		if err := p.failRenewal(tx, &txn); err != nil {
+			return err
+		}
+		result.failed = &txn
+
+	case event.Paid:
+		// A payment that failed first may still go through (e.g. a retried bank transfer)
+		if txn.Status == dbm.TxnStatusPaid || txn.Status == dbm.TxnStatusRefunded {
+			result.status, result.reason = dbm.WebhookEventIgnored, "transaction is "+string(txn.Status)
+			return nil
+		}
+		updates := map[string]interface{}{
+			"status":  dbm.TxnStatusPaid,
+			"paid_at": time.Now().Unix(),
+		}
+		if event.PaymentRef != "" {
+			updates["payment_method_ref"] = event.PaymentRef
+		}
+		if err := tx.Model(&txn).Updates(updates).Error; err != nil {
+			return err
+		}
+		sub, err := p.activateSubscription(tx, &txn)
 		if err != nil {
-			log.Printf("webhook: failed to update txn/subscription for order %d: %v", orderCode, err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to process transaction",
-			})
-			return
+			return err
 		}
+		result.paid = &txn
+		result.activated = sub
+	}
+	return nil
+}
 
+// recordWebhookFailure keeps the error of a failed delivery on the event row; the processing
+// itself was rolled back.
+func (p *paymentService) recordWebhookFailure(ctx context.Context, providerName string, event *PaymentWebhookEvent, cause error) {
+	row := dbm.WebhookEvent{
+		Provider:      providerName,
+		EventID:       event.EventID,
+		ProviderTxnID: event.ProviderTxnID,
+		Status:        dbm.WebhookEventFailed,
+		Attempts:      1,
+		LastError:     cause.Error(),
+	}
+	err := p.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "provider"}, {Name: "event_id"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "status"}, Value: dbm.WebhookEventFailed},
+			{Column: clause.Column{Name: "attempts"}, Value: gorm.Expr("webhook_events.attempts + 1")},
+			{Column: clause.Column{Name: "last_error"}, Value: cause.Error()},
+		},
+	}).Create(&row).Error
+	if err != nil {
+		log.Printf("[payments] webhook failure not recorded (provider=%s, event=%s, err=%v)", providerName, event.EventID, err)
 	}
 }
 
 func (p *paymentService) activateSubscription(tx *gorm.DB,
-	txn *dbm.Transaction) error {
+	txn *dbm.Transaction) (*dbm.Subscription, error) {
 	// Extract plan_code from txn.metadata (or store PlanID/PlanCode on Transaction explicitly)
 	type meta struct {
-		PlanID   uuid.UUID `json:"plan_id"`
-		PlanCode string    `json:"plan_code"`
+		PlanID       uuid.UUID `json:"plan_id"`
+		PlanCode     string    `json:"plan_code"`
+		PlanChangeID uuid.UUID `json:"plan_change_id"`
+		CouponID     uuid.UUID `json:"coupon_id"`
+		RenewalOf    uuid.UUID `json:"renewal_of"`
 	}
 	var m meta
 	if err := json.Unmarshal(txn.Metadata, &m); err != nil || m.PlanCode == "" {
 		// Fallback: resolve by amount/currency if pricing unique; safer to require plan_code in metadata
-		return fmt.Errorf("missing plan info in transaction metadata")
+		return nil, fmt.Errorf("missing plan info in transaction metadata")
 	}
 
 	var plan dbm.Plan
-	if err := tx.Where("id = ? AND is_active = TRUE", m.PlanID).First(&plan).Error; err != nil {
-		return fmt.Errorf("plan not found while activating: %w", err)
+	// The payment was made for this plan, even if it was retired or replaced since checkout
+	if err := tx.Unscoped().Where("id = ?", m.PlanID).First(&plan).Error; err != nil {
+		return nil, fmt.Errorf("plan not found while activating: %w", err)
+	}
+
+	// The coupon counts as redeemed once a payment with it goes through, even past its limit:
+	// the money is taken by then. Free activations claimed theirs already (see activateFree)
+	if m.CouponID != uuid.Nil && txn.Provider != couponProvider {
+		if err := tx.Model(&dbm.Coupon{}).Where("id = ?", m.CouponID).
+			Update("redemptions", gorm.Expr("redemptions + 1")).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	// A prorated plan change replaces the running subscription instead of queueing after it
+	if m.PlanChangeID != uuid.Nil {
+		var change dbm.SubscriptionPlanChange
+		if err := tx.Where("id = ?", m.PlanChangeID).First(&change).Error; err != nil {
+			return nil, fmt.Errorf("plan change not found while activating: %w", err)
+		}
+		return p.applyPlanChange(tx, &change, txn, &plan)
 	}
 
 	// Determine new period
@@ -344,18 +631,13 @@ func (p *paymentService) activateSubscription(tx *gorm.DB,
 		Order("ends_at DESC").
 		First(&current).Error
 
-	if err == nil && current.Status == dbm.SubStatusActive && current.AutoRenew && current.EndsAt > now.Unix() {
+	// A renewal that failed once leaves the subscription past due with its period still running
+	if err == nil && (current.Status == dbm.SubStatusActive || current.Status == dbm.SubStatusPastDue) &&
+		current.AutoRenew && current.EndsAt > now.Unix() {
 		starts = time.Unix(current.EndsAt, 0).In(p.loc) // extend from end
 	}
 
-	// Compute end
-	var ends time.Time
-	switch plan.Period {
-	case dbm.PeriodYear:
-		ends = starts.AddDate(1, 0, 0)
-	default:
-		ends = starts.AddDate(0, 1, 0)
-	}
+	ends := periodEnd(starts, plan.Period)
 
 	// If plan has TrialDays and the account is new/no active sub, apply trial before paid window if desired.
 	// In paid flow, many systems set trial before charging; here we bought already, so we just grant period.
@@ -370,8 +652,8 @@ func (p *paymentService) activateSubscription(tx *gorm.DB,
 		EndsAt:    endsAt,
 		AutoRenew: true,
 
-		Provider:           p.cfg.ProviderName,
-		ProviderCustomerID: "",                                           // payOS may not have customer concept; leave blank
+		Provider:           txn.Provider,
+		ProviderCustomerID: "",                                           // checkouts are one-off payments, no customer kept
 		ProviderSubID:      strconv.FormatInt(time.Now().UnixNano(), 10), // unique placeholder
 
 		Metadata: jsonRaw(map[string]any{
@@ -382,14 +664,130 @@ func (p *paymentService) activateSubscription(tx *gorm.DB,
 	}
 
 	if err := tx.Create(&sub).Error; err != nil {
-		return err
+		return nil, err
+	}
+	if err := tx.Model(txn).Update("subscription_id", sub.ID).Error; err != nil {
+		return nil, err
+	}
+	// A paid renewal ends the past-due period it renews; one still running ends with its period
+	if m.RenewalOf != uuid.Nil {
+		if err := tx.Model(&dbm.Subscription{}).
+			Where("id = ? AND status = ? AND ends_at <= ?", m.RenewalOf, dbm.SubStatusPastDue, now.Unix()).
+			Updates(map[string]any{"status": dbm.SubStatusExpired, "next_renewal_at": nil}).Error; err != nil {
+			return nil, err
+		}
 	}
 
 	// Optional: snapshot subscription on Account
 	_ = tx.Model(&dbm.Account{BaseModel: dbm.BaseModel{ID: txn.AccountID}}).
 		Update("subscription_snapshot", jsonRaw(sub)).Error
 
-	return nil
+	sub.Plan = plan
+	return &sub, nil
+}
+
+func subscriptionEvent(sub *dbm.Subscription) SubscriptionEvent {
+	return SubscriptionEvent{
+		SubscriptionID: sub.ID.String(),
+		PlanCode:       sub.Plan.Code,
+		StartsAt:       utils.FormatRFC3339VN(utils.FromUnixSecondsVN(sub.StartsAt)),
+		EndsAt:         utils.FormatRFC3339VN(utils.FromUnixSecondsVN(sub.EndsAt)),
+	}
+}
+
+func (p *paymentService) NotifyExpiringSubscriptions(ctx context.Context) (int, error) {
+	now := time.Now().Unix()
+
+	// Marking and reading in one statement lets every instance run the check
+	var subs []dbm.Subscription
+	err := p.db.WithContext(ctx).Raw(`
+UPDATE subscriptions s SET expiry_notice_at = ?
+WHERE s.status = ? AND s.deleted_at IS NULL AND s.expiry_notice_at IS NULL
+	AND s.ends_at > ? AND s.ends_at <= ?
+	AND NOT EXISTS (SELECT 1 FROM subscriptions n
+		WHERE n.account_id = s.account_id AND n.id <> s.id AND n.deleted_at IS NULL
+			AND n.status IN ? AND n.ends_at > s.ends_at)
+RETURNING s.*`,
+		now, dbm.SubStatusActive, now, now+int64(p.expiryNotice.Seconds()),
+		[]dbm.SubscriptionStatus{dbm.SubStatusActive, dbm.SubStatusTrialing}).
+		Scan(&subs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range subs {
+		sub := &subs[i]
+		if err := p.db.WithContext(ctx).Select("id", "code").First(&sub.Plan, "id = ?", sub.PlanID).Error; err != nil {
+			log.Printf("[subscriptions] plan of %s: %v", sub.ID, err)
+		}
+		p.events.Publish(ctx, sub.AccountID, EventSubscriptionExpiring, subscriptionEvent(sub))
+	}
+	return len(subs), nil
+}
+
+func (p *paymentService) Start() {
+	go func() {
+		ticker := time.NewTicker(p.checkInterval)
+		defer ticker.Stop()
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			n, err := p.NotifyExpiringSubscriptions(ctx)
+			cancel()
+			if err != nil {
+				log.Printf("[subscriptions] expiry check failed: %v", err)
+			} else if n > 0 {
+				log.Printf("[subscriptions] %d subscriptions expiring soon", n)
+			}
+			ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+			n, err = p.ResumeOverduePauses(ctx)
+			cancel()
+			if err != nil {
+				log.Printf("[subscriptions] resuming overdue pauses failed: %v", err)
+			} else if n > 0 {
+				log.Printf("[subscriptions] resumed %d subscriptions at the end of their maximum pause", n)
+			}
+			ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+			n, err = p.CloseCanceledSubscriptions(ctx)
+			cancel()
+			if err != nil {
+				log.Printf("[subscriptions] closing canceled subscriptions failed: %v", err)
+			} else if n > 0 {
+				log.Printf("[subscriptions] closed %d canceled subscriptions at the end of their period", n)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(p.renewalInterval)
+		defer ticker.Stop()
+		for {
+			// Provider calls and mail for every due subscription take longer than the checks above
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+			n, err := p.RenewSubscriptions(ctx)
+			cancel()
+			if err != nil {
+				log.Printf("[subscriptions] renewal run failed: %v", err)
+			} else if n > 0 {
+				log.Printf("[subscriptions] took a renewal step for %d subscriptions", n)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (p *paymentService) Stop() {
+	p.stopOnce.Do(func() { close(p.stop) })
 }
 
 func jsonRaw(v any) []byte {
@@ -397,9 +795,16 @@ func jsonRaw(v any) []byte {
 	return b
 }
 
-func NewPaymentService(db *gorm.DB, cfg PayOSConfig) (PaymentService, error) {
-	if cfg.ClientID == "" || cfg.ApiKey == "" || cfg.ChecksumKey == "" {
-		return nil, errors.New("missing payOS credentials")
+// NewPaymentService reads SUBSCRIPTION_EXPIRY_NOTICE (how long before the end the expiring
+// event goes out, default 72h), SUBSCRIPTION_EXPIRY_CHECK_INTERVAL (default 1h),
+// SUBSCRIPTION_MAX_PAUSE (default 720h), SUBSCRIPTION_RENEWAL_LEAD (how long before the end
+// the renewal checkout goes out, default 72h), SUBSCRIPTION_DUNNING_GRACE (how long an unpaid
+// renewal keeps the plan, default 240h), SUBSCRIPTION_RENEWAL_INTERVAL (default 24h) and
+// APP_PUBLIC_URL for the billing links in emails.
+// receipts emails the receipt of every payment a webhook reports paid.
+func NewPaymentService(db *gorm.DB, providers []PaymentProvider, defaultProvider string, events EventBus, mail IMailService, receipts ReceiptMailer) (PaymentService, error) {
+	if len(providers) == 0 {
+		return nil, errors.New("no payment provider configured")
 	}
 	// VN timezone normalization
 	vnLoc, err := time.LoadLocation("Asia/Ho_Chi_Minh")
@@ -407,9 +812,43 @@ func NewPaymentService(db *gorm.DB, cfg PayOSConfig) (PaymentService, error) {
 		vnLoc = time.FixedZone("ICT", 7*3600)
 	}
 
-	return &paymentService{
-		db:  db,
-		cfg: cfg,
-		loc: vnLoc,
-	}, nil
+	p := &paymentService{
+		db:            db,
+		providers:     newPaymentProviders(providers, defaultProvider),
+		loc:           vnLoc,
+		events:        events,
+		mail:          mail,
+		receipts:      receipts,
+		appURL:        "https://vivu.com",
+		expiryNotice:  72 * time.Hour,
+		checkInterval: time.Hour,
+		maxPause:      30 * 24 * time.Hour,
+		stop:          make(chan struct{}),
+
+		renewalLead:     72 * time.Hour,
+		dunningGrace:    10 * 24 * time.Hour,
+		renewalInterval: 24 * time.Hour,
+	}
+	if d, err := time.ParseDuration(os.Getenv("SUBSCRIPTION_EXPIRY_NOTICE")); err == nil && d > 0 {
+		p.expiryNotice = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("SUBSCRIPTION_EXPIRY_CHECK_INTERVAL")); err == nil && d > 0 {
+		p.checkInterval = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("SUBSCRIPTION_MAX_PAUSE")); err == nil && d > 0 {
+		p.maxPause = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("SUBSCRIPTION_RENEWAL_LEAD")); err == nil && d > 0 {
+		p.renewalLead = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("SUBSCRIPTION_DUNNING_GRACE")); err == nil && d > 0 {
+		p.dunningGrace = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("SUBSCRIPTION_RENEWAL_INTERVAL")); err == nil && d > 0 {
+		p.renewalInterval = d
+	}
+	if u := strings.TrimRight(os.Getenv("APP_PUBLIC_URL"), "/"); u != "" {
+		p.appURL = u
+	}
+	return p, nil
 }
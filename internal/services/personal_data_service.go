@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+type PersonalDataServiceInterface interface {
+	// Inventory lists every table holding personal data with its purpose and what an erasure
+	// does to it; with an account, how many of its rows each holds.
+	Inventory(ctx context.Context, accountID string) (*response_models.PersonalDataInventory, error)
+	// Export returns everything stored about the account as a JSON file, secrets left out.
+	// Rows are read while the file is written.
+	Export(ctx context.Context, accountID, requestedBy string) (*FileExport, error)
+	// Erase deletes the account's data, redacts what others still need (comments on their
+	// journeys, slow query captures of its prompts), anonymizes what billing must keep, and
+	// removes its stored files and in-memory quiz sessions.
+	Erase(ctx context.Context, accountID, requestedBy string) (*response_models.PersonalDataErasure, error)
+}
+
+type PersonalDataService struct {
+	accountRepo repositories.AccountRepository
+	repo        repositories.PersonalDataRepositoryInterface
+	storage     FileStorage
+	prompts     PromptServiceInterface
+	sessions    *AccountSessionGuard
+}
+
+func NewPersonalDataService(
+	accountRepo repositories.AccountRepository,
+	repo repositories.PersonalDataRepositoryInterface,
+	storage FileStorage,
+	prompts PromptServiceInterface,
+	sessions *AccountSessionGuard,
+) PersonalDataServiceInterface {
+	return &PersonalDataService{accountRepo: accountRepo, repo: repo, storage: storage, prompts: prompts, sessions: sessions}
+}
+
+// personalDataTables lists the registry, with the row counts when given.
+func personalDataTables(counts []int64) []response_models.PersonalDataTable {
+	out := make([]response_models.PersonalDataTable, 0, len(repositories.PersonalDataTables))
+	for i, t := range repositories.PersonalDataTables {
+		entry := response_models.PersonalDataTable{Table: t.Table, Purpose: t.Purpose, Erasure: t.Erasure}
+		if counts != nil {
+			entry.Rows = &counts[i]
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+func (s *PersonalDataService) findAccount(ctx context.Context, accountID string) (*db_models.Account, uuid.UUID, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, uuid.Nil, utils.ErrInvalidInput.WithMessage("Invalid account ID")
+	}
+	account, err := s.accountRepo.FindById(ctx, accountID)
+	if err != nil {
+		return nil, uuid.Nil, utils.ErrDatabaseError
+	}
+	if account == nil {
+		return nil, uuid.Nil, utils.ErrAccountNotFound
+	}
+	return account, id, nil
+}
+
+func (s *PersonalDataService) Inventory(ctx context.Context, accountID string) (*response_models.PersonalDataInventory, error) {
+	if accountID == "" {
+		return &response_models.PersonalDataInventory{Tables: personalDataTables(nil)}, nil
+	}
+	_, id, err := s.findAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.repo.CountOwned(ctx, id)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	return &response_models.PersonalDataInventory{AccountID: accountID, Tables: personalDataTables(counts)}, nil
+}
+
+func (s *PersonalDataService) Export(ctx context.Context, accountID, requestedBy string) (*FileExport, error) {
+	_, id, err := s.findAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.repo.CountOwned(ctx, id)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if err := s.repo.InsertRequest(ctx, &db_models.PersonalDataRequest{
+		AccountID:   id,
+		Kind:        db_models.PersonalDataExport,
+		RequestedBy: requestedBy,
+		Report:      repositories.PersonalDataReport(counts),
+	}); err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+
+	now := time.Now().In(vnLoc)
+	return &FileExport{
+		FileName:    fmt.Sprintf("vivu_data_%s_%s.json", accountID, now.Format("20060102_1504")),
+		ContentType: "application/json",
+		Write:       func(w io.Writer) error { return s.writeExport(ctx, w, id, now) },
+	}, nil
+}
+
+// writeExport writes one JSON document, a table at a time so only one table is in memory.
+func (s *PersonalDataService) writeExport(ctx context.Context, w io.Writer, accountID uuid.UUID, at time.Time) error {
+	head, _ := json.Marshal(map[string]string{"account_id": accountID.String(), "exported_at": utils.FormatRFC3339VN(at)})
+	if _, err := fmt.Fprintf(w, "%s,\"tables\":[", head[:len(head)-1]); err != nil {
+		return err
+	}
+	for i := range repositories.PersonalDataTables {
+		rows, err := s.repo.ExportTable(ctx, i, accountID)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows.Rows {
+			for col, v := range row {
+				// JSON columns come back as bytes; anything else in bytes is text
+				if b, ok := v.([]byte); ok {
+					if json.Valid(b) {
+						row[col] = json.RawMessage(b)
+					} else {
+						row[col] = string(b)
+					}
+				}
+			}
+		}
+		if rows.Rows == nil {
+			rows.Rows = []map[string]any{}
+		}
+		b, err := json.Marshal(map[string]any{"table": rows.Table, "purpose": rows.Purpose, "rows": rows.Rows})
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]}")
+	return err
+}
+
+func (s *PersonalDataService) Erase(ctx context.Context, accountID, requestedBy string) (*response_models.PersonalDataErasure, error) {
+	if accountID == requestedBy {
+		return nil, utils.ErrInvalidInput.WithMessage("You cannot erase your own account")
+	}
+	account, id, err := s.findAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if account.Role == "admin" {
+		return nil, utils.ErrInvalidInput.WithMessage("Admin accounts cannot be erased")
+	}
+
+	// Files are removed once their rows are gone; a file left behind is unreachable
+	keys, err := s.repo.StorageKeys(ctx, id)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	touched, err := s.repo.Erase(ctx, id, requestedBy)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	s.sessions.Forget(accountID)
+
+	out := &response_models.PersonalDataErasure{
+		AccountID:    accountID,
+		Tables:       personalDataTables(touched),
+		QuizSessions: s.prompts.ForgetAccount(accountID),
+	}
+	for _, key := range keys {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			log.Printf("[personal-data] erase %s: file %s: %v", accountID, key, err)
+			continue
+		}
+		out.FilesDeleted++
+	}
+	return out, nil
+}
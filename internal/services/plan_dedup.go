@@ -0,0 +1,87 @@
+package services
+
+import (
+	"fmt"
+	"math"
+
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/response_models"
+)
+
+// dedupePlan keeps the first visit of every POI across the whole plan. A later repeat is
+// swapped for the nearest unused candidate (same category first) or dropped when none is left.
+// used seeds POIs already taken outside the plan (POI ID -> day number), e.g. the other days of
+// a journey when a single day is regenerated. Replacements are added to byID.
+func dedupePlan(plan *response_models.PlanOnly, candidates []*db_models.POI, byID map[string]*db_models.POI, used map[string]int) []string {
+	if plan == nil {
+		return nil
+	}
+	if used == nil {
+		used = make(map[string]int)
+	}
+	var notes []string
+	for di := range plan.Days {
+		day := &plan.Days[di]
+		kept := day.Activities[:0]
+		for _, a := range day.Activities {
+			firstDay, seen := used[a.MainPOIID]
+			if a.MainPOIID == "" || !seen {
+				if a.MainPOIID != "" {
+					used[a.MainPOIID] = day.Day
+				}
+				kept = append(kept, a)
+				continue
+			}
+
+			ref := byID[a.MainPOIID]
+			label := planActivityLabel(a)
+			if ref != nil {
+				label = ref.Name
+			}
+			alt := nearestUnusedPOI(ref, candidates, used)
+			if alt == nil {
+				notes = append(notes, fmt.Sprintf("Day %d: removed %s (already on day %d)", day.Day, label, firstDay))
+				continue
+			}
+			notes = append(notes, fmt.Sprintf("Day %d: replaced %s (already on day %d) with %s", day.Day, label, firstDay, alt.Name))
+			altID := alt.ID.String()
+			byID[altID] = alt
+			used[altID] = day.Day
+			a.MainPOIID = altID
+			a.MainPOI = nil
+			a.Note = ""
+			kept = append(kept, a)
+		}
+		day.Activities = kept
+	}
+	return notes
+}
+
+// nearestUnusedPOI picks the closest candidate not in used, preferring the category of ref.
+// Without a reference it returns the first unused candidate.
+func nearestUnusedPOI(ref *db_models.POI, candidates []*db_models.POI, used map[string]int) *db_models.POI {
+	var best, bestSameCat *db_models.POI
+	bestDist, bestSameDist := math.MaxFloat64, math.MaxFloat64
+	for _, c := range candidates {
+		if c == nil {
+			continue
+		}
+		if _, taken := used[c.ID.String()]; taken {
+			continue
+		}
+		if ref == nil {
+			return c
+		}
+		d := haversineMeters(ref.Latitude, ref.Longitude, c.Latitude, c.Longitude)
+		if d < bestDist {
+			best, bestDist = c, d
+		}
+		if ref.CategoryID != nil && c.CategoryID != nil && *ref.CategoryID == *c.CategoryID && d < bestSameDist {
+			bestSameCat, bestSameDist = c, d
+		}
+	}
+	if bestSameCat != nil {
+		return bestSameCat
+	}
+	return best
+}
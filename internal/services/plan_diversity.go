@@ -0,0 +1,128 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/response_models"
+)
+
+const (
+	defaultDiversityMin = 0.55
+	// A part below this gets a regeneration hint
+	diversityHintBelow = 0.6
+	maxFoodStopsPerDay = 2
+)
+
+// diversityMinFromEnv reads PLAN_DIVERSITY_MIN (0..1, default 0.55); 0 disables regeneration.
+func diversityMinFromEnv() float64 {
+	if v, err := strconv.ParseFloat(os.Getenv("PLAN_DIVERSITY_MIN"), 64); err == nil && v >= 0 && v <= 1 {
+		return v
+	}
+	return defaultDiversityMin
+}
+
+// scorePlanDiversity rates a plan from 0 to 1: half category entropy, a quarter indoor/outdoor
+// balance and a quarter food spacing (no food stops back to back, at most two a day).
+// Activities whose POI is not in byID are ignored.
+func scorePlanDiversity(plan *response_models.PlanOnly, byID map[string]*db_models.POI, categorize func(*db_models.POI) string) *response_models.PlanDiversity {
+	counts := make(map[string]int)
+	total, indoor, outdoor := 0, 0, 0
+	foodStops, foodViolations := 0, 0
+
+	for _, d := range plan.Days {
+		dayFood := 0
+		prevFood := false
+		for _, a := range d.Activities {
+			poi := byID[a.MainPOIID]
+			if poi == nil {
+				continue
+			}
+			total++
+			category := categorize(poi)
+			counts[category]++
+
+			switch weatherExposure(poi) {
+			case 1:
+				outdoor++
+			case -1:
+				indoor++
+			}
+
+			food := category == "Restaurant" || category == "Cafe"
+			if food {
+				foodStops++
+				dayFood++
+				if prevFood || dayFood > maxFoodStopsPerDay {
+					foodViolations++
+				}
+			}
+			prevFood = food
+		}
+	}
+
+	out := &response_models.PlanDiversity{CategoryEntropy: 1, IndoorOutdoorBalance: 1, FoodSpacing: 1}
+
+	// Shannon entropy, normalised by the best possible for this many activities
+	if total > 1 && len(counts) > 0 {
+		var h float64
+		for _, n := range counts {
+			p := float64(n) / float64(total)
+			h -= p * math.Log(p)
+		}
+		out.CategoryEntropy = h / math.Log(float64(total))
+	}
+	if indoor+outdoor > 0 {
+		out.IndoorOutdoorBalance = 1 - math.Abs(float64(outdoor-indoor))/float64(outdoor+indoor)
+	}
+	if foodStops > 0 {
+		out.FoodSpacing = 1 - float64(foodViolations)/float64(foodStops)
+	}
+	out.Score = 0.5*out.CategoryEntropy + 0.25*out.IndoorOutdoorBalance + 0.25*out.FoodSpacing
+
+	if out.CategoryEntropy < diversityHintBelow {
+		out.Hints = append(out.Hints, fmt.Sprintf("Mix more kinds of places; the plan leans on %s.", topCategory(counts)))
+	}
+	if out.IndoorOutdoorBalance < diversityHintBelow {
+		side := "indoor"
+		if outdoor > indoor {
+			side = "outdoor"
+		}
+		out.Hints = append(out.Hints, fmt.Sprintf("Balance indoor and outdoor stops; the plan is mostly %s.", side))
+	}
+	if out.FoodSpacing < diversityHintBelow {
+		out.Hints = append(out.Hints, fmt.Sprintf("Never put food stops back to back, and keep at most %d a day.", maxFoodStopsPerDay))
+	}
+
+	out.Score = roundScore(out.Score)
+	out.CategoryEntropy = roundScore(out.CategoryEntropy)
+	out.IndoorOutdoorBalance = roundScore(out.IndoorOutdoorBalance)
+	out.FoodSpacing = roundScore(out.FoodSpacing)
+	return out
+}
+
+func topCategory(counts map[string]int) string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	if len(names) == 0 {
+		return "one kind of place"
+	}
+	return strings.ToLower(names[0]) + " stops"
+}
+
+func roundScore(v float64) float64 {
+	return math.Round(v*100) / 100
+}
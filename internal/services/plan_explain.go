@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+// selectionSignals completes the retrieval signals of a planned POI with what the POI itself
+// says: the traveler's interests among its tags and its best-reviewed rating. retrieval is nil
+// for POIs that did not come from the search (e.g. a hand-picked replacement).
+func selectionSignals(retrieval *response_models.SelectionSignals, poi *db_models.POI, interests []string) *response_models.SelectionSignals {
+	out := &response_models.SelectionSignals{}
+	if retrieval != nil {
+		*out = *retrieval
+	}
+	if poi == nil {
+		return out
+	}
+
+	wanted := make(map[string]bool, len(interests))
+	for _, in := range interests {
+		wanted[strings.ToLower(in)] = true
+	}
+	out.MatchedTags = nil
+	for _, tag := range poi.Tags {
+		if tag == nil {
+			continue
+		}
+		if wanted[strings.ToLower(tag.EnName)] || wanted[strings.ToLower(tag.ViName)] || wanted[tag.ID.String()] {
+			out.MatchedTags = append(out.MatchedTags, tag.EnName)
+		}
+	}
+
+	var best *db_models.POIExternalRef
+	for i := range poi.ExternalRefs {
+		r := &poi.ExternalRefs[i]
+		if r.Rating != nil && (best == nil || reviewCount(r) > reviewCount(best)) {
+			best = r
+		}
+	}
+	if best != nil {
+		rating := *best.Rating
+		out.Rating = &rating
+		out.ReviewCount = reviewCount(best)
+		out.RatingSource = best.Source
+	}
+	return out
+}
+
+type PlanExplainServiceInterface interface {
+	// ExplainActivity says why the planner picked an activity's POI. planID is the journey the
+	// plan was saved as; owner and members only.
+	ExplainActivity(ctx context.Context, userID, planID, activityID string) (*response_models.ActivityExplanation, error)
+}
+
+type PlanExplainService struct {
+	pollRepo repositories.JourneyPollRepositoryInterface
+	poisRepo repositories.POIRepository
+}
+
+func NewPlanExplainService(pollRepo repositories.JourneyPollRepositoryInterface, poisRepo repositories.POIRepository) PlanExplainServiceInterface {
+	return &PlanExplainService{pollRepo: pollRepo, poisRepo: poisRepo}
+}
+
+func (s *PlanExplainService) ExplainActivity(ctx context.Context, userID, planID, activityID string) (*response_models.ActivityExplanation, error) {
+	journey, _, _, err := journeyAccess(ctx, s.pollRepo, planID, userID)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(activityID)
+	if err != nil {
+		return nil, utils.ErrInvalidInput.WithMessage("Invalid activity ID")
+	}
+	activity, err := s.pollRepo.FindActivity(ctx, journey.ID, id)
+	if err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	if activity == nil {
+		return nil, utils.RecordNotFound.WithMessage("Activity not found in this plan")
+	}
+	poi, err := s.poisRepo.GetByIDWithDetails(ctx, activity.SelectedPOIID.String())
+	if err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+
+	out := &response_models.ActivityExplanation{
+		ActivityID: activity.ID.String(),
+		POIID:      activity.SelectedPOIID.String(),
+		Signals:    activity.Selection,
+	}
+	if poi != nil {
+		out.POIName = poi.Name
+	}
+	out.Summary, out.Reasons = explainSelection(out.POIName, activity.Selection, poi)
+	return out, nil
+}
+
+// explainSelection turns the signals into a one-line summary and a reason per signal.
+func explainSelection(name string, sig *response_models.SelectionSignals, poi *db_models.POI) (string, []string) {
+	if name == "" {
+		name = "This place"
+	}
+	if sig == nil {
+		// Added by hand or planned before signals were kept: only the POI itself can speak
+		sig = selectionSignals(nil, poi, nil)
+		reasons := []string{"This activity was added by hand or planned before we kept the reasons for each pick."}
+		if r := ratingReason(sig); r != "" {
+			reasons = append(reasons, r)
+		}
+		return name + " is part of your plan.", reasons
+	}
+
+	var reasons []string
+	if sig.Similarity != nil {
+		switch pct := int(*sig.Similarity*100 + 0.5); {
+		case *sig.Similarity >= 0.8:
+			reasons = append(reasons, fmt.Sprintf("It closely matches what you asked for (%d%% similar).", pct))
+		case *sig.Similarity >= 0.6:
+			reasons = append(reasons, fmt.Sprintf("It matches what you asked for (%d%% similar).", pct))
+		default:
+			reasons = append(reasons, fmt.Sprintf("It is loosely related to what you asked for (%d%% similar).", pct))
+		}
+	}
+	if sig.KeywordRank != nil {
+		reasons = append(reasons, fmt.Sprintf("Its name or description contains words from your request (#%d in text search).", *sig.KeywordRank))
+	}
+	if sig.LocationMatch {
+		reasons = append(reasons, "It is in the destination you picked.")
+	}
+	if len(sig.MatchedTags) > 0 {
+		reasons = append(reasons, fmt.Sprintf("It fits your interests: %s.", strings.Join(sig.MatchedTags, ", ")))
+	}
+	if r := ratingReason(sig); r != "" {
+		reasons = append(reasons, r)
+	}
+	if len(reasons) == 0 {
+		reasons = append(reasons, "It was among the places available at your destination.")
+	}
+
+	summary := name + " was picked for your plan."
+	if sig.Rank > 0 && sig.Candidates > 0 {
+		summary = fmt.Sprintf("%s ranked #%d of the %d places we considered for your request.", name, sig.Rank, sig.Candidates)
+	}
+	return summary, reasons
+}
+
+func ratingReason(sig *response_models.SelectionSignals) string {
+	if sig.Rating == nil {
+		return ""
+	}
+	r := fmt.Sprintf("Travelers rate it %.1f on %s", *sig.Rating, sig.RatingSource)
+	if sig.ReviewCount > 0 {
+		r += fmt.Sprintf(" across %d reviews", sig.ReviewCount)
+	}
+	if sig.ReviewCount >= 1000 {
+		r += ", one of the most popular places around"
+	}
+	return r + "."
+}
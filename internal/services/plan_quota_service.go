@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+const defaultFreePlanMonthlyLimit = 5
+
+// PlanQuotaServiceInterface meters AI plan generation of free accounts per calendar month.
+// Subscribers are not metered; callers only consult it for free accounts.
+type PlanQuotaServiceInterface interface {
+	// ReservePlan takes one plan of the current month before generating it, atomically, so
+	// parallel requests cannot overshoot the limit. It returns the month the plan counts
+	// against, or utils.ErrPlanQuotaExceeded with the quota as data when none is left.
+	ReservePlan(ctx context.Context, accountID string) (string, error)
+	// ReleasePlan gives back a plan reserved for month when its generation failed.
+	ReleasePlan(ctx context.Context, accountID, month string) error
+}
+
+type PlanQuotaService struct {
+	repo  repositories.PlanUsageRepositoryInterface
+	limit int
+	now   func() time.Time
+}
+
+// NewPlanQuotaService reads FREE_PLAN_MONTHLY_LIMIT (default 5); 0 turns the quota off.
+func NewPlanQuotaService(repo repositories.PlanUsageRepositoryInterface) PlanQuotaServiceInterface {
+	limit := defaultFreePlanMonthlyLimit
+	if v, err := strconv.Atoi(os.Getenv("FREE_PLAN_MONTHLY_LIMIT")); err == nil && v >= 0 {
+		limit = v
+	}
+	return &PlanQuotaService{repo: repo, limit: limit, now: time.Now}
+}
+
+func (s *PlanQuotaService) ReservePlan(ctx context.Context, accountID string) (string, error) {
+	if s.limit <= 0 {
+		return "", nil
+	}
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return "", utils.ErrInvalidInput
+	}
+
+	month, resetsAt := s.period()
+	reserved, err := s.repo.Reserve(ctx, id, month, s.limit)
+	if err != nil {
+		log.Printf("[quota] reserve plan for %s: %v", accountID, err)
+		return "", utils.ErrDatabaseError
+	}
+	if !reserved {
+		used, err := s.repo.CountForMonth(ctx, id, month)
+		if err != nil {
+			return "", utils.ErrDatabaseError
+		}
+		return "", utils.NewPlanQuotaError(utils.PlanQuota{
+			Limit:     s.limit,
+			Used:      used,
+			Remaining: 0,
+			ResetsAt:  resetsAt.Format(time.RFC3339),
+		})
+	}
+	return month, nil
+}
+
+func (s *PlanQuotaService) ReleasePlan(ctx context.Context, accountID, month string) error {
+	if month == "" {
+		return nil
+	}
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return utils.ErrInvalidInput
+	}
+	if err := s.repo.Release(ctx, id, month); err != nil {
+		log.Printf("[quota] release plan for %s: %v", accountID, err)
+		return utils.ErrDatabaseError
+	}
+	return nil
+}
+
+// period returns the current month key (VN time) and when the next one starts.
+func (s *PlanQuotaService) period() (string, time.Time) {
+	now := s.now().In(vnLoc)
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, vnLoc)
+	return start.Format("2006-01"), start.AddDate(0, 1, 0)
+}
@@ -2,6 +2,19 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
 	"vivu/internal/models/response_models"
 	"vivu/internal/repositories"
 	"vivu/pkg/utils"
@@ -10,6 +23,21 @@ import (
 type PlanServiceInterface interface {
 	GetPlans() ([]string, error)
 	GetPlanInfoById(ctx context.Context, planId string) (response_models.SubscriptionPlan, error)
+
+	// Catalog management (admin). Every change is written to the plan's audit trail with the
+	// admin who made it.
+	ListCatalog(ctx context.Context) ([]response_models.AdminPlan, error)
+	CreatePlan(ctx context.Context, req request_models.CreatePlanRequest, actor string) (*response_models.AdminPlan, error)
+	// UpdatePlan refuses to change price, currency or period of a plan with running
+	// subscriptions; CreateVersion is the way to reprice it.
+	UpdatePlan(ctx context.Context, planID string, req request_models.UpdatePlanRequest, actor string) (*response_models.AdminPlan, error)
+	CreateVersion(ctx context.Context, planID string, req request_models.CreatePlanVersionRequest, actor string) (*response_models.AdminPlan, error)
+	// SetActive offers the plan to new checkouts or withdraws it; running subscriptions are
+	// not affected either way.
+	SetActive(ctx context.Context, planID string, active bool, actor string) (*response_models.AdminPlan, error)
+	// DeletePlan only removes plans nobody ever subscribed to; deactivate the others.
+	DeletePlan(ctx context.Context, planID string, actor string) error
+	ListAudit(ctx context.Context, planID string) ([]response_models.PlanAuditEntry, error)
 }
 
 func NewPlanService(planRepo repositories.IPlanRepository) PlanServiceInterface {
@@ -53,3 +81,391 @@ func (p *PlanService) GetPlanInfoById(ctx context.Context, planId string) (respo
 	return result, nil
 
 }
+
+var (
+	planCodePattern   = regexp.MustCompile(`^[a-z0-9][a-z0-9_]*$`)
+	planVersionSuffix = regexp.MustCompile(`_v[0-9]+$`)
+
+	// planEntitlementKinds is every entitlement a plan can grant and the kind of its value.
+	planEntitlementKinds = map[string]string{
+		db_models.EntitlementUnlimitedPlans: "bool",
+		db_models.EntitlementMaxTripDays:    "int",
+		db_models.EntitlementAIProfile:      "string",
+	}
+)
+
+func (p *PlanService) ListCatalog(ctx context.Context) ([]response_models.AdminPlan, error) {
+	rows, err := p.planRepo.ListCatalog(ctx, time.Now().Unix())
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	out := make([]response_models.AdminPlan, 0, len(rows))
+	for i := range rows {
+		out = append(out, toAdminPlan(&rows[i].Plan, rows[i].ActiveSubscriptions))
+	}
+	return out, nil
+}
+
+func (p *PlanService) CreatePlan(ctx context.Context, req request_models.CreatePlanRequest, actor string) (*response_models.AdminPlan, error) {
+	code := strings.TrimSpace(req.Code)
+	if !planCodePattern.MatchString(code) {
+		return nil, utils.ErrInvalidInput.WithMessage("Plan codes use lowercase letters, digits and _")
+	}
+	features, err := planEntitlements(req.Entitlements)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.ensureCodeFree(ctx, code); err != nil {
+		return nil, err
+	}
+
+	plan := &db_models.Plan{
+		Code:            code,
+		Name:            strings.TrimSpace(req.Name),
+		Description:     req.Description,
+		BackgroundImage: req.BackgroundImage,
+		Period:          db_models.BillingPeriod(req.Period),
+		PriceMinor:      req.PriceMinor,
+		Currency:        strings.ToUpper(req.Currency),
+		TrialDays:       req.TrialDays,
+		IsActive:        req.IsActive == nil || *req.IsActive,
+		Features:        features,
+		Version:         1,
+	}
+	if err := p.planRepo.CreatePlan(ctx, plan); err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	p.audit(ctx, plan.ID, db_models.PlanAuditCreated, actor, planAuditChanges(nil, plan))
+	out := toAdminPlan(plan, 0)
+	return &out, nil
+}
+
+func (p *PlanService) UpdatePlan(ctx context.Context, planID string, req request_models.UpdatePlanRequest, actor string) (*response_models.AdminPlan, error) {
+	plan, err := p.findPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	features, err := planEntitlements(req.Entitlements)
+	if err != nil {
+		return nil, err
+	}
+	active, err := p.planRepo.CountActiveSubscriptions(ctx, planID, time.Now().Unix())
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+
+	currency := strings.ToUpper(req.Currency)
+	repriced := req.PriceMinor != plan.PriceMinor || currency != plan.Currency || db_models.BillingPeriod(req.Period) != plan.Period
+	if repriced && active > 0 {
+		return nil, utils.ErrPlanHasSubscribers
+	}
+
+	before := *plan
+	plan.Name = strings.TrimSpace(req.Name)
+	plan.Description = req.Description
+	plan.BackgroundImage = req.BackgroundImage
+	plan.Period = db_models.BillingPeriod(req.Period)
+	plan.PriceMinor = req.PriceMinor
+	plan.Currency = currency
+	plan.TrialDays = req.TrialDays
+	plan.IsActive = req.IsActive
+	plan.Features = features
+	found, err := p.planRepo.UpdatePlan(ctx, plan)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if !found {
+		return nil, utils.RecordNotFound.WithMessage("Plan not found")
+	}
+	if changes := planAuditChanges(&before, plan); len(changes) > 0 {
+		p.audit(ctx, plan.ID, db_models.PlanAuditUpdated, actor, changes)
+	}
+	out := toAdminPlan(plan, active)
+	return &out, nil
+}
+
+func (p *PlanService) CreateVersion(ctx context.Context, planID string, req request_models.CreatePlanVersionRequest, actor string) (*response_models.AdminPlan, error) {
+	previous, err := p.findPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	next := *previous
+	next.BaseModel = db_models.BaseModel{}
+	next.Version = previous.Version + 1
+	next.PreviousPlanID = &previous.ID
+	next.IsActive = true
+	next.Code = strings.TrimSpace(req.Code)
+	if next.Code == "" {
+		next.Code = fmt.Sprintf("%s_v%d", planVersionSuffix.ReplaceAllString(previous.Code, ""), next.Version)
+	}
+	if !planCodePattern.MatchString(next.Code) {
+		return nil, utils.ErrInvalidInput.WithMessage("Plan codes use lowercase letters, digits and _")
+	}
+	if req.PriceMinor != nil {
+		next.PriceMinor = *req.PriceMinor
+	}
+	if req.Currency != nil {
+		next.Currency = strings.ToUpper(*req.Currency)
+	}
+	if req.Period != nil {
+		next.Period = db_models.BillingPeriod(*req.Period)
+	}
+	if req.TrialDays != nil {
+		next.TrialDays = *req.TrialDays
+	}
+	if err := p.ensureCodeFree(ctx, next.Code); err != nil {
+		return nil, err
+	}
+
+	if err := p.planRepo.CreateVersion(ctx, previous, &next); err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	versioned := planAuditChanges(previous, &next)
+	versioned["replaced_by"] = planAuditChange{To: next.ID.String()}
+	p.audit(ctx, previous.ID, db_models.PlanAuditVersioned, actor, versioned)
+	p.audit(ctx, next.ID, db_models.PlanAuditCreated, actor, planAuditChanges(nil, &next))
+	out := toAdminPlan(&next, 0)
+	return &out, nil
+}
+
+func (p *PlanService) SetActive(ctx context.Context, planID string, active bool, actor string) (*response_models.AdminPlan, error) {
+	plan, err := p.findPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	running, err := p.planRepo.CountActiveSubscriptions(ctx, planID, time.Now().Unix())
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if plan.IsActive != active {
+		plan.IsActive = active
+		found, err := p.planRepo.UpdatePlan(ctx, plan)
+		if err != nil {
+			return nil, utils.ErrDatabaseError
+		}
+		if !found {
+			return nil, utils.RecordNotFound.WithMessage("Plan not found")
+		}
+		action := db_models.PlanAuditDeactivated
+		if active {
+			action = db_models.PlanAuditActivated
+		}
+		p.audit(ctx, plan.ID, action, actor, map[string]planAuditChange{"is_active": {From: !active, To: active}})
+	}
+	out := toAdminPlan(plan, running)
+	return &out, nil
+}
+
+func (p *PlanService) DeletePlan(ctx context.Context, planID string, actor string) error {
+	plan, err := p.findPlan(ctx, planID)
+	if err != nil {
+		return err
+	}
+	n, err := p.planRepo.CountSubscriptions(ctx, planID)
+	if err != nil {
+		return utils.ErrDatabaseError
+	}
+	if n > 0 {
+		return utils.ErrPlanHasSubscribers.WithMessage("This plan has subscriptions and cannot be deleted; deactivate it instead")
+	}
+	found, err := p.planRepo.DeletePlan(ctx, planID)
+	if err != nil {
+		return utils.ErrDatabaseError
+	}
+	if !found {
+		return utils.RecordNotFound.WithMessage("Plan not found")
+	}
+	p.audit(ctx, plan.ID, db_models.PlanAuditDeleted, actor, planAuditChanges(plan, nil))
+	return nil
+}
+
+// planAuditLimit caps the audit trail returned for a plan.
+const planAuditLimit = 200
+
+func (p *PlanService) ListAudit(ctx context.Context, planID string) ([]response_models.PlanAuditEntry, error) {
+	if _, err := uuid.Parse(planID); err != nil {
+		return nil, utils.ErrInvalidInput.WithMessage("Invalid plan ID")
+	}
+	// Deleted plans keep their trail, so the plan is not looked up
+	entries, err := p.planRepo.ListAudit(ctx, planID, planAuditLimit)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	out := make([]response_models.PlanAuditEntry, 0, len(entries))
+	for _, e := range entries {
+		entry := response_models.PlanAuditEntry{
+			ID:          e.ID.String(),
+			PlanID:      e.PlanID.String(),
+			Action:      e.Action,
+			PerformedBy: e.PerformedBy,
+			Changes:     map[string]any{},
+			CreatedAt:   utils.FormatRFC3339VN(utils.FromUnixSecondsVN(e.CreatedAt)),
+		}
+		if len(e.Changes) > 0 {
+			_ = json.Unmarshal(e.Changes, &entry.Changes)
+		}
+		out = append(out, entry)
+	}
+	return out, nil
+}
+
+type planAuditChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// planAuditChanges lists the fields that differ between two states of a plan; a nil before is
+// a new plan and a nil after a deleted one.
+func planAuditChanges(before, after *db_models.Plan) map[string]planAuditChange {
+	from, to := planAuditFields(before), planAuditFields(after)
+	changes := map[string]planAuditChange{}
+	for _, field := range []string{"code", "name", "description", "background_image", "period", "price_minor", "currency", "trial_days", "is_active", "entitlements"} {
+		if !reflect.DeepEqual(from[field], to[field]) {
+			changes[field] = planAuditChange{From: from[field], To: to[field]}
+		}
+	}
+	return changes
+}
+
+func planAuditFields(plan *db_models.Plan) map[string]any {
+	if plan == nil {
+		return map[string]any{}
+	}
+	fields := map[string]any{
+		"code":             plan.Code,
+		"name":             plan.Name,
+		"description":      "",
+		"background_image": plan.BackgroundImage,
+		"period":           string(plan.Period),
+		"price_minor":      plan.PriceMinor,
+		"currency":         plan.Currency,
+		"trial_days":       plan.TrialDays,
+		"is_active":        plan.IsActive,
+		"entitlements":     map[string]any{},
+	}
+	if plan.Description != nil {
+		fields["description"] = *plan.Description
+	}
+	if len(plan.Features) > 0 {
+		var entitlements map[string]any
+		if json.Unmarshal(plan.Features, &entitlements) == nil && entitlements != nil {
+			fields["entitlements"] = entitlements
+		}
+	}
+	return fields
+}
+
+// audit records a catalog change. The change itself is already saved, so a failed write is
+// logged rather than reported to the admin.
+func (p *PlanService) audit(ctx context.Context, planID uuid.UUID, action, actor string, changes map[string]planAuditChange) {
+	raw, err := json.Marshal(changes)
+	if err != nil {
+		log.Printf("[plan-catalog] encoding %s audit of plan %s: %v", action, planID, err)
+		return
+	}
+	entry := &db_models.PlanAuditLog{PlanID: planID, Action: action, PerformedBy: actor, Changes: datatypes.JSON(raw)}
+	if err := p.planRepo.InsertAudit(ctx, entry); err != nil {
+		log.Printf("[plan-catalog] writing %s audit of plan %s by %s: %v", action, planID, actor, err)
+	}
+}
+
+func (p *PlanService) findPlan(ctx context.Context, planID string) (*db_models.Plan, error) {
+	if _, err := uuid.Parse(planID); err != nil {
+		return nil, utils.ErrInvalidInput.WithMessage("Invalid plan ID")
+	}
+	plan, err := p.planRepo.GetPlanInfoById(ctx, planID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if plan == nil {
+		return nil, utils.RecordNotFound.WithMessage("Plan not found")
+	}
+	return plan, nil
+}
+
+func (p *PlanService) ensureCodeFree(ctx context.Context, code string) error {
+	exists, err := p.planRepo.CodeExists(ctx, code)
+	if err != nil {
+		return utils.ErrDatabaseError
+	}
+	if exists {
+		return utils.ErrPlanCodeTaken
+	}
+	return nil
+}
+
+// planEntitlements checks the entitlements against planEntitlementKinds and returns them as
+// the Features document.
+func planEntitlements(in map[string]any) (datatypes.JSON, error) {
+	out := make(map[string]any, len(in))
+	for key, value := range in {
+		kind, ok := planEntitlementKinds[key]
+		if !ok {
+			return nil, utils.ErrInvalidInput.WithMessage(fmt.Sprintf("Unknown entitlement %q", key))
+		}
+		switch v := value.(type) {
+		case bool:
+			if kind != "bool" {
+				return nil, utils.ErrInvalidInput.WithMessage(fmt.Sprintf("Entitlement %s must be %s", key, entitlementKindName(kind)))
+			}
+			out[key] = v
+		case float64: // JSON numbers
+			if kind != "int" || v < 0 || v != math.Trunc(v) {
+				return nil, utils.ErrInvalidInput.WithMessage(fmt.Sprintf("Entitlement %s must be %s", key, entitlementKindName(kind)))
+			}
+			out[key] = int64(v)
+		case string:
+			if kind != "string" || !planCodePattern.MatchString(v) {
+				return nil, utils.ErrInvalidInput.WithMessage(fmt.Sprintf("Entitlement %s must be %s", key, entitlementKindName(kind)))
+			}
+			out[key] = v
+		default:
+			return nil, utils.ErrInvalidInput.WithMessage(fmt.Sprintf("Entitlement %s must be %s", key, entitlementKindName(kind)))
+		}
+	}
+	raw, err := json.Marshal(out)
+	if err != nil {
+		return nil, utils.ErrInternal.Wrap(err)
+	}
+	return datatypes.JSON(raw), nil
+}
+
+func entitlementKindName(kind string) string {
+	switch kind {
+	case "bool":
+		return "true or false"
+	case "string":
+		return "a name of lowercase letters, digits and _"
+	}
+	return "a whole number of at least 0"
+}
+
+func toAdminPlan(plan *db_models.Plan, activeSubscriptions int64) response_models.AdminPlan {
+	out := response_models.AdminPlan{
+		SubscriptionPlan: response_models.SubscriptionPlan{
+			ID:              plan.ID,
+			Code:            plan.Code,
+			Name:            plan.Name,
+			Description:     plan.Description,
+			BackgroundImage: plan.BackgroundImage,
+			Period:          string(plan.Period),
+			Price:           plan.PriceMinor,
+			Currency:        plan.Currency,
+			TrialDays:       plan.TrialDays,
+			IsActive:        plan.IsActive,
+		},
+		Version:             plan.Version,
+		Entitlements:        map[string]any{},
+		ActiveSubscriptions: activeSubscriptions,
+		CreatedAt:           utils.FormatRFC3339VN(utils.FromUnixSecondsVN(plan.CreatedAt)),
+		UpdatedAt:           utils.FormatRFC3339VN(utils.FromUnixSecondsVN(plan.UpdatedAt)),
+	}
+	if plan.PreviousPlanID != nil {
+		out.PreviousPlanID = plan.PreviousPlanID.String()
+	}
+	if len(plan.Features) > 0 {
+		_ = json.Unmarshal(plan.Features, &out.Entitlements)
+	}
+	return out
+}
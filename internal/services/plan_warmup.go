@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+)
+
+// Destinations warmed even before anyone saved a journey there.
+const defaultPlanWarmupSeeds = "Da Lat;Hanoi;Ho Chi Minh City;Hoi An;Nha Trang;Phu Quoc"
+
+const (
+	planWarmupWindow     = 30 * 24 * time.Hour // journeys saved this long ago still make a destination popular
+	planWarmupKeepUnused = 24 * time.Hour      // a query nobody asked for this long is no longer refreshed
+	planWarmupMaxPoints  = 40                  // candidates whose pairwise distances are prefetched per destination
+)
+
+// planRetriever is the retrieval step of plan generation, which the warm-up caches.
+type planRetriever interface {
+	rankRelevantPOIs(ctx context.Context, query string) ([]*db_models.POI, map[string]*response_models.SelectionSignals, error)
+	parseDestination(dest string) string
+}
+
+// PlanWarmup keeps the retrieval sets of plan generation and the distances between their POIs
+// warm for popular destinations, so generating a plan for Da Lat or Hanoi runs neither the
+// hybrid search nor a routing request. Retrieval results of other destinations are cached too,
+// but only until they expire.
+type PlanWarmup struct {
+	dashboard repositories.DashboardRepository
+	matrix    DistanceMatrixService
+	retriever planRetriever // set by NewPromptService
+
+	seeds    []string
+	top      int
+	ttl      time.Duration
+	interval time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*planRetrievalEntry // by normalized query
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+type planRetrievalEntry struct {
+	destination string // normalized
+	query       string
+	pois        []*db_models.POI
+	signals     map[string]*response_models.SelectionSignals
+	expiresAt   time.Time
+	usedAt      time.Time
+}
+
+// NewPlanWarmup reads PLAN_WARMUP_INTERVAL (default 1h), PLAN_WARMUP_TTL (default 6h), how long
+// a retrieval set is served, PLAN_WARMUP_TOP (default 10), how many of the destinations with
+// the most journeys in the last 30 days are warmed, and PLAN_WARMUP_SEEDS, the destinations
+// always warmed, separated by ";".
+func NewPlanWarmup(dashboard repositories.DashboardRepository, matrix DistanceMatrixService) *PlanWarmup {
+	w := &PlanWarmup{
+		dashboard: dashboard,
+		matrix:    matrix,
+		top:       10,
+		ttl:       6 * time.Hour,
+		interval:  time.Hour,
+		entries:   make(map[string]*planRetrievalEntry),
+		stop:      make(chan struct{}),
+	}
+	if d, err := time.ParseDuration(os.Getenv("PLAN_WARMUP_INTERVAL")); err == nil && d > 0 {
+		w.interval = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("PLAN_WARMUP_TTL")); err == nil && d > 0 {
+		w.ttl = d
+	}
+	if n, err := strconv.Atoi(os.Getenv("PLAN_WARMUP_TOP")); err == nil && n >= 0 {
+		w.top = n
+	}
+	seeds, ok := os.LookupEnv("PLAN_WARMUP_SEEDS")
+	if !ok {
+		seeds = defaultPlanWarmupSeeds
+	}
+	for _, s := range strings.Split(seeds, ";") {
+		if s = strings.TrimSpace(s); s != "" {
+			w.seeds = append(w.seeds, s)
+		}
+	}
+	return w
+}
+
+// planWarmupKey makes queries that only differ in case or spacing share an entry.
+func planWarmupKey(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// Retrieve returns the retrieval set of query from the cache, running and caching the search on
+// a miss. Callers get their own slice; the POIs and signals are shared and must not be changed.
+func (w *PlanWarmup) Retrieve(ctx context.Context, destination, query string) ([]*db_models.POI, map[string]*response_models.SelectionSignals, error) {
+	key := planWarmupKey(query)
+	now := time.Now()
+
+	w.mu.Lock()
+	if e, ok := w.entries[key]; ok && now.Before(e.expiresAt) {
+		e.usedAt = now
+		pois, signals := append([]*db_models.POI(nil), e.pois...), e.signals
+		w.mu.Unlock()
+		return pois, signals, nil
+	}
+	w.mu.Unlock()
+
+	pois, signals, err := w.retriever.rankRelevantPOIs(ctx, query)
+	if err != nil || len(pois) == 0 {
+		return pois, signals, err
+	}
+	w.store(planWarmupKey(destination), query, pois, signals, now, now)
+	return append([]*db_models.POI(nil), pois...), signals, nil
+}
+
+func (w *PlanWarmup) store(destination, query string, pois []*db_models.POI, signals map[string]*response_models.SelectionSignals, now, usedAt time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.entries[planWarmupKey(query)] = &planRetrievalEntry{
+		destination: destination,
+		query:       query,
+		pois:        pois,
+		signals:     signals,
+		expiresAt:   now.Add(w.ttl),
+		usedAt:      usedAt,
+	}
+}
+
+// RunOnce refreshes the retrieval sets of the popular destinations, prefetches the distances
+// between their POIs and drops what nobody asked for lately. It returns how many destinations
+// were warmed.
+func (w *PlanWarmup) RunOnce(ctx context.Context) (int, error) {
+	if w.retriever == nil {
+		return 0, errors.New("plan warm-up has no retriever")
+	}
+	now := time.Now()
+	w.evict(now)
+
+	warmed := 0
+	for _, dest := range w.popularDestinations(ctx, now) {
+		if ctx.Err() != nil {
+			return warmed, ctx.Err()
+		}
+		key := planWarmupKey(dest)
+
+		// The bare destination is what a quiz without interests searches for; the queries
+		// travellers asked for lately are refreshed along with it
+		queries := map[string]string{planWarmupKey(dest): dest}
+		usedAt := map[string]time.Time{planWarmupKey(dest): now}
+		w.mu.Lock()
+		for k, e := range w.entries {
+			if e.destination == key {
+				queries[k] = e.query
+				usedAt[k] = e.usedAt
+			}
+		}
+		w.mu.Unlock()
+
+		var candidates [][]*db_models.POI
+		for k, query := range queries {
+			pois, signals, err := w.retriever.rankRelevantPOIs(ctx, query)
+			if err != nil {
+				log.Printf("[plan-warmup] retrieval for %q: %v", query, err)
+				continue
+			}
+			if len(pois) == 0 {
+				continue
+			}
+			w.store(key, query, pois, signals, now, usedAt[k])
+			candidates = append(candidates, pois)
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+		w.prefetchDistances(ctx, dest, candidates)
+		warmed++
+	}
+	return warmed, nil
+}
+
+// popularDestinations is the seeds followed by the destinations with the most journeys lately,
+// normalized the way the quiz names them.
+func (w *PlanWarmup) popularDestinations(ctx context.Context, now time.Time) []string {
+	names := append([]string(nil), w.seeds...)
+	if w.top > 0 {
+		rows, err := w.dashboard.TopDestinations(ctx, now.Add(-planWarmupWindow), now, w.top)
+		if err != nil {
+			log.Printf("[plan-warmup] popular destinations: %v", err)
+		}
+		for _, r := range rows {
+			names = append(names, r.Location)
+		}
+	}
+
+	seen := make(map[string]bool, len(names))
+	out := make([]string, 0, len(names))
+	for _, n := range names {
+		dest := w.retriever.parseDestination(n)
+		if k := planWarmupKey(dest); k != "" && !seen[k] {
+			seen[k] = true
+			out = append(out, dest)
+		}
+	}
+	return out
+}
+
+// prefetchDistances fills the pair cache with the distances between the best candidates of a
+// destination, ranked by their best position in any of its retrieval sets. Only the default
+// travel mode is prefetched.
+func (w *PlanWarmup) prefetchDistances(ctx context.Context, dest string, candidates [][]*db_models.POI) {
+	best := make(map[string]int)
+	byID := make(map[string]*db_models.POI)
+	for _, pois := range candidates {
+		for rank, poi := range pois {
+			id := poi.ID.String()
+			if r, ok := best[id]; !ok || rank < r {
+				best[id] = rank
+			}
+			byID[id] = poi
+		}
+	}
+	ids := make([]string, 0, len(byID))
+	for id, poi := range byID {
+		if hasCoords(poi.Latitude, poi.Longitude) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if best[ids[i]] != best[ids[j]] {
+			return best[ids[i]] < best[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	if len(ids) > planWarmupMaxPoints {
+		ids = ids[:planWarmupMaxPoints]
+	}
+	if len(ids) < 2 {
+		return
+	}
+
+	points := make([]MatrixPoint, 0, len(ids))
+	for _, id := range ids {
+		points = append(points, MatrixPoint{ID: id, Lat: byID[id].Latitude, Lng: byID[id].Longitude})
+	}
+	if _, err := w.matrix.ComputeDistances(ctx, points, TravelModeDriving); err != nil {
+		log.Printf("[plan-warmup] distances for %s: %v", dest, err)
+	}
+}
+
+// evict drops the expired entries nobody asked for lately; the others are refreshed by the run.
+func (w *PlanWarmup) evict(now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for k, e := range w.entries {
+		if !now.Before(e.expiresAt) && now.Sub(e.usedAt) > planWarmupKeepUnused {
+			delete(w.entries, k)
+		}
+	}
+}
+
+// Start warms the popular destinations in the background until Stop is called.
+func (w *PlanWarmup) Start() {
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+			n, err := w.RunOnce(ctx)
+			cancel()
+			if err != nil {
+				log.Printf("[plan-warmup] run failed: %v", err)
+			} else {
+				log.Printf("[plan-warmup] warmed %d destinations", n)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (w *PlanWarmup) Stop() {
+	w.stopOnce.Do(func() { close(w.stop) })
+}
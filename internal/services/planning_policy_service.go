@@ -0,0 +1,385 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+const (
+	// planningPolicyMaxPinned caps the policy POIs put ahead of the retrieval results, so the
+	// model still gets the traveler's matches to choose from.
+	planningPolicyMaxPinned = 10
+	// requiredVisitMinutes is the slot given to a required POI the model left out.
+	requiredVisitMinutes = 90
+	// planMaxActivitiesPerDay is the most the plan prompt allows; required POIs are not added past it.
+	planMaxActivitiesPerDay = 5
+)
+
+type PlanningPolicyServiceInterface interface {
+	// GetPolicy returns the account's policy, empty when none was set.
+	GetPolicy(ctx context.Context, accountID string) (*response_models.PlanningPolicyResponse, error)
+	SetPolicy(ctx context.Context, accountID string, req request_models.SetPlanningPolicyRequest, updatedBy string) (*response_models.PlanningPolicyResponse, error)
+	DeletePolicy(ctx context.Context, accountID string) error
+
+	// PolicyFor returns the policy plans of the account are generated with, or nil.
+	PolicyFor(ctx context.Context, accountID string) *PlanningPolicy
+}
+
+type PlanningPolicyService struct {
+	repo     repositories.PlanningPolicyRepositoryInterface
+	poisRepo repositories.POIRepository
+}
+
+func NewPlanningPolicyService(repo repositories.PlanningPolicyRepositoryInterface, poisRepo repositories.POIRepository) PlanningPolicyServiceInterface {
+	return &PlanningPolicyService{repo: repo, poisRepo: poisRepo}
+}
+
+func (s *PlanningPolicyService) GetPolicy(ctx context.Context, accountID string) (*response_models.PlanningPolicyResponse, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, utils.ErrInvalidInput
+	}
+	policy, err := s.repo.FindByAccountID(ctx, id)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if policy == nil {
+		policy = &db_models.PlanningPolicy{}
+	}
+	return toPlanningPolicyResponse(*policy), nil
+}
+
+func (s *PlanningPolicyService) SetPolicy(ctx context.Context, accountID string, req request_models.SetPlanningPolicyRequest, updatedBy string) (*response_models.PlanningPolicyResponse, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, utils.ErrInvalidInput
+	}
+
+	var exclude []string
+	for _, e := range req.Exclude {
+		if e = strings.TrimSpace(e); e != "" && !slices.Contains(exclude, e) {
+			exclude = append(exclude, e)
+		}
+	}
+	boost, require := normalizePOIIDs(req.BoostPOIIDs), normalizePOIIDs(req.RequirePOIIDs)
+
+	// Pinning a POI that does not exist would silently do nothing
+	ids := append(append([]string{}, boost...), require...)
+	if len(ids) > 0 {
+		pois, err := s.poisRepo.ListPoisByPoisId(ctx, ids)
+		if err != nil {
+			return nil, utils.ErrDatabaseError
+		}
+		found := make(map[string]bool, len(pois))
+		for _, poi := range pois {
+			found[poi.ID.String()] = true
+		}
+		var unknown []string
+		for _, id := range ids {
+			if !found[id] && !slices.Contains(unknown, id) {
+				unknown = append(unknown, id)
+			}
+		}
+		if len(unknown) > 0 {
+			return nil, utils.ErrInvalidInput.WithMessage("Unknown POI ids: " + strings.Join(unknown, ", "))
+		}
+	}
+
+	policy := &db_models.PlanningPolicy{
+		AccountID:     id,
+		Exclude:       pq.StringArray(exclude),
+		BoostPOIIDs:   pq.StringArray(boost),
+		RequirePOIIDs: pq.StringArray(require),
+		UpdatedBy:     updatedBy,
+	}
+	if err := s.repo.UpsertPolicy(ctx, policy); err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	return toPlanningPolicyResponse(*policy), nil
+}
+
+func (s *PlanningPolicyService) DeletePolicy(ctx context.Context, accountID string) error {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return utils.ErrInvalidInput
+	}
+	found, err := s.repo.DeleteByAccountID(ctx, id)
+	if err != nil {
+		return utils.ErrDatabaseError
+	}
+	if !found {
+		return utils.RecordNotFound
+	}
+	return nil
+}
+
+func (s *PlanningPolicyService) PolicyFor(ctx context.Context, accountID string) *PlanningPolicy {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil
+	}
+	policy, err := s.repo.FindByAccountID(ctx, id)
+	if err != nil {
+		// Like the destination rules, a policy that cannot be read does not fail the plan
+		log.Printf("[policy] failed to load the planning policy of %s: %v", accountID, err)
+		return nil
+	}
+	return newPlanningPolicy(policy)
+}
+
+func normalizePOIIDs(ids []string) []string {
+	var out []string
+	for _, raw := range ids {
+		id, err := uuid.Parse(strings.TrimSpace(raw))
+		if err != nil {
+			continue
+		}
+		if s := id.String(); !slices.Contains(out, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func toPlanningPolicyResponse(p db_models.PlanningPolicy) *response_models.PlanningPolicyResponse {
+	return &response_models.PlanningPolicyResponse{
+		Exclude:       append([]string{}, p.Exclude...),
+		BoostPOIIDs:   append([]string{}, p.BoostPOIIDs...),
+		RequirePOIIDs: append([]string{}, p.RequirePOIIDs...),
+		UpdatedBy:     p.UpdatedBy,
+		UpdatedAt:     p.UpdatedAt,
+	}
+}
+
+// PlanningPolicy is a partner's policy as the planner applies it: its exclusions filter the
+// retrieval and the final plan like the traveler's own, its POIs are pinned ahead of the
+// retrieval results and named in the prompt. All methods accept a nil policy, which applies
+// nothing.
+type PlanningPolicy struct {
+	exclusions Exclusions
+	pinned     []string // required first, then boosted
+	required   map[string]bool
+}
+
+func newPlanningPolicy(p *db_models.PlanningPolicy) *PlanningPolicy {
+	if p == nil {
+		return nil
+	}
+	out := &PlanningPolicy{
+		exclusions: parseExclusions(p.Exclude),
+		required:   make(map[string]bool, len(p.RequirePOIIDs)),
+	}
+	out.exclusions.reason = "excluded by your travel provider"
+	for _, id := range p.RequirePOIIDs {
+		out.required[id] = true
+		out.pinned = append(out.pinned, id)
+	}
+	for _, id := range p.BoostPOIIDs {
+		if !out.required[id] {
+			out.pinned = append(out.pinned, id)
+		}
+	}
+	return out
+}
+
+// Exclusions are the policy's exclusions; plan adjustments credit them to the provider.
+func (p *PlanningPolicy) Exclusions() Exclusions {
+	if p == nil {
+		return Exclusions{}
+	}
+	return p.exclusions
+}
+
+// Pin puts the pinned POIs, required before boosted and in policy order, ahead of the
+// candidates, without duplicates.
+func (p *PlanningPolicy) Pin(candidates, pinned []*db_models.POI) []*db_models.POI {
+	if p == nil || len(pinned) == 0 {
+		return candidates
+	}
+	rank := make(map[string]int, len(p.pinned))
+	for i, id := range p.pinned {
+		rank[id] = i
+	}
+	pinned = slices.Clone(pinned)
+	sort.SliceStable(pinned, func(i, j int) bool { return rank[pinned[i].ID.String()] < rank[pinned[j].ID.String()] })
+	if len(pinned) > planningPolicyMaxPinned {
+		pinned = pinned[:planningPolicyMaxPinned]
+	}
+
+	out := make([]*db_models.POI, 0, len(pinned)+len(candidates))
+	seen := make(map[string]bool, len(pinned))
+	for _, poi := range pinned {
+		seen[poi.ID.String()] = true
+		out = append(out, poi)
+	}
+	for _, poi := range candidates {
+		if !seen[poi.ID.String()] {
+			out = append(out, poi)
+		}
+	}
+	return out
+}
+
+// PromptIDs splits the policy POIs of list into the preferred and the required ones.
+func (p *PlanningPolicy) PromptIDs(list []request_models.POISummary) (preferred, required []string) {
+	if p == nil {
+		return nil, nil
+	}
+	for _, poi := range list {
+		switch {
+		case p.required[poi.ID]:
+			required = append(required, poi.ID)
+		case slices.Contains(p.pinned, poi.ID):
+			preferred = append(preferred, poi.ID)
+		}
+	}
+	return preferred, required
+}
+
+// PromptLines are the policy's instructions for free-text prompts, naming its POIs among pois.
+func (p *PlanningPolicy) PromptLines(pois []*db_models.POI) []string {
+	if p == nil {
+		return nil
+	}
+	out := p.exclusions.PromptLines()
+	var preferred, required []string
+	for _, poi := range pois {
+		switch id := poi.ID.String(); {
+		case p.required[id]:
+			required = append(required, poi.Name)
+		case slices.Contains(p.pinned, id):
+			preferred = append(preferred, poi.Name)
+		}
+	}
+	if len(required) > 0 {
+		out = append(out, "Must include (hard constraint, schedule each exactly once): "+strings.Join(required, ", "))
+	}
+	if len(preferred) > 0 {
+		out = append(out, "Prefer these places when they fit the day: "+strings.Join(preferred, ", "))
+	}
+	return out
+}
+
+// EnforcePlan schedules the required POIs of list the model left out, in the first free slot
+// inside window of their suggested day or else of the lightest day, and reports what it added
+// or could not fit.
+func (p *PlanningPolicy) EnforcePlan(plan *response_models.PlanOnly, list []request_models.POISummary, window WorkingWindow) []string {
+	if p == nil || plan == nil || len(plan.Days) == 0 {
+		return nil
+	}
+	scheduled := make(map[string]bool)
+	for _, d := range plan.Days {
+		for _, a := range d.Activities {
+			scheduled[a.MainPOIID] = true
+		}
+	}
+
+	var notes []string
+	for _, poi := range list {
+		if !p.required[poi.ID] || scheduled[poi.ID] {
+			continue
+		}
+		di, start, ok := requiredSlot(plan, poi.SuggestedDay, window)
+		if !ok {
+			notes = append(notes, fmt.Sprintf("Could not fit %s, which your travel provider always includes", poi.Name))
+			continue
+		}
+		day := &plan.Days[di]
+		day.Activities = append(day.Activities, response_models.PlanOnlyActivity{
+			StartTime: formatClock(start),
+			EndTime:   formatClock(start + requiredVisitMinutes),
+			MainPOIID: poi.ID,
+		})
+		sort.SliceStable(day.Activities, func(i, j int) bool {
+			a, _ := clockMinutes(day.Activities[i].StartTime)
+			b, _ := clockMinutes(day.Activities[j].StartTime)
+			return a < b
+		})
+		scheduled[poi.ID] = true
+		notes = append(notes, fmt.Sprintf("Day %d: added %s (always included by your travel provider)", day.Day, poi.Name))
+	}
+	return notes
+}
+
+// requiredSlot finds a day with room for one more activity and the start of its first gap of
+// requiredVisitMinutes inside window. The suggested day is tried first, then the lightest days.
+func requiredSlot(plan *response_models.PlanOnly, suggestedDay int, window WorkingWindow) (int, int, bool) {
+	order := make([]int, 0, len(plan.Days))
+	for i := range plan.Days {
+		order = append(order, i)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := plan.Days[order[i]], plan.Days[order[j]]
+		if (a.Day == suggestedDay) != (b.Day == suggestedDay) {
+			return a.Day == suggestedDay
+		}
+		return len(a.Activities) < len(b.Activities)
+	})
+
+	for _, di := range order {
+		acts := plan.Days[di].Activities
+		if len(acts) >= planMaxActivitiesPerDay {
+			continue
+		}
+		var busy []timeWindow
+		for _, a := range acts {
+			from, ok1 := clockMinutes(a.StartTime)
+			to, ok2 := clockMinutes(a.EndTime)
+			if ok1 && ok2 && from < to {
+				busy = append(busy, timeWindow{From: from, To: to})
+			}
+		}
+		sort.Slice(busy, func(i, j int) bool { return busy[i].From < busy[j].From })
+		cursor := window.Start
+		for _, b := range busy {
+			if b.From-cursor >= requiredVisitMinutes {
+				return di, cursor, true
+			}
+			cursor = max(cursor, b.To)
+		}
+		if window.End-cursor >= requiredVisitMinutes {
+			return di, cursor, true
+		}
+	}
+	return 0, 0, false
+}
+
+// planningPolicy is the policy of the account's partner, nil without one.
+func (p *PromptService) planningPolicy(ctx context.Context, userId string) *PlanningPolicy {
+	if p.policies == nil {
+		return nil
+	}
+	return p.policies.PolicyFor(ctx, userId)
+}
+
+// policyPOIs loads the POIs the policy pins that lie in one of provinceIDs: a partner's hotel
+// in Hoi An is not pushed into a trip to Da Lat.
+func (p *PromptService) policyPOIs(ctx context.Context, policy *PlanningPolicy, provinceIDs []string) []*db_models.POI {
+	if policy == nil || len(policy.pinned) == 0 || len(provinceIDs) == 0 {
+		return nil
+	}
+	pois, err := p.poisRepo.ListPoisByPoisId(ctx, policy.pinned)
+	if err != nil {
+		log.Printf("[policy] failed to load pinned POIs: %v", err)
+		return nil
+	}
+	out := pois[:0]
+	for _, poi := range pois {
+		if slices.Contains(provinceIDs, poi.ProvinceID.String()) {
+			out = append(out, poi)
+		}
+	}
+	return out
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"vivu/internal/models/db_models"
+)
+
+// poiEmbeddingContentVersion is stored with every embedding. Bump it whenever the text below
+// changes: the embedding worker re-queues POIs embedded from an older version on start.
+const poiEmbeddingContentVersion = 2
+
+// POIEmbeddingContentBuilder composes the text a POI is embedded from. Embedding models have no
+// notion of fields, so a field's weight is how many times it is repeated: the name, category and
+// tags should dominate the vector, the long description should not drown them.
+type POIEmbeddingContentBuilder struct {
+	NameWeight     int
+	CategoryWeight int
+	TagWeight      int
+	ProvinceWeight int
+
+	MaxDescriptionRunes int
+	MaxReviewKeywords   int
+}
+
+// NewPOIEmbeddingContentBuilder reads EMBEDDING_FIELD_WEIGHTS, e.g. "name=3,category=2,tags=2,province=1"
+// (the default); unknown or invalid entries are ignored.
+func NewPOIEmbeddingContentBuilder() POIEmbeddingContentBuilder {
+	b := POIEmbeddingContentBuilder{
+		NameWeight:          3,
+		CategoryWeight:      2,
+		TagWeight:           2,
+		ProvinceWeight:      1,
+		MaxDescriptionRunes: 1200,
+		MaxReviewKeywords:   12,
+	}
+	for _, kv := range strings.Split(os.Getenv("EMBEDDING_FIELD_WEIGHTS"), ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(kv), "=")
+		if !ok {
+			continue
+		}
+		w, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || w < 0 || w > 5 {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "name":
+			b.NameWeight = w
+		case "category":
+			b.CategoryWeight = w
+		case "tags":
+			b.TagWeight = w
+		case "province":
+			b.ProvinceWeight = w
+		}
+	}
+	return b
+}
+
+// Build returns the embedding text of p. The POI needs Category, Province, Tags and ExternalRefs
+// loaded; reviewKeywords come from reviewKeywords over its check-in notes.
+func (b POIEmbeddingContentBuilder) Build(p *db_models.POI, reviewKeywords []string) string {
+	var lines []string
+	repeat := func(line string, weight int) {
+		if line == "" {
+			return
+		}
+		for i := 0; i < weight; i++ {
+			lines = append(lines, line)
+		}
+	}
+
+	repeat("Name: "+p.Name, max(b.NameWeight, 1))
+	if p.Category.Name != "" {
+		repeat("Category: "+p.Category.Name, b.CategoryWeight)
+	}
+	if tags := poiTagNames(p); len(tags) > 0 {
+		repeat("Tags: "+strings.Join(tags, ", "), b.TagWeight)
+	}
+	if p.Province.Name != "" {
+		repeat("Province: "+p.Province.Name, b.ProvinceWeight)
+	}
+
+	// Structured attributes, once each
+	if p.Address != "" {
+		lines = append(lines, "Address: "+p.Address)
+	}
+	if p.OpeningHours != "" {
+		lines = append(lines, "Opening hours: "+p.OpeningHours)
+	}
+	if r := ratingLine(p.ExternalRefs); r != "" {
+		lines = append(lines, r)
+	}
+	if len(reviewKeywords) > 0 {
+		if len(reviewKeywords) > b.MaxReviewKeywords {
+			reviewKeywords = reviewKeywords[:b.MaxReviewKeywords]
+		}
+		lines = append(lines, "Visitors mention: "+strings.Join(reviewKeywords, ", "))
+	}
+
+	if d := strings.TrimSpace(p.Description); d != "" {
+		if runes := []rune(d); b.MaxDescriptionRunes > 0 && len(runes) > b.MaxDescriptionRunes {
+			d = string(runes[:b.MaxDescriptionRunes])
+		}
+		lines = append(lines, "Description: "+d)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ratingLine describes how the POI rates on third-party sites, best-reviewed source first.
+func ratingLine(refs []db_models.POIExternalRef) string {
+	var best *db_models.POIExternalRef
+	for i := range refs {
+		r := &refs[i]
+		if r.Rating == nil {
+			continue
+		}
+		if best == nil || reviewCount(r) > reviewCount(best) {
+			best = r
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	label := "well rated"
+	switch {
+	case *best.Rating >= 4.5:
+		label = "highly rated"
+	case *best.Rating < 3.5:
+		label = "mixed reviews"
+	}
+	if n := reviewCount(best); n >= 1000 {
+		label += ", very popular"
+	}
+	return fmt.Sprintf("Rating: %.1f on %s (%s)", *best.Rating, best.Source, label)
+}
+
+func reviewCount(r *db_models.POIExternalRef) int {
+	if r.ReviewCount == nil {
+		return 0
+	}
+	return *r.ReviewCount
+}
+
+// reviewStopwords are left out of review keywords (English and unaccented Vietnamese filler).
+// Words under 3 letters are dropped before this check.
+var reviewStopwords = map[string]struct{}{
+	"the": {}, "and": {}, "for": {}, "with": {}, "was": {}, "were": {}, "this": {}, "that": {},
+	"are": {}, "but": {}, "very": {}, "have": {}, "had": {}, "not": {}, "you": {}, "our": {},
+	"its": {}, "too": {}, "really": {}, "place": {}, "there": {}, "here": {},
+	"cua": {}, "rat": {}, "nhung": {}, "cho": {}, "voi": {}, "khong": {}, "duoc": {},
+	"của": {}, "rất": {}, "những": {}, "với": {}, "không": {}, "được": {},
+}
+
+// reviewKeywords returns the words that come up in at least two notes, most frequent first.
+func reviewKeywords(notes []string, limit int) []string {
+	counts := make(map[string]int)
+	for _, note := range notes {
+		seen := make(map[string]struct{})
+		for _, w := range strings.FieldsFunc(strings.ToLower(note), func(r rune) bool {
+			return !unicode.IsLetter(r)
+		}) {
+			if len([]rune(w)) < 3 {
+				continue
+			}
+			if _, stop := reviewStopwords[w]; stop {
+				continue
+			}
+			if _, dup := seen[w]; dup {
+				continue
+			}
+			seen[w] = struct{}{}
+			counts[w]++
+		}
+	}
+
+	out := make([]string, 0, len(counts))
+	for w, n := range counts {
+		if n >= 2 {
+			out = append(out, w)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if counts[out[i]] != counts[out[j]] {
+			return counts[out[i]] > counts[out[j]]
+		}
+		return out[i] < out[j]
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
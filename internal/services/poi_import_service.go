@@ -0,0 +1,345 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+const (
+	poiImportMaxRows   = 5000
+	poiImportBatchSize = 200
+)
+
+type BulkImportServiceInterface interface {
+	// ImportPOIs validates every row of a CSV or XLSX file and inserts the valid ones.
+	// With dryRun nothing is written.
+	ImportPOIs(ctx context.Context, fileName string, data []byte, dryRun bool) (*response_models.POIImportReport, error)
+}
+
+type BulkImportService struct {
+	repo       repositories.POIImportRepositoryInterface
+	boundaries ProvinceBoundaryServiceInterface
+	bounds     CoordinateBounds
+}
+
+func NewBulkImportService(repo repositories.POIImportRepositoryInterface, boundaries ProvinceBoundaryServiceInterface) BulkImportServiceInterface {
+	return &BulkImportService{repo: repo, boundaries: boundaries, bounds: CoordinateBoundsFromEnv()}
+}
+
+// Accepted header names (case-insensitive) for each column.
+var poiImportColumns = map[string][]string{
+	"name":          {"name", "tên", "ten"},
+	"lat":           {"lat", "latitude", "vĩ độ", "vi do"},
+	"lng":           {"lng", "lon", "long", "longitude", "kinh độ", "kinh do"},
+	"province":      {"province", "province_id", "tỉnh", "tinh"},
+	"category":      {"category", "category_id", "loại", "loai"},
+	"address":       {"address", "địa chỉ", "dia chi"},
+	"opening_hours": {"opening_hours", "opening hours", "hours", "giờ mở cửa"},
+	"contact_info":  {"contact_info", "contact", "phone", "liên hệ"},
+	"description":   {"description", "mô tả", "mo ta"},
+	"images":        {"images", "image", "image_urls", "ảnh"},
+}
+
+type poiImportRow struct {
+	Row    int
+	Values map[string]string
+}
+
+func (s *BulkImportService) ImportPOIs(ctx context.Context, fileName string, data []byte, dryRun bool) (*response_models.POIImportReport, error) {
+	var records [][]string
+	var err error
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".xlsx":
+		records, err = utils.ReadXLSXRows(data)
+	case ".csv":
+		records, err = readImportCSV(data)
+	default:
+		return nil, utils.ErrPOIImportFileInvalid
+	}
+	if err != nil {
+		log.Printf("[poi-import] %s: %v", fileName, err)
+		return nil, utils.ErrPOIImportFileInvalid
+	}
+
+	rows, err := mapImportRows(records)
+	if err != nil || len(rows) == 0 || len(rows) > poiImportMaxRows {
+		return nil, utils.ErrPOIImportFileInvalid
+	}
+
+	provinces, err := s.repo.ListProvinces(ctx)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	categories, err := s.repo.ListCategories(ctx)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	provinceByKey := make(map[string]uuid.UUID, len(provinces)*2)
+	for _, p := range provinces {
+		provinceByKey[p.ID.String()] = p.ID
+		provinceByKey[lookupKey(p.Name)] = p.ID
+	}
+	categoryByKey := make(map[string]uuid.UUID, len(categories)*2)
+	for _, c := range categories {
+		categoryByKey[c.ID.String()] = c.ID
+		categoryByKey[lookupKey(c.Name)] = c.ID
+	}
+
+	locator, err := s.boundaries.Locator(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &response_models.POIImportReport{
+		TotalRows: len(rows),
+		DryRun:    dryRun,
+		Errors:    []response_models.POIImportRowError{},
+	}
+
+	// First pass: per-row validation
+	var valid []*db_models.POI
+	var validRows []int
+	provinceSet := make(map[uuid.UUID]struct{})
+	for _, row := range rows {
+		poi, rowErrs := buildImportPOI(row, provinceByKey, categoryByKey, locator, s.bounds)
+		if len(rowErrs) > 0 {
+			report.Errors = append(report.Errors, rowErrs...)
+			continue
+		}
+		valid = append(valid, poi)
+		validRows = append(validRows, row.Row)
+		provinceSet[poi.ProvinceID] = struct{}{}
+	}
+
+	// Second pass: duplicates against the catalog and within the file
+	provinceIDs := make([]uuid.UUID, 0, len(provinceSet))
+	for id := range provinceSet {
+		provinceIDs = append(provinceIDs, id)
+	}
+	existing, err := s.repo.ExistingPOINames(ctx, provinceIDs)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	toInsert := make([]*db_models.POI, 0, len(valid))
+	seenAt := make(map[string]int)
+	for i, poi := range valid {
+		key := repositories.POIImportKey(poi.ProvinceID, poi.Name)
+		if _, dup := existing[key]; dup {
+			report.Errors = append(report.Errors, rowError(validRows[i], "name", "a POI with this name already exists in the province"))
+			continue
+		}
+		if first, dup := seenAt[key]; dup {
+			report.Errors = append(report.Errors, rowError(validRows[i], "name", fmt.Sprintf("duplicate of row %d", first)))
+			continue
+		}
+		seenAt[key] = validRows[i]
+		toInsert = append(toInsert, poi)
+	}
+
+	report.Valid = len(toInsert)
+	report.Failed = countFailedRows(report.Errors)
+	if dryRun || len(toInsert) == 0 {
+		return report, nil
+	}
+
+	if err := s.repo.InsertPOIs(ctx, toInsert, poiImportBatchSize); err != nil {
+		log.Printf("[poi-import] insert failed: %v", err)
+		return nil, utils.ErrDatabaseError
+	}
+	report.Inserted = len(toInsert)
+	for _, p := range toInsert {
+		report.CreatedIDs = append(report.CreatedIDs, p.ID.String())
+	}
+	return report, nil
+}
+
+func buildImportPOI(row poiImportRow, provinces, categories map[string]uuid.UUID, locator *ProvinceLocator, bounds CoordinateBounds) (*db_models.POI, []response_models.POIImportRowError) {
+	var errs []response_models.POIImportRowError
+	v := row.Values
+
+	name := strings.TrimSpace(v["name"])
+	if name == "" {
+		errs = append(errs, rowError(row.Row, "name", "name is required"))
+	} else if len([]rune(name)) > 255 {
+		errs = append(errs, rowError(row.Row, "name", "name is longer than 255 characters"))
+	}
+
+	lat, latErr := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(v["lat"]), ",", "."), 64)
+	lng, lngErr := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(v["lng"]), ",", "."), 64)
+	if latErr != nil || lngErr != nil {
+		errs = append(errs, rowError(row.Row, "latitude", "latitude and longitude must be numbers"))
+	} else if err := bounds.Validate(lat, lng); err != nil {
+		var appErr *utils.AppError
+		errors.As(err, &appErr)
+		errs = append(errs, rowError(row.Row, "latitude", appErr.Message))
+	}
+
+	// A blank province is taken from the outline the coordinates fall in; a given one must
+	// contain them when its outline is known.
+	coordsOK := latErr == nil && lngErr == nil && len(errs) == 0
+	var provinceID uuid.UUID
+	if p := strings.TrimSpace(v["province"]); p != "" {
+		id, ok := provinces[lookupKey(p)]
+		switch {
+		case !ok:
+			errs = append(errs, rowError(row.Row, "province", fmt.Sprintf("unknown province %q", p)))
+		case coordsOK:
+			if inside, known := locator.Contains(id, lat, lng); known && !inside {
+				msg := fmt.Sprintf("coordinates are outside %s", locator.Name(id))
+				if _, in, found := locator.Locate(lat, lng); found {
+					msg += fmt.Sprintf(" (they are in %s)", in)
+				}
+				errs = append(errs, rowError(row.Row, "province", msg))
+			}
+		}
+		provinceID = id
+	} else if coordsOK {
+		id, _, found := locator.Locate(lat, lng)
+		if !found {
+			errs = append(errs, rowError(row.Row, "province", "province is required: the coordinates are not inside any known province outline"))
+		}
+		provinceID = id
+	} else {
+		errs = append(errs, rowError(row.Row, "province", "province is required"))
+	}
+
+	var categoryID *uuid.UUID
+	if c := strings.TrimSpace(v["category"]); c != "" {
+		id, ok := categories[lookupKey(c)]
+		if !ok {
+			errs = append(errs, rowError(row.Row, "category", fmt.Sprintf("unknown category %q", c)))
+		} else {
+			categoryID = &id
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	poi := &db_models.POI{
+		Name:         name,
+		Latitude:     lat,
+		Longitude:    lng,
+		ProvinceID:   provinceID,
+		CategoryID:   categoryID,
+		OpeningHours: strings.TrimSpace(v["opening_hours"]),
+		ContactInfo:  strings.TrimSpace(v["contact_info"]),
+		Address:      strings.TrimSpace(v["address"]),
+		Description:  strings.TrimSpace(v["description"]),
+	}
+	poi.ID = uuid.New()
+	if images := splitImportList(v["images"]); len(images) > 0 {
+		poi.Details = db_models.POIDetail{Images: pq.StringArray(images)}
+	}
+	return poi, nil
+}
+
+func readImportCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // Excel BOM
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	if first, _, _ := bytes.Cut(data, []byte("\n")); bytes.Count(first, []byte(";")) > bytes.Count(first, []byte(",")) {
+		r.Comma = ';'
+	}
+
+	var out [][]string
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rec)
+	}
+}
+
+// mapImportRows maps the header row to known columns and drops blank rows.
+// Row numbers are 1-based spreadsheet rows, header included.
+func mapImportRows(records [][]string) ([]poiImportRow, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("empty file")
+	}
+	col := make(map[string]int)
+	for i, h := range records[0] {
+		h = strings.ToLower(strings.TrimSpace(h))
+		for key, aliases := range poiImportColumns {
+			for _, a := range aliases {
+				if h == a {
+					if _, seen := col[key]; !seen {
+						col[key] = i
+					}
+				}
+			}
+		}
+	}
+	// province may be left out when the coordinates fall inside a stored outline
+	for _, required := range []string{"name", "lat", "lng"} {
+		if _, ok := col[required]; !ok {
+			return nil, fmt.Errorf("missing %s column", required)
+		}
+	}
+
+	var rows []poiImportRow
+	for i, rec := range records[1:] {
+		values := make(map[string]string, len(col))
+		blank := true
+		for key, idx := range col {
+			if idx < len(rec) {
+				values[key] = rec[idx]
+				if strings.TrimSpace(rec[idx]) != "" {
+					blank = false
+				}
+			}
+		}
+		if !blank {
+			rows = append(rows, poiImportRow{Row: i + 2, Values: values})
+		}
+	}
+	return rows, nil
+}
+
+func lookupKey(s string) string {
+	return strings.ToLower(foldDiacritics(strings.Join(strings.Fields(s), " ")))
+}
+
+func splitImportList(s string) []string {
+	var out []string
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == '|' || r == ';' || r == '\n' }) {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func rowError(row int, field, msg string) response_models.POIImportRowError {
+	return response_models.POIImportRowError{Row: row, Field: field, Message: msg}
+}
+
+func countFailedRows(errs []response_models.POIImportRowError) int {
+	rows := make(map[int]struct{}, len(errs))
+	for _, e := range errs {
+		rows[e.Row] = struct{}{}
+	}
+	return len(rows)
+}
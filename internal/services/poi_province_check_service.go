@@ -0,0 +1,290 @@
+package services
+
+import (
+	"context"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+const poiProvinceCheckBatchSize = 1000
+
+type POIProvinceCheckServiceInterface interface {
+	// RunOnce checks every POI against the province outlines, queues the mismatches and closes
+	// the flags of POIs that were fixed meanwhile.
+	RunOnce(ctx context.Context) (*response_models.POIProvinceScan, error)
+	ListFlags(ctx context.Context, query request_models.POIProvinceFlagQuery) (*response_models.POIProvinceFlagPage, error)
+	// ApplyFlag writes the suggested province and/or coordinates to the POI.
+	ApplyFlag(ctx context.Context, flagID, adminID string) error
+	DismissFlag(ctx context.Context, flagID, adminID string) error
+
+	Start()
+	Stop()
+}
+
+type POIProvinceCheckService struct {
+	repo       repositories.POIProvinceFlagRepositoryInterface
+	boundaries ProvinceBoundaryServiceInterface
+	interval   time.Duration
+
+	running  sync.Mutex
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// NewPOIProvinceCheckService reads POI_PROVINCE_CHECK_INTERVAL (default 6h).
+func NewPOIProvinceCheckService(repo repositories.POIProvinceFlagRepositoryInterface, boundaries ProvinceBoundaryServiceInterface) POIProvinceCheckServiceInterface {
+	s := &POIProvinceCheckService{
+		repo:       repo,
+		boundaries: boundaries,
+		interval:   6 * time.Hour,
+		stop:       make(chan struct{}),
+	}
+	if d, err := time.ParseDuration(os.Getenv("POI_PROVINCE_CHECK_INTERVAL")); err == nil && d > 0 {
+		s.interval = d
+	}
+	return s
+}
+
+func (s *POIProvinceCheckService) RunOnce(ctx context.Context) (*response_models.POIProvinceScan, error) {
+	if !s.running.TryLock() {
+		return nil, utils.ErrInvalidInput.WithMessage("A province check is already running")
+	}
+	defer s.running.Unlock()
+
+	scan := &response_models.POIProvinceScan{}
+	locator, err := s.boundaries.Locator(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if locator.Empty() {
+		return scan, nil
+	}
+
+	after := uuid.Nil
+	for {
+		pois, err := s.repo.POILocationsAfter(ctx, after, poiProvinceCheckBatchSize)
+		if err != nil {
+			return nil, utils.ErrDatabaseError.Wrap(err)
+		}
+		if len(pois) == 0 {
+			return scan, nil
+		}
+		after = pois[len(pois)-1].ID
+
+		now := time.Now().Unix()
+		var flags []db_models.POIProvinceFlag
+		var fine []uuid.UUID
+		for _, p := range pois {
+			scan.Checked++
+			flag, known := checkPOIProvince(locator, p)
+			switch {
+			case flag != nil:
+				flag.DetectedAt = now
+				flags = append(flags, *flag)
+				switch flag.Kind {
+				case db_models.POIProvinceFlagWrongProvince:
+					scan.WrongProvince++
+				case db_models.POIProvinceFlagSwapped:
+					scan.Swapped++
+				default:
+					scan.Outside++
+				}
+			case known:
+				fine = append(fine, p.ID)
+			default:
+				scan.Unknown++
+			}
+		}
+		if err := s.repo.SaveFlags(ctx, flags); err != nil {
+			return nil, utils.ErrDatabaseError.Wrap(err)
+		}
+		cleared, err := s.repo.ClearFlags(ctx, fine, now)
+		if err != nil {
+			return nil, utils.ErrDatabaseError.Wrap(err)
+		}
+		scan.Cleared += cleared
+
+		if len(pois) < poiProvinceCheckBatchSize {
+			return scan, nil
+		}
+	}
+}
+
+// checkPOIProvince returns the flag a POI deserves, if any; known is false when neither its
+// province nor any other outline can tell where it belongs.
+func checkPOIProvince(locator *ProvinceLocator, p repositories.POILocation) (flag *db_models.POIProvinceFlag, known bool) {
+	if p.Latitude == 0 && p.Longitude == 0 {
+		return nil, false // no coordinates at all; the quality report lists these
+	}
+	inside, hasOutline := locator.Contains(p.ProvinceID, p.Latitude, p.Longitude)
+	if hasOutline && inside {
+		return nil, true
+	}
+
+	flag = &db_models.POIProvinceFlag{
+		POIID:      p.ID,
+		Status:     db_models.POIProvinceFlagOpen,
+		Latitude:   p.Latitude,
+		Longitude:  p.Longitude,
+		ProvinceID: p.ProvinceID,
+	}
+	if id, _, found := locator.Locate(p.Latitude, p.Longitude); found {
+		flag.Kind = db_models.POIProvinceFlagWrongProvince
+		flag.SuggestedProvinceID = &id
+		return flag, true
+	}
+	if id, _, found := locator.Locate(p.Longitude, p.Latitude); found {
+		flag.Kind = db_models.POIProvinceFlagSwapped
+		lat, lng := p.Longitude, p.Latitude
+		flag.SuggestedLatitude, flag.SuggestedLongitude = &lat, &lng
+		if id != p.ProvinceID {
+			flag.SuggestedProvinceID = &id
+		}
+		return flag, true
+	}
+	if hasOutline {
+		flag.Kind = db_models.POIProvinceFlagOutside
+		return flag, true
+	}
+	return nil, false
+}
+
+func (s *POIProvinceCheckService) ListFlags(ctx context.Context, query request_models.POIProvinceFlagQuery) (*response_models.POIProvinceFlagPage, error) {
+	filter := repositories.POIProvinceFlagFilter{Status: query.Status, Kind: query.Kind}
+	if query.ProvinceID != "" {
+		id, err := uuid.Parse(query.ProvinceID)
+		if err != nil {
+			return nil, utils.ErrInvalidInput
+		}
+		filter.ProvinceID = &id
+	}
+
+	flags, total, err := s.repo.ListFlags(ctx, filter, query.Page, query.PageSize)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	out := &response_models.POIProvinceFlagPage{
+		Items:    make([]response_models.POIProvinceFlag, 0, len(flags)),
+		Total:    total,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	}
+	for i := range flags {
+		out.Items = append(out.Items, toPOIProvinceFlagResponse(&flags[i]))
+	}
+	return out, nil
+}
+
+func (s *POIProvinceCheckService) ApplyFlag(ctx context.Context, flagID, adminID string) error {
+	flag, err := s.openFlag(ctx, flagID)
+	if err != nil {
+		return err
+	}
+	if flag.SuggestedProvinceID == nil && flag.SuggestedLatitude == nil {
+		return utils.ErrInvalidInput.WithMessage("This flag has no suggested correction; fix the POI by hand or dismiss the flag")
+	}
+
+	applied, err := s.repo.ApplyFlag(ctx, flag, adminID, time.Now().Unix())
+	if err != nil {
+		return utils.ErrDatabaseError.Wrap(err)
+	}
+	if !applied {
+		return utils.ErrInvalidInput.WithMessage("The POI changed since it was checked; run the check again")
+	}
+	return nil
+}
+
+func (s *POIProvinceCheckService) DismissFlag(ctx context.Context, flagID, adminID string) error {
+	if _, err := s.openFlag(ctx, flagID); err != nil {
+		return err
+	}
+	found, err := s.repo.ResolveFlag(ctx, flagID, db_models.POIProvinceFlagDismissed, adminID, time.Now().Unix())
+	if err != nil {
+		return utils.ErrDatabaseError.Wrap(err)
+	}
+	if !found {
+		return utils.ErrInvalidInput.WithMessage("This flag is no longer open")
+	}
+	return nil
+}
+
+func (s *POIProvinceCheckService) openFlag(ctx context.Context, flagID string) (*db_models.POIProvinceFlag, error) {
+	if _, err := uuid.Parse(flagID); err != nil {
+		return nil, utils.ErrInvalidInput.WithMessage("Invalid flag ID")
+	}
+	flag, err := s.repo.FindFlag(ctx, flagID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	if flag == nil {
+		return nil, utils.RecordNotFound
+	}
+	if flag.Status != db_models.POIProvinceFlagOpen {
+		return nil, utils.ErrInvalidInput.WithMessage("This flag is no longer open")
+	}
+	return flag, nil
+}
+
+func (s *POIProvinceCheckService) Start() {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+			scan, err := s.RunOnce(ctx)
+			cancel()
+			if err != nil {
+				log.Printf("[province-check] run failed: %v", err)
+			} else if n := scan.WrongProvince + scan.Swapped + scan.Outside; n > 0 {
+				log.Printf("[province-check] %d of %d POIs do not match their province", n, scan.Checked)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (s *POIProvinceCheckService) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+func toPOIProvinceFlagResponse(f *db_models.POIProvinceFlag) response_models.POIProvinceFlag {
+	out := response_models.POIProvinceFlag{
+		ID:                 f.ID.String(),
+		POIID:              f.POIID.String(),
+		POIName:            f.POI.Name,
+		Kind:               f.Kind,
+		Status:             f.Status,
+		Latitude:           f.Latitude,
+		Longitude:          f.Longitude,
+		ProvinceID:         f.ProvinceID.String(),
+		Province:           f.Province.Name,
+		SuggestedLatitude:  f.SuggestedLatitude,
+		SuggestedLongitude: f.SuggestedLongitude,
+		DetectedAt:         utils.FormatRFC3339VN(utils.FromUnixSecondsVN(f.DetectedAt)),
+		ResolvedBy:         f.ResolvedBy,
+	}
+	if f.SuggestedProvinceID != nil {
+		out.SuggestedProvinceID = f.SuggestedProvinceID.String()
+	}
+	if f.SuggestedProvince != nil {
+		out.SuggestedProvince = f.SuggestedProvince.Name
+	}
+	if f.ResolvedAt != nil {
+		out.ResolvedAt = utils.FormatRFC3339VN(utils.FromUnixSecondsVN(*f.ResolvedAt))
+	}
+	return out
+}
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+// Weights add up to 100. Coordinates weigh most: without them a POI cannot be routed at all.
+var poiQualityScoring = repositories.POIQualityScoring{
+	Coords:            25,
+	Hours:             15,
+	Images:            20,
+	Description:       15,
+	Embedding:         15,
+	Reviews:           10,
+	ImageTarget:       3,
+	DescriptionTarget: 200,
+}
+
+var poiQualityGaps = []string{
+	repositories.POIGapCoords, repositories.POIGapHours, repositories.POIGapImages,
+	repositories.POIGapDescription, repositories.POIGapEmbedding, repositories.POIGapReviews,
+}
+
+const poiQualityExportBatchSize = 500
+
+var poiQualityExportColumns = []string{
+	"id", "name", "province", "category", "status", "score", "missing", "image_count", "description_length", "updated_at",
+}
+
+type POIQualityServiceInterface interface {
+	ListScores(ctx context.Context, query request_models.POIQualityQuery) (*response_models.POIQualityPage, error)
+	// GetReport sums up the gaps of the POIs matching the query, per gap and per province.
+	GetReport(ctx context.Context, query request_models.POIQualityQuery) (*response_models.POIQualityReport, error)
+	// ExportNeedsAttention returns the file to stream; nothing is read until Write is called.
+	ExportNeedsAttention(ctx context.Context, query request_models.POIQualityExportQuery) (*FileExport, error)
+}
+
+type POIQualityService struct {
+	repo           repositories.POIQualityRepositoryInterface
+	attentionBelow int // POI_QUALITY_ATTENTION_BELOW, default 60
+}
+
+func NewPOIQualityService(repo repositories.POIQualityRepositoryInterface) POIQualityServiceInterface {
+	s := &POIQualityService{repo: repo, attentionBelow: 60}
+	if n, err := strconv.Atoi(os.Getenv("POI_QUALITY_ATTENTION_BELOW")); err == nil && n > 0 && n <= 100 {
+		s.attentionBelow = n
+	}
+	return s
+}
+
+func (s *POIQualityService) ListScores(ctx context.Context, query request_models.POIQualityQuery) (*response_models.POIQualityPage, error) {
+	filter, err := poiQualityFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, total, err := s.repo.ListScores(ctx, poiQualityScoring, filter, (query.Page-1)*query.PageSize, query.PageSize)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+
+	out := &response_models.POIQualityPage{
+		Items:    make([]response_models.POIQuality, 0, len(rows)),
+		Total:    total,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	}
+	for i := range rows {
+		out.Items = append(out.Items, toPOIQualityResponse(&rows[i]))
+	}
+	return out, nil
+}
+
+func (s *POIQualityService) GetReport(ctx context.Context, query request_models.POIQualityQuery) (*response_models.POIQualityReport, error) {
+	filter, err := poiQualityFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.repo.Summarize(ctx, poiQualityScoring, filter, s.attentionBelow)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	provinces, err := s.repo.SummarizeByProvince(ctx, poiQualityScoring, filter, s.attentionBelow)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+
+	report := &response_models.POIQualityReport{
+		Total:          summary.Total,
+		AverageScore:   math.Round(summary.AverageScore*10) / 10,
+		AttentionBelow: s.attentionBelow,
+		NeedsAttention: summary.NeedsAttention,
+		Gaps: map[string]int64{
+			repositories.POIGapCoords:      summary.MissingCoords,
+			repositories.POIGapHours:       summary.MissingHours,
+			repositories.POIGapImages:      summary.FewImages,
+			repositories.POIGapDescription: summary.ShortDesc,
+			repositories.POIGapEmbedding:   summary.NoEmbedding,
+			repositories.POIGapReviews:     summary.NoReviews,
+		},
+		Provinces: make([]response_models.POIQualityProvinceGap, 0, len(provinces)),
+	}
+	for _, p := range provinces {
+		report.Provinces = append(report.Provinces, response_models.POIQualityProvinceGap{
+			ProvinceID:     p.ProvinceID.String(),
+			Province:       p.Province,
+			Total:          p.Total,
+			AverageScore:   math.Round(p.AverageScore*10) / 10,
+			NeedsAttention: p.NeedsAttention,
+		})
+	}
+	return report, nil
+}
+
+func (s *POIQualityService) ExportNeedsAttention(ctx context.Context, query request_models.POIQualityExportQuery) (*FileExport, error) {
+	filter, err := poiQualityFilter(query.POIQualityQuery)
+	if err != nil {
+		return nil, err
+	}
+	if filter.MaxScore == nil {
+		below := s.attentionBelow - 1
+		filter.MaxScore = &below
+	}
+
+	format := query.Format
+	if format == "" {
+		format = ExportFormatXLSX
+	}
+	name := "pois_needing_attention_" + time.Now().In(vnLoc).Format("20060102_1504") + "." + format
+	stream := func(fn func([]repositories.POIQualityRow) error) error {
+		for offset := 0; ; offset += poiQualityExportBatchSize {
+			rows, _, err := s.repo.ListScores(ctx, poiQualityScoring, filter, offset, poiQualityExportBatchSize)
+			if err != nil {
+				return err
+			}
+			if err := fn(rows); err != nil {
+				return err
+			}
+			if len(rows) < poiQualityExportBatchSize {
+				return nil
+			}
+		}
+	}
+
+	if format == ExportFormatCSV {
+		return &FileExport{
+			FileName:    name,
+			ContentType: "text/csv; charset=utf-8",
+			Write:       func(w io.Writer) error { return writePOIQualityCSV(w, stream) },
+		}, nil
+	}
+	return &FileExport{
+		FileName:    name,
+		ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		Write:       func(w io.Writer) error { return writePOIQualityXLSX(w, stream) },
+	}, nil
+}
+
+type poiQualityStream func(fn func([]repositories.POIQualityRow) error) error
+
+func writePOIQualityCSV(w io.Writer, stream poiQualityStream) error {
+	if _, err := io.WriteString(w, "\xef\xbb\xbf"); err != nil { // BOM so Excel reads Vietnamese correctly
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(poiQualityExportColumns); err != nil {
+		return err
+	}
+	err := stream(func(batch []repositories.POIQualityRow) error {
+		for i := range batch {
+			q := toPOIQualityResponse(&batch[i])
+			if err := cw.Write([]string{
+				q.ID, q.Name, q.Province, q.Category, q.Status, strconv.Itoa(q.Score), strings.Join(q.Missing, "|"),
+				strconv.Itoa(q.ImageCount), strconv.Itoa(q.DescriptionLength), q.UpdatedAt,
+			}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writePOIQualityXLSX(w io.Writer, stream poiQualityStream) error {
+	xw, err := utils.NewXLSXWriter(w, "Needs attention")
+	if err != nil {
+		return err
+	}
+	header := make([]any, len(poiQualityExportColumns))
+	for i, c := range poiQualityExportColumns {
+		header[i] = c
+	}
+	if err := xw.WriteRow(header...); err != nil {
+		return err
+	}
+	err = stream(func(batch []repositories.POIQualityRow) error {
+		for i := range batch {
+			q := toPOIQualityResponse(&batch[i])
+			if err := xw.WriteRow(q.ID, q.Name, q.Province, q.Category, q.Status, q.Score, strings.Join(q.Missing, "|"),
+				q.ImageCount, q.DescriptionLength, q.UpdatedAt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return xw.Close()
+}
+
+func poiQualityFilter(query request_models.POIQualityQuery) (repositories.POIQualityFilter, error) {
+	filter := repositories.POIQualityFilter{
+		Status:   strings.TrimSpace(query.Status),
+		MinScore: query.MinScore,
+		MaxScore: query.MaxScore,
+		Gap:      query.Missing,
+	}
+	if query.ProvinceID != "" {
+		id, err := uuid.Parse(query.ProvinceID)
+		if err != nil {
+			return filter, utils.ErrInvalidInput
+		}
+		filter.ProvinceID = &id
+	}
+	if query.CategoryID != "" {
+		id, err := uuid.Parse(query.CategoryID)
+		if err != nil {
+			return filter, utils.ErrInvalidInput
+		}
+		filter.CategoryID = &id
+	}
+	if filter.MinScore != nil && filter.MaxScore != nil && *filter.MinScore > *filter.MaxScore {
+		return filter, utils.ErrInvalidInput.WithMessage("min_score must not exceed max_score")
+	}
+	return filter, nil
+}
+
+// poiQualityMissing lists the gaps of a POI, in the order of poiQualityGaps.
+func poiQualityMissing(r *repositories.POIQualityRow) []string {
+	missing := make([]string, 0, len(poiQualityGaps))
+	for _, gap := range poiQualityGaps {
+		var has bool
+		switch gap {
+		case repositories.POIGapCoords:
+			has = r.HasCoords
+		case repositories.POIGapHours:
+			has = r.HasHours
+		case repositories.POIGapImages:
+			has = r.ImageCount >= poiQualityScoring.ImageTarget
+		case repositories.POIGapDescription:
+			has = r.DescriptionLength >= poiQualityScoring.DescriptionTarget
+		case repositories.POIGapEmbedding:
+			has = r.HasEmbedding
+		case repositories.POIGapReviews:
+			has = r.HasReviews
+		}
+		if !has {
+			missing = append(missing, gap)
+		}
+	}
+	return missing
+}
+
+func toPOIQualityResponse(r *repositories.POIQualityRow) response_models.POIQuality {
+	return response_models.POIQuality{
+		ID:                r.ID.String(),
+		Name:              r.Name,
+		Status:            r.Status,
+		Province:          r.Province,
+		Category:          r.Category,
+		Score:             r.Score,
+		Missing:           poiQualityMissing(r),
+		ImageCount:        r.ImageCount,
+		DescriptionLength: r.DescriptionLength,
+		UpdatedAt:         utils.FormatRFC3339VN(utils.FromUnixSecondsVN(r.UpdatedAt)),
+	}
+}
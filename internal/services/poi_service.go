@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/google/uuid"
 	"log"
+	"math"
 	"vivu/internal/models/db_models"
 	"vivu/internal/models/request_models"
 	"vivu/internal/models/response_models"
@@ -19,10 +20,13 @@ type POIServiceInterface interface {
 	DeletePoi(id uuid.UUID, ctx context.Context) error
 	ListPois(ctx context.Context, page, pageSize int) ([]db_models.POI, error)
 	SearchPoiByNameAndProvince(name, provinceID string, page, pageSize int, ctx context.Context) ([]response_models.POI, error)
+	SearchPOIs(ctx context.Context, query, provinceID string, page, pageSize int) (*response_models.POISearchPage, error)
+	FindNearbyPOIs(ctx context.Context, lat, lng, radiusMeters float64, category string, limit int) ([]response_models.POINearbyHit, error)
 }
 
 type PoiService struct {
 	poiRepository repositories.POIRepository
+	bounds        CoordinateBounds
 }
 
 func (p *PoiService) SearchPoiByNameAndProvince(name, provinceID string, page, pageSize int, ctx context.Context) ([]response_models.POI, error) {
@@ -40,29 +44,79 @@ func (p *PoiService) SearchPoiByNameAndProvince(name, provinceID string, page, p
 	poiResponses := make([]response_models.POI, 0, len(pois))
 
 	for _, poi := range pois {
-		var poiDetails *response_models.PoiDetails
-		if poi.Details.ID != uuid.Nil {
-			poiDetails = &response_models.PoiDetails{
-				ID:          poi.Details.ID.String(),
-				Description: poi.Description,
-				Image:       poi.Details.Images,
-			}
-		}
+		poiResponses = append(poiResponses, toPOIResponse(poi))
+	}
 
-		poiResponses = append(poiResponses, response_models.POI{
-			ID:           poi.ID.String(),
-			Name:         poi.Name,
-			Latitude:     poi.Latitude,
-			Longitude:    poi.Longitude,
-			Category:     poi.Category.Name,
-			OpeningHours: poi.OpeningHours,
-			ContactInfo:  poi.ContactInfo,
-			Address:      poi.Address,
-			PoiDetails:   poiDetails,
+	return poiResponses, nil
+}
+
+// SearchPOIs is the accent-insensitive, ranked full-text search behind GET /pois/search.
+func (p *PoiService) SearchPOIs(ctx context.Context, query, provinceID string, page, pageSize int) (*response_models.POISearchPage, error) {
+	hits, total, err := p.poiRepository.SearchPOIsFullText(ctx, query, provinceID, page, pageSize)
+	if err != nil {
+		log.Printf("Error in full-text POI search: %v", err)
+		return nil, utils.ErrDatabaseError
+	}
+
+	out := &response_models.POISearchPage{
+		Items:    make([]response_models.POISearchHit, 0, len(hits)),
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}
+	for _, h := range hits {
+		out.Items = append(out.Items, response_models.POISearchHit{POI: toPOIResponse(h.POI), Rank: h.Rank})
+	}
+	return out, nil
+}
+
+// FindNearbyPOIs lists published POIs around a point, nearest first, behind GET /pois/nearby.
+func (p *PoiService) FindNearbyPOIs(ctx context.Context, lat, lng, radiusMeters float64, category string, limit int) ([]response_models.POINearbyHit, error) {
+	hits, err := p.poiRepository.FindNearbyPOIs(ctx, lat, lng, radiusMeters, category, limit)
+	if err != nil {
+		log.Printf("Error finding nearby POIs: %v", err)
+		return nil, utils.ErrDatabaseError
+	}
+
+	out := make([]response_models.POINearbyHit, 0, len(hits))
+	for _, h := range hits {
+		out = append(out, response_models.POINearbyHit{
+			POI:            toPOIResponse(h.POI),
+			DistanceMeters: math.Round(h.DistanceMeters),
 		})
 	}
+	return out, nil
+}
 
-	return poiResponses, nil
+func toPOIResponse(poi *db_models.POI) response_models.POI {
+	var poiDetails *response_models.PoiDetails
+	if poi.Details.ID != uuid.Nil {
+		poiDetails = &response_models.PoiDetails{
+			ID:          poi.Details.ID.String(),
+			Description: poi.Description,
+			Image:       poi.Details.Images,
+		}
+	}
+
+	out := response_models.POI{
+		ID:           poi.ID.String(),
+		Name:         poi.Name,
+		Latitude:     poi.Latitude,
+		Longitude:    poi.Longitude,
+		Category:     poi.Category.Name,
+		OpeningHours: poi.OpeningHours,
+		ContactInfo:  poi.ContactInfo,
+		Address:      poi.Address,
+		PoiDetails:   poiDetails,
+	}
+
+	// Only snapshots we actually have; a ref that was never fetched has nothing to show yet
+	for _, ref := range poi.ExternalRefs {
+		if ref.FetchedAt != nil {
+			out.ExternalRatings = append(out.ExternalRatings, ToExternalRating(ref))
+		}
+	}
+	return out
 }
 
 func (p *PoiService) ListPois(ctx context.Context, page, pageSize int) ([]db_models.POI, error) {
@@ -97,6 +151,10 @@ func (p *PoiService) DeletePoi(id uuid.UUID, ctx context.Context) error {
 }
 
 func (p *PoiService) UpdatePoi(pois request_models.UpdatePoiRequest, ctx context.Context) error {
+	if err := p.bounds.Validate(pois.Latitude, pois.Longitude); err != nil {
+		return err
+	}
+
 	existingPOI, err := p.poiRepository.GetByIDWithDetails(ctx, pois.ID.String())
 	if err != nil {
 		log.Printf("Error fetching POI: %v", err)
@@ -130,6 +188,9 @@ func (p *PoiService) UpdatePoi(pois request_models.UpdatePoiRequest, ctx context
 }
 
 func (p *PoiService) CreatePois(pois request_models.CreatePoiRequest, ctx context.Context) error {
+	if err := p.bounds.Validate(pois.Latitude, pois.Longitude); err != nil {
+		return err
+	}
 
 	newPOI := &db_models.POI{
 		Name:         pois.Name,
@@ -168,26 +229,7 @@ func (p *PoiService) GetPOIById(id string, ctx context.Context) (response_models
 		return response_models.POI{}, utils.ErrPOINotFound
 	}
 
-	var poiDetails *response_models.PoiDetails
-	if poi.Details.ID != uuid.Nil {
-		poiDetails = &response_models.PoiDetails{
-			ID:          poi.Details.ID.String(),
-			Description: poi.Description, // or poi.Details.Description if preferred
-			Image:       poi.Details.Images,
-		}
-	}
-
-	return response_models.POI{
-		ID:           poi.ID.String(),
-		Name:         poi.Name,
-		Latitude:     poi.Latitude,
-		Longitude:    poi.Longitude,
-		Category:     poi.Category.Name,
-		OpeningHours: poi.OpeningHours,
-		ContactInfo:  poi.ContactInfo,
-		Address:      poi.Address,
-		PoiDetails:   poiDetails,
-	}, nil
+	return toPOIResponse(poi), nil
 }
 
 func (p *PoiService) GetPoisByProvince(province string, page, pageSize int, ctx context.Context) ([]response_models.POI, error) {
@@ -236,5 +278,6 @@ func (p *PoiService) GetPoisByProvince(province string, page, pageSize int, ctx
 func NewPOIService(poiRepository repositories.POIRepository) POIServiceInterface {
 	return &PoiService{
 		poiRepository: poiRepository,
+		bounds:        CoordinateBoundsFromEnv(),
 	}
 }
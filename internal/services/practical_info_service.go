@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+type PracticalInfoServiceInterface interface {
+	ListInfo(ctx context.Context) ([]response_models.PracticalInfoResponse, error)
+	GetInfo(ctx context.Context, provinceID string) (*response_models.PracticalInfoResponse, error)
+	SetInfo(ctx context.Context, provinceID string, req request_models.SetPracticalInfoRequest, updatedBy string) (*response_models.PracticalInfoResponse, error)
+	DeleteInfo(ctx context.Context, provinceID string) error
+
+	// TipsForPOIs flattens the info of the provinces the POIs belong to into itinerary tips.
+	// It returns nil when nothing is curated.
+	TipsForPOIs(ctx context.Context, poiIDs []string) []string
+}
+
+type PracticalInfoService struct {
+	repo repositories.PracticalInfoRepositoryInterface
+}
+
+func NewPracticalInfoService(repo repositories.PracticalInfoRepositoryInterface) PracticalInfoServiceInterface {
+	return &PracticalInfoService{repo: repo}
+}
+
+func (s *PracticalInfoService) ListInfo(ctx context.Context) ([]response_models.PracticalInfoResponse, error) {
+	infos, err := s.repo.ListInfo(ctx)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	out := make([]response_models.PracticalInfoResponse, 0, len(infos))
+	for _, i := range infos {
+		out = append(out, toPracticalInfoResponse(i))
+	}
+	return out, nil
+}
+
+func (s *PracticalInfoService) GetInfo(ctx context.Context, provinceID string) (*response_models.PracticalInfoResponse, error) {
+	if _, err := uuid.Parse(provinceID); err != nil {
+		return nil, utils.ErrInvalidInput
+	}
+	info, err := s.repo.FindByProvinceID(ctx, provinceID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if info == nil {
+		return nil, utils.RecordNotFound
+	}
+	out := toPracticalInfoResponse(*info)
+	return &out, nil
+}
+
+func (s *PracticalInfoService) SetInfo(ctx context.Context, provinceID string, req request_models.SetPracticalInfoRequest, updatedBy string) (*response_models.PracticalInfoResponse, error) {
+	id, err := uuid.Parse(provinceID)
+	if err != nil {
+		return nil, utils.ErrInvalidInput
+	}
+
+	info := &db_models.PracticalInfo{
+		ProvinceID:   id,
+		Connectivity: pq.StringArray(cleanTipLines(req.Connectivity)),
+		Transport:    pq.StringArray(cleanTipLines(req.Transport)),
+		Tipping:      strings.TrimSpace(req.Tipping),
+		Other:        pq.StringArray(cleanTipLines(req.Other)),
+		UpdatedBy:    updatedBy,
+	}
+	if err := s.repo.UpsertInfo(ctx, info); err != nil {
+		// FK violation on an unknown province lands here too
+		return nil, utils.ErrDatabaseError
+	}
+
+	out := toPracticalInfoResponse(*info)
+	return &out, nil
+}
+
+func (s *PracticalInfoService) DeleteInfo(ctx context.Context, provinceID string) error {
+	if _, err := uuid.Parse(provinceID); err != nil {
+		return utils.ErrInvalidInput
+	}
+	found, err := s.repo.DeleteByProvinceID(ctx, provinceID)
+	if err != nil {
+		return utils.ErrDatabaseError
+	}
+	if !found {
+		return utils.RecordNotFound
+	}
+	return nil
+}
+
+func (s *PracticalInfoService) TipsForPOIs(ctx context.Context, poiIDs []string) []string {
+	infos, err := s.repo.FindByPOIIDs(ctx, poiIDs)
+	if err != nil {
+		// Tips are nice to have, never a reason to fail a plan
+		log.Printf("[practical-info] failed to load practical info: %v", err)
+		return nil
+	}
+
+	var out []string
+	for _, i := range infos {
+		// Only name the province when the trip spans several
+		prefix := func(topic string) string {
+			if len(infos) > 1 && i.Province.Name != "" {
+				return i.Province.Name + " – " + topic + ": "
+			}
+			return topic + ": "
+		}
+		for _, line := range i.Connectivity {
+			out = append(out, prefix("SIM & internet")+line)
+		}
+		for _, line := range i.Transport {
+			out = append(out, prefix("Getting around")+line)
+		}
+		if i.Tipping != "" {
+			out = append(out, prefix("Tipping")+i.Tipping)
+		}
+		for _, line := range i.Other {
+			out = append(out, prefix("Good to know")+line)
+		}
+	}
+	return out
+}
+
+func cleanTipLines(lines []string) []string {
+	out := make([]string, 0, len(lines))
+	for _, l := range lines {
+		if l = strings.TrimSpace(l); l != "" {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+func toPracticalInfoResponse(i db_models.PracticalInfo) response_models.PracticalInfoResponse {
+	out := response_models.PracticalInfoResponse{
+		ProvinceID:   i.ProvinceID.String(),
+		ProvinceName: i.Province.Name,
+		Connectivity: append([]string{}, i.Connectivity...),
+		Transport:    append([]string{}, i.Transport...),
+		Tipping:      i.Tipping,
+		Other:        append([]string{}, i.Other...),
+		UpdatedBy:    i.UpdatedBy,
+	}
+	if i.UpdatedAt > 0 {
+		out.UpdatedAt = time.Unix(i.UpdatedAt, 0).UTC().Format(time.RFC3339)
+	}
+	return out
+}
@@ -6,33 +6,43 @@ import (
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
+	"github.com/pgvector/pgvector-go"
 	"log"
 	"math/rand"
 	"net/url"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 	"vivu/internal/models/db_models"
 	"vivu/internal/models/request_models"
 	"vivu/internal/models/response_models"
 	"vivu/internal/repositories"
+	mem "vivu/pkg/memcache"
 	"vivu/pkg/utils"
 )
 
 type PromptServiceInterface interface {
 	CreatePrompt(ctx context.Context, prompt string) (string, error)
 	PromptInput(ctx context.Context, request request_models.CreateTagRequest) (string, error)
-	CreateNarrativeAIPlan(ctx context.Context, userPrompt string) (*response_models.TravelItinerary, error)
+	// CreateNarrativeAIPlan plans from free text for userId, whose exclusions and age apply.
+	CreateNarrativeAIPlan(ctx context.Context, userPrompt, userId string) (*response_models.TravelItinerary, error)
 	ExtractLocationFromPrompt(prompt string) []string
 
-	StartTravelQuiz(ctx context.Context, userID string) (*response_models.QuizResponse, error)
-	ProcessQuizAnswer(ctx context.Context, request request_models.QuizRequest) (*response_models.QuizResponse, error)
-	GeneratePersonalizedPlan(ctx context.Context, sessionID string) (*response_models.QuizResultResponse, error)
+	StartTravelQuiz(ctx context.Context, userID, presetID string) (*response_models.QuizResponse, error)
+	// ProcessQuizAnswer records answers in a quiz session of the user and moves it to the next step.
+	ProcessQuizAnswer(ctx context.Context, userID string, request request_models.QuizRequest) (*response_models.QuizResponse, error)
+	// KeepQuizAlive extends a quiz session of the user that has not expired yet.
+	KeepQuizAlive(ctx context.Context, sessionID, userID string) (*response_models.QuizResponse, error)
+	GeneratePersonalizedPlan(ctx context.Context, sessionID, userID string) (*response_models.QuizResultResponse, error)
 
-	GeneratePlanOnly(ctx context.Context, sessionID, userId string) (*response_models.PlanOnly, error)
-	GeneratePlanAndSave(ctx context.Context, sessionID string, userId uuid.UUID) (uuid.UUID, error)
+	GeneratePlanOnly(ctx context.Context, sessionID, userId, mode string) (*response_models.PlanOnly, error)
+	GeneratePlanAndSave(ctx context.Context, sessionID string, userId uuid.UUID, mode string) (uuid.UUID, error)
+	RegenerateJourneyDay(ctx context.Context, journeyId, userId string, dayNumber int) (*response_models.PlanOnly, error)
+
+	// ForgetAccount drops the account's quiz sessions, answers typed included.
+	ForgetAccount(accountID string) int
 }
 
 var vnLoc = func() *time.Location {
@@ -53,6 +63,26 @@ type planModelProfile struct {
 	TravelStyle  []string `json:"travel_style,omitempty"`
 	Interests    []string `json:"interests,omitempty"`
 	Tags         []string `json:"tags,omitempty"`
+	// relaxed, balanced or packed, from the travel preset
+	Pace string `json:"pace,omitempty"`
+	// Dietary needs every food stop has to meet, e.g. "vegetarian"
+	Dietary []string `json:"dietary,omitempty"`
+	// Hard constraints from the destination rules of the candidate provinces
+	DestinationRules []string `json:"destination_rules,omitempty"`
+	// Traveler's day window ("HH:MM"); activities start no earlier and end no later
+	DayStart string `json:"day_start"`
+	DayEnd   string `json:"day_end"`
+	// Who travels along, e.g. "child (age 2)"; pacing rules for them are in DestinationRules
+	Companions []string `json:"companions,omitempty"`
+	// Kinds of places that must never appear in the plan
+	MustNotInclude []string `json:"must_not_include,omitempty"`
+	// POIs the travel provider favours, and POIs it wants in every plan going their way
+	PreferredPOIIDs   []string `json:"preferred_poi_ids,omitempty"`
+	MustIncludePOIIDs []string `json:"must_include_poi_ids,omitempty"`
+	// POIs already on other days of the journey; never scheduled again
+	AlreadyUsedPOIIDs []string `json:"already_used_poi_ids,omitempty"`
+	// Set on a retry after a plan scored too low on diversity
+	DiversityHints []string `json:"diversity_hints,omitempty"`
 }
 
 type PromptService struct {
@@ -61,11 +91,21 @@ type PromptService struct {
 	aiService      utils.EmbeddingClientInterface
 	embededRepo    repositories.IPoiEmbededRepository
 	poisRepo       repositories.POIRepository
-	quizSessions   map[string]*QuizSession
-	sessionMutex   sync.RWMutex
+	quizSessions   mem.QuizSessionStore
 	matrixSvc      DistanceMatrixService
 	journeyRepo    repositories.JourneyRepository
 	accountSerivce AccountServiceInterface
+	rulesService   DestinationRuleServiceInterface
+	practicalInfo  PracticalInfoServiceInterface
+	planQuota      PlanQuotaServiceInterface
+	presets        TravelPresetServiceInterface
+	events         EventBus
+	warmup         *PlanWarmup                    // nil runs the retrieval on every plan
+	aiProfiles     AIClientResolverInterface      // nil generates every plan with aiService
+	policies       PlanningPolicyServiceInterface // nil applies no partner policy
+	diversityMin   float64
+	quizTTL        time.Duration // a quiz session expires this long after the last answer or heartbeat
+	quizWarning    time.Duration // clients warn the traveller this long before
 }
 
 func NewPromptService(
@@ -77,8 +117,17 @@ func NewPromptService(
 	matrixSvc DistanceMatrixService,
 	journeyRepo repositories.JourneyRepository,
 	accountService AccountServiceInterface,
+	rulesService DestinationRuleServiceInterface,
+	practicalInfo PracticalInfoServiceInterface,
+	planQuota PlanQuotaServiceInterface,
+	presets TravelPresetServiceInterface,
+	events EventBus,
+	warmup *PlanWarmup,
+	aiProfiles AIClientResolverInterface,
+	policies PlanningPolicyServiceInterface,
+	quizSessions mem.QuizSessionStore,
 ) PromptServiceInterface {
-	return &PromptService{
+	p := &PromptService{
 		poisService:    poisService,
 		tagService:     tagService,
 		aiService:      aiService,
@@ -87,30 +136,41 @@ func NewPromptService(
 		matrixSvc:      matrixSvc,
 		journeyRepo:    journeyRepo,
 		accountSerivce: accountService,
+		rulesService:   rulesService,
+		practicalInfo:  practicalInfo,
+		planQuota:      planQuota,
+		presets:        presets,
+		events:         events,
+		warmup:         warmup,
+		aiProfiles:     aiProfiles,
+		policies:       policies,
+		quizSessions:   quizSessions,
+		diversityMin:   diversityMinFromEnv(),
 	}
-}
-
-type QuizSession struct {
-	SessionID   string            `json:"session_id"`
-	UserID      string            `json:"user_id"`
-	Answers     map[string]string `json:"answers"`
-	CurrentStep int               `json:"current_step"`
-	CreatedAt   time.Time         `json:"created_at"`
-	UpdatedAt   time.Time         `json:"updated_at"`
+	p.quizTTL, p.quizWarning = quizSessionTimings()
+	if warmup != nil {
+		warmup.retriever = p
+	}
+	return p
 }
 
 // ---------- Plan generate & save ----------
 
-func (p *PromptService) GeneratePlanAndSave(ctx context.Context, sessionID string, userId uuid.UUID) (uuid.UUID, error) {
-	plan, err := p.GeneratePlanOnly(ctx, sessionID, userId.String())
+func (p *PromptService) GeneratePlanAndSave(ctx context.Context, sessionID string, userId uuid.UUID, mode string) (uuid.UUID, error) {
+	plan, err := p.GeneratePlanOnly(ctx, sessionID, userId.String(), mode)
 	if err != nil {
 		return uuid.Nil, err
 	}
 	resultUUid := p.savePlanAsyncWithRetry(sessionID, userId, plan)
 	if resultUUid == uuid.Nil {
-		return uuid.Nil, fmt.Errorf("failed to save plan after retries")
+		return uuid.Nil, utils.ErrDatabaseError.WithMessage("The plan was generated but could not be saved, please try again")
 	}
 
+	p.events.Publish(ctx, userId, EventPlanGenerated, PlanGeneratedEvent{
+		JourneyID:    resultUUid.String(),
+		Destination:  plan.Destination,
+		DurationDays: plan.Duration,
+	})
 	return resultUUid, nil
 }
 
@@ -134,12 +194,10 @@ func (p *PromptService) savePlanAsyncWithRetry(sessionID string, userId uuid.UUI
 	}
 
 	// Pull start date from the quiz session (VN tz); fallback to VN today
-	p.sessionMutex.RLock()
-	sess := p.quizSessions[sessionID]
-	p.sessionMutex.RUnlock()
+	sess, found := p.quizSessions.Get(sessionID)
 
 	startVN := time.Now().In(vnLoc)
-	if sess != nil {
+	if found {
 		if sd, ok := sess.Answers["start_date"]; ok {
 			if dt, err := parseDateVN(sd); err == nil {
 				startVN = dt
@@ -176,12 +234,13 @@ func (p *PromptService) savePlanAsyncWithRetry(sessionID string, userId uuid.UUI
 	return uuid.Nil
 }
 
-func (p *PromptService) GeneratePlanOnly(ctx context.Context, sessionID, userId string) (*response_models.PlanOnly, error) {
-	p.sessionMutex.RLock()
-	session, ok := p.quizSessions[sessionID]
-	p.sessionMutex.RUnlock()
-	if !ok {
-		return nil, fmt.Errorf("quiz session not found")
+func (p *PromptService) GeneratePlanOnly(ctx context.Context, sessionID, userId, mode string) (*response_models.PlanOnly, error) {
+	ctx, span := utils.Tracer.Start(ctx, "PromptService.GeneratePlanOnly")
+	defer span.End()
+
+	session, err := p.touchQuizSession(sessionID, userId, nil)
+	if err != nil {
+		return nil, err
 	}
 
 	startTime := time.Now()
@@ -202,23 +261,70 @@ func (p *PromptService) GeneratePlanOnly(ctx context.Context, sessionID, userId
 	fmt.Printf("userwithid %s have sub: %v", userId, userHaveSubcriptions)
 
 	if profile.Duration > 3 && userHaveSubcriptions == false {
-		return nil, fmt.Errorf("free users can only create up to 3-day itineraries. Please subscribe for longer trips")
+		return nil, utils.ErrUserDoNotHavePremium.WithMessage("Free users can only create up to 3-day itineraries. Please subscribe for longer trips")
 	}
+	// Reserve before the AI call so parallel requests cannot all pass the check; anything short
+	// of a produced plan gives the reservation back
+	var quotaMonth string
+	if !userHaveSubcriptions {
+		if quotaMonth, err = p.planQuota.ReservePlan(ctx, userId); err != nil {
+			return nil, err
+		}
+	}
+	produced := false
+	defer func() {
+		if !produced {
+			_ = p.planQuota.ReleasePlan(context.WithoutCancel(ctx), userId, quotaMonth)
+		}
+	}()
 
-	pois, err := p.findPersonalizedPOIs(ctx, profile)
+	ctx = p.withAIProfile(ctx, userId)
+
+	pois, signals, err := p.findPersonalizedPOIs(ctx, profile)
 	if err != nil || len(pois) == 0 {
-		return nil, fmt.Errorf("no relevant POIs")
+		return nil, utils.ErrPoorQualityInput.WithMessage("No places match this destination yet, try a nearby city")
+	}
+	companions := p.resolveCompanions(ctx, session.Answers, userId)
+	pois = filterForCompanions(pois, companions)
+	exclusions := p.resolveExclusions(ctx, session.Answers, userId)
+	policy := p.planningPolicy(ctx, userId)
+	policyExclusions := policy.Exclusions()
+	if pois = policyExclusions.Filter(exclusions.Filter(pois)); len(pois) == 0 {
+		return nil, utils.ErrPoorQualityInput.WithMessage("Every matching place is on your avoid list, try removing some of them")
 	}
+	// The provider's POIs of the destination go first, so they make it into the list below
+	pinned := p.policyPOIs(ctx, policy, dominantProvinces(pois))
+	pois = policy.Pin(pois, policyExclusions.Filter(exclusions.Filter(filterForCompanions(pinned, companions))))
 
 	var list []request_models.POISummary
+	provinceSet := make(map[string]struct{})
 	for _, poi := range pois {
 		list = append(list, request_models.POISummary{
 			ID: poi.ID.String(), Name: poi.Name, Category: p.categorizePOI(poi), Description: poi.Description,
 		})
+		if poi.ProvinceID != uuid.Nil {
+			provinceSet[poi.ProvinceID.String()] = struct{}{}
+		}
 		if len(list) >= 20 {
 			break
 		}
 	}
+	// Suggest a day per POI by area, so the model does not zig-zag across the destination
+	if dayOf := clusterPOIsByDay(pois[:len(list)], profile.Duration); dayOf != nil {
+		for i := range list {
+			list[i].SuggestedDay = dayOf[list[i].ID]
+		}
+	}
+	provinceIDs := make([]string, 0, len(provinceSet))
+	for id := range provinceSet {
+		provinceIDs = append(provinceIDs, id)
+	}
+	guardrails := p.rulesService.GuardrailsForProvinces(ctx, provinceIDs)
+	window, explicitWindow := p.resolveWorkingWindow(ctx, session.Answers, userId)
+	if explicitWindow {
+		guardrails = guardrails.WithWorkingWindow(window)
+	}
+	guardrails = guardrails.WithCompanions(companions)
 
 	dayCount := profile.Duration
 
@@ -258,21 +364,37 @@ func (p *PromptService) GeneratePlanOnly(ctx context.Context, sessionID, userId
 		TravelStyle:  append([]string{}, profile.TravelStyle...), // copy
 		Interests:    append([]string{}, profile.Interests...),   // copy
 		Tags:         tags,
+		Pace:         session.Answers["pace"],
+		Dietary:      parseCSVTags(session.Answers["dietary"]),
+
+		DestinationRules: guardrails.PromptLines(),
+		DayStart:         window.StartClock(),
+		DayEnd:           window.EndClock(),
+		Companions:       companions.Profile(),
+		MustNotInclude:   append(exclusions.Labels(), policyExclusions.Labels()...),
 	}
+	payload.PreferredPOIIDs, payload.MustIncludePOIIDs = policy.PromptIDs(list)
 
-	jsonPlan, err := p.aiService.GeneratePlanOnlyJSON(ctx, payload, list, dayCount)
+	plan, err := p.requestPlan(ctx, payload, list, dayCount)
 	if err != nil {
 		return nil, err
 	}
 
-	var plan response_models.PlanOnly
-	if err := json.Unmarshal([]byte(jsonPlan), &plan); err != nil {
-		return nil, fmt.Errorf("invalid plan json: %w", err)
+	// A monotonous plan gets one more try with targeted hints; the more varied of the two wins
+	candByID := make(map[string]*db_models.POI, len(pois))
+	for _, poi := range pois {
+		candByID[poi.ID.String()] = poi
 	}
-
-	if len(plan.Days) != dayCount {
-		return nil, fmt.Errorf("expected %d days, got %d", dayCount, len(plan.Days))
+	diversity := scorePlanDiversity(&plan, candByID, p.categorizePOI)
+	if p.diversityMin > 0 && diversity.Score < p.diversityMin && len(diversity.Hints) > 0 {
+		payload.DiversityHints = diversity.Hints
+		if retry, err := p.requestPlan(ctx, payload, list, dayCount); err != nil {
+			log.Printf("[plan] diversity retry failed, keeping first plan: %v", err)
+		} else if retryDiversity := scorePlanDiversity(&retry, candByID, p.categorizePOI); retryDiversity.Score > diversity.Score {
+			plan = retry
+		}
 	}
+	policyNotes := policy.EnforcePlan(&plan, list, window)
 
 	uniq := make(map[string]struct{})
 	for _, d := range plan.Days {
@@ -283,7 +405,7 @@ func (p *PromptService) GeneratePlanOnly(ctx context.Context, sessionID, userId
 		}
 	}
 	if len(uniq) == 0 {
-		return nil, fmt.Errorf("plan contains no poi ids")
+		return nil, utils.ErrUnexpectedBehaviorOfAI.Wrap(fmt.Errorf("plan contains no poi ids"))
 	}
 
 	ids := make([]string, 0, len(uniq))
@@ -293,31 +415,24 @@ func (p *PromptService) GeneratePlanOnly(ctx context.Context, sessionID, userId
 
 	dbPOIs, err := p.poisRepo.ListPoisByPoisId(ctx, ids)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load pois for enrichment: %w", err)
+		return nil, utils.ErrDatabaseError.Wrap(fmt.Errorf("failed to load pois for enrichment: %w", err))
 	}
 
-	respByID := make(map[string]response_models.POI, len(dbPOIs))
+	dbByID := make(map[string]*db_models.POI, len(dbPOIs))
 	for _, poi := range dbPOIs {
-		respByID[poi.ID.String()] = response_models.POI{
-			ID:           poi.ID.String(),
-			Name:         poi.Name,
-			Latitude:     poi.Latitude,
-			Longitude:    poi.Longitude,
-			Category:     poi.Category.Name,
-			OpeningHours: poi.OpeningHours,
-			ContactInfo:  poi.ContactInfo,
-			Address:      poi.Address,
-			PoiDetails: func() *response_models.PoiDetails {
-				if poi.Details.ID == uuid.Nil {
-					return nil
-				}
-				return &response_models.PoiDetails{
-					ID:          poi.Details.ID.String(),
-					Description: poi.Description,
-					Image:       poi.Details.Images,
-				}
-			}(),
+		dbByID[poi.ID.String()] = poi
+	}
+
+	// A POI is visited once per trip; repeats get the nearest unused candidate instead
+	dedupNotes := dedupePlan(&plan, pois, dbByID, nil)
+
+	respByID := make(map[string]response_models.POI, len(dbByID))
+	golden := make(map[string]bool)
+	for id, poi := range dbByID {
+		if isGoldenHourPOI(poi) {
+			golden[id] = true
 		}
+		respByID[id] = planPOIResponse(poi)
 	}
 
 	for di := range plan.Days {
@@ -333,6 +448,37 @@ func (p *PromptService) GeneratePlanOnly(ctx context.Context, sessionID, userId
 		}
 	}
 
+	// Viewpoints are best at sunrise/sunset; the rules below still win if they disagree
+	if len(golden) > 0 {
+		tripStart := time.Now().In(vnLoc)
+		if dt, err := parseDateVN(startStr); err == nil {
+			tripStart = dt
+		}
+		for _, m := range placeGoldenHour(&plan, tripStart, golden, window) {
+			log.Printf("[plan] %s", m)
+		}
+	}
+
+	// The model does not always follow the rules; repair what it got wrong before computing legs
+	plan.RuleAdjustments = append(dedupNotes, exclusions.EnforcePlan(&plan, dbByID)...)
+	plan.RuleAdjustments = append(plan.RuleAdjustments, policyExclusions.EnforcePlan(&plan, dbByID)...)
+	plan.RuleAdjustments = append(plan.RuleAdjustments, policyNotes...)
+	plan.RuleAdjustments = append(plan.RuleAdjustments, guardrails.EnforcePlan(&plan)...)
+	plan.Tips = companions.Tips()
+	plan.Diversity = scorePlanDiversity(&plan, dbByID, p.categorizePOI)
+
+	// Indoor fallback per day, built on the final time slots
+	buildRainyDays(&plan, dbByID, pois, planPOIResponse)
+
+	// Keep why each POI was picked, for GET /prompt/explain
+	interests := parseCSVTags(session.Answers["tags"])
+	for di := range plan.Days {
+		for ai := range plan.Days[di].Activities {
+			act := &plan.Days[di].Activities[ai]
+			act.Selection = selectionSignals(signals[act.MainPOIID], dbByID[act.MainPOIID], interests)
+		}
+	}
+
 	// Build distance matrix + legs as before
 	idList := make([]string, 0, len(respByID))
 	for id := range respByID {
@@ -343,15 +489,24 @@ func (p *PromptService) GeneratePlanOnly(ctx context.Context, sessionID, userId
 		poi := respByID[id]
 		points = append(points, MatrixPoint{ID: id, Lat: poi.Latitude, Lng: poi.Longitude})
 	}
-	distMat, err := p.matrixSvc.ComputeDistances(ctx, points)
-	if err == nil {
+	mode = NormalizeTravelMode(mode)
+	plan.TravelMode = mode
+	distMat, err := p.matrixSvc.ComputeDistances(ctx, points, mode)
+	if err != nil {
+		// A partial matrix is still returned when only some tiles failed
+		log.Printf("distance matrix: %v", err)
+	}
+	if distMat != nil {
 		plan.DistanceMatrix = make(response_models.DistanceMatrix, len(distMat))
 		for fromID, row := range distMat {
 			if _, ok := plan.DistanceMatrix[fromID]; !ok {
 				plan.DistanceMatrix[fromID] = map[string]response_models.MatrixEdge{}
 			}
 			for toID, edge := range row {
-				plan.DistanceMatrix[fromID][toID] = response_models.MatrixEdge{DistanceMeters: edge.DistanceMeters}
+				plan.DistanceMatrix[fromID][toID] = response_models.MatrixEdge{
+					DistanceMeters:  edge.DistanceMeters,
+					DurationSeconds: edge.DurationSeconds,
+				}
 			}
 		}
 	}
@@ -364,28 +519,108 @@ func (p *PromptService) GeneratePlanOnly(ctx context.Context, sessionID, userId
 			if from == nil || to == nil {
 				continue
 			}
-			var dPtr *int
+			var dPtr, tPtr *int
 			if plan.DistanceMatrix != nil {
 				if row, ok := plan.DistanceMatrix[from.ID]; ok {
 					if cell, ok := row[to.ID]; ok {
 						d := cell.DistanceMeters
+						t := cell.DurationSeconds
 						dPtr = &d
+						tPtr = &t
 						plan.Days[di].Activities[ai].DistanceToNextMeters = dPtr
+						plan.Days[di].Activities[ai].DurationToNextSeconds = tPtr
 					}
 				}
 			}
-			url := BuildGoogleDirURL(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+			url := BuildGoogleDirURL(from.Latitude, from.Longitude, to.Latitude, to.Longitude, mode)
 			plan.Days[di].Activities[ai].NextLegMapURL = url
 			from.DistanceToNextMeters = dPtr
+			from.DurationToNextSeconds = tPtr
 			from.NextLegMapURL = url
 		}
 	}
 
 	plan.CreatedAt = time.Now()
 	log.Printf("Enriched plan with distances and URLs in %.3f ms", time.Since(startTime).Seconds())
+
+	produced = true
 	return &plan, nil
 }
 
+// withAIProfile picks the AI client the account's plans are generated with for the rest of the
+// call. Embeddings always use the default client: the stored vectors came from its model.
+func (p *PromptService) withAIProfile(ctx context.Context, userId string) context.Context {
+	if p.aiProfiles == nil || aiClientFrom(ctx) != nil {
+		return ctx
+	}
+	client, profile := p.aiProfiles.Resolve(ctx, userId)
+	if profile != "" {
+		log.Printf("[plan] generating for %s with AI profile %s", userId, profile)
+	}
+	return WithAIClient(ctx, client)
+}
+
+// planClient is the client chosen for the call, or the default one.
+func (p *PromptService) planClient(ctx context.Context) utils.EmbeddingClientInterface {
+	if client := aiClientFrom(ctx); client != nil {
+		return client
+	}
+	return p.aiService
+}
+
+// requestPlan asks the model for a plan-only answer and checks it has dayCount days.
+func (p *PromptService) requestPlan(ctx context.Context, payload planModelProfile, list []request_models.POISummary, dayCount int) (response_models.PlanOnly, error) {
+	var plan response_models.PlanOnly
+	jsonPlan, err := p.planClient(ctx).GeneratePlanOnlyJSON(ctx, payload, list, dayCount)
+	if err != nil {
+		return plan, utils.ErrUnexpectedBehaviorOfAI.Wrap(err)
+	}
+	if err := json.Unmarshal([]byte(jsonPlan), &plan); err != nil {
+		return plan, utils.ErrUnexpectedBehaviorOfAI.Wrap(fmt.Errorf("invalid plan json: %w", err))
+	}
+	if len(plan.Days) != dayCount {
+		return plan, utils.ErrUnexpectedBehaviorOfAI.Wrap(fmt.Errorf("expected %d days, got %d", dayCount, len(plan.Days)))
+	}
+	return plan, nil
+}
+
+func planPOIResponse(poi *db_models.POI) response_models.POI {
+	out := response_models.POI{
+		ID:           poi.ID.String(),
+		Name:         poi.Name,
+		Latitude:     poi.Latitude,
+		Longitude:    poi.Longitude,
+		Category:     poi.Category.Name,
+		OpeningHours: poi.OpeningHours,
+		ContactInfo:  poi.ContactInfo,
+		Address:      poi.Address,
+	}
+	if poi.Details.ID != uuid.Nil {
+		out.PoiDetails = &response_models.PoiDetails{
+			ID:          poi.Details.ID.String(),
+			Description: poi.Description,
+			Image:       poi.Details.Images,
+		}
+	}
+	return out
+}
+
+// resolveWorkingWindow picks the quiz answer, then the account preference, then the default.
+// explicit is false only for the default, which the model is trusted to follow on its own.
+func (p *PromptService) resolveWorkingWindow(ctx context.Context, answers map[string]string, userId string) (WorkingWindow, bool) {
+	if w, ok := ParseWorkingWindow(answers["day_window"]); ok {
+		return w, true
+	}
+	if userId != "" {
+		if acc, err := p.accountSerivce.GetProfileInfo(ctx, userId); err == nil {
+			if w, ok := NewWorkingWindow(acc.DayStart, acc.DayEnd); ok {
+				return w, true
+			}
+		}
+	}
+	return DefaultWorkingWindow, false
+}
+
 // ---------- Utils ----------
 
 // parseCSVTags splits by comma, trims, and drops empties.
@@ -405,63 +640,89 @@ func parseCSVTags(s string) []string {
 	return out
 }
 
-func BuildGoogleDirURL(originLat, originLng, destLat, destLng float64) string {
+func BuildGoogleDirURL(originLat, originLng, destLat, destLng float64, mode string) string {
+	travelMode := NormalizeTravelMode(mode)
+	if travelMode == TravelModeCycling {
+		travelMode = "bicycling" // Google Maps naming
+	}
 	q := url.Values{}
 	q.Set("api", "1")
 	q.Set("origin", fmt.Sprintf("%f,%f", originLat, originLng))
 	q.Set("destination", fmt.Sprintf("%f,%f", destLat, destLng))
-	q.Set("travelmode", "driving")
+	q.Set("travelmode", travelMode)
 	return "https://www.google.com/maps/dir/?" + q.Encode()
 }
 
 // ---------- Quiz flow (reworked) ----------
 
-func (p *PromptService) StartTravelQuiz(ctx context.Context, userID string) (*response_models.QuizResponse, error) {
+func (p *PromptService) ForgetAccount(accountID string) int {
+	return p.quizSessions.DeleteOwnedBy(accountID)
+}
+
+func (p *PromptService) StartTravelQuiz(ctx context.Context, userID, presetID string) (*response_models.QuizResponse, error) {
 	sessionID := fmt.Sprintf("quiz_%s_%d", userID, time.Now().Unix())
 
-	session := &QuizSession{
+	now := time.Now()
+	session := mem.QuizSession{
 		SessionID:   sessionID,
 		UserID:      userID,
 		Answers:     make(map[string]string),
 		CurrentStep: 1,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		ExpiresAt:   now.Add(p.quizTTL),
 	}
-
-	p.sessionMutex.Lock()
-	if p.quizSessions == nil {
-		p.quizSessions = make(map[string]*QuizSession)
+	var prefilled map[string]string
+	if presetID != "" {
+		answers, err := p.presets.QuizAnswers(ctx, userID, presetID)
+		if err != nil {
+			return nil, err
+		}
+		prefilled = answers
+		session.Prefilled = make(map[string]bool, len(answers))
+		for key, value := range answers {
+			session.Answers[key] = value
+			session.Prefilled[key] = true
+		}
 	}
-	p.quizSessions[sessionID] = session
-	p.sessionMutex.Unlock()
+
+	p.quizSessions.Put(session, p.quizTTL+quizExpiredKeep)
 
 	questions := p.generateQuizQuestions()
 
-	return &response_models.QuizResponse{
-		Questions:    []request_models.QuizQuestion{questions[0]},
-		CurrentStep:  1,
-		TotalSteps:   len(questions),
-		SessionID:    sessionID,
-		IsComplete:   false,
-		NextEndpoint: "/api/quiz/answer",
-	}, nil
+	return p.stampQuizExpiry(&response_models.QuizResponse{
+		Questions:   []request_models.QuizQuestion{questions[0]},
+		CurrentStep: 1,
+		TotalSteps:  len(questions),
+		SessionID:   sessionID,
+		IsComplete:  false,
+		NextStep:    response_models.QuizStepAnswer,
+		Prefilled:   prefilled,
+	}, &session), nil
 }
 
-func (p *PromptService) ProcessQuizAnswer(ctx context.Context, request request_models.QuizRequest) (*response_models.QuizResponse, error) {
-	p.sessionMutex.Lock()
-	session, exists := p.quizSessions[request.SessionID]
-	if !exists {
-		p.sessionMutex.Unlock()
-		return nil, fmt.Errorf("quiz session not found")
-	}
-	for key, value := range request.Answers {
-		session.Answers[key] = strings.TrimSpace(value)
-	}
-	session.UpdatedAt = time.Now()
-	p.sessionMutex.Unlock()
-
+func (p *PromptService) ProcessQuizAnswer(ctx context.Context, userID string, request request_models.QuizRequest) (*response_models.QuizResponse, error) {
 	questions := p.generateQuizQuestions()
 
+	// The answers, step and completion move in the one update that keeps the session alive
+	var resp *response_models.QuizResponse
+	session, err := p.touchQuizSession(request.SessionID, userID, func(session *mem.QuizSession) error {
+		for key, value := range request.Answers {
+			session.Answers[key] = strings.TrimSpace(value)
+		}
+		resp = nextQuizStep(session, questions)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p.stampQuizExpiry(resp, &session), nil
+}
+
+// nextQuizStep validates the answer of the current step and moves the session on: it asks
+// the question again when the answer is invalid, the next one not answered by a preset, or
+// marks the quiz complete.
+func nextQuizStep(session *mem.QuizSession, questions []request_models.QuizQuestion) *response_models.QuizResponse {
 	// validate step input where helpful (dates/pax)
 	switch session.CurrentStep {
 	case 2: // start_date
@@ -475,12 +736,27 @@ func (p *PromptService) ProcessQuizAnswer(ctx context.Context, request request_m
 						Required: true,
 						Category: "dates",
 					}},
-					CurrentStep:  session.CurrentStep,
-					TotalSteps:   len(questions),
-					SessionID:    request.SessionID,
-					IsComplete:   false,
-					NextEndpoint: "/api/quiz/answer",
-				}, nil
+					CurrentStep: session.CurrentStep,
+					TotalSteps:  len(questions),
+					SessionID:   session.SessionID,
+					IsComplete:  false,
+					NextStep:    response_models.QuizStepAnswer,
+				}
+			}
+		}
+	case 6: // day_window (optional)
+		if dw := session.Answers["day_window"]; dw != "" && !strings.EqualFold(dw, dayWindowFromProfile) {
+			if _, ok := ParseWorkingWindow(dw); !ok {
+				q := dayWindowQuestion()
+				q.Question = "Please pick a preset or enter a window like 07:30-20:00 (at least 2 hours) ⏰"
+				return &response_models.QuizResponse{
+					Questions:   []request_models.QuizQuestion{q},
+					CurrentStep: session.CurrentStep,
+					TotalSteps:  len(questions),
+					SessionID:   session.SessionID,
+					IsComplete:  false,
+					NextStep:    response_models.QuizStepAnswer,
+				}
 			}
 		}
 	case 3: // end_date
@@ -494,41 +770,46 @@ func (p *PromptService) ProcessQuizAnswer(ctx context.Context, request request_m
 						Required: true,
 						Category: "dates",
 					}},
-					CurrentStep:  session.CurrentStep,
-					TotalSteps:   len(questions),
-					SessionID:    request.SessionID,
-					IsComplete:   false,
-					NextEndpoint: "/api/quiz/answer",
-				}, nil
+					CurrentStep: session.CurrentStep,
+					TotalSteps:  len(questions),
+					SessionID:   session.SessionID,
+					IsComplete:  false,
+					NextStep:    response_models.QuizStepAnswer,
+				}
 			}
 		}
 	}
 
 	if session.CurrentStep >= len(questions) {
+		session.Complete = true
 		return &response_models.QuizResponse{
-			Questions:    nil,
-			CurrentStep:  session.CurrentStep,
-			TotalSteps:   len(questions),
-			SessionID:    request.SessionID,
-			IsComplete:   true,
-			NextEndpoint: "/api/quiz/generate-plan",
-		}, nil
+			Questions:   nil,
+			CurrentStep: session.CurrentStep,
+			TotalSteps:  len(questions),
+			SessionID:   session.SessionID,
+			IsComplete:  true,
+			NextStep:    response_models.QuizStepPlan,
+		}
 	}
 
 	session.CurrentStep++
+	// Questions the preset already answered are skipped, the last one is always asked
+	for session.CurrentStep < len(questions) && session.Prefilled[questions[session.CurrentStep-1].ID] {
+		session.CurrentStep++
+	}
 	nextQuestion := questions[session.CurrentStep-1]
 
 	return &response_models.QuizResponse{
-		Questions:    []request_models.QuizQuestion{nextQuestion},
-		CurrentStep:  session.CurrentStep,
-		TotalSteps:   len(questions),
-		SessionID:    request.SessionID,
-		IsComplete:   false,
-		NextEndpoint: "/api/quiz/answer",
-	}, nil
+		Questions:   []request_models.QuizQuestion{nextQuestion},
+		CurrentStep: session.CurrentStep,
+		TotalSteps:  len(questions),
+		SessionID:   session.SessionID,
+		IsComplete:  false,
+		NextStep:    response_models.QuizStepAnswer,
+	}
 }
 
-// Only collect: destination, start_date, end_date, num_customers, budget
+// Only collect: destination, start_date, end_date, num_customers, budget, day_window, companions, avoid
 func (p *PromptService) generateQuizQuestions() []request_models.QuizQuestion {
 	return []request_models.QuizQuestion{
 		{
@@ -564,37 +845,67 @@ func (p *PromptService) generateQuizQuestions() []request_models.QuizQuestion {
 			ID:       "budget",
 			Question: "What is your budget per person per day? 💰",
 			Type:     "single_choice",
-			Options:  []string{"$0-30", "$31-70", "$71-150", "$151-300", "$300+"},
+			Options:  quizBudgetOptions,
 			Required: true,
 			Category: "budget",
 		},
+		dayWindowQuestion(),
+		companionsQuestion(),
+		avoidQuestion(),
+	}
+}
+
+var quizBudgetOptions = []string{"$0-30", "$31-70", "$71-150", "$151-300", "$300+"}
+
+// dayWindowFromProfile keeps whatever the account has saved (or the default).
+const dayWindowFromProfile = "Use my profile setting"
+
+func dayWindowQuestion() request_models.QuizQuestion {
+	return request_models.QuizQuestion{
+		ID:       "day_window",
+		Question: "When do you like your days to start and end? ⏰ (pick one or type e.g. 07:30-20:00)",
+		Type:     "single_choice",
+		Options: []string{
+			"Early bird (06:00-18:00)",
+			"Standard (09:00-21:00)",
+			"Night owl (11:00-23:30)",
+			dayWindowFromProfile,
+		},
+		Required: false,
+		Category: "schedule",
 	}
 }
 
 // ---------- Personalized plan (uses the new inputs) ----------
 
-func (p *PromptService) GeneratePersonalizedPlan(ctx context.Context, sessionID string) (*response_models.QuizResultResponse, error) {
-	p.sessionMutex.RLock()
-	session, exists := p.quizSessions[sessionID]
-	p.sessionMutex.RUnlock()
-	if !exists {
-		return nil, fmt.Errorf("quiz session not found")
+func (p *PromptService) GeneratePersonalizedPlan(ctx context.Context, sessionID, userID string) (*response_models.QuizResultResponse, error) {
+	session, err := p.touchQuizSession(sessionID, userID, nil)
+	if err != nil {
+		return nil, err
 	}
 
 	profile := p.createTravelProfile(session.Answers) // Duration computed from dates
 	personalizedPrompt := p.buildPersonalizedPrompt(session.Answers)
+	companions := p.resolveCompanions(ctx, session.Answers, session.UserID)
+	exclusions := p.resolveExclusions(ctx, session.Answers, session.UserID)
+	policy := p.planningPolicy(ctx, session.UserID)
+	lines := append(companions.PromptLines(), (*PlanGuardrails)(nil).WithCompanions(companions).PromptLines()...)
+	if lines = append(lines, exclusions.PromptLines()...); len(lines) > 0 {
+		personalizedPrompt += "\n" + strings.Join(lines, "\n") + "\n"
+	}
 
-	relevantPOIs, err := p.findPersonalizedPOIs(ctx, profile)
+	relevantPOIs, _, err := p.findPersonalizedPOIs(ctx, profile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find relevant POIs: %w", err)
 	}
 
-	itinerary, err := p.CreateNarrativeAIPlan(ctx, personalizedPrompt)
+	itinerary, err := p.createNarrativeAIPlan(ctx, personalizedPrompt, exclusions, policy)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate itinerary: %w", err)
 	}
 
-	recommendations := p.generatePersonalizedRecommendations(relevantPOIs, profile, session.Answers)
+	itinerary.GeneralTips = append(itinerary.GeneralTips, companions.Tips()...)
+	recommendations := p.generatePersonalizedRecommendations(policy.Exclusions().Filter(exclusions.Filter(filterForCompanions(relevantPOIs, companions))), profile, session.Answers)
 
 	return &response_models.QuizResultResponse{
 		SessionID:       sessionID,
@@ -701,6 +1012,12 @@ func (p *PromptService) buildPersonalizedPrompt(answers map[string]string) strin
 	b.WriteString(fmt.Sprintf("Duration: %d days\n", durationDays))
 	b.WriteString(fmt.Sprintf("Travelers: %s people\n", pax))
 	b.WriteString(fmt.Sprintf("Budget per person per day: %s\n", budget))
+	if pace := strings.TrimSpace(answers["pace"]); pace != "" {
+		b.WriteString(fmt.Sprintf("Pace: %s\n", pace))
+	}
+	if dietary := parseCSVTags(answers["dietary"]); len(dietary) > 0 {
+		b.WriteString(fmt.Sprintf("Dietary needs (every food stop must meet them): %s\n", strings.Join(dietary, ", ")))
+	}
 	b.WriteString("\nConstraints:\n- Use realistic times per activity\n- Cluster activities geographically when possible\n- Include food suggestions that match the budget\n- Prefer family-friendly options if party > 2 adults\n")
 	b.WriteString("\nReturn a detailed, structured plan (JSON acceptable) with days and activities.\n")
 
@@ -753,8 +1070,7 @@ func (p *PromptService) parseDestination(dest string) string {
 // callAIServiceWithStructuredPrompt, buildExplicitAIPrompt, tryConvertSingleToMultiDay,
 // generateAIPlanWithRetry, buildUltraExplicitAIPrompt, convertSingleToMultiDayJSON,
 // extractLocationFromActivity, generateStructuredPlanWithBetterFormat,
-// findRelevantPOIs + strategies, findPOIsByLocation, findPOIsByEmbedding,
-// findPOIsByKeywords, extractKeywords, mergePOIsWithoutDuplicates ] ...
+// findRelevantPOIs (hybrid search), dominantProvinces, findPOIsByLocation ] ...
 
 // NOTE: No functional edits required to these blocks for the new quiz inputs.
 
@@ -787,8 +1103,8 @@ func (p *PromptService) parseInterests(interests string) []string {
 	return strings.Split(interests, ",")
 }
 
-// findPersonalizedPOIs finds POIs that match the user's profile
-func (p *PromptService) findPersonalizedPOIs(ctx context.Context, profile response_models.TravelProfile) ([]*db_models.POI, error) {
+// findPersonalizedPOIs finds POIs that match the user's profile, with the retrieval signals of each by ID
+func (p *PromptService) findPersonalizedPOIs(ctx context.Context, profile response_models.TravelProfile) ([]*db_models.POI, map[string]*response_models.SelectionSignals, error) {
 	// Combine location-based and preference-based search
 	var searchTerms []string
 
@@ -801,8 +1117,12 @@ func (p *PromptService) findPersonalizedPOIs(ctx context.Context, profile respon
 	// Add travel style
 	searchTerms = append(searchTerms, profile.TravelStyle...)
 
-	// Use your existing multi-strategy POI finding
-	return p.findRelevantPOIs(ctx, strings.Join(searchTerms, " "))
+	// Use your existing multi-strategy POI finding; popular destinations are served warm
+	query := strings.Join(searchTerms, " ")
+	if p.warmup != nil {
+		return p.warmup.Retrieve(ctx, profile.Destination, query)
+	}
+	return p.rankRelevantPOIs(ctx, query)
 }
 
 // generatePersonalizedRecommendations creates tailored recommendations
@@ -962,8 +1282,6 @@ func (p *PromptService) CreatePrompt(ctx context.Context, prompt string) (string
 		return "", utils.ErrUnexpectedBehaviorOfAI
 	}
 
-	log.Printf("Creating prompt with vector: %v", vector)
-
 	// Get similar POIs based on vector similarity
 	poiEmbeddedIds, err := p.embededRepo.GetListOfPoiEmbededByVector(vector, nil)
 	if err != nil {
@@ -1020,14 +1338,20 @@ func (p *PromptService) PromptInput(ctx context.Context, request request_models.
 }
 
 // Enhanced CreateAIPlan method for narrative-style itineraries
-func (p *PromptService) CreateNarrativeAIPlan(ctx context.Context, userPrompt string) (*response_models.TravelItinerary, error) {
+func (p *PromptService) CreateNarrativeAIPlan(ctx context.Context, userPrompt, userId string) (*response_models.TravelItinerary, error) {
+	return p.createNarrativeAIPlan(ctx, userPrompt, p.resolveExclusions(ctx, nil, userId), p.planningPolicy(ctx, userId))
+}
+
+// createNarrativeAIPlan keeps excluded places out of the candidates and out of the final
+// itinerary, and puts the POIs of the partner's policy first and in the prompt.
+func (p *PromptService) createNarrativeAIPlan(ctx context.Context, userPrompt string, exclusions Exclusions, policy *PlanningPolicy) (*response_models.TravelItinerary, error) {
 	// Validate input
 	if strings.TrimSpace(userPrompt) == "" {
 		return nil, utils.ErrInvalidInput
 	}
 
 	startTime := time.Now()
-	log.Printf("ts: %d - Creating narrative AI plan for prompt: %s", time.Since(startTime), userPrompt)
+	log.Printf("ts: %d - Creating narrative AI plan", time.Since(startTime))
 
 	// Find relevant POIs
 	pois, err := p.findRelevantPOIs(ctx, userPrompt)
@@ -1035,9 +1359,12 @@ func (p *PromptService) CreateNarrativeAIPlan(ctx context.Context, userPrompt st
 		return nil, utils.ErrPOINotFound
 	}
 
-	if len(pois) == 0 {
+	policyExclusions := policy.Exclusions()
+	if pois = policyExclusions.Filter(exclusions.Filter(pois)); len(pois) == 0 {
 		return nil, utils.ErrPoorQualityInput
 	}
+	pinned := p.policyPOIs(ctx, policy, dominantProvinces(pois))
+	pois = policy.Pin(pois, policyExclusions.Filter(exclusions.Filter(pinned)))
 
 	// Extract location and day count
 	locations := p.ExtractLocationFromPrompt(userPrompt)
@@ -1048,8 +1375,13 @@ func (p *PromptService) CreateNarrativeAIPlan(ctx context.Context, userPrompt st
 
 	dayCount := extractDayCount(userPrompt)
 
+	planPrompt := userPrompt
+	if lines := policy.PromptLines(pois); len(lines) > 0 {
+		planPrompt += "\n" + strings.Join(lines, "\n") + "\n"
+	}
+
 	// Generate enhanced AI plan
-	rawResponse, err := p.generateNarrativeAIPlan(ctx, userPrompt, pois, dayCount, destination)
+	rawResponse, err := p.generateNarrativeAIPlan(ctx, planPrompt, pois, dayCount, destination)
 	if err != nil {
 		log.Printf("AI generation error: %v", err)
 		return nil, utils.ErrUnexpectedBehaviorOfAI
@@ -1060,6 +1392,17 @@ func (p *PromptService) CreateNarrativeAIPlan(ctx context.Context, userPrompt st
 
 	// Build narrative itinerary
 	itinerary := p.buildNarrativeItinerary(rawResponse, travelPOIs, destination, dayCount, userPrompt)
+	exclusions.EnforceItinerary(itinerary)
+	policyExclusions.EnforceItinerary(itinerary)
+
+	// Curated SIM, taxi and tipping info beats whatever the model would make up
+	poiIDs := make([]string, 0, len(pois))
+	for _, poi := range pois {
+		poiIDs = append(poiIDs, poi.ID.String())
+	}
+	if tips := p.practicalInfo.TipsForPOIs(ctx, poiIDs); len(tips) > 0 {
+		itinerary.GeneralTips = append(tips, itinerary.GeneralTips...)
+	}
 
 	return itinerary, nil
 }
@@ -1934,7 +2277,6 @@ func (p *PromptService) callAIServiceWithStructuredPrompt(ctx context.Context, u
 	prompt := p.buildExplicitAIPrompt(userPrompt, poiTextList, dayCount)
 
 	log.Printf("Sending structured prompt to AI for %d days", dayCount)
-	log.Printf("Prompt: %s", prompt)
 
 	return p.aiService.GenerateStructuredPlan(ctx, prompt, poiTextList, dayCount)
 }
@@ -2206,152 +2548,157 @@ Return JSON in this exact format:
 	return p.aiService.GenerateStructuredPlan(ctx, instruction, poiTextList, dayCount)
 }
 
-// Multi-strategy POI finding
+// findRelevantPOIs ranks POIs for a prompt. Vector similarity and full-text match are ranked and
+// fused in one query (reciprocal-rank fusion); POIs matched by location names add their own RRF term,
+// and the search is narrowed to the provinces those locations point at.
 func (p *PromptService) findRelevantPOIs(ctx context.Context, userPrompt string) ([]*db_models.POI, error) {
-	var allPOIs []*db_models.POI
+	pois, _, err := p.rankRelevantPOIs(ctx, userPrompt)
+	return pois, err
+}
+
+// rankRelevantPOIs is findRelevantPOIs keeping the retrieval signals of each POI, by ID.
+func (p *PromptService) rankRelevantPOIs(ctx context.Context, userPrompt string) ([]*db_models.POI, map[string]*response_models.SelectionSignals, error) {
+	const maxPOIs = 20
 
-	// Strategy 1: Location-based search
+	// Location-based candidates also tell us which province(s) the user means
+	var locationPOIs []*db_models.POI
 	locations := p.ExtractLocationFromPrompt(userPrompt)
 	if len(locations) > 0 {
 		log.Printf("Found locations in prompt: %v", locations)
-		locationPOIs, err := p.findPOIsByLocation(ctx, locations)
-		if err == nil && len(locationPOIs) > 0 {
-			allPOIs = append(allPOIs, locationPOIs...)
+		found, err := p.findPOIsByLocation(ctx, locations)
+		if err == nil && len(found) > 0 {
+			locationPOIs = found
 			log.Printf("Found %d POIs by location search", len(locationPOIs))
 		}
 	}
+	provinceIDs := dominantProvinces(locationPOIs)
 
-	// Strategy 2: Embedding-based search (your existing logic)
-	embeddingPOIs, err := p.findPOIsByEmbedding(ctx, userPrompt)
-	if err == nil && len(embeddingPOIs) > 0 {
-		allPOIs = p.mergePOIsWithoutDuplicates(allPOIs, embeddingPOIs)
-		log.Printf("Total POIs after embedding search: %d", len(allPOIs))
+	// Keyword-only ranking is still useful when the embedding provider is down
+	var vector *pgvector.Vector
+	if emb, err := p.aiService.GetEmbedding(ctx, userPrompt); err == nil {
+		vector = &emb
+	} else {
+		log.Printf("Embedding failed, hybrid search falls back to keywords only: %v", err)
 	}
 
-	// Strategy 3: Keyword-based fallback
-	if len(allPOIs) < 5 {
-		keywordPOIs, err := p.findPOIsByKeywords(ctx, userPrompt)
-		if err == nil && len(keywordPOIs) > 0 {
-			allPOIs = p.mergePOIsWithoutDuplicates(allPOIs, keywordPOIs)
-			log.Printf("Total POIs after keyword search: %d", len(allPOIs))
-		}
+	// The search binds what the traveler typed and its embedding; a slow run's capture is theirs
+	searchCtx := utils.WithDataPurpose(ctx, db_models.PurposeAIPrompt)
+	hits, err := p.embededRepo.HybridSearchPOIs(searchCtx, vector, userPrompt, repositories.HybridSearchOptions{
+		Limit:       maxPOIs * 2,
+		ProvinceIDs: provinceIDs,
+	})
+	if err != nil {
+		log.Printf("Hybrid POI search failed: %v", err)
 	}
 
-	// Limit results to avoid overwhelming the AI
-	if len(allPOIs) > 20 {
-		allPOIs = allPOIs[:20]
+	// Fuse the location ranking into the hybrid scores
+	scores := make(map[string]float64, len(hits)+len(locationPOIs))
+	signals := make(map[string]*response_models.SelectionSignals, len(hits)+len(locationPOIs))
+	for _, h := range hits {
+		scores[h.PoiID] = h.Score
+		signals[h.PoiID] = &response_models.SelectionSignals{VectorRank: h.VectorRank, KeywordRank: h.KeywordRank, Similarity: h.Similarity}
 	}
-
-	return allPOIs, nil
-}
-
-// Find POIs by location names - you'll need to implement this in your repository
-func (p *PromptService) findPOIsByLocation(ctx context.Context, locations []string) ([]*db_models.POI, error) {
-
-	var allPOIs []*db_models.POI
-
-	// You can implement a more sophisticated location search here
-	// For now, we'll search by POI names containing the location
-	pois, err := p.poisRepo.FindPOIsByLocationNames(ctx, locations)
-	if err == nil {
-		allPOIs = append(allPOIs, pois...)
+	for rank, poi := range locationPOIs {
+		id := poi.ID.String()
+		scores[id] += 1.0 / float64(repositories.RRFK+rank+1)
+		if signals[id] == nil {
+			signals[id] = &response_models.SelectionSignals{}
+		}
+		signals[id].LocationMatch = true
 	}
-
-	return allPOIs, nil
-}
-
-// Find POIs using embedding (your existing logic)
-func (p *PromptService) findPOIsByEmbedding(ctx context.Context, userPrompt string) ([]*db_models.POI, error) {
-	embedding, err := p.aiService.GetEmbedding(ctx, userPrompt)
-	if err != nil {
-		return nil, err
+	if len(scores) == 0 {
+		return nil, nil, nil
 	}
 
-	embeddedPois, err := p.embededRepo.GetListOfPoiEmbededByVector(embedding, nil)
-	if err != nil || len(embeddedPois) == 0 {
-		return nil, fmt.Errorf("no POIs found via embedding")
+	ids := make([]string, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
 	}
-
-	var poiIDs []string
-	for _, ep := range embeddedPois {
-		poiIDs = append(poiIDs, ep.PoiID)
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	if len(ids) > maxPOIs {
+		ids = ids[:maxPOIs]
 	}
 
-	result, err := p.poisRepo.ListPoisByPoisId(ctx, poiIDs)
-	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve POIs by IDs: %w", err)
+	byID := make(map[string]*db_models.POI, len(ids))
+	for _, poi := range locationPOIs {
+		byID[poi.ID.String()] = poi
 	}
-
-	if len(result) == 0 {
-		return nil, fmt.Errorf("no POIs found for the provided embedding")
+	var toLoad []string
+	for _, id := range ids {
+		if _, ok := byID[id]; !ok {
+			toLoad = append(toLoad, id)
+		}
 	}
-	return result, nil
-}
-
-// Find POIs by keywords (fallback method)
-func (p *PromptService) findPOIsByKeywords(ctx context.Context, userPrompt string) ([]*db_models.POI, error) {
-	keywords := p.extractKeywords(userPrompt)
-	if len(keywords) == 0 {
-		return nil, fmt.Errorf("no keywords found")
+	if len(toLoad) > 0 {
+		loaded, err := p.poisRepo.ListPoisByPoisId(ctx, toLoad)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to retrieve POIs by IDs: %w", err)
+		}
+		for _, poi := range loaded {
+			byID[poi.ID.String()] = poi
+		}
 	}
 
-	// You'll need to implement this in your repository
-	return p.poisRepo.SearchPOIsByKeywords(ctx, keywords)
+	result := make([]*db_models.POI, 0, len(ids))
+	kept := make(map[string]*response_models.SelectionSignals, len(ids))
+	for _, id := range ids {
+		if poi, ok := byID[id]; ok {
+			result = append(result, poi)
+			s := signals[id]
+			s.Score = scores[id]
+			s.Rank = len(result)
+			kept[id] = s
+		}
+	}
+	for _, s := range kept {
+		s.Candidates = len(result)
+	}
+	log.Printf("Hybrid search selected %d POIs", len(result))
+	return result, kept, nil
 }
 
-// Extract keywords from prompt
-func (p *PromptService) extractKeywords(prompt string) []string {
-	lower := strings.ToLower(prompt)
-
-	travelKeywords := []string{
-		"restaurant", "food", "eat", "dining", "cafe", "coffee",
-		"hotel", "accommodation", "stay", "lodge", "resort",
-		"temple", "pagoda", "church", "museum", "gallery",
-		"park", "garden", "nature", "mountain", "beach", "lake",
-		"shopping", "market", "mall", "souvenir",
-		"nightlife", "bar", "club", "entertainment",
-		"adventure", "hiking", "trekking", "cycling",
-		"culture", "history", "traditional", "local",
-
-		// Vietnamese keywords
-		"nhà hàng", "ăn", "quán", "cà phê",
-		"khách sạn", "nghỉ", "resort",
-		"chùa", "đền", "bảo tàng",
-		"công viên", "núi", "biển", "hồ",
-		"chợ", "mua sắm",
-		"văn hóa", "lịch sử", "truyền thống",
+// dominantProvinces returns the province(s) holding most of the location matches, so a prompt
+// naming "Da Lat" does not pull in POIs from elsewhere that just happen to mention it.
+func dominantProvinces(pois []*db_models.POI) []string {
+	counts := make(map[string]int)
+	best := 0
+	for _, poi := range pois {
+		if poi.ProvinceID == uuid.Nil {
+			continue
+		}
+		id := poi.ProvinceID.String()
+		counts[id]++
+		if counts[id] > best {
+			best = counts[id]
+		}
 	}
 
-	var foundKeywords []string
-	for _, keyword := range travelKeywords {
-		if strings.Contains(lower, keyword) {
-			foundKeywords = append(foundKeywords, keyword)
+	var out []string
+	for id, c := range counts {
+		if c == best {
+			out = append(out, id)
 		}
 	}
-
-	return foundKeywords
+	sort.Strings(out)
+	return out
 }
 
-// Merge POI lists without duplicates
-func (p *PromptService) mergePOIsWithoutDuplicates(existing, new []*db_models.POI) []*db_models.POI {
-	seen := make(map[string]bool)
-	var result []*db_models.POI
+// Find POIs by location names - you'll need to implement this in your repository
+func (p *PromptService) findPOIsByLocation(ctx context.Context, locations []string) ([]*db_models.POI, error) {
 
-	// Add existing POIs
-	for _, poi := range existing {
-		if !seen[poi.ID.String()] {
-			seen[poi.ID.String()] = true
-			result = append(result, poi)
-		}
-	}
+	var allPOIs []*db_models.POI
 
-	// Add new POIs
-	for _, poi := range new {
-		if !seen[poi.ID.String()] {
-			seen[poi.ID.String()] = true
-			result = append(result, poi)
-		}
+	// You can implement a more sophisticated location search here
+	// For now, we'll search by POI names containing the location
+	pois, err := p.poisRepo.FindPOIsByLocationNames(ctx, locations)
+	if err == nil {
+		allPOIs = append(allPOIs, pois...)
 	}
 
-	return result
+	return allPOIs, nil
 }
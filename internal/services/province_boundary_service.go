@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+const (
+	// DefaultBoundaryTolerance (degrees, about 500 m) keeps a country map light enough for phones.
+	DefaultBoundaryTolerance = 0.005
+	MaxBoundaryTolerance     = 0.5
+
+	provinceLocatorTTL = 10 * time.Minute
+)
+
+type ProvinceBoundaryServiceInterface interface {
+	// ListBoundaries returns every known outline simplified to tolerance degrees.
+	ListBoundaries(ctx context.Context, tolerance float64) (*response_models.ProvinceBoundaryCollection, error)
+	GetBoundary(ctx context.Context, provinceID string, tolerance float64) (*response_models.ProvinceBoundaryFeature, error)
+	SetBoundary(ctx context.Context, provinceID string, req request_models.SetProvinceBoundaryRequest, updatedBy string) (*response_models.ProvinceBoundaryFeature, error)
+	DeleteBoundary(ctx context.Context, provinceID string) error
+
+	// Locator returns the point lookup over the stored outlines, cached for a few minutes.
+	Locator(ctx context.Context) (*ProvinceLocator, error)
+}
+
+type ProvinceBoundaryService struct {
+	repo repositories.ProvinceBoundaryRepositoryInterface
+
+	mu       sync.Mutex
+	locator  *ProvinceLocator
+	loadedAt time.Time
+}
+
+func NewProvinceBoundaryService(repo repositories.ProvinceBoundaryRepositoryInterface) ProvinceBoundaryServiceInterface {
+	return &ProvinceBoundaryService{repo: repo}
+}
+
+// ProvinceLocator answers which province a point lies in. Provinces without an outline are
+// unknown to it: callers treat them as "cannot tell", not as a mismatch.
+type ProvinceLocator struct {
+	provinces []locatedProvince
+	byID      map[uuid.UUID]*locatedProvince
+}
+
+type locatedProvince struct {
+	id                             uuid.UUID
+	name                           string
+	source                         string
+	shape                          geoMultiPolygon
+	minLat, minLng, maxLat, maxLng float64
+}
+
+func (p *locatedProvince) contains(lat, lng float64) bool {
+	return lat >= p.minLat && lat <= p.maxLat && lng >= p.minLng && lng <= p.maxLng && p.shape.contains(lat, lng)
+}
+
+// Empty reports whether no province has an outline yet.
+func (l *ProvinceLocator) Empty() bool {
+	return l == nil || len(l.provinces) == 0
+}
+
+// Locate returns the province whose outline contains the point.
+func (l *ProvinceLocator) Locate(lat, lng float64) (uuid.UUID, string, bool) {
+	if l == nil {
+		return uuid.Nil, "", false
+	}
+	for i := range l.provinces {
+		if l.provinces[i].contains(lat, lng) {
+			return l.provinces[i].id, l.provinces[i].name, true
+		}
+	}
+	return uuid.Nil, "", false
+}
+
+// Contains reports whether the point is inside the province; known is false when the
+// province has no outline.
+func (l *ProvinceLocator) Contains(provinceID uuid.UUID, lat, lng float64) (inside, known bool) {
+	if l == nil {
+		return false, false
+	}
+	p, ok := l.byID[provinceID]
+	if !ok {
+		return false, false
+	}
+	return p.contains(lat, lng), true
+}
+
+// Name returns the name of a province with an outline.
+func (l *ProvinceLocator) Name(provinceID uuid.UUID) string {
+	if l == nil {
+		return ""
+	}
+	if p, ok := l.byID[provinceID]; ok {
+		return p.name
+	}
+	return ""
+}
+
+func (s *ProvinceBoundaryService) Locator(ctx context.Context) (*ProvinceLocator, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.locator != nil && time.Since(s.loadedAt) < provinceLocatorTTL {
+		return s.locator, nil
+	}
+
+	boundaries, err := s.repo.ListBoundaries(ctx)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	l := &ProvinceLocator{byID: make(map[uuid.UUID]*locatedProvince, len(boundaries))}
+	for _, b := range boundaries {
+		shape, err := parseBoundaryGeoJSON(b.Geometry)
+		if err != nil {
+			log.Printf("[province-boundary] skipping %s: %v", b.ProvinceID, err)
+			continue
+		}
+		l.provinces = append(l.provinces, locatedProvince{
+			id: b.ProvinceID, name: b.Province.Name, source: b.Source, shape: shape,
+			minLat: b.MinLat, minLng: b.MinLng, maxLat: b.MaxLat, maxLng: b.MaxLng,
+		})
+	}
+	for i := range l.provinces {
+		l.byID[l.provinces[i].id] = &l.provinces[i]
+	}
+
+	s.locator, s.loadedAt = l, time.Now()
+	return l, nil
+}
+
+func (s *ProvinceBoundaryService) forgetLocator() {
+	s.mu.Lock()
+	s.locator = nil
+	s.mu.Unlock()
+}
+
+func (s *ProvinceBoundaryService) ListBoundaries(ctx context.Context, tolerance float64) (*response_models.ProvinceBoundaryCollection, error) {
+	l, err := s.Locator(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := &response_models.ProvinceBoundaryCollection{
+		Type:     "FeatureCollection",
+		Features: make([]response_models.ProvinceBoundaryFeature, 0, len(l.provinces)),
+	}
+	for i := range l.provinces {
+		f, err := boundaryFeature(&l.provinces[i], tolerance)
+		if err != nil {
+			return nil, utils.ErrInternal.Wrap(err)
+		}
+		out.Features = append(out.Features, *f)
+	}
+	return out, nil
+}
+
+func (s *ProvinceBoundaryService) GetBoundary(ctx context.Context, provinceID string, tolerance float64) (*response_models.ProvinceBoundaryFeature, error) {
+	id, err := uuid.Parse(provinceID)
+	if err != nil {
+		return nil, utils.ErrInvalidInput.WithMessage("Invalid province ID")
+	}
+	l, err := s.Locator(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := l.byID[id]
+	if !ok {
+		return nil, utils.RecordNotFound.WithMessage("This province has no boundary yet")
+	}
+	f, err := boundaryFeature(p, tolerance)
+	if err != nil {
+		return nil, utils.ErrInternal.Wrap(err)
+	}
+	return f, nil
+}
+
+func (s *ProvinceBoundaryService) SetBoundary(ctx context.Context, provinceID string, req request_models.SetProvinceBoundaryRequest, updatedBy string) (*response_models.ProvinceBoundaryFeature, error) {
+	id, err := uuid.Parse(provinceID)
+	if err != nil {
+		return nil, utils.ErrInvalidInput.WithMessage("Invalid province ID")
+	}
+	shape, err := parseBoundaryGeoJSON(req.Geometry)
+	if err != nil {
+		return nil, utils.ErrInvalidInput.WithMessage(errBadGeometry.Error())
+	}
+	geometry, err := shape.geoJSON()
+	if err != nil {
+		return nil, utils.ErrInternal.Wrap(err)
+	}
+
+	minLat, minLng, maxLat, maxLng := shape.bounds()
+	boundary := &db_models.ProvinceBoundary{
+		ProvinceID: id,
+		Geometry:   datatypes.JSON(geometry),
+		MinLat:     minLat,
+		MinLng:     minLng,
+		MaxLat:     maxLat,
+		MaxLng:     maxLng,
+		Points:     shape.points(),
+		Source:     strings.TrimSpace(req.Source),
+		UpdatedBy:  updatedBy,
+	}
+	if err := s.repo.UpsertBoundary(ctx, boundary); err != nil {
+		// FK violation on an unknown province lands here too
+		return nil, utils.ErrDatabaseError
+	}
+	s.forgetLocator()
+
+	return s.GetBoundary(ctx, provinceID, 0)
+}
+
+func (s *ProvinceBoundaryService) DeleteBoundary(ctx context.Context, provinceID string) error {
+	if _, err := uuid.Parse(provinceID); err != nil {
+		return utils.ErrInvalidInput.WithMessage("Invalid province ID")
+	}
+	found, err := s.repo.DeleteByProvinceID(ctx, provinceID)
+	if err != nil {
+		return utils.ErrDatabaseError
+	}
+	if !found {
+		return utils.RecordNotFound.WithMessage("This province has no boundary")
+	}
+	s.forgetLocator()
+	return nil
+}
+
+func boundaryFeature(p *locatedProvince, tolerance float64) (*response_models.ProvinceBoundaryFeature, error) {
+	shape := p.shape.simplify(tolerance)
+	geometry, err := shape.geoJSON()
+	if err != nil {
+		return nil, err
+	}
+	return &response_models.ProvinceBoundaryFeature{
+		Type: "Feature",
+		ID:   p.id.String(),
+		BBox: [4]float64{p.minLng, p.minLat, p.maxLng, p.maxLat},
+		Properties: response_models.ProvinceBoundaryProperties{
+			ProvinceID: p.id.String(),
+			Name:       p.name,
+			Points:     shape.points(),
+			Source:     p.source,
+		},
+		Geometry: geometry,
+	}, nil
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+)
+
+// Province boundaries are kept as GeoJSON MultiPolygons: polygons of rings of [lng, lat]
+// positions, the first ring the outline and the others holes.
+type (
+	geoRing         [][2]float64
+	geoPolygon      []geoRing
+	geoMultiPolygon []geoPolygon
+)
+
+var errBadGeometry = errors.New("geometry must be a GeoJSON Polygon or MultiPolygon with closed rings of at least 4 positions")
+
+// parseBoundaryGeoJSON accepts a Polygon or MultiPolygon geometry, a Feature holding one or a
+// FeatureCollection of them, and returns everything as one MultiPolygon.
+func parseBoundaryGeoJSON(raw []byte) (geoMultiPolygon, error) {
+	var doc struct {
+		Type        string            `json:"type"`
+		Coordinates json.RawMessage   `json:"coordinates"`
+		Geometry    json.RawMessage   `json:"geometry"`
+		Features    []json.RawMessage `json:"features"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, errBadGeometry
+	}
+
+	var out geoMultiPolygon
+	switch doc.Type {
+	case "Polygon":
+		var p geoPolygon
+		if err := json.Unmarshal(doc.Coordinates, &p); err != nil {
+			return nil, errBadGeometry
+		}
+		out = geoMultiPolygon{p}
+	case "MultiPolygon":
+		if err := json.Unmarshal(doc.Coordinates, &out); err != nil {
+			return nil, errBadGeometry
+		}
+	case "Feature":
+		return parseBoundaryGeoJSON(doc.Geometry)
+	case "FeatureCollection":
+		for _, f := range doc.Features {
+			mp, err := parseBoundaryGeoJSON(f)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, mp...)
+		}
+	default:
+		return nil, errBadGeometry
+	}
+
+	if len(out) == 0 {
+		return nil, errBadGeometry
+	}
+	for _, polygon := range out {
+		if len(polygon) == 0 {
+			return nil, errBadGeometry
+		}
+		for _, ring := range polygon {
+			if len(ring) < 4 || ring[0] != ring[len(ring)-1] {
+				return nil, errBadGeometry
+			}
+			for _, p := range ring {
+				if p[0] < -180 || p[0] > 180 || p[1] < -90 || p[1] > 90 {
+					return nil, errBadGeometry
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+func (mp geoMultiPolygon) geoJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"type": "MultiPolygon", "coordinates": mp})
+}
+
+// bounds returns the bounding box as min lat, min lng, max lat, max lng.
+func (mp geoMultiPolygon) bounds() (minLat, minLng, maxLat, maxLng float64) {
+	minLat, minLng, maxLat, maxLng = math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, polygon := range mp {
+		for _, p := range polygon[0] {
+			minLng, maxLng = math.Min(minLng, p[0]), math.Max(maxLng, p[0])
+			minLat, maxLat = math.Min(minLat, p[1]), math.Max(maxLat, p[1])
+		}
+	}
+	return minLat, minLng, maxLat, maxLng
+}
+
+func (mp geoMultiPolygon) points() int {
+	n := 0
+	for _, polygon := range mp {
+		for _, ring := range polygon {
+			n += len(ring)
+		}
+	}
+	return n
+}
+
+// contains reports whether the point lies inside an outline and outside its holes.
+func (mp geoMultiPolygon) contains(lat, lng float64) bool {
+	for _, polygon := range mp {
+		if !polygon[0].contains(lat, lng) {
+			continue
+		}
+		inHole := false
+		for _, hole := range polygon[1:] {
+			if hole.contains(lat, lng) {
+				inHole = true
+				break
+			}
+		}
+		if !inHole {
+			return true
+		}
+	}
+	return false
+}
+
+// contains is the even-odd ray casting test; points exactly on an edge may go either way.
+func (r geoRing) contains(lat, lng float64) bool {
+	inside := false
+	for i, j := 0, len(r)-1; i < len(r); j, i = i, i+1 {
+		xi, yi := r[i][0], r[i][1]
+		xj, yj := r[j][0], r[j][1]
+		if (yi > lat) != (yj > lat) && lng < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// simplify drops vertices closer than tolerance (degrees) to the simplified outline
+// (Douglas-Peucker). Rings that would collapse keep their original shape for outlines and
+// are dropped for holes.
+func (mp geoMultiPolygon) simplify(tolerance float64) geoMultiPolygon {
+	if tolerance <= 0 {
+		return mp
+	}
+	out := make(geoMultiPolygon, 0, len(mp))
+	for _, polygon := range mp {
+		simplified := make(geoPolygon, 0, len(polygon))
+		for i, ring := range polygon {
+			s := ring.simplify(tolerance)
+			switch {
+			case len(s) >= 4:
+				simplified = append(simplified, s)
+			case i == 0:
+				simplified = append(simplified, ring)
+			}
+		}
+		out = append(out, simplified)
+	}
+	return out
+}
+
+func (r geoRing) simplify(tolerance float64) geoRing {
+	if len(r) <= 4 {
+		return r
+	}
+	keep := make([]bool, len(r))
+	keep[0], keep[len(r)-1] = true, true
+	// A closed ring starts and ends on the same point; split it at its farthest vertex so the
+	// first segment has two distinct ends.
+	far, farDist := 0, -1.0
+	for i := 1; i < len(r)-1; i++ {
+		if d := math.Hypot(r[i][0]-r[0][0], r[i][1]-r[0][1]); d > farDist {
+			far, farDist = i, d
+		}
+	}
+	keep[far] = true
+	douglasPeucker(r, 0, far, tolerance, keep)
+	douglasPeucker(r, far, len(r)-1, tolerance, keep)
+
+	out := make(geoRing, 0, len(r))
+	for i, p := range r {
+		if keep[i] {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func douglasPeucker(r geoRing, first, last int, tolerance float64, keep []bool) {
+	if last-first < 2 {
+		return
+	}
+	idx, maxDist := -1, tolerance
+	for i := first + 1; i < last; i++ {
+		if d := segmentDistance(r[i], r[first], r[last]); d > maxDist {
+			idx, maxDist = i, d
+		}
+	}
+	if idx < 0 {
+		return
+	}
+	keep[idx] = true
+	douglasPeucker(r, first, idx, tolerance, keep)
+	douglasPeucker(r, idx, last, tolerance, keep)
+}
+
+// segmentDistance is the planar distance from p to the segment a-b, in degrees.
+func segmentDistance(p, a, b [2]float64) float64 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	if dx == 0 && dy == 0 {
+		return math.Hypot(p[0]-a[0], p[1]-a[1])
+	}
+	t := ((p[0]-a[0])*dx + (p[1]-a[1])*dy) / (dx*dx + dy*dy)
+	t = math.Max(0, math.Min(1, t))
+	return math.Hypot(p[0]-(a[0]+t*dx), p[1]-(a[1]+t*dy))
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+type ProvinceGuideServiceInterface interface {
+	ListGuides(ctx context.Context) ([]response_models.ProvinceGuideResponse, error)
+	// GetGuide returns the landing-screen content of a province, its highlights resolved to
+	// POIs in the curated order.
+	GetGuide(ctx context.Context, provinceID string) (*response_models.ProvinceGuideResponse, error)
+	SetGuide(ctx context.Context, provinceID string, req request_models.SetProvinceGuideRequest, updatedBy string) (*response_models.ProvinceGuideResponse, error)
+	DeleteGuide(ctx context.Context, provinceID string) error
+}
+
+type ProvinceGuideService struct {
+	repo    repositories.ProvinceGuideRepositoryInterface
+	poiRepo repositories.POIRepository
+}
+
+func NewProvinceGuideService(repo repositories.ProvinceGuideRepositoryInterface, poiRepo repositories.POIRepository) ProvinceGuideServiceInterface {
+	return &ProvinceGuideService{repo: repo, poiRepo: poiRepo}
+}
+
+func (s *ProvinceGuideService) ListGuides(ctx context.Context) ([]response_models.ProvinceGuideResponse, error) {
+	guides, err := s.repo.ListGuides(ctx)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	var ids []string
+	for _, g := range guides {
+		ids = append(ids, g.HighlightPOIs...)
+	}
+	pois, err := s.highlightPOIs(ctx, ids)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+
+	out := make([]response_models.ProvinceGuideResponse, 0, len(guides))
+	for _, g := range guides {
+		out = append(out, toProvinceGuideResponse(g, pois))
+	}
+	return out, nil
+}
+
+func (s *ProvinceGuideService) GetGuide(ctx context.Context, provinceID string) (*response_models.ProvinceGuideResponse, error) {
+	if _, err := uuid.Parse(provinceID); err != nil {
+		return nil, utils.ErrInvalidInput
+	}
+	guide, err := s.repo.FindByProvinceID(ctx, provinceID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if guide == nil {
+		return nil, utils.RecordNotFound
+	}
+	pois, err := s.highlightPOIs(ctx, guide.HighlightPOIs)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	out := toProvinceGuideResponse(*guide, pois)
+	return &out, nil
+}
+
+func (s *ProvinceGuideService) SetGuide(ctx context.Context, provinceID string, req request_models.SetProvinceGuideRequest, updatedBy string) (*response_models.ProvinceGuideResponse, error) {
+	id, err := uuid.Parse(provinceID)
+	if err != nil {
+		return nil, utils.ErrInvalidInput
+	}
+
+	var highlights []string
+	seen := map[string]bool{}
+	for _, h := range req.HighlightPOIs {
+		if h = strings.ToLower(h); !seen[h] {
+			seen[h] = true
+			highlights = append(highlights, h)
+		}
+	}
+	pois, err := s.highlightPOIs(ctx, highlights)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	for _, h := range highlights {
+		poi, ok := pois[h]
+		if !ok {
+			return nil, utils.ErrInvalidInput.WithMessage("Highlight POI " + h + " does not exist or is still a draft")
+		}
+		if poi.ProvinceID != id {
+			return nil, utils.ErrInvalidInput.WithMessage("Highlight POI " + poi.Name + " is not in this province")
+		}
+	}
+
+	days := make([]int64, 0, len(req.TripDays))
+	for _, d := range req.TripDays {
+		days = append(days, int64(d))
+	}
+	slices.Sort(days)
+	days = slices.Compact(days)
+
+	guide := &db_models.ProvinceGuide{
+		ProvinceID:    id,
+		CoverImageURL: strings.TrimSpace(req.CoverImageURL),
+		Intro:         strings.TrimSpace(req.Intro),
+		HighlightPOIs: pq.StringArray(highlights),
+		TripDays:      pq.Int64Array(days),
+		UpdatedBy:     updatedBy,
+	}
+	if err := s.repo.UpsertGuide(ctx, guide); err != nil {
+		// FK violation on an unknown province lands here too
+		log.Printf("[province-guide] saving guide of %s: %v", provinceID, err)
+		return nil, utils.ErrDatabaseError
+	}
+
+	out := toProvinceGuideResponse(*guide, pois)
+	return &out, nil
+}
+
+func (s *ProvinceGuideService) DeleteGuide(ctx context.Context, provinceID string) error {
+	if _, err := uuid.Parse(provinceID); err != nil {
+		return utils.ErrInvalidInput
+	}
+	found, err := s.repo.DeleteByProvinceID(ctx, provinceID)
+	if err != nil {
+		return utils.ErrDatabaseError
+	}
+	if !found {
+		return utils.RecordNotFound
+	}
+	return nil
+}
+
+// highlightPOIs loads the live, reviewed POIs among ids by ID. Deleted or draft POIs are left
+// out, so a guide stops showing them without being edited.
+func (s *ProvinceGuideService) highlightPOIs(ctx context.Context, ids []string) (map[string]*db_models.POI, error) {
+	out := map[string]*db_models.POI{}
+	if len(ids) == 0 {
+		return out, nil
+	}
+	pois, err := s.poiRepo.ListPoisByPoisId(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pois {
+		if p.Status != db_models.POIStatusDraft {
+			out[p.ID.String()] = p
+		}
+	}
+	return out, nil
+}
+
+func toProvinceGuideResponse(g db_models.ProvinceGuide, pois map[string]*db_models.POI) response_models.ProvinceGuideResponse {
+	out := response_models.ProvinceGuideResponse{
+		ProvinceID:    g.ProvinceID.String(),
+		ProvinceName:  g.Province.Name,
+		CoverImageURL: g.CoverImageURL,
+		Intro:         g.Intro,
+		Highlights:    []response_models.POI{},
+		TripDays:      make([]int, 0, len(g.TripDays)),
+		UpdatedBy:     g.UpdatedBy,
+	}
+	for _, id := range g.HighlightPOIs {
+		// A POI moved to another province since it was picked is no highlight of this one
+		if p, ok := pois[id]; ok && p.ProvinceID == g.ProvinceID {
+			out.Highlights = append(out.Highlights, toPOIResponse(p))
+		}
+	}
+	for _, d := range g.TripDays {
+		out.TripDays = append(out.TripDays, int(d))
+	}
+	if g.UpdatedAt > 0 {
+		out.UpdatedAt = time.Unix(g.UpdatedAt, 0).UTC().Format(time.RFC3339)
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"vivu/internal/models/response_models"
+	mem "vivu/pkg/memcache"
+	"vivu/pkg/utils"
+)
+
+// quizExpiredKeep is how long an expired quiz session is remembered, so the client is told it
+// expired (and restarts) rather than that it never existed.
+const quizExpiredKeep = 24 * time.Hour
+
+// quizSessionTimings reads QUIZ_SESSION_TTL (default 30m), how long a quiz session lives after
+// the last answer or heartbeat, and QUIZ_SESSION_WARNING (default 5m), how long before the
+// expiry clients are asked to warn the traveller.
+func quizSessionTimings() (ttl, warning time.Duration) {
+	ttl, warning = 30*time.Minute, 5*time.Minute
+	if d, err := time.ParseDuration(os.Getenv("QUIZ_SESSION_TTL")); err == nil && d > 0 {
+		ttl = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("QUIZ_SESSION_WARNING")); err == nil && d >= 0 && d < ttl {
+		warning = d
+	}
+	return ttl, warning
+}
+
+// touchQuizSession keeps a session of the user alive for another TTL and returns it; change,
+// when set, is applied in the same update so answers from two instances never interleave.
+// Sessions of someone else are reported as not found. An expired session is left as it is
+// (and remembered for quizExpiredKeep) so the client can be told it expired.
+func (p *PromptService) touchQuizSession(sessionID, userID string, change func(*mem.QuizSession) error) (mem.QuizSession, error) {
+	session, err := p.quizSessions.Update(sessionID, p.quizTTL+quizExpiredKeep, func(session *mem.QuizSession) error {
+		if session.UserID != userID {
+			return utils.ErrQuizSessionNotFound
+		}
+		now := time.Now()
+		if !now.Before(session.ExpiresAt) {
+			return utils.ErrQuizSessionExpired.WithData(response_models.QuizExpired{
+				SessionID: sessionID,
+				ExpiredAt: session.ExpiresAt.Unix(),
+			})
+		}
+		session.UpdatedAt = now
+		session.ExpiresAt = now.Add(p.quizTTL)
+		if change != nil {
+			return change(session)
+		}
+		return nil
+	})
+	if errors.Is(err, mem.ErrNoQuizSession) {
+		return mem.QuizSession{}, utils.ErrQuizSessionNotFound
+	}
+	return session, err
+}
+
+// stampQuizExpiry tells the client when the session expires and when to start warning.
+func (p *PromptService) stampQuizExpiry(resp *response_models.QuizResponse, session *mem.QuizSession) *response_models.QuizResponse {
+	resp.ExpiresAt = session.ExpiresAt.Unix()
+	resp.WarnAt = session.ExpiresAt.Add(-p.quizWarning).Unix()
+	return resp
+}
+
+// KeepQuizAlive extends the session of a traveller still busy with a question. Clients send it
+// on activity (typing, scrolling the options), not on a timer, so an abandoned quiz expires.
+func (p *PromptService) KeepQuizAlive(ctx context.Context, sessionID, userID string) (*response_models.QuizResponse, error) {
+	session, err := p.touchQuizSession(sessionID, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	next := response_models.QuizStepAnswer
+	if session.Complete {
+		next = response_models.QuizStepPlan
+	}
+	return p.stampQuizExpiry(&response_models.QuizResponse{
+		CurrentStep: session.CurrentStep,
+		TotalSteps:  len(p.generateQuizQuestions()),
+		SessionID:   sessionID,
+		IsComplete:  session.Complete,
+		NextStep:    next,
+	}, &session), nil
+}
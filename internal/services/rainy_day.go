@@ -0,0 +1,144 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/response_models"
+)
+
+// Whole words/phrases (diacritics folded) that mark a POI as sheltered or as weather-exposed.
+// A POI matching neither is kept in the rainy-day plan as it is.
+var (
+	indoorKeywords = []string{
+		"museum", "bao tang", "gallery", "exhibition", "trien lam", "cafe", "coffee", "ca phe",
+		"restaurant", "nha hang", "quan an", "food court", "shopping", "mall", "trung tam thuong mai",
+		"cinema", "rap chieu phim", "theater", "theatre", "nha hat", "spa", "massage", "aquarium",
+		"bookstore", "nha sach", "cooking class", "indoor", "pagoda", "chua", "temple", "den",
+		"church", "nha tho", "covered market", "workshop",
+	}
+	outdoorKeywords = []string{
+		"beach", "bai bien", "bien", "park", "cong vien", "waterfall", "thac", "lake", "mountain", "nui",
+		"hiking", "trekking", "island", "dao", "viewpoint", "lookout", "garden", "vuon", "river", "song",
+		"boat", "cruise", "zoo", "thao cam vien", "camping", "deo", "doi", "national park", "outdoor",
+		"ruong bac thang", "rice terrace", "canyon", "kayak",
+	}
+)
+
+// weatherExposure returns -1 for indoor POIs, 1 for outdoor ones and 0 when unknown.
+func weatherExposure(p *db_models.POI) int {
+	text := poiKeywordText(p)
+	// Outdoor wins: a "park cafe" still gets you wet on the way
+	switch {
+	case hasKeyword(text, outdoorKeywords) || isGoldenHourPOI(p):
+		return 1
+	case hasKeyword(text, indoorKeywords):
+		return -1
+	default:
+		return 0
+	}
+}
+
+// poiKeywordText is the POI's name, category and tags folded to lower-case words.
+func poiKeywordText(p *db_models.POI) string {
+	parts := []string{p.Name, p.Category.Name}
+	for _, t := range p.Tags {
+		if t != nil {
+			parts = append(parts, t.EnName, t.ViName)
+		}
+	}
+	return keywordText(parts...)
+}
+
+// keywordText folds parts to lower-case unaccented words separated by single spaces.
+func keywordText(parts ...string) string {
+	folded := lookupKey(strings.Join(parts, " "))
+	return strings.Join(strings.FieldsFunc(folded, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	}), " ")
+}
+
+// hasKeyword reports whether one of words appears as whole words in text (see poiKeywordText).
+func hasKeyword(text string, words []string) bool {
+	text = " " + text + " "
+	for _, w := range words {
+		if strings.Contains(text, " "+w+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// buildRainyDays fills Day.RainyDay with the day's slots where every outdoor stop is replaced by
+// the nearest indoor candidate not already used anywhere in the plan. Days with nothing to swap
+// get no rainy plan.
+func buildRainyDays(plan *response_models.PlanOnly, byID map[string]*db_models.POI, candidates []*db_models.POI, toResponse func(*db_models.POI) response_models.POI) {
+	used := make(map[string]bool)
+	for _, d := range plan.Days {
+		for _, a := range d.Activities {
+			used[a.MainPOIID] = true
+		}
+	}
+	var indoor []*db_models.POI
+	for _, c := range candidates {
+		if !used[c.ID.String()] && weatherExposure(c) < 0 {
+			indoor = append(indoor, c)
+		}
+	}
+
+	for di := range plan.Days {
+		day := &plan.Days[di]
+		rainy := make([]response_models.PlanOnlyActivity, 0, len(day.Activities))
+		swapped := false
+
+		for _, a := range day.Activities {
+			poi := byID[a.MainPOIID]
+			if poi == nil || weatherExposure(poi) <= 0 {
+				rainy = append(rainy, response_models.PlanOnlyActivity{
+					StartTime: a.StartTime, EndTime: a.EndTime, MainPOIID: a.MainPOIID, MainPOI: a.MainPOI, Note: a.Note,
+				})
+				continue
+			}
+
+			best := -1
+			bestDist := math.MaxFloat64
+			for i, c := range indoor {
+				if c == nil {
+					continue
+				}
+				dist := 0.0
+				if hasCoords(poi.Latitude, poi.Longitude) && hasCoords(c.Latitude, c.Longitude) {
+					dist = haversineMeters(poi.Latitude, poi.Longitude, c.Latitude, c.Longitude)
+				}
+				if dist < bestDist {
+					best, bestDist = i, dist
+				}
+			}
+			if best < 0 {
+				rainy = append(rainy, response_models.PlanOnlyActivity{
+					StartTime: a.StartTime, EndTime: a.EndTime, MainPOIID: a.MainPOIID, MainPOI: a.MainPOI,
+					Note: "Outdoor: check the weather, no indoor alternative found",
+				})
+				continue
+			}
+
+			alt := indoor[best]
+			indoor[best] = nil
+			resp := toResponse(alt)
+			rainy = append(rainy, response_models.PlanOnlyActivity{
+				StartTime: a.StartTime,
+				EndTime:   a.EndTime,
+				MainPOIID: alt.ID.String(),
+				MainPOI:   &resp,
+				Note:      fmt.Sprintf("Rainy-day swap for %s", poi.Name),
+			})
+			swapped = true
+		}
+
+		if swapped {
+			day.RainyDay = rainy
+		}
+	}
+}
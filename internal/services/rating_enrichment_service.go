@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+type RatingEnrichmentServiceInterface interface {
+	SetExternalRef(ctx context.Context, poiID string, req request_models.SetPOIExternalRefRequest) (*response_models.ExternalRating, error)
+	RefreshPOI(ctx context.Context, poiID string) ([]response_models.ExternalRating, error)
+	// RefreshStale refreshes one batch of outdated snapshots and returns how many were attempted.
+	RefreshStale(ctx context.Context) (int, error)
+
+	Start()
+	Stop()
+}
+
+var ratingAttribution = map[string]string{
+	db_models.ExternalSourceGoogle:      "Rating from Google",
+	db_models.ExternalSourceTripAdvisor: "Rating from Tripadvisor",
+}
+
+type RatingEnrichmentService struct {
+	refRepo   repositories.POIExternalRefRepositoryInterface
+	poiRepo   repositories.POIRepository
+	providers map[string]RatingProvider
+
+	interval   time.Duration // how often the job wakes up
+	maxAge     time.Duration // snapshots older than this are refreshed
+	retryAfter time.Duration // wait before retrying a listing that failed
+	batchSize  int
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// NewRatingEnrichmentService reads RATING_REFRESH_INTERVAL (default 1h) and RATING_MAX_AGE
+// (default 7 days). Sources without credentials are kept but never refreshed.
+func NewRatingEnrichmentService(refRepo repositories.POIExternalRefRepositoryInterface, poiRepo repositories.POIRepository, providers map[string]RatingProvider) RatingEnrichmentServiceInterface {
+	s := &RatingEnrichmentService{
+		refRepo:    refRepo,
+		poiRepo:    poiRepo,
+		providers:  providers,
+		interval:   time.Hour,
+		maxAge:     7 * 24 * time.Hour,
+		retryAfter: 6 * time.Hour,
+		batchSize:  50,
+		stop:       make(chan struct{}),
+	}
+	if d, err := time.ParseDuration(os.Getenv("RATING_REFRESH_INTERVAL")); err == nil && d > 0 {
+		s.interval = d
+	}
+	if d, err := time.ParseDuration(os.Getenv("RATING_MAX_AGE")); err == nil && d > 0 {
+		s.maxAge = d
+	}
+	return s
+}
+
+func (s *RatingEnrichmentService) SetExternalRef(ctx context.Context, poiID string, req request_models.SetPOIExternalRefRequest) (*response_models.ExternalRating, error) {
+	id, err := uuid.Parse(poiID)
+	if err != nil {
+		return nil, utils.ErrInvalidInput
+	}
+	poi, err := s.poiRepo.GetByIDWithDetails(ctx, poiID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if poi == nil {
+		return nil, utils.ErrPOINotFound
+	}
+
+	ref := &db_models.POIExternalRef{
+		POIID:      id,
+		Source:     req.Source,
+		ExternalID: req.ExternalID,
+		URL:        req.URL,
+	}
+	if err := s.refRepo.UpsertRef(ctx, ref); err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+
+	// Fetch right away so the admin sees whether the ID is right
+	refs, err := s.refRepo.ListRefsByPOI(ctx, id)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	for i := range refs {
+		if refs[i].Source == req.Source {
+			s.refresh(ctx, &refs[i])
+			out := ToExternalRating(refs[i])
+			return &out, nil
+		}
+	}
+	return nil, utils.ErrDatabaseError
+}
+
+func (s *RatingEnrichmentService) RefreshPOI(ctx context.Context, poiID string) ([]response_models.ExternalRating, error) {
+	id, err := uuid.Parse(poiID)
+	if err != nil {
+		return nil, utils.ErrInvalidInput
+	}
+	refs, err := s.refRepo.ListRefsByPOI(ctx, id)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if len(refs) == 0 {
+		return nil, utils.RecordNotFound
+	}
+
+	out := make([]response_models.ExternalRating, 0, len(refs))
+	for i := range refs {
+		s.refresh(ctx, &refs[i])
+		out = append(out, ToExternalRating(refs[i]))
+	}
+	return out, nil
+}
+
+func (s *RatingEnrichmentService) RefreshStale(ctx context.Context) (int, error) {
+	if len(s.providers) == 0 {
+		return 0, nil
+	}
+	now := time.Now()
+	refs, err := s.refRepo.ListStaleRefs(ctx, now.Add(-s.maxAge).Unix(), now.Add(-s.retryAfter).Unix(), s.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	attempted := 0
+	for i := range refs {
+		if _, ok := s.providers[refs[i].Source]; !ok {
+			continue
+		}
+		s.refresh(ctx, &refs[i])
+		attempted++
+	}
+	return attempted, nil
+}
+
+// refresh fetches one snapshot and stores it. On failure the previous snapshot is kept
+// (an old rating with its timestamp beats no rating) and the error is recorded.
+func (s *RatingEnrichmentService) refresh(ctx context.Context, ref *db_models.POIExternalRef) {
+	provider, ok := s.providers[ref.Source]
+	if !ok {
+		return
+	}
+
+	snap, err := provider.FetchRating(ctx, ref.ExternalID)
+	if err != nil {
+		log.Printf("[ratings] %s %s (poi %s): %v", ref.Source, ref.ExternalID, ref.POIID, err)
+		ref.LastError = err.Error()
+	} else {
+		now := time.Now().Unix()
+		ref.Rating = snap.Rating
+		ref.ReviewCount = snap.ReviewCount
+		ref.FetchedAt = &now
+		ref.LastError = ""
+		if snap.URL != "" {
+			ref.URL = snap.URL
+		}
+	}
+
+	if err := s.refRepo.SaveSnapshot(ctx, ref); err != nil {
+		log.Printf("[ratings] failed to save snapshot for %s: %v", ref.ID, err)
+	}
+}
+
+// Start runs the refresh job in the background until Stop is called.
+func (s *RatingEnrichmentService) Start() {
+	if len(s.providers) == 0 {
+		log.Println("[ratings] no rating provider configured, enrichment job disabled")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), s.interval)
+			if n, err := s.RefreshStale(ctx); err != nil {
+				log.Printf("[ratings] refresh failed: %v", err)
+			} else if n > 0 {
+				log.Printf("[ratings] refreshed %d rating snapshots", n)
+			}
+			cancel()
+
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (s *RatingEnrichmentService) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// ToExternalRating maps a stored snapshot to its API shape.
+func ToExternalRating(ref db_models.POIExternalRef) response_models.ExternalRating {
+	out := response_models.ExternalRating{
+		Source:      ref.Source,
+		Attribution: ratingAttribution[ref.Source],
+		Rating:      ref.Rating,
+		ReviewCount: ref.ReviewCount,
+		URL:         ref.URL,
+	}
+	if ref.FetchedAt != nil {
+		out.FetchedAt = time.Unix(*ref.FetchedAt, 0).UTC().Format(time.RFC3339)
+	}
+	return out
+}
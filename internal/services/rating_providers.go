@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"vivu/internal/models/db_models"
+	"vivu/pkg/utils"
+)
+
+// RatingSnapshot is what a third-party site says about a place right now.
+type RatingSnapshot struct {
+	Rating      *float64
+	ReviewCount *int
+	URL         string
+}
+
+// RatingProvider fetches the public rating of one listing on a third-party site.
+type RatingProvider interface {
+	Source() string
+	FetchRating(ctx context.Context, externalID string) (RatingSnapshot, error)
+}
+
+// NewRatingProvidersFromEnv enables every provider that has credentials:
+// GOOGLE_PLACES_API_KEY and TRIPADVISOR_API_KEY.
+func NewRatingProvidersFromEnv() map[string]RatingProvider {
+	client := utils.NewTracedHTTPClient(10 * time.Second)
+	out := make(map[string]RatingProvider)
+	if key := os.Getenv("GOOGLE_PLACES_API_KEY"); key != "" {
+		out[db_models.ExternalSourceGoogle] = &googlePlacesProvider{apiKey: key, http: client}
+	}
+	if key := os.Getenv("TRIPADVISOR_API_KEY"); key != "" {
+		out[db_models.ExternalSourceTripAdvisor] = &tripAdvisorProvider{apiKey: key, http: client}
+	}
+	return out
+}
+
+// ---------- Google Places (New) ----------
+
+type googlePlacesProvider struct {
+	apiKey string
+	http   *http.Client
+}
+
+func (g *googlePlacesProvider) Source() string { return db_models.ExternalSourceGoogle }
+
+// FetchRating uses Place Details with a field mask, so only the rating fields are billed.
+func (g *googlePlacesProvider) FetchRating(ctx context.Context, placeID string) (RatingSnapshot, error) {
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://places.googleapis.com/v1/places/"+url.PathEscape(placeID), nil)
+	req.Header.Set("X-Goog-Api-Key", g.apiKey)
+	req.Header.Set("X-Goog-FieldMask", "rating,userRatingCount,googleMapsUri")
+
+	var payload struct {
+		Rating          *float64 `json:"rating"`
+		UserRatingCount *int     `json:"userRatingCount"`
+		GoogleMapsURI   string   `json:"googleMapsUri"`
+	}
+	if err := getRatingJSON(g.http, req, &payload); err != nil {
+		return RatingSnapshot{}, fmt.Errorf("google places: %w", err)
+	}
+	return RatingSnapshot{Rating: payload.Rating, ReviewCount: payload.UserRatingCount, URL: payload.GoogleMapsURI}, nil
+}
+
+// ---------- Tripadvisor Content API ----------
+
+type tripAdvisorProvider struct {
+	apiKey string
+	http   *http.Client
+}
+
+func (t *tripAdvisorProvider) Source() string { return db_models.ExternalSourceTripAdvisor }
+
+func (t *tripAdvisorProvider) FetchRating(ctx context.Context, locationID string) (RatingSnapshot, error) {
+	u := fmt.Sprintf("https://api.content.tripadvisor.com/api/v1/location/%s/details?key=%s&language=en",
+		url.PathEscape(locationID), url.QueryEscape(t.apiKey))
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	req.Header.Set("Accept", "application/json")
+
+	// Tripadvisor returns numbers as strings
+	var payload struct {
+		Rating     string `json:"rating"`
+		NumReviews string `json:"num_reviews"`
+		WebURL     string `json:"web_url"`
+	}
+	if err := getRatingJSON(t.http, req, &payload); err != nil {
+		return RatingSnapshot{}, fmt.Errorf("tripadvisor: %w", err)
+	}
+
+	snap := RatingSnapshot{URL: payload.WebURL}
+	if v, err := strconv.ParseFloat(payload.Rating, 64); err == nil {
+		snap.Rating = &v
+	}
+	if v, err := strconv.Atoi(payload.NumReviews); err == nil {
+		snap.ReviewCount = &v
+	}
+	return snap, nil
+}
+
+func getRatingJSON(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("bad status: %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+)
+
+// InvoiceConfig is what the seller prints on invoices and receipts.
+type InvoiceConfig struct {
+	SellerName    string
+	SellerAddress string
+	SellerTaxCode string // mã số thuế
+	SellerEmail   string
+	VATRate       float64 // percent included in every price; 0 prints no VAT lines
+}
+
+// InvoiceConfigFromEnv reads INVOICE_SELLER_NAME (default "Vivu"), INVOICE_SELLER_ADDRESS,
+// INVOICE_SELLER_TAX_CODE, INVOICE_SELLER_EMAIL and INVOICE_VAT_RATE (percent, e.g. 10).
+func InvoiceConfigFromEnv() InvoiceConfig {
+	cfg := InvoiceConfig{
+		SellerName:    "Vivu",
+		SellerAddress: strings.TrimSpace(os.Getenv("INVOICE_SELLER_ADDRESS")),
+		SellerTaxCode: strings.TrimSpace(os.Getenv("INVOICE_SELLER_TAX_CODE")),
+		SellerEmail:   strings.TrimSpace(os.Getenv("INVOICE_SELLER_EMAIL")),
+	}
+	if name := strings.TrimSpace(os.Getenv("INVOICE_SELLER_NAME")); name != "" {
+		cfg.SellerName = name
+	}
+	if rate, err := strconv.ParseFloat(os.Getenv("INVOICE_VAT_RATE"), 64); err == nil && rate > 0 && rate < 100 {
+		cfg.VATRate = rate
+	}
+	return cfg
+}
+
+func (c InvoiceConfig) seller() response_models.InvoiceParty {
+	return response_models.InvoiceParty{Name: c.SellerName, Email: c.SellerEmail, Address: c.SellerAddress, TaxCode: c.SellerTaxCode}
+}
+
+// vatIncluded is the VAT inside a price that includes it, rounded to the minor unit.
+func (c InvoiceConfig) vatIncluded(amountMinor int64) int64 {
+	if c.VATRate <= 0 {
+		return 0
+	}
+	return int64(math.Round(float64(amountMinor) * c.VATRate / (100 + c.VATRate)))
+}
+
+func receiptFileName(inv *response_models.Invoice) string {
+	return "receipt-" + inv.Number + ".pdf"
+}
+
+// receiptAmount is formatMinor with the currency code instead of "₫", which the core PDF
+// fonts cannot print.
+func receiptAmount(amount int64, currency string) string {
+	if strings.EqualFold(currency, "VND") {
+		return strings.TrimSuffix(formatVND(amount), " ₫") + " VND"
+	}
+	return formatMinor(amount, currency)
+}
+
+// receiptDate prints an RFC 3339 time of an invoice as a Vietnamese date.
+func receiptDate(s string) string {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return s
+	}
+	return t.In(vnLoc).Format("02/01/2006")
+}
+
+// renderReceiptPDF lays an invoice out on one A4 page: seller and buyer, the lines, and the
+// totals with the VAT they include.
+func renderReceiptPDF(inv *response_models.Invoice) ([]byte, error) {
+	th := defaultPdfTheme()
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 20, 15)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.SetTitle("Receipt "+inv.Number, true)
+	pdf.SetAuthor(inv.Seller.Name, true)
+	family, tr := setupPdfFont(pdf, th)
+	pdf.AddPage()
+
+	// Seller on the left, invoice number and dates on the right
+	pdf.SetFont(family, "B", 20)
+	pdf.SetTextColor(th.Primary.R, th.Primary.G, th.Primary.B)
+	pdf.CellFormat(100, 10, tr(inv.Seller.Name), "", 0, "L", false, 0, "")
+	pdf.SetFont(family, "B", 14)
+	pdf.SetTextColor(0, 0, 0)
+	title := "RECEIPT"
+	if inv.VATRate > 0 {
+		title = "VAT INVOICE"
+	}
+	pdf.CellFormat(0, 10, title, "", 1, "R", false, 0, "")
+
+	top := pdf.GetY()
+	pdf.SetFont(family, "", 9)
+	pdf.SetTextColor(th.Muted.R, th.Muted.G, th.Muted.B)
+	for _, line := range []string{inv.Seller.Address, taxCodeLine(inv.Seller.TaxCode), inv.Seller.Email} {
+		if line != "" {
+			pdf.CellFormat(100, 5, tr(line), "", 2, "L", false, 0, "")
+		}
+	}
+	sellerBottom := pdf.GetY()
+
+	pdf.SetXY(115, top)
+	details := [][2]string{{"No.", inv.Number}, {"Date", receiptDate(inv.IssuedAt)}, {"Status", "Paid"}}
+	if inv.RefundedAt != "" {
+		details[2][1] = "Refunded " + receiptDate(inv.RefundedAt)
+	}
+	for _, d := range details {
+		pdf.SetX(115)
+		pdf.SetFont(family, "", 9)
+		pdf.SetTextColor(th.Muted.R, th.Muted.G, th.Muted.B)
+		pdf.CellFormat(25, 5, d[0], "", 0, "L", false, 0, "")
+		pdf.SetFont(family, "B", 9)
+		pdf.SetTextColor(0, 0, 0)
+		pdf.CellFormat(0, 5, tr(d[1]), "", 1, "R", false, 0, "")
+	}
+	pdf.SetY(math.Max(sellerBottom, pdf.GetY()) + 6)
+
+	pdf.SetFont(family, "B", 10)
+	pdf.SetTextColor(th.Primary.R, th.Primary.G, th.Primary.B)
+	pdf.CellFormat(0, 6, "Billed to", "", 1, "L", false, 0, "")
+	pdf.SetFont(family, "", 10)
+	pdf.SetTextColor(0, 0, 0)
+	for _, line := range []string{inv.BilledTo.Name, inv.BilledTo.Email} {
+		if line != "" {
+			pdf.CellFormat(0, 5, tr(line), "", 1, "L", false, 0, "")
+		}
+	}
+	pdf.Ln(6)
+
+	pdf.SetFont(family, "B", 9)
+	pdf.SetFillColor(th.Primary.R, th.Primary.G, th.Primary.B)
+	pdf.SetTextColor(255, 255, 255)
+	pdf.CellFormat(95, 7, "Description", "", 0, "L", true, 0, "")
+	pdf.CellFormat(45, 7, "Period", "", 0, "L", true, 0, "")
+	pdf.CellFormat(40, 7, "Amount", "", 1, "R", true, 0, "")
+	pdf.SetFont(family, "", 9)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetDrawColor(th.Muted.R, th.Muted.G, th.Muted.B)
+	for _, l := range inv.Lines {
+		period := ""
+		if l.PeriodStart != "" && l.PeriodEnd != "" {
+			period = receiptDate(l.PeriodStart) + " - " + receiptDate(l.PeriodEnd)
+		}
+		pdf.CellFormat(95, 7, tr(l.Description), "B", 0, "L", false, 0, "")
+		pdf.CellFormat(45, 7, period, "B", 0, "L", false, 0, "")
+		pdf.CellFormat(40, 7, receiptAmount(l.AmountMinor, inv.Currency), "B", 1, "R", false, 0, "")
+	}
+	pdf.Ln(3)
+
+	totals := [][2]string{}
+	if inv.VATRate > 0 {
+		totals = append(totals,
+			[2]string{"Subtotal (excl. VAT)", receiptAmount(inv.SubtotalMinor, inv.Currency)},
+			[2]string{"VAT " + strconv.FormatFloat(inv.VATRate, 'f', -1, 64) + "%", receiptAmount(inv.VATMinor, inv.Currency)},
+		)
+	}
+	totals = append(totals, [2]string{"Total (" + strings.ToUpper(inv.Currency) + ")", receiptAmount(inv.TotalMinor, inv.Currency)})
+	for i, t := range totals {
+		style := ""
+		if i == len(totals)-1 {
+			style = "B"
+		}
+		pdf.SetFont(family, style, 10)
+		pdf.CellFormat(140, 6, t[0], "", 0, "R", false, 0, "")
+		pdf.CellFormat(40, 6, t[1], "", 1, "R", false, 0, "")
+	}
+	pdf.Ln(8)
+
+	pdf.SetFont(family, "I", 8)
+	pdf.SetTextColor(th.Muted.R, th.Muted.G, th.Muted.B)
+	note := fmt.Sprintf("Paid through %s. Payment reference %s.", inv.Provider, inv.TransactionID)
+	if inv.VATRate > 0 {
+		note += " Prices include VAT."
+	}
+	pdf.MultiCell(0, 4, tr(note), "", "L", false)
+	pdf.CellFormat(0, 4, tr(th.FooterLabel), "", 1, "L", false, 0, "")
+
+	if err := pdf.Error(); err != nil {
+		return nil, fmt.Errorf("render receipt: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("write receipt: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func taxCodeLine(code string) string {
+	if code == "" {
+		return ""
+	}
+	return "Tax code: " + code
+}
+
+// ReceiptMailer emails the receipt of a payment once it is paid.
+type ReceiptMailer interface {
+	SendReceipt(ctx context.Context, accountID uuid.UUID, transactionID string) error
+}
+
+type receiptMailer struct {
+	repo        repositories.BillingRepositoryInterface
+	accountRepo repositories.AccountRepository
+	mail        IMailService
+	invoice     InvoiceConfig
+	enabled     bool
+	appURL      string
+}
+
+// NewReceiptMailer sends receipts only when RECEIPT_EMAILS is true; the button of the email
+// opens APP_PUBLIC_URL/billing.
+func NewReceiptMailer(repo repositories.BillingRepositoryInterface, accountRepo repositories.AccountRepository, mail IMailService) ReceiptMailer {
+	m := &receiptMailer{repo: repo, accountRepo: accountRepo, mail: mail, invoice: InvoiceConfigFromEnv(), appURL: "https://vivu.com"}
+	m.enabled, _ = strconv.ParseBool(os.Getenv("RECEIPT_EMAILS"))
+	if u := strings.TrimRight(os.Getenv("APP_PUBLIC_URL"), "/"); u != "" {
+		m.appURL = u
+	}
+	return m
+}
+
+func (m *receiptMailer) SendReceipt(ctx context.Context, accountID uuid.UUID, transactionID string) error {
+	if !m.enabled || m.mail == nil {
+		return nil
+	}
+	txn, err := m.repo.FindTransaction(ctx, accountID, transactionID)
+	if err != nil {
+		return err
+	}
+	if txn == nil || !slices.Contains(invoicedStatuses, txn.Status) {
+		return nil
+	}
+	account, err := m.accountRepo.FindById(ctx, accountID.String())
+	if err != nil || account == nil {
+		return fmt.Errorf("account of %s: %v", transactionID, err)
+	}
+	invoices, err := buildInvoices(ctx, m.repo, m.invoice, account, []db_models.Transaction{*txn})
+	if err != nil {
+		return err
+	}
+	inv := &invoices[0]
+	data, err := renderReceiptPDF(inv)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Thank you for your payment of %s. Your receipt %s is attached.", formatMinor(inv.TotalMinor, inv.Currency), inv.Number)
+	return m.mail.SendMailWithAttachment(account.Email, "Your Vivu receipt "+inv.Number, body, "View billing", m.appURL+"/billing",
+		MailAttachment{FileName: receiptFileName(inv), ContentType: "application/pdf", Data: data})
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+)
+
+// RouteOptimizer orders the stops of a single day so the traveller does not zig-zag
+// across town. Days are small (2–8 stops), so nearest-neighbor + 2-opt is plenty.
+type RouteOptimizer struct {
+	matrix DistanceMatrixService
+}
+
+func NewRouteOptimizer(matrix DistanceMatrixService) *RouteOptimizer {
+	return &RouteOptimizer{matrix: matrix}
+}
+
+// OptimizedRoute is the result of ordering a day's stops.
+// Order holds indexes into the input slice; the first stop always stays first.
+type OptimizedRoute struct {
+	Order          []int
+	BeforeMeters   int
+	AfterMeters    int
+	DistanceMatrix DistanceMatrix
+}
+
+// OptimizeOpenPath keeps points[0] as the starting stop and returns the visiting order
+// minimising the total distance of the open path (no return leg).
+func (o *RouteOptimizer) OptimizeOpenPath(ctx context.Context, points []MatrixPoint, mode string) (*OptimizedRoute, error) {
+	n := len(points)
+	identity := make([]int, n)
+	for i := range identity {
+		identity[i] = i
+	}
+	if n < 3 {
+		return &OptimizedRoute{Order: identity}, nil
+	}
+
+	mat, err := o.matrix.ComputeDistances(ctx, points, mode)
+	var partial *PartialMatrixError
+	if err != nil && !errors.As(err, &partial) {
+		return nil, err
+	}
+
+	dist := func(a, b int) int {
+		if e, ok := mat[points[a].ID][points[b].ID]; ok {
+			return e.DistanceMeters
+		}
+		// Pair missing (failed tile or no route): fall back to straight-line distance
+		return int(haversineMeters(points[a].Lat, points[a].Lng, points[b].Lat, points[b].Lng))
+	}
+
+	order := nearestNeighborOrder(n, dist)
+	order = twoOptOpenPath(order, dist)
+
+	before := pathLength(identity, dist)
+	after := pathLength(order, dist)
+	if after >= before {
+		// Never make things worse than what the user (or the AI) already picked
+		order, after = identity, before
+	}
+
+	return &OptimizedRoute{
+		Order:          order,
+		BeforeMeters:   before,
+		AfterMeters:    after,
+		DistanceMatrix: mat,
+	}, nil
+}
+
+func nearestNeighborOrder(n int, dist func(a, b int) int) []int {
+	visited := make([]bool, n)
+	order := make([]int, 0, n)
+
+	cur := 0
+	visited[cur] = true
+	order = append(order, cur)
+
+	for len(order) < n {
+		best, bestD := -1, 0
+		for j := 0; j < n; j++ {
+			if visited[j] {
+				continue
+			}
+			if d := dist(cur, j); best == -1 || d < bestD {
+				best, bestD = j, d
+			}
+		}
+		visited[best] = true
+		order = append(order, best)
+		cur = best
+	}
+	return order
+}
+
+// twoOptOpenPath reverses segments while it shortens the path. Index 0 is pinned.
+func twoOptOpenPath(order []int, dist func(a, b int) int) []int {
+	n := len(order)
+	out := append([]int(nil), order...)
+
+	improved := true
+	for iter := 0; improved && iter < 100; iter++ {
+		improved = false
+		for i := 1; i < n-1; i++ {
+			for k := i + 1; k < n; k++ {
+				// edges (i-1,i) and (k,k+1) become (i-1,k) and (i,k+1)
+				delta := dist(out[i-1], out[k]) - dist(out[i-1], out[i])
+				if k+1 < n {
+					delta += dist(out[i], out[k+1]) - dist(out[k], out[k+1])
+				}
+				if delta < 0 {
+					for a, b := i, k; a < b; a, b = a+1, b-1 {
+						out[a], out[b] = out[b], out[a]
+					}
+					improved = true
+				}
+			}
+		}
+	}
+	return out
+}
+
+func pathLength(order []int, dist func(a, b int) int) int {
+	total := 0
+	for i := 0; i+1 < len(order); i++ {
+		total += dist(order[i], order[i+1])
+	}
+	return total
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+// Known switches. Enabling one turns the subsystem OFF (or maintenance ON).
+const (
+	SwitchMaintenance    = "maintenance_mode"
+	SwitchPlanGeneration = "disable_plan_generation"
+	SwitchImports        = "disable_imports"
+	SwitchExports        = "disable_exports"
+	SwitchAIProfiles     = "disable_ai_profiles" // every plan uses the default AI client
+)
+
+var knownSwitches = map[string]string{
+	SwitchMaintenance:    "ViVu is under maintenance. We'll be back shortly!",
+	SwitchPlanGeneration: "Trip planning is temporarily unavailable. Please try again in a few minutes.",
+	SwitchImports:        "Imports are temporarily unavailable. Please try again later.",
+	SwitchExports:        "Exports are temporarily unavailable. Please try again later.",
+	SwitchAIProfiles:     "Every trip is planned with the default model.",
+}
+
+type RuntimeSwitchServiceInterface interface {
+	// IsEngaged is called on the request path; it only reads the in-memory snapshot.
+	IsEngaged(key string) (bool, string)
+	ListSwitches(ctx context.Context) ([]response_models.RuntimeSwitchResponse, error)
+	SetSwitch(ctx context.Context, key string, enabled bool, message string, updatedBy string) (*response_models.RuntimeSwitchResponse, error)
+}
+
+type switchState struct {
+	enabled   bool
+	message   string
+	updatedBy string
+	updatedAt int64
+}
+
+type RuntimeSwitchService struct {
+	repo            repositories.RuntimeSwitchRepositoryInterface
+	refreshInterval time.Duration
+
+	mu          sync.RWMutex
+	state       map[string]switchState
+	lastRefresh time.Time
+	refreshing  bool
+}
+
+// NewRuntimeSwitchService loads the switches and keeps them fresh every refreshInterval, so a change
+// made on one instance reaches all instances without a redeploy. MAINTENANCE_MODE=true forces
+// maintenance regardless of the database (useful when the database itself is the problem).
+func NewRuntimeSwitchService(repo repositories.RuntimeSwitchRepositoryInterface) RuntimeSwitchServiceInterface {
+	interval := 15 * time.Second
+	if v, err := time.ParseDuration(os.Getenv("RUNTIME_SWITCH_REFRESH")); err == nil && v > 0 {
+		interval = v
+	}
+	s := &RuntimeSwitchService{
+		repo:            repo,
+		refreshInterval: interval,
+		state:           make(map[string]switchState),
+	}
+	s.refresh(context.Background())
+	return s
+}
+
+func (s *RuntimeSwitchService) refresh(ctx context.Context) {
+	rows, err := s.repo.ListSwitches(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshing = false
+	s.lastRefresh = time.Now()
+	if err != nil {
+		// Keep the last known state
+		log.Printf("[switches] refresh failed: %v", err)
+		return
+	}
+	next := make(map[string]switchState, len(rows))
+	for _, r := range rows {
+		next[r.Key] = switchState{enabled: r.Enabled, message: r.Message, updatedBy: r.UpdatedBy, updatedAt: r.UpdatedAt}
+	}
+	s.state = next
+}
+
+func (s *RuntimeSwitchService) IsEngaged(key string) (bool, string) {
+	if key == SwitchMaintenance {
+		if forced, _ := strconv.ParseBool(os.Getenv("MAINTENANCE_MODE")); forced {
+			return true, knownSwitches[SwitchMaintenance]
+		}
+	}
+
+	s.mu.Lock()
+	if !s.refreshing && time.Since(s.lastRefresh) > s.refreshInterval {
+		s.refreshing = true
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			s.refresh(ctx)
+		}()
+	}
+	st, ok := s.state[key]
+	s.mu.Unlock()
+
+	if !ok || !st.enabled {
+		return false, ""
+	}
+	msg := st.message
+	if msg == "" {
+		msg = knownSwitches[key]
+	}
+	return true, msg
+}
+
+func (s *RuntimeSwitchService) ListSwitches(ctx context.Context) ([]response_models.RuntimeSwitchResponse, error) {
+	s.refresh(ctx)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]response_models.RuntimeSwitchResponse, 0, len(knownSwitches))
+	for _, key := range []string{SwitchMaintenance, SwitchPlanGeneration, SwitchImports, SwitchExports} {
+		out = append(out, s.toResponse(key, s.state[key]))
+	}
+	return out, nil
+}
+
+func (s *RuntimeSwitchService) SetSwitch(ctx context.Context, key string, enabled bool, message string, updatedBy string) (*response_models.RuntimeSwitchResponse, error) {
+	if _, ok := knownSwitches[key]; !ok {
+		return nil, utils.ErrInvalidInput
+	}
+
+	sw := &db_models.RuntimeSwitch{
+		Key:       key,
+		Enabled:   enabled,
+		Message:   message,
+		UpdatedBy: updatedBy,
+	}
+	if err := s.repo.UpsertSwitch(ctx, sw); err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	log.Printf("[switches] %s set to %v by %s", key, enabled, updatedBy)
+
+	st := switchState{enabled: enabled, message: message, updatedBy: updatedBy, updatedAt: sw.UpdatedAt}
+	s.mu.Lock()
+	s.state[key] = st
+	s.mu.Unlock()
+
+	resp := s.toResponse(key, st)
+	return &resp, nil
+}
+
+func (s *RuntimeSwitchService) toResponse(key string, st switchState) response_models.RuntimeSwitchResponse {
+	msg := st.message
+	if msg == "" {
+		msg = knownSwitches[key]
+	}
+	out := response_models.RuntimeSwitchResponse{
+		Key:       key,
+		Enabled:   st.enabled,
+		Message:   msg,
+		UpdatedBy: st.updatedBy,
+	}
+	if st.updatedAt > 0 {
+		out.UpdatedAt = utils.FormatRFC3339VN(utils.FromUnixSecondsVN(st.updatedAt))
+	}
+	return out
+}
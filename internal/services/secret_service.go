@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sort"
+
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/secrets"
+	"vivu/pkg/utils"
+)
+
+type SecretServiceInterface interface {
+	// Report lists the keys in use and how much every version of them was read, by instance.
+	Report(ctx context.Context) (*response_models.SecretReport, error)
+	// Refresh reloads the secrets manager now, on the answering instance; the others pick
+	// the change up at their next refresh.
+	Refresh(ctx context.Context) (*response_models.SecretReport, error)
+}
+
+type SecretService struct {
+	store *secrets.Store
+	repo  repositories.SecretUsageRepositoryInterface
+}
+
+func NewSecretService(store *secrets.Store, repo repositories.SecretUsageRepositoryInterface) SecretServiceInterface {
+	return &SecretService{store: store, repo: repo}
+}
+
+func (s *SecretService) Refresh(ctx context.Context) (*response_models.SecretReport, error) {
+	rotated, err := s.store.Refresh(ctx)
+	if err != nil {
+		return nil, utils.ErrInternal.WithMessage("Could not reload the secrets manager").Wrap(err)
+	}
+	report, err := s.Report(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.Rotated = rotated
+	return report, nil
+}
+
+func (s *SecretService) Report(ctx context.Context) (*response_models.SecretReport, error) {
+	// Include the reads of this instance that were not flushed yet
+	if err := s.store.Flush(ctx); err != nil {
+		log.Printf("[secrets] flushing usage failed: %v", err)
+	}
+	rows, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+
+	out := &response_models.SecretReport{Instance: s.store.Instance(), Source: s.store.SourceName()}
+	byName := make(map[string]*response_models.SecretKey)
+	key := func(name string) *response_models.SecretKey {
+		k, ok := byName[name]
+		if !ok {
+			k = &response_models.SecretKey{Name: name, Versions: []response_models.SecretKeyVersion{}}
+			byName[name] = k
+		}
+		return k
+	}
+	for _, info := range s.store.Keys() {
+		k := key(info.Name)
+		k.Source = info.Source
+		k.Fingerprint = info.Fingerprint
+		k.LoadedAt = utils.FormatRFC3339VN(info.LoadedAt.In(vnLoc))
+		if !info.RotatedAt.IsZero() {
+			k.RotatedAt = utils.FormatRFC3339VN(info.RotatedAt.In(vnLoc))
+		}
+	}
+
+	// rows come most recently used first, so versions and instances keep that order
+	type versionAcc struct {
+		name          string
+		v             response_models.SecretKeyVersion
+		first, latest int64
+	}
+	versions := make(map[string]*versionAcc)
+	var order []string
+	for _, r := range rows {
+		id := r.Name + "|" + r.Fingerprint
+		acc, ok := versions[id]
+		if !ok {
+			acc = &versionAcc{name: r.Name, v: response_models.SecretKeyVersion{Fingerprint: r.Fingerprint}, first: r.FirstUsedAt, latest: r.LastUsedAt}
+			versions[id] = acc
+			order = append(order, id)
+		}
+		acc.v.Uses += r.Uses
+		acc.first = min(acc.first, r.FirstUsedAt)
+		acc.latest = max(acc.latest, r.LastUsedAt)
+		acc.v.Instances = append(acc.v.Instances, response_models.SecretKeyInstance{
+			Instance:   r.Instance,
+			Source:     r.Source,
+			Uses:       r.Uses,
+			LastUsedAt: utils.FormatRFC3339VN(utils.FromUnixSecondsVN(r.LastUsedAt)),
+		})
+	}
+	for _, id := range order {
+		acc := versions[id]
+		k := key(acc.name)
+		acc.v.Current = acc.v.Fingerprint == k.Fingerprint
+		acc.v.FirstUsedAt = utils.FormatRFC3339VN(utils.FromUnixSecondsVN(acc.first))
+		acc.v.LastUsedAt = utils.FormatRFC3339VN(utils.FromUnixSecondsVN(acc.latest))
+		k.Versions = append(k.Versions, acc.v)
+	}
+
+	out.Keys = make([]response_models.SecretKey, 0, len(byName))
+	for _, k := range byName {
+		out.Keys = append(out.Keys, *k)
+	}
+	sort.Slice(out.Keys, func(i, j int) bool { return out.Keys[i].Name < out.Keys[j].Name })
+	return out, nil
+}
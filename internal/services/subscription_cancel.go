@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	dbm "vivu/internal/models/db_models"
+	"vivu/internal/models/response_models"
+	"vivu/pkg/utils"
+)
+
+// CancelSubscription turns renewal of the running subscription off. The plan stays on until
+// the end of the paid period, when CloseCanceledSubscriptions ends it.
+func (p *paymentService) CancelSubscription(ctx context.Context, accountID uuid.UUID) (*response_models.SubscriptionStatusResponse, error) {
+	return p.SetAutoRenew(ctx, accountID, false)
+}
+
+func (p *paymentService) SetAutoRenew(ctx context.Context, accountID uuid.UUID, enabled bool) (*response_models.SubscriptionStatusResponse, error) {
+	now := time.Now().Unix()
+
+	var sub dbm.Subscription
+	changed := false
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("Plan", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+			Where("account_id = ?", accountID).
+			Where("status = ? OR (status = ? AND starts_at <= ? AND ends_at > ?)", dbm.SubStatusPaused, dbm.SubStatusActive, now, now).
+			Order("ends_at DESC").
+			First(&sub).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrNoActiveSubscription
+		}
+		if err != nil {
+			return utils.ErrDatabaseError
+		}
+		if sub.AutoRenew == enabled {
+			return nil
+		}
+
+		sub.AutoRenew = enabled
+		sub.CanceledAt = nil
+		if !enabled {
+			sub.CanceledAt = &now
+		}
+		if err := tx.Model(&sub).Updates(map[string]any{
+			"auto_renew":  sub.AutoRenew,
+			"canceled_at": sub.CanceledAt,
+		}).Error; err != nil {
+			return utils.ErrDatabaseError
+		}
+		changed = true
+		return p.snapshotSubscription(tx, &sub)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if changed {
+		p.sendRenewalConfirmation(ctx, &sub)
+	}
+	return p.GetStatusOfSubscription(ctx, accountID)
+}
+
+// sendRenewalConfirmation tells the account that renewal was turned off or back on. The mail
+// outbox retries it; failing to queue it does not undo the change.
+func (p *paymentService) sendRenewalConfirmation(ctx context.Context, sub *dbm.Subscription) {
+	if p.mail == nil {
+		return
+	}
+	var account dbm.Account
+	if err := p.db.WithContext(ctx).Select("id", "email").First(&account, "id = ?", sub.AccountID).Error; err != nil {
+		log.Printf("[subscriptions] account of %s: %v", sub.ID, err)
+		return
+	}
+
+	endsAt := utils.FromUnixSecondsVN(sub.EndsAt).Format("02/01/2006")
+	planName := sub.Plan.Name
+	if planName == "" {
+		planName = "Vivu Premium"
+	}
+	subject := "Your Vivu subscription was canceled"
+	body := fmt.Sprintf("Your %s subscription will not renew. You keep every premium feature until %s; after that your account goes back to the free plan. Changed your mind? Turn renewal back on any time before then.", planName, endsAt)
+	cta := "Turn renewal back on"
+	if sub.AutoRenew {
+		subject = "Your Vivu subscription will renew"
+		body = fmt.Sprintf("Renewal of your %s subscription is back on. Your current period ends on %s.", planName, endsAt)
+		cta = "Manage my subscription"
+	}
+	if sub.Status == dbm.SubStatusPaused {
+		body += " Your subscription is paused: the end date moves forward by the time it stays paused."
+	}
+
+	if err := p.mail.SendMailToNotifyUser(account.Email, subject, body, cta, p.appURL+"/billing"); err != nil {
+		log.Printf("[subscriptions] renewal confirmation to %s: %v", account.Email, err)
+	}
+}
+
+// CloseCanceledSubscriptions ends the canceled subscriptions whose paid period is over, so
+// the account loses the plan's features.
+func (p *paymentService) CloseCanceledSubscriptions(ctx context.Context) (int, error) {
+	now := time.Now().Unix()
+
+	closed := 0
+	for {
+		var subs []dbm.Subscription
+		err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			// Another instance closing the same rows skips them instead of waiting
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("status = ? AND auto_renew = FALSE AND ends_at <= ?", dbm.SubStatusActive, now).
+				Limit(100).
+				Find(&subs).Error; err != nil {
+				return err
+			}
+			for i := range subs {
+				sub := &subs[i]
+				sub.Status = dbm.SubStatusCanceled
+				if err := tx.Model(sub).Update("status", sub.Status).Error; err != nil {
+					return err
+				}
+				if err := p.snapshotSubscription(tx, sub); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return closed, err
+		}
+		closed += len(subs)
+		if len(subs) < 100 {
+			return closed, nil
+		}
+	}
+}
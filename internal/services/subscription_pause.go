@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	dbm "vivu/internal/models/db_models"
+	"vivu/internal/models/response_models"
+	"vivu/pkg/utils"
+)
+
+func (p *paymentService) PauseSubscription(ctx context.Context, accountID uuid.UUID) (*response_models.SubscriptionStatusResponse, error) {
+	now := time.Now().Unix()
+
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var sub dbm.Subscription
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("account_id = ? AND status = ? AND starts_at <= ? AND ends_at > ?", accountID, dbm.SubStatusActive, now, now).
+			Order("ends_at DESC").
+			First(&sub).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrNoActiveSubscription
+		}
+		if err != nil {
+			return utils.ErrDatabaseError
+		}
+		if sub.PausedAt != nil {
+			return utils.ErrSubscriptionPauseUsed
+		}
+
+		// A renewal paid in advance would keep the plan running through the pause
+		var queued int64
+		if err := tx.Model(&dbm.Subscription{}).
+			Where("account_id = ? AND id <> ? AND status = ? AND starts_at >= ?", accountID, sub.ID, dbm.SubStatusActive, sub.EndsAt).
+			Count(&queued).Error; err != nil {
+			return utils.ErrDatabaseError
+		}
+		if queued > 0 {
+			return utils.ErrInvalidInput.WithMessage("A renewal is already paid for; subscriptions with a queued renewal cannot be paused")
+		}
+
+		sub.Status = dbm.SubStatusPaused
+		sub.PausedAt = &now
+		sub.PausedRemaining = sub.EndsAt - now
+		if err := tx.Model(&sub).Updates(map[string]any{
+			"status":           sub.Status,
+			"paused_at":        now,
+			"paused_remaining": sub.PausedRemaining,
+		}).Error; err != nil {
+			return utils.ErrDatabaseError
+		}
+		return p.snapshotSubscription(tx, &sub)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p.GetStatusOfSubscription(ctx, accountID)
+}
+
+func (p *paymentService) ResumeSubscription(ctx context.Context, accountID uuid.UUID) (*response_models.SubscriptionStatusResponse, error) {
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var sub dbm.Subscription
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("account_id = ? AND status = ?", accountID, dbm.SubStatusPaused).
+			First(&sub).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrSubscriptionNotPaused
+		}
+		if err != nil {
+			return utils.ErrDatabaseError
+		}
+		return p.resume(tx, &sub, time.Now().Unix())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p.GetStatusOfSubscription(ctx, accountID)
+}
+
+// ResumeOverduePauses resumes the subscriptions paused for longer than the maximum pause, as
+// if they were resumed the moment it ran out.
+func (p *paymentService) ResumeOverduePauses(ctx context.Context) (int, error) {
+	deadline := time.Now().Add(-p.maxPause).Unix()
+
+	resumed := 0
+	for {
+		var subs []dbm.Subscription
+		err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			// Another instance resuming the same rows skips them instead of waiting
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("status = ? AND paused_at <= ?", dbm.SubStatusPaused, deadline).
+				Limit(100).
+				Find(&subs).Error; err != nil {
+				return err
+			}
+			for i := range subs {
+				if err := p.resume(tx, &subs[i], *subs[i].PausedAt+int64(p.maxPause.Seconds())); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return resumed, err
+		}
+		resumed += len(subs)
+		if len(subs) < 100 {
+			return resumed, nil
+		}
+	}
+}
+
+// resume runs the subscription again from at with the time it had left when paused.
+func (p *paymentService) resume(tx *gorm.DB, sub *dbm.Subscription, at int64) error {
+	sub.Status = dbm.SubStatusActive
+	sub.EndsAt = at + sub.PausedRemaining
+	sub.ResumedAt = &at
+	sub.ExpiryNoticeAt = nil // the end moved: warn again before the new one
+	if err := tx.Model(sub).Updates(map[string]any{
+		"status":           sub.Status,
+		"ends_at":          sub.EndsAt,
+		"resumed_at":       at,
+		"paused_remaining": 0,
+		"expiry_notice_at": nil,
+	}).Error; err != nil {
+		return utils.ErrDatabaseError
+	}
+	sub.PausedRemaining = 0
+	return p.snapshotSubscription(tx, sub)
+}
+
+func (p *paymentService) snapshotSubscription(tx *gorm.DB, sub *dbm.Subscription) error {
+	if err := tx.Model(&dbm.Account{BaseModel: dbm.BaseModel{ID: sub.AccountID}}).
+		Update("subscription_snapshot", jsonRaw(sub)).Error; err != nil {
+		log.Printf("[subscriptions] snapshot of %s: %v", sub.AccountID, err)
+		return utils.ErrDatabaseError
+	}
+	return nil
+}
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	dbm "vivu/internal/models/db_models"
+	"vivu/internal/models/response_models"
+	"vivu/pkg/utils"
+)
+
+// Plan changes made without a payment are recorded as a zero-amount transaction of this provider
+const planChangeProvider = "credit"
+
+// planChangeQuote is the proration of a change between two plans at one instant.
+type planChangeQuote struct {
+	remaining    int64 // seconds left on the current subscription
+	creditMinor  int64 // unused value of the current subscription
+	chargeMinor  int64 // new price minus the credit, never below zero
+	bonusSeconds int64 // credit beyond the new price, as time on the new plan
+}
+
+// quotePlanChange credits the unused share of what the current subscription was worth and
+// charges the rest of the new plan's price. Credit the new price does not use up is turned
+// into time on the new plan at its own rate instead of being refunded.
+func quotePlanChange(valueMinor, startsAt, endsAt, now, newPriceMinor, newPeriodSeconds int64) planChangeQuote {
+	q := planChangeQuote{remaining: endsAt - now}
+	if q.remaining < 0 {
+		q.remaining = 0
+	}
+	if length := endsAt - startsAt; length > 0 && valueMinor > 0 {
+		q.creditMinor = valueMinor * q.remaining / length
+	}
+	q.chargeMinor = newPriceMinor - q.creditMinor
+	if q.chargeMinor < 0 {
+		q.bonusSeconds = -q.chargeMinor * newPeriodSeconds / newPriceMinor
+		q.chargeMinor = 0
+	}
+	return q
+}
+
+// periodEnd is when a plan bought at starts runs out.
+func periodEnd(starts time.Time, period dbm.BillingPeriod) time.Time {
+	if period == dbm.PeriodYear {
+		return starts.AddDate(1, 0, 0)
+	}
+	return starts.AddDate(0, 1, 0)
+}
+
+// subscriptionValue is what the subscription was worth over its whole period: the value a plan
+// change recorded, else the amount paid for it, else the price of its plan.
+func subscriptionValue(sub *dbm.Subscription) int64 {
+	var m struct {
+		ValueMinor  *int64 `json:"value_minor"`
+		AmountMinor *int64 `json:"amount_minor"`
+	}
+	if err := json.Unmarshal(sub.Metadata, &m); err == nil {
+		switch {
+		case m.ValueMinor != nil:
+			return *m.ValueMinor
+		case m.AmountMinor != nil:
+			return *m.AmountMinor
+		}
+	}
+	return sub.Plan.PriceMinor
+}
+
+func (p *paymentService) ChangePlan(ctx context.Context, accountID uuid.UUID, planCode string) (*response_models.PlanChangeResponse, error) {
+	var plan dbm.Plan
+	err := p.db.WithContext(ctx).Where("code = ? AND is_active = TRUE", planCode).First(&plan).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, utils.RecordNotFound.WithMessage("This plan is not available")
+	}
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if plan.PriceMinor <= 0 {
+		return nil, utils.ErrInvalidInput.WithMessage("This plan cannot be bought")
+	}
+
+	var (
+		change dbm.SubscriptionPlanChange
+		from   dbm.Subscription
+		sub    *dbm.Subscription
+	)
+	now := time.Now().In(p.loc)
+	err = p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("account_id = ? AND status IN ? AND (status = ? OR (starts_at <= ? AND ends_at > ?))",
+				accountID, []dbm.SubscriptionStatus{dbm.SubStatusActive, dbm.SubStatusPaused},
+				dbm.SubStatusPaused, now.Unix(), now.Unix()).
+			Order("ends_at DESC").
+			First(&from).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ErrNoActiveSubscription
+		}
+		if err != nil {
+			return utils.ErrDatabaseError
+		}
+		if from.Status == dbm.SubStatusPaused {
+			return utils.ErrInvalidInput.WithMessage("Resume your subscription before changing plans")
+		}
+		if from.PlanID == plan.ID {
+			return utils.ErrInvalidInput.WithMessage("You are already on this plan")
+		}
+		if err := tx.Unscoped().Where("id = ?", from.PlanID).First(&from.Plan).Error; err != nil {
+			return utils.ErrDatabaseError
+		}
+		if !strings.EqualFold(from.Plan.Currency, plan.Currency) {
+			return utils.ErrInvalidInput.WithMessage("Plans in another currency cannot be prorated; cancel and subscribe to it instead")
+		}
+
+		// The queued renewal would still start on the old plan when the new one is running
+		var queued int64
+		if err := tx.Model(&dbm.Subscription{}).
+			Where("account_id = ? AND id <> ? AND status = ? AND starts_at >= ?", accountID, from.ID, dbm.SubStatusActive, from.EndsAt).
+			Count(&queued).Error; err != nil {
+			return utils.ErrDatabaseError
+		}
+		if queued > 0 {
+			return utils.ErrInvalidInput.WithMessage("A renewal is already paid for; change plans once it has started")
+		}
+
+		newPeriod := periodEnd(now, plan.Period).Unix() - now.Unix()
+		q := quotePlanChange(subscriptionValue(&from), from.StartsAt, from.EndsAt, now.Unix(), plan.PriceMinor, newPeriod)
+
+		direction := dbm.PlanChangeDowngrade
+		if monthlyPrice(plan) > monthlyPrice(from.Plan) {
+			direction = dbm.PlanChangeUpgrade
+		}
+		change = dbm.SubscriptionPlanChange{
+			AccountID:          accountID,
+			FromSubscriptionID: from.ID,
+			FromPlanID:         from.PlanID,
+			ToPlanID:           plan.ID,
+			Direction:          direction,
+			Status:             dbm.PlanChangePending,
+			Currency:           strings.ToUpper(plan.Currency),
+			RemainingSeconds:   q.remaining,
+			CreditMinor:        q.creditMinor,
+			ChargeMinor:        q.chargeMinor,
+			BonusSeconds:       q.bonusSeconds,
+		}
+		// A change still waiting for its payment is superseded; if its checkout is paid anyway
+		// it no longer applies (see applyPlanChange)
+		if err := tx.Model(&dbm.SubscriptionPlanChange{}).
+			Where("from_subscription_id = ? AND status = ?", from.ID, dbm.PlanChangePending).
+			Update("status", dbm.PlanChangeFailed).Error; err != nil {
+			return utils.ErrDatabaseError
+		}
+		if err := tx.Create(&change).Error; err != nil {
+			return utils.ErrDatabaseError
+		}
+		if change.ChargeMinor > 0 {
+			return nil // applied once the checkout below is paid
+		}
+
+		// The credit covers the new plan: nothing to pay, the change applies now
+		paidAt := now.Unix()
+		txn := dbm.Transaction{
+			AccountID:     accountID,
+			Currency:      change.Currency,
+			Status:        dbm.TxnStatusPaid,
+			Provider:      planChangeProvider,
+			ProviderTxnID: "plan_change:" + change.ID.String(),
+			PaidAt:        &paidAt,
+			Metadata: jsonRaw(map[string]any{
+				"plan_id":        plan.ID,
+				"plan_code":      plan.Code,
+				"plan_change_id": change.ID,
+				"credit_minor":   change.CreditMinor,
+			}),
+		}
+		if err := tx.Create(&txn).Error; err != nil {
+			return utils.ErrDatabaseError
+		}
+		sub, err = p.applyPlanChange(tx, &change, &txn, &plan)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := &response_models.PlanChangeResponse{
+		ChangeID:     change.ID,
+		Direction:    change.Direction,
+		Status:       change.Status,
+		FromPlanCode: from.Plan.Code,
+		ToPlanCode:   plan.Code,
+		Currency:     change.Currency,
+		CreditMinor:  change.CreditMinor,
+		ChargeMinor:  change.ChargeMinor,
+		BonusDays:    int(change.BonusSeconds / 86400),
+	}
+
+	if sub == nil {
+		gateway, err := p.providers.pick("", plan.Currency)
+		var checkout *response_models.CreateCheckoutResponse
+		if err == nil {
+			checkout, err = p.createPaymentLink(ctx, gateway, accountID, &plan, change.ChargeMinor,
+				fmt.Sprintf("Change to %s", plan.Code), map[string]any{"plan_change_id": change.ID})
+		}
+		if err != nil {
+			_ = p.db.WithContext(ctx).Model(&change).Update("status", dbm.PlanChangeFailed).Error
+			return nil, err
+		}
+		out.Checkout = checkout
+		return out, nil
+	}
+
+	p.events.Publish(ctx, accountID, EventSubscriptionActivated, subscriptionEvent(sub))
+	out.Subscription, err = p.GetStatusOfSubscription(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// applyPlanChange ends the old subscription now and starts one on the new plan, running for
+// its period plus the bonus the credit left over. A change that was superseded, or whose old
+// subscription is no longer running, is marked failed instead: its credit is gone or already
+// spent. The payment stands and is logged for a refund; nil is returned with no error so the
+// webhook still settles the transaction.
+func (p *paymentService) applyPlanChange(tx *gorm.DB, change *dbm.SubscriptionPlanChange, txn *dbm.Transaction, plan *dbm.Plan) (*dbm.Subscription, error) {
+	now := time.Now().In(p.loc)
+
+	var from dbm.Subscription
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", change.FromSubscriptionID).
+		First(&from).Error; err != nil {
+		return nil, err
+	}
+	// A change whose payment failed first still applies when that payment goes through after all
+	superseded := change.Status == dbm.PlanChangeApplied ||
+		change.Status == dbm.PlanChangeFailed && (change.TransactionID == nil || *change.TransactionID != txn.ID)
+	running := from.Status == dbm.SubStatusActive && from.EndsAt > now.Unix() || from.Status == dbm.SubStatusPaused
+	if superseded || !running {
+		log.Printf("[payments] plan change %s (%s) no longer applies, subscription %s is %s: refund transaction %s",
+			change.ID, change.Status, from.ID, from.Status, txn.ID)
+		change.Status = dbm.PlanChangeFailed
+		change.TransactionID = &txn.ID
+		return nil, tx.Model(change).Updates(map[string]any{
+			"status":         change.Status,
+			"transaction_id": txn.ID,
+		}).Error
+	}
+	if err := tx.Model(&from).Updates(map[string]any{
+		"status":           dbm.SubStatusReplaced,
+		"ends_at":          now.Unix(),
+		"paused_remaining": 0,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	ends := periodEnd(now, plan.Period).Add(time.Duration(change.BonusSeconds) * time.Second)
+	sub := dbm.Subscription{
+		AccountID:  change.AccountID,
+		PlanID:     plan.ID,
+		Status:     dbm.SubStatusActive,
+		StartsAt:   now.Unix(),
+		EndsAt:     ends.Unix(),
+		AutoRenew:  from.AutoRenew,
+		CanceledAt: from.CanceledAt,
+
+		Provider:      txn.Provider,
+		ProviderSubID: strconv.FormatInt(time.Now().UnixNano(), 10), // unique placeholder
+
+		Metadata: jsonRaw(map[string]any{
+			"activated_by_txn": txn.ID,
+			"amount_minor":     txn.AmountMinor,
+			"currency":         txn.Currency,
+			"plan_change_id":   change.ID,
+			// What the period is worth for the next change: paid plus credited
+			"value_minor": change.ChargeMinor + change.CreditMinor,
+		}),
+	}
+	if err := tx.Create(&sub).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(txn).Update("subscription_id", sub.ID).Error; err != nil {
+		return nil, err
+	}
+
+	appliedAt := now.Unix()
+	change.Status = dbm.PlanChangeApplied
+	change.ToSubscriptionID = &sub.ID
+	change.TransactionID = &txn.ID
+	change.AppliedAt = &appliedAt
+	if err := tx.Model(change).Updates(map[string]any{
+		"status":             change.Status,
+		"to_subscription_id": sub.ID,
+		"transaction_id":     txn.ID,
+		"applied_at":         appliedAt,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	if err := p.snapshotSubscription(tx, &sub); err != nil {
+		return nil, err
+	}
+	sub.Plan = *plan
+	return &sub, nil
+}
+
+// failPlanChange marks the change a failed payment was for, if any, as failed.
+func (p *paymentService) failPlanChange(tx *gorm.DB, txn *dbm.Transaction) error {
+	var m struct {
+		PlanChangeID uuid.UUID `json:"plan_change_id"`
+	}
+	if err := json.Unmarshal(txn.Metadata, &m); err != nil || m.PlanChangeID == uuid.Nil {
+		return nil
+	}
+	return tx.Model(&dbm.SubscriptionPlanChange{}).
+		Where("id = ? AND status = ?", m.PlanChangeID, dbm.PlanChangePending).
+		Updates(map[string]any{"status": dbm.PlanChangeFailed, "transaction_id": txn.ID}).Error
+}
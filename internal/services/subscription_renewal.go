@@ -0,0 +1,315 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	dbm "vivu/internal/models/db_models"
+	"vivu/pkg/utils"
+)
+
+// renewalRetries are when, counted from the end of the period, the renewal job opens another
+// checkout for a subscription that is still unpaid. Those past the grace period are skipped.
+var renewalRetries = []time.Duration{0, 3 * 24 * time.Hour, 7 * 24 * time.Hour}
+
+// A claimed subscription whose checkout could not be opened is tried again after this long.
+const renewalRetryOnError = 6 * time.Hour
+
+// nextRenewalStep is when the renewal job acts next on a subscription ending at endsAt: the
+// first attempt lead before the end, the retries after it, and the downgrade at the end of
+// the grace period.
+func nextRenewalStep(endsAt, now int64, lead, grace time.Duration) int64 {
+	steps := []int64{endsAt - int64(lead.Seconds())}
+	for _, r := range renewalRetries {
+		if r < grace {
+			steps = append(steps, endsAt+int64(r.Seconds()))
+		}
+	}
+	steps = append(steps, endsAt+int64(grace.Seconds()))
+	for _, s := range steps {
+		if s > now {
+			return s
+		}
+	}
+	return now
+}
+
+// RenewSubscriptions opens a renewal checkout for every auto-renewing subscription that is
+// due and emails it: once before the end, then on the retry schedule while it stays unpaid,
+// marking it past due. A subscription still unpaid at the end of the grace period expires.
+func (p *paymentService) RenewSubscriptions(ctx context.Context) (int, error) {
+	now := time.Now().Unix()
+
+	// Periods a paid renewal follows are over; they need no renewal of their own
+	if err := p.db.WithContext(ctx).Exec(`
+UPDATE subscriptions s SET status = ?, next_renewal_at = NULL
+WHERE s.status IN ? AND s.deleted_at IS NULL AND s.ends_at <= ?
+	AND EXISTS (SELECT 1 FROM subscriptions n
+		WHERE n.account_id = s.account_id AND n.id <> s.id AND n.deleted_at IS NULL
+			AND n.status IN ? AND n.starts_at >= s.starts_at AND n.ends_at > s.ends_at)`,
+		dbm.SubStatusExpired, []dbm.SubscriptionStatus{dbm.SubStatusActive, dbm.SubStatusPastDue}, now,
+		[]dbm.SubscriptionStatus{dbm.SubStatusActive, dbm.SubStatusTrialing}).Error; err != nil {
+		return 0, err
+	}
+
+	renewed := 0
+	for {
+		// Claiming and reading in one statement lets every instance run the job; the claim
+		// lapses after renewalRetryOnError if the instance dies before it is done
+		var subs []dbm.Subscription
+		err := p.db.WithContext(ctx).Raw(`
+UPDATE subscriptions SET next_renewal_at = ?
+WHERE id IN (SELECT s.id FROM subscriptions s
+	WHERE s.status IN ? AND s.auto_renew AND s.deleted_at IS NULL
+		AND s.ends_at <= ? AND COALESCE(s.next_renewal_at, 0) <= ?
+		AND NOT EXISTS (SELECT 1 FROM subscriptions n
+			WHERE n.account_id = s.account_id AND n.id <> s.id AND n.deleted_at IS NULL
+				AND n.status IN ? AND n.ends_at > s.ends_at)
+	ORDER BY s.ends_at
+	LIMIT 100
+	FOR UPDATE SKIP LOCKED)
+RETURNING *`,
+			now+int64(renewalRetryOnError.Seconds()),
+			[]dbm.SubscriptionStatus{dbm.SubStatusActive, dbm.SubStatusPastDue},
+			now+int64(p.renewalLead.Seconds()), now,
+			[]dbm.SubscriptionStatus{dbm.SubStatusActive, dbm.SubStatusTrialing}).
+			Scan(&subs).Error
+		if err != nil {
+			return renewed, err
+		}
+		for i := range subs {
+			if err := p.renewSubscription(ctx, &subs[i], now); err != nil {
+				log.Printf("[subscriptions] renewal of %s: %v", subs[i].ID, err)
+				continue
+			}
+			renewed++
+		}
+		if len(subs) < 100 {
+			return renewed, nil
+		}
+	}
+}
+
+// renewSubscription takes the next renewal step for a claimed subscription.
+func (p *paymentService) renewSubscription(ctx context.Context, sub *dbm.Subscription, now int64) error {
+	if err := p.db.WithContext(ctx).Unscoped().First(&sub.Plan, "id = ?", sub.PlanID).Error; err != nil {
+		return err
+	}
+	if now >= sub.EndsAt+int64(p.dunningGrace.Seconds()) {
+		return p.expireUnpaidSubscription(ctx, sub, now)
+	}
+
+	plan, err := p.renewalPlan(ctx, &sub.Plan)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return p.stopRenewal(ctx, sub, now)
+	}
+	if err != nil {
+		return err
+	}
+
+	updates := map[string]any{}
+	if now >= sub.EndsAt && sub.Status == dbm.SubStatusActive {
+		sub.Status = dbm.SubStatusPastDue
+		updates["status"] = sub.Status
+		if sub.PastDueAt == nil {
+			sub.PastDueAt = &now
+			updates["past_due_at"] = now
+		}
+	}
+
+	// The subscription's own gateway when it is still configured, else the default
+	provider := sub.Provider
+	if _, ok := p.providers.get(provider); !ok {
+		provider = ""
+	}
+	gateway, err := p.providers.pick(provider, plan.Currency)
+	if err != nil {
+		return err
+	}
+	checkout, err := p.createPaymentLink(ctx, gateway, sub.AccountID, plan, plan.PriceMinor,
+		fmt.Sprintf("Renewal %s", plan.Code), map[string]any{"renewal_of": sub.ID})
+	if err != nil {
+		// The status change still holds; the claim brings the checkout back later
+		if len(updates) > 0 {
+			if err := p.db.WithContext(ctx).Model(sub).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		return err
+	}
+
+	next := nextRenewalStep(sub.EndsAt, now, p.renewalLead, p.dunningGrace)
+	sub.RenewalAttempts++
+	sub.NextRenewalAt = &next
+	updates["renewal_attempts"] = sub.RenewalAttempts
+	updates["next_renewal_at"] = next
+	err = p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(sub).Updates(updates).Error; err != nil {
+			return err
+		}
+		if _, ok := updates["status"]; ok {
+			return p.snapshotSubscription(tx, sub)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, ok := updates["status"]; ok {
+		p.events.Publish(ctx, sub.AccountID, EventSubscriptionPastDue, subscriptionEvent(sub))
+	}
+	p.sendDunningMail(ctx, sub, plan, checkout.PaymentURL, now)
+	return nil
+}
+
+// renewalPlan is the plan a subscription renews on: its own, or the newest active version of it
+// when it was retired. gorm.ErrRecordNotFound means it is no longer sold.
+func (p *paymentService) renewalPlan(ctx context.Context, plan *dbm.Plan) (*dbm.Plan, error) {
+	current := plan
+	// Versions chain through PreviousPlanID; a bound keeps a bad chain from looping
+	for range 20 {
+		if current.IsActive && !current.DeletedAt.Valid && current.PriceMinor > 0 {
+			return current, nil
+		}
+		var next dbm.Plan
+		if err := p.db.WithContext(ctx).Unscoped().
+			Where("previous_plan_id = ?", current.ID).
+			Order("version DESC").
+			First(&next).Error; err != nil {
+			return nil, err
+		}
+		current = &next
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// stopRenewal turns renewal off for a subscription whose plan is no longer sold. A running one
+// ends with its period as a canceled one would; one already past due ends now.
+func (p *paymentService) stopRenewal(ctx context.Context, sub *dbm.Subscription, now int64) error {
+	sub.AutoRenew = false
+	sub.CanceledAt = &now
+	sub.NextRenewalAt = nil
+	if sub.Status == dbm.SubStatusPastDue {
+		sub.Status = dbm.SubStatusActive
+		if now >= sub.EndsAt {
+			sub.Status = dbm.SubStatusExpired
+		}
+	}
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(sub).Updates(map[string]any{
+			"status":          sub.Status,
+			"auto_renew":      false,
+			"canceled_at":     now,
+			"next_renewal_at": nil,
+		}).Error; err != nil {
+			return err
+		}
+		return p.snapshotSubscription(tx, sub)
+	})
+	if err != nil {
+		return err
+	}
+
+	planName := subscriptionPlanName(sub)
+	body := fmt.Sprintf("%s is no longer offered, so your subscription will not renew. You keep every premium feature until %s; after that your account goes back to the free plan. Have a look at our current plans any time.",
+		planName, utils.FromUnixSecondsVN(sub.EndsAt).Format("02/01/2006"))
+	if sub.Status == dbm.SubStatusExpired {
+		body = fmt.Sprintf("%s is no longer offered, so your subscription could not be renewed and your account is back on the free plan. Have a look at our current plans any time.", planName)
+	}
+	p.sendSubscriptionMail(ctx, sub, "Your Vivu subscription will not renew", body, "See plans", p.appURL+"/billing")
+	return nil
+}
+
+// expireUnpaidSubscription downgrades the account at the end of the grace period.
+func (p *paymentService) expireUnpaidSubscription(ctx context.Context, sub *dbm.Subscription, now int64) error {
+	sub.Status = dbm.SubStatusExpired
+	sub.NextRenewalAt = nil
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(sub).Updates(map[string]any{
+			"status":          sub.Status,
+			"next_renewal_at": nil,
+		}).Error; err != nil {
+			return err
+		}
+		return p.snapshotSubscription(tx, sub)
+	})
+	if err != nil {
+		return err
+	}
+
+	p.events.Publish(ctx, sub.AccountID, EventSubscriptionExpired, subscriptionEvent(sub))
+	body := fmt.Sprintf("We could not renew your %s subscription, so your account is back on the free plan. Your journeys are all still there; subscribe again any time to get every premium feature back.",
+		subscriptionPlanName(sub))
+	p.sendSubscriptionMail(ctx, sub, "Your Vivu subscription has ended", body, "See plans", p.appURL+"/billing")
+	return nil
+}
+
+// sendDunningMail emails the renewal checkout: a reminder before the end, then that the
+// renewal is overdue and when the account goes back to the free plan.
+func (p *paymentService) sendDunningMail(ctx context.Context, sub *dbm.Subscription, plan *dbm.Plan, paymentURL string, now int64) {
+	period := "month"
+	if plan.Period == dbm.PeriodYear {
+		period = "year"
+	}
+	price := formatMinor(plan.PriceMinor, plan.Currency)
+	endsAt := utils.FromUnixSecondsVN(sub.EndsAt).Format("02/01/2006")
+
+	subject := "Renew your Vivu subscription"
+	body := fmt.Sprintf("Your %s subscription ends on %s. Pay %s to renew it for another %s and keep every premium feature.",
+		subscriptionPlanName(sub), endsAt, price, period)
+	if now >= sub.EndsAt || sub.Status == dbm.SubStatusPastDue {
+		graceEnds := utils.FromUnixSecondsVN(sub.EndsAt + int64(p.dunningGrace.Seconds())).Format("02/01/2006")
+		subject = "We could not renew your Vivu subscription"
+		body = fmt.Sprintf("The renewal of your %s subscription is still unpaid. You keep every premium feature until %s; after that your account goes back to the free plan. Pay %s to renew it for another %s.",
+			subscriptionPlanName(sub), graceEnds, price, period)
+	}
+	p.sendSubscriptionMail(ctx, sub, subject, body, "Renew now", paymentURL)
+}
+
+// sendSubscriptionMail emails the account a note about its subscription with a button to url.
+// The mail outbox retries it; failing to queue it does not undo the change.
+func (p *paymentService) sendSubscriptionMail(ctx context.Context, sub *dbm.Subscription, subject, body, cta, url string) {
+	if p.mail == nil {
+		return
+	}
+	var account dbm.Account
+	if err := p.db.WithContext(ctx).Select("id", "email").First(&account, "id = ?", sub.AccountID).Error; err != nil {
+		log.Printf("[subscriptions] account of %s: %v", sub.ID, err)
+		return
+	}
+	if err := p.mail.SendMailToNotifyUser(account.Email, subject, body, cta, url); err != nil {
+		log.Printf("[subscriptions] mail to %s: %v", account.Email, err)
+	}
+}
+
+func subscriptionPlanName(sub *dbm.Subscription) string {
+	if sub.Plan.Name == "" {
+		return "Vivu Premium"
+	}
+	return sub.Plan.Name
+}
+
+// failRenewal marks the subscription a failed renewal checkout was for as past due. It keeps
+// the plan; the renewal job retries on its schedule.
+func (p *paymentService) failRenewal(tx *gorm.DB, txn *dbm.Transaction) error {
+	var m struct {
+		RenewalOf uuid.UUID `json:"renewal_of"`
+	}
+	if err := json.Unmarshal(txn.Metadata, &m); err != nil || m.RenewalOf == uuid.Nil {
+		return nil
+	}
+	now := time.Now().Unix()
+	return tx.Model(&dbm.Subscription{}).
+		Where("id = ? AND status = ?", m.RenewalOf, dbm.SubStatusActive).
+		Updates(map[string]any{
+			"status":      dbm.SubStatusPastDue,
+			"past_due_at": gorm.Expr("COALESCE(past_due_at, ?)", now),
+		}).Error
+}
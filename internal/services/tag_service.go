@@ -2,7 +2,10 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"log"
+
+	"github.com/google/uuid"
 	"vivu/internal/models/db_models"
 	"vivu/internal/models/request_models"
 	"vivu/internal/models/response_models"
@@ -13,8 +16,13 @@ import (
 type TagServiceInterface interface {
 	GetAllTags(page int, pageSize int, ctx context.Context) ([]response_models.TagResponse, error)
 	InsertTagTx(tag request_models.CreateTagRequest, ctx context.Context) error
+	// BulkAssignTag tags the listed POIs, or every POI a filter matches, in one transaction.
+	BulkAssignTag(ctx context.Context, req request_models.BulkAssignTagRequest) (*response_models.BulkAssignTagResponse, error)
 }
 
+// A bulk assignment tags at most this many POIs; a filter matching more has to be narrowed.
+const bulkTagMaxPOIs = 1000
+
 type TagService struct {
 	tagRepo repositories.TagRepositoryInterface
 }
@@ -60,6 +68,79 @@ func (t *TagService) GetAllTags(page int, pageSize int, ctx context.Context) ([]
 	return tagResponses, nil
 }
 
+func (t *TagService) BulkAssignTag(ctx context.Context, req request_models.BulkAssignTagRequest) (*response_models.BulkAssignTagResponse, error) {
+	if (len(req.PoiIDs) > 0) == (req.Filter != nil) {
+		return nil, utils.ErrInvalidInput.WithMessage("Send either poi_ids or a filter")
+	}
+	tag, err := t.tagRepo.GetTagByID(req.TagID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if tag == nil {
+		return nil, utils.ErrTagNotFound
+	}
+
+	var ids []uuid.UUID
+	var filter repositories.POITagFilter
+	if req.Filter != nil {
+		f := req.Filter
+		if f.ProvinceID == "" && f.CategoryID == "" && f.Name == "" {
+			return nil, utils.ErrInvalidInput.WithMessage("The filter needs a province, a category or a name")
+		}
+		if f.ProvinceID != "" {
+			id := uuid.MustParse(f.ProvinceID)
+			filter.ProvinceID = &id
+		}
+		if f.CategoryID != "" {
+			id := uuid.MustParse(f.CategoryID)
+			filter.CategoryID = &id
+		}
+		filter.Name = f.Name
+	} else {
+		seen := map[uuid.UUID]bool{}
+		for _, s := range req.PoiIDs {
+			if id := uuid.MustParse(s); !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	matched, err := t.tagRepo.FindPOIIDs(ctx, ids, filter, bulkTagMaxPOIs+1)
+	if err != nil {
+		log.Printf("[tags] bulk assign of %s: %v", tag.ID, err)
+		return nil, utils.ErrDatabaseError
+	}
+	if len(matched) > bulkTagMaxPOIs {
+		return nil, utils.ErrInvalidInput.WithMessage(fmt.Sprintf("The filter matches more than %d POIs; narrow it", bulkTagMaxPOIs))
+	}
+
+	resp := &response_models.BulkAssignTagResponse{TagID: tag.ID.String(), Matched: len(matched)}
+	if ids != nil {
+		found := make(map[uuid.UUID]bool, len(matched))
+		for _, id := range matched {
+			found[id] = true
+		}
+		for _, id := range ids {
+			if !found[id] {
+				resp.MissingPoiIDs = append(resp.MissingPoiIDs, id.String())
+			}
+		}
+	}
+	if len(matched) == 0 {
+		return resp, nil
+	}
+
+	tagged, err := t.tagRepo.AssignTag(ctx, tag.ID, matched)
+	if err != nil {
+		log.Printf("[tags] bulk assign of %s: %v", tag.ID, err)
+		return nil, utils.ErrDatabaseError
+	}
+	resp.Assigned = len(tagged)
+	resp.AlreadyTagged = len(matched) - len(tagged)
+	return resp, nil
+}
+
 func NewTagService(tagRepo repositories.TagRepositoryInterface) TagServiceInterface {
 	return &TagService{
 		tagRepo: tagRepo,
@@ -0,0 +1,364 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+const (
+	// MaxTravelDocumentBytes caps one uploaded file or email attachment.
+	MaxTravelDocumentBytes = 10 << 20
+	// MaxInboundEmailBytes caps a forwarded email with its attachments; the raw email is
+	// filed under this cap, not the per-document one.
+	MaxInboundEmailBytes = 25 << 20
+
+	inboxAddressPrefix = "trip-"
+)
+
+// servableDocumentTypes are served with their own Content-Type; anything else (an uploader or
+// an email sender can claim text/html) goes out as application/octet-stream.
+var servableDocumentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/webp":      true,
+	"image/gif":       true,
+	"image/heic":      true,
+	"image/heif":      true,
+	"text/plain":      true,
+	"text/calendar":   true,
+}
+
+// DocumentServeType returns the Content-Type a stored document is downloaded with.
+func DocumentServeType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !servableDocumentTypes[strings.ToLower(mediaType)] {
+		return "application/octet-stream"
+	}
+	return contentType
+}
+
+type TravelDocumentServiceInterface interface {
+	// Upload stores a file in the journey's vault (owner and editors).
+	Upload(ctx context.Context, journeyID, userID, fileName, contentType string, data []byte) (*response_models.TravelDocument, error)
+	List(ctx context.Context, journeyID, userID string) ([]response_models.TravelDocument, error)
+	// Download returns a document and its decrypted content (any participant).
+	Download(ctx context.Context, journeyID, userID, documentID string) (*response_models.TravelDocument, []byte, error)
+	// Delete removes a document (its uploader or the journey owner).
+	Delete(ctx context.Context, journeyID, userID, documentID string) error
+
+	// Inbox returns the journey's forwarding address, creating it on first use.
+	Inbox(ctx context.Context, journeyID, userID string) (*response_models.JourneyInbox, error)
+	// ReceiveEmail files a raw email posted by the inbound mail provider. Emails from addresses
+	// that are not participants of the journey are refused.
+	ReceiveEmail(ctx context.Context, secret string, raw []byte) (*response_models.InboundMailResult, error)
+}
+
+type TravelDocumentService struct {
+	repo     repositories.TravelDocumentRepositoryInterface
+	pollRepo repositories.JourneyPollRepositoryInterface
+	storage  FileStorage
+
+	inboxDomain   string
+	inboundSecret string
+}
+
+// NewTravelDocumentService reads INBOUND_MAIL_DOMAIN (default inbox.vivu.com) and
+// INBOUND_MAIL_SECRET; without a secret the inbound endpoint is disabled.
+func NewTravelDocumentService(repo repositories.TravelDocumentRepositoryInterface, pollRepo repositories.JourneyPollRepositoryInterface, storage FileStorage) TravelDocumentServiceInterface {
+	s := &TravelDocumentService{
+		repo:          repo,
+		pollRepo:      pollRepo,
+		storage:       storage,
+		inboxDomain:   "inbox.vivu.com",
+		inboundSecret: os.Getenv("INBOUND_MAIL_SECRET"),
+	}
+	if d := strings.TrimSpace(os.Getenv("INBOUND_MAIL_DOMAIN")); d != "" {
+		s.inboxDomain = strings.ToLower(d)
+	}
+	return s
+}
+
+func (s *TravelDocumentService) Upload(ctx context.Context, journeyID, userID, fileName, contentType string, data []byte) (*response_models.TravelDocument, error) {
+	journey, _, role, err := journeyAccess(ctx, s.pollRepo, journeyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role == db_models.JourneyRoleViewer {
+		return nil, utils.ErrUnauthorized.WithMessage("Viewers cannot add documents")
+	}
+	doc, err := s.store(ctx, journey.ID, uuid.MustParse(userID), db_models.TravelDocumentUpload, fileName, contentType, data, MaxTravelDocumentBytes, nil)
+	if err != nil {
+		return nil, err
+	}
+	out := toTravelDocumentResponse(*doc)
+	return &out, nil
+}
+
+// store saves the content first, so a row never points at a missing file. maxBytes caps the
+// content: MaxTravelDocumentBytes for files, MaxInboundEmailBytes for a whole raw email.
+func (s *TravelDocumentService) store(ctx context.Context, journeyID, accountID uuid.UUID, kind, fileName, contentType string, data []byte, maxBytes int, parsed *response_models.BookingEmailInfo) (*db_models.TravelDocument, error) {
+	if len(data) == 0 {
+		return nil, utils.ErrInvalidInput.WithMessage("file is empty")
+	}
+	if len(data) > maxBytes {
+		return nil, utils.ErrInvalidInput.WithMessage(fmt.Sprintf("file is too large (max %d MB)", maxBytes>>20))
+	}
+	fileName = sanitizeFileName(fileName)
+	if contentType == "" || contentType == "application/octet-stream" {
+		if byExt := mime.TypeByExtension(filepath.Ext(fileName)); byExt != "" {
+			contentType = byExt
+		} else {
+			contentType = http.DetectContentType(data)
+		}
+	}
+
+	doc := &db_models.TravelDocument{
+		BaseModel:   db_models.BaseModel{ID: uuid.New()},
+		JourneyID:   journeyID,
+		AccountID:   accountID,
+		Kind:        kind,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Parsed:      parsed,
+	}
+	doc.StorageKey = fmt.Sprintf("journeys/%s/%s", journeyID, doc.ID)
+	if err := s.storage.Put(ctx, doc.StorageKey, data); err != nil {
+		if errors.Is(err, utils.ErrEncryptionKeyMissing) {
+			return nil, utils.ErrStorageNotConfigured
+		}
+		return nil, utils.ErrInternal.Wrap(err)
+	}
+	if err := s.repo.Create(ctx, doc); err != nil {
+		_ = s.storage.Delete(ctx, doc.StorageKey)
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	return doc, nil
+}
+
+func (s *TravelDocumentService) List(ctx context.Context, journeyID, userID string) ([]response_models.TravelDocument, error) {
+	journey, _, _, err := journeyAccess(ctx, s.pollRepo, journeyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := s.repo.ListByJourney(ctx, journey.ID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	out := make([]response_models.TravelDocument, 0, len(docs))
+	for _, d := range docs {
+		out = append(out, toTravelDocumentResponse(d))
+	}
+	return out, nil
+}
+
+func (s *TravelDocumentService) Download(ctx context.Context, journeyID, userID, documentID string) (*response_models.TravelDocument, []byte, error) {
+	doc, _, err := s.document(ctx, journeyID, userID, documentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := s.storage.Get(ctx, doc.StorageKey)
+	if errors.Is(err, ErrStoredFileNotFound) {
+		return nil, nil, utils.RecordNotFound.WithMessage("The file of this document is missing")
+	}
+	if errors.Is(err, utils.ErrEncryptionKeyMissing) {
+		return nil, nil, utils.ErrStorageNotConfigured
+	}
+	if err != nil {
+		return nil, nil, utils.ErrInternal.Wrap(err)
+	}
+	out := toTravelDocumentResponse(*doc)
+	return &out, data, nil
+}
+
+func (s *TravelDocumentService) Delete(ctx context.Context, journeyID, userID, documentID string) error {
+	doc, role, err := s.document(ctx, journeyID, userID, documentID)
+	if err != nil {
+		return err
+	}
+	if doc.AccountID.String() != userID && role != db_models.JourneyRoleOwner {
+		return utils.ErrUnauthorized.WithMessage("Only the uploader or the journey owner can delete a document")
+	}
+	if err := s.repo.Delete(ctx, doc.ID); err != nil {
+		return utils.ErrDatabaseError.Wrap(err)
+	}
+	if err := s.storage.Delete(ctx, doc.StorageKey); err != nil {
+		log.Printf("[documents] delete %s: %v", doc.StorageKey, err)
+	}
+	return nil
+}
+
+func (s *TravelDocumentService) document(ctx context.Context, journeyID, userID, documentID string) (*db_models.TravelDocument, string, error) {
+	journey, _, role, err := journeyAccess(ctx, s.pollRepo, journeyID, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	id, err := uuid.Parse(documentID)
+	if err != nil {
+		return nil, "", utils.ErrInvalidInput.WithMessage("Invalid document ID")
+	}
+	doc, err := s.repo.Find(ctx, journey.ID, id)
+	if err != nil {
+		return nil, "", utils.ErrDatabaseError.Wrap(err)
+	}
+	if doc == nil {
+		return nil, "", utils.RecordNotFound
+	}
+	return doc, role, nil
+}
+
+func (s *TravelDocumentService) Inbox(ctx context.Context, journeyID, userID string) (*response_models.JourneyInbox, error) {
+	journey, _, role, err := journeyAccess(ctx, s.pollRepo, journeyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role == db_models.JourneyRoleViewer {
+		return nil, utils.ErrUnauthorized.WithMessage("Viewers cannot add documents")
+	}
+	token := make([]byte, 12)
+	if _, err := rand.Read(token); err != nil {
+		return nil, utils.ErrInternal.Wrap(err)
+	}
+	inbox, err := s.repo.EnsureInbox(ctx, journey.ID, hex.EncodeToString(token))
+	if err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	return &response_models.JourneyInbox{
+		JourneyID: journey.ID,
+		Address:   fmt.Sprintf("%s%s@%s", inboxAddressPrefix, inbox.Token, s.inboxDomain),
+	}, nil
+}
+
+func (s *TravelDocumentService) ReceiveEmail(ctx context.Context, secret string, raw []byte) (*response_models.InboundMailResult, error) {
+	if s.inboundSecret == "" {
+		return nil, utils.RecordNotFound
+	}
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(s.inboundSecret)) != 1 {
+		return nil, utils.ErrUnauthenticated
+	}
+	email, err := parseInboundEmail(raw)
+	if err != nil {
+		return nil, utils.ErrInvalidInput.WithMessage("Cannot parse the email").Wrap(err)
+	}
+
+	var inbox *db_models.JourneyInbox
+	for _, to := range email.Recipients {
+		local, domain, _ := strings.Cut(to, "@")
+		if domain != s.inboxDomain || !strings.HasPrefix(local, inboxAddressPrefix) {
+			continue
+		}
+		if inbox, err = s.repo.FindInboxByToken(ctx, strings.TrimPrefix(local, inboxAddressPrefix)); err != nil {
+			return nil, utils.ErrDatabaseError.Wrap(err)
+		}
+		if inbox != nil {
+			break
+		}
+	}
+	if inbox == nil {
+		return nil, utils.RecordNotFound.WithMessage("No journey inbox among the recipients")
+	}
+
+	journey, members, err := s.journeyWithMembers(ctx, inbox.JourneyID)
+	if err != nil {
+		return nil, err
+	}
+	var sender *db_models.Account
+	for _, a := range journeyParticipants(journey, members) {
+		if strings.EqualFold(a.Email, email.From) {
+			a := a
+			sender = &a
+			break
+		}
+	}
+	if sender == nil {
+		return nil, utils.ErrUnauthorized.WithMessage("The sender is not a participant of this journey")
+	}
+
+	info := bookingEmailInfo(email)
+	subject := email.Subject
+	if subject == "" {
+		subject = "Booking email"
+	}
+	if _, err := s.store(ctx, journey.ID, sender.ID, db_models.TravelDocumentEmail, subject+".eml", "message/rfc822", raw, MaxInboundEmailBytes, info); err != nil {
+		return nil, err
+	}
+	stored := 1
+	for _, a := range email.Attachments {
+		if _, err := s.store(ctx, journey.ID, sender.ID, db_models.TravelDocumentEmailAttachment, a.FileName, a.ContentType, a.Data, MaxTravelDocumentBytes, nil); err != nil {
+			// A bad attachment should not lose the email itself
+			log.Printf("[documents] attachment %q of an email to journey %s: %v", a.FileName, journey.ID, err)
+			continue
+		}
+		stored++
+	}
+	return &response_models.InboundMailResult{JourneyID: journey.ID, Documents: stored}, nil
+}
+
+func (s *TravelDocumentService) journeyWithMembers(ctx context.Context, journeyID uuid.UUID) (*db_models.Journey, []db_models.JourneyMember, error) {
+	journey, err := s.pollRepo.FindJourney(ctx, journeyID.String())
+	if err != nil {
+		return nil, nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	if journey == nil {
+		return nil, nil, utils.ErrJourneyNotFound
+	}
+	members, err := s.pollRepo.ListMembers(ctx, journey.ID)
+	if err != nil {
+		return nil, nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	return journey, members, nil
+}
+
+// sanitizeFileName keeps the base name of an uploaded file, without control characters.
+func sanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == "/" {
+		name = "document"
+	}
+	// Cut by rune so a Vietnamese name never ends in half a character
+	if runes := []rune(name); len(runes) > 200 {
+		ext := []rune(filepath.Ext(name))
+		if len(ext) > 20 {
+			ext = nil
+		}
+		name = string(runes[:200-len(ext)]) + string(ext)
+	}
+	return name
+}
+
+func toTravelDocumentResponse(d db_models.TravelDocument) response_models.TravelDocument {
+	return response_models.TravelDocument{
+		ID:          d.ID,
+		Kind:        d.Kind,
+		FileName:    d.FileName,
+		ContentType: d.ContentType,
+		Size:        d.Size,
+		UploadedBy:  d.AccountID,
+		CreatedAt:   time.Unix(d.CreatedAt, 0).UTC().Format(time.RFC3339),
+		Parsed:      d.Parsed,
+	}
+}
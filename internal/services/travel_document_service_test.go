@@ -0,0 +1,194 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+const documentTestSecret = "inbound-secret"
+
+// documentRepo keeps created documents in memory and knows a single inbox.
+type documentRepo struct {
+	repositories.TravelDocumentRepositoryInterface
+	inbox   db_models.JourneyInbox
+	created []db_models.TravelDocument
+}
+
+func (r *documentRepo) Create(ctx context.Context, doc *db_models.TravelDocument) error {
+	r.created = append(r.created, *doc)
+	return nil
+}
+
+func (r *documentRepo) FindInboxByToken(ctx context.Context, token string) (*db_models.JourneyInbox, error) {
+	if token != r.inbox.Token {
+		return nil, nil
+	}
+	inbox := r.inbox
+	return &inbox, nil
+}
+
+// documentJourneys serves one journey without members.
+type documentJourneys struct {
+	repositories.JourneyPollRepositoryInterface
+	journey db_models.Journey
+}
+
+func (r documentJourneys) FindJourney(ctx context.Context, journeyID string) (*db_models.Journey, error) {
+	if journeyID != r.journey.ID.String() {
+		return nil, nil
+	}
+	journey := r.journey
+	return &journey, nil
+}
+
+func (r documentJourneys) ListMembers(ctx context.Context, journeyID uuid.UUID) ([]db_models.JourneyMember, error) {
+	return nil, nil
+}
+
+type memoryStorage map[string][]byte
+
+func (m memoryStorage) Put(ctx context.Context, key string, data []byte) error {
+	m[key] = data
+	return nil
+}
+
+func (m memoryStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	data, ok := m[key]
+	if !ok {
+		return nil, ErrStoredFileNotFound
+	}
+	return data, nil
+}
+
+func (m memoryStorage) Delete(ctx context.Context, key string) error {
+	delete(m, key)
+	return nil
+}
+
+func newDocumentTestService() (*TravelDocumentService, *documentRepo, db_models.Journey) {
+	journey := db_models.Journey{
+		BaseModel: db_models.BaseModel{ID: uuid.New()},
+		AccountID: uuid.New(),
+		Account:   db_models.Account{Email: "owner@example.com"},
+	}
+	repo := &documentRepo{inbox: db_models.JourneyInbox{JourneyID: journey.ID, Token: "abc123"}}
+	return &TravelDocumentService{
+		repo:          repo,
+		pollRepo:      documentJourneys{journey: journey},
+		storage:       memoryStorage{},
+		inboxDomain:   "inbox.vivu.com",
+		inboundSecret: documentTestSecret,
+	}, repo, journey
+}
+
+// bookingEmail builds a forwarded email from the journey owner with a plain text body and,
+// when attachment is set, one base64 PDF attachment.
+func bookingEmail(body string, attachment []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString("From: Owner <owner@example.com>\r\n")
+	b.WriteString("To: trip-abc123@inbox.vivu.com\r\n")
+	b.WriteString("Subject: Hotel booking\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: multipart/mixed; boundary=\"b\"\r\n\r\n")
+	b.WriteString("--b\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(body)
+	b.WriteString("\r\n")
+	if attachment != nil {
+		b.WriteString("--b\r\nContent-Type: application/pdf\r\n")
+		b.WriteString("Content-Disposition: attachment; filename=\"voucher.pdf\"\r\n")
+		b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+		enc := base64.StdEncoding.EncodeToString(attachment)
+		for len(enc) > 76 {
+			b.WriteString(enc[:76] + "\r\n")
+			enc = enc[76:]
+		}
+		b.WriteString(enc + "\r\n")
+	}
+	b.WriteString("--b--\r\n")
+	return b.Bytes()
+}
+
+func TestReceiveEmailSizeLimits(t *testing.T) {
+	const elevenMB = 11 << 20
+
+	tests := []struct {
+		name      string
+		raw       []byte
+		wantErr   error
+		wantKinds []string
+	}{
+		{
+			name:      "small email",
+			raw:       bookingEmail("See you soon", []byte("%PDF-1.4 voucher")),
+			wantKinds: []string{db_models.TravelDocumentEmail, db_models.TravelDocumentEmailAttachment},
+		},
+		{
+			// base64 makes the 8.25 MB attachment an 11 MB email
+			name:      "11 MB email",
+			raw:       bookingEmail("See you soon", make([]byte, elevenMB*3/4)),
+			wantKinds: []string{db_models.TravelDocumentEmail, db_models.TravelDocumentEmailAttachment},
+		},
+		{
+			name:      "11 MB attachment is skipped, the email is kept",
+			raw:       bookingEmail("See you soon", make([]byte, elevenMB)),
+			wantKinds: []string{db_models.TravelDocumentEmail},
+		},
+		{
+			name:    "over the inbound cap",
+			raw:     bookingEmail("See you soon", make([]byte, MaxInboundEmailBytes*3/4)),
+			wantErr: utils.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo, journey := newDocumentTestService()
+			res, err := svc.ReceiveEmail(context.Background(), documentTestSecret, tt.raw)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ReceiveEmail() error = %v, want %v", err, tt.wantErr)
+				}
+				if len(repo.created) != 0 {
+					t.Fatalf("stored %d documents after an error", len(repo.created))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReceiveEmail() error = %v", err)
+			}
+			if res.JourneyID != journey.ID || res.Documents != len(tt.wantKinds) {
+				t.Fatalf("ReceiveEmail() = %+v, want %d documents for journey %s", res, len(tt.wantKinds), journey.ID)
+			}
+			var kinds []string
+			for _, d := range repo.created {
+				kinds = append(kinds, d.Kind)
+			}
+			if fmt.Sprint(kinds) != fmt.Sprint(tt.wantKinds) {
+				t.Fatalf("stored kinds = %v, want %v", kinds, tt.wantKinds)
+			}
+			if email := repo.created[0]; email.Size != int64(len(tt.raw)) {
+				t.Fatalf("stored email size = %d, want %d", email.Size, len(tt.raw))
+			}
+		})
+	}
+}
+
+func TestUploadSizeLimit(t *testing.T) {
+	svc, repo, journey := newDocumentTestService()
+	_, err := svc.Upload(context.Background(), journey.ID.String(), journey.AccountID.String(), "scan.pdf", "application/pdf", make([]byte, 11<<20))
+	if !errors.Is(err, utils.ErrInvalidInput) {
+		t.Fatalf("Upload() error = %v, want %v", err, utils.ErrInvalidInput)
+	}
+	if len(repo.created) != 0 {
+		t.Fatalf("stored %d documents for an oversized upload", len(repo.created))
+	}
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+// maxTravelPresets keeps the preset picker at quiz start short.
+const maxTravelPresets = 10
+
+type TravelPresetServiceInterface interface {
+	ListPresets(ctx context.Context, accountID string) ([]response_models.TravelPreset, error)
+	CreatePreset(ctx context.Context, accountID string, req request_models.TravelPresetRequest) (*response_models.TravelPreset, error)
+	UpdatePreset(ctx context.Context, accountID, presetID string, req request_models.TravelPresetRequest) (*response_models.TravelPreset, error)
+	DeletePreset(ctx context.Context, accountID, presetID string) error
+
+	// QuizAnswers returns the quiz answers the preset stands for, keyed like mem.QuizSession.Answers.
+	QuizAnswers(ctx context.Context, accountID, presetID string) (map[string]string, error)
+}
+
+type TravelPresetService struct {
+	repo repositories.TravelPresetRepositoryInterface
+}
+
+func NewTravelPresetService(repo repositories.TravelPresetRepositoryInterface) TravelPresetServiceInterface {
+	return &TravelPresetService{repo: repo}
+}
+
+func (s *TravelPresetService) ListPresets(ctx context.Context, accountID string) ([]response_models.TravelPreset, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, utils.ErrInvalidInput
+	}
+	presets, err := s.repo.ListPresets(ctx, id)
+	if err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	out := make([]response_models.TravelPreset, 0, len(presets))
+	for _, p := range presets {
+		out = append(out, toTravelPresetResponse(p))
+	}
+	return out, nil
+}
+
+func (s *TravelPresetService) CreatePreset(ctx context.Context, accountID string, req request_models.TravelPresetRequest) (*response_models.TravelPreset, error) {
+	id, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, utils.ErrInvalidInput
+	}
+	existing, err := s.repo.ListPresets(ctx, id)
+	if err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	if len(existing) >= maxTravelPresets {
+		return nil, utils.ErrInvalidInput.WithMessage("You can save up to 10 presets, delete one first")
+	}
+
+	preset := &db_models.TravelPreset{AccountID: id}
+	if err := s.apply(ctx, preset, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreatePreset(ctx, preset); err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	out := toTravelPresetResponse(*preset)
+	return &out, nil
+}
+
+func (s *TravelPresetService) UpdatePreset(ctx context.Context, accountID, presetID string, req request_models.TravelPresetRequest) (*response_models.TravelPreset, error) {
+	preset, err := s.find(ctx, accountID, presetID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, preset, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SavePreset(ctx, preset); err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	out := toTravelPresetResponse(*preset)
+	return &out, nil
+}
+
+func (s *TravelPresetService) DeletePreset(ctx context.Context, accountID, presetID string) error {
+	aid, err := uuid.Parse(accountID)
+	if err != nil {
+		return utils.ErrInvalidInput
+	}
+	pid, err := uuid.Parse(presetID)
+	if err != nil {
+		return utils.ErrInvalidInput.WithMessage("Invalid preset ID")
+	}
+	found, err := s.repo.DeletePreset(ctx, aid, pid)
+	if err != nil {
+		return utils.ErrDatabaseError.Wrap(err)
+	}
+	if !found {
+		return utils.RecordNotFound.WithMessage("Preset not found")
+	}
+	return nil
+}
+
+func (s *TravelPresetService) QuizAnswers(ctx context.Context, accountID, presetID string) (map[string]string, error) {
+	preset, err := s.find(ctx, accountID, presetID)
+	if err != nil {
+		return nil, err
+	}
+	answers := make(map[string]string)
+	if preset.Budget != "" {
+		answers["budget"] = preset.Budget
+	}
+	if preset.Pace != "" {
+		answers["pace"] = preset.Pace
+	}
+	if len(preset.Interests) > 0 {
+		answers["tags"] = strings.Join(preset.Interests, ",")
+	}
+	if len(preset.Dietary) > 0 {
+		answers["dietary"] = strings.Join(preset.Dietary, ",")
+	}
+	return answers, nil
+}
+
+func (s *TravelPresetService) find(ctx context.Context, accountID, presetID string) (*db_models.TravelPreset, error) {
+	aid, err := uuid.Parse(accountID)
+	if err != nil {
+		return nil, utils.ErrInvalidInput
+	}
+	pid, err := uuid.Parse(presetID)
+	if err != nil {
+		return nil, utils.ErrInvalidInput.WithMessage("Invalid preset ID")
+	}
+	preset, err := s.repo.FindPreset(ctx, aid, pid)
+	if err != nil {
+		return nil, utils.ErrDatabaseError.Wrap(err)
+	}
+	if preset == nil {
+		return nil, utils.RecordNotFound.WithMessage("Preset not found")
+	}
+	return preset, nil
+}
+
+// apply validates req and copies it onto preset.
+func (s *TravelPresetService) apply(ctx context.Context, preset *db_models.TravelPreset, req request_models.TravelPresetRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return utils.ErrInvalidInput.WithMessage("Preset name is required")
+	}
+	if req.Budget != "" && !slices.Contains(quizBudgetOptions, req.Budget) {
+		return utils.ErrInvalidInput.WithMessage("budget must be one of " + strings.Join(quizBudgetOptions, ", "))
+	}
+	taken, err := s.repo.NameTaken(ctx, preset.AccountID, name, preset.ID)
+	if err != nil {
+		return utils.ErrDatabaseError.Wrap(err)
+	}
+	if taken {
+		return utils.ErrPresetNameTaken
+	}
+
+	preset.Name = name
+	preset.Pace = req.Pace
+	preset.Budget = req.Budget
+	preset.Interests = cleanPresetList(req.Interests)
+	preset.Dietary = cleanPresetList(req.Dietary)
+	return nil
+}
+
+// cleanPresetList trims entries and drops empty and repeated ones. Commas are dropped too,
+// since the quiz answers carry these lists comma-separated.
+func cleanPresetList(list []string) []string {
+	out := make([]string, 0, len(list))
+	seen := make(map[string]bool)
+	for _, entry := range list {
+		entry = strings.TrimSpace(strings.ReplaceAll(entry, ",", " "))
+		if entry == "" || seen[strings.ToLower(entry)] {
+			continue
+		}
+		seen[strings.ToLower(entry)] = true
+		out = append(out, entry)
+	}
+	return out
+}
+
+func toTravelPresetResponse(p db_models.TravelPreset) response_models.TravelPreset {
+	return response_models.TravelPreset{
+		ID:        p.ID.String(),
+		Name:      p.Name,
+		Pace:      p.Pace,
+		Budget:    p.Budget,
+		Interests: append([]string{}, p.Interests...),
+		Dietary:   append([]string{}, p.Dietary...),
+	}
+}
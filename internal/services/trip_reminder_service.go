@@ -0,0 +1,381 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+type tripReminderOffset struct {
+	Kind   string
+	Before time.Duration
+}
+
+// tripReminderOffsets is how long before the trip start each reminder goes out, latest last.
+// The itinerary summary goes out itineraryDays before the start.
+func tripReminderOffsets(itineraryDays int) []tripReminderOffset {
+	offsets := []tripReminderOffset{
+		{db_models.TripReminderPacking, 14 * 24 * time.Hour},
+		{db_models.TripReminderWeather, 3 * 24 * time.Hour},
+		{db_models.TripReminderDayOf, 24 * time.Hour},
+	}
+	offsets = append(offsets, tripReminderOffset{db_models.TripReminderItinerary, time.Duration(itineraryDays) * 24 * time.Hour})
+	sort.SliceStable(offsets, func(i, j int) bool { return offsets[i].Before > offsets[j].Before })
+	return offsets
+}
+
+type TripReminderServiceInterface interface {
+	GetReminders(ctx context.Context, journeyID, userID string) (*response_models.TripRemindersResponse, error)
+	// SetReminders turns the pre-trip sequence of one journey on or off.
+	SetReminders(ctx context.Context, journeyID, userID string, enabled bool) (*response_models.TripRemindersResponse, error)
+	// RunOnce schedules reminders of upcoming journeys and sends the ones that are due.
+	RunOnce(ctx context.Context) (int, error)
+
+	Start()
+	Stop()
+}
+
+type TripReminderService struct {
+	repo    repositories.TripReminderRepositoryInterface
+	mail    IMailService
+	appURL  string
+	offsets []tripReminderOffset
+
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	claimLease  time.Duration // a batch this instance claimed stays hidden from the others this long
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// NewTripReminderService reads TRIP_REMINDER_INTERVAL (default 10m), TRIP_ITINERARY_EMAIL_DAYS
+// (1-13, default 2) and APP_PUBLIC_URL for links.
+func NewTripReminderService(repo repositories.TripReminderRepositoryInterface, mail IMailService) TripReminderServiceInterface {
+	s := &TripReminderService{
+		repo:        repo,
+		mail:        mail,
+		appURL:      "https://vivu.com",
+		interval:    10 * time.Minute,
+		batchSize:   100,
+		maxAttempts: 5,
+		claimLease:  5 * time.Minute,
+		stop:        make(chan struct{}),
+	}
+	if d, err := time.ParseDuration(os.Getenv("TRIP_REMINDER_INTERVAL")); err == nil && d > 0 {
+		s.interval = d
+	}
+	if u := strings.TrimRight(os.Getenv("APP_PUBLIC_URL"), "/"); u != "" {
+		s.appURL = u
+	}
+	itineraryDays := 2
+	if n, err := strconv.Atoi(os.Getenv("TRIP_ITINERARY_EMAIL_DAYS")); err == nil && n >= 1 && n <= 13 {
+		itineraryDays = n
+	}
+	s.offsets = tripReminderOffsets(itineraryDays)
+	return s
+}
+
+func (s *TripReminderService) GetReminders(ctx context.Context, journeyID, userID string) (*response_models.TripRemindersResponse, error) {
+	journey, err := s.ownedJourney(ctx, journeyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	// Saved a minute ago? Show the schedule without waiting for the worker
+	if !journey.RemindersOff {
+		if err := s.repo.UpsertSchedule(ctx, tripReminderSchedule(*journey, time.Now(), s.offsets)); err != nil {
+			return nil, utils.ErrDatabaseError
+		}
+	}
+	return s.remindersResponse(ctx, journey)
+}
+
+func (s *TripReminderService) SetReminders(ctx context.Context, journeyID, userID string, enabled bool) (*response_models.TripRemindersResponse, error) {
+	journey, err := s.ownedJourney(ctx, journeyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetRemindersOff(ctx, journey.ID, !enabled); err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	return s.GetReminders(ctx, journeyID, userID)
+}
+
+func (s *TripReminderService) ownedJourney(ctx context.Context, journeyID, userID string) (*db_models.Journey, error) {
+	if _, err := uuid.Parse(journeyID); err != nil {
+		return nil, utils.ErrInvalidInput
+	}
+	journey, err := s.repo.FindJourney(ctx, journeyID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if journey == nil {
+		return nil, utils.ErrJourneyNotFound
+	}
+	if journey.AccountID.String() != userID {
+		return nil, utils.ErrUnauthorized
+	}
+	return journey, nil
+}
+
+func (s *TripReminderService) remindersResponse(ctx context.Context, journey *db_models.Journey) (*response_models.TripRemindersResponse, error) {
+	rows, err := s.repo.ListForJourney(ctx, journey.ID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	out := &response_models.TripRemindersResponse{
+		JourneyID:      journey.ID,
+		Enabled:        !journey.RemindersOff,
+		AccountEnabled: !journey.Account.TripEmailsOff,
+		Reminders:      make([]response_models.TripReminderItem, 0, len(rows)),
+	}
+	for _, r := range rows {
+		item := response_models.TripReminderItem{
+			Kind:   r.Kind,
+			DueAt:  time.Unix(r.DueAt, 0).UTC().Format(time.RFC3339),
+			Status: "scheduled",
+		}
+		switch {
+		case r.SentAt != nil && r.Skipped:
+			item.Status = "skipped"
+		case r.SentAt != nil:
+			item.Status = "sent"
+			item.SentAt = time.Unix(*r.SentAt, 0).UTC().Format(time.RFC3339)
+		case r.Attempts >= s.maxAttempts:
+			item.Status = "failed"
+		}
+		out.Reminders = append(out.Reminders, item)
+	}
+	return out, nil
+}
+
+// tripReminderSchedule is the reminder set of a journey that has not started yet.
+func tripReminderSchedule(j db_models.Journey, now time.Time, offsets []tripReminderOffset) []db_models.TripReminder {
+	if j.StartDate <= now.Unix() {
+		return nil
+	}
+	start := time.Unix(j.StartDate, 0)
+	out := make([]db_models.TripReminder, 0, len(offsets))
+	for _, o := range offsets {
+		out = append(out, db_models.TripReminder{
+			JourneyID: j.ID,
+			Kind:      o.Kind,
+			TripStart: j.StartDate,
+			DueAt:     start.Add(-o.Before).Unix(),
+		})
+	}
+	return out
+}
+
+func (s *TripReminderService) RunOnce(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	// Journeys saved or moved since the last run; the earliest reminder is due 14 days out
+	horizon := now.Add(s.offsets[0].Before + s.interval)
+	journeys, err := s.repo.UpcomingJourneys(ctx, now.Unix(), horizon.Unix())
+	if err != nil {
+		return 0, err
+	}
+	var rows []db_models.TripReminder
+	for _, j := range journeys {
+		rows = append(rows, tripReminderSchedule(j, now, s.offsets)...)
+	}
+	if err := s.repo.UpsertSchedule(ctx, rows); err != nil {
+		return 0, err
+	}
+
+	due, err := s.repo.ClaimDue(ctx, now.Unix(), s.batchSize, s.maxAttempts, s.claimLease)
+	if err != nil {
+		return 0, err
+	}
+
+	// A journey saved 2 days before the start has its packing and weather reminders due at once:
+	// send only the latest one
+	byJourney := make(map[uuid.UUID][]db_models.TripReminder)
+	for _, r := range due {
+		byJourney[r.JourneyID] = append(byJourney[r.JourneyID], r)
+	}
+	sent := 0
+	for _, list := range byJourney {
+		sort.Slice(list, func(i, j int) bool { return list[i].DueAt < list[j].DueAt })
+		latest := list[len(list)-1]
+
+		var superseded []uuid.UUID
+		for _, r := range list[:len(list)-1] {
+			superseded = append(superseded, r.ID)
+		}
+		if err := s.repo.MarkSent(ctx, superseded, true); err != nil {
+			return sent, err
+		}
+
+		if err := s.send(latest); err != nil {
+			log.Printf("[reminders] %s for journey %s: %v", latest.Kind, latest.JourneyID, err)
+			if markErr := s.repo.MarkFailed(ctx, latest.ID, err.Error()); markErr != nil {
+				return sent, markErr
+			}
+			continue
+		}
+		if err := s.repo.MarkSent(ctx, []uuid.UUID{latest.ID}, false); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+func (s *TripReminderService) send(r db_models.TripReminder) error {
+	to := r.Journey.Account.Email
+	if to == "" {
+		return fmt.Errorf("account has no email")
+	}
+	if s.mail == nil {
+		return fmt.Errorf("mail service is not configured")
+	}
+	link := fmt.Sprintf("%s/journeys/%s", s.appURL, r.JourneyID)
+	if r.Kind == db_models.TripReminderItinerary {
+		locale := mailLocale(r.Journey.Account.Locale)
+		start := time.Unix(r.Journey.StartDate, 0).In(vnLoc)
+		return s.mail.SendLocalized(to, locale, MailTemplateTripItinerary, map[string]string{
+			"where": tripWhere(&r.Journey),
+			"start": start.Format("02/01/2006"),
+			"plan":  tripItineraryPlan(&r.Journey, start, locale),
+			"url":   link,
+		})
+	}
+	subject, body := tripReminderMessage(r.Kind, &r.Journey)
+	return s.mail.SendMailToNotifyUser(to, subject, body, "Open my trip", link)
+}
+
+func tripWhere(j *db_models.Journey) string {
+	if j.Location != "" {
+		return j.Location
+	}
+	return j.Title
+}
+
+func tripReminderMessage(kind string, j *db_models.Journey) (string, string) {
+	where := tripWhere(j)
+	start := time.Unix(j.StartDate, 0).In(vnLoc)
+	days := len(j.Days)
+
+	switch kind {
+	case db_models.TripReminderPacking:
+		lines := []string{
+			fmt.Sprintf("Two weeks to go until %s (%s, %d days). Time to start packing:", where, start.Format("02/01/2006"), days),
+			"• Passport or ID card, and a copy of your bookings",
+			"• Phone charger, power bank and a Type A/C plug adapter",
+			"• Light clothes, a layer for air-conditioned buses and a rain jacket",
+			"• Sunscreen, insect repellent and any regular medicine",
+			"• Some cash in VND for street food and small shops",
+		}
+		return "Your trip is in 2 weeks: packing list", strings.Join(lines, "\n")
+
+	case db_models.TripReminderWeather:
+		body := fmt.Sprintf("Three days until %s. Have a look at the forecast now.", where)
+		for _, d := range j.Days {
+			if len(d.RainyPlan) > 0 {
+				body += " If rain is expected, your plan already has indoor alternatives: swap a day in one tap from the trip page."
+				break
+			}
+		}
+		return "Check the weather for your trip", body
+
+	default: // day_of
+		body := fmt.Sprintf("Your trip to %s starts tomorrow! Open the trip on the day to follow it step by step.", where)
+		if first := firstActivity(j); first != nil && first.SelectedPOI.Name != "" {
+			body += fmt.Sprintf(" First stop: %s at %s.", first.SelectedPOI.Name, first.Time.In(vnLoc).Format("15:04"))
+		}
+		return "Your trip starts tomorrow", body
+	}
+}
+
+// viWeekdays are the usual Vietnamese short names, Sunday first like time.Weekday.
+var viWeekdays = [7]string{"CN", "T2", "T3", "T4", "T5", "T6", "T7"}
+
+// tripItineraryPlan lists the stops of each day in order, in VN time, in locale (vi or en).
+func tripItineraryPlan(j *db_models.Journey, start time.Time, locale string) string {
+	days := append([]db_models.JourneyDay(nil), j.Days...)
+	sort.Slice(days, func(a, b int) bool { return days[a].DayNumber < days[b].DayNumber })
+
+	var lines []string
+	for _, d := range days {
+		date := start.AddDate(0, 0, d.DayNumber-1)
+		heading, free := fmt.Sprintf("Day %d (%s)", d.DayNumber, date.Format("Mon 02/01")), "• Free day"
+		if locale == "vi" {
+			heading = fmt.Sprintf("Ngày %d (%s %s)", d.DayNumber, viWeekdays[date.Weekday()], date.Format("02/01"))
+			free = "• Ngày tự do"
+		}
+		lines = append(lines, "", heading)
+
+		activities := append([]db_models.JourneyActivity(nil), d.Activities...)
+		sort.Slice(activities, func(a, b int) bool { return activities[a].Time.Before(activities[b].Time) })
+		if len(activities) == 0 {
+			lines = append(lines, free)
+		}
+		for _, a := range activities {
+			name := a.SelectedPOI.Name
+			if name == "" {
+				name = a.ActivityType
+			}
+			lines = append(lines, fmt.Sprintf("• %s %s", a.Time.In(vnLoc).Format("15:04"), name))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func firstActivity(j *db_models.Journey) *db_models.JourneyActivity {
+	var first *db_models.JourneyActivity
+	for di := range j.Days {
+		if j.Days[di].DayNumber != 1 {
+			continue
+		}
+		for ai := range j.Days[di].Activities {
+			a := &j.Days[di].Activities[ai]
+			if first == nil || a.Time.Before(first.Time) {
+				first = a
+			}
+		}
+	}
+	return first
+}
+
+// Start schedules and sends reminders in the background until Stop is called.
+func (s *TripReminderService) Start() {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+			n, err := s.RunOnce(ctx)
+			cancel()
+			if err != nil {
+				log.Printf("[reminders] run failed: %v", err)
+			} else if n > 0 {
+				log.Printf("[reminders] sent %d pre-trip reminders", n)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (s *TripReminderService) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
@@ -0,0 +1,545 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/datatypes"
+	"vivu/internal/models/db_models"
+	"vivu/internal/models/request_models"
+	"vivu/internal/models/response_models"
+	"vivu/internal/repositories"
+	"vivu/pkg/utils"
+)
+
+const (
+	webhookSignatureHeader = "X-Vivu-Signature"
+	webhookMaxEndpoints    = 10 // per account
+	webhookBatchSize       = 50
+	webhookSenders         = 8
+	webhookClaimLease      = 2 * time.Minute
+	webhookMaxAttempts     = 8
+	webhookFirstRetry      = 30 * time.Second // doubled after every failed attempt
+	webhookMaxRetry        = 6 * time.Hour
+)
+
+type WebhookServiceInterface interface {
+	EventBus
+
+	// ListEndpoints returns the endpoints of the account; admins also see the ones receiving
+	// every account's events.
+	ListEndpoints(ctx context.Context, userID string, admin bool) ([]response_models.WebhookEndpoint, error)
+	// CreateEndpoint returns the signing secret, which is never shown again.
+	CreateEndpoint(ctx context.Context, userID string, admin bool, req request_models.CreateWebhookRequest) (*response_models.CreatedWebhookEndpoint, error)
+	UpdateEndpoint(ctx context.Context, userID string, admin bool, endpointID string, req request_models.UpdateWebhookRequest) (*response_models.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, userID string, admin bool, endpointID string) error
+	// ListDeliveries is the delivery log of one endpoint, or of every endpoint when endpointID
+	// is empty (admins only).
+	ListDeliveries(ctx context.Context, userID string, admin bool, endpointID string, query request_models.WebhookDeliveryQuery) (*response_models.WebhookDeliveryPage, error)
+
+	// RunOnce sends the deliveries that are due and returns how many went through.
+	RunOnce(ctx context.Context) (int, error)
+	Start()
+	Stop()
+}
+
+type WebhookService struct {
+	repo      repositories.WebhookRepositoryInterface
+	client    *http.Client
+	interval  time.Duration
+	allowHTTP bool
+
+	kick     chan struct{}
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// NewWebhookService reads WEBHOOK_DELIVERY_INTERVAL (default 10s) and WEBHOOK_ALLOW_HTTP,
+// which lets endpoints use plain http and private addresses (local development only).
+func NewWebhookService(repo repositories.WebhookRepositoryInterface) WebhookServiceInterface {
+	s := &WebhookService{
+		repo:     repo,
+		interval: 10 * time.Second,
+		kick:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+	}
+	if d, err := time.ParseDuration(os.Getenv("WEBHOOK_DELIVERY_INTERVAL")); err == nil && d > 0 {
+		s.interval = d
+	}
+	s.allowHTTP, _ = strconv.ParseBool(os.Getenv("WEBHOOK_ALLOW_HTTP"))
+	s.client = webhookHTTPClient(s.allowHTTP)
+	return s
+}
+
+// webhookHTTPClient refuses to connect to loopback, private and link-local addresses, so an
+// endpoint URL cannot reach into our own network, whatever its host resolves to.
+func webhookHTTPClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("refusing to deliver to %s", host)
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: transport,
+		// a redirect would be followed without the checks above on the URL
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+}
+
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsMulticast())
+}
+
+type webhookEvent struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	CreatedAt string `json:"created_at"`
+	AccountID string `json:"account_id"`
+	Data      any    `json:"data"`
+}
+
+func (s *WebhookService) Publish(ctx context.Context, accountID uuid.UUID, eventType string, data any) {
+	// the event outlives the request that raised it
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	endpoints, err := s.repo.MatchingEndpoints(ctx, accountID, eventType)
+	if err != nil {
+		log.Printf("[webhooks] dropping %s for %s: %v", eventType, accountID, err)
+		return
+	}
+	if len(endpoints) == 0 {
+		return
+	}
+
+	now := time.Now()
+	event := webhookEvent{
+		ID:        uuid.NewString(),
+		Type:      eventType,
+		CreatedAt: utils.FormatRFC3339VN(now.In(vnLoc)),
+		AccountID: accountID.String(),
+		Data:      data,
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("[webhooks] dropping %s for %s: %v", eventType, accountID, err)
+		return
+	}
+
+	eventID := uuid.MustParse(event.ID)
+	deliveries := make([]db_models.WebhookDelivery, 0, len(endpoints))
+	for _, e := range endpoints {
+		deliveries = append(deliveries, db_models.WebhookDelivery{
+			EndpointID:    e.ID,
+			EventID:       eventID,
+			EventType:     eventType,
+			Payload:       datatypes.JSON(body),
+			Status:        db_models.WebhookDeliveryPending,
+			NextAttemptAt: now.Unix(),
+		})
+	}
+	if err := s.repo.CreateDeliveries(ctx, deliveries); err != nil {
+		log.Printf("[webhooks] dropping %s for %s: %v", eventType, accountID, err)
+		return
+	}
+
+	select {
+	case s.kick <- struct{}{}:
+	default:
+	}
+}
+
+func (s *WebhookService) ListEndpoints(ctx context.Context, userID string, admin bool) ([]response_models.WebhookEndpoint, error) {
+	accountID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, utils.ErrInvalidInput
+	}
+	endpoints, err := s.repo.ListEndpoints(ctx, accountID, admin)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	out := make([]response_models.WebhookEndpoint, 0, len(endpoints))
+	for i := range endpoints {
+		out = append(out, toWebhookEndpointResponse(&endpoints[i]))
+	}
+	return out, nil
+}
+
+func (s *WebhookService) CreateEndpoint(ctx context.Context, userID string, admin bool, req request_models.CreateWebhookRequest) (*response_models.CreatedWebhookEndpoint, error) {
+	accountID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, utils.ErrInvalidInput
+	}
+	if req.AllAccounts && !admin {
+		return nil, utils.ErrUnauthorized.WithMessage("Only admins can receive the events of every account")
+	}
+	if err := s.checkEndpointURL(req.URL); err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.ListEndpoints(ctx, accountID, false)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if len(existing) >= webhookMaxEndpoints {
+		return nil, utils.ErrInvalidInput.WithMessage(fmt.Sprintf("An account can have at most %d webhooks", webhookMaxEndpoints))
+	}
+
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return nil, utils.ErrInternal.Wrap(err)
+	}
+	secret := "whsec_" + hex.EncodeToString(b)
+	sealed, err := utils.EncryptString(secret)
+	if errors.Is(err, utils.ErrEncryptionKeyMissing) {
+		return nil, utils.ErrStorageNotConfigured
+	}
+	if err != nil {
+		return nil, utils.ErrInternal.Wrap(err)
+	}
+
+	endpoint := &db_models.WebhookEndpoint{
+		URL:         strings.TrimSpace(req.URL),
+		Description: strings.TrimSpace(req.Description),
+		Events:      pq.StringArray(uniqueStrings(req.Events)),
+		Secret:      sealed,
+		Active:      true,
+		CreatedBy:   userID,
+	}
+	if !req.AllAccounts {
+		endpoint.AccountID = &accountID
+	}
+	if err := s.repo.CreateEndpoint(ctx, endpoint); err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	return &response_models.CreatedWebhookEndpoint{WebhookEndpoint: toWebhookEndpointResponse(endpoint), Secret: secret}, nil
+}
+
+func (s *WebhookService) UpdateEndpoint(ctx context.Context, userID string, admin bool, endpointID string, req request_models.UpdateWebhookRequest) (*response_models.WebhookEndpoint, error) {
+	endpoint, err := s.ownedEndpoint(ctx, userID, admin, endpointID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkEndpointURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	endpoint.URL = strings.TrimSpace(req.URL)
+	endpoint.Description = strings.TrimSpace(req.Description)
+	endpoint.Events = pq.StringArray(uniqueStrings(req.Events))
+	endpoint.Active = req.Active
+	found, err := s.repo.UpdateEndpoint(ctx, endpoint)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if !found {
+		return nil, utils.RecordNotFound.WithMessage("Webhook not found")
+	}
+	out := toWebhookEndpointResponse(endpoint)
+	return &out, nil
+}
+
+func (s *WebhookService) DeleteEndpoint(ctx context.Context, userID string, admin bool, endpointID string) error {
+	if _, err := s.ownedEndpoint(ctx, userID, admin, endpointID); err != nil {
+		return err
+	}
+	found, err := s.repo.DeleteEndpoint(ctx, endpointID)
+	if err != nil {
+		return utils.ErrDatabaseError
+	}
+	if !found {
+		return utils.RecordNotFound.WithMessage("Webhook not found")
+	}
+	return nil
+}
+
+func (s *WebhookService) ListDeliveries(ctx context.Context, userID string, admin bool, endpointID string, query request_models.WebhookDeliveryQuery) (*response_models.WebhookDeliveryPage, error) {
+	filter := repositories.WebhookDeliveryFilter{Status: query.Status, EventType: query.Event}
+	switch {
+	case endpointID != "":
+		endpoint, err := s.ownedEndpoint(ctx, userID, admin, endpointID)
+		if err != nil {
+			return nil, err
+		}
+		filter.EndpointID = &endpoint.ID
+	case !admin:
+		return nil, utils.ErrUnauthorized
+	}
+
+	rows, total, err := s.repo.ListDeliveries(ctx, filter, query.Page, query.PageSize)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	out := &response_models.WebhookDeliveryPage{
+		Items:    make([]response_models.WebhookDelivery, 0, len(rows)),
+		Total:    total,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	}
+	for i := range rows {
+		out.Items = append(out.Items, toWebhookDeliveryResponse(&rows[i]))
+	}
+	return out, nil
+}
+
+// ownedEndpoint loads an endpoint the caller may manage: one of their account, or a global
+// one for admins.
+func (s *WebhookService) ownedEndpoint(ctx context.Context, userID string, admin bool, endpointID string) (*db_models.WebhookEndpoint, error) {
+	if _, err := uuid.Parse(endpointID); err != nil {
+		return nil, utils.ErrInvalidInput.WithMessage("Invalid webhook ID")
+	}
+	endpoint, err := s.repo.FindEndpoint(ctx, endpointID)
+	if err != nil {
+		return nil, utils.ErrDatabaseError
+	}
+	if endpoint == nil {
+		return nil, utils.RecordNotFound.WithMessage("Webhook not found")
+	}
+	switch {
+	case endpoint.AccountID != nil && endpoint.AccountID.String() == userID:
+	case endpoint.AccountID == nil && admin:
+	default:
+		return nil, utils.RecordNotFound.WithMessage("Webhook not found")
+	}
+	return endpoint, nil
+}
+
+func (s *WebhookService) checkEndpointURL(raw string) error {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return utils.ErrInvalidInput.WithMessage("Invalid webhook URL")
+	}
+	if s.allowHTTP {
+		return nil
+	}
+	if u.Scheme != "https" {
+		return utils.ErrInvalidInput.WithMessage("Webhook URLs must use https")
+	}
+	host := u.Hostname()
+	if ip := net.ParseIP(host); (ip != nil && !isPublicIP(ip)) || strings.EqualFold(host, "localhost") {
+		return utils.ErrInvalidInput.WithMessage("Webhook URLs must point to a public address")
+	}
+	return nil
+}
+
+func (s *WebhookService) RunOnce(ctx context.Context) (int, error) {
+	now := time.Now()
+	due, err := s.repo.ClaimDue(ctx, now.Unix(), webhookBatchSize, webhookClaimLease)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		delivered int
+	)
+	jobs := make(chan *db_models.WebhookDelivery)
+	for i := 0; i < webhookSenders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range jobs {
+				if s.deliver(ctx, d) {
+					mu.Lock()
+					delivered++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range due {
+		jobs <- &due[i]
+	}
+	close(jobs)
+	wg.Wait()
+	return delivered, nil
+}
+
+// deliver makes one attempt and records its outcome.
+func (s *WebhookService) deliver(ctx context.Context, d *db_models.WebhookDelivery) bool {
+	status, err := s.send(ctx, d)
+	if err == nil {
+		if err := s.repo.MarkDelivered(ctx, d.ID, status, time.Now().Unix()); err != nil {
+			log.Printf("[webhooks] delivery %s sent but not recorded: %v", d.ID, err)
+		}
+		return true
+	}
+
+	var next *int64
+	var permanent *webhookPermanentError
+	if !errors.As(err, &permanent) && d.Attempts+1 < webhookMaxAttempts {
+		delay := webhookFirstRetry << d.Attempts
+		if delay > webhookMaxRetry {
+			delay = webhookMaxRetry
+		}
+		at := time.Now().Add(delay).Unix()
+		next = &at
+	}
+	reason := err.Error()
+	if len(reason) > 500 {
+		reason = reason[:500]
+	}
+	if err := s.repo.MarkAttemptFailed(ctx, d.ID, status, reason, next); err != nil {
+		log.Printf("[webhooks] delivery %s failed and not recorded: %v", d.ID, err)
+	}
+	return false
+}
+
+// webhookPermanentError ends a delivery without retrying it.
+type webhookPermanentError struct{ reason string }
+
+func (e *webhookPermanentError) Error() string { return e.reason }
+
+func (s *WebhookService) send(ctx context.Context, d *db_models.WebhookDelivery) (int, error) {
+	endpoint := d.Endpoint
+	switch {
+	case endpoint.ID == uuid.Nil || endpoint.DeletedAt.Valid:
+		return 0, &webhookPermanentError{"webhook was deleted"}
+	case !endpoint.Active:
+		return 0, &webhookPermanentError{"webhook is disabled"}
+	}
+	secret, err := utils.DecryptString(endpoint.Secret)
+	if err != nil {
+		return 0, &webhookPermanentError{"cannot open the signing secret"}
+	}
+
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "."))
+	mac.Write(d.Payload)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, &webhookPermanentError{"invalid webhook URL"}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Vivu-Webhooks/1.0")
+	req.Header.Set("X-Vivu-Event", d.EventType)
+	req.Header.Set("X-Vivu-Delivery", d.ID.String())
+	req.Header.Set(webhookSignatureHeader, "t="+ts+",v1="+hex.EncodeToString(mac.Sum(nil)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint answered %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (s *WebhookService) Start() {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			n, err := s.RunOnce(ctx)
+			cancel()
+			if err != nil {
+				log.Printf("[webhooks] run failed: %v", err)
+			} else if n > 0 {
+				log.Printf("[webhooks] delivered %d events", n)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-s.kick:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (s *WebhookService) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+func uniqueStrings(in []string) []string {
+	seen := make(map[string]bool, len(in))
+	out := make([]string, 0, len(in))
+	for _, v := range in {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func toWebhookEndpointResponse(e *db_models.WebhookEndpoint) response_models.WebhookEndpoint {
+	out := response_models.WebhookEndpoint{
+		ID:          e.ID.String(),
+		AllAccounts: e.AccountID == nil,
+		URL:         e.URL,
+		Description: e.Description,
+		Events:      []string(e.Events),
+		Active:      e.Active,
+		CreatedAt:   utils.FormatRFC3339VN(utils.FromUnixSecondsVN(e.CreatedAt)),
+	}
+	if out.Events == nil {
+		out.Events = []string{}
+	}
+	if e.AccountID != nil {
+		out.AccountID = e.AccountID.String()
+	}
+	return out
+}
+
+func toWebhookDeliveryResponse(d *db_models.WebhookDelivery) response_models.WebhookDelivery {
+	out := response_models.WebhookDelivery{
+		ID:             d.ID.String(),
+		EndpointID:     d.EndpointID.String(),
+		EventID:        d.EventID.String(),
+		Event:          d.EventType,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		CreatedAt:      utils.FormatRFC3339VN(utils.FromUnixSecondsVN(d.CreatedAt)),
+		Payload:        json.RawMessage(d.Payload),
+	}
+	if d.Status == db_models.WebhookDeliveryPending {
+		out.NextAttemptAt = utils.FormatRFC3339VN(utils.FromUnixSecondsVN(d.NextAttemptAt))
+	}
+	if d.DeliveredAt != nil {
+		out.DeliveredAt = utils.FormatRFC3339VN(utils.FromUnixSecondsVN(*d.DeliveredAt))
+	}
+	return out
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WorkingWindow is the part of the day a traveler wants to be out, in minutes after midnight (VN).
+type WorkingWindow struct {
+	Start, End int
+}
+
+// DefaultWorkingWindow is what the planner assumes when the traveler has not said otherwise.
+var DefaultWorkingWindow = WorkingWindow{Start: 9 * 60, End: 21 * 60}
+
+// Quiz presets; a custom "HH:MM-HH:MM" answer is accepted too.
+var workingWindowPresets = map[string]WorkingWindow{
+	"early bird": {Start: 6 * 60, End: 18 * 60},
+	"standard":   DefaultWorkingWindow,
+	"night owl":  {Start: 11 * 60, End: 23*60 + 30},
+}
+
+// ParseWorkingWindow accepts "HH:MM-HH:MM" or a quiz preset label ("Early bird (06:00-18:00)").
+// The window must not wrap midnight and must leave at least two hours.
+func ParseWorkingWindow(s string) (WorkingWindow, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for label, w := range workingWindowPresets {
+		if strings.HasPrefix(s, label) {
+			return w, true
+		}
+	}
+	tw, err := parseTimeWindow(s)
+	if err != nil || tw.To-tw.From < 120 {
+		return WorkingWindow{}, false
+	}
+	return WorkingWindow{Start: tw.From, End: tw.To}, true
+}
+
+// NewWorkingWindow builds a window from stored "HH:MM" bounds; ok is false when either is unset or invalid.
+func NewWorkingWindow(start, end string) (WorkingWindow, bool) {
+	if start == "" || end == "" {
+		return WorkingWindow{}, false
+	}
+	return ParseWorkingWindow(start + "-" + end)
+}
+
+func (w WorkingWindow) StartClock() string { return formatClock(w.Start) }
+func (w WorkingWindow) EndClock() string   { return formatClock(w.End) }
+
+func (w WorkingWindow) String() string {
+	return w.StartClock() + "-" + w.EndClock()
+}
+
+func (w WorkingWindow) contains(start, end int) bool {
+	return start >= w.Start && end <= w.End
+}
+
+// outside is the rest of the day as a restricted window (wrapping midnight).
+func (w WorkingWindow) outside() timeWindow {
+	return timeWindow{From: w.End, To: w.Start}
+}
+
+func (w WorkingWindow) promptLine() string {
+	return fmt.Sprintf("Schedule every activity between %s and %s (the traveler's day window).", w.StartClock(), w.EndClock())
+}
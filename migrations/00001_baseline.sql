@@ -0,0 +1,482 @@
+-- +goose Up
+-- The schema as it stood when AutoMigrate ran on every boot. Every statement is idempotent, so
+-- databases created before versioned migrations simply record it as applied.
+CREATE EXTENSION IF NOT EXISTS vector;
+
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'subscription_status') THEN
+        CREATE TYPE subscription_status AS ENUM ('trialing', 'active', 'past_due', 'canceled', 'expired');
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'transaction_status') THEN
+        CREATE TYPE transaction_status AS ENUM ('pending', 'paid', 'failed', 'refunded');
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'billing_period') THEN
+        CREATE TYPE billing_period AS ENUM ('month', 'year');
+    END IF;
+END
+$$;
+-- +goose StatementEnd
+
+CREATE TABLE IF NOT EXISTS categories (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    name text NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT uni_categories_name UNIQUE (name)
+);
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories (deleted_at);
+
+CREATE TABLE IF NOT EXISTS provinces (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    name text,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_provinces_deleted_at ON provinces (deleted_at);
+
+CREATE TABLE IF NOT EXISTS pois (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    name text,
+    latitude decimal,
+    longitude decimal,
+    province_id uuid,
+    category_id uuid,
+    status text,
+    opening_hours text,
+    contact_info text,
+    description text,
+    address text,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_categories_po_is FOREIGN KEY (category_id) REFERENCES categories(id),
+    CONSTRAINT fk_provinces_po_is FOREIGN KEY (province_id) REFERENCES provinces(id)
+);
+CREATE INDEX IF NOT EXISTS idx_pois_deleted_at ON pois (deleted_at);
+
+CREATE TABLE IF NOT EXISTS tags (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    en_name text,
+    vi_name text,
+    icon text,
+    PRIMARY KEY (id),
+    CONSTRAINT uni_tags_en_name UNIQUE (en_name),
+    CONSTRAINT uni_tags_vi_name UNIQUE (vi_name)
+);
+CREATE INDEX IF NOT EXISTS idx_tags_deleted_at ON tags (deleted_at);
+
+CREATE TABLE IF NOT EXISTS poi_tags (
+    tag_id uuid,
+    poi_id uuid,
+    PRIMARY KEY (tag_id, poi_id),
+    CONSTRAINT fk_poi_tags_tag FOREIGN KEY (tag_id) REFERENCES tags(id),
+    CONSTRAINT fk_poi_tags_poi FOREIGN KEY (poi_id) REFERENCES pois(id)
+);
+
+CREATE TABLE IF NOT EXISTS poi_details (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    poi_id uuid NOT NULL,
+    images text[],
+    PRIMARY KEY (id),
+    CONSTRAINT fk_pois_details FOREIGN KEY (poi_id) REFERENCES pois(id)
+);
+CREATE INDEX IF NOT EXISTS idx_poi_details_deleted_at ON poi_details (deleted_at);
+
+CREATE TABLE IF NOT EXISTS accounts (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    name text,
+    email text,
+    password_hash text,
+    role text DEFAULT 'user',
+    day_start varchar(5),
+    day_end varchar(5),
+    companions jsonb,
+    avoid text[],
+    subscription_snapshot jsonb DEFAULT '{}',
+    PRIMARY KEY (id),
+    CONSTRAINT uni_accounts_email UNIQUE (email)
+);
+CREATE INDEX IF NOT EXISTS idx_accounts_deleted_at ON accounts (deleted_at);
+
+CREATE TABLE IF NOT EXISTS journeys (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    account_id uuid,
+    title text,
+    start_date bigint,
+    end_date bigint,
+    is_shared boolean,
+    is_completed boolean,
+    location text,
+    reminders_off boolean NOT NULL DEFAULT false,
+    diversity jsonb,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_accounts_journeys FOREIGN KEY (account_id) REFERENCES accounts(id)
+);
+CREATE INDEX IF NOT EXISTS idx_journeys_deleted_at ON journeys (deleted_at);
+
+CREATE TABLE IF NOT EXISTS journey_days (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    journey_id uuid,
+    date timestamptz,
+    day_number bigint,
+    accommodation_poi_id uuid,
+    rainy_plan jsonb,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_journey_days_accommodation FOREIGN KEY (accommodation_poi_id) REFERENCES pois(id),
+    CONSTRAINT fk_journeys_days FOREIGN KEY (journey_id) REFERENCES journeys(id)
+);
+CREATE INDEX IF NOT EXISTS idx_journey_days_deleted_at ON journey_days (deleted_at);
+
+CREATE TABLE IF NOT EXISTS journey_activities (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    journey_day_id uuid,
+    time timestamptz,
+    end_time timestamptz,
+    activity_type text,
+    selected_poi_id uuid,
+    notes text,
+    booking_status varchar(16),
+    booking_partner varchar(64),
+    booking_reference varchar(128),
+    PRIMARY KEY (id),
+    CONSTRAINT fk_pois_activities FOREIGN KEY (selected_poi_id) REFERENCES pois(id),
+    CONSTRAINT fk_journey_days_activities FOREIGN KEY (journey_day_id) REFERENCES journey_days(id)
+);
+CREATE INDEX IF NOT EXISTS idx_journey_activities_deleted_at ON journey_activities (deleted_at);
+
+CREATE TABLE IF NOT EXISTS plans (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    code text,
+    name text,
+    description text,
+    background_image text,
+    period billing_period,
+    price_minor bigint,
+    currency varchar(3),
+    trial_days integer DEFAULT 0,
+    is_active boolean DEFAULT true,
+    features jsonb DEFAULT '{}',
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_plans_code ON plans (code);
+CREATE INDEX IF NOT EXISTS idx_plans_deleted_at ON plans (deleted_at);
+
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    account_id uuid,
+    plan_id uuid,
+    status subscription_status,
+    starts_at bigint NOT NULL,
+    ends_at bigint NOT NULL,
+    canceled_at bigint,
+    auto_renew boolean DEFAULT true,
+    provider text,
+    provider_customer_id text,
+    provider_sub_id text,
+    metadata jsonb DEFAULT '{}',
+    PRIMARY KEY (id),
+    CONSTRAINT fk_accounts_subs FOREIGN KEY (account_id) REFERENCES accounts(id),
+    CONSTRAINT fk_subscriptions_plan FOREIGN KEY (plan_id) REFERENCES plans(id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_subscriptions_provider_sub_id ON subscriptions (provider_sub_id);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_provider_customer_id ON subscriptions (provider_customer_id);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_provider ON subscriptions (provider);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_status ON subscriptions (status);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_plan_id ON subscriptions (plan_id);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_account_id ON subscriptions (account_id);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_deleted_at ON subscriptions (deleted_at);
+
+CREATE TABLE IF NOT EXISTS transactions (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    account_id uuid,
+    subscription_id uuid,
+    amount_minor bigint,
+    currency varchar(3),
+    status transaction_status,
+    provider text,
+    provider_txn_id text,
+    payment_method_ref text,
+    authorized_at bigint,
+    paid_at bigint,
+    refunded_at bigint,
+    receipt jsonb DEFAULT '{}',
+    metadata jsonb DEFAULT '{}',
+    PRIMARY KEY (id),
+    CONSTRAINT fk_transactions_subscription FOREIGN KEY (subscription_id) REFERENCES subscriptions(id),
+    CONSTRAINT fk_accounts_payments FOREIGN KEY (account_id) REFERENCES accounts(id)
+);
+CREATE INDEX IF NOT EXISTS idx_transactions_provider_txn_id ON transactions (provider_txn_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_provider ON transactions (provider);
+CREATE INDEX IF NOT EXISTS idx_transactions_status ON transactions (status);
+CREATE INDEX IF NOT EXISTS idx_transactions_subscription_id ON transactions (subscription_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_account_id ON transactions (account_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_deleted_at ON transactions (deleted_at);
+
+CREATE TABLE IF NOT EXISTS feedbacks (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    user_id uuid NOT NULL,
+    comment text NOT NULL,
+    rating bigint NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT chk_feedbacks_rating CHECK (rating >= 1 AND rating <= 5)
+);
+CREATE INDEX IF NOT EXISTS idx_feedbacks_deleted_at ON feedbacks (deleted_at);
+
+CREATE TABLE IF NOT EXISTS query_diagnostics (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    fingerprint varchar(32),
+    "table" varchar(128),
+    sql text NOT NULL,
+    bound_sql text,
+    duration_ms bigint,
+    rows_affected bigint,
+    plan text,
+    occurrences bigint NOT NULL DEFAULT 1,
+    last_seen_at bigint,
+    last_captured_at bigint,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_query_diagnostics_last_seen_at ON query_diagnostics (last_seen_at);
+CREATE INDEX IF NOT EXISTS idx_query_diagnostics_duration_ms ON query_diagnostics (duration_ms);
+CREATE INDEX IF NOT EXISTS idx_query_diagnostics_table ON query_diagnostics ("table");
+CREATE UNIQUE INDEX IF NOT EXISTS idx_query_diagnostics_fingerprint ON query_diagnostics (fingerprint);
+CREATE INDEX IF NOT EXISTS idx_query_diagnostics_deleted_at ON query_diagnostics (deleted_at);
+
+CREATE TABLE IF NOT EXISTS runtime_switches (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    key varchar(64) NOT NULL,
+    enabled boolean NOT NULL DEFAULT false,
+    message text,
+    updated_by varchar(64),
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_runtime_switches_key ON runtime_switches (key);
+CREATE INDEX IF NOT EXISTS idx_runtime_switches_deleted_at ON runtime_switches (deleted_at);
+
+CREATE TABLE IF NOT EXISTS poi_distance_cache (
+    mode varchar(16),
+    from_poi varchar(64),
+    to_poi varchar(64),
+    distance_meters bigint NOT NULL,
+    duration_seconds bigint NOT NULL DEFAULT 0,
+    expires_at bigint NOT NULL,
+    PRIMARY KEY (mode, from_poi, to_poi)
+);
+CREATE INDEX IF NOT EXISTS idx_poi_distance_cache_expires_at ON poi_distance_cache (expires_at);
+
+CREATE TABLE IF NOT EXISTS account_merge_logs (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    source_account_id uuid NOT NULL,
+    target_account_id uuid NOT NULL,
+    performed_by varchar(64),
+    dry_run boolean NOT NULL,
+    report jsonb DEFAULT '{}',
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_account_merge_logs_target_account_id ON account_merge_logs (target_account_id);
+CREATE INDEX IF NOT EXISTS idx_account_merge_logs_source_account_id ON account_merge_logs (source_account_id);
+CREATE INDEX IF NOT EXISTS idx_account_merge_logs_deleted_at ON account_merge_logs (deleted_at);
+
+CREATE TABLE IF NOT EXISTS poi_external_refs (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    poi_id uuid NOT NULL,
+    source varchar(32) NOT NULL,
+    external_id varchar(255) NOT NULL,
+    url text,
+    rating decimal,
+    review_count bigint,
+    fetched_at bigint,
+    last_error text,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_pois_external_refs FOREIGN KEY (poi_id) REFERENCES pois(id)
+);
+CREATE INDEX IF NOT EXISTS idx_poi_external_refs_fetched_at ON poi_external_refs (fetched_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_poi_external_source ON poi_external_refs (poi_id, source);
+CREATE INDEX IF NOT EXISTS idx_poi_external_refs_deleted_at ON poi_external_refs (deleted_at);
+
+CREATE TABLE IF NOT EXISTS destination_rules (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    province_id uuid NOT NULL,
+    max_activities_per_day bigint NOT NULL DEFAULT 0,
+    forbidden_windows text[],
+    buffer_minutes bigint NOT NULL DEFAULT 0,
+    note text,
+    updated_by varchar(64),
+    PRIMARY KEY (id),
+    CONSTRAINT fk_destination_rules_province FOREIGN KEY (province_id) REFERENCES provinces(id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_destination_rules_province_id ON destination_rules (province_id);
+CREATE INDEX IF NOT EXISTS idx_destination_rules_deleted_at ON destination_rules (deleted_at);
+
+CREATE TABLE IF NOT EXISTS poi_embedding_outbox (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    poi_id uuid NOT NULL,
+    reason varchar(32),
+    processed_at bigint,
+    attempts bigint NOT NULL DEFAULT 0,
+    last_error text,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_poi_embedding_outbox_processed_at ON poi_embedding_outbox (processed_at);
+CREATE INDEX IF NOT EXISTS idx_poi_embedding_outbox_poi_id ON poi_embedding_outbox (poi_id);
+CREATE INDEX IF NOT EXISTS idx_poi_embedding_outbox_deleted_at ON poi_embedding_outbox (deleted_at);
+
+CREATE TABLE IF NOT EXISTS practical_infos (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    province_id uuid NOT NULL,
+    connectivity text[],
+    transport text[],
+    tipping text,
+    other text[],
+    updated_by varchar(64),
+    PRIMARY KEY (id),
+    CONSTRAINT fk_practical_infos_province FOREIGN KEY (province_id) REFERENCES provinces(id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_practical_infos_province_id ON practical_infos (province_id);
+CREATE INDEX IF NOT EXISTS idx_practical_infos_deleted_at ON practical_infos (deleted_at);
+
+CREATE TABLE IF NOT EXISTS trip_reminders (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    journey_id uuid NOT NULL,
+    kind varchar(16) NOT NULL,
+    trip_start bigint NOT NULL,
+    due_at bigint NOT NULL,
+    sent_at bigint,
+    skipped boolean,
+    attempts bigint NOT NULL DEFAULT 0,
+    last_error text,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_trip_reminders_journey FOREIGN KEY (journey_id) REFERENCES journeys(id)
+);
+CREATE INDEX IF NOT EXISTS idx_trip_reminders_due_at ON trip_reminders (due_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_trip_reminder_kind ON trip_reminders (journey_id, kind);
+CREATE INDEX IF NOT EXISTS idx_trip_reminders_deleted_at ON trip_reminders (deleted_at);
+
+CREATE TABLE IF NOT EXISTS plan_usages (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    account_id uuid NOT NULL,
+    month varchar(7) NOT NULL,
+    count bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_plan_usages_account FOREIGN KEY (account_id) REFERENCES accounts(id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_plan_usage_account_month ON plan_usages (account_id, month);
+CREATE INDEX IF NOT EXISTS idx_plan_usages_deleted_at ON plan_usages (deleted_at);
+
+CREATE TABLE IF NOT EXISTS poi_booking_links (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    poi_id uuid NOT NULL,
+    partner varchar(64) NOT NULL,
+    url text NOT NULL,
+    deep_link_params jsonb,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_poi_booking_partner ON poi_booking_links (poi_id, partner);
+CREATE INDEX IF NOT EXISTS idx_poi_booking_links_deleted_at ON poi_booking_links (deleted_at);
+
+CREATE TABLE IF NOT EXISTS booking_events (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    account_id uuid NOT NULL,
+    poi_id uuid NOT NULL,
+    activity_id uuid,
+    partner varchar(64) NOT NULL,
+    url text,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_booking_events_partner ON booking_events (partner);
+CREATE INDEX IF NOT EXISTS idx_booking_events_activity_id ON booking_events (activity_id);
+CREATE INDEX IF NOT EXISTS idx_booking_events_poi_id ON booking_events (poi_id);
+CREATE INDEX IF NOT EXISTS idx_booking_events_account_id ON booking_events (account_id);
+CREATE INDEX IF NOT EXISTS idx_booking_events_deleted_at ON booking_events (deleted_at);
+
+CREATE TABLE IF NOT EXISTS poi_embeddings (
+    poi_id text,
+    name text,
+    description text,
+    province_id text,
+    category_id text,
+    tags text[],
+    embedding vector(1536),
+    content_version bigint NOT NULL DEFAULT 0,
+    created_at timestamptz,
+    PRIMARY KEY (poi_id)
+);
+
+-- +goose Down
+-- +goose StatementBegin
+DO $$
+BEGIN
+    RAISE EXCEPTION 'baseline migration cannot be rolled back';
+END
+$$;
+-- +goose StatementEnd
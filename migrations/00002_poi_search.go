@@ -0,0 +1,52 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddNamedMigrationNoTxContext("00002_poi_search.go", upPOISearch, downPOISearch)
+}
+
+// Full-text search on pois. unaccent() is only STABLE, so it cannot be used in a generated
+// column directly; f_unaccent pins the dictionary and is declared IMMUTABLE instead.
+var poiSearchDDL = []string{
+	`CREATE EXTENSION IF NOT EXISTS unaccent`,
+	`CREATE OR REPLACE FUNCTION f_unaccent(text) RETURNS text
+		LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT
+		AS $$ SELECT public.unaccent('public.unaccent'::regdictionary, $1) $$`,
+	`ALTER TABLE pois ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (
+			setweight(to_tsvector('simple', f_unaccent(coalesce(name, ''))), 'A') ||
+			setweight(to_tsvector('simple', f_unaccent(coalesce(address, ''))), 'B') ||
+			setweight(to_tsvector('simple', f_unaccent(coalesce(description, ''))), 'C')
+		) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_pois_search_vector ON pois USING GIN (search_vector)`,
+}
+
+// upPOISearch adds the generated search_vector column and its GIN index. The unaccent extension
+// needs a superuser once; without it the migration fails instead of being recorded half applied.
+func upPOISearch(ctx context.Context, db *sql.DB) error {
+	for _, stmt := range poiSearchDDL {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("poi full-text search: %w", err)
+		}
+	}
+	return nil
+}
+
+func downPOISearch(ctx context.Context, db *sql.DB) error {
+	for _, stmt := range []string{
+		`DROP INDEX IF EXISTS idx_pois_search_vector`,
+		`ALTER TABLE pois DROP COLUMN IF EXISTS search_vector`,
+	} {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
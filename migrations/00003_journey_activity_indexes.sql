@@ -0,0 +1,8 @@
+-- +goose Up
+-- Day views and reminder scans read activities of a day in time order.
+CREATE INDEX IF NOT EXISTS idx_journey_activities_day_time ON journey_activities (journey_day_id, time);
+CREATE INDEX IF NOT EXISTS idx_journey_activities_selected_poi_id ON journey_activities (selected_poi_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_journey_activities_selected_poi_id;
+DROP INDEX IF EXISTS idx_journey_activities_day_time;
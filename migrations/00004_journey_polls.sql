@@ -0,0 +1,68 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS journey_members (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    journey_id uuid NOT NULL,
+    account_id uuid NOT NULL,
+    role varchar(16) NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_journey_members_account FOREIGN KEY (account_id) REFERENCES accounts(id)
+);
+CREATE INDEX IF NOT EXISTS idx_journey_members_account_id ON journey_members (account_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_journey_member ON journey_members (journey_id, account_id);
+CREATE INDEX IF NOT EXISTS idx_journey_members_deleted_at ON journey_members (deleted_at);
+
+CREATE TABLE IF NOT EXISTS journey_polls (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    journey_id uuid NOT NULL,
+    activity_id uuid NOT NULL,
+    created_by uuid NOT NULL,
+    question varchar(255),
+    deadline bigint NOT NULL,
+    status varchar(16) NOT NULL DEFAULT 'open',
+    winner_option_id uuid,
+    closed_at bigint,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_journey_polls_status ON journey_polls (status);
+CREATE INDEX IF NOT EXISTS idx_journey_polls_deadline ON journey_polls (deadline);
+CREATE INDEX IF NOT EXISTS idx_journey_polls_activity_id ON journey_polls (activity_id);
+CREATE INDEX IF NOT EXISTS idx_journey_polls_journey_id ON journey_polls (journey_id);
+CREATE INDEX IF NOT EXISTS idx_journey_polls_deleted_at ON journey_polls (deleted_at);
+
+CREATE TABLE IF NOT EXISTS journey_poll_options (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    poll_id uuid NOT NULL,
+    poi_id uuid NOT NULL,
+    position bigint NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_journey_poll_options_poi FOREIGN KEY (poi_id) REFERENCES pois(id),
+    CONSTRAINT fk_journey_polls_options FOREIGN KEY (poll_id) REFERENCES journey_polls(id)
+);
+CREATE INDEX IF NOT EXISTS idx_journey_poll_options_poll_id ON journey_poll_options (poll_id);
+CREATE INDEX IF NOT EXISTS idx_journey_poll_options_deleted_at ON journey_poll_options (deleted_at);
+
+CREATE TABLE IF NOT EXISTS journey_poll_votes (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    poll_id uuid NOT NULL,
+    account_id uuid NOT NULL,
+    option_id uuid NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_journey_polls_votes FOREIGN KEY (poll_id) REFERENCES journey_polls(id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_poll_vote ON journey_poll_votes (poll_id, account_id);
+CREATE INDEX IF NOT EXISTS idx_journey_poll_votes_deleted_at ON journey_poll_votes (deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS journey_poll_votes, journey_poll_options, journey_polls, journey_members;
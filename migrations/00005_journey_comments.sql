@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS journey_comments (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    journey_id uuid NOT NULL,
+    activity_id uuid,
+    parent_id uuid,
+    account_id uuid NOT NULL,
+    body text NOT NULL,
+    mentions jsonb,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_journey_comments_account FOREIGN KEY (account_id) REFERENCES accounts(id)
+);
+CREATE INDEX IF NOT EXISTS idx_journey_comments_parent_id ON journey_comments (parent_id);
+CREATE INDEX IF NOT EXISTS idx_journey_comments_activity_id ON journey_comments (activity_id);
+CREATE INDEX IF NOT EXISTS idx_journey_comments_journey_id ON journey_comments (journey_id);
+CREATE INDEX IF NOT EXISTS idx_journey_comments_deleted_at ON journey_comments (deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS journey_comments;
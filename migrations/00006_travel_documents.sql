@@ -0,0 +1,35 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS travel_documents (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    journey_id uuid NOT NULL,
+    account_id uuid NOT NULL,
+    kind varchar(24) NOT NULL,
+    file_name varchar(255) NOT NULL,
+    content_type varchar(128),
+    size bigint NOT NULL,
+    storage_key varchar(255) NOT NULL,
+    parsed jsonb,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_travel_documents_journey_id ON travel_documents (journey_id);
+CREATE INDEX IF NOT EXISTS idx_travel_documents_deleted_at ON travel_documents (deleted_at);
+
+CREATE TABLE IF NOT EXISTS journey_inboxes (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    journey_id uuid NOT NULL,
+    token varchar(32) NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_journey_inboxes_token ON journey_inboxes (token);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_journey_inboxes_journey_id ON journey_inboxes (journey_id);
+CREATE INDEX IF NOT EXISTS idx_journey_inboxes_deleted_at ON journey_inboxes (deleted_at);
+
+-- +goose Down
+-- Drops the metadata only; stored files are left in the file storage.
+DROP TABLE IF EXISTS travel_documents, journey_inboxes;
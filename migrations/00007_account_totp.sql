@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS totp_secret text,
+    ADD COLUMN IF NOT EXISTS totp_enabled boolean NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS totp_last_step bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS totp_backup_codes text[];
+
+-- +goose Down
+ALTER TABLE accounts
+    DROP COLUMN IF EXISTS totp_backup_codes,
+    DROP COLUMN IF EXISTS totp_last_step,
+    DROP COLUMN IF EXISTS totp_enabled,
+    DROP COLUMN IF EXISTS totp_secret;
@@ -0,0 +1,35 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS journey_budgets (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    journey_id uuid NOT NULL,
+    amount bigint NOT NULL,
+    alert_sent_at bigint,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_journey_budgets_journey_id ON journey_budgets (journey_id);
+CREATE INDEX IF NOT EXISTS idx_journey_budgets_deleted_at ON journey_budgets (deleted_at);
+
+CREATE TABLE IF NOT EXISTS journey_expenses (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    journey_id uuid NOT NULL,
+    account_id uuid NOT NULL,
+    activity_id uuid,
+    amount bigint NOT NULL,
+    category varchar(32) NOT NULL,
+    merchant varchar(128),
+    note varchar(500),
+    spent_at bigint NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_journey_expenses_spent_at ON journey_expenses (spent_at);
+CREATE INDEX IF NOT EXISTS idx_journey_expenses_journey_id ON journey_expenses (journey_id);
+CREATE INDEX IF NOT EXISTS idx_journey_expenses_deleted_at ON journey_expenses (deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS journey_expenses, journey_budgets;
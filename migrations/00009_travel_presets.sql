@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS travel_presets (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    account_id uuid NOT NULL,
+    name varchar(60) NOT NULL,
+    pace varchar(16),
+    budget varchar(16),
+    interests text[],
+    dietary text[],
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_travel_preset_name ON travel_presets (account_id, name);
+CREATE INDEX IF NOT EXISTS idx_travel_presets_deleted_at ON travel_presets (deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS travel_presets;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE journey_activities ADD COLUMN IF NOT EXISTS selection jsonb;
+
+-- +goose Down
+ALTER TABLE journey_activities DROP COLUMN IF EXISTS selection;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Workers claim outbox rows before embedding them, so several instances never embed the same POI.
+ALTER TABLE poi_embedding_outbox ADD COLUMN IF NOT EXISTS claimed_until bigint;
+
+-- +goose Down
+ALTER TABLE poi_embedding_outbox DROP COLUMN IF EXISTS claimed_until;
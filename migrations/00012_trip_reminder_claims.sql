@@ -0,0 +1,6 @@
+-- +goose Up
+-- Instances claim due reminders before sending them, so each reminder goes out once.
+ALTER TABLE trip_reminders ADD COLUMN IF NOT EXISTS claimed_until bigint;
+
+-- +goose Down
+ALTER TABLE trip_reminders DROP COLUMN IF EXISTS claimed_until;
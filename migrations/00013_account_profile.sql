@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS avatar_url text,
+    ADD COLUMN IF NOT EXISTS locale varchar(5),
+    ADD COLUMN IF NOT EXISTS preferred_currency varchar(3);
+
+-- +goose Down
+ALTER TABLE accounts
+    DROP COLUMN IF EXISTS preferred_currency,
+    DROP COLUMN IF EXISTS locale,
+    DROP COLUMN IF EXISTS avatar_url;
@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS status varchar(16) NOT NULL DEFAULT 'active',
+    ADD COLUMN IF NOT EXISTS banned_at bigint,
+    ADD COLUMN IF NOT EXISTS ban_reason text,
+    ADD COLUMN IF NOT EXISTS sessions_revoked_at bigint NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_accounts_status ON accounts (status);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_accounts_status;
+ALTER TABLE accounts
+    DROP COLUMN IF EXISTS sessions_revoked_at,
+    DROP COLUMN IF EXISTS ban_reason,
+    DROP COLUMN IF EXISTS banned_at,
+    DROP COLUMN IF EXISTS status;
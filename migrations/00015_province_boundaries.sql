@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS province_boundaries (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    province_id uuid NOT NULL,
+    geometry jsonb NOT NULL,
+    min_lat double precision NOT NULL,
+    min_lng double precision NOT NULL,
+    max_lat double precision NOT NULL,
+    max_lng double precision NOT NULL,
+    points bigint NOT NULL DEFAULT 0,
+    source varchar(255),
+    updated_by varchar(64),
+    PRIMARY KEY (id),
+    CONSTRAINT fk_province_boundaries_province FOREIGN KEY (province_id) REFERENCES provinces(id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_province_boundaries_province_id ON province_boundaries (province_id);
+CREATE INDEX IF NOT EXISTS idx_province_boundaries_deleted_at ON province_boundaries (deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS province_boundaries;
@@ -0,0 +1,28 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS poi_province_flags (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    poi_id uuid NOT NULL,
+    kind varchar(20) NOT NULL,
+    status varchar(16) NOT NULL DEFAULT 'open',
+    latitude double precision NOT NULL,
+    longitude double precision NOT NULL,
+    province_id uuid NOT NULL,
+    suggested_province_id uuid,
+    suggested_latitude double precision,
+    suggested_longitude double precision,
+    detected_at bigint NOT NULL,
+    resolved_at bigint,
+    resolved_by varchar(64),
+    PRIMARY KEY (id),
+    CONSTRAINT fk_poi_province_flags_poi FOREIGN KEY (poi_id) REFERENCES pois(id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_poi_province_flags_poi_id ON poi_province_flags (poi_id);
+CREATE INDEX IF NOT EXISTS idx_poi_province_flags_status ON poi_province_flags (status);
+CREATE INDEX IF NOT EXISTS idx_poi_province_flags_province_id ON poi_province_flags (province_id);
+CREATE INDEX IF NOT EXISTS idx_poi_province_flags_deleted_at ON poi_province_flags (deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS poi_province_flags;
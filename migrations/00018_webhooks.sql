@@ -0,0 +1,47 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    account_id uuid,
+    url text NOT NULL,
+    description varchar(255),
+    events text[],
+    secret text NOT NULL,
+    active boolean NOT NULL DEFAULT true,
+    created_by varchar(64),
+    PRIMARY KEY (id),
+    CONSTRAINT fk_webhook_endpoints_account FOREIGN KEY (account_id) REFERENCES accounts(id)
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_endpoints_account_id ON webhook_endpoints (account_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_endpoints_deleted_at ON webhook_endpoints (deleted_at);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    endpoint_id uuid NOT NULL,
+    event_id uuid NOT NULL,
+    event_type varchar(64) NOT NULL,
+    payload jsonb NOT NULL,
+    status varchar(16) NOT NULL DEFAULT 'pending',
+    attempts bigint NOT NULL DEFAULT 0,
+    next_attempt_at bigint NOT NULL,
+    last_status_code bigint NOT NULL DEFAULT 0,
+    last_error text,
+    delivered_at bigint,
+    claimed_until bigint,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_webhook_deliveries_endpoint FOREIGN KEY (endpoint_id) REFERENCES webhook_endpoints(id)
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_endpoint_id ON webhook_deliveries (endpoint_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_event_id ON webhook_deliveries (event_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_status ON webhook_deliveries (status);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_next_attempt_at ON webhook_deliveries (next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_deleted_at ON webhook_deliveries (deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_endpoints;
@@ -0,0 +1,22 @@
+-- +goose Up
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS daily_quota bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS api_key_usages (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    api_key_id uuid NOT NULL,
+    day varchar(10) NOT NULL,
+    requests bigint NOT NULL DEFAULT 0,
+    errors bigint NOT NULL DEFAULT 0,
+    throttled bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_api_key_usages_api_key FOREIGN KEY (api_key_id) REFERENCES api_keys(id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_key_usage_key_day ON api_key_usages (api_key_id, day);
+CREATE INDEX IF NOT EXISTS idx_api_key_usages_deleted_at ON api_key_usages (deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS api_key_usages;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS daily_quota;
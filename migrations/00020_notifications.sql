@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS notifications (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    account_id uuid NOT NULL,
+    kind varchar(64) NOT NULL,
+    title varchar(200) NOT NULL,
+    body text,
+    data jsonb,
+    read_at bigint,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_notifications_account FOREIGN KEY (account_id) REFERENCES accounts(id)
+);
+CREATE INDEX IF NOT EXISTS idx_notifications_account_created ON notifications (account_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications (account_id) WHERE read_at IS NULL AND deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_notifications_deleted_at ON notifications (deleted_at);
+
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS expiry_notice_at bigint;
+
+-- +goose Down
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS expiry_notice_at;
+DROP TABLE IF EXISTS notifications;
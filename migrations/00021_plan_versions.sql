@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE plans ADD COLUMN IF NOT EXISTS version integer NOT NULL DEFAULT 1;
+ALTER TABLE plans ADD COLUMN IF NOT EXISTS previous_plan_id uuid;
+
+-- +goose Down
+ALTER TABLE plans DROP COLUMN IF EXISTS previous_plan_id;
+ALTER TABLE plans DROP COLUMN IF EXISTS version;
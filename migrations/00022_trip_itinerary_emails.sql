@@ -0,0 +1,6 @@
+-- +goose Up
+-- Account-wide opt-out of the pre-trip emails (packing, weather, itinerary summary, day-of).
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS trip_emails_off boolean NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE accounts DROP COLUMN IF EXISTS trip_emails_off;
@@ -0,0 +1,16 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+-- ADD VALUE cannot run inside a transaction block on older Postgres versions.
+ALTER TYPE subscription_status ADD VALUE IF NOT EXISTS 'paused';
+
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS paused_at bigint,
+    ADD COLUMN IF NOT EXISTS paused_remaining bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS resumed_at bigint;
+
+-- +goose Down
+-- Enum values cannot be dropped; 'paused' stays on the type.
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS resumed_at,
+    DROP COLUMN IF EXISTS paused_remaining,
+    DROP COLUMN IF EXISTS paused_at;
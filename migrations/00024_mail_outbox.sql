@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS mail_outbox_messages (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    kind varchar(16) NOT NULL,
+    recipient varchar(320) NOT NULL,
+    subject text NOT NULL,
+    body text,
+    cta_text varchar(100),
+    cta_url text,
+    code text,
+    status varchar(16) NOT NULL DEFAULT 'pending',
+    attempts bigint NOT NULL DEFAULT 0,
+    next_attempt_at bigint NOT NULL,
+    last_error text,
+    sent_at bigint,
+    expires_at bigint,
+    claimed_until bigint,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_mail_outbox_messages_recipient ON mail_outbox_messages (recipient);
+CREATE INDEX IF NOT EXISTS idx_mail_outbox_messages_status ON mail_outbox_messages (status);
+CREATE INDEX IF NOT EXISTS idx_mail_outbox_messages_next_attempt_at ON mail_outbox_messages (next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_mail_outbox_messages_deleted_at ON mail_outbox_messages (deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS mail_outbox_messages;
@@ -0,0 +1,45 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS churn_scores (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    account_id uuid NOT NULL,
+    score bigint NOT NULL,
+    segment varchar(16) NOT NULL,
+    reasons text[],
+    last_journey_at bigint,
+    recent_journeys bigint NOT NULL DEFAULT 0,
+    prior_journeys bigint NOT NULL DEFAULT 0,
+    auto_renew_off boolean NOT NULL DEFAULT false,
+    scored_at bigint NOT NULL,
+    winback_segment varchar(16),
+    winback_started_at bigint,
+    winback_step bigint NOT NULL DEFAULT 0,
+    winback_next_at bigint,
+    winback_last_at bigint,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_churn_scores_account FOREIGN KEY (account_id) REFERENCES accounts(id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_churn_scores_account_id ON churn_scores (account_id);
+CREATE INDEX IF NOT EXISTS idx_churn_scores_segment ON churn_scores (segment);
+CREATE INDEX IF NOT EXISTS idx_churn_scores_winback_next_at ON churn_scores (winback_next_at);
+CREATE INDEX IF NOT EXISTS idx_churn_scores_deleted_at ON churn_scores (deleted_at);
+
+CREATE TABLE IF NOT EXISTS winback_campaigns (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    segment varchar(16) NOT NULL,
+    active boolean NOT NULL DEFAULT false,
+    steps jsonb,
+    updated_by varchar(64),
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_winback_campaigns_segment ON winback_campaigns (segment);
+CREATE INDEX IF NOT EXISTS idx_winback_campaigns_deleted_at ON winback_campaigns (deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS winback_campaigns;
+DROP TABLE IF EXISTS churn_scores;
@@ -0,0 +1,30 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS announcements (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    kind varchar(16) NOT NULL,
+    title varchar(200) NOT NULL,
+    body text,
+    cta_text varchar(100),
+    cta_url text,
+    dismissible boolean NOT NULL DEFAULT true,
+    priority bigint NOT NULL DEFAULT 0,
+    tiers text[],
+    locales text[],
+    min_app_version varchar(32),
+    max_app_version varchar(32),
+    active boolean NOT NULL DEFAULT false,
+    starts_at bigint NOT NULL,
+    ends_at bigint,
+    created_by varchar(64),
+    updated_by varchar(64),
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_announcements_active ON announcements (active);
+CREATE INDEX IF NOT EXISTS idx_announcements_starts_at ON announcements (starts_at);
+CREATE INDEX IF NOT EXISTS idx_announcements_deleted_at ON announcements (deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS announcements;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE mail_outbox_messages ADD COLUMN IF NOT EXISTS template varchar(64);
+ALTER TABLE mail_outbox_messages ADD COLUMN IF NOT EXISTS locale varchar(5);
+ALTER TABLE mail_outbox_messages ADD COLUMN IF NOT EXISTS params jsonb;
+
+-- +goose Down
+ALTER TABLE mail_outbox_messages DROP COLUMN IF EXISTS params;
+ALTER TABLE mail_outbox_messages DROP COLUMN IF EXISTS locale;
+ALTER TABLE mail_outbox_messages DROP COLUMN IF EXISTS template;
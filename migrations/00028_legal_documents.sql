@@ -0,0 +1,41 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS legal_documents (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    kind varchar(16) NOT NULL,
+    version varchar(32) NOT NULL,
+    title varchar(200) NOT NULL,
+    url text,
+    body text,
+    minor boolean NOT NULL DEFAULT false,
+    published_at bigint NOT NULL,
+    published_by varchar(64),
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_legal_documents_kind_version ON legal_documents (kind, version);
+CREATE INDEX IF NOT EXISTS idx_legal_documents_deleted_at ON legal_documents (deleted_at);
+
+CREATE TABLE IF NOT EXISTS legal_acceptances (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    account_id uuid NOT NULL,
+    document_id uuid NOT NULL,
+    kind varchar(16) NOT NULL,
+    version varchar(32) NOT NULL,
+    accepted_at bigint NOT NULL,
+    ip varchar(64),
+    user_agent text,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_legal_acceptances_account FOREIGN KEY (account_id) REFERENCES accounts(id),
+    CONSTRAINT fk_legal_acceptances_document FOREIGN KEY (document_id) REFERENCES legal_documents(id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_legal_acceptances_account_document ON legal_acceptances (account_id, document_id);
+CREATE INDEX IF NOT EXISTS idx_legal_acceptances_deleted_at ON legal_acceptances (deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS legal_acceptances;
+DROP TABLE IF EXISTS legal_documents;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS birth_year integer;
+
+-- +goose Down
+ALTER TABLE accounts DROP COLUMN IF EXISTS birth_year;
@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS secret_key_usages (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    name varchar(64) NOT NULL,
+    fingerprint varchar(16) NOT NULL,
+    instance varchar(128) NOT NULL,
+    source varchar(128) NOT NULL,
+    uses bigint NOT NULL DEFAULT 0,
+    first_used_at bigint NOT NULL,
+    last_used_at bigint NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_secret_key_usages_key ON secret_key_usages (name, fingerprint, instance);
+CREATE INDEX IF NOT EXISTS idx_secret_key_usages_last_used_at ON secret_key_usages (last_used_at);
+CREATE INDEX IF NOT EXISTS idx_secret_key_usages_deleted_at ON secret_key_usages (deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS secret_key_usages;
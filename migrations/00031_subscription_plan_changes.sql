@@ -0,0 +1,36 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+-- ADD VALUE cannot run inside a transaction block on older Postgres versions.
+ALTER TYPE subscription_status ADD VALUE IF NOT EXISTS 'replaced';
+
+CREATE TABLE IF NOT EXISTS subscription_plan_changes (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    account_id uuid NOT NULL,
+    from_subscription_id uuid NOT NULL,
+    to_subscription_id uuid,
+    from_plan_id uuid NOT NULL,
+    to_plan_id uuid NOT NULL,
+    transaction_id uuid,
+    direction varchar(16) NOT NULL,
+    status varchar(16) NOT NULL,
+    currency varchar(3) NOT NULL,
+    remaining_seconds bigint NOT NULL,
+    credit_minor bigint NOT NULL,
+    charge_minor bigint NOT NULL,
+    bonus_seconds bigint NOT NULL DEFAULT 0,
+    applied_at bigint,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_subscription_plan_changes_account FOREIGN KEY (account_id) REFERENCES accounts(id)
+);
+CREATE INDEX IF NOT EXISTS idx_subscription_plan_changes_account_id ON subscription_plan_changes (account_id);
+CREATE INDEX IF NOT EXISTS idx_subscription_plan_changes_direction ON subscription_plan_changes (direction);
+CREATE INDEX IF NOT EXISTS idx_subscription_plan_changes_status ON subscription_plan_changes (status);
+CREATE INDEX IF NOT EXISTS idx_subscription_plan_changes_applied_at ON subscription_plan_changes (applied_at);
+CREATE INDEX IF NOT EXISTS idx_subscription_plan_changes_deleted_at ON subscription_plan_changes (deleted_at);
+
+-- +goose Down
+-- Enum values cannot be dropped; 'replaced' stays on the type.
+DROP TABLE IF EXISTS subscription_plan_changes;
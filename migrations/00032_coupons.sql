@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS coupons (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    code varchar(32) NOT NULL,
+    kind varchar(16) NOT NULL,
+    percent_off integer NOT NULL DEFAULT 0,
+    amount_off_minor bigint NOT NULL DEFAULT 0,
+    currency varchar(3),
+    max_redemptions integer,
+    redemptions integer NOT NULL DEFAULT 0,
+    expires_at bigint,
+    plan_codes text[],
+    is_active boolean NOT NULL DEFAULT true,
+    created_by varchar(64),
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_coupons_code ON coupons (code);
+CREATE INDEX IF NOT EXISTS idx_coupons_deleted_at ON coupons (deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS coupons;
@@ -0,0 +1,26 @@
+-- +goose Up
+ALTER TABLE query_diagnostics
+    ADD COLUMN IF NOT EXISTS owner_account_id uuid,
+    ADD COLUMN IF NOT EXISTS purpose varchar(32);
+CREATE INDEX IF NOT EXISTS idx_query_diagnostics_owner_account_id ON query_diagnostics (owner_account_id);
+
+CREATE TABLE IF NOT EXISTS personal_data_requests (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    account_id uuid NOT NULL,
+    kind varchar(16) NOT NULL,
+    requested_by varchar(64),
+    report jsonb DEFAULT '{}',
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_personal_data_requests_account_id ON personal_data_requests (account_id);
+CREATE INDEX IF NOT EXISTS idx_personal_data_requests_deleted_at ON personal_data_requests (deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS personal_data_requests;
+DROP INDEX IF EXISTS idx_query_diagnostics_owner_account_id;
+ALTER TABLE query_diagnostics
+    DROP COLUMN IF EXISTS purpose,
+    DROP COLUMN IF EXISTS owner_account_id;
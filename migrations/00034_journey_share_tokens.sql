@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE journeys ADD COLUMN IF NOT EXISTS share_token varchar(32);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_journeys_share_token ON journeys (share_token);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_journeys_share_token;
+ALTER TABLE journeys DROP COLUMN IF EXISTS share_token;
@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS webhook_events (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    provider varchar(16) NOT NULL,
+    event_id varchar(128) NOT NULL,
+    provider_txn_id text,
+    outcome varchar(16),
+    status varchar(16) NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    last_error text,
+    processed_at bigint,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_events_provider_event ON webhook_events (provider, event_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_events_provider_txn_id ON webhook_events (provider_txn_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_events_status ON webhook_events (status);
+CREATE INDEX IF NOT EXISTS idx_webhook_events_deleted_at ON webhook_events (deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS webhook_events;
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS renewal_attempts integer NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS next_renewal_at bigint,
+    ADD COLUMN IF NOT EXISTS past_due_at bigint;
+CREATE INDEX IF NOT EXISTS idx_subscriptions_next_renewal_at ON subscriptions (next_renewal_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_subscriptions_next_renewal_at;
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS past_due_at,
+    DROP COLUMN IF EXISTS next_renewal_at,
+    DROP COLUMN IF EXISTS renewal_attempts;
@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS province_guides (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    province_id uuid NOT NULL,
+    cover_image_url text,
+    intro text,
+    highlight_pois text[],
+    trip_days integer[],
+    updated_by varchar(64),
+    PRIMARY KEY (id),
+    CONSTRAINT fk_province_guides_province FOREIGN KEY (province_id) REFERENCES provinces(id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_province_guides_province_id ON province_guides (province_id);
+CREATE INDEX IF NOT EXISTS idx_province_guides_deleted_at ON province_guides (deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS province_guides;
@@ -0,0 +1,41 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS collections (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    slug varchar(120) NOT NULL,
+    title varchar(200) NOT NULL,
+    description text,
+    cover_image_url text,
+    province_id uuid,
+    is_published boolean NOT NULL DEFAULT false,
+    updated_by varchar(64),
+    PRIMARY KEY (id),
+    CONSTRAINT fk_collections_province FOREIGN KEY (province_id) REFERENCES provinces(id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_collections_slug ON collections (slug);
+CREATE INDEX IF NOT EXISTS idx_collections_province_id ON collections (province_id);
+CREATE INDEX IF NOT EXISTS idx_collections_is_published ON collections (is_published);
+CREATE INDEX IF NOT EXISTS idx_collections_deleted_at ON collections (deleted_at);
+
+CREATE TABLE IF NOT EXISTS collection_items (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    collection_id uuid NOT NULL,
+    poi_id uuid NOT NULL,
+    position integer NOT NULL,
+    note text,
+    duration_minutes integer NOT NULL DEFAULT 0,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_collection_items_collection FOREIGN KEY (collection_id) REFERENCES collections(id) ON DELETE CASCADE,
+    CONSTRAINT fk_collection_items_poi FOREIGN KEY (poi_id) REFERENCES pois(id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_collection_items_collection_poi ON collection_items (collection_id, poi_id);
+CREATE INDEX IF NOT EXISTS idx_collection_items_deleted_at ON collection_items (deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS collection_items;
+DROP TABLE IF EXISTS collections;
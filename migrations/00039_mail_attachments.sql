@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE mail_outbox_messages ADD COLUMN IF NOT EXISTS attachment_name varchar(255);
+ALTER TABLE mail_outbox_messages ADD COLUMN IF NOT EXISTS attachment_type varchar(100);
+ALTER TABLE mail_outbox_messages ADD COLUMN IF NOT EXISTS attachment bytea;
+
+-- +goose Down
+ALTER TABLE mail_outbox_messages DROP COLUMN IF EXISTS attachment;
+ALTER TABLE mail_outbox_messages DROP COLUMN IF EXISTS attachment_type;
+ALTER TABLE mail_outbox_messages DROP COLUMN IF EXISTS attachment_name;
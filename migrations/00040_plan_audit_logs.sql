@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS plan_audit_logs (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    plan_id uuid NOT NULL,
+    action varchar(32) NOT NULL,
+    performed_by varchar(64),
+    changes jsonb DEFAULT '{}',
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_plan_audit_logs_plan_id ON plan_audit_logs (plan_id);
+CREATE INDEX IF NOT EXISTS idx_plan_audit_logs_deleted_at ON plan_audit_logs (deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS plan_audit_logs;
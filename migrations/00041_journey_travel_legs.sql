@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE journey_activities ADD COLUMN IF NOT EXISTS leg jsonb;
+ALTER TABLE journey_days ADD COLUMN IF NOT EXISTS return_leg jsonb;
+
+-- +goose Down
+ALTER TABLE journey_days DROP COLUMN IF EXISTS return_leg;
+ALTER TABLE journey_activities DROP COLUMN IF EXISTS leg;
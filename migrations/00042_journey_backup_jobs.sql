@@ -0,0 +1,25 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS journey_backup_jobs (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    account_id uuid NOT NULL,
+    status varchar(16) NOT NULL DEFAULT 'pending',
+    attempts bigint NOT NULL DEFAULT 0,
+    last_error text,
+    claimed_until bigint,
+    storage_key varchar(255),
+    file_name varchar(255),
+    size_bytes bigint,
+    journeys bigint,
+    completed_at bigint,
+    expires_at bigint,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_journey_backup_jobs_account_id ON journey_backup_jobs (account_id);
+CREATE INDEX IF NOT EXISTS idx_journey_backup_jobs_status ON journey_backup_jobs (status);
+CREATE INDEX IF NOT EXISTS idx_journey_backup_jobs_deleted_at ON journey_backup_jobs (deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS journey_backup_jobs;
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS planning_policies (
+    id uuid,
+    created_at bigint,
+    updated_at bigint,
+    deleted_at timestamptz,
+    account_id uuid NOT NULL,
+    exclude text[],
+    boost_poi_ids text[],
+    require_poi_ids text[],
+    updated_by varchar(64),
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_planning_policies_account_id ON planning_policies (account_id);
+CREATE INDEX IF NOT EXISTS idx_planning_policies_deleted_at ON planning_policies (deleted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS planning_policies;
@@ -0,0 +1,13 @@
+// Package migrations holds the versioned schema changes applied by `vivu migrate` and on startup.
+// SQL files are embedded; Go migrations register themselves from init and are only used where a
+// step needs logic. Every migration is frozen DDL: never derive one from the live models, which
+// keep changing after the migration has run.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
+
+// Dir is the directory inside FS that goose reads from.
+const Dir = "."
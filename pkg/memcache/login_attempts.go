@@ -0,0 +1,86 @@
+package mem
+
+import (
+	"sync"
+	"time"
+)
+
+// LoginAttempts is the failed-login state of one key (an email or an IP).
+type LoginAttempts struct {
+	Failures    int       // since the last lockout or success
+	Lockouts    int       // consecutive lockouts, drives the exponential backoff
+	LockedUntil time.Time // zero when not locked
+	UnlockToken string    // emailed to the owner of a locked account
+}
+
+type LoginAttemptStore interface {
+	Get(key string) LoginAttempts
+
+	// Update applies fn to the state of key atomically and keeps the result for ttl.
+	Update(key string, ttl time.Duration, fn func(*LoginAttempts)) LoginAttempts
+
+	Delete(key string)
+}
+
+type attemptEntry struct {
+	state     LoginAttempts
+	expiresAt time.Time
+}
+
+type LoginAttemptCache struct {
+	mu   sync.Mutex
+	data map[string]attemptEntry
+}
+
+func NewLoginAttempts() *LoginAttemptCache {
+	c := &LoginAttemptCache{data: make(map[string]attemptEntry)}
+	go c.sweep()
+	return c
+}
+
+func (c *LoginAttemptCache) Get(key string) LoginAttempts {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.data[key]
+	if !ok || time.Now().After(e.expiresAt) {
+		return LoginAttempts{}
+	}
+	return e.state
+}
+
+func (c *LoginAttemptCache) Update(key string, ttl time.Duration, fn func(*LoginAttempts)) LoginAttempts {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.data[key]
+	if !ok || time.Now().After(e.expiresAt) {
+		e = attemptEntry{}
+	}
+	fn(&e.state)
+	e.expiresAt = time.Now().Add(ttl)
+	c.data[key] = e
+	return e.state
+}
+
+func (c *LoginAttemptCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.data, key)
+}
+
+// sweep drops expired keys so a credential-stuffing run does not grow the map forever.
+func (c *LoginAttemptCache) sweep() {
+	ticker := time.NewTicker(10 * time.Minute)
+	defer ticker.Stop()
+	for range ticker.C {
+		now := time.Now()
+		c.mu.Lock()
+		for key, e := range c.data {
+			if now.After(e.expiresAt) {
+				delete(c.data, key)
+			}
+		}
+		c.mu.Unlock()
+	}
+}
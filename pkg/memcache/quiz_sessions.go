@@ -0,0 +1,124 @@
+package mem
+
+import (
+	"errors"
+	"maps"
+	"sync"
+	"time"
+)
+
+// QuizSession is the progress of one travel quiz. The store hands out copies, so a session
+// read on one instance of the API is never changed behind the back of another.
+type QuizSession struct {
+	SessionID   string            `json:"session_id"`
+	UserID      string            `json:"user_id"`
+	Answers     map[string]string `json:"answers"`
+	CurrentStep int               `json:"current_step"`
+	Prefilled   map[string]bool   `json:"prefilled,omitempty"` // answers from a travel preset
+	Complete    bool              `json:"complete"`            // every question answered, the plan can be generated
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	ExpiresAt   time.Time         `json:"expires_at"` // pushed back on every answer and heartbeat
+}
+
+// ErrNoQuizSession is returned by Update for a session the store does not hold.
+var ErrNoQuizSession = errors.New("quiz session not found")
+
+type QuizSessionStore interface {
+	Get(sessionID string) (QuizSession, bool)
+
+	// Put stores the session for ttl.
+	Put(session QuizSession, ttl time.Duration)
+
+	// Update applies fn to the session atomically and keeps the result for ttl. Nothing is
+	// stored when fn fails; its error is returned.
+	Update(sessionID string, ttl time.Duration, fn func(*QuizSession) error) (QuizSession, error)
+
+	// DeleteOwnedBy drops every session of the user and returns how many there were.
+	DeleteOwnedBy(userID string) int
+}
+
+type quizEntry struct {
+	session   QuizSession
+	expiresAt time.Time
+}
+
+type QuizSessionCache struct {
+	mu   sync.Mutex
+	data map[string]quizEntry
+}
+
+func NewQuizSessions() *QuizSessionCache {
+	c := &QuizSessionCache{data: make(map[string]quizEntry)}
+	go c.sweep()
+	return c
+}
+
+func (c *QuizSessionCache) Get(sessionID string) (QuizSession, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.data[sessionID]
+	if !ok || time.Now().After(e.expiresAt) {
+		return QuizSession{}, false
+	}
+	return cloneQuizSession(e.session), true
+}
+
+func (c *QuizSessionCache) Put(session QuizSession, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data[session.SessionID] = quizEntry{session: cloneQuizSession(session), expiresAt: time.Now().Add(ttl)}
+}
+
+func (c *QuizSessionCache) Update(sessionID string, ttl time.Duration, fn func(*QuizSession) error) (QuizSession, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.data[sessionID]
+	if !ok || time.Now().After(e.expiresAt) {
+		return QuizSession{}, ErrNoQuizSession
+	}
+	session := cloneQuizSession(e.session)
+	if err := fn(&session); err != nil {
+		return QuizSession{}, err
+	}
+	c.data[sessionID] = quizEntry{session: session, expiresAt: time.Now().Add(ttl)}
+	return cloneQuizSession(session), nil
+}
+
+func (c *QuizSessionCache) DeleteOwnedBy(userID string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := 0
+	for id, e := range c.data {
+		if e.session.UserID == userID {
+			delete(c.data, id)
+			n++
+		}
+	}
+	return n
+}
+
+// sweep drops expired sessions so abandoned quizzes do not grow the map forever.
+func (c *QuizSessionCache) sweep() {
+	ticker := time.NewTicker(10 * time.Minute)
+	defer ticker.Stop()
+	for range ticker.C {
+		now := time.Now()
+		c.mu.Lock()
+		for id, e := range c.data {
+			if now.After(e.expiresAt) {
+				delete(c.data, id)
+			}
+		}
+		c.mu.Unlock()
+	}
+}
+
+func cloneQuizSession(s QuizSession) QuizSession {
+	s.Answers = maps.Clone(s.Answers)
+	s.Prefilled = maps.Clone(s.Prefilled)
+	return s
+}
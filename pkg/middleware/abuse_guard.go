@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"vivu/pkg/utils"
+)
+
+// TrustedProxies reads TRUSTED_PROXIES (comma separated IPs or CIDRs of our own load balancers).
+// Only these may set X-Forwarded-For; with none configured gin keys every per-IP check on the
+// socket address, so a client cannot pick a fresh IP per request.
+func TrustedProxies() []string {
+	var proxies []string
+	for _, p := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			proxies = append(proxies, p)
+		}
+	}
+	return proxies
+}
+
+// IPRateLimiter is a fixed-window counter per client IP. It is in-memory on purpose:
+// it only has to slow down scripted abuse of public forms, not meter paid usage.
+type IPRateLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	window  time.Duration
+	buckets map[string]*ipBucket
+}
+
+type ipBucket struct {
+	count   int
+	resetAt time.Time
+}
+
+func NewIPRateLimiter(limit int, window time.Duration) *IPRateLimiter {
+	l := &IPRateLimiter{
+		limit:   limit,
+		window:  window,
+		buckets: make(map[string]*ipBucket),
+	}
+	go l.sweep()
+	return l
+}
+
+// Allow records a hit for ip and returns false (with the wait time) once the window is used up.
+func (l *IPRateLimiter) Allow(ip string) (bool, time.Duration) {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[ip]
+	if !ok || now.After(b.resetAt) {
+		l.buckets[ip] = &ipBucket{count: 1, resetAt: now.Add(l.window)}
+		return true, 0
+	}
+	if b.count >= l.limit {
+		return false, b.resetAt.Sub(now)
+	}
+	b.count++
+	return true, 0
+}
+
+func (l *IPRateLimiter) sweep() {
+	ticker := time.NewTicker(l.window)
+	defer ticker.Stop()
+	for range ticker.C {
+		now := time.Now()
+		l.mu.Lock()
+		for ip, b := range l.buckets {
+			if now.After(b.resetAt) {
+				delete(l.buckets, ip)
+			}
+		}
+		l.mu.Unlock()
+	}
+}
+
+// VelocityLimitMiddleware answers 429 once an IP exceeds the limiter's budget.
+func VelocityLimitMiddleware(limiter *IPRateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ok, wait := limiter.Allow(c.ClientIP()); !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			utils.RespondError(c, http.StatusTooManyRequests, "Too many requests, please try again later")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// CaptchaHeader carries the Turnstile/reCAPTCHA token from the client.
+const CaptchaHeader = "X-Captcha-Token"
+
+// CaptchaMiddleware verifies the CAPTCHA token when a verifier is configured; with a nil
+// verifier it is a no-op so local and mobile builds keep working without a site key.
+func CaptchaMiddleware(verifier utils.CaptchaVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if verifier == nil {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+		defer cancel()
+
+		ok, err := verifier.Verify(ctx, c.GetHeader(CaptchaHeader), c.ClientIP())
+		if err != nil {
+			// Provider outage should not lock everyone out; the velocity limit still applies,
+			// keyed on an IP the client cannot forge (see TrustedProxies)
+			log.Printf("[captcha] verify failed, letting request through: %v", err)
+			c.Next()
+			return
+		}
+		if !ok {
+			utils.RespondError(c, http.StatusForbidden, "CAPTCHA verification failed")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
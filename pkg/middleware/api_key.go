@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"vivu/pkg/utils"
+)
+
+// APIKeyHeader carries the key of a partner integration.
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyPrincipal is the account an API key acts for.
+type APIKeyPrincipal struct {
+	KeyID      string
+	AccountID  string
+	Role       string
+	DailyQuota int // as stored on the key; the meter resolves 0
+}
+
+// APIKeyVerifier resolves a raw key holding scope; see services.APIKeyService.Verify.
+type APIKeyVerifier func(ctx context.Context, key, scope string) (APIKeyPrincipal, error)
+
+// APIKeyMeter counts the calls of each key against its daily quota; see
+// services.APIUsageService.
+type APIKeyMeter interface {
+	// Allow counts one call of the key, or refuses it once the quota of the day is used up.
+	// limit is 0 when the key has no quota.
+	Allow(ctx context.Context, keyID string, quota int) (limit, remaining int, resetIn time.Duration, ok bool)
+	// Record notes the status an allowed call was answered with.
+	Record(keyID string, status int)
+}
+
+var (
+	apiKeyVerifier APIKeyVerifier
+	apiKeyMeter    APIKeyMeter
+)
+
+// UseAPIKeyVerifier enables X-API-Key on the routes using APIKeyAuth or OptionalAPIKey.
+// Call it before serving.
+func UseAPIKeyVerifier(v APIKeyVerifier) {
+	apiKeyVerifier = v
+}
+
+// UseAPIKeyMeter enables daily quotas and usage counts for key calls. Call it before serving.
+func UseAPIKeyMeter(m APIKeyMeter) {
+	apiKeyMeter = m
+}
+
+// APIKeyAuth accepts an X-API-Key holding scope and otherwise falls back to the bearer token
+// like JWTAuthMiddleware; scope "" accepts any key. A key call runs as the account the key
+// belongs to, with "api_key_id" set in the context.
+func APIKeyAuth(scope string) gin.HandlerFunc {
+	jwt := JWTAuthMiddleware()
+	return func(c *gin.Context) {
+		if c.GetHeader(APIKeyHeader) == "" {
+			jwt(c)
+			return
+		}
+		authenticateAPIKey(c, scope)
+	}
+}
+
+// OptionalAPIKey checks an X-API-Key when one is sent and lets anonymous calls through, for
+// routes that are public anyway.
+func OptionalAPIKey(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader(APIKeyHeader) == "" {
+			c.Next()
+			return
+		}
+		authenticateAPIKey(c, scope)
+	}
+}
+
+func authenticateAPIKey(c *gin.Context, scope string) {
+	if apiKeyVerifier == nil {
+		utils.HandleServiceError(c, utils.ErrInvalidAPIKey)
+		c.Abort()
+		return
+	}
+	principal, err := apiKeyVerifier(c.Request.Context(), c.GetHeader(APIKeyHeader), scope)
+	if err != nil {
+		utils.HandleServiceError(c, err)
+		c.Abort()
+		return
+	}
+
+	c.Set("user_id", principal.AccountID)
+	c.Set("Role", principal.Role)
+	c.Set("api_key_id", principal.KeyID)
+
+	if apiKeyMeter != nil {
+		limit, remaining, resetIn, ok := apiKeyMeter.Allow(c.Request.Context(), principal.KeyID, principal.DailyQuota)
+		if limit > 0 {
+			c.Header("X-RateLimit-Limit", strconv.Itoa(limit))
+			c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		}
+		if !ok {
+			utils.HandleServiceError(c, utils.ErrAPIQuotaExceeded.WithRetryAfter(resetIn))
+			c.Abort()
+			return
+		}
+		defer func() { apiKeyMeter.Record(principal.KeyID, c.Writer.Status()) }()
+	}
+	c.Next()
+}
@@ -1,12 +1,27 @@
 package middleware
 
 import (
+	"context"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strings"
+	"time"
 	"vivu/pkg/utils"
 )
 
+// AccountGuard refuses tokens of banned accounts and of revoked sessions; satisfied by
+// services.AccountSessionGuard.
+type AccountGuard interface {
+	Check(ctx context.Context, userID string, issuedAt time.Time) error
+}
+
+var accountGuard AccountGuard
+
+// UseAccountGuard makes JWTAuthMiddleware check every token against g. Call it before serving.
+func UseAccountGuard(g AccountGuard) {
+	accountGuard = g
+}
+
 func JWTAuthMiddleware() gin.HandlerFunc {
 
 	return func(c *gin.Context) {
@@ -38,7 +53,56 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if accountGuard != nil {
+			var issuedAt time.Time
+			if claims.IssuedAt != nil {
+				issuedAt = claims.IssuedAt.Time
+			}
+			if err := accountGuard.Check(c.Request.Context(), claims.UserId, issuedAt); err != nil {
+				utils.HandleServiceError(c, err)
+				c.Abort()
+				return
+			}
+		}
+
+		if err := checkLegalGate(c.Request.Context(), c.Request.URL.Path, claims.UserId, claims.Role); err != nil {
+			utils.HandleServiceError(c, err)
+			c.Abort()
+			return
+		}
+
 		// Pass user information to the next handler
+		c.Request = c.Request.WithContext(utils.WithDataOwner(c.Request.Context(), claims.UserId))
+		c.Set("user_id", claims.UserId)
+		c.Set("Role", claims.Role)
+		c.Next()
+	}
+}
+
+// OptionalJWTAuthMiddleware sets user_id and Role when the request carries a valid token and
+// lets it through as a guest otherwise, for endpoints that adapt to who is asking.
+func OptionalJWTAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if !strings.HasPrefix(authHeader, "Bearer ") {
+			c.Next()
+			return
+		}
+		claims, err := utils.ValidateToken(strings.TrimPrefix(authHeader, "Bearer "))
+		if err != nil {
+			c.Next()
+			return
+		}
+		if accountGuard != nil {
+			var issuedAt time.Time
+			if claims.IssuedAt != nil {
+				issuedAt = claims.IssuedAt.Time
+			}
+			if accountGuard.Check(c.Request.Context(), claims.UserId, issuedAt) != nil {
+				c.Next()
+				return
+			}
+		}
 		c.Set("user_id", claims.UserId)
 		c.Set("Role", claims.Role)
 		c.Next()
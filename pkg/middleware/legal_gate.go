@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"vivu/pkg/utils"
+)
+
+// LegalGate reports the legal documents an account still has to accept, as a
+// utils.ErrLegalAcceptanceRequired; satisfied by services.LegalServiceInterface.
+type LegalGate interface {
+	Pending(ctx context.Context, userID string) error
+}
+
+var legalGate LegalGate
+
+// Paths an account that has not accepted the current terms can still use: reading and
+// accepting them, and its own profile.
+var legalBypassPrefixes = []string{
+	"/legal",
+	"/admin",
+	"/accounts/me",
+	"/accounts/profile",
+}
+
+// UseLegalGate makes JWTAuthMiddleware answer 451 to accounts that have not accepted the
+// current terms of service or privacy policy. Call it before serving.
+func UseLegalGate(g LegalGate) {
+	legalGate = g
+}
+
+// checkLegalGate returns the 451 error for an account with documents to accept. Failing to look the
+// acceptances up lets the request through: the documents are asked for on the next one.
+func checkLegalGate(ctx context.Context, path, userID, role string) error {
+	if legalGate == nil || role == "admin" {
+		return nil
+	}
+	for _, p := range legalBypassPrefixes {
+		if strings.HasPrefix(path, p) {
+			return nil
+		}
+	}
+	err := legalGate.Pending(ctx, userID)
+	if err != nil && !errors.Is(err, utils.ErrLegalAcceptanceRequired) {
+		log.Printf("[legal] checking acceptances of %s: %v", userID, err)
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"vivu/pkg/utils"
+)
+
+// SwitchReader is satisfied by services.RuntimeSwitchServiceInterface.
+type SwitchReader interface {
+	IsEngaged(key string) (bool, string)
+}
+
+// Paths that stay reachable in maintenance mode, so admins can log in (with their second factor,
+// or after a lockout) and turn it off, payment callbacks are not lost and the apps can still
+// show the maintenance notice.
+var maintenanceBypassPrefixes = []string{
+	"/admin",
+	"/swagger",
+	"/accounts/login",
+	"/accounts/2fa/challenge",
+	"/accounts/unlock",
+	"/payments/webhook",
+	"/announcements/active",
+}
+
+// MaintenanceMiddleware answers 503 for every request while the maintenance switch is engaged.
+func MaintenanceMiddleware(switches SwitchReader, key string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		for _, p := range maintenanceBypassPrefixes {
+			if strings.HasPrefix(path, p) {
+				c.Next()
+				return
+			}
+		}
+
+		if on, msg := switches.IsEngaged(key); on {
+			c.Header("Retry-After", "120")
+			utils.RespondError(c, http.StatusServiceUnavailable, msg)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// KillSwitchMiddleware turns off a single expensive subsystem while its switch is engaged.
+func KillSwitchMiddleware(switches SwitchReader, key string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if on, msg := switches.IsEngaged(key); on {
+			c.Header("Retry-After", "300")
+			utils.RespondError(c, http.StatusServiceUnavailable, msg)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -3,11 +3,17 @@ package middleware
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// TraceIDMiddleware exposes the request's trace ID as "trace_id" and X-Trace-ID. Behind the
+// OpenTelemetry middleware it is the span's trace ID, so logs and traces share it.
 func TraceIDMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		traceID := uuid.New().String()
+		if sc := trace.SpanContextFromContext(c.Request.Context()); sc.HasTraceID() {
+			traceID = sc.TraceID().String()
+		}
 		c.Set("trace_id", traceID)
 		c.Writer.Header().Set("X-Trace-ID", traceID)
 		c.Next()
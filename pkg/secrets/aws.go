@@ -0,0 +1,127 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AWSSource reads one AWS Secrets Manager secret holding a JSON object of secret names to
+// values. Requests are signed with Signature Version 4 from static credentials.
+type AWSSource struct {
+	Region          string
+	SecretID        string // name or ARN, e.g. vivu/production
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // set with temporary credentials
+
+	HTTP *http.Client
+}
+
+func (a *AWSSource) Name() string {
+	return "aws:" + a.SecretID
+}
+
+func (a *AWSSource) Load(ctx context.Context) (map[string]string, error) {
+	payload, _ := json.Marshal(map[string]string{"SecretId": a.SecretID})
+	host := fmt.Sprintf("secretsmanager.%s.amazonaws.com", a.Region)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://"+host+"/", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	a.sign(req, host, payload, time.Now().UTC())
+
+	client := a.HTTP
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("aws secrets manager: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var out struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("aws secrets manager: %w", err)
+	}
+	var values map[string]any
+	if err := json.Unmarshal([]byte(out.SecretString), &values); err != nil {
+		return nil, fmt.Errorf("aws secrets manager: %s must be a JSON object: %w", a.SecretID, err)
+	}
+	return stringValues(values), nil
+}
+
+// sign adds the SigV4 headers for the secretsmanager service.
+func (a *AWSSource) sign(req *http.Request, host string, payload []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if a.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", a.SessionToken)
+	}
+
+	headers := map[string]string{
+		"content-type": req.Header.Get("Content-Type"),
+		"host":         host,
+		"x-amz-date":   amzDate,
+		"x-amz-target": req.Header.Get("X-Amz-Target"),
+	}
+	names := []string{"content-type", "host", "x-amz-date", "x-amz-target"}
+	if a.SessionToken != "" {
+		headers["x-amz-security-token"] = a.SessionToken
+		names = append(names, "x-amz-security-token")
+	}
+	var canonicalHeaders strings.Builder
+	for _, n := range names {
+		canonicalHeaders.WriteString(n + ":" + strings.TrimSpace(headers[n]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodPost, "/", "", canonicalHeaders.String(), signedHeaders, sha256Hex(payload),
+	}, "\n")
+	scope := day + "/" + a.Region + "/secretsmanager/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+a.SecretAccessKey), day)
+	key = hmacSHA256(key, a.Region)
+	key = hmacSHA256(key, "secretsmanager")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		a.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
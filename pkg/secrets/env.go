@@ -0,0 +1,60 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SourceFromEnv picks the secrets manager from SECRETS_BACKEND: "vault", "aws", or "env"
+// (default, environment variables only). Each environment reads its own secret, named
+// vivu/<SECRETS_ENV> (default vivu/development) unless VAULT_SECRET_PATH or AWS_SECRET_ID say
+// otherwise. Vault uses VAULT_ADDR, VAULT_TOKEN, VAULT_NAMESPACE and VAULT_KV_MOUNT (default
+// secret); AWS uses AWS_REGION, AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN.
+func SourceFromEnv() (Source, error) {
+	env := os.Getenv("SECRETS_ENV")
+	if env == "" {
+		env = "development"
+	}
+	path := "vivu/" + env
+
+	switch backend := strings.ToLower(os.Getenv("SECRETS_BACKEND")); backend {
+	case "", "env":
+		return nil, nil
+	case "vault":
+		v := &VaultSource{
+			Addr:      os.Getenv("VAULT_ADDR"),
+			Token:     os.Getenv("VAULT_TOKEN"),
+			Namespace: os.Getenv("VAULT_NAMESPACE"),
+			Mount:     os.Getenv("VAULT_KV_MOUNT"),
+			Path:      os.Getenv("VAULT_SECRET_PATH"),
+		}
+		if v.Addr == "" || v.Token == "" {
+			return nil, fmt.Errorf("secrets: VAULT_ADDR and VAULT_TOKEN are required")
+		}
+		if v.Mount == "" {
+			v.Mount = "secret"
+		}
+		if v.Path == "" {
+			v.Path = path
+		}
+		return v, nil
+	case "aws":
+		a := &AWSSource{
+			Region:          os.Getenv("AWS_REGION"),
+			SecretID:        os.Getenv("AWS_SECRET_ID"),
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+		if a.Region == "" || a.AccessKeyID == "" || a.SecretAccessKey == "" {
+			return nil, fmt.Errorf("secrets: AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required")
+		}
+		if a.SecretID == "" {
+			a.SecretID = path
+		}
+		return a, nil
+	default:
+		return nil, fmt.Errorf("secrets: unknown SECRETS_BACKEND %q", backend)
+	}
+}
@@ -0,0 +1,272 @@
+// Package secrets resolves API keys from a secrets manager (Vault or AWS Secrets Manager),
+// falling back to environment variables, and reloads them periodically so a rotated key is
+// picked up without a restart. Every read is counted per key fingerprint: after a rotation the
+// usage shows whether anything still runs on the old key before it is revoked.
+package secrets
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Getter returns the current value of a secret, empty when it is not set.
+type Getter interface {
+	Get(name string) string
+}
+
+// Source loads every secret of the environment at once, as name -> value.
+type Source interface {
+	Name() string
+	Load(ctx context.Context) (map[string]string, error)
+}
+
+// KeyUsage is how much one version of a secret was read since the last flush.
+type KeyUsage struct {
+	Name        string
+	Fingerprint string
+	Source      string
+	Uses        int64
+	FirstUsedAt time.Time
+	LastUsedAt  time.Time
+}
+
+// UsageSink keeps the usage of every instance; satisfied by repositories.SecretUsageRepository.
+type UsageSink interface {
+	RecordUsage(ctx context.Context, instance string, usage []KeyUsage) error
+}
+
+// KeyInfo describes the version of a secret an instance holds, never its value.
+type KeyInfo struct {
+	Name        string
+	Source      string
+	Fingerprint string
+	LoadedAt    time.Time
+	RotatedAt   time.Time // zero until the value changes while running
+}
+
+type secretValue struct {
+	value       string
+	source      string
+	fingerprint string
+	loadedAt    time.Time
+	rotatedAt   time.Time
+}
+
+type Store struct {
+	source   Source // nil: environment variables only
+	sink     UsageSink
+	interval time.Duration
+	instance string
+
+	mu     sync.RWMutex
+	values map[string]secretValue
+
+	usageMu sync.Mutex
+	usage   map[string]*KeyUsage // by name and fingerprint
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// NewStore loads source once; a failure leaves the environment variables in use until a
+// later refresh succeeds.
+func NewStore(source Source, sink UsageSink, interval time.Duration) *Store {
+	instance, _ := os.Hostname()
+	s := &Store{
+		source:   source,
+		sink:     sink,
+		interval: interval,
+		instance: instance,
+		values:   make(map[string]secretValue),
+		usage:    make(map[string]*KeyUsage),
+		stop:     make(chan struct{}),
+	}
+	if source != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		if _, err := s.Refresh(ctx); err != nil {
+			log.Printf("[secrets] loading from %s failed, using environment variables: %v", source.Name(), err)
+		}
+	}
+	return s
+}
+
+// Fingerprint identifies a value without revealing it.
+func Fingerprint(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// Instance names this process in the usage it records.
+func (s *Store) Instance() string {
+	return s.instance
+}
+
+// SourceName is where secrets come from: the secrets manager, or "env".
+func (s *Store) SourceName() string {
+	if s.source == nil {
+		return "env"
+	}
+	return s.source.Name()
+}
+
+func (s *Store) Get(name string) string {
+	s.mu.RLock()
+	v, ok := s.values[name]
+	s.mu.RUnlock()
+	if !ok {
+		raw := os.Getenv(name)
+		if raw == "" {
+			return ""
+		}
+		v = secretValue{value: raw, source: "env", fingerprint: Fingerprint(raw), loadedAt: time.Now()}
+		s.mu.Lock()
+		if cur, ok := s.values[name]; ok {
+			v = cur // loaded by a refresh meanwhile
+		} else {
+			s.values[name] = v
+		}
+		s.mu.Unlock()
+	}
+	s.recordUse(name, v)
+	return v.value
+}
+
+func (s *Store) recordUse(name string, v secretValue) {
+	now := time.Now()
+	key := name + "|" + v.fingerprint
+	s.usageMu.Lock()
+	u, ok := s.usage[key]
+	if !ok {
+		u = &KeyUsage{Name: name, Fingerprint: v.fingerprint, Source: v.source, FirstUsedAt: now}
+		s.usage[key] = u
+	}
+	u.Uses++
+	u.LastUsedAt = now
+	s.usageMu.Unlock()
+}
+
+// Refresh reloads the secrets manager and returns the names whose value changed. Secrets it
+// no longer has fall back to their environment variable.
+func (s *Store) Refresh(ctx context.Context) ([]string, error) {
+	if s.source == nil {
+		return nil, nil
+	}
+	loaded, err := s.source.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var rotated []string
+	s.mu.Lock()
+	next := make(map[string]secretValue, len(loaded))
+	for name, value := range loaded {
+		if value == "" {
+			continue
+		}
+		v := secretValue{value: value, source: s.source.Name(), fingerprint: Fingerprint(value), loadedAt: now}
+		if prev, ok := s.values[name]; ok {
+			v.rotatedAt = prev.rotatedAt
+			if prev.fingerprint != v.fingerprint {
+				v.rotatedAt = now
+				rotated = append(rotated, name)
+				log.Printf("[secrets] %s rotated (%s -> %s)", name, prev.fingerprint, v.fingerprint)
+			} else {
+				v.loadedAt = prev.loadedAt
+			}
+		}
+		next[name] = v
+	}
+	s.values = next
+	s.mu.Unlock()
+
+	sort.Strings(rotated)
+	return rotated, nil
+}
+
+// Keys describes the secrets read or loaded so far.
+func (s *Store) Keys() []KeyInfo {
+	s.mu.RLock()
+	out := make([]KeyInfo, 0, len(s.values))
+	for name, v := range s.values {
+		out = append(out, KeyInfo{Name: name, Source: v.source, Fingerprint: v.fingerprint, LoadedAt: v.loadedAt, RotatedAt: v.rotatedAt})
+	}
+	s.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Flush hands the usage counted since the last flush to the sink.
+func (s *Store) Flush(ctx context.Context) error {
+	if s.sink == nil {
+		return nil
+	}
+	s.usageMu.Lock()
+	pending := make([]KeyUsage, 0, len(s.usage))
+	for _, u := range s.usage {
+		pending = append(pending, *u)
+	}
+	s.usage = make(map[string]*KeyUsage)
+	s.usageMu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if err := s.sink.RecordUsage(ctx, s.instance, pending); err != nil {
+		// Counted again with the next flush
+		s.usageMu.Lock()
+		for i := range pending {
+			p := pending[i]
+			key := p.Name + "|" + p.Fingerprint
+			if u, ok := s.usage[key]; ok {
+				u.Uses += p.Uses
+				u.FirstUsedAt = p.FirstUsedAt
+			} else {
+				s.usage[key] = &p
+			}
+		}
+		s.usageMu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Start refreshes the secrets and flushes their usage every interval.
+func (s *Store) Start() {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				if err := s.Flush(ctx); err != nil {
+					log.Printf("[secrets] flushing usage failed: %v", err)
+				}
+				cancel()
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			if _, err := s.Refresh(ctx); err != nil {
+				log.Printf("[secrets] refreshing from %s failed, keeping the loaded keys: %v", s.SourceName(), err)
+			}
+			if err := s.Flush(ctx); err != nil {
+				log.Printf("[secrets] flushing usage failed: %v", err)
+			}
+			cancel()
+		}
+	}()
+}
+
+func (s *Store) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
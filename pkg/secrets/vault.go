@@ -0,0 +1,77 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VaultSource reads one KV version 2 secret, whose keys are the secret names
+// (GEMINI_API_KEY, MAPBOX_ACCESS_TOKEN, ...).
+type VaultSource struct {
+	Addr      string // e.g. https://vault.internal:8200
+	Token     string
+	Namespace string // Vault Enterprise only
+	Mount     string // KV mount, "secret" by default
+	Path      string // e.g. vivu/production
+
+	HTTP *http.Client
+}
+
+func (v *VaultSource) Name() string {
+	return "vault:" + v.Path
+}
+
+func (v *VaultSource) Load(ctx context.Context) (map[string]string, error) {
+	url := fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimRight(v.Addr, "/"), v.Mount, strings.Trim(v.Path, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+
+	client := v.HTTP
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var doc struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+	return stringValues(doc.Data.Data), nil
+}
+
+// stringValues keeps the string entries of a secret document.
+func stringValues(in map[string]any) map[string]string {
+	out := make(map[string]string, len(in))
+	for k, v := range in {
+		if s, ok := v.(string); ok {
+			out[k] = s
+		}
+	}
+	return out
+}
@@ -0,0 +1,285 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"vivu/internal/models/request_models"
+
+	"github.com/pgvector/pgvector-go"
+	"github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/api/googleapi"
+)
+
+// AIProvider is one named backend of an AIRouter.
+type AIProvider struct {
+	Name   string
+	Client EmbeddingClientInterface
+}
+
+// AIProviderStats is a snapshot of one provider's counters since startup.
+type AIProviderStats struct {
+	Name        string `json:"name"`
+	Requests    int64  `json:"requests"`
+	Failures    int64  `json:"failures"`
+	Failovers   int64  `json:"failovers"` // calls handed to the next provider after this one failed or was open
+	CircuitOpen bool   `json:"circuit_open"`
+	OpenUntil   string `json:"open_until,omitempty"`
+	Embeddings  bool   `json:"embeddings"` // serves GetEmbedding(s)
+}
+
+// AIStatsReporter is implemented by clients that keep per-provider counters.
+type AIStatsReporter interface {
+	AIProviderStats() []AIProviderStats
+}
+
+type AIRouterConfig struct {
+	CallTimeout      time.Duration // per provider attempt, so a hung provider still leaves time for the next
+	FailureThreshold int           // consecutive retryable failures that open the circuit
+	Cooldown         time.Duration // how long an open circuit skips the provider
+}
+
+// AIRouter implements EmbeddingClientInterface over several providers. Generation calls go to
+// the providers in order and move on when one is out of quota, times out or errors server-side.
+// Embeddings always use the one embedding provider: vectors from different models cannot be
+// compared with the ones already stored, so they never fail over.
+type AIRouter struct {
+	providers []*routedProvider
+	embedder  *routedProvider
+	cfg       AIRouterConfig
+}
+
+type routedProvider struct {
+	AIProvider
+
+	mu        sync.Mutex
+	requests  int64
+	failures  int64
+	failovers int64
+	streak    int // consecutive retryable failures
+	openUntil time.Time
+}
+
+// NewAIRouter routes generation over providers in order and embeddings to the provider named embedding.
+func NewAIRouter(providers []AIProvider, embedding string, cfg AIRouterConfig) (*AIRouter, error) {
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("ai router: no providers")
+	}
+	if cfg.CallTimeout <= 0 {
+		cfg.CallTimeout = 60 * time.Second
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 3
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 2 * time.Minute
+	}
+
+	r := &AIRouter{cfg: cfg}
+	for _, p := range providers {
+		rp := &routedProvider{AIProvider: p}
+		r.providers = append(r.providers, rp)
+		if p.Name == embedding {
+			r.embedder = rp
+		}
+	}
+	if r.embedder == nil {
+		return nil, fmt.Errorf("ai router: embedding provider %q is not in the provider list", embedding)
+	}
+	return r, nil
+}
+
+func (r *AIRouter) GetEmbedding(ctx context.Context, text string) (pgvector.Vector, error) {
+	return callProvider(r, ctx, r.embedder, func(ctx context.Context, c EmbeddingClientInterface) (pgvector.Vector, error) {
+		return c.GetEmbedding(ctx, text)
+	})
+}
+
+func (r *AIRouter) GetEmbeddings(ctx context.Context, texts []string) ([]pgvector.Vector, error) {
+	return callProvider(r, ctx, r.embedder, func(ctx context.Context, c EmbeddingClientInterface) ([]pgvector.Vector, error) {
+		return c.GetEmbeddings(ctx, texts)
+	})
+}
+
+func (r *AIRouter) GenerateStructuredPlan(ctx context.Context, userPrompt string, pois []string, dayCount int) (string, error) {
+	return routeGeneration(r, ctx, func(ctx context.Context, c EmbeddingClientInterface) (string, error) {
+		return c.GenerateStructuredPlan(ctx, userPrompt, pois, dayCount)
+	})
+}
+
+func (r *AIRouter) GeneratePlanOnlyJSON(ctx context.Context, profile any, poiList []request_models.POISummary, dayCount int) (string, error) {
+	return routeGeneration(r, ctx, func(ctx context.Context, c EmbeddingClientInterface) (string, error) {
+		return c.GeneratePlanOnlyJSON(ctx, profile, poiList, dayCount)
+	})
+}
+
+// routeGeneration tries providers in order, skipping open circuits. When every circuit is open
+// the providers are tried anyway: a slim chance of an answer beats a certain error.
+func routeGeneration[T any](r *AIRouter, ctx context.Context, call func(context.Context, EmbeddingClientInterface) (T, error)) (T, error) {
+	order := make([]*routedProvider, 0, len(r.providers))
+	var open []*routedProvider
+	for _, p := range r.providers {
+		if p.isOpen() {
+			open = append(open, p)
+			continue
+		}
+		order = append(order, p)
+	}
+	if len(order) == 0 {
+		order = open
+	} else {
+		for _, p := range open {
+			p.countFailover()
+		}
+	}
+
+	var zero T
+	var lastErr error
+	for i, p := range order {
+		out, err := callProvider(r, ctx, p, call)
+		if err == nil {
+			return out, nil
+		}
+		lastErr = err
+		// A cancelled caller or a bad request would fail the same way anywhere
+		if ctx.Err() != nil || !IsRetryableAIError(err) {
+			return zero, err
+		}
+		if i+1 < len(order) {
+			p.countFailover()
+			log.Printf("[ai] %s failed, failing over to %s: %v", p.Name, order[i+1].Name, err)
+		}
+	}
+	return zero, lastErr
+}
+
+func callProvider[T any](r *AIRouter, ctx context.Context, p *routedProvider, call func(context.Context, EmbeddingClientInterface) (T, error)) (T, error) {
+	callCtx, cancel := context.WithTimeout(ctx, r.cfg.CallTimeout)
+	defer cancel()
+	callCtx, span := Tracer.Start(callCtx, "ai."+p.Name, trace.WithAttributes(attribute.String("ai.provider", p.Name)))
+	defer span.End()
+
+	out, err := call(callCtx, p.Client)
+	// Our own per-call timeout is the provider's fault; the caller's is not
+	if err != nil && ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) && !errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("%s: %w (%v)", p.Name, context.DeadlineExceeded, err)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	p.record(err, ctx.Err() == nil && IsRetryableAIError(err), r.cfg)
+	return out, err
+}
+
+func (p *routedProvider) record(err error, retryable bool, cfg AIRouterConfig) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests++
+	if err == nil {
+		p.streak = 0
+		return
+	}
+	p.failures++
+	if !retryable {
+		return
+	}
+	p.streak++
+	if p.streak >= cfg.FailureThreshold {
+		p.openUntil = time.Now().Add(cfg.Cooldown)
+		p.streak = 0
+		log.Printf("[ai] circuit for %s open until %s: %v", p.Name, p.openUntil.Format(time.RFC3339), err)
+	}
+}
+
+func (p *routedProvider) isOpen() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return time.Now().Before(p.openUntil)
+}
+
+func (p *routedProvider) countFailover() {
+	p.mu.Lock()
+	p.failovers++
+	p.mu.Unlock()
+}
+
+func (r *AIRouter) AIProviderStats() []AIProviderStats {
+	now := time.Now()
+	out := make([]AIProviderStats, 0, len(r.providers))
+	for _, p := range r.providers {
+		p.mu.Lock()
+		s := AIProviderStats{
+			Name:        p.Name,
+			Requests:    p.requests,
+			Failures:    p.failures,
+			Failovers:   p.failovers,
+			CircuitOpen: now.Before(p.openUntil),
+			Embeddings:  p == r.embedder,
+		}
+		if s.CircuitOpen {
+			s.OpenUntil = p.openUntil.Format(time.RFC3339)
+		}
+		p.mu.Unlock()
+		out = append(out, s)
+	}
+	return out
+}
+
+// IsRetryableAIError is true for quota, rate-limit, timeout and server-side errors, i.e. the
+// ones another provider could answer. Bad requests and malformed output are not retried.
+func IsRetryableAIError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return retryableStatus(apiErr.HTTPStatusCode)
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return retryableStatus(reqErr.HTTPStatusCode)
+	}
+	var gErr *googleapi.Error
+	if errors.As(err, &gErr) {
+		return retryableStatus(gErr.Code)
+	}
+	var aErr *AnthropicAPIError
+	if errors.As(err, &aErr) {
+		return retryableStatus(aErr.StatusCode)
+	}
+	var oErr *OllamaAPIError
+	if errors.As(err, &oErr) {
+		return retryableStatus(oErr.StatusCode)
+	}
+
+	// The Gemini SDK surfaces gRPC statuses as text
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"resource_exhausted", "quota", "rate limit", "unavailable", "deadline_exceeded", "overloaded"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code == http.StatusRequestTimeout || code >= 500
+}
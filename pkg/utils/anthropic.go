@@ -0,0 +1,207 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"vivu/internal/models/request_models"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+const (
+	anthropicMessagesURL = "https://api.anthropic.com/v1/messages"
+	anthropicAPIVersion  = "2023-06-01"
+)
+
+// AnthropicClient generates plans with Claude through the Messages API. Anthropic has no
+// embeddings endpoint, so run it behind the AI router with another embedding provider.
+type AnthropicClient struct {
+	apiKey    string
+	model     string
+	maxTokens int
+	http      *http.Client
+	parser    *GeminiEmbeddingClient // only used for its cleanJSONResponse
+}
+
+// AnthropicAPIError is a non-2xx answer from the Messages API.
+type AnthropicAPIError struct {
+	StatusCode int
+	Type       string
+	Message    string
+}
+
+func (e *AnthropicAPIError) Error() string {
+	return fmt.Sprintf("anthropic: %d %s: %s", e.StatusCode, e.Type, e.Message)
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	MaxTokens   int                `json:"max_tokens"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	Temperature float64            `json:"temperature"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Error      *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// NewAnthropicClient creates the Claude provider. ANTHROPIC_MAX_TOKENS caps the answer (default 8192).
+func NewAnthropicClient(apiKey, model string) EmbeddingClientInterface {
+	maxTokens := 8192
+	if v, err := strconv.Atoi(os.Getenv("ANTHROPIC_MAX_TOKENS")); err == nil && v > 0 {
+		maxTokens = v
+	}
+	return &AnthropicClient{
+		apiKey:    apiKey,
+		model:     model,
+		maxTokens: maxTokens,
+		http:      NewTracedHTTPClient(90 * time.Second),
+		parser:    &GeminiEmbeddingClient{},
+	}
+}
+
+func (c *AnthropicClient) GetEmbedding(ctx context.Context, text string) (pgvector.Vector, error) {
+	return pgvector.Vector{}, fmt.Errorf("anthropic provider does not support embeddings")
+}
+
+func (c *AnthropicClient) GetEmbeddings(ctx context.Context, texts []string) ([]pgvector.Vector, error) {
+	return nil, fmt.Errorf("anthropic provider does not support embeddings")
+}
+
+func (c *AnthropicClient) GenerateStructuredPlan(ctx context.Context, userPrompt string, pois []string, dayCount int) (string, error) {
+	if strings.TrimSpace(userPrompt) == "" {
+		return "", fmt.Errorf("user prompt cannot be empty")
+	}
+	if len(pois) == 0 {
+		return "", fmt.Errorf("POI list cannot be empty")
+	}
+	if dayCount < 1 || dayCount > 30 {
+		return "", fmt.Errorf("bad dayCount")
+	}
+
+	system := fmt.Sprintf(`You are a travel planner AI.
+
+Generate a %d-day itinerary based on the user prompt and the list of POIs.
+
+Each day should include:
+- day: number
+- date: optional
+- activities: list of activity blocks
+- Each activity includes: activity, start_time, end_time, main_poi_id, alternative_poi_ids, what_to_do
+
+Only use the POI IDs provided. Do not invent new places.
+Answer with the JSON only: no markdown, no prose.`, dayCount)
+
+	var poiBuf strings.Builder
+	for _, poi := range pois {
+		poiBuf.WriteString("- " + poi + "\n")
+	}
+	content, err := c.complete(ctx, system, fmt.Sprintf("User prompt: %s\n\nAvailable POIs:\n%s", userPrompt, poiBuf.String()), 0.3)
+	if err != nil {
+		return "", err
+	}
+	content = c.parser.cleanJSONResponse(content)
+	if !json.Valid([]byte(content)) {
+		return "", fmt.Errorf("not valid json")
+	}
+	return content, nil
+}
+
+func (c *AnthropicClient) GeneratePlanOnlyJSON(ctx context.Context, profile any, poiList []request_models.POISummary, dayCount int) (string, error) {
+	if err := validatePlanOnlyArgs(poiList, dayCount); err != nil {
+		return "", err
+	}
+
+	// No JSON mode in the Messages API: the system prompt pins the format and we cut the object out
+	content, err := c.complete(ctx,
+		"You are a scheduling engine. Reply with a single JSON object and nothing else: no markdown fences, no commentary.",
+		buildPlanOnlyPrompt(profile, poiList, dayCount), 0.1)
+	if err != nil {
+		return "", err
+	}
+	content = c.parser.cleanJSONResponse(content)
+	if !json.Valid([]byte(content)) {
+		return "", fmt.Errorf("not valid json")
+	}
+	return content, nil
+}
+
+// complete sends one user turn and returns the concatenated text blocks of the answer.
+func (c *AnthropicClient) complete(ctx context.Context, system, user string, temperature float64) (string, error) {
+	body, err := json.Marshal(anthropicRequest{
+		Model:       c.model,
+		MaxTokens:   c.maxTokens,
+		System:      system,
+		Messages:    []anthropicMessage{{Role: "user", Content: user}},
+		Temperature: temperature,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, anthropicMessagesURL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("anthropic-version", anthropicAPIVersion)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("anthropic: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("anthropic: %w", err)
+	}
+	var out anthropicResponse
+	if err := json.Unmarshal(raw, &out); err != nil && resp.StatusCode < 300 {
+		return "", fmt.Errorf("anthropic: decode response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		apiErr := &AnthropicAPIError{StatusCode: resp.StatusCode, Type: http.StatusText(resp.StatusCode)}
+		if out.Error != nil {
+			apiErr.Type, apiErr.Message = out.Error.Type, out.Error.Message
+		}
+		return "", apiErr
+	}
+	if out.StopReason == "max_tokens" {
+		return "", fmt.Errorf("anthropic: answer truncated at %d tokens", c.maxTokens)
+	}
+
+	var text strings.Builder
+	for _, block := range out.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return "", fmt.Errorf("no content")
+	}
+	return text.String(), nil
+}
@@ -1,140 +1,43 @@
 package utils
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"log"
+	"math"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
 )
 
 type APIResponse struct {
-	Status  string      `json:"status"`
-	Code    int         `json:"code"`
-	Message string      `json:"message,omitempty"`
-	TraceID string      `json:"trace_id,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
+	Status  string `json:"status"`
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+	// Stable error identifier (AppError.Code), only on errors from HandleServiceError
+	ErrorCode string      `json:"error_code,omitempty"`
+	TraceID   string      `json:"trace_id,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
 }
 
-var errorHandlers = map[error]func(*gin.Context, string){
-	ErrTagNotFound: func(c *gin.Context, traceID string) {
-		c.JSON(http.StatusOK, APIResponse{
-			Status:  "error",
-			Code:    http.StatusOK,
-			Message: "Tag not found",
-			TraceID: traceID,
-		})
-	},
-	ErrInvalidPage: func(c *gin.Context, traceID string) {
-		c.JSON(http.StatusBadRequest, APIResponse{
-			Status:  "error",
-			Code:    http.StatusBadRequest,
-			Message: "Page must be greater than 0",
-			TraceID: traceID,
-		})
-	},
-	ErrInvalidPageSize: func(c *gin.Context, traceID string) {
-		c.JSON(http.StatusBadRequest, APIResponse{
-			Status:  "error",
-			Code:    http.StatusBadRequest,
-			Message: "Page size must be between 1 and 100",
-			TraceID: traceID,
-		})
-	},
-	ErrDatabaseError: func(c *gin.Context, traceID string) {
-		c.JSON(http.StatusOK, APIResponse{
-			Status:  "error",
-			Code:    http.StatusInternalServerError,
-			Message: "Internal server error",
-			TraceID: traceID,
-		})
-	},
-	ErrUnexpectedBehaviorOfAI: func(c *gin.Context, traceID string) {
-		c.JSON(http.StatusOK, APIResponse{
-			Status:  "error",
-			Code:    http.StatusInternalServerError,
-			Message: "Unexpected error from AI service",
-			TraceID: traceID,
-		})
-	},
-	ErrPoorQualityInput: func(c *gin.Context, traceID string) {
-		c.JSON(http.StatusOK, APIResponse{
-			Status:  "improve_input",
-			Code:    http.StatusBadRequest,
-			Message: "Input quality is too low please consider improving it so we can help you better",
-			TraceID: traceID,
-		})
-	},
-	ErrInvalidInput: func(c *gin.Context, traceID string) {
-		c.JSON(http.StatusOK, APIResponse{
-			Status:  "bad Request",
-			Code:    http.StatusBadRequest,
-			Message: "Invalid input",
-			TraceID: traceID,
-		})
-	},
-	ErrAccountNotFound: func(c *gin.Context, traceID string) {
-		c.JSON(http.StatusOK, APIResponse{
-			Status:  "error",
-			Code:    http.StatusNotFound,
-			Message: "Account not found",
-			TraceID: traceID,
-		})
-	},
-	ErrInvalidCredentials: func(c *gin.Context, traceID string) {
-		c.JSON(http.StatusOK, APIResponse{
-			Status:  "error",
-			Code:    http.StatusUnauthorized,
-			Message: "User or password is incorrect",
-			TraceID: traceID,
-		})
-	},
-	ErrEmailAlreadyExists: func(c *gin.Context, traceID string) {
-		c.JSON(http.StatusOK, APIResponse{
-			Status:  "error",
-			Code:    http.StatusConflict,
-			Message: "Email already exists",
-			TraceID: traceID,
-		})
-	},
-	ErrJourneyNotFound: func(c *gin.Context, traceID string) {
-		c.JSON(http.StatusOK, APIResponse{
-			Status:  "error",
-			Code:    http.StatusNotFound,
-			Message: "Journey not found",
-			TraceID: traceID,
-		})
-	},
-	ErrThirdService: func(c *gin.Context, traceID string) {
-		c.JSON(http.StatusOK, APIResponse{
-			Status:  "error",
-			Code:    http.StatusBadGateway,
-			Message: "Error from third party service",
-			TraceID: traceID,
-		})
-	},
-	ErrInvalidToken: func(c *gin.Context, traceID string) {
-		c.JSON(http.StatusOK, APIResponse{
-			Status:  "error",
-			Code:    http.StatusUnauthorized,
-			Message: "Invalid token",
-			TraceID: traceID,
-		})
-	},
-	ErrUserDoNotHavePremium: func(c *gin.Context, traceID string) {
-		c.JSON(http.StatusBadRequest, APIResponse{
-			Status:  "error",
-			Code:    http.StatusForbidden,
-			Message: "User do not have premium access to generate plan more than 3 days",
-			TraceID: traceID,
-		})
-	},
-	ErrPOINotFound: func(c *gin.Context, traceID string) {
-		c.JSON(http.StatusOK, APIResponse{
-			Status:  "error",
-			Code:    http.StatusNotFound,
-			Message: "Point of Interest not found",
-			TraceID: traceID,
-		})
-	},
+// StatusCodesHeader lets a client pick how HandleServiceError reports errors: "strict" sends
+// the logical code as the HTTP status, "legacy" keeps the historical status (mostly 200) with
+// the real code only in the body. Clients that send nothing get legacy, so mobile releases
+// that predate the header keep working; API_STATUS_CODES=strict flips the default once they
+// are gone.
+const StatusCodesHeader = "X-Status-Codes"
+
+var legacyStatusDefault = !strings.EqualFold(os.Getenv("API_STATUS_CODES"), "strict")
+
+func useLegacyStatus(c *gin.Context) bool {
+	switch strings.ToLower(c.GetHeader(StatusCodesHeader)) {
+	case "strict":
+		return false
+	case "legacy":
+		return true
+	}
+	return legacyStatusDefault
 }
 
 func RespondSuccess(c *gin.Context, data interface{}, message string) {
@@ -158,19 +61,20 @@ func RespondError(c *gin.Context, code int, message string) {
 	})
 }
 
-// HandleServiceError O(1)
+// HandleServiceError reports err to the client: AppErrors (also wrapped ones) with their own
+// status and message, anything else as a 500. Causes are logged with the trace ID.
 func HandleServiceError(c *gin.Context, err error) {
-	traceID, _ := c.Get("trace_id")
+	traceID := c.GetString("trace_id")
 
-	if handler, exists := errorHandlers[err]; exists {
-		handler(c, traceID.(string))
-	} else {
-		log.Printf("Unknown error: %v", err)
-		c.JSON(http.StatusOK, APIResponse{
-			Status:  "error",
-			Code:    http.StatusInternalServerError,
-			Message: "Internal server error",
-			TraceID: traceID.(string),
-		})
+	var appErr *AppError
+	if !errors.As(err, &appErr) {
+		log.Printf("[%s] unhandled error: %v", traceID, err)
+		appErr = ErrInternal.Wrap(err)
+	} else if appErr.Status >= http.StatusInternalServerError || appErr.Cause != nil {
+		log.Printf("[%s] %s: %v", traceID, appErr.Code, err)
+	}
+	if appErr.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(appErr.RetryAfter.Seconds()))))
 	}
+	c.JSON(appErr.httpStatus(useLegacyStatus(c)), appErr.response(traceID))
 }
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"net/http"
+	"time"
+)
+
+// AppError is an error the API knows how to report: a stable code for clients, the HTTP
+// status, a message safe to show and the underlying cause, which is logged but never sent.
+type AppError struct {
+	Code    string // e.g. "journey_not_found"
+	Status  int    // HTTP status, also APIResponse.Code
+	Message string
+	Cause   error
+	Data    any // extra payload for the client, e.g. the remaining quota
+
+	// RetryAfter is sent as the Retry-After header when set, e.g. on a login lockout
+	RetryAfter time.Duration
+
+	detail       string // Error() text when there is no cause
+	kind         string // APIResponse.Status; "error" when empty
+	legacyStatus int    // HTTP status for legacy clients (see StatusCodesHeader); Status when 0
+}
+
+// NewAppError creates an error reported with status and message.
+func NewAppError(code string, status int, message string) *AppError {
+	return &AppError{Code: code, Status: status, Message: message}
+}
+
+func (e *AppError) Error() string {
+	text := e.detail
+	if text == "" {
+		text = e.Message
+	}
+	if e.Cause != nil {
+		return text + ": " + e.Cause.Error()
+	}
+	return text
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Cause
+}
+
+// Is matches every AppError with the same code, so a wrapped or reworded copy still
+// satisfies errors.Is(err, ErrJourneyNotFound).
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	return ok && t.Code == e.Code
+}
+
+// Wrap returns a copy of e caused by cause.
+func (e *AppError) Wrap(cause error) *AppError {
+	out := *e
+	out.Cause = cause
+	return &out
+}
+
+// WithMessage returns a copy of e shown to the client as message.
+func (e *AppError) WithMessage(message string) *AppError {
+	out := *e
+	out.Message = message
+	return &out
+}
+
+// WithData returns a copy of e sent with data.
+func (e *AppError) WithData(data any) *AppError {
+	out := *e
+	out.Data = data
+	return &out
+}
+
+// WithRetryAfter returns a copy of e telling the client to retry after d.
+func (e *AppError) WithRetryAfter(d time.Duration) *AppError {
+	out := *e
+	out.RetryAfter = d
+	return &out
+}
+
+func (e *AppError) response(traceID string) APIResponse {
+	kind := e.kind
+	if kind == "" {
+		kind = "error"
+	}
+	return APIResponse{
+		Status:    kind,
+		Code:      e.Status,
+		ErrorCode: e.Code,
+		Message:   e.Message,
+		TraceID:   traceID,
+		Data:      e.Data,
+	}
+}
+
+func (e *AppError) httpStatus(legacy bool) int {
+	if legacy && e.legacyStatus != 0 {
+		return e.legacyStatus
+	}
+	if e.Status == 0 {
+		return http.StatusInternalServerError
+	}
+	return e.Status
+}
@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// ErrEncryptionKeyMissing is returned when DATA_ENCRYPTION_KEY is not configured.
+var ErrEncryptionKeyMissing = errors.New("DATA_ENCRYPTION_KEY is not set")
+
+var (
+	dataKeyOnce sync.Once
+	dataKey     []byte
+	dataKeyErr  error
+)
+
+// loadDataKey reads DATA_ENCRYPTION_KEY, 32 bytes as base64 or hex, once.
+func loadDataKey() ([]byte, error) {
+	dataKeyOnce.Do(func() {
+		raw := os.Getenv("DATA_ENCRYPTION_KEY")
+		if raw == "" {
+			dataKeyErr = ErrEncryptionKeyMissing
+			return
+		}
+		if k, err := base64.StdEncoding.DecodeString(raw); err == nil && len(k) == 32 {
+			dataKey = k
+			return
+		}
+		if k, err := hex.DecodeString(raw); err == nil && len(k) == 32 {
+			dataKey = k
+			return
+		}
+		dataKeyErr = fmt.Errorf("DATA_ENCRYPTION_KEY must be 32 bytes, base64 or hex encoded")
+	})
+	return dataKey, dataKeyErr
+}
+
+// EncryptBytes seals data with AES-256-GCM under DATA_ENCRYPTION_KEY; the nonce is prepended.
+func EncryptBytes(plain []byte) ([]byte, error) {
+	gcm, err := dataCipher()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plain, nil), nil
+}
+
+// DecryptBytes opens data sealed by EncryptBytes.
+func DecryptBytes(sealed []byte) ([]byte, error) {
+	gcm, err := dataCipher()
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, body := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, body, nil)
+}
+
+// EncryptString is EncryptBytes for short secrets stored in text columns (base64).
+func EncryptString(plain string) (string, error) {
+	sealed, err := EncryptBytes([]byte(plain))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptString opens a value sealed by EncryptString.
+func DecryptString(sealed string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", err
+	}
+	plain, err := DecryptBytes(raw)
+	return string(plain), err
+}
+
+func dataCipher() (cipher.AEAD, error) {
+	key, err := loadDataKey()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -1,24 +1,376 @@
 package utils
 
-import "errors"
+import "net/http"
 
+// Errors services return. Each one carries how the API reports it; wrap a cause with Wrap or
+// reword it with WithMessage, errors.Is still matches the variable below.
 var (
-	ErrTagNotFound            = errors.New("tag not found")
-	ErrInvalidPage            = errors.New("invalid page parameter")
-	ErrInvalidPageSize        = errors.New("invalid page size parameter")
-	ErrDatabaseError          = errors.New("database error")
-	ErrPOINotFound            = errors.New("poi not found")
-	ErrUnexpectedBehaviorOfAI = errors.New("unexpected error from AI service")
-	ErrInvalidInput           = errors.New("invalid input")
-	ErrPoorQualityInput       = errors.New("input quality is too low please consider improving it so we can help you better")
-	ErrUnauthorized           = errors.New("unauthorized")
-	ErrUnauthenticated        = errors.New("unauthenticated")
-	ErrAccountNotFound        = errors.New("account not found")
-	ErrInvalidCredentials     = errors.New("user or password is incorrect")
-	ErrEmailAlreadyExists     = errors.New("email already exists")
-	ErrJourneyNotFound        = errors.New("journey not found")
-	RecordNotFound            = errors.New("record not found")
-	ErrThirdService           = errors.New("third service error")
-	ErrInvalidToken           = errors.New("invalid token")
-	ErrUserDoNotHavePremium   = errors.New("user do not have premium")
+	ErrInternal = &AppError{
+		Code:         "internal_error",
+		Status:       http.StatusInternalServerError,
+		Message:      "Internal server error",
+		detail:       "internal error",
+		legacyStatus: http.StatusOK,
+	}
+	ErrTagNotFound = &AppError{
+		Code:         "tag_not_found",
+		Status:       http.StatusNotFound,
+		Message:      "Tag not found",
+		detail:       "tag not found",
+		legacyStatus: http.StatusOK,
+	}
+	ErrInvalidPage = &AppError{
+		Code:    "invalid_page",
+		Status:  http.StatusBadRequest,
+		Message: "Page must be greater than 0",
+		detail:  "invalid page parameter",
+	}
+	ErrInvalidPageSize = &AppError{
+		Code:    "invalid_page_size",
+		Status:  http.StatusBadRequest,
+		Message: "Page size must be between 1 and 100",
+		detail:  "invalid page size parameter",
+	}
+	ErrDatabaseError = &AppError{
+		Code:         "database_error",
+		Status:       http.StatusInternalServerError,
+		Message:      "Internal server error",
+		detail:       "database error",
+		legacyStatus: http.StatusOK,
+	}
+	ErrPOINotFound = &AppError{
+		Code:         "poi_not_found",
+		Status:       http.StatusNotFound,
+		Message:      "Point of Interest not found",
+		detail:       "poi not found",
+		legacyStatus: http.StatusOK,
+	}
+	ErrUnexpectedBehaviorOfAI = &AppError{
+		Code:         "ai_error",
+		Status:       http.StatusInternalServerError,
+		Message:      "Unexpected error from AI service",
+		detail:       "unexpected error from AI service",
+		legacyStatus: http.StatusOK,
+	}
+	ErrInvalidInput = &AppError{
+		Code:         "invalid_input",
+		Status:       http.StatusBadRequest,
+		Message:      "Invalid input",
+		detail:       "invalid input",
+		kind:         "bad Request",
+		legacyStatus: http.StatusOK,
+	}
+	ErrPoorQualityInput = &AppError{
+		Code:         "poor_quality_input",
+		Status:       http.StatusBadRequest,
+		Message:      "Input quality is too low please consider improving it so we can help you better",
+		detail:       "input quality is too low please consider improving it so we can help you better",
+		kind:         "improve_input",
+		legacyStatus: http.StatusOK,
+	}
+	ErrUnauthorized = &AppError{
+		Code:         "unauthorized",
+		Status:       http.StatusForbidden,
+		Message:      "You do not have access to this resource",
+		detail:       "unauthorized",
+		legacyStatus: http.StatusOK,
+	}
+	ErrUnauthenticated = &AppError{
+		Code:         "unauthenticated",
+		Status:       http.StatusUnauthorized,
+		Message:      "Authentication required",
+		detail:       "unauthenticated",
+		legacyStatus: http.StatusOK,
+	}
+	ErrAccountNotFound = &AppError{
+		Code:         "account_not_found",
+		Status:       http.StatusNotFound,
+		Message:      "Account not found",
+		detail:       "account not found",
+		legacyStatus: http.StatusOK,
+	}
+	ErrInvalidCredentials = &AppError{
+		Code:         "invalid_credentials",
+		Status:       http.StatusUnauthorized,
+		Message:      "User or password is incorrect",
+		detail:       "user or password is incorrect",
+		legacyStatus: http.StatusOK,
+	}
+	ErrEmailAlreadyExists = &AppError{
+		Code:         "email_already_exists",
+		Status:       http.StatusConflict,
+		Message:      "Email already exists",
+		detail:       "email already exists",
+		legacyStatus: http.StatusOK,
+	}
+	ErrJourneyNotFound = &AppError{
+		Code:         "journey_not_found",
+		Status:       http.StatusNotFound,
+		Message:      "Journey not found",
+		detail:       "journey not found",
+		legacyStatus: http.StatusOK,
+	}
+	RecordNotFound = &AppError{
+		Code:         "record_not_found",
+		Status:       http.StatusNotFound,
+		Message:      "Record not found",
+		detail:       "record not found",
+		legacyStatus: http.StatusOK,
+	}
+	ErrThirdService = &AppError{
+		Code:         "third_party_error",
+		Status:       http.StatusBadGateway,
+		Message:      "Error from third party service",
+		detail:       "third service error",
+		legacyStatus: http.StatusOK,
+	}
+	ErrInvalidToken = &AppError{
+		Code:         "invalid_token",
+		Status:       http.StatusUnauthorized,
+		Message:      "Invalid token",
+		detail:       "invalid token",
+		legacyStatus: http.StatusOK,
+	}
+	ErrUserDoNotHavePremium = &AppError{
+		Code:         "premium_required",
+		Status:       http.StatusForbidden,
+		Message:      "User do not have premium access to generate plan more than 3 days",
+		detail:       "user do not have premium",
+		legacyStatus: http.StatusBadRequest,
+	}
+	ErrDisposableEmail = &AppError{
+		Code:         "disposable_email",
+		Status:       http.StatusBadRequest,
+		Message:      "Please register with a permanent email address",
+		detail:       "disposable email addresses are not allowed",
+		legacyStatus: http.StatusOK,
+	}
+	ErrImportFileInvalid = &AppError{
+		Code:         "import_file_invalid",
+		Status:       http.StatusBadRequest,
+		Message:      "Import file is invalid or has no usable places (KML or CSV with a name column, max 500 places)",
+		detail:       "import file is invalid or has no usable places",
+		legacyStatus: http.StatusOK,
+	}
+	ErrPlanRuleViolation = &AppError{
+		Code:         "plan_rule_violation",
+		Status:       http.StatusUnprocessableEntity,
+		Message:      "This activity breaks the destination rules or your day window (daily limit, restricted hours or required buffer between activities)",
+		detail:       "change breaks the destination rules",
+		legacyStatus: http.StatusOK,
+	}
+	ErrPOIImportFileInvalid = &AppError{
+		Code:         "poi_import_file_invalid",
+		Status:       http.StatusBadRequest,
+		Message:      "POI import needs a .csv or .xlsx file with name, latitude and longitude columns (max 5000 rows)",
+		detail:       "poi import file is invalid",
+		legacyStatus: http.StatusOK,
+	}
+	ErrQuizSessionNotFound = &AppError{
+		Code:         "quiz_session_not_found",
+		Status:       http.StatusNotFound,
+		Message:      "Quiz session not found or expired, please start the quiz again",
+		detail:       "quiz session not found",
+		legacyStatus: http.StatusOK,
+	}
+	ErrQuizSessionExpired = &AppError{
+		Code:         "quiz_session_expired",
+		Status:       http.StatusGone,
+		Message:      "Your quiz expired after a while without answers, please start it again",
+		detail:       "quiz session expired",
+		legacyStatus: http.StatusOK,
+	}
+	ErrPlanQuotaExceeded = &AppError{
+		Code:    "plan_quota_exceeded",
+		Status:  http.StatusTooManyRequests,
+		Message: "Free plan quota for this month is used up. Please subscribe for unlimited plans",
+		detail:  "monthly plan quota exceeded",
+		kind:    "quota_exceeded",
+	}
+	ErrJourneyBackupLimited = &AppError{
+		Code:    "journey_backup_limited",
+		Status:  http.StatusTooManyRequests,
+		Message: "You already requested several backups today, please try again later",
+		detail:  "daily journey backup limit reached",
+	}
+	ErrStorageNotConfigured = &AppError{
+		Code:         "storage_not_configured",
+		Status:       http.StatusServiceUnavailable,
+		Message:      "Document storage is not available right now",
+		detail:       "file storage encryption key is not configured",
+		legacyStatus: http.StatusOK,
+	}
+	ErrPresetNameTaken = &AppError{
+		Code:         "preset_name_taken",
+		Status:       http.StatusConflict,
+		Message:      "You already have a preset with this name",
+		detail:       "travel preset name taken",
+		legacyStatus: http.StatusOK,
+	}
+	ErrReceiptScanUnavailable = &AppError{
+		Code:         "receipt_scan_unavailable",
+		Status:       http.StatusServiceUnavailable,
+		Message:      "Receipt scanning is not available right now, please enter the expense by hand",
+		detail:       "receipt scanner is not configured",
+		legacyStatus: http.StatusOK,
+	}
+	ErrAccountBanned = &AppError{
+		Code:    "account_banned",
+		Status:  http.StatusForbidden,
+		Message: "This account has been suspended. Please contact support",
+		detail:  "account banned",
+	}
+	ErrSessionRevoked = &AppError{
+		Code:    "session_revoked",
+		Status:  http.StatusUnauthorized,
+		Message: "Your session has ended, please log in again",
+		detail:  "token issued before the account's sessions were revoked",
+	}
+	ErrLoginLocked = &AppError{
+		Code:    "login_locked",
+		Status:  http.StatusTooManyRequests,
+		Message: "Too many failed login attempts. Please try again later",
+		detail:  "login locked after repeated failures",
+	}
+	ErrInvalidAPIKey = &AppError{
+		Code:    "invalid_api_key",
+		Status:  http.StatusUnauthorized,
+		Message: "Invalid or revoked API key",
+		detail:  "api key unknown, revoked or expired",
+	}
+	ErrAPIKeyScope = &AppError{
+		Code:    "api_key_scope",
+		Status:  http.StatusForbidden,
+		Message: "This API key is not allowed to call this endpoint",
+		detail:  "api key lacks the scope of the route",
+	}
+	ErrCoordinatesInvalid = &AppError{
+		Code:    "coordinates_invalid",
+		Status:  http.StatusBadRequest,
+		Message: "Latitude must be between -90 and 90 and longitude between -180 and 180",
+		detail:  "coordinates out of range",
+	}
+	ErrCoordinatesZero = &AppError{
+		Code:    "coordinates_zero",
+		Status:  http.StatusBadRequest,
+		Message: "Coordinates are 0,0; please set the real location",
+		detail:  "coordinates are zero",
+	}
+	ErrCoordinatesSwapped = &AppError{
+		Code:    "coordinates_swapped",
+		Status:  http.StatusBadRequest,
+		Message: "Latitude and longitude look swapped",
+		detail:  "coordinates fit the country bounds once swapped",
+	}
+	ErrCoordinatesOutOfBounds = &AppError{
+		Code:    "coordinates_out_of_bounds",
+		Status:  http.StatusBadRequest,
+		Message: "Coordinates are outside the supported country",
+		detail:  "coordinates outside the country bounds",
+	}
+	ErrAPIQuotaExceeded = &AppError{
+		Code:    "api_quota_exceeded",
+		Status:  http.StatusTooManyRequests,
+		Message: "The daily quota of this API key is used up; it resets at midnight (Vietnam time)",
+		detail:  "api key daily quota exceeded",
+		kind:    "quota_exceeded",
+	}
+	ErrPlanHasSubscribers = &AppError{
+		Code:    "plan_has_subscribers",
+		Status:  http.StatusConflict,
+		Message: "This plan has active subscriptions; create a new version to change its price, currency or period",
+		detail:  "plan has active subscriptions",
+	}
+	ErrPlanCodeTaken = &AppError{
+		Code:    "plan_code_taken",
+		Status:  http.StatusConflict,
+		Message: "Another plan already uses this code",
+		detail:  "plan code exists",
+	}
+	ErrNoActiveSubscription = &AppError{
+		Code:    "no_active_subscription",
+		Status:  http.StatusNotFound,
+		Message: "You have no active subscription",
+		detail:  "no active subscription",
+	}
+	ErrSubscriptionPauseUsed = &AppError{
+		Code:    "subscription_pause_used",
+		Status:  http.StatusConflict,
+		Message: "This subscription was already paused once this period",
+		detail:  "subscription already paused this period",
+	}
+	ErrSubscriptionNotPaused = &AppError{
+		Code:    "subscription_not_paused",
+		Status:  http.StatusConflict,
+		Message: "Your subscription is not paused",
+		detail:  "no paused subscription",
+	}
+	ErrPaymentsUnavailable = &AppError{
+		Code:    "payments_unavailable",
+		Status:  http.StatusServiceUnavailable,
+		Message: "Payments are not available right now, please try again later",
+		detail:  "payment provider is not configured",
+	}
+	ErrLegalAcceptanceRequired = &AppError{
+		Code:    "legal_acceptance_required",
+		Status:  http.StatusUnavailableForLegalReasons,
+		Message: "Please review and accept the updated terms to continue",
+		detail:  "legal documents not accepted",
+		kind:    "legal_acceptance_required",
+	}
+	ErrLegalVersionTaken = &AppError{
+		Code:    "legal_version_taken",
+		Status:  http.StatusConflict,
+		Message: "This version of the document is already published",
+		detail:  "legal document version exists",
+	}
+	ErrLegalVersionOutdated = &AppError{
+		Code:    "legal_version_outdated",
+		Status:  http.StatusConflict,
+		Message: "A newer version of this document was published; please review it",
+		detail:  "accepted legal document is not the current version",
+	}
+	ErrCouponInvalid = &AppError{
+		Code:    "coupon_invalid",
+		Status:  http.StatusUnprocessableEntity,
+		Message: "This coupon cannot be used",
+		detail:  "coupon not applicable",
+	}
+	ErrCouponCodeTaken = &AppError{
+		Code:    "coupon_code_taken",
+		Status:  http.StatusConflict,
+		Message: "Another coupon already uses this code",
+		detail:  "coupon code exists",
+	}
+	ErrRefundUnsupported = &AppError{
+		Code:    "refund_unsupported",
+		Status:  http.StatusUnprocessableEntity,
+		Message: "This payment cannot be refunded automatically",
+		detail:  "payment provider has no refund for this payment",
+	}
+	ErrActivityTimeConflict = &AppError{
+		Code:         "activity_time_conflict",
+		Status:       http.StatusUnprocessableEntity,
+		Message:      "This time does not work for the activity (opening hours, overlap or travel time); see the suggested times",
+		detail:       "activity time conflicts with its day",
+		legacyStatus: http.StatusOK,
+	}
+	ErrTransactionNotFound = &AppError{
+		Code:    "transaction_not_found",
+		Status:  http.StatusNotFound,
+		Message: "Transaction not found",
+		detail:  "transaction not found",
+	}
+	ErrTransactionNotRefundable = &AppError{
+		Code:    "transaction_not_refundable",
+		Status:  http.StatusConflict,
+		Message: "Only paid transactions can be refunded, and only once",
+		detail:  "transaction is not paid or already refunded",
+	}
+	ErrJourneyDayNotEmpty = &AppError{
+		Code:    "journey_day_not_empty",
+		Status:  http.StatusConflict,
+		Message: "This day already has activities; send replace to overwrite them",
+		detail:  "journey day has activities",
+	}
 )
@@ -0,0 +1,36 @@
+package utils
+
+import "context"
+
+type dataOwnerKey struct{}
+
+// DataOwner is the account whose data a request handles and the purpose it is handled for.
+// Whatever is logged or stored on the side while serving the request (slow query captures)
+// is tagged with it, so an export or erasure of the account can find it.
+type DataOwner struct {
+	AccountID string
+	Purpose   string
+}
+
+// WithDataOwner tags ctx with the account it works for; the purpose is left as it was.
+func WithDataOwner(ctx context.Context, accountID string) context.Context {
+	owner, _ := DataOwnerFrom(ctx)
+	owner.AccountID = accountID
+	return context.WithValue(ctx, dataOwnerKey{}, owner)
+}
+
+// WithDataPurpose narrows the purpose of the data handled under ctx, e.g. to the planner's
+// prompt while searching with what the traveler typed.
+func WithDataPurpose(ctx context.Context, purpose string) context.Context {
+	owner, _ := DataOwnerFrom(ctx)
+	owner.Purpose = purpose
+	return context.WithValue(ctx, dataOwnerKey{}, owner)
+}
+
+func DataOwnerFrom(ctx context.Context) (DataOwner, bool) {
+	if ctx == nil {
+		return DataOwner{}, false
+	}
+	owner, ok := ctx.Value(dataOwnerKey{}).(DataOwner)
+	return owner, ok
+}
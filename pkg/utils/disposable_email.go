@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// Common throwaway-inbox providers. Extra domains can be added with DISPOSABLE_EMAIL_DOMAINS
+// (comma separated); it is read on the first check, so a change takes effect after a restart
+// without a new build.
+var disposableEmailDomains = map[string]struct{}{
+	"10minutemail.com":  {},
+	"33mail.com":        {},
+	"dispostable.com":   {},
+	"emailondeck.com":   {},
+	"fakeinbox.com":     {},
+	"getnada.com":       {},
+	"guerrillamail.com": {},
+	"guerrillamail.net": {},
+	"maildrop.cc":       {},
+	"mailinator.com":    {},
+	"mailnesia.com":     {},
+	"mintemail.com":     {},
+	"mohmal.com":        {},
+	"moakt.com":         {},
+	"sharklasers.com":   {},
+	"spamgourmet.com":   {},
+	"temp-mail.org":     {},
+	"tempmail.dev":      {},
+	"tempmailo.com":     {},
+	"tempr.email":       {},
+	"throwawaymail.com": {},
+	"trashmail.com":     {},
+	"yopmail.com":       {},
+	"yopmail.net":       {},
+	"mail.tm":           {},
+	"burnermail.io":     {},
+	"discard.email":     {},
+	"mytemp.email":      {},
+	"spambox.us":        {},
+	"inboxkitten.com":   {},
+}
+
+var loadExtraDisposableDomains sync.Once
+
+// addExtraDisposableDomains merges DISPOSABLE_EMAIL_DOMAINS into the list. It runs on first
+// use rather than in init, which would read the environment before .env is loaded.
+func addExtraDisposableDomains() {
+	for _, d := range strings.Split(os.Getenv("DISPOSABLE_EMAIL_DOMAINS"), ",") {
+		if d = strings.ToLower(strings.TrimSpace(d)); d != "" {
+			disposableEmailDomains[d] = struct{}{}
+		}
+	}
+}
+
+// IsDisposableEmail reports whether the address belongs to a known throwaway provider.
+// Subdomains of a listed domain count as well (e.g. x.mailinator.com).
+func IsDisposableEmail(email string) bool {
+	loadExtraDisposableDomains.Do(addExtraDisposableDomains)
+
+	at := strings.LastIndex(email, "@")
+	if at < 0 || at == len(email)-1 {
+		return false
+	}
+	domain := strings.ToLower(strings.TrimSpace(email[at+1:]))
+	for domain != "" {
+		if _, ok := disposableEmailDomains[domain]; ok {
+			return true
+		}
+		dot := strings.Index(domain, ".")
+		if dot < 0 {
+			break
+		}
+		domain = domain[dot+1:]
+	}
+	return false
+}
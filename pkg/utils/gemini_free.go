@@ -5,10 +5,10 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
-	"github.com/sashabaranov/go-openai"
 	"hash/fnv"
 	"log"
 	"math"
+	"os"
 	"regexp"
 	"strings"
 	"sync"
@@ -17,25 +17,24 @@ import (
 
 	"github.com/google/generative-ai-go/genai"
 	"github.com/pgvector/pgvector-go"
-	"google.golang.org/api/option"
 )
 
 // GeminiEmbeddingClient implements EmbeddingClientInterface using Google's Gemini models
 type GeminiEmbeddingClient struct {
-	client *genai.Client
+	client *rotatingGenAI
 	model  string
 }
 
-// NewGeminiEmbeddingClient creates a new Gemini client
-func NewGeminiEmbeddingClient(apiKey, model string) (EmbeddingClientInterface, error) {
+// NewGeminiEmbeddingClient creates a new Gemini client; a new key returned by apiKey is used
+// from the next call on.
+func NewGeminiEmbeddingClient(apiKey KeyFunc, model string) (EmbeddingClientInterface, error) {
 	if model == "" {
 		model = "gemini-2.5-flash-lite" // Free tier model
 	}
 
-	ctx := context.Background()
-	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
+	client, err := newRotatingGenAI(apiKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+		return nil, err
 	}
 
 	return &GeminiEmbeddingClient{
@@ -51,62 +50,22 @@ func (c *GeminiEmbeddingClient) GeneratePlanOnlyJSON(
 	dayCount int,
 ) (string, error) {
 
-	if dayCount < 1 || dayCount > 30 {
-		return "", fmt.Errorf("bad dayCount")
-	}
-	if len(poiList) == 0 {
-		return "", fmt.Errorf("no pois")
+	if err := validatePlanOnlyArgs(poiList, dayCount); err != nil {
+		return "", err
 	}
 
-	m := c.client.GenerativeModel(c.model)
+	client, err := c.client.get(ctx)
+	if err != nil {
+		return "", err
+	}
+	m := client.GenerativeModel(c.model)
 	// Force JSON-only so you can delete brace-matching hacks:
 	m.ResponseMIMEType = "application/json"
 	m.SetTopP(0.5)
 	m.SetTopK(20)
 	m.SetTemperature(0.1)
 
-	schema := `
-{
-  "destination": "string",
-  "duration_days": 3,
-  "days": [
-    {
-      "day": 1,
-      "activities": [
-        {"start_time":"09:00","end_time":"11:00","main_poi_id":"<ID from list>"}
-      ]
-    }
-  ]
-}`
-
-	// Build a tight instruction. No prose, exact JSON keys.
-	var poiBuf strings.Builder
-	for _, p := range poiList {
-		fmt.Fprintf(&poiBuf, "- ID:%s | Name:%s | Category:%s | Description:%s \n", p.ID, p.Name, p.Category, p.Description)
-	}
-
-	prompt := fmt.Sprintf(`
-You are scheduling a %d-day travel plan. Return **JSON only** that exactly matches the schema below. 
-Use only POI IDs from the list. Ensure realistic times (09:00–21:00), 2–5 activities/day, and do not overlap times.
-Respect a relaxed pace if the profile indicates "relaxed", otherwise standard.
-
-Schema (example, match keys exactly):
-%s
-
-Profile (read-only, use to bias selection and density):
-%+v
-
-Allowed POIs (use IDs from here only):
-%s
-
-Hard constraints:
-- Exactly %d days in "days".
-- Each day.day = 1..%d (no gaps).
-- start_time < end_time; times formatted HH:MM.
-- Choose diverse categories when possible.
-
-Return JSON only. No comments, no markdown.
-`, dayCount, schema, profile, poiBuf.String(), dayCount, dayCount)
+	prompt := buildPlanOnlyPrompt(profile, poiList, dayCount)
 
 	resp, err := m.GenerateContent(ctx, genai.Text(prompt))
 	if err != nil {
@@ -161,7 +120,11 @@ func (c *GeminiEmbeddingClient) GenerateStructuredPlan(ctx context.Context, user
 		return "", fmt.Errorf("day count cannot exceed 30 days")
 	}
 
-	model := c.client.GenerativeModel(c.model)
+	client, err := c.client.get(ctx)
+	if err != nil {
+		return "", err
+	}
+	model := client.GenerativeModel(c.model)
 
 	// OPTIMIZATION 1: More aggressive model settings for faster generation
 	model.SetTemperature(0.1)      // Lower temperature = faster, more deterministic
@@ -732,11 +695,17 @@ func NewEmbeddingClient(provider, apiKey, model string) (EmbeddingClientInterfac
 	switch strings.ToLower(provider) {
 	case "openai":
 		return &OpenAIEmbeddingClient{
-			client: openai.NewClient(apiKey),
+			client: newOpenAIClient(apiKey),
 			model:  model,
 		}, nil
 	case "gemini":
-		return NewGeminiEmbeddingClient(apiKey, model)
+		return NewGeminiEmbeddingClient(StaticKey(apiKey), model)
+	case "anthropic":
+		return NewAnthropicClient(apiKey, model), nil
+	case "ollama":
+		return NewOllamaClient(os.Getenv("OLLAMA_BASE_URL"), model, os.Getenv("OLLAMA_EMBED_MODEL")), nil
+	case "mock":
+		return NewMockAIClient(), nil
 	default:
 		return nil, fmt.Errorf("unsupported provider: %s", provider)
 	}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+)
+
+// KeyFunc returns the current value of an API key; secrets.Store.Get behind it lets a key be
+// rotated while running.
+type KeyFunc func() string
+
+// StaticKey is a KeyFunc for a key that never changes.
+func StaticKey(key string) KeyFunc {
+	return func() string { return key }
+}
+
+// rotatingGenAI holds a Gemini client for the current API key and builds a new one when the
+// key changes. Calls already running finish on the client they started with.
+type rotatingGenAI struct {
+	key KeyFunc
+
+	mu      sync.Mutex
+	current string
+	client  *genai.Client
+}
+
+func newRotatingGenAI(key KeyFunc) (*rotatingGenAI, error) {
+	r := &rotatingGenAI{key: key}
+	if _, err := r.get(context.Background()); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingGenAI) get(ctx context.Context) (*genai.Client, error) {
+	key := r.key()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.client != nil && key == r.current {
+		return r.client, nil
+	}
+	if key == "" {
+		if r.client != nil {
+			return r.client, nil // keep the last key rather than fail every call
+		}
+		return nil, errors.New("gemini: no API key")
+	}
+	// The SDK's Google transport is already instrumented with otelhttp, so calls are traced as is
+	client, err := genai.NewClient(context.WithoutCancel(ctx), option.WithAPIKey(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+	}
+	r.client, r.current = client, key
+	return client, nil
+}
+
+func (r *rotatingGenAI) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.client == nil {
+		return nil
+	}
+	return r.client.Close()
+}
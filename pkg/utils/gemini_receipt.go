@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// ReceiptScan is what was read off a receipt photo; fields that could not be read stay empty.
+type ReceiptScan struct {
+	Total    float64 `json:"total"`    // grand total, in Currency
+	Currency string  `json:"currency"` // ISO 4217, e.g. VND
+	Merchant string  `json:"merchant"`
+	Date     string  `json:"date"`     // YYYY-MM-DD
+	Category string  `json:"category"` // food, transport, lodging, activities, shopping or other
+}
+
+type ReceiptScanner interface {
+	ScanReceipt(ctx context.Context, image []byte, mimeType string) (*ReceiptScan, error)
+}
+
+// GeminiReceiptScanner reads receipts with a Gemini vision model.
+type GeminiReceiptScanner struct {
+	client *rotatingGenAI
+	model  string
+}
+
+func NewGeminiReceiptScanner(apiKey KeyFunc, model string) (ReceiptScanner, error) {
+	if model == "" {
+		model = "gemini-2.5-flash"
+	}
+	client, err := newRotatingGenAI(apiKey)
+	if err != nil {
+		return nil, err
+	}
+	return &GeminiReceiptScanner{client: client, model: model}, nil
+}
+
+const receiptPrompt = `Read this receipt or bill photo and return only JSON:
+{"total": number, "currency": "ISO 4217 code", "merchant": "shop name", "date": "YYYY-MM-DD", "category": "food|transport|lodging|activities|shopping|other"}
+- total is the grand total actually paid (after tax, service charge and discounts), as a plain number. Vietnamese receipts use "." as thousands separator: "125.000" is 125000.
+- Use VND when the receipt shows đ, ₫, VND or VNĐ, or has no currency but Vietnamese text.
+- Leave a field empty ("" or 0) when it is not on the receipt; never guess.`
+
+func (s *GeminiReceiptScanner) ScanReceipt(ctx context.Context, image []byte, mimeType string) (*ReceiptScan, error) {
+	client, err := s.client.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m := client.GenerativeModel(s.model)
+	m.ResponseMIMEType = "application/json"
+	m.SetTemperature(0)
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	resp, err := m.GenerateContent(ctx, genai.Blob{MIMEType: mimeType, Data: image}, genai.Text(receiptPrompt))
+	if err != nil {
+		return nil, fmt.Errorf("gemini: %w", err)
+	}
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		return nil, fmt.Errorf("no content")
+	}
+
+	var scan ReceiptScan
+	content := fmt.Sprintf("%v", resp.Candidates[0].Content.Parts[0])
+	if err := json.Unmarshal([]byte(content), &scan); err != nil {
+		return nil, fmt.Errorf("gemini: receipt is not valid json: %w", err)
+	}
+	if scan.Total < 0 || math.IsNaN(scan.Total) || math.IsInf(scan.Total, 0) {
+		scan.Total = 0
+	}
+	scan.Currency = strings.ToUpper(strings.TrimSpace(scan.Currency))
+	scan.Merchant = strings.TrimSpace(scan.Merchant)
+	scan.Category = strings.ToLower(strings.TrimSpace(scan.Category))
+	return &scan, nil
+}
@@ -28,6 +28,34 @@ func CreateToken(userId uuid.UUID, role string) (string, error) {
 	return token.SignedString(jwtKey)
 }
 
+// twoFactorKey signs 2FA challenge tokens. It differs from jwtKey so a challenge token is never
+// accepted as an access token.
+var twoFactorKey = append([]byte(os.Getenv("JWT_SECRET")), []byte("|2fa-challenge")...)
+
+// CreateTwoFactorChallenge is the token a password login returns when the account has 2FA on;
+// it is exchanged for an access token together with a TOTP or backup code.
+func CreateTwoFactorChallenge(userId uuid.UUID) (string, error) {
+	claims := &jwt.RegisteredClaims{
+		Subject:   userId.String(),
+		Audience:  jwt.ClaimStrings{"2fa"},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(5 * time.Minute)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(twoFactorKey)
+}
+
+// ValidateTwoFactorChallenge returns the account ID of a valid, unexpired challenge token.
+func ValidateTwoFactorChallenge(tokenString string) (string, error) {
+	claims := &jwt.RegisteredClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return twoFactorKey, nil
+	}, jwt.WithAudience("2fa"), jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid {
+		return "", ErrInvalidToken
+	}
+	return claims.Subject, nil
+}
+
 func ValidateToken(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
@@ -0,0 +1,17 @@
+package utils
+
+// PendingLegalDocument is sent with ErrLegalAcceptanceRequired: a document version the
+// account has to accept before going on.
+type PendingLegalDocument struct {
+	Kind        string `json:"kind"` // terms | privacy
+	Version     string `json:"version"`
+	Title       string `json:"title"`
+	URL         string `json:"url,omitempty"`
+	PublishedAt string `json:"published_at"` // RFC3339
+}
+
+// NewLegalAcceptanceError lists what is left to accept; errors.Is matches
+// ErrLegalAcceptanceRequired.
+func NewLegalAcceptanceError(pending []PendingLegalDocument) *AppError {
+	return ErrLegalAcceptanceRequired.WithData(pending)
+}
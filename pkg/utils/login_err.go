@@ -0,0 +1,21 @@
+package utils
+
+import "time"
+
+// LoginLock is sent with ErrLoginLocked so clients can show when to try again.
+type LoginLock struct {
+	LockedUntil       string `json:"locked_until"` // RFC3339
+	RetryAfterSeconds int    `json:"retry_after_seconds"`
+}
+
+// NewLoginLockedError reports a login lockout until until, with the matching Retry-After;
+// errors.Is matches ErrLoginLocked.
+func NewLoginLockedError(until time.Time) *AppError {
+	wait := time.Until(until).Round(time.Second)
+	if wait < time.Second {
+		wait = time.Second
+	}
+	return ErrLoginLocked.
+		WithData(LoginLock{LockedUntil: until.UTC().Format(time.RFC3339), RetryAfterSeconds: int(wait.Seconds())}).
+		WithRetryAfter(wait)
+}
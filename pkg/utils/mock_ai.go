@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+	"vivu/internal/models/request_models"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+// MockAIClient is a deterministic, offline AI provider. It is meant for load tests and
+// local runs where we want the whole plan pipeline (DB, matrix, save) without paying for tokens.
+type MockAIClient struct {
+	latency time.Duration
+	hasher  *GeminiEmbeddingClient // only used for its hash-based textToVector
+}
+
+// NewMockAIClient creates the mock provider. MOCK_AI_LATENCY_MS simulates model latency.
+func NewMockAIClient() EmbeddingClientInterface {
+	latency := 0 * time.Millisecond
+	if v, err := strconv.Atoi(os.Getenv("MOCK_AI_LATENCY_MS")); err == nil && v > 0 {
+		latency = time.Duration(v) * time.Millisecond
+	}
+	return &MockAIClient{
+		latency: latency,
+		hasher:  &GeminiEmbeddingClient{},
+	}
+}
+
+func (m *MockAIClient) wait(ctx context.Context) error {
+	if m.latency <= 0 {
+		return nil
+	}
+	select {
+	case <-time.After(m.latency):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *MockAIClient) GetEmbedding(ctx context.Context, text string) (pgvector.Vector, error) {
+	return m.hasher.textToVector(text), nil
+}
+
+func (m *MockAIClient) GetEmbeddings(ctx context.Context, texts []string) ([]pgvector.Vector, error) {
+	out := make([]pgvector.Vector, len(texts))
+	for i, t := range texts {
+		out[i] = m.hasher.textToVector(t)
+	}
+	return out, nil
+}
+
+func (m *MockAIClient) GenerateStructuredPlan(ctx context.Context, userPrompt string, pois []string, dayCount int) (string, error) {
+	return "", fmt.Errorf("mock provider does not support structured plans")
+}
+
+// GeneratePlanOnlyJSON spreads the given POIs round-robin over three fixed slots per day.
+func (m *MockAIClient) GeneratePlanOnlyJSON(ctx context.Context, profile any, poiList []request_models.POISummary, dayCount int) (string, error) {
+	if dayCount < 1 || dayCount > 30 {
+		return "", fmt.Errorf("bad dayCount")
+	}
+	if len(poiList) == 0 {
+		return "", fmt.Errorf("no pois")
+	}
+	if err := m.wait(ctx); err != nil {
+		return "", err
+	}
+
+	type activity struct {
+		StartTime string `json:"start_time"`
+		EndTime   string `json:"end_time"`
+		MainPOIID string `json:"main_poi_id"`
+	}
+	type day struct {
+		Day        int        `json:"day"`
+		Activities []activity `json:"activities"`
+	}
+
+	slots := [][2]string{{"09:00", "11:00"}, {"13:00", "15:00"}, {"17:00", "19:00"}}
+	days := make([]day, 0, dayCount)
+	next := 0
+	for d := 1; d <= dayCount; d++ {
+		acts := make([]activity, 0, len(slots))
+		for _, s := range slots {
+			acts = append(acts, activity{StartTime: s[0], EndTime: s[1], MainPOIID: poiList[next%len(poiList)].ID})
+			next++
+		}
+		days = append(days, day{Day: d, Activities: acts})
+	}
+
+	out, err := json.Marshal(map[string]any{
+		"destination":   "mock",
+		"duration_days": dayCount,
+		"days":          days,
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
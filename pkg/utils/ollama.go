@@ -0,0 +1,210 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"vivu/internal/models/request_models"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+const (
+	defaultOllamaBaseURL    = "http://localhost:11434"
+	defaultOllamaChatModel  = "llama3.1"
+	defaultOllamaEmbedModel = "nomic-embed-text"
+
+	// poi_embeddings.embedding is vector(1536); smaller local vectors are zero-padded,
+	// which leaves cosine distances unchanged.
+	storedEmbeddingDimensions = 1536
+)
+
+// OllamaClient talks to a local or self-hosted Ollama server, so the platform runs without
+// external AI keys. Vectors from it are not comparable with OpenAI/Gemini ones: reindex after switching.
+type OllamaClient struct {
+	baseURL    string
+	chatModel  string
+	embedModel string
+	http       *http.Client
+	parser     *GeminiEmbeddingClient // only used for its cleanJSONResponse
+}
+
+// OllamaAPIError is a non-2xx answer from the Ollama server.
+type OllamaAPIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *OllamaAPIError) Error() string {
+	return fmt.Sprintf("ollama: %d: %s", e.StatusCode, e.Message)
+}
+
+// NewOllamaClient creates the Ollama provider; empty arguments fall back to
+// http://localhost:11434, llama3.1 and nomic-embed-text.
+func NewOllamaClient(baseURL, chatModel, embedModel string) EmbeddingClientInterface {
+	if baseURL == "" {
+		baseURL = defaultOllamaBaseURL
+	}
+	if chatModel == "" {
+		chatModel = defaultOllamaChatModel
+	}
+	if embedModel == "" {
+		embedModel = defaultOllamaEmbedModel
+	}
+	return &OllamaClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		chatModel:  chatModel,
+		embedModel: embedModel,
+		// Local models on CPU are slow; the AI router's per-call timeout still applies
+		http:   NewTracedHTTPClient(5 * time.Minute),
+		parser: &GeminiEmbeddingClient{},
+	}
+}
+
+func (c *OllamaClient) GetEmbedding(ctx context.Context, text string) (pgvector.Vector, error) {
+	vectors, err := c.GetEmbeddings(ctx, []string{text})
+	if err != nil {
+		return pgvector.Vector{}, err
+	}
+	return vectors[0], nil
+}
+
+func (c *OllamaClient) GetEmbeddings(ctx context.Context, texts []string) ([]pgvector.Vector, error) {
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("no input texts provided")
+	}
+	var out struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	if err := c.post(ctx, "/api/embed", map[string]any{"model": c.embedModel, "input": texts}, &out); err != nil {
+		return nil, fmt.Errorf("embedding request failed: %w", err)
+	}
+	if len(out.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("embedding count mismatch: got %d, expected %d", len(out.Embeddings), len(texts))
+	}
+
+	vectors := make([]pgvector.Vector, len(out.Embeddings))
+	for i, e := range out.Embeddings {
+		if len(e) > storedEmbeddingDimensions {
+			return nil, fmt.Errorf("ollama: %s returns %d dimensions, at most %d are supported", c.embedModel, len(e), storedEmbeddingDimensions)
+		}
+		padded := make([]float32, storedEmbeddingDimensions)
+		copy(padded, e)
+		vectors[i] = pgvector.NewVector(padded)
+	}
+	return vectors, nil
+}
+
+func (c *OllamaClient) GenerateStructuredPlan(ctx context.Context, userPrompt string, pois []string, dayCount int) (string, error) {
+	if strings.TrimSpace(userPrompt) == "" {
+		return "", fmt.Errorf("user prompt cannot be empty")
+	}
+	if len(pois) == 0 {
+		return "", fmt.Errorf("POI list cannot be empty")
+	}
+	if dayCount < 1 || dayCount > 30 {
+		return "", fmt.Errorf("bad dayCount")
+	}
+
+	system := fmt.Sprintf(`You are a travel planner AI.
+
+Generate a %d-day itinerary based on the user prompt and the list of POIs.
+
+Each day should include:
+- day: number
+- date: optional
+- activities: list of activity blocks
+- Each activity includes: activity, start_time, end_time, main_poi_id, alternative_poi_ids, what_to_do
+
+Only use the POI IDs provided. Do not invent new places.
+Return valid JSON.`, dayCount)
+
+	var poiBuf strings.Builder
+	for _, poi := range pois {
+		poiBuf.WriteString("- " + poi + "\n")
+	}
+	return c.chatJSON(ctx, system, fmt.Sprintf("User prompt: %s\n\nAvailable POIs:\n%s", userPrompt, poiBuf.String()), 0.3)
+}
+
+func (c *OllamaClient) GeneratePlanOnlyJSON(ctx context.Context, profile any, poiList []request_models.POISummary, dayCount int) (string, error) {
+	if err := validatePlanOnlyArgs(poiList, dayCount); err != nil {
+		return "", err
+	}
+	return c.chatJSON(ctx, "", buildPlanOnlyPrompt(profile, poiList, dayCount), 0.1)
+}
+
+// chatJSON runs one non-streaming chat turn in Ollama's JSON mode.
+func (c *OllamaClient) chatJSON(ctx context.Context, system, user string, temperature float64) (string, error) {
+	messages := []map[string]string{}
+	if system != "" {
+		messages = append(messages, map[string]string{"role": "system", "content": system})
+	}
+	messages = append(messages, map[string]string{"role": "user", "content": user})
+
+	var out struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	}
+	err := c.post(ctx, "/api/chat", map[string]any{
+		"model":    c.chatModel,
+		"messages": messages,
+		"stream":   false,
+		"format":   "json",
+		"options":  map[string]any{"temperature": temperature},
+	}, &out)
+	if err != nil {
+		return "", err
+	}
+
+	content := c.parser.cleanJSONResponse(out.Message.Content)
+	if content == "" {
+		return "", fmt.Errorf("no content")
+	}
+	if !json.Valid([]byte(content)) {
+		return "", fmt.Errorf("not valid json")
+	}
+	return content, nil
+}
+
+func (c *OllamaClient) post(ctx context.Context, path string, body any, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("ollama: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		_ = json.Unmarshal(raw, &e)
+		if e.Error == "" {
+			e.Error = http.StatusText(resp.StatusCode)
+		}
+		return &OllamaAPIError{StatusCode: resp.StatusCode, Message: e.Error}
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("ollama: decode response: %w", err)
+	}
+	return nil
+}
@@ -2,7 +2,9 @@ package utils
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"os"
 	"vivu/internal/models/request_models"
 
 	"github.com/pgvector/pgvector-go"
@@ -22,19 +24,53 @@ type EmbeddingClientInterface interface {
 }
 
 type OpenAIEmbeddingClient struct {
-	client *openai.Client
-	model  string
+	client    *openai.Client
+	model     string
+	chatModel string // used for plan generation; OPENAI_CHAT_MODEL, default gpt-4o-mini
 }
 
 func (c *OpenAIEmbeddingClient) GeneratePlanOnlyJSON(ctx context.Context, profile any, poiList []request_models.POISummary, dayCount int) (string, error) {
-	//TODO implement me
-	panic("implement me")
+	if err := validatePlanOnlyArgs(poiList, dayCount); err != nil {
+		return "", err
+	}
+
+	resp, err := c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model: c.chatModel,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleUser, Content: buildPlanOnlyPrompt(profile, poiList, dayCount)},
+		},
+		ResponseFormat: &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONObject},
+		Temperature:    0.1,
+	})
+	if err != nil {
+		return "", fmt.Errorf("openai: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no content")
+	}
+	content := resp.Choices[0].Message.Content
+	if !json.Valid([]byte(content)) {
+		return "", fmt.Errorf("not valid json")
+	}
+	return content, nil
+}
+
+// newOpenAIClient builds the SDK client on a traced HTTP client.
+func newOpenAIClient(apiKey string) *openai.Client {
+	cfg := openai.DefaultConfig(apiKey)
+	cfg.HTTPClient = NewTracedHTTPClient(0)
+	return openai.NewClientWithConfig(cfg)
 }
 
 func NewOpenAIEmbeddingClient(apiKey, model string) EmbeddingClientInterface {
+	chatModel := os.Getenv("OPENAI_CHAT_MODEL")
+	if chatModel == "" {
+		chatModel = openai.GPT4oMini
+	}
 	return &OpenAIEmbeddingClient{
-		client: openai.NewClient(apiKey),
-		model:  model,
+		client:    newOpenAIClient(apiKey),
+		model:     model,
+		chatModel: chatModel,
 	}
 }
 
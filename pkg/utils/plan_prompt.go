@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"vivu/internal/models/request_models"
+)
+
+func validatePlanOnlyArgs(poiList []request_models.POISummary, dayCount int) error {
+	if dayCount < 1 || dayCount > 30 {
+		return fmt.Errorf("bad dayCount")
+	}
+	if len(poiList) == 0 {
+		return fmt.Errorf("no pois")
+	}
+	return nil
+}
+
+// buildPlanOnlyPrompt is the plan-only instruction shared by every provider, so a failover
+// to another model produces the same JSON shape.
+func buildPlanOnlyPrompt(profile any, poiList []request_models.POISummary, dayCount int) string {
+	schema := `
+{
+  "destination": "string",
+  "duration_days": 3,
+  "days": [
+    {
+      "day": 1,
+      "activities": [
+        {"start_time":"09:00","end_time":"11:00","main_poi_id":"<ID from list>"}
+      ]
+    }
+  ]
+}`
+
+	// Build a tight instruction. No prose, exact JSON keys.
+	var poiBuf strings.Builder
+	for _, p := range poiList {
+		fmt.Fprintf(&poiBuf, "- ID:%s | Name:%s | Category:%s | Description:%s", p.ID, p.Name, p.Category, p.Description)
+		if p.SuggestedDay > 0 {
+			fmt.Fprintf(&poiBuf, " | Day:%d", p.SuggestedDay)
+		}
+		poiBuf.WriteString(" \n")
+	}
+
+	return fmt.Sprintf(`
+You are scheduling a %d-day travel plan. Return **JSON only** that exactly matches the schema below. 
+Use only POI IDs from the list. Ensure realistic times inside the profile's day window (day_start–day_end, default 09:00–21:00), 2–5 activities/day, and do not overlap times.
+Respect the profile's pace: "relaxed" means 2–3 activities/day with long breaks, "packed" up to 5, otherwise standard.
+
+Schema (example, match keys exactly):
+%s
+
+Profile (read-only, use to bias selection and density):
+%+v
+
+Allowed POIs (use IDs from here only):
+%s
+
+Hard constraints:
+- Exactly %d days in "days".
+- Each day.day = 1..%d (no gaps).
+- start_time < end_time; times formatted HH:MM.
+- Choose diverse categories when possible.
+- Put viewpoints and photography spots near sunset (or sunrise) when the day allows.
+- If the profile has destination_rules, follow every one of them; they override the defaults above.
+- Never schedule a POI that matches anything in the profile's must_not_include.
+- Schedule every POI in the profile's must_include_poi_ids exactly once, and prefer those in preferred_poi_ids when they fit the day.
+- If the profile has dietary needs, only pick food places that can serve all of them.
+- POIs marked Day:N are grouped by area; schedule them on day N so each day stays in one area.
+- If the profile has diversity_hints, a previous attempt was too monotonous; follow every hint.
+- Use each POI at most once across all days, and never use an ID listed in the profile's already_used_poi_ids.
+
+Return JSON only. No comments, no markdown.
+`, dayCount, schema, profile, poiBuf.String(), dayCount, dayCount)
+}
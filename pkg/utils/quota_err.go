@@ -0,0 +1,14 @@
+package utils
+
+// PlanQuota is sent with ErrPlanQuotaExceeded so clients can show when plans come back.
+type PlanQuota struct {
+	Limit     int    `json:"limit"`
+	Used      int    `json:"used"`
+	Remaining int    `json:"remaining"`
+	ResetsAt  string `json:"resets_at"` // RFC3339, start of next month (VN)
+}
+
+// NewPlanQuotaError reports a used-up monthly quota; errors.Is matches ErrPlanQuotaExceeded.
+func NewPlanQuotaError(q PlanQuota) *AppError {
+	return ErrPlanQuotaExceeded.WithData(q)
+}
@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+)
+
+// Tracer opens the application's own spans (services, AI calls). It resolves the global
+// provider lazily, so it is safe to use before tracing is configured.
+var Tracer = otel.Tracer("vivu")
+
+// NewTracedHTTPClient returns a client whose requests are child spans of the request context
+// and carry the trace headers to the remote side.
+func NewTracedHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: otelhttp.NewTransport(http.DefaultTransport)}
+}